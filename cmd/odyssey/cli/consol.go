@@ -43,6 +43,19 @@ func (c *ConsolOpsCLI) TriggerRefresh(ctx context.Context, groupID, period strin
 	return c.jobs.Enqueue(ctx, task, asynq.MaxRetry(3))
 }
 
+// TriggerRestatement enqueues a rebuild of a past period after a member
+// restated it. Locked periods need restatement.OverrideLock.
+func (c *ConsolOpsCLI) TriggerRestatement(ctx context.Context, groupID, period string, restatement jobs.ConsolRestatement) (*asynq.TaskInfo, error) {
+	if c == nil || c.jobs == nil {
+		return nil, errors.New("consol cli: client not configured")
+	}
+	task, err := jobs.NewConsolidateRestatementTask(groupID, period, restatement)
+	if err != nil {
+		return nil, err
+	}
+	return c.jobs.Enqueue(ctx, task, asynq.MaxRetry(3))
+}
+
 // InspectQueue proxies queue statistics for observability.
 func (c *ConsolOpsCLI) InspectQueue(ctx context.Context) (QueueStats, error) {
 	if c == nil || c.jobs == nil {
@@ -53,6 +53,8 @@ func (c *JobsCLI) Trigger(ctx context.Context, name string) (*asynq.TaskInfo, er
 		task, err = jobs.NewAnomalyScanTask(12, 2.5)
 	case jobs.TaskConsolidateRefresh:
 		task, err = jobs.NewConsolidateRefreshTask("all", "active")
+	case jobs.TaskDataExport:
+		task = jobs.NewDataExportTask()
 	default:
 		return nil, fmt.Errorf("jobs cli: unsupported job %s", name)
 	}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/odyssey-erp/odyssey-erp/internal/approvals"
+	"github.com/odyssey-erp/odyssey-erp/internal/archive"
+	"github.com/odyssey-erp/odyssey-erp/internal/netting"
 	"github.com/odyssey-erp/odyssey-erp/internal/platform/cache"
 	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/i18n"
+	"github.com/odyssey-erp/odyssey-erp/internal/reportjob"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 	"html/template"
 	"log/slog"
@@ -39,6 +44,8 @@ import (
 	closehttp "github.com/odyssey-erp/odyssey-erp/internal/close/http"
 	"github.com/odyssey-erp/odyssey-erp/internal/consol"
 	consolhttp "github.com/odyssey-erp/odyssey-erp/internal/consol/http"
+	"github.com/odyssey-erp/odyssey-erp/internal/currency"
+	"github.com/odyssey-erp/odyssey-erp/internal/dashboard"
 	deliveryorders "github.com/odyssey-erp/odyssey-erp/internal/delivery/orders"
 	eliminationpkg "github.com/odyssey-erp/odyssey-erp/internal/elimination"
 	eliminationhttp "github.com/odyssey-erp/odyssey-erp/internal/elimination/http"
@@ -48,11 +55,17 @@ import (
 	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
 	jobmetrics "github.com/odyssey-erp/odyssey-erp/internal/jobs"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/taxes"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/warehouses"
+	"github.com/odyssey-erp/odyssey-erp/internal/notify"
 	"github.com/odyssey-erp/odyssey-erp/internal/observability"
+	"github.com/odyssey-erp/odyssey-erp/internal/openitems"
 	"github.com/odyssey-erp/odyssey-erp/internal/procurement"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/roles"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/documents"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/users"
 	variancepkg "github.com/odyssey-erp/odyssey-erp/internal/variance"
@@ -137,7 +150,7 @@ func main() {
 	sessionManager := shared.NewSessionManager(redisClient, "odyssey_session", cfg.SessionSecret, cfg.SessionTTL, cfg.IsProduction())
 	csrfManager := shared.NewCSRFManager(cfg.CSRFSecret)
 
-	templates, err := view.NewEngine()
+	templates, err := view.NewEngineWithFormatting(view.Formatting{Locale: i18n.For(cfg.UILocale), Currency: cfg.FXBaseCurrency})
 	if err != nil {
 		logger.Error("parse templates", slog.Any("error", err))
 		os.Exit(1)
@@ -158,24 +171,63 @@ func main() {
 	mappingRepo := mappings.NewRepository(dbpool)
 
 	journalService := journals.NewService(journalRepo, auditLogger, closeService)
-	accountingHandler := accounting.NewHandler(logger, dbpool, templates, auditLogger, closeService)
+	closeService.SetYearEnd(journalService, mappingRepo)
 	integrationHooks := integration.NewHooks(journalService, periodRepo, mappingRepo)
+	integrationHooks.SetPostingQueue(journalService)
+	integrationHooks.SetDimensionResolver(warehouses.NewService(warehouses.NewRepository(dbpool)))
+	currencyService := currency.NewService(currency.NewRepository(dbpool), cfg.FXBaseCurrency)
+	integrationHooks.SetRateResolver(currencyService)
+	approvalMatrix := approvals.NewMatrixService(approvals.NewRepository(dbpool))
+	approvalMatrix.SetRateResolver(currencyService)
 
 	inventoryRepo := inventory.NewRepository(dbpool)
-	inventoryService := inventory.NewService(inventoryRepo, auditLogger, idempotencyStore, inventory.ServiceConfig{}, integrationHooks)
+	inventoryService := inventory.NewService(inventoryRepo, auditLogger, idempotencyStore, inventory.ServiceConfig{TransferApprovalThreshold: cfg.InventoryTransferApprovalThreshold, GLTolerance: cfg.InventoryGLTolerance, BatchStockCountJournals: cfg.InventoryStockCountGLBatch, Replenishment: inventory.ReplenishmentParams{LeadTimeDays: cfg.InventoryReplenishmentLeadTimeDays, DemandDays: cfg.InventoryReplenishmentDemandDays}}, integrationHooks)
+	inventoryService.SetApprovals(approvalRecorder)
+	inventoryService.SetPeriodGuard(periodRepo)
+	inventoryService.SetApprovalRouter(approvalMatrix)
 
 	procurementRepo := procurement.NewRepository(dbpool)
 	procurementService := procurement.NewService(procurementRepo, inventoryService, approvalRecorder, auditLogger, idempotencyStore, integrationHooks)
+	procurementService.SetCurrencyValidator(currencyService)
+	procurementService.SetApprovalRouter(approvalMatrix)
+	grnCostPolicy, err := procurement.ParseCostPolicy(cfg.ProcurementGRNCostPolicy)
+	if err != nil {
+		logger.Error("parse GRN cost policy", slog.Any("error", err))
+		os.Exit(1)
+	}
+	procurementService.SetCostPolicy(grnCostPolicy)
+	procurementService.SetReplenishmentPlanner(inventoryService)
 
 	rbacService := rbac.NewService(dbpool)
+	rbacService.SetAuditor(auditLogger)
 	rbacMiddleware := rbac.Middleware{Service: rbacService, Logger: logger}
+	accountingHandler := accounting.NewHandler(logger, dbpool, templates, journalService, csrfManager, rbacMiddleware)
+	currencyHandler := currency.NewHandler(logger, currencyService, rbacMiddleware)
 
 	usersRepo := users.NewRepository(dbpool)
 	usersService := users.NewService(usersRepo)
+	commonPasswords, err := auth.LoadCommonPasswords(cfg.PasswordCommonListFile)
+	if err != nil {
+		logger.Error("load common passwords", slog.Any("error", err))
+		os.Exit(1)
+	}
+	passwordPolicy := auth.PasswordPolicy{
+		MinLength:      cfg.PasswordMinLength,
+		MinCharClasses: cfg.PasswordMinCharClasses,
+		DisallowCommon: cfg.PasswordDisallowCommon,
+		Common:         commonPasswords,
+		RotationDays:   cfg.PasswordRotationDays,
+		HistorySize:    cfg.PasswordHistory,
+	}
+	authService.SetPasswordPolicy(passwordPolicy)
+	usersService.SetPasswordPolicy(passwordPolicy)
+	usersService.SetAuditor(auditLogger)
+	usersService.SetCompanyAssigner(rbacService)
 	usersHandler := users.NewHandler(logger, usersService, templates, csrfManager, sessionManager, rbacMiddleware)
 
 	rolesRepo := roles.NewRepository(dbpool)
 	rolesService := roles.NewService(rolesRepo)
+	rolesService.SetAuditor(auditLogger)
 	rolesHandler := roles.NewHandler(logger, rolesService, templates, csrfManager, sessionManager, rbacMiddleware)
 
 	permissionsHandler := rbac.NewPermissionsHandler(logger, rbacService, templates, csrfManager, sessionManager, rbacMiddleware)
@@ -183,13 +235,32 @@ func main() {
 	arRepo := ar.NewRepository(dbpool)
 	arService := ar.NewService(arRepo)
 	arService.SetDeliveryService(deliveryorders.NewInvoicingAdapter(dbpool))
+	arService.SetAccountingService(integrationHooks)
+	dueDatePolicy := shared.NewBusinessDayPolicy(dbpool)
+	arService.SetDueDatePolicy(dueDatePolicy)
+	arService.SetPeriodGuard(periodRepo)
+	arService.SetCurrencyValidator(currencyService)
+	arService.SetRateResolver(currencyService)
 	arHandler := ar.NewHandler(logger, arService, templates, csrfManager, sessionManager, rbacMiddleware)
 
 	apRepo := ap.NewRepository(dbpool)
 	apService := ap.NewService(apRepo, procurementService)
+	apService.SetAuditor(auditLogger)
 	apService.SetIntegrationHandler(integrationHooks)
+	apService.SetDueDatePolicy(dueDatePolicy)
+	apService.SetPeriodGuard(periodRepo)
+	apService.SetCurrencyValidator(currencyService)
+	apService.SetDefaultTaxResolver(taxes.NewResolver(dbpool))
 	apHandler := ap.NewHandler(logger, apService, templates, csrfManager, sessionManager, rbacMiddleware)
 
+	nettingService := netting.NewService(netting.NewRepository(dbpool))
+	nettingService.SetPeriodGuard(periodRepo)
+	nettingService.SetIntegrationHandler(integrationHooks)
+	nettingHandler := netting.NewHandler(logger, nettingService, templates, csrfManager, rbacMiddleware)
+
+	archiveService := archive.NewService(archive.NewRepository(dbpool), archive.Config{RetentionDays: cfg.ArchiveRetentionDays})
+	archiveHandler := archive.NewHandler(logger, archiveService, templates, csrfManager, rbacMiddleware)
+
 	closeHandler := closehttp.NewHandler(logger, closeService, templates, csrfManager, rbacMiddleware)
 	eliminationRepo := eliminationpkg.NewRepository(dbpool)
 	eliminationService := eliminationpkg.NewService(eliminationRepo, journalService)
@@ -198,6 +269,7 @@ func main() {
 	analyticsRepo := sqlc.New(dbpool)
 	analyticsCache := analytics.NewCache(redisClient, 10*time.Minute)
 	analyticsService := analytics.NewService(analyticsRepo, analyticsCache)
+	journalService.SetCacheInvalidator(analyticsCache)
 	pdfExporter := &export.PDFExporter{Endpoint: cfg.GotenbergURL, Client: http.DefaultClient}
 	analyticsValidator := analyticsPeriodValidator{pool: dbpool, logger: logger}
 	analyticsHandler := analytichttp.NewHandler(
@@ -218,6 +290,7 @@ func main() {
 	auditService := audit.NewService(auditRepo)
 	auditExporter := audit.NewExporter(templates)
 	auditHandler := audithttp.NewHandler(logger, auditService, templates, auditExporter, rbacService)
+	auditHandler.SetSequenceService(audit.NewSequenceService(audit.NewSequenceRepository(dbpool)))
 	metrics := observability.NewMetrics()
 	jobmetrics.NewMetrics(metrics.Registerer())
 	if err := consolhttp.SetupCacheMetrics(metrics.Registerer()); err != nil {
@@ -231,10 +304,46 @@ func main() {
 	salesHandler := sales.NewHandler(logger, salesService, templates, csrfManager, sessionManager, rbacMiddleware)
 
 	masterdataHandler := masterdata.NewHandler(logger, dbpool, templates, csrfManager, sessionManager, rbacMiddleware)
+	taxIDValidator, err := shared.LoadTaxIDValidator(cfg.TaxIDFormatsFile)
+	if err != nil {
+		logger.Error("load tax id formats", slog.Any("error", err))
+		os.Exit(1)
+	}
+	salesService.Customers.SetTaxIDValidator(taxIDValidator)
+	masterdataHandler.SetTaxIDValidator(taxIDValidator)
+	salesService.Orders.SetMinMarginPercent(cfg.SalesMinMarginPercent)
+	salesService.Quotations.SetCurrencyValidator(currencyService)
+	lossReasons, err := quotations.LoadLossReasons(cfg.QuotationLossReasonsFile)
+	if err != nil {
+		logger.Error("load quotation loss reasons", slog.Any("error", err))
+		os.Exit(1)
+	}
+	salesService.Quotations.SetLossReasons(lossReasons)
+	salesService.Returns.SetInventory(inventoryService)
+	salesService.Returns.SetCreditNotes(arService)
+	salesService.Orders.SetCurrencyValidator(currencyService)
+	salesService.Orders.SetRateResolver(currencyService)
+	salesService.Orders.SetChangeOrderAutoApproveBelow(cfg.SalesOrderChangeAutoApproveBelow)
+	salesService.Orders.SetApprovals(approvalRecorder)
+	salesService.Orders.SetApprovalRouter(approvalMatrix)
+	procurementService.SetUnitConverter(salesService.Products)
 
 	reportClient := report.NewClient(cfg.GotenbergURL)
 	reportHandler := report.NewHandler(reportClient, logger)
 
+	apRemittance, err := ap.NewRemittanceExporter(reportClient)
+	if err != nil {
+		logger.Error("init AP remittance exporter", slog.Any("error", err))
+		os.Exit(1)
+	}
+	apHandler.SetRemittanceExporter(apRemittance)
+	salesDocuments, err := documents.NewExporter(reportClient)
+	if err != nil {
+		logger.Error("init sales document exporter", slog.Any("error", err))
+		os.Exit(1)
+	}
+	salesService.Documents.SetExporter(salesDocuments)
+
 	consolPDFClient, err := consolhttp.NewPDFRenderClient(cfg.GotenbergURL)
 	if err != nil {
 		logger.Error("init consol pdf client", slog.Any("error", err))
@@ -242,9 +351,17 @@ func main() {
 	}
 
 	consolRepo := consol.NewRepository(dbpool)
+	consolRepo.SetDailyRates(currencyService)
 	consolService := consol.NewService(consolRepo)
 	consolBSService := consol.NewBalanceSheetService(consolRepo)
 	consolPLService := consol.NewProfitLossService(consolRepo)
+	statementMapping, err := consol.LoadStatementMapping(cfg.ConsolStatementMappingFile)
+	if err != nil {
+		logger.Error("load consol statement mapping", slog.Any("error", err))
+		os.Exit(1)
+	}
+	consolBSService.SetStatementMapping(statementMapping)
+	consolPLService.SetStatementMapping(statementMapping)
 
 	consolBSHandler, err := consolhttp.NewBalanceSheetHandler(logger, consolBSService, templates, csrfManager, sessionManager, rbacMiddleware, consolPDFClient)
 	if err != nil {
@@ -273,8 +390,32 @@ func main() {
 		os.Exit(1)
 	}
 	defer jobClient.Close()
+	arService.SetInvoiceEmailQueue(jobClient)
+	usersService.SetCredentialMailer(jobClient, cfg.AppBaseURL)
 	varianceHandler := variancepkg.NewHandler(logger, varianceService, templates, csrfManager, rbacMiddleware, jobClient)
-	boardpackHandler := boardpackhttp.NewHandler(logger, boardpackService, templates, csrfManager, rbacMiddleware, jobClient)
+	openItemsHandler := openitems.NewHandler(logger, openitems.NewService(openitems.NewRepository(dbpool), arService, apService), rbacMiddleware)
+	approvalPolicies, err := approvals.NewPolicies(cfg.ApprovalSLA)
+	if err != nil {
+		logger.Error("load approval SLA", slog.Any("error", err))
+		os.Exit(1)
+	}
+	approvalsHandler := approvals.NewHandler(logger, approvals.NewService(approvals.NewRepository(dbpool), nil, approvals.Config{Policies: approvalPolicies}, logger), approvalMatrix, rbacMiddleware)
+	reportGenerators := append(reportjob.DefaultGenerators(journalService, inventoryService), reportjob.AgingGenerators(apService, arService, reportClient)...)
+	reportGenerators = append(reportGenerators, reportjob.SalesDocumentGenerators(salesService.Documents)...)
+	reportJobService := reportjob.NewService(reportjob.NewRepository(dbpool), reportGenerators...)
+	reportJobService.SetTTL(cfg.ReportTTL)
+	reportJobHandler := reportjob.NewHandler(logger, reportJobService, jobClient, rbacMiddleware)
+	salesHandler.SetDocumentQueue(reportJobHandler)
+	boardpackScheduler := boardpacksvc.NewScheduler(boardpackRepo, boardpackService, jobClient, logger)
+	closeService.SetCloseListener(boardpackScheduler)
+	boardpackHandler := boardpackhttp.NewHandler(logger, boardpackService, boardpackScheduler, templates, csrfManager, rbacMiddleware, jobClient)
+	notifyService := notify.NewService(notify.NewRepository(dbpool), jobClient, cfg.AppBaseURL, logger)
+	procurementService.SetApprovalNotifier(notifyService)
+	salesService.Quotations.SetApprovalNotifier(notifyService)
+	salesService.Comments.SetNotifier(notifyService)
+	notifyHandler := notify.NewHandler(logger, notifyService, templates, csrfManager)
+	dashboardService := dashboard.NewService(dashboard.NewRepository(dbpool), dashboard.DefaultWidgets(analyticsService, salesService.Orders, inventoryService)...)
+	dashboardHandler := dashboard.NewHandler(logger, dashboardService, templates, csrfManager, rbacMiddleware)
 
 	inspector := asynq.NewInspector(asynq.RedisClientOpt{Addr: cfg.RedisAddr})
 	defer func() {
@@ -294,11 +435,17 @@ func main() {
 		AccountingHandler:  accountingHandler,
 		ARHandler:          arHandler,
 		APHandler:          apHandler,
+		NettingHandler:     nettingHandler,
+		ArchiveHandler:     archiveHandler,
+		CurrencyHandler:    currencyHandler,
 		RolesHandler:       rolesHandler,
 		UsersHandler:       usersHandler,
 		CloseHandler:       closeHandler,
 		EliminationHandler: eliminationHandler,
 		VarianceHandler:    varianceHandler,
+		OpenItemsHandler:   openItemsHandler,
+		ApprovalsHandler:   approvalsHandler,
+		ReportJobHandler:   reportJobHandler,
 		BoardPackHandler:   boardpackHandler,
 		InventoryHandler:   inventoryHandler,
 		ProcurementHandler: procurementHandler,
@@ -313,6 +460,8 @@ func main() {
 		InsightsHandler:    insightsHandler,
 		AuditHandler:       auditHandler,
 		PermissionsHandler: permissionsHandler,
+		NotifyHandler:      notifyHandler,
+		DashboardHandler:   dashboardHandler,
 		Metrics:            metrics,
 	})
 
@@ -15,8 +15,23 @@ import (
 
 	"github.com/odyssey-erp/odyssey-erp/internal/analytics"
 	"github.com/odyssey-erp/odyssey-erp/internal/app"
+	"github.com/odyssey-erp/odyssey-erp/internal/ap"
+	"github.com/odyssey-erp/odyssey-erp/internal/approvals"
+	"github.com/odyssey-erp/odyssey-erp/internal/archive"
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
 	"github.com/odyssey-erp/odyssey-erp/internal/boardpack"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/journals"
 	"github.com/odyssey-erp/odyssey-erp/internal/consol"
+	"github.com/odyssey-erp/odyssey-erp/internal/currency"
+	"github.com/odyssey-erp/odyssey-erp/internal/dataexport"
+	"github.com/odyssey-erp/odyssey-erp/internal/elimination"
+	"github.com/odyssey-erp/odyssey-erp/internal/insights"
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/objectstore"
+	"github.com/odyssey-erp/odyssey-erp/internal/reportjob"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/documents"
 	"github.com/odyssey-erp/odyssey-erp/internal/variance"
 	"github.com/odyssey-erp/odyssey-erp/jobs"
 	"github.com/odyssey-erp/odyssey-erp/report"
@@ -66,9 +81,14 @@ func main() {
 
 	warmupJob := jobs.NewInsightsWarmupJob(analyticsService, pool, logger, nil)
 	anomalyJob := jobs.NewAnomalyScanJob(pool, logger, nil)
+	currencyService := currency.NewService(currency.NewRepository(pool), cfg.FXBaseCurrency)
 	consolRepo := consol.NewRepository(pool)
+	consolRepo.SetDailyRates(currencyService)
 	consolService := consol.NewService(consolRepo)
 	consolidator := jobs.NewConsolidateRefreshJob(consolService, consolRepo, logger, nil)
+	// Template expansion never posts journals, so no ledger is needed.
+	eliminationTemplatesJob := jobs.NewEliminationTemplatesJob(elimination.NewService(elimination.NewRepository(pool), nil), logger, nil)
+	creditHoldJob := jobs.NewCreditHoldScanJob(customers.NewService(customers.NewRepository(pool)), logger, nil)
 	varianceRepo := variance.NewRepository(pool)
 	varianceService := variance.NewService(varianceRepo)
 	varianceJob := variance.NewSnapshotJob(varianceService, logger)
@@ -76,17 +96,61 @@ func main() {
 	boardpackRepo := boardpack.NewRepository(pool)
 	boardpackService := boardpack.NewService(boardpackRepo)
 	boardpackBuilder := boardpack.NewBuilder(boardpackRepo, varianceService, analyticsService)
+	boardpackBuilder.WithInsights(insights.NewService(analyticsRepo))
 	pdfClient := report.NewClient(cfg.GotenbergURL)
 	boardpackRenderer, err := boardpack.NewRenderer(pdfClient)
 	if err != nil {
 		logger.Error("init board pack renderer", slog.Any("error", err))
 		os.Exit(1)
 	}
+	jobClient, err := jobs.NewClient(asynq.RedisClientOpt{Addr: cfg.RedisAddr})
+	if err != nil {
+		logger.Error("init job client", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer jobClient.Close()
 	boardpackJob := boardpack.NewJob(boardpack.JobConfig{
 		Service:    boardpackService,
 		Builder:    boardpackBuilder,
 		Renderer:   boardpackRenderer,
 		StorageDir: cfg.BoardPackStorageDir,
+		Mailer:     jobClient,
+		BaseURL:    cfg.AppBaseURL,
+		Logger:     logger,
+	})
+	boardpackScheduler := boardpack.NewScheduler(boardpackRepo, boardpackService, jobClient, logger)
+
+	mailer := &jobs.SMTPMailer{Host: cfg.SMTPHost, Port: cfg.SMTPPort, From: cfg.SMTPFrom, Logger: logger}
+	arDocuments, err := ar.NewDocumentExporter(pdfClient)
+	if err != nil {
+		logger.Error("init AR document exporter", slog.Any("error", err))
+		os.Exit(1)
+	}
+	// Invoice emails only read invoices, so the service needs no ledger hooks.
+	arService := ar.NewService(ar.NewRepository(pool))
+	arService.SetInvoiceDelivery(arDocuments, mailer)
+	arService.SetRateResolver(currencyService)
+
+	salesDocuments, err := documents.NewExporter(pdfClient)
+	if err != nil {
+		logger.Error("init sales document exporter", slog.Any("error", err))
+		os.Exit(1)
+	}
+	salesService := sales.NewService(pool)
+	salesService.Documents.SetExporter(salesDocuments)
+
+	// Report generators only read, so the services need no audit or posting hooks.
+	reportGenerators := append(reportjob.DefaultGenerators(
+		journals.NewService(journals.NewRepository(pool), nil, nil),
+		inventory.NewService(inventory.NewRepository(pool), nil, nil, inventory.ServiceConfig{}, nil),
+	), reportjob.AgingGenerators(ap.NewService(ap.NewRepository(pool), nil), arService, pdfClient)...)
+	reportGenerators = append(reportGenerators, reportjob.SalesDocumentGenerators(salesService.Documents)...)
+	reportJobService := reportjob.NewService(reportjob.NewRepository(pool), reportGenerators...)
+	reportJobService.SetTTL(cfg.ReportTTL)
+	reportJob := reportjob.NewJob(reportjob.JobConfig{
+		Service:    reportJobService,
+		StorageDir: cfg.ReportStorageDir,
+		Mailer:     mailer,
 		Logger:     logger,
 	})
 
@@ -100,27 +164,102 @@ func main() {
 		logger.Error("build anomaly task", slog.Any("error", err))
 		os.Exit(1)
 	}
-	consolidateTask, err := jobs.NewConsolidateRefreshTask("all", "active")
+	consolidateTask, err := jobs.NewConsolidateRefreshTask(jobs.ConsolScopeChanged, "active")
 	if err != nil {
 		logger.Error("build consolidate task", slog.Any("error", err))
 		os.Exit(1)
 	}
 
+	cron := []jobs.CronRegistration{
+		{Spec: "15 1 * * *", Task: warmupTask, Options: []asynq.Option{asynq.MaxRetry(3)}},
+		{Spec: "30 1 * * *", Task: anomalyTask, Options: []asynq.Option{asynq.MaxRetry(3)}},
+		{Spec: "45 1 * * *", Task: jobs.NewEliminationTemplatesApplyTask(), Options: []asynq.Option{asynq.MaxRetry(3)}},
+		{Spec: "0 2 * * *", Task: consolidateTask, Options: []asynq.Option{asynq.MaxRetry(3)}},
+		{Spec: "30 2 * * *", Task: jobs.NewCreditHoldScanTask(), Options: []asynq.Option{asynq.MaxRetry(3)}},
+		{Spec: "0 6 * * *", Task: jobs.NewBoardPackScheduleTask(), Options: []asynq.Option{asynq.MaxRetry(3)}},
+		{Spec: "0 * * * *", Task: jobs.NewReportCleanupTask(), Options: []asynq.Option{asynq.MaxRetry(3)}},
+		{Spec: "0 7 * * *", Task: jobs.NewReportScheduleTask(), Options: []asynq.Option{asynq.MaxRetry(3)}},
+	}
+	// The daily rate fetch only runs when a provider is configured.
+	if cfg.FXProviderURL != "" {
+		currencyService.SetProvider(currency.NewHTTPProvider(cfg.FXProviderURL, cfg.FXProviderTimeout))
+		cron = append(cron, jobs.CronRegistration{Spec: cfg.FXFetchSchedule, Task: jobs.NewFXDailyFetchTask(), Options: []asynq.Option{asynq.MaxRetry(3)}})
+	}
+	handlers := []jobs.TaskHandler{
+		{Type: jobs.TaskAnalyticsInsightsWarmup, Handler: warmupJob.Handle},
+		{Type: jobs.TaskAnalyticsAnomalyScan, Handler: anomalyJob.Handle},
+		{Type: jobs.TaskConsolidateRefresh, Handler: consolidator.Handle},
+		{Type: jobs.TaskEliminationTemplatesApply, Handler: eliminationTemplatesJob.Handle},
+		{Type: jobs.TaskVarianceSnapshotProcess, Handler: varianceJob.Handle},
+		{Type: jobs.TaskBoardPackGenerate, Handler: boardpackJob.Handle},
+		{Type: jobs.TaskBoardPackSchedule, Handler: boardpackScheduler.Handle},
+		{Type: jobs.TaskCreditHoldScan, Handler: creditHoldJob.Handle},
+		{Type: jobs.TaskReportGenerate, Handler: reportJob.Handle},
+		{Type: jobs.TaskReportCleanup, Handler: reportJob.HandleCleanup},
+		{Type: jobs.TaskReportSchedule, Handler: reportJob.HandleSchedules},
+		{Type: jobs.TaskFXDailyFetch, Handler: jobs.NewFXDailyFetchJob(currencyService, logger, nil).Handle},
+		{Type: jobs.TaskARInvoiceEmail, Handler: ar.NewInvoiceEmailJob(arService, logger).Handle},
+	}
+	// The data lake export only runs when a schedule is configured.
+	if cfg.DataExportSchedule != "" {
+		store, err := objectstore.New(objectstore.Config{
+			Endpoint:  cfg.DataExportEndpoint,
+			Region:    cfg.DataExportRegion,
+			Bucket:    cfg.DataExportBucket,
+			AccessKey: cfg.DataExportAccessKey,
+			SecretKey: cfg.DataExportSecretKey,
+			Timeout:   cfg.DataExportTimeout,
+		})
+		if err != nil {
+			logger.Error("init data export store", slog.Any("error", err))
+			os.Exit(1)
+		}
+		exportService, err := dataexport.NewService(dataexport.NewRepository(pool), store, dataexport.Config{
+			Prefix:   cfg.DataExportPrefix,
+			Tables:   cfg.DataExportTables,
+			Format:   cfg.DataExportFormat,
+			Attempts: cfg.DataExportAttempts,
+		})
+		if err != nil {
+			logger.Error("init data export", slog.Any("error", err))
+			os.Exit(1)
+		}
+		handlers = append(handlers, jobs.TaskHandler{Type: jobs.TaskDataExport, Handler: dataexport.NewJob(exportService, logger).Handle})
+		cron = append(cron, jobs.CronRegistration{Spec: cfg.DataExportSchedule, Task: jobs.NewDataExportTask(), Options: []asynq.Option{asynq.MaxRetry(3)}})
+	}
+
+	// Approval escalation only runs when a schedule is configured.
+	if cfg.ApprovalEscalationSchedule != "" {
+		approvalPolicies, err := approvals.NewPolicies(cfg.ApprovalSLA)
+		if err != nil {
+			logger.Error("load approval SLA", slog.Any("error", err))
+			os.Exit(1)
+		}
+		approvalService := approvals.NewService(approvals.NewRepository(pool), jobClient, approvals.Config{
+			Policies:           approvalPolicies,
+			FallbackApproverID: cfg.ApprovalFallbackApproverID,
+			BaseURL:            cfg.AppBaseURL,
+		}, logger)
+		handlers = append(handlers, jobs.TaskHandler{Type: jobs.TaskApprovalEscalation, Handler: approvals.NewJob(approvalService, logger).Handle})
+		cron = append(cron, jobs.CronRegistration{Spec: cfg.ApprovalEscalationSchedule, Task: jobs.NewApprovalEscalationTask(), Options: []asynq.Option{asynq.MaxRetry(3)}})
+	}
+
+	// Document archival only runs when a schedule is configured.
+	if cfg.ArchiveSchedule != "" {
+		archiveService := archive.NewService(archive.NewRepository(pool), archive.Config{
+			RetentionDays: cfg.ArchiveRetentionDays,
+			BatchSize:     cfg.ArchiveBatchSize,
+		})
+		handlers = append(handlers, jobs.TaskHandler{Type: jobs.TaskDocumentArchive, Handler: archive.NewJob(archiveService, logger).Handle})
+		cron = append(cron, jobs.CronRegistration{Spec: cfg.ArchiveSchedule, Task: jobs.NewDocumentArchiveTask(), Options: []asynq.Option{asynq.MaxRetry(3)}})
+	}
+
 	worker, err := jobs.NewWorker(jobs.WorkerConfig{
 		RedisOpts: asynq.RedisClientOpt{Addr: cfg.RedisAddr},
 		Logger:    logger,
-		Handlers: []jobs.TaskHandler{
-			{Type: jobs.TaskAnalyticsInsightsWarmup, Handler: warmupJob.Handle},
-			{Type: jobs.TaskAnalyticsAnomalyScan, Handler: anomalyJob.Handle},
-			{Type: jobs.TaskConsolidateRefresh, Handler: consolidator.Handle},
-			{Type: jobs.TaskVarianceSnapshotProcess, Handler: varianceJob.Handle},
-			{Type: jobs.TaskBoardPackGenerate, Handler: boardpackJob.Handle},
-		},
-		Cron: []jobs.CronRegistration{
-			{Spec: "15 1 * * *", Task: warmupTask, Options: []asynq.Option{asynq.MaxRetry(3)}},
-			{Spec: "30 1 * * *", Task: anomalyTask, Options: []asynq.Option{asynq.MaxRetry(3)}},
-			{Spec: "0 2 * * *", Task: consolidateTask, Options: []asynq.Option{asynq.MaxRetry(3)}},
-		},
+		Mailer:    mailer,
+		Handlers:  handlers,
+		Cron:      cron,
 	})
 	if err != nil {
 		logger.Error("init worker", slog.Any("error", err))
@@ -1,9 +1,16 @@
 package accounts
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 )
 
@@ -11,23 +18,80 @@ type Handler struct {
 	service   *Service
 	logger    *slog.Logger
 	templates *view.Engine
+	csrf      *internalShared.CSRFManager
+	rbac      rbac.Middleware
 }
 
-func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine) *Handler {
-	return &Handler{logger: logger, service: service, templates: templates}
+func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, csrf *internalShared.CSRFManager, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, rbac: rbac}
 }
 
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
-	accounts, err := h.service.List(r.Context())
+	companyID, _ := strconv.ParseInt(r.URL.Query().Get("company_id"), 10, 64)
+	if companyID > 0 && !internalShared.CompanyAllowed(r.Context(), companyID) {
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	accounts, err := h.service.List(r.Context(), companyID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list accounts", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	companies, err := h.service.Companies(r.Context())
 	if err != nil {
-		h.logger.Error("list accounts", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list chart companies", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
-	data := map[string]any{"Accounts": accounts}
-	viewData := view.TemplateData{Title: "Chart of Accounts", Data: data}
+	sess := internalShared.SessionFromContext(r.Context())
+	var csrfToken string
+	if h.csrf != nil {
+		csrfToken, _ = h.csrf.EnsureToken(r.Context(), sess)
+	}
+	var flash *internalShared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	data := map[string]any{"Accounts": accounts, "Companies": companies, "CompanyID": companyID}
+	viewData := view.TemplateData{Title: "Chart of Accounts", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
 	if err := h.templates.Render(w, "pages/accounting/coa_list.html", viewData); err != nil {
-		h.logger.Error("render coa", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render coa", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// CopyChart clones the submitted template chart into the target company.
+func (h *Handler) CopyChart(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	input := CopyChartInput{}
+	input.FromCompanyID, _ = strconv.ParseInt(r.PostFormValue("from_company_id"), 10, 64)
+	input.ToCompanyID, _ = strconv.ParseInt(r.PostFormValue("company_id"), 10, 64)
+	if !internalShared.CompanyAllowed(r.Context(), input.ToCompanyID) ||
+		(input.FromCompanyID > 0 && !internalShared.CompanyAllowed(r.Context(), input.FromCompanyID)) {
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	location := "/accounting/coa?company_id=" + strconv.FormatInt(input.ToCompanyID, 10)
+	copied, err := h.service.CopyChart(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCopy) || errors.Is(err, shared.ErrChartNotEmpty) {
+			h.redirectWithFlash(w, r, "/accounting/coa", "danger", err.Error())
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "copy chart of accounts", slog.Any("error", err))
+		h.redirectWithFlash(w, r, "/accounting/coa", "danger", internalShared.UserSafeMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, location, "success", fmt.Sprintf("Copied %d accounts into the company chart", copied))
+}
+
+func (h *Handler) redirectWithFlash(w http.ResponseWriter, r *http.Request, location, kind, message string) {
+	if sess := internalShared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(internalShared.FlashMessage{Kind: kind, Message: message})
 	}
+	http.Redirect(w, r, location, http.StatusSeeOther)
 }
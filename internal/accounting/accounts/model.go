@@ -23,4 +23,28 @@ type Account struct {
 	IsActive  bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// CompanyID is the company whose chart holds the account; nil is the
+	// shared chart.
+	CompanyID *int64
+}
+
+// Shared reports whether the account belongs to the shared chart.
+func (a Account) Shared() bool {
+	return a.CompanyID == nil
+}
+
+// Company is a company a chart of accounts can belong to.
+type Company struct {
+	ID   int64
+	Code string
+	Name string
+	// Accounts counts the company's own accounts.
+	Accounts int
+}
+
+// CopyChartInput copies the chart of FromCompanyID, or the shared chart when
+// it is 0, into ToCompanyID.
+type CopyChartInput struct {
+	FromCompanyID int64
+	ToCompanyID   int64
 }
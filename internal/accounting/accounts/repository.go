@@ -3,11 +3,20 @@ package accounts
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Repository interface {
-	List(ctx context.Context) ([]Account, error)
+	List(ctx context.Context, companyID int64) ([]Account, error)
+	Companies(ctx context.Context) ([]Company, error)
+	WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error
+}
+
+// TxRepository is used while copying a chart.
+type TxRepository interface {
+	CountCompanyAccounts(ctx context.Context, companyID int64) (int, error)
+	CopyChart(ctx context.Context, fromCompanyID, toCompanyID int64) (int, error)
 }
 
 type repository struct {
@@ -18,8 +27,13 @@ func NewRepository(db *pgxpool.Pool) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) List(ctx context.Context) ([]Account, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, code, name, type, parent_id, is_active, created_at, updated_at FROM accounts ORDER BY code`)
+func (r *repository) List(ctx context.Context, companyID int64) ([]Account, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, code, name, type, parent_id, is_active, created_at, updated_at, company_id
+FROM accounts a
+WHERE a.company_id = $1
+   OR (a.company_id IS NULL AND NOT EXISTS (
+        SELECT 1 FROM accounts own WHERE own.company_id = $1 AND own.code = a.code))
+ORDER BY code`, companyID)
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +41,7 @@ func (r *repository) List(ctx context.Context) ([]Account, error) {
 	var accounts []Account
 	for rows.Next() {
 		var a Account
-		err := rows.Scan(&a.ID, &a.Code, &a.Name, &a.Type, &a.ParentID, &a.IsActive, &a.CreatedAt, &a.UpdatedAt)
+		err := rows.Scan(&a.ID, &a.Code, &a.Name, &a.Type, &a.ParentID, &a.IsActive, &a.CreatedAt, &a.UpdatedAt, &a.CompanyID)
 		if err != nil {
 			return nil, err
 		}
@@ -35,3 +49,90 @@ func (r *repository) List(ctx context.Context) ([]Account, error) {
 	}
 	return accounts, rows.Err()
 }
+
+func (r *repository) Companies(ctx context.Context) ([]Company, error) {
+	rows, err := r.db.Query(ctx, `SELECT c.id, c.code, c.name, COUNT(a.id)
+FROM companies c
+LEFT JOIN accounts a ON a.company_id = c.id
+GROUP BY c.id, c.code, c.name
+ORDER BY c.code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var companies []Company
+	for rows.Next() {
+		var c Company
+		if err := rows.Scan(&c.ID, &c.Code, &c.Name, &c.Accounts); err != nil {
+			return nil, err
+		}
+		companies = append(companies, c)
+	}
+	return companies, rows.Err()
+}
+
+func (r *repository) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(ctx, &txRepository{tx: tx}); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+type txRepository struct {
+	tx pgx.Tx
+}
+
+// CountCompanyAccounts counts the company's own accounts, locking the company
+// row so concurrent copies into it serialise.
+func (r *txRepository) CountCompanyAccounts(ctx context.Context, companyID int64) (int, error) {
+	if _, err := r.tx.Exec(ctx, `SELECT 1 FROM companies WHERE id = $1 FOR UPDATE`, companyID); err != nil {
+		return 0, err
+	}
+	var count int
+	err := r.tx.QueryRow(ctx, `SELECT COUNT(*) FROM accounts WHERE company_id = $1`, companyID).Scan(&count)
+	return count, err
+}
+
+// CopyChart inserts the template chart's accounts into the company's chart,
+// rebuilds their parent links by code and carries the consolidation account
+// mappings over to the new accounts.
+func (r *txRepository) CopyChart(ctx context.Context, fromCompanyID, toCompanyID int64) (int, error) {
+	tag, err := r.tx.Exec(ctx, `INSERT INTO accounts (code, name, type, is_active, ic_flag, company_id)
+SELECT code, name, type, is_active, ic_flag, $2
+FROM accounts
+WHERE company_id IS NOT DISTINCT FROM NULLIF($1::BIGINT, 0)`, fromCompanyID, toCompanyID)
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.tx.Exec(ctx, `UPDATE accounts child
+SET parent_id = parent.id
+FROM accounts src
+JOIN accounts src_parent ON src_parent.id = src.parent_id
+JOIN accounts parent ON parent.company_id = $2 AND parent.code = src_parent.code
+WHERE child.company_id = $2
+  AND src.company_id IS NOT DISTINCT FROM NULLIF($1::BIGINT, 0)
+  AND src.code = child.code`, fromCompanyID, toCompanyID)
+	if err != nil {
+		return 0, err
+	}
+	// The company's own mappings point at the accounts it posted to before,
+	// and a source company's mappings apply in the groups both belong to.
+	_, err = r.tx.Exec(ctx, `INSERT INTO account_map (group_id, company_id, local_account_id, group_account_id)
+SELECT am.group_id, $2, own.id, am.group_account_id
+FROM account_map am
+JOIN accounts src ON src.id = am.local_account_id
+JOIN accounts own ON own.company_id = $2 AND own.code = src.code
+WHERE am.company_id = $2
+   OR (am.company_id = NULLIF($1::BIGINT, 0) AND EXISTS (
+        SELECT 1 FROM consol_members cm WHERE cm.group_id = am.group_id AND cm.company_id = $2))
+ON CONFLICT (group_id, company_id, local_account_id) DO NOTHING`, fromCompanyID, toCompanyID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -1,7 +1,12 @@
 package accounts
 
-import "github.com/go-chi/chi/v5"
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
 
 func (h *Handler) MountRoutes(r chi.Router) {
 	r.Get("/", h.List)
+	r.With(h.rbac.RequireAll(shared.PermFinanceGLEdit)).Post("/copy", h.CopyChart)
 }
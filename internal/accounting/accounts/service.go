@@ -1,6 +1,14 @@
 package accounts
 
-import "context"
+import (
+	"context"
+	"errors"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+// ErrInvalidCopy indicates a chart copied onto itself or into no company.
+var ErrInvalidCopy = errors.New("accounting: choose a target company different from the template chart")
 
 type Service struct {
 	repo Repository
@@ -10,6 +18,35 @@ func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) List(ctx context.Context) ([]Account, error) {
-	return s.repo.List(ctx)
+// List returns the chart a company posts to: its own accounts plus the shared
+// accounts whose code it does not override. Company 0 lists the shared chart.
+func (s *Service) List(ctx context.Context, companyID int64) ([]Account, error) {
+	return s.repo.List(ctx, companyID)
+}
+
+// Companies lists the companies with the size of their own charts.
+func (s *Service) Companies(ctx context.Context) ([]Company, error) {
+	return s.repo.Companies(ctx)
+}
+
+// CopyChart clones a template chart, keeping codes, names, types and the
+// parent hierarchy, into a company that has no chart of its own yet. It
+// returns the number of accounts created.
+func (s *Service) CopyChart(ctx context.Context, input CopyChartInput) (int, error) {
+	if input.ToCompanyID <= 0 || input.FromCompanyID == input.ToCompanyID {
+		return 0, ErrInvalidCopy
+	}
+	var copied int
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		existing, err := tx.CountCompanyAccounts(ctx, input.ToCompanyID)
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return shared.ErrChartNotEmpty
+		}
+		copied, err = tx.CopyChart(ctx, input.FromCompanyID, input.ToCompanyID)
+		return err
+	})
+	return copied, err
 }
@@ -9,6 +9,10 @@ import (
 
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/accounts"
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/journals"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 
 )
@@ -19,32 +23,34 @@ type Handler struct {
 	templates      *view.Engine
 	accountHandler *accounts.Handler
 	journalHandler *journals.Handler
+	queries        *sqlc.Queries
+	rbac           rbac.Middleware
 	// Future: ReportHandler
 }
 
 // NewHandler builds a Handler instance.
-// Note: Dependencies like audit and guard should be injected here or created if simple.
-// For now, assuming they are passed or we create separate constructors.
-func NewHandler(logger *slog.Logger, db *pgxpool.Pool, templates *view.Engine, audit journals.AuditPort, guard journals.PeriodGuard) *Handler {
+// The journal service is shared with integrations so postings made through the
+// UI go through the same audit, period guard and cache invalidation wiring.
+func NewHandler(logger *slog.Logger, db *pgxpool.Pool, templates *view.Engine, journalService *journals.Service, csrf *shared.CSRFManager, rbac rbac.Middleware) *Handler {
 	// Repositories
 	accountRepo := accounts.NewRepository(db)
-	journalRepo := journals.NewRepository(db)
 	// periodRepo := periods.NewRepository(db)
 	// mappingRepo := mappings.NewRepository(db)
 
 	// Services
 	accountService := accounts.NewService(accountRepo)
-	journalService := journals.NewService(journalRepo, audit, guard)
 
 	// Handlers
-	accountHandler := accounts.NewHandler(logger, accountService, templates)
-	journalHandler := journals.NewHandler(logger, journalService, templates)
+	accountHandler := accounts.NewHandler(logger, accountService, templates, csrf, rbac)
+	journalHandler := journals.NewHandler(logger, journalService, templates, csrf, rbac)
 
 	return &Handler{
 		logger:         logger,
 		templates:      templates,
 		accountHandler: accountHandler,
 		journalHandler: journalHandler,
+		queries:        sqlc.New(db),
+		rbac:           rbac,
 	}
 }
 
@@ -60,7 +66,9 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	// Legacy/Direct routes for now until Report module is fully separated
 	r.Get("/gl", h.handleGeneralLedger)
 	r.Get("/trial-balance", h.handleTrialBalance)
+	r.With(h.rbac.RequireAny(shared.PermFinanceGLView)).Get("/trial-balance/compare", h.handleTrialBalanceComparison)
 	r.Get("/pnl", h.handleProfitLoss)
+	r.With(h.rbac.RequireAny(shared.PermFinanceGLView)).Get("/pnl/dimensions", h.handleProfitLossByDimension)
 	r.Get("/balance-sheet", h.handleBalanceSheet)
 
 	r.Get("/finance/reports/trial-balance/pdf", h.handleNotImplemented)
@@ -85,7 +93,7 @@ func (h *Handler) handleProfitLoss(w http.ResponseWriter, r *http.Request) {
 	h.accountHandler.List(w, r)
 }
 
-func (h *Handler) handleNotImplemented(w http.ResponseWriter, _ *http.Request) {
+func (h *Handler) handleNotImplemented(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("ledger handler invoked", slog.String("path", "finance"))
-	http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+	httpx.Error(w, r, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
 }
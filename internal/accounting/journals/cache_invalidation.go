@@ -0,0 +1,39 @@
+package journals
+
+import (
+	"context"
+	"time"
+)
+
+// CacheInvalidator drops cached reports derived from the ledger; satisfied by analytics.Cache.
+type CacheInvalidator interface {
+	InvalidatePeriod(ctx context.Context, companyID int64, period string) error
+}
+
+// SetCacheInvalidator enables cache invalidation after postings and voids.
+func (s *Service) SetCacheInvalidator(cache CacheInvalidator) {
+	s.cache = cache
+}
+
+// invalidateCaches clears cached analytics for every company touched by the
+// lines in the month of the journal date, matching the YYYY-MM periods and
+// company_id (0 when no dimension) used by the analytics views. Failures are
+// ignored; the entries then expire with the cache TTL.
+func (s *Service) invalidateCaches(ctx context.Context, date time.Time, lines []JournalLine) {
+	if s.cache == nil || date.IsZero() {
+		return
+	}
+	period := date.Format("2006-01")
+	seen := make(map[int64]bool, 1)
+	for _, line := range lines {
+		var companyID int64
+		if line.DimCompanyID != nil {
+			companyID = *line.DimCompanyID
+		}
+		if seen[companyID] {
+			continue
+		}
+		seen[companyID] = true
+		_ = s.cache.InvalidatePeriod(ctx, companyID, period)
+	}
+}
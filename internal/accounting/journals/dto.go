@@ -79,3 +79,36 @@ type ReverseInput struct {
 	Override   bool
 	TargetDate *time.Time
 }
+
+// DimensionFilter narrows journal lines by dimension. Nil fields match any value.
+type DimensionFilter struct {
+	CompanyID   *int64
+	BranchID    *int64
+	WarehouseID *int64
+}
+
+// ReclassInput wraps parameters for moving a balance between accounts.
+type ReclassInput struct {
+	SourceAccountID int64
+	TargetAccountID int64
+	PeriodID        int64
+	Date            *time.Time
+	Dimensions      DimensionFilter
+	Memo            string
+	ActorID         int64
+	Override        bool
+}
+
+// Validate ensures reclass input meets minimum criteria.
+func (in ReclassInput) Validate() error {
+	if in.PeriodID == 0 {
+		return errors.New("accounting: period required")
+	}
+	if in.SourceAccountID == 0 || in.TargetAccountID == 0 {
+		return errors.New("accounting: source and target account required")
+	}
+	if in.SourceAccountID == in.TargetAccountID {
+		return shared.ErrReclassSameAccount
+	}
+	return nil
+}
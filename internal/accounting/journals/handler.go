@@ -1,9 +1,14 @@
 package journals
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 )
 
@@ -11,35 +16,64 @@ type Handler struct {
 	service   *Service
 	logger    *slog.Logger
 	templates *view.Engine
+	csrf      *shared.CSRFManager
+	rbac      rbac.Middleware
 }
 
-func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine) *Handler {
-	return &Handler{logger: logger, service: service, templates: templates}
+func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, csrf *shared.CSRFManager, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, rbac: rbac}
 }
 
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	entries, err := h.service.List(r.Context())
 	if err != nil {
-		h.logger.Error("list journals", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list journals", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	data := map[string]any{"JournalEntries": entries}
 	viewData := view.TemplateData{Title: "Journal Entries", Data: data}
 	if err := h.templates.Render(w, "pages/accounting/journals_list.html", viewData); err != nil {
-		h.logger.Error("render journals", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render journals", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 	}
 }
 
+// ListLines returns journal lines as JSON. Pass cursor (the previous
+// response's next_cursor) for keyset pagination, or offset for page jumps.
+func (h *Handler) ListLines(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	page, err := h.service.ListLines(r.Context(), ListLinesRequest{
+		AccountID: parseOptionalID(q.Get("account_id")),
+		PeriodID:  parseOptionalID(q.Get("period_id")),
+		CompanyID: parseOptionalID(q.Get("company_id")),
+		BranchID:  parseOptionalID(q.Get("branch_id")),
+		Limit:     limit,
+		Offset:    offset,
+		Cursor:    q.Get("cursor"),
+	})
+	if errors.Is(err, shared.ErrInvalidCursor) {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid cursor", err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list journal lines", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "")
+		return
+	}
+	httpx.JSON(w, http.StatusOK, page)
+}
+
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Not implemented yet", http.StatusNotImplemented)
+	httpx.Error(w, r, "Not implemented yet", http.StatusNotImplemented)
 }
 
 func (h *Handler) Void(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Not implemented yet", http.StatusNotImplemented)
+	httpx.Error(w, r, "Not implemented yet", http.StatusNotImplemented)
 }
 
 func (h *Handler) Reverse(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Not implemented yet", http.StatusNotImplemented)
+	httpx.Error(w, r, "Not implemented yet", http.StatusNotImplemented)
 }
@@ -0,0 +1,42 @@
+package journals
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// DimensionIssues lists posted journal lines with inconsistent dimensions
+// for cleanup. It renders the report page, or JSON when the client accepts
+// application/json.
+func (h *Handler) DimensionIssues(w http.ResponseWriter, r *http.Request) {
+	wantsJSON := strings.Contains(r.Header.Get("Accept"), "application/json")
+	report, err := h.service.DimensionIssues(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "journal dimension issues", slog.Any("error", err))
+		if wantsJSON {
+			httpx.Problem(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "")
+			return
+		}
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if wantsJSON {
+		httpx.JSON(w, http.StatusOK, report)
+		return
+	}
+	viewData := view.TemplateData{
+		Title:       "Dimension Issues",
+		CurrentPath: r.URL.Path,
+		Data: map[string]any{
+			"Report": report,
+			"Limit":  MaxDimensionIssues,
+		},
+	}
+	if err := h.templates.Render(w, "pages/accounting/journal_dimension_issues.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render journal dimension issues", slog.Any("error", err))
+	}
+}
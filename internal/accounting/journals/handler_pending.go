@@ -0,0 +1,171 @@
+package journals
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// Pending renders the company's queue of integration journals awaiting
+// approval, with the per-module auto-post settings.
+func (h *Handler) Pending(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	companyID, err := internalShared.RequireCompanyID(r.Context(), parseID(q.Get("company_id")), 0)
+	if err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	status := PendingStatus(q.Get("status"))
+	switch status {
+	case "", PendingStatusPending, PendingStatusApproved, PendingStatusRejected:
+	default:
+		httpx.Error(w, r, "Invalid status", http.StatusBadRequest)
+		return
+	}
+	// Unrestricted users pick a company first; nothing is listed until then.
+	var (
+		entries  []PendingJournal
+		settings []PostingSetting
+	)
+	if companyID > 0 {
+		entries, err = h.service.ListPending(r.Context(), PendingFilter{CompanyID: companyID, Status: status})
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "list pending journals", slog.Any("error", err))
+			httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		settings, err = h.service.PostingSettings(r.Context(), companyID)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "load posting settings", slog.Any("error", err))
+			httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	if status == "" {
+		status = PendingStatusPending
+	}
+	sess := internalShared.SessionFromContext(r.Context())
+	var csrfToken string
+	if h.csrf != nil {
+		csrfToken, _ = h.csrf.EnsureToken(r.Context(), sess)
+	}
+	var flash *internalShared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{
+		Title:       "Pending Integration Journals",
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data: map[string]any{
+			"CompanyID": companyID,
+			"Status":    string(status),
+			"Journals":  entries,
+			"Settings":  settings,
+		},
+	}
+	if err := h.templates.Render(w, "pages/accounting/journals_pending.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render pending journals", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// UpdatePostingSetting switches auto-posting for one integration module of
+// the submitted company.
+func (h *Handler) UpdatePostingSetting(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	setting := PostingSetting{
+		CompanyID: parseID(r.PostFormValue("company_id")),
+		Module:    r.PostFormValue("module"),
+		AutoPost:  r.PostFormValue("auto_post") == "1",
+	}
+	location := pendingLocation(setting.CompanyID)
+	actorID := sessionUserID(internalShared.SessionFromContext(r.Context()))
+	if err := h.service.SetAutoPost(r.Context(), setting, actorID); err != nil {
+		h.logger.ErrorContext(r.Context(), "update posting setting", slog.Any("error", err))
+		h.redirectWithFlash(w, r, location, "danger", pendingErrorMessage(err))
+		return
+	}
+	message := fmt.Sprintf("%s journals now post automatically", setting.Module)
+	if !setting.AutoPost {
+		message = fmt.Sprintf("%s journals now wait for approval", setting.Module)
+	}
+	h.redirectWithFlash(w, r, location, "success", message)
+}
+
+// ApprovePending posts a staged integration journal to the ledger.
+func (h *Handler) ApprovePending(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	location := pendingLocation(parseID(r.PostFormValue("company_id")))
+	actorID := sessionUserID(internalShared.SessionFromContext(r.Context()))
+	entry, err := h.service.ApprovePending(r.Context(), id, actorID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "approve pending journal", slog.Any("error", err), slog.Int64("id", id))
+		h.redirectWithFlash(w, r, location, "danger", pendingErrorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, location, "success", fmt.Sprintf("Posted JE %d", entry.Number))
+}
+
+// RejectPending discards a staged integration journal with a reason.
+func (h *Handler) RejectPending(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	location := pendingLocation(parseID(r.PostFormValue("company_id")))
+	actorID := sessionUserID(internalShared.SessionFromContext(r.Context()))
+	if err := h.service.RejectPending(r.Context(), id, actorID, r.PostFormValue("reason")); err != nil {
+		h.logger.ErrorContext(r.Context(), "reject pending journal", slog.Any("error", err), slog.Int64("id", id))
+		h.redirectWithFlash(w, r, location, "danger", pendingErrorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, location, "success", "Pending journal rejected")
+}
+
+func (h *Handler) redirectWithFlash(w http.ResponseWriter, r *http.Request, location, kind, message string) {
+	if sess := internalShared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(internalShared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, location, http.StatusSeeOther)
+}
+
+func pendingLocation(companyID int64) string {
+	if companyID <= 0 {
+		return "/accounting/journals/pending"
+	}
+	return "/accounting/journals/pending?company_id=" + strconv.FormatInt(companyID, 10)
+}
+
+// pendingErrorMessage shows review and posting validation errors verbatim and
+// hides everything else behind the generic user-safe message.
+func pendingErrorMessage(err error) string {
+	for _, known := range []error{
+		shared.ErrPendingJournalNotFound, shared.ErrUnknownIntegrationModule, shared.ErrRejectReasonRequired,
+		shared.ErrInvalidStatus, shared.ErrSourceAlreadyLinked, shared.ErrPeriodLocked, shared.ErrInvalidPeriod,
+		shared.ErrDateOutOfRange, shared.ErrAccountCompanyMismatch, shared.ErrDimensionMismatch,
+	} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	return internalShared.UserSafeMessage(err)
+}
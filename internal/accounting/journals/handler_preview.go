@@ -0,0 +1,86 @@
+package journals
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ManualSourceModule tags journals keyed in by hand.
+const ManualSourceModule = "ACCOUNTING:MANUAL"
+
+type previewLineRequest struct {
+	AccountID   int64   `json:"account_id"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	CompanyID   *int64  `json:"company_id"`
+	BranchID    *int64  `json:"branch_id"`
+	WarehouseID *int64  `json:"warehouse_id"`
+}
+
+type previewRequest struct {
+	PeriodID     int64                `json:"period_id"`
+	Date         string               `json:"date"`
+	Memo         string               `json:"memo"`
+	SourceModule string               `json:"source_module"`
+	SourceID     string               `json:"source_id"`
+	Lines        []previewLineRequest `json:"lines"`
+}
+
+// Preview dry-runs a journal posting and returns the validation errors and
+// net effect per account as JSON. Nothing is written.
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	var req previewRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid Body", "Request body must be a JSON journal with period_id, date and lines.")
+		return
+	}
+	input := PostingInput{
+		PeriodID:     req.PeriodID,
+		Memo:         req.Memo,
+		SourceModule: req.SourceModule,
+		SourceID:     uuid.New(),
+		PostedBy:     sessionUserID(internalShared.SessionFromContext(r.Context())),
+	}
+	if input.SourceModule == "" {
+		input.SourceModule = ManualSourceModule
+	}
+	if req.SourceID != "" {
+		id, err := uuid.Parse(req.SourceID)
+		if err != nil {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid Source", "source_id must be a UUID.")
+			return
+		}
+		input.SourceID = id
+	}
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid Date", "date must be in YYYY-MM-DD format.")
+			return
+		}
+		input.Date = date
+	}
+	for _, line := range req.Lines {
+		input.Lines = append(input.Lines, PostingLineInput{
+			AccountID: line.AccountID,
+			Debit:     line.Debit,
+			Credit:    line.Credit,
+			CompanyID: line.CompanyID,
+			BranchID:  line.BranchID,
+			Warehouse: line.WarehouseID,
+		})
+	}
+
+	preview, err := h.service.PreviewJournal(r.Context(), input)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "preview journal", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "")
+		return
+	}
+	httpx.JSON(w, http.StatusOK, preview)
+}
@@ -0,0 +1,130 @@
+package journals
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// ReclassForm renders the GL reclassification form.
+func (h *Handler) ReclassForm(w http.ResponseWriter, r *http.Request) {
+	h.renderReclass(w, r, ReclassInput{}, "", http.StatusOK)
+}
+
+// Reclass posts a reclassification journal from the submitted form.
+func (h *Handler) Reclass(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	input := ReclassInput{
+		SourceAccountID: parseID(r.PostFormValue("source_account_id")),
+		TargetAccountID: parseID(r.PostFormValue("target_account_id")),
+		PeriodID:        parseID(r.PostFormValue("period_id")),
+		Dimensions: DimensionFilter{
+			CompanyID:   parseOptionalID(r.PostFormValue("company_id")),
+			BranchID:    parseOptionalID(r.PostFormValue("branch_id")),
+			WarehouseID: parseOptionalID(r.PostFormValue("warehouse_id")),
+		},
+		Memo:     r.PostFormValue("memo"),
+		ActorID:  sessionUserID(internalShared.SessionFromContext(r.Context())),
+		Override: r.PostFormValue("override") == "on",
+	}
+	if raw := r.PostFormValue("date"); raw != "" {
+		if date, err := time.Parse("2006-01-02", raw); err == nil {
+			input.Date = &date
+		}
+	}
+
+	result, err := h.service.Reclassify(r.Context(), input)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "reclassify journal", slog.Any("error", err))
+		h.renderReclass(w, r, input, reclassErrorMessage(err), http.StatusBadRequest)
+		return
+	}
+	if sess := internalShared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(internalShared.FlashMessage{
+			Kind:    "success",
+			Message: fmt.Sprintf("Posted JE %d moving %.2f from %s to %s", result.Entry.Number, result.Amount, result.Source.Code, result.Target.Code),
+		})
+	}
+	http.Redirect(w, r, "/accounting/journals", http.StatusSeeOther)
+}
+
+func (h *Handler) renderReclass(w http.ResponseWriter, r *http.Request, input ReclassInput, errMsg string, status int) {
+	accounts, periodList, err := h.service.ReclassOptions(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load reclass options", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	sess := internalShared.SessionFromContext(r.Context())
+	var csrfToken string
+	if h.csrf != nil {
+		csrfToken, _ = h.csrf.EnsureToken(r.Context(), sess)
+	}
+	var flash *internalShared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{
+		Title:       "GL Reclassification",
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data: map[string]any{
+			"Accounts": accounts,
+			"Periods":  periodList,
+			"Input":    input,
+			"Error":    errMsg,
+		},
+	}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, "pages/accounting/journal_reclass.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render reclass", slog.Any("error", err))
+	}
+}
+
+// reclassErrorMessage shows accounting validation errors verbatim and hides
+// everything else behind the generic user-safe message.
+func reclassErrorMessage(err error) string {
+	for _, known := range []error{
+		shared.ErrReclassSameAccount, shared.ErrReclassTypeMismatch, shared.ErrReclassNothingToMove,
+		shared.ErrAccountNotFound, shared.ErrPeriodLocked, shared.ErrInvalidPeriod, shared.ErrDateOutOfRange,
+		shared.ErrAccountCompanyMismatch,
+	} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	return internalShared.UserSafeMessage(err)
+}
+
+func parseID(raw string) int64 {
+	id, _ := strconv.ParseInt(raw, 10, 64)
+	return id
+}
+
+func parseOptionalID(raw string) *int64 {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return nil
+	}
+	return &id
+}
+
+func sessionUserID(sess *internalShared.Session) int64 {
+	if sess == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(sess.User(), 10, 64)
+	return id
+}
@@ -0,0 +1,136 @@
+package journals
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// Search finds journal entries by the query filters. It renders the search
+// page, or JSON when the client accepts application/json.
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	wantsJSON := strings.Contains(r.Header.Get("Accept"), "application/json")
+	req, err := parseSearchRequest(q)
+	var page JournalSearchPage
+	if err == nil {
+		page, err = h.service.Search(r.Context(), req)
+	}
+	status := http.StatusOK
+	errMsg := ""
+	if err != nil {
+		if !errors.Is(err, shared.ErrInvalidSearch) {
+			h.logger.ErrorContext(r.Context(), "search journals", slog.Any("error", err))
+			if wantsJSON {
+				httpx.Problem(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "")
+				return
+			}
+			errMsg, status = internalShared.UserSafeMessage(err), http.StatusInternalServerError
+		} else {
+			if wantsJSON {
+				httpx.Problem(w, http.StatusBadRequest, "Invalid search", err.Error())
+				return
+			}
+			errMsg, status = err.Error(), http.StatusBadRequest
+		}
+	}
+	if wantsJSON {
+		httpx.JSON(w, http.StatusOK, page)
+		return
+	}
+
+	accounts, err := h.service.SearchAccounts(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load journal search accounts", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	pageQuery := url.Values{}
+	for key, values := range q {
+		if key != "page" {
+			pageQuery[key] = values
+		}
+	}
+	viewData := view.TemplateData{
+		Title:       "Journal Search",
+		CurrentPath: r.URL.Path,
+		Data: map[string]any{
+			"Accounts":  accounts,
+			"Query":     q,
+			"PageQuery": template.URL(pageQuery.Encode()),
+			"Result":    page,
+			"Searched":  len(q) > 0,
+			"Error":     errMsg,
+		},
+	}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, "pages/accounting/journal_search.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render journal search", slog.Any("error", err))
+	}
+}
+
+// parseSearchRequest reads the search filters from the query string. Empty
+// values are ignored; malformed ones are reported as ErrInvalidSearch.
+func parseSearchRequest(q url.Values) (SearchRequest, error) {
+	req := SearchRequest{
+		SourceModule: q.Get("source_module"),
+		Memo:         q.Get("memo"),
+		Status:       JournalStatus(q.Get("status")),
+	}
+	var err error
+	if req.From, err = parseSearchDate(q.Get("from"), "from"); err != nil {
+		return req, err
+	}
+	if req.To, err = parseSearchDate(q.Get("to"), "to"); err != nil {
+		return req, err
+	}
+	if raw := strings.TrimSpace(q.Get("account_id")); raw != "" {
+		if req.AccountID = parseOptionalID(raw); req.AccountID == nil {
+			return req, fmt.Errorf("%w: account_id must be a positive number", shared.ErrInvalidSearch)
+		}
+	}
+	if req.MinAmount, err = parseSearchAmount(q.Get("min_amount"), "min_amount"); err != nil {
+		return req, err
+	}
+	if req.MaxAmount, err = parseSearchAmount(q.Get("max_amount"), "max_amount"); err != nil {
+		return req, err
+	}
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	req.PerPage, _ = strconv.Atoi(q.Get("per_page"))
+	return req, nil
+}
+
+func parseSearchDate(raw, field string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be a date (YYYY-MM-DD)", shared.ErrInvalidSearch, field)
+	}
+	return date, nil
+}
+
+func parseSearchAmount(raw, field string) (*float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be a number", shared.ErrInvalidSearch, field)
+	}
+	return &amount, nil
+}
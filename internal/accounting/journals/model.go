@@ -44,3 +44,33 @@ type JournalLine struct {
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
+
+// AccountRef is the chart of accounts data journals need for validation.
+type AccountRef struct {
+	ID       int64
+	Code     string
+	Name     string
+	Type     string
+	IsActive bool
+	// CompanyID owns the account's chart; 0 is the shared chart.
+	// CompanyCode is that company's code.
+	CompanyID   int64
+	CompanyCode string
+}
+
+// DimensionBalance is the net debit-minus-credit balance of one account for
+// a single company/branch/warehouse combination.
+type DimensionBalance struct {
+	CompanyID   *int64
+	BranchID    *int64
+	WarehouseID *int64
+	Balance     float64
+}
+
+// ClosingBalance is the net debit-minus-credit balance of a revenue or expense
+// account over a fiscal year for one dimension combination.
+type ClosingBalance struct {
+	AccountID int64
+	Type      string
+	DimensionBalance
+}
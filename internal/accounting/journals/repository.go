@@ -12,12 +12,25 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 // Repository encapsulates DB operations for journals.
 // It also needs access to periods for transaction-safe checks.
 type Repository interface {
 	List(ctx context.Context) ([]JournalEntry, error)
+	ListLines(ctx context.Context, req ListLinesRequest) ([]JournalLineItem, error)
+	Search(ctx context.Context, req SearchRequest) ([]JournalSearchHit, int, error)
+	// ListAccounts and ListPeriods feed the reclassification form.
+	ListAccounts(ctx context.Context) ([]AccountRef, error)
+	ListPeriods(ctx context.Context) ([]periods.Period, error)
+	// InconsistentDimensionLines feeds the dimension issue report.
+	InconsistentDimensionLines(ctx context.Context, limit int) ([]DimensionIssue, DimensionOwners, error)
+	// Integration posting settings and the pending journal queue
+	PostingSettings(ctx context.Context, companyID int64) (map[string]bool, error)
+	SavePostingSetting(ctx context.Context, setting PostingSetting, actorID int64) error
+	InsertPendingJournal(ctx context.Context, p PendingJournal) (bool, error)
+	ListPendingJournals(ctx context.Context, filter PendingFilter) ([]PendingJournal, error)
 	// Tx Operations are internal or exposed via specific service methods
 	WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error
 }
@@ -33,6 +46,24 @@ type TxRepository interface {
 	// Period operations needed within journal transactions
 	GetPeriodForUpdate(ctx context.Context, periodID int64) (periods.Period, error)
 	GetNextOpenPeriodAfter(ctx context.Context, date time.Time) (periods.Period, error)
+
+	// Account lookups needed for reclassification
+	GetAccount(ctx context.Context, accountID int64) (AccountRef, error)
+	AccountBalances(ctx context.Context, accountID, periodID int64, filter DimensionFilter) ([]DimensionBalance, error)
+
+	// Dimension masters needed to validate posted lines
+	DimensionOwners(ctx context.Context, branchIDs, warehouseIDs []int64) (DimensionOwners, error)
+	// Charts of accounts needed to resolve company accounts
+	AccountCharts(ctx context.Context, accountIDs, companyIDs []int64) (AccountCharts, error)
+
+	// Year-end closing
+	FindSourceLink(ctx context.Context, module string, ref uuid.UUID) (int64, error)
+	IncomeStatementBalances(ctx context.Context, companyID int64, from, to time.Time) ([]ClosingBalance, error)
+
+	// Pending integration journal review
+	GetPendingJournalForUpdate(ctx context.Context, id int64) (PendingJournal, error)
+	FindPendingBySource(ctx context.Context, module string, ref uuid.UUID) (PendingJournal, error)
+	DecidePendingJournal(ctx context.Context, id int64, decision PendingDecision) error
 }
 
 type repository struct {
@@ -44,7 +75,11 @@ func NewRepository(db *pgxpool.Pool) Repository {
 }
 
 func (r *repository) List(ctx context.Context) ([]JournalEntry, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, number, period_id, date, source_module, source_id, memo, posted_by, posted_at, status, created_at, updated_at FROM journal_entries ORDER BY number DESC`)
+	scope := internalShared.CompanyScopeFromContext(ctx)
+	rows, err := r.db.Query(ctx, `SELECT id, number, period_id, date, source_module, source_id, memo, posted_by, posted_at, status, created_at, updated_at
+FROM journal_entries je
+WHERE $1 OR `+fmt.Sprintf(scopedEntry, "$2")+`
+ORDER BY number DESC`, scope.Unrestricted, scope.CompanyIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +96,81 @@ func (r *repository) List(ctx context.Context) ([]JournalEntry, error) {
 	return entries, rows.Err()
 }
 
+// ListLines pages posted journal lines newest first. A non-zero AfterID uses
+// the primary key as the keyset so deep pages avoid OFFSET scans. Lines of
+// companies outside the caller's scope are left out.
+func (r *repository) ListLines(ctx context.Context, req ListLinesRequest) ([]JournalLineItem, error) {
+	scope := internalShared.CompanyScopeFromContext(ctx)
+	query := `SELECT jl.id, jl.je_id, je.number, je.date, je.period_id, jl.account_id, a.code,
+       jl.debit::float8, jl.credit::float8, jl.dim_company_id, jl.dim_branch_id, jl.dim_warehouse_id
+FROM journal_lines jl
+JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
+JOIN accounts a ON a.id = jl.account_id
+WHERE ($1::bigint IS NULL OR jl.account_id = $1)
+  AND ($2::bigint IS NULL OR je.period_id = $2)
+  AND ($3::bigint IS NULL OR jl.dim_company_id = $3)
+  AND ($4::bigint IS NULL OR jl.dim_branch_id = $4)
+  AND ($5::bigint = 0 OR jl.id < $5)
+  AND ($8 OR COALESCE(jl.dim_company_id, a.company_id) IS NULL OR COALESCE(jl.dim_company_id, a.company_id) = ANY($9))
+ORDER BY jl.id DESC
+LIMIT $6 OFFSET $7`
+	rows, err := r.db.Query(ctx, query, req.AccountID, req.PeriodID, req.CompanyID, req.BranchID, req.AfterID, req.Limit, req.Offset,
+		scope.Unrestricted, scope.CompanyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var lines []JournalLineItem
+	for rows.Next() {
+		var l JournalLineItem
+		if err := rows.Scan(&l.ID, &l.JournalID, &l.JournalNumber, &l.Date, &l.PeriodID, &l.AccountID, &l.AccountCode,
+			&l.Debit, &l.Credit, &l.DimCompanyID, &l.DimBranchID, &l.DimWarehouseID); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+func (r *repository) ListAccounts(ctx context.Context) ([]AccountRef, error) {
+	rows, err := r.db.Query(ctx, `SELECT a.id, a.code, a.name, a.type, a.is_active, COALESCE(a.company_id, 0), COALESCE(c.code, '')
+FROM accounts a
+LEFT JOIN companies c ON c.id = a.company_id
+WHERE a.is_active
+ORDER BY a.code, a.company_id NULLS FIRST`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var accounts []AccountRef
+	for rows.Next() {
+		var a AccountRef
+		if err := rows.Scan(&a.ID, &a.Code, &a.Name, &a.Type, &a.IsActive, &a.CompanyID, &a.CompanyCode); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *repository) ListPeriods(ctx context.Context) ([]periods.Period, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, code, start_date, end_date, status, closed_at, locked_by, created_at, updated_at
+FROM periods ORDER BY start_date DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []periods.Period
+	for rows.Next() {
+		var p periods.Period
+		if err := rows.Scan(&p.ID, &p.Code, &p.StartDate, &p.EndDate, &p.Status, &p.ClosedAt, &p.LockedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
 func (r *repository) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
 	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
 	if err != nil {
@@ -184,6 +294,93 @@ FROM periods WHERE status='OPEN' AND start_date >= $1 ORDER BY start_date ASC LI
 	return p, nil
 }
 
+func (r *txRepository) GetAccount(ctx context.Context, accountID int64) (AccountRef, error) {
+	var a AccountRef
+	err := r.tx.QueryRow(ctx, `SELECT a.id, a.code, a.name, a.type, a.is_active, COALESCE(a.company_id, 0), COALESCE(c.code, '')
+FROM accounts a
+LEFT JOIN companies c ON c.id = a.company_id
+WHERE a.id=$1`, accountID).
+		Scan(&a.ID, &a.Code, &a.Name, &a.Type, &a.IsActive, &a.CompanyID, &a.CompanyCode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AccountRef{}, shared.ErrAccountNotFound
+		}
+		return AccountRef{}, err
+	}
+	return a, nil
+}
+
+// AccountBalances sums posted lines of the account in the period, grouped by
+// dimension combination. Nil filter fields match any dimension value.
+func (r *txRepository) AccountBalances(ctx context.Context, accountID, periodID int64, filter DimensionFilter) ([]DimensionBalance, error) {
+	rows, err := r.tx.Query(ctx, `SELECT jl.dim_company_id, jl.dim_branch_id, jl.dim_warehouse_id, COALESCE(SUM(jl.debit - jl.credit), 0)::float8
+FROM journal_lines jl
+JOIN journal_entries je ON je.id = jl.je_id
+WHERE jl.account_id=$1 AND je.period_id=$2 AND je.status='POSTED'
+  AND ($3::bigint IS NULL OR jl.dim_company_id=$3)
+  AND ($4::bigint IS NULL OR jl.dim_branch_id=$4)
+  AND ($5::bigint IS NULL OR jl.dim_warehouse_id=$5)
+GROUP BY jl.dim_company_id, jl.dim_branch_id, jl.dim_warehouse_id
+ORDER BY jl.dim_company_id NULLS FIRST, jl.dim_branch_id NULLS FIRST, jl.dim_warehouse_id NULLS FIRST`,
+		accountID, periodID, filter.CompanyID, filter.BranchID, filter.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var balances []DimensionBalance
+	for rows.Next() {
+		var b DimensionBalance
+		if err := rows.Scan(&b.CompanyID, &b.BranchID, &b.WarehouseID, &b.Balance); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// FindSourceLink returns the journal linked to the source, or 0 when none is.
+func (r *txRepository) FindSourceLink(ctx context.Context, module string, ref uuid.UUID) (int64, error) {
+	var entryID int64
+	err := r.tx.QueryRow(ctx, `SELECT je_id FROM source_links WHERE module=$1 AND ref_id=$2`, module, ref).Scan(&entryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return entryID, nil
+}
+
+// IncomeStatementBalances sums posted revenue and expense lines of the company
+// dated within [from, to], grouped by account and dimension combination. Lines
+// without a company dimension belong to the company owning the account.
+func (r *txRepository) IncomeStatementBalances(ctx context.Context, companyID int64, from, to time.Time) ([]ClosingBalance, error) {
+	rows, err := r.tx.Query(ctx, `SELECT jl.account_id, a.type, jl.dim_branch_id, jl.dim_warehouse_id, COALESCE(SUM(jl.debit - jl.credit), 0)::float8
+FROM journal_lines jl
+JOIN journal_entries je ON je.id = jl.je_id
+JOIN accounts a ON a.id = jl.account_id
+WHERE a.type IN ('REVENUE','EXPENSE') AND je.status='POSTED'
+  AND je.date BETWEEN $2 AND $3
+  AND COALESCE(jl.dim_company_id, a.company_id) = $1
+GROUP BY jl.account_id, a.code, a.type, jl.dim_branch_id, jl.dim_warehouse_id
+ORDER BY a.code, jl.dim_branch_id NULLS FIRST, jl.dim_warehouse_id NULLS FIRST`,
+		companyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var balances []ClosingBalance
+	for rows.Next() {
+		var b ClosingBalance
+		if err := rows.Scan(&b.AccountID, &b.Type, &b.BranchID, &b.WarehouseID, &b.Balance); err != nil {
+			return nil, err
+		}
+		b.CompanyID = &companyID
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
 // Helpers
 func nullInt(val int64) any {
 	if val == 0 {
@@ -0,0 +1,51 @@
+package journals
+
+import "context"
+
+// AccountCharts loads the chart and code of each account, and the accounts of
+// the companies' own charts sharing those codes.
+func (r *txRepository) AccountCharts(ctx context.Context, accountIDs, companyIDs []int64) (AccountCharts, error) {
+	charts := AccountCharts{Owner: map[int64]int64{}, Code: map[int64]string{}, CompanyCodes: map[int64]map[string]int64{}}
+	rows, err := r.tx.Query(ctx, `SELECT id, COALESCE(company_id, 0), code FROM accounts WHERE id = ANY($1)`, accountIDs)
+	if err != nil {
+		return AccountCharts{}, err
+	}
+	var codes []string
+	for rows.Next() {
+		var id, owner int64
+		var code string
+		if err := rows.Scan(&id, &owner, &code); err != nil {
+			rows.Close()
+			return AccountCharts{}, err
+		}
+		charts.Owner[id] = owner
+		charts.Code[id] = code
+		codes = append(codes, code)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return AccountCharts{}, err
+	}
+	if len(companyIDs) == 0 {
+		return charts, nil
+	}
+
+	rows, err = r.tx.Query(ctx, `SELECT company_id, code, id FROM accounts
+WHERE company_id = ANY($1) AND code = ANY($2)`, companyIDs, codes)
+	if err != nil {
+		return AccountCharts{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var companyID, id int64
+		var code string
+		if err := rows.Scan(&companyID, &code, &id); err != nil {
+			return AccountCharts{}, err
+		}
+		if charts.CompanyCodes[companyID] == nil {
+			charts.CompanyCodes[companyID] = map[string]int64{}
+		}
+		charts.CompanyCodes[companyID][code] = id
+	}
+	return charts, rows.Err()
+}
@@ -0,0 +1,92 @@
+package journals
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DimensionOwners loads the company of each branch and the branch of each
+// warehouse, including the company of those warehouse branches.
+func (r *txRepository) DimensionOwners(ctx context.Context, branchIDs, warehouseIDs []int64) (DimensionOwners, error) {
+	owners := DimensionOwners{BranchCompany: map[int64]int64{}, WarehouseBranch: map[int64]int64{}}
+	rows, err := r.tx.Query(ctx, `SELECT id, branch_id FROM warehouses WHERE id = ANY($1)`, warehouseIDs)
+	if err != nil {
+		return DimensionOwners{}, err
+	}
+	for rows.Next() {
+		var warehouseID, branchID int64
+		if err := rows.Scan(&warehouseID, &branchID); err != nil {
+			rows.Close()
+			return DimensionOwners{}, err
+		}
+		owners.WarehouseBranch[warehouseID] = branchID
+		branchIDs = append(branchIDs, branchID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return DimensionOwners{}, err
+	}
+
+	rows, err = r.tx.Query(ctx, `SELECT id, company_id FROM branches WHERE id = ANY($1)`, branchIDs)
+	if err != nil {
+		return DimensionOwners{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var branchID, companyID int64
+		if err := rows.Scan(&branchID, &companyID); err != nil {
+			return DimensionOwners{}, err
+		}
+		owners.BranchCompany[branchID] = companyID
+	}
+	return owners, rows.Err()
+}
+
+// InconsistentDimensionLines returns up to limit posted lines, oldest first,
+// whose branch or warehouse is missing or does not belong to the line's
+// company or branch. The owners of the branches and warehouses involved are
+// returned alongside so the caller can describe each problem.
+func (r *repository) InconsistentDimensionLines(ctx context.Context, limit int) ([]DimensionIssue, DimensionOwners, error) {
+	rows, err := r.db.Query(ctx, `SELECT jl.id, je.id, je.number, je.date, je.source_module, a.code, a.name,
+       jl.debit::float8, jl.credit::float8, jl.dim_company_id, jl.dim_branch_id, jl.dim_warehouse_id,
+       b.company_id, w.branch_id, wb.company_id
+FROM journal_lines jl
+JOIN journal_entries je ON je.id = jl.je_id
+JOIN accounts a ON a.id = jl.account_id
+LEFT JOIN branches b ON b.id = jl.dim_branch_id
+LEFT JOIN warehouses w ON w.id = jl.dim_warehouse_id
+LEFT JOIN branches wb ON wb.id = w.branch_id
+WHERE je.status = 'POSTED'
+  AND ((jl.dim_branch_id IS NOT NULL AND (b.id IS NULL OR b.company_id <> jl.dim_company_id))
+    OR (jl.dim_warehouse_id IS NOT NULL AND (w.id IS NULL
+        OR w.branch_id <> jl.dim_branch_id
+        OR (jl.dim_branch_id IS NULL AND wb.company_id <> jl.dim_company_id))))
+ORDER BY je.date, je.number, jl.id
+LIMIT $1`, limit)
+	if err != nil {
+		return nil, DimensionOwners{}, err
+	}
+	defer rows.Close()
+	owners := DimensionOwners{BranchCompany: map[int64]int64{}, WarehouseBranch: map[int64]int64{}}
+	var issues []DimensionIssue
+	for rows.Next() {
+		var issue DimensionIssue
+		var branchCompany, warehouseBranch, warehouseCompany pgtype.Int8
+		if err := rows.Scan(&issue.LineID, &issue.JournalID, &issue.JournalNumber, &issue.Date, &issue.SourceModule,
+			&issue.AccountCode, &issue.AccountName, &issue.Debit, &issue.Credit,
+			&issue.CompanyID, &issue.BranchID, &issue.WarehouseID,
+			&branchCompany, &warehouseBranch, &warehouseCompany); err != nil {
+			return nil, DimensionOwners{}, err
+		}
+		if issue.BranchID != nil && branchCompany.Valid {
+			owners.BranchCompany[*issue.BranchID] = branchCompany.Int64
+		}
+		if issue.WarehouseID != nil && warehouseBranch.Valid {
+			owners.WarehouseBranch[*issue.WarehouseID] = warehouseBranch.Int64
+			owners.BranchCompany[warehouseBranch.Int64] = warehouseCompany.Int64
+		}
+		issues = append(issues, issue)
+	}
+	return issues, owners, rows.Err()
+}
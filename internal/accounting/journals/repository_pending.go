@@ -0,0 +1,138 @@
+package journals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+// pendingLine is the stored JSON shape of a staged posting line.
+type pendingLine struct {
+	AccountID   int64   `json:"account_id"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	CompanyID   *int64  `json:"company_id,omitempty"`
+	BranchID    *int64  `json:"branch_id,omitempty"`
+	WarehouseID *int64  `json:"warehouse_id,omitempty"`
+}
+
+const pendingColumns = `id, company_id, module, period_id, date, source_module, source_id, memo, lines,
+       status, journal_entry_id, decided_by, decided_at, decision_note, created_at`
+
+func (r *repository) PostingSettings(ctx context.Context, companyID int64) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx, `SELECT module, auto_post FROM integration_posting_settings WHERE company_id = $1`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	settings := make(map[string]bool)
+	for rows.Next() {
+		var module string
+		var autoPost bool
+		if err := rows.Scan(&module, &autoPost); err != nil {
+			return nil, err
+		}
+		settings[module] = autoPost
+	}
+	return settings, rows.Err()
+}
+
+func (r *repository) SavePostingSetting(ctx context.Context, setting PostingSetting, actorID int64) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO integration_posting_settings (company_id, module, auto_post, updated_by)
+VALUES ($1, $2, $3, NULLIF($4, 0))
+ON CONFLICT (company_id, module) DO UPDATE
+SET auto_post = EXCLUDED.auto_post, updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+		setting.CompanyID, setting.Module, setting.AutoPost, actorID)
+	return err
+}
+
+// InsertPendingJournal stages a posting, reporting false when its source is
+// already staged.
+func (r *repository) InsertPendingJournal(ctx context.Context, p PendingJournal) (bool, error) {
+	lines := make([]pendingLine, len(p.Input.Lines))
+	for i, l := range p.Input.Lines {
+		lines[i] = pendingLine{AccountID: l.AccountID, Debit: l.Debit, Credit: l.Credit, CompanyID: l.CompanyID, BranchID: l.BranchID, WarehouseID: l.Warehouse}
+	}
+	raw, err := json.Marshal(lines)
+	if err != nil {
+		return false, err
+	}
+	tag, err := r.db.Exec(ctx, `INSERT INTO pending_journals (company_id, module, period_id, date, source_module, source_id, memo, lines, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (source_module, source_id) DO NOTHING`,
+		p.CompanyID, p.Module, p.Input.PeriodID, p.Input.Date, p.Input.SourceModule, p.Input.SourceID, p.Input.Memo, raw, p.Status)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *repository) ListPendingJournals(ctx context.Context, filter PendingFilter) ([]PendingJournal, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+pendingColumns+`
+FROM pending_journals
+WHERE company_id = $1 AND status = $2
+ORDER BY created_at, id
+LIMIT $3`, filter.CompanyID, filter.Status, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []PendingJournal
+	for rows.Next() {
+		p, err := scanPendingJournal(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *txRepository) GetPendingJournalForUpdate(ctx context.Context, id int64) (PendingJournal, error) {
+	p, err := scanPendingJournal(r.tx.QueryRow(ctx, `SELECT `+pendingColumns+` FROM pending_journals WHERE id = $1 FOR UPDATE`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PendingJournal{}, shared.ErrPendingJournalNotFound
+	}
+	return p, err
+}
+
+// FindPendingBySource locks the staged journal of a source document.
+func (r *txRepository) FindPendingBySource(ctx context.Context, module string, ref uuid.UUID) (PendingJournal, error) {
+	p, err := scanPendingJournal(r.tx.QueryRow(ctx, `SELECT `+pendingColumns+`
+FROM pending_journals WHERE source_module = $1 AND source_id = $2 FOR UPDATE`, module, ref))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PendingJournal{}, shared.ErrPendingJournalNotFound
+	}
+	return p, err
+}
+
+func (r *txRepository) DecidePendingJournal(ctx context.Context, id int64, d PendingDecision) error {
+	_, err := r.tx.Exec(ctx, `UPDATE pending_journals
+SET status = $2, decided_by = NULLIF($3, 0), decided_at = $4, journal_entry_id = $5, decision_note = $6
+WHERE id = $1`, id, d.Status, d.ActorID, d.At, d.JournalEntryID, d.Note)
+	return err
+}
+
+func scanPendingJournal(row pgx.Row) (PendingJournal, error) {
+	var (
+		p   PendingJournal
+		raw []byte
+	)
+	if err := row.Scan(&p.ID, &p.CompanyID, &p.Module, &p.Input.PeriodID, &p.Input.Date, &p.Input.SourceModule, &p.Input.SourceID,
+		&p.Input.Memo, &raw, &p.Status, &p.JournalEntryID, &p.DecidedBy, &p.DecidedAt, &p.DecisionNote, &p.CreatedAt); err != nil {
+		return PendingJournal{}, err
+	}
+	var lines []pendingLine
+	if err := json.Unmarshal(raw, &lines); err != nil {
+		return PendingJournal{}, err
+	}
+	p.Input.Lines = make([]PostingLineInput, len(lines))
+	for i, l := range lines {
+		p.Input.Lines[i] = PostingLineInput{AccountID: l.AccountID, Debit: l.Debit, Credit: l.Credit, CompanyID: l.CompanyID, BranchID: l.BranchID, Warehouse: l.WarehouseID}
+	}
+	return p, nil
+}
@@ -0,0 +1,136 @@
+package journals
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// scopedEntry keeps journal entries aliased je with a line of the shared chart
+// or of a company bound to the placeholder. A line belongs to its company
+// dimension, falling back to the company owning its account.
+const scopedEntry = `EXISTS (SELECT 1 FROM journal_lines sl JOIN accounts sa ON sa.id = sl.account_id
+WHERE sl.je_id = je.id
+  AND (COALESCE(sl.dim_company_id, sa.company_id) IS NULL OR COALESCE(sl.dim_company_id, sa.company_id) = ANY(%s)))`
+
+// Search returns one page of journal entries matching req with all their
+// lines. Line filters are applied through EXISTS so an entry is counted once
+// however many of its lines match. Entries entirely of companies outside the
+// caller's scope are left out.
+func (r *repository) Search(ctx context.Context, req SearchRequest) ([]JournalSearchHit, int, error) {
+	var conditions []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if !req.From.IsZero() {
+		conditions = append(conditions, "je.date >= "+arg(req.From))
+	}
+	if !req.To.IsZero() {
+		conditions = append(conditions, "je.date <= "+arg(req.To))
+	}
+	if req.SourceModule != "" {
+		conditions = append(conditions, "je.source_module = "+arg(req.SourceModule))
+	}
+	if req.Status != "" {
+		conditions = append(conditions, "je.status = "+arg(string(req.Status))+"::journal_status")
+	}
+	if req.Memo != "" {
+		conditions = append(conditions, "je.memo ILIKE "+arg("%"+escapeLike(req.Memo)+"%"))
+	}
+	if lineMatch := searchLineMatch(req, arg); lineMatch != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM journal_lines jl WHERE jl.je_id = je.id AND "+lineMatch+")")
+	}
+	if scope := internalShared.CompanyScopeFromContext(ctx); !scope.Unrestricted {
+		conditions = append(conditions, fmt.Sprintf(scopedEntry, arg(scope.CompanyIDs)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM journal_entries je "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	query := `SELECT je.id, je.number, je.period_id, je.date, je.source_module, COALESCE(je.memo, ''), je.status,
+       COALESCE((SELECT SUM(l.debit) FROM journal_lines l WHERE l.je_id = je.id), 0)::float8
+FROM journal_entries je ` + where + `
+ORDER BY je.date DESC, je.number DESC
+LIMIT ` + arg(req.PerPage) + ` OFFSET ` + arg((req.Page-1)*req.PerPage)
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	var hits []JournalSearchHit
+	index := make(map[int64]int)
+	ids := make([]int64, 0, req.PerPage)
+	for rows.Next() {
+		var h JournalSearchHit
+		if err := rows.Scan(&h.ID, &h.Number, &h.PeriodID, &h.Date, &h.SourceModule, &h.Memo, &h.Status, &h.TotalDebit); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		index[h.ID] = len(hits)
+		ids = append(ids, h.ID)
+		hits = append(hits, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	args = []any{ids}
+	lineMatch := searchLineMatch(req, arg)
+	if lineMatch == "" {
+		lineMatch = "FALSE"
+	}
+	lineRows, err := r.db.Query(ctx, `SELECT jl.je_id, jl.id, jl.account_id, a.code, a.name, jl.debit::float8, jl.credit::float8, `+lineMatch+`
+FROM journal_lines jl
+JOIN accounts a ON a.id = jl.account_id
+WHERE jl.je_id = ANY($1)
+ORDER BY jl.je_id, jl.id`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer lineRows.Close()
+	for lineRows.Next() {
+		var entryID int64
+		var l JournalSearchLine
+		if err := lineRows.Scan(&entryID, &l.ID, &l.AccountID, &l.AccountCode, &l.AccountName, &l.Debit, &l.Credit, &l.Matched); err != nil {
+			return nil, 0, err
+		}
+		if i, ok := index[entryID]; ok {
+			hits[i].Lines = append(hits[i].Lines, l)
+		}
+	}
+	return hits, total, lineRows.Err()
+}
+
+// searchLineMatch builds the line filter condition on alias jl, or "" when
+// the search has no line filters.
+func searchLineMatch(req SearchRequest, arg func(any) string) string {
+	var conditions []string
+	if req.AccountID != nil {
+		conditions = append(conditions, "jl.account_id = "+arg(*req.AccountID))
+	}
+	if req.MinAmount != nil {
+		conditions = append(conditions, "(jl.debit + jl.credit) >= "+arg(*req.MinAmount))
+	}
+	if req.MaxAmount != nil {
+		conditions = append(conditions, "(jl.debit + jl.credit) <= "+arg(*req.MaxAmount))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// escapeLike escapes LIKE wildcards so memo text is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
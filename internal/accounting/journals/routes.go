@@ -1,10 +1,27 @@
 package journals
 
-import "github.com/go-chi/chi/v5"
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
 
 func (h *Handler) MountRoutes(r chi.Router) {
 	r.Get("/", h.List)
+	r.With(h.rbac.RequireAny(shared.PermFinanceGLView)).Get("/lines", h.ListLines)
+	r.With(h.rbac.RequireAny(shared.PermFinanceGLView)).Get("/search", h.Search)
+	r.With(h.rbac.RequireAny(shared.PermFinanceGLView)).Get("/dimension-issues", h.DimensionIssues)
+	r.With(h.rbac.RequireAny(shared.PermFinanceGLView)).Get("/pending", h.Pending)
 	r.Post("/", h.Create)
 	r.Post("/{id}/void", h.Void)
 	r.Post("/{id}/reverse", h.Reverse)
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAll(shared.PermFinanceGLEdit))
+		r.Get("/reclass", h.ReclassForm)
+		r.Post("/reclass", h.Reclass)
+		r.Post("/preview", h.Preview)
+		r.Post("/pending/settings", h.UpdatePostingSetting)
+		r.Post("/pending/{id}/approve", h.ApprovePending)
+		r.Post("/pending/{id}/reject", h.RejectPending)
+	})
 }
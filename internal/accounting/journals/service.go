@@ -25,6 +25,7 @@ type Service struct {
 	repo  Repository
 	audit AuditPort
 	guard PeriodGuard
+	cache CacheInvalidator
 	now   func() time.Time
 }
 
@@ -46,49 +47,71 @@ func (s *Service) PostJournal(ctx context.Context, input PostingInput) (JournalE
 	if err := input.Validate(); err != nil {
 		return JournalEntry{}, err
 	}
+	// Lines are resolved into company charts in place; keep the caller's.
+	input.Lines = append([]PostingLineInput(nil), input.Lines...)
 	var entry JournalEntry
 	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
-		if s.guard != nil {
-			if err := s.guard.EnsurePeriodOpenForPosting(ctx, input.PeriodID); err != nil {
-				if errors.Is(err, closepkg.ErrPeriodHardClosed) {
-					return shared.ErrPeriodLocked
-				}
-				return err
-			}
-		}
-		period, err := tx.GetPeriodForUpdate(ctx, input.PeriodID)
-		if err != nil {
-			return err
-		}
-		if period.Status == periods.PeriodStatusLocked {
-			return shared.ErrPeriodLocked
-		}
-		if period.Status != periods.PeriodStatusOpen && period.Status != periods.PeriodStatusClosed {
-			return shared.ErrInvalidPeriod
-		}
-		if input.Date.Before(period.StartDate) || input.Date.After(period.EndDate) {
-			return shared.ErrDateOutOfRange
-		}
-		inserted, err := tx.InsertJournalEntry(ctx, input)
-		if err != nil {
-			return err
-		}
-		if err := tx.InsertJournalLines(ctx, inserted.ID, input.Lines); err != nil {
-			return err
-		}
-		if err := tx.LinkSource(ctx, input.SourceModule, input.SourceID, inserted.ID); err != nil {
-			if errors.Is(err, shared.ErrSourceConflict) {
-				return shared.ErrSourceAlreadyLinked
+		var err error
+		entry, err = s.postInTx(ctx, tx, input)
+		return err
+	})
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	s.recordPosting(ctx, input, entry)
+	return entry, nil
+}
+
+// postInTx validates and inserts a balanced posting inside tx, linking it to
+// its source document.
+func (s *Service) postInTx(ctx context.Context, tx TxRepository, input PostingInput) (JournalEntry, error) {
+	if s.guard != nil {
+		if err := s.guard.EnsurePeriodOpenForPosting(ctx, input.PeriodID); err != nil {
+			if errors.Is(err, closepkg.ErrPeriodHardClosed) {
+				return JournalEntry{}, shared.ErrPeriodLocked
 			}
-			return err
+			return JournalEntry{}, err
 		}
-		inserted.Lines = toJournalLines(inserted.ID, input.Lines, s.now())
-		entry = inserted
-		return nil
-	})
+	}
+	period, err := tx.GetPeriodForUpdate(ctx, input.PeriodID)
 	if err != nil {
 		return JournalEntry{}, err
 	}
+	if period.Status == periods.PeriodStatusLocked {
+		return JournalEntry{}, shared.ErrPeriodLocked
+	}
+	if period.Status != periods.PeriodStatusOpen && period.Status != periods.PeriodStatusClosed {
+		return JournalEntry{}, shared.ErrInvalidPeriod
+	}
+	if input.Date.Before(period.StartDate) || input.Date.After(period.EndDate) {
+		return JournalEntry{}, shared.ErrDateOutOfRange
+	}
+	if err := s.resolveCompanyAccounts(ctx, tx, input.Lines); err != nil {
+		return JournalEntry{}, err
+	}
+	if err := s.validateDimensions(ctx, tx, input.Lines); err != nil {
+		return JournalEntry{}, err
+	}
+	inserted, err := tx.InsertJournalEntry(ctx, input)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	if err := tx.InsertJournalLines(ctx, inserted.ID, input.Lines); err != nil {
+		return JournalEntry{}, err
+	}
+	if err := tx.LinkSource(ctx, input.SourceModule, input.SourceID, inserted.ID); err != nil {
+		if errors.Is(err, shared.ErrSourceConflict) {
+			return JournalEntry{}, shared.ErrSourceAlreadyLinked
+		}
+		return JournalEntry{}, err
+	}
+	inserted.Lines = toJournalLines(inserted.ID, input.Lines, s.now())
+	return inserted, nil
+}
+
+// recordPosting invalidates cached reports and audits a committed posting.
+func (s *Service) recordPosting(ctx context.Context, input PostingInput, entry JournalEntry) {
+	s.invalidateCaches(ctx, entry.Date, entry.Lines)
 	if s.audit != nil {
 		_ = s.audit.Record(ctx, internalShared.AuditLog{
 			ActorID:  input.PostedBy,
@@ -103,7 +126,6 @@ func (s *Service) PostJournal(ctx context.Context, input PostingInput) (JournalE
 			At: s.now(),
 		})
 	}
-	return entry, nil
 }
 
 func (s *Service) VoidJournal(ctx context.Context, input VoidInput) (JournalEntry, error) {
@@ -142,6 +164,7 @@ func (s *Service) VoidJournal(ctx context.Context, input VoidInput) (JournalEntr
 		return JournalEntry{}, err
 	}
 	entry.Lines = lines
+	s.invalidateCaches(ctx, entry.Date, entry.Lines)
 	if s.audit != nil {
 		_ = s.audit.Record(ctx, internalShared.AuditLog{
 			ActorID:  input.ActorID,
@@ -219,6 +242,7 @@ func (s *Service) ReverseJournal(ctx context.Context, input ReverseInput) (Journ
 	if err != nil {
 		return JournalEntry{}, err
 	}
+	s.invalidateCaches(ctx, reversal.Date, reversal.Lines)
 	if s.audit != nil {
 		_ = s.audit.Record(ctx, internalShared.AuditLog{
 			ActorID:  input.ActorID,
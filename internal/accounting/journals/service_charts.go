@@ -0,0 +1,64 @@
+package journals
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+// AccountCharts describes the charts the accounts of a posting belong to.
+// Owner maps each account to its company, 0 for the shared chart. Code maps
+// each account to its code, and CompanyCodes maps a company to the accounts
+// of its own chart by code.
+type AccountCharts struct {
+	Owner        map[int64]int64
+	Code         map[int64]string
+	CompanyCodes map[int64]map[string]int64
+}
+
+// resolveCompanyAccounts moves lines posted to the shared chart onto the
+// account with the same code in the line company's chart, when the company
+// has one, and rejects lines posted to another company's account.
+func (s *Service) resolveCompanyAccounts(ctx context.Context, tx TxRepository, lines []PostingLineInput) error {
+	accountIDs := make([]int64, 0, len(lines))
+	var companyIDs []int64
+	for _, line := range lines {
+		accountIDs = append(accountIDs, line.AccountID)
+		if line.CompanyID != nil {
+			companyIDs = append(companyIDs, *line.CompanyID)
+		}
+	}
+	charts, err := tx.AccountCharts(ctx, accountIDs, companyIDs)
+	if err != nil {
+		return err
+	}
+	return applyCompanyCharts(lines, charts)
+}
+
+// applyCompanyCharts rewrites lines in place. A company account on a line
+// without a company stamps the account's company on the line. Errors name
+// the first offending line, counting from 1.
+func applyCompanyCharts(lines []PostingLineInput, charts AccountCharts) error {
+	for i := range lines {
+		line := &lines[i]
+		owner := charts.Owner[line.AccountID]
+		if owner != 0 {
+			if line.CompanyID == nil {
+				company := owner
+				line.CompanyID = &company
+			} else if *line.CompanyID != owner {
+				return fmt.Errorf("%w: line %d: account %s belongs to company %d, not %d",
+					shared.ErrAccountCompanyMismatch, i+1, charts.Code[line.AccountID], owner, *line.CompanyID)
+			}
+			continue
+		}
+		if line.CompanyID == nil {
+			continue
+		}
+		if id, ok := charts.CompanyCodes[*line.CompanyID][charts.Code[line.AccountID]]; ok {
+			line.AccountID = id
+		}
+	}
+	return nil
+}
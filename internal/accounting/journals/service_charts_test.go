@@ -0,0 +1,73 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+func TestPostJournalResolvesCompanyCharts(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	// Shared accounts 1 (1110) and 2 (4100); company 1 has its own 1110 as
+	// account 11, company 2 has its own 4100 as account 22.
+	charts := &AccountCharts{
+		Owner:        map[int64]int64{1: 0, 2: 0, 11: 1, 22: 2},
+		Code:         map[int64]string{1: "1110", 2: "4100", 11: "1110", 22: "4100"},
+		CompanyCodes: map[int64]map[string]int64{1: {"1110": 11}, 2: {"4100": 22}},
+	}
+	var posted []PostingLineInput
+	repo := stubRepo{
+		period: periods.Period{ID: 1, Status: periods.PeriodStatusOpen, StartDate: time.Now().Add(-time.Hour), EndDate: time.Now().Add(time.Hour)},
+		posted: &posted,
+		charts: charts,
+	}
+	service := NewService(repo, nil, nil)
+	posting := func(lines ...PostingLineInput) PostingInput {
+		return PostingInput{PeriodID: 1, Date: time.Now(), SourceModule: "TEST", SourceID: uuid.New(), PostedBy: 10, Lines: lines}
+	}
+
+	input := posting(
+		PostingLineInput{AccountID: 1, Debit: 50, CompanyID: id(1)},
+		PostingLineInput{AccountID: 2, Credit: 50, CompanyID: id(1)},
+	)
+	if _, err := service.PostJournal(context.Background(), input); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if posted[0].AccountID != 11 {
+		t.Fatalf("expected shared 1110 to resolve to company account 11, got %d", posted[0].AccountID)
+	}
+	if posted[1].AccountID != 2 {
+		t.Fatalf("expected 4100 to stay on the shared account, got %d", posted[1].AccountID)
+	}
+	if input.Lines[0].AccountID != 1 {
+		t.Fatalf("expected the caller's lines to be left untouched")
+	}
+
+	posted = nil
+	if _, err := service.PostJournal(context.Background(), posting(
+		PostingLineInput{AccountID: 22, Debit: 50},
+		PostingLineInput{AccountID: 1, Credit: 50},
+	)); err != nil {
+		t.Fatalf("post without company: %v", err)
+	}
+	if posted[0].CompanyID == nil || *posted[0].CompanyID != 2 {
+		t.Fatalf("expected company 2 to be stamped from its account")
+	}
+	if posted[1].AccountID != 1 || posted[1].CompanyID != nil {
+		t.Fatalf("expected a line without company to stay on the shared chart")
+	}
+
+	_, err := service.PostJournal(context.Background(), posting(
+		PostingLineInput{AccountID: 22, Debit: 50, CompanyID: id(1)},
+		PostingLineInput{AccountID: 1, Credit: 50, CompanyID: id(1)},
+	))
+	if !errors.Is(err, shared.ErrAccountCompanyMismatch) {
+		t.Fatalf("expected ErrAccountCompanyMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package journals
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+// MaxDimensionIssues caps the lines returned by the dimension issue report.
+const MaxDimensionIssues = 500
+
+// DimensionOwners maps branch dimensions to the company owning them and
+// warehouse dimensions to the branch owning them. IDs missing from the maps
+// do not exist.
+type DimensionOwners struct {
+	BranchCompany   map[int64]int64
+	WarehouseBranch map[int64]int64
+}
+
+// DimensionIssue is a posted journal line whose dimensions contradict the
+// company, branch and warehouse masters. Problem says how.
+type DimensionIssue struct {
+	LineID        int64     `json:"line_id"`
+	JournalID     int64     `json:"journal_id"`
+	JournalNumber int64     `json:"journal_number"`
+	Date          time.Time `json:"date"`
+	SourceModule  string    `json:"source_module"`
+	AccountCode   string    `json:"account_code"`
+	AccountName   string    `json:"account_name"`
+	Debit         float64   `json:"debit"`
+	Credit        float64   `json:"credit"`
+	CompanyID     *int64    `json:"company_id"`
+	BranchID      *int64    `json:"branch_id"`
+	WarehouseID   *int64    `json:"warehouse_id"`
+	Problem       string    `json:"problem"`
+}
+
+// DimensionIssueReport lists inconsistent posted lines, oldest first.
+// Truncated is set when more than MaxDimensionIssues lines are affected.
+type DimensionIssueReport struct {
+	Issues    []DimensionIssue `json:"issues"`
+	Truncated bool             `json:"truncated"`
+}
+
+// DimensionIssues reports posted journal lines whose branch does not belong
+// to their company, whose warehouse does not belong to their branch (or,
+// without a branch, to their company), or that point at a branch or
+// warehouse that no longer exists.
+func (s *Service) DimensionIssues(ctx context.Context) (DimensionIssueReport, error) {
+	issues, owners, err := s.repo.InconsistentDimensionLines(ctx, MaxDimensionIssues+1)
+	if err != nil {
+		return DimensionIssueReport{}, err
+	}
+	report := DimensionIssueReport{Issues: issues}
+	if len(report.Issues) > MaxDimensionIssues {
+		report.Issues, report.Truncated = report.Issues[:MaxDimensionIssues], true
+	}
+	for i := range report.Issues {
+		issue := &report.Issues[i]
+		issue.Problem = dimensionProblem(issue.CompanyID, issue.BranchID, issue.WarehouseID, owners)
+	}
+	return report, nil
+}
+
+// validateDimensions rejects lines whose dimensions are inconsistent with
+// the branch and warehouse masters.
+func (s *Service) validateDimensions(ctx context.Context, tx TxRepository, lines []PostingLineInput) error {
+	var branchIDs, warehouseIDs []int64
+	for _, line := range lines {
+		if line.BranchID != nil {
+			branchIDs = append(branchIDs, *line.BranchID)
+		}
+		if line.Warehouse != nil {
+			warehouseIDs = append(warehouseIDs, *line.Warehouse)
+		}
+	}
+	if len(branchIDs) == 0 && len(warehouseIDs) == 0 {
+		return nil
+	}
+	owners, err := tx.DimensionOwners(ctx, branchIDs, warehouseIDs)
+	if err != nil {
+		return err
+	}
+	return checkDimensions(lines, owners)
+}
+
+// checkDimensions returns ErrDimensionMismatch naming the first inconsistent
+// line, counting from 1.
+func checkDimensions(lines []PostingLineInput, owners DimensionOwners) error {
+	for i, line := range lines {
+		if problem := dimensionProblem(line.CompanyID, line.BranchID, line.Warehouse, owners); problem != "" {
+			return fmt.Errorf("%w: line %d: %s", shared.ErrDimensionMismatch, i+1, problem)
+		}
+	}
+	return nil
+}
+
+// dimensionProblem describes why the dimension combination is inconsistent,
+// or returns "" when it is not. Unset dimensions are not checked.
+func dimensionProblem(companyID, branchID, warehouseID *int64, owners DimensionOwners) string {
+	if branchID != nil {
+		company, ok := owners.BranchCompany[*branchID]
+		if !ok {
+			return fmt.Sprintf("branch %d does not exist", *branchID)
+		}
+		if companyID != nil && company != *companyID {
+			return fmt.Sprintf("branch %d belongs to company %d, not %d", *branchID, company, *companyID)
+		}
+	}
+	if warehouseID != nil {
+		branch, ok := owners.WarehouseBranch[*warehouseID]
+		if !ok {
+			return fmt.Sprintf("warehouse %d does not exist", *warehouseID)
+		}
+		if branchID != nil {
+			if branch != *branchID {
+				return fmt.Sprintf("warehouse %d belongs to branch %d, not %d", *warehouseID, branch, *branchID)
+			}
+		} else if companyID != nil {
+			if company := owners.BranchCompany[branch]; company != *companyID {
+				return fmt.Sprintf("warehouse %d belongs to company %d, not %d", *warehouseID, company, *companyID)
+			}
+		}
+	}
+	return ""
+}
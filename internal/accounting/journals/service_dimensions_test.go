@@ -0,0 +1,115 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+func dimensionOwners() *DimensionOwners {
+	// Company 1 owns branch 10 with warehouse 100; company 2 owns branch 20
+	// with warehouse 200.
+	return &DimensionOwners{
+		BranchCompany:   map[int64]int64{10: 1, 20: 2},
+		WarehouseBranch: map[int64]int64{100: 10, 200: 20},
+	}
+}
+
+func dimensionPosting(line PostingLineInput) PostingInput {
+	line.AccountID, line.Debit = 1, 50
+	return PostingInput{
+		PeriodID:     1,
+		Date:         time.Now(),
+		SourceModule: "TEST",
+		SourceID:     uuid.New(),
+		PostedBy:     10,
+		Lines:        []PostingLineInput{{AccountID: 2, Credit: 50}, line},
+	}
+}
+
+func TestPostJournalRejectsInconsistentDimensions(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	var posted []PostingLineInput
+	repo := stubRepo{
+		period: periods.Period{ID: 1, Status: periods.PeriodStatusOpen, StartDate: time.Now().Add(-time.Hour), EndDate: time.Now().Add(time.Hour)},
+		posted: &posted,
+		owners: dimensionOwners(),
+	}
+	service := NewService(repo, nil, nil)
+
+	cases := []struct {
+		line PostingLineInput
+		want string
+	}{
+		{PostingLineInput{CompanyID: id(1), BranchID: id(20)}, "line 2: branch 20 belongs to company 2, not 1"},
+		{PostingLineInput{BranchID: id(10), Warehouse: id(200)}, "line 2: warehouse 200 belongs to branch 20, not 10"},
+		{PostingLineInput{CompanyID: id(1), Warehouse: id(200)}, "line 2: warehouse 200 belongs to company 2, not 1"},
+		{PostingLineInput{CompanyID: id(1), BranchID: id(99)}, "line 2: branch 99 does not exist"},
+		{PostingLineInput{Warehouse: id(999)}, "line 2: warehouse 999 does not exist"},
+	}
+	for _, tc := range cases {
+		_, err := service.PostJournal(context.Background(), dimensionPosting(tc.line))
+		if !errors.Is(err, shared.ErrDimensionMismatch) {
+			t.Fatalf("expected ErrDimensionMismatch for %q, got %v", tc.want, err)
+		}
+		if !strings.HasSuffix(err.Error(), tc.want) {
+			t.Fatalf("expected error ending in %q, got %q", tc.want, err.Error())
+		}
+	}
+	if len(posted) != 0 {
+		t.Fatalf("no lines should be inserted, got %d", len(posted))
+	}
+
+	consistent := []PostingLineInput{
+		{CompanyID: id(1), BranchID: id(10), Warehouse: id(100)},
+		{CompanyID: id(2), Warehouse: id(200)},
+		{BranchID: id(20)},
+		{CompanyID: id(2)},
+	}
+	for _, line := range consistent {
+		if _, err := service.PostJournal(context.Background(), dimensionPosting(line)); err != nil {
+			t.Fatalf("expected %+v to post, got %v", line, err)
+		}
+	}
+}
+
+type dimensionIssueRepo struct {
+	stubRepo
+	issues []DimensionIssue
+	limit  int
+}
+
+func (r *dimensionIssueRepo) InconsistentDimensionLines(ctx context.Context, limit int) ([]DimensionIssue, DimensionOwners, error) {
+	r.limit = limit
+	return r.issues, *dimensionOwners(), nil
+}
+
+func TestDimensionIssuesDescribesEachLineAndTruncates(t *testing.T) {
+	company, branch := int64(1), int64(20)
+	repo := &dimensionIssueRepo{}
+	for i := 0; i <= MaxDimensionIssues; i++ {
+		repo.issues = append(repo.issues, DimensionIssue{LineID: int64(i + 1), CompanyID: &company, BranchID: &branch})
+	}
+	service := NewService(repo, nil, nil)
+
+	report, err := service.DimensionIssues(context.Background())
+	if err != nil {
+		t.Fatalf("dimension issues: %v", err)
+	}
+	if repo.limit != MaxDimensionIssues+1 {
+		t.Fatalf("expected the repository to be asked for one extra line, got limit %d", repo.limit)
+	}
+	if !report.Truncated || len(report.Issues) != MaxDimensionIssues {
+		t.Fatalf("expected %d issues and a truncation flag, got %d (truncated=%v)", MaxDimensionIssues, len(report.Issues), report.Truncated)
+	}
+	if got := report.Issues[0].Problem; got != "branch 20 belongs to company 2, not 1" {
+		t.Fatalf("unexpected problem %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package journals
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -15,21 +16,80 @@ import (
 )
 
 type stubRepo struct {
-	period periods.Period
+	period   periods.Period
+	accounts map[int64]AccountRef
+	balances []DimensionBalance
+	closing  []ClosingBalance
+	linked   int64
+	posted   *[]PostingLineInput
+	owners   *DimensionOwners
+	charts   *AccountCharts
+	pending  *PendingJournal
+	settings map[string]bool
 }
 
 func (r stubRepo) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
 	// In test stub we ignore the wrapper type for simplicity or mock it
-	return fn(ctx, stubTx{period: r.period})
+	return fn(ctx, stubTx{period: r.period, accounts: r.accounts, balances: r.balances, closing: r.closing, linked: r.linked, posted: r.posted, owners: r.owners, charts: r.charts, pending: r.pending, settings: r.settings})
 }
 
 func (r stubRepo) List(ctx context.Context) ([]JournalEntry, error) {
 	return nil, nil
 }
 
+func (r stubRepo) ListLines(ctx context.Context, req ListLinesRequest) ([]JournalLineItem, error) {
+	return nil, nil
+}
+
+func (r stubRepo) Search(ctx context.Context, req SearchRequest) ([]JournalSearchHit, int, error) {
+	return nil, 0, nil
+}
+
+func (r stubRepo) ListAccounts(ctx context.Context) ([]AccountRef, error) {
+	return nil, nil
+}
+
+func (r stubRepo) ListPeriods(ctx context.Context) ([]periods.Period, error) {
+	return nil, nil
+}
+
+func (r stubRepo) InconsistentDimensionLines(ctx context.Context, limit int) ([]DimensionIssue, DimensionOwners, error) {
+	return nil, DimensionOwners{}, nil
+}
+
+func (r stubRepo) PostingSettings(ctx context.Context, companyID int64) (map[string]bool, error) {
+	return r.settings, nil
+}
+
+func (r stubRepo) SavePostingSetting(ctx context.Context, setting PostingSetting, actorID int64) error {
+	r.settings[setting.Module] = setting.AutoPost
+	return nil
+}
+
+func (r stubRepo) InsertPendingJournal(ctx context.Context, p PendingJournal) (bool, error) {
+	if r.pending == nil {
+		return false, errors.New("not implemented")
+	}
+	*r.pending = p
+	return true, nil
+}
+
+func (r stubRepo) ListPendingJournals(ctx context.Context, filter PendingFilter) ([]PendingJournal, error) {
+	return nil, nil
+}
+
 // Ensure stubTx implements TxRepository
 type stubTx struct {
-	period periods.Period
+	period   periods.Period
+	accounts map[int64]AccountRef
+	balances []DimensionBalance
+	closing  []ClosingBalance
+	linked   int64
+	posted   *[]PostingLineInput
+	owners   *DimensionOwners
+	charts   *AccountCharts
+	pending  *PendingJournal
+	settings map[string]bool
 }
 
 func (tx stubTx) InsertJournalEntry(ctx context.Context, in PostingInput) (JournalEntry, error) {
@@ -46,6 +106,9 @@ func (tx stubTx) InsertJournalEntry(ctx context.Context, in PostingInput) (Journ
 }
 
 func (tx stubTx) InsertJournalLines(ctx context.Context, entryID int64, lines []PostingLineInput) error {
+	if tx.posted != nil {
+		*tx.posted = append(*tx.posted, lines...)
+	}
 	return nil
 }
 
@@ -62,6 +125,9 @@ func (tx stubTx) GetNextOpenPeriodAfter(ctx context.Context, date time.Time) (pe
 }
 
 func (tx stubTx) GetJournalWithLines(ctx context.Context, entryID int64) (JournalEntry, []JournalLine, error) {
+	if tx.linked != 0 && entryID == tx.linked {
+		return JournalEntry{ID: entryID, Number: 900 + entryID, Status: JournalStatusPosted}, nil, nil
+	}
 	return JournalEntry{}, nil, errors.New("not implemented")
 }
 
@@ -69,6 +135,62 @@ func (tx stubTx) UpdateJournalStatus(ctx context.Context, entryID int64, status
 	return nil
 }
 
+func (tx stubTx) GetAccount(ctx context.Context, accountID int64) (AccountRef, error) {
+	account, ok := tx.accounts[accountID]
+	if !ok {
+		return AccountRef{}, shared.ErrAccountNotFound
+	}
+	return account, nil
+}
+
+func (tx stubTx) AccountBalances(ctx context.Context, accountID, periodID int64, filter DimensionFilter) ([]DimensionBalance, error) {
+	return tx.balances, nil
+}
+
+func (tx stubTx) FindSourceLink(ctx context.Context, module string, ref uuid.UUID) (int64, error) {
+	return tx.linked, nil
+}
+
+func (tx stubTx) DimensionOwners(ctx context.Context, branchIDs, warehouseIDs []int64) (DimensionOwners, error) {
+	if tx.owners == nil {
+		return DimensionOwners{}, errors.New("not implemented")
+	}
+	return *tx.owners, nil
+}
+
+func (tx stubTx) AccountCharts(ctx context.Context, accountIDs, companyIDs []int64) (AccountCharts, error) {
+	if tx.charts == nil {
+		return AccountCharts{}, nil
+	}
+	return *tx.charts, nil
+}
+
+func (tx stubTx) IncomeStatementBalances(ctx context.Context, companyID int64, from, to time.Time) ([]ClosingBalance, error) {
+	return tx.closing, nil
+}
+
+func (tx stubTx) GetPendingJournalForUpdate(ctx context.Context, id int64) (PendingJournal, error) {
+	if tx.pending == nil || tx.pending.ID != id {
+		return PendingJournal{}, shared.ErrPendingJournalNotFound
+	}
+	return *tx.pending, nil
+}
+
+func (tx stubTx) FindPendingBySource(ctx context.Context, module string, ref uuid.UUID) (PendingJournal, error) {
+	if tx.pending == nil || tx.pending.Input.SourceModule != module || tx.pending.Input.SourceID != ref {
+		return PendingJournal{}, shared.ErrPendingJournalNotFound
+	}
+	return *tx.pending, nil
+}
+
+func (tx stubTx) DecidePendingJournal(ctx context.Context, id int64, decision PendingDecision) error {
+	tx.pending.Status = decision.Status
+	tx.pending.JournalEntryID = decision.JournalEntryID
+	tx.pending.DecidedBy = &decision.ActorID
+	tx.pending.DecisionNote = decision.Note
+	return nil
+}
+
 type stubGuard struct {
 	err error
 }
@@ -127,3 +249,43 @@ func TestPostJournalAllowsSoftClosedPeriod(t *testing.T) {
 	}
 }
 
+
+type recordingInvalidator struct {
+	calls []string
+}
+
+func (r *recordingInvalidator) InvalidatePeriod(ctx context.Context, companyID int64, period string) error {
+	r.calls = append(r.calls, fmt.Sprintf("%d:%s", companyID, period))
+	return nil
+}
+
+func TestPostJournalInvalidatesAnalyticsPerCompany(t *testing.T) {
+	date := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	repo := stubRepo{period: periods.Period{
+		ID:        1,
+		Status:    periods.PeriodStatusOpen,
+		StartDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+	}}
+	cache := &recordingInvalidator{}
+	service := NewService(repo, nil, nil)
+	service.SetCacheInvalidator(cache)
+	company := int64(4)
+	input := PostingInput{
+		PeriodID:     1,
+		Date:         date,
+		SourceModule: "TEST",
+		SourceID:     uuid.New(),
+		Lines: []PostingLineInput{
+			{AccountID: 1, Debit: 100, CompanyID: &company},
+			{AccountID: 2, Credit: 60, CompanyID: &company},
+			{AccountID: 3, Credit: 40},
+		},
+	}
+	if _, err := service.PostJournal(context.Background(), input); err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if len(cache.calls) != 2 || cache.calls[0] != "4:2025-03" || cache.calls[1] != "0:2025-03" {
+		t.Fatalf("unexpected invalidations %v", cache.calls)
+	}
+}
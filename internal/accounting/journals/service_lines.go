@@ -0,0 +1,80 @@
+package journals
+
+import (
+	"context"
+	"time"
+
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// DefaultLinesLimit is the page size used when ListLinesRequest.Limit is unset.
+const DefaultLinesLimit = 100
+
+// ListLinesRequest filters journal lines for the cursor-paginated listing.
+// Lines are ordered newest first by line id; Cursor resumes after the last
+// line of the previous page and takes precedence over Offset.
+type ListLinesRequest struct {
+	AccountID *int64
+	PeriodID  *int64
+	CompanyID *int64
+	BranchID  *int64
+	Limit     int
+	Offset    int
+	Cursor    string
+	// AfterID is the decoded cursor position; set by the service.
+	AfterID int64
+}
+
+// JournalLineItem is a posted journal line with its entry header.
+type JournalLineItem struct {
+	ID             int64     `json:"id"`
+	JournalID      int64     `json:"journal_id"`
+	JournalNumber  int64     `json:"journal_number"`
+	Date           time.Time `json:"date"`
+	PeriodID       int64     `json:"period_id"`
+	AccountID      int64     `json:"account_id"`
+	AccountCode    string    `json:"account_code"`
+	Debit          float64   `json:"debit"`
+	Credit         float64   `json:"credit"`
+	DimCompanyID   *int64    `json:"dim_company_id,omitempty"`
+	DimBranchID    *int64    `json:"dim_branch_id,omitempty"`
+	DimWarehouseID *int64    `json:"dim_warehouse_id,omitempty"`
+}
+
+// JournalLinePage is one page of journal lines. NextCursor is empty on the
+// last page.
+type JournalLinePage struct {
+	Lines      []JournalLineItem `json:"lines"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// ListLines returns journal lines using keyset pagination when a cursor is
+// supplied and offset pagination otherwise.
+func (s *Service) ListLines(ctx context.Context, req ListLinesRequest) (JournalLinePage, error) {
+	if req.Limit <= 0 {
+		req.Limit = DefaultLinesLimit
+	}
+	if req.Limit > internalShared.MaxListLimit {
+		req.Limit = internalShared.MaxListLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	if req.Cursor != "" {
+		cursor, err := internalShared.DecodeCursor(req.Cursor)
+		if err != nil {
+			return JournalLinePage{}, err
+		}
+		req.AfterID = cursor.ID
+		req.Offset = 0
+	}
+	lines, err := s.repo.ListLines(ctx, req)
+	if err != nil {
+		return JournalLinePage{}, err
+	}
+	page := JournalLinePage{Lines: lines}
+	if len(lines) == req.Limit {
+		page.NextCursor = internalShared.EncodeCursor(internalShared.Cursor{ID: lines[len(lines)-1].ID})
+	}
+	return page, nil
+}
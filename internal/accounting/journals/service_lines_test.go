@@ -0,0 +1,73 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type linesRepo struct {
+	stubRepo
+	lines []JournalLineItem
+	got   *ListLinesRequest
+}
+
+func (r linesRepo) ListLines(ctx context.Context, req ListLinesRequest) ([]JournalLineItem, error) {
+	*r.got = req
+	var out []JournalLineItem
+	for _, l := range r.lines {
+		if req.AfterID != 0 && l.ID >= req.AfterID {
+			continue
+		}
+		if len(out) == req.Limit {
+			break
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+func TestListLinesFollowsCursorAcrossPages(t *testing.T) {
+	var got ListLinesRequest
+	repo := linesRepo{
+		lines: []JournalLineItem{{ID: 5}, {ID: 4}, {ID: 3}},
+		got:   &got,
+	}
+	service := NewService(repo, nil, nil)
+
+	first, err := service.ListLines(context.Background(), ListLinesRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if len(first.Lines) != 2 || first.NextCursor == "" {
+		t.Fatalf("expected full first page with cursor, got %+v", first)
+	}
+
+	second, err := service.ListLines(context.Background(), ListLinesRequest{Limit: 2, Offset: 10, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if got.AfterID != 4 || got.Offset != 0 {
+		t.Fatalf("expected keyset after 4 without offset, got %+v", got)
+	}
+	if len(second.Lines) != 1 || second.Lines[0].ID != 3 || second.NextCursor != "" {
+		t.Fatalf("unexpected last page %+v", second)
+	}
+}
+
+func TestListLinesCapsLimitAndRejectsBadCursor(t *testing.T) {
+	var got ListLinesRequest
+	service := NewService(linesRepo{got: &got}, nil, nil)
+
+	if _, err := service.ListLines(context.Background(), ListLinesRequest{Limit: 5000}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if got.Limit != internalShared.MaxListLimit {
+		t.Fatalf("expected limit capped at %d, got %d", internalShared.MaxListLimit, got.Limit)
+	}
+	if _, err := service.ListLines(context.Background(), ListLinesRequest{Cursor: "???"}); !errors.Is(err, internalShared.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
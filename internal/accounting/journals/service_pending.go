@@ -0,0 +1,351 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// Integration modules whose journals can be staged for review. They match
+// the account mapping modules the integration hooks post with.
+const (
+	IntegrationModuleGRN       = "GRN"
+	IntegrationModuleAP        = "AP"
+	IntegrationModuleAR        = "AR"
+	IntegrationModuleInventory = "INVENTORY"
+	IntegrationModuleNetting   = "NETTING"
+)
+
+// IntegrationModules lists the modules with a posting setting, in display order.
+func IntegrationModules() []string {
+	return []string{IntegrationModuleGRN, IntegrationModuleAP, IntegrationModuleAR, IntegrationModuleInventory, IntegrationModuleNetting}
+}
+
+// PendingStatus enumerates review states of a staged integration journal.
+type PendingStatus string
+
+const (
+	PendingStatusPending  PendingStatus = "PENDING"
+	PendingStatusApproved PendingStatus = "APPROVED"
+	PendingStatusRejected PendingStatus = "REJECTED"
+)
+
+// SourceState is how far the integration journal of a source document got.
+// The zero value means the document was never journalled.
+type SourceState string
+
+const (
+	SourceStatePosted   SourceState = "POSTED"
+	SourceStatePending  SourceState = "PENDING"
+	SourceStateRejected SourceState = "REJECTED"
+)
+
+// PostingSetting controls whether a company's integration journals from one
+// module post straight to the ledger or wait for approval.
+type PostingSetting struct {
+	CompanyID int64
+	Module    string
+	AutoPost  bool
+}
+
+// PendingJournal is an integration journal staged for an accountant to
+// approve into the ledger or reject.
+type PendingJournal struct {
+	ID             int64
+	CompanyID      int64
+	Module         string
+	Input          PostingInput
+	Status         PendingStatus
+	JournalEntryID *int64
+	DecidedBy      *int64
+	DecidedAt      *time.Time
+	DecisionNote   string
+	CreatedAt      time.Time
+}
+
+// Total is the journal's debit total.
+func (p PendingJournal) Total() float64 {
+	var total float64
+	for _, line := range p.Input.Lines {
+		total += line.Debit
+	}
+	return total
+}
+
+// PendingDecision records the outcome of a pending journal review.
+type PendingDecision struct {
+	Status         PendingStatus
+	ActorID        int64
+	JournalEntryID *int64
+	Note           string
+	At             time.Time
+}
+
+// PendingFilter scopes the pending journal queue. An empty Status lists
+// journals awaiting review.
+type PendingFilter struct {
+	CompanyID int64
+	Status    PendingStatus
+	Limit     int
+}
+
+// AutoPostEnabled reports whether the company's integration journals from
+// module post straight to the ledger. Auto-posting is the default, also for
+// postings whose company is unknown.
+func (s *Service) AutoPostEnabled(ctx context.Context, companyID int64, module string) (bool, error) {
+	if companyID == 0 {
+		return true, nil
+	}
+	settings, err := s.repo.PostingSettings(ctx, companyID)
+	if err != nil {
+		return false, err
+	}
+	autoPost, ok := settings[module]
+	return autoPost || !ok, nil
+}
+
+// PostingSettings lists every integration module's setting for the company.
+func (s *Service) PostingSettings(ctx context.Context, companyID int64) ([]PostingSetting, error) {
+	saved, err := s.repo.PostingSettings(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PostingSetting, 0, len(IntegrationModules()))
+	for _, module := range IntegrationModules() {
+		autoPost, ok := saved[module]
+		out = append(out, PostingSetting{CompanyID: companyID, Module: module, AutoPost: autoPost || !ok})
+	}
+	return out, nil
+}
+
+// SetAutoPost switches auto-posting for one of the company's integration
+// modules. Journals already staged stay in the queue.
+func (s *Service) SetAutoPost(ctx context.Context, setting PostingSetting, actorID int64) error {
+	setting.Module = strings.ToUpper(strings.TrimSpace(setting.Module))
+	if !isIntegrationModule(setting.Module) {
+		return shared.ErrUnknownIntegrationModule
+	}
+	if setting.CompanyID == 0 || !internalShared.CompanyAllowed(ctx, setting.CompanyID) {
+		return internalShared.ErrForbidden
+	}
+	if err := s.repo.SavePostingSetting(ctx, setting, actorID); err != nil {
+		return err
+	}
+	if s.audit != nil {
+		_ = s.audit.Record(ctx, internalShared.AuditLog{
+			ActorID:  actorID,
+			Action:   "journal.posting_setting",
+			Entity:   "company",
+			EntityID: fmt.Sprintf("%d", setting.CompanyID),
+			Meta:     map[string]any{"module": setting.Module, "auto_post": setting.AutoPost},
+			At:       s.now(),
+		})
+	}
+	return nil
+}
+
+// StageJournal queues an integration posting for review instead of posting
+// it. Staging the same source again is a no-op, like posting it twice.
+func (s *Service) StageJournal(ctx context.Context, companyID int64, module string, input PostingInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	if companyID == 0 || !isIntegrationModule(module) {
+		return shared.ErrUnknownIntegrationModule
+	}
+	_, err := s.repo.InsertPendingJournal(ctx, PendingJournal{
+		CompanyID: companyID,
+		Module:    module,
+		Input:     input,
+		Status:    PendingStatusPending,
+	})
+	return err
+}
+
+// SourceState reports whether the journal of a source document reached the
+// ledger, waits in the review queue or was rejected from it. Integrations
+// check it before reversing a document's journal.
+func (s *Service) SourceState(ctx context.Context, module string, ref uuid.UUID) (SourceState, error) {
+	var state SourceState
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		entryID, err := tx.FindSourceLink(ctx, module, ref)
+		if err != nil {
+			return err
+		}
+		if entryID != 0 {
+			state = SourceStatePosted
+			return nil
+		}
+		pending, err := tx.FindPendingBySource(ctx, module, ref)
+		if errors.Is(err, shared.ErrPendingJournalNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch pending.Status {
+		case PendingStatusPending:
+			state = SourceStatePending
+		case PendingStatusRejected:
+			state = SourceStateRejected
+		}
+		return nil
+	})
+	return state, err
+}
+
+// CancelPendingSource rejects the staged journal of a source document that
+// was voided before anyone approved it, so the void has nothing to reverse.
+// It returns the journal's state afterwards: a journal approved in the
+// meantime is posted and still needs reversing.
+func (s *Service) CancelPendingSource(ctx context.Context, module string, ref uuid.UUID, reason string) (SourceState, error) {
+	var (
+		state     SourceState
+		cancelled int64
+	)
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		pending, err := tx.FindPendingBySource(ctx, module, ref)
+		if errors.Is(err, shared.ErrPendingJournalNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch pending.Status {
+		case PendingStatusApproved:
+			state = SourceStatePosted
+			return nil
+		case PendingStatusRejected:
+			state = SourceStateRejected
+			return nil
+		}
+		state, cancelled = SourceStateRejected, pending.ID
+		return tx.DecidePendingJournal(ctx, pending.ID, PendingDecision{
+			Status: PendingStatusRejected,
+			Note:   reason,
+			At:     s.now(),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	if cancelled != 0 {
+		s.auditPending(ctx, "journal.pending.cancel", cancelled, 0, map[string]any{"reason": reason})
+	}
+	return state, nil
+}
+
+// ListPending returns the company's staged journals, oldest first.
+func (s *Service) ListPending(ctx context.Context, filter PendingFilter) ([]PendingJournal, error) {
+	if !internalShared.CompanyAllowed(ctx, filter.CompanyID) {
+		return nil, nil
+	}
+	if filter.Status == "" {
+		filter.Status = PendingStatusPending
+	}
+	if filter.Limit <= 0 || filter.Limit > internalShared.MaxListLimit {
+		filter.Limit = internalShared.MaxListLimit
+	}
+	return s.repo.ListPendingJournals(ctx, filter)
+}
+
+// ApprovePending posts a staged journal to the ledger as the approver. The
+// posting is checked exactly like an auto-posted one, against the period it
+// was staged for.
+func (s *Service) ApprovePending(ctx context.Context, id, actorID int64) (JournalEntry, error) {
+	var (
+		entry JournalEntry
+		input PostingInput
+	)
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		pending, err := s.lockPending(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		input = pending.Input
+		input.PostedBy = actorID
+		if entry, err = s.postInTx(ctx, tx, input); err != nil {
+			return err
+		}
+		return tx.DecidePendingJournal(ctx, id, PendingDecision{
+			Status:         PendingStatusApproved,
+			ActorID:        actorID,
+			JournalEntryID: &entry.ID,
+			At:             s.now(),
+		})
+	})
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	s.recordPosting(ctx, input, entry)
+	s.auditPending(ctx, "journal.pending.approve", id, actorID, map[string]any{"journal_id": entry.ID, "number": entry.Number})
+	return entry, nil
+}
+
+// RejectPending discards a staged journal. Nothing reaches the ledger; the
+// source document stays unjournalled until corrected by a manual entry.
+func (s *Service) RejectPending(ctx context.Context, id, actorID int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return shared.ErrRejectReasonRequired
+	}
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		if _, err := s.lockPending(ctx, tx, id); err != nil {
+			return err
+		}
+		return tx.DecidePendingJournal(ctx, id, PendingDecision{
+			Status:  PendingStatusRejected,
+			ActorID: actorID,
+			Note:    reason,
+			At:      s.now(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.auditPending(ctx, "journal.pending.reject", id, actorID, map[string]any{"reason": reason})
+	return nil
+}
+
+func (s *Service) lockPending(ctx context.Context, tx TxRepository, id int64) (PendingJournal, error) {
+	pending, err := tx.GetPendingJournalForUpdate(ctx, id)
+	if err != nil {
+		return PendingJournal{}, err
+	}
+	if !internalShared.CompanyAllowed(ctx, pending.CompanyID) {
+		return PendingJournal{}, shared.ErrPendingJournalNotFound
+	}
+	if pending.Status != PendingStatusPending {
+		return PendingJournal{}, shared.ErrInvalidStatus
+	}
+	return pending, nil
+}
+
+func (s *Service) auditPending(ctx context.Context, action string, id, actorID int64, meta map[string]any) {
+	if s.audit == nil {
+		return
+	}
+	_ = s.audit.Record(ctx, internalShared.AuditLog{
+		ActorID:  actorID,
+		Action:   action,
+		Entity:   "pending_journal",
+		EntityID: fmt.Sprintf("%d", id),
+		Meta:     meta,
+		At:       s.now(),
+	})
+}
+
+func isIntegrationModule(module string) bool {
+	for _, m := range IntegrationModules() {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
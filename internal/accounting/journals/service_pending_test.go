@@ -0,0 +1,117 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+func pendingInput() PostingInput {
+	return PostingInput{
+		PeriodID:     1,
+		Date:         time.Now(),
+		SourceModule: "PROCUREMENT.GRN",
+		SourceID:     uuid.New(),
+		Memo:         "GRN 1",
+		Lines: []PostingLineInput{
+			{AccountID: 1, Debit: 150},
+			{AccountID: 2, Credit: 150},
+		},
+	}
+}
+
+func TestAutoPostEnabledDefaultsOn(t *testing.T) {
+	service := NewService(stubRepo{settings: map[string]bool{IntegrationModuleAP: false}}, nil, nil)
+	ctx := context.Background()
+
+	for module, want := range map[string]bool{IntegrationModuleAP: false, IntegrationModuleGRN: true} {
+		got, err := service.AutoPostEnabled(ctx, 7, module)
+		if err != nil {
+			t.Fatalf("AutoPostEnabled(%s): %v", module, err)
+		}
+		if got != want {
+			t.Fatalf("AutoPostEnabled(%s) = %v, want %v", module, got, want)
+		}
+	}
+	if got, _ := service.AutoPostEnabled(ctx, 0, IntegrationModuleAP); !got {
+		t.Fatalf("expected postings without a company to auto-post")
+	}
+	settings, err := service.PostingSettings(ctx, 7)
+	if err != nil {
+		t.Fatalf("PostingSettings: %v", err)
+	}
+	if len(settings) != len(IntegrationModules()) || !settings[0].AutoPost || settings[1].AutoPost {
+		t.Fatalf("unexpected settings %+v", settings)
+	}
+}
+
+func TestSetAutoPostRejectsUnknownModule(t *testing.T) {
+	service := NewService(stubRepo{settings: map[string]bool{}}, nil, nil)
+	err := service.SetAutoPost(context.Background(), PostingSetting{CompanyID: 7, Module: "PAYROLL"}, 3)
+	if !errors.Is(err, shared.ErrUnknownIntegrationModule) {
+		t.Fatalf("expected ErrUnknownIntegrationModule, got %v", err)
+	}
+}
+
+func TestApprovePendingPostsAsApprover(t *testing.T) {
+	pending := &PendingJournal{ID: 5, CompanyID: 7, Module: IntegrationModuleGRN, Input: pendingInput(), Status: PendingStatusPending}
+	var posted []PostingLineInput
+	repo := stubRepo{
+		period:  periods.Period{ID: 1, Status: periods.PeriodStatusOpen, StartDate: time.Now().Add(-time.Hour), EndDate: time.Now().Add(time.Hour)},
+		posted:  &posted,
+		pending: pending,
+	}
+	service := NewService(repo, nil, nil)
+
+	entry, err := service.ApprovePending(context.Background(), 5, 42)
+	if err != nil {
+		t.Fatalf("ApprovePending: %v", err)
+	}
+	if entry.PostedBy != 42 || entry.SourceModule != "PROCUREMENT.GRN" || len(posted) != 2 {
+		t.Fatalf("unexpected posting %+v lines %+v", entry, posted)
+	}
+	if pending.Status != PendingStatusApproved || pending.JournalEntryID == nil || *pending.JournalEntryID != entry.ID {
+		t.Fatalf("expected pending journal approved and linked, got %+v", pending)
+	}
+	if _, err := service.ApprovePending(context.Background(), 5, 42); !errors.Is(err, shared.ErrInvalidStatus) {
+		t.Fatalf("expected approving twice to fail with ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestRejectPendingRequiresReason(t *testing.T) {
+	pending := &PendingJournal{ID: 5, CompanyID: 7, Input: pendingInput(), Status: PendingStatusPending}
+	service := NewService(stubRepo{pending: pending}, nil, nil)
+
+	if err := service.RejectPending(context.Background(), 5, 42, "  "); !errors.Is(err, shared.ErrRejectReasonRequired) {
+		t.Fatalf("expected ErrRejectReasonRequired, got %v", err)
+	}
+	if err := service.RejectPending(context.Background(), 5, 42, "wrong warehouse"); err != nil {
+		t.Fatalf("RejectPending: %v", err)
+	}
+	if pending.Status != PendingStatusRejected || pending.DecisionNote != "wrong warehouse" || pending.JournalEntryID != nil {
+		t.Fatalf("unexpected rejected journal %+v", pending)
+	}
+}
+
+func TestStageJournalQueuesPosting(t *testing.T) {
+	staged := &PendingJournal{}
+	service := NewService(stubRepo{pending: staged}, nil, nil)
+	input := pendingInput()
+
+	if err := service.StageJournal(context.Background(), 7, IntegrationModuleGRN, input); err != nil {
+		t.Fatalf("StageJournal: %v", err)
+	}
+	if staged.CompanyID != 7 || staged.Status != PendingStatusPending || staged.Input.SourceID != input.SourceID {
+		t.Fatalf("unexpected staged journal %+v", staged)
+	}
+	input.Lines[1].Credit = 100
+	if err := service.StageJournal(context.Background(), 7, IntegrationModuleGRN, input); !errors.Is(err, shared.ErrUnbalanced) {
+		t.Fatalf("expected unbalanced staging to fail, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	closepkg "github.com/odyssey-erp/odyssey-erp/internal/close"
+)
+
+// errPreviewRollback ends the preview transaction without committing.
+var errPreviewRollback = errors.New("accounting: preview rollback")
+
+// AccountEffect is the net change a previewed journal makes to one account.
+// Balance figures are debit-minus-credit for the journal's period.
+type AccountEffect struct {
+	AccountID       int64   `json:"account_id"`
+	Code            string  `json:"code,omitempty"`
+	Name            string  `json:"name,omitempty"`
+	Type            string  `json:"type,omitempty"`
+	Debit           float64 `json:"debit"`
+	Credit          float64 `json:"credit"`
+	Net             float64 `json:"net"`
+	BalanceBefore   float64 `json:"balance_before"`
+	BalanceAfter    float64 `json:"balance_after"`
+	AccountResolved bool    `json:"account_resolved"`
+}
+
+// PostingPreview is the outcome of a dry-run posting. Valid is true when
+// PostJournal would accept the same input.
+type PostingPreview struct {
+	Valid       bool            `json:"valid"`
+	Balanced    bool            `json:"balanced"`
+	TotalDebit  float64         `json:"total_debit"`
+	TotalCredit float64         `json:"total_credit"`
+	PeriodCode  string          `json:"period_code,omitempty"`
+	Accounts    []AccountEffect `json:"accounts"`
+	Errors      []string        `json:"errors"`
+}
+
+// PreviewJournal runs the checks PostJournal would and reports the net effect
+// per account without writing anything. Validation problems are collected in
+// the preview; the error result is reserved for infrastructure failures.
+func (s *Service) PreviewJournal(ctx context.Context, input PostingInput) (PostingPreview, error) {
+	preview := PostingPreview{Accounts: []AccountEffect{}, Errors: []string{}}
+	if err := input.Validate(); err != nil {
+		preview.Errors = append(preview.Errors, err.Error())
+	}
+	for _, line := range input.Lines {
+		preview.TotalDebit += line.Debit
+		preview.TotalCredit += line.Credit
+	}
+	preview.TotalDebit = math.Round(preview.TotalDebit*100) / 100
+	preview.TotalCredit = math.Round(preview.TotalCredit*100) / 100
+	preview.Balanced = len(input.Lines) > 0 && preview.TotalDebit == preview.TotalCredit
+
+	var effects map[int64]*AccountEffect
+	var order []int64
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		periodOK := false
+		if input.PeriodID != 0 {
+			msg, err := s.previewPeriod(ctx, tx, input, &preview)
+			if err != nil {
+				return err
+			}
+			if msg != "" {
+				preview.Errors = append(preview.Errors, msg)
+			} else {
+				periodOK = true
+			}
+		}
+		// Resolve a copy so the effects show the company-chart accounts
+		// PostJournal would post to, as it does inside its transaction.
+		lines := append([]PostingLineInput(nil), input.Lines...)
+		if err := s.resolveCompanyAccounts(ctx, tx, lines); err != nil {
+			if !errors.Is(err, shared.ErrAccountCompanyMismatch) {
+				return err
+			}
+			preview.Errors = append(preview.Errors, err.Error())
+			lines = input.Lines
+		}
+		if err := s.validateDimensions(ctx, tx, lines); err != nil {
+			if !errors.Is(err, shared.ErrDimensionMismatch) {
+				return err
+			}
+			preview.Errors = append(preview.Errors, err.Error())
+		}
+		effects, order = accountEffects(lines)
+		for _, id := range order {
+			effect := effects[id]
+			account, err := tx.GetAccount(ctx, effect.AccountID)
+			switch {
+			case errors.Is(err, shared.ErrAccountNotFound):
+				preview.Errors = append(preview.Errors, fmt.Sprintf("accounting: account %d not found", effect.AccountID))
+			case err != nil:
+				return err
+			default:
+				effect.Code, effect.Name, effect.Type = account.Code, account.Name, account.Type
+				effect.AccountResolved = true
+				if !account.IsActive {
+					preview.Errors = append(preview.Errors, fmt.Sprintf("accounting: account %s is inactive", account.Code))
+				}
+			}
+			effect.Net = math.Round((effect.Debit-effect.Credit)*100) / 100
+			if effect.AccountResolved && periodOK {
+				balances, err := tx.AccountBalances(ctx, effect.AccountID, input.PeriodID, DimensionFilter{})
+				if err != nil {
+					return err
+				}
+				for _, b := range balances {
+					effect.BalanceBefore += b.Balance
+				}
+				effect.BalanceBefore = math.Round(effect.BalanceBefore*100) / 100
+			}
+			effect.BalanceAfter = math.Round((effect.BalanceBefore+effect.Net)*100) / 100
+		}
+		return errPreviewRollback
+	})
+	if err != nil && !errors.Is(err, errPreviewRollback) {
+		return PostingPreview{}, err
+	}
+
+	for _, id := range order {
+		preview.Accounts = append(preview.Accounts, *effects[id])
+	}
+	sort.Slice(preview.Accounts, func(i, j int) bool {
+		a, b := preview.Accounts[i], preview.Accounts[j]
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.AccountID < b.AccountID
+	})
+	preview.Valid = len(preview.Errors) == 0
+	return preview, nil
+}
+
+// accountEffects sums the lines per account, keeping the order in which the
+// accounts first appear. Lines without an account are skipped.
+func accountEffects(lines []PostingLineInput) (map[int64]*AccountEffect, []int64) {
+	effects := map[int64]*AccountEffect{}
+	var order []int64
+	for _, line := range lines {
+		if line.AccountID == 0 {
+			continue
+		}
+		effect, ok := effects[line.AccountID]
+		if !ok {
+			effect = &AccountEffect{AccountID: line.AccountID}
+			effects[line.AccountID] = effect
+			order = append(order, line.AccountID)
+		}
+		effect.Debit += line.Debit
+		effect.Credit += line.Credit
+	}
+	return effects, order
+}
+
+// previewPeriod applies PostJournal's period checks and returns the message
+// for the first one that fails.
+func (s *Service) previewPeriod(ctx context.Context, tx TxRepository, input PostingInput, preview *PostingPreview) (string, error) {
+	if s.guard != nil {
+		if err := s.guard.EnsurePeriodOpenForPosting(ctx, input.PeriodID); err != nil {
+			if errors.Is(err, closepkg.ErrPeriodHardClosed) {
+				return shared.ErrPeriodLocked.Error(), nil
+			}
+			return err.Error(), nil
+		}
+	}
+	period, err := tx.GetPeriodForUpdate(ctx, input.PeriodID)
+	if errors.Is(err, shared.ErrInvalidPeriod) {
+		return err.Error(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	preview.PeriodCode = period.Code
+	if period.Status == periods.PeriodStatusLocked {
+		return shared.ErrPeriodLocked.Error(), nil
+	}
+	if period.Status != periods.PeriodStatusOpen && period.Status != periods.PeriodStatusClosed {
+		return shared.ErrInvalidPeriod.Error(), nil
+	}
+	if input.Date.Before(period.StartDate) || input.Date.After(period.EndDate) {
+		return shared.ErrDateOutOfRange.Error(), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,162 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+func TestPreviewJournalReportsNetEffectWithoutPosting(t *testing.T) {
+	var posted []PostingLineInput
+	repo := reclassRepo(periods.PeriodStatusOpen, "EXPENSE", []DimensionBalance{{Balance: 100}, {Balance: 25}}, &posted)
+	service := NewService(repo, nil, nil)
+
+	preview, err := service.PreviewJournal(context.Background(), PostingInput{
+		PeriodID:     1,
+		Date:         time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+		SourceModule: ManualSourceModule,
+		SourceID:     uuid.New(),
+		Lines: []PostingLineInput{
+			{AccountID: 20, Debit: 60},
+			{AccountID: 20, Debit: 40},
+			{AccountID: 10, Credit: 100},
+		},
+	})
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if !preview.Valid || !preview.Balanced || len(preview.Errors) != 0 {
+		t.Fatalf("expected valid balanced preview, got %+v", preview)
+	}
+	if len(posted) != 0 {
+		t.Fatalf("preview must not post lines, got %+v", posted)
+	}
+	if len(preview.Accounts) != 2 || preview.Accounts[0].Code != "6100" || preview.Accounts[1].Code != "6200" {
+		t.Fatalf("unexpected accounts: %+v", preview.Accounts)
+	}
+	source, target := preview.Accounts[0], preview.Accounts[1]
+	if source.Net != -100 || source.BalanceBefore != 125 || source.BalanceAfter != 25 {
+		t.Fatalf("unexpected source effect: %+v", source)
+	}
+	if target.Net != 100 || target.BalanceAfter != 225 {
+		t.Fatalf("unexpected target effect: %+v", target)
+	}
+}
+
+func TestPreviewJournalCollectsValidationErrors(t *testing.T) {
+	repo := reclassRepo(periods.PeriodStatusOpen, "EXPENSE", nil, nil)
+	service := NewService(repo, nil, nil)
+
+	preview, err := service.PreviewJournal(context.Background(), PostingInput{
+		PeriodID:     1,
+		Date:         time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		SourceModule: ManualSourceModule,
+		SourceID:     uuid.New(),
+		Lines: []PostingLineInput{
+			{AccountID: 10, Debit: 50},
+			{AccountID: 99, Credit: 40},
+		},
+	})
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Valid || preview.Balanced {
+		t.Fatalf("expected invalid unbalanced preview, got %+v", preview)
+	}
+	// Unbalanced lines, a date outside September and an unknown account.
+	if len(preview.Errors) != 3 {
+		t.Fatalf("expected 3 errors, got %v", preview.Errors)
+	}
+	if preview.Accounts[0].AccountID != 99 || preview.Accounts[0].AccountResolved {
+		t.Fatalf("account 99 should sort first unresolved: %+v", preview.Accounts[0])
+	}
+}
+
+func TestPreviewJournalResolvesCompanyCharts(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	repo := reclassRepo(periods.PeriodStatusOpen, "EXPENSE", nil, nil)
+	// Company 1 keeps its own 6100 as account 11.
+	repo.accounts[11] = AccountRef{ID: 11, Code: "6100", Name: "Office Supplies (Co 1)", Type: "EXPENSE", IsActive: true}
+	repo.charts = &AccountCharts{
+		Owner:        map[int64]int64{10: 0, 20: 0, 11: 1},
+		Code:         map[int64]string{10: "6100", 20: "6200", 11: "6100"},
+		CompanyCodes: map[int64]map[string]int64{1: {"6100": 11}},
+	}
+	service := NewService(repo, nil, nil)
+	posting := func(lines ...PostingLineInput) PostingInput {
+		return PostingInput{
+			PeriodID:     1,
+			Date:         time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+			SourceModule: ManualSourceModule,
+			SourceID:     uuid.New(),
+			Lines:        lines,
+		}
+	}
+
+	input := posting(
+		PostingLineInput{AccountID: 10, Debit: 30, CompanyID: id(1)},
+		PostingLineInput{AccountID: 20, Credit: 30, CompanyID: id(1)},
+	)
+	preview, err := service.PreviewJournal(context.Background(), input)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if !preview.Valid {
+		t.Fatalf("expected valid preview, got %v", preview.Errors)
+	}
+	if len(preview.Accounts) != 2 || preview.Accounts[0].AccountID != 11 || preview.Accounts[1].AccountID != 20 {
+		t.Fatalf("expected effects on company account 11 and shared account 20, got %+v", preview.Accounts)
+	}
+	if input.Lines[0].AccountID != 10 {
+		t.Fatalf("expected the caller's lines to be left untouched")
+	}
+
+	preview, err = service.PreviewJournal(context.Background(), posting(
+		PostingLineInput{AccountID: 11, Debit: 30, CompanyID: id(2)},
+		PostingLineInput{AccountID: 20, Credit: 30, CompanyID: id(2)},
+	))
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Valid || len(preview.Errors) != 1 || !strings.Contains(preview.Errors[0], shared.ErrAccountCompanyMismatch.Error()) {
+		t.Fatalf("expected a company mismatch error, got %v", preview.Errors)
+	}
+}
+
+func TestPreviewJournalAgreesWithPostOnDimensions(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	var posted []PostingLineInput
+	repo := reclassRepo(periods.PeriodStatusOpen, "EXPENSE", nil, &posted)
+	repo.owners = dimensionOwners()
+	service := NewService(repo, nil, nil)
+	// Branch 20 belongs to company 2, so PostJournal rejects the debit line.
+	input := PostingInput{
+		PeriodID:     1,
+		Date:         time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+		SourceModule: ManualSourceModule,
+		SourceID:     uuid.New(),
+		PostedBy:     10,
+		Lines: []PostingLineInput{
+			{AccountID: 20, Debit: 30, CompanyID: id(1), BranchID: id(20)},
+			{AccountID: 10, Credit: 30, CompanyID: id(1)},
+		},
+	}
+
+	preview, err := service.PreviewJournal(context.Background(), input)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	_, postErr := service.PostJournal(context.Background(), input)
+	if !errors.Is(postErr, shared.ErrDimensionMismatch) {
+		t.Fatalf("expected PostJournal to reject the dimensions, got %v", postErr)
+	}
+	if preview.Valid || len(preview.Errors) != 1 || preview.Errors[0] != postErr.Error() {
+		t.Fatalf("expected the preview to report %q, got %v", postErr, preview.Errors)
+	}
+}
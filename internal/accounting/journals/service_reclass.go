@@ -0,0 +1,211 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	closepkg "github.com/odyssey-erp/odyssey-erp/internal/close"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ReclassSourceModule tags journals posted by the reclassification tool.
+const ReclassSourceModule = "ACCOUNTING:RECLASS"
+
+// ReclassResult describes a posted reclassification.
+type ReclassResult struct {
+	Entry  JournalEntry
+	Source AccountRef
+	Target AccountRef
+	Period periods.Period
+	Amount float64
+}
+
+// Reclassify moves the net balance of the source account in the period to the
+// target account with a single balanced journal. Balances are moved per
+// dimension combination so branch and warehouse reporting stays intact.
+// Accounts of different types are only reclassified when Override is set.
+func (s *Service) Reclassify(ctx context.Context, input ReclassInput) (ReclassResult, error) {
+	if err := input.Validate(); err != nil {
+		return ReclassResult{}, err
+	}
+	var result ReclassResult
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		if s.guard != nil {
+			if err := s.guard.EnsurePeriodOpenForPosting(ctx, input.PeriodID); err != nil {
+				if errors.Is(err, closepkg.ErrPeriodHardClosed) {
+					return shared.ErrPeriodLocked
+				}
+				return err
+			}
+		}
+		period, err := tx.GetPeriodForUpdate(ctx, input.PeriodID)
+		if err != nil {
+			return err
+		}
+		if period.Status == periods.PeriodStatusLocked {
+			return shared.ErrPeriodLocked
+		}
+		if period.Status != periods.PeriodStatusOpen && period.Status != periods.PeriodStatusClosed {
+			return shared.ErrInvalidPeriod
+		}
+		date := period.EndDate
+		if input.Date != nil {
+			date = *input.Date
+		}
+		if date.Before(period.StartDate) || date.After(period.EndDate) {
+			return shared.ErrDateOutOfRange
+		}
+
+		source, err := tx.GetAccount(ctx, input.SourceAccountID)
+		if err != nil {
+			return err
+		}
+		target, err := tx.GetAccount(ctx, input.TargetAccountID)
+		if err != nil {
+			return err
+		}
+		if source.Type != target.Type && !input.Override {
+			return fmt.Errorf("%w (%s %s, %s %s)", shared.ErrReclassTypeMismatch, source.Code, source.Type, target.Code, target.Type)
+		}
+
+		balances, err := tx.AccountBalances(ctx, source.ID, period.ID, input.Dimensions)
+		if err != nil {
+			return err
+		}
+		lines, amount := reclassLines(source.ID, target.ID, balances)
+		if len(lines) == 0 {
+			return shared.ErrReclassNothingToMove
+		}
+		if err := checkReclassChart(target, lines); err != nil {
+			return err
+		}
+
+		posting := PostingInput{
+			PeriodID:     period.ID,
+			Date:         date,
+			SourceModule: ReclassSourceModule,
+			SourceID:     uuid.New(),
+			Memo:         reclassMemo(input.Memo, source, target, period, amount),
+			PostedBy:     input.ActorID,
+			Lines:        lines,
+		}
+		if err := posting.Validate(); err != nil {
+			return err
+		}
+		inserted, err := tx.InsertJournalEntry(ctx, posting)
+		if err != nil {
+			return err
+		}
+		if err := tx.InsertJournalLines(ctx, inserted.ID, posting.Lines); err != nil {
+			return err
+		}
+		if err := tx.LinkSource(ctx, posting.SourceModule, posting.SourceID, inserted.ID); err != nil {
+			return err
+		}
+		inserted.Lines = toJournalLines(inserted.ID, posting.Lines, s.now())
+		result = ReclassResult{Entry: inserted, Source: source, Target: target, Period: period, Amount: amount}
+		return nil
+	})
+	if err != nil {
+		return ReclassResult{}, err
+	}
+	s.invalidateCaches(ctx, result.Entry.Date, result.Entry.Lines)
+	if s.audit != nil {
+		_ = s.audit.Record(ctx, internalShared.AuditLog{
+			ActorID:  input.ActorID,
+			Action:   "journal.reclassify",
+			Entity:   "journal_entry",
+			EntityID: fmt.Sprintf("%d", result.Entry.ID),
+			Meta: map[string]any{
+				"number":         result.Entry.Number,
+				"source_account": result.Source.Code,
+				"target_account": result.Target.Code,
+				"period":         result.Period.Code,
+				"amount":         result.Amount,
+				"override":       input.Override && result.Source.Type != result.Target.Type,
+				"dimensions":     dimensionMeta(input.Dimensions),
+			},
+			At: s.now(),
+		})
+	}
+	return result, nil
+}
+
+// reclassLines builds a pair of lines per dimension combination that clears
+// the source balance into the target. The returned amount is the net moved,
+// positive for a debit balance and negative for a credit balance.
+func reclassLines(sourceID, targetID int64, balances []DimensionBalance) ([]PostingLineInput, float64) {
+	var lines []PostingLineInput
+	var net float64
+	for _, bal := range balances {
+		amount := math.Round(bal.Balance*100) / 100
+		if amount == 0 {
+			continue
+		}
+		src := PostingLineInput{AccountID: sourceID, CompanyID: bal.CompanyID, BranchID: bal.BranchID, Warehouse: bal.WarehouseID}
+		dst := PostingLineInput{AccountID: targetID, CompanyID: bal.CompanyID, BranchID: bal.BranchID, Warehouse: bal.WarehouseID}
+		if amount > 0 {
+			src.Credit, dst.Debit = amount, amount
+		} else {
+			src.Debit, dst.Credit = -amount, -amount
+		}
+		lines = append(lines, src, dst)
+		net += amount
+	}
+	return lines, math.Round(net*100) / 100
+}
+
+func reclassMemo(memo string, source, target AccountRef, period periods.Period, amount float64) string {
+	base := fmt.Sprintf("Reclass %s %s to %s %s for %s (%.2f)", source.Code, source.Name, target.Code, target.Name, period.Code, amount)
+	if memo == "" {
+		return base
+	}
+	return base + ": " + memo
+}
+
+func dimensionMeta(filter DimensionFilter) map[string]any {
+	meta := map[string]any{}
+	if filter.CompanyID != nil {
+		meta["company_id"] = *filter.CompanyID
+	}
+	if filter.BranchID != nil {
+		meta["branch_id"] = *filter.BranchID
+	}
+	if filter.WarehouseID != nil {
+		meta["warehouse_id"] = *filter.WarehouseID
+	}
+	return meta
+}
+
+// ReclassOptions returns the active accounts and periods offered by the
+// reclassification form.
+func (s *Service) ReclassOptions(ctx context.Context) ([]AccountRef, []periods.Period, error) {
+	accounts, err := s.repo.ListAccounts(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	list, err := s.repo.ListPeriods(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return accounts, list, nil
+}
+
+// checkReclassChart rejects moving another company's balance onto an account
+// of a company chart.
+func checkReclassChart(target AccountRef, lines []PostingLineInput) error {
+	if target.CompanyID == 0 {
+		return nil
+	}
+	for _, line := range lines {
+		if line.AccountID == target.ID && (line.CompanyID == nil || *line.CompanyID != target.CompanyID) {
+			return fmt.Errorf("%w: %s belongs to company %s", shared.ErrAccountCompanyMismatch, target.Code, target.CompanyCode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+func reclassRepo(status periods.PeriodStatus, targetType string, balances []DimensionBalance, posted *[]PostingLineInput) stubRepo {
+	return stubRepo{
+		period: periods.Period{
+			ID:        1,
+			Code:      "2026-09",
+			Status:    status,
+			StartDate: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		},
+		accounts: map[int64]AccountRef{
+			10: {ID: 10, Code: "6100", Name: "Office Supplies", Type: "EXPENSE", IsActive: true},
+			20: {ID: 20, Code: "6200", Name: "IT Supplies", Type: targetType, IsActive: true},
+		},
+		balances: balances,
+		posted:   posted,
+	}
+}
+
+func TestReclassifyMovesNetBalancePerDimension(t *testing.T) {
+	branch := int64(3)
+	var posted []PostingLineInput
+	repo := reclassRepo(periods.PeriodStatusOpen, "EXPENSE", []DimensionBalance{
+		{Balance: 150},
+		{BranchID: &branch, Balance: -40},
+		{Balance: 0.001},
+	}, &posted)
+	service := NewService(repo, nil, nil)
+
+	result, err := service.Reclassify(context.Background(), ReclassInput{
+		SourceAccountID: 10,
+		TargetAccountID: 20,
+		PeriodID:        1,
+		ActorID:         7,
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if result.Amount != 110 {
+		t.Fatalf("expected net 110, got %v", result.Amount)
+	}
+	if !result.Entry.Date.Equal(repo.period.EndDate) {
+		t.Fatalf("expected period end date, got %v", result.Entry.Date)
+	}
+	if result.Entry.SourceModule != ReclassSourceModule {
+		t.Fatalf("unexpected source module %q", result.Entry.SourceModule)
+	}
+	want := []PostingLineInput{
+		{AccountID: 10, Credit: 150},
+		{AccountID: 20, Debit: 150},
+		{AccountID: 10, Debit: 40, BranchID: &branch},
+		{AccountID: 20, Credit: 40, BranchID: &branch},
+	}
+	if len(posted) != len(want) {
+		t.Fatalf("expected %d lines, got %d", len(want), len(posted))
+	}
+	for i, line := range posted {
+		if line.AccountID != want[i].AccountID || line.Debit != want[i].Debit || line.Credit != want[i].Credit || line.BranchID != want[i].BranchID {
+			t.Fatalf("line %d = %+v, want %+v", i, line, want[i])
+		}
+	}
+}
+
+func TestReclassifyRejectsTypeMismatchWithoutOverride(t *testing.T) {
+	repo := reclassRepo(periods.PeriodStatusOpen, "ASSET", []DimensionBalance{{Balance: 100}}, nil)
+	service := NewService(repo, nil, nil)
+	input := ReclassInput{SourceAccountID: 10, TargetAccountID: 20, PeriodID: 1}
+
+	if _, err := service.Reclassify(context.Background(), input); !errors.Is(err, shared.ErrReclassTypeMismatch) {
+		t.Fatalf("expected ErrReclassTypeMismatch, got %v", err)
+	}
+	input.Override = true
+	if _, err := service.Reclassify(context.Background(), input); err != nil {
+		t.Fatalf("expected override to succeed, got %v", err)
+	}
+}
+
+func TestReclassifyRespectsPeriodLock(t *testing.T) {
+	repo := reclassRepo(periods.PeriodStatusLocked, "EXPENSE", []DimensionBalance{{Balance: 100}}, nil)
+	service := NewService(repo, nil, nil)
+	input := ReclassInput{SourceAccountID: 10, TargetAccountID: 20, PeriodID: 1}
+	if _, err := service.Reclassify(context.Background(), input); !errors.Is(err, shared.ErrPeriodLocked) {
+		t.Fatalf("expected ErrPeriodLocked, got %v", err)
+	}
+}
+
+func TestReclassifyRejectsEmptyBalance(t *testing.T) {
+	repo := reclassRepo(periods.PeriodStatusOpen, "EXPENSE", nil, nil)
+	service := NewService(repo, nil, nil)
+	input := ReclassInput{SourceAccountID: 10, TargetAccountID: 20, PeriodID: 1}
+	if _, err := service.Reclassify(context.Background(), input); !errors.Is(err, shared.ErrReclassNothingToMove) {
+		t.Fatalf("expected ErrReclassNothingToMove, got %v", err)
+	}
+	input.TargetAccountID = 10
+	if _, err := service.Reclassify(context.Background(), input); !errors.Is(err, shared.ErrReclassSameAccount) {
+		t.Fatalf("expected ErrReclassSameAccount, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package journals
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+const (
+	// DefaultSearchPerPage is the page size used when SearchRequest.PerPage is
+	// unset.
+	DefaultSearchPerPage = 25
+	// MaxSearchPerPage caps the journals returned per search page.
+	MaxSearchPerPage = 100
+)
+
+// SearchRequest filters journal entries. All filters combine with AND; zero
+// values match everything and To is inclusive. AccountID and the amount
+// range are line filters: an entry matches when one of its lines satisfies
+// all of them, and those lines are flagged as matched.
+type SearchRequest struct {
+	From         time.Time
+	To           time.Time
+	SourceModule string
+	AccountID    *int64
+	// MinAmount and MaxAmount bound a line's debit or credit amount.
+	MinAmount *float64
+	MaxAmount *float64
+	// Memo matches the entry memo case-insensitively.
+	Memo    string
+	Status  JournalStatus
+	Page    int
+	PerPage int
+}
+
+// HasLineFilters reports whether the search narrows individual lines.
+func (r SearchRequest) HasLineFilters() bool {
+	return r.AccountID != nil || r.MinAmount != nil || r.MaxAmount != nil
+}
+
+// JournalSearchLine is one line of a journal found by the search. Matched is
+// set on the lines that satisfy the line filters.
+type JournalSearchLine struct {
+	ID          int64   `json:"id"`
+	AccountID   int64   `json:"account_id"`
+	AccountCode string  `json:"account_code"`
+	AccountName string  `json:"account_name"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	Matched     bool    `json:"matched"`
+}
+
+// JournalSearchHit is a journal entry found by the search with all its lines.
+type JournalSearchHit struct {
+	ID           int64               `json:"id"`
+	Number       int64               `json:"number"`
+	PeriodID     int64               `json:"period_id"`
+	Date         time.Time           `json:"date"`
+	SourceModule string              `json:"source_module"`
+	Memo         string              `json:"memo"`
+	Status       JournalStatus       `json:"status"`
+	TotalDebit   float64             `json:"total_debit"`
+	Lines        []JournalSearchLine `json:"lines"`
+}
+
+// JournalSearchPage is one page of search results, newest journal first.
+type JournalSearchPage struct {
+	Entries    []JournalSearchHit `json:"entries"`
+	Total      int                `json:"total"`
+	Page       int                `json:"page"`
+	PerPage    int                `json:"per_page"`
+	TotalPages int                `json:"total_pages"`
+}
+
+// Search finds journal entries by date, source module, status, memo text,
+// account and line amount.
+func (s *Service) Search(ctx context.Context, req SearchRequest) (JournalSearchPage, error) {
+	req.SourceModule = strings.ToUpper(strings.TrimSpace(req.SourceModule))
+	req.Memo = strings.TrimSpace(req.Memo)
+	req.Status = JournalStatus(strings.ToUpper(strings.TrimSpace(string(req.Status))))
+	if req.Status != "" && req.Status != JournalStatusPosted && req.Status != JournalStatusVoid {
+		return JournalSearchPage{}, fmt.Errorf("%w: unknown status %q", shared.ErrInvalidSearch, req.Status)
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		return JournalSearchPage{}, fmt.Errorf("%w: date range ends before it starts", shared.ErrInvalidSearch)
+	}
+	if (req.MinAmount != nil && *req.MinAmount < 0) || (req.MaxAmount != nil && *req.MaxAmount < 0) {
+		return JournalSearchPage{}, fmt.Errorf("%w: amounts cannot be negative", shared.ErrInvalidSearch)
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MaxAmount < *req.MinAmount {
+		return JournalSearchPage{}, fmt.Errorf("%w: maximum amount is below the minimum", shared.ErrInvalidSearch)
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage <= 0 {
+		req.PerPage = DefaultSearchPerPage
+	}
+	if req.PerPage > MaxSearchPerPage {
+		req.PerPage = MaxSearchPerPage
+	}
+	entries, total, err := s.repo.Search(ctx, req)
+	if err != nil {
+		return JournalSearchPage{}, err
+	}
+	return JournalSearchPage{
+		Entries:    entries,
+		Total:      total,
+		Page:       req.Page,
+		PerPage:    req.PerPage,
+		TotalPages: (total + req.PerPage - 1) / req.PerPage,
+	}, nil
+}
+
+// SearchAccounts returns the accounts offered by the search form.
+func (s *Service) SearchAccounts(ctx context.Context) ([]AccountRef, error) {
+	return s.repo.ListAccounts(ctx)
+}
@@ -0,0 +1,77 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+type searchRepo struct {
+	stubRepo
+	got   *SearchRequest
+	total int
+}
+
+func (r searchRepo) Search(ctx context.Context, req SearchRequest) ([]JournalSearchHit, int, error) {
+	*r.got = req
+	return []JournalSearchHit{{ID: 1, Lines: []JournalSearchLine{{ID: 10, Matched: true}, {ID: 11}}}}, r.total, nil
+}
+
+func TestSearchNormalizesFiltersAndPages(t *testing.T) {
+	var got SearchRequest
+	service := NewService(searchRepo{got: &got, total: 230}, nil, nil)
+
+	accountID := int64(7)
+	page, err := service.Search(context.Background(), SearchRequest{
+		SourceModule: " ar ",
+		Status:       "posted",
+		Memo:         "  rent ",
+		AccountID:    &accountID,
+		PerPage:      500,
+		Page:         -1,
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got.SourceModule != "AR" || got.Status != JournalStatusPosted || got.Memo != "rent" {
+		t.Fatalf("expected normalized filters, got %+v", got)
+	}
+	if !got.HasLineFilters() {
+		t.Fatalf("expected account to be a line filter")
+	}
+	if got.PerPage != MaxSearchPerPage || got.Page != 1 {
+		t.Fatalf("expected page 1 capped at %d, got %+v", MaxSearchPerPage, got)
+	}
+	if page.Total != 230 || page.TotalPages != 3 || len(page.Entries) != 1 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	if _, err := service.Search(context.Background(), SearchRequest{}); err != nil {
+		t.Fatalf("empty search: %v", err)
+	}
+	if got.PerPage != DefaultSearchPerPage || got.HasLineFilters() {
+		t.Fatalf("expected default page size without line filters, got %+v", got)
+	}
+}
+
+func TestSearchRejectsContradictoryFilters(t *testing.T) {
+	var got SearchRequest
+	service := NewService(searchRepo{got: &got}, nil, nil)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	low, high, negative := 10.0, 5.0, -1.0
+
+	cases := map[string]SearchRequest{
+		"status":      {Status: "DRAFT"},
+		"dates":       {From: day, To: day.AddDate(0, 0, -1)},
+		"amount span": {MinAmount: &low, MaxAmount: &high},
+		"negative":    {MinAmount: &negative},
+	}
+	for name, req := range cases {
+		if _, err := service.Search(context.Background(), req); !errors.Is(err, shared.ErrInvalidSearch) {
+			t.Errorf("%s: expected ErrInvalidSearch, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	closepkg "github.com/odyssey-erp/odyssey-erp/internal/close"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// YearEndSourceModule tags retained earnings closing journals.
+const YearEndSourceModule = "CLOSE:YEAR_END"
+
+// YearEndSourceID identifies the closing journal of a company and fiscal year.
+func YearEndSourceID(companyID int64, fiscalYear int) uuid.UUID {
+	return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("YEAR_END:%d:%d", companyID, fiscalYear)))
+}
+
+// CloseFiscalYear posts a journal dated on the last day of the fiscal year
+// that zeroes every revenue and expense balance of the company and books the
+// net income to the retained earnings account. The journal is linked to a
+// source derived from company and year, so a second call returns the existing
+// entry with AlreadyPosted set instead of posting again.
+func (s *Service) CloseFiscalYear(ctx context.Context, in closepkg.YearEndClosing) (closepkg.YearEndResult, error) {
+	if in.CompanyID == 0 || in.FiscalYear == 0 || in.LedgerPeriodID == 0 || in.RetainedEarningsAccountID == 0 {
+		return closepkg.YearEndResult{}, errors.New("accounting: company, fiscal year, period and retained earnings account required")
+	}
+	if in.StartDate.IsZero() || in.EndDate.IsZero() || in.StartDate.After(in.EndDate) {
+		return closepkg.YearEndResult{}, errors.New("accounting: invalid fiscal year range")
+	}
+	result := closepkg.YearEndResult{CompanyID: in.CompanyID, FiscalYear: in.FiscalYear}
+	sourceID := YearEndSourceID(in.CompanyID, in.FiscalYear)
+	var posted []JournalLine
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		existing, err := tx.FindSourceLink(ctx, YearEndSourceModule, sourceID)
+		if err != nil {
+			return err
+		}
+		if existing != 0 {
+			entry, _, err := tx.GetJournalWithLines(ctx, existing)
+			if err != nil {
+				return err
+			}
+			result.JournalID = entry.ID
+			result.JournalNumber = entry.Number
+			result.AlreadyPosted = true
+			return nil
+		}
+		if s.guard != nil {
+			if err := s.guard.EnsurePeriodOpenForPosting(ctx, in.LedgerPeriodID); err != nil {
+				if errors.Is(err, closepkg.ErrPeriodHardClosed) {
+					return shared.ErrPeriodLocked
+				}
+				return err
+			}
+		}
+		period, err := tx.GetPeriodForUpdate(ctx, in.LedgerPeriodID)
+		if err != nil {
+			return err
+		}
+		if period.Status == periods.PeriodStatusLocked {
+			return shared.ErrPeriodLocked
+		}
+		if period.Status != periods.PeriodStatusOpen && period.Status != periods.PeriodStatusClosed {
+			return shared.ErrInvalidPeriod
+		}
+		if in.EndDate.Before(period.StartDate) || in.EndDate.After(period.EndDate) {
+			return shared.ErrDateOutOfRange
+		}
+		retained, err := tx.GetAccount(ctx, in.RetainedEarningsAccountID)
+		if err != nil {
+			return err
+		}
+		if retained.Type != "EQUITY" {
+			return fmt.Errorf("%w (%s %s)", shared.ErrRetainedEarningsNotEquity, retained.Code, retained.Type)
+		}
+
+		balances, err := tx.IncomeStatementBalances(ctx, in.CompanyID, in.StartDate, in.EndDate)
+		if err != nil {
+			return err
+		}
+		lines, netIncome := yearEndLines(retained.ID, balances)
+		if len(lines) == 0 {
+			return shared.ErrYearEndNothingToClose
+		}
+
+		posting := PostingInput{
+			PeriodID:     period.ID,
+			Date:         in.EndDate,
+			SourceModule: YearEndSourceModule,
+			SourceID:     sourceID,
+			Memo:         fmt.Sprintf("Year-end closing %d to %s %s (net income %.2f)", in.FiscalYear, retained.Code, retained.Name, netIncome),
+			PostedBy:     in.ActorID,
+			Lines:        lines,
+		}
+		if err := posting.Validate(); err != nil {
+			return err
+		}
+		inserted, err := tx.InsertJournalEntry(ctx, posting)
+		if err != nil {
+			return err
+		}
+		if err := tx.InsertJournalLines(ctx, inserted.ID, posting.Lines); err != nil {
+			return err
+		}
+		if err := tx.LinkSource(ctx, posting.SourceModule, posting.SourceID, inserted.ID); err != nil {
+			if errors.Is(err, shared.ErrSourceConflict) {
+				return shared.ErrSourceAlreadyLinked
+			}
+			return err
+		}
+		result.JournalID = inserted.ID
+		result.JournalNumber = inserted.Number
+		result.NetIncome = netIncome
+		posted = toJournalLines(inserted.ID, posting.Lines, s.now())
+		return nil
+	})
+	if err != nil {
+		return closepkg.YearEndResult{}, err
+	}
+	if posted == nil {
+		return result, nil
+	}
+	s.invalidateCaches(ctx, in.EndDate, posted)
+	if s.audit != nil {
+		_ = s.audit.Record(ctx, internalShared.AuditLog{
+			ActorID:  in.ActorID,
+			Action:   "journal.year_end_close",
+			Entity:   "journal_entry",
+			EntityID: fmt.Sprintf("%d", result.JournalID),
+			Meta: map[string]any{
+				"number":      result.JournalNumber,
+				"company_id":  in.CompanyID,
+				"fiscal_year": in.FiscalYear,
+				"net_income":  result.NetIncome,
+			},
+			At: s.now(),
+		})
+	}
+	return result, nil
+}
+
+// yearEndLines reverses each revenue and expense balance and offsets it
+// against retained earnings per dimension combination, so branch and
+// warehouse equity stays consistent with their results. The returned net
+// income is positive for a profit.
+func yearEndLines(retainedID int64, balances []ClosingBalance) ([]PostingLineInput, float64) {
+	type dimKey struct{ company, branch, warehouse int64 }
+	var lines []PostingLineInput
+	var order []dimKey
+	offsets := make(map[dimKey]DimensionBalance)
+	for _, bal := range balances {
+		amount := math.Round(bal.Balance*100) / 100
+		if amount == 0 {
+			continue
+		}
+		line := PostingLineInput{AccountID: bal.AccountID, CompanyID: bal.CompanyID, BranchID: bal.BranchID, Warehouse: bal.WarehouseID}
+		if amount > 0 {
+			line.Credit = amount
+		} else {
+			line.Debit = -amount
+		}
+		lines = append(lines, line)
+
+		key := dimKey{derefInt(bal.CompanyID), derefInt(bal.BranchID), derefInt(bal.WarehouseID)}
+		offset, ok := offsets[key]
+		if !ok {
+			order = append(order, key)
+			offset = bal.DimensionBalance
+			offset.Balance = 0
+		}
+		offset.Balance += amount
+		offsets[key] = offset
+	}
+	var netIncome float64
+	for _, key := range order {
+		offset := offsets[key]
+		amount := math.Round(offset.Balance*100) / 100
+		if amount == 0 {
+			continue
+		}
+		line := PostingLineInput{AccountID: retainedID, CompanyID: offset.CompanyID, BranchID: offset.BranchID, Warehouse: offset.WarehouseID}
+		if amount > 0 {
+			line.Debit = amount
+		} else {
+			line.Credit = -amount
+		}
+		lines = append(lines, line)
+		netIncome -= amount
+	}
+	return lines, math.Round(netIncome*100) / 100
+}
+
+func derefInt(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
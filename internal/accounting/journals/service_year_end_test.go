@@ -0,0 +1,121 @@
+package journals
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	closepkg "github.com/odyssey-erp/odyssey-erp/internal/close"
+)
+
+func yearEndRepo(closing []ClosingBalance, linked int64, posted *[]PostingLineInput) stubRepo {
+	return stubRepo{
+		period: periods.Period{
+			ID:        12,
+			Code:      "2026-12",
+			Status:    periods.PeriodStatusClosed,
+			StartDate: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+		accounts: map[int64]AccountRef{
+			30: {ID: 30, Code: "3100", Name: "Retained Earnings", Type: "EQUITY", IsActive: true},
+			40: {ID: 40, Code: "4000", Name: "Revenue", Type: "REVENUE", IsActive: true},
+		},
+		closing: closing,
+		linked:  linked,
+		posted:  posted,
+	}
+}
+
+func yearEndInput(retainedID int64) closepkg.YearEndClosing {
+	return closepkg.YearEndClosing{
+		CompanyID:                 1,
+		FiscalYear:                2026,
+		StartDate:                 time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:                   time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+		LedgerPeriodID:            12,
+		RetainedEarningsAccountID: retainedID,
+		ActorID:                   7,
+	}
+}
+
+func TestCloseFiscalYearMovesNetIncomeToRetainedEarnings(t *testing.T) {
+	company := int64(1)
+	branch := int64(3)
+	var posted []PostingLineInput
+	repo := yearEndRepo([]ClosingBalance{
+		{AccountID: 40, Type: "REVENUE", DimensionBalance: DimensionBalance{CompanyID: &company, Balance: -1000}},
+		{AccountID: 51, Type: "EXPENSE", DimensionBalance: DimensionBalance{CompanyID: &company, Balance: 600}},
+		{AccountID: 51, Type: "EXPENSE", DimensionBalance: DimensionBalance{CompanyID: &company, BranchID: &branch, Balance: 150}},
+		{AccountID: 52, Type: "EXPENSE", DimensionBalance: DimensionBalance{CompanyID: &company, Balance: 0.001}},
+	}, 0, &posted)
+	service := NewService(repo, nil, nil)
+
+	result, err := service.CloseFiscalYear(context.Background(), yearEndInput(30))
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if result.NetIncome != 250 || result.AlreadyPosted {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	want := []PostingLineInput{
+		{AccountID: 40, Debit: 1000},
+		{AccountID: 51, Credit: 600},
+		{AccountID: 51, Credit: 150, BranchID: &branch},
+		{AccountID: 30, Credit: 400},
+		{AccountID: 30, Debit: 150, BranchID: &branch},
+	}
+	if len(posted) != len(want) {
+		t.Fatalf("expected %d lines, got %d", len(want), len(posted))
+	}
+	for i, line := range posted {
+		if line.AccountID != want[i].AccountID || line.Debit != want[i].Debit || line.Credit != want[i].Credit || line.BranchID != want[i].BranchID {
+			t.Fatalf("line %d = %+v, want %+v", i, line, want[i])
+		}
+		if line.CompanyID == nil || *line.CompanyID != company {
+			t.Fatalf("line %d missing company dimension", i)
+		}
+	}
+}
+
+func TestCloseFiscalYearIsIdempotent(t *testing.T) {
+	var posted []PostingLineInput
+	repo := yearEndRepo([]ClosingBalance{{AccountID: 40, Type: "REVENUE", DimensionBalance: DimensionBalance{Balance: -1000}}}, 55, &posted)
+	service := NewService(repo, nil, nil)
+
+	result, err := service.CloseFiscalYear(context.Background(), yearEndInput(30))
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if !result.AlreadyPosted || result.JournalID != 55 {
+		t.Fatalf("expected existing journal 55, got %+v", result)
+	}
+	if len(posted) != 0 {
+		t.Fatalf("expected no new lines, got %d", len(posted))
+	}
+}
+
+func TestCloseFiscalYearValidatesAccountsAndBalances(t *testing.T) {
+	repo := yearEndRepo([]ClosingBalance{{AccountID: 40, Type: "REVENUE", DimensionBalance: DimensionBalance{Balance: -1000}}}, 0, nil)
+	service := NewService(repo, nil, nil)
+	if _, err := service.CloseFiscalYear(context.Background(), yearEndInput(40)); !errors.Is(err, shared.ErrRetainedEarningsNotEquity) {
+		t.Fatalf("expected ErrRetainedEarningsNotEquity, got %v", err)
+	}
+
+	service = NewService(yearEndRepo(nil, 0, nil), nil, nil)
+	if _, err := service.CloseFiscalYear(context.Background(), yearEndInput(30)); !errors.Is(err, shared.ErrYearEndNothingToClose) {
+		t.Fatalf("expected ErrYearEndNothingToClose, got %v", err)
+	}
+}
+
+func TestCloseFiscalYearRespectsPeriodLock(t *testing.T) {
+	repo := yearEndRepo([]ClosingBalance{{AccountID: 40, Type: "REVENUE", DimensionBalance: DimensionBalance{Balance: -1000}}}, 0, nil)
+	repo.period.Status = periods.PeriodStatusLocked
+	service := NewService(repo, nil, nil)
+	if _, err := service.CloseFiscalYear(context.Background(), yearEndInput(30)); !errors.Is(err, shared.ErrPeriodLocked) {
+		t.Fatalf("expected ErrPeriodLocked, got %v", err)
+	}
+}
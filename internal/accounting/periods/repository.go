@@ -12,6 +12,7 @@ import (
 
 type Repository interface {
 	FindOpenPeriodByDate(ctx context.Context, date time.Time) (Period, error)
+	EnsureOpen(ctx context.Context, date time.Time) error
 	// Additional methods can be added as needed
 }
 
@@ -37,3 +38,21 @@ FROM periods WHERE status='OPEN' AND $1 BETWEEN start_date AND end_date ORDER BY
 	}
 	return period, nil
 }
+
+// EnsureOpen checks that the period covering date accepts postings. It
+// returns ErrInvalidPeriod when no period covers the date and ErrPeriodLocked
+// when the covering period is closed or locked.
+func (r *repository) EnsureOpen(ctx context.Context, date time.Time) error {
+	var status PeriodStatus
+	err := r.db.QueryRow(ctx, `SELECT status FROM periods WHERE $1 BETWEEN start_date AND end_date ORDER BY start_date LIMIT 1`, date).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return shared.ErrInvalidPeriod
+		}
+		return err
+	}
+	if status != PeriodStatusOpen {
+		return shared.ErrPeriodLocked
+	}
+	return nil
+}
@@ -16,3 +16,7 @@ func NewService(repo Repository) *Service {
 func (s *Service) FindOpenPeriodByDate(ctx context.Context, date time.Time) (Period, error) {
 	return s.repo.FindOpenPeriodByDate(ctx, date)
 }
+
+func (s *Service) EnsureOpen(ctx context.Context, date time.Time) error {
+	return s.repo.EnsureOpen(ctx, date)
+}
@@ -0,0 +1,98 @@
+package accounting
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/reports"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
+)
+
+// handleProfitLossByDimension returns the period P&L split by the branch or
+// warehouse recorded on journal lines, with lines lacking that dimension in an
+// unallocated bucket.
+//
+// Query parameters: period_id, group_by (branch or warehouse, default branch),
+// company_id, branch_id, warehouse_id. branch_id and warehouse_id also accept
+// "unallocated" to keep only lines without that dimension.
+func (h *Handler) handleProfitLossByDimension(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	periodID, err := strconv.ParseInt(q.Get("period_id"), 10, 64)
+	if err != nil || periodID <= 0 {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid period", "period_id is required")
+		return
+	}
+	dimension := strings.ToLower(strings.TrimSpace(q.Get("group_by")))
+	switch dimension {
+	case "":
+		dimension = "branch"
+	case "branch", "warehouse":
+	default:
+		httpx.Problem(w, http.StatusBadRequest, "Invalid grouping", "group_by must be branch or warehouse")
+		return
+	}
+	companyID, err := optionalInt8(q.Get("company_id"))
+	if err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid company", err.Error())
+		return
+	}
+	branchID, branchUnallocated, err := dimensionFilter(q.Get("branch_id"))
+	if err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid branch", err.Error())
+		return
+	}
+	warehouseID, warehouseUnallocated, err := dimensionFilter(q.Get("warehouse_id"))
+	if err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid warehouse", err.Error())
+		return
+	}
+
+	rows, err := h.queries.ProfitLossByDimension(r.Context(), sqlc.ProfitLossByDimensionParams{
+		PeriodID:             periodID,
+		Dimension:            dimension,
+		CompanyID:            companyID,
+		BranchID:             branchID,
+		BranchUnallocated:    branchUnallocated,
+		WarehouseID:          warehouseID,
+		WarehouseUnallocated: warehouseUnallocated,
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "profit and loss by dimension", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, "Failed to load profit and loss", "")
+		return
+	}
+	balances := make([]reports.DimensionAccountBalance, 0, len(rows))
+	for _, row := range rows {
+		bal := reports.DimensionAccountBalance{
+			DimensionName: row.DimensionName,
+			AccountBalance: reports.AccountBalance{
+				Code:   row.Code,
+				Name:   row.Name,
+				Type:   string(row.Type),
+				Debit:  row.Debit,
+				Credit: row.Credit,
+			},
+		}
+		if row.DimensionID.Valid {
+			id := row.DimensionID.Int64
+			bal.DimensionID = &id
+		}
+		balances = append(balances, bal)
+	}
+	httpx.JSON(w, http.StatusOK, reports.BuildDimensionProfitAndLoss(dimension, balances))
+}
+
+// dimensionFilter parses a dimension id filter; "unallocated" selects lines
+// where the dimension is not set.
+func dimensionFilter(raw string) (pgtype.Int8, bool, error) {
+	if strings.EqualFold(strings.TrimSpace(raw), "unallocated") {
+		return pgtype.Int8{}, true, nil
+	}
+	id, err := optionalInt8(raw)
+	return id, false, err
+}
@@ -0,0 +1,100 @@
+package reports
+
+import "sort"
+
+// DimensionAccountBalance is an account's period movement on journal lines
+// carrying one branch or warehouse. A nil DimensionID marks lines without it.
+type DimensionAccountBalance struct {
+	DimensionID   *int64
+	DimensionName string
+	AccountBalance
+}
+
+// DimensionProfitAndLossAccount is one revenue or expense account in a slice.
+// Amount is positive for income on revenue accounts and for cost on expenses.
+type DimensionProfitAndLossAccount struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Amount float64 `json:"amount"`
+}
+
+// DimensionProfitAndLossSlice is the P&L of a single branch or warehouse, or
+// of the unallocated lines.
+type DimensionProfitAndLossSlice struct {
+	DimensionID   *int64                          `json:"dimension_id"`
+	DimensionName string                          `json:"dimension_name"`
+	Unallocated   bool                            `json:"unallocated"`
+	Accounts      []DimensionProfitAndLossAccount `json:"accounts"`
+	Revenue       float64                         `json:"revenue"`
+	Expense       float64                         `json:"expense"`
+	NetIncome     float64                         `json:"net_income"`
+}
+
+// DimensionProfitAndLoss splits the P&L by a journal line dimension.
+type DimensionProfitAndLoss struct {
+	Dimension string                        `json:"dimension"`
+	Slices    []DimensionProfitAndLossSlice `json:"slices"`
+	Revenue   float64                       `json:"revenue"`
+	Expense   float64                       `json:"expense"`
+	NetIncome float64                       `json:"net_income"`
+}
+
+// BuildDimensionProfitAndLoss groups balances by dimension value. Slices are
+// ordered by name with the unallocated bucket last.
+func BuildDimensionProfitAndLoss(dimension string, balances []DimensionAccountBalance) DimensionProfitAndLoss {
+	type bucket struct {
+		slice    DimensionProfitAndLossSlice
+		balances []AccountBalance
+	}
+	buckets := make(map[int64]*bucket)
+	var unallocated *bucket
+	order := make([]*bucket, 0)
+	for _, bal := range balances {
+		var b *bucket
+		if bal.DimensionID == nil {
+			if unallocated == nil {
+				unallocated = &bucket{slice: DimensionProfitAndLossSlice{DimensionName: "Unallocated", Unallocated: true}}
+			}
+			b = unallocated
+		} else {
+			var ok bool
+			if b, ok = buckets[*bal.DimensionID]; !ok {
+				id := *bal.DimensionID
+				b = &bucket{slice: DimensionProfitAndLossSlice{DimensionID: &id, DimensionName: bal.DimensionName}}
+				buckets[id] = b
+				order = append(order, b)
+			}
+		}
+		b.balances = append(b.balances, bal.AccountBalance)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].slice.DimensionName != order[j].slice.DimensionName {
+			return order[i].slice.DimensionName < order[j].slice.DimensionName
+		}
+		return *order[i].slice.DimensionID < *order[j].slice.DimensionID
+	})
+	if unallocated != nil {
+		order = append(order, unallocated)
+	}
+
+	result := DimensionProfitAndLoss{Dimension: dimension, Slices: make([]DimensionProfitAndLossSlice, 0, len(order))}
+	for _, b := range order {
+		pl := BuildProfitAndLoss(b.balances)
+		slice := b.slice
+		for _, acc := range pl.Revenue.Accounts {
+			slice.Accounts = append(slice.Accounts, DimensionProfitAndLossAccount{Code: acc.Code, Name: acc.Name, Type: "REVENUE", Amount: acc.Amount})
+		}
+		for _, acc := range pl.Expense.Accounts {
+			slice.Accounts = append(slice.Accounts, DimensionProfitAndLossAccount{Code: acc.Code, Name: acc.Name, Type: "EXPENSE", Amount: acc.Amount})
+		}
+		slice.Revenue = pl.Revenue.Total
+		slice.Expense = pl.Expense.Total
+		slice.NetIncome = pl.NetIncome
+		result.Revenue += slice.Revenue
+		result.Expense += slice.Expense
+		result.NetIncome += slice.NetIncome
+		result.Slices = append(result.Slices, slice)
+	}
+	return result
+}
@@ -71,3 +71,60 @@ func TestBuildBalanceSheet(t *testing.T) {
 		t.Fatalf("expected total L+E 470 got %v", bs.TotalLiabilitiesAndEquity)
 	}
 }
+
+func TestBuildTrialBalanceComparison(t *testing.T) {
+	balances := []CompanyAccountBalance{
+		{CompanyID: 1, AccountBalance: AccountBalance{Code: "1000", Name: "Cash", Type: "ASSET", Opening: 100, Debit: 50, Credit: 20}},
+		{CompanyID: 2, AccountBalance: AccountBalance{Code: "1000", Name: "Cash", Type: "ASSET", Opening: 80, Debit: 10}},
+		{CompanyID: 2, AccountBalance: AccountBalance{Code: "4000", Name: "Sales", Type: "REVENUE", Credit: 40}},
+		{CompanyID: 3, AccountBalance: AccountBalance{Code: "1000", Name: "Cash", Type: "ASSET", Debit: 5}},
+		{CompanyID: 9, AccountBalance: AccountBalance{Code: "9000", Name: "Ignored", Type: "ASSET", Debit: 5}},
+	}
+
+	cmp := BuildTrialBalanceComparison([]int64{1, 2, 3}, 1, 2, balances)
+	if len(cmp.Rows) != 2 {
+		t.Fatalf("expected 2 rows got %d", len(cmp.Rows))
+	}
+	cash := cmp.Rows[0]
+	if cash.Code != "1000" || cash.Closing[0] != 130 || cash.Closing[1] != 90 || cash.Closing[2] != 5 {
+		t.Fatalf("unexpected cash row %+v", cash)
+	}
+	if cash.Variance != 40 {
+		t.Fatalf("expected cash variance 40 got %v", cash.Variance)
+	}
+	sales := cmp.Rows[1]
+	if sales.Closing[0] != 0 || sales.Closing[1] != -40 || sales.Variance != 40 {
+		t.Fatalf("unexpected sales row %+v", sales)
+	}
+	if cmp.Totals[1] != 50 || cmp.TotalVariance != 80 {
+		t.Fatalf("unexpected totals %v variance %v", cmp.Totals, cmp.TotalVariance)
+	}
+}
+
+func TestBuildDimensionProfitAndLoss(t *testing.T) {
+	north, south := int64(2), int64(1)
+	balances := []DimensionAccountBalance{
+		{DimensionID: &north, DimensionName: "North", AccountBalance: AccountBalance{Code: "4000", Name: "Sales", Type: "REVENUE", Credit: 900}},
+		{DimensionID: &north, DimensionName: "North", AccountBalance: AccountBalance{Code: "5000", Name: "COGS", Type: "EXPENSE", Debit: 400}},
+		{AccountBalance: AccountBalance{Code: "6100", Name: "Head office rent", Type: "EXPENSE", Debit: 150}},
+		{DimensionID: &south, DimensionName: "South", AccountBalance: AccountBalance{Code: "4000", Name: "Sales", Type: "REVENUE", Credit: 300, Debit: 20}},
+	}
+
+	pl := BuildDimensionProfitAndLoss("branch", balances)
+	if len(pl.Slices) != 3 {
+		t.Fatalf("expected 3 slices got %d", len(pl.Slices))
+	}
+	if pl.Slices[0].DimensionName != "North" || pl.Slices[0].NetIncome != 500 {
+		t.Fatalf("unexpected first slice %+v", pl.Slices[0])
+	}
+	if pl.Slices[1].DimensionName != "South" || pl.Slices[1].Revenue != 280 {
+		t.Fatalf("unexpected second slice %+v", pl.Slices[1])
+	}
+	last := pl.Slices[2]
+	if !last.Unallocated || last.DimensionID != nil || last.Expense != 150 {
+		t.Fatalf("expected unallocated bucket last, got %+v", last)
+	}
+	if pl.Revenue != 1180 || pl.Expense != 550 || pl.NetIncome != 630 {
+		t.Fatalf("unexpected totals revenue %v expense %v net %v", pl.Revenue, pl.Expense, pl.NetIncome)
+	}
+}
@@ -0,0 +1,78 @@
+package reports
+
+import "sort"
+
+// CompanyAccountBalance is an account balance scoped to a single company.
+type CompanyAccountBalance struct {
+	CompanyID int64
+	AccountBalance
+}
+
+// TrialBalanceComparisonRow aligns one account across the compared companies.
+type TrialBalanceComparisonRow struct {
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	Closing  []float64 `json:"closing"`
+	Variance float64   `json:"variance"`
+}
+
+// TrialBalanceComparison holds closing balances per company as parallel columns.
+// Closing values follow the order of CompanyIDs; Variance is base minus compare.
+type TrialBalanceComparison struct {
+	CompanyIDs       []int64                     `json:"company_ids"`
+	BaseCompanyID    int64                       `json:"base_company_id"`
+	CompareCompanyID int64                       `json:"compare_company_id"`
+	Rows             []TrialBalanceComparisonRow `json:"rows"`
+	Totals           []float64                   `json:"totals"`
+	TotalVariance    float64                     `json:"total_variance"`
+}
+
+// BuildTrialBalanceComparison pivots per-company balances by account code.
+func BuildTrialBalanceComparison(companyIDs []int64, baseID, compareID int64, balances []CompanyAccountBalance) TrialBalanceComparison {
+	column := make(map[int64]int, len(companyIDs))
+	for i, id := range companyIDs {
+		column[id] = i
+	}
+	result := TrialBalanceComparison{
+		CompanyIDs:       companyIDs,
+		BaseCompanyID:    baseID,
+		CompareCompanyID: compareID,
+		Totals:           make([]float64, len(companyIDs)),
+	}
+	rows := make(map[string]*TrialBalanceComparisonRow)
+	codes := make([]string, 0)
+	for _, bal := range balances {
+		idx, ok := column[bal.CompanyID]
+		if !ok {
+			continue
+		}
+		row, ok := rows[bal.Code]
+		if !ok {
+			row = &TrialBalanceComparisonRow{
+				Code:    bal.Code,
+				Name:    bal.Name,
+				Type:    bal.Type,
+				Closing: make([]float64, len(companyIDs)),
+			}
+			rows[bal.Code] = row
+			codes = append(codes, bal.Code)
+		}
+		closing := bal.Closing()
+		row.Closing[idx] += closing
+		result.Totals[idx] += closing
+	}
+
+	sort.Strings(codes)
+	baseIdx, hasBase := column[baseID]
+	compareIdx, hasCompare := column[compareID]
+	for _, code := range codes {
+		row := rows[code]
+		if hasBase && hasCompare {
+			row.Variance = row.Closing[baseIdx] - row.Closing[compareIdx]
+			result.TotalVariance += row.Variance
+		}
+		result.Rows = append(result.Rows, *row)
+	}
+	return result
+}
@@ -23,4 +23,34 @@ var (
 	ErrMappingNotFound = errors.New("accounting: account mapping not found")
 	// ErrSourceConflict indicates the source link already exists.
 	ErrSourceConflict = errors.New("accounting: source link conflict")
+	// ErrReclassSameAccount indicates source and target accounts are identical.
+	ErrReclassSameAccount = errors.New("accounting: source and target account must differ")
+	// ErrReclassTypeMismatch indicates a cross-type reclass without override.
+	ErrReclassTypeMismatch = errors.New("accounting: source and target account types differ; override required")
+	// ErrReclassNothingToMove indicates the source account has no balance to move.
+	ErrReclassNothingToMove = errors.New("accounting: source account has no balance to reclassify")
+	// ErrYearEndNothingToClose indicates no revenue or expense balance exists for the fiscal year.
+	ErrYearEndNothingToClose = errors.New("accounting: no revenue or expense balance to close for the fiscal year")
+	// ErrRetainedEarningsNotEquity indicates the mapped retained earnings account is not an equity account.
+	ErrRetainedEarningsNotEquity = errors.New("accounting: retained earnings account must be an equity account")
+	// ErrInvalidSearch indicates contradictory journal search filters.
+	ErrInvalidSearch = errors.New("accounting: invalid journal search")
+	// ErrDimensionMismatch indicates a line's branch or warehouse does not belong
+	// to its company or branch, or does not exist.
+	ErrDimensionMismatch = errors.New("accounting: inconsistent line dimensions")
+	// ErrAccountNotFound indicates missing account.
+	ErrAccountNotFound = errors.New("accounting: account not found")
+	// ErrAccountCompanyMismatch indicates a line posts to another company's
+	// chart of accounts.
+	ErrAccountCompanyMismatch = errors.New("accounting: account belongs to another company's chart")
+	// ErrChartNotEmpty indicates the target company already has its own chart.
+	ErrChartNotEmpty = errors.New("accounting: company already has a chart of accounts")
+	// ErrPendingJournalNotFound indicates a missing or out-of-scope pending
+	// integration journal.
+	ErrPendingJournalNotFound = errors.New("accounting: pending journal not found")
+	// ErrUnknownIntegrationModule indicates a posting setting for a module
+	// without integration journals.
+	ErrUnknownIntegrationModule = errors.New("accounting: unknown integration module")
+	// ErrRejectReasonRequired indicates a pending journal rejected without a reason.
+	ErrRejectReasonRequired = errors.New("accounting: a reason is required to reject a pending journal")
 )
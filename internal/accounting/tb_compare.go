@@ -0,0 +1,133 @@
+package accounting
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/reports"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
+)
+
+// handleTrialBalanceComparison returns closing balances for several companies
+// aligned by account code, with the variance between a base and compare company.
+//
+// Query parameters: period_id, company_ids (comma separated, at least two),
+// base and compare (default to the first two companies), branch_id, warehouse_id.
+func (h *Handler) handleTrialBalanceComparison(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	periodID, err := strconv.ParseInt(q.Get("period_id"), 10, 64)
+	if err != nil || periodID <= 0 {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid period", "period_id is required")
+		return
+	}
+	companyIDs, err := parseIDList(q.Get("company_ids"))
+	if err != nil || len(companyIDs) < 2 {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid companies", "company_ids must list at least two companies")
+		return
+	}
+	for _, id := range companyIDs {
+		if !shared.CompanyAllowed(r.Context(), id) {
+			httpx.Problem(w, http.StatusForbidden, "Forbidden", "company_ids includes a company outside your scope")
+			return
+		}
+	}
+	baseID, compareID := companyIDs[0], companyIDs[1]
+	if raw := q.Get("base"); raw != "" {
+		if baseID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid base company", err.Error())
+			return
+		}
+	}
+	if raw := q.Get("compare"); raw != "" {
+		if compareID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid compare company", err.Error())
+			return
+		}
+	}
+	if baseID == compareID || !containsID(companyIDs, baseID) || !containsID(companyIDs, compareID) {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid comparison", "base and compare must be two different companies from company_ids")
+		return
+	}
+	branchID, err := optionalInt8(q.Get("branch_id"))
+	if err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid branch", err.Error())
+		return
+	}
+	warehouseID, err := optionalInt8(q.Get("warehouse_id"))
+	if err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid warehouse", err.Error())
+		return
+	}
+
+	rows, err := h.queries.CompareTrialBalanceByCompany(r.Context(), sqlc.CompareTrialBalanceByCompanyParams{
+		PeriodID:    periodID,
+		CompanyIds:  companyIDs,
+		BranchID:    branchID,
+		WarehouseID: warehouseID,
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "trial balance comparison", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, "Failed to load balances", "")
+		return
+	}
+	balances := make([]reports.CompanyAccountBalance, 0, len(rows))
+	for _, row := range rows {
+		balances = append(balances, reports.CompanyAccountBalance{
+			CompanyID: row.CompanyID,
+			AccountBalance: reports.AccountBalance{
+				Code:    row.Code,
+				Name:    row.Name,
+				Type:    string(row.Type),
+				Opening: row.Opening,
+				Debit:   row.Debit,
+				Credit:  row.Credit,
+			},
+		})
+	}
+	httpx.JSON(w, http.StatusOK, reports.BuildTrialBalanceComparison(companyIDs, baseID, compareID, balances))
+}
+
+func parseIDList(raw string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, errors.New("invalid id " + part)
+		}
+		if !containsID(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func optionalInt8(raw string) (pgtype.Int8, error) {
+	if strings.TrimSpace(raw) == "" {
+		return pgtype.Int8{}, nil
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil {
+		return pgtype.Int8{}, err
+	}
+	return pgtype.Int8{Int64: id, Valid: true}, nil
+}
@@ -15,8 +15,13 @@ import (
 const (
 	cacheVersionKey = "analytics:version"
 	bumpChannel     = "gl.bump"
+	scanBatchSize   = 100
 )
 
+// ErrScanUnsupported indicates selective invalidation is unavailable; cached
+// entries then expire with the TTL.
+var ErrScanUnsupported = errors.New("analytics cache: key scan unsupported")
+
 // Cache wraps Redis based caching with versioning controls.
 type Cache struct {
 	client *redis.Client
@@ -114,6 +119,34 @@ func (c *Cache) Bump(ctx context.Context) error {
 	return c.client.Publish(ctx, bumpChannel, strconv.FormatInt(ver, 10)).Err()
 }
 
+// InvalidatePeriod deletes the cached entries of a company that include the
+// YYYY-MM period, leaving other companies and periods warm. When the Redis
+// deployment does not allow SCAN the error wraps ErrScanUnsupported and the
+// entries simply expire with the cache TTL.
+func (c *Cache) InvalidatePeriod(ctx context.Context, companyID int64, period string) error {
+	if c == nil || c.client == nil {
+		return nil
+	}
+	pattern := strings.Join([]string{"analytics", formatInt(companyID), "*"}, ":")
+	var stale []string
+	iter := c.client.Scan(ctx, 0, pattern, scanBatchSize).Iterator()
+	for iter.Next(ctx) {
+		if key := iter.Val(); keyCoversPeriod(key, period) {
+			stale = append(stale, key)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrScanUnsupported, err)
+	}
+	for start := 0; start < len(stale); start += scanBatchSize {
+		end := min(start+scanBatchSize, len(stale))
+		if err := c.client.Del(ctx, stale[start:end]...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ListenForInvalidation subscribes to version bump notifications.
 func (c *Cache) ListenForInvalidation(ctx context.Context, channel string) error {
 	if c == nil || c.client == nil {
@@ -147,18 +180,42 @@ func (c *Cache) ListenForInvalidation(ctx context.Context, channel string) error
 	return nil
 }
 
+// Cache keys follow analytics:<company>:<kind>:<branch>:<args...>:<version>
+// so every entry of a company can be found with a single SCAN pattern and the
+// trailing arguments tell which periods the entry covers.
+
 func keyKPI(companyID int64, branchID *int64, period string) string {
-	return strings.Join([]string{"analytics", "kpi", formatInt(companyID), branchToken(branchID), period}, ":")
+	return strings.Join([]string{"analytics", formatInt(companyID), "kpi", branchToken(branchID), period}, ":")
 }
 
 func keyPLTrend(companyID int64, branchID *int64, from, to string) string {
-	return strings.Join([]string{"analytics", "pl_trend", formatInt(companyID), branchToken(branchID), from, to}, ":")
+	return strings.Join([]string{"analytics", formatInt(companyID), "pl_trend", branchToken(branchID), from, to}, ":")
 }
 
 func keyCashflow(companyID int64, branchID *int64, from, to string) string {
-	return strings.Join([]string{"analytics", "cashflow", formatInt(companyID), branchToken(branchID), from, to}, ":")
+	return strings.Join([]string{"analytics", formatInt(companyID), "cashflow", branchToken(branchID), from, to}, ":")
 }
 
 func keyAging(prefix string, companyID int64, branchID *int64, asOf time.Time) string {
-	return strings.Join([]string{"analytics", prefix, formatInt(companyID), branchToken(branchID), asOf.Format("2006-01-02")}, ":")
+	return strings.Join([]string{"analytics", formatInt(companyID), prefix, branchToken(branchID), asOf.Format("2006-01-02")}, ":")
+}
+
+// keyCoversPeriod reports whether the cached entry includes figures for the
+// YYYY-MM period. Unknown layouts are treated as covering it.
+func keyCoversPeriod(key, period string) bool {
+	parts := strings.Split(key, ":")
+	if len(parts) < 5 {
+		return true
+	}
+	args := parts[4 : len(parts)-1]
+	switch parts[2] {
+	case "kpi":
+		return len(args) != 1 || args[0] == period
+	case "pl_trend", "cashflow":
+		return len(args) != 2 || (args[0] <= period && period <= args[1])
+	case "aging_ar", "aging_ap":
+		return len(args) != 1 || len(args[0]) < 7 || args[0][:7] >= period
+	default:
+		return true
+	}
 }
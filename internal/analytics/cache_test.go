@@ -0,0 +1,70 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInvalidatePeriodDropsOnlyCoveringEntries(t *testing.T) {
+	repo := &mockRepo{}
+	svc, cleanup := newTestService(t, repo)
+	defer cleanup()
+	ctx := context.Background()
+
+	jan := KPIFilter{Period: "2025-01", CompanyID: 7}
+	feb := KPIFilter{Period: "2025-02", CompanyID: 7}
+	other := KPIFilter{Period: "2025-01", CompanyID: 8}
+	trend := TrendFilter{From: "2024-08", To: "2025-01", CompanyID: 7}
+	for _, filter := range []KPIFilter{jan, feb, other} {
+		if _, err := svc.GetKPISummary(ctx, filter); err != nil {
+			t.Fatalf("kpi: %v", err)
+		}
+	}
+	if _, err := svc.GetPLTrend(ctx, trend); err != nil {
+		t.Fatalf("trend: %v", err)
+	}
+	if repo.kpiCalls != 3 || repo.plCalls != 1 {
+		t.Fatalf("unexpected warm-up calls kpi=%d pl=%d", repo.kpiCalls, repo.plCalls)
+	}
+
+	if err := svc.cache.InvalidatePeriod(ctx, 7, "2025-01"); err != nil {
+		t.Fatalf("invalidate: %v", err)
+	}
+
+	for _, filter := range []KPIFilter{jan, feb, other} {
+		if _, err := svc.GetKPISummary(ctx, filter); err != nil {
+			t.Fatalf("kpi: %v", err)
+		}
+	}
+	if repo.kpiCalls != 4 {
+		t.Fatalf("expected only company 7 January KPI to reload, calls %d", repo.kpiCalls)
+	}
+	if _, err := svc.GetPLTrend(ctx, trend); err != nil {
+		t.Fatalf("trend: %v", err)
+	}
+	if repo.plCalls != 2 {
+		t.Fatalf("expected trend covering January to reload, calls %d", repo.plCalls)
+	}
+}
+
+func TestKeyCoversPeriod(t *testing.T) {
+	asOf := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		key  string
+		want bool
+	}{
+		{keyKPI(1, nil, "2025-02") + ":3", true},
+		{keyKPI(1, nil, "2025-03") + ":3", false},
+		{keyCashflow(1, nil, "2024-10", "2025-03") + ":3", true},
+		{keyCashflow(1, nil, "2025-03", "2025-06") + ":3", false},
+		{keyAging("aging_ar", 1, nil, asOf) + ":3", true},
+		{keyAging("aging_ap", 1, nil, asOf.AddDate(0, -2, 0)) + ":3", false},
+		{"analytics:1:unknown:-:x:3", true},
+	}
+	for _, tc := range cases {
+		if got := keyCoversPeriod(tc.key, "2025-02"); got != tc.want {
+			t.Fatalf("keyCoversPeriod(%q) = %v, want %v", tc.key, got, tc.want)
+		}
+	}
+}
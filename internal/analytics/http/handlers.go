@@ -17,6 +17,7 @@ import (
 	"github.com/odyssey-erp/odyssey-erp/internal/analytics/export"
 	"github.com/odyssey-erp/odyssey-erp/internal/analytics/svg"
 	"github.com/odyssey-erp/odyssey-erp/internal/analytics/ui"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 	"golang.org/x/sync/errgroup"
@@ -94,13 +95,13 @@ func (h *Handler) WithNow(fn func() time.Time) {
 func (h *Handler) handleDashboard(w http.ResponseWriter, r *http.Request) {
 	sess := shared.SessionFromContext(r.Context())
 	if err := h.authorize(r.Context(), sess, shared.PermFinanceAnalyticsView, shared.PermFinanceGLView); err != nil {
-		h.respondAuthError(w, err)
+		h.respondAuthError(w, r, err)
 		return
 	}
 
 	filters, err := h.parseFilters(r)
 	if err != nil {
-		h.handleFilterError(w, err)
+		h.handleFilterError(w, r, err)
 		return
 	}
 
@@ -109,20 +110,20 @@ func (h *Handler) handleDashboard(w http.ResponseWriter, r *http.Request) {
 
 	if h.periods != nil {
 		if err := h.periods.ValidatePeriod(ctx, filters.Period); err != nil {
-			h.handleValidationFailure(w, fmt.Errorf("period invalid: %w", err))
+			h.handleValidationFailure(w, r, fmt.Errorf("period invalid: %w", err))
 			return
 		}
 	}
 
 	data, err := h.loadDashboardData(ctx, filters)
 	if err != nil {
-		h.handleServerError(w, "load dashboard", err)
+		h.handleServerError(w, r, "load dashboard", err)
 		return
 	}
 
 	vm, err := h.buildViewModel(filters, data)
 	if err != nil {
-		h.handleServerError(w, "render charts", err)
+		h.handleServerError(w, r, "render charts", err)
 		return
 	}
 
@@ -140,7 +141,7 @@ func (h *Handler) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		Data:        vm,
 	}
 	if err := h.templates.Render(w, "pages/finance/dashboard.html", viewData); err != nil {
-		h.handleServerError(w, "render template", err)
+		h.handleServerError(w, r, "render template", err)
 	}
 }
 
@@ -152,17 +153,17 @@ func (h *Handler) handleKPI(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) handlePDF(w http.ResponseWriter, r *http.Request) {
 	sess := shared.SessionFromContext(r.Context())
 	if err := h.authorize(r.Context(), sess, shared.PermFinanceAnalyticsExport, shared.PermFinanceGLView); err != nil {
-		h.respondAuthError(w, err)
+		h.respondAuthError(w, r, err)
 		return
 	}
 	if h.pdf == nil {
-		h.handleServerError(w, "pdf exporter", errors.New("pdf exporter not configured"))
+		h.handleServerError(w, r, "pdf exporter", errors.New("pdf exporter not configured"))
 		return
 	}
 
 	filters, err := h.parseFilters(r)
 	if err != nil {
-		h.handleFilterError(w, err)
+		h.handleFilterError(w, r, err)
 		return
 	}
 
@@ -171,14 +172,14 @@ func (h *Handler) handlePDF(w http.ResponseWriter, r *http.Request) {
 
 	if h.periods != nil {
 		if err := h.periods.ValidatePeriod(ctx, filters.Period); err != nil {
-			h.handleValidationFailure(w, fmt.Errorf("period invalid: %w", err))
+			h.handleValidationFailure(w, r, fmt.Errorf("period invalid: %w", err))
 			return
 		}
 	}
 
 	data, err := h.loadDashboardData(ctx, filters)
 	if err != nil {
-		h.handleServerError(w, "load dashboard", err)
+		h.handleServerError(w, r, "load dashboard", err)
 		return
 	}
 
@@ -192,7 +193,7 @@ func (h *Handler) handlePDF(w http.ResponseWriter, r *http.Request) {
 	}
 	pdfBytes, err := h.pdf.RenderDashboard(ctx, payload)
 	if err != nil {
-		h.handleServerError(w, "render pdf", err)
+		h.handleServerError(w, r, "render pdf", err)
 		return
 	}
 
@@ -207,13 +208,13 @@ func (h *Handler) handlePDF(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) handleCSV(w http.ResponseWriter, r *http.Request) {
 	sess := shared.SessionFromContext(r.Context())
 	if err := h.authorize(r.Context(), sess, shared.PermFinanceAnalyticsExport, shared.PermFinanceGLView); err != nil {
-		h.respondAuthError(w, err)
+		h.respondAuthError(w, r, err)
 		return
 	}
 
 	filters, err := h.parseFilters(r)
 	if err != nil {
-		h.handleFilterError(w, err)
+		h.handleFilterError(w, r, err)
 		return
 	}
 
@@ -222,14 +223,14 @@ func (h *Handler) handleCSV(w http.ResponseWriter, r *http.Request) {
 
 	if h.periods != nil {
 		if err := h.periods.ValidatePeriod(ctx, filters.Period); err != nil {
-			h.handleValidationFailure(w, fmt.Errorf("period invalid: %w", err))
+			h.handleValidationFailure(w, r, fmt.Errorf("period invalid: %w", err))
 			return
 		}
 	}
 
 	data, err := h.loadDashboardData(ctx, filters)
 	if err != nil {
-		h.handleServerError(w, "load dashboard", err)
+		h.handleServerError(w, r, "load dashboard", err)
 		return
 	}
 
@@ -241,27 +242,27 @@ func (h *Handler) handleCSV(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	if err := export.WriteKPICSV(buf, data.summary, filters.Period); err != nil {
-		h.handleServerError(w, "write kpi csv", err)
+		h.handleServerError(w, r, "write kpi csv", err)
 		return
 	}
 	buf.WriteString("\n")
 	if err := export.WritePLTrendCSV(buf, data.pl); err != nil {
-		h.handleServerError(w, "write pl csv", err)
+		h.handleServerError(w, r, "write pl csv", err)
 		return
 	}
 	buf.WriteString("\n")
 	if err := export.WriteCashflowTrendCSV(buf, data.cashflow); err != nil {
-		h.handleServerError(w, "write cashflow csv", err)
+		h.handleServerError(w, r, "write cashflow csv", err)
 		return
 	}
 	buf.WriteString("\n")
 	if err := export.WriteAgingCSV(buf, data.ar); err != nil {
-		h.handleServerError(w, "write ar csv", err)
+		h.handleServerError(w, r, "write ar csv", err)
 		return
 	}
 	buf.WriteString("\n")
 	if err := export.WriteAgingCSV(buf, data.ap); err != nil {
-		h.handleServerError(w, "write ap csv", err)
+		h.handleServerError(w, r, "write ap csv", err)
 		return
 	}
 
@@ -304,12 +305,12 @@ func (h *Handler) authorize(ctx context.Context, sess *shared.Session, requiredP
 	return errPermissionDenied
 }
 
-func (h *Handler) respondAuthError(w http.ResponseWriter, err error) {
+func (h *Handler) respondAuthError(w http.ResponseWriter, r *http.Request, err error) {
 	if errors.Is(err, errPermissionDenied) {
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		return
 	}
-	h.handleServerError(w, "authorization", err)
+	h.handleServerError(w, r, "authorization", err)
 }
 
 func (h *Handler) parseFilters(r *http.Request) (ui.DashboardFilters, error) {
@@ -327,7 +328,7 @@ func (h *Handler) parseFilters(r *http.Request) (ui.DashboardFilters, error) {
 
 	companyStr := strings.TrimSpace(r.URL.Query().Get("company_id"))
 	if companyStr == "" {
-		companyStr = "1"
+		companyStr = strconv.FormatInt(shared.ScopedCompanyID(r.Context(), 0, 1), 10)
 	}
 	companyID, err := strconv.ParseInt(companyStr, 10, 64)
 	if err != nil || companyID <= 0 {
@@ -510,23 +511,23 @@ func (h *Handler) buildViewModel(filters ui.DashboardFilters, data dashboardData
 	return vm, nil
 }
 
-func (h *Handler) handleFilterError(w http.ResponseWriter, err error) {
+func (h *Handler) handleFilterError(w http.ResponseWriter, r *http.Request, err error) {
 	var vErr validationError
 	if errors.As(err, &vErr) {
-		http.Error(w, "Parameter tidak valid", http.StatusBadRequest)
+		httpx.Error(w, r, "Parameter tidak valid", http.StatusBadRequest)
 		return
 	}
-	h.handleServerError(w, "parse filters", err)
+	h.handleServerError(w, r, "parse filters", err)
 }
 
-func (h *Handler) handleValidationFailure(w http.ResponseWriter, err error) {
+func (h *Handler) handleValidationFailure(w http.ResponseWriter, r *http.Request, err error) {
 	h.logError("validate period", err)
-	http.Error(w, "Periode tidak ditemukan", http.StatusBadRequest)
+	httpx.Error(w, r, "Periode tidak ditemukan", http.StatusBadRequest)
 }
 
-func (h *Handler) handleServerError(w http.ResponseWriter, context string, err error) {
+func (h *Handler) handleServerError(w http.ResponseWriter, r *http.Request, context string, err error) {
 	h.logError(context, err)
-	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 }
 
 func (h *Handler) logError(context string, err error) {
@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httprate"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
@@ -19,7 +20,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	limiter := httprate.Limit(10, time.Minute,
 		httprate.WithKeyFuncs(rateLimitKey),
 		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
-			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			httpx.Error(w, r, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
 		}),
 	)
 
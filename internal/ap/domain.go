@@ -1,6 +1,7 @@
 package ap
 
 import (
+	"math"
 	"time"
 )
 
@@ -27,15 +28,34 @@ type APInvoice struct {
 	TaxAmount    float64
 	Total        float64
 	Status       APInvoiceStatus
+	IssuedAt     time.Time
 	DueAt        time.Time
-	PostedAt     *time.Time
-	PostedBy     *int64
-	VoidedAt     *time.Time
-	VoidedBy     *int64
-	VoidReason   *string
-	CreatedBy    int64
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// PaymentTermsDays holds the supplier terms that produced DueAt; nil when
+	// the due date was entered by hand.
+	PaymentTermsDays *int
+	PostedAt         *time.Time
+	PostedBy         *int64
+	VoidedAt         *time.Time
+	VoidedBy         *int64
+	VoidReason       *string
+	CreatedBy        int64
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	// WithholdingRate and WithholdingAmount snapshot the PPh the supplier's
+	// payments are subject to; Withheld is what payments have withheld so far.
+	WithholdingTaxID  *int64
+	WithholdingCode   string
+	WithholdingRate   float64
+	WithholdingAmount float64
+	Withheld          float64
+}
+
+// WithholdingDue returns the withholding not yet deducted from payments.
+func (i APInvoice) WithholdingDue() float64 {
+	if due := i.WithholdingAmount - i.Withheld; due > 0 {
+		return due
+	}
+	return 0
 }
 
 // APInvoiceLine represents a line item on an AP invoice.
@@ -76,9 +96,12 @@ type APPayment struct {
 	PaidAt       time.Time
 	Method       string
 	Note         string
+	PaymentRunID *int64
 	CreatedBy    int64
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	// WithheldAmount is the tax withheld on top of the cash Amount paid.
+	WithheldAmount float64
 }
 
 // APPaymentSummary for display in invoice detail.
@@ -98,6 +121,7 @@ type APPaymentAllocation struct {
 	APPaymentID int64
 	APInvoiceID int64
 	Amount      float64
+	Withheld    float64
 	CreatedAt   time.Time
 }
 
@@ -112,6 +136,7 @@ type APPaymentAllocationDetail struct {
 	InvoiceTotal  float64
 	DueAt         time.Time
 	Amount        float64
+	Withheld      float64
 }
 
 // APPaymentWithDetails includes payment with allocation breakdown and ledger status.
@@ -144,13 +169,213 @@ type APAgingDetail struct {
 	Total        float64
 }
 
+// APAgingReport is the aging summary with its supplier breakdown.
+type APAgingReport struct {
+	AsOf      time.Time
+	Summary   APAgingBucket
+	Suppliers []APAgingDetail
+}
+
 // APInvoiceBalance represents an invoice balance for batch aging calculations.
 type APInvoiceBalance struct {
-	ID         int64
-	DueAt      time.Time
+	ID           int64
+	SupplierID   int64
+	SupplierName string
+	DueAt        time.Time
+	Total        float64
+	PaidAmount   float64
+	Balance      float64
+}
+
+// APPaymentRun model.
+type APPaymentRun struct {
+	ID              int64
+	Number          string
+	PayDate         time.Time
+	Method          string
+	Note            string
+	DueBefore       time.Time
+	SupplierID      *int64
+	CashLimit       float64
+	GroupBySupplier bool
+	Total           float64
+	PaymentCount    int
+	CreatedBy       int64
+	CreatedAt       time.Time
+}
+
+// PaymentRunInvoice is a posted invoice with an open balance considered by a
+// payment run. PaymentID and PaymentNumber are set once the run is created.
+type PaymentRunInvoice struct {
+	InvoiceID     int64
+	Number        string
+	SupplierID    int64
+	SupplierName  string
+	Currency      string
+	DueAt         time.Time
+	Amount        float64
+	PaymentID     int64
+	PaymentNumber string
+	// Withheld is the withholding deducted when the run settles Amount.
+	Withheld float64
+}
+
+// Net returns the cash the run pays for the invoice.
+func (i PaymentRunInvoice) Net() float64 {
+	return roundAmount(i.Amount - i.Withheld)
+}
+
+// PaymentRunSupplier groups the invoices a run pays to one supplier in one
+// currency. Total is the cash paid, after withholding.
+type PaymentRunSupplier struct {
+	SupplierID   int64
+	SupplierName string
+	Currency     string
+	Invoices     []PaymentRunInvoice
+	Total        float64
+	Withheld     float64
+}
+
+// PaymentRunPreview is the proposed selection shown before a run is created.
+type PaymentRunPreview struct {
+	Filter    PaymentRunFilter
+	Suppliers []PaymentRunSupplier
+	Deferred  []PaymentRunInvoice
+	Total     float64
+}
+
+// APPaymentRunWithDetails includes the per-supplier breakdown of a run.
+type APPaymentRunWithDetails struct {
+	APPaymentRun
+	Suppliers    []PaymentRunSupplier
+	LedgerPosted bool
+}
+
+// RemittanceAdvice tells one supplier which invoices a payment run settled.
+type RemittanceAdvice struct {
+	RunNumber string
+	PayDate   time.Time
+	Method    string
+	Note      string
+	Supplier  PaymentRunSupplier
+}
+
+// GRIRFilter selects the goods-received-not-invoiced accruals to report.
+type GRIRFilter struct {
+	AsOf       time.Time
+	SupplierID int64
+}
+
+// GRIROutstanding is a posted GRN whose received value is not yet fully
+// covered by posted AP invoices as of the report date.
+type GRIROutstanding struct {
+	GRNID         int64
+	GRNNumber     string
+	SupplierID    int64
+	SupplierName  string
+	ReceivedAt    time.Time
+	ReceivedValue float64
+	InvoicedValue float64
+	InvoiceCount  int
+	Accrued       float64
+	AgeDays       int
+}
+
+// PartiallyInvoiced reports whether some of the receipt has been invoiced.
+func (o GRIROutstanding) PartiallyInvoiced() bool {
+	return o.InvoicedValue > 0
+}
+
+// GRIRSupplier totals the outstanding accrual for one supplier.
+type GRIRSupplier struct {
+	SupplierID   int64
+	SupplierName string
+	GRNs         []GRIROutstanding
+	Accrued      float64
+}
+
+// GRIRLedger is the balance of the account mapped to grn.grir, credit positive.
+type GRIRLedger struct {
+	AccountCode string
+	AccountName string
+	Balance     float64
+}
+
+// GRIRReport lists outstanding GR/IR accruals per supplier. Ledger is only
+// filled for unfiltered reports, since the GL balance is not kept per supplier.
+type GRIRReport struct {
+	Filter     GRIRFilter
+	Suppliers  []GRIRSupplier
 	Total      float64
-	PaidAmount float64
-	Balance    float64
+	Ledger     *GRIRLedger
+	Difference float64
+}
+
+// Reconciled reports whether the accrual total ties to the GL balance.
+func (r GRIRReport) Reconciled() bool {
+	return r.Ledger != nil && math.Abs(r.Difference) < cashTolerance
+}
+
+// WithholdingTax is a tax such as PPh 23 that is deducted from supplier
+// payments and remitted to the tax office instead.
+type WithholdingTax struct {
+	ID   int64
+	Code string
+	Name string
+	Rate float64
+}
+
+// WithholdingFilter selects the payments whose withholding is reported.
+type WithholdingFilter struct {
+	From       time.Time
+	To         time.Time
+	SupplierID int64
+}
+
+// WithholdingLine is the tax one payment withheld against one invoice. Base
+// is the share of the invoice subtotal (DPP) the payment settled.
+type WithholdingLine struct {
+	PaymentID     int64
+	PaymentNumber string
+	PaidAt        time.Time
+	InvoiceID     int64
+	InvoiceNumber string
+	SupplierID    int64
+	SupplierName  string
+	SupplierTaxID string
+	TaxCode       string
+	Rate          float64
+	Currency      string
+	Base          float64
+	Withheld      float64
+}
+
+// WithholdingSupplier totals the withholding of one supplier per tax and
+// currency, the unit a withholding slip is issued for.
+type WithholdingSupplier struct {
+	SupplierID    int64
+	SupplierName  string
+	SupplierTaxID string
+	TaxCode       string
+	Currency      string
+	Lines         []WithholdingLine
+	Base          float64
+	Withheld      float64
+}
+
+// WithholdingTotal sums the withholding of one tax and currency.
+type WithholdingTotal struct {
+	TaxCode  string
+	Currency string
+	Base     float64
+	Withheld float64
+}
+
+// WithholdingReport summarises the tax withheld from supplier payments.
+type WithholdingReport struct {
+	Filter    WithholdingFilter
+	Suppliers []WithholdingSupplier
+	Totals    []WithholdingTotal
 }
 
 // --- Input DTOs ---
@@ -158,16 +383,29 @@ type APInvoiceBalance struct {
 // CreateAPInvoiceInput for creating AP invoices.
 type CreateAPInvoiceInput struct {
 	SupplierID int64
-	GRNID      *int64
-	POID       *int64
-	Number     string
-	Currency   string
-	Subtotal   float64
-	TaxAmount  float64
-	Total      float64
-	DueDate    time.Time
-	CreatedBy  int64
-	Lines      []CreateAPInvoiceLineInput
+	// CompanyID owns the invoice and selects its due date policy. It is
+	// taken from the supplier.
+	CompanyID int64
+	GRNID     *int64
+	POID      *int64
+	Number    string
+	Currency  string
+	Subtotal  float64
+	TaxAmount float64
+	Total     float64
+	// IssuedAt defaults to today. A zero DueDate is derived from IssuedAt
+	// plus the supplier's payment terms.
+	IssuedAt         time.Time
+	DueDate          time.Time
+	PaymentTermsDays *int
+	CreatedBy        int64
+	Lines            []CreateAPInvoiceLineInput
+	// WithholdingTaxID nil applies the supplier's default withholding tax; a
+	// zero ID opts the invoice out. The rate and amount are filled in by the
+	// service.
+	WithholdingTaxID  *int64
+	WithholdingRate   float64
+	WithholdingAmount float64
 }
 
 // CreateAPInvoiceLineInput for invoice line items.
@@ -184,17 +422,29 @@ type CreateAPInvoiceLineInput struct {
 // CreateAPInvoiceFromGRNInput creates invoice from goods receipt.
 type CreateAPInvoiceFromGRNInput struct {
 	GRNID     int64
+	IssuedAt  time.Time
 	DueDate   time.Time
 	CreatedBy int64
 	Number    string
+	// WithholdingTaxID follows CreateAPInvoiceInput.WithholdingTaxID.
+	WithholdingTaxID *int64
+	// InputTaxID selects the tax charged on every line: nil takes the
+	// supplier's default tax and 0 charges none.
+	InputTaxID *int64
 }
 
 // CreateAPInvoiceFromPOInput creates invoice from purchase order.
 type CreateAPInvoiceFromPOInput struct {
 	POID      int64
+	IssuedAt  time.Time
 	DueDate   time.Time
 	CreatedBy int64
 	Number    string
+	// WithholdingTaxID follows CreateAPInvoiceInput.WithholdingTaxID.
+	WithholdingTaxID *int64
+	// InputTaxID selects the tax charged on every line: nil takes the
+	// supplier's default tax and 0 charges none.
+	InputTaxID *int64
 }
 
 // PostAPInvoiceInput for posting an invoice.
@@ -212,28 +462,54 @@ type VoidAPInvoiceInput struct {
 
 // CreateAPPaymentInput for creating AP payments.
 type CreateAPPaymentInput struct {
-	Number      string
-	SupplierID  int64
-	Amount      float64
-	PaidAt      time.Time
-	Method      string
-	Note        string
-	CreatedBy   int64
-	Allocations []PaymentAllocationInput
+	Number       string
+	SupplierID   int64
+	Amount       float64
+	PaidAt       time.Time
+	Method       string
+	Note         string
+	CreatedBy    int64
+	PaymentRunID *int64
+	Allocations  []PaymentAllocationInput
+	// WithheldAmount is filled in by the service from the allocations.
+	WithheldAmount float64
 }
 
-// PaymentAllocationInput for allocating payment to invoices.
+// PaymentAllocationInput for allocating payment to invoices. Amount settles
+// the invoice gross; Withheld is the part of it kept back as tax.
 type PaymentAllocationInput struct {
 	APInvoiceID int64
 	Amount      float64
+	Withheld    float64
 }
 
 // ListAPInvoicesRequest for filtering invoices.
 type ListAPInvoicesRequest struct {
 	Status     APInvoiceStatus
 	SupplierID int64
-	FromDate   time.Time
-	ToDate     time.Time
-	Limit      int
-	Offset     int
+	// CompanyID limits the list to invoices booked for one company when set.
+	CompanyID int64
+	FromDate  time.Time
+	ToDate    time.Time
+	Limit     int
+	Offset    int
+}
+
+// PaymentRunFilter selects payable invoices for a payment run. A zero
+// CashLimit means every due invoice is selected.
+type PaymentRunFilter struct {
+	DueBefore  time.Time
+	SupplierID int64
+	CashLimit  float64
+}
+
+// CreatePaymentRunInput pays the selected invoices in full in one run.
+type CreatePaymentRunInput struct {
+	Filter          PaymentRunFilter
+	InvoiceIDs      []int64
+	PayDate         time.Time
+	Method          string
+	Note            string
+	GroupBySupplier bool
+	CreatedBy       int64
 }
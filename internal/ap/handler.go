@@ -10,6 +10,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -17,12 +19,13 @@ import (
 
 // Handler manages AP endpoints.
 type Handler struct {
-	logger    *slog.Logger
-	service   *Service
-	templates *view.Engine
-	csrf      *shared.CSRFManager
-	sessions  *shared.SessionManager
-	rbac      rbac.Middleware
+	logger     *slog.Logger
+	service    *Service
+	templates  *view.Engine
+	csrf       *shared.CSRFManager
+	sessions   *shared.SessionManager
+	rbac       rbac.Middleware
+	remittance *RemittanceExporter
 }
 
 // NewHandler builds Handler instance.
@@ -30,6 +33,11 @@ func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, c
 	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, sessions: sessions, rbac: rbac}
 }
 
+// SetRemittanceExporter enables remittance advice PDFs for payment runs.
+func (h *Handler) SetRemittanceExporter(exporter *RemittanceExporter) {
+	h.remittance = exporter
+}
+
 // MountRoutes registers AP routes.
 func (h *Handler) MountRoutes(r chi.Router) {
 	// View routes
@@ -43,7 +51,13 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Get("/payments", h.listPayments)
 		r.Get("/payments/new", h.showCreatePaymentForm)
 		r.Get("/payments/{id}", h.showPaymentDetail)
+		r.Get("/payment-runs", h.listPaymentRuns)
+		r.Get("/payment-runs/new", h.showPaymentRunPreview)
+		r.Get("/payment-runs/{id}", h.showPaymentRun)
+		r.Get("/payment-runs/{id}/remittance/{supplierID}", h.remittancePDF)
 		r.Get("/aging", h.showAPAgingReport)
+		r.Get("/grir", h.showGRIRReport)
+		r.Get("/withholding", h.showWithholdingReport)
 	})
 
 	// Create/Action routes
@@ -52,8 +66,9 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.With(h.rbac.RequireAny("finance.ap.create")).Post("/invoices/from-grn/{grnID}", h.createInvoiceFromGRN)
 		r.With(h.rbac.RequireAny("finance.ap.create")).Post("/invoices/from-po/{poID}", h.createInvoiceFromPO)
 		r.With(h.rbac.RequireAny("finance.ap.post")).Post("/invoices/{id}/post", h.postInvoice)
-		r.With(h.rbac.RequireAny("finance.ap.void")).Post("/invoices/{id}/void", h.voidInvoice)
+		r.With(h.rbac.RequireAll("finance.ap.edit")).Post("/invoices/{id}/void", h.voidInvoice)
 		r.With(h.rbac.RequireAny("finance.ap.payment")).Post("/payments", h.createAPPayment)
+		r.With(h.rbac.RequireAny("finance.ap.payment")).Post("/payment-runs", h.createPaymentRun)
 	})
 }
 
@@ -74,7 +89,7 @@ func (h *Handler) listInvoices(w http.ResponseWriter, r *http.Request) {
 		Limit:      100,
 	})
 	if err != nil {
-		h.logger.Error("list AP invoices", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "list AP invoices", slog.Any("error", err))
 		h.render(w, r, "pages/ap/ap_invoice_list.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusInternalServerError)
@@ -93,13 +108,13 @@ func (h *Handler) showInvoiceDetail(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid invoice ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid invoice ID", http.StatusBadRequest)
 		return
 	}
 
 	invoice, err := h.service.GetAPInvoiceWithDetails(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get AP invoice", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.ErrorContext(r.Context(), "get AP invoice", slog.Any("error", err), slog.Int64("id", id))
 		h.render(w, r, "pages/ap/ap_invoice_form.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusNotFound)
@@ -113,15 +128,59 @@ func (h *Handler) showInvoiceDetail(w http.ResponseWriter, r *http.Request) {
 
 // showCreateInvoiceForm shows the create invoice form.
 func (h *Handler) showCreateInvoiceForm(w http.ResponseWriter, r *http.Request) {
-	h.render(w, r, "pages/ap/ap_invoice_form.html", map[string]any{
-		"Errors": formErrors{},
-	}, http.StatusOK)
+	h.render(w, r, "pages/ap/ap_invoice_form.html", h.invoiceFormData(r, formErrors{}), http.StatusOK)
+}
+
+// invoiceFormData loads the withholding taxes offered on the invoice form.
+func (h *Handler) invoiceFormData(r *http.Request, errs formErrors) map[string]any {
+	taxes, err := h.service.ListWithholdingTaxes(r.Context())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "list withholding taxes", slog.Any("error", err))
+	}
+	inputTaxes, err := h.service.ListInputTaxes(r.Context())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "list input taxes", slog.Any("error", err))
+	}
+	return map[string]any{
+		"Errors":           errs,
+		"WithholdingTaxes": taxes,
+		"InputTaxes":       inputTaxes,
+	}
+}
+
+// withInputTaxNotice appends the supplier default input tax to the flash when
+// the invoice took it.
+func (h *Handler) withInputTaxNotice(r *http.Request, message string, invoice APInvoice, inputTaxID *int64) string {
+	if inputTaxID != nil {
+		return message
+	}
+	notice, err := h.service.InputTaxNotice(r.Context(), invoice.SupplierID, invoice.IssuedAt)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "load supplier default tax", slog.Any("error", err), slog.Int64("supplier_id", invoice.SupplierID))
+	}
+	if notice == "" {
+		return message
+	}
+	return message + ". " + notice
+}
+
+// parseWithholdingTaxID reads the invoice withholding or input tax choice:
+// blank keeps the supplier default (nil) and "0" opts out.
+func parseWithholdingTaxID(value string) *int64 {
+	if value == "" {
+		return nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return nil
+	}
+	return &id
 }
 
 // createAPInvoice handles manual invoice creation.
 func (h *Handler) createAPInvoice(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
@@ -137,57 +196,59 @@ func (h *Handler) createAPInvoice(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if sourceIDStr == "" {
-		h.render(w, r, "pages/ap/ap_invoice_form.html", map[string]any{
-			"Errors": formErrors{"general": "Source ID is required"},
-		}, http.StatusBadRequest)
+		h.render(w, r, "pages/ap/ap_invoice_form.html", h.invoiceFormData(r, formErrors{"general": "Source ID is required"}), http.StatusBadRequest)
 		return
 	}
 	sourceID, err := strconv.ParseInt(sourceIDStr, 10, 64)
 	if err != nil {
-		h.render(w, r, "pages/ap/ap_invoice_form.html", map[string]any{
-			"Errors": formErrors{"general": "Invalid source ID"},
-		}, http.StatusBadRequest)
+		h.render(w, r, "pages/ap/ap_invoice_form.html", h.invoiceFormData(r, formErrors{"general": "Invalid source ID"}), http.StatusBadRequest)
 		return
 	}
 
+	// A blank due date falls back to the supplier's payment terms.
+	issuedAt, _ := time.Parse("2006-01-02", r.PostFormValue("issued_date"))
 	dueDate, _ := time.Parse("2006-01-02", r.PostFormValue("due_date"))
-	if dueDate.IsZero() {
-		dueDate = time.Now().AddDate(0, 0, 30)
-	}
 
 	sess := shared.SessionFromContext(r.Context())
 	userID := getUserID(sess)
 	number := r.PostFormValue("number")
+	withholdingTaxID := parseWithholdingTaxID(r.PostFormValue("withholding_tax_id"))
+	inputTaxID := parseWithholdingTaxID(r.PostFormValue("input_tax_id"))
 
 	var invoice APInvoice
 	switch sourceType {
 	case "grn":
 		invoice, err = h.service.CreateAPInvoiceFromGRN(r.Context(), CreateAPInvoiceFromGRNInput{
-			GRNID:     sourceID,
-			DueDate:   dueDate,
-			CreatedBy: userID,
-			Number:    number,
+			GRNID:            sourceID,
+			IssuedAt:         issuedAt,
+			DueDate:          dueDate,
+			CreatedBy:        userID,
+			Number:           number,
+			WithholdingTaxID: withholdingTaxID,
+			InputTaxID:       inputTaxID,
 		})
 	case "po":
 		invoice, err = h.service.CreateAPInvoiceFromPO(r.Context(), CreateAPInvoiceFromPOInput{
-			POID:      sourceID,
-			DueDate:   dueDate,
-			CreatedBy: userID,
-			Number:    number,
+			POID:             sourceID,
+			IssuedAt:         issuedAt,
+			DueDate:          dueDate,
+			CreatedBy:        userID,
+			Number:           number,
+			WithholdingTaxID: withholdingTaxID,
+			InputTaxID:       inputTaxID,
 		})
 	default:
 		err = fmt.Errorf("unsupported source type")
 	}
 
 	if err != nil {
-		h.logger.Error("create AP invoice", slog.Any("error", err))
-		h.render(w, r, "pages/ap/ap_invoice_form.html", map[string]any{
-			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
-		}, http.StatusBadRequest)
+		h.logger.ErrorContext(r.Context(), "create AP invoice", slog.Any("error", err))
+		h.render(w, r, "pages/ap/ap_invoice_form.html", h.invoiceFormData(r, formErrors{"general": shared.UserSafeMessage(err)}), http.StatusBadRequest)
 		return
 	}
 
-	h.redirectWithFlash(w, r, "/finance/ap/invoices/"+strconv.FormatInt(invoice.ID, 10), "success", "AP Invoice created")
+	h.redirectWithFlash(w, r, "/finance/ap/invoices/"+strconv.FormatInt(invoice.ID, 10), "success",
+		h.withInputTaxNotice(r, "AP Invoice created", invoice, inputTaxID))
 }
 
 // createInvoiceFromGRN creates invoice from goods receipt.
@@ -195,19 +256,18 @@ func (h *Handler) createInvoiceFromGRN(w http.ResponseWriter, r *http.Request) {
 	grnIDStr := chi.URLParam(r, "grnID")
 	grnID, err := strconv.ParseInt(grnIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid GRN ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid GRN ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
+	// A blank due date falls back to the supplier's payment terms.
+	issuedAt, _ := time.Parse("2006-01-02", r.PostFormValue("issued_date"))
 	dueDate, _ := time.Parse("2006-01-02", r.PostFormValue("due_date"))
-	if dueDate.IsZero() {
-		dueDate = time.Now().AddDate(0, 0, 30) // Default 30 days
-	}
 	number := r.PostFormValue("number")
 
 	sess := shared.SessionFromContext(r.Context())
@@ -215,17 +275,19 @@ func (h *Handler) createInvoiceFromGRN(w http.ResponseWriter, r *http.Request) {
 
 	invoice, err := h.service.CreateAPInvoiceFromGRN(r.Context(), CreateAPInvoiceFromGRNInput{
 		GRNID:     grnID,
+		IssuedAt:  issuedAt,
 		DueDate:   dueDate,
 		CreatedBy: userID,
 		Number:    number,
 	})
 	if err != nil {
-		h.logger.Error("create invoice from GRN", slog.Any("error", err), slog.Int64("grn_id", grnID))
+		h.logger.ErrorContext(r.Context(), "create invoice from GRN", slog.Any("error", err), slog.Int64("grn_id", grnID))
 		h.redirectWithFlash(w, r, "/procurement/grns", "error", shared.UserSafeMessage(err))
 		return
 	}
 
-	h.redirectWithFlash(w, r, "/finance/ap/invoices/"+strconv.FormatInt(invoice.ID, 10), "success", "Invoice created from GRN")
+	h.redirectWithFlash(w, r, "/finance/ap/invoices/"+strconv.FormatInt(invoice.ID, 10), "success",
+		h.withInputTaxNotice(r, "Invoice created from GRN", invoice, nil))
 }
 
 // createInvoiceFromPO creates invoice from purchase order.
@@ -233,19 +295,18 @@ func (h *Handler) createInvoiceFromPO(w http.ResponseWriter, r *http.Request) {
 	poIDStr := chi.URLParam(r, "poID")
 	poID, err := strconv.ParseInt(poIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid PO ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid PO ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
+	// A blank due date falls back to the supplier's payment terms.
+	issuedAt, _ := time.Parse("2006-01-02", r.PostFormValue("issued_date"))
 	dueDate, _ := time.Parse("2006-01-02", r.PostFormValue("due_date"))
-	if dueDate.IsZero() {
-		dueDate = time.Now().AddDate(0, 0, 30)
-	}
 	number := r.PostFormValue("number")
 
 	sess := shared.SessionFromContext(r.Context())
@@ -253,24 +314,26 @@ func (h *Handler) createInvoiceFromPO(w http.ResponseWriter, r *http.Request) {
 
 	invoice, err := h.service.CreateAPInvoiceFromPO(r.Context(), CreateAPInvoiceFromPOInput{
 		POID:      poID,
+		IssuedAt:  issuedAt,
 		DueDate:   dueDate,
 		CreatedBy: userID,
 		Number:    number,
 	})
 	if err != nil {
-		h.logger.Error("create invoice from PO", slog.Any("error", err), slog.Int64("po_id", poID))
+		h.logger.ErrorContext(r.Context(), "create invoice from PO", slog.Any("error", err), slog.Int64("po_id", poID))
 		h.redirectWithFlash(w, r, "/procurement/pos", "error", shared.UserSafeMessage(err))
 		return
 	}
 
-	h.redirectWithFlash(w, r, "/finance/ap/invoices/"+strconv.FormatInt(invoice.ID, 10), "success", "Invoice created from PO")
+	h.redirectWithFlash(w, r, "/finance/ap/invoices/"+strconv.FormatInt(invoice.ID, 10), "success",
+		h.withInputTaxNotice(r, "Invoice created from PO", invoice, nil))
 }
 
 func (h *Handler) postInvoice(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid invoice ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid invoice ID", http.StatusBadRequest)
 		return
 	}
 
@@ -281,7 +344,7 @@ func (h *Handler) postInvoice(w http.ResponseWriter, r *http.Request) {
 		InvoiceID: id,
 		PostedBy:  userID,
 	}); err != nil {
-		h.logger.Error("post AP invoice", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.ErrorContext(r.Context(), "post AP invoice", slog.Any("error", err), slog.Int64("id", id))
 		h.redirectWithFlash(w, r, "/finance/ap/invoices/"+idStr, "error", shared.UserSafeMessage(err))
 		return
 	}
@@ -293,12 +356,12 @@ func (h *Handler) voidInvoice(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid invoice ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid invoice ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
@@ -311,18 +374,34 @@ func (h *Handler) voidInvoice(w http.ResponseWriter, r *http.Request) {
 		VoidedBy:   userID,
 		VoidReason: reason,
 	}); err != nil {
-		h.logger.Error("void AP invoice", slog.Any("error", err), slog.Int64("id", id))
-		h.redirectWithFlash(w, r, "/finance/ap/invoices/"+idStr, "error", shared.UserSafeMessage(err))
+		h.logger.ErrorContext(r.Context(), "void AP invoice", slog.Any("error", err), slog.Int64("id", id))
+		h.redirectWithFlash(w, r, "/finance/ap/invoices/"+idStr, "error", voidErrorMessage(err))
 		return
 	}
 
 	h.redirectWithFlash(w, r, "/finance/ap/invoices/"+idStr, "success", "Invoice voided")
 }
 
+// voidErrorMessage explains why a void was refused and hides everything else
+// behind the generic user-safe message.
+func voidErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrVoidReasonRequired):
+		return "Enter a reason for voiding this invoice."
+	case errors.Is(err, ErrInvalidStatus):
+		return "Only draft or posted invoices without payments can be voided."
+	case errors.Is(err, accounting.ErrPeriodLocked):
+		return "The invoice's posting period is closed, so it can no longer be voided."
+	case errors.Is(err, accounting.ErrInvalidPeriod):
+		return "No accounting period covers the invoice's posting date."
+	}
+	return shared.UserSafeMessage(err)
+}
+
 func (h *Handler) listPayments(w http.ResponseWriter, r *http.Request) {
 	payments, err := h.service.ListAPPayments(r.Context())
 	if err != nil {
-		h.logger.Error("list AP payments", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "list AP payments", slog.Any("error", err))
 		h.render(w, r, "pages/ap/ap_payment_list.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusInternalServerError)
@@ -352,13 +431,13 @@ func (h *Handler) showPaymentDetail(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid payment ID", http.StatusBadRequest)
 		return
 	}
 
 	payment, err := h.service.GetAPPaymentWithDetails(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get AP payment", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.ErrorContext(r.Context(), "get AP payment", slog.Any("error", err), slog.Int64("id", id))
 		h.render(w, r, "pages/ap/ap_payment_list.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusNotFound)
@@ -372,7 +451,7 @@ func (h *Handler) showPaymentDetail(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) createAPPayment(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
@@ -423,7 +502,7 @@ func (h *Handler) createAPPayment(w http.ResponseWriter, r *http.Request) {
 			h.redirectWithFlash(w, r, "/finance/ap/payments/"+strconv.FormatInt(payment.ID, 10), "warning", message)
 			return
 		}
-		h.logger.Error("create AP payment", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "create AP payment", slog.Any("error", err))
 		invoices, _ := h.service.ListAPInvoices(r.Context(), ListAPInvoicesRequest{
 			Status: APStatusPosted,
 			Limit:  100,
@@ -441,7 +520,7 @@ func (h *Handler) createAPPayment(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) showAPAgingReport(w http.ResponseWriter, r *http.Request) {
 	aging, err := h.service.CalculateAPAging(r.Context(), time.Now())
 	if err != nil {
-		h.logger.Error("calculate AP aging", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "calculate AP aging", slog.Any("error", err))
 		h.render(w, r, "pages/ap/ap_aging_report.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusInternalServerError)
@@ -472,7 +551,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
 	}
 }
 
@@ -0,0 +1,40 @@
+package ap
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// showGRIRReport lists goods received but not yet invoiced, for AP to clear
+// stale accruals at period end.
+func (h *Handler) showGRIRReport(w http.ResponseWriter, r *http.Request) {
+	filter := parseGRIRFilter(r.URL.Query())
+	report, err := h.service.GRIRReport(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "GR/IR report", slog.Any("error", err))
+		h.render(w, r, "pages/ap/ap_grir_report.html", map[string]any{
+			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
+			"Report": GRIRReport{Filter: filter},
+		}, http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/ap/ap_grir_report.html", map[string]any{
+		"Errors": formErrors{},
+		"Report": report,
+	}, http.StatusOK)
+}
+
+func parseGRIRFilter(values url.Values) GRIRFilter {
+	filter := GRIRFilter{}
+	filter.AsOf, _ = time.Parse("2006-01-02", values.Get("as_of"))
+	filter.SupplierID, _ = strconv.ParseInt(values.Get("supplier_id"), 10, 64)
+	if filter.AsOf.IsZero() {
+		filter.AsOf = time.Now()
+	}
+	return filter
+}
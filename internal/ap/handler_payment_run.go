@@ -0,0 +1,177 @@
+package ap
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func (h *Handler) listPaymentRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.service.ListPaymentRuns(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list AP payment runs", slog.Any("error", err))
+		h.render(w, r, "pages/ap/ap_payment_run_list.html", map[string]any{
+			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
+		}, http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/ap/ap_payment_run_list.html", map[string]any{
+		"Runs": runs,
+	}, http.StatusOK)
+}
+
+// showPaymentRunPreview lists the invoices a run would pay for the filter in the query string.
+func (h *Handler) showPaymentRunPreview(w http.ResponseWriter, r *http.Request) {
+	filter := parsePaymentRunFilter(r.URL.Query())
+	preview, err := h.service.PreviewPaymentRun(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "preview AP payment run", slog.Any("error", err))
+		h.render(w, r, "pages/ap/ap_payment_run_form.html", map[string]any{
+			"Errors":  formErrors{"general": paymentRunErrorMessage(err)},
+			"Preview": PaymentRunPreview{Filter: filter},
+			"PayDate": time.Now(),
+		}, http.StatusBadRequest)
+		return
+	}
+	h.render(w, r, "pages/ap/ap_payment_run_form.html", map[string]any{
+		"Errors":  formErrors{},
+		"Preview": preview,
+		"PayDate": time.Now(),
+	}, http.StatusOK)
+}
+
+func (h *Handler) createPaymentRun(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	filter := parsePaymentRunFilter(r.PostForm)
+	payDate, _ := time.Parse("2006-01-02", r.PostFormValue("pay_date"))
+	var invoiceIDs []int64
+	for _, raw := range r.PostForm["invoice_id"] {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			invoiceIDs = append(invoiceIDs, id)
+		}
+	}
+
+	run, err := h.service.CreatePaymentRun(r.Context(), CreatePaymentRunInput{
+		Filter:          filter,
+		InvoiceIDs:      invoiceIDs,
+		PayDate:         payDate,
+		Method:          r.PostFormValue("method"),
+		Note:            r.PostFormValue("note"),
+		GroupBySupplier: r.PostFormValue("group_by_supplier") == "on",
+		CreatedBy:       getUserID(shared.SessionFromContext(r.Context())),
+	})
+	if err != nil {
+		var ledgerErr *LedgerPostError
+		if errors.As(err, &ledgerErr) && run.ID != 0 {
+			message := ledgerErr.Error()
+			if ledgerErr.Retryable {
+				message = message + ". Retry posting after updating ledger period/mapping."
+			}
+			h.redirectWithFlash(w, r, "/finance/ap/payment-runs/"+strconv.FormatInt(run.ID, 10), "warning", message)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "create AP payment run", slog.Any("error", err))
+		h.redirectWithFlash(w, r, "/finance/ap/payment-runs/new?"+paymentRunQuery(filter), "error", paymentRunErrorMessage(err))
+		return
+	}
+
+	h.redirectWithFlash(w, r, "/finance/ap/payment-runs/"+strconv.FormatInt(run.ID, 10), "success",
+		fmt.Sprintf("Payment run %s created with %d payment(s)", run.Number, run.PaymentCount))
+}
+
+func (h *Handler) showPaymentRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid payment run ID", http.StatusBadRequest)
+		return
+	}
+	run, err := h.service.GetPaymentRun(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get AP payment run", slog.Any("error", err), slog.Int64("id", id))
+		h.render(w, r, "pages/ap/ap_payment_run_list.html", map[string]any{
+			"Errors": formErrors{"general": paymentRunErrorMessage(err)},
+		}, http.StatusNotFound)
+		return
+	}
+	h.render(w, r, "pages/ap/ap_payment_run_detail.html", map[string]any{
+		"Run":           run,
+		"CanRemittance": h.remittance != nil,
+	}, http.StatusOK)
+}
+
+func (h *Handler) remittancePDF(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid payment run ID", http.StatusBadRequest)
+		return
+	}
+	supplierID, err := strconv.ParseInt(chi.URLParam(r, "supplierID"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+	if h.remittance == nil {
+		httpx.Error(w, r, "PDF export not configured", http.StatusServiceUnavailable)
+		return
+	}
+	advice, err := h.service.Remittance(r.Context(), id, supplierID, r.URL.Query().Get("currency"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load remittance advice", slog.Any("error", err), slog.Int64("run_id", id), slog.Int64("supplier_id", supplierID))
+		httpx.Error(w, r, paymentRunErrorMessage(err), http.StatusNotFound)
+		return
+	}
+	pdf, err := h.remittance.Render(r.Context(), advice)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "render remittance pdf", slog.Any("error", err), slog.Int64("run_id", id))
+		httpx.Error(w, r, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=remittance-%s-%d-%s.pdf", advice.RunNumber, supplierID, advice.Supplier.Currency))
+	_, _ = w.Write(pdf)
+}
+
+func parsePaymentRunFilter(values url.Values) PaymentRunFilter {
+	filter := PaymentRunFilter{}
+	filter.DueBefore, _ = time.Parse("2006-01-02", values.Get("due_before"))
+	filter.SupplierID, _ = strconv.ParseInt(values.Get("supplier_id"), 10, 64)
+	filter.CashLimit, _ = strconv.ParseFloat(values.Get("cash_limit"), 64)
+	return filter
+}
+
+func paymentRunQuery(filter PaymentRunFilter) string {
+	values := url.Values{}
+	if !filter.DueBefore.IsZero() {
+		values.Set("due_before", filter.DueBefore.Format("2006-01-02"))
+	}
+	if filter.SupplierID != 0 {
+		values.Set("supplier_id", strconv.FormatInt(filter.SupplierID, 10))
+	}
+	if filter.CashLimit > 0 {
+		values.Set("cash_limit", strconv.FormatFloat(filter.CashLimit, 'f', 2, 64))
+	}
+	return values.Encode()
+}
+
+// paymentRunErrorMessage shows payment run validation errors verbatim and
+// hides everything else behind the generic user-safe message.
+func paymentRunErrorMessage(err error) string {
+	for _, known := range []error{ErrPaymentRunNotFound, ErrPaymentRunEmpty, ErrPaymentRunStale, ErrCashLimitExceeded, ErrInvalidCashLimit} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
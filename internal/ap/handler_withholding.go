@@ -0,0 +1,80 @@
+package ap
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// showWithholdingReport summarises the PPh withheld from supplier payments
+// for the period, for filing and issuing withholding slips. format=csv
+// downloads the lines instead.
+func (h *Handler) showWithholdingReport(w http.ResponseWriter, r *http.Request) {
+	filter := parseWithholdingFilter(r.URL.Query())
+	report, err := h.service.WithholdingReport(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "withholding report", slog.Any("error", err))
+		h.render(w, r, "pages/ap/ap_withholding_report.html", map[string]any{
+			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
+			"Report": WithholdingReport{Filter: filter},
+		}, http.StatusInternalServerError)
+		return
+	}
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=ap-withholding-%s.csv", report.Filter.From.Format("2006-01")))
+		if err := writeWithholdingCSV(w, report); err != nil {
+			h.logger.ErrorContext(r.Context(), "withholding report csv", slog.Any("error", err))
+		}
+		return
+	}
+	h.render(w, r, "pages/ap/ap_withholding_report.html", map[string]any{
+		"Errors": formErrors{},
+		"Report": report,
+	}, http.StatusOK)
+}
+
+func parseWithholdingFilter(values url.Values) WithholdingFilter {
+	filter := WithholdingFilter{}
+	filter.From, _ = time.Parse("2006-01-02", values.Get("from"))
+	filter.To, _ = time.Parse("2006-01-02", values.Get("to"))
+	filter.SupplierID, _ = strconv.ParseInt(values.Get("supplier_id"), 10, 64)
+	return filter
+}
+
+func writeWithholdingCSV(w io.Writer, report WithholdingReport) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{
+		"supplier", "supplier_tax_id", "tax_code", "rate", "payment", "paid_at",
+		"invoice", "currency", "base", "withheld",
+	}); err != nil {
+		return err
+	}
+	for _, supplier := range report.Suppliers {
+		for _, line := range supplier.Lines {
+			if err := out.Write([]string{
+				line.SupplierName,
+				line.SupplierTaxID,
+				line.TaxCode,
+				strconv.FormatFloat(line.Rate, 'f', 2, 64),
+				line.PaymentNumber,
+				line.PaidAt.Format("2006-01-02"),
+				line.InvoiceNumber,
+				line.Currency,
+				strconv.FormatFloat(line.Base, 'f', 2, 64),
+				strconv.FormatFloat(line.Withheld, 'f', 2, 64),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
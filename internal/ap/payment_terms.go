@@ -0,0 +1,32 @@
+package ap
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// applyPaymentTerms dates the invoice. IssuedAt defaults to today; a missing
+// due date is derived from the supplier's payment terms and the terms are kept
+// on the invoice, while an explicit due date is treated as a manual override.
+func (s *Service) applyPaymentTerms(ctx context.Context, input *CreateAPInvoiceInput) error {
+	if input.IssuedAt.IsZero() {
+		input.IssuedAt = time.Now()
+	}
+	input.IssuedAt = truncateDay(input.IssuedAt)
+	input.PaymentTermsDays = nil
+	if !input.DueDate.IsZero() {
+		return nil
+	}
+	days, err := s.repo.SupplierPaymentTerms(ctx, input.SupplierID)
+	if err != nil {
+		return fmt.Errorf("load supplier payment terms: %w", err)
+	}
+	input.DueDate = input.IssuedAt.AddDate(0, 0, days)
+	input.PaymentTermsDays = &days
+	return nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,67 @@
+package ap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+	"github.com/odyssey-erp/odyssey-erp/web"
+)
+
+// PDFRenderer converts HTML documents into PDF bytes.
+type PDFRenderer interface {
+	RenderHTML(ctx context.Context, html string) ([]byte, error)
+}
+
+// RemittanceExporter renders supplier remittance advices as PDF.
+type RemittanceExporter struct {
+	renderer  PDFRenderer
+	templates *template.Template
+}
+
+// NewRemittanceExporter parses the remittance template.
+func NewRemittanceExporter(renderer PDFRenderer) (*RemittanceExporter, error) {
+	funcMap := template.FuncMap{
+		"formatDate": func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format("02 Jan 2006")
+		},
+		"formatDecimal": func(v float64) string {
+			return strconv.FormatFloat(v, 'f', 2, 64)
+		},
+	}
+	tpl, err := template.New("ap_remittance_pdf.html").Funcs(funcMap).ParseFS(
+		web.Templates, "templates/reports/ap_remittance_pdf.html",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parse remittance template: %w", err)
+	}
+	return &RemittanceExporter{renderer: renderer, templates: tpl}, nil
+}
+
+// HTML renders the remittance advice document.
+func (e *RemittanceExporter) HTML(advice RemittanceAdvice) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := e.templates.ExecuteTemplate(buf, "reports/ap_remittance_pdf.html", view.TemplateData{Data: advice}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Render converts the remittance advice into a PDF.
+func (e *RemittanceExporter) Render(ctx context.Context, advice RemittanceAdvice) ([]byte, error) {
+	if e == nil || e.renderer == nil {
+		return nil, fmt.Errorf("remittance exporter not initialized")
+	}
+	html, err := e.HTML(advice)
+	if err != nil {
+		return nil, fmt.Errorf("render remittance: %w", err)
+	}
+	return e.renderer.RenderHTML(ctx, html)
+}
@@ -2,12 +2,16 @@ package ap
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odyssey-erp/odyssey-erp/internal/netting"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -20,9 +24,32 @@ type Repository interface {
 	ListAPInvoices(ctx context.Context, req ListAPInvoicesRequest) ([]APInvoice, error)
 	CountInvoicesByGRN(ctx context.Context, grnID int64) (int, error)
 	GetAPInvoiceBalancesBatch(ctx context.Context) ([]APInvoiceBalance, error)
+	// SupplierPaymentTerms returns the supplier's default net terms in days.
+	SupplierPaymentTerms(ctx context.Context, supplierID int64) (int, error)
+	// SupplierCompanyID returns the company owning the supplier, 0 when shared.
+	SupplierCompanyID(ctx context.Context, supplierID int64) (int64, error)
 
 	ListAPPayments(ctx context.Context) ([]APPayment, error)
 	GetAPPaymentWithDetails(ctx context.Context, id int64) (APPaymentWithDetails, error)
+
+	ListPaymentRunCandidates(ctx context.Context, filter PaymentRunFilter) ([]PaymentRunInvoice, error)
+	ListPaymentRuns(ctx context.Context) ([]APPaymentRun, error)
+	GetPaymentRun(ctx context.Context, id int64) (APPaymentRunWithDetails, error)
+
+	// ListGRIROutstanding returns posted GRNs not fully covered by posted invoices.
+	ListGRIROutstanding(ctx context.Context, filter GRIRFilter) ([]GRIROutstanding, error)
+	// GRIRLedgerBalance returns the grn.grir account balance; false when unmapped.
+	GRIRLedgerBalance(ctx context.Context, asOf time.Time) (GRIRLedger, bool, error)
+
+	// SupplierWithholdingTax returns the supplier's default withholding tax, nil when none.
+	SupplierWithholdingTax(ctx context.Context, supplierID int64) (*WithholdingTax, error)
+	// GetWithholdingTax returns ErrNotWithholdingTax unless the tax is flagged as withholding.
+	GetWithholdingTax(ctx context.Context, id int64) (WithholdingTax, error)
+	ListWithholdingTaxes(ctx context.Context) ([]WithholdingTax, error)
+	// ListInputTaxes returns the non-withholding taxes.
+	ListInputTaxes(ctx context.Context) ([]shared.TaxRate, error)
+	// ListWithholdingLines returns the tax withheld per payment allocation.
+	ListWithholdingLines(ctx context.Context, filter WithholdingFilter) ([]WithholdingLine, error)
 }
 
 // TxRepository defines operations within a transaction.
@@ -36,9 +63,14 @@ type TxRepository interface {
 	CreateAPPayment(ctx context.Context, input CreateAPPaymentInput) (int64, error)
 	CreatePaymentAllocation(ctx context.Context, input PaymentAllocationInput, paymentID int64) error
 
+	// LockPayableInvoices locks the posted invoices among ids and returns their open balances.
+	LockPayableInvoices(ctx context.Context, ids []int64) ([]PaymentRunInvoice, error)
+	CreatePaymentRun(ctx context.Context, run APPaymentRun) (int64, error)
+
 	// Helper for generating numbers
 	GenerateAPInvoiceNumber(ctx context.Context) (string, error)
 	GenerateAPPaymentNumber(ctx context.Context) (string, error)
+	GenerateAPPaymentRunNumber(ctx context.Context) (string, error)
 }
 
 // Ensure implementation
@@ -72,36 +104,70 @@ func (r *pgRepository) WithTx(ctx context.Context, fn func(context.Context, TxRe
 	return tx.Commit(ctx)
 }
 
+// GetAPInvoice returns an invoice by ID. Invoices of companies outside the
+// caller's scope are reported as not found.
 func (r *pgRepository) GetAPInvoice(ctx context.Context, id int64) (APInvoice, error) {
+	allowed, err := r.invoiceInScope(ctx, id)
+	if err != nil {
+		return APInvoice{}, err
+	}
+	if !allowed {
+		return APInvoice{}, ErrInvoiceNotFound
+	}
 	row, err := r.q.GetAPInvoice(ctx, id)
 	if err != nil {
 		return APInvoice{}, err
 	}
 
 	return APInvoice{
-		ID:           row.ID,
-		Number:       row.Number,
-		SupplierID:   row.SupplierID,
-		SupplierName: row.SupplierName,
-		GRNID:        toInt64Ptr(row.GrnID),
-		POID:         toInt64Ptr(row.PoID),
-		Currency:     row.Currency,
-		Subtotal:     numericToFloat(row.Subtotal),
-		TaxAmount:    numericToFloat(row.TaxAmount),
-		Total:        numericToFloat(row.Total),
-		Status:       APInvoiceStatus(row.Status),
-		DueAt:        dateToTime(row.DueAt),
-		PostedAt:     timestampToTime(row.PostedAt),
-		PostedBy:     toInt64Ptr(row.PostedBy),
-		VoidedAt:     timestampToTime(row.VoidedAt),
-		VoidedBy:     toInt64Ptr(row.VoidedBy),
-		VoidReason:   toStrPtr(row.VoidReason),
-		CreatedBy:    row.CreatedBy.Int64,
-		CreatedAt:    safeTime(row.CreatedAt),
-		UpdatedAt:    safeTime(row.UpdatedAt),
+		ID:                row.ID,
+		Number:            row.Number,
+		SupplierID:        row.SupplierID,
+		SupplierName:      row.SupplierName,
+		GRNID:             toInt64Ptr(row.GrnID),
+		POID:              toInt64Ptr(row.PoID),
+		Currency:          row.Currency,
+		Subtotal:          numericToFloat(row.Subtotal),
+		TaxAmount:         numericToFloat(row.TaxAmount),
+		Total:             numericToFloat(row.Total),
+		Status:            APInvoiceStatus(row.Status),
+		IssuedAt:          dateToTime(row.IssuedAt),
+		DueAt:             dateToTime(row.DueAt),
+		PaymentTermsDays:  toIntPtr(row.PaymentTermsDays),
+		PostedAt:          timestampToTime(row.PostedAt),
+		PostedBy:          toInt64Ptr(row.PostedBy),
+		VoidedAt:          timestampToTime(row.VoidedAt),
+		VoidedBy:          toInt64Ptr(row.VoidedBy),
+		VoidReason:        toStrPtr(row.VoidReason),
+		CreatedBy:         row.CreatedBy.Int64,
+		CreatedAt:         safeTime(row.CreatedAt),
+		UpdatedAt:         safeTime(row.UpdatedAt),
+		WithholdingTaxID:  toInt64Ptr(row.WithholdingTaxID),
+		WithholdingCode:   row.WithholdingCode,
+		WithholdingRate:   numericToFloat(row.WithholdingRate),
+		WithholdingAmount: numericToFloat(row.WithholdingAmount),
+		Withheld:          numericToFloat(row.WithheldAmount),
 	}, nil
 }
 
+func (r *pgRepository) SupplierPaymentTerms(ctx context.Context, supplierID int64) (int, error) {
+	var days int
+	err := r.pool.QueryRow(ctx, "SELECT payment_terms_days FROM suppliers WHERE id = $1", supplierID).Scan(&days)
+	if err != nil {
+		return 0, err
+	}
+	return days, nil
+}
+
+func (r *pgRepository) SupplierCompanyID(ctx context.Context, supplierID int64) (int64, error) {
+	var companyID int64
+	err := r.pool.QueryRow(ctx, "SELECT COALESCE(company_id, 0) FROM suppliers WHERE id = $1", supplierID).Scan(&companyID)
+	if err != nil {
+		return 0, err
+	}
+	return companyID, nil
+}
+
 func (r *pgRepository) CountInvoicesByGRN(ctx context.Context, grnID int64) (int, error) {
 	var count int
 	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM ap_invoices WHERE grn_id = $1", grnID).Scan(&count)
@@ -119,11 +185,13 @@ func (r *pgRepository) GetAPInvoiceBalancesBatch(ctx context.Context) ([]APInvoi
 	balances := make([]APInvoiceBalance, len(rows))
 	for i, row := range rows {
 		balances[i] = APInvoiceBalance{
-			ID:         row.ID,
-			DueAt:      dateToTime(row.DueAt),
-			Total:      numericToFloat(row.Total),
-			PaidAmount: numericToFloat(row.PaidAmount),
-			Balance:    numericToFloat(row.Balance),
+			ID:           row.ID,
+			SupplierID:   row.SupplierID,
+			SupplierName: row.SupplierName,
+			DueAt:        dateToTime(row.DueAt),
+			Total:        numericToFloat(row.Total),
+			PaidAmount:   numericToFloat(row.PaidAmount),
+			Balance:      numericToFloat(row.Balance),
 		}
 	}
 	return balances, nil
@@ -209,66 +277,133 @@ func (r *pgRepository) GetAPInvoiceWithDetails(ctx context.Context, id int64) (A
 	}, nil
 }
 
+// ListAPInvoices returns invoices with optional filtering, newest first.
+// Invoices of companies outside the caller's scope are left out; invoices
+// without a company stay visible.
 func (r *pgRepository) ListAPInvoices(ctx context.Context, req ListAPInvoicesRequest) ([]APInvoice, error) {
-	var invoices []APInvoice
+	query := `
+SELECT i.id, i.number, i.supplier_id, s.name, i.grn_id, i.po_id, i.currency,
+       i.subtotal, i.tax_amount, i.total, i.status, i.issued_at, i.due_at,
+       i.posted_at, i.posted_by, i.voided_at, i.voided_by, i.void_reason,
+       i.created_by, i.created_at, i.updated_at
+FROM ap_invoices i
+JOIN suppliers s ON s.id = i.supplier_id
+WHERE 1=1`
+	args := []any{}
+	argNum := 1
 
 	if req.Status != "" {
-		rows, err := r.q.ListAPInvoicesByStatus(ctx, string(req.Status))
-		if err != nil {
-			return nil, err
-		}
-		invoices = make([]APInvoice, len(rows))
-		for i, row := range rows {
-			invoices[i] = APInvoice{
-				ID: row.ID, Number: row.Number, SupplierID: row.SupplierID, GRNID: toInt64Ptr(row.GrnID),
-				SupplierName: row.SupplierName, POID: toInt64Ptr(row.PoID),
-				Currency: row.Currency, Subtotal: numericToFloat(row.Subtotal), TaxAmount: numericToFloat(row.TaxAmount), Total: numericToFloat(row.Total),
-				Status: APInvoiceStatus(row.Status), DueAt: dateToTime(row.DueAt), PostedAt: timestampToTime(row.PostedAt), PostedBy: toInt64Ptr(row.PostedBy),
-				VoidedAt: timestampToTime(row.VoidedAt), VoidedBy: toInt64Ptr(row.VoidedBy), VoidReason: toStrPtr(row.VoidReason), CreatedBy: row.CreatedBy.Int64, CreatedAt: safeTime(row.CreatedAt), UpdatedAt: safeTime(row.UpdatedAt),
-			}
-		}
-	} else if req.SupplierID != 0 {
-		rows, err := r.q.ListAPInvoicesBySupplier(ctx, req.SupplierID)
-		if err != nil {
-			return nil, err
-		}
-		invoices = make([]APInvoice, len(rows))
-		for i, row := range rows {
-			invoices[i] = APInvoice{
-				ID: row.ID, Number: row.Number, SupplierID: row.SupplierID, GRNID: toInt64Ptr(row.GrnID),
-				SupplierName: row.SupplierName, POID: toInt64Ptr(row.PoID),
-				Currency: row.Currency, Subtotal: numericToFloat(row.Subtotal), TaxAmount: numericToFloat(row.TaxAmount), Total: numericToFloat(row.Total),
-				Status: APInvoiceStatus(row.Status), DueAt: dateToTime(row.DueAt), PostedAt: timestampToTime(row.PostedAt), PostedBy: toInt64Ptr(row.PostedBy),
-				VoidedAt: timestampToTime(row.VoidedAt), VoidedBy: toInt64Ptr(row.VoidedBy), VoidReason: toStrPtr(row.VoidReason), CreatedBy: row.CreatedBy.Int64, CreatedAt: safeTime(row.CreatedAt), UpdatedAt: safeTime(row.UpdatedAt),
-			}
-		}
-	} else {
-		rows, err := r.q.ListAPInvoices(ctx)
-		if err != nil {
+		query += fmt.Sprintf(" AND i.status = $%d", argNum)
+		args = append(args, string(req.Status))
+		argNum++
+	}
+	if req.SupplierID != 0 {
+		query += fmt.Sprintf(" AND i.supplier_id = $%d", argNum)
+		args = append(args, req.SupplierID)
+		argNum++
+	}
+	if req.CompanyID != 0 {
+		query += fmt.Sprintf(" AND i.company_id = $%d", argNum)
+		args = append(args, req.CompanyID)
+		argNum++
+	}
+	if !req.FromDate.IsZero() {
+		query += fmt.Sprintf(" AND i.created_at >= $%d", argNum)
+		args = append(args, req.FromDate)
+		argNum++
+	}
+	if !req.ToDate.IsZero() {
+		query += fmt.Sprintf(" AND i.created_at <= $%d", argNum)
+		args = append(args, req.ToDate)
+		argNum++
+	}
+	if scope := shared.CompanyScopeFromContext(ctx); !scope.Unrestricted {
+		query += fmt.Sprintf(" AND (i.company_id IS NULL OR i.company_id = ANY($%d))", argNum)
+		args = append(args, scope.CompanyIDs)
+		argNum++
+	}
+
+	query += " ORDER BY i.created_at DESC"
+
+	if req.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argNum)
+		args = append(args, req.Limit)
+		argNum++
+	}
+	if req.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argNum)
+		args = append(args, req.Offset)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []APInvoice
+	for rows.Next() {
+		var row sqlc.ListAPInvoicesRow
+		var issuedAt pgtype.Date
+		if err := rows.Scan(&row.ID, &row.Number, &row.SupplierID, &row.SupplierName, &row.GrnID, &row.PoID, &row.Currency,
+			&row.Subtotal, &row.TaxAmount, &row.Total, &row.Status, &issuedAt, &row.DueAt,
+			&row.PostedAt, &row.PostedBy, &row.VoidedAt, &row.VoidedBy, &row.VoidReason,
+			&row.CreatedBy, &row.CreatedAt, &row.UpdatedAt); err != nil {
 			return nil, err
 		}
-		invoices = make([]APInvoice, len(rows))
-		for i, row := range rows {
-			invoices[i] = APInvoice{
-				ID: row.ID, Number: row.Number, SupplierID: row.SupplierID, GRNID: toInt64Ptr(row.GrnID),
-				SupplierName: row.SupplierName, POID: toInt64Ptr(row.PoID),
-				Currency: row.Currency, Subtotal: numericToFloat(row.Subtotal), TaxAmount: numericToFloat(row.TaxAmount), Total: numericToFloat(row.Total),
-				Status: APInvoiceStatus(row.Status), DueAt: dateToTime(row.DueAt), PostedAt: timestampToTime(row.PostedAt), PostedBy: toInt64Ptr(row.PostedBy),
-				VoidedAt: timestampToTime(row.VoidedAt), VoidedBy: toInt64Ptr(row.VoidedBy), VoidReason: toStrPtr(row.VoidReason), CreatedBy: row.CreatedBy.Int64, CreatedAt: safeTime(row.CreatedAt), UpdatedAt: safeTime(row.UpdatedAt),
-			}
-		}
+		invoices = append(invoices, APInvoice{
+			ID: row.ID, Number: row.Number, SupplierID: row.SupplierID, GRNID: toInt64Ptr(row.GrnID),
+			SupplierName: row.SupplierName, POID: toInt64Ptr(row.PoID),
+			Currency: row.Currency, Subtotal: numericToFloat(row.Subtotal), TaxAmount: numericToFloat(row.TaxAmount), Total: numericToFloat(row.Total),
+			Status: APInvoiceStatus(row.Status), IssuedAt: dateToTime(issuedAt), DueAt: dateToTime(row.DueAt), PostedAt: timestampToTime(row.PostedAt), PostedBy: toInt64Ptr(row.PostedBy),
+			VoidedAt: timestampToTime(row.VoidedAt), VoidedBy: toInt64Ptr(row.VoidedBy), VoidReason: toStrPtr(row.VoidReason), CreatedBy: row.CreatedBy.Int64, CreatedAt: safeTime(row.CreatedAt), UpdatedAt: safeTime(row.UpdatedAt),
+		})
 	}
-	return invoices, nil
+	return invoices, rows.Err()
 }
 
+// invoiceInScope reports whether the invoice belongs to the caller's company
+// scope. Invoices without a company are in every scope.
+func (r *pgRepository) invoiceInScope(ctx context.Context, id int64) (bool, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
+	if scope.Unrestricted {
+		return true, nil
+	}
+	var allowed bool
+	err := r.pool.QueryRow(ctx, `
+SELECT company_id IS NULL OR company_id = ANY($2)
+FROM ap_invoices
+WHERE id = $1`, id, scope.CompanyIDs).Scan(&allowed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return allowed, err
+}
+
+// ListAPPayments returns payments newest first. Payments against invoices of
+// companies outside the caller's scope are left out.
 func (r *pgRepository) ListAPPayments(ctx context.Context) ([]APPayment, error) {
-	rows, err := r.q.ListAPPayments(ctx)
+	scope := shared.CompanyScopeFromContext(ctx)
+	rows, err := r.pool.Query(ctx, `
+SELECT p.id, p.number, p.ap_invoice_id, p.supplier_id, COALESCE(s.name, '') AS supplier_name, p.amount, p.paid_at, p.method, p.note,
+       p.created_by, p.created_at
+FROM ap_payments p
+LEFT JOIN suppliers s ON s.id = p.supplier_id
+LEFT JOIN ap_invoices i ON i.id = p.ap_invoice_id
+WHERE $1 OR i.company_id IS NULL OR i.company_id = ANY($2)
+ORDER BY p.paid_at DESC`, scope.Unrestricted, scope.CompanyIDs)
 	if err != nil {
 		return nil, err
 	}
-	payments := make([]APPayment, len(rows))
-	for i, r := range rows {
-		payments[i] = APPayment{
+	defer rows.Close()
+	var payments []APPayment
+	for rows.Next() {
+		var r sqlc.ListAPPaymentsRow
+		if err := rows.Scan(&r.ID, &r.Number, &r.ApInvoiceID, &r.SupplierID, &r.SupplierName, &r.Amount, &r.PaidAt, &r.Method, &r.Note,
+			&r.CreatedBy, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		payments = append(payments, APPayment{
 			ID:           r.ID,
 			Number:       r.Number,
 			APInvoiceID:  toInt64Ptr(r.ApInvoiceID),
@@ -280,25 +415,28 @@ func (r *pgRepository) ListAPPayments(ctx context.Context) ([]APPayment, error)
 			Note:         r.Note,
 			CreatedBy:    r.CreatedBy.Int64,
 			CreatedAt:    safeTime(r.CreatedAt),
-		}
+		})
 	}
-	return payments, nil
+	return payments, rows.Err()
 }
 
 func (r *pgRepository) GetAPPaymentWithDetails(ctx context.Context, id int64) (APPaymentWithDetails, error) {
 	var (
-		apInvoiceID pgtype.Int8
-		supplierID  pgtype.Int8
-		amount      pgtype.Numeric
-		paidAt      pgtype.Date
-		createdBy   pgtype.Int8
-		createdAt   pgtype.Timestamptz
-		updatedAt   pgtype.Timestamptz
+		apInvoiceID  pgtype.Int8
+		supplierID   pgtype.Int8
+		amount       pgtype.Numeric
+		paidAt       pgtype.Date
+		paymentRunID pgtype.Int8
+		createdBy    pgtype.Int8
+		createdAt    pgtype.Timestamptz
+		updatedAt    pgtype.Timestamptz
+		withheld     pgtype.Numeric
 	)
 	var payment APPayment
 	err := r.pool.QueryRow(ctx, `
 SELECT p.id, p.number, p.ap_invoice_id, p.supplier_id, COALESCE(s.name, '') AS supplier_name,
-       p.amount, p.paid_at, p.method, p.note, p.created_by, p.created_at, p.updated_at
+       p.amount, p.paid_at, p.method, p.note, p.payment_run_id, p.created_by, p.created_at, p.updated_at,
+       p.withheld_amount
 FROM ap_payments p
 LEFT JOIN suppliers s ON s.id = p.supplier_id
 WHERE p.id = $1`, id).Scan(
@@ -311,9 +449,11 @@ WHERE p.id = $1`, id).Scan(
 		&paidAt,
 		&payment.Method,
 		&payment.Note,
+		&paymentRunID,
 		&createdBy,
 		&createdAt,
 		&updatedAt,
+		&withheld,
 	)
 	if err != nil {
 		return APPaymentWithDetails{}, err
@@ -322,12 +462,14 @@ WHERE p.id = $1`, id).Scan(
 	payment.SupplierID = supplierID.Int64
 	payment.Amount = numericToFloat(amount)
 	payment.PaidAt = dateToTime(paidAt)
+	payment.PaymentRunID = toInt64Ptr(paymentRunID)
 	payment.CreatedBy = createdBy.Int64
 	payment.CreatedAt = safeTime(createdAt)
 	payment.UpdatedAt = safeTime(updatedAt)
+	payment.WithheldAmount = numericToFloat(withheld)
 
 	rows, err := r.pool.Query(ctx, `
-SELECT pa.id, pa.ap_payment_id, pa.ap_invoice_id, pa.amount,
+SELECT pa.id, pa.ap_payment_id, pa.ap_invoice_id, pa.amount, pa.withheld_amount,
        i.number AS invoice_number, i.po_id, i.total, i.status, i.due_at
 FROM ap_payment_allocations pa
 JOIN ap_invoices i ON i.id = pa.ap_invoice_id
@@ -342,7 +484,7 @@ ORDER BY i.number`, id)
 	var totalAllocated float64
 	for rows.Next() {
 		var alloc APPaymentAllocationDetail
-		var allocAmount pgtype.Numeric
+		var allocAmount, allocWithheld pgtype.Numeric
 		var poID pgtype.Int8
 		var total pgtype.Numeric
 		var status string
@@ -352,6 +494,7 @@ ORDER BY i.number`, id)
 			&alloc.APPaymentID,
 			&alloc.APInvoiceID,
 			&allocAmount,
+			&allocWithheld,
 			&alloc.InvoiceNumber,
 			&poID,
 			&total,
@@ -365,6 +508,7 @@ ORDER BY i.number`, id)
 		alloc.InvoiceTotal = numericToFloat(total)
 		alloc.DueAt = dateToTime(dueAt)
 		alloc.Amount = numericToFloat(allocAmount)
+		alloc.Withheld = numericToFloat(allocWithheld)
 		totalAllocated += alloc.Amount
 		allocations = append(allocations, alloc)
 	}
@@ -372,18 +516,23 @@ ORDER BY i.number`, id)
 		return APPaymentWithDetails{}, err
 	}
 
+	// Payments created by a payment run are journalled once for the whole run;
+	// netting payments are journalled with their settlement.
 	var posted bool
-	sourceID := apPaymentSourceID(payment.ID)
-	if err := r.pool.QueryRow(ctx, `
-SELECT EXISTS (
-    SELECT 1
-    FROM journal_entries
-    WHERE source_module = $1 AND source_id = $2 AND status = 'POSTED'
-)`, "PROCUREMENT.AP_PAYMENT", uuidToPg(sourceID)).Scan(&posted); err != nil {
+	switch {
+	case payment.PaymentRunID != nil:
+		posted, err = r.journalPosted(ctx, "PROCUREMENT.AP_PAYMENT_RUN", apPaymentRunSourceID(*payment.PaymentRunID))
+	case payment.Method == netting.PaymentMethod:
+		posted, err = r.nettingJournalPosted(ctx, payment.ID)
+	default:
+		posted, err = r.journalPosted(ctx, "PROCUREMENT.AP_PAYMENT", apPaymentSourceID(payment.ID))
+	}
+	if err != nil {
 		return APPaymentWithDetails{}, err
 	}
 
-	unallocated := payment.Amount - totalAllocated
+	// Allocations settle invoices gross; the withheld part was never paid in cash.
+	unallocated := payment.Amount + payment.WithheldAmount - totalAllocated
 	if unallocated < 0 {
 		unallocated = 0
 	}
@@ -397,6 +546,187 @@ SELECT EXISTS (
 	}, nil
 }
 
+func (r *pgRepository) journalPosted(ctx context.Context, module string, sourceID uuid.UUID) (bool, error) {
+	var posted bool
+	err := r.pool.QueryRow(ctx, `
+SELECT EXISTS (
+    SELECT 1
+    FROM journal_entries
+    WHERE source_module = $1 AND source_id = $2 AND status = 'POSTED'
+)`, module, uuidToPg(sourceID)).Scan(&posted)
+	return posted, err
+}
+
+func (r *pgRepository) nettingJournalPosted(ctx context.Context, paymentID int64) (bool, error) {
+	var settlementID int64
+	err := r.pool.QueryRow(ctx, `SELECT id FROM netting_settlements WHERE ap_payment_id = $1`, paymentID).Scan(&settlementID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return r.journalPosted(ctx, netting.JournalSourceModule, netting.JournalSourceID(settlementID))
+}
+
+func (r *pgRepository) ListPaymentRunCandidates(ctx context.Context, filter PaymentRunFilter) ([]PaymentRunInvoice, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
+	rows, err := r.pool.Query(ctx, `
+SELECT i.id, i.number, i.supplier_id, s.name AS supplier_name, i.currency, i.due_at,
+       (i.total - COALESCE(SUM(pa.amount), 0))::NUMERIC AS balance,
+       GREATEST(i.withholding_amount - COALESCE(SUM(pa.withheld_amount), 0), 0)::NUMERIC AS withholding_due
+FROM ap_invoices i
+JOIN suppliers s ON s.id = i.supplier_id
+LEFT JOIN ap_payment_allocations pa ON pa.ap_invoice_id = i.id
+WHERE i.status = 'POSTED'
+  AND i.due_at <= $1
+  AND ($2::BIGINT = 0 OR i.supplier_id = $2)
+  AND ($3 OR i.company_id IS NULL OR i.company_id = ANY($4))
+GROUP BY i.id, s.name
+HAVING (i.total - COALESCE(SUM(pa.amount), 0)) > 0
+ORDER BY i.due_at, i.id`, timeToDate(filter.DueBefore), filter.SupplierID, scope.Unrestricted, scope.CompanyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []PaymentRunInvoice
+	for rows.Next() {
+		var inv PaymentRunInvoice
+		var dueAt pgtype.Date
+		var balance, withholding pgtype.Numeric
+		if err := rows.Scan(&inv.InvoiceID, &inv.Number, &inv.SupplierID, &inv.SupplierName, &inv.Currency, &dueAt, &balance, &withholding); err != nil {
+			return nil, err
+		}
+		inv.DueAt = dateToTime(dueAt)
+		inv.Amount = numericToFloat(balance)
+		inv.Withheld = numericToFloat(withholding)
+		invoices = append(invoices, inv)
+	}
+	return invoices, rows.Err()
+}
+
+// paymentRunInScope keeps payment runs whose paid invoices all belong to
+// companies in scope ($1 unrestricted, $2 company IDs).
+const paymentRunInScope = `($1 OR NOT EXISTS (
+    SELECT 1 FROM ap_payments p
+    JOIN ap_payment_allocations pa ON pa.ap_payment_id = p.id
+    JOIN ap_invoices i ON i.id = pa.ap_invoice_id
+    WHERE p.payment_run_id = r.id AND i.company_id IS NOT NULL AND NOT i.company_id = ANY($2)))`
+
+const paymentRunColumns = `r.id, r.number, r.pay_date, r.method, r.note, r.due_before, r.supplier_id,
+       r.cash_limit, r.group_by_supplier, r.total, r.created_by, r.created_at,
+       (SELECT COUNT(*) FROM ap_payments p WHERE p.payment_run_id = r.id) AS payment_count`
+
+func scanPaymentRun(row pgx.Row) (APPaymentRun, error) {
+	var (
+		run        APPaymentRun
+		payDate    pgtype.Date
+		dueBefore  pgtype.Date
+		supplierID pgtype.Int8
+		cashLimit  pgtype.Numeric
+		total      pgtype.Numeric
+		createdBy  pgtype.Int8
+		createdAt  pgtype.Timestamptz
+	)
+	if err := row.Scan(
+		&run.ID, &run.Number, &payDate, &run.Method, &run.Note, &dueBefore, &supplierID,
+		&cashLimit, &run.GroupBySupplier, &total, &createdBy, &createdAt, &run.PaymentCount,
+	); err != nil {
+		return APPaymentRun{}, err
+	}
+	run.PayDate = dateToTime(payDate)
+	run.DueBefore = dateToTime(dueBefore)
+	run.SupplierID = toInt64Ptr(supplierID)
+	run.CashLimit = numericToFloat(cashLimit)
+	run.Total = numericToFloat(total)
+	run.CreatedBy = createdBy.Int64
+	run.CreatedAt = safeTime(createdAt)
+	return run, nil
+}
+
+func (r *pgRepository) ListPaymentRuns(ctx context.Context) ([]APPaymentRun, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
+	rows, err := r.pool.Query(ctx, `SELECT `+paymentRunColumns+`
+FROM ap_payment_runs r
+WHERE `+paymentRunInScope+`
+ORDER BY r.created_at DESC
+LIMIT 100`, scope.Unrestricted, scope.CompanyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []APPaymentRun
+	for rows.Next() {
+		run, err := scanPaymentRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (r *pgRepository) GetPaymentRun(ctx context.Context, id int64) (APPaymentRunWithDetails, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
+	run, err := scanPaymentRun(r.pool.QueryRow(ctx, `SELECT `+paymentRunColumns+`
+FROM ap_payment_runs r
+WHERE `+paymentRunInScope+` AND r.id = $3`, scope.Unrestricted, scope.CompanyIDs, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return APPaymentRunWithDetails{}, ErrPaymentRunNotFound
+		}
+		return APPaymentRunWithDetails{}, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+SELECT p.id, p.number, p.supplier_id, COALESCE(s.name, '') AS supplier_name,
+       pa.ap_invoice_id, i.number, i.currency, i.due_at, pa.amount, pa.withheld_amount
+FROM ap_payments p
+JOIN ap_payment_allocations pa ON pa.ap_payment_id = p.id
+JOIN ap_invoices i ON i.id = pa.ap_invoice_id
+LEFT JOIN suppliers s ON s.id = p.supplier_id
+WHERE p.payment_run_id = $1
+ORDER BY i.due_at, i.id`, id)
+	if err != nil {
+		return APPaymentRunWithDetails{}, err
+	}
+	defer rows.Close()
+
+	var invoices []PaymentRunInvoice
+	for rows.Next() {
+		var inv PaymentRunInvoice
+		var supplierID pgtype.Int8
+		var dueAt pgtype.Date
+		var amount, withheld pgtype.Numeric
+		if err := rows.Scan(
+			&inv.PaymentID, &inv.PaymentNumber, &supplierID, &inv.SupplierName,
+			&inv.InvoiceID, &inv.Number, &inv.Currency, &dueAt, &amount, &withheld,
+		); err != nil {
+			return APPaymentRunWithDetails{}, err
+		}
+		inv.SupplierID = supplierID.Int64
+		inv.DueAt = dateToTime(dueAt)
+		inv.Amount = numericToFloat(amount)
+		inv.Withheld = numericToFloat(withheld)
+		invoices = append(invoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return APPaymentRunWithDetails{}, err
+	}
+
+	posted, err := r.journalPosted(ctx, "PROCUREMENT.AP_PAYMENT_RUN", apPaymentRunSourceID(run.ID))
+	if err != nil {
+		return APPaymentRunWithDetails{}, err
+	}
+	return APPaymentRunWithDetails{
+		APPaymentRun: run,
+		Suppliers:    groupBySupplier(invoices),
+		LedgerPosted: posted,
+	}, nil
+}
+
 // Transaction Repository Implementation
 
 type pgTxRepository struct {
@@ -405,17 +735,23 @@ type pgTxRepository struct {
 
 func (tx *pgTxRepository) CreateAPInvoice(ctx context.Context, input CreateAPInvoiceInput) (int64, error) {
 	return tx.q.CreateAPInvoice(ctx, sqlc.CreateAPInvoiceParams{
-		Number:     input.Number,
-		SupplierID: input.SupplierID,
-		GrnID:      toNullInt64(input.GRNID),
-		PoID:       toNullInt64(input.POID),
-		Currency:   input.Currency,
-		Subtotal:   floatToNumeric(input.Subtotal),
-		TaxAmount:  floatToNumeric(input.TaxAmount),
-		Total:      floatToNumeric(input.Total),
-		Status:     string(APStatusDraft),
-		DueAt:      timeToDate(input.DueDate),
-		CreatedBy:  toNullInt64(&input.CreatedBy),
+		Number:            input.Number,
+		SupplierID:        input.SupplierID,
+		GrnID:             toNullInt64(input.GRNID),
+		PoID:              toNullInt64(input.POID),
+		Currency:          input.Currency,
+		Subtotal:          floatToNumeric(input.Subtotal),
+		TaxAmount:         floatToNumeric(input.TaxAmount),
+		Total:             floatToNumeric(input.Total),
+		Status:            string(APStatusDraft),
+		IssuedAt:          timeToDate(input.IssuedAt),
+		DueAt:             timeToDate(input.DueDate),
+		PaymentTermsDays:  toNullInt32(input.PaymentTermsDays),
+		CreatedBy:         toNullInt64(&input.CreatedBy),
+		WithholdingTaxID:  toNullInt64(input.WithholdingTaxID),
+		WithholdingRate:   floatToNumeric(input.WithholdingRate),
+		WithholdingAmount: floatToNumeric(input.WithholdingAmount),
+		CompanyID:         pgtype.Int8{Int64: input.CompanyID, Valid: input.CompanyID > 0},
 	})
 }
 
@@ -473,26 +809,70 @@ func (tx *pgTxRepository) CreateAPPayment(ctx context.Context, input CreateAPPay
 	}
 
 	return tx.q.CreateAPPayment(ctx, sqlc.CreateAPPaymentParams{
-		Number:      input.Number,
-		ApInvoiceID: toNullInt64(invoiceIDPtr),
-		SupplierID:  toNullInt64(supplierIDPtr),
-		Amount:      floatToNumeric(input.Amount),
-		PaidAt:      timeToDate(input.PaidAt),
-		Method:      input.Method,
-		Note:        input.Note,
-		CreatedBy:   toNullInt64(&input.CreatedBy),
+		Number:         input.Number,
+		ApInvoiceID:    toNullInt64(invoiceIDPtr),
+		SupplierID:     toNullInt64(supplierIDPtr),
+		Amount:         floatToNumeric(input.Amount),
+		PaidAt:         timeToDate(input.PaidAt),
+		Method:         input.Method,
+		Note:           input.Note,
+		CreatedBy:      toNullInt64(&input.CreatedBy),
+		PaymentRunID:   toNullInt64(input.PaymentRunID),
+		WithheldAmount: floatToNumeric(input.WithheldAmount),
 	})
 }
 
 func (tx *pgTxRepository) CreatePaymentAllocation(ctx context.Context, input PaymentAllocationInput, paymentID int64) error {
 	_, err := tx.q.CreateAPPaymentAllocation(ctx, sqlc.CreateAPPaymentAllocationParams{
-		ApPaymentID: paymentID,
-		ApInvoiceID: input.APInvoiceID,
-		Amount:      floatToNumeric(input.Amount),
+		ApPaymentID:    paymentID,
+		ApInvoiceID:    input.APInvoiceID,
+		Amount:         floatToNumeric(input.Amount),
+		WithheldAmount: floatToNumeric(input.Withheld),
 	})
 	return err
 }
 
+func (tx *pgTxRepository) LockPayableInvoices(ctx context.Context, ids []int64) ([]PaymentRunInvoice, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
+	rows, err := tx.q.LockPayableAPInvoices(ctx, sqlc.LockPayableAPInvoicesParams{
+		InvoiceIds:   ids,
+		Unrestricted: scope.Unrestricted,
+		CompanyIds:   scope.CompanyIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	invoices := make([]PaymentRunInvoice, len(rows))
+	for i, row := range rows {
+		invoices[i] = PaymentRunInvoice{
+			InvoiceID:    row.ID,
+			Number:       row.Number,
+			SupplierID:   row.SupplierID,
+			SupplierName: row.SupplierName,
+			Currency:     row.Currency,
+			DueAt:        dateToTime(row.DueAt),
+			Amount:       numericToFloat(row.Balance),
+			Withheld:     numericToFloat(row.WithholdingDue),
+		}
+	}
+	return invoices, nil
+}
+
+func (tx *pgTxRepository) CreatePaymentRun(ctx context.Context, run APPaymentRun) (int64, error) {
+	return tx.q.CreateAPPaymentRun(ctx, sqlc.CreateAPPaymentRunParams{
+		Number:          run.Number,
+		PayDate:         timeToDate(run.PayDate),
+		Method:          run.Method,
+		Note:            run.Note,
+		DueBefore:       timeToDate(run.DueBefore),
+		SupplierID:      toNullInt64(run.SupplierID),
+		CashLimit:       floatToNumeric(run.CashLimit),
+		GroupBySupplier: run.GroupBySupplier,
+		Total:           floatToNumeric(run.Total),
+		CreatedBy:       toNullInt64(&run.CreatedBy),
+	})
+}
+
 func (tx *pgTxRepository) GenerateAPInvoiceNumber(ctx context.Context) (string, error) {
 	res, err := tx.q.GenerateAPInvoiceNumber(ctx)
 	if err != nil {
@@ -509,6 +889,14 @@ func (tx *pgTxRepository) GenerateAPPaymentNumber(ctx context.Context) (string,
 	return res.(string), nil
 }
 
+func (tx *pgTxRepository) GenerateAPPaymentRunNumber(ctx context.Context) (string, error) {
+	res, err := tx.q.GenerateAPPaymentRunNumber(ctx)
+	if err != nil {
+		return "", err
+	}
+	return res.(string), nil
+}
+
 // Helpers
 
 func numericToFloat(n pgtype.Numeric) float64 {
@@ -555,6 +943,21 @@ func toNullInt64(i *int64) pgtype.Int8 {
 	return pgtype.Int8{Int64: *i, Valid: true}
 }
 
+func toIntPtr(i pgtype.Int4) *int {
+	if !i.Valid {
+		return nil
+	}
+	v := int(i.Int32)
+	return &v
+}
+
+func toNullInt32(i *int) pgtype.Int4 {
+	if i == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: int32(*i), Valid: true}
+}
+
 func uuidToPg(id uuid.UUID) pgtype.UUID {
 	return pgtype.UUID{Bytes: id, Valid: true}
 }
@@ -563,6 +966,10 @@ func apPaymentSourceID(id int64) uuid.UUID {
 	return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("APPAY:%d", id)))
 }
 
+func apPaymentRunSourceID(id int64) uuid.UUID {
+	return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("APPAYRUN:%d", id)))
+}
+
 func toText(s string) pgtype.Text {
 	return pgtype.Text{String: s, Valid: s != ""}
 }
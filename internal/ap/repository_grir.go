@@ -0,0 +1,99 @@
+package ap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ListGRIROutstanding values each GRN line the way the GRN posting does
+// (qty × unit cost rounded to cents), less goods rejected in quality
+// inspection the way the rejection posting does, and subtracts the net
+// amount of posted invoices linked to the GRN.
+func (r *pgRepository) ListGRIROutstanding(ctx context.Context, filter GRIRFilter) ([]GRIROutstanding, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT g.id, g.number, g.supplier_id, s.name, g.received_at,
+       rcv.amount::NUMERIC AS received,
+       COALESCE(inv.amount, 0)::NUMERIC AS invoiced,
+       COALESCE(inv.invoice_count, 0) AS invoice_count
+FROM grns g
+JOIN suppliers s ON s.id = g.supplier_id
+JOIN LATERAL (
+    SELECT COALESCE(SUM(ROUND(l.qty * l.unit_cost, 2)), 0)
+         - COALESCE((
+               SELECT SUM(ROUND(d.qty * qc.unit_cost, 2))
+               FROM grn_inspection_decisions d
+               JOIN grn_inspections qc ON qc.id = d.inspection_id
+               WHERE qc.grn_id = g.id AND d.decision = 'REJECT' AND d.decided_at::DATE <= $1
+           ), 0) AS amount
+    FROM grn_lines l
+    WHERE l.grn_id = g.id
+) rcv ON TRUE
+LEFT JOIN LATERAL (
+    SELECT SUM(CASE WHEN i.subtotal > 0 THEN i.subtotal ELSE i.total END) AS amount,
+           COUNT(*)::INT AS invoice_count
+    FROM ap_invoices i
+    WHERE i.grn_id = g.id
+      AND i.status IN ('POSTED', 'PAID')
+      AND COALESCE(i.posted_at::DATE, i.issued_at) <= $1
+) inv ON TRUE
+WHERE g.status = 'POSTED'
+  AND g.received_at::DATE <= $1
+  AND ($2::BIGINT = 0 OR g.supplier_id = $2)
+  AND rcv.amount - COALESCE(inv.amount, 0) > 0.005
+ORDER BY s.name, g.received_at, g.id`, timeToDate(filter.AsOf), filter.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []GRIROutstanding
+	for rows.Next() {
+		var (
+			line       GRIROutstanding
+			receivedAt pgtype.Timestamptz
+			received   pgtype.Numeric
+			invoiced   pgtype.Numeric
+		)
+		if err := rows.Scan(&line.GRNID, &line.GRNNumber, &line.SupplierID, &line.SupplierName, &receivedAt,
+			&received, &invoiced, &line.InvoiceCount); err != nil {
+			return nil, err
+		}
+		line.ReceivedAt = receivedAt.Time
+		line.ReceivedValue = numericToFloat(received)
+		line.InvoicedValue = numericToFloat(invoiced)
+		out = append(out, line)
+	}
+	return out, rows.Err()
+}
+
+// GRIRLedgerBalance sums posted journal lines on the account mapped to
+// GRN/grn.grir up to and including asOf.
+func (r *pgRepository) GRIRLedgerBalance(ctx context.Context, asOf time.Time) (GRIRLedger, bool, error) {
+	var (
+		ledger  GRIRLedger
+		balance pgtype.Numeric
+	)
+	err := r.pool.QueryRow(ctx, `
+SELECT a.code, a.name,
+       COALESCE((
+           SELECT SUM(jl.credit - jl.debit)
+           FROM journal_lines jl
+           JOIN journal_entries je ON je.id = jl.je_id
+           WHERE jl.account_id = a.id AND je.status = 'POSTED' AND je.date <= $1
+       ), 0)::NUMERIC
+FROM account_mappings m
+JOIN accounts a ON a.id = m.account_id
+WHERE m.module = 'GRN' AND m.key = 'grn.grir'`, timeToDate(asOf)).Scan(&ledger.AccountCode, &ledger.AccountName, &balance)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return GRIRLedger{}, false, nil
+	}
+	if err != nil {
+		return GRIRLedger{}, false, err
+	}
+	ledger.Balance = numericToFloat(balance)
+	return ledger, true, nil
+}
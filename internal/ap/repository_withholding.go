@@ -0,0 +1,143 @@
+package ap
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func (r *pgRepository) SupplierWithholdingTax(ctx context.Context, supplierID int64) (*WithholdingTax, error) {
+	var (
+		tax  WithholdingTax
+		rate pgtype.Numeric
+	)
+	err := r.pool.QueryRow(ctx, `
+SELECT t.id, t.code, t.name, t.rate
+FROM suppliers s
+JOIN taxes t ON t.id = s.withholding_tax_id
+WHERE s.id = $1 AND t.is_withholding`, supplierID).Scan(&tax.ID, &tax.Code, &tax.Name, &rate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tax.Rate = numericToFloat(rate)
+	return &tax, nil
+}
+
+func (r *pgRepository) GetWithholdingTax(ctx context.Context, id int64) (WithholdingTax, error) {
+	var (
+		tax  WithholdingTax
+		rate pgtype.Numeric
+	)
+	err := r.pool.QueryRow(ctx, `
+SELECT id, code, name, rate
+FROM taxes
+WHERE id = $1 AND is_withholding`, id).Scan(&tax.ID, &tax.Code, &tax.Name, &rate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return WithholdingTax{}, ErrNotWithholdingTax
+	}
+	if err != nil {
+		return WithholdingTax{}, err
+	}
+	tax.Rate = numericToFloat(rate)
+	return tax, nil
+}
+
+func (r *pgRepository) ListWithholdingTaxes(ctx context.Context) ([]WithholdingTax, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT id, code, name, rate
+FROM taxes
+WHERE is_withholding
+ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taxes []WithholdingTax
+	for rows.Next() {
+		var tax WithholdingTax
+		var rate pgtype.Numeric
+		if err := rows.Scan(&tax.ID, &tax.Code, &tax.Name, &rate); err != nil {
+			return nil, err
+		}
+		tax.Rate = numericToFloat(rate)
+		taxes = append(taxes, tax)
+	}
+	return taxes, rows.Err()
+}
+
+func (r *pgRepository) ListInputTaxes(ctx context.Context) ([]shared.TaxRate, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT id, code, name, rate::float8
+FROM taxes
+WHERE NOT is_withholding
+ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taxes []shared.TaxRate
+	for rows.Next() {
+		var tax shared.TaxRate
+		if err := rows.Scan(&tax.TaxID, &tax.Code, &tax.Name, &tax.Rate); err != nil {
+			return nil, err
+		}
+		taxes = append(taxes, tax)
+	}
+	return taxes, rows.Err()
+}
+
+// ListWithholdingLines returns every allocation that withheld tax from a
+// payment dated within the filter. The base is the allocated amount scaled
+// from the invoice total down to its subtotal, which is what the rate was
+// applied to.
+func (r *pgRepository) ListWithholdingLines(ctx context.Context, filter WithholdingFilter) ([]WithholdingLine, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT p.id, p.number, p.paid_at, i.id, i.number, i.supplier_id, s.name, s.tax_id,
+       COALESCE(t.code, ''), i.withholding_rate, i.currency,
+       ROUND(pa.amount * i.subtotal / NULLIF(i.total, 0), 2)::NUMERIC AS base,
+       pa.withheld_amount
+FROM ap_payment_allocations pa
+JOIN ap_payments p ON p.id = pa.ap_payment_id
+JOIN ap_invoices i ON i.id = pa.ap_invoice_id
+JOIN suppliers s ON s.id = i.supplier_id
+LEFT JOIN taxes t ON t.id = i.withholding_tax_id
+WHERE pa.withheld_amount > 0
+  AND p.paid_at BETWEEN $1 AND $2
+  AND ($3::BIGINT = 0 OR i.supplier_id = $3)
+ORDER BY s.name, p.paid_at, p.id, i.id`, timeToDate(filter.From), timeToDate(filter.To), filter.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []WithholdingLine
+	for rows.Next() {
+		var (
+			line     WithholdingLine
+			paidAt   pgtype.Date
+			rate     pgtype.Numeric
+			base     pgtype.Numeric
+			withheld pgtype.Numeric
+		)
+		if err := rows.Scan(&line.PaymentID, &line.PaymentNumber, &paidAt, &line.InvoiceID, &line.InvoiceNumber,
+			&line.SupplierID, &line.SupplierName, &line.SupplierTaxID, &line.TaxCode, &rate, &line.Currency,
+			&base, &withheld); err != nil {
+			return nil, err
+		}
+		line.PaidAt = dateToTime(paidAt)
+		line.Rate = numericToFloat(rate)
+		line.Base = numericToFloat(base)
+		line.Withheld = numericToFloat(withheld)
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
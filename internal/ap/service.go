@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/procurement"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 var (
@@ -14,12 +18,30 @@ var (
 	ErrPaymentNotFound = errors.New("payment not found")
 	ErrInvalidStatus   = errors.New("invalid status for operation")
 	ErrAlreadyInvoiced = errors.New("invoice already exists for GRN")
+	// ErrVoidReasonRequired indicates a void request without a reason.
+	ErrVoidReasonRequired = errors.New("void reason required")
 )
 
+// PeriodGuard checks that the accounting period covering a date still
+// accepts postings.
+type PeriodGuard interface {
+	EnsureOpen(ctx context.Context, date time.Time) error
+}
+
+// AuditPort records AP invoice status changes.
+type AuditPort interface {
+	Record(ctx context.Context, log shared.AuditLog) error
+}
+
 type Service struct {
 	repo               Repository
 	procurementService *procurement.Service
 	integration        procurement.IntegrationHandler
+	audit              AuditPort
+	dueDates           shared.DueDatePolicy
+	periods            PeriodGuard
+	currencies         shared.CurrencyValidator
+	taxes              shared.DefaultTaxResolver
 }
 
 func NewService(repo Repository, procService *procurement.Service) *Service {
@@ -34,13 +56,84 @@ func (s *Service) SetIntegrationHandler(handler procurement.IntegrationHandler)
 	s.integration = handler
 }
 
+// SetAuditor enables before/after audit snapshots on invoice status changes.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
+}
+
+// SetPeriodGuard restricts voiding posted invoices to open periods.
+func (s *Service) SetPeriodGuard(guard PeriodGuard) {
+	s.periods = guard
+}
+
+// SetDueDatePolicy rolls new invoice due dates to the company's business days.
+func (s *Service) SetDueDatePolicy(policy shared.DueDatePolicy) {
+	s.dueDates = policy
+}
+
+// SetCurrencyValidator rejects new invoices in inactive currencies.
+func (s *Service) SetCurrencyValidator(v shared.CurrencyValidator) {
+	s.currencies = v
+}
+
+// recordInvoiceChange stores the invoice state around a status change. Audit
+// failures never fail the operation itself.
+func (s *Service) recordInvoiceChange(ctx context.Context, action string, actorID int64, before APInvoice, meta map[string]any) {
+	if s.audit == nil {
+		return
+	}
+	after, err := s.repo.GetAPInvoice(ctx, before.ID)
+	if err != nil {
+		return
+	}
+	if actorID == 0 {
+		actorID = shared.AuditActorFromContext(ctx)
+	}
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	meta["number"] = after.Number
+	_ = s.audit.Record(ctx, shared.AuditLog{
+		ActorID:  actorID,
+		Action:   action,
+		Entity:   "ap_invoices",
+		EntityID: strconv.FormatInt(after.ID, 10),
+		Meta:     meta,
+		Before:   before,
+		After:    after,
+	})
+}
+
 // CreateAPInvoice creates a new AP invoice manually.
 func (s *Service) CreateAPInvoice(ctx context.Context, input CreateAPInvoiceInput) (APInvoice, error) {
 	if len(input.Lines) == 0 {
 		return APInvoice{}, errors.New("at least one line is required")
 	}
+	if s.currencies != nil {
+		if err := s.currencies.ValidateCurrency(ctx, input.Currency); err != nil {
+			return APInvoice{}, err
+		}
+	}
+	if err := s.applyPaymentTerms(ctx, &input); err != nil {
+		return APInvoice{}, err
+	}
+	if err := s.applyWithholding(ctx, &input); err != nil {
+		return APInvoice{}, err
+	}
+	companyID, err := s.repo.SupplierCompanyID(ctx, input.SupplierID)
+	if err != nil {
+		return APInvoice{}, fmt.Errorf("load supplier company: %w", err)
+	}
+	input.CompanyID = companyID
+	if s.dueDates != nil {
+		due, err := s.dueDates.AdjustDueDate(ctx, input.CompanyID, input.DueDate)
+		if err != nil {
+			return APInvoice{}, fmt.Errorf("adjust due date: %w", err)
+		}
+		input.DueDate = due
+	}
 	var invoiceID int64
-	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
 		// Generate number if not provided
 		if input.Number == "" {
 			num, err := tx.GenerateAPInvoiceNumber(ctx)
@@ -62,6 +155,8 @@ func (s *Service) CreateAPInvoice(ctx context.Context, input CreateAPInvoiceInpu
 		input.Subtotal = subtotal
 		input.TaxAmount = taxAmount
 		input.Total = subtotal + taxAmount
+		// Withholding is levied on the base before VAT (DPP).
+		input.WithholdingAmount = roundAmount(subtotal * input.WithholdingRate / 100)
 
 		id, err := tx.CreateAPInvoice(ctx, input)
 		if err != nil {
@@ -93,9 +188,6 @@ func (s *Service) CreateAPInvoiceFromGRN(ctx context.Context, input CreateAPInvo
 	if count > 0 {
 		return APInvoice{}, ErrAlreadyInvoiced
 	}
-	if input.DueDate.IsZero() {
-		input.DueDate = time.Now().AddDate(0, 0, 30)
-	}
 
 	// 1. Get GRN details
 	grn, lines, err := s.procurementService.GetGRNWithLines(ctx, input.GRNID)
@@ -110,13 +202,15 @@ func (s *Service) CreateAPInvoiceFromGRN(ctx context.Context, input CreateAPInvo
 	// 2. Prepare Invoice Input
 	currency := "IDR"
 	invInput := CreateAPInvoiceInput{
-		SupplierID: grn.SupplierID,
-		GRNID:      &grn.ID,
-		POID:       nil,
-		DueDate:    input.DueDate,
-		CreatedBy:  input.CreatedBy,
-		Currency:   currency,
-		Number:     input.Number,
+		SupplierID:       grn.SupplierID,
+		GRNID:            &grn.ID,
+		POID:             nil,
+		IssuedAt:         input.IssuedAt,
+		DueDate:          input.DueDate,
+		CreatedBy:        input.CreatedBy,
+		Currency:         currency,
+		Number:           input.Number,
+		WithholdingTaxID: input.WithholdingTaxID,
 	}
 	if grn.POID != 0 {
 		po, _, err := s.procurementService.GetPOWithLines(ctx, grn.POID)
@@ -137,16 +231,30 @@ func (s *Service) CreateAPInvoiceFromGRN(ctx context.Context, input CreateAPInvo
 		invInput.POID = &poID
 	}
 
-	// 3. Map Lines
+	inputTax, err := s.resolveInputTax(ctx, grn.SupplierID, input.InputTaxID, input.IssuedAt)
+	if err != nil {
+		return APInvoice{}, err
+	}
+	var taxPct float64
+	if inputTax != nil {
+		taxPct = inputTax.Rate
+	}
+
+	// 3. Map Lines; goods rejected in quality inspection went back to the
+	// supplier and are not billed.
 	for _, l := range lines {
+		qty := l.Qty - l.RejectedQty
+		if qty <= 0 {
+			continue
+		}
 		invInput.Lines = append(invInput.Lines, CreateAPInvoiceLineInput{
 			GRNLineID:   &l.ID,
 			ProductID:   l.ProductID,
 			Description: fmt.Sprintf("Product %d", l.ProductID), // Should fetch product name ideally
-			Quantity:    l.Qty,
+			Quantity:    qty,
 			UnitPrice:   l.UnitCost,
 			DiscountPct: 0,
-			TaxPct:      0, // Need logic to fetch tax from PO
+			TaxPct:      taxPct,
 		})
 	}
 
@@ -160,9 +268,6 @@ func (s *Service) CreateAPInvoiceFromPO(ctx context.Context, input CreateAPInvoi
 	if err != nil {
 		return APInvoice{}, fmt.Errorf("failed to get PO: %w", err)
 	}
-	if input.DueDate.IsZero() {
-		input.DueDate = time.Now().AddDate(0, 0, 30)
-	}
 
 	if po.Status != procurement.POStatusApproved && po.Status != procurement.POStatusClosed {
 		return APInvoice{}, errors.New("PO must be approved before invoicing")
@@ -177,12 +282,23 @@ func (s *Service) CreateAPInvoiceFromPO(ctx context.Context, input CreateAPInvoi
 	}
 
 	invInput := CreateAPInvoiceInput{
-		SupplierID: po.SupplierID,
-		Currency:   currency,
-		DueDate:    input.DueDate,
-		CreatedBy:  input.CreatedBy,
-		Number:     input.Number,
-		POID:       &input.POID,
+		SupplierID:       po.SupplierID,
+		Currency:         currency,
+		IssuedAt:         input.IssuedAt,
+		DueDate:          input.DueDate,
+		CreatedBy:        input.CreatedBy,
+		Number:           input.Number,
+		POID:             &input.POID,
+		WithholdingTaxID: input.WithholdingTaxID,
+	}
+
+	inputTax, err := s.resolveInputTax(ctx, po.SupplierID, input.InputTaxID, input.IssuedAt)
+	if err != nil {
+		return APInvoice{}, err
+	}
+	var taxPct float64
+	if inputTax != nil {
+		taxPct = inputTax.Rate
 	}
 
 	for _, l := range lines {
@@ -196,7 +312,7 @@ func (s *Service) CreateAPInvoiceFromPO(ctx context.Context, input CreateAPInvoi
 			Quantity:    l.Qty,
 			UnitPrice:   l.Price,
 			DiscountPct: 0,
-			TaxPct:      0,
+			TaxPct:      taxPct,
 		})
 	}
 
@@ -217,6 +333,7 @@ func (s *Service) PostAPInvoice(ctx context.Context, input PostAPInvoiceInput) e
 	}); err != nil {
 		return err
 	}
+	s.recordInvoiceChange(ctx, "ap_invoice.post", input.PostedBy, inv, nil)
 
 	if s.integration != nil {
 		invoice, err := s.repo.GetAPInvoice(ctx, input.InvoiceID)
@@ -237,7 +354,9 @@ func (s *Service) PostAPInvoice(ctx context.Context, input PostAPInvoiceInput) e
 			Number:     invoice.Number,
 			SupplierID: invoice.SupplierID,
 			GRNID:      grnID,
+			Currency:   invoice.Currency,
 			Total:      invoice.Total,
+			TaxAmount:  invoice.TaxAmount,
 			PostedAt:   *postedAt,
 		}); err != nil {
 			return err
@@ -246,8 +365,13 @@ func (s *Service) PostAPInvoice(ctx context.Context, input PostAPInvoiceInput) e
 	return nil
 }
 
-// VoidAPInvoice voids an invoice.
+// VoidAPInvoice voids an invoice. Voiding a posted invoice needs its posting
+// period to be open and reverses the ledger entry in that period.
 func (s *Service) VoidAPInvoice(ctx context.Context, input VoidAPInvoiceInput) error {
+	input.VoidReason = strings.TrimSpace(input.VoidReason)
+	if input.VoidReason == "" {
+		return ErrVoidReasonRequired
+	}
 	inv, err := s.repo.GetAPInvoice(ctx, input.InvoiceID)
 	if err != nil {
 		return err
@@ -255,9 +379,43 @@ func (s *Service) VoidAPInvoice(ctx context.Context, input VoidAPInvoiceInput) e
 	if inv.Status == APStatusPaid || inv.Status == APStatusVoid {
 		return ErrInvalidStatus
 	}
-	return s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
-		return tx.VoidAPInvoice(ctx, input)
-	})
+	posted := inv.Status == APStatusPosted
+	postedAt := inv.IssuedAt
+	if inv.PostedAt != nil {
+		postedAt = *inv.PostedAt
+	}
+	if posted && s.periods != nil {
+		if err := s.periods.EnsureOpen(ctx, postedAt); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		if err := tx.VoidAPInvoice(ctx, input); err != nil {
+			return err
+		}
+		if !posted || s.integration == nil {
+			return nil
+		}
+		var grnID int64
+		if inv.GRNID != nil {
+			grnID = *inv.GRNID
+		}
+		return s.integration.HandleAPInvoiceVoided(ctx, procurement.APInvoiceVoidedEvent{
+			ID:         inv.ID,
+			Number:     inv.Number,
+			SupplierID: inv.SupplierID,
+			GRNID:      grnID,
+			Currency:   inv.Currency,
+			Total:      inv.Total,
+			TaxAmount:  inv.TaxAmount,
+			PostedAt:   postedAt,
+			Reason:     input.VoidReason,
+		})
+	}); err != nil {
+		return err
+	}
+	s.recordInvoiceChange(ctx, "ap_invoice.void", input.VoidedBy, inv, map[string]any{"reason": input.VoidReason})
+	return nil
 }
 
 // RegisterAPPayment records a payment.
@@ -279,6 +437,8 @@ func (s *Service) RegisterAPPayment(ctx context.Context, input CreateAPPaymentIn
 		invoiceTotals[alloc.APInvoiceID] += alloc.Amount
 	}
 	var supplierID int64
+	var currency string
+	details := make(map[int64]APInvoiceWithDetails, len(invoiceTotals))
 	for invoiceID, allocTotal := range invoiceTotals {
 		inv, err := s.repo.GetAPInvoice(ctx, invoiceID)
 		if err != nil {
@@ -292,6 +452,11 @@ func (s *Service) RegisterAPPayment(ctx context.Context, input CreateAPPaymentIn
 		if input.SupplierID != 0 && inv.SupplierID != input.SupplierID {
 			return APPayment{}, errors.New("payment supplier does not match invoice supplier")
 		}
+		if currency == "" {
+			currency = inv.Currency
+		} else if inv.Currency != currency {
+			return APPayment{}, errors.New("allocations must reference invoices in the same currency")
+		}
 		if inv.Status != APStatusPosted {
 			return APPayment{}, fmt.Errorf("invoice %s must be posted before payment allocation", inv.Number)
 		}
@@ -312,11 +477,15 @@ func (s *Service) RegisterAPPayment(ctx context.Context, input CreateAPPaymentIn
 		if allocTotal > detail.Balance {
 			return APPayment{}, fmt.Errorf("allocation exceeds invoice %s balance", inv.Number)
 		}
+		details[invoiceID] = detail
 	}
 	if input.SupplierID == 0 && supplierID != 0 {
 		input.SupplierID = supplierID
 	}
-	if totalAllocated > input.Amount {
+	// Allocations settle invoices gross while the withheld tax is not paid in
+	// cash, so only the net has to be covered by the payment amount.
+	input.WithheldAmount = withholdAllocations(input.Allocations, details)
+	if roundAmount(totalAllocated-input.WithheldAmount) > input.Amount {
 		return APPayment{}, errors.New("total allocation exceeds payment amount")
 	}
 
@@ -372,14 +541,15 @@ func (s *Service) RegisterAPPayment(ctx context.Context, input CreateAPPaymentIn
 		apInvoiceIDPtr = &allocationInvoiceID
 	}
 	payment := APPayment{
-		ID:          paymentID,
-		Number:      input.Number,
-		APInvoiceID: apInvoiceIDPtr,
-		SupplierID:  input.SupplierID,
-		Amount:      input.Amount,
-		PaidAt:      input.PaidAt,
-		Method:      input.Method,
-		Note:        input.Note,
+		ID:             paymentID,
+		Number:         input.Number,
+		APInvoiceID:    apInvoiceIDPtr,
+		SupplierID:     input.SupplierID,
+		Amount:         input.Amount,
+		PaidAt:         input.PaidAt,
+		Method:         input.Method,
+		Note:           input.Note,
+		WithheldAmount: input.WithheldAmount,
 	}
 
 	if s.integration != nil {
@@ -394,7 +564,9 @@ func (s *Service) RegisterAPPayment(ctx context.Context, input CreateAPPaymentIn
 			ID:          paymentID,
 			Number:      input.Number,
 			APInvoiceID: apInvoiceID,
+			Currency:    currency,
 			Amount:      input.Amount,
+			Withheld:    input.WithheldAmount,
 			PaidAt:      input.PaidAt,
 		}); err != nil {
 			return payment, wrapLedgerPostError(err)
@@ -407,33 +579,78 @@ func (s *Service) RegisterAPPayment(ctx context.Context, input CreateAPPaymentIn
 
 // CalculateAPAging returns aging summary.
 func (s *Service) CalculateAPAging(ctx context.Context, asOf time.Time) (APAgingBucket, error) {
-	balances, err := s.repo.GetAPInvoiceBalancesBatch(ctx)
+	report, err := s.APAgingReport(ctx, asOf)
 	if err != nil {
 		return APAgingBucket{}, err
 	}
+	return report.Summary, nil
+}
 
-	bucket := APAgingBucket{}
+// APAgingReport ages every open posted invoice by days past due at asOf and
+// breaks the totals down by supplier, largest balance first.
+func (s *Service) APAgingReport(ctx context.Context, asOf time.Time) (APAgingReport, error) {
+	balances, err := s.repo.GetAPInvoiceBalancesBatch(ctx)
+	if err != nil {
+		return APAgingReport{}, err
+	}
 
+	report := APAgingReport{AsOf: asOf}
+	suppliers := make(map[int64]*APAgingDetail)
 	for _, inv := range balances {
 		if inv.Balance <= 0 {
 			continue
 		}
-
 		daysOverdue := int(asOf.Sub(inv.DueAt).Hours() / 24)
+		report.Summary.add(daysOverdue, inv.Balance)
 
-		if daysOverdue <= 0 {
-			bucket.Current += inv.Balance
-		} else if daysOverdue <= 30 {
-			bucket.Bucket30 += inv.Balance
-		} else if daysOverdue <= 60 {
-			bucket.Bucket60 += inv.Balance
-		} else if daysOverdue <= 90 {
-			bucket.Bucket90 += inv.Balance
-		} else {
-			bucket.Bucket120 += inv.Balance
+		detail := suppliers[inv.SupplierID]
+		if detail == nil {
+			detail = &APAgingDetail{SupplierID: inv.SupplierID, SupplierName: inv.SupplierName}
+			suppliers[inv.SupplierID] = detail
+		}
+		detail.add(daysOverdue, inv.Balance)
+	}
+	for _, detail := range suppliers {
+		report.Suppliers = append(report.Suppliers, *detail)
+	}
+	sort.Slice(report.Suppliers, func(i, j int) bool {
+		a, b := report.Suppliers[i], report.Suppliers[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
 		}
+		return a.SupplierName < b.SupplierName
+	})
+	return report, nil
+}
+
+// Total sums every bucket.
+func (b APAgingBucket) Total() float64 {
+	return b.Current + b.Bucket30 + b.Bucket60 + b.Bucket90 + b.Bucket120
+}
+
+func (b *APAgingBucket) add(daysOverdue int, amount float64) {
+	*agingSlot(daysOverdue, &b.Current, &b.Bucket30, &b.Bucket60, &b.Bucket90, &b.Bucket120) += amount
+}
+
+func (d *APAgingDetail) add(daysOverdue int, amount float64) {
+	*agingSlot(daysOverdue, &d.Current, &d.Bucket30, &d.Bucket60, &d.Bucket90, &d.Bucket120) += amount
+	d.Total += amount
+}
+
+// agingSlot picks the bucket a balance falls in by days past due.
+func agingSlot(daysOverdue int, current, bucket30, bucket60, bucket90, bucket120 *float64) *float64 {
+	switch {
+	case daysOverdue <= 0:
+		return current
+	case daysOverdue <= 30:
+		return bucket30
+	case daysOverdue <= 60:
+		return bucket60
+	case daysOverdue <= 90:
+		return bucket90
+	default:
+		return bucket120
 	}
-	return bucket, nil
 }
 
 func (s *Service) ListAPInvoices(ctx context.Context, req ListAPInvoicesRequest) ([]APInvoice, error) {
@@ -0,0 +1,76 @@
+package ap
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// GRIRReport lists GRNs received but not (fully) invoiced as of the filter
+// date, grouped by supplier, and compares the total with the GR/IR account.
+func (s *Service) GRIRReport(ctx context.Context, filter GRIRFilter) (GRIRReport, error) {
+	if filter.AsOf.IsZero() {
+		filter.AsOf = time.Now()
+	}
+	lines, err := s.repo.ListGRIROutstanding(ctx, filter)
+	if err != nil {
+		return GRIRReport{}, err
+	}
+	suppliers, total := SummarizeGRIR(lines, filter.AsOf)
+	report := GRIRReport{Filter: filter, Suppliers: suppliers, Total: total}
+	if filter.SupplierID != 0 {
+		return report, nil
+	}
+	ledger, ok, err := s.repo.GRIRLedgerBalance(ctx, filter.AsOf)
+	if err != nil {
+		return GRIRReport{}, err
+	}
+	if ok {
+		report.Ledger = &ledger
+		report.Difference = roundAmount(ledger.Balance - total)
+	}
+	return report, nil
+}
+
+// SummarizeGRIR computes the accrual and age of each GRN and groups them by
+// supplier, largest accrual first. It returns the grand total.
+func SummarizeGRIR(lines []GRIROutstanding, asOf time.Time) ([]GRIRSupplier, float64) {
+	index := make(map[int64]int)
+	var (
+		suppliers []GRIRSupplier
+		total     float64
+	)
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	for _, line := range lines {
+		line.Accrued = roundAmount(line.ReceivedValue - line.InvoicedValue)
+		if line.Accrued <= 0 {
+			continue
+		}
+		received := time.Date(line.ReceivedAt.Year(), line.ReceivedAt.Month(), line.ReceivedAt.Day(), 0, 0, 0, 0, time.UTC)
+		if age := int(day.Sub(received).Hours() / 24); age > 0 {
+			line.AgeDays = age
+		}
+		i, ok := index[line.SupplierID]
+		if !ok {
+			i = len(suppliers)
+			index[line.SupplierID] = i
+			suppliers = append(suppliers, GRIRSupplier{SupplierID: line.SupplierID, SupplierName: line.SupplierName})
+		}
+		suppliers[i].GRNs = append(suppliers[i].GRNs, line)
+		suppliers[i].Accrued += line.Accrued
+		total += line.Accrued
+	}
+	for i := range suppliers {
+		suppliers[i].Accrued = roundAmount(suppliers[i].Accrued)
+		sort.SliceStable(suppliers[i].GRNs, func(a, b int) bool {
+			return suppliers[i].GRNs[a].ReceivedAt.Before(suppliers[i].GRNs[b].ReceivedAt)
+		})
+	}
+	sort.SliceStable(suppliers, func(i, j int) bool {
+		if suppliers[i].Accrued != suppliers[j].Accrued {
+			return suppliers[i].Accrued > suppliers[j].Accrued
+		}
+		return suppliers[i].SupplierName < suppliers[j].SupplierName
+	})
+	return suppliers, roundAmount(total)
+}
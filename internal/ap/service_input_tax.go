@@ -0,0 +1,60 @@
+package ap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ErrUnknownInputTax rejects an input tax that does not exist.
+var ErrUnknownInputTax = fmt.Errorf("%w: unknown input tax", shared.ErrValidation)
+
+// SetDefaultTaxResolver enables the supplier's default input tax on invoices
+// created from goods receipts and purchase orders.
+func (s *Service) SetDefaultTaxResolver(taxes shared.DefaultTaxResolver) {
+	s.taxes = taxes
+}
+
+// resolveInputTax picks the input tax of an invoice created from a receipt or
+// order: the one chosen on the invoice, else the supplier's default, at the
+// rate effective on the issue date. A nil result charges no tax; a supplier
+// without a default is invoiced without tax rather than blocking payables.
+func (s *Service) resolveInputTax(ctx context.Context, supplierID int64, taxID *int64, on time.Time) (*shared.TaxRate, error) {
+	if s.taxes == nil || (taxID != nil && *taxID == 0) {
+		return nil, nil
+	}
+	if taxID == nil {
+		return s.taxes.SupplierDefaultTax(ctx, supplierID, on)
+	}
+	tax, err := s.taxes.RateOn(ctx, *taxID, on)
+	if err != nil {
+		return nil, err
+	}
+	if tax == nil {
+		return nil, ErrUnknownInputTax
+	}
+	return tax, nil
+}
+
+// InputTaxNotice describes the supplier's default input tax for the flash
+// shown after creating an invoice; it is empty when none applies.
+func (s *Service) InputTaxNotice(ctx context.Context, supplierID int64, on time.Time) (string, error) {
+	if s.taxes == nil {
+		return "", nil
+	}
+	tax, err := s.taxes.SupplierDefaultTax(ctx, supplierID, on)
+	if err != nil || tax == nil {
+		return "", err
+	}
+	if tax.Exempt() {
+		return fmt.Sprintf("Supplier is tax exempt (%s); no input tax charged.", tax.Code), nil
+	}
+	return fmt.Sprintf("Supplier default tax %s applied.", tax.Notice()), nil
+}
+
+// ListInputTaxes returns the taxes that can be charged on supplier invoices.
+func (s *Service) ListInputTaxes(ctx context.Context) ([]shared.TaxRate, error) {
+	return s.repo.ListInputTaxes(ctx)
+}
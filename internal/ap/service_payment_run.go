@@ -0,0 +1,325 @@
+package ap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/procurement"
+)
+
+var (
+	ErrPaymentRunNotFound = errors.New("payment run not found")
+	ErrPaymentRunEmpty    = errors.New("select at least one invoice to pay")
+	ErrPaymentRunStale    = errors.New("some selected invoices are no longer payable in this run")
+	ErrCashLimitExceeded  = errors.New("selected invoices exceed the cash limit")
+	ErrInvalidCashLimit   = errors.New("cash limit cannot be negative")
+)
+
+// cashTolerance absorbs float rounding when comparing totals with the cash limit.
+const cashTolerance = 0.005
+
+// PreviewPaymentRun lists the posted invoices due by the filter date, oldest
+// first, that fit within the cash limit. Nothing is written.
+func (s *Service) PreviewPaymentRun(ctx context.Context, filter PaymentRunFilter) (PaymentRunPreview, error) {
+	if filter.CashLimit < 0 {
+		return PaymentRunPreview{}, ErrInvalidCashLimit
+	}
+	if filter.DueBefore.IsZero() {
+		filter.DueBefore = time.Now()
+	}
+	candidates, err := s.repo.ListPaymentRunCandidates(ctx, filter)
+	if err != nil {
+		return PaymentRunPreview{}, err
+	}
+	selected, deferred := SelectPaymentRunInvoices(candidates, filter.CashLimit)
+	var total float64
+	for _, inv := range selected {
+		total += inv.Net()
+	}
+	return PaymentRunPreview{
+		Filter:    filter,
+		Suppliers: groupBySupplier(selected),
+		Deferred:  deferred,
+		Total:     roundAmount(total),
+	}, nil
+}
+
+// SelectPaymentRunInvoices picks invoices in due-date order while their cash,
+// net of withholding, fits in the cash limit. An invoice that would breach the
+// limit is deferred, but a smaller invoice due later may still be selected. A
+// zero limit selects all.
+func SelectPaymentRunInvoices(candidates []PaymentRunInvoice, cashLimit float64) (selected, deferred []PaymentRunInvoice) {
+	ordered := append([]PaymentRunInvoice(nil), candidates...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if !ordered[i].DueAt.Equal(ordered[j].DueAt) {
+			return ordered[i].DueAt.Before(ordered[j].DueAt)
+		}
+		return ordered[i].InvoiceID < ordered[j].InvoiceID
+	})
+	var total float64
+	for _, inv := range ordered {
+		if inv.Amount <= 0 {
+			continue
+		}
+		if cashLimit > 0 && total+inv.Net() > cashLimit+cashTolerance {
+			deferred = append(deferred, inv)
+			continue
+		}
+		total += inv.Net()
+		selected = append(selected, inv)
+	}
+	return selected, deferred
+}
+
+// CreatePaymentRun pays the selected invoices in full, withholding any tax
+// still due on them from the cash paid. Payments, allocations and status
+// updates are written in one transaction against locked invoices, then a
+// single combined cash/AP journal is posted for the run.
+func (s *Service) CreatePaymentRun(ctx context.Context, input CreatePaymentRunInput) (APPaymentRun, error) {
+	if input.Filter.CashLimit < 0 {
+		return APPaymentRun{}, ErrInvalidCashLimit
+	}
+	ids := uniqueIDs(input.InvoiceIDs)
+	if len(ids) == 0 {
+		return APPaymentRun{}, ErrPaymentRunEmpty
+	}
+	if input.PayDate.IsZero() {
+		input.PayDate = time.Now()
+	}
+	if input.Filter.DueBefore.IsZero() {
+		input.Filter.DueBefore = input.PayDate
+	}
+	if input.Method == "" {
+		input.Method = "TRANSFER"
+	}
+
+	run := APPaymentRun{
+		PayDate:         input.PayDate,
+		Method:          input.Method,
+		Note:            input.Note,
+		DueBefore:       input.Filter.DueBefore,
+		CashLimit:       input.Filter.CashLimit,
+		GroupBySupplier: input.GroupBySupplier,
+		CreatedBy:       input.CreatedBy,
+	}
+	if input.Filter.SupplierID != 0 {
+		supplierID := input.Filter.SupplierID
+		run.SupplierID = &supplierID
+	}
+
+	var byCurrency, withheldByCurrency map[string]float64
+	var withheld float64
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		byCurrency = make(map[string]float64)
+		withheldByCurrency = make(map[string]float64)
+		withheld = 0
+		invoices, err := tx.LockPayableInvoices(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if len(invoices) != len(ids) {
+			return ErrPaymentRunStale
+		}
+		var total float64
+		for _, inv := range invoices {
+			if inv.Amount <= 0 || inv.DueAt.After(run.DueBefore) {
+				return fmt.Errorf("%w: %s", ErrPaymentRunStale, inv.Number)
+			}
+			if run.SupplierID != nil && inv.SupplierID != *run.SupplierID {
+				return fmt.Errorf("%w: %s", ErrPaymentRunStale, inv.Number)
+			}
+			total += inv.Net()
+			byCurrency[inv.Currency] += inv.Net()
+			if inv.Withheld > 0 {
+				withheld += inv.Withheld
+				withheldByCurrency[inv.Currency] += inv.Withheld
+			}
+		}
+		if run.CashLimit > 0 && total > run.CashLimit+cashTolerance {
+			return ErrCashLimitExceeded
+		}
+		run.Total = roundAmount(total)
+
+		number, err := tx.GenerateAPPaymentRunNumber(ctx)
+		if err != nil {
+			return err
+		}
+		run.Number = number
+		runID, err := tx.CreatePaymentRun(ctx, run)
+		if err != nil {
+			return err
+		}
+		run.ID = runID
+
+		note := input.Note
+		if note == "" {
+			note = "Payment run " + run.Number
+		}
+		for _, batch := range paymentRunBatches(invoices, input.GroupBySupplier) {
+			payment := CreateAPPaymentInput{
+				SupplierID:   batch[0].SupplierID,
+				PaidAt:       run.PayDate,
+				Method:       run.Method,
+				Note:         note,
+				CreatedBy:    input.CreatedBy,
+				PaymentRunID: &runID,
+			}
+			for _, inv := range batch {
+				payment.Amount += inv.Net()
+				payment.WithheldAmount += inv.Withheld
+				payment.Allocations = append(payment.Allocations, PaymentAllocationInput{
+					APInvoiceID: inv.InvoiceID,
+					Amount:      inv.Amount,
+					Withheld:    inv.Withheld,
+				})
+			}
+			payment.Amount = roundAmount(payment.Amount)
+			payment.WithheldAmount = roundAmount(payment.WithheldAmount)
+			if payment.Number, err = tx.GenerateAPPaymentNumber(ctx); err != nil {
+				return err
+			}
+			paymentID, err := tx.CreateAPPayment(ctx, payment)
+			if err != nil {
+				return err
+			}
+			for _, alloc := range payment.Allocations {
+				if err := tx.CreatePaymentAllocation(ctx, alloc, paymentID); err != nil {
+					return err
+				}
+				if err := tx.UpdateAPStatus(ctx, alloc.APInvoiceID, APStatusPaid); err != nil {
+					return err
+				}
+			}
+			run.PaymentCount++
+		}
+		return nil
+	})
+	if err != nil {
+		return APPaymentRun{}, err
+	}
+
+	if s.integration != nil {
+		if err := s.integration.HandleAPPaymentRunPosted(ctx, procurement.APPaymentRunPostedEvent{
+			ID:                 run.ID,
+			Number:             run.Number,
+			Amount:             run.Total,
+			PaidAt:             run.PayDate,
+			ByCurrency:         byCurrency,
+			Withheld:           roundAmount(withheld),
+			WithheldByCurrency: withheldByCurrency,
+		}); err != nil {
+			return run, wrapLedgerPostError(err)
+		}
+	}
+	return run, nil
+}
+
+// ListPaymentRuns returns recent payment runs.
+func (s *Service) ListPaymentRuns(ctx context.Context) ([]APPaymentRun, error) {
+	return s.repo.ListPaymentRuns(ctx)
+}
+
+// GetPaymentRun returns a payment run with its per-supplier breakdown.
+func (s *Service) GetPaymentRun(ctx context.Context, id int64) (APPaymentRunWithDetails, error) {
+	return s.repo.GetPaymentRun(ctx, id)
+}
+
+// Remittance builds the remittance advice for one supplier paid by a run, in
+// one currency. An empty currency picks the supplier's first currency.
+func (s *Service) Remittance(ctx context.Context, runID, supplierID int64, currency string) (RemittanceAdvice, error) {
+	run, err := s.repo.GetPaymentRun(ctx, runID)
+	if err != nil {
+		return RemittanceAdvice{}, err
+	}
+	for _, supplier := range run.Suppliers {
+		if supplier.SupplierID == supplierID && (currency == "" || supplier.Currency == currency) {
+			return RemittanceAdvice{
+				RunNumber: run.Number,
+				PayDate:   run.PayDate,
+				Method:    run.Method,
+				Note:      run.Note,
+				Supplier:  supplier,
+			}, nil
+		}
+	}
+	return RemittanceAdvice{}, ErrPaymentRunNotFound
+}
+
+// paymentRunBatches splits invoices into one batch per payment: per supplier
+// and currency when grouping, otherwise per invoice.
+func paymentRunBatches(invoices []PaymentRunInvoice, bySupplier bool) [][]PaymentRunInvoice {
+	var batches [][]PaymentRunInvoice
+	if !bySupplier {
+		for _, inv := range invoices {
+			batches = append(batches, []PaymentRunInvoice{inv})
+		}
+		return batches
+	}
+	for _, supplier := range groupBySupplier(invoices) {
+		batches = append(batches, supplier.Invoices)
+	}
+	return batches
+}
+
+// groupBySupplier groups invoices per supplier and currency, ordered by
+// supplier name then currency, keeping the invoice order within each group.
+// Amounts in different currencies are never added together.
+func groupBySupplier(invoices []PaymentRunInvoice) []PaymentRunSupplier {
+	type groupKey struct {
+		supplierID int64
+		currency   string
+	}
+	index := make(map[groupKey]int)
+	var suppliers []PaymentRunSupplier
+	for _, inv := range invoices {
+		key := groupKey{inv.SupplierID, inv.Currency}
+		i, ok := index[key]
+		if !ok {
+			i = len(suppliers)
+			index[key] = i
+			suppliers = append(suppliers, PaymentRunSupplier{SupplierID: inv.SupplierID, SupplierName: inv.SupplierName, Currency: inv.Currency})
+		}
+		suppliers[i].Invoices = append(suppliers[i].Invoices, inv)
+		suppliers[i].Total += inv.Net()
+		suppliers[i].Withheld += inv.Withheld
+	}
+	for i := range suppliers {
+		suppliers[i].Total = roundAmount(suppliers[i].Total)
+		suppliers[i].Withheld = roundAmount(suppliers[i].Withheld)
+	}
+	sort.SliceStable(suppliers, func(i, j int) bool {
+		if suppliers[i].SupplierName != suppliers[j].SupplierName {
+			return suppliers[i].SupplierName < suppliers[j].SupplierName
+		}
+		if suppliers[i].SupplierID != suppliers[j].SupplierID {
+			return suppliers[i].SupplierID < suppliers[j].SupplierID
+		}
+		return suppliers[i].Currency < suppliers[j].Currency
+	})
+	return suppliers
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
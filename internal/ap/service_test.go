@@ -9,19 +9,29 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/procurement"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 type memoryAPRepo struct {
-	invoices     map[int64]APInvoice
-	lines        map[int64][]APInvoiceLine
-	payments     map[int64]APPayment
-	allocations  map[int64][]APPaymentAllocation
-	nextID       int64
-	nextLineID   int64
-	nextPayID    int64
-	nextAllocID  int64
-	numberCursor int64
+	invoices        map[int64]APInvoice
+	lines           map[int64][]APInvoiceLine
+	payments        map[int64]APPayment
+	allocations     map[int64][]APPaymentAllocation
+	runs            map[int64]APPaymentRun
+	nextID          int64
+	nextLineID      int64
+	nextPayID       int64
+	nextAllocID     int64
+	numberCursor    int64
+	grir            []GRIROutstanding
+	grirLedger      *GRIRLedger
+	terms           map[int64]int
+	supplierCompany map[int64]int64
+	taxes           map[int64]WithholdingTax
+	supplierWHT     map[int64]int64
+	whtLines        []WithholdingLine
 }
 
 type memoryAPTx struct {
@@ -34,6 +44,7 @@ func newMemoryAPRepo() *memoryAPRepo {
 		lines:       make(map[int64][]APInvoiceLine),
 		payments:    make(map[int64]APPayment),
 		allocations: make(map[int64][]APPaymentAllocation),
+		runs:        make(map[int64]APPaymentRun),
 	}
 }
 
@@ -46,13 +57,22 @@ func (r *memoryAPRepo) GetAPInvoice(ctx context.Context, id int64) (APInvoice, e
 	if !ok {
 		return APInvoice{}, ErrInvoiceNotFound
 	}
+	inv.Withheld = r.withheld(id)
 	return inv, nil
 }
 
+func (r *memoryAPRepo) withheld(id int64) float64 {
+	var total float64
+	for _, alloc := range r.allocations[id] {
+		total += alloc.Withheld
+	}
+	return total
+}
+
 func (r *memoryAPRepo) GetAPInvoiceWithDetails(ctx context.Context, id int64) (APInvoiceWithDetails, error) {
-	inv, ok := r.invoices[id]
-	if !ok {
-		return APInvoiceWithDetails{}, ErrInvoiceNotFound
+	inv, err := r.GetAPInvoice(ctx, id)
+	if err != nil {
+		return APInvoiceWithDetails{}, err
 	}
 	lines := append([]APInvoiceLine(nil), r.lines[id]...)
 	allocs := r.allocations[id]
@@ -94,6 +114,59 @@ func (r *memoryAPRepo) ListAPInvoices(ctx context.Context, req ListAPInvoicesReq
 	return out, nil
 }
 
+func (r *memoryAPRepo) SupplierCompanyID(ctx context.Context, supplierID int64) (int64, error) {
+	return r.supplierCompany[supplierID], nil
+}
+
+func (r *memoryAPRepo) SupplierPaymentTerms(ctx context.Context, supplierID int64) (int, error) {
+	if days, ok := r.terms[supplierID]; ok {
+		return days, nil
+	}
+	return 30, nil
+}
+
+func (r *memoryAPRepo) SupplierWithholdingTax(ctx context.Context, supplierID int64) (*WithholdingTax, error) {
+	taxID, ok := r.supplierWHT[supplierID]
+	if !ok {
+		return nil, nil
+	}
+	tax := r.taxes[taxID]
+	return &tax, nil
+}
+
+func (r *memoryAPRepo) GetWithholdingTax(ctx context.Context, id int64) (WithholdingTax, error) {
+	tax, ok := r.taxes[id]
+	if !ok {
+		return WithholdingTax{}, ErrNotWithholdingTax
+	}
+	return tax, nil
+}
+
+func (r *memoryAPRepo) ListInputTaxes(ctx context.Context) ([]shared.TaxRate, error) {
+	return nil, nil
+}
+
+func (r *memoryAPRepo) ListWithholdingTaxes(ctx context.Context) ([]WithholdingTax, error) {
+	var out []WithholdingTax
+	for _, tax := range r.taxes {
+		out = append(out, tax)
+	}
+	return out, nil
+}
+
+func (r *memoryAPRepo) ListWithholdingLines(ctx context.Context, filter WithholdingFilter) ([]WithholdingLine, error) {
+	var out []WithholdingLine
+	for _, line := range r.whtLines {
+		if line.PaidAt.Before(filter.From) || line.PaidAt.After(filter.To) {
+			continue
+		}
+		if filter.SupplierID == 0 || line.SupplierID == filter.SupplierID {
+			out = append(out, line)
+		}
+	}
+	return out, nil
+}
+
 func (r *memoryAPRepo) CountInvoicesByGRN(ctx context.Context, grnID int64) (int, error) {
 	count := 0
 	for _, inv := range r.invoices {
@@ -138,11 +211,12 @@ func (r *memoryAPRepo) GetAPPaymentWithDetails(ctx context.Context, id int64) (A
 				InvoiceTotal:  inv.Total,
 				DueAt:         inv.DueAt,
 				Amount:        alloc.Amount,
+				Withheld:      alloc.Withheld,
 			})
 			totalAllocated += alloc.Amount
 		}
 	}
-	unallocated := pay.Amount - totalAllocated
+	unallocated := pay.Amount + pay.WithheldAmount - totalAllocated
 	if unallocated < 0 {
 		unallocated = 0
 	}
@@ -168,36 +242,156 @@ func (r *memoryAPRepo) GetAPInvoiceBalancesBatch(ctx context.Context) ([]APInvoi
 		balance := inv.Total - paid
 		if balance > 0 {
 			balances = append(balances, APInvoiceBalance{
-				ID:         inv.ID,
-				DueAt:      inv.DueAt,
-				Total:      inv.Total,
-				PaidAmount: paid,
-				Balance:    balance,
+				ID:           inv.ID,
+				SupplierID:   inv.SupplierID,
+				SupplierName: inv.SupplierName,
+				DueAt:        inv.DueAt,
+				Total:        inv.Total,
+				PaidAmount:   paid,
+				Balance:      balance,
 			})
 		}
 	}
 	return balances, nil
 }
 
+func (r *memoryAPRepo) openBalance(id int64) float64 {
+	balance := r.invoices[id].Total
+	for _, alloc := range r.allocations[id] {
+		balance -= alloc.Amount
+	}
+	return balance
+}
+
+func (r *memoryAPRepo) runInvoice(inv APInvoice, amount float64) PaymentRunInvoice {
+	return PaymentRunInvoice{
+		InvoiceID:    inv.ID,
+		Number:       inv.Number,
+		SupplierID:   inv.SupplierID,
+		SupplierName: inv.SupplierName,
+		Currency:     inv.Currency,
+		DueAt:        inv.DueAt,
+		Amount:       amount,
+	}
+}
+
+// payableRunInvoice is an open invoice with the withholding still due on it.
+func (r *memoryAPRepo) payableRunInvoice(inv APInvoice) PaymentRunInvoice {
+	out := r.runInvoice(inv, r.openBalance(inv.ID))
+	inv.Withheld = r.withheld(inv.ID)
+	out.Withheld = inv.WithholdingDue()
+	return out
+}
+
+func (r *memoryAPRepo) ListPaymentRunCandidates(ctx context.Context, filter PaymentRunFilter) ([]PaymentRunInvoice, error) {
+	var out []PaymentRunInvoice
+	for id, inv := range r.invoices {
+		if inv.Status != APStatusPosted || inv.DueAt.After(filter.DueBefore) {
+			continue
+		}
+		if filter.SupplierID != 0 && inv.SupplierID != filter.SupplierID {
+			continue
+		}
+		if r.openBalance(id) > 0 {
+			out = append(out, r.payableRunInvoice(inv))
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryAPRepo) ListPaymentRuns(ctx context.Context) ([]APPaymentRun, error) {
+	var out []APPaymentRun
+	for _, run := range r.runs {
+		out = append(out, run)
+	}
+	return out, nil
+}
+
+func (r *memoryAPRepo) ListGRIROutstanding(ctx context.Context, filter GRIRFilter) ([]GRIROutstanding, error) {
+	var out []GRIROutstanding
+	for _, line := range r.grir {
+		if filter.SupplierID == 0 || line.SupplierID == filter.SupplierID {
+			out = append(out, line)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryAPRepo) GRIRLedgerBalance(ctx context.Context, asOf time.Time) (GRIRLedger, bool, error) {
+	if r.grirLedger == nil {
+		return GRIRLedger{}, false, nil
+	}
+	return *r.grirLedger, true, nil
+}
+
+func (r *memoryAPRepo) GetPaymentRun(ctx context.Context, id int64) (APPaymentRunWithDetails, error) {
+	run, ok := r.runs[id]
+	if !ok {
+		return APPaymentRunWithDetails{}, ErrPaymentRunNotFound
+	}
+	var invoices []PaymentRunInvoice
+	for invoiceID, allocs := range r.allocations {
+		for _, alloc := range allocs {
+			pay := r.payments[alloc.APPaymentID]
+			if pay.PaymentRunID == nil || *pay.PaymentRunID != id {
+				continue
+			}
+			inv := r.runInvoice(r.invoices[invoiceID], alloc.Amount)
+			inv.PaymentID, inv.PaymentNumber = pay.ID, pay.Number
+			inv.Withheld = alloc.Withheld
+			invoices = append(invoices, inv)
+		}
+	}
+	return APPaymentRunWithDetails{APPaymentRun: run, Suppliers: groupBySupplier(invoices)}, nil
+}
+
+func (tx *memoryAPTx) LockPayableInvoices(ctx context.Context, ids []int64) ([]PaymentRunInvoice, error) {
+	var out []PaymentRunInvoice
+	for _, id := range ids {
+		inv, ok := tx.repo.invoices[id]
+		if !ok || inv.Status != APStatusPosted {
+			continue
+		}
+		out = append(out, tx.repo.payableRunInvoice(inv))
+	}
+	return out, nil
+}
+
+func (tx *memoryAPTx) CreatePaymentRun(ctx context.Context, run APPaymentRun) (int64, error) {
+	run.ID = int64(len(tx.repo.runs) + 1)
+	tx.repo.runs[run.ID] = run
+	return run.ID, nil
+}
+
+func (tx *memoryAPTx) GenerateAPPaymentRunNumber(ctx context.Context) (string, error) {
+	tx.repo.numberCursor++
+	return "PR-TEST-" + fmtInt(tx.repo.numberCursor), nil
+}
+
 func (tx *memoryAPTx) CreateAPInvoice(ctx context.Context, input CreateAPInvoiceInput) (int64, error) {
 	tx.repo.nextID++
 	id := tx.repo.nextID
 	now := time.Now()
 	inv := APInvoice{
-		ID:         id,
-		Number:     input.Number,
-		SupplierID: input.SupplierID,
-		GRNID:      input.GRNID,
-		POID:       input.POID,
-		Currency:   input.Currency,
-		Subtotal:   input.Subtotal,
-		TaxAmount:  input.TaxAmount,
-		Total:      input.Total,
-		Status:     APStatusDraft,
-		DueAt:      input.DueDate,
-		CreatedBy:  input.CreatedBy,
-		CreatedAt:  now,
-		UpdatedAt:  now,
+		ID:                id,
+		Number:            input.Number,
+		SupplierID:        input.SupplierID,
+		GRNID:             input.GRNID,
+		POID:              input.POID,
+		Currency:          input.Currency,
+		Subtotal:          input.Subtotal,
+		TaxAmount:         input.TaxAmount,
+		Total:             input.Total,
+		Status:            APStatusDraft,
+		IssuedAt:          input.IssuedAt,
+		DueAt:             input.DueDate,
+		CreatedBy:         input.CreatedBy,
+		PaymentTermsDays:  input.PaymentTermsDays,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		WithholdingTaxID:  input.WithholdingTaxID,
+		WithholdingRate:   input.WithholdingRate,
+		WithholdingAmount: input.WithholdingAmount,
 	}
 	tx.repo.invoices[id] = inv
 	return id, nil
@@ -276,17 +470,19 @@ func (tx *memoryAPTx) CreateAPPayment(ctx context.Context, input CreateAPPayment
 		apInvoiceID = &first
 	}
 	payment := APPayment{
-		ID:          id,
-		Number:      input.Number,
-		APInvoiceID: apInvoiceID,
-		SupplierID:  input.SupplierID,
-		Amount:      input.Amount,
-		PaidAt:      input.PaidAt,
-		Method:      input.Method,
-		Note:        input.Note,
-		CreatedBy:   input.CreatedBy,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:             id,
+		Number:         input.Number,
+		APInvoiceID:    apInvoiceID,
+		SupplierID:     input.SupplierID,
+		Amount:         input.Amount,
+		PaidAt:         input.PaidAt,
+		Method:         input.Method,
+		Note:           input.Note,
+		PaymentRunID:   input.PaymentRunID,
+		CreatedBy:      input.CreatedBy,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		WithheldAmount: input.WithheldAmount,
 	}
 	tx.repo.payments[id] = payment
 	return id, nil
@@ -299,6 +495,7 @@ func (tx *memoryAPTx) CreatePaymentAllocation(ctx context.Context, input Payment
 		APPaymentID: paymentID,
 		APInvoiceID: input.APInvoiceID,
 		Amount:      input.Amount,
+		Withheld:    input.Withheld,
 		CreatedAt:   time.Now(),
 	}
 	tx.repo.allocations[input.APInvoiceID] = append(tx.repo.allocations[input.APInvoiceID], alloc)
@@ -363,6 +560,26 @@ func (s *stubProcRepo) ListGRNs(ctx context.Context, limit, offset int, filters
 	return nil, 0, nil
 }
 
+func (s *stubProcRepo) SerialTrackedProducts(ctx context.Context, productIDs []int64) (map[int64]bool, error) {
+	return nil, nil
+}
+
+func (s *stubProcRepo) SupplierApprovalStatus(ctx context.Context, supplierID int64) (string, error) {
+	return "APPROVED", nil
+}
+
+func (s *stubProcRepo) ExistingSerials(ctx context.Context, productID int64, serials []string) ([]string, error) {
+	return nil, nil
+}
+
+func (s *stubProcRepo) InspectionRequirements(ctx context.Context, supplierID int64, productIDs []int64) (map[int64]bool, error) {
+	return nil, nil
+}
+
+func (s *stubProcRepo) ListInspections(ctx context.Context, filter procurement.InspectionFilter) ([]procurement.GRNInspection, error) {
+	return nil, nil
+}
+
 func TestCreateAPInvoiceFromGRN(t *testing.T) {
 	ctx := context.Background()
 	apRepo := newMemoryAPRepo()
@@ -404,6 +621,29 @@ func TestCreateAPInvoiceFromGRN(t *testing.T) {
 	require.Len(t, apRepo.lines[inv.ID], 2)
 }
 
+func TestCreateAPInvoiceFromGRNBillsOnlyGoodsNotRejected(t *testing.T) {
+	ctx := context.Background()
+	apRepo := newMemoryAPRepo()
+	procRepo := newStubProcRepo()
+	procRepo.grns[1] = procurement.GoodsReceipt{ID: 1, SupplierID: 10, Status: procurement.GRNStatusPosted}
+	procRepo.grnLines[1] = []procurement.GRNLine{
+		{ID: 1, ProductID: 100, Qty: 4, UnitCost: 50, RejectedQty: 1},
+		{ID: 2, ProductID: 101, Qty: 2, UnitCost: 25, RejectedQty: 2},
+	}
+	procSvc := procurement.NewService(procRepo, nil, nil, nil, nil, nil)
+	svc := NewService(apRepo, procSvc)
+
+	inv, err := svc.CreateAPInvoiceFromGRN(ctx, CreateAPInvoiceFromGRNInput{
+		GRNID:     1,
+		DueDate:   time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC),
+		CreatedBy: 5,
+		Number:    "INV-QC-1",
+	})
+	require.NoError(t, err)
+	require.InDelta(t, 150.0, inv.Total, 0.001)
+	require.Len(t, apRepo.lines[inv.ID], 1, "a fully rejected line is not billed")
+}
+
 func TestCreateAPInvoiceFromPO(t *testing.T) {
 	ctx := context.Background()
 	apRepo := newMemoryAPRepo()
@@ -435,6 +675,33 @@ func TestCreateAPInvoiceFromPO(t *testing.T) {
 	require.InDelta(t, 120.0, inv.Total, 0.001)
 }
 
+type recordingDueDates struct {
+	companies []int64
+}
+
+func (p *recordingDueDates) AdjustDueDate(ctx context.Context, companyID int64, due time.Time) (time.Time, error) {
+	p.companies = append(p.companies, companyID)
+	return due, nil
+}
+
+func TestCreateAPInvoiceUsesSupplierCompanyDueDatePolicy(t *testing.T) {
+	apRepo := newMemoryAPRepo()
+	apRepo.supplierCompany = map[int64]int64{7: 5}
+	svc := NewService(apRepo, nil)
+	policy := &recordingDueDates{}
+	svc.SetDueDatePolicy(policy)
+	ctx := shared.ContextWithCompanyScope(context.Background(), shared.CompanyScope{CompanyIDs: []int64{3, 5}})
+
+	_, err := svc.CreateAPInvoice(ctx, CreateAPInvoiceInput{
+		SupplierID: 7,
+		Currency:   "IDR",
+		DueDate:    time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Lines:      []CreateAPInvoiceLineInput{{ProductID: 1, Quantity: 1, UnitPrice: 100}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{5}, policy.companies, "the supplier's company, not the user's default, sets the due date policy")
+}
+
 func TestRegisterAPPaymentMultiAllocation(t *testing.T) {
 	ctx := context.Background()
 	apRepo := newMemoryAPRepo()
@@ -474,6 +741,531 @@ func TestRegisterAPPaymentMultiAllocation(t *testing.T) {
 	require.Error(t, err)
 }
 
+type recordingIntegration struct {
+	runs     []procurement.APPaymentRunPostedEvent
+	voids    []procurement.APInvoiceVoidedEvent
+	payments []procurement.APPaymentPostedEvent
+}
+
+func (r *recordingIntegration) HandleGRNPosted(context.Context, procurement.GRNPostedEvent) error {
+	return nil
+}
+
+func (r *recordingIntegration) HandleGRNInspectionDecided(context.Context, procurement.GRNInspectionDecidedEvent) error {
+	return nil
+}
+
+func (r *recordingIntegration) HandleAPInvoicePosted(context.Context, procurement.APInvoicePostedEvent) error {
+	return nil
+}
+
+func (r *recordingIntegration) HandleAPInvoiceVoided(_ context.Context, evt procurement.APInvoiceVoidedEvent) error {
+	r.voids = append(r.voids, evt)
+	return nil
+}
+
+func (r *recordingIntegration) HandleAPPaymentPosted(_ context.Context, evt procurement.APPaymentPostedEvent) error {
+	r.payments = append(r.payments, evt)
+	return nil
+}
+
+func (r *recordingIntegration) HandleAPPaymentRunPosted(_ context.Context, evt procurement.APPaymentRunPostedEvent) error {
+	r.runs = append(r.runs, evt)
+	return nil
+}
+
+func TestSelectPaymentRunInvoicesCashLimit(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	candidates := []PaymentRunInvoice{
+		{InvoiceID: 3, DueAt: day(10), Amount: 50},
+		{InvoiceID: 1, DueAt: day(1), Amount: 100},
+		{InvoiceID: 2, DueAt: day(5), Amount: 300},
+	}
+
+	selected, deferred := SelectPaymentRunInvoices(candidates, 200)
+	require.Len(t, selected, 2)
+	require.Equal(t, int64(1), selected[0].InvoiceID)
+	require.Equal(t, int64(3), selected[1].InvoiceID)
+	require.Len(t, deferred, 1)
+	require.Equal(t, int64(2), deferred[0].InvoiceID)
+
+	selected, deferred = SelectPaymentRunInvoices(candidates, 0)
+	require.Len(t, selected, 3)
+	require.Empty(t, deferred)
+}
+
+func TestAPAgingReportBreaksDownBySupplier(t *testing.T) {
+	ctx := context.Background()
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, procurement.NewService(newStubProcRepo(), nil, nil, nil, nil, nil))
+
+	asOf := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	apRepo.invoices[1] = APInvoice{ID: 1, SupplierID: 10, SupplierName: "Acme", Total: 100, Status: APStatusPosted, DueAt: asOf.AddDate(0, 0, 5)}
+	apRepo.invoices[2] = APInvoice{ID: 2, SupplierID: 10, SupplierName: "Acme", Total: 200, Status: APStatusPosted, DueAt: asOf.AddDate(0, 0, -45)}
+	apRepo.invoices[3] = APInvoice{ID: 3, SupplierID: 20, SupplierName: "Globex", Total: 900, Status: APStatusPosted, DueAt: asOf.AddDate(0, 0, -120)}
+	apRepo.invoices[4] = APInvoice{ID: 4, SupplierID: 20, SupplierName: "Globex", Total: 50, Status: APStatusDraft, DueAt: asOf}
+	apRepo.allocations[3] = []APPaymentAllocation{{APInvoiceID: 3, Amount: 400}}
+
+	report, err := svc.APAgingReport(ctx, asOf)
+	require.NoError(t, err)
+	require.Equal(t, asOf, report.AsOf)
+	require.InDelta(t, 100.0, report.Summary.Current, 0.001)
+	require.InDelta(t, 200.0, report.Summary.Bucket60, 0.001)
+	require.InDelta(t, 500.0, report.Summary.Bucket120, 0.001)
+	require.InDelta(t, 800.0, report.Summary.Total(), 0.001)
+
+	require.Len(t, report.Suppliers, 2)
+	require.Equal(t, "Globex", report.Suppliers[0].SupplierName)
+	require.InDelta(t, 500.0, report.Suppliers[0].Total, 0.001)
+	require.Equal(t, int64(10), report.Suppliers[1].SupplierID)
+	require.InDelta(t, 100.0, report.Suppliers[1].Current, 0.001)
+	require.InDelta(t, 200.0, report.Suppliers[1].Bucket60, 0.001)
+	require.InDelta(t, 300.0, report.Suppliers[1].Total, 0.001)
+
+	summary, err := svc.CalculateAPAging(ctx, asOf)
+	require.NoError(t, err)
+	require.Equal(t, report.Summary, summary)
+}
+
+func TestCreatePaymentRunGroupsBySupplier(t *testing.T) {
+	ctx := context.Background()
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, procurement.NewService(newStubProcRepo(), nil, nil, nil, nil, nil))
+	integration := &recordingIntegration{}
+	svc.SetIntegrationHandler(integration)
+
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	apRepo.invoices[1] = APInvoice{ID: 1, Number: "INV-1", SupplierID: 10, SupplierName: "Acme", Total: 100, Status: APStatusPosted, DueAt: due}
+	apRepo.invoices[2] = APInvoice{ID: 2, Number: "INV-2", SupplierID: 10, SupplierName: "Acme", Total: 250, Status: APStatusPosted, DueAt: due}
+	apRepo.invoices[3] = APInvoice{ID: 3, Number: "INV-3", SupplierID: 20, SupplierName: "Globex", Total: 80, Status: APStatusPosted, DueAt: due}
+	apRepo.invoices[4] = APInvoice{ID: 4, Number: "INV-4", SupplierID: 20, SupplierName: "Globex", Total: 500, Status: APStatusPosted, DueAt: due.AddDate(0, 1, 0)}
+
+	preview, err := svc.PreviewPaymentRun(ctx, PaymentRunFilter{DueBefore: due})
+	require.NoError(t, err)
+	require.Len(t, preview.Suppliers, 2)
+	require.InDelta(t, 430.0, preview.Total, 0.001)
+
+	payDate := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	run, err := svc.CreatePaymentRun(ctx, CreatePaymentRunInput{
+		Filter:          PaymentRunFilter{DueBefore: due},
+		InvoiceIDs:      []int64{1, 2, 3},
+		PayDate:         payDate,
+		GroupBySupplier: true,
+		CreatedBy:       1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, run.PaymentCount)
+	require.InDelta(t, 430.0, run.Total, 0.001)
+	require.Len(t, apRepo.payments, 2)
+	for _, id := range []int64{1, 2, 3} {
+		require.Equal(t, APStatusPaid, apRepo.invoices[id].Status)
+	}
+	require.Equal(t, APStatusPosted, apRepo.invoices[4].Status)
+
+	require.Len(t, integration.runs, 1)
+	require.Equal(t, run.ID, integration.runs[0].ID)
+	require.InDelta(t, 430.0, integration.runs[0].Amount, 0.001)
+
+	advice, err := svc.Remittance(ctx, run.ID, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, "Acme", advice.Supplier.SupplierName)
+	require.Len(t, advice.Supplier.Invoices, 2)
+	require.InDelta(t, 350.0, advice.Supplier.Total, 0.001)
+
+	exporter, err := NewRemittanceExporter(nil)
+	require.NoError(t, err)
+	html, err := exporter.HTML(advice)
+	require.NoError(t, err)
+	require.Contains(t, html, "Acme")
+	require.Contains(t, html, "INV-2")
+	require.Contains(t, html, "350.00")
+}
+
+func TestPaymentRunBatchesSplitSupplierCurrencies(t *testing.T) {
+	invoices := []PaymentRunInvoice{
+		{InvoiceID: 1, SupplierID: 10, SupplierName: "Acme", Currency: "IDR", Amount: 100},
+		{InvoiceID: 2, SupplierID: 10, SupplierName: "Acme", Currency: "USD", Amount: 5},
+		{InvoiceID: 3, SupplierID: 10, SupplierName: "Acme", Currency: "IDR", Amount: 50},
+	}
+	suppliers := groupBySupplier(invoices)
+	require.Len(t, suppliers, 2)
+	require.Equal(t, "IDR", suppliers[0].Currency)
+	require.InDelta(t, 150.0, suppliers[0].Total, 0.001)
+	require.Equal(t, "USD", suppliers[1].Currency)
+	require.InDelta(t, 5.0, suppliers[1].Total, 0.001)
+
+	batches := paymentRunBatches(invoices, true)
+	require.Len(t, batches, 2)
+	for _, batch := range batches {
+		for _, inv := range batch {
+			require.Equal(t, batch[0].Currency, inv.Currency)
+		}
+	}
+}
+
+func TestCreatePaymentRunRejectsStaleSelection(t *testing.T) {
+	ctx := context.Background()
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, procurement.NewService(newStubProcRepo(), nil, nil, nil, nil, nil))
+
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	apRepo.invoices[1] = APInvoice{ID: 1, Number: "INV-1", SupplierID: 10, Total: 100, Status: APStatusPaid, DueAt: due}
+	apRepo.invoices[2] = APInvoice{ID: 2, Number: "INV-2", SupplierID: 10, Total: 100, Status: APStatusPosted, DueAt: due}
+
+	_, err := svc.CreatePaymentRun(ctx, CreatePaymentRunInput{Filter: PaymentRunFilter{DueBefore: due}, InvoiceIDs: []int64{1, 2}})
+	require.ErrorIs(t, err, ErrPaymentRunStale)
+
+	_, err = svc.CreatePaymentRun(ctx, CreatePaymentRunInput{Filter: PaymentRunFilter{DueBefore: due, CashLimit: 50}, InvoiceIDs: []int64{2}})
+	require.ErrorIs(t, err, ErrCashLimitExceeded)
+	require.Empty(t, apRepo.payments)
+}
+
 func fmtInt(val int64) string {
 	return strconv.FormatInt(val, 10)
 }
+
+type recordingAudit struct {
+	logs []shared.AuditLog
+}
+
+func (a *recordingAudit) Record(ctx context.Context, log shared.AuditLog) error {
+	a.logs = append(a.logs, log)
+	return nil
+}
+
+func TestVoidAPInvoiceRecordsSnapshots(t *testing.T) {
+	ctx := context.Background()
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, nil)
+	audit := &recordingAudit{}
+	svc.SetAuditor(audit)
+
+	apRepo.invoices[1] = APInvoice{ID: 1, Number: "AP-1", SupplierID: 10, Total: 100, Status: APStatusPosted}
+	require.NoError(t, svc.VoidAPInvoice(ctx, VoidAPInvoiceInput{InvoiceID: 1, VoidedBy: 4, VoidReason: "duplicate"}))
+
+	require.Len(t, audit.logs, 1)
+	log := audit.logs[0]
+	require.Equal(t, "ap_invoice.void", log.Action)
+	require.Equal(t, int64(4), log.ActorID)
+	require.Equal(t, "1", log.EntityID)
+	require.Equal(t, APStatusPosted, log.Before.(APInvoice).Status)
+	require.Equal(t, APStatusVoid, log.After.(APInvoice).Status)
+	require.Equal(t, "duplicate", log.Meta["reason"])
+}
+
+type stubPeriodGuard struct {
+	err   error
+	dates []time.Time
+}
+
+func (g *stubPeriodGuard) EnsureOpen(_ context.Context, date time.Time) error {
+	g.dates = append(g.dates, date)
+	return g.err
+}
+
+func TestVoidAPInvoiceRequiresReason(t *testing.T) {
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, nil)
+	apRepo.invoices[1] = APInvoice{ID: 1, Number: "AP-1", Total: 100, Status: APStatusDraft}
+
+	err := svc.VoidAPInvoice(context.Background(), VoidAPInvoiceInput{InvoiceID: 1, VoidReason: "  "})
+	require.ErrorIs(t, err, ErrVoidReasonRequired)
+	require.Equal(t, APStatusDraft, apRepo.invoices[1].Status)
+}
+
+func TestVoidPostedAPInvoiceRejectedInLockedPeriod(t *testing.T) {
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, nil)
+	integration := &recordingIntegration{}
+	svc.SetIntegrationHandler(integration)
+	svc.SetPeriodGuard(&stubPeriodGuard{err: accounting.ErrPeriodLocked})
+	postedAt := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	apRepo.invoices[1] = APInvoice{ID: 1, Number: "AP-1", Total: 100, Status: APStatusPosted, PostedAt: &postedAt}
+
+	err := svc.VoidAPInvoice(context.Background(), VoidAPInvoiceInput{InvoiceID: 1, VoidReason: "duplicate"})
+	require.ErrorIs(t, err, accounting.ErrPeriodLocked)
+	require.Equal(t, APStatusPosted, apRepo.invoices[1].Status)
+	require.Empty(t, integration.voids)
+}
+
+func TestVoidPostedAPInvoiceReversesLedger(t *testing.T) {
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, nil)
+	integration := &recordingIntegration{}
+	svc.SetIntegrationHandler(integration)
+	guard := &stubPeriodGuard{}
+	svc.SetPeriodGuard(guard)
+	postedAt := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	grnID := int64(7)
+	apRepo.invoices[1] = APInvoice{ID: 1, Number: "AP-1", SupplierID: 10, GRNID: &grnID, Total: 100, Status: APStatusPosted, PostedAt: &postedAt}
+
+	require.NoError(t, svc.VoidAPInvoice(context.Background(), VoidAPInvoiceInput{InvoiceID: 1, VoidedBy: 4, VoidReason: "duplicate"}))
+	require.Equal(t, APStatusVoid, apRepo.invoices[1].Status)
+	require.Equal(t, []time.Time{postedAt}, guard.dates)
+	require.Len(t, integration.voids, 1)
+	evt := integration.voids[0]
+	require.Equal(t, int64(7), evt.GRNID)
+	require.Equal(t, 100.0, evt.Total)
+	require.Equal(t, postedAt, evt.PostedAt)
+	require.Equal(t, "duplicate", evt.Reason)
+}
+
+func TestVoidDraftAPInvoiceSkipsPeriodAndLedger(t *testing.T) {
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, nil)
+	integration := &recordingIntegration{}
+	svc.SetIntegrationHandler(integration)
+	guard := &stubPeriodGuard{err: accounting.ErrPeriodLocked}
+	svc.SetPeriodGuard(guard)
+	apRepo.invoices[1] = APInvoice{ID: 1, Number: "AP-1", Total: 100, Status: APStatusDraft}
+
+	require.NoError(t, svc.VoidAPInvoice(context.Background(), VoidAPInvoiceInput{InvoiceID: 1, VoidReason: "typo"}))
+	require.Equal(t, APStatusVoid, apRepo.invoices[1].Status)
+	require.Empty(t, guard.dates)
+	require.Empty(t, integration.voids)
+}
+
+func TestGRIRReportGroupsBySupplierAndTiesToLedger(t *testing.T) {
+	repo := newMemoryAPRepo()
+	asOf := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+	repo.grir = []GRIROutstanding{
+		{GRNID: 1, GRNNumber: "GRN-001", SupplierID: 10, SupplierName: "Acme", ReceivedAt: asOf.AddDate(0, 0, -45), ReceivedValue: 1000},
+		{GRNID: 2, GRNNumber: "GRN-002", SupplierID: 20, SupplierName: "Borneo", ReceivedAt: asOf.AddDate(0, 0, -3), ReceivedValue: 2500, InvoicedValue: 1000, InvoiceCount: 1},
+		{GRNID: 3, GRNNumber: "GRN-003", SupplierID: 10, SupplierName: "Acme", ReceivedAt: asOf.AddDate(0, 0, -10), ReceivedValue: 300.25},
+	}
+	repo.grirLedger = &GRIRLedger{AccountCode: "5500", AccountName: "GRIR Clearing", Balance: 2800.25}
+	svc := NewService(repo, nil)
+
+	report, err := svc.GRIRReport(context.Background(), GRIRFilter{AsOf: asOf})
+	require.NoError(t, err)
+	require.Len(t, report.Suppliers, 2)
+	require.Equal(t, "Borneo", report.Suppliers[0].SupplierName)
+	require.Equal(t, 1500.0, report.Suppliers[0].Accrued)
+	require.True(t, report.Suppliers[0].GRNs[0].PartiallyInvoiced())
+	require.Equal(t, 1300.25, report.Suppliers[1].Accrued)
+	require.Equal(t, []string{"GRN-001", "GRN-003"}, []string{report.Suppliers[1].GRNs[0].GRNNumber, report.Suppliers[1].GRNs[1].GRNNumber})
+	require.Equal(t, 45, report.Suppliers[1].GRNs[0].AgeDays)
+	require.Equal(t, 2800.25, report.Total)
+	require.NotNil(t, report.Ledger)
+	require.True(t, report.Reconciled())
+
+	filtered, err := svc.GRIRReport(context.Background(), GRIRFilter{AsOf: asOf, SupplierID: 10})
+	require.NoError(t, err)
+	require.Len(t, filtered.Suppliers, 1)
+	require.Nil(t, filtered.Ledger, "GL balance is not per supplier")
+	require.False(t, filtered.Reconciled())
+}
+
+func TestCreateAPInvoiceDefaultsDueDateFromSupplierTerms(t *testing.T) {
+	ctx := context.Background()
+	apRepo := newMemoryAPRepo()
+	apRepo.terms = map[int64]int{7: 45}
+	svc := NewService(apRepo, nil)
+	issued := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	lines := []CreateAPInvoiceLineInput{{ProductID: 1, Quantity: 1, UnitPrice: 100}}
+
+	inv, err := svc.CreateAPInvoice(ctx, CreateAPInvoiceInput{
+		SupplierID: 7,
+		Number:     "INV-T-1",
+		IssuedAt:   issued,
+		Lines:      lines,
+	})
+	require.NoError(t, err)
+	require.Equal(t, issued.AddDate(0, 0, 45), inv.DueAt)
+	require.NotNil(t, inv.PaymentTermsDays)
+	require.Equal(t, 45, *inv.PaymentTermsDays)
+
+	override := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	inv, err = svc.CreateAPInvoice(ctx, CreateAPInvoiceInput{
+		SupplierID: 7,
+		Number:     "INV-T-2",
+		IssuedAt:   issued,
+		DueDate:    override,
+		Lines:      lines,
+	})
+	require.NoError(t, err)
+	require.Equal(t, override, inv.DueAt)
+	require.Nil(t, inv.PaymentTermsDays)
+}
+
+func withholdingRepo() *memoryAPRepo {
+	apRepo := newMemoryAPRepo()
+	apRepo.taxes = map[int64]WithholdingTax{3: {ID: 3, Code: "PPH23", Name: "PPh 23", Rate: 2}}
+	apRepo.supplierWHT = map[int64]int64{7: 3}
+	return apRepo
+}
+
+func TestCreateAPInvoiceAppliesSupplierWithholding(t *testing.T) {
+	ctx := context.Background()
+	apRepo := withholdingRepo()
+	svc := NewService(apRepo, nil)
+	lines := []CreateAPInvoiceLineInput{{ProductID: 1, Quantity: 10, UnitPrice: 100, TaxPct: 11}}
+
+	inv, err := svc.CreateAPInvoice(ctx, CreateAPInvoiceInput{SupplierID: 7, Number: "INV-W-1", Lines: lines})
+	require.NoError(t, err)
+	require.NotNil(t, inv.WithholdingTaxID)
+	require.Equal(t, int64(3), *inv.WithholdingTaxID)
+	require.InDelta(t, 20.0, inv.WithholdingAmount, 0.001)
+
+	none := int64(0)
+	inv, err = svc.CreateAPInvoice(ctx, CreateAPInvoiceInput{SupplierID: 7, Number: "INV-W-2", Lines: lines, WithholdingTaxID: &none})
+	require.NoError(t, err)
+	require.Nil(t, inv.WithholdingTaxID)
+	require.Zero(t, inv.WithholdingAmount)
+
+	vat := int64(9)
+	_, err = svc.CreateAPInvoice(ctx, CreateAPInvoiceInput{SupplierID: 8, Number: "INV-W-3", Lines: lines, WithholdingTaxID: &vat})
+	require.ErrorIs(t, err, ErrNotWithholdingTax)
+}
+
+func TestRegisterAPPaymentWithholdsTax(t *testing.T) {
+	ctx := context.Background()
+	apRepo := withholdingRepo()
+	svc := NewService(apRepo, procurement.NewService(newStubProcRepo(), nil, nil, nil, nil, nil))
+	integration := &recordingIntegration{}
+	svc.SetIntegrationHandler(integration)
+	taxID := int64(3)
+	apRepo.invoices[1] = APInvoice{ID: 1, SupplierID: 7, Currency: "IDR", Subtotal: 1000, Total: 1110,
+		Status: APStatusPosted, WithholdingTaxID: &taxID, WithholdingRate: 2, WithholdingAmount: 20}
+	paidAt := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	// Cash must cover the allocation net of its withholding.
+	_, err := svc.RegisterAPPayment(ctx, CreateAPPaymentInput{
+		Amount: 360, PaidAt: paidAt, Allocations: []PaymentAllocationInput{{APInvoiceID: 1, Amount: 370}},
+	})
+	require.Error(t, err)
+
+	// Each third withholds its 6.67 share; the settling payment withholds
+	// the 6.66 still due so the invoice withholds exactly 20.
+	var payment APPayment
+	for _, cash := range []float64{363.33, 363.33, 363.34} {
+		payment, err = svc.RegisterAPPayment(ctx, CreateAPPaymentInput{
+			Amount: cash, PaidAt: paidAt, Allocations: []PaymentAllocationInput{{APInvoiceID: 1, Amount: 370}},
+		})
+		require.NoError(t, err)
+	}
+	require.InDelta(t, 6.66, payment.WithheldAmount, 0.001)
+	require.InDelta(t, 20.0, apRepo.withheld(1), 0.001)
+	require.Equal(t, APStatusPaid, apRepo.invoices[1].Status)
+
+	detail, err := svc.GetAPPaymentWithDetails(ctx, payment.ID)
+	require.NoError(t, err)
+	require.Zero(t, detail.Unallocated)
+	require.Len(t, integration.payments, 3)
+	require.InDelta(t, 363.34, integration.payments[2].Amount, 0.001)
+	require.InDelta(t, 6.66, integration.payments[2].Withheld, 0.001)
+}
+
+func TestCreatePaymentRunNetsWithholding(t *testing.T) {
+	ctx := context.Background()
+	apRepo := withholdingRepo()
+	svc := NewService(apRepo, procurement.NewService(newStubProcRepo(), nil, nil, nil, nil, nil))
+	integration := &recordingIntegration{}
+	svc.SetIntegrationHandler(integration)
+
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	apRepo.invoices[1] = APInvoice{ID: 1, Number: "INV-1", SupplierID: 7, SupplierName: "Acme", Currency: "IDR",
+		Subtotal: 1000, Total: 1110, Status: APStatusPosted, DueAt: due, WithholdingRate: 2, WithholdingAmount: 20}
+	apRepo.invoices[2] = APInvoice{ID: 2, Number: "INV-2", SupplierID: 7, SupplierName: "Acme", Currency: "IDR",
+		Total: 100, Status: APStatusPosted, DueAt: due}
+
+	// Only the cash after withholding counts against the limit.
+	preview, err := svc.PreviewPaymentRun(ctx, PaymentRunFilter{DueBefore: due, CashLimit: 1190})
+	require.NoError(t, err)
+	require.Empty(t, preview.Deferred)
+	require.InDelta(t, 1190.0, preview.Total, 0.001)
+
+	run, err := svc.CreatePaymentRun(ctx, CreatePaymentRunInput{
+		Filter:          PaymentRunFilter{DueBefore: due, CashLimit: 1190},
+		InvoiceIDs:      []int64{1, 2},
+		PayDate:         due,
+		GroupBySupplier: true,
+	})
+	require.NoError(t, err)
+	require.InDelta(t, 1190.0, run.Total, 0.001)
+	require.Len(t, apRepo.payments, 1)
+	for _, payment := range apRepo.payments {
+		require.InDelta(t, 1190.0, payment.Amount, 0.001)
+		require.InDelta(t, 20.0, payment.WithheldAmount, 0.001)
+	}
+	require.InDelta(t, 20.0, apRepo.withheld(1), 0.001)
+	require.Len(t, integration.runs, 1)
+	require.InDelta(t, 20.0, integration.runs[0].WithheldByCurrency["IDR"], 0.001)
+
+	advice, err := svc.Remittance(ctx, run.ID, 7, "")
+	require.NoError(t, err)
+	require.InDelta(t, 1190.0, advice.Supplier.Total, 0.001)
+	require.InDelta(t, 20.0, advice.Supplier.Withheld, 0.001)
+}
+
+func TestWithholdingReportGroupsBySupplierAndTax(t *testing.T) {
+	ctx := context.Background()
+	apRepo := newMemoryAPRepo()
+	svc := NewService(apRepo, nil)
+	day := func(d int) time.Time { return time.Date(2026, 4, d, 0, 0, 0, 0, time.UTC) }
+	apRepo.whtLines = []WithholdingLine{
+		{PaymentID: 1, SupplierID: 2, SupplierName: "Globex", TaxCode: "PPH23", Currency: "IDR", PaidAt: day(3), Base: 1000, Withheld: 20},
+		{PaymentID: 2, SupplierID: 1, SupplierName: "Acme", TaxCode: "PPH23", Currency: "IDR", PaidAt: day(5), Base: 500, Withheld: 10},
+		{PaymentID: 3, SupplierID: 2, SupplierName: "Globex", TaxCode: "PPH23", Currency: "IDR", PaidAt: day(9), Base: 250, Withheld: 5},
+		{PaymentID: 4, SupplierID: 2, SupplierName: "Globex", TaxCode: "PPH4-2", Currency: "IDR", PaidAt: day(9), Base: 1000, Withheld: 100},
+		{PaymentID: 5, SupplierID: 1, SupplierName: "Acme", TaxCode: "PPH23", Currency: "IDR", PaidAt: day(1).AddDate(0, 1, 0), Base: 100, Withheld: 2},
+	}
+
+	report, err := svc.WithholdingReport(ctx, WithholdingFilter{From: day(1)})
+	require.NoError(t, err)
+	require.Equal(t, day(30), report.Filter.To)
+	require.Len(t, report.Suppliers, 3)
+	require.Equal(t, "Acme", report.Suppliers[0].SupplierName)
+	require.Equal(t, "PPH23", report.Suppliers[1].TaxCode)
+	require.Len(t, report.Suppliers[1].Lines, 2)
+	require.InDelta(t, 25.0, report.Suppliers[1].Withheld, 0.001)
+	require.Equal(t, []WithholdingTotal{
+		{TaxCode: "PPH23", Currency: "IDR", Base: 1750, Withheld: 35},
+		{TaxCode: "PPH4-2", Currency: "IDR", Base: 1000, Withheld: 100},
+	}, report.Totals)
+}
+
+type stubTaxResolver struct {
+	suppliers map[int64]shared.TaxRate
+	dates     []time.Time
+}
+
+func (r *stubTaxResolver) CustomerDefaultTax(context.Context, int64, time.Time) (*shared.TaxRate, error) {
+	return nil, nil
+}
+
+func (r *stubTaxResolver) SupplierDefaultTax(_ context.Context, supplierID int64, on time.Time) (*shared.TaxRate, error) {
+	r.dates = append(r.dates, on)
+	tax, ok := r.suppliers[supplierID]
+	if !ok {
+		return nil, nil
+	}
+	return &tax, nil
+}
+
+func (r *stubTaxResolver) RateOn(context.Context, int64, time.Time) (*shared.TaxRate, error) {
+	return nil, nil
+}
+
+func TestCreateAPInvoiceFromPOAppliesSupplierDefaultTax(t *testing.T) {
+	ctx := context.Background()
+	procRepo := newStubProcRepo()
+	procRepo.pos[10] = procurement.PurchaseOrder{ID: 10, SupplierID: 7, Status: procurement.POStatusApproved, Currency: "IDR"}
+	procRepo.poLines[10] = []procurement.POLine{{ID: 1, ProductID: 500, Qty: 3, Price: 40}}
+	svc := NewService(newMemoryAPRepo(), procurement.NewService(procRepo, nil, nil, nil, nil, nil))
+	taxes := &stubTaxResolver{suppliers: map[int64]shared.TaxRate{7: {TaxID: 1, Code: "PPN", Rate: 11}}}
+	svc.SetDefaultTaxResolver(taxes)
+	issued := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	inv, err := svc.CreateAPInvoiceFromPO(ctx, CreateAPInvoiceFromPOInput{POID: 10, IssuedAt: issued, Number: "INV-TAX-1"})
+	require.NoError(t, err)
+	require.InDelta(t, 13.2, inv.TaxAmount, 0.001)
+	require.InDelta(t, 133.2, inv.Total, 0.001)
+	require.Equal(t, []time.Time{issued}, taxes.dates, "the rate is taken on the invoice date")
+
+	none := int64(0)
+	inv, err = svc.CreateAPInvoiceFromPO(ctx, CreateAPInvoiceFromPOInput{POID: 10, IssuedAt: issued, Number: "INV-TAX-2", InputTaxID: &none})
+	require.NoError(t, err)
+	require.Zero(t, inv.TaxAmount)
+	require.InDelta(t, 120.0, inv.Total, 0.001)
+}
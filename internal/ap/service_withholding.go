@@ -0,0 +1,173 @@
+package ap
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ErrNotWithholdingTax rejects a tax that is not flagged as withholding.
+var ErrNotWithholdingTax = fmt.Errorf("%w: tax is not a withholding tax", shared.ErrValidation)
+
+// applyWithholding resolves the withholding tax of a new invoice: the one
+// chosen on the invoice, else the supplier's default. The amount is computed
+// once the subtotal is known.
+func (s *Service) applyWithholding(ctx context.Context, input *CreateAPInvoiceInput) error {
+	input.WithholdingRate = 0
+	input.WithholdingAmount = 0
+	var tax *WithholdingTax
+	switch {
+	case input.WithholdingTaxID == nil:
+		t, err := s.repo.SupplierWithholdingTax(ctx, input.SupplierID)
+		if err != nil {
+			return err
+		}
+		tax = t
+	case *input.WithholdingTaxID > 0:
+		t, err := s.repo.GetWithholdingTax(ctx, *input.WithholdingTaxID)
+		if err != nil {
+			return err
+		}
+		tax = &t
+	}
+	if tax == nil {
+		input.WithholdingTaxID = nil
+		return nil
+	}
+	id := tax.ID
+	input.WithholdingTaxID = &id
+	input.WithholdingRate = tax.Rate
+	return nil
+}
+
+// withholdAllocations sets the tax withheld on each allocation and returns
+// the total. A partial payment withholds its pro-rata share of the invoice
+// withholding; the allocation that settles an invoice withholds whatever is
+// still due, so rounding never leaves withholding behind.
+func withholdAllocations(allocs []PaymentAllocationInput, invoices map[int64]APInvoiceWithDetails) float64 {
+	balance := make(map[int64]float64, len(invoices))
+	due := make(map[int64]float64, len(invoices))
+	for id, inv := range invoices {
+		balance[id] = inv.Balance
+		due[id] = inv.WithholdingDue()
+	}
+	var total float64
+	for i := range allocs {
+		alloc := &allocs[i]
+		inv := invoices[alloc.APInvoiceID]
+		alloc.Withheld = 0
+		if remaining := due[alloc.APInvoiceID]; remaining > 0 && inv.Total > 0 {
+			if alloc.Amount >= balance[alloc.APInvoiceID]-cashTolerance {
+				alloc.Withheld = roundAmount(remaining)
+			} else {
+				alloc.Withheld = math.Min(roundAmount(alloc.Amount*inv.WithholdingAmount/inv.Total), roundAmount(remaining))
+			}
+		}
+		balance[alloc.APInvoiceID] -= alloc.Amount
+		due[alloc.APInvoiceID] -= alloc.Withheld
+		total += alloc.Withheld
+	}
+	return roundAmount(total)
+}
+
+// ListWithholdingTaxes returns the taxes that can be withheld from payments.
+func (s *Service) ListWithholdingTaxes(ctx context.Context) ([]WithholdingTax, error) {
+	return s.repo.ListWithholdingTaxes(ctx)
+}
+
+// WithholdingReport summarises the tax withheld from payments made in the
+// filter period, which defaults to the current month.
+func (s *Service) WithholdingReport(ctx context.Context, filter WithholdingFilter) (WithholdingReport, error) {
+	if filter.From.IsZero() {
+		now := time.Now()
+		filter.From = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	if filter.To.IsZero() || filter.To.Before(filter.From) {
+		filter.To = filter.From.AddDate(0, 1, -1)
+	}
+	lines, err := s.repo.ListWithholdingLines(ctx, filter)
+	if err != nil {
+		return WithholdingReport{}, err
+	}
+	suppliers, totals := SummarizeWithholding(lines)
+	return WithholdingReport{Filter: filter, Suppliers: suppliers, Totals: totals}, nil
+}
+
+// SummarizeWithholding groups withholding lines per supplier, tax and
+// currency, ordered by supplier name, and totals them per tax and currency.
+func SummarizeWithholding(lines []WithholdingLine) ([]WithholdingSupplier, []WithholdingTotal) {
+	type key struct {
+		supplierID int64
+		taxCode    string
+		currency   string
+	}
+	type totalKey struct {
+		taxCode  string
+		currency string
+	}
+	index := make(map[key]int)
+	totalIndex := make(map[totalKey]int)
+	var (
+		suppliers []WithholdingSupplier
+		totals    []WithholdingTotal
+	)
+	for _, line := range lines {
+		k := key{line.SupplierID, line.TaxCode, line.Currency}
+		i, ok := index[k]
+		if !ok {
+			i = len(suppliers)
+			index[k] = i
+			suppliers = append(suppliers, WithholdingSupplier{
+				SupplierID:    line.SupplierID,
+				SupplierName:  line.SupplierName,
+				SupplierTaxID: line.SupplierTaxID,
+				TaxCode:       line.TaxCode,
+				Currency:      line.Currency,
+			})
+		}
+		suppliers[i].Lines = append(suppliers[i].Lines, line)
+		suppliers[i].Base += line.Base
+		suppliers[i].Withheld += line.Withheld
+
+		tk := totalKey{line.TaxCode, line.Currency}
+		j, ok := totalIndex[tk]
+		if !ok {
+			j = len(totals)
+			totalIndex[tk] = j
+			totals = append(totals, WithholdingTotal{TaxCode: line.TaxCode, Currency: line.Currency})
+		}
+		totals[j].Base += line.Base
+		totals[j].Withheld += line.Withheld
+	}
+	for i := range suppliers {
+		suppliers[i].Base = roundAmount(suppliers[i].Base)
+		suppliers[i].Withheld = roundAmount(suppliers[i].Withheld)
+	}
+	for i := range totals {
+		totals[i].Base = roundAmount(totals[i].Base)
+		totals[i].Withheld = roundAmount(totals[i].Withheld)
+	}
+	sort.SliceStable(suppliers, func(i, j int) bool {
+		if suppliers[i].SupplierName != suppliers[j].SupplierName {
+			return suppliers[i].SupplierName < suppliers[j].SupplierName
+		}
+		if suppliers[i].SupplierID != suppliers[j].SupplierID {
+			return suppliers[i].SupplierID < suppliers[j].SupplierID
+		}
+		if suppliers[i].TaxCode != suppliers[j].TaxCode {
+			return suppliers[i].TaxCode < suppliers[j].TaxCode
+		}
+		return suppliers[i].Currency < suppliers[j].Currency
+	})
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].TaxCode != totals[j].TaxCode {
+			return totals[i].TaxCode < totals[j].TaxCode
+		}
+		return totals[i].Currency < totals[j].Currency
+	})
+	return suppliers, totals
+}
@@ -14,6 +14,7 @@ type Config struct {
 	AppReadTimeout    time.Duration `envconfig:"APP_READ_TIMEOUT" default:"15s"`
 	AppWriteTimeout   time.Duration `envconfig:"APP_WRITE_TIMEOUT" default:"15s"`
 	AppRequestTimeout time.Duration `envconfig:"APP_REQUEST_TIMEOUT" default:"30s"`
+	AppBaseURL        string        `envconfig:"APP_BASE_URL" default:"http://localhost:8080"`
 
 	LogFormat string `envconfig:"LOG_FORMAT" default:"pretty"`
 
@@ -25,12 +26,122 @@ type Config struct {
 
 	CSRFSecret string `envconfig:"CSRF_SECRET" required:"true"`
 
+	// Password policy enforced when users are created and change their
+	// password. Zero length, classes, rotation or history switch that rule
+	// off. PasswordCommonListFile holds one rejected password per line; empty
+	// uses the built-in list.
+	PasswordMinLength      int    `envconfig:"PASSWORD_MIN_LENGTH" default:"12"`
+	PasswordMinCharClasses int    `envconfig:"PASSWORD_MIN_CHAR_CLASSES" default:"3"`
+	PasswordDisallowCommon bool   `envconfig:"PASSWORD_DISALLOW_COMMON" default:"true"`
+	PasswordCommonListFile string `envconfig:"PASSWORD_COMMON_LIST_FILE"`
+	// PasswordRotationDays forces a change at login once a password is older.
+	PasswordRotationDays int `envconfig:"PASSWORD_ROTATION_DAYS" default:"0"`
+	// PasswordHistory is how many previous passwords cannot be reused.
+	PasswordHistory int `envconfig:"PASSWORD_HISTORY" default:"5"`
+
 	SMTPHost string `envconfig:"SMTP_HOST" default:"127.0.0.1"`
 	SMTPPort int    `envconfig:"SMTP_PORT" default:"1025"`
 	SMTPFrom string `envconfig:"SMTP_FROM" default:"no-reply@odyssey.local"`
 
 	GotenbergURL        string `envconfig:"GOTENBERG_URL" default:"http://127.0.0.1:3000"`
 	BoardPackStorageDir string `envconfig:"BOARD_PACK_STORAGE" default:"./var/boardpacks"`
+
+	// ReportStorageDir holds files produced by async report jobs; ReportTTL is
+	// how long they stay downloadable before the cleanup job removes them.
+	ReportStorageDir string        `envconfig:"REPORT_STORAGE" default:"./var/reports"`
+	ReportTTL        time.Duration `envconfig:"REPORT_TTL" default:"24h"`
+
+	// InventoryTransferApprovalThreshold holds warehouse transfers valued at or
+	// above this amount for approval. Zero posts every transfer immediately.
+	InventoryTransferApprovalThreshold float64 `envconfig:"INVENTORY_TRANSFER_APPROVAL_THRESHOLD" default:"0"`
+	// InventoryGLTolerance is the unexplained difference between the stock
+	// subledger and the inventory GL account still reported as reconciled.
+	InventoryGLTolerance float64 `envconfig:"INVENTORY_GL_TOLERANCE" default:"1"`
+	// InventoryStockCountGLBatch posts a stock count's adjustments as one
+	// journal instead of one journal per product.
+	InventoryStockCountGLBatch bool `envconfig:"INVENTORY_STOCK_COUNT_GL_BATCH" default:"true"`
+	// InventoryReplenishmentLeadTimeDays is the supplier lead time used by
+	// replenishment rules without their own; InventoryReplenishmentDemandDays
+	// is the outbound history averaged into daily demand.
+	InventoryReplenishmentLeadTimeDays int `envconfig:"INVENTORY_REPLENISHMENT_LEAD_TIME_DAYS" default:"7"`
+	InventoryReplenishmentDemandDays   int `envconfig:"INVENTORY_REPLENISHMENT_DEMAND_DAYS" default:"90"`
+
+	// ProcurementGRNCostPolicy values received goods at the GRN cost
+	// ("actual") or at the PO price with the difference posted to purchase
+	// price variance ("po_price").
+	ProcurementGRNCostPolicy string `envconfig:"PROCUREMENT_GRN_COST_POLICY" default:"actual"`
+
+	// DeliveryDailyCapacity is how many delivery orders a day can take before
+	// the delivery calendar flags it as overbooked. Zero disables the flag.
+	DeliveryDailyCapacity int `envconfig:"DELIVERY_DAILY_CAPACITY" default:"0"`
+
+	// SalesMinMarginPercent flags sales orders whose estimated margin is below
+	// this percentage of revenue. The default flags loss-making orders.
+	SalesMinMarginPercent float64 `envconfig:"SALES_MIN_MARGIN_PERCENT" default:"0"`
+	// SalesOrderChangeAutoApproveBelow applies change orders that move a
+	// confirmed order's total by less than this amount without approval. Zero
+	// sends every change for approval.
+	SalesOrderChangeAutoApproveBelow float64 `envconfig:"SALES_ORDER_CHANGE_AUTO_APPROVE_BELOW" default:"0"`
+
+	// TaxIDFormatsFile points at a JSON list of per-country tax ID formats.
+	// Empty uses the built-in defaults (Indonesian NPWP).
+	TaxIDFormatsFile string `envconfig:"TAX_ID_FORMATS_FILE"`
+
+	// QuotationLossReasonsFile points at a JSON list of loss reasons offered
+	// when rejecting a quotation. Empty uses the built-in list.
+	QuotationLossReasonsFile string `envconfig:"QUOTATION_LOSS_REASONS_FILE"`
+
+	// ConsolStatementMappingFile points at a JSON list of rules mapping group
+	// account code prefixes to consolidated statement lines. Empty treats 5xxx
+	// expense accounts as COGS.
+	ConsolStatementMappingFile string `envconfig:"CONSOL_STATEMENT_MAPPING_FILE"`
+
+	// FXBaseCurrency is the functional currency AP postings, AR aging and
+	// consolidation fallbacks convert into.
+	FXBaseCurrency string `envconfig:"FX_BASE_CURRENCY" default:"IDR"`
+
+	// UILocale picks the thousands and decimal separators used to render
+	// numbers in the web UI ("id" or "en"). Amounts take their symbol and
+	// decimals from the document currency, falling back to FXBaseCurrency.
+	UILocale string `envconfig:"UI_LOCALE" default:"id"`
+
+	// FXProviderURL is a JSON rates endpoint polled daily by the worker at
+	// FXFetchSchedule (cron, UTC). Empty disables the fetch; rates are then
+	// maintained through the API only.
+	FXProviderURL     string        `envconfig:"FX_PROVIDER_URL"`
+	FXProviderTimeout time.Duration `envconfig:"FX_PROVIDER_TIMEOUT" default:"10s"`
+	FXFetchSchedule   string        `envconfig:"FX_FETCH_SCHEDULE" default:"0 0 * * *"`
+
+	// DataExportSchedule (cron, UTC) uploads incremental table extracts to an
+	// S3-compatible bucket. Empty disables the export.
+	DataExportSchedule  string        `envconfig:"DATA_EXPORT_SCHEDULE"`
+	DataExportTables    []string      `envconfig:"DATA_EXPORT_TABLES"`
+	DataExportFormat    string        `envconfig:"DATA_EXPORT_FORMAT" default:"csv"`
+	DataExportPrefix    string        `envconfig:"DATA_EXPORT_PREFIX" default:"odyssey"`
+	DataExportAttempts  int           `envconfig:"DATA_EXPORT_UPLOAD_ATTEMPTS" default:"3"`
+	DataExportEndpoint  string        `envconfig:"DATA_EXPORT_S3_ENDPOINT"`
+	DataExportRegion    string        `envconfig:"DATA_EXPORT_S3_REGION" default:"us-east-1"`
+	DataExportBucket    string        `envconfig:"DATA_EXPORT_S3_BUCKET"`
+	DataExportAccessKey string        `envconfig:"DATA_EXPORT_S3_ACCESS_KEY"`
+	DataExportSecretKey string        `envconfig:"DATA_EXPORT_S3_SECRET_KEY"`
+	DataExportTimeout   time.Duration `envconfig:"DATA_EXPORT_S3_TIMEOUT" default:"5m"`
+
+	// ArchiveSchedule (cron, UTC) archives completed sales orders and settled
+	// AR invoices untouched for ArchiveRetentionDays. Empty disables the job.
+	ArchiveSchedule      string `envconfig:"ARCHIVE_SCHEDULE"`
+	ArchiveRetentionDays int    `envconfig:"ARCHIVE_RETENTION_DAYS" default:"730"`
+	ArchiveBatchSize     int    `envconfig:"ARCHIVE_BATCH_SIZE" default:"500"`
+
+	// ApprovalSLA maps approval modules (PO, INVENTORY_TRANSFER) to how long a
+	// submission may wait, e.g. "PO:48h,INVENTORY_TRANSFER:24h". Modules left
+	// out are not tracked.
+	ApprovalSLA map[string]time.Duration `envconfig:"APPROVAL_SLA" default:"PO:48h,INVENTORY_TRANSFER:24h"`
+	// ApprovalEscalationSchedule (cron, UTC) escalates approvals past SLA to
+	// the approvers' managers. Empty disables escalation.
+	ApprovalEscalationSchedule string `envconfig:"APPROVAL_ESCALATION_SCHEDULE" default:"0 * * * *"`
+	// ApprovalFallbackApproverID receives escalations when no approver has a
+	// manager. Zero only flags the document as overdue.
+	ApprovalFallbackApproverID int64 `envconfig:"APPROVAL_FALLBACK_APPROVER_ID" default:"0"`
 }
 
 // LoadConfig reads configuration from environment variables.
@@ -3,12 +3,15 @@ package app
 import (
 	"log/slog"
 	"os"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/requestid"
 )
 
-// NewLogger returns a configured slog.Logger based on configuration.
+// NewLogger returns a configured slog.Logger based on configuration. Lines
+// logged with a request or task context carry its request_id.
 func NewLogger(cfg *Config) *slog.Logger {
 	if cfg != nil && cfg.LogFormat == "json" {
-		return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: true}))
+		return slog.New(requestid.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: true})))
 	}
-	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{AddSource: true}))
+	return slog.New(requestid.NewLogHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{AddSource: true})))
 }
@@ -13,6 +13,8 @@ import (
 
 
 	"github.com/odyssey-erp/odyssey-erp/internal/observability"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/requestid"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
@@ -77,8 +79,8 @@ func MiddlewareStack(cfg MiddlewareConfig) []func(http.Handler) http.Handler {
 			ctx := r.Context()
 			sess, err := cfg.SessionManager.Load(ctx, r)
 			if err != nil {
-				cfg.Logger.Error("failed to load session", slog.Any("error", err))
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				cfg.Logger.ErrorContext(r.Context(), "failed to load session", slog.Any("error", err))
+				httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
 			ctx = shared.ContextWithSession(ctx, sess)
@@ -104,7 +106,7 @@ func MiddlewareStack(cfg MiddlewareConfig) []func(http.Handler) http.Handler {
 			}
 			sess := shared.SessionFromContext(r.Context())
 			if sess == nil {
-				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 				return
 			}
 			token := r.PostFormValue(shared.CSRFFormField)
@@ -112,8 +114,8 @@ func MiddlewareStack(cfg MiddlewareConfig) []func(http.Handler) http.Handler {
 				token = r.Header.Get("X-CSRF-Token")
 			}
 			if err := cfg.CSRFManager.VerifyToken(r.Context(), sess, token); err != nil {
-				cfg.Logger.Warn("csrf validation failed", slog.String("path", r.URL.Path))
-				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				cfg.Logger.WarnContext(r.Context(), "csrf validation failed", slog.String("path", r.URL.Path))
+				httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -127,15 +129,15 @@ func MiddlewareStack(cfg MiddlewareConfig) []func(http.Handler) http.Handler {
 
 	middlewares := []func(http.Handler) http.Handler{
 		middleware.RealIP,
-		middleware.RequestID,
+		requestid.Middleware,
 		sessionMiddleware,
 		middleware.Recoverer,
 		middleware.Timeout(timeout),
 		func(next http.Handler) http.Handler {
 			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				if err := secureMiddleware.Process(w, r); err != nil {
-					cfg.Logger.Warn("secure headers blocked request", slog.Any("error", err))
-					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					cfg.Logger.WarnContext(r.Context(), "secure headers blocked request", slog.Any("error", err))
+					httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 					return
 				}
 				next.ServeHTTP(w, r)
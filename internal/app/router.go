@@ -12,20 +12,29 @@ import (
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting"
 	analytichttp "github.com/odyssey-erp/odyssey-erp/internal/analytics/http"
 	"github.com/odyssey-erp/odyssey-erp/internal/ap"
+	"github.com/odyssey-erp/odyssey-erp/internal/approvals"
 	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+	"github.com/odyssey-erp/odyssey-erp/internal/archive"
 	audithttp "github.com/odyssey-erp/odyssey-erp/internal/audit/http"
 	auth "github.com/odyssey-erp/odyssey-erp/internal/auth"
 	boardpackhttp "github.com/odyssey-erp/odyssey-erp/internal/boardpack/http"
 	closehttp "github.com/odyssey-erp/odyssey-erp/internal/close/http"
 	consolhttp "github.com/odyssey-erp/odyssey-erp/internal/consol/http"
+	"github.com/odyssey-erp/odyssey-erp/internal/currency"
+	"github.com/odyssey-erp/odyssey-erp/internal/dashboard"
 	"github.com/odyssey-erp/odyssey-erp/internal/delivery"
 	eliminationhttp "github.com/odyssey-erp/odyssey-erp/internal/elimination/http"
 	insightshhtp "github.com/odyssey-erp/odyssey-erp/internal/insights/http"
 	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata"
+	"github.com/odyssey-erp/odyssey-erp/internal/netting"
+	"github.com/odyssey-erp/odyssey-erp/internal/notify"
 	"github.com/odyssey-erp/odyssey-erp/internal/observability"
+	"github.com/odyssey-erp/odyssey-erp/internal/openitems"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/procurement"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/reportjob"
 	"github.com/odyssey-erp/odyssey-erp/internal/roles"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
@@ -52,6 +61,9 @@ type RouterParams struct {
 	CloseHandler       *closehttp.Handler
 	EliminationHandler *eliminationhttp.Handler
 	VarianceHandler    *variance.Handler
+	OpenItemsHandler   *openitems.Handler
+	ApprovalsHandler   *approvals.Handler
+	ReportJobHandler   *reportjob.Handler
 	InsightsHandler    *insightshhtp.Handler
 	AuditHandler       *audithttp.Handler
 	InventoryHandler   *inventory.Handler
@@ -59,6 +71,10 @@ type RouterParams struct {
 	SalesHandler       *sales.Handler
 	MasterDataHandler  *masterdata.Handler
 	APHandler          *ap.Handler
+	NettingHandler     *netting.Handler
+	ArchiveHandler     *archive.Handler
+	DashboardHandler   *dashboard.Handler
+	CurrencyHandler    *currency.Handler
 	Pool               *pgxpool.Pool
 	RBACMiddleware     rbac.Middleware
 
@@ -68,6 +84,7 @@ type RouterParams struct {
 	AnalyticsHandler   *analytichttp.Handler
 	ConsolHandler      *consolhttp.Handler
 	PermissionsHandler *rbac.PermissionsHandler
+	NotifyHandler      *notify.Handler
 	Metrics            *observability.Metrics
 }
 
@@ -86,6 +103,8 @@ func NewRouter(params RouterParams) http.Handler {
 	}
 
 	r.Use(chimw.Logger)
+	r.Use(params.RBACMiddleware.CompanyScope())
+	r.Use(params.AuthHandler.RotationGuard)
 
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -107,8 +126,8 @@ func NewRouter(params RouterParams) http.Handler {
 			Flash:     flash,
 		}
 		if err := params.Templates.Render(w, "pages/landing.html", data); err != nil {
-			params.Logger.Error("render landing", slog.Any("error", err))
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			params.Logger.ErrorContext(r.Context(), "render landing", slog.Any("error", err))
+			httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		}
 	})
 
@@ -126,17 +145,21 @@ func NewRouter(params RouterParams) http.Handler {
 		if sess != nil {
 			flash = sess.PopFlash()
 		}
+		home := map[string]any{
+			"AppEnv": params.Config.AppEnv,
+		}
+		if params.DashboardHandler != nil {
+			home["Widgets"] = params.DashboardHandler.Cards(r)
+		}
 		data := view.TemplateData{
 			Title:     "Odyssey ERP",
 			CSRFToken: csrfToken,
 			Flash:     flash,
-			Data: map[string]any{
-				"AppEnv": params.Config.AppEnv,
-			},
+			Data:      home,
 		}
 		if err := params.Templates.Render(w, "pages/home.html", data); err != nil {
-			params.Logger.Error("render home", slog.Any("error", err))
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			params.Logger.ErrorContext(r.Context(), "render home", slog.Any("error", err))
+			httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		}
 	})
 
@@ -156,6 +179,15 @@ func NewRouter(params RouterParams) http.Handler {
 			params.APHandler.MountRoutes(r)
 		})
 	}
+	if params.NettingHandler != nil {
+		params.NettingHandler.MountRoutes(r)
+	}
+	if params.ArchiveHandler != nil {
+		params.ArchiveHandler.MountRoutes(r)
+	}
+	if params.CurrencyHandler != nil {
+		params.CurrencyHandler.MountRoutes(r)
+	}
 	if params.RolesHandler != nil {
 		r.Route("/roles", func(r chi.Router) {
 			params.RolesHandler.MountRoutes(r)
@@ -178,16 +210,33 @@ func NewRouter(params RouterParams) http.Handler {
 	if params.VarianceHandler != nil {
 		params.VarianceHandler.MountRoutes(r)
 	}
+	if params.OpenItemsHandler != nil {
+		params.OpenItemsHandler.MountRoutes(r)
+	}
+	if params.ReportJobHandler != nil {
+		params.ReportJobHandler.MountRoutes(r)
+	}
+	if params.ApprovalsHandler != nil {
+		params.ApprovalsHandler.MountRoutes(r)
+	}
 	r.Route("/inventory", params.InventoryHandler.MountRoutes)
 	r.Route("/procurement", params.ProcurementHandler.MountRoutes)
 	if params.SalesHandler != nil {
-		r.Route("/sales", params.SalesHandler.MountRoutes)
+		r.Route("/sales", func(r chi.Router) {
+			r.Use(params.RBACMiddleware.RequireCompany())
+			params.SalesHandler.MountRoutes(r)
+		})
 	}
 	if params.MasterDataHandler != nil {
 		r.Route("/masterdata", params.MasterDataHandler.MountRoutes)
 	}
 	r.Route("/delivery", func(r chi.Router) {
-		delivery.MountRoutes(r, params.Pool, params.Logger, params.Templates, params.CSRFManager, params.RBACMiddleware)
+		r.Use(params.RBACMiddleware.RequireCompany())
+		var dailyCapacity int
+		if params.Config != nil {
+			dailyCapacity = params.Config.DeliveryDailyCapacity
+		}
+		delivery.MountRoutes(r, params.Pool, params.Logger, params.Templates, params.CSRFManager, params.RBACMiddleware, dailyCapacity)
 	})
 	r.Route("/report", params.ReportHandler.MountRoutes)
 	if params.ConsolHandler != nil {
@@ -206,6 +255,12 @@ func NewRouter(params RouterParams) http.Handler {
 	if params.PermissionsHandler != nil {
 		r.Route("/permissions", params.PermissionsHandler.MountRoutes)
 	}
+	if params.DashboardHandler != nil {
+		params.DashboardHandler.MountRoutes(r)
+	}
+	if params.NotifyHandler != nil {
+		r.Route("/account", params.NotifyHandler.MountRoutes)
+	}
 	if params.Metrics != nil {
 		r.Get("/metrics", params.Metrics.HTMLHandler().ServeHTTP)
 		r.Method(http.MethodGet, "/metrics/prometheus", params.Metrics.Handler())
@@ -220,6 +275,9 @@ func NewRouter(params RouterParams) http.Handler {
 		fileServer := http.StripPrefix("/static/", http.FileServer(http.FS(staticFS)))
 		r.Handle("/static/*", staticCacheHandler(fileServer))
 	}
+	if params.PermissionsHandler != nil {
+		params.PermissionsHandler.SetRoutes(r)
+	}
 
 	return r
 }
@@ -0,0 +1,185 @@
+package approvals
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentType describes a module that records approvals through
+// shared.ApprovalRecorder.
+type DocumentType struct {
+	Module string
+	Label  string
+	// ApprovePermission identifies the approvers whose managers are notified
+	// when the document stays pending past its SLA.
+	ApprovePermission string
+	// Path is the page where the pending documents are reviewed.
+	Path string
+}
+
+// documentTypes lists the modules writing to the approvals log, keyed by the
+// module code they record under.
+var documentTypes = map[string]DocumentType{
+	"PO":                 {Module: "PO", Label: "Purchase Order", ApprovePermission: "procurement.edit", Path: "/procurement/pos"},
+	"INVENTORY_TRANSFER": {Module: "INVENTORY_TRANSFER", Label: "Inventory Transfer", ApprovePermission: "inventory.approve", Path: "/inventory/transfers/approvals"},
+	"SO_CHANGE":          {Module: "SO_CHANGE", Label: "Sales Order Change", ApprovePermission: "sales.order.confirm", Path: "/sales/orders"},
+}
+
+// Policy is the SLA for one document type.
+type Policy struct {
+	DocumentType
+	SLA time.Duration
+}
+
+// ErrInvalidPolicy is returned for an unknown module or a non-positive SLA.
+var ErrInvalidPolicy = errors.New("approvals: invalid SLA policy")
+
+// NewPolicies builds the SLA policies from module codes to durations, as
+// configured by APPROVAL_SLA. Modules without an SLA are not tracked.
+func NewPolicies(slas map[string]time.Duration) (map[string]Policy, error) {
+	policies := make(map[string]Policy, len(slas))
+	for module, sla := range slas {
+		module = strings.ToUpper(strings.TrimSpace(module))
+		docType, ok := documentTypes[module]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown module %q", ErrInvalidPolicy, module)
+		}
+		if sla <= 0 {
+			return nil, fmt.Errorf("%w: SLA for %s must be positive", ErrInvalidPolicy, module)
+		}
+		policies[module] = Policy{DocumentType: docType, SLA: sla}
+	}
+	return policies, nil
+}
+
+// PendingApproval is a document whose latest approval log entry is a submit.
+type PendingApproval struct {
+	Module      string
+	RefID       uuid.UUID
+	SubmittedBy int64
+	Submitter   string
+	Note        string
+	SubmittedAt time.Time
+	// EscalatedAt is set once the escalation job flagged the submission as
+	// overdue.
+	EscalatedAt *time.Time
+}
+
+// OverdueApproval is a pending approval past its SLA.
+type OverdueApproval struct {
+	Module      string     `json:"module"`
+	Label       string     `json:"label"`
+	RefID       uuid.UUID  `json:"ref_id"`
+	Note        string     `json:"note"`
+	SubmittedBy int64      `json:"submitted_by"`
+	Submitter   string     `json:"submitter"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	DueAt       time.Time  `json:"due_at"`
+	AgeHours    float64    `json:"age_hours"`
+	HoursOver   float64    `json:"hours_over"`
+	Escalated   bool       `json:"escalated"`
+	EscalatedAt *time.Time `json:"escalated_at,omitempty"`
+}
+
+// ModuleSummary aggregates the overdue approvals of one document type.
+type ModuleSummary struct {
+	Module       string  `json:"module"`
+	Label        string  `json:"label"`
+	SLAHours     float64 `json:"sla_hours"`
+	Pending      int     `json:"pending"`
+	Overdue      int     `json:"overdue"`
+	OldestHours  float64 `json:"oldest_hours"`
+	AverageHours float64 `json:"average_hours"`
+}
+
+// Report lists approvals pending past SLA, most overdue first.
+type Report struct {
+	AsOf    time.Time         `json:"as_of"`
+	Modules []ModuleSummary   `json:"modules"`
+	Items   []OverdueApproval `json:"items"`
+}
+
+// Escalation records that an overdue submission was escalated.
+type Escalation struct {
+	Module          string
+	RefID           uuid.UUID
+	SubmittedAt     time.Time
+	EscalatedAt     time.Time
+	NotifiedUserIDs []int64
+}
+
+// Recipient is a user receiving an escalation email.
+type Recipient struct {
+	UserID int64
+	Email  string
+	Name   string
+}
+
+// BuildReport splits pending approvals into per-module summaries and the
+// overdue list. Modules without a policy are skipped.
+func BuildReport(pending []PendingApproval, policies map[string]Policy, now time.Time) Report {
+	report := Report{AsOf: now, Modules: []ModuleSummary{}, Items: []OverdueApproval{}}
+	summaries := make(map[string]*ModuleSummary, len(policies))
+	for module, policy := range policies {
+		summaries[module] = &ModuleSummary{Module: module, Label: policy.Label, SLAHours: policy.SLA.Hours()}
+	}
+	for _, p := range pending {
+		policy, ok := policies[p.Module]
+		if !ok {
+			continue
+		}
+		summary := summaries[p.Module]
+		summary.Pending++
+		due := p.SubmittedAt.Add(policy.SLA)
+		if !now.After(due) {
+			continue
+		}
+		age := now.Sub(p.SubmittedAt).Hours()
+		summary.Overdue++
+		summary.AverageHours += age
+		if age > summary.OldestHours {
+			summary.OldestHours = age
+		}
+		report.Items = append(report.Items, OverdueApproval{
+			Module:      p.Module,
+			Label:       policy.Label,
+			RefID:       p.RefID,
+			Note:        p.Note,
+			SubmittedBy: p.SubmittedBy,
+			Submitter:   p.Submitter,
+			SubmittedAt: p.SubmittedAt,
+			DueAt:       due,
+			AgeHours:    roundHours(age),
+			HoursOver:   roundHours(now.Sub(due).Hours()),
+			Escalated:   p.EscalatedAt != nil,
+			EscalatedAt: p.EscalatedAt,
+		})
+	}
+	for _, summary := range summaries {
+		if summary.Overdue > 0 {
+			summary.AverageHours = roundHours(summary.AverageHours / float64(summary.Overdue))
+		}
+		summary.OldestHours = roundHours(summary.OldestHours)
+		report.Modules = append(report.Modules, *summary)
+	}
+	sort.Slice(report.Modules, func(i, j int) bool {
+		if report.Modules[i].Overdue != report.Modules[j].Overdue {
+			return report.Modules[i].Overdue > report.Modules[j].Overdue
+		}
+		return report.Modules[i].Module < report.Modules[j].Module
+	})
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return report.Items[i].HoursOver > report.Items[j].HoursOver
+	})
+	return report
+}
+
+func roundHours(h float64) float64 {
+	return math.Round(h*10) / 10
+}
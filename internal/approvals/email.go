@@ -0,0 +1,44 @@
+package approvals
+
+import (
+	"fmt"
+	"strings"
+)
+
+type escalationEmail struct {
+	Subject string
+	Body    string
+}
+
+// renderEscalationEmail builds the plain-text escalation sent to a manager or
+// the fallback approver.
+func renderEscalationEmail(item OverdueApproval, policy Policy, rcpt Recipient, baseURL string) escalationEmail {
+	name := rcpt.Name
+	if name == "" {
+		name = rcpt.Email
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", name)
+	fmt.Fprintf(&body, "A %s has been waiting for approval longer than its %s SLA.\n\n", policy.Label, formatHours(policy.SLA.Hours()))
+	if item.Note != "" {
+		fmt.Fprintf(&body, "Note:      %s\n", item.Note)
+	}
+	if item.Submitter != "" {
+		fmt.Fprintf(&body, "Submitter: %s\n", item.Submitter)
+	}
+	fmt.Fprintf(&body, "Submitted: %s\n", item.SubmittedAt.Format("02 Jan 2006 15:04 MST"))
+	fmt.Fprintf(&body, "Overdue:   %s\n", formatHours(item.HoursOver))
+	fmt.Fprintf(&body, "Link:      %s%s\n", baseURL, policy.Path)
+	body.WriteString("\nPlease follow up with the approvers or approve it yourself.\n")
+	return escalationEmail{
+		Subject: fmt.Sprintf("[Approval overdue] %s pending %s", policy.Label, formatHours(item.AgeHours)),
+		Body:    body.String(),
+	}
+}
+
+func formatHours(h float64) string {
+	if h >= 48 {
+		return fmt.Sprintf("%.1f days", h/24)
+	}
+	return fmt.Sprintf("%.1f hours", h)
+}
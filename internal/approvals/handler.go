@@ -0,0 +1,140 @@
+package approvals
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/csvimport"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// matrixLimits bounds approval matrix imports; a matrix has a few bands per
+// document type.
+var matrixLimits = csvimport.Limits{MaxBytes: 1 << 20, MaxRows: 1000, PerMinute: 5}
+
+// Handler serves the approvals pending past SLA report and the approval
+// matrix import and export.
+type Handler struct {
+	logger  *slog.Logger
+	service *Service
+	matrix  *MatrixService
+	rbac    rbac.Middleware
+}
+
+// NewHandler constructs the handler.
+func NewHandler(logger *slog.Logger, service *Service, matrix *MatrixService, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, matrix: matrix, rbac: rbac}
+}
+
+// MountRoutes registers routes.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Route("/reports/approvals-overdue", func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermReportView))
+		r.Get("/", h.overdue)
+	})
+	r.Route("/approvals/matrix", func(r chi.Router) {
+		r.With(h.rbac.RequireAny(shared.PermRolesView, shared.PermFinanceGLView)).Get("/", h.exportMatrix)
+		r.With(h.rbac.RequireAll(shared.PermRolesEdit), csvimport.Guard(matrixLimits)).Post("/", h.importMatrix)
+	})
+}
+
+func (h *Handler) overdue(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.Report(r.Context(), r.URL.Query().Get("module"))
+	if err != nil {
+		if errors.Is(err, ErrInvalidPolicy) {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid module", err.Error())
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "approvals overdue report", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, "Report unavailable", shared.UserSafeMessage(err))
+		return
+	}
+	httpx.JSON(w, http.StatusOK, report)
+}
+
+func (h *Handler) exportMatrix(w http.ResponseWriter, r *http.Request) {
+	format := FormatJSON
+	if raw := r.URL.Query().Get("format"); raw != "" {
+		var err error
+		if format, err = ParseFormat(raw); err != nil {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid format", err.Error())
+			return
+		}
+	}
+	matrix, err := h.matrix.Export(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "approval matrix export", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, "Export unavailable", shared.UserSafeMessage(err))
+		return
+	}
+	contentType := "application/json"
+	if format == FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=approval-matrix.%s", format))
+	if err := EncodeMatrix(w, matrix, format); err != nil {
+		h.logger.ErrorContext(r.Context(), "approval matrix encode", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) importMatrix(w http.ResponseWriter, r *http.Request) {
+	format, err := uploadFormat(r)
+	if err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid format", err.Error())
+		return
+	}
+	body, err := csvimport.OpenUpload(r, "file")
+	if err != nil {
+		csvimport.WriteError(w, matrixLimits, err)
+		return
+	}
+	bands, err := DecodeMatrix(body, format, matrixLimits.MaxRows)
+	if err == nil {
+		bands, err = h.matrix.Import(r.Context(), bands, sessionUserID(r))
+	}
+	if err != nil {
+		var problems MatrixErrors
+		switch {
+		case errors.As(err, &problems):
+			httpx.Problem(w, http.StatusUnprocessableEntity, "Invalid approval matrix", strings.Join(problems, "\n"))
+		case errors.Is(err, ErrInvalidMatrix):
+			httpx.Problem(w, http.StatusUnprocessableEntity, "Invalid approval matrix", err.Error())
+		default:
+			h.logger.ErrorContext(r.Context(), "approval matrix import", slog.Any("error", err))
+			csvimport.WriteError(w, matrixLimits, err)
+		}
+		return
+	}
+	httpx.JSON(w, http.StatusOK, map[string]any{"bands": len(bands)})
+}
+
+// uploadFormat takes the format query parameter, or JSON for a raw
+// application/json body, or CSV otherwise.
+func uploadFormat(r *http.Request) (Format, error) {
+	if raw := r.URL.Query().Get("format"); raw != "" {
+		return ParseFormat(raw)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		return FormatJSON, nil
+	}
+	return FormatCSV, nil
+}
+
+func sessionUserID(r *http.Request) int64 {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(sess.User(), 10, 64)
+	return id
+}
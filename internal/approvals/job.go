@@ -0,0 +1,39 @@
+package approvals
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+)
+
+// Job runs the scheduled SLA escalation on the worker.
+type Job struct {
+	service *Service
+	logger  *slog.Logger
+}
+
+// NewJob constructs a Job handler.
+func NewJob(service *Service, logger *slog.Logger) *Job {
+	return &Job{service: service, logger: logger}
+}
+
+// Handle fulfils the asynq.HandlerFunc contract for jobs.TaskApprovalEscalation.
+// Escalations that failed stay unflagged and are retried on the next run.
+func (j *Job) Handle(ctx context.Context, _ *asynq.Task) error {
+	if j == nil || j.service == nil {
+		return fmt.Errorf("approval escalation job not configured")
+	}
+	escalated, err := j.service.Escalate(ctx)
+	if err != nil {
+		if j.logger != nil {
+			j.logger.ErrorContext(ctx, "approval escalation failed", slog.Any("error", err), slog.Int("escalated", escalated))
+		}
+		return err
+	}
+	if j.logger != nil {
+		j.logger.InfoContext(ctx, "approval escalation completed", slog.Int("escalated", escalated))
+	}
+	return nil
+}
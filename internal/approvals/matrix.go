@@ -0,0 +1,262 @@
+package approvals
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/csvimport"
+)
+
+// Band routes submissions of one document type whose amount falls in
+// [MinAmount, MaxAmount) to approvers holding any of Roles. A nil MaxAmount
+// has no upper limit.
+type Band struct {
+	DocumentType string   `json:"document_type"`
+	MinAmount    float64  `json:"min_amount"`
+	MaxAmount    *float64 `json:"max_amount"`
+	Roles        []string `json:"roles"`
+}
+
+// Covers reports whether amount falls in the band.
+func (b Band) Covers(amount float64) bool {
+	return amount >= b.MinAmount && (b.MaxAmount == nil || amount < *b.MaxAmount)
+}
+
+// Matrix is the approval matrix as exported and imported.
+type Matrix struct {
+	Bands     []Band     `json:"bands"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+}
+
+// Resolve returns the roles of the band covering amount for the document
+// type, or nil when no band covers it.
+func (m Matrix) Resolve(documentType string, amount float64) []string {
+	for _, band := range m.Bands {
+		if band.DocumentType == documentType && band.Covers(amount) {
+			return band.Roles
+		}
+	}
+	return nil
+}
+
+// ErrInvalidMatrix wraps every approval matrix validation failure.
+var ErrInvalidMatrix = errors.New("approvals: invalid approval matrix")
+
+// MatrixErrors lists every problem found in an imported matrix.
+type MatrixErrors []string
+
+func (e MatrixErrors) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidMatrix, strings.Join(e, "; "))
+}
+
+// Unwrap lets errors.Is match ErrInvalidMatrix.
+func (e MatrixErrors) Unwrap() error {
+	return ErrInvalidMatrix
+}
+
+// Format is an approval matrix file format.
+type Format string
+
+const (
+	// FormatJSON is a {"bands": [...]} document.
+	FormatJSON Format = "json"
+	// FormatCSV has one band per row; roles are separated by semicolons and an
+	// empty max_amount has no upper limit.
+	FormatCSV Format = "csv"
+)
+
+// ParseFormat accepts "json" or "csv", case-insensitively.
+func ParseFormat(raw string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(raw))) {
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("%w: unknown format %q, use json or csv", ErrInvalidMatrix, raw)
+}
+
+var csvHeader = []string{"document_type", "min_amount", "max_amount", "roles"}
+
+// NormalizeBands trims and upper-cases document types, trims and de-duplicates
+// roles and sorts the bands by document type and lower bound, so exports diff
+// cleanly.
+func NormalizeBands(bands []Band) []Band {
+	out := make([]Band, 0, len(bands))
+	for _, band := range bands {
+		band.DocumentType = strings.ToUpper(strings.TrimSpace(band.DocumentType))
+		roles := make([]string, 0, len(band.Roles))
+		seen := make(map[string]struct{}, len(band.Roles))
+		for _, role := range band.Roles {
+			role = strings.TrimSpace(role)
+			if _, dup := seen[role]; role == "" || dup {
+				continue
+			}
+			seen[role] = struct{}{}
+			roles = append(roles, role)
+		}
+		band.Roles = roles
+		out = append(out, band)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].DocumentType != out[j].DocumentType {
+			return out[i].DocumentType < out[j].DocumentType
+		}
+		return out[i].MinAmount < out[j].MinAmount
+	})
+	return out
+}
+
+// ValidateBands checks normalized bands: known document types, non-negative
+// ranges, at least one existing role per band and no overlapping bands within
+// a document type. Gaps are allowed; amounts in a gap need no matrix role.
+func ValidateBands(bands []Band, roles map[string]struct{}) error {
+	var problems MatrixErrors
+	for i, band := range bands {
+		label := fmt.Sprintf("band %d (%s %s)", i+1, band.DocumentType, band.rangeLabel())
+		if _, ok := documentTypes[band.DocumentType]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown document type %q", label, band.DocumentType))
+		}
+		if band.MinAmount < 0 || math.IsNaN(band.MinAmount) {
+			problems = append(problems, fmt.Sprintf("%s: min_amount must not be negative", label))
+		}
+		if band.MaxAmount != nil && !(*band.MaxAmount > band.MinAmount) {
+			problems = append(problems, fmt.Sprintf("%s: max_amount must be greater than min_amount", label))
+		}
+		if len(band.Roles) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: at least one role is required", label))
+		}
+		for _, role := range band.Roles {
+			if _, ok := roles[role]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown role %q", label, role))
+			}
+		}
+		if i > 0 {
+			prev := bands[i-1]
+			if prev.DocumentType == band.DocumentType && (prev.MaxAmount == nil || *prev.MaxAmount > band.MinAmount) {
+				problems = append(problems, fmt.Sprintf("%s overlaps band %d (%s)", label, i, prev.rangeLabel()))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+func (b Band) rangeLabel() string {
+	upper := "∞"
+	if b.MaxAmount != nil {
+		upper = formatAmount(*b.MaxAmount)
+	}
+	return formatAmount(b.MinAmount) + "–" + upper
+}
+
+// DecodeMatrix reads bands in the given format. CSV input goes through the
+// shared import reader so row limits apply.
+func DecodeMatrix(r io.Reader, format Format, maxRows int) ([]Band, error) {
+	if format == FormatJSON {
+		var m Matrix
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMatrix, err)
+		}
+		return m.Bands, nil
+	}
+	reader := csvimport.NewReader(r, maxRows)
+	header, err := reader.Header()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range csvHeader {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidMatrix, name)
+		}
+	}
+	var bands []Band
+	var problems MatrixErrors
+	for {
+		record, line, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		band := Band{DocumentType: field("document_type"), Roles: strings.Split(field("roles"), ";")}
+		if band.MinAmount, err = parseAmount(field("min_amount")); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: invalid min_amount %q", line, field("min_amount")))
+		}
+		if raw := field("max_amount"); raw != "" {
+			upper, err := parseAmount(raw)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: invalid max_amount %q", line, raw))
+			}
+			band.MaxAmount = &upper
+		}
+		bands = append(bands, band)
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return bands, nil
+}
+
+// EncodeMatrix writes the matrix in the given format.
+func EncodeMatrix(w io.Writer, m Matrix, format Format) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, band := range m.Bands {
+		upper := ""
+		if band.MaxAmount != nil {
+			upper = formatAmount(*band.MaxAmount)
+		}
+		if err := cw.Write([]string{band.DocumentType, formatAmount(band.MinAmount), upper, strings.Join(band.Roles, ";")}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func parseAmount(raw string) (float64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	return v, nil
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,128 @@
+package approvals
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// MatrixStore persists the approval matrix and the roles routed to each
+// submission.
+type MatrixStore interface {
+	LoadMatrix(ctx context.Context) (Matrix, error)
+	ReplaceMatrix(ctx context.Context, bands []Band, actorID int64) error
+	RoleNames(ctx context.Context) (map[string]struct{}, error)
+	SaveRoute(ctx context.Context, module string, ref uuid.UUID, amount float64, roles []string) error
+	RouteRoles(ctx context.Context, module string, ref uuid.UUID) ([]string, bool, error)
+	UserHasRole(ctx context.Context, userID int64, roles []string) (bool, error)
+}
+
+// MatrixService imports, exports and applies the approval matrix. The matrix
+// is read from the database on every submission, so an import takes effect
+// for the next submission without a restart.
+type MatrixService struct {
+	store MatrixStore
+	rates shared.RateResolver
+	now   func() time.Time
+}
+
+// NewMatrixService constructs the service.
+func NewMatrixService(store MatrixStore) *MatrixService {
+	return &MatrixService{store: store, now: time.Now}
+}
+
+// SetRateResolver converts foreign-currency document amounts to the base
+// currency before they are matched against the bands. Without it amounts are
+// matched as entered.
+func (s *MatrixService) SetRateResolver(rates shared.RateResolver) {
+	s.rates = rates
+}
+
+// Export returns the current matrix.
+func (s *MatrixService) Export(ctx context.Context) (Matrix, error) {
+	return s.store.LoadMatrix(ctx)
+}
+
+// Import validates bands and replaces the whole matrix with them. Nothing is
+// changed when any band is invalid.
+func (s *MatrixService) Import(ctx context.Context, bands []Band, actorID int64) ([]Band, error) {
+	bands = NormalizeBands(bands)
+	roles, err := s.store.RoleNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateBands(bands, roles); err != nil {
+		return nil, err
+	}
+	if err := s.store.ReplaceMatrix(ctx, bands, actorID); err != nil {
+		return nil, err
+	}
+	return bands, nil
+}
+
+// RequiredRoles implements shared.ApprovalRouter.
+func (s *MatrixService) RequiredRoles(ctx context.Context, module string, amount float64, currency string) ([]string, error) {
+	roles, _, err := s.resolve(ctx, module, amount, currency)
+	return roles, err
+}
+
+// Route implements shared.ApprovalRouter. A resubmission replaces the roles
+// routed to the previous submission.
+func (s *MatrixService) Route(ctx context.Context, module string, ref uuid.UUID, amount float64, currency string) ([]string, error) {
+	roles, base, err := s.resolve(ctx, module, amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.SaveRoute(ctx, module, ref, base, roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Authorize implements shared.ApprovalRouter. Submissions routed to no role,
+// or made before the matrix existed, only need the approve permission.
+func (s *MatrixService) Authorize(ctx context.Context, module string, ref uuid.UUID, actorID int64) error {
+	roles, ok, err := s.store.RouteRoles(ctx, module, ref)
+	if err != nil || !ok || len(roles) == 0 {
+		return err
+	}
+	allowed, err := s.store.UserHasRole(ctx, actorID, roles)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s needs one of %s", shared.ErrApproverRole, module, strings.Join(roles, ", "))
+	}
+	return nil
+}
+
+// resolve matches the amount, converted to the base currency, against the
+// current matrix.
+func (s *MatrixService) resolve(ctx context.Context, module string, amount float64, currency string) ([]string, float64, error) {
+	base, err := s.toBase(ctx, amount, currency)
+	if err != nil {
+		return nil, 0, err
+	}
+	matrix, err := s.store.LoadMatrix(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return matrix.Resolve(module, base), base, nil
+}
+
+func (s *MatrixService) toBase(ctx context.Context, amount float64, currency string) (float64, error) {
+	if s.rates == nil || currency == "" || strings.EqualFold(currency, s.rates.BaseCurrency()) {
+		return amount, nil
+	}
+	converted, err := s.rates.Convert(ctx, amount, currency, s.rates.BaseCurrency(), s.now())
+	if err != nil {
+		return 0, fmt.Errorf("approvals: convert %s to %s: %w", currency, s.rates.BaseCurrency(), err)
+	}
+	return math.Round(converted*100) / 100, nil
+}
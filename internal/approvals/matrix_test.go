@@ -0,0 +1,193 @@
+package approvals
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type fakeMatrixStore struct {
+	matrix    Matrix
+	roles     map[string]struct{}
+	routes    map[uuid.UUID][]string
+	userRoles map[int64][]string
+	replaced  int
+}
+
+func (f *fakeMatrixStore) LoadMatrix(context.Context) (Matrix, error) {
+	return f.matrix, nil
+}
+
+func (f *fakeMatrixStore) ReplaceMatrix(_ context.Context, bands []Band, _ int64) error {
+	f.matrix = Matrix{Bands: bands}
+	f.replaced++
+	return nil
+}
+
+func (f *fakeMatrixStore) RoleNames(context.Context) (map[string]struct{}, error) {
+	return f.roles, nil
+}
+
+func (f *fakeMatrixStore) SaveRoute(_ context.Context, _ string, ref uuid.UUID, _ float64, roles []string) error {
+	if f.routes == nil {
+		f.routes = make(map[uuid.UUID][]string)
+	}
+	f.routes[ref] = roles
+	return nil
+}
+
+func (f *fakeMatrixStore) RouteRoles(_ context.Context, _ string, ref uuid.UUID) ([]string, bool, error) {
+	roles, ok := f.routes[ref]
+	return roles, ok, nil
+}
+
+func (f *fakeMatrixStore) UserHasRole(_ context.Context, userID int64, roles []string) (bool, error) {
+	for _, held := range f.userRoles[userID] {
+		for _, role := range roles {
+			if held == role {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func amount(v float64) *float64 { return &v }
+
+func knownRoles(names ...string) map[string]struct{} {
+	out := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		out[name] = struct{}{}
+	}
+	return out
+}
+
+func TestValidateBandsRejectsOverlapsAndUnknownRoles(t *testing.T) {
+	bands := NormalizeBands([]Band{
+		{DocumentType: "po", MinAmount: 10000, Roles: []string{"cfo"}},
+		{DocumentType: "PO", MinAmount: 0, MaxAmount: amount(15000), Roles: []string{"manager", " manager"}},
+		{DocumentType: "INVOICE", MinAmount: 0, Roles: []string{"auditor"}},
+	})
+	err := ValidateBands(bands, knownRoles("manager", "cfo"))
+	var problems MatrixErrors
+	if !errors.As(err, &problems) || !errors.Is(err, ErrInvalidMatrix) {
+		t.Fatalf("expected MatrixErrors, got %v", err)
+	}
+	joined := strings.Join(problems, "\n")
+	for _, want := range []string{`unknown document type "INVOICE"`, `unknown role "auditor"`, "overlaps band"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %q in %q", want, joined)
+		}
+	}
+	if got := bands[1].Roles; !reflect.DeepEqual(got, []string{"manager"}) {
+		t.Fatalf("expected de-duplicated roles, got %v", got)
+	}
+}
+
+func TestValidateBandsAllowsGapsAndAdjacentBands(t *testing.T) {
+	bands := NormalizeBands([]Band{
+		{DocumentType: "PO", MinAmount: 1000, MaxAmount: amount(10000), Roles: []string{"manager"}},
+		{DocumentType: "PO", MinAmount: 10000, Roles: []string{"cfo"}},
+		{DocumentType: "INVENTORY_TRANSFER", MinAmount: 5000, Roles: []string{"manager"}},
+	})
+	if err := ValidateBands(bands, knownRoles("manager", "cfo")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	matrix := Matrix{Bands: bands}
+	cases := map[float64][]string{500: nil, 1000: {"manager"}, 9999.99: {"manager"}, 10000: {"cfo"}}
+	for value, want := range cases {
+		if got := matrix.Resolve("PO", value); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Resolve(%v) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestMatrixRoundTripsCSVAndJSON(t *testing.T) {
+	matrix := Matrix{Bands: []Band{
+		{DocumentType: "PO", MinAmount: 0, MaxAmount: amount(2500.5), Roles: []string{"manager"}},
+		{DocumentType: "PO", MinAmount: 2500.5, Roles: []string{"manager", "cfo"}},
+	}}
+	for _, format := range []Format{FormatCSV, FormatJSON} {
+		var buf bytes.Buffer
+		if err := EncodeMatrix(&buf, matrix, format); err != nil {
+			t.Fatalf("%s encode: %v", format, err)
+		}
+		bands, err := DecodeMatrix(&buf, format, 100)
+		if err != nil {
+			t.Fatalf("%s decode: %v", format, err)
+		}
+		if !reflect.DeepEqual(bands, matrix.Bands) {
+			t.Fatalf("%s round trip = %+v", format, bands)
+		}
+	}
+}
+
+func TestDecodeMatrixReportsCSVLines(t *testing.T) {
+	csvData := "document_type,min_amount,max_amount,roles\nPO,abc,,manager\nPO,10,x,cfo\n"
+	_, err := DecodeMatrix(strings.NewReader(csvData), FormatCSV, 100)
+	var problems MatrixErrors
+	if !errors.As(err, &problems) || len(problems) != 2 {
+		t.Fatalf("expected two line errors, got %v", err)
+	}
+	if !strings.HasPrefix(problems[0], "line 2:") || !strings.HasPrefix(problems[1], "line 3:") {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+	if _, err := DecodeMatrix(strings.NewReader("document_type,roles\n"), FormatCSV, 100); !errors.Is(err, ErrInvalidMatrix) {
+		t.Fatalf("expected missing column error, got %v", err)
+	}
+}
+
+func TestMatrixServiceImportIsAllOrNothing(t *testing.T) {
+	store := &fakeMatrixStore{roles: knownRoles("manager")}
+	svc := NewMatrixService(store)
+	_, err := svc.Import(context.Background(), []Band{
+		{DocumentType: "PO", MinAmount: 0, Roles: []string{"manager"}},
+		{DocumentType: "PO", MinAmount: 100, Roles: []string{"ghost"}},
+	}, 1)
+	if !errors.Is(err, ErrInvalidMatrix) || store.replaced != 0 {
+		t.Fatalf("expected rejected import, got %v (replaced %d)", err, store.replaced)
+	}
+	bands, err := svc.Import(context.Background(), []Band{{DocumentType: "po", MinAmount: 0, Roles: []string{"manager"}}}, 1)
+	if err != nil || len(bands) != 1 || store.replaced != 1 {
+		t.Fatalf("expected import, got %v (replaced %d)", err, store.replaced)
+	}
+}
+
+func TestMatrixServiceAuthorizesAgainstRoutedRoles(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeMatrixStore{
+		matrix:    Matrix{Bands: []Band{{DocumentType: "PO", MinAmount: 1000, Roles: []string{"cfo"}}}},
+		userRoles: map[int64][]string{1: {"manager"}, 2: {"cfo"}},
+	}
+	svc := NewMatrixService(store)
+	small, large, legacy := uuid.New(), uuid.New(), uuid.New()
+	if _, err := svc.Route(ctx, "PO", small, 500, ""); err != nil {
+		t.Fatalf("route small: %v", err)
+	}
+	roles, err := svc.Route(ctx, "PO", large, 5000, "")
+	if err != nil || !reflect.DeepEqual(roles, []string{"cfo"}) {
+		t.Fatalf("route large = %v, %v", roles, err)
+	}
+
+	// A later matrix change does not re-route existing submissions.
+	store.matrix = Matrix{}
+
+	if err := svc.Authorize(ctx, "PO", large, 1); !errors.Is(err, shared.ErrApproverRole) {
+		t.Fatalf("expected ErrApproverRole, got %v", err)
+	}
+	for _, ref := range []uuid.UUID{large, small, legacy} {
+		if err := svc.Authorize(ctx, "PO", ref, 2); err != nil {
+			t.Fatalf("authorize %s: %v", ref, err)
+		}
+	}
+	if err := svc.Authorize(ctx, "PO", small, 1); err != nil {
+		t.Fatalf("small PO should need no matrix role: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package approvals
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository reads the approvals log and records escalations in PostgreSQL.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs a Repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// ListPending returns documents of the given modules whose latest approval
+// log entry is a submit, oldest first, with their escalation if any.
+func (r *Repository) ListPending(ctx context.Context, modules []string) ([]PendingApproval, error) {
+	const query = `
+		WITH latest AS (
+			SELECT DISTINCT ON (module, ref_id) module, ref_id, actor_id, action, note, at
+			FROM approvals
+			WHERE module = ANY($1::text[])
+			ORDER BY module, ref_id, at DESC, id DESC
+		)
+		SELECT l.module, l.ref_id, l.actor_id, COALESCE(NULLIF(u.name, ''), u.email, ''), l.note, l.at, e.escalated_at
+		FROM latest l
+		LEFT JOIN users u ON u.id = l.actor_id
+		LEFT JOIN approval_escalations e
+			ON e.module = l.module AND e.ref_id = l.ref_id AND e.submitted_at = l.at
+		WHERE l.action = 'SUBMIT'
+		ORDER BY l.at, l.module, l.ref_id
+	`
+	rows, err := r.pool.Query(ctx, query, modules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []PendingApproval
+	for rows.Next() {
+		var p PendingApproval
+		if err := rows.Scan(&p.Module, &p.RefID, &p.SubmittedBy, &p.Submitter, &p.Note, &p.SubmittedAt, &p.EscalatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// ApproverManagers returns the active managers of active users granted the
+// permission, excluding the given submitter as a manager of themself.
+func (r *Repository) ApproverManagers(ctx context.Context, permission string, submitterID int64) ([]Recipient, error) {
+	const query = `
+		SELECT DISTINCT m.id, m.email, m.name
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		JOIN users m ON m.id = u.manager_id
+		WHERE p.name = $1 AND u.is_active AND m.is_active AND m.id <> $2
+		ORDER BY m.id
+	`
+	rows, err := r.pool.Query(ctx, query, permission, submitterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Recipient
+	for rows.Next() {
+		var rcpt Recipient
+		if err := rows.Scan(&rcpt.UserID, &rcpt.Email, &rcpt.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, rcpt)
+	}
+	return out, rows.Err()
+}
+
+// Recipient returns the user when active.
+func (r *Repository) Recipient(ctx context.Context, userID int64) (Recipient, bool, error) {
+	var rcpt Recipient
+	err := r.pool.QueryRow(ctx, `SELECT id, email, name FROM users WHERE id = $1 AND is_active`, userID).
+		Scan(&rcpt.UserID, &rcpt.Email, &rcpt.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Recipient{}, false, nil
+		}
+		return Recipient{}, false, err
+	}
+	return rcpt, true, nil
+}
+
+// RecordEscalation flags the submission as overdue. A submission is only
+// flagged once.
+func (r *Repository) RecordEscalation(ctx context.Context, e Escalation) error {
+	notified := e.NotifiedUserIDs
+	if notified == nil {
+		notified = []int64{}
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO approval_escalations (module, ref_id, submitted_at, escalated_at, notified_user_ids)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (module, ref_id, submitted_at) DO NOTHING
+	`, e.Module, e.RefID, e.SubmittedAt, e.EscalatedAt, notified)
+	return err
+}
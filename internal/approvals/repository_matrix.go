@@ -0,0 +1,124 @@
+package approvals
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// LoadMatrix returns the bands ordered by document type and lower bound, with
+// who last replaced them.
+func (r *Repository) LoadMatrix(ctx context.Context) (Matrix, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT m.document_type, m.min_amount::float8, m.max_amount::float8, m.roles,
+		       m.updated_at, COALESCE(NULLIF(u.name, ''), u.email, '')
+		FROM approval_matrix m
+		LEFT JOIN users u ON u.id = m.updated_by
+		ORDER BY m.document_type, m.min_amount
+	`)
+	if err != nil {
+		return Matrix{}, err
+	}
+	defer rows.Close()
+	matrix := Matrix{Bands: []Band{}}
+	for rows.Next() {
+		var band Band
+		var updatedAt time.Time
+		if err := rows.Scan(&band.DocumentType, &band.MinAmount, &band.MaxAmount, &band.Roles, &updatedAt, &matrix.UpdatedBy); err != nil {
+			return Matrix{}, err
+		}
+		matrix.UpdatedAt = &updatedAt
+		matrix.Bands = append(matrix.Bands, band)
+	}
+	return matrix, rows.Err()
+}
+
+// ReplaceMatrix swaps the whole matrix for bands in one transaction.
+func (r *Repository) ReplaceMatrix(ctx context.Context, bands []Band, actorID int64) error {
+	var updatedBy *int64
+	if actorID > 0 {
+		updatedBy = &actorID
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `DELETE FROM approval_matrix`); err != nil {
+		return err
+	}
+	for _, band := range bands {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO approval_matrix (document_type, min_amount, max_amount, roles, updated_by)
+			VALUES ($1, $2, $3, $4, $5)
+		`, band.DocumentType, band.MinAmount, band.MaxAmount, band.Roles, updatedBy); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RoleNames returns every role name.
+func (r *Repository) RoleNames(ctx context.Context) (map[string]struct{}, error) {
+	rows, err := r.pool.Query(ctx, `SELECT name FROM roles`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]struct{})
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		out[name] = struct{}{}
+	}
+	return out, rows.Err()
+}
+
+// SaveRoute records the roles routed to a submission, replacing those of an
+// earlier submission of the same document.
+func (r *Repository) SaveRoute(ctx context.Context, module string, ref uuid.UUID, amount float64, roles []string) error {
+	if roles == nil {
+		roles = []string{}
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO approval_routes (module, ref_id, amount, roles, routed_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (module, ref_id) DO UPDATE
+		SET amount = EXCLUDED.amount, roles = EXCLUDED.roles, routed_at = EXCLUDED.routed_at
+	`, module, ref, amount, roles)
+	return err
+}
+
+// RouteRoles returns the roles routed to a submission and whether it was
+// routed at all.
+func (r *Repository) RouteRoles(ctx context.Context, module string, ref uuid.UUID) ([]string, bool, error) {
+	var roles []string
+	err := r.pool.QueryRow(ctx, `SELECT roles FROM approval_routes WHERE module = $1 AND ref_id = $2`, module, ref).Scan(&roles)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return roles, true, nil
+}
+
+// UserHasRole reports whether the active user holds any of the roles.
+func (r *Repository) UserHasRole(ctx context.Context, userID int64, roles []string) (bool, error) {
+	var ok bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM user_roles ur
+			JOIN roles ro ON ro.id = ur.role_id
+			JOIN users u ON u.id = ur.user_id
+			WHERE ur.user_id = $1 AND u.is_active AND ro.name = ANY($2::text[])
+		)
+	`, userID, roles).Scan(&ok)
+	return ok, err
+}
@@ -0,0 +1,165 @@
+package approvals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+// Store reads pending approvals and escalation recipients.
+type Store interface {
+	ListPending(ctx context.Context, modules []string) ([]PendingApproval, error)
+	ApproverManagers(ctx context.Context, permission string, submitterID int64) ([]Recipient, error)
+	Recipient(ctx context.Context, userID int64) (Recipient, bool, error)
+	RecordEscalation(ctx context.Context, e Escalation) error
+}
+
+// EmailQueue enqueues outbound emails for the worker.
+type EmailQueue interface {
+	EnqueueSendEmail(ctx context.Context, payload jobs.SendEmailPayload) (*asynq.TaskInfo, error)
+}
+
+// Config tunes SLA tracking.
+type Config struct {
+	Policies map[string]Policy
+	// FallbackApproverID is notified when none of the approvers has an active
+	// manager. Zero only flags the document.
+	FallbackApproverID int64
+	BaseURL            string
+}
+
+// Service tracks pending approvals against their SLA and escalates overdue
+// ones.
+type Service struct {
+	store   Store
+	queue   EmailQueue
+	config  Config
+	logger  *slog.Logger
+	now     func() time.Time
+	modules []string
+}
+
+// NewService constructs the SLA service. The queue may be nil where only the
+// report is served.
+func NewService(store Store, queue EmailQueue, config Config, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	modules := make([]string, 0, len(config.Policies))
+	for module := range config.Policies {
+		modules = append(modules, module)
+	}
+	return &Service{
+		store:   store,
+		queue:   queue,
+		config:  config,
+		logger:  logger,
+		now:     time.Now,
+		modules: modules,
+	}
+}
+
+// Report lists approvals pending past their SLA, optionally for one module.
+func (s *Service) Report(ctx context.Context, module string) (Report, error) {
+	policies := s.config.Policies
+	if module = strings.ToUpper(strings.TrimSpace(module)); module != "" {
+		policy, ok := policies[module]
+		if !ok {
+			return Report{}, fmt.Errorf("%w: no SLA for %q", ErrInvalidPolicy, module)
+		}
+		policies = map[string]Policy{module: policy}
+	}
+	pending, err := s.pending(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	return BuildReport(pending, policies, s.now().UTC()), nil
+}
+
+// Escalate flags every approval pending past its SLA that was not escalated
+// yet and emails the approvers' managers, or the fallback approver when none
+// of the approvers has a manager. It returns the number of escalations.
+func (s *Service) Escalate(ctx context.Context) (int, error) {
+	if s.queue == nil {
+		return 0, errors.New("approvals: email queue not configured")
+	}
+	pending, err := s.pending(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := s.now().UTC()
+	report := BuildReport(pending, s.config.Policies, now)
+	escalated := 0
+	var errs []error
+	for _, item := range report.Items {
+		if item.Escalated {
+			continue
+		}
+		policy := s.config.Policies[item.Module]
+		recipients, err := s.recipients(ctx, policy, item.SubmittedBy)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolve recipients for %s %s: %w", item.Module, item.RefID, err))
+			continue
+		}
+		if len(recipients) == 0 {
+			s.logger.WarnContext(ctx, "overdue approval has no escalation recipient", slog.String("module", item.Module), slog.String("ref_id", item.RefID.String()))
+		}
+		notified := make([]int64, 0, len(recipients))
+		var sendErr error
+		for _, rcpt := range recipients {
+			msg := renderEscalationEmail(item, policy, rcpt, s.config.BaseURL)
+			if _, err := s.queue.EnqueueSendEmail(ctx, jobs.SendEmailPayload{To: rcpt.Email, Subject: msg.Subject, Body: msg.Body}); err != nil {
+				sendErr = err
+				break
+			}
+			notified = append(notified, rcpt.UserID)
+		}
+		if sendErr != nil {
+			// Leave it unflagged so the next run retries the escalation.
+			errs = append(errs, fmt.Errorf("enqueue escalation for %s %s: %w", item.Module, item.RefID, sendErr))
+			continue
+		}
+		if err := s.store.RecordEscalation(ctx, Escalation{
+			Module:          item.Module,
+			RefID:           item.RefID,
+			SubmittedAt:     item.SubmittedAt,
+			EscalatedAt:     now,
+			NotifiedUserIDs: notified,
+		}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		escalated++
+	}
+	return escalated, errors.Join(errs...)
+}
+
+func (s *Service) pending(ctx context.Context) ([]PendingApproval, error) {
+	if len(s.modules) == 0 {
+		return nil, nil
+	}
+	return s.store.ListPending(ctx, s.modules)
+}
+
+func (s *Service) recipients(ctx context.Context, policy Policy, submitterID int64) ([]Recipient, error) {
+	managers, err := s.store.ApproverManagers(ctx, policy.ApprovePermission, submitterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(managers) > 0 || s.config.FallbackApproverID == 0 {
+		return managers, nil
+	}
+	rcpt, ok, err := s.store.Recipient(ctx, s.config.FallbackApproverID)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return []Recipient{rcpt}, nil
+}
@@ -0,0 +1,171 @@
+package approvals
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+type fakeStore struct {
+	pending   []PendingApproval
+	managers  map[string][]Recipient
+	users     map[int64]Recipient
+	escalated []Escalation
+}
+
+func (f *fakeStore) ListPending(context.Context, []string) ([]PendingApproval, error) {
+	return f.pending, nil
+}
+
+func (f *fakeStore) ApproverManagers(_ context.Context, permission string, _ int64) ([]Recipient, error) {
+	return f.managers[permission], nil
+}
+
+func (f *fakeStore) Recipient(_ context.Context, userID int64) (Recipient, bool, error) {
+	rcpt, ok := f.users[userID]
+	return rcpt, ok, nil
+}
+
+func (f *fakeStore) RecordEscalation(_ context.Context, e Escalation) error {
+	f.escalated = append(f.escalated, e)
+	return nil
+}
+
+type fakeQueue struct {
+	sent []jobs.SendEmailPayload
+	err  error
+}
+
+func (f *fakeQueue) EnqueueSendEmail(_ context.Context, payload jobs.SendEmailPayload) (*asynq.TaskInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.sent = append(f.sent, payload)
+	return &asynq.TaskInfo{}, nil
+}
+
+func testPolicies(t *testing.T) map[string]Policy {
+	t.Helper()
+	policies, err := NewPolicies(map[string]time.Duration{"po": 48 * time.Hour, "INVENTORY_TRANSFER": 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("NewPolicies: %v", err)
+	}
+	return policies
+}
+
+func TestNewPoliciesRejectsUnknownModule(t *testing.T) {
+	if _, err := NewPolicies(map[string]time.Duration{"INVOICE": time.Hour}); !errors.Is(err, ErrInvalidPolicy) {
+		t.Fatalf("expected ErrInvalidPolicy, got %v", err)
+	}
+	if _, err := NewPolicies(map[string]time.Duration{"PO": 0}); !errors.Is(err, ErrInvalidPolicy) {
+		t.Fatalf("expected ErrInvalidPolicy for zero SLA, got %v", err)
+	}
+}
+
+func TestBuildReportListsOnlyPastSLA(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	escalatedAt := now.Add(-time.Hour)
+	pending := []PendingApproval{
+		{Module: "PO", RefID: uuid.New(), SubmittedAt: now.Add(-72 * time.Hour), EscalatedAt: &escalatedAt},
+		{Module: "PO", RefID: uuid.New(), SubmittedAt: now.Add(-12 * time.Hour)},
+		{Module: "INVENTORY_TRANSFER", RefID: uuid.New(), SubmittedAt: now.Add(-30 * time.Hour)},
+		{Module: "UNTRACKED", RefID: uuid.New(), SubmittedAt: now.Add(-500 * time.Hour)},
+	}
+
+	report := BuildReport(pending, testPolicies(t), now)
+
+	if len(report.Items) != 2 {
+		t.Fatalf("expected 2 overdue items, got %+v", report.Items)
+	}
+	first := report.Items[0]
+	if first.Module != "PO" || first.HoursOver != 24 || first.AgeHours != 72 || !first.Escalated {
+		t.Fatalf("unexpected first item %+v", first)
+	}
+	if report.Items[1].Module != "INVENTORY_TRANSFER" || report.Items[1].HoursOver != 6 {
+		t.Fatalf("unexpected second item %+v", report.Items[1])
+	}
+	if len(report.Modules) != 2 {
+		t.Fatalf("expected 2 module summaries, got %+v", report.Modules)
+	}
+	for _, m := range report.Modules {
+		if m.Module == "PO" && (m.Pending != 2 || m.Overdue != 1 || m.OldestHours != 72) {
+			t.Fatalf("unexpected PO summary %+v", m)
+		}
+	}
+}
+
+func TestEscalateNotifiesManagersOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	escalatedAt := now.Add(-time.Hour)
+	store := &fakeStore{
+		pending: []PendingApproval{
+			{Module: "PO", RefID: uuid.New(), SubmittedBy: 3, Submitter: "Buyer", Note: "PO PO-001 submitted", SubmittedAt: now.Add(-50 * time.Hour)},
+			{Module: "PO", RefID: uuid.New(), SubmittedBy: 3, SubmittedAt: now.Add(-80 * time.Hour), EscalatedAt: &escalatedAt},
+		},
+		managers: map[string][]Recipient{"procurement.edit": {{UserID: 9, Email: "head@example.com", Name: "Head of Purchasing"}}},
+	}
+	queue := &fakeQueue{}
+	svc := NewService(store, queue, Config{Policies: testPolicies(t), FallbackApproverID: 1, BaseURL: "https://erp.example.com/"}, nil)
+	svc.now = func() time.Time { return now }
+
+	count, err := svc.Escalate(context.Background())
+	if err != nil {
+		t.Fatalf("Escalate: %v", err)
+	}
+	if count != 1 || len(store.escalated) != 1 {
+		t.Fatalf("expected one escalation, got %d %+v", count, store.escalated)
+	}
+	if got := store.escalated[0].NotifiedUserIDs; len(got) != 1 || got[0] != 9 {
+		t.Fatalf("expected manager notified, got %v", got)
+	}
+	if len(queue.sent) != 1 || queue.sent[0].To != "head@example.com" {
+		t.Fatalf("expected email to manager, got %+v", queue.sent)
+	}
+	for _, want := range []string{"Hello Head of Purchasing,", "PO PO-001 submitted", "https://erp.example.com/procurement/pos"} {
+		if !strings.Contains(queue.sent[0].Body, want) {
+			t.Fatalf("body missing %q:\n%s", want, queue.sent[0].Body)
+		}
+	}
+}
+
+func TestEscalateFallsBackWhenNoManager(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		pending: []PendingApproval{{Module: "INVENTORY_TRANSFER", RefID: uuid.New(), SubmittedAt: now.Add(-25 * time.Hour)}},
+		users:   map[int64]Recipient{1: {UserID: 1, Email: "ops@example.com"}},
+	}
+	queue := &fakeQueue{}
+	svc := NewService(store, queue, Config{Policies: testPolicies(t), FallbackApproverID: 1}, nil)
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.Escalate(context.Background()); err != nil {
+		t.Fatalf("Escalate: %v", err)
+	}
+	if len(queue.sent) != 1 || queue.sent[0].To != "ops@example.com" {
+		t.Fatalf("expected email to fallback approver, got %+v", queue.sent)
+	}
+}
+
+func TestEscalateLeavesUnflaggedWhenQueueFails(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		pending:  []PendingApproval{{Module: "PO", RefID: uuid.New(), SubmittedAt: now.Add(-49 * time.Hour)}},
+		managers: map[string][]Recipient{"procurement.edit": {{UserID: 9, Email: "head@example.com"}}},
+	}
+	svc := NewService(store, &fakeQueue{err: errors.New("redis down")}, Config{Policies: testPolicies(t)}, nil)
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.Escalate(context.Background()); err == nil {
+		t.Fatal("expected error when the email cannot be queued")
+	}
+	if len(store.escalated) != 0 {
+		t.Fatalf("expected no escalation recorded, got %+v", store.escalated)
+	}
+}
@@ -0,0 +1,191 @@
+package ar
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// CreditExposureTotals is one customer's outstanding AR and open order value
+// in one currency, as summed by the database.
+type CreditExposureTotals struct {
+	CustomerID    int64
+	CustomerCode  string
+	CustomerName  string
+	CreditLimit   float64
+	Currency      string
+	OutstandingAR float64
+	OpenOrders    float64
+}
+
+// CustomerCreditExposure is what a customer owes and has on order against its
+// credit limit. Customers with a zero limit have no limit, so no headroom or
+// utilization.
+type CustomerCreditExposure struct {
+	CustomerID    int64
+	CustomerCode  string
+	CustomerName  string
+	CreditLimit   float64
+	OutstandingAR float64
+	OpenOrders    float64
+	Exposure      float64
+	Headroom      float64
+	// Utilization is the exposure as a percentage of the credit limit.
+	Utilization float64
+	OverLimit   bool
+}
+
+// HasLimit reports whether the customer has a credit limit.
+func (c CustomerCreditExposure) HasLimit() bool {
+	return c.CreditLimit > 0
+}
+
+// CreditExposureSort orders the customers of the report.
+type CreditExposureSort string
+
+const (
+	SortByUtilization CreditExposureSort = "utilization"
+	SortByExposure    CreditExposureSort = "exposure"
+	SortByHeadroom    CreditExposureSort = "headroom"
+	SortByCustomer    CreditExposureSort = "customer"
+)
+
+// CreditExposureFilter selects and orders the report. Utilization and
+// exposure sort highest first, headroom lowest first and customer by code,
+// unless Reverse is set.
+type CreditExposureFilter struct {
+	Sort          CreditExposureSort
+	Reverse       bool
+	OverLimitOnly bool
+}
+
+// CreditExposureReport lists customer credit exposure in Currency with totals
+// over the listed customers.
+type CreditExposureReport struct {
+	AsOf      time.Time
+	Currency  string
+	Customers []CustomerCreditExposure
+	Totals    CustomerCreditExposure
+	OverLimit int
+}
+
+// CreditExposureReport combines each customer's posted unpaid AR with the
+// undelivered value of its confirmed orders and compares the sum with its
+// credit limit. Balances are summed per currency in SQL; with a rate
+// resolver they are converted to the base currency at today's rate.
+func (s *Service) CreditExposureReport(ctx context.Context, filter CreditExposureFilter) (CreditExposureReport, error) {
+	totals, err := s.repo.CreditExposureTotals(ctx, shared.CompanyScopeFromContext(ctx))
+	if err != nil {
+		return CreditExposureReport{}, err
+	}
+	report := CreditExposureReport{AsOf: time.Now()}
+	if s.rates != nil {
+		report.Currency = s.rates.BaseCurrency()
+	}
+
+	byCustomer := make(map[int64]*CustomerCreditExposure)
+	var order []int64
+	for _, t := range totals {
+		c := byCustomer[t.CustomerID]
+		if c == nil {
+			c = &CustomerCreditExposure{
+				CustomerID:   t.CustomerID,
+				CustomerCode: t.CustomerCode,
+				CustomerName: t.CustomerName,
+				CreditLimit:  t.CreditLimit,
+			}
+			byCustomer[t.CustomerID] = c
+			order = append(order, t.CustomerID)
+		}
+		ar, orders := t.OutstandingAR, t.OpenOrders
+		if s.rates != nil && t.Currency != "" && !strings.EqualFold(t.Currency, report.Currency) {
+			if ar, err = s.convertExposure(ctx, ar, t.Currency, report.AsOf); err != nil {
+				return CreditExposureReport{}, fmt.Errorf("convert exposure of %s: %w", t.CustomerCode, err)
+			}
+			if orders, err = s.convertExposure(ctx, orders, t.Currency, report.AsOf); err != nil {
+				return CreditExposureReport{}, fmt.Errorf("convert exposure of %s: %w", t.CustomerCode, err)
+			}
+		}
+		c.OutstandingAR += ar
+		c.OpenOrders += orders
+	}
+
+	for _, id := range order {
+		c := byCustomer[id]
+		c.OutstandingAR = roundCents(c.OutstandingAR)
+		c.OpenOrders = roundCents(c.OpenOrders)
+		c.Exposure = roundCents(c.OutstandingAR + c.OpenOrders)
+		if c.HasLimit() {
+			c.Headroom = roundCents(c.CreditLimit - c.Exposure)
+			c.Utilization = math.Round(c.Exposure/c.CreditLimit*1000) / 10
+			c.OverLimit = c.Headroom < 0
+		}
+		if filter.OverLimitOnly && !c.OverLimit {
+			continue
+		}
+		if c.OverLimit {
+			report.OverLimit++
+		}
+		report.Totals.CreditLimit += c.CreditLimit
+		report.Totals.OutstandingAR += c.OutstandingAR
+		report.Totals.OpenOrders += c.OpenOrders
+		report.Totals.Exposure += c.Exposure
+		report.Totals.Headroom += c.Headroom
+		report.Customers = append(report.Customers, *c)
+	}
+	if report.Totals.CreditLimit > 0 {
+		report.Totals.Utilization = math.Round(report.Totals.Exposure/report.Totals.CreditLimit*1000) / 10
+	}
+	sortCreditExposure(report.Customers, filter)
+	return report, nil
+}
+
+func (s *Service) convertExposure(ctx context.Context, amount float64, currency string, on time.Time) (float64, error) {
+	if amount == 0 {
+		return 0, nil
+	}
+	return s.rates.Convert(ctx, amount, currency, s.rates.BaseCurrency(), on)
+}
+
+// sortCreditExposure orders customers by the filter's sort, falling back to
+// the customer code. Customers without a limit come after those with one
+// when sorting by utilization or headroom.
+func sortCreditExposure(customers []CustomerCreditExposure, filter CreditExposureFilter) {
+	less := func(a, b CustomerCreditExposure) (bool, bool) {
+		switch filter.Sort {
+		case SortByCustomer:
+			return false, false
+		case SortByExposure:
+			return a.Exposure > b.Exposure, a.Exposure != b.Exposure
+		case SortByHeadroom:
+			if a.HasLimit() != b.HasLimit() {
+				return a.HasLimit(), true
+			}
+			return a.Headroom < b.Headroom, a.Headroom != b.Headroom
+		default:
+			if a.HasLimit() != b.HasLimit() {
+				return a.HasLimit(), true
+			}
+			return a.Utilization > b.Utilization, a.Utilization != b.Utilization
+		}
+	}
+	sort.SliceStable(customers, func(i, j int) bool {
+		a, b := customers[i], customers[j]
+		if filter.Reverse {
+			a, b = b, a
+		}
+		if before, decided := less(a, b); decided {
+			return before
+		}
+		return a.CustomerCode < b.CustomerCode
+	})
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
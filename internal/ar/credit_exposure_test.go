@@ -0,0 +1,94 @@
+package ar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func (r *memoryARRepo) CreditExposureTotals(context.Context, shared.CompanyScope) ([]CreditExposureTotals, error) {
+	return r.exposure, nil
+}
+
+type usdRates struct{}
+
+func (usdRates) Convert(_ context.Context, amount float64, from, _ string, _ time.Time) (float64, error) {
+	if from == "USD" {
+		return amount * 16000, nil
+	}
+	return amount, nil
+}
+
+func (usdRates) BaseCurrency() string { return "IDR" }
+
+func exposureRepo() *memoryARRepo {
+	repo := newMemoryARRepo()
+	repo.exposure = []CreditExposureTotals{
+		{CustomerID: 1, CustomerCode: "C001", CustomerName: "Alpha", CreditLimit: 1000000, Currency: "IDR", OutstandingAR: 600000, OpenOrders: 100000},
+		{CustomerID: 1, CustomerCode: "C001", CustomerName: "Alpha", CreditLimit: 1000000, Currency: "USD", OutstandingAR: 25},
+		{CustomerID: 2, CustomerCode: "C002", CustomerName: "Beta", CreditLimit: 2000000, Currency: "IDR", OutstandingAR: 500000},
+		{CustomerID: 3, CustomerCode: "C003", CustomerName: "Gamma", Currency: "IDR", OpenOrders: 300000},
+		{CustomerID: 4, CustomerCode: "C004", CustomerName: "Delta", CreditLimit: 500000},
+	}
+	return repo
+}
+
+func TestCreditExposureReportCombinesARAndOpenOrders(t *testing.T) {
+	svc := NewService(exposureRepo())
+	svc.SetRateResolver(usdRates{})
+
+	report, err := svc.CreditExposureReport(context.Background(), CreditExposureFilter{Sort: SortByUtilization})
+	require.NoError(t, err)
+	require.Equal(t, "IDR", report.Currency)
+	require.Len(t, report.Customers, 4)
+
+	alpha := report.Customers[0]
+	require.Equal(t, "C001", alpha.CustomerCode)
+	require.Equal(t, 1000000.0, alpha.OutstandingAR)
+	require.Equal(t, 100000.0, alpha.OpenOrders)
+	require.Equal(t, 1100000.0, alpha.Exposure)
+	require.Equal(t, -100000.0, alpha.Headroom)
+	require.Equal(t, 110.0, alpha.Utilization)
+	require.True(t, alpha.OverLimit)
+
+	require.Equal(t, []string{"C001", "C002", "C004", "C003"}, exposureCodes(report))
+	gamma := report.Customers[3]
+	require.False(t, gamma.HasLimit())
+	require.False(t, gamma.OverLimit)
+	require.Equal(t, 300000.0, gamma.Exposure)
+
+	require.Equal(t, 1, report.OverLimit)
+	require.Equal(t, 1900000.0, report.Totals.Exposure)
+	require.Equal(t, 3500000.0, report.Totals.CreditLimit)
+}
+
+func TestCreditExposureReportSortsAndFilters(t *testing.T) {
+	svc := NewService(exposureRepo())
+	svc.SetRateResolver(usdRates{})
+	ctx := context.Background()
+
+	report, err := svc.CreditExposureReport(ctx, CreditExposureFilter{Sort: SortByHeadroom})
+	require.NoError(t, err)
+	require.Equal(t, []string{"C001", "C004", "C002", "C003"}, exposureCodes(report))
+
+	report, err = svc.CreditExposureReport(ctx, CreditExposureFilter{Sort: SortByExposure, Reverse: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"C004", "C003", "C002", "C001"}, exposureCodes(report))
+
+	report, err = svc.CreditExposureReport(ctx, CreditExposureFilter{Sort: SortByCustomer, OverLimitOnly: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"C001"}, exposureCodes(report))
+	require.Equal(t, 1100000.0, report.Totals.Exposure)
+}
+
+func exposureCodes(report CreditExposureReport) []string {
+	codes := make([]string, len(report.Customers))
+	for i, c := range report.Customers {
+		codes[i] = c.CustomerCode
+	}
+	return codes
+}
@@ -0,0 +1,161 @@
+package ar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrCreditExceedsBalance indicates a credit note larger than the open
+// balance of the invoice it should reduce.
+var ErrCreditExceedsBalance = errors.New("ar: credit note exceeds invoice balance")
+
+// ARCreditNote reduces what a customer owes. ARInvoiceID is zero when the
+// credit is held on the customer's account instead of an invoice.
+type ARCreditNote struct {
+	ID            int64
+	Number        string
+	CustomerID    int64
+	SOID          int64
+	ARInvoiceID   int64
+	SalesReturnID int64
+	Currency      string
+	Subtotal      float64
+	TaxAmount     float64
+	Total         float64
+	Reason        string
+	CreatedBy     int64
+	CreatedAt     time.Time
+}
+
+// CreateARCreditNoteInput for raising a credit note. When ARInvoiceID is
+// zero and SOID is set, the newest posted invoice of that sales order with
+// enough open balance is credited.
+type CreateARCreditNoteInput struct {
+	CustomerID    int64
+	SOID          int64
+	ARInvoiceID   int64
+	SalesReturnID int64
+	Number        string
+	Currency      string
+	Subtotal      float64
+	TaxAmount     float64
+	Total         float64
+	Reason        string
+	CreatedBy     int64
+}
+
+// CreateCreditNote raises a credit note, applying it to an open invoice when
+// one can absorb it, and journals the reversal of receivables and revenue.
+// A sales return gets one credit note: raising it again returns the existing
+// note and retries its journal, which posts at most once.
+func (s *Service) CreateCreditNote(ctx context.Context, input CreateARCreditNoteInput) (*ARCreditNote, error) {
+	if input.SalesReturnID != 0 {
+		existing, err := s.repo.GetCreditNoteBySalesReturn(ctx, input.SalesReturnID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, s.journalCreditNote(ctx, existing)
+		}
+	}
+	if input.CustomerID == 0 {
+		return nil, errors.New("customer ID required")
+	}
+	if input.Total <= 0 {
+		return nil, errors.New("total must be positive")
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if s.currencies != nil {
+		if err := s.currencies.ValidateCurrency(ctx, input.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.ARInvoiceID != 0 {
+		invoice, err := s.repo.GetARInvoice(ctx, input.ARInvoiceID)
+		if err != nil {
+			return nil, err
+		}
+		if invoice == nil {
+			return nil, ErrInvoiceNotFound
+		}
+		if invoice.Status != ARStatusPosted || invoice.CustomerID != input.CustomerID {
+			return nil, ErrInvalidStatus
+		}
+		_, _, balance, err := s.repo.GetInvoiceBalance(ctx, invoice.ID)
+		if err != nil {
+			return nil, err
+		}
+		if input.Total > balance+0.005 {
+			return nil, ErrCreditExceedsBalance
+		}
+	} else if input.SOID != 0 {
+		id, err := s.creditableInvoice(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		input.ARInvoiceID = id
+	}
+
+	if input.Number == "" {
+		num, err := s.repo.GenerateCreditNoteNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		input.Number = num
+	}
+	note, err := s.repo.CreateARCreditNote(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return note, s.journalCreditNote(ctx, note)
+}
+
+// journalCreditNote posts the credit note's ledger entry. The note is
+// returned alongside the error so callers can retry the posting.
+func (s *Service) journalCreditNote(ctx context.Context, note *ARCreditNote) error {
+	if s.accounting == nil {
+		return nil
+	}
+	if err := s.accounting.CreateARCreditNoteJournal(ctx, note); err != nil {
+		return fmt.Errorf("journal AR credit note %s: %w", note.Number, err)
+	}
+	return nil
+}
+
+// creditableInvoice finds the newest posted invoice of the sales order whose
+// open balance covers the credit. Zero means none does.
+func (s *Service) creditableInvoice(ctx context.Context, input CreateARCreditNoteInput) (int64, error) {
+	invoices, err := s.repo.ListARInvoices(ctx, ListARInvoicesRequest{
+		Status:     ARStatusPosted,
+		CustomerID: input.CustomerID,
+		Limit:      1000,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var best *ARInvoice
+	for i := range invoices {
+		inv := &invoices[i]
+		if inv.SOID != input.SOID {
+			continue
+		}
+		_, _, balance, err := s.repo.GetInvoiceBalance(ctx, inv.ID)
+		if err != nil {
+			return 0, err
+		}
+		if input.Total > balance+0.005 {
+			continue
+		}
+		if best == nil || inv.ID > best.ID {
+			best = inv
+		}
+	}
+	if best == nil {
+		return 0, nil
+	}
+	return best.ID, nil
+}
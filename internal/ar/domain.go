@@ -19,6 +19,7 @@ type ARInvoice struct {
 	ID              int64
 	Number          string
 	CustomerID      int64
+	CustomerName    string
 	SOID            int64
 	DeliveryOrderID int64
 	Currency        string
@@ -57,11 +58,10 @@ type ARInvoiceLine struct {
 // ARInvoiceWithDetails includes invoice with lines and customer info.
 type ARInvoiceWithDetails struct {
 	ARInvoice
-	CustomerName string
-	Lines        []ARInvoiceLine
-	Payments     []ARPaymentSummary
-	PaidAmount   float64
-	Balance      float64
+	Lines      []ARInvoiceLine
+	Payments   []ARPaymentSummary
+	PaidAmount float64
+	Balance    float64
 }
 
 // ARPayment model.
@@ -119,6 +119,13 @@ type ARAgingDetail struct {
 	Total        float64
 }
 
+// ARAgingReport is the aging summary with its customer breakdown.
+type ARAgingReport struct {
+	AsOf      time.Time
+	Summary   ARAgingBucket
+	Customers []ARAgingDetail
+}
+
 // --- Input DTOs ---
 
 // CreateARInvoiceInput for creating AR invoices.
@@ -126,6 +133,7 @@ type CreateARInvoiceInput struct {
 	CustomerID      int64
 	SOID            int64
 	DeliveryOrderID int64
+	CompanyID       int64 // selects the due date policy, the customer's company when zero; not stored
 	Number          string
 	Currency        string
 	Subtotal        float64
@@ -188,8 +196,12 @@ type PaymentAllocationInput struct {
 type ListARInvoicesRequest struct {
 	Status     ARInvoiceStatus
 	CustomerID int64
-	FromDate   time.Time
-	ToDate     time.Time
-	Limit      int
-	Offset     int
+	// CompanyID limits the list to customers of one company when set.
+	CompanyID int64
+	FromDate  time.Time
+	ToDate    time.Time
+	Limit     int
+	Offset    int
+	// IncludeArchived also lists invoices taken out of the list by archival.
+	IncludeArchived bool
 }
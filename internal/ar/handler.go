@@ -1,13 +1,17 @@
 package ar
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -40,6 +44,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Get("/payments", h.listPayments)
 		r.Get("/payments/new", h.showCreatePaymentForm)
 		r.Get("/aging", h.showARAgingReport)
+		r.Get("/credit-exposure", h.showCreditExposure)
 		r.Get("/customer-statement", h.showCustomerStatement)
 	})
 
@@ -56,6 +61,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAll(shared.PermFinanceAREdit))
 		r.Post("/invoices/{id}/post", h.postInvoice)
 		r.Post("/invoices/{id}/void", h.voidInvoice)
+		r.Post("/invoices/{id}/email", h.emailInvoice)
 	})
 }
 
@@ -71,12 +77,13 @@ func (h *Handler) listInvoices(w http.ResponseWriter, r *http.Request) {
 	}
 
 	invoices, err := h.service.ListARInvoices(r.Context(), ListARInvoicesRequest{
-		Status:     status,
-		CustomerID: customerID,
-		Limit:      100,
+		Status:          status,
+		CustomerID:      customerID,
+		Limit:           100,
+		IncludeArchived: r.URL.Query().Get("include_archived") == "1",
 	})
 	if err != nil {
-		h.logger.Error("list AR invoices", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "list AR invoices", slog.Any("error", err))
 		h.render(w, r, "pages/ar/ar_invoice_form.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusInternalServerError)
@@ -95,21 +102,27 @@ func (h *Handler) showInvoiceDetail(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid invoice ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid invoice ID", http.StatusBadRequest)
 		return
 	}
 
 	invoice, err := h.service.GetARInvoiceWithDetails(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get AR invoice", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.ErrorContext(r.Context(), "get AR invoice", slog.Any("error", err), slog.Int64("id", id))
 		h.render(w, r, "pages/ar/ar_invoice_form.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusNotFound)
 		return
 	}
 
-	h.render(w, r, "pages/ar/ar_invoice_form.html", map[string]any{
+	emails, err := h.service.ListInvoiceEmails(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list AR invoice emails", slog.Any("error", err), slog.Int64("id", id))
+	}
+
+	h.render(w, r, "pages/ar/invoice_detail.html", map[string]any{
 		"Invoice": invoice,
+		"Emails":  emails,
 	}, http.StatusOK)
 }
 
@@ -123,7 +136,7 @@ func (h *Handler) showCreateInvoiceForm(w http.ResponseWriter, r *http.Request)
 // createARInvoice handles invoice creation.
 func (h *Handler) createARInvoice(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
@@ -144,7 +157,7 @@ func (h *Handler) createARInvoice(w http.ResponseWriter, r *http.Request) {
 		CreatedBy:  userID,
 	})
 	if err != nil {
-		h.logger.Error("create AR invoice", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "create AR invoice", slog.Any("error", err))
 		h.render(w, r, "pages/ar/ar_invoice_form.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusBadRequest)
@@ -159,12 +172,12 @@ func (h *Handler) createInvoiceFromDelivery(w http.ResponseWriter, r *http.Reque
 	doIDStr := chi.URLParam(r, "doID")
 	doID, err := strconv.ParseInt(doIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid delivery order ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid delivery order ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
@@ -182,7 +195,7 @@ func (h *Handler) createInvoiceFromDelivery(w http.ResponseWriter, r *http.Reque
 		CreatedBy:       userID,
 	})
 	if err != nil {
-		h.logger.Error("create invoice from delivery", slog.Any("error", err), slog.Int64("do_id", doID))
+		h.logger.ErrorContext(r.Context(), "create invoice from delivery", slog.Any("error", err), slog.Int64("do_id", doID))
 		h.redirectWithFlash(w, r, "/delivery/orders/"+doIDStr, "error", shared.UserSafeMessage(err))
 		return
 	}
@@ -195,7 +208,7 @@ func (h *Handler) postInvoice(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid invoice ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid invoice ID", http.StatusBadRequest)
 		return
 	}
 
@@ -206,7 +219,7 @@ func (h *Handler) postInvoice(w http.ResponseWriter, r *http.Request) {
 		InvoiceID: id,
 		PostedBy:  userID,
 	}); err != nil {
-		h.logger.Error("post AR invoice", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.ErrorContext(r.Context(), "post AR invoice", slog.Any("error", err), slog.Int64("id", id))
 		h.redirectWithFlash(w, r, "/finance/ar/invoices/"+idStr, "error", shared.UserSafeMessage(err))
 		return
 	}
@@ -214,17 +227,47 @@ func (h *Handler) postInvoice(w http.ResponseWriter, r *http.Request) {
 	h.redirectWithFlash(w, r, "/finance/ar/invoices/"+idStr, "success", "Invoice posted successfully")
 }
 
+// emailInvoice queues the invoice PDF for emailing to the customer.
+func (h *Handler) emailInvoice(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid invoice ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	email, err := h.service.SendInvoiceEmail(r.Context(), SendInvoiceEmailInput{
+		InvoiceID:        id,
+		Cc:               r.PostFormValue("cc"),
+		Bcc:              r.PostFormValue("bcc"),
+		IncludeStatement: r.PostFormValue("include_statement") != "",
+		RequestedBy:      getUserID(shared.SessionFromContext(r.Context())),
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "email AR invoice", slog.Any("error", err), slog.Int64("id", id))
+		h.redirectWithFlash(w, r, "/finance/ar/invoices/"+idStr, "error", shared.UserSafeMessage(err))
+		return
+	}
+
+	h.redirectWithFlash(w, r, "/finance/ar/invoices/"+idStr, "success", "Invoice queued for emailing to "+email.Recipient)
+}
+
 // voidInvoice voids an invoice.
 func (h *Handler) voidInvoice(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid invoice ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid invoice ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
@@ -237,19 +280,35 @@ func (h *Handler) voidInvoice(w http.ResponseWriter, r *http.Request) {
 		VoidedBy:   userID,
 		VoidReason: reason,
 	}); err != nil {
-		h.logger.Error("void AR invoice", slog.Any("error", err), slog.Int64("id", id))
-		h.redirectWithFlash(w, r, "/finance/ar/invoices/"+idStr, "error", shared.UserSafeMessage(err))
+		h.logger.ErrorContext(r.Context(), "void AR invoice", slog.Any("error", err), slog.Int64("id", id))
+		h.redirectWithFlash(w, r, "/finance/ar/invoices/"+idStr, "error", voidErrorMessage(err))
 		return
 	}
 
 	h.redirectWithFlash(w, r, "/finance/ar/invoices/"+idStr, "success", "Invoice voided")
 }
 
+// voidErrorMessage explains why a void was refused and hides everything else
+// behind the generic user-safe message.
+func voidErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrVoidReasonRequired):
+		return "Enter a reason for voiding this invoice."
+	case errors.Is(err, ErrInvalidStatus):
+		return "Only draft or posted invoices can be voided."
+	case errors.Is(err, accounting.ErrPeriodLocked):
+		return "The invoice's posting period is closed, so it can no longer be voided."
+	case errors.Is(err, accounting.ErrInvalidPeriod):
+		return "No accounting period covers the invoice's posting date."
+	}
+	return shared.UserSafeMessage(err)
+}
+
 // listPayments shows payment list.
 func (h *Handler) listPayments(w http.ResponseWriter, r *http.Request) {
 	payments, err := h.service.GetARPayments(r.Context())
 	if err != nil {
-		h.logger.Error("list AR payments", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "list AR payments", slog.Any("error", err))
 		h.render(w, r, "pages/ar/ar_payment_form.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusInternalServerError)
@@ -278,7 +337,7 @@ func (h *Handler) showCreatePaymentForm(w http.ResponseWriter, r *http.Request)
 // createARPayment handles payment creation.
 func (h *Handler) createARPayment(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
@@ -303,7 +362,7 @@ func (h *Handler) createARPayment(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 	if err != nil {
-		h.logger.Error("create AR payment", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "create AR payment", slog.Any("error", err))
 		h.render(w, r, "pages/ar/ar_payment_form.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusBadRequest)
@@ -317,7 +376,7 @@ func (h *Handler) createARPayment(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) showARAgingReport(w http.ResponseWriter, r *http.Request) {
 	aging, err := h.service.CalculateARAging(r.Context(), time.Now())
 	if err != nil {
-		h.logger.Error("calculate AR aging", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "calculate AR aging", slog.Any("error", err))
 		h.render(w, r, "pages/ar/ar_aging_report.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusInternalServerError)
@@ -332,11 +391,60 @@ func (h *Handler) showARAgingReport(w http.ResponseWriter, r *http.Request) {
 	}, http.StatusOK)
 }
 
+// showCreditExposure shows every customer's AR and open orders against its
+// credit limit.
+func (h *Handler) showCreditExposure(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := CreditExposureFilter{
+		Sort:          CreditExposureSort(q.Get("sort")),
+		Reverse:       q.Get("reverse") == "1",
+		OverLimitOnly: q.Get("over_limit") == "1",
+	}
+	switch filter.Sort {
+	case SortByUtilization, SortByExposure, SortByHeadroom, SortByCustomer:
+	default:
+		filter.Sort = SortByUtilization
+	}
+
+	report, err := h.service.CreditExposureReport(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "credit exposure report", slog.Any("error", err))
+		h.render(w, r, "pages/ar/credit_exposure.html", map[string]any{
+			"Filter": filter,
+			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
+		}, http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/ar/credit_exposure.html", map[string]any{
+		"Filter":    filter,
+		"Report":    report,
+		"SortLinks": creditExposureSortLinks(filter),
+	}, http.StatusOK)
+}
+
+// creditExposureSortLinks builds the column header links; following the
+// link of the current sort reverses it.
+func creditExposureSortLinks(filter CreditExposureFilter) map[string]string {
+	links := make(map[string]string, 4)
+	for _, sort := range []CreditExposureSort{SortByCustomer, SortByExposure, SortByHeadroom, SortByUtilization} {
+		q := url.Values{"sort": {string(sort)}}
+		if sort == filter.Sort && !filter.Reverse {
+			q.Set("reverse", "1")
+		}
+		if filter.OverLimitOnly {
+			q.Set("over_limit", "1")
+		}
+		links[string(sort)] = "/finance/ar/credit-exposure?" + q.Encode()
+	}
+	return links
+}
+
 // showCustomerStatement shows customer statement.
 func (h *Handler) showCustomerStatement(w http.ResponseWriter, r *http.Request) {
-	invoices, err := h.service.ListARInvoices(r.Context(), ListARInvoicesRequest{Limit: 1000})
+	// A statement is the customer's full history, archived invoices included.
+	invoices, err := h.service.ListARInvoices(r.Context(), ListARInvoicesRequest{Limit: 1000, IncludeArchived: true})
 	if err != nil {
-		h.logger.Error("get customer statement", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "get customer statement", slog.Any("error", err))
 		h.render(w, r, "pages/ar/customer_statement.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 		}, http.StatusInternalServerError)
@@ -363,7 +471,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
 	}
 }
 
@@ -0,0 +1,318 @@
+package ar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+var (
+	// ErrInvoiceNotSendable rejects emailing a draft or void invoice.
+	ErrInvoiceNotSendable = fmt.Errorf("%w: only posted or paid invoices can be emailed", shared.ErrValidation)
+	// ErrNoCustomerEmail indicates the customer has no email address on file.
+	ErrNoCustomerEmail = fmt.Errorf("%w: customer has no email address", shared.ErrValidation)
+	// ErrInvoiceEmailNotFound indicates an unknown invoice email log entry.
+	ErrInvoiceEmailNotFound = errors.New("ar: invoice email not found")
+	// ErrInvoiceEmailDisabled indicates no queue or mailer is configured.
+	ErrInvoiceEmailDisabled = errors.New("ar: invoice email is not configured")
+)
+
+// InvoiceEmailStatus tracks the delivery of an invoice email.
+type InvoiceEmailStatus string
+
+const (
+	InvoiceEmailQueued InvoiceEmailStatus = "QUEUED"
+	InvoiceEmailSent   InvoiceEmailStatus = "SENT"
+	InvoiceEmailFailed InvoiceEmailStatus = "FAILED"
+)
+
+// InvoiceEmail logs one request to email an invoice to its customer.
+type InvoiceEmail struct {
+	ID               int64
+	ARInvoiceID      int64
+	Recipient        string
+	Cc               []string
+	Bcc              []string
+	IncludeStatement bool
+	Status           InvoiceEmailStatus
+	Attempts         int
+	LastError        string
+	RequestedBy      int64
+	RequestedAt      time.Time
+	SentAt           *time.Time
+}
+
+// SendInvoiceEmailInput requests an invoice email. Cc and Bcc may each hold
+// several addresses separated by commas or semicolons.
+type SendInvoiceEmailInput struct {
+	InvoiceID        int64
+	Cc               string
+	Bcc              string
+	IncludeStatement bool
+	RequestedBy      int64
+}
+
+// CustomerStatement lists a customer's open posted invoices.
+type CustomerStatement struct {
+	CustomerID   int64
+	CustomerName string
+	AsOf         time.Time
+	Lines        []StatementLine
+	// Balances totals the open amount per currency.
+	Balances map[string]float64
+}
+
+// StatementLine is one open invoice on a statement of account.
+type StatementLine struct {
+	Number   string
+	PostedAt *time.Time
+	DueAt    time.Time
+	Currency string
+	Total    float64
+	Paid     float64
+	Balance  float64
+}
+
+// InvoiceEmailQueue hands invoice emails to the worker.
+type InvoiceEmailQueue interface {
+	EnqueueARInvoiceEmail(ctx context.Context, emailID int64) (*asynq.TaskInfo, error)
+}
+
+// EmailSender delivers a composed email.
+type EmailSender interface {
+	Send(ctx context.Context, payload jobs.SendEmailPayload) error
+}
+
+// InvoiceDocuments renders the PDFs attached to invoice emails.
+type InvoiceDocuments interface {
+	InvoicePDF(ctx context.Context, invoice ARInvoiceWithDetails) ([]byte, error)
+	StatementPDF(ctx context.Context, statement CustomerStatement) ([]byte, error)
+}
+
+// SetInvoiceEmailQueue enables emailing invoices from the web app.
+func (s *Service) SetInvoiceEmailQueue(queue InvoiceEmailQueue) {
+	s.emailQueue = queue
+}
+
+// SetInvoiceDelivery lets the worker render and send queued invoice emails.
+func (s *Service) SetInvoiceDelivery(documents InvoiceDocuments, sender EmailSender) {
+	s.documents = documents
+	s.sender = sender
+}
+
+// SendInvoiceEmail logs an email of the invoice to the customer's address and
+// queues it for the worker. Draft and void invoices cannot be sent.
+func (s *Service) SendInvoiceEmail(ctx context.Context, input SendInvoiceEmailInput) (*InvoiceEmail, error) {
+	if s.emailQueue == nil {
+		return nil, ErrInvoiceEmailDisabled
+	}
+	invoice, err := s.repo.GetARInvoice(ctx, input.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	if !emailable(invoice.Status) {
+		return nil, ErrInvoiceNotSendable
+	}
+	_, recipient, err := s.repo.CustomerContact(ctx, invoice.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	recipient = strings.TrimSpace(recipient)
+	if recipient == "" {
+		return nil, ErrNoCustomerEmail
+	}
+	cc, err := parseAddresses(input.Cc)
+	if err != nil {
+		return nil, err
+	}
+	bcc, err := parseAddresses(input.Bcc)
+	if err != nil {
+		return nil, err
+	}
+	email := InvoiceEmail{
+		ARInvoiceID:      invoice.ID,
+		Recipient:        recipient,
+		Cc:               cc,
+		Bcc:              bcc,
+		IncludeStatement: input.IncludeStatement,
+		Status:           InvoiceEmailQueued,
+		RequestedBy:      input.RequestedBy,
+	}
+	email.ID, err = s.repo.CreateInvoiceEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.emailQueue.EnqueueARInvoiceEmail(ctx, email.ID); err != nil {
+		_ = s.repo.RecordInvoiceEmailAttempt(ctx, email.ID, InvoiceEmailFailed, err.Error(), time.Now())
+		return nil, fmt.Errorf("queue invoice email: %w", err)
+	}
+	return &email, nil
+}
+
+// ListInvoiceEmails returns the email log of an invoice, newest first.
+func (s *Service) ListInvoiceEmails(ctx context.Context, invoiceID int64) ([]InvoiceEmail, error) {
+	return s.repo.ListInvoiceEmails(ctx, invoiceID)
+}
+
+// DeliverInvoiceEmail renders and sends a queued invoice email, recording the
+// attempt on its log entry. A failed attempt stays queued for the next retry
+// unless lastAttempt is set. An invoice voided since it was queued is not
+// sent.
+func (s *Service) DeliverInvoiceEmail(ctx context.Context, emailID int64, lastAttempt bool) error {
+	if s.documents == nil || s.sender == nil {
+		return ErrInvoiceEmailDisabled
+	}
+	email, err := s.repo.GetInvoiceEmail(ctx, emailID)
+	if err != nil {
+		return err
+	}
+	if email == nil {
+		return ErrInvoiceEmailNotFound
+	}
+	if email.Status == InvoiceEmailSent {
+		return nil
+	}
+	payload, err := s.composeInvoiceEmail(ctx, *email)
+	if err == nil {
+		err = s.sender.Send(ctx, payload)
+	}
+	if err != nil {
+		status := InvoiceEmailQueued
+		if lastAttempt || errors.Is(err, shared.ErrValidation) {
+			status = InvoiceEmailFailed
+		}
+		if recErr := s.repo.RecordInvoiceEmailAttempt(ctx, email.ID, status, err.Error(), time.Now()); recErr != nil {
+			return errors.Join(err, recErr)
+		}
+		return err
+	}
+	return s.repo.RecordInvoiceEmailAttempt(ctx, email.ID, InvoiceEmailSent, "", time.Now())
+}
+
+func (s *Service) composeInvoiceEmail(ctx context.Context, email InvoiceEmail) (jobs.SendEmailPayload, error) {
+	invoice, err := s.repo.GetARInvoiceWithDetails(ctx, email.ARInvoiceID)
+	if err != nil {
+		return jobs.SendEmailPayload{}, err
+	}
+	if invoice == nil {
+		return jobs.SendEmailPayload{}, ErrInvoiceNotFound
+	}
+	if !emailable(invoice.Status) {
+		return jobs.SendEmailPayload{}, ErrInvoiceNotSendable
+	}
+	pdf, err := s.documents.InvoicePDF(ctx, *invoice)
+	if err != nil {
+		return jobs.SendEmailPayload{}, fmt.Errorf("render invoice pdf: %w", err)
+	}
+	payload := jobs.SendEmailPayload{
+		To:      email.Recipient,
+		Cc:      email.Cc,
+		Bcc:     email.Bcc,
+		Subject: fmt.Sprintf("Invoice %s", invoice.Number),
+		Attachments: []jobs.EmailAttachment{
+			{Filename: fmt.Sprintf("invoice-%s.pdf", invoice.Number), ContentType: "application/pdf", Content: pdf},
+		},
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Dear %s,\n\n", invoice.CustomerName)
+	fmt.Fprintf(&body, "Please find attached invoice %s for %s %.2f, due on %s.\n",
+		invoice.Number, invoice.Currency, invoice.Total, invoice.DueAt.Format("02 Jan 2006"))
+	if email.IncludeStatement {
+		statement, err := s.CustomerStatement(ctx, invoice.CustomerID, time.Now())
+		if err != nil {
+			return jobs.SendEmailPayload{}, err
+		}
+		statementPDF, err := s.documents.StatementPDF(ctx, statement)
+		if err != nil {
+			return jobs.SendEmailPayload{}, fmt.Errorf("render statement pdf: %w", err)
+		}
+		payload.Attachments = append(payload.Attachments, jobs.EmailAttachment{
+			Filename:    fmt.Sprintf("statement-%s.pdf", statement.AsOf.Format("2006-01-02")),
+			ContentType: "application/pdf",
+			Content:     statementPDF,
+		})
+		body.WriteString("Your statement of account is attached as well.\n")
+	}
+	body.WriteString("\nThank you for your business.\n")
+	payload.Body = body.String()
+	return payload, nil
+}
+
+// CustomerStatement lists the customer's posted invoices that are still open
+// as of asOf, oldest due date first.
+func (s *Service) CustomerStatement(ctx context.Context, customerID int64, asOf time.Time) (CustomerStatement, error) {
+	name, _, err := s.repo.CustomerContact(ctx, customerID)
+	if err != nil {
+		return CustomerStatement{}, err
+	}
+	invoices, err := s.repo.ListARInvoices(ctx, ListARInvoicesRequest{
+		Status:     ARStatusPosted,
+		CustomerID: customerID,
+		Limit:      1000,
+	})
+	if err != nil {
+		return CustomerStatement{}, err
+	}
+	statement := CustomerStatement{CustomerID: customerID, CustomerName: name, AsOf: asOf, Balances: map[string]float64{}}
+	for _, inv := range invoices {
+		total, paid, balance, err := s.repo.GetInvoiceBalance(ctx, inv.ID)
+		if err != nil {
+			return CustomerStatement{}, err
+		}
+		if balance < 0.005 {
+			continue
+		}
+		statement.Lines = append(statement.Lines, StatementLine{
+			Number:   inv.Number,
+			PostedAt: inv.PostedAt,
+			DueAt:    inv.DueAt,
+			Currency: inv.Currency,
+			Total:    total,
+			Paid:     paid,
+			Balance:  balance,
+		})
+		statement.Balances[inv.Currency] += balance
+	}
+	sort.SliceStable(statement.Lines, func(i, j int) bool {
+		if !statement.Lines[i].DueAt.Equal(statement.Lines[j].DueAt) {
+			return statement.Lines[i].DueAt.Before(statement.Lines[j].DueAt)
+		}
+		return statement.Lines[i].Number < statement.Lines[j].Number
+	})
+	return statement, nil
+}
+
+func emailable(status ARInvoiceStatus) bool {
+	return status == ARStatusPosted || status == ARStatusPaid
+}
+
+// parseAddresses parses a comma or semicolon separated list of email
+// addresses, with or without display names.
+func parseAddresses(raw string) ([]string, error) {
+	raw = strings.TrimSpace(strings.NewReplacer(";", ",", "\r\n", ",", "\n", ",").Replace(raw))
+	raw = strings.Trim(raw, ", ")
+	if raw == "" {
+		return nil, nil
+	}
+	list, err := mail.ParseAddressList(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid email address list %q", shared.ErrValidation, raw)
+	}
+	out := make([]string, len(list))
+	for i, addr := range list {
+		out[i] = addr.Address
+	}
+	return out, nil
+}
@@ -0,0 +1,205 @@
+package ar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+func (r *memoryARRepo) CustomerContact(_ context.Context, customerID int64) (string, string, error) {
+	return "Customer", r.customerEmails[customerID], nil
+}
+
+func (r *memoryARRepo) CustomerCompanyID(_ context.Context, customerID int64) (int64, error) {
+	return r.customerCompanies[customerID], nil
+}
+
+func (r *memoryARRepo) CreateInvoiceEmail(_ context.Context, email InvoiceEmail) (int64, error) {
+	email.ID = int64(len(r.emails) + 1)
+	r.emails[email.ID] = &email
+	return email.ID, nil
+}
+
+func (r *memoryARRepo) GetInvoiceEmail(_ context.Context, id int64) (*InvoiceEmail, error) {
+	email, ok := r.emails[id]
+	if !ok {
+		return nil, ErrInvoiceEmailNotFound
+	}
+	copied := *email
+	return &copied, nil
+}
+
+func (r *memoryARRepo) ListInvoiceEmails(_ context.Context, invoiceID int64) ([]InvoiceEmail, error) {
+	var out []InvoiceEmail
+	for _, email := range r.emails {
+		if email.ARInvoiceID == invoiceID {
+			out = append(out, *email)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryARRepo) RecordInvoiceEmailAttempt(_ context.Context, id int64, status InvoiceEmailStatus, lastError string, at time.Time) error {
+	email := r.emails[id]
+	email.Attempts++
+	email.Status, email.LastError = status, lastError
+	if status == InvoiceEmailSent {
+		email.SentAt = &at
+	}
+	return nil
+}
+
+type fakeEmailQueue struct {
+	queued []int64
+	err    error
+}
+
+func (f *fakeEmailQueue) EnqueueARInvoiceEmail(_ context.Context, emailID int64) (*asynq.TaskInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.queued = append(f.queued, emailID)
+	return &asynq.TaskInfo{}, nil
+}
+
+type fakeDocuments struct {
+	statement CustomerStatement
+}
+
+func (f *fakeDocuments) InvoicePDF(context.Context, ARInvoiceWithDetails) ([]byte, error) {
+	return []byte("%PDF-invoice"), nil
+}
+
+func (f *fakeDocuments) StatementPDF(_ context.Context, statement CustomerStatement) ([]byte, error) {
+	f.statement = statement
+	return []byte("%PDF-statement"), nil
+}
+
+type fakeSender struct {
+	sent []jobs.SendEmailPayload
+	err  error
+}
+
+func (f *fakeSender) Send(_ context.Context, payload jobs.SendEmailPayload) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, payload)
+	return nil
+}
+
+func emailRepo() *memoryARRepo {
+	repo := newMemoryARRepo()
+	repo.customerEmails[100] = "billing@customer.test"
+	repo.invoices[1] = &ARInvoice{ID: 1, Number: "INV-1", CustomerID: 100, Currency: "IDR", Total: 1100, Status: ARStatusPosted, DueAt: time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)}
+	repo.invoices[2] = &ARInvoice{ID: 2, Number: "INV-2", CustomerID: 100, Currency: "IDR", Total: 500, Status: ARStatusDraft}
+	repo.invoices[3] = &ARInvoice{ID: 3, Number: "INV-3", CustomerID: 100, Currency: "IDR", Total: 300, Status: ARStatusVoid}
+	return repo
+}
+
+func TestSendInvoiceEmailQueuesPostedInvoicesOnly(t *testing.T) {
+	ctx := context.Background()
+	repo := emailRepo()
+	queue := &fakeEmailQueue{}
+	svc := NewService(repo)
+
+	_, err := svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 1})
+	require.ErrorIs(t, err, ErrInvoiceEmailDisabled)
+
+	svc.SetInvoiceEmailQueue(queue)
+	_, err = svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 2})
+	require.ErrorIs(t, err, ErrInvoiceNotSendable)
+	_, err = svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 3})
+	require.ErrorIs(t, err, ErrInvoiceNotSendable)
+	_, err = svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 1, Cc: "not-an-address"})
+	require.ErrorIs(t, err, shared.ErrValidation)
+
+	email, err := svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{
+		InvoiceID:        1,
+		Cc:               "ar@odyssey.test; Sales <sales@odyssey.test>",
+		Bcc:              "audit@odyssey.test",
+		IncludeStatement: true,
+		RequestedBy:      7,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "billing@customer.test", email.Recipient)
+	require.Equal(t, []string{"ar@odyssey.test", "sales@odyssey.test"}, email.Cc)
+	require.Equal(t, []string{"audit@odyssey.test"}, email.Bcc)
+	require.Equal(t, []int64{email.ID}, queue.queued)
+	require.Equal(t, InvoiceEmailQueued, repo.emails[email.ID].Status)
+
+	repo.customerEmails[100] = " "
+	_, err = svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 1})
+	require.ErrorIs(t, err, ErrNoCustomerEmail)
+
+	repo.customerEmails[100] = "billing@customer.test"
+	queue.err = errors.New("redis down")
+	_, err = svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 1})
+	require.Error(t, err)
+	require.Equal(t, InvoiceEmailFailed, repo.emails[2].Status)
+}
+
+func TestDeliverInvoiceEmailAttachesPDFsAndLogsAttempts(t *testing.T) {
+	ctx := context.Background()
+	repo := emailRepo()
+	docs := &fakeDocuments{}
+	sender := &fakeSender{err: errors.New("connection refused")}
+	svc := NewService(repo)
+	svc.SetInvoiceEmailQueue(&fakeEmailQueue{})
+	svc.SetInvoiceDelivery(docs, sender)
+
+	email, err := svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 1, Cc: "ar@odyssey.test", IncludeStatement: true})
+	require.NoError(t, err)
+
+	require.Error(t, svc.DeliverInvoiceEmail(ctx, email.ID, false))
+	require.Equal(t, InvoiceEmailQueued, repo.emails[email.ID].Status)
+	require.Equal(t, "connection refused", repo.emails[email.ID].LastError)
+
+	sender.err = nil
+	require.NoError(t, svc.DeliverInvoiceEmail(ctx, email.ID, false))
+	logged := repo.emails[email.ID]
+	require.Equal(t, InvoiceEmailSent, logged.Status)
+	require.Equal(t, 2, logged.Attempts)
+	require.NotNil(t, logged.SentAt)
+
+	require.Len(t, sender.sent, 1)
+	sent := sender.sent[0]
+	require.Equal(t, "billing@customer.test", sent.To)
+	require.Equal(t, []string{"ar@odyssey.test"}, sent.Cc)
+	require.Equal(t, "Invoice INV-1", sent.Subject)
+	require.Len(t, sent.Attachments, 2)
+	require.Equal(t, "invoice-INV-1.pdf", sent.Attachments[0].Filename)
+	require.Len(t, docs.statement.Lines, 1)
+	require.Equal(t, 1100.0, docs.statement.Balances["IDR"])
+
+	require.NoError(t, svc.DeliverInvoiceEmail(ctx, email.ID, false))
+	require.Len(t, sender.sent, 1, "sent emails are not delivered again")
+}
+
+func TestDeliverInvoiceEmailFailsVoidedInvoiceAndLastAttempt(t *testing.T) {
+	ctx := context.Background()
+	repo := emailRepo()
+	sender := &fakeSender{err: errors.New("mailbox unavailable")}
+	svc := NewService(repo)
+	svc.SetInvoiceEmailQueue(&fakeEmailQueue{})
+	svc.SetInvoiceDelivery(&fakeDocuments{}, sender)
+
+	first, err := svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 1})
+	require.NoError(t, err)
+	require.Error(t, svc.DeliverInvoiceEmail(ctx, first.ID, true))
+	require.Equal(t, InvoiceEmailFailed, repo.emails[first.ID].Status)
+
+	second, err := svc.SendInvoiceEmail(ctx, SendInvoiceEmailInput{InvoiceID: 1})
+	require.NoError(t, err)
+	repo.invoices[1].Status = ARStatusVoid
+	require.ErrorIs(t, svc.DeliverInvoiceEmail(ctx, second.ID, false), ErrInvoiceNotSendable)
+	require.Equal(t, InvoiceEmailFailed, repo.emails[second.ID].Status)
+	require.Empty(t, sender.sent)
+}
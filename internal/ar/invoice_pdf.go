@@ -0,0 +1,67 @@
+package ar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+	"github.com/odyssey-erp/odyssey-erp/web"
+)
+
+// PDFRenderer converts HTML documents into PDF bytes.
+type PDFRenderer interface {
+	RenderHTML(ctx context.Context, html string) ([]byte, error)
+}
+
+// DocumentExporter renders AR invoices and statements of account as PDF.
+type DocumentExporter struct {
+	renderer  PDFRenderer
+	templates *template.Template
+}
+
+// NewDocumentExporter parses the invoice and statement templates.
+func NewDocumentExporter(renderer PDFRenderer) (*DocumentExporter, error) {
+	funcMap := template.FuncMap{
+		"formatDate": func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format("02 Jan 2006")
+		},
+		"formatDecimal": func(v float64) string {
+			return strconv.FormatFloat(v, 'f', 2, 64)
+		},
+	}
+	tpl, err := template.New("ar_invoice_pdf.html").Funcs(funcMap).ParseFS(
+		web.Templates, "templates/reports/ar_invoice_pdf.html", "templates/reports/ar_statement_pdf.html",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parse AR document templates: %w", err)
+	}
+	return &DocumentExporter{renderer: renderer, templates: tpl}, nil
+}
+
+// InvoicePDF renders the customer copy of an invoice.
+func (e *DocumentExporter) InvoicePDF(ctx context.Context, invoice ARInvoiceWithDetails) ([]byte, error) {
+	return e.render(ctx, "reports/ar_invoice_pdf.html", invoice)
+}
+
+// StatementPDF renders a statement of account.
+func (e *DocumentExporter) StatementPDF(ctx context.Context, statement CustomerStatement) ([]byte, error) {
+	return e.render(ctx, "reports/ar_statement_pdf.html", statement)
+}
+
+func (e *DocumentExporter) render(ctx context.Context, name string, data any) ([]byte, error) {
+	if e == nil || e.renderer == nil {
+		return nil, fmt.Errorf("AR document exporter not initialized")
+	}
+	buf := &bytes.Buffer{}
+	if err := e.templates.ExecuteTemplate(buf, name, view.TemplateData{Data: data}); err != nil {
+		return nil, fmt.Errorf("render %s: %w", name, err)
+	}
+	return e.renderer.RenderHTML(ctx, buf.String())
+}
@@ -0,0 +1,51 @@
+package ar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+// InvoiceEmailJob delivers queued invoice emails on the worker.
+type InvoiceEmailJob struct {
+	service *Service
+	logger  *slog.Logger
+}
+
+// NewInvoiceEmailJob constructs an InvoiceEmailJob handler.
+func NewInvoiceEmailJob(service *Service, logger *slog.Logger) *InvoiceEmailJob {
+	return &InvoiceEmailJob{service: service, logger: logger}
+}
+
+// Handle fulfils the asynq.HandlerFunc contract for jobs.TaskARInvoiceEmail.
+// Transport failures are returned so Asynq retries; the final retry marks
+// the email failed. Emails that can never be sent are not retried.
+func (j *InvoiceEmailJob) Handle(ctx context.Context, task *asynq.Task) error {
+	if j == nil || j.service == nil {
+		return fmt.Errorf("invoice email job not configured")
+	}
+	var payload jobs.ARInvoiceEmailPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil || payload.EmailID == 0 {
+		return asynq.SkipRetry
+	}
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	err := j.service.DeliverInvoiceEmail(ctx, payload.EmailID, retried >= maxRetry)
+	if err == nil {
+		return nil
+	}
+	if j.logger != nil {
+		j.logger.WarnContext(ctx, "send invoice email", slog.Int64("email_id", payload.EmailID), slog.Int("retried", retried), slog.Any("error", err))
+	}
+	if errors.Is(err, ErrInvoiceEmailNotFound) || errors.Is(err, shared.ErrValidation) {
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 // Repository provides PostgreSQL backed persistence for AR.
@@ -143,15 +144,18 @@ func (r *Repository) CreateARInvoiceLine(ctx context.Context, invoiceID int64, l
 	return &result, nil
 }
 
-// GetARInvoice retrieves an invoice by ID.
+// GetARInvoice retrieves an invoice by ID. Invoices of customers outside the
+// caller's company scope are reported as not found.
 func (r *Repository) GetARInvoice(ctx context.Context, id int64) (*ARInvoice, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
 	query := `
 		SELECT id, number, customer_id, so_id, delivery_order_id, currency,
 			subtotal, tax_amount, total, status, due_at,
 			posted_at, posted_by, voided_at, voided_by, void_reason,
 			created_by, created_at, updated_at
 		FROM ar_invoices
-		WHERE id = $1`
+		WHERE id = $1
+		  AND ($2 OR customer_id IN (SELECT id FROM customers WHERE company_id = ANY($3)))`
 
 	var inv ARInvoice
 	var soID, doID, postedBy, voidedBy, createdBy pgtype.Int8
@@ -159,7 +163,7 @@ func (r *Repository) GetARInvoice(ctx context.Context, id int64) (*ARInvoice, er
 	var voidReason pgtype.Text
 	var subtotal, taxAmount pgtype.Numeric
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := r.pool.QueryRow(ctx, query, id, scope.Unrestricted, scope.CompanyIDs).Scan(
 		&inv.ID, &inv.Number, &inv.CustomerID, &soID, &doID, &inv.Currency,
 		&subtotal, &taxAmount, &inv.Total, &inv.Status, &inv.DueAt,
 		&postedAt, &postedBy, &voidedAt, &voidedBy, &voidReason,
@@ -206,8 +210,7 @@ func (r *Repository) GetARInvoiceWithDetails(ctx context.Context, id int64) (*AR
 	}
 
 	// Get customer name
-	var customerName string
-	_ = r.pool.QueryRow(ctx, "SELECT name FROM customers WHERE id = $1", inv.CustomerID).Scan(&customerName)
+	_ = r.pool.QueryRow(ctx, "SELECT name FROM customers WHERE id = $1", inv.CustomerID).Scan(&inv.CustomerName)
 
 	// Get lines
 	lines, err := r.ListARInvoiceLines(ctx, id)
@@ -225,50 +228,63 @@ func (r *Repository) GetARInvoiceWithDetails(ctx context.Context, id int64) (*AR
 	_, paidAmount, balance, _ := r.GetInvoiceBalance(ctx, id)
 
 	return &ARInvoiceWithDetails{
-		ARInvoice:    *inv,
-		CustomerName: customerName,
-		Lines:        lines,
-		Payments:     payments,
-		PaidAmount:   paidAmount,
-		Balance:      balance,
+		ARInvoice:  *inv,
+		Lines:      lines,
+		Payments:   payments,
+		PaidAmount: paidAmount,
+		Balance:    balance,
 	}, nil
 }
 
 // ListARInvoices returns invoices with optional filtering.
 func (r *Repository) ListARInvoices(ctx context.Context, req ListARInvoicesRequest) ([]ARInvoice, error) {
 	query := `
-		SELECT id, number, customer_id, so_id, delivery_order_id, currency,
-			subtotal, tax_amount, total, status, due_at,
-			posted_at, posted_by, voided_at, voided_by, void_reason,
-			created_by, created_at, updated_at
-		FROM ar_invoices
+		SELECT i.id, i.number, i.customer_id, COALESCE(c.name, ''), i.so_id, i.delivery_order_id, i.currency,
+			i.subtotal, i.tax_amount, i.total, i.status, i.due_at,
+			i.posted_at, i.posted_by, i.voided_at, i.voided_by, i.void_reason,
+			i.created_by, i.created_at, i.updated_at
+		FROM ar_invoices i
+		LEFT JOIN customers c ON c.id = i.customer_id
 		WHERE 1=1`
 
 	args := []any{}
 	argNum := 1
 
 	if req.Status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argNum)
+		query += fmt.Sprintf(" AND i.status = $%d", argNum)
 		args = append(args, string(req.Status))
 		argNum++
 	}
 	if req.CustomerID > 0 {
-		query += fmt.Sprintf(" AND customer_id = $%d", argNum)
+		query += fmt.Sprintf(" AND i.customer_id = $%d", argNum)
 		args = append(args, req.CustomerID)
 		argNum++
 	}
+	if req.CompanyID > 0 {
+		query += fmt.Sprintf(" AND c.company_id = $%d", argNum)
+		args = append(args, req.CompanyID)
+		argNum++
+	}
 	if !req.FromDate.IsZero() {
-		query += fmt.Sprintf(" AND created_at >= $%d", argNum)
+		query += fmt.Sprintf(" AND i.created_at >= $%d", argNum)
 		args = append(args, req.FromDate)
 		argNum++
 	}
 	if !req.ToDate.IsZero() {
-		query += fmt.Sprintf(" AND created_at <= $%d", argNum)
+		query += fmt.Sprintf(" AND i.created_at <= $%d", argNum)
 		args = append(args, req.ToDate)
 		argNum++
 	}
+	if !req.IncludeArchived {
+		query += " AND i.archived_at IS NULL"
+	}
+	if scope := shared.CompanyScopeFromContext(ctx); !scope.Unrestricted {
+		query += fmt.Sprintf(" AND c.company_id = ANY($%d)", argNum)
+		args = append(args, scope.CompanyIDs)
+		argNum++
+	}
 
-	query += " ORDER BY created_at DESC"
+	query += " ORDER BY i.created_at DESC"
 
 	if req.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argNum)
@@ -295,7 +311,7 @@ func (r *Repository) ListARInvoices(ctx context.Context, req ListARInvoicesReque
 		var subtotal, taxAmount pgtype.Numeric
 
 		err := rows.Scan(
-			&inv.ID, &inv.Number, &inv.CustomerID, &soID, &doID, &inv.Currency,
+			&inv.ID, &inv.Number, &inv.CustomerID, &inv.CustomerName, &soID, &doID, &inv.Currency,
 			&subtotal, &taxAmount, &inv.Total, &inv.Status, &inv.DueAt,
 			&postedAt, &postedBy, &voidedAt, &voidedBy, &voidReason,
 			&createdBy, &inv.CreatedAt, &inv.UpdatedAt,
@@ -409,7 +425,8 @@ func (r *Repository) GetInvoiceBalance(ctx context.Context, id int64) (total, pa
 		SELECT 
 			i.total,
 			COALESCE(SUM(pa.amount), 0) AS paid_amount,
-			i.total - COALESCE(SUM(pa.amount), 0) AS balance
+			i.total - COALESCE(SUM(pa.amount), 0)
+				- (SELECT COALESCE(SUM(cn.total), 0) FROM ar_credit_notes cn WHERE cn.ar_invoice_id = i.id) AS balance
 		FROM ar_invoices i
 		LEFT JOIN ar_payment_allocations pa ON pa.ar_invoice_id = i.id
 		WHERE i.id = $1
@@ -422,6 +439,16 @@ func (r *Repository) GetInvoiceBalance(ctx context.Context, id int64) (total, pa
 	return
 }
 
+// CustomerCompanyID returns the company owning the customer.
+func (r *Repository) CustomerCompanyID(ctx context.Context, customerID int64) (int64, error) {
+	var companyID int64
+	err := r.pool.QueryRow(ctx, `SELECT company_id FROM customers WHERE id = $1`, customerID).Scan(&companyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return companyID, err
+}
+
 // CountInvoicesByDelivery counts invoices for a delivery order.
 func (r *Repository) CountInvoicesByDelivery(ctx context.Context, deliveryOrderID int64) (int, error) {
 	var count int
@@ -505,15 +532,20 @@ func (r *Repository) CreatePaymentAllocation(ctx context.Context, paymentID, inv
 	return err
 }
 
-// ListARPayments returns all payments.
+// ListARPayments returns all payments within the caller's company scope.
 func (r *Repository) ListARPayments(ctx context.Context) ([]ARPayment, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
 	query := `
 		SELECT id, number, ar_invoice_id, amount, paid_at, method, note, 
 			created_by, created_at, updated_at
 		FROM ar_payments
+		WHERE $1 OR ar_invoice_id IN (
+			SELECT i.id FROM ar_invoices i
+			JOIN customers c ON c.id = i.customer_id
+			WHERE c.company_id = ANY($2))
 		ORDER BY paid_at DESC`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.pool.Query(ctx, query, scope.Unrestricted, scope.CompanyIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,64 @@
+package ar
+
+import (
+	"context"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// CreditExposureTotals sums, per customer and currency, the unpaid balance of
+// posted invoices after payments and credit notes, and the undelivered share
+// of confirmed and in-process order lines. Customers with a credit limit are
+// listed even without exposure. A restricted scope limits the customers to
+// its companies.
+func (r *Repository) CreditExposureTotals(ctx context.Context, scope shared.CompanyScope) ([]CreditExposureTotals, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH ar AS (
+			SELECT i.customer_id, i.currency,
+				SUM(i.total - COALESCE(pa.paid, 0) - COALESCE(cn.credited, 0)) AS balance
+			FROM ar_invoices i
+			LEFT JOIN (
+				SELECT ar_invoice_id, SUM(amount) AS paid FROM ar_payment_allocations GROUP BY ar_invoice_id
+			) pa ON pa.ar_invoice_id = i.id
+			LEFT JOIN (
+				SELECT ar_invoice_id, SUM(total) AS credited FROM ar_credit_notes
+				WHERE ar_invoice_id IS NOT NULL GROUP BY ar_invoice_id
+			) cn ON cn.ar_invoice_id = i.id
+			WHERE i.status = 'POSTED'
+			  AND i.total - COALESCE(pa.paid, 0) - COALESCE(cn.credited, 0) > 0
+			GROUP BY i.customer_id, i.currency
+		), open_orders AS (
+			SELECT so.customer_id, so.currency,
+				SUM(l.line_total * (l.quantity - l.quantity_delivered) / l.quantity) AS open_value
+			FROM sales_orders so
+			JOIN sales_order_lines l ON l.sales_order_id = so.id
+			WHERE so.status IN ('CONFIRMED', 'PROCESSING') AND l.quantity_delivered < l.quantity
+			GROUP BY so.customer_id, so.currency
+		), amounts AS (
+			SELECT customer_id, currency, balance, 0 AS open_value FROM ar
+			UNION ALL
+			SELECT customer_id, currency, 0, open_value FROM open_orders
+		)
+		SELECT c.id, c.code, c.name, c.credit_limit::float8, COALESCE(a.currency, ''),
+			COALESCE(SUM(a.balance), 0)::float8, COALESCE(SUM(a.open_value), 0)::float8
+		FROM customers c
+		LEFT JOIN amounts a ON a.customer_id = c.id
+		WHERE (c.credit_limit > 0 OR a.customer_id IS NOT NULL)
+		  AND ($1 OR c.company_id = ANY($2))
+		GROUP BY c.id, c.code, c.name, c.credit_limit, a.currency
+		ORDER BY c.code, c.id, a.currency
+	`, scope.Unrestricted, scope.CompanyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []CreditExposureTotals
+	for rows.Next() {
+		var t CreditExposureTotals
+		if err := rows.Scan(&t.CustomerID, &t.CustomerCode, &t.CustomerName, &t.CreditLimit, &t.Currency, &t.OutstandingAR, &t.OpenOrders); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
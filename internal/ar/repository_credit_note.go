@@ -0,0 +1,76 @@
+package ar
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateARCreditNote stores a credit note.
+func (r *Repository) CreateARCreditNote(ctx context.Context, input CreateARCreditNoteInput) (*ARCreditNote, error) {
+	note := ARCreditNote{
+		Number:        input.Number,
+		CustomerID:    input.CustomerID,
+		SOID:          input.SOID,
+		ARInvoiceID:   input.ARInvoiceID,
+		SalesReturnID: input.SalesReturnID,
+		Currency:      input.Currency,
+		Subtotal:      input.Subtotal,
+		TaxAmount:     input.TaxAmount,
+		Total:         input.Total,
+		Reason:        input.Reason,
+		CreatedBy:     input.CreatedBy,
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO ar_credit_notes (
+			number, customer_id, so_id, ar_invoice_id, sales_return_id, currency,
+			subtotal, tax_amount, total, reason, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at`,
+		input.Number, input.CustomerID, optionalID(input.SOID), optionalID(input.ARInvoiceID),
+		optionalID(input.SalesReturnID), input.Currency, input.Subtotal, input.TaxAmount, input.Total,
+		input.Reason, optionalID(input.CreatedBy),
+	).Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// GetCreditNoteBySalesReturn returns the credit note raised for a sales
+// return, or nil when there is none.
+func (r *Repository) GetCreditNoteBySalesReturn(ctx context.Context, salesReturnID int64) (*ARCreditNote, error) {
+	var (
+		note            ARCreditNote
+		soID, invoiceID pgtype.Int8
+		createdBy       pgtype.Int8
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, number, customer_id, so_id, ar_invoice_id, sales_return_id, currency,
+		       subtotal::float8, tax_amount::float8, total::float8, reason, created_by, created_at
+		FROM ar_credit_notes
+		WHERE sales_return_id = $1`, salesReturnID,
+	).Scan(&note.ID, &note.Number, &note.CustomerID, &soID, &invoiceID, &note.SalesReturnID, &note.Currency,
+		&note.Subtotal, &note.TaxAmount, &note.Total, &note.Reason, &createdBy, &note.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	note.SOID, note.ARInvoiceID, note.CreatedBy = soID.Int64, invoiceID.Int64, createdBy.Int64
+	return &note, nil
+}
+
+// GenerateCreditNoteNumber generates a unique credit note number.
+func (r *Repository) GenerateCreditNoteNumber(ctx context.Context) (string, error) {
+	var number string
+	err := r.pool.QueryRow(ctx, "SELECT generate_ar_credit_note_number()").Scan(&number)
+	return number, err
+}
+
+func optionalID(id int64) pgtype.Int8 {
+	return pgtype.Int8{Int64: id, Valid: id > 0}
+}
@@ -0,0 +1,108 @@
+package ar
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CustomerContact returns the customer's name and email address.
+func (r *Repository) CustomerContact(ctx context.Context, customerID int64) (string, string, error) {
+	var name string
+	var email pgtype.Text
+	err := r.pool.QueryRow(ctx, `SELECT name, email FROM customers WHERE id = $1`, customerID).Scan(&name, &email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return name, email.String, nil
+}
+
+// CreateInvoiceEmail logs a queued invoice email.
+func (r *Repository) CreateInvoiceEmail(ctx context.Context, email InvoiceEmail) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO ar_invoice_emails (ar_invoice_id, recipient, cc, bcc, include_statement, status, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		email.ARInvoiceID, email.Recipient, nonNilStrings(email.Cc), nonNilStrings(email.Bcc),
+		email.IncludeStatement, email.Status, optionalID(email.RequestedBy),
+	).Scan(&id)
+	return id, err
+}
+
+const invoiceEmailColumns = `id, ar_invoice_id, recipient, cc, bcc, include_statement, status,
+		attempts, last_error, COALESCE(requested_by, 0), requested_at, sent_at`
+
+// GetInvoiceEmail returns one invoice email log entry.
+func (r *Repository) GetInvoiceEmail(ctx context.Context, id int64) (*InvoiceEmail, error) {
+	email, err := scanInvoiceEmail(r.pool.QueryRow(ctx, `SELECT `+invoiceEmailColumns+` FROM ar_invoice_emails WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvoiceEmailNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &email, nil
+}
+
+// ListInvoiceEmails returns the email log of an invoice, newest first.
+func (r *Repository) ListInvoiceEmails(ctx context.Context, invoiceID int64) ([]InvoiceEmail, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+invoiceEmailColumns+`
+		FROM ar_invoice_emails
+		WHERE ar_invoice_id = $1
+		ORDER BY requested_at DESC, id DESC`, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []InvoiceEmail
+	for rows.Next() {
+		email, err := scanInvoiceEmail(rows)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// RecordInvoiceEmailAttempt counts a delivery attempt and stores its outcome.
+func (r *Repository) RecordInvoiceEmailAttempt(ctx context.Context, id int64, status InvoiceEmailStatus, lastError string, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE ar_invoice_emails
+		SET attempts = attempts + 1,
+		    status = $2,
+		    last_error = $3,
+		    sent_at = CASE WHEN $2 = 'SENT' THEN $4 ELSE sent_at END
+		WHERE id = $1`, id, status, lastError, at)
+	return err
+}
+
+func scanInvoiceEmail(row pgx.Row) (InvoiceEmail, error) {
+	var email InvoiceEmail
+	var sentAt pgtype.Timestamptz
+	if err := row.Scan(&email.ID, &email.ARInvoiceID, &email.Recipient, &email.Cc, &email.Bcc,
+		&email.IncludeStatement, &email.Status, &email.Attempts, &email.LastError, &email.RequestedBy,
+		&email.RequestedAt, &sentAt); err != nil {
+		return InvoiceEmail{}, err
+	}
+	if sentAt.Valid {
+		email.SentAt = &sentAt.Time
+	}
+	return email, nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -3,7 +3,12 @@ package ar
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 // Error definitions
@@ -12,6 +17,7 @@ var (
 	ErrInvalidStatus      = errors.New("ar: invalid invoice status for this operation")
 	ErrInsufficientAmount = errors.New("ar: payment amount exceeds invoice balance")
 	ErrAlreadyInvoiced    = errors.New("ar: delivery order already invoiced")
+	ErrVoidReasonRequired = errors.New("ar: void reason required")
 )
 
 // RepositoryPort defines data access methods for AR.
@@ -38,6 +44,23 @@ type RepositoryPort interface {
 
 	// Aging operations
 	ListAROutstanding(ctx context.Context) ([]ARInvoice, error)
+
+	// Credit exposure
+	CreditExposureTotals(ctx context.Context, scope shared.CompanyScope) ([]CreditExposureTotals, error)
+
+	// Credit note operations
+	CreateARCreditNote(ctx context.Context, input CreateARCreditNoteInput) (*ARCreditNote, error)
+	GetCreditNoteBySalesReturn(ctx context.Context, salesReturnID int64) (*ARCreditNote, error)
+	GenerateCreditNoteNumber(ctx context.Context) (string, error)
+
+	// Invoice email operations
+	CustomerContact(ctx context.Context, customerID int64) (name, email string, err error)
+	// CustomerCompanyID returns the company owning the customer.
+	CustomerCompanyID(ctx context.Context, customerID int64) (int64, error)
+	CreateInvoiceEmail(ctx context.Context, email InvoiceEmail) (int64, error)
+	GetInvoiceEmail(ctx context.Context, id int64) (*InvoiceEmail, error)
+	ListInvoiceEmails(ctx context.Context, invoiceID int64) ([]InvoiceEmail, error)
+	RecordInvoiceEmailAttempt(ctx context.Context, id int64, status InvoiceEmailStatus, lastError string, at time.Time) error
 }
 
 // DeliveryServicePort for fetching delivery order details.
@@ -52,6 +75,7 @@ type DeliveryOrderInfo struct {
 	CustomerID   int64
 	CustomerName string
 	SalesOrderID int64
+	CompanyID    int64
 	WarehouseID  int64
 	Currency     string
 	Lines        []DeliveryLineInfo
@@ -71,6 +95,14 @@ type DeliveryLineInfo struct {
 // AccountingServicePort for creating journal entries.
 type AccountingServicePort interface {
 	CreateARPostingJournal(ctx context.Context, invoice *ARInvoice) error
+	CreateARVoidJournal(ctx context.Context, invoice *ARInvoice, reason string) error
+	CreateARCreditNoteJournal(ctx context.Context, note *ARCreditNote) error
+}
+
+// PeriodGuard checks that the accounting period covering a date still
+// accepts postings.
+type PeriodGuard interface {
+	EnsureOpen(ctx context.Context, date time.Time) error
 }
 
 // Service handles AR business logic.
@@ -78,6 +110,13 @@ type Service struct {
 	repo       RepositoryPort
 	delivery   DeliveryServicePort
 	accounting AccountingServicePort
+	dueDates   shared.DueDatePolicy
+	periods    PeriodGuard
+	currencies shared.CurrencyValidator
+	rates      shared.RateResolver
+	emailQueue InvoiceEmailQueue
+	documents  InvoiceDocuments
+	sender     EmailSender
 }
 
 // NewService builds Service instance.
@@ -95,6 +134,27 @@ func (s *Service) SetAccountingService(accounting AccountingServicePort) {
 	s.accounting = accounting
 }
 
+// SetPeriodGuard restricts voiding posted invoices to open periods.
+func (s *Service) SetPeriodGuard(guard PeriodGuard) {
+	s.periods = guard
+}
+
+// SetDueDatePolicy rolls new invoice due dates to the company's business days.
+func (s *Service) SetDueDatePolicy(policy shared.DueDatePolicy) {
+	s.dueDates = policy
+}
+
+// SetCurrencyValidator rejects new invoices in inactive currencies.
+func (s *Service) SetCurrencyValidator(v shared.CurrencyValidator) {
+	s.currencies = v
+}
+
+// SetRateResolver reports aging in the base currency. Without it balances
+// are summed in their document currencies.
+func (s *Service) SetRateResolver(rates shared.RateResolver) {
+	s.rates = rates
+}
+
 // CreateARInvoice creates a new AR invoice with lines.
 func (s *Service) CreateARInvoice(ctx context.Context, input CreateARInvoiceInput) (*ARInvoice, error) {
 	if input.CustomerID == 0 {
@@ -103,6 +163,25 @@ func (s *Service) CreateARInvoice(ctx context.Context, input CreateARInvoiceInpu
 	if input.Total <= 0 {
 		return nil, errors.New("total must be positive")
 	}
+	if s.currencies != nil {
+		if err := s.currencies.ValidateCurrency(ctx, input.Currency); err != nil {
+			return nil, err
+		}
+	}
+	if s.dueDates != nil {
+		if input.CompanyID == 0 {
+			companyID, err := s.repo.CustomerCompanyID(ctx, input.CustomerID)
+			if err != nil {
+				return nil, fmt.Errorf("load customer company: %w", err)
+			}
+			input.CompanyID = companyID
+		}
+		due, err := s.dueDates.AdjustDueDate(ctx, input.CompanyID, input.DueDate)
+		if err != nil {
+			return nil, fmt.Errorf("adjust due date: %w", err)
+		}
+		input.DueDate = due
+	}
 
 	// Generate number if not provided
 	if input.Number == "" {
@@ -179,6 +258,7 @@ func (s *Service) CreateARInvoiceFromDelivery(ctx context.Context, input CreateA
 		CustomerID:      do.CustomerID,
 		SOID:            do.SalesOrderID,
 		DeliveryOrderID: do.ID,
+		CompanyID:       do.CompanyID,
 		Currency:        do.Currency,
 		Subtotal:        subtotal,
 		TaxAmount:       taxAmount,
@@ -227,7 +307,9 @@ func (s *Service) PostARInvoice(ctx context.Context, input PostARInvoiceInput) e
 
 	// Create accounting journal entry if service available
 	if s.accounting != nil {
+		postedAt := time.Now()
 		invoice.Status = ARStatusPosted
+		invoice.PostedAt, invoice.PostedBy = &postedAt, &input.PostedBy
 		if err := s.accounting.CreateARPostingJournal(ctx, invoice); err != nil {
 			// Log but don't fail - journal can be created manually
 			// In production, this should use a saga/outbox pattern
@@ -237,8 +319,15 @@ func (s *Service) PostARInvoice(ctx context.Context, input PostARInvoiceInput) e
 	return nil
 }
 
-// VoidARInvoice voids an invoice.
+// VoidARInvoice voids an invoice. Voiding a posted invoice needs its posting
+// period to be open and reverses the ledger entry once the invoice is void.
+// When the reversal fails the invoice stays void, and voiding it again
+// retries the reversal; it posts at most once.
 func (s *Service) VoidARInvoice(ctx context.Context, input VoidARInvoiceInput) error {
+	input.VoidReason = strings.TrimSpace(input.VoidReason)
+	if input.VoidReason == "" {
+		return ErrVoidReasonRequired
+	}
 	invoice, err := s.repo.GetARInvoice(ctx, input.InvoiceID)
 	if err != nil {
 		return err
@@ -246,14 +335,49 @@ func (s *Service) VoidARInvoice(ctx context.Context, input VoidARInvoiceInput) e
 	if invoice == nil {
 		return ErrInvoiceNotFound
 	}
-	if invoice.Status != ARStatusDraft && invoice.Status != ARStatusPosted {
+	switch invoice.Status {
+	case ARStatusDraft:
+		return s.repo.VoidARInvoice(ctx, input.InvoiceID, input.VoidedBy, input.VoidReason)
+	case ARStatusPosted:
+	case ARStatusVoid:
+		if invoice.PostedAt == nil {
+			return ErrInvalidStatus
+		}
+		if invoice.VoidedBy != nil {
+			input.VoidedBy = *invoice.VoidedBy
+		}
+		input.VoidReason = invoice.VoidReason
+		return s.reverseARInvoice(ctx, invoice, input)
+	default:
 		return ErrInvalidStatus
 	}
-	if input.VoidReason == "" {
-		return errors.New("void reason required")
+	// Invoices posted before posted_at was recorded fall back to their
+	// creation date, so the period check is never skipped.
+	if invoice.PostedAt == nil {
+		invoice.PostedAt = &invoice.CreatedAt
+	}
+	if s.periods != nil {
+		if err := s.periods.EnsureOpen(ctx, *invoice.PostedAt); err != nil {
+			return err
+		}
 	}
+	if err := s.repo.VoidARInvoice(ctx, input.InvoiceID, input.VoidedBy, input.VoidReason); err != nil {
+		return err
+	}
+	return s.reverseARInvoice(ctx, invoice, input)
+}
 
-	return s.repo.VoidARInvoice(ctx, input.InvoiceID, input.VoidedBy, input.VoidReason)
+// reverseARInvoice posts the ledger reversal of a voided invoice. The
+// accounting side skips invoices whose posting never reached the ledger.
+func (s *Service) reverseARInvoice(ctx context.Context, invoice *ARInvoice, input VoidARInvoiceInput) error {
+	if s.accounting == nil {
+		return nil
+	}
+	invoice.VoidedBy = &input.VoidedBy
+	if err := s.accounting.CreateARVoidJournal(ctx, invoice, input.VoidReason); err != nil {
+		return fmt.Errorf("reverse AR invoice journal: %w", err)
+	}
+	return nil
 }
 
 // RegisterARPayment records a payment and allocates to invoice(s).
@@ -330,15 +454,27 @@ func (s *Service) GetARPayments(ctx context.Context) ([]ARPayment, error) {
 
 // CalculateARAging groups invoices by due date buckets.
 func (s *Service) CalculateARAging(ctx context.Context, asOf time.Time) (ARAgingBucket, error) {
-	invoices, err := s.repo.ListAROutstanding(ctx)
+	report, err := s.ARAgingReport(ctx, asOf)
 	if err != nil {
 		return ARAgingBucket{}, err
 	}
+	return report.Summary, nil
+}
+
+// ARAgingReport ages every open posted invoice by days past due at asOf, in
+// base currency, and breaks the totals down by customer, largest balance
+// first.
+func (s *Service) ARAgingReport(ctx context.Context, asOf time.Time) (ARAgingReport, error) {
+	invoices, err := s.repo.ListAROutstanding(ctx)
+	if err != nil {
+		return ARAgingReport{}, err
+	}
 	if asOf.IsZero() {
 		asOf = time.Now()
 	}
 
-	var bucket ARAgingBucket
+	report := ARAgingReport{AsOf: asOf}
+	customers := make(map[int64]*ARAgingDetail)
 	for _, inv := range invoices {
 		// Get balance for this invoice
 		_, _, balance, err := s.repo.GetInvoiceBalance(ctx, inv.ID)
@@ -348,20 +484,66 @@ func (s *Service) CalculateARAging(ctx context.Context, asOf time.Time) (ARAging
 		if balance <= 0 {
 			continue
 		}
+		if s.rates != nil && inv.Currency != "" {
+			balance, err = s.rates.Convert(ctx, balance, inv.Currency, s.rates.BaseCurrency(), asOf)
+			if err != nil {
+				return ARAgingReport{}, fmt.Errorf("convert invoice %s: %w", inv.Number, err)
+			}
+		}
 
 		days := int(asOf.Sub(inv.DueAt).Hours() / 24)
-		switch {
-		case days <= 0:
-			bucket.Current += balance
-		case days <= 30:
-			bucket.Bucket30 += balance
-		case days <= 60:
-			bucket.Bucket60 += balance
-		case days <= 90:
-			bucket.Bucket90 += balance
-		default:
-			bucket.Bucket120 += balance
+		report.Summary.add(days, balance)
+
+		detail := customers[inv.CustomerID]
+		if detail == nil {
+			name, _, err := s.repo.CustomerContact(ctx, inv.CustomerID)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return ARAgingReport{}, err
+			}
+			detail = &ARAgingDetail{CustomerID: inv.CustomerID, CustomerName: name}
+			customers[inv.CustomerID] = detail
 		}
+		detail.add(days, balance)
+	}
+	for _, detail := range customers {
+		report.Customers = append(report.Customers, *detail)
+	}
+	sort.Slice(report.Customers, func(i, j int) bool {
+		a, b := report.Customers[i], report.Customers[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.CustomerID < b.CustomerID
+	})
+	return report, nil
+}
+
+// Total sums every bucket.
+func (b ARAgingBucket) Total() float64 {
+	return b.Current + b.Bucket30 + b.Bucket60 + b.Bucket90 + b.Bucket120
+}
+
+func (b *ARAgingBucket) add(days int, amount float64) {
+	*agingSlot(days, &b.Current, &b.Bucket30, &b.Bucket60, &b.Bucket90, &b.Bucket120) += amount
+}
+
+func (d *ARAgingDetail) add(days int, amount float64) {
+	*agingSlot(days, &d.Current, &d.Bucket30, &d.Bucket60, &d.Bucket90, &d.Bucket120) += amount
+	d.Total += amount
+}
+
+// agingSlot picks the bucket a balance falls in by days past due.
+func agingSlot(days int, current, bucket30, bucket60, bucket90, bucket120 *float64) *float64 {
+	switch {
+	case days <= 0:
+		return current
+	case days <= 30:
+		return bucket30
+	case days <= 60:
+		return bucket60
+	case days <= 90:
+		return bucket90
+	default:
+		return bucket120
 	}
-	return bucket, nil
 }
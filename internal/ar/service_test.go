@@ -2,30 +2,41 @@ package ar
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 type memoryARRepo struct {
-	invoices       map[int64]*ARInvoice
-	invoiceLines   map[int64][]ARInvoiceLine
-	payments       map[int64]*ARPayment
-	allocations    map[int64][]PaymentAllocationInput
-	nextInvoiceID  int64
-	nextPaymentID  int64
-	nextLineID     int64
-	invoiceCounter int64
-	paymentCounter int64
+	invoices          map[int64]*ARInvoice
+	invoiceLines      map[int64][]ARInvoiceLine
+	payments          map[int64]*ARPayment
+	allocations       map[int64][]PaymentAllocationInput
+	creditNotes       []ARCreditNote
+	customerEmails    map[int64]string
+	customerCompanies map[int64]int64
+	emails            map[int64]*InvoiceEmail
+	nextInvoiceID     int64
+	nextPaymentID     int64
+	nextLineID        int64
+	invoiceCounter    int64
+	paymentCounter    int64
+	exposure          []CreditExposureTotals
 }
 
 func newMemoryARRepo() *memoryARRepo {
 	return &memoryARRepo{
-		invoices:     make(map[int64]*ARInvoice),
-		invoiceLines: make(map[int64][]ARInvoiceLine),
-		payments:     make(map[int64]*ARPayment),
-		allocations:  make(map[int64][]PaymentAllocationInput),
+		invoices:       make(map[int64]*ARInvoice),
+		invoiceLines:   make(map[int64][]ARInvoiceLine),
+		payments:       make(map[int64]*ARPayment),
+		allocations:    make(map[int64][]PaymentAllocationInput),
+		customerEmails: make(map[int64]string),
+		emails:         make(map[int64]*InvoiceEmail),
 	}
 }
 
@@ -152,7 +163,13 @@ func (r *memoryARRepo) GetInvoiceBalance(ctx context.Context, id int64) (total,
 			}
 		}
 	}
-	return inv.Total, paid, inv.Total - paid, nil
+	credited := 0.0
+	for _, note := range r.creditNotes {
+		if note.ARInvoiceID == id {
+			credited += note.Total
+		}
+	}
+	return inv.Total, paid, inv.Total - paid - credited, nil
 }
 
 func (r *memoryARRepo) CountInvoicesByDelivery(ctx context.Context, deliveryOrderID int64) (int, error) {
@@ -237,6 +254,35 @@ func (r *memoryARRepo) ListAROutstanding(ctx context.Context) ([]ARInvoice, erro
 	return out, nil
 }
 
+func (r *memoryARRepo) CreateARCreditNote(ctx context.Context, input CreateARCreditNoteInput) (*ARCreditNote, error) {
+	note := ARCreditNote{
+		ID:            int64(len(r.creditNotes) + 1),
+		Number:        input.Number,
+		CustomerID:    input.CustomerID,
+		SOID:          input.SOID,
+		ARInvoiceID:   input.ARInvoiceID,
+		SalesReturnID: input.SalesReturnID,
+		Total:         input.Total,
+		Reason:        input.Reason,
+	}
+	r.creditNotes = append(r.creditNotes, note)
+	return &note, nil
+}
+
+func (r *memoryARRepo) GetCreditNoteBySalesReturn(ctx context.Context, salesReturnID int64) (*ARCreditNote, error) {
+	for i := range r.creditNotes {
+		if r.creditNotes[i].SalesReturnID == salesReturnID {
+			note := r.creditNotes[i]
+			return &note, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryARRepo) GenerateCreditNoteNumber(ctx context.Context) (string, error) {
+	return "CN-TEST-" + string(rune('0'+len(r.creditNotes)+1)), nil
+}
+
 func TestCreateARInvoice(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemoryARRepo()
@@ -271,6 +317,32 @@ func TestCreateARInvoice(t *testing.T) {
 	require.Equal(t, "Product A", lines[0].Description)
 }
 
+type recordingDueDates struct {
+	companies []int64
+}
+
+func (p *recordingDueDates) AdjustDueDate(ctx context.Context, companyID int64, due time.Time) (time.Time, error) {
+	p.companies = append(p.companies, companyID)
+	return due, nil
+}
+
+func TestCreateARInvoiceUsesCustomerCompanyDueDatePolicy(t *testing.T) {
+	repo := newMemoryARRepo()
+	repo.customerCompanies = map[int64]int64{100: 5}
+	svc := NewService(repo)
+	policy := &recordingDueDates{}
+	svc.SetDueDatePolicy(policy)
+	ctx := shared.ContextWithCompanyScope(context.Background(), shared.CompanyScope{CompanyIDs: []int64{3, 5}})
+	input := CreateARInvoiceInput{CustomerID: 100, Currency: "IDR", Total: 1100, DueDate: time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)}
+
+	_, err := svc.CreateARInvoice(ctx, input)
+	require.NoError(t, err)
+	input.CompanyID = 3
+	_, err = svc.CreateARInvoice(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, []int64{5, 3}, policy.companies, "the invoice's company, not the user's default, sets the due date policy")
+}
+
 func TestCreateARInvoiceRequiresCustomerID(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemoryARRepo()
@@ -362,6 +434,100 @@ func TestVoidARInvoice(t *testing.T) {
 	require.Equal(t, "Customer cancelled", updated.VoidReason)
 }
 
+type stubPeriodGuard struct {
+	err error
+}
+
+func (g stubPeriodGuard) EnsureOpen(context.Context, time.Time) error {
+	return g.err
+}
+
+type recordingAccounting struct {
+	voids   []string
+	credits []string
+	voidErr error
+}
+
+func (a *recordingAccounting) CreateARPostingJournal(context.Context, *ARInvoice) error {
+	return nil
+}
+
+func (a *recordingAccounting) CreateARVoidJournal(_ context.Context, invoice *ARInvoice, reason string) error {
+	if a.voidErr != nil {
+		return a.voidErr
+	}
+	a.voids = append(a.voids, invoice.Number+": "+reason)
+	return nil
+}
+
+func (a *recordingAccounting) CreateARCreditNoteJournal(_ context.Context, note *ARCreditNote) error {
+	a.credits = append(a.credits, note.Number)
+	return nil
+}
+
+func TestVoidARInvoiceGuards(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryARRepo()
+	svc := NewService(repo)
+	acct := &recordingAccounting{}
+	svc.SetAccountingService(acct)
+
+	inv, err := svc.CreateARInvoice(ctx, CreateARInvoiceInput{CustomerID: 100, Number: "INV-005", Total: 500, CreatedBy: 1})
+	require.NoError(t, err)
+	require.NoError(t, svc.PostARInvoice(ctx, PostARInvoiceInput{InvoiceID: inv.ID, PostedBy: 1}))
+
+	err = svc.VoidARInvoice(ctx, VoidARInvoiceInput{InvoiceID: inv.ID, VoidedBy: 3})
+	require.ErrorIs(t, err, ErrVoidReasonRequired)
+
+	svc.SetPeriodGuard(stubPeriodGuard{err: accounting.ErrPeriodLocked})
+	err = svc.VoidARInvoice(ctx, VoidARInvoiceInput{InvoiceID: inv.ID, VoidedBy: 3, VoidReason: "Customer cancelled"})
+	require.ErrorIs(t, err, accounting.ErrPeriodLocked)
+	updated, _ := repo.GetARInvoice(ctx, inv.ID)
+	require.Equal(t, ARStatusPosted, updated.Status)
+	require.Empty(t, acct.voids)
+
+	svc.SetPeriodGuard(stubPeriodGuard{})
+	require.NoError(t, svc.VoidARInvoice(ctx, VoidARInvoiceInput{InvoiceID: inv.ID, VoidedBy: 3, VoidReason: "Customer cancelled"}))
+	updated, _ = repo.GetARInvoice(ctx, inv.ID)
+	require.Equal(t, ARStatusVoid, updated.Status)
+	require.Equal(t, []string{"INV-005: Customer cancelled"}, acct.voids)
+}
+
+func TestVoidARInvoiceRetriesFailedReversal(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryARRepo()
+	svc := NewService(repo)
+	acct := &recordingAccounting{voidErr: errors.New("ledger down")}
+	svc.SetAccountingService(acct)
+
+	inv, err := svc.CreateARInvoice(ctx, CreateARInvoiceInput{CustomerID: 100, Number: "INV-006", Total: 600, CreatedBy: 1})
+	require.NoError(t, err)
+	require.NoError(t, svc.PostARInvoice(ctx, PostARInvoiceInput{InvoiceID: inv.ID, PostedBy: 1}))
+
+	err = svc.VoidARInvoice(ctx, VoidARInvoiceInput{InvoiceID: inv.ID, VoidedBy: 3, VoidReason: "Customer cancelled"})
+	require.Error(t, err)
+	updated, _ := repo.GetARInvoice(ctx, inv.ID)
+	require.Equal(t, ARStatusVoid, updated.Status)
+
+	acct.voidErr = nil
+	require.NoError(t, svc.VoidARInvoice(ctx, VoidARInvoiceInput{InvoiceID: inv.ID, VoidedBy: 5, VoidReason: "Retry"}))
+	require.Equal(t, []string{"INV-006: Customer cancelled"}, acct.voids)
+}
+
+func TestVoidARInvoiceChecksPeriodWithoutPostDate(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryARRepo()
+	svc := NewService(repo)
+	svc.SetPeriodGuard(stubPeriodGuard{err: accounting.ErrPeriodLocked})
+
+	inv, err := svc.CreateARInvoice(ctx, CreateARInvoiceInput{CustomerID: 100, Number: "INV-007", Total: 700, CreatedBy: 1})
+	require.NoError(t, err)
+	repo.invoices[inv.ID].Status = ARStatusPosted
+
+	err = svc.VoidARInvoice(ctx, VoidARInvoiceInput{InvoiceID: inv.ID, VoidedBy: 3, VoidReason: "Customer cancelled"})
+	require.ErrorIs(t, err, accounting.ErrPeriodLocked)
+}
+
 func TestRegisterARPayment(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemoryARRepo()
@@ -465,3 +631,82 @@ func TestCalculateARAging(t *testing.T) {
 	require.Equal(t, 200.0, bucket.Bucket30)
 	require.Equal(t, 300.0, bucket.Bucket60)
 }
+
+func TestARAgingReportBreaksDownByCustomer(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryARRepo()
+	svc := NewService(repo)
+
+	asOf := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	inputs := []CreateARInvoiceInput{
+		{CustomerID: 100, Number: "INV-D1", Total: 100, DueDate: asOf.AddDate(0, 0, 10)},
+		{CustomerID: 100, Number: "INV-D2", Total: 250, DueDate: asOf.AddDate(0, 0, -75)},
+		{CustomerID: 200, Number: "INV-D3", Total: 600, DueDate: asOf.AddDate(0, 0, -10)},
+	}
+	for _, input := range inputs {
+		input.CreatedBy = 1
+		inv, err := svc.CreateARInvoice(ctx, input)
+		require.NoError(t, err)
+		require.NoError(t, svc.PostARInvoice(ctx, PostARInvoiceInput{InvoiceID: inv.ID, PostedBy: 1}))
+	}
+
+	report, err := svc.ARAgingReport(ctx, asOf)
+	require.NoError(t, err)
+	require.InDelta(t, 100.0, report.Summary.Current, 0.001)
+	require.InDelta(t, 600.0, report.Summary.Bucket30, 0.001)
+	require.InDelta(t, 250.0, report.Summary.Bucket90, 0.001)
+	require.InDelta(t, 950.0, report.Summary.Total(), 0.001)
+
+	require.Len(t, report.Customers, 2)
+	require.Equal(t, int64(200), report.Customers[0].CustomerID)
+	require.Equal(t, "Customer", report.Customers[0].CustomerName)
+	require.InDelta(t, 600.0, report.Customers[0].Total, 0.001)
+	require.Equal(t, int64(100), report.Customers[1].CustomerID)
+	require.InDelta(t, 100.0, report.Customers[1].Current, 0.001)
+	require.InDelta(t, 250.0, report.Customers[1].Bucket90, 0.001)
+	require.InDelta(t, 350.0, report.Customers[1].Total, 0.001)
+}
+
+func TestCreateCreditNoteAppliesToSalesOrderInvoice(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryARRepo()
+	svc := NewService(repo)
+
+	inv, err := svc.CreateARInvoice(ctx, CreateARInvoiceInput{CustomerID: 100, SOID: 7, Number: "INV-010", Total: 1000})
+	require.NoError(t, err)
+	require.NoError(t, svc.PostARInvoice(ctx, PostARInvoiceInput{InvoiceID: inv.ID, PostedBy: 1}))
+
+	note, err := svc.CreateCreditNote(ctx, CreateARCreditNoteInput{CustomerID: 100, SOID: 7, Total: 300, Reason: "Return"})
+	require.NoError(t, err)
+	require.Equal(t, inv.ID, note.ARInvoiceID)
+	require.Equal(t, "CN-TEST-1", note.Number)
+
+	_, _, balance, _ := repo.GetInvoiceBalance(ctx, inv.ID)
+	require.Equal(t, 700.0, balance)
+
+	// Larger than what is left open: kept on account instead.
+	onAccount, err := svc.CreateCreditNote(ctx, CreateARCreditNoteInput{CustomerID: 100, SOID: 7, Total: 800})
+	require.NoError(t, err)
+	require.Zero(t, onAccount.ARInvoiceID)
+
+	_, err = svc.CreateCreditNote(ctx, CreateARCreditNoteInput{CustomerID: 100, ARInvoiceID: inv.ID, Total: 800})
+	require.ErrorIs(t, err, ErrCreditExceedsBalance)
+}
+
+func TestCreateCreditNoteForSalesReturnJournalsOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryARRepo()
+	svc := NewService(repo)
+	acct := &recordingAccounting{}
+	svc.SetAccountingService(acct)
+
+	input := CreateARCreditNoteInput{CustomerID: 100, SalesReturnID: 9, Total: 250, Reason: "Return RMA-9"}
+	note, err := svc.CreateCreditNote(ctx, input)
+	require.NoError(t, err)
+	again, err := svc.CreateCreditNote(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, note.ID, again.ID)
+	require.Len(t, repo.creditNotes, 1)
+	// Raising it again retries the journal; the ledger ignores the repeat.
+	require.Equal(t, []string{note.Number, note.Number}, acct.credits)
+}
@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kind identifies the type of an archived document.
+type Kind string
+
+const (
+	// KindSalesOrder is a completed or cancelled sales order.
+	KindSalesOrder Kind = "SALES_ORDER"
+	// KindARInvoice is a paid or voided AR invoice.
+	KindARInvoice Kind = "AR_INVOICE"
+)
+
+// Kinds lists the archivable document kinds in the order a run processes them.
+var Kinds = []Kind{KindSalesOrder, KindARInvoice}
+
+// Defaults used when the configuration leaves a value unset.
+const (
+	DefaultRetentionDays = 730
+	DefaultBatchSize     = 500
+)
+
+var (
+	// ErrNotArchived is returned when restoring a document that is not archived.
+	ErrNotArchived = errors.New("archive: document is not archived")
+	// ErrRunNotFound is returned when an archive run does not exist.
+	ErrRunNotFound = errors.New("archive: run not found")
+	// ErrNothingToRestore is returned when every document of a run is restored already.
+	ErrNothingToRestore = errors.New("archive: run has no archived documents left")
+)
+
+// Config controls the archival run.
+type Config struct {
+	// RetentionDays is how long a closed document stays in the lists after
+	// its last change.
+	RetentionDays int
+	// BatchSize caps the documents of one kind archived per transaction.
+	BatchSize int
+}
+
+// Run records one execution of the archival job.
+type Run struct {
+	ID            int64
+	RetentionDays int
+	Cutoff        time.Time
+	StartedAt     time.Time
+	FinishedAt    *time.Time
+	Archived      int
+	Error         string
+}
+
+// Document is a registry entry for an archived document. RestoredAt is set
+// once the document is back in the lists.
+type Document struct {
+	ID         int64
+	RunID      int64
+	Kind       Kind
+	DocumentID int64
+	DocNumber  string
+	ArchivedAt time.Time
+	RestoredAt *time.Time
+	RestoredBy *int64
+}
+
+// Link returns the detail page of the document.
+func (d Document) Link() string {
+	switch d.Kind {
+	case KindSalesOrder:
+		return fmt.Sprintf("/sales/orders/%d", d.DocumentID)
+	case KindARInvoice:
+		return fmt.Sprintf("/finance/ar/invoices/%d", d.DocumentID)
+	}
+	return ""
+}
+
+// DocumentFilter narrows the registry listing.
+type DocumentFilter struct {
+	Kind Kind
+	// RunID limits the list to one run when set.
+	RunID int64
+	// Number matches the document number prefix.
+	Number string
+	// IncludeRestored also lists documents that were restored.
+	IncludeRestored bool
+	Limit           int
+}
@@ -0,0 +1,163 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+const (
+	listRunsLimit      = 20
+	listDocumentsLimit = 200
+)
+
+// Handler wires the archive SSR endpoints.
+type Handler struct {
+	logger    *slog.Logger
+	service   *Service
+	templates *view.Engine
+	csrf      *shared.CSRFManager
+	rbac      rbac.Middleware
+}
+
+// NewHandler constructs handler.
+func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, csrf *shared.CSRFManager, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, rbac: rbac}
+}
+
+// MountRoutes registers routes.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Route("/archive", func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermArchiveManage))
+		r.Get("/", h.list)
+		r.Post("/documents/{id}/restore", h.restoreDocument)
+		r.Post("/runs/{id}/restore", h.restoreRun)
+	})
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := DocumentFilter{
+		Kind:            Kind(strings.ToUpper(strings.TrimSpace(q.Get("kind")))),
+		RunID:           parseInt64(q.Get("run")),
+		Number:          strings.TrimSpace(q.Get("number")),
+		IncludeRestored: q.Get("include_restored") == "1",
+		Limit:           listDocumentsLimit,
+	}
+	runs, err := h.service.ListRuns(r.Context(), listRunsLimit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list archive runs", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	documents, err := h.service.ListDocuments(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list archived documents", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/archive/index.html", "Document Archive", map[string]any{
+		"Runs":          runs,
+		"Documents":     documents,
+		"Filter":        filter,
+		"Kinds":         Kinds,
+		"RetentionDays": h.service.RetentionDays(),
+		"Limit":         listDocumentsLimit,
+		"Back":          r.URL.RequestURI(),
+	}, http.StatusOK)
+}
+
+func (h *Handler) restoreDocument(w http.ResponseWriter, r *http.Request) {
+	id := parseInt64(chi.URLParam(r, "id"))
+	back := backTo(r)
+	if err := h.service.RestoreDocument(r.Context(), id, currentUser(r)); err != nil {
+		h.redirectWithFlash(w, r, back, "danger", errorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, back, "success", "Document restored to the lists")
+}
+
+func (h *Handler) restoreRun(w http.ResponseWriter, r *http.Request) {
+	id := parseInt64(chi.URLParam(r, "id"))
+	back := backTo(r)
+	restored, err := h.service.RestoreRun(r.Context(), id, currentUser(r))
+	if err != nil {
+		h.redirectWithFlash(w, r, back, "danger", errorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, back, "success", fmt.Sprintf("%d documents restored to the lists", restored))
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, tpl, title string, data any, status int) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{
+		Title:       title,
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data:        data,
+	}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, tpl, viewData); err != nil && h.logger != nil {
+		h.logger.ErrorContext(r.Context(), "render archive template", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) redirectWithFlash(w http.ResponseWriter, r *http.Request, location, kind, message string) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, location, http.StatusSeeOther)
+}
+
+// backTo returns to the filtered list the form was posted from.
+func backTo(r *http.Request) string {
+	if err := r.ParseForm(); err == nil {
+		if back := r.PostForm.Get("back"); strings.HasPrefix(back, "/archive") {
+			return back
+		}
+	}
+	return "/archive"
+}
+
+func errorMessage(err error) string {
+	known := []error{ErrNotArchived, ErrRunNotFound, ErrNothingToRestore}
+	for _, k := range known {
+		if errors.Is(err, k) {
+			return err.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
+
+func parseInt64(value string) int64 {
+	v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func currentUser(r *http.Request) int64 {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil {
+		return 0
+	}
+	v, _ := strconv.ParseInt(sess.User(), 10, 64)
+	return v
+}
@@ -0,0 +1,41 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+)
+
+// Job runs the scheduled archival on the worker.
+type Job struct {
+	service *Service
+	logger  *slog.Logger
+}
+
+// NewJob constructs a Job handler.
+func NewJob(service *Service, logger *slog.Logger) *Job {
+	return &Job{service: service, logger: logger}
+}
+
+// Handle fulfils the asynq.HandlerFunc contract for jobs.TaskDocumentArchive.
+// Batches committed before a failure stay archived; the rest are picked up
+// by the next run.
+func (j *Job) Handle(ctx context.Context, _ *asynq.Task) error {
+	if j == nil || j.service == nil {
+		return fmt.Errorf("document archive job not configured")
+	}
+	run, err := j.service.Run(ctx)
+	if err != nil {
+		if j.logger != nil {
+			j.logger.ErrorContext(ctx, "document archive failed", slog.Any("error", err), slog.Int64("run_id", run.ID), slog.Int("archived", run.Archived))
+		}
+		return err
+	}
+	if j.logger != nil {
+		j.logger.InfoContext(ctx, "document archive completed", slog.Int64("run_id", run.ID), slog.Int("archived", run.Archived),
+			slog.Time("cutoff", run.Cutoff))
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
+)
+
+// eligibleSQL selects, per kind, the closed documents a run may archive:
+// last changed before the cutoff ($1), never archived before (restored
+// documents stay out) and not referenced by a document that is still open.
+var eligibleSQL = map[Kind]string{
+	KindSalesOrder: `
+SELECT so.id, so.doc_number
+FROM sales_orders so
+WHERE so.archived_at IS NULL
+  AND so.status IN ('COMPLETED', 'CANCELLED')
+  AND so.updated_at < $1
+  AND NOT EXISTS (SELECT 1 FROM archived_documents ad WHERE ad.kind = 'SALES_ORDER' AND ad.document_id = so.id)
+  AND NOT EXISTS (SELECT 1 FROM delivery_orders d WHERE d.sales_order_id = so.id AND d.status NOT IN ('DELIVERED', 'CANCELLED'))
+  AND NOT EXISTS (SELECT 1 FROM delivery_backorders b WHERE b.sales_order_id = so.id AND b.status = 'OPEN')
+  AND NOT EXISTS (SELECT 1 FROM ar_invoices i WHERE i.so_id = so.id AND i.status IN ('DRAFT', 'POSTED'))
+  AND NOT EXISTS (SELECT 1 FROM sales_returns sr WHERE sr.sales_order_id = so.id AND sr.status <> 'CREDITED')
+  AND NOT EXISTS (SELECT 1 FROM sales_order_changes c WHERE c.sales_order_id = so.id AND c.status = 'PENDING')
+ORDER BY so.id
+LIMIT $2
+FOR UPDATE OF so SKIP LOCKED`,
+	KindARInvoice: `
+SELECT i.id, i.number
+FROM ar_invoices i
+WHERE i.archived_at IS NULL
+  AND i.status IN ('PAID', 'VOID')
+  AND i.updated_at < $1
+  AND NOT EXISTS (SELECT 1 FROM archived_documents ad WHERE ad.kind = 'AR_INVOICE' AND ad.document_id = i.id)
+  AND NOT EXISTS (SELECT 1 FROM customer_credit_hold_invoices hi
+                  JOIN customer_credit_holds h ON h.id = hi.hold_id
+                  WHERE hi.ar_invoice_id = i.id AND h.released_at IS NULL)
+  AND NOT EXISTS (SELECT 1 FROM ar_invoice_emails e WHERE e.ar_invoice_id = i.id AND e.status = 'QUEUED')
+  AND NOT EXISTS (SELECT 1 FROM sales_orders so WHERE so.id = i.so_id AND so.status NOT IN ('COMPLETED', 'CANCELLED'))
+ORDER BY i.id
+LIMIT $2
+FOR UPDATE OF i SKIP LOCKED`,
+}
+
+// documentTables maps each kind to the table holding its documents.
+var documentTables = map[Kind]string{
+	KindSalesOrder: "sales_orders",
+	KindARInvoice:  "ar_invoices",
+}
+
+// restoreSQL clears archived_at on the documents of the registry entries
+// matched by the filter and marks those entries restored. $1 is the filter
+// value, $2 the restoring user.
+const restoreSQL = `
+WITH restored AS (
+    UPDATE archived_documents SET restored_at = NOW(), restored_by = NULLIF($2::bigint, 0)
+    WHERE %s = $1 AND restored_at IS NULL
+    RETURNING kind, document_id
+), orders AS (
+    UPDATE sales_orders SET archived_at = NULL
+    WHERE id IN (SELECT document_id FROM restored WHERE kind = 'SALES_ORDER')
+), invoices AS (
+    UPDATE ar_invoices SET archived_at = NULL
+    WHERE id IN (SELECT document_id FROM restored WHERE kind = 'AR_INVOICE')
+)
+SELECT COUNT(*) FROM restored`
+
+const runColumns = `
+SELECT id, retention_days, cutoff, started_at, finished_at, archived, error
+FROM archive_runs`
+
+// Repository persists archive data in Postgres.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs the repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// WithTx runs fn inside a transaction.
+func (r *Repository) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
+	return db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		return fn(ctx, &txRepository{tx: tx})
+	})
+}
+
+// CreateRun records the start of a run.
+func (r *Repository) CreateRun(ctx context.Context, retentionDays int, cutoff time.Time) (Run, error) {
+	run := Run{RetentionDays: retentionDays, Cutoff: cutoff}
+	err := r.pool.QueryRow(ctx, `INSERT INTO archive_runs (retention_days, cutoff) VALUES ($1, $2) RETURNING id, started_at`,
+		retentionDays, cutoff).Scan(&run.ID, &run.StartedAt)
+	return run, err
+}
+
+// FinishRun records the outcome of a run.
+func (r *Repository) FinishRun(ctx context.Context, id int64, archived int, runErr string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE archive_runs SET finished_at = NOW(), archived = $2, error = $3 WHERE id = $1`,
+		id, archived, runErr)
+	return err
+}
+
+// GetRun returns a run, or nil when it does not exist.
+func (r *Repository) GetRun(ctx context.Context, id int64) (*Run, error) {
+	var run Run
+	err := scanRun(r.pool.QueryRow(ctx, runColumns+` WHERE id = $1`, id), &run)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListRuns returns the latest runs first.
+func (r *Repository) ListRuns(ctx context.Context, limit int) ([]Run, error) {
+	rows, err := r.pool.Query(ctx, runColumns+` ORDER BY id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Run
+	for rows.Next() {
+		var run Run
+		if err := scanRun(rows, &run); err != nil {
+			return nil, err
+		}
+		out = append(out, run)
+	}
+	return out, rows.Err()
+}
+
+// ListDocuments returns registry entries, latest archived first.
+func (r *Repository) ListDocuments(ctx context.Context, filter DocumentFilter) ([]Document, error) {
+	query := `
+SELECT id, run_id, kind, document_id, doc_number, archived_at, restored_at, restored_by
+FROM archived_documents
+WHERE ($1::text = '' OR kind = $1)
+  AND ($2::bigint = 0 OR run_id = $2)
+  AND ($3::text = '' OR doc_number ILIKE $3 || '%')
+  AND ($4::boolean OR restored_at IS NULL)
+ORDER BY archived_at DESC, id DESC
+LIMIT $5`
+	rows, err := r.pool.Query(ctx, query, string(filter.Kind), filter.RunID, filter.Number, filter.IncludeRestored, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Document
+	for rows.Next() {
+		var doc Document
+		var kind string
+		if err := rows.Scan(&doc.ID, &doc.RunID, &kind, &doc.DocumentID, &doc.DocNumber,
+			&doc.ArchivedAt, &doc.RestoredAt, &doc.RestoredBy); err != nil {
+			return nil, err
+		}
+		doc.Kind = Kind(kind)
+		out = append(out, doc)
+	}
+	return out, rows.Err()
+}
+
+type txRepository struct {
+	tx pgx.Tx
+}
+
+// ArchiveBatch locks a batch of eligible documents, stamps archived_at and
+// registers them under the run.
+func (r *txRepository) ArchiveBatch(ctx context.Context, kind Kind, runID int64, cutoff time.Time, limit int) (int, error) {
+	query, ok := eligibleSQL[kind]
+	if !ok {
+		return 0, fmt.Errorf("archive: unknown kind %q", kind)
+	}
+	rows, err := r.tx.Query(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	var numbers []string
+	for rows.Next() {
+		var id int64
+		var number string
+		if err := rows.Scan(&id, &number); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+		numbers = append(numbers, number)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var archivedAt time.Time
+	if err := r.tx.QueryRow(ctx, `SELECT NOW()`).Scan(&archivedAt); err != nil {
+		return 0, err
+	}
+	if _, err := r.tx.Exec(ctx, fmt.Sprintf(`UPDATE %s SET archived_at = $2 WHERE id = ANY($1)`, documentTables[kind]),
+		ids, archivedAt); err != nil {
+		return 0, err
+	}
+	if _, err := r.tx.Exec(ctx, `
+INSERT INTO archived_documents (run_id, kind, document_id, doc_number, archived_at)
+SELECT $1, $2, d.id, d.number, $5
+FROM unnest($3::bigint[], $4::text[]) AS d(id, number)`, runID, string(kind), ids, numbers, archivedAt); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// RestoreDocument restores the document of one registry entry.
+func (r *txRepository) RestoreDocument(ctx context.Context, id, actorID int64) (bool, error) {
+	var restored int
+	if err := r.tx.QueryRow(ctx, fmt.Sprintf(restoreSQL, "id"), id, actorID).Scan(&restored); err != nil {
+		return false, err
+	}
+	return restored > 0, nil
+}
+
+// RestoreRun restores every document of a run still archived.
+func (r *txRepository) RestoreRun(ctx context.Context, runID, actorID int64) (int, error) {
+	var restored int
+	err := r.tx.QueryRow(ctx, fmt.Sprintf(restoreSQL, "run_id"), runID, actorID).Scan(&restored)
+	return restored, err
+}
+
+func scanRun(row pgx.Row, run *Run) error {
+	return row.Scan(&run.ID, &run.RetentionDays, &run.Cutoff, &run.StartedAt, &run.FinishedAt, &run.Archived, &run.Error)
+}
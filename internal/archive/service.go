@@ -0,0 +1,145 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RepositoryPort persists archive runs and the document registry.
+type RepositoryPort interface {
+	CreateRun(ctx context.Context, retentionDays int, cutoff time.Time) (Run, error)
+	FinishRun(ctx context.Context, id int64, archived int, runErr string) error
+	GetRun(ctx context.Context, id int64) (*Run, error)
+	ListRuns(ctx context.Context, limit int) ([]Run, error)
+	ListDocuments(ctx context.Context, filter DocumentFilter) ([]Document, error)
+	WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error
+}
+
+// TxRepository is the transactional part of archiving and restoring.
+type TxRepository interface {
+	// ArchiveBatch archives up to limit eligible documents of one kind last
+	// changed before cutoff, registers them under the run and returns how
+	// many it archived.
+	ArchiveBatch(ctx context.Context, kind Kind, runID int64, cutoff time.Time, limit int) (int, error)
+	// RestoreDocument brings one registered document back into the lists.
+	// It returns false when the entry is unknown or already restored.
+	RestoreDocument(ctx context.Context, id, actorID int64) (bool, error)
+	// RestoreRun brings every document of a run still archived back into the
+	// lists and returns how many it restored.
+	RestoreRun(ctx context.Context, runID, actorID int64) (int, error)
+}
+
+// Service archives closed documents past the retention window and restores
+// them on request.
+type Service struct {
+	repo RepositoryPort
+	cfg  Config
+	now  func() time.Time
+}
+
+// NewService builds the service, applying defaults to unset configuration.
+func NewService(repo RepositoryPort, cfg Config) *Service {
+	if cfg.RetentionDays <= 0 {
+		cfg.RetentionDays = DefaultRetentionDays
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	return &Service{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// RetentionDays returns the configured retention window.
+func (s *Service) RetentionDays() int {
+	return s.cfg.RetentionDays
+}
+
+// Run archives every eligible document, one batch per transaction, so a
+// failure keeps the batches committed before it. The run is recorded with
+// its count and error either way.
+func (s *Service) Run(ctx context.Context) (Run, error) {
+	cutoff := s.now().AddDate(0, 0, -s.cfg.RetentionDays)
+	run, err := s.repo.CreateRun(ctx, s.cfg.RetentionDays, cutoff)
+	if err != nil {
+		return Run{}, err
+	}
+	runErr := s.archiveAll(ctx, &run)
+	var message string
+	if runErr != nil {
+		message = runErr.Error()
+	}
+	if err := s.repo.FinishRun(ctx, run.ID, run.Archived, message); err != nil && runErr == nil {
+		runErr = err
+	}
+	return run, runErr
+}
+
+func (s *Service) archiveAll(ctx context.Context, run *Run) error {
+	for _, kind := range Kinds {
+		for {
+			var archived int
+			err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+				var err error
+				archived, err = tx.ArchiveBatch(ctx, kind, run.ID, run.Cutoff, s.cfg.BatchSize)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("archive %s: %w", kind, err)
+			}
+			run.Archived += archived
+			if archived < s.cfg.BatchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ListRuns returns the most recent runs first.
+func (s *Service) ListRuns(ctx context.Context, limit int) ([]Run, error) {
+	return s.repo.ListRuns(ctx, limit)
+}
+
+// ListDocuments returns registry entries, most recently archived first.
+func (s *Service) ListDocuments(ctx context.Context, filter DocumentFilter) ([]Document, error) {
+	return s.repo.ListDocuments(ctx, filter)
+}
+
+// RestoreDocument puts one archived document back into the lists. A
+// restored document is never archived again by a later run.
+func (s *Service) RestoreDocument(ctx context.Context, id, actorID int64) error {
+	return s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		restored, err := tx.RestoreDocument(ctx, id, actorID)
+		if err != nil {
+			return err
+		}
+		if !restored {
+			return ErrNotArchived
+		}
+		return nil
+	})
+}
+
+// RestoreRun puts every document a run archived back into the lists.
+func (s *Service) RestoreRun(ctx context.Context, runID, actorID int64) (int, error) {
+	run, err := s.repo.GetRun(ctx, runID)
+	if err != nil {
+		return 0, err
+	}
+	if run == nil {
+		return 0, ErrRunNotFound
+	}
+	var restored int
+	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		n, err := tx.RestoreRun(ctx, runID, actorID)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNothingToRestore
+		}
+		restored = n
+		return nil
+	})
+	return restored, err
+}
@@ -0,0 +1,136 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepo struct {
+	eligible map[Kind]int
+	failKind Kind
+	runs     []Run
+	docs     []Document
+	batches  []int
+}
+
+func (r *memoryRepo) CreateRun(_ context.Context, retentionDays int, cutoff time.Time) (Run, error) {
+	run := Run{ID: int64(len(r.runs) + 1), RetentionDays: retentionDays, Cutoff: cutoff}
+	r.runs = append(r.runs, run)
+	return run, nil
+}
+
+func (r *memoryRepo) FinishRun(_ context.Context, id int64, archived int, runErr string) error {
+	now := time.Now()
+	run := &r.runs[id-1]
+	run.FinishedAt, run.Archived, run.Error = &now, archived, runErr
+	return nil
+}
+
+func (r *memoryRepo) GetRun(_ context.Context, id int64) (*Run, error) {
+	if id < 1 || int(id) > len(r.runs) {
+		return nil, nil
+	}
+	return &r.runs[id-1], nil
+}
+
+func (r *memoryRepo) ListRuns(context.Context, int) ([]Run, error) { return r.runs, nil }
+
+func (r *memoryRepo) ListDocuments(context.Context, DocumentFilter) ([]Document, error) {
+	return r.docs, nil
+}
+
+func (r *memoryRepo) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
+	return fn(ctx, r)
+}
+
+func (r *memoryRepo) ArchiveBatch(_ context.Context, kind Kind, runID int64, _ time.Time, limit int) (int, error) {
+	if kind == r.failKind {
+		return 0, errors.New("boom")
+	}
+	n := min(r.eligible[kind], limit)
+	r.eligible[kind] -= n
+	for i := 0; i < n; i++ {
+		r.docs = append(r.docs, Document{ID: int64(len(r.docs) + 1), RunID: runID, Kind: kind})
+	}
+	r.batches = append(r.batches, n)
+	return n, nil
+}
+
+func (r *memoryRepo) RestoreDocument(_ context.Context, id, actorID int64) (bool, error) {
+	for i := range r.docs {
+		if r.docs[i].ID == id && r.docs[i].RestoredAt == nil {
+			now := time.Now()
+			r.docs[i].RestoredAt, r.docs[i].RestoredBy = &now, &actorID
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryRepo) RestoreRun(ctx context.Context, runID, actorID int64) (int, error) {
+	var restored int
+	for _, doc := range r.docs {
+		if doc.RunID != runID {
+			continue
+		}
+		if ok, _ := r.RestoreDocument(ctx, doc.ID, actorID); ok {
+			restored++
+		}
+	}
+	return restored, nil
+}
+
+func TestRunArchivesInBatchesUntilExhausted(t *testing.T) {
+	repo := &memoryRepo{eligible: map[Kind]int{KindSalesOrder: 5, KindARInvoice: 2}}
+	svc := NewService(repo, Config{RetentionDays: 30, BatchSize: 2})
+	now := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	run, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 7, run.Archived)
+	require.Equal(t, now.AddDate(0, 0, -30), run.Cutoff)
+	// Five orders take three batches; two invoices fill a batch, so one more
+	// empty batch confirms nothing is left.
+	require.Equal(t, []int{2, 2, 1, 2, 0}, repo.batches)
+	require.NotNil(t, repo.runs[0].FinishedAt)
+	require.Equal(t, 7, repo.runs[0].Archived)
+	require.Empty(t, repo.runs[0].Error)
+}
+
+func TestRunRecordsFailureWithPartialCount(t *testing.T) {
+	repo := &memoryRepo{eligible: map[Kind]int{KindSalesOrder: 3}, failKind: KindARInvoice}
+	svc := NewService(repo, Config{BatchSize: 10})
+
+	run, err := svc.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 3, run.Archived)
+	require.Equal(t, 3, repo.runs[0].Archived)
+	require.Contains(t, repo.runs[0].Error, "AR_INVOICE")
+	require.Equal(t, DefaultRetentionDays, repo.runs[0].RetentionDays)
+}
+
+func TestRestoreDocumentAndRun(t *testing.T) {
+	repo := &memoryRepo{eligible: map[Kind]int{KindSalesOrder: 2, KindARInvoice: 1}}
+	svc := NewService(repo, Config{})
+	ctx := context.Background()
+	_, err := svc.Run(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RestoreDocument(ctx, 1, 9))
+	require.ErrorIs(t, svc.RestoreDocument(ctx, 1, 9), ErrNotArchived)
+	require.Equal(t, int64(9), *repo.docs[0].RestoredBy)
+
+	restored, err := svc.RestoreRun(ctx, 1, 9)
+	require.NoError(t, err)
+	require.Equal(t, 2, restored)
+
+	_, err = svc.RestoreRun(ctx, 1, 9)
+	require.ErrorIs(t, err, ErrNothingToRestore)
+	_, err = svc.RestoreRun(ctx, 42, 9)
+	require.ErrorIs(t, err, ErrRunNotFound)
+}
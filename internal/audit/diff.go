@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// ChangeKind menandai jenis perubahan satu field.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeUpdated ChangeKind = "changed"
+)
+
+// FieldChange mewakili perbedaan satu field antara snapshot before dan after.
+type FieldChange struct {
+	Field  string
+	Kind   ChangeKind
+	Before string
+	After  string
+}
+
+// DiffSnapshots membandingkan dua snapshot JSON field demi field. Objek
+// bersarang diratakan dengan path bertitik, sedangkan array dibandingkan
+// utuh. Hasil diurutkan berdasarkan nama field.
+func DiffSnapshots(before, after []byte) ([]FieldChange, error) {
+	left, err := flattenSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	right, err := flattenSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+	var changes []FieldChange
+	for field, oldValue := range left {
+		newValue, ok := right[field]
+		switch {
+		case !ok:
+			changes = append(changes, FieldChange{Field: field, Kind: ChangeRemoved, Before: oldValue})
+		case oldValue != newValue:
+			changes = append(changes, FieldChange{Field: field, Kind: ChangeUpdated, Before: oldValue, After: newValue})
+		}
+	}
+	for field, newValue := range right {
+		if _, ok := left[field]; !ok {
+			changes = append(changes, FieldChange{Field: field, Kind: ChangeAdded, After: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func flattenSnapshot(raw []byte) (map[string]string, error) {
+	out := make(map[string]string)
+	if len(raw) == 0 {
+		return out, nil
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	flattenInto(out, "", decoded)
+	return out, nil
+}
+
+func flattenInto(out map[string]string, prefix string, fields map[string]any) {
+	for key, value := range fields {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenInto(out, path, nested)
+			continue
+		}
+		out[path] = formatSnapshotValue(value)
+	}
+}
+
+func formatSnapshotValue(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	default:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(encoded))
+	}
+}
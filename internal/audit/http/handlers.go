@@ -10,7 +10,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/odyssey-erp/odyssey-erp/internal/audit"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 )
@@ -26,6 +29,7 @@ const (
 type TimelineService interface {
 	Timeline(ctx context.Context, filters audit.TimelineFilters) (audit.Result, error)
 	Export(ctx context.Context, filters audit.TimelineFilters) ([]audit.TimelineRow, error)
+	Detail(ctx context.Context, id int64) (audit.Detail, error)
 }
 
 // Exporter writes audit timeline exports.
@@ -46,6 +50,7 @@ type Handler struct {
 	exporter  Exporter
 	templates *view.Engine
 	rbac      RBACService
+	sequences SequenceService
 	now       func() time.Time
 }
 
@@ -66,25 +71,25 @@ func NewHandler(logger *slog.Logger, service TimelineService, templates *view.En
 
 func (h *Handler) handleTimeline(w http.ResponseWriter, r *http.Request) {
 	if h.templates == nil || h.service == nil {
-		http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		httpx.Error(w, r, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
 		return
 	}
 
 	sess := shared.SessionFromContext(r.Context())
 	if err := h.authorize(r.Context(), sess, shared.PermFinanceAuditView); err != nil {
-		h.respondAuthError(w, err)
+		h.respondAuthError(w, r, err)
 		return
 	}
 
 	filters, err := h.parseFilters(r)
 	if err != nil {
-		h.handleFilterError(w, err)
+		h.handleFilterError(w, r, err)
 		return
 	}
 
 	result, err := h.service.Timeline(r.Context(), filters)
 	if err != nil {
-		h.handleServerError(w, "load audit timeline", err)
+		h.handleServerError(w, r, "load audit timeline", err)
 		return
 	}
 
@@ -100,76 +105,110 @@ func (h *Handler) handleTimeline(w http.ResponseWriter, r *http.Request) {
 		Data:        vm,
 	}
 	if err := h.templates.Render(w, "pages/finance/audit_timeline.html", data); err != nil {
-		h.handleServerError(w, "render audit timeline", err)
+		h.handleServerError(w, r, "render audit timeline", err)
+	}
+}
+
+func (h *Handler) handleDetail(w http.ResponseWriter, r *http.Request) {
+	if h.templates == nil || h.service == nil {
+		httpx.Error(w, r, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		return
+	}
+	sess := shared.SessionFromContext(r.Context())
+	if err := h.authorize(r.Context(), sess, shared.PermFinanceAuditView); err != nil {
+		h.respondAuthError(w, r, err)
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	detail, err := h.service.Detail(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, audit.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.handleServerError(w, r, "load audit detail", err)
+		return
+	}
+	data := view.TemplateData{
+		Title:       "Audit Detail",
+		CurrentPath: r.URL.Path,
+		Data:        detail,
+	}
+	if err := h.templates.Render(w, "pages/finance/audit_detail.html", data); err != nil {
+		h.handleServerError(w, r, "render audit detail", err)
 	}
 }
 
 func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
 	if h.exporter == nil || h.service == nil {
-		http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		httpx.Error(w, r, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
 		return
 	}
 	sess := shared.SessionFromContext(r.Context())
 	if err := h.authorize(r.Context(), sess, shared.PermFinanceInsightsExport); err != nil {
-		h.respondAuthError(w, err)
+		h.respondAuthError(w, r, err)
 		return
 	}
 	filters, err := h.parseFilters(r)
 	if err != nil {
-		h.handleFilterError(w, err)
+		h.handleFilterError(w, r, err)
 		return
 	}
 	rows, err := h.service.Export(r.Context(), filters)
 	if err != nil {
-		h.handleServerError(w, "export audit timeline", err)
+		h.handleServerError(w, r, "export audit timeline", err)
 		return
 	}
 	csvBytes, err := h.exporter.WriteCSV(rows)
 	if err != nil {
-		h.handleServerError(w, "encode csv", err)
+		h.handleServerError(w, r, "encode csv", err)
 		return
 	}
 	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
 	w.Header().Set("Content-Disposition", "attachment; filename=\"audit-timeline.csv\"")
 	if _, err := w.Write(csvBytes); err != nil {
-		h.logger.Warn("write csv", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "write csv", slog.Any("error", err))
 	}
 }
 
 func (h *Handler) handlePDF(w http.ResponseWriter, r *http.Request) {
 	if h.exporter == nil || h.service == nil {
-		http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		httpx.Error(w, r, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
 		return
 	}
 	sess := shared.SessionFromContext(r.Context())
 	if err := h.authorize(r.Context(), sess, shared.PermFinanceInsightsExport); err != nil {
-		h.respondAuthError(w, err)
+		h.respondAuthError(w, r, err)
 		return
 	}
 	filters, err := h.parseFilters(r)
 	if err != nil {
-		h.handleFilterError(w, err)
+		h.handleFilterError(w, r, err)
 		return
 	}
 	rows, err := h.service.Export(r.Context(), filters)
 	if err != nil {
-		h.handleServerError(w, "export pdf data", err)
+		h.handleServerError(w, r, "export pdf data", err)
 		return
 	}
 	vm := h.buildViewModel(filters, audit.Result{Rows: rows, Paging: audit.PagingInfo{Page: 1, PageSize: len(rows)}})
 	pdfBytes, err := h.exporter.RenderPDF(r.Context(), vm)
 	if err != nil {
 		if errors.Is(err, audit.ErrPDFUnavailable) {
-			http.Error(w, "PDF export belum tersedia", http.StatusNotImplemented)
+			httpx.Error(w, r, "PDF export belum tersedia", http.StatusNotImplemented)
 			return
 		}
-		h.handleServerError(w, "render pdf", err)
+		h.handleServerError(w, r, "render pdf", err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/pdf")
 	w.Header().Set("Content-Disposition", "attachment; filename=\"audit-timeline.pdf\"")
 	if _, err := w.Write(pdfBytes); err != nil {
-		h.logger.Warn("write pdf", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "write pdf", slog.Any("error", err))
 	}
 }
 
@@ -224,6 +263,7 @@ func (h *Handler) parseFilters(r *http.Request) (audit.TimelineFilters, error) {
 		Actor:    strings.TrimSpace(r.URL.Query().Get("actor")),
 		Entity:   strings.TrimSpace(r.URL.Query().Get("entity")),
 		Action:   strings.TrimSpace(r.URL.Query().Get("action")),
+		Security: r.URL.Query().Get("security") == "1",
 		Page:     page,
 		PageSize: pageSize,
 	}, nil
@@ -234,11 +274,12 @@ func (h *Handler) buildViewModel(filters audit.TimelineFilters, result audit.Res
 	copy(rows, result.Rows)
 	return audit.ViewModel{
 		Filters: audit.FiltersViewModel{
-			From:   filters.From,
-			To:     filters.To,
-			Actor:  filters.Actor,
-			Entity: filters.Entity,
-			Action: filters.Action,
+			From:     filters.From,
+			To:       filters.To,
+			Actor:    filters.Actor,
+			Entity:   filters.Entity,
+			Action:   filters.Action,
+			Security: filters.Security,
 		},
 		Rows:   rows,
 		Paging: result.Paging,
@@ -273,28 +314,28 @@ func (h *Handler) authorize(ctx context.Context, sess *shared.Session, perm stri
 	return errPermissionDenied
 }
 
-func (h *Handler) respondAuthError(w http.ResponseWriter, err error) {
+func (h *Handler) respondAuthError(w http.ResponseWriter, r *http.Request, err error) {
 	if errors.Is(err, errPermissionDenied) {
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		return
 	}
-	h.handleServerError(w, "authorize", err)
+	h.handleServerError(w, r, "authorize", err)
 }
 
-func (h *Handler) handleFilterError(w http.ResponseWriter, err error) {
+func (h *Handler) handleFilterError(w http.ResponseWriter, r *http.Request, err error) {
 	var v validationError
 	if errors.As(err, &v) {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
-	h.handleServerError(w, "validate filters", err)
+	h.handleServerError(w, r, "validate filters", err)
 }
 
-func (h *Handler) handleServerError(w http.ResponseWriter, message string, err error) {
+func (h *Handler) handleServerError(w http.ResponseWriter, r *http.Request, message string, err error) {
 	if h.logger != nil {
 		h.logger.Error(message, slog.Any("error", err))
 	}
-	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 }
 
 type validationError struct {
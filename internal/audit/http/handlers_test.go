@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/odyssey-erp/odyssey-erp/internal/audit"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -17,6 +19,7 @@ type stubTimelineService struct {
 	result      audit.Result
 	exportRows  []audit.TimelineRow
 	lastFilters audit.TimelineFilters
+	detail      audit.Detail
 }
 
 func (s *stubTimelineService) Timeline(ctx context.Context, filters audit.TimelineFilters) (audit.Result, error) {
@@ -29,6 +32,13 @@ func (s *stubTimelineService) Export(ctx context.Context, filters audit.Timeline
 	return s.exportRows, nil
 }
 
+func (s *stubTimelineService) Detail(ctx context.Context, id int64) (audit.Detail, error) {
+	if id != s.detail.ID {
+		return audit.Detail{}, audit.ErrNotFound
+	}
+	return s.detail, nil
+}
+
 type stubExporter struct {
 	csv []byte
 }
@@ -127,3 +137,84 @@ func TestPDFNotImplemented(t *testing.T) {
 		t.Fatalf("expected 501, got %d", rr.Code)
 	}
 }
+
+func TestDetailRendersFieldDiff(t *testing.T) {
+	service := &stubTimelineService{detail: audit.Detail{
+		ID:          42,
+		Action:      "product.update",
+		Entity:      "products",
+		EntityID:    "9",
+		HasSnapshot: true,
+		Changes:     []audit.FieldChange{{Field: "price", Kind: audit.ChangeUpdated, Before: "10", After: "12.5"}},
+	}}
+	handler := newAuditHandler(t, service, stubExporter{}, []string{shared.PermFinanceAuditView})
+	sess := &shared.Session{}
+	sess.SetUser("7")
+	router := chi.NewRouter()
+	router.Get("/audit/{id}", handler.handleDetail)
+
+	req := httptest.NewRequest(http.MethodGet, "/audit/42", nil)
+	req = req.WithContext(shared.ContextWithSession(req.Context(), sess))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "price") || !strings.Contains(body, "12.5") {
+		t.Fatalf("expected field diff in response: %s", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/audit/43", nil)
+	req = req.WithContext(shared.ContextWithSession(req.Context(), sess))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+type stubSequenceRepo struct {
+	numbers []audit.SequenceNumber
+}
+
+func (s stubSequenceRepo) SequenceNumbers(context.Context, audit.SequenceQuery) ([]audit.SequenceNumber, error) {
+	return s.numbers, nil
+}
+
+func (s stubSequenceRepo) SequenceCounters(context.Context, audit.SequenceQuery) ([]audit.SequenceCounter, error) {
+	return nil, nil
+}
+
+func TestSequencesRendersUnexplainedGap(t *testing.T) {
+	handler := newAuditHandler(t, &stubTimelineService{}, stubExporter{}, []string{shared.PermFinanceAuditView})
+	handler.SetSequenceService(audit.NewSequenceService(stubSequenceRepo{numbers: []audit.SequenceNumber{
+		{DocType: "INV", Series: "INV-2403", Seq: 1, Number: "INV-2403-00001", Status: "POSTED"},
+		{DocType: "INV", Series: "INV-2403", Seq: 2, Number: "INV-2403-00002", Status: "VOID", Voided: true},
+		{DocType: "INV", Series: "INV-2403", Seq: 5, Number: "INV-2403-00005", Status: "PAID"},
+	}}))
+	sess := &shared.Session{}
+	sess.SetUser("7")
+
+	req := httptest.NewRequest(http.MethodGet, "/audit/sequences?doc_type=inv", nil)
+	req = req.WithContext(shared.ContextWithSession(req.Context(), sess))
+	rr := httptest.NewRecorder()
+	handler.handleSequences(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	body := rr.Body.String()
+	for _, want := range []string{"INV-2403-00002 (VOID)", "INV-2403-00003", "INV-2403-00004", "Gap tidak terjelaskan", `value="2024-03"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in response: %s", want, body)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/audit/sequences?from=2024-04&to=2024-03", nil)
+	req = req.WithContext(shared.ContextWithSession(req.Context(), sess))
+	rr = httptest.NewRecorder()
+	handler.handleSequences(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for reversed range, got %d", rr.Code)
+	}
+}
@@ -8,13 +8,15 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httprate"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 const rateLimit = 10
 const rateWindow = time.Minute
 
-// MountRoutes mendaftarkan endpoint audit timeline dan ekspor CSV.
+// MountRoutes mendaftarkan endpoint audit timeline, detail entri, laporan
+// integritas nomor, dan ekspor CSV.
 func (h *Handler) MountRoutes(r chi.Router) {
 	if h == nil {
 		return
@@ -22,10 +24,12 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	limiter := httprate.Limit(rateLimit, rateWindow,
 		httprate.WithKeyFuncs(rateLimitKey),
 		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
-			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			httpx.Error(w, r, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
 		}),
 	)
 	r.Get("/audit", h.handleTimeline)
+	r.Get("/audit/{id:[0-9]+}", h.handleDetail)
+	r.Get("/audit/sequences", h.handleSequences)
 	r.Group(func(gr chi.Router) {
 		gr.Use(limiter)
 		gr.Get("/audit/export.csv", h.handleExport)
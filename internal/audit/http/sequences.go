@@ -0,0 +1,108 @@
+package audithttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/audit"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+const maxSequenceMonths = 12
+
+// SequenceService menyusun laporan integritas nomor dokumen.
+type SequenceService interface {
+	Report(ctx context.Context, filters audit.SequenceFilters) (audit.SequenceReport, error)
+	DocumentTypes() []audit.DocumentType
+}
+
+// SetSequenceService mengaktifkan laporan integritas nomor di /audit/sequences.
+func (h *Handler) SetSequenceService(service SequenceService) {
+	h.sequences = service
+}
+
+func (h *Handler) handleSequences(w http.ResponseWriter, r *http.Request) {
+	if h.templates == nil || h.sequences == nil {
+		httpx.Error(w, r, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		return
+	}
+	sess := shared.SessionFromContext(r.Context())
+	if err := h.authorize(r.Context(), sess, shared.PermFinanceAuditView); err != nil {
+		h.respondAuthError(w, r, err)
+		return
+	}
+	filters, err := h.parseSequenceFilters(r)
+	if err != nil {
+		h.handleFilterError(w, r, err)
+		return
+	}
+	report, err := h.sequences.Report(r.Context(), filters)
+	if err != nil {
+		if errors.Is(err, shared.ErrValidation) {
+			httpx.Error(w, r, shared.UserSafeMessage(err), http.StatusBadRequest)
+			return
+		}
+		h.handleServerError(w, r, "load sequence report", err)
+		return
+	}
+	data := view.TemplateData{
+		Title:       "Integritas Nomor Dokumen",
+		CurrentPath: r.URL.Path,
+		Data: map[string]any{
+			"Report": report,
+			"Types":  h.sequences.DocumentTypes(),
+		},
+	}
+	if err := h.templates.Render(w, "pages/finance/audit_sequences.html", data); err != nil {
+		h.handleServerError(w, r, "render sequence report", err)
+	}
+}
+
+// parseSequenceFilters membaca filter bulan (YYYY-MM), perusahaan, dan jenis
+// dokumen. Tanpa bulan, bulan berjalan yang dipakai.
+func (h *Handler) parseSequenceFilters(r *http.Request) (audit.SequenceFilters, error) {
+	q := r.URL.Query()
+	now := h.now().UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	parseMonth := func(field string, fallback time.Time) (time.Time, error) {
+		raw := strings.TrimSpace(q.Get(field))
+		if raw == "" {
+			return fallback, nil
+		}
+		t, err := time.Parse("2006-01", raw)
+		if err != nil {
+			return time.Time{}, validationError{field: field}
+		}
+		return t, nil
+	}
+	to, err := parseMonth("to", current)
+	if err != nil {
+		return audit.SequenceFilters{}, err
+	}
+	from, err := parseMonth("from", to)
+	if err != nil {
+		return audit.SequenceFilters{}, err
+	}
+	if from.After(to) || from.AddDate(0, maxSequenceMonths, 0).Before(to) {
+		return audit.SequenceFilters{}, validationError{field: "range"}
+	}
+	filters := audit.SequenceFilters{
+		DocType: strings.ToUpper(strings.TrimSpace(q.Get("doc_type"))),
+		From:    from,
+		To:      to,
+	}
+	if raw := strings.TrimSpace(q.Get("company_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			return audit.SequenceFilters{}, validationError{field: "company_id"}
+		}
+		filters.CompanyID = id
+	}
+	return filters, nil
+}
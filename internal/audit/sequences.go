@@ -0,0 +1,335 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// DocumentType mendeskripsikan satu jenis dokumen bernomor yang diperiksa
+// oleh laporan integritas nomor.
+type DocumentType struct {
+	// Code sama dengan doc_type pada view document_number_register.
+	Code string
+	Name string
+	// PerCompany false berarti satu seri nomor dipakai lintas perusahaan.
+	PerCompany bool
+	// StartsAt adalah nomor pertama tiap seri; 0 memakai nomor terkecil yang ada.
+	StartsAt int64
+	// Counter adalah doc_type di document_sequences yang menyimpan nomor
+	// terakhir yang pernah dikeluarkan, bila ada.
+	Counter string
+}
+
+// DefaultDocumentTypes mengembalikan jenis dokumen yang dipantau secara bawaan.
+func DefaultDocumentTypes() []DocumentType {
+	return []DocumentType{
+		{Code: "QT", Name: "Sales Quotation", PerCompany: true, StartsAt: 1, Counter: "QT"},
+		{Code: "SO", Name: "Sales Order", PerCompany: true, StartsAt: 1, Counter: "SO"},
+		{Code: "DO", Name: "Delivery Order", PerCompany: true, StartsAt: 1},
+		{Code: "INV", Name: "AR Invoice", StartsAt: 1},
+		{Code: "JE", Name: "Journal Entry"},
+	}
+}
+
+// SequenceQuery adalah filter yang diteruskan ke repository.
+type SequenceQuery struct {
+	DocTypes  []string
+	Counters  []string
+	CompanyID int64
+	From      time.Time
+	To        time.Time
+}
+
+// SequenceNumber adalah satu nomor dokumen yang tercatat.
+type SequenceNumber struct {
+	DocType   string
+	DocID     int64
+	CompanyID int64
+	Series    string
+	Seq       int64
+	Number    string
+	Status    string
+	Voided    bool
+}
+
+// SequenceCounter adalah nomor terakhir yang dikeluarkan untuk satu seri.
+type SequenceCounter struct {
+	DocType   string
+	CompanyID int64
+	Series    string
+	Last      int64
+}
+
+// SequenceRepository membaca nomor dokumen dan counter penomoran.
+type SequenceRepository interface {
+	SequenceNumbers(ctx context.Context, query SequenceQuery) ([]SequenceNumber, error)
+	SequenceCounters(ctx context.Context, query SequenceQuery) ([]SequenceCounter, error)
+}
+
+// SequenceFilters adalah filter laporan integritas nomor.
+type SequenceFilters struct {
+	CompanyID int64
+	DocType   string
+	From      time.Time
+	To        time.Time
+}
+
+// SequenceGap adalah rentang nomor berurutan yang tidak dipakai dokumen aktif.
+// Gap yang Explained seluruhnya berisi dokumen batal/void; selain itu nomor
+// tersebut tidak memiliki dokumen sama sekali.
+type SequenceGap struct {
+	From      string
+	To        string
+	Count     int64
+	Explained bool
+	Documents []SequenceNumber
+}
+
+// SequenceSeries adalah hasil pemeriksaan satu seri nomor.
+type SequenceSeries struct {
+	DocType     string
+	DocTypeName string
+	CompanyID   int64
+	Series      string
+	First       string
+	Last        string
+	Issued      int
+	Voided      int
+	Missing     int64
+	Gaps        []SequenceGap
+}
+
+// Unexplained menandakan seri memiliki nomor hilang tanpa dokumen.
+func (s SequenceSeries) Unexplained() bool {
+	return s.Missing > 0
+}
+
+// SequenceReport adalah laporan integritas nomor per jenis dokumen.
+type SequenceReport struct {
+	Filters     SequenceFilters
+	Types       []DocumentType
+	Series      []SequenceSeries
+	Voided      int
+	Missing     int64
+	Unexplained int
+}
+
+// SequenceService menyusun laporan integritas nomor dokumen.
+type SequenceService struct {
+	repo  SequenceRepository
+	types []DocumentType
+}
+
+// NewSequenceService membuat service laporan nomor. Tanpa types, jenis
+// dokumen bawaan yang dipakai.
+func NewSequenceService(repo SequenceRepository, types ...DocumentType) *SequenceService {
+	if len(types) == 0 {
+		types = DefaultDocumentTypes()
+	}
+	return &SequenceService{repo: repo, types: types}
+}
+
+// DocumentTypes mengembalikan jenis dokumen yang dipantau.
+func (s *SequenceService) DocumentTypes() []DocumentType {
+	return s.types
+}
+
+// Report memeriksa setiap seri nomor dalam periode filter, mencari gap, dan
+// mencocokkannya dengan dokumen batal/void.
+func (s *SequenceService) Report(ctx context.Context, filters SequenceFilters) (SequenceReport, error) {
+	if s.repo == nil {
+		return SequenceReport{}, fmt.Errorf("audit: sequence repository not configured")
+	}
+	if filters.From.IsZero() || filters.To.IsZero() || filters.From.After(filters.To) {
+		return SequenceReport{}, fmt.Errorf("%w: a valid period range is required", shared.ErrValidation)
+	}
+	types := s.types
+	if filters.DocType != "" {
+		types = nil
+		for _, t := range s.types {
+			if strings.EqualFold(t.Code, filters.DocType) {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 0 {
+			return SequenceReport{}, fmt.Errorf("%w: unknown document type %q", shared.ErrValidation, filters.DocType)
+		}
+	}
+	query := SequenceQuery{
+		CompanyID: filters.CompanyID,
+		From:      monthStart(filters.From),
+		To:        monthStart(filters.To),
+	}
+	for _, t := range types {
+		query.DocTypes = append(query.DocTypes, t.Code)
+		if t.Counter != "" {
+			query.Counters = append(query.Counters, t.Counter)
+		}
+	}
+	numbers, err := s.repo.SequenceNumbers(ctx, query)
+	if err != nil {
+		return SequenceReport{}, err
+	}
+	counters, err := s.repo.SequenceCounters(ctx, query)
+	if err != nil {
+		return SequenceReport{}, err
+	}
+
+	report := SequenceReport{Filters: filters, Types: types, Series: []SequenceSeries{}}
+	for _, t := range types {
+		for _, series := range buildSeries(t, numbers, counters) {
+			report.Voided += series.Voided
+			report.Missing += series.Missing
+			if series.Unexplained() {
+				report.Unexplained++
+			}
+			report.Series = append(report.Series, series)
+		}
+	}
+	return report, nil
+}
+
+type seriesKey struct {
+	companyID int64
+	series    string
+}
+
+// buildSeries mengelompokkan nomor satu jenis dokumen per seri lalu
+// memeriksa gap di tiap seri.
+func buildSeries(t DocumentType, numbers []SequenceNumber, counters []SequenceCounter) []SequenceSeries {
+	grouped := map[seriesKey][]SequenceNumber{}
+	for _, n := range numbers {
+		if n.DocType != t.Code {
+			continue
+		}
+		key := seriesKey{series: n.Series}
+		if t.PerCompany {
+			key.companyID = n.CompanyID
+		}
+		grouped[key] = append(grouped[key], n)
+	}
+	lastIssued := map[seriesKey]int64{}
+	if t.Counter != "" {
+		for _, c := range counters {
+			if c.DocType != t.Counter {
+				continue
+			}
+			key := seriesKey{companyID: c.CompanyID, series: c.Series}
+			if _, ok := grouped[key]; !ok {
+				// Nomor dikeluarkan tetapi tidak ada satu dokumen pun yang tersimpan.
+				grouped[key] = nil
+			}
+			lastIssued[key] = c.Last
+		}
+	}
+
+	keys := make([]seriesKey, 0, len(grouped))
+	for key := range grouped {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].companyID != keys[j].companyID {
+			return keys[i].companyID < keys[j].companyID
+		}
+		return keys[i].series < keys[j].series
+	})
+	result := make([]SequenceSeries, 0, len(keys))
+	for _, key := range keys {
+		series := checkSeries(key.series, grouped[key], t.StartsAt, lastIssued[key])
+		series.DocType, series.DocTypeName = t.Code, t.Name
+		series.CompanyID, series.Series = key.companyID, key.series
+		result = append(result, series)
+	}
+	return result
+}
+
+// checkSeries mencari gap antara nomor awal seri dan nomor terakhir yang
+// dikeluarkan. Nomor tanpa dokumen menjadi gap yang tidak terjelaskan;
+// dokumen batal/void berurutan menjadi gap yang terjelaskan.
+func checkSeries(series string, numbers []SequenceNumber, startsAt, lastIssued int64) SequenceSeries {
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i].Seq < numbers[j].Seq })
+	format := numberFormat(series, numbers)
+	result := SequenceSeries{Gaps: []SequenceGap{}}
+
+	first := startsAt
+	if first <= 0 && len(numbers) > 0 {
+		first = numbers[0].Seq
+	}
+	last := lastIssued
+	if len(numbers) > 0 && numbers[len(numbers)-1].Seq > last {
+		last = numbers[len(numbers)-1].Seq
+	}
+	if first <= 0 || last < first {
+		return result
+	}
+	result.First, result.Last = format(first), format(last)
+
+	var voided []SequenceNumber
+	flushVoided := func() {
+		if len(voided) == 0 {
+			return
+		}
+		result.Gaps = append(result.Gaps, SequenceGap{
+			From:      voided[0].Number,
+			To:        voided[len(voided)-1].Number,
+			Count:     int64(len(voided)),
+			Explained: true,
+			Documents: voided,
+		})
+		voided = nil
+	}
+	missing := func(from, to int64) {
+		if from > to {
+			return
+		}
+		flushVoided()
+		result.Missing += to - from + 1
+		result.Gaps = append(result.Gaps, SequenceGap{From: format(from), To: format(to), Count: to - from + 1})
+	}
+
+	next := first
+	for _, n := range numbers {
+		if n.Seq < next {
+			continue
+		}
+		missing(next, n.Seq-1)
+		next = n.Seq + 1
+		result.Issued++
+		if !n.Voided {
+			flushVoided()
+			continue
+		}
+		result.Voided++
+		voided = append(voided, n)
+	}
+	missing(next, last)
+	flushVoided()
+	return result
+}
+
+// numberFormat menyusun nomor lengkap dari seq memakai prefix dan lebar
+// angka nomor yang sudah ada, mis. SO-2601-0004. Tanpa contoh nomor, dipakai
+// nama seri diikuti seq tanpa padding.
+func numberFormat(series string, numbers []SequenceNumber) func(int64) string {
+	prefix, width := series+"-", 0
+	if len(numbers) > 0 {
+		number := numbers[0].Number
+		cut := len(number)
+		for cut > 0 && number[cut-1] >= '0' && number[cut-1] <= '9' {
+			cut--
+		}
+		prefix, width = number[:cut], len(number)-cut
+	}
+	return func(seq int64) string {
+		return fmt.Sprintf("%s%0*d", prefix, width, seq)
+	}
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
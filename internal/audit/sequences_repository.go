@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgSequenceRepository membaca view document_number_register dan tabel
+// document_sequences.
+type PgSequenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSequenceRepository membuat repository laporan nomor berbasis pgx.
+func NewSequenceRepository(pool *pgxpool.Pool) *PgSequenceRepository {
+	return &PgSequenceRepository{pool: pool}
+}
+
+// SequenceNumbers mengembalikan nomor dokumen pada periode filter. Dokumen
+// yang dinomori lintas perusahaan selalu ikut.
+func (r *PgSequenceRepository) SequenceNumbers(ctx context.Context, query SequenceQuery) ([]SequenceNumber, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT doc_type, doc_id, COALESCE(company_id, 0), series, seq, number, status, voided
+		FROM document_number_register
+		WHERE doc_type = ANY($1::text[])
+		  AND ($2::bigint = 0 OR company_id IS NULL OR company_id = $2)
+		  AND period BETWEEN $3::date AND $4::date
+		ORDER BY doc_type, company_id, series, seq
+	`, query.DocTypes, query.CompanyID, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var numbers []SequenceNumber
+	for rows.Next() {
+		var n SequenceNumber
+		if err := rows.Scan(&n.DocType, &n.DocID, &n.CompanyID, &n.Series, &n.Seq, &n.Number, &n.Status, &n.Voided); err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, rows.Err()
+}
+
+// SequenceCounters mengembalikan nomor terakhir yang dikeluarkan per seri.
+// Periode YYYYMM dipetakan ke nama seri, mis. SO-2601.
+func (r *PgSequenceRepository) SequenceCounters(ctx context.Context, query SequenceQuery) ([]SequenceCounter, error) {
+	if len(query.Counters) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT doc_type, company_id, doc_type || '-' || substr(period, 3, 4), seq
+		FROM document_sequences
+		WHERE doc_type = ANY($1::text[])
+		  AND ($2::bigint = 0 OR company_id = $2)
+		  AND period BETWEEN to_char($3::date, 'YYYYMM') AND to_char($4::date, 'YYYYMM')
+	`, query.Counters, query.CompanyID, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var counters []SequenceCounter
+	for rows.Next() {
+		var c SequenceCounter
+		if err := rows.Scan(&c.DocType, &c.CompanyID, &c.Series, &c.Last); err != nil {
+			return nil, err
+		}
+		counters = append(counters, c)
+	}
+	return counters, rows.Err()
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type stubSequenceRepo struct {
+	numbers  []SequenceNumber
+	counters []SequenceCounter
+	query    SequenceQuery
+}
+
+func (s *stubSequenceRepo) SequenceNumbers(_ context.Context, query SequenceQuery) ([]SequenceNumber, error) {
+	s.query = query
+	return s.numbers, nil
+}
+
+func (s *stubSequenceRepo) SequenceCounters(context.Context, SequenceQuery) ([]SequenceCounter, error) {
+	return s.counters, nil
+}
+
+func so(seq int64, number, status string) SequenceNumber {
+	return SequenceNumber{DocType: "SO", CompanyID: 1, Series: "SO-2610", Seq: seq, Number: number, Status: status, Voided: status == "CANCELLED"}
+}
+
+func TestSequenceReportSeparatesExplainedAndUnexplainedGaps(t *testing.T) {
+	repo := &stubSequenceRepo{
+		numbers: []SequenceNumber{
+			so(1, "SO-2610-0001", "CONFIRMED"),
+			so(2, "SO-2610-0002", "CANCELLED"),
+			so(3, "SO-2610-0003", "CANCELLED"),
+			so(4, "SO-2610-0004", "COMPLETED"),
+			so(7, "SO-2610-0007", "DRAFT"),
+		},
+		// Number 8 was issued but the order was never saved.
+		counters: []SequenceCounter{{DocType: "SO", CompanyID: 1, Series: "SO-2610", Last: 8}},
+	}
+	svc := NewSequenceService(repo)
+	month := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	report, err := svc.Report(context.Background(), SequenceFilters{DocType: "so", From: month, To: month})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(repo.query.DocTypes) != 1 || repo.query.Counters[0] != "SO" {
+		t.Fatalf("unexpected query %+v", repo.query)
+	}
+	if len(report.Series) != 1 {
+		t.Fatalf("expected one series, got %+v", report.Series)
+	}
+	series := report.Series[0]
+	if series.First != "SO-2610-0001" || series.Last != "SO-2610-0008" || series.Issued != 5 || series.Voided != 2 || series.Missing != 3 {
+		t.Fatalf("unexpected series %+v", series)
+	}
+	if len(series.Gaps) != 3 {
+		t.Fatalf("expected 3 gaps, got %+v", series.Gaps)
+	}
+	explained, missing, tail := series.Gaps[0], series.Gaps[1], series.Gaps[2]
+	if !explained.Explained || explained.From != "SO-2610-0002" || explained.To != "SO-2610-0003" || len(explained.Documents) != 2 {
+		t.Fatalf("cancelled orders should explain the first gap: %+v", explained)
+	}
+	if missing.Explained || missing.From != "SO-2610-0005" || missing.To != "SO-2610-0006" || missing.Count != 2 {
+		t.Fatalf("unexpected unexplained gap %+v", missing)
+	}
+	if tail.Explained || tail.From != "SO-2610-0008" || tail.Count != 1 {
+		t.Fatalf("issued but unsaved number should be flagged: %+v", tail)
+	}
+	if !series.Unexplained() || report.Unexplained != 1 || report.Missing != 3 || report.Voided != 2 {
+		t.Fatalf("unexpected totals %+v", report)
+	}
+}
+
+func TestSequenceReportGlobalSeriesStartsAtLowestNumber(t *testing.T) {
+	repo := &stubSequenceRepo{numbers: []SequenceNumber{
+		{DocType: "JE", Series: "JE", Seq: 100040, Number: "100040", Status: "POSTED"},
+		{DocType: "JE", Series: "JE", Seq: 100041, Number: "100041", Status: "VOID", Voided: true},
+		{DocType: "JE", Series: "JE", Seq: 100042, Number: "100042", Status: "POSTED"},
+	}}
+	svc := NewSequenceService(repo)
+	month := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	report, err := svc.Report(context.Background(), SequenceFilters{CompanyID: 3, DocType: "JE", From: month, To: month})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	series := report.Series[0]
+	if series.CompanyID != 0 || series.First != "100040" || series.Missing != 0 || len(series.Gaps) != 1 || !series.Gaps[0].Explained {
+		t.Fatalf("unexpected journal series %+v", series)
+	}
+	if report.Unexplained != 0 {
+		t.Fatalf("voided journal should not count as unexplained: %+v", report)
+	}
+}
+
+func TestSequenceReportRejectsUnknownType(t *testing.T) {
+	svc := NewSequenceService(&stubSequenceRepo{})
+	month := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := svc.Report(context.Background(), SequenceFilters{DocType: "PO", From: month, To: month}); !errors.Is(err, shared.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -2,13 +2,17 @@ package audit
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -16,8 +20,12 @@ import (
 type Repository interface {
 	AuditTimelineWindow(ctx context.Context, arg sqlc.AuditTimelineWindowParams) ([]sqlc.AuditTimelineWindowRow, error)
 	AuditTimelineAll(ctx context.Context, arg sqlc.AuditTimelineAllParams) ([]sqlc.AuditTimelineAllRow, error)
+	AuditLogGet(ctx context.Context, id int64) (sqlc.AuditLogGetRow, error)
 }
 
+// ErrNotFound dikembalikan bila entri audit tidak ditemukan.
+var ErrNotFound = errors.New("audit: entry not found")
+
 // Result membungkus hasil timeline dengan informasi paging.
 type Result struct {
 	Rows   []TimelineRow
@@ -57,6 +65,7 @@ func (s *Service) Timeline(ctx context.Context, filters TimelineFilters) (Result
 		Actor:      optionalText(filters.Actor),
 		Entity:     optionalText(filters.Entity),
 		Action:     optionalText(filters.Action),
+		Entities:   securityEntities(filters.Security),
 		OffsetRows: int32(offset),
 		LimitRows:  int32(pageSize + 1),
 	}
@@ -70,7 +79,7 @@ func (s *Service) Timeline(ctx context.Context, filters TimelineFilters) (Result
 	}
 	resultRows := make([]TimelineRow, 0, len(rows))
 	for _, row := range rows {
-		resultRows = append(resultRows, mapTimelineRow(row.At, row.Actor, row.Action, row.Entity, row.EntityID, row.JournalNo, row.PeriodCode))
+		resultRows = append(resultRows, mapTimelineRow(row.ID, row.At, row.Actor, row.Action, row.Entity, row.EntityID, row.JournalNo, row.PeriodCode))
 	}
 	paging := PagingInfo{Page: page, PageSize: pageSize, HasNext: hasNext}
 	if page > 1 {
@@ -88,11 +97,12 @@ func (s *Service) Export(ctx context.Context, filters TimelineFilters) ([]Timeli
 		return nil, fmt.Errorf("audit: repository not configured")
 	}
 	params := sqlc.AuditTimelineAllParams{
-		FromAt: toPgTime(filters.From),
-		ToAt:   toPgTime(filters.To),
-		Actor:  optionalText(filters.Actor),
-		Entity: optionalText(filters.Entity),
-		Action: optionalText(filters.Action),
+		FromAt:   toPgTime(filters.From),
+		ToAt:     toPgTime(filters.To),
+		Actor:    optionalText(filters.Actor),
+		Entity:   optionalText(filters.Entity),
+		Action:   optionalText(filters.Action),
+		Entities: securityEntities(filters.Security),
 	}
 	rows, err := s.repo.AuditTimelineAll(ctx, params)
 	if err != nil {
@@ -100,11 +110,62 @@ func (s *Service) Export(ctx context.Context, filters TimelineFilters) ([]Timeli
 	}
 	result := make([]TimelineRow, 0, len(rows))
 	for _, row := range rows {
-		result = append(result, mapTimelineRow(row.At, row.Actor, row.Action, row.Entity, row.EntityID, row.JournalNo, row.PeriodCode))
+		result = append(result, mapTimelineRow(row.ID, row.At, row.Actor, row.Action, row.Entity, row.EntityID, row.JournalNo, row.PeriodCode))
 	}
 	return result, nil
 }
 
+// Detail mengambil satu entri audit dan menghitung diff field demi field
+// antara snapshot before dan after.
+func (s *Service) Detail(ctx context.Context, id int64) (Detail, error) {
+	if s.repo == nil {
+		return Detail{}, fmt.Errorf("audit: repository not configured")
+	}
+	row, err := s.repo.AuditLogGet(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Detail{}, ErrNotFound
+		}
+		return Detail{}, err
+	}
+	detail := Detail{
+		ID:          row.ID,
+		Actor:       row.Actor,
+		Action:      row.Action,
+		Entity:      row.Entity,
+		EntityID:    row.EntityID,
+		HasSnapshot: len(row.Before) > 0 || len(row.After) > 0,
+	}
+	if row.At.Valid {
+		detail.At = row.At.Time
+	}
+	if len(row.Meta) > 0 {
+		if err := json.Unmarshal(row.Meta, &detail.Meta); err != nil {
+			return Detail{}, fmt.Errorf("audit: decode meta: %w", err)
+		}
+	}
+	detail.Truncated = snapshotTruncated(row.Before) || snapshotTruncated(row.After)
+	if detail.Truncated {
+		return detail, nil
+	}
+	changes, err := DiffSnapshots(row.Before, row.After)
+	if err != nil {
+		return Detail{}, fmt.Errorf("audit: diff snapshots: %w", err)
+	}
+	detail.Changes = changes
+	return detail, nil
+}
+
+func snapshotTruncated(raw []byte) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	var marker struct {
+		Truncated bool `json:"_truncated"`
+	}
+	return json.Unmarshal(raw, &marker) == nil && marker.Truncated
+}
+
 func toPgTime(t time.Time) pgtype.Timestamptz {
 	if t.IsZero() {
 		return pgtype.Timestamptz{}
@@ -120,7 +181,15 @@ func optionalText(value string) pgtype.Text {
 	return pgtype.Text{String: trimmed, Valid: true}
 }
 
-func mapTimelineRow(at pgtype.Timestamptz, actor, action, entity, entityID string, journal pgtype.Int8, period pgtype.Text) TimelineRow {
+// securityEntities mengembalikan entitas audit keamanan bila filter aktif.
+func securityEntities(enabled bool) []string {
+	if !enabled {
+		return nil
+	}
+	return shared.SecurityAuditEntities()
+}
+
+func mapTimelineRow(id int64, at pgtype.Timestamptz, actor, action, entity, entityID string, journal pgtype.Int8, period pgtype.Text) TimelineRow {
 	var ts time.Time
 	if at.Valid {
 		ts = at.Time
@@ -134,6 +203,7 @@ func mapTimelineRow(at pgtype.Timestamptz, actor, action, entity, entityID strin
 		periodCode = period.String
 	}
 	return TimelineRow{
+		ID:        id,
 		At:        ts,
 		Actor:     actor,
 		Action:    action,
@@ -2,11 +2,14 @@ package audit
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -15,6 +18,7 @@ type stubTimelineRepo struct {
 	allRows        []sqlc.AuditTimelineAllRow
 	lastWindowCall sqlc.AuditTimelineWindowParams
 	lastAllCall    sqlc.AuditTimelineAllParams
+	logs           map[int64]sqlc.AuditLogGetRow
 }
 
 func (s *stubTimelineRepo) AuditTimelineWindow(ctx context.Context, arg sqlc.AuditTimelineWindowParams) ([]sqlc.AuditTimelineWindowRow, error) {
@@ -27,6 +31,14 @@ func (s *stubTimelineRepo) AuditTimelineAll(ctx context.Context, arg sqlc.AuditT
 	return s.allRows, nil
 }
 
+func (s *stubTimelineRepo) AuditLogGet(ctx context.Context, id int64) (sqlc.AuditLogGetRow, error) {
+	row, ok := s.logs[id]
+	if !ok {
+		return sqlc.AuditLogGetRow{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
 func TestServiceTimelinePaging(t *testing.T) {
 	repo := &stubTimelineRepo{
 		windowRows: []sqlc.AuditTimelineWindowRow{
@@ -79,6 +91,70 @@ func TestServiceExportReturnsAllRows(t *testing.T) {
 	}
 }
 
+func TestServiceSecurityFilterNarrowsEntities(t *testing.T) {
+	repo := &stubTimelineRepo{}
+	svc := NewService(repo)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := svc.Timeline(context.Background(), TimelineFilters{From: from, Security: true}); err != nil {
+		t.Fatalf("timeline: %v", err)
+	}
+	if got := repo.lastWindowCall.Entities; len(got) != 3 || got[0] != shared.AuditEntityRole {
+		t.Fatalf("expected security entities, got %v", got)
+	}
+	if _, err := svc.Export(context.Background(), TimelineFilters{From: from}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if repo.lastAllCall.Entities != nil {
+		t.Fatalf("expected no entity list without security filter, got %v", repo.lastAllCall.Entities)
+	}
+}
+
+func TestServiceDetailDiffsSnapshots(t *testing.T) {
+	repo := &stubTimelineRepo{logs: map[int64]sqlc.AuditLogGetRow{
+		5: {
+			ID:       5,
+			Action:   "customer.update",
+			Entity:   "customers",
+			EntityID: "12",
+			Meta:     []byte(`{"code":"C-12"}`),
+			Before:   []byte(`{"name":"Acme","credit_limit":1000,"address":{"city":"Bandung"},"fax":"123"}`),
+			After:    []byte(`{"name":"Acme","credit_limit":2500,"address":{"city":"Jakarta"},"email":"ap@acme.test"}`),
+		},
+		6: {ID: 6, Before: []byte(`{"_truncated":true,"_bytes":20000}`), After: []byte(`{"name":"x"}`)},
+	}}
+	svc := NewService(repo)
+
+	detail, err := svc.Detail(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("detail: %v", err)
+	}
+	want := []FieldChange{
+		{Field: "address.city", Kind: ChangeUpdated, Before: "Bandung", After: "Jakarta"},
+		{Field: "credit_limit", Kind: ChangeUpdated, Before: "1000", After: "2500"},
+		{Field: "email", Kind: ChangeAdded, After: "ap@acme.test"},
+		{Field: "fax", Kind: ChangeRemoved, Before: "123"},
+	}
+	if len(detail.Changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), detail.Changes)
+	}
+	for i := range want {
+		if detail.Changes[i] != want[i] {
+			t.Fatalf("change %d = %+v, want %+v", i, detail.Changes[i], want[i])
+		}
+	}
+	if !detail.HasSnapshot || detail.Meta["code"] != "C-12" {
+		t.Fatalf("unexpected detail %+v", detail)
+	}
+
+	truncated, err := svc.Detail(context.Background(), 6)
+	if err != nil || !truncated.Truncated || truncated.Changes != nil {
+		t.Fatalf("expected truncated detail without diff, got %+v, %v", truncated, err)
+	}
+	if _, err := svc.Detail(context.Background(), 99); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func mockWindowRow(ts, actor, action, entity, entityID string, journal int64, period string) sqlc.AuditTimelineWindowRow {
 	tval, _ := time.Parse(time.RFC3339, ts)
 	row := sqlc.AuditTimelineWindowRow{
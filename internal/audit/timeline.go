@@ -9,6 +9,7 @@ type TimelineFilters struct {
 	Actor    string
 	Entity   string
 	Action   string
+	Security bool
 	Page     int
 	PageSize int
 }
@@ -22,6 +23,7 @@ type TimelineRow struct {
 	EntityID  string
 	Period    string
 	JournalNo string
+	ID        int64
 }
 
 // PagingInfo menyimpan metadata pagination sederhana.
@@ -35,11 +37,12 @@ type PagingInfo struct {
 
 // FiltersViewModel menampung nilai filter untuk template.
 type FiltersViewModel struct {
-	From   time.Time
-	To     time.Time
-	Actor  string
-	Entity string
-	Action string
+	From     time.Time
+	To       time.Time
+	Actor    string
+	Entity   string
+	Action   string
+	Security bool
 }
 
 // ViewModel menyatukan data untuk template audit timeline.
@@ -48,3 +51,19 @@ type ViewModel struct {
 	Rows    []TimelineRow
 	Paging  PagingInfo
 }
+
+// Detail menampung satu entri audit beserta diff snapshot before/after.
+type Detail struct {
+	ID       int64
+	At       time.Time
+	Actor    string
+	Action   string
+	Entity   string
+	EntityID string
+	Meta     map[string]any
+	// HasSnapshot bernilai true bila entri menyimpan snapshot before/after.
+	HasSnapshot bool
+	// Truncated bernilai true bila salah satu snapshot melewati batas ukuran.
+	Truncated bool
+	Changes   []FieldChange
+}
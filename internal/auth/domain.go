@@ -10,4 +10,9 @@ type User struct {
 	IsActive     bool
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	// PasswordChangedAt is when the current password was set.
+	PasswordChangedAt time.Time
+	// MustChangePassword asks for a new password before the account can be
+	// used: set by an administrator, or when the password is past rotation.
+	MustChangePassword bool
 }
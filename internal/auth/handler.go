@@ -1,14 +1,17 @@
 package auth
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 )
@@ -40,6 +43,26 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	r.Get("/login", h.showLogin)
 	r.Post("/login", h.handleLogin)
 	r.Post("/logout", h.handleLogout)
+	r.Get("/password", h.showChangePassword)
+	r.Post("/password", h.handleChangePassword)
+}
+
+// sessionPasswordChange marks a session whose password must be changed
+// before anything else is allowed.
+const sessionPasswordChange = "password_change_required"
+
+// RotationGuard sends users who must change their password to the change
+// form until they do.
+func (h *Handler) RotationGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := shared.SessionFromContext(r.Context())
+		if sess == nil || sess.Get(sessionPasswordChange) == "" ||
+			strings.HasPrefix(r.URL.Path, "/auth/") || strings.HasPrefix(r.URL.Path, "/static/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		http.Redirect(w, r, "/auth/password", http.StatusSeeOther)
+	})
 }
 
 type loginForm struct {
@@ -68,14 +91,14 @@ func (h *Handler) showLogin(w http.ResponseWriter, r *http.Request) {
 		Data:        data,
 	}
 	if err := h.templates.Render(w, "pages/login.html", viewData); err != nil {
-		h.logger.Error("render login", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render login", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 	}
 }
 
 func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	sess := shared.SessionFromContext(r.Context())
@@ -97,9 +120,16 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			errors["general"] = "Email atau password tidak valid"
 		} else {
+			redirect := "/"
 			if sess != nil {
 				sess.SetUser(strconv.FormatInt(user.ID, 10))
-				sess.AddFlash(shared.FlashMessage{Kind: "success", Message: "Selamat datang kembali"})
+				if user.MustChangePassword {
+					sess.Set(sessionPasswordChange, "1")
+					sess.AddFlash(shared.FlashMessage{Kind: "warning", Message: "Password Anda harus diganti sebelum melanjutkan"})
+					redirect = "/auth/password"
+				} else {
+					sess.AddFlash(shared.FlashMessage{Kind: "success", Message: "Selamat datang kembali"})
+				}
 			}
 			expiresAt := time.Now().Add(h.sessionManager.TTL())
 			sessionID := ""
@@ -108,13 +138,13 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 			}
 			if sessionID != "" {
 				if err := h.service.RegisterSession(r.Context(), sessionID, user.ID, expiresAt, r.RemoteAddr, r.UserAgent()); err != nil {
-					h.logger.Warn("register session", slog.Any("error", err))
+					h.logger.WarnContext(r.Context(), "register session", slog.Any("error", err))
 				}
 			}
 			if sess == nil {
-				h.logger.Error("session missing during login")
+				h.logger.ErrorContext(r.Context(), "session missing during login")
 			}
-			http.Redirect(w, r, "/", http.StatusSeeOther)
+			http.Redirect(w, r, redirect, http.StatusSeeOther)
 			return
 		}
 	}
@@ -133,7 +163,7 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusBadRequest)
 	if err := h.templates.Render(w, "pages/login.html", viewData); err != nil {
-		h.logger.Error("render login invalid", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render login invalid", slog.Any("error", err))
 	}
 }
 
@@ -141,13 +171,97 @@ func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
 	sess := shared.SessionFromContext(r.Context())
 	if sess != nil {
 		if err := h.service.RemoveSession(r.Context(), sess.ID); err != nil {
-			h.logger.Warn("remove session", slog.Any("error", err))
+			h.logger.WarnContext(r.Context(), "remove session", slog.Any("error", err))
 		}
 		h.sessionManager.Destroy(sess)
 	}
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+type changePasswordPageData struct {
+	Required bool
+	Policy   PasswordPolicy
+	Errors   map[string]string
+}
+
+func (h *Handler) showChangePassword(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil || sess.User() == "" {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+	h.renderChangePassword(w, r, sess, map[string]string{}, http.StatusOK)
+}
+
+func (h *Handler) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil || sess.User() == "" {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	userID, err := strconv.ParseInt(sess.User(), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+	current := r.PostFormValue("current_password")
+	next := r.PostFormValue("new_password")
+	errs := make(map[string]string)
+	switch {
+	case current == "":
+		errs["current_password"] = "Masukkan password saat ini"
+	case next != r.PostFormValue("confirm_password"):
+		errs["confirm_password"] = "Konfirmasi password tidak sama"
+	}
+	if len(errs) == 0 {
+		err := h.service.ChangePassword(r.Context(), userID, current, next)
+		switch {
+		case err == nil:
+			sess.Delete(sessionPasswordChange)
+			sess.AddFlash(shared.FlashMessage{Kind: "success", Message: "Password berhasil diganti"})
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return
+		case errors.Is(err, shared.ErrInvalidCredentials):
+			errs["current_password"] = "Password saat ini salah"
+		case errors.Is(err, ErrPasswordTooShort):
+			errs["new_password"] = "Password minimal " + strconv.Itoa(h.service.PasswordPolicy().MinLength) + " karakter"
+		case errors.Is(err, ErrPasswordTooSimple):
+			errs["new_password"] = "Gabungkan minimal " + strconv.Itoa(h.service.PasswordPolicy().MinCharClasses) + " jenis karakter: huruf kecil, huruf besar, angka dan simbol"
+		case errors.Is(err, ErrPasswordCommon):
+			errs["new_password"] = "Password terlalu mudah ditebak"
+		case errors.Is(err, ErrPasswordReused):
+			errs["new_password"] = "Password ini sudah pernah dipakai, pilih yang lain"
+		default:
+			h.logger.ErrorContext(r.Context(), "change password", slog.Any("error", err), slog.Int64("user_id", userID))
+			errs["general"] = shared.UserSafeMessage(err)
+		}
+	}
+	h.renderChangePassword(w, r, sess, errs, http.StatusBadRequest)
+}
+
+func (h *Handler) renderChangePassword(w http.ResponseWriter, r *http.Request, sess *shared.Session, errs map[string]string, status int) {
+	csrfToken, _ := h.csrfManager.EnsureToken(r.Context(), sess)
+	viewData := view.TemplateData{
+		Title:       "Ganti Password",
+		CSRFToken:   csrfToken,
+		Flash:       sess.PopFlash(),
+		CurrentPath: r.URL.Path,
+		Data: changePasswordPageData{
+			Required: sess.Get(sessionPasswordChange) != "",
+			Policy:   h.service.PasswordPolicy(),
+			Errors:   errs,
+		},
+	}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, "pages/change_password.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render change password", slog.Any("error", err))
+	}
+}
+
 // ShowLoginForTest exposes the GET handler for tests.
 func (h *Handler) ShowLoginForTest(w http.ResponseWriter, r *http.Request) {
 	h.showLogin(w, r)
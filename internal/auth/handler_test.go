@@ -20,7 +20,8 @@ import (
 )
 
 type stubRepo struct {
-	user *auth.User
+	user    *auth.User
+	history []string
 }
 
 func (s *stubRepo) FindByEmail(ctx context.Context, email string) (*auth.User, error) {
@@ -30,6 +31,27 @@ func (s *stubRepo) FindByEmail(ctx context.Context, email string) (*auth.User, e
 	return s.user, nil
 }
 
+func (s *stubRepo) FindByID(ctx context.Context, id int64) (*auth.User, error) {
+	if s.user == nil || s.user.ID != id {
+		return nil, shared.ErrNotFound
+	}
+	return s.user, nil
+}
+
+func (s *stubRepo) PasswordHistory(ctx context.Context, userID int64, limit int) ([]string, error) {
+	if len(s.history) > limit {
+		return s.history[:limit], nil
+	}
+	return s.history, nil
+}
+
+func (s *stubRepo) SetPassword(ctx context.Context, userID int64, hash string) error {
+	s.user.PasswordHash = hash
+	s.user.MustChangePassword = false
+	s.history = append([]string{hash}, s.history...)
+	return nil
+}
+
 func (s *stubRepo) CreateSession(ctx context.Context, id string, userID int64, expiresAt time.Time, ip, ua string) error {
 	return nil
 }
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrPasswordTooShort rejects a password below the policy's minimum length.
+	ErrPasswordTooShort = errors.New("password is too short")
+	// ErrPasswordTooSimple rejects a password mixing too few character classes.
+	ErrPasswordTooSimple = errors.New("password needs more kinds of characters")
+	// ErrPasswordCommon rejects a well-known password or one containing the
+	// account's email name.
+	ErrPasswordCommon = errors.New("password is too easy to guess")
+	// ErrPasswordReused rejects one of the account's recent passwords.
+	ErrPasswordReused = errors.New("password was used recently")
+)
+
+// PasswordPolicy is enforced whenever a password is set. Zero values switch a
+// rule off.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters.
+	MinLength int
+	// MinCharClasses is how many of lower case, upper case, digits and
+	// symbols the password must mix.
+	MinCharClasses int
+	// DisallowCommon rejects the common passwords and passwords containing
+	// the email's local part.
+	DisallowCommon bool
+	// Common holds the lower-cased passwords rejected by DisallowCommon.
+	// Nil uses the built-in list.
+	Common map[string]struct{}
+	// RotationDays forces a change at login once the password is older.
+	RotationDays int
+	// HistorySize is how many previous passwords cannot be reused.
+	HistorySize int
+}
+
+// DefaultPasswordPolicy is used until the configured policy is set.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: 12, MinCharClasses: 3, DisallowCommon: true, HistorySize: 5}
+}
+
+// Validate checks a new password against the policy. email is the account's
+// address, whose local part may not appear in the password.
+func (p PasswordPolicy) Validate(password, email string) error {
+	if p.MinLength > 0 && len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w: use at least %d characters", ErrPasswordTooShort, p.MinLength)
+	}
+	if p.MinCharClasses > 0 && charClasses(password) < p.MinCharClasses {
+		return fmt.Errorf("%w: mix at least %d of lower case, upper case, digits and symbols", ErrPasswordTooSimple, p.MinCharClasses)
+	}
+	if !p.DisallowCommon {
+		return nil
+	}
+	lower := strings.ToLower(password)
+	common := p.Common
+	if common == nil {
+		common = builtinCommonPasswords
+	}
+	if _, ok := common[lower]; ok {
+		return ErrPasswordCommon
+	}
+	if name, _, _ := strings.Cut(strings.ToLower(email), "@"); len(name) >= 3 && strings.Contains(lower, name) {
+		return ErrPasswordCommon
+	}
+	return nil
+}
+
+// Expired reports whether a password set at changedAt must be rotated.
+func (p PasswordPolicy) Expired(changedAt, now time.Time) bool {
+	if p.RotationDays <= 0 || changedAt.IsZero() {
+		return false
+	}
+	return now.Sub(changedAt) >= time.Duration(p.RotationDays)*24*time.Hour
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// LoadCommonPasswords reads one password per line, skipping blanks and lines
+// starting with #. An empty path returns nil, selecting the built-in list.
+func LoadCommonPasswords(path string) (map[string]struct{}, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read common passwords: %w", err)
+	}
+	defer f.Close()
+	common := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		common[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read common passwords: %w", err)
+	}
+	return common, nil
+}
+
+var builtinCommonPasswords = func() map[string]struct{} {
+	list := []string{
+		"123456", "123456789", "12345678", "1234567890", "password", "password1",
+		"password123", "passw0rd", "p@ssw0rd", "p@ssword1", "qwerty", "qwerty123",
+		"qwertyuiop", "abc123", "admin", "admin123", "admin@123", "administrator",
+		"welcome", "welcome1", "welcome123", "letmein", "iloveyou", "monkey",
+		"dragon", "football", "baseball", "sunshine", "princess", "master",
+		"superman", "trustno1", "changeme", "secret", "root", "toor",
+		"indonesia", "jakarta", "bismillah", "rahasia", "sayang", "odyssey",
+		"odyssey123", "1q2w3e4r", "1qaz2wsx", "zaq12wsx", "aa123456", "111111",
+		"000000", "654321", "123123", "121212", "666666", "888888",
+	}
+	set := make(map[string]struct{}, len(list))
+	for _, p := range list {
+		set[p] = struct{}{}
+	}
+	return set
+}()
@@ -0,0 +1,96 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/auth"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := auth.DefaultPasswordPolicy()
+	cases := []struct {
+		password string
+		want     error
+	}{
+		{"Short1!", auth.ErrPasswordTooShort},
+		{"alllowercaseletters", auth.ErrPasswordTooSimple},
+		{"Password1234", nil},
+		{"xxBudiSantoso99", auth.ErrPasswordCommon},
+		{"Tr0ubadour&Horse", nil},
+	}
+	for _, tc := range cases {
+		err := policy.Validate(tc.password, "budisantoso@example.com")
+		if tc.want == nil && err != nil {
+			t.Fatalf("%q: unexpected error %v", tc.password, err)
+		}
+		if tc.want != nil && !errors.Is(err, tc.want) {
+			t.Fatalf("%q: expected %v, got %v", tc.password, tc.want, err)
+		}
+	}
+
+	policy.Common = map[string]struct{}{"tr0ubadour&horse": {}}
+	if err := policy.Validate("Tr0ubadour&Horse", "user@example.com"); !errors.Is(err, auth.ErrPasswordCommon) {
+		t.Fatalf("expected the configured list to be used, got %v", err)
+	}
+	policy.DisallowCommon = false
+	if err := policy.Validate("Tr0ubadour&Horse", "user@example.com"); err != nil {
+		t.Fatalf("expected common check to be off, got %v", err)
+	}
+}
+
+func TestPasswordPolicyExpired(t *testing.T) {
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	policy := auth.PasswordPolicy{RotationDays: 90}
+	if policy.Expired(now.AddDate(0, 0, -89), now) {
+		t.Fatalf("password changed 89 days ago should not expire")
+	}
+	if !policy.Expired(now.AddDate(0, 0, -90), now) {
+		t.Fatalf("password changed 90 days ago should expire")
+	}
+	if (auth.PasswordPolicy{}).Expired(now.AddDate(-5, 0, 0), now) {
+		t.Fatalf("rotation off should never expire")
+	}
+}
+
+func TestChangePasswordRejectsReuse(t *testing.T) {
+	hash := func(password string) string {
+		h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("hash: %v", err)
+		}
+		return string(h)
+	}
+	repo := &stubRepo{
+		user:    &auth.User{ID: 1, Email: "user@test.local", PasswordHash: hash("Current#Pass1"), IsActive: true, MustChangePassword: true},
+		history: []string{hash("Current#Pass1"), hash("Older#Pass22")},
+	}
+	svc := auth.NewService(repo)
+	ctx := context.Background()
+
+	if err := svc.ChangePassword(ctx, 1, "wrong", "Brand#NewPass3"); !errors.Is(err, shared.ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, 1, "Current#Pass1", "Older#Pass22"); !errors.Is(err, auth.ErrPasswordReused) {
+		t.Fatalf("expected reuse to be rejected, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, 1, "Current#Pass1", "short"); !errors.Is(err, auth.ErrPasswordTooShort) {
+		t.Fatalf("expected policy error, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, 1, "Current#Pass1", "Brand#NewPass3"); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+	if repo.user.MustChangePassword {
+		t.Fatalf("expected forced change to be cleared")
+	}
+
+	svc.SetPasswordPolicy(auth.PasswordPolicy{MinLength: 8})
+	if err := svc.ChangePassword(ctx, 1, "Brand#NewPass3", "Older#Pass22"); err != nil {
+		t.Fatalf("expected reuse to be allowed without history, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -15,18 +16,26 @@ import (
 // Repository defines persistence operations for auth module.
 type Repository interface {
 	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByID(ctx context.Context, id int64) (*User, error)
+	// PasswordHistory returns the hashes of the user's last limit passwords,
+	// newest first.
+	PasswordHistory(ctx context.Context, userID int64, limit int) ([]string, error)
+	// SetPassword stores a new password hash, records it in the history and
+	// clears the forced change.
+	SetPassword(ctx context.Context, userID int64, hash string) error
 	CreateSession(ctx context.Context, id string, userID int64, expiresAt time.Time, ip, ua string) error
 	DeleteSession(ctx context.Context, id string) error
 }
 
 // PGRepository implements Repository using PostgreSQL.
 type PGRepository struct {
+	pool    *pgxpool.Pool
 	queries *sqlc.Queries
 }
 
 // NewRepository constructs a PostgreSQL repository.
 func NewRepository(pool *pgxpool.Pool) *PGRepository {
-	return &PGRepository{queries: sqlc.New(pool)}
+	return &PGRepository{pool: pool, queries: sqlc.New(pool)}
 }
 
 // FindByEmail fetches a user by email.
@@ -46,9 +55,78 @@ func (r *PGRepository) FindByEmail(ctx context.Context, email string) (*User, er
 		CreatedAt:    record.CreatedAt.Time,
 		UpdatedAt:    record.UpdatedAt.Time,
 	}
+	if err := r.loadPasswordState(ctx, user); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
+// FindByID fetches a user by id.
+func (r *PGRepository) FindByID(ctx context.Context, id int64) (*User, error) {
+	user := &User{ID: id}
+	err := r.pool.QueryRow(ctx, `
+		SELECT email, password_hash, is_active, created_at, updated_at
+		FROM users WHERE id = $1`, id).Scan(&user.Email, &user.PasswordHash, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, shared.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadPasswordState(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *PGRepository) loadPasswordState(ctx context.Context, user *User) error {
+	return r.pool.QueryRow(ctx, `
+		SELECT password_changed_at, must_change_password FROM users WHERE id = $1`, user.ID).
+		Scan(&user.PasswordChangedAt, &user.MustChangePassword)
+}
+
+// PasswordHistory returns the user's most recent password hashes.
+func (r *PGRepository) PasswordHistory(ctx context.Context, userID int64, limit int) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT password_hash FROM user_password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+// SetPassword replaces the user's password and records it in the history.
+func (r *PGRepository) SetPassword(ctx context.Context, userID int64, hash string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+	if _, err := tx.Exec(ctx, `
+		UPDATE users
+		SET password_hash = $2, password_changed_at = NOW(), must_change_password = FALSE, updated_at = NOW()
+		WHERE id = $1`, userID, hash); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_password_history (user_id, password_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 // CreateSession persists a new login session in the database for auditing.
 func (r *PGRepository) CreateSession(ctx context.Context, id string, userID int64, expiresAt time.Time, ip, ua string) error {
 	now := time.Now().UTC()
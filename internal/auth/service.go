@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -11,12 +12,25 @@ import (
 
 // Service wraps authentication business rules.
 type Service struct {
-	repo Repository
+	repo   Repository
+	policy PasswordPolicy
+	now    func() time.Time
 }
 
 // NewService constructs a new Service.
 func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+	return &Service{repo: repo, policy: DefaultPasswordPolicy(), now: time.Now}
+}
+
+// SetPasswordPolicy replaces the policy enforced on password changes and
+// rotation.
+func (s *Service) SetPasswordPolicy(policy PasswordPolicy) {
+	s.policy = policy
+}
+
+// PasswordPolicy returns the enforced policy.
+func (s *Service) PasswordPolicy() PasswordPolicy {
+	return s.policy
 }
 
 // Authenticate validates email/password credentials.
@@ -31,9 +45,49 @@ func (s *Service) Authenticate(ctx context.Context, email, password string) (*Us
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
 		return nil, shared.ErrInvalidCredentials
 	}
+	if s.policy.Expired(user.PasswordChangedAt, s.now()) {
+		user.MustChangePassword = true
+	}
 	return user, nil
 }
 
+// ChangePassword replaces the user's password after checking the current one,
+// the policy and the password history.
+func (s *Service) ChangePassword(ctx context.Context, userID int64, current, next string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(current)); err != nil {
+		return shared.ErrInvalidCredentials
+	}
+	if err := s.policy.Validate(next, user.Email); err != nil {
+		return err
+	}
+	previous := []string{user.PasswordHash}
+	if s.policy.HistorySize > 0 {
+		history, err := s.repo.PasswordHistory(ctx, userID, s.policy.HistorySize)
+		if err != nil {
+			return err
+		}
+		previous = append(previous, history...)
+	}
+	for _, hash := range previous {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(next))
+		if err == nil {
+			return ErrPasswordReused
+		}
+		if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return err
+		}
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(next), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetPassword(ctx, userID, string(hash))
+}
+
 // RegisterSession persists the session metadata in postgres.
 func (s *Service) RegisterSession(ctx context.Context, id string, userID int64, expiresAt time.Time, ip, ua string) error {
 	return s.repo.CreateSession(ctx, id, userID, expiresAt, ip, ua)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/reports"
 	"github.com/odyssey-erp/odyssey-erp/internal/analytics"
+	"github.com/odyssey-erp/odyssey-erp/internal/insights"
 	"github.com/odyssey-erp/odyssey-erp/internal/variance"
 )
 
@@ -23,6 +24,12 @@ type KPIProvider interface {
 	GetKPISummary(ctx context.Context, filter analytics.KPIFilter) (analytics.KPISummary, error)
 }
 
+// InsightsProvider exposes the insights comparison used to write the exec
+// summary narrative.
+type InsightsProvider interface {
+	Load(ctx context.Context, filters insights.CompareFilters) (insights.Result, error)
+}
+
 type dataRepository interface {
 	AggregateAccountBalances(ctx context.Context, companyID, periodID int64) ([]reports.AccountBalance, error)
 	GetTemplate(ctx context.Context, id int64) (Template, error)
@@ -33,6 +40,7 @@ type Builder struct {
 	repo     dataRepository
 	variance VarianceProvider
 	kpi      KPIProvider
+	insights InsightsProvider
 	now      func() time.Time
 	topLimit int
 }
@@ -49,6 +57,11 @@ func (b *Builder) WithTopVarianceLimit(limit int) {
 	}
 }
 
+// WithInsights enables the narrative in the exec summary.
+func (b *Builder) WithInsights(provider InsightsProvider) {
+	b.insights = provider
+}
+
 // Build constructs the document view-model for the supplied board pack.
 func (b *Builder) Build(ctx context.Context, pack BoardPack) (DocumentData, error) {
 	if pack.Template == nil {
@@ -70,24 +83,29 @@ func (b *Builder) Build(ctx context.Context, pack BoardPack) (DocumentData, erro
 
 	warnings := make([]string, 0)
 	kpiSummary := KPISummary{}
+	var priorSummary *KPISummary
+	priorName, priorAsOf := priorPeriod(period)
 	if b.kpi != nil {
 		summary, err := b.kpi.GetKPISummary(ctx, analytics.KPIFilter{Period: period.Name, CompanyID: company.ID, AsOf: period.EndDate})
 		if err != nil {
 			warnings = append(warnings, fmt.Sprintf("KPI summary gagal dimuat: %v", err))
 		} else {
-			kpiSummary = KPISummary{
-				NetProfit:     summary.NetProfit,
-				Revenue:       summary.Revenue,
-				Opex:          summary.Opex,
-				COGS:          summary.COGS,
-				CashIn:        summary.CashIn,
-				CashOut:       summary.CashOut,
-				AROutstanding: summary.AROutstanding,
-				APOutstanding: summary.APOutstanding,
+			kpiSummary = kpiFromAnalytics(summary)
+			prior, err := b.kpi.GetKPISummary(ctx, analytics.KPIFilter{Period: priorName, CompanyID: company.ID, AsOf: priorAsOf})
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("KPI periode %s gagal dimuat: %v", priorName, err))
+			} else if prior != (analytics.KPISummary{}) {
+				converted := kpiFromAnalytics(prior)
+				priorSummary = &converted
 			}
 		}
 	}
 
+	varianceRows, varianceWarn := b.loadVarianceRows(ctx, pack)
+	if varianceWarn != "" {
+		warnings = append(warnings, varianceWarn)
+	}
+
 	sections := make([]SectionData, 0, len(pack.Template.Sections))
 	generatedAt := b.now()
 	requestedBy := metadataInt64(pack.Metadata, "requested_by")
@@ -102,8 +120,15 @@ func (b *Builder) Build(ctx context.Context, pack BoardPack) (DocumentData, erro
 				RequestedBy:   requestedBy,
 				VarianceLabel: varianceLabel,
 				KPISummary:    kpiSummary,
+				PriorPeriod:   priorName,
+				KPICards:      kpiCards(kpiSummary, priorSummary),
 				Status:        pack.Status,
 			}
+			narrative, warn := b.narrative(ctx, company, period, varianceRows)
+			if warn != "" {
+				warnings = append(warnings, warn)
+			}
+			exec.Narrative = narrative
 			sections = append(sections, SectionData{Type: SectionExecSummary, Title: section.Title, Exec: exec, HasContent: true})
 		case SectionPLSummary:
 			sections = append(sections, SectionData{Type: SectionPLSummary, Title: section.Title, Payload: pl, HasContent: len(pl.Revenue.Accounts)+len(pl.Expense.Accounts) > 0})
@@ -114,10 +139,7 @@ func (b *Builder) Build(ctx context.Context, pack BoardPack) (DocumentData, erro
 			sections = append(sections, SectionData{Type: SectionCashflow, Title: section.Title, Cashflow: cf, HasContent: cf.CashIn != 0 || cf.CashOut != 0})
 		case SectionTopVariances:
 			limit := b.limitFromOptions(section.Options)
-			rows, warn := b.loadTopVariances(ctx, pack, limit)
-			if warn != "" {
-				warnings = append(warnings, warn)
-			}
+			rows := topVariances(varianceRows, limit)
 			sections = append(sections, SectionData{Type: SectionTopVariances, Title: section.Title, Payload: rows, Limit: limit, HasContent: len(rows) > 0})
 		default:
 			warnings = append(warnings, fmt.Sprintf("Section %s tidak dikenal", section.Type))
@@ -135,6 +157,72 @@ func (b *Builder) Build(ctx context.Context, pack BoardPack) (DocumentData, erro
 	}, nil
 }
 
+func kpiFromAnalytics(summary analytics.KPISummary) KPISummary {
+	return KPISummary{
+		NetProfit:     summary.NetProfit,
+		Revenue:       summary.Revenue,
+		Opex:          summary.Opex,
+		COGS:          summary.COGS,
+		CashIn:        summary.CashIn,
+		CashOut:       summary.CashOut,
+		AROutstanding: summary.AROutstanding,
+		APOutstanding: summary.APOutstanding,
+	}
+}
+
+// priorPeriod returns the KPI period (YYYY-MM) of the month before the pack
+// period and the as-of date used for its aging balances.
+func priorPeriod(period Period) (string, time.Time) {
+	start := period.StartDate
+	if start.IsZero() {
+		start = period.EndDate
+	}
+	first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	return first.AddDate(0, -1, 0).Format("2006-01"), first.AddDate(0, 0, -1)
+}
+
+// kpiCards pairs each exec summary metric with its prior-period value. A nil
+// prior means the prior period has no KPI data.
+func kpiCards(current KPISummary, prior *KPISummary) []KPICard {
+	var before KPISummary
+	if prior != nil {
+		before = *prior
+	}
+	metrics := []struct {
+		label           string
+		current, before float64
+	}{
+		{"Revenue", current.Revenue, before.Revenue},
+		{"Net Profit", current.NetProfit, before.NetProfit},
+		{"Opex", current.Opex, before.Opex},
+		{"COGS", current.COGS, before.COGS},
+		{"Cash In", current.CashIn, before.CashIn},
+		{"Cash Out", current.CashOut, before.CashOut},
+	}
+	cards := make([]KPICard, 0, len(metrics))
+	for _, m := range metrics {
+		card := KPICard{Label: m.label, Current: m.current}
+		if prior != nil {
+			card.HasPrior = true
+			card.Prior = m.before
+			card.Delta = m.current - m.before
+			card.Direction = DirectionFlat
+			switch {
+			case card.Delta > 0:
+				card.Direction = DirectionUp
+			case card.Delta < 0:
+				card.Direction = DirectionDown
+			}
+			if m.before != 0 {
+				card.HasChangePct = true
+				card.ChangePct = card.Delta / math.Abs(m.before) * 100
+			}
+		}
+		cards = append(cards, card)
+	}
+	return cards
+}
+
 func (b *Builder) limitFromOptions(opts map[string]any) int {
 	if opts == nil {
 		return b.topLimit
@@ -154,7 +242,7 @@ func (b *Builder) limitFromOptions(opts map[string]any) int {
 	return b.topLimit
 }
 
-func (b *Builder) loadTopVariances(ctx context.Context, pack BoardPack, limit int) ([]variance.VarianceRow, string) {
+func (b *Builder) loadVarianceRows(ctx context.Context, pack BoardPack) ([]variance.VarianceRow, string) {
 	if pack.VarianceSnapshotID == nil || b.variance == nil {
 		return nil, ""
 	}
@@ -162,13 +250,44 @@ func (b *Builder) loadTopVariances(ctx context.Context, pack BoardPack, limit in
 	if err != nil {
 		return nil, fmt.Sprintf("Gagal memuat variance snapshot #%d: %v", *pack.VarianceSnapshotID, err)
 	}
-	sort.Slice(rows, func(i, j int) bool {
-		return math.Abs(rows[i].Variance) > math.Abs(rows[j].Variance)
+	return rows, ""
+}
+
+func topVariances(rows []variance.VarianceRow, limit int) []variance.VarianceRow {
+	sorted := append([]variance.VarianceRow(nil), rows...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return math.Abs(sorted[i].Variance) > math.Abs(sorted[j].Variance)
 	})
-	if limit > len(rows) {
-		limit = len(rows)
+	if limit > len(sorted) {
+		limit = len(sorted)
+	}
+	return sorted[:limit]
+}
+
+// narrative writes the exec summary sentences from the insights comparison
+// of the period's month and the variance snapshot rows.
+func (b *Builder) narrative(ctx context.Context, company Company, period Period, rows []variance.VarianceRow) ([]string, string) {
+	input := insights.NarrativeInput{}
+	warn := ""
+	if b.insights != nil {
+		month := period.EndDate.Format("2006-01")
+		result, err := b.insights.Load(ctx, insights.CompareFilters{From: month, To: month, CompanyID: &company.ID})
+		if err != nil {
+			warn = fmt.Sprintf("Insights gagal dimuat: %v", err)
+		} else {
+			input.Variance, input.Contribution = result.Variance, result.Contribution
+		}
+	}
+	for _, row := range rows {
+		input.Accounts = append(input.Accounts, insights.AccountVariance{
+			Code:        row.AccountCode,
+			Name:        row.AccountName,
+			Variance:    row.Variance,
+			VariancePct: row.VariancePct,
+			Flagged:     row.Flagged,
+		})
 	}
-	return rows[:limit], ""
+	return insights.Narrative(input), warn
 }
 
 func metadataInt64(meta map[string]any, key string) *int64 {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/reports"
 	"github.com/odyssey-erp/odyssey-erp/internal/analytics"
+	"github.com/odyssey-erp/odyssey-erp/internal/insights"
 	"github.com/odyssey-erp/odyssey-erp/internal/variance"
 )
 
@@ -35,12 +36,26 @@ func (s stubVariance) LoadSnapshotPayload(ctx context.Context, id int64) ([]vari
 
 type stubKPI struct {
 	summary analytics.KPISummary
+	periods map[string]analytics.KPISummary
 }
 
 func (s stubKPI) GetKPISummary(ctx context.Context, filter analytics.KPIFilter) (analytics.KPISummary, error) {
+	if s.periods != nil {
+		return s.periods[filter.Period], nil
+	}
 	return s.summary, nil
 }
 
+type stubInsights struct {
+	result  insights.Result
+	filters insights.CompareFilters
+}
+
+func (s *stubInsights) Load(ctx context.Context, filters insights.CompareFilters) (insights.Result, error) {
+	s.filters = filters
+	return s.result, nil
+}
+
 func TestBuilderBuildProducesSections(t *testing.T) {
 	repo := &stubRepo{
 		balances: []reports.AccountBalance{
@@ -60,11 +75,13 @@ func TestBuilderBuildProducesSections(t *testing.T) {
 		},
 	}
 	varianceRows := []variance.VarianceRow{
-		{AccountCode: "6000", Variance: 100},
+		{AccountCode: "6000", AccountName: "Marketing", Variance: 100, VariancePct: 40, Flagged: true},
 		{AccountCode: "7000", Variance: 50},
 		{AccountCode: "8000", Variance: 25},
 	}
 	builder := NewBuilder(repo, stubVariance{rows: varianceRows}, stubKPI{summary: analytics.KPISummary{Revenue: 1500, CashIn: 900, CashOut: 400}})
+	insightsStub := &stubInsights{result: insights.Result{Variance: []insights.VarianceMetric{{Metric: "Revenue", MoMPct: 8, YoYPct: 2}}}}
+	builder.WithInsights(insightsStub)
 	pack := BoardPack{
 		ID:           99,
 		CompanyID:    1,
@@ -108,6 +125,11 @@ func TestBuilderBuildProducesSections(t *testing.T) {
 	}
 	require.NotNil(t, exec.Exec)
 	require.Equal(t, int64(42), *exec.Exec.RequestedBy)
+	require.Equal(t, "2024-01", insightsStub.filters.To)
+	require.Equal(t, []string{
+		"Revenue up 8.0% MoM.",
+		"1 account breached its variance threshold; the largest is 6000 Marketing, up 40.0%.",
+	}, exec.Exec.Narrative)
 	require.NotZero(t, data.GeneratedAt)
 }
 
@@ -140,3 +162,60 @@ func TestBuilderBuildWithoutVarianceSnapshot(t *testing.T) {
 	require.Len(t, data.Sections, len(repo.template.Sections))
 	require.Nil(t, pack.VarianceSnapshotID)
 }
+
+func TestBuilderBuildComparesKPIWithPriorPeriod(t *testing.T) {
+	repo := &stubRepo{template: Template{ID: 3, Sections: []TemplateSection{{Type: SectionExecSummary, Title: "Executive"}}}}
+	pack := BoardPack{
+		ID:          102,
+		CompanyID:   1,
+		PeriodID:    12,
+		PeriodName:  "2024-03",
+		PeriodStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		TemplateID:  repo.template.ID,
+		Template:    &repo.template,
+	}
+
+	kpi := stubKPI{periods: map[string]analytics.KPISummary{
+		"2024-03": {Revenue: 1200, NetProfit: -50, Opex: 300, CashIn: 400},
+		"2024-02": {Revenue: 1000, NetProfit: 100, Opex: 300},
+	}}
+	data, err := NewBuilder(repo, nil, kpi).Build(context.Background(), pack)
+	require.NoError(t, err)
+	exec := data.Sections[0].Exec
+	require.Equal(t, "2024-02", exec.PriorPeriod)
+	require.Len(t, exec.KPICards, 6)
+
+	revenue := exec.KPICards[0]
+	require.True(t, revenue.HasPrior)
+	require.Equal(t, float64(200), revenue.Delta)
+	require.True(t, revenue.HasChangePct)
+	require.InDelta(t, 20, revenue.ChangePct, 0.0001)
+	require.Equal(t, DirectionUp, revenue.Direction)
+
+	profit := exec.KPICards[1]
+	require.Equal(t, DirectionDown, profit.Direction)
+	require.InDelta(t, -150, profit.ChangePct, 0.0001)
+
+	require.Equal(t, DirectionFlat, exec.KPICards[2].Direction)
+
+	cashIn := exec.KPICards[4]
+	require.Equal(t, "Cash In", cashIn.Label)
+	require.True(t, cashIn.HasPrior)
+	require.Equal(t, float64(400), cashIn.Delta)
+	require.False(t, cashIn.HasChangePct)
+
+	delete(kpi.periods, "2024-02")
+	data, err = NewBuilder(repo, nil, kpi).Build(context.Background(), pack)
+	require.NoError(t, err)
+	for _, card := range data.Sections[0].Exec.KPICards {
+		require.False(t, card.HasPrior, card.Label)
+		require.False(t, card.HasChangePct, card.Label)
+	}
+}
+
+func TestPriorPeriodCrossesYear(t *testing.T) {
+	name, asOf := priorPeriod(Period{StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	require.Equal(t, "2023-12", name)
+	require.Equal(t, time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), asOf)
+}
@@ -2,6 +2,7 @@ package boardpack
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -143,7 +144,12 @@ type ExecSummaryData struct {
 	RequestedBy   *int64
 	VarianceLabel string
 	KPISummary    KPISummary
-	Status        Status
+	// PriorPeriod names the month the KPI cards are compared against.
+	PriorPeriod string
+	KPICards    []KPICard
+	Status      Status
+	// Narrative is the rule-based plain-language summary of the period.
+	Narrative []string
 }
 
 // KPISummary contains selective key metrics surfaced in the exec summary.
@@ -158,6 +164,27 @@ type KPISummary struct {
 	APOutstanding float64
 }
 
+// KPI change directions.
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+	DirectionFlat = "flat"
+)
+
+// KPICard compares one exec summary metric with the prior period. HasPrior
+// is false when the prior period has no KPI data, and HasChangePct is false
+// when the prior value is zero, so neither renders a misleading change.
+type KPICard struct {
+	Label        string
+	Current      float64
+	Prior        float64
+	Delta        float64
+	ChangePct    float64
+	Direction    string
+	HasPrior     bool
+	HasChangePct bool
+}
+
 // CashflowSummary highlights the cash movement for the selected period.
 type CashflowSummary struct {
 	CashIn  float64
@@ -200,6 +227,7 @@ var (
 	ErrCompanyNotFound   = errors.New("boardpack: company not found")
 	ErrPeriodNotFound    = errors.New("boardpack: period not found")
 	ErrInvalidStatus     = errors.New("boardpack: invalid status transition")
+	ErrScheduleNotFound  = errors.New("boardpack: schedule not found")
 )
 
 // NormaliseStatus uppercases and trims the provided status string.
@@ -212,3 +240,89 @@ func NormaliseStatus(v string) Status {
 		return StatusPending
 	}
 }
+
+// ScheduleTrigger selects when a schedule generates its board pack.
+type ScheduleTrigger string
+
+const (
+	// TriggerPeriodClose generates the pack when the company's period is hard closed.
+	TriggerPeriodClose ScheduleTrigger = "PERIOD_CLOSE"
+	// TriggerMonthly generates the pack for the last ended period on DayOfMonth.
+	TriggerMonthly ScheduleTrigger = "MONTHLY"
+)
+
+// Schedule auto-generates a board pack for a company/template combination.
+type Schedule struct {
+	ID           int64
+	CompanyID    int64
+	CompanyName  string
+	TemplateID   int64
+	TemplateName string
+	Trigger      ScheduleTrigger
+	DayOfMonth   int
+	Recipients   []string
+	IsActive     bool
+	CreatedBy    int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ScheduleInput defines the payload accepted when saving a schedule.
+type ScheduleInput struct {
+	CompanyID  int64
+	TemplateID int64
+	Trigger    ScheduleTrigger
+	DayOfMonth int
+	Recipients []string
+	ActorID    int64
+}
+
+// Validate ensures the schedule can be stored.
+func (in ScheduleInput) Validate() error {
+	if in.CompanyID <= 0 {
+		return errors.New("boardpack: company id required")
+	}
+	if in.TemplateID <= 0 {
+		return errors.New("boardpack: template id required")
+	}
+	if in.ActorID <= 0 {
+		return errors.New("boardpack: actor id required")
+	}
+	switch in.Trigger {
+	case TriggerPeriodClose:
+	case TriggerMonthly:
+		if in.DayOfMonth < 1 || in.DayOfMonth > 28 {
+			return errors.New("boardpack: day of month must be between 1 and 28")
+		}
+	default:
+		return fmt.Errorf("boardpack: unknown schedule trigger %q", in.Trigger)
+	}
+	for _, email := range in.Recipients {
+		if !strings.Contains(email, "@") {
+			return fmt.Errorf("boardpack: invalid recipient %q", email)
+		}
+	}
+	return nil
+}
+
+// ParseRecipients splits a free-form recipient list on commas, semicolons and
+// whitespace, dropping duplicates.
+func ParseRecipients(raw string) []string {
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+	out := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		email := strings.ToLower(strings.TrimSpace(field))
+		if email == "" {
+			continue
+		}
+		if _, dup := seen[email]; dup {
+			continue
+		}
+		seen[email] = struct{}{}
+		out = append(out, email)
+	}
+	return out
+}
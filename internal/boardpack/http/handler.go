@@ -12,6 +12,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/boardpack"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -22,6 +23,7 @@ import (
 type Handler struct {
 	logger    *slog.Logger
 	service   *boardpack.Service
+	scheduler *boardpack.Scheduler
 	templates *view.Engine
 	csrf      *shared.CSRFManager
 	rbac      rbac.Middleware
@@ -29,8 +31,8 @@ type Handler struct {
 }
 
 // NewHandler constructs a Handler value.
-func NewHandler(logger *slog.Logger, service *boardpack.Service, templates *view.Engine, csrf *shared.CSRFManager, rbac rbac.Middleware, jobsClient *jobs.Client) *Handler {
-	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, rbac: rbac, jobs: jobsClient}
+func NewHandler(logger *slog.Logger, service *boardpack.Service, scheduler *boardpack.Scheduler, templates *view.Engine, csrf *shared.CSRFManager, rbac rbac.Middleware, jobsClient *jobs.Client) *Handler {
+	return &Handler{logger: logger, service: service, scheduler: scheduler, templates: templates, csrf: csrf, rbac: rbac, jobs: jobsClient}
 }
 
 // MountRoutes registers HTTP routes.
@@ -40,6 +42,11 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Get("/", h.list)
 		r.Get("/new", h.newForm)
 		r.Post("/", h.create)
+		r.Get("/schedules", h.schedules)
+		r.Post("/schedules", h.saveSchedule)
+		r.Post("/schedules/{id}/run", h.runSchedule)
+		r.Post("/schedules/{id}/toggle", h.toggleSchedule)
+		r.Post("/schedules/{id}/delete", h.deleteSchedule)
 		r.Get("/{id}", h.detail)
 		r.Get("/{id}/download", h.download)
 	})
@@ -64,7 +71,7 @@ type newPageData struct {
 // list renders board pack history.
 func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
 	filter := boardpack.ListFilter{
-		CompanyID: parseInt64(r.URL.Query().Get("company_id")),
+		CompanyID: shared.ScopedCompanyID(r.Context(), parseInt64(r.URL.Query().Get("company_id")), 0),
 		PeriodID:  parseInt64(r.URL.Query().Get("period_id")),
 		Limit:     50,
 	}
@@ -73,8 +80,8 @@ func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
 	}
 	packs, err := h.service.List(r.Context(), filter)
 	if err != nil {
-		h.logger.Error("list board packs", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list board packs", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	companies, _ := h.service.ListCompanies(r.Context())
@@ -94,20 +101,20 @@ func (h *Handler) newForm(w http.ResponseWriter, r *http.Request) {
 	companyID := parseInt64(r.URL.Query().Get("company_id"))
 	companies, err := h.service.ListCompanies(r.Context())
 	if err != nil {
-		h.logger.Error("list companies", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list companies", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	periods, err := h.service.ListPeriods(r.Context(), companyID, 36)
 	if err != nil {
-		h.logger.Error("list periods", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list periods", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	templates, err := h.service.ListTemplates(r.Context())
 	if err != nil {
-		h.logger.Error("list templates", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list templates", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	snapshots, _ := h.service.ListVarianceSnapshots(r.Context(), companyID, 20)
@@ -124,7 +131,7 @@ func (h *Handler) newForm(w http.ResponseWriter, r *http.Request) {
 // create handles POST submission for a new board pack request.
 func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	req := boardpack.CreateRequest{
@@ -137,13 +144,13 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 	}
 	pack, err := h.service.Create(r.Context(), req)
 	if err != nil {
-		h.logger.Warn("create board pack", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "create board pack", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/board-packs/new", "danger", shared.UserSafeMessage(err))
 		return
 	}
 	if h.jobs != nil {
 		if _, err := h.jobs.EnqueueBoardPack(r.Context(), pack.ID); err != nil && h.logger != nil {
-			h.logger.Warn("enqueue board pack", slog.Any("error", err))
+			h.logger.WarnContext(r.Context(), "enqueue board pack", slog.Any("error", err))
 		}
 	}
 	h.redirectWithFlash(w, r, "/board-packs/"+strconv.FormatInt(pack.ID, 10), "success", "Board pack dikirim ke antrian")
@@ -158,8 +165,8 @@ func (h *Handler) detail(w http.ResponseWriter, r *http.Request) {
 			http.NotFound(w, r)
 			return
 		}
-		h.logger.Error("get board pack", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "get board pack", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	h.render(w, r, "pages/boardpacks/detail.html", "Board Pack Detail", map[string]any{"Pack": pack})
@@ -174,25 +181,25 @@ func (h *Handler) download(w http.ResponseWriter, r *http.Request) {
 			http.NotFound(w, r)
 			return
 		}
-		h.logger.Error("download board pack", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "download board pack", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	if pack.Status != boardpack.StatusReady || pack.FilePath == "" {
-		http.Error(w, "file belum siap", http.StatusBadRequest)
+		httpx.Error(w, r, "file belum siap", http.StatusBadRequest)
 		return
 	}
 	file, err := os.Open(pack.FilePath)
 	if err != nil {
-		h.logger.Error("open board pack", slog.Any("error", err), slog.String("path", pack.FilePath))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "open board pack", slog.Any("error", err), slog.String("path", pack.FilePath))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	defer file.Close()
 	w.Header().Set("Content-Type", "application/pdf")
 	w.Header().Set("Content-Disposition", "attachment; filename=board-pack-"+strconv.FormatInt(pack.ID, 10)+".pdf")
 	if _, err := io.Copy(w, file); err != nil && h.logger != nil {
-		h.logger.Warn("stream board pack", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "stream board pack", slog.Any("error", err))
 	}
 }
 
@@ -211,7 +218,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, tpl, title stri
 		Data:        data,
 	}
 	if err := h.templates.Render(w, tpl, viewData); err != nil && h.logger != nil {
-		h.logger.Error("render boardpack template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render boardpack template", slog.Any("error", err))
 	}
 }
 
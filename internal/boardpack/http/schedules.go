@@ -0,0 +1,126 @@
+package boardpackhttp
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/boardpack"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type schedulesPageData struct {
+	Schedules []boardpack.Schedule
+	Companies []boardpack.Company
+	Templates []boardpack.Template
+	Triggers  []boardpack.ScheduleTrigger
+}
+
+// schedules renders the auto-generation schedules.
+func (h *Handler) schedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.service.ListSchedules(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list board pack schedules", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	companies, _ := h.service.ListCompanies(r.Context())
+	templates, _ := h.service.ListTemplates(r.Context())
+	data := schedulesPageData{
+		Schedules: schedules,
+		Companies: companies,
+		Templates: templates,
+		Triggers:  []boardpack.ScheduleTrigger{boardpack.TriggerPeriodClose, boardpack.TriggerMonthly},
+	}
+	h.render(w, r, "pages/boardpacks/schedules.html", "Board Pack Schedules", data)
+}
+
+// saveSchedule creates or replaces the schedule for a company/template pair.
+func (h *Handler) saveSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	day, _ := strconv.Atoi(strings.TrimSpace(r.PostFormValue("day_of_month")))
+	in := boardpack.ScheduleInput{
+		CompanyID:  parseInt64(r.PostFormValue("company_id")),
+		TemplateID: parseInt64(r.PostFormValue("template_id")),
+		Trigger:    boardpack.ScheduleTrigger(strings.ToUpper(strings.TrimSpace(r.PostFormValue("trigger")))),
+		DayOfMonth: day,
+		Recipients: boardpack.ParseRecipients(r.PostFormValue("recipients")),
+		ActorID:    currentUser(r),
+	}
+	if _, err := h.service.SaveSchedule(r.Context(), in); err != nil {
+		h.logger.WarnContext(r.Context(), "save board pack schedule", slog.Any("error", err))
+		h.redirectWithFlash(w, r, "/board-packs/schedules", "danger", shared.UserSafeMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, "/board-packs/schedules", "success", "Jadwal board pack disimpan")
+}
+
+// runSchedule generates a schedule's pack for the latest ended period. An
+// existing pack is reused unless force is checked.
+func (h *Handler) runSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if h.scheduler == nil {
+		httpx.Error(w, r, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		return
+	}
+	force := r.PostFormValue("force") != ""
+	pack, created, err := h.scheduler.RunNow(r.Context(), parseInt64(chi.URLParam(r, "id")), force)
+	if err != nil {
+		if errors.Is(err, boardpack.ErrScheduleNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.WarnContext(r.Context(), "run board pack schedule", slog.Any("error", err))
+		h.redirectWithFlash(w, r, "/board-packs/schedules", "danger", shared.UserSafeMessage(err))
+		return
+	}
+	location := "/board-packs/" + strconv.FormatInt(pack.ID, 10)
+	if !created {
+		h.redirectWithFlash(w, r, location, "warning", "Board pack untuk period ini sudah ada; centang Force untuk membuat ulang")
+		return
+	}
+	h.redirectWithFlash(w, r, location, "success", "Board pack dikirim ke antrian")
+}
+
+// toggleSchedule pauses or resumes a schedule.
+func (h *Handler) toggleSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	active := r.PostFormValue("active") == "true"
+	if err := h.service.SetScheduleActive(r.Context(), parseInt64(chi.URLParam(r, "id")), active); err != nil {
+		h.scheduleError(w, r, "toggle board pack schedule", err)
+		return
+	}
+	h.redirectWithFlash(w, r, "/board-packs/schedules", "success", "Jadwal board pack diperbarui")
+}
+
+// deleteSchedule removes a schedule.
+func (h *Handler) deleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSchedule(r.Context(), parseInt64(chi.URLParam(r, "id"))); err != nil {
+		h.scheduleError(w, r, "delete board pack schedule", err)
+		return
+	}
+	h.redirectWithFlash(w, r, "/board-packs/schedules", "success", "Jadwal board pack dihapus")
+}
+
+func (h *Handler) scheduleError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if errors.Is(err, boardpack.ErrScheduleNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	h.logger.ErrorContext(r.Context(), msg, slog.Any("error", err))
+	httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -15,12 +15,20 @@ import (
 	"github.com/odyssey-erp/odyssey-erp/jobs"
 )
 
-// JobConfig wires dependencies required by the worker job.
+// EmailQueue enqueues outbound emails for the worker.
+type EmailQueue interface {
+	EnqueueSendEmail(ctx context.Context, payload jobs.SendEmailPayload) (*asynq.TaskInfo, error)
+}
+
+// JobConfig wires dependencies required by the worker job. Mailer and BaseURL
+// are optional; without a mailer no ready notifications are sent.
 type JobConfig struct {
 	Service    *Service
 	Builder    *Builder
 	Renderer   *Renderer
 	StorageDir string
+	Mailer     EmailQueue
+	BaseURL    string
 	Logger     *slog.Logger
 }
 
@@ -30,12 +38,22 @@ type Job struct {
 	builder    *Builder
 	renderer   *Renderer
 	storageDir string
+	mailer     EmailQueue
+	baseURL    string
 	logger     *slog.Logger
 }
 
 // NewJob constructs a Job handler.
 func NewJob(cfg JobConfig) *Job {
-	return &Job{service: cfg.Service, builder: cfg.Builder, renderer: cfg.Renderer, storageDir: cfg.StorageDir, logger: cfg.Logger}
+	return &Job{
+		service:    cfg.Service,
+		builder:    cfg.Builder,
+		renderer:   cfg.Renderer,
+		storageDir: cfg.StorageDir,
+		mailer:     cfg.Mailer,
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		logger:     cfg.Logger,
+	}
 }
 
 // Handle fulfils the asynq.HandlerFunc contract.
@@ -89,11 +107,49 @@ func (j *Job) Handle(ctx context.Context, task *asynq.Task) error {
 		meta["warnings"] = data.Warnings
 	}
 	meta["generated_at"] = data.GeneratedAt
-	if _, err := j.service.MarkReady(ctx, pack, path, rendered.Length, nil, meta); err != nil {
+	ready, err := j.service.MarkReady(ctx, pack, path, rendered.Length, nil, meta)
+	if err != nil {
 		return err
 	}
 	if j.logger != nil {
-		j.logger.Info("board pack ready", slog.Int64("board_pack_id", pack.ID), slog.String("file", path))
+		j.logger.InfoContext(ctx, "board pack ready", slog.Int64("board_pack_id", pack.ID), slog.String("file", path))
+	}
+	j.notifyReady(ctx, ready)
+	return nil
+}
+
+// notifyReady emails the recipients a schedule stored on the pack. Failures
+// are logged; the pack stays READY.
+func (j *Job) notifyReady(ctx context.Context, pack BoardPack) {
+	if j.mailer == nil {
+		return
+	}
+	recipients := metadataStrings(pack.Metadata["notify"])
+	if len(recipients) == 0 {
+		return
+	}
+	subject := fmt.Sprintf("Board pack ready: %s %s", pack.CompanyName, pack.PeriodName)
+	body := fmt.Sprintf("The %s board pack for %s, period %s, is ready.\n\n%s/board-packs/%d\n",
+		pack.TemplateName, pack.CompanyName, pack.PeriodName, j.baseURL, pack.ID)
+	for _, to := range recipients {
+		if _, err := j.mailer.EnqueueSendEmail(ctx, jobs.SendEmailPayload{To: to, Subject: subject, Body: body}); err != nil && j.logger != nil {
+			j.logger.WarnContext(ctx, "enqueue board pack email", slog.Int64("board_pack_id", pack.ID), slog.String("to", to), slog.Any("error", err))
+		}
+	}
+}
+
+func metadataStrings(v any) []string {
+	switch vals := v.(type) {
+	case []string:
+		return vals
+	case []any:
+		out := make([]string, 0, len(vals))
+		for _, val := range vals {
+			if s, ok := val.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, s)
+			}
+		}
+		return out
 	}
 	return nil
 }
@@ -493,3 +493,147 @@ func mapBoardPackFromList(row sqlc.ListBoardPacksRow) BoardPack {
 
 	return bp
 }
+
+// Schedules
+
+const scheduleColumns = `s.id, s.company_id, COALESCE(c.name, ''), s.template_id, tpl.name, s.trigger,
+    COALESCE(s.day_of_month, 0), s.recipients, s.is_active, s.created_by, s.created_at, s.updated_at
+FROM board_pack_schedules s
+JOIN board_pack_templates tpl ON tpl.id = s.template_id
+LEFT JOIN companies c ON c.id = s.company_id`
+
+// ListSchedules returns every schedule ordered by company and template.
+func (r *Repository) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	return r.querySchedules(ctx, `SELECT `+scheduleColumns+` ORDER BY c.name, tpl.name`)
+}
+
+// ListActiveSchedules returns active schedules for a trigger, optionally
+// limited to one company.
+func (r *Repository) ListActiveSchedules(ctx context.Context, trigger ScheduleTrigger, companyID int64) ([]Schedule, error) {
+	return r.querySchedules(ctx, `SELECT `+scheduleColumns+`
+WHERE s.is_active AND s.trigger = $1 AND ($2::bigint = 0 OR s.company_id = $2)
+ORDER BY s.id`, string(trigger), companyID)
+}
+
+// GetSchedule loads a schedule by id.
+func (r *Repository) GetSchedule(ctx context.Context, id int64) (Schedule, error) {
+	schedules, err := r.querySchedules(ctx, `SELECT `+scheduleColumns+` WHERE s.id = $1`, id)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if len(schedules) == 0 {
+		return Schedule{}, ErrScheduleNotFound
+	}
+	return schedules[0], nil
+}
+
+// UpsertSchedule stores the schedule for a company/template pair, replacing
+// the trigger and recipients of an existing one.
+func (r *Repository) UpsertSchedule(ctx context.Context, in ScheduleInput) (int64, error) {
+	var day pgtype.Int4
+	if in.Trigger == TriggerMonthly {
+		day = pgtype.Int4{Int32: int32(in.DayOfMonth), Valid: true}
+	}
+	recipients := in.Recipients
+	if recipients == nil {
+		recipients = []string{}
+	}
+	var id int64
+	err := r.pool.QueryRow(ctx, `INSERT INTO board_pack_schedules (company_id, template_id, trigger, day_of_month, recipients, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (company_id, template_id) DO UPDATE
+SET trigger = EXCLUDED.trigger, day_of_month = EXCLUDED.day_of_month, recipients = EXCLUDED.recipients,
+    is_active = TRUE, updated_at = NOW()
+RETURNING id`, in.CompanyID, in.TemplateID, string(in.Trigger), day, recipients, in.ActorID).Scan(&id)
+	return id, err
+}
+
+// SetScheduleActive pauses or resumes a schedule.
+func (r *Repository) SetScheduleActive(ctx context.Context, id int64, active bool) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE board_pack_schedules SET is_active = $2, updated_at = NOW() WHERE id = $1`, id, active)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// DeleteSchedule removes a schedule; packs it generated are kept.
+func (r *Repository) DeleteSchedule(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM board_pack_schedules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// FindBoardPackForPeriod returns the latest pack for the company, period and
+// template that has not failed.
+func (r *Repository) FindBoardPackForPeriod(ctx context.Context, companyID, periodID, templateID int64) (BoardPack, bool, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT id FROM board_packs
+WHERE company_id = $1 AND period_id = $2 AND template_id = $3 AND status <> 'FAILED'
+ORDER BY created_at DESC LIMIT 1`, companyID, periodID, templateID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return BoardPack{}, false, nil
+	}
+	if err != nil {
+		return BoardPack{}, false, err
+	}
+	pack, err := r.GetBoardPack(ctx, id)
+	if err != nil {
+		return BoardPack{}, false, err
+	}
+	return pack, true, nil
+}
+
+// LatestEndedPeriod returns the company's most recent period ending before asOf.
+func (r *Repository) LatestEndedPeriod(ctx context.Context, companyID int64, asOf time.Time) (Period, bool, error) {
+	var (
+		p          Period
+		start, end pgtype.Date
+		status     string
+	)
+	err := r.pool.QueryRow(ctx, `SELECT id, name, start_date, end_date, status::text, COALESCE(company_id, 0)
+FROM accounting_periods
+WHERE (company_id = $1 OR company_id IS NULL) AND end_date < $2::date
+ORDER BY end_date DESC, company_id NULLS LAST
+LIMIT 1`, companyID, asOf).Scan(&p.ID, &p.Name, &start, &end, &status, &p.CompanyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Period{}, false, nil
+	}
+	if err != nil {
+		return Period{}, false, err
+	}
+	p.StartDate, p.EndDate, p.Status = start.Time, end.Time, status
+	return p, true, nil
+}
+
+func (r *Repository) querySchedules(ctx context.Context, query string, args ...any) ([]Schedule, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schedules []Schedule
+	for rows.Next() {
+		var (
+			s       Schedule
+			trigger string
+			day     int32
+		)
+		if err := rows.Scan(&s.ID, &s.CompanyID, &s.CompanyName, &s.TemplateID, &s.TemplateName, &trigger,
+			&day, &s.Recipients, &s.IsActive, &s.CreatedBy, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		s.Trigger = ScheduleTrigger(trigger)
+		s.DayOfMonth = int(day)
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
@@ -0,0 +1,161 @@
+package boardpack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// PackQueue enqueues board pack generation tasks.
+type PackQueue interface {
+	EnqueueBoardPack(ctx context.Context, boardPackID int64) (*asynq.TaskInfo, error)
+}
+
+type scheduleRepository interface {
+	ListActiveSchedules(ctx context.Context, trigger ScheduleTrigger, companyID int64) ([]Schedule, error)
+	GetSchedule(ctx context.Context, id int64) (Schedule, error)
+	FindBoardPackForPeriod(ctx context.Context, companyID, periodID, templateID int64) (BoardPack, bool, error)
+	LatestEndedPeriod(ctx context.Context, companyID int64, asOf time.Time) (Period, bool, error)
+}
+
+type packCreator interface {
+	Create(ctx context.Context, req CreateRequest) (BoardPack, error)
+}
+
+// Scheduler creates and enqueues board packs for configured schedules. A pack
+// that already exists for the period and template is reused unless forced.
+type Scheduler struct {
+	repo   scheduleRepository
+	packs  packCreator
+	queue  PackQueue
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewScheduler constructs a Scheduler.
+func NewScheduler(repo *Repository, service *Service, queue PackQueue, logger *slog.Logger) *Scheduler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Scheduler{repo: repo, packs: service, queue: queue, logger: logger, now: time.Now}
+}
+
+// Generate creates the schedule's pack for a period and enqueues it. When a
+// pack that has not failed already exists it is returned with created false,
+// unless force is set.
+func (s *Scheduler) Generate(ctx context.Context, schedule Schedule, periodID int64, force bool) (BoardPack, bool, error) {
+	if !force {
+		existing, ok, err := s.repo.FindBoardPackForPeriod(ctx, schedule.CompanyID, periodID, schedule.TemplateID)
+		if err != nil {
+			return BoardPack{}, false, err
+		}
+		if ok {
+			return existing, false, nil
+		}
+	}
+	pack, err := s.packs.Create(ctx, CreateRequest{
+		CompanyID:  schedule.CompanyID,
+		PeriodID:   periodID,
+		TemplateID: schedule.TemplateID,
+		ActorID:    schedule.CreatedBy,
+		Metadata: map[string]any{
+			"schedule_id": schedule.ID,
+			"trigger":     string(schedule.Trigger),
+			"notify":      schedule.Recipients,
+		},
+	})
+	if err != nil {
+		return BoardPack{}, false, err
+	}
+	if s.queue != nil {
+		if _, err := s.queue.EnqueueBoardPack(ctx, pack.ID); err != nil {
+			return pack, true, fmt.Errorf("enqueue board pack %d: %w", pack.ID, err)
+		}
+	}
+	return pack, true, nil
+}
+
+// PeriodHardClosed runs the company's period-close schedules. Failures are
+// logged so they never undo the close itself.
+func (s *Scheduler) PeriodHardClosed(ctx context.Context, companyID, periodID int64) {
+	schedules, err := s.repo.ListActiveSchedules(ctx, TriggerPeriodClose, companyID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "list board pack schedules", slog.Int64("company_id", companyID), slog.Any("error", err))
+		return
+	}
+	for _, schedule := range schedules {
+		s.run(ctx, schedule, periodID)
+	}
+}
+
+// RunDue generates packs for monthly schedules falling on now's day, for the
+// latest period that ended before now. It returns how many packs were created.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) (int, error) {
+	schedules, err := s.repo.ListActiveSchedules(ctx, TriggerMonthly, 0)
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for _, schedule := range schedules {
+		if schedule.DayOfMonth != now.Day() {
+			continue
+		}
+		period, ok, err := s.repo.LatestEndedPeriod(ctx, schedule.CompanyID, now)
+		if err != nil {
+			return created, err
+		}
+		if !ok {
+			s.logger.WarnContext(ctx, "board pack schedule has no ended period", slog.Int64("schedule_id", schedule.ID))
+			continue
+		}
+		if s.run(ctx, schedule, period.ID) {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// RunNow generates a schedule's pack for the latest ended period on demand.
+func (s *Scheduler) RunNow(ctx context.Context, scheduleID int64, force bool) (BoardPack, bool, error) {
+	schedule, err := s.repo.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return BoardPack{}, false, err
+	}
+	period, ok, err := s.repo.LatestEndedPeriod(ctx, schedule.CompanyID, s.now())
+	if err != nil {
+		return BoardPack{}, false, err
+	}
+	if !ok {
+		return BoardPack{}, false, ErrPeriodNotFound
+	}
+	return s.Generate(ctx, schedule, period.ID, force)
+}
+
+// Handle fulfils the asynq.HandlerFunc contract for the daily schedule task.
+func (s *Scheduler) Handle(ctx context.Context, _ *asynq.Task) error {
+	if s == nil || s.repo == nil || s.packs == nil {
+		return errors.New("boardpack scheduler not configured")
+	}
+	created, err := s.RunDue(ctx, s.now())
+	if err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "board pack schedules processed", slog.Int("created", created))
+	return nil
+}
+
+func (s *Scheduler) run(ctx context.Context, schedule Schedule, periodID int64) bool {
+	pack, created, err := s.Generate(ctx, schedule, periodID, false)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "scheduled board pack", slog.Int64("schedule_id", schedule.ID), slog.Int64("period_id", periodID), slog.Any("error", err))
+		return created
+	}
+	if !created {
+		s.logger.InfoContext(ctx, "scheduled board pack already exists", slog.Int64("schedule_id", schedule.ID), slog.Int64("board_pack_id", pack.ID))
+	}
+	return created
+}
@@ -0,0 +1,127 @@
+package boardpack
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+type stubScheduleRepo struct {
+	schedules []Schedule
+	existing  map[int64]BoardPack
+	period    Period
+}
+
+func (s *stubScheduleRepo) ListActiveSchedules(ctx context.Context, trigger ScheduleTrigger, companyID int64) ([]Schedule, error) {
+	var out []Schedule
+	for _, sched := range s.schedules {
+		if sched.Trigger == trigger && (companyID == 0 || sched.CompanyID == companyID) {
+			out = append(out, sched)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubScheduleRepo) GetSchedule(ctx context.Context, id int64) (Schedule, error) {
+	for _, sched := range s.schedules {
+		if sched.ID == id {
+			return sched, nil
+		}
+	}
+	return Schedule{}, ErrScheduleNotFound
+}
+
+func (s *stubScheduleRepo) FindBoardPackForPeriod(ctx context.Context, companyID, periodID, templateID int64) (BoardPack, bool, error) {
+	pack, ok := s.existing[periodID]
+	return pack, ok, nil
+}
+
+func (s *stubScheduleRepo) LatestEndedPeriod(ctx context.Context, companyID int64, asOf time.Time) (Period, bool, error) {
+	return s.period, s.period.ID != 0, nil
+}
+
+type stubPackCreator struct {
+	created []CreateRequest
+}
+
+func (s *stubPackCreator) Create(ctx context.Context, req CreateRequest) (BoardPack, error) {
+	s.created = append(s.created, req)
+	return BoardPack{ID: int64(100 + len(s.created)), CompanyID: req.CompanyID, PeriodID: req.PeriodID, TemplateID: req.TemplateID}, nil
+}
+
+type stubPackQueue struct {
+	enqueued []int64
+}
+
+func (s *stubPackQueue) EnqueueBoardPack(ctx context.Context, id int64) (*asynq.TaskInfo, error) {
+	s.enqueued = append(s.enqueued, id)
+	return &asynq.TaskInfo{}, nil
+}
+
+func newTestScheduler(repo *stubScheduleRepo) (*Scheduler, *stubPackCreator, *stubPackQueue) {
+	packs := &stubPackCreator{}
+	queue := &stubPackQueue{}
+	return &Scheduler{repo: repo, packs: packs, queue: queue, logger: slog.Default(), now: time.Now}, packs, queue
+}
+
+func TestSchedulerPeriodCloseSkipsExistingPack(t *testing.T) {
+	repo := &stubScheduleRepo{
+		schedules: []Schedule{
+			{ID: 1, CompanyID: 7, TemplateID: 3, Trigger: TriggerPeriodClose, Recipients: []string{"cfo@example.com"}, CreatedBy: 9},
+			{ID: 2, CompanyID: 8, TemplateID: 3, Trigger: TriggerPeriodClose, CreatedBy: 9},
+		},
+		existing: map[int64]BoardPack{},
+	}
+	scheduler, packs, queue := newTestScheduler(repo)
+
+	scheduler.PeriodHardClosed(context.Background(), 7, 42)
+	require.Len(t, packs.created, 1)
+	req := packs.created[0]
+	require.Equal(t, int64(42), req.PeriodID)
+	require.Equal(t, int64(9), req.ActorID)
+	require.Equal(t, []string{"cfo@example.com"}, req.Metadata["notify"])
+	require.Equal(t, []int64{101}, queue.enqueued)
+
+	repo.existing[42] = BoardPack{ID: 101, Status: StatusReady}
+	scheduler.PeriodHardClosed(context.Background(), 7, 42)
+	require.Len(t, packs.created, 1, "existing pack must not be regenerated")
+
+	pack, created, err := scheduler.Generate(context.Background(), repo.schedules[0], 42, true)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, int64(102), pack.ID)
+}
+
+func TestSchedulerRunDueMatchesDayOfMonth(t *testing.T) {
+	repo := &stubScheduleRepo{
+		schedules: []Schedule{
+			{ID: 1, CompanyID: 7, TemplateID: 3, Trigger: TriggerMonthly, DayOfMonth: 5, CreatedBy: 9},
+			{ID: 2, CompanyID: 7, TemplateID: 4, Trigger: TriggerMonthly, DayOfMonth: 6, CreatedBy: 9},
+			{ID: 3, CompanyID: 7, TemplateID: 5, Trigger: TriggerPeriodClose, CreatedBy: 9},
+		},
+		existing: map[int64]BoardPack{},
+		period:   Period{ID: 31, CompanyID: 7},
+	}
+	scheduler, packs, _ := newTestScheduler(repo)
+
+	created, err := scheduler.RunDue(context.Background(), time.Date(2026, 3, 5, 6, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 1, created)
+	require.Equal(t, int64(3), packs.created[0].TemplateID)
+	require.Equal(t, int64(31), packs.created[0].PeriodID)
+}
+
+func TestScheduleInputValidate(t *testing.T) {
+	in := ScheduleInput{CompanyID: 1, TemplateID: 2, ActorID: 3, Trigger: TriggerMonthly, DayOfMonth: 31}
+	require.Error(t, in.Validate())
+	in.DayOfMonth = 5
+	require.NoError(t, in.Validate())
+	in.Recipients = ParseRecipients("CFO@example.com; board@example.com cfo@example.com")
+	require.Equal(t, []string{"cfo@example.com", "board@example.com"}, in.Recipients)
+	in.Recipients = append(in.Recipients, "not-an-email")
+	require.Error(t, in.Validate())
+}
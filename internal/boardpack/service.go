@@ -131,3 +131,40 @@ func (s *Service) MarkFailed(ctx context.Context, id int64, errMessage string) e
 	}
 	return s.repo.MarkFailed(ctx, id, errMessage)
 }
+
+// ListSchedules returns every auto-generation schedule.
+func (s *Service) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	return s.repo.ListSchedules(ctx)
+}
+
+// SaveSchedule creates or replaces the schedule for a company/template pair.
+func (s *Service) SaveSchedule(ctx context.Context, in ScheduleInput) (Schedule, error) {
+	if err := in.Validate(); err != nil {
+		return Schedule{}, err
+	}
+	if _, err := s.repo.GetCompany(ctx, in.CompanyID); err != nil {
+		return Schedule{}, err
+	}
+	tpl, err := s.repo.GetTemplate(ctx, in.TemplateID)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if !tpl.IsActive {
+		return Schedule{}, fmt.Errorf("boardpack: template nonaktif")
+	}
+	id, err := s.repo.UpsertSchedule(ctx, in)
+	if err != nil {
+		return Schedule{}, err
+	}
+	return s.repo.GetSchedule(ctx, id)
+}
+
+// SetScheduleActive pauses or resumes a schedule.
+func (s *Service) SetScheduleActive(ctx context.Context, id int64, active bool) error {
+	return s.repo.SetScheduleActive(ctx, id, active)
+}
+
+// DeleteSchedule removes a schedule.
+func (s *Service) DeleteSchedule(ctx context.Context, id int64) error {
+	return s.repo.DeleteSchedule(ctx, id)
+}
@@ -151,3 +151,32 @@ var ErrChecklistIncomplete = errors.New("close: checklist not complete")
 
 // ErrActiveRunExists indicates a run already exists for the period.
 var ErrActiveRunExists = errors.New("close: close run already active for this period")
+
+// YearEndClosing asks the ledger to zero a company's revenue and expense
+// accounts for a fiscal year into retained earnings.
+type YearEndClosing struct {
+	CompanyID                 int64
+	FiscalYear                int
+	StartDate                 time.Time
+	EndDate                   time.Time
+	LedgerPeriodID            int64
+	RetainedEarningsAccountID int64
+	ActorID                   int64
+}
+
+// YearEndResult describes the closing journal for a company and fiscal year.
+type YearEndResult struct {
+	CompanyID     int64
+	FiscalYear    int
+	JournalID     int64
+	JournalNumber int64
+	NetIncome     float64
+	// AlreadyPosted is set when the year had been closed before and nothing new was posted.
+	AlreadyPosted bool
+}
+
+// ErrNotFinalPeriod indicates the year-end closing was requested for a period other than the last one of its fiscal year.
+var ErrNotFinalPeriod = errors.New("close: year-end closing requires the final period of the fiscal year")
+
+// ErrYearEndNotConfigured indicates the ledger or retained earnings mapping is unavailable.
+var ErrYearEndNotConfigured = errors.New("close: year-end closing is not configured")
@@ -2,6 +2,7 @@ package closehttp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,7 +12,9 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	accshared "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/close"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -28,6 +31,8 @@ type closeService interface {
 	UpdateChecklist(ctx context.Context, in close.ChecklistUpdateInput) (close.ChecklistItem, error)
 	SoftClose(ctx context.Context, runID, actorID int64) (close.Period, error)
 	HardClose(ctx context.Context, runID, actorID int64) (close.Period, error)
+	CloseFiscalYear(ctx context.Context, runID, actorID int64) (close.YearEndResult, error)
+	CompareSnapshot(ctx context.Context, periodID int64) (close.SnapshotComparison, error)
 }
 
 // Handler wires HTTP endpoints for managing accounting periods and close runs.
@@ -51,6 +56,7 @@ type periodListRow struct {
 	StatusBadge  badgeView
 	HasRun       bool
 	RunURL       string
+	SnapshotURL  string
 	ShowStartRun bool
 }
 
@@ -64,6 +70,13 @@ type closeRunPageData struct {
 	Summary           checklistSummary
 	SoftClose         actionState
 	HardClose         actionState
+	YearEnd           actionState
+	ShowYearEnd       bool
+}
+
+type snapshotPageData struct {
+	Period     close.Period
+	Comparison close.SnapshotComparison
 }
 
 type checklistRowView struct {
@@ -111,6 +124,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	r.Route("/accounting/periods", func(r chi.Router) {
 		r.Use(h.rbac.RequireAny("finance.period.close"))
 		r.Get("/", h.listPeriods)
+		r.Get("/{id}/snapshot", h.showSnapshot)
 		r.Group(func(r chi.Router) {
 			r.Use(h.rbac.RequireAll("finance.period.close"))
 			r.Post("/", h.createPeriod)
@@ -125,6 +139,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 			r.Post("/{id}/checklist/{itemID}", h.updateChecklist)
 			r.Post("/{id}/soft-close", h.softClose)
 			r.Post("/{id}/hard-close", h.hardClose)
+			r.Post("/{id}/year-end", h.closeFiscalYear)
 		})
 	})
 }
@@ -133,8 +148,8 @@ func (h *Handler) listPeriods(w http.ResponseWriter, r *http.Request) {
 	companyID := h.resolveCompanyID(r)
 	periods, err := h.service.ListPeriods(r.Context(), companyID, periodsPageLimit, 0)
 	if err != nil {
-		h.logger.Error("list periods", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list periods", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	yearFilter := strings.TrimSpace(r.URL.Query().Get("year"))
@@ -171,7 +186,7 @@ func (h *Handler) listPeriods(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) createPeriod(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	companyID := h.resolveCompanyID(r)
@@ -193,7 +208,7 @@ func (h *Handler) createPeriod(w http.ResponseWriter, r *http.Request) {
 		EndDate:   endDate,
 	}
 	if _, err := h.service.CreatePeriod(r.Context(), input); err != nil {
-		h.logger.Warn("create period", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "create period", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/accounting/periods", "danger", shared.UserSafeMessage(err))
 		return
 	}
@@ -203,7 +218,7 @@ func (h *Handler) createPeriod(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) startCloseRun(w http.ResponseWriter, r *http.Request) {
 	periodID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil || periodID == 0 {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	companyID := h.resolveCompanyID(r)
@@ -214,7 +229,7 @@ func (h *Handler) startCloseRun(w http.ResponseWriter, r *http.Request) {
 		Notes:     strings.TrimSpace(r.PostFormValue("notes")),
 	})
 	if err != nil {
-		h.logger.Warn("start close run", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "start close run", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/accounting/periods", "danger", shared.UserSafeMessage(err))
 		return
 	}
@@ -224,19 +239,19 @@ func (h *Handler) startCloseRun(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) showCloseRun(w http.ResponseWriter, r *http.Request) {
 	runID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil || runID == 0 {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	run, err := h.service.GetCloseRun(r.Context(), runID)
 	if err != nil {
-		h.logger.Error("get close run", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get close run", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
 	}
 	period, err := h.service.GetPeriod(r.Context(), run.PeriodID)
 	if err != nil {
-		h.logger.Error("get period for run", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "get period for run", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	summary := summariseChecklist(run.Checklist)
@@ -254,19 +269,49 @@ func (h *Handler) showCloseRun(w http.ResponseWriter, r *http.Request) {
 		Summary:           summary,
 		SoftClose:         softCloseState(period.Status),
 		HardClose:         hardCloseState(period.Status, summary),
+		YearEnd:           yearEndState(period.Status),
+		ShowYearEnd:       close.IsFiscalYearEnd(period),
 	}
 	h.render(w, r, "pages/close/run.html", "Close Run", data, http.StatusOK)
 }
 
+func (h *Handler) showSnapshot(w http.ResponseWriter, r *http.Request) {
+	periodID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || periodID == 0 {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	period, err := h.service.GetPeriod(r.Context(), periodID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get period for snapshot", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	comparison, err := h.service.CompareSnapshot(r.Context(), periodID)
+	if err != nil {
+		if errors.Is(err, close.ErrSnapshotNotFound) {
+			h.redirectWithFlash(w, r, "/accounting/periods", "info", "Snapshot hard close belum tersedia untuk periode ini")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "compare period snapshot", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/close/snapshot.html", "Snapshot Periode", snapshotPageData{
+		Period:     period,
+		Comparison: comparison,
+	}, http.StatusOK)
+}
+
 func (h *Handler) updateChecklist(w http.ResponseWriter, r *http.Request) {
 	runID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil || runID == 0 {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	itemID, err := strconv.ParseInt(chi.URLParam(r, "itemID"), 10, 64)
 	if err != nil || itemID == 0 {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	status := close.ChecklistStatus(strings.ToUpper(strings.TrimSpace(r.PostFormValue("status"))))
@@ -277,7 +322,7 @@ func (h *Handler) updateChecklist(w http.ResponseWriter, r *http.Request) {
 		Comment: strings.TrimSpace(r.PostFormValue("comment")),
 	})
 	if err != nil {
-		h.logger.Warn("update checklist", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "update checklist", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/close-runs/"+strconv.FormatInt(runID, 10), "danger", shared.UserSafeMessage(err))
 		return
 	}
@@ -287,11 +332,11 @@ func (h *Handler) updateChecklist(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) softClose(w http.ResponseWriter, r *http.Request) {
 	runID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil || runID == 0 {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	if _, err := h.service.SoftClose(r.Context(), runID, currentUser(r)); err != nil {
-		h.logger.Warn("soft close", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "soft close", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/close-runs/"+strconv.FormatInt(runID, 10), "danger", shared.UserSafeMessage(err))
 		return
 	}
@@ -301,17 +346,37 @@ func (h *Handler) softClose(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) hardClose(w http.ResponseWriter, r *http.Request) {
 	runID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil || runID == 0 {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	if _, err := h.service.HardClose(r.Context(), runID, currentUser(r)); err != nil {
-		h.logger.Warn("hard close", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "hard close", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/close-runs/"+strconv.FormatInt(runID, 10), "danger", shared.UserSafeMessage(err))
 		return
 	}
 	h.redirectWithFlash(w, r, "/close-runs/"+strconv.FormatInt(runID, 10), "success", "Periode di-hard-close")
 }
 
+func (h *Handler) closeFiscalYear(w http.ResponseWriter, r *http.Request) {
+	runID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || runID == 0 {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	location := "/close-runs/" + strconv.FormatInt(runID, 10)
+	result, err := h.service.CloseFiscalYear(r.Context(), runID, currentUser(r))
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "year-end close", slog.Any("error", err))
+		h.redirectWithFlash(w, r, location, "danger", yearEndErrorMessage(err))
+		return
+	}
+	if result.AlreadyPosted {
+		h.redirectWithFlash(w, r, location, "info", fmt.Sprintf("Tutup buku tahun %d sudah diposting (jurnal #%d)", result.FiscalYear, result.JournalNumber))
+		return
+	}
+	h.redirectWithFlash(w, r, location, "success", fmt.Sprintf("Jurnal tutup buku tahun %d diposting (jurnal #%d, laba bersih %.2f)", result.FiscalYear, result.JournalNumber, result.NetIncome))
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, title string, data any, status int) {
 	sess := shared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -328,7 +393,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
 	}
 }
 
@@ -350,7 +415,7 @@ func (h *Handler) resolveCompanyID(r *http.Request) int64 {
 		}
 	}
 	if raw == "" {
-		return 0
+		return shared.ScopedCompanyID(r.Context(), 0, 0)
 	}
 	id, err := strconv.ParseInt(raw, 10, 64)
 	if err != nil || id < 0 {
@@ -374,11 +439,16 @@ func newPeriodListRow(period close.Period) periodListRow {
 	if hasRun {
 		runURL = "/close-runs/" + strconv.FormatInt(period.LatestRunID, 10)
 	}
+	var snapshotURL string
+	if period.Status == close.PeriodStatusHardClosed {
+		snapshotURL = "/accounting/periods/" + strconv.FormatInt(period.ID, 10) + "/snapshot"
+	}
 	return periodListRow{
 		Period:       period,
 		StatusBadge:  badgeForPeriodStatus(period.Status),
 		HasRun:       hasRun,
 		RunURL:       runURL,
+		SnapshotURL:  snapshotURL,
 		ShowStartRun: !hasRun && period.Status == close.PeriodStatusOpen,
 	}
 }
@@ -479,6 +549,34 @@ func hardCloseState(status close.PeriodStatus, summary checklistSummary) actionS
 	return actionState{Enabled: true}
 }
 
+func yearEndState(status close.PeriodStatus) actionState {
+	if status == close.PeriodStatusHardClosed {
+		return actionState{Enabled: false, Message: "Periode sudah hard close"}
+	}
+	return actionState{Enabled: true}
+}
+
+// yearEndErrorMessage shows known closing errors verbatim; everything else
+// falls back to the generic user-safe message.
+func yearEndErrorMessage(err error) string {
+	for _, known := range []error{
+		close.ErrNotFinalPeriod,
+		close.ErrYearEndNotConfigured,
+		close.ErrPeriodHardClosed,
+		close.ErrChecklistLocked,
+		accshared.ErrMappingNotFound,
+		accshared.ErrRetainedEarningsNotEquity,
+		accshared.ErrYearEndNothingToClose,
+		accshared.ErrPeriodLocked,
+		accshared.ErrInvalidPeriod,
+	} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
+
 func humanizeStatus(value string) string {
 	value = strings.ToLower(strings.TrimSpace(value))
 	if value == "" {
@@ -15,6 +15,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/reports"
 	"github.com/odyssey-erp/odyssey-erp/internal/close"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
@@ -160,6 +161,48 @@ func TestShowCloseRunDisplaysProgress(t *testing.T) {
 	if !strings.Contains(body, "Checklist belum selesai (2 dari 3)") {
 		t.Fatalf("expected hard close guard message when checklist pending")
 	}
+	if strings.Contains(body, "/close-runs/50/year-end") {
+		t.Fatalf("expected no year-end action outside the final period")
+	}
+}
+
+func TestCloseFiscalYearFlashesResult(t *testing.T) {
+	var calls int
+	svc := &stubCloseService{
+		closeYearFn: func(ctx context.Context, runID, actorID int64) (close.YearEndResult, error) {
+			calls++
+			if calls == 1 {
+				return close.YearEndResult{}, close.ErrNotFinalPeriod
+			}
+			return close.YearEndResult{FiscalYear: 2025, JournalNumber: 812, NetIncome: 1500}, nil
+		},
+	}
+	handler, sessions := newTestHandler(t, svc)
+
+	post := func() (*httptest.ResponseRecorder, *shared.FlashMessage) {
+		req := httptest.NewRequest(http.MethodPost, "/close-runs/60/year-end", nil)
+		sess := loadSession(t, sessions, req)
+		sess.SetUser("99")
+		req = req.WithContext(shared.ContextWithSession(req.Context(), sess))
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", "60")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+		rr := httptest.NewRecorder()
+		handler.closeFiscalYear(rr, req)
+		return rr, sess.PopFlash()
+	}
+
+	rr, flash := post()
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/close-runs/60" {
+		t.Fatalf("unexpected response %d %s", rr.Code, rr.Header().Get("Location"))
+	}
+	if flash == nil || flash.Kind != "danger" || flash.Message != close.ErrNotFinalPeriod.Error() {
+		t.Fatalf("expected final period error flash, got %+v", flash)
+	}
+	_, flash = post()
+	if flash == nil || flash.Kind != "success" || !strings.Contains(flash.Message, "#812") {
+		t.Fatalf("expected success flash with journal number, got %+v", flash)
+	}
 }
 
 type stubCloseService struct {
@@ -171,6 +214,77 @@ type stubCloseService struct {
 	updateChecklistFn func(context.Context, close.ChecklistUpdateInput) (close.ChecklistItem, error)
 	softCloseFn       func(context.Context, int64, int64) (close.Period, error)
 	hardCloseFn       func(context.Context, int64, int64) (close.Period, error)
+	closeYearFn       func(context.Context, int64, int64) (close.YearEndResult, error)
+	compareSnapshotFn func(context.Context, int64) (close.SnapshotComparison, error)
+}
+
+func TestShowSnapshotHighlightsLiveVariance(t *testing.T) {
+	snapshot := close.PeriodSnapshot{
+		PeriodID:   70,
+		CompanyID:  3,
+		RunID:      50,
+		CapturedBy: 99,
+		CapturedAt: time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC),
+		Lines: []close.SnapshotLine{
+			{AccountCode: "1100", AccountName: "Kas", AccountType: "ASSET", Debit: 500, Closing: 500},
+			{AccountCode: "4100", AccountName: "Penjualan", AccountType: "REVENUE", Credit: 500, Closing: -500},
+		},
+	}
+	snapshot.Totals = close.StatementTotals{NetIncome: 500, TotalAssets: 500}
+	svc := &stubCloseService{
+		getPeriodFn: func(ctx context.Context, id int64) (close.Period, error) {
+			return close.Period{ID: id, CompanyID: 3, Name: "2024-02", Status: close.PeriodStatusHardClosed}, nil
+		},
+		compareSnapshotFn: func(ctx context.Context, periodID int64) (close.SnapshotComparison, error) {
+			return close.BuildSnapshotComparison(snapshot, []reports.AccountBalance{
+				{Code: "1100", Name: "Kas", Type: "ASSET", Debit: 650},
+				{Code: "4100", Name: "Penjualan", Type: "REVENUE", Credit: 650},
+			}), nil
+		},
+	}
+	handler, sessions := newTestHandler(t, svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounting/periods/70/snapshot", nil)
+	sess := loadSession(t, sessions, req)
+	req = req.WithContext(shared.ContextWithSession(req.Context(), sess))
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", "70")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+	handler.showSnapshot(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "berbeda dari yang dilaporkan") {
+		t.Fatalf("expected variance warning in page")
+	}
+	if !strings.Contains(body, "150,00") {
+		t.Fatalf("expected net income difference in page")
+	}
+}
+
+func TestShowSnapshotRedirectsWhenMissing(t *testing.T) {
+	handler, sessions := newTestHandler(t, &stubCloseService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/accounting/periods/71/snapshot", nil)
+	sess := loadSession(t, sessions, req)
+	req = req.WithContext(shared.ContextWithSession(req.Context(), sess))
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", "71")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+	rr := httptest.NewRecorder()
+	handler.showSnapshot(rr, req)
+
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/accounting/periods" {
+		t.Fatalf("unexpected response %d %s", rr.Code, rr.Header().Get("Location"))
+	}
+	if flash := sess.PopFlash(); flash == nil || flash.Kind != "info" {
+		t.Fatalf("expected info flash, got %+v", flash)
+	}
 }
 
 func (s *stubCloseService) ListPeriods(ctx context.Context, companyID int64, limit, offset int) ([]close.Period, error) {
@@ -229,6 +343,20 @@ func (s *stubCloseService) HardClose(ctx context.Context, runID, actorID int64)
 	return close.Period{}, nil
 }
 
+func (s *stubCloseService) CloseFiscalYear(ctx context.Context, runID, actorID int64) (close.YearEndResult, error) {
+	if s.closeYearFn != nil {
+		return s.closeYearFn(ctx, runID, actorID)
+	}
+	return close.YearEndResult{}, nil
+}
+
+func (s *stubCloseService) CompareSnapshot(ctx context.Context, periodID int64) (close.SnapshotComparison, error) {
+	if s.compareSnapshotFn != nil {
+		return s.compareSnapshotFn(ctx, periodID)
+	}
+	return close.SnapshotComparison{}, close.ErrSnapshotNotFound
+}
+
 func newTestHandler(t *testing.T, svc *stubCloseService) (*Handler, *shared.SessionManager) {
 	t.Helper()
 	mr := miniredis.RunT(t)
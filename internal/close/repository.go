@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/reports"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -334,6 +335,114 @@ func (r *Repository) UpdateRunStatus(ctx context.Context, tx pgx.Tx, runID int64
 	})
 }
 
+// TrialBalance returns per-account opening, debit and credit totals from
+// posted journals for the company and ledger period. A nil tx reads outside
+// any transaction.
+func (r *Repository) TrialBalance(ctx context.Context, tx pgx.Tx, companyID, ledgerPeriodID int64) ([]reports.AccountBalance, error) {
+	q := r.queries
+	if tx != nil {
+		q = sqlc.New(tx)
+	}
+	rows, err := q.CompareTrialBalanceByCompany(ctx, sqlc.CompareTrialBalanceByCompanyParams{
+		PeriodID:   ledgerPeriodID,
+		CompanyIds: []int64{companyID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]reports.AccountBalance, 0, len(rows))
+	for _, row := range rows {
+		balances = append(balances, reports.AccountBalance{
+			Code:    row.Code,
+			Name:    row.Name,
+			Type:    string(row.Type),
+			Opening: row.Opening,
+			Debit:   row.Debit,
+			Credit:  row.Credit,
+		})
+	}
+	return balances, nil
+}
+
+// InsertSnapshot stores a hard-close snapshot with its lines. It returns false
+// without writing anything when the period already has a snapshot.
+func (r *Repository) InsertSnapshot(ctx context.Context, tx pgx.Tx, snapshot PeriodSnapshot) (bool, error) {
+	q := sqlc.New(tx)
+	id, err := q.InsertPeriodSnapshot(ctx, sqlc.InsertPeriodSnapshotParams{
+		PeriodID:         snapshot.PeriodID,
+		CompanyID:        snapshot.CompanyID,
+		RunID:            snapshot.RunID,
+		NetIncome:        snapshot.Totals.NetIncome,
+		TotalAssets:      snapshot.Totals.TotalAssets,
+		TotalLiabilities: snapshot.Totals.TotalLiabilities,
+		TotalEquity:      snapshot.Totals.TotalEquity,
+		CapturedBy:       snapshot.CapturedBy,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, line := range snapshot.Lines {
+		if err := q.InsertPeriodSnapshotLine(ctx, sqlc.InsertPeriodSnapshotLineParams{
+			SnapshotID:  id,
+			AccountCode: line.AccountCode,
+			AccountName: line.AccountName,
+			AccountType: line.AccountType,
+			Opening:     line.Opening,
+			Debit:       line.Debit,
+			Credit:      line.Credit,
+			Closing:     line.Closing,
+		}); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// LoadSnapshot fetches the hard-close snapshot for an accounting period.
+func (r *Repository) LoadSnapshot(ctx context.Context, periodID int64) (PeriodSnapshot, error) {
+	row, err := r.queries.LoadPeriodSnapshot(ctx, periodID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PeriodSnapshot{}, ErrSnapshotNotFound
+		}
+		return PeriodSnapshot{}, err
+	}
+	lineRows, err := r.queries.ListPeriodSnapshotLines(ctx, row.ID)
+	if err != nil {
+		return PeriodSnapshot{}, err
+	}
+	snapshot := PeriodSnapshot{
+		ID:        row.ID,
+		PeriodID:  row.PeriodID,
+		CompanyID: row.CompanyID,
+		RunID:     row.RunID,
+		Totals: StatementTotals{
+			NetIncome:        row.NetIncome,
+			TotalAssets:      row.TotalAssets,
+			TotalLiabilities: row.TotalLiabilities,
+			TotalEquity:      row.TotalEquity,
+		},
+		CapturedBy: row.CapturedBy,
+		CapturedAt: row.CapturedAt.Time,
+		Lines:      make([]SnapshotLine, len(lineRows)),
+	}
+	for i, line := range lineRows {
+		snapshot.Lines[i] = SnapshotLine{
+			AccountCode: line.AccountCode,
+			AccountName: line.AccountName,
+			AccountType: line.AccountType,
+			Opening:     line.Opening,
+			Debit:       line.Debit,
+			Credit:      line.Credit,
+			Closing:     line.Closing,
+		}
+	}
+	return snapshot, nil
+}
+
 func legacyStatusFromAccounting(status PeriodStatus) string {
 	switch status {
 	case PeriodStatusSoftClosed:
@@ -11,8 +11,17 @@ import (
 
 // Service orchestrates accounting period lifecycle and close runs.
 type Service struct {
-	repo *Repository
-	now  func() time.Time
+	repo     *Repository
+	ledger   LedgerCloser
+	mappings AccountMappingPort
+	listener CloseListener
+	now      func() time.Time
+}
+
+// CloseListener is told when a period has been hard closed, after the close
+// is committed. It cannot fail the close.
+type CloseListener interface {
+	PeriodHardClosed(ctx context.Context, companyID, periodID int64)
 }
 
 // NewService constructs a Service instance.
@@ -30,6 +39,11 @@ func (s *Service) WithNow(now func() time.Time) {
 	}
 }
 
+// SetCloseListener registers a listener for hard-closed periods.
+func (s *Service) SetCloseListener(listener CloseListener) {
+	s.listener = listener
+}
+
 // ListPeriods returns paginated periods for the specified company.
 func (s *Service) ListPeriods(ctx context.Context, companyID int64, limit, offset int) ([]Period, error) {
 	return s.repo.ListPeriods(ctx, companyID, limit, offset)
@@ -180,7 +194,8 @@ func (s *Service) SoftClose(ctx context.Context, runID, actorID int64) (Period,
 	return s.repo.LoadPeriod(ctx, periodID)
 }
 
-// HardClose locks the period and enforces checklist completion.
+// HardClose locks the period and enforces checklist completion. The period's
+// trial balance is snapshotted in the same transaction.
 func (s *Service) HardClose(ctx context.Context, runID, actorID int64) (Period, error) {
 	if actorID == 0 {
 		return Period{}, errors.New("close: actor required")
@@ -201,6 +216,9 @@ func (s *Service) HardClose(ctx context.Context, runID, actorID int64) (Period,
 		if !done {
 			return ErrChecklistIncomplete
 		}
+		if err := s.captureSnapshot(ctx, tx, run, actorID); err != nil {
+			return err
+		}
 		if err := s.repo.UpdatePeriodStatus(ctx, tx, run.PeriodID, PeriodStatusHardClosed, actorID); err != nil {
 			return err
 		}
@@ -213,7 +231,14 @@ func (s *Service) HardClose(ctx context.Context, runID, actorID int64) (Period,
 	if err != nil {
 		return Period{}, err
 	}
-	return s.repo.LoadPeriod(ctx, periodID)
+	period, err := s.repo.LoadPeriod(ctx, periodID)
+	if err != nil {
+		return Period{}, err
+	}
+	if s.listener != nil {
+		s.listener.PeriodHardClosed(ctx, period.CompanyID, period.ID)
+	}
+	return period, nil
 }
 
 // EnsurePeriodOpenForPosting validates that the ledger period is not hard closed.
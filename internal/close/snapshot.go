@@ -0,0 +1,222 @@
+package close
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/reports"
+)
+
+// ErrSnapshotNotFound indicates the period has no hard-close snapshot, typically
+// because it was hard closed before snapshots were captured.
+var ErrSnapshotNotFound = errors.New("close: no snapshot captured for period")
+
+// snapshotTolerance absorbs rounding from the NUMERIC(18,2) snapshot columns.
+const snapshotTolerance = 0.005
+
+// SnapshotLine holds one account's trial balance figures for the period.
+type SnapshotLine struct {
+	AccountCode string
+	AccountName string
+	AccountType string
+	Opening     float64
+	Debit       float64
+	Credit      float64
+	Closing     float64
+}
+
+// StatementTotals are the headline profit and loss and balance sheet figures.
+type StatementTotals struct {
+	NetIncome        float64
+	TotalAssets      float64
+	TotalLiabilities float64
+	TotalEquity      float64
+}
+
+// PeriodSnapshot is the trial balance frozen when a period was hard closed.
+type PeriodSnapshot struct {
+	ID         int64
+	PeriodID   int64
+	CompanyID  int64
+	RunID      int64
+	Totals     StatementTotals
+	CapturedBy int64
+	CapturedAt time.Time
+	Lines      []SnapshotLine
+}
+
+// SnapshotVariance compares an account's reported and live figures.
+type SnapshotVariance struct {
+	AccountCode     string
+	AccountName     string
+	ReportedDebit   float64
+	ReportedCredit  float64
+	ReportedClosing float64
+	LiveDebit       float64
+	LiveCredit      float64
+	LiveClosing     float64
+	Difference      float64
+	Changed         bool
+}
+
+// StatementVariance compares a reported statement total with its live value.
+type StatementVariance struct {
+	Label      string
+	Reported   float64
+	Live       float64
+	Difference float64
+	Changed    bool
+}
+
+// SnapshotComparison sets the hard-close snapshot against figures recomputed
+// from the ledger today.
+type SnapshotComparison struct {
+	Snapshot   PeriodSnapshot
+	Live       StatementTotals
+	Statements []StatementVariance
+	Accounts   []SnapshotVariance
+	Changed    bool
+}
+
+// PeriodSnapshot returns the snapshot captured when the period was hard closed.
+func (s *Service) PeriodSnapshot(ctx context.Context, periodID int64) (PeriodSnapshot, error) {
+	return s.repo.LoadSnapshot(ctx, periodID)
+}
+
+// CompareSnapshot recomputes the period's trial balance from posted journals
+// and compares it with the hard-close snapshot.
+func (s *Service) CompareSnapshot(ctx context.Context, periodID int64) (SnapshotComparison, error) {
+	snapshot, err := s.repo.LoadSnapshot(ctx, periodID)
+	if err != nil {
+		return SnapshotComparison{}, err
+	}
+	period, err := s.repo.LoadPeriod(ctx, periodID)
+	if err != nil {
+		return SnapshotComparison{}, err
+	}
+	live, err := s.repo.TrialBalance(ctx, nil, snapshot.CompanyID, period.PeriodID)
+	if err != nil {
+		return SnapshotComparison{}, err
+	}
+	return BuildSnapshotComparison(snapshot, live), nil
+}
+
+// captureSnapshot records the period's trial balance inside the hard-close
+// transaction. A period keeps the first snapshot taken for it.
+func (s *Service) captureSnapshot(ctx context.Context, tx pgx.Tx, run CloseRun, actorID int64) error {
+	period, err := s.repo.LoadPeriodForUpdate(ctx, tx, run.PeriodID)
+	if err != nil {
+		return err
+	}
+	companyID := run.CompanyID
+	if companyID == 0 {
+		companyID = period.CompanyID
+	}
+	balances, err := s.repo.TrialBalance(ctx, tx, companyID, period.PeriodID)
+	if err != nil {
+		return err
+	}
+	snapshot := PeriodSnapshot{
+		PeriodID:   period.ID,
+		CompanyID:  companyID,
+		RunID:      run.ID,
+		Totals:     BuildStatementTotals(balances),
+		CapturedBy: actorID,
+		Lines:      snapshotLines(balances),
+	}
+	_, err = s.repo.InsertSnapshot(ctx, tx, snapshot)
+	return err
+}
+
+// BuildStatementTotals derives net income and balance sheet totals from
+// account balances using the standard report builders.
+func BuildStatementTotals(balances []reports.AccountBalance) StatementTotals {
+	pl := reports.BuildProfitAndLoss(balances)
+	bs := reports.BuildBalanceSheet(balances)
+	return StatementTotals{
+		NetIncome:        pl.NetIncome,
+		TotalAssets:      bs.Assets.Total,
+		TotalLiabilities: bs.Liabilities.Total,
+		TotalEquity:      bs.Equity.Total,
+	}
+}
+
+// BuildSnapshotComparison lines up the snapshot with live balances by account
+// code. Accounts present on only one side compare against zero.
+func BuildSnapshotComparison(snapshot PeriodSnapshot, live []reports.AccountBalance) SnapshotComparison {
+	liveTotals := BuildStatementTotals(live)
+	cmp := SnapshotComparison{Snapshot: snapshot, Live: liveTotals}
+
+	reported := snapshot.Totals
+	for _, row := range []struct {
+		label          string
+		reported, live float64
+	}{
+		{"Laba Bersih", reported.NetIncome, liveTotals.NetIncome},
+		{"Total Aset", reported.TotalAssets, liveTotals.TotalAssets},
+		{"Total Liabilitas", reported.TotalLiabilities, liveTotals.TotalLiabilities},
+		{"Total Ekuitas", reported.TotalEquity, liveTotals.TotalEquity},
+	} {
+		variance := StatementVariance{
+			Label:      row.label,
+			Reported:   row.reported,
+			Live:       row.live,
+			Difference: row.live - row.reported,
+		}
+		variance.Changed = math.Abs(variance.Difference) >= snapshotTolerance
+		cmp.Changed = cmp.Changed || variance.Changed
+		cmp.Statements = append(cmp.Statements, variance)
+	}
+
+	accounts := make(map[string]*SnapshotVariance)
+	for _, line := range snapshot.Lines {
+		accounts[line.AccountCode] = &SnapshotVariance{
+			AccountCode:     line.AccountCode,
+			AccountName:     line.AccountName,
+			ReportedDebit:   line.Debit,
+			ReportedCredit:  line.Credit,
+			ReportedClosing: line.Closing,
+		}
+	}
+	for _, acc := range live {
+		row, ok := accounts[acc.Code]
+		if !ok {
+			row = &SnapshotVariance{AccountCode: acc.Code, AccountName: acc.Name}
+			accounts[acc.Code] = row
+		}
+		row.LiveDebit += acc.Debit
+		row.LiveCredit += acc.Credit
+		row.LiveClosing += acc.Closing()
+	}
+	for _, row := range accounts {
+		row.Difference = row.LiveClosing - row.ReportedClosing
+		row.Changed = math.Abs(row.Difference) >= snapshotTolerance ||
+			math.Abs(row.LiveDebit-row.ReportedDebit) >= snapshotTolerance ||
+			math.Abs(row.LiveCredit-row.ReportedCredit) >= snapshotTolerance
+		cmp.Changed = cmp.Changed || row.Changed
+		cmp.Accounts = append(cmp.Accounts, *row)
+	}
+	sort.Slice(cmp.Accounts, func(i, j int) bool { return cmp.Accounts[i].AccountCode < cmp.Accounts[j].AccountCode })
+	return cmp
+}
+
+func snapshotLines(balances []reports.AccountBalance) []SnapshotLine {
+	lines := make([]SnapshotLine, 0, len(balances))
+	for _, acc := range balances {
+		lines = append(lines, SnapshotLine{
+			AccountCode: acc.Code,
+			AccountName: acc.Name,
+			AccountType: acc.Type,
+			Opening:     acc.Opening,
+			Debit:       acc.Debit,
+			Credit:      acc.Credit,
+			Closing:     acc.Closing(),
+		})
+	}
+	return lines
+}
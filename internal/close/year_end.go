@@ -0,0 +1,87 @@
+package close
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/mappings"
+)
+
+const (
+	// RetainedEarningsMappingModule and RetainedEarningsMappingKey locate the
+	// retained earnings account in account_mappings.
+	RetainedEarningsMappingModule = "CLOSE"
+	RetainedEarningsMappingKey    = "close.retained_earnings"
+)
+
+// LedgerCloser posts the retained earnings closing journal; satisfied by journals.Service.
+type LedgerCloser interface {
+	CloseFiscalYear(ctx context.Context, in YearEndClosing) (YearEndResult, error)
+}
+
+// AccountMappingPort resolves mapped ledger accounts.
+type AccountMappingPort interface {
+	Get(ctx context.Context, module, key string) (mappings.AccountMapping, error)
+}
+
+// SetYearEnd enables the year-end closing operation.
+func (s *Service) SetYearEnd(ledger LedgerCloser, mappings AccountMappingPort) {
+	s.ledger = ledger
+	s.mappings = mappings
+}
+
+// CloseFiscalYear posts the closing entry moving the fiscal year's revenue and
+// expense balances into retained earnings. Fiscal years follow the calendar, so
+// it only runs from a close run on the company's period ending 31 December
+// that is not yet hard closed. The ledger keys the journal by company and year,
+// so repeating the call returns the existing entry instead of posting again.
+func (s *Service) CloseFiscalYear(ctx context.Context, runID, actorID int64) (YearEndResult, error) {
+	if actorID == 0 {
+		return YearEndResult{}, errors.New("close: actor required")
+	}
+	if s.ledger == nil || s.mappings == nil {
+		return YearEndResult{}, ErrYearEndNotConfigured
+	}
+	run, err := s.repo.LoadCloseRun(ctx, runID)
+	if err != nil {
+		return YearEndResult{}, err
+	}
+	if run.Status == RunStatusCancelled {
+		return YearEndResult{}, ErrChecklistLocked
+	}
+	period, err := s.repo.LoadPeriod(ctx, run.PeriodID)
+	if err != nil {
+		return YearEndResult{}, err
+	}
+	if period.Status == PeriodStatusHardClosed {
+		return YearEndResult{}, ErrPeriodHardClosed
+	}
+	if !IsFiscalYearEnd(period) {
+		return YearEndResult{}, ErrNotFinalPeriod
+	}
+	companyID := run.CompanyID
+	if companyID == 0 {
+		companyID = period.CompanyID
+	}
+	mapping, err := s.mappings.Get(ctx, RetainedEarningsMappingModule, RetainedEarningsMappingKey)
+	if err != nil {
+		return YearEndResult{}, err
+	}
+	year := period.EndDate.Year()
+	return s.ledger.CloseFiscalYear(ctx, YearEndClosing{
+		CompanyID:                 companyID,
+		FiscalYear:                year,
+		StartDate:                 time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:                   period.EndDate,
+		LedgerPeriodID:            period.PeriodID,
+		RetainedEarningsAccountID: mapping.AccountID,
+		ActorID:                   actorID,
+	})
+}
+
+// IsFiscalYearEnd reports whether the period is the last one of its fiscal year.
+func IsFiscalYearEnd(period Period) bool {
+	end := period.EndDate
+	return end.Month() == time.December && end.Day() == 31
+}
@@ -36,6 +36,8 @@ type TrialBalance struct {
 	Lines         []GroupAccountBalance
 	Contributions []Contribution
 	Members       []Member
+	// Unmapped lists balances left out because their accounts are not mapped.
+	Unmapped MappingCheck
 }
 
 // Totals summarises consolidated debit/credit totals.
@@ -59,3 +61,21 @@ type Member struct {
 	Name      string
 	Enabled   bool
 }
+
+// RefreshWatermark compares when a group's source data last changed with the
+// source state captured at its last refresh for one period.
+type RefreshWatermark struct {
+	// SourceChangedAt is the latest updated_at across contributing journals,
+	// group membership and account mappings.
+	SourceChangedAt time.Time
+	// RefreshedThrough is the SourceChangedAt captured at the last refresh;
+	// zero when the group has never been refreshed for the period.
+	RefreshedThrough time.Time
+	// RefreshedAt is when the last refresh ran.
+	RefreshedAt time.Time
+}
+
+// Changed reports whether the group needs a refresh.
+func (w RefreshWatermark) Changed() bool {
+	return w.RefreshedThrough.IsZero() || w.SourceChangedAt.After(w.RefreshedThrough)
+}
@@ -157,7 +157,10 @@ func writeBSCsv(w io.Writer, report consol.BalanceSheetReport, warnings []string
 	}
 	totalsRows := [][]string{
 		{"Totals", "", "Assets", "", formatDecimal(report.Totals.Assets)},
+		{"Totals", "", "Liabilities", "", formatDecimal(report.Totals.Liabilities)},
+		{"Totals", "", "Equity", "", formatDecimal(report.Totals.Equity)},
 		{"Totals", "", "Liabilities + Equity", "", formatDecimal(report.Totals.LiabEquity)},
+		{"Totals", "", "Difference", "", formatDecimal(report.Totals.Difference)},
 		{"Totals", "", "Balanced", "", strconv.FormatBool(report.Totals.Balanced)},
 		{"Totals", "", "Delta FX", "", formatDecimal(report.Totals.DeltaFX)},
 	}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/consol"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/xlsx"
+)
+
+// writeTBXLSX renders the consolidated trial balance as a two sheet workbook: an
+// info sheet carrying group/period metadata and a data sheet with one column per
+// member entity plus the group total.
+func writeTBXLSX(w io.Writer, tb consol.TrialBalance, generatedAt time.Time) error {
+	members := tbXLSXMembers(tb)
+
+	info := xlsx.Sheet{Name: "Info", Rows: [][]xlsx.Cell{
+		{xlsx.Text("Report"), xlsx.Text("Consolidated Trial Balance")},
+		{xlsx.Text("Group"), xlsx.Textf("%s (ID %d)", tb.GroupName, tb.Filters.GroupID)},
+		{xlsx.Text("Period"), xlsx.Text(tb.Filters.Period)},
+		{xlsx.Text("Reporting Currency"), xlsx.Text(tb.ReportingCCY)},
+		{xlsx.Text("Entities"), xlsx.Text(tbXLSXEntityLabel(members))},
+		{xlsx.Text("Generated At"), xlsx.Text(generatedAt.UTC().Format(time.RFC3339))},
+	}}
+
+	header := []xlsx.Cell{xlsx.Text("Group Account"), xlsx.Text("Name")}
+	for _, m := range members {
+		header = append(header, xlsx.Text(m.Name))
+	}
+	header = append(header, xlsx.Text("Group Total"))
+
+	data := xlsx.Sheet{Name: "Trial Balance", Rows: [][]xlsx.Cell{header}}
+	memberTotals := make([]float64, len(members))
+	var grandTotal float64
+	for _, line := range tb.Lines {
+		amounts := make(map[int64]float64, len(line.Members))
+		for _, share := range line.Members {
+			amounts[share.CompanyID] += share.LocalAmount
+		}
+		row := []xlsx.Cell{xlsx.Text(line.GroupAccountCode), xlsx.Text(line.GroupAccountName)}
+		for i, m := range members {
+			amount := amounts[m.CompanyID]
+			memberTotals[i] += amount
+			row = append(row, xlsx.Num(amount))
+		}
+		row = append(row, xlsx.Num(line.GroupAmount))
+		grandTotal += line.GroupAmount
+		data.Rows = append(data.Rows, row)
+	}
+	totals := []xlsx.Cell{xlsx.Text("Total"), xlsx.Blank()}
+	for _, amount := range memberTotals {
+		totals = append(totals, xlsx.Num(amount))
+	}
+	totals = append(totals, xlsx.Num(grandTotal))
+	data.Rows = append(data.Rows, totals)
+
+	return xlsx.Write(w, []xlsx.Sheet{info, data})
+}
+
+// tbXLSXMembers returns the member columns honouring the entity filter while
+// keeping the group's member ordering stable.
+func tbXLSXMembers(tb consol.TrialBalance) []consol.Member {
+	if len(tb.Filters.Entities) == 0 {
+		return tb.Members
+	}
+	include := make(map[int64]struct{}, len(tb.Filters.Entities))
+	for _, id := range tb.Filters.Entities {
+		include[id] = struct{}{}
+	}
+	members := make([]consol.Member, 0, len(include))
+	for _, m := range tb.Members {
+		if _, ok := include[m.CompanyID]; ok {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+func tbXLSXEntityLabel(members []consol.Member) string {
+	if len(members) == 0 {
+		return "All"
+	}
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.Name
+	}
+	return strings.Join(names, ", ")
+}
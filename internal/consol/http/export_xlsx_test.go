@@ -0,0 +1,83 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/consol"
+)
+
+func TestWriteTBXLSXMemberColumnsAndTotals(t *testing.T) {
+	tb := consol.TrialBalance{
+		Filters:      consol.Filters{GroupID: 7, Period: "2024-03"},
+		GroupName:    "Odyssey Group",
+		ReportingCCY: "IDR",
+		Members: []consol.Member{
+			{CompanyID: 1, Name: "PT Alpha"},
+			{CompanyID: 2, Name: "PT Beta & Co"},
+		},
+		Lines: []consol.GroupAccountBalance{
+			{GroupAccountCode: "1000", GroupAccountName: "Cash", GroupAmount: 150, Members: []consol.MemberShare{
+				{CompanyID: 1, LocalAmount: 100},
+				{CompanyID: 2, LocalAmount: 50},
+			}},
+			{GroupAccountCode: "2000", GroupAccountName: "Payables", GroupAmount: -150, Members: []consol.MemberShare{
+				{CompanyID: 2, LocalAmount: -150},
+			}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := writeTBXLSX(&buf, tb, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("writeTBXLSX: %v", err)
+	}
+	parts := readXLSXParts(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "xl/workbook.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("expected part %s in workbook", name)
+		}
+	}
+	info := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{"Odyssey Group (ID 7)", "2024-03", "IDR"} {
+		if !strings.Contains(info, want) {
+			t.Fatalf("info sheet missing %q", want)
+		}
+	}
+	data := parts["xl/worksheets/sheet2.xml"]
+	for _, want := range []string{
+		"PT Beta &amp; Co",
+		`<c r="E1" t="inlineStr"><is><t xml:space="preserve">Group Total</t></is></c>`,
+		`<c r="C3"><v>0.00</v></c>`,
+		`<c r="D4"><v>-100.00</v></c>`,
+		`<c r="E4"><v>0.00</v></c>`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("data sheet missing %q", want)
+		}
+	}
+}
+
+func readXLSXParts(t *testing.T, payload []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(payload), int64(len(payload)))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	parts := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open part %s: %v", f.Name, err)
+		}
+		body, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("read part %s: %v", f.Name, err)
+		}
+		parts[f.Name] = string(body)
+	}
+	return parts
+}
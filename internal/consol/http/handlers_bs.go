@@ -14,6 +14,7 @@ import (
 	"github.com/go-chi/httprate"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/consol"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -142,8 +143,8 @@ func (h *BalanceSheetHandler) HandleGet(w http.ResponseWriter, r *http.Request)
 		Data:        vm,
 	}
 	if err := h.templates.Render(w, "pages/finance/consol_bs.html", data); err != nil {
-		h.logger.Error("render consol bs", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render consol bs", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 	}
 }
 
@@ -151,13 +152,13 @@ func (h *BalanceSheetHandler) HandleGet(w http.ResponseWriter, r *http.Request)
 func (h *BalanceSheetHandler) HandleExportCSV(w http.ResponseWriter, r *http.Request) {
 	filters, errors := h.parseFilters(r)
 	if len(errors) > 0 {
-		http.Error(w, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
+		httpx.Error(w, r, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
 		return
 	}
 	report, warnings, err := h.service.Build(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("build consol bs csv", slog.Any("error", err))
-		http.Error(w, shared.UserSafeMessage(err), http.StatusBadRequest)
+		h.logger.ErrorContext(r.Context(), "build consol bs csv", slog.Any("error", err))
+		httpx.Error(w, r, shared.UserSafeMessage(err), http.StatusBadRequest)
 		return
 	}
 	exportWarnings := append([]string(nil), warnings...)
@@ -172,14 +173,14 @@ func (h *BalanceSheetHandler) HandleExportCSV(w http.ResponseWriter, r *http.Req
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	if err := writeBSCsv(w, report, vm.Warnings); err != nil {
-		h.logger.Error("stream consol bs csv", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "stream consol bs csv", slog.Any("error", err))
 	}
 }
 
 // HandleExportPDF handles PDF exports of the balance sheet.
 func (h *BalanceSheetHandler) HandleExportPDF(w http.ResponseWriter, r *http.Request) {
 	if h.pdfExporter == nil || !h.pdfExporter.Ready() {
-		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		httpx.Error(w, r, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 		return
 	}
 	h.pdfExporter.Serve(w, r, h)
@@ -230,6 +231,7 @@ func (h *BalanceSheetHandler) parseFilters(r *http.Request) (consol.BalanceSheet
 	default:
 		errors["fx"] = "Pilihan FX tidak valid"
 	}
+	entityIDs = scopeEntities(r, entityIDs, errors)
 	if len(errors) > 0 {
 		return consol.BalanceSheetFilters{}, errors
 	}
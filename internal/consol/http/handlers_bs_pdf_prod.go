@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/web"
 )
 
@@ -42,13 +43,13 @@ func (p *bsPDFRenderer) Ready() bool {
 func (p *bsPDFRenderer) Serve(w http.ResponseWriter, r *http.Request, h *BalanceSheetHandler) {
 	filters, errors := h.parseFilters(r)
 	if len(errors) > 0 {
-		http.Error(w, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
+		httpx.Error(w, r, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
 		return
 	}
 	report, warnings, err := h.service.Build(r.Context(), filters)
 	if err != nil {
-		p.logger.Error("build consol bs pdf", slog.Any("error", err))
-		http.Error(w, "Failed to generate report", http.StatusBadRequest)
+		p.logger.ErrorContext(r.Context(), "build consol bs pdf", slog.Any("error", err))
+		httpx.Error(w, r, "Failed to generate report", http.StatusBadRequest)
 		return
 	}
 	extraWarnings := append([]string(nil), warnings...)
@@ -58,14 +59,14 @@ func (p *bsPDFRenderer) Serve(w http.ResponseWriter, r *http.Request, h *Balance
 	vm := NewConsolBSViewModel(report, extraWarnings)
 	buf := &bytes.Buffer{}
 	if err := p.templates.ExecuteTemplate(buf, "consol_bs_pdf.html", vm); err != nil {
-		p.logger.Error("render consol bs pdf", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		p.logger.ErrorContext(r.Context(), "render consol bs pdf", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	pdf, err := p.client.RenderHTML(r.Context(), buf.String())
 	if err != nil {
-		p.logger.Error("generate consol bs pdf", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		p.logger.ErrorContext(r.Context(), "generate consol bs pdf", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
 		return
 	}
 	filename := fmt.Sprintf("consol_bs-%d-%s.pdf", report.Filters.GroupID, report.Filters.Period)
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/consol"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// ConsolICMatchVM drives the intercompany matching page rendering.
+type ConsolICMatchVM struct {
+	Filters consol.ICMatchFilters
+	Report  consol.ICMatchReport
+	Errors  map[string]string
+}
+
+func (h *Handler) handleICMatching(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	filter, errors := parseICMatchFilters(r)
+	vm := ConsolICMatchVM{Filters: filter, Errors: errors}
+	if len(errors) == 0 {
+		report, err := h.service.IntercompanyMatching(r.Context(), filter)
+		if err != nil {
+			errors["general"] = shared.UserSafeMessage(err)
+		} else {
+			vm.Filters = report.Filters
+			vm.Report = report
+		}
+	}
+	data := view.TemplateData{
+		Title:       "Intercompany Matching",
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data:        vm,
+	}
+	if err := h.templates.Render(w, "pages/finance/consol_ic_matching.html", data); err != nil {
+		h.logger.ErrorContext(r.Context(), "render consol ic matching", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func parseICMatchFilters(r *http.Request) (consol.ICMatchFilters, map[string]string) {
+	q := r.URL.Query()
+	errors := make(map[string]string)
+	filter := consol.ICMatchFilters{Period: strings.TrimSpace(q.Get("period")), Tolerance: consol.DefaultICMatchTolerance}
+	groupID, err := strconv.ParseInt(q.Get("group"), 10, 64)
+	if err != nil || groupID <= 0 {
+		errors["group"] = "Group tidak valid"
+	}
+	filter.GroupID = groupID
+	if filter.Period == "" {
+		errors["period"] = "Periode wajib diisi"
+	}
+	if raw := strings.TrimSpace(q.Get("tolerance")); raw != "" {
+		tolerance, err := strconv.ParseFloat(raw, 64)
+		if err != nil || tolerance < 0 {
+			errors["tolerance"] = "Toleransi tidak valid"
+		} else {
+			filter.Tolerance = tolerance
+		}
+	}
+	return filter, errors
+}
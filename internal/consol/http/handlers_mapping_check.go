@@ -0,0 +1,79 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/consol"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// ConsolMappingCheckVM drives the mapping completeness page rendering.
+type ConsolMappingCheckVM struct {
+	GroupID int64
+	Period  string
+	Check   consol.MappingCheck
+	Checked bool
+	Errors  map[string]string
+}
+
+func (h *Handler) handleMappingCheck(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	q := r.URL.Query()
+	vm := ConsolMappingCheckVM{Period: strings.TrimSpace(q.Get("period")), Errors: make(map[string]string)}
+	groupID, err := strconv.ParseInt(q.Get("group"), 10, 64)
+	if err != nil || groupID <= 0 {
+		vm.Errors["group"] = "Group tidak valid"
+	}
+	vm.GroupID = groupID
+	if vm.Period == "" {
+		vm.Errors["period"] = "Periode wajib diisi"
+	}
+	if len(vm.Errors) == 0 {
+		check, err := h.service.CheckMappings(r.Context(), groupID, vm.Period)
+		if err != nil {
+			vm.Errors["general"] = shared.UserSafeMessage(err)
+		} else {
+			vm.Check = scopeMappingGaps(r, check)
+			vm.Checked = true
+		}
+	}
+	data := view.TemplateData{
+		Title:       "Mapping Check",
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data:        vm,
+	}
+	if err := h.templates.Render(w, "pages/finance/consol_mapping_check.html", data); err != nil {
+		h.logger.ErrorContext(r.Context(), "render consol mapping check", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// scopeMappingGaps keeps only members the user is assigned to.
+func scopeMappingGaps(r *http.Request, check consol.MappingCheck) consol.MappingCheck {
+	scope := shared.CompanyScopeFromContext(r.Context())
+	if scope.Unrestricted {
+		return check
+	}
+	allowed := make([]int64, 0, len(check.Members))
+	for _, gap := range check.Members {
+		if scope.Allows(gap.CompanyID) {
+			allowed = append(allowed, gap.CompanyID)
+		}
+	}
+	if len(allowed) == 0 {
+		return consol.MappingCheck{GroupID: check.GroupID, Period: check.Period, GroupName: check.GroupName}
+	}
+	return check.Only(allowed)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/go-chi/httprate"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/consol"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -138,8 +139,8 @@ func (h *ProfitLossHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
 		Data:        vm,
 	}
 	if err := h.templates.Render(w, "pages/finance/consol_pl.html", data); err != nil {
-		h.logger.Error("render consol pl", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render consol pl", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 	}
 }
 
@@ -147,13 +148,13 @@ func (h *ProfitLossHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
 func (h *ProfitLossHandler) HandleExportCSV(w http.ResponseWriter, r *http.Request) {
 	filters, errors := h.parseFilters(r)
 	if len(errors) > 0 {
-		http.Error(w, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
+		httpx.Error(w, r, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
 		return
 	}
 	report, warnings, err := h.service.Build(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("build consol pl csv", slog.Any("error", err))
-		http.Error(w, shared.UserSafeMessage(err), http.StatusBadRequest)
+		h.logger.ErrorContext(r.Context(), "build consol pl csv", slog.Any("error", err))
+		httpx.Error(w, r, shared.UserSafeMessage(err), http.StatusBadRequest)
 		return
 	}
 	vm := NewConsolPLViewModel(report, warnings)
@@ -164,14 +165,14 @@ func (h *ProfitLossHandler) HandleExportCSV(w http.ResponseWriter, r *http.Reque
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	if err := writePLCSV(w, report, vm.Warnings); err != nil {
-		h.logger.Error("stream consol pl csv", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "stream consol pl csv", slog.Any("error", err))
 	}
 }
 
 // HandleExportPDF serves the PDF export of the consolidated P&L statement.
 func (h *ProfitLossHandler) HandleExportPDF(w http.ResponseWriter, r *http.Request) {
 	if h.pdfExporter == nil || !h.pdfExporter.Ready() {
-		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		httpx.Error(w, r, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 		return
 	}
 	h.pdfExporter.Serve(w, r, h)
@@ -222,6 +223,7 @@ func (h *ProfitLossHandler) parseFilters(r *http.Request) (consol.ProfitLossFilt
 	default:
 		errors["fx"] = "Pilihan FX tidak valid"
 	}
+	entityIDs = scopeEntities(r, entityIDs, errors)
 	if len(errors) > 0 {
 		return consol.ProfitLossFilters{}, errors
 	}
@@ -14,6 +14,8 @@ import (
 	"github.com/go-chi/httprate"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/consol"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/xlsx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -85,6 +87,8 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAny(shared.PermFinanceConsolView))
 		r.Get("/finance/consol", h.handleDashboard)
 		r.Get("/finance/consol/tb", h.handleGetTB)
+		r.Get("/finance/consol/ic-matching", h.handleICMatching)
+		r.Get("/finance/consol/mapping-check", h.handleMappingCheck)
 	})
 	r.Get("/consol", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/finance/consol", http.StatusSeeOther)
@@ -93,6 +97,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAny(shared.PermFinanceConsolExport))
 		r.Use(h.rateLimit)
 		r.Get("/finance/consol/tb/export.csv", h.handleExportCSV)
+		r.Get("/finance/consol/tb/export.xlsx", h.handleExportXLSX)
 		r.Get("/finance/consol/tb/pdf", h.handleExportPDF)
 	})
 
@@ -130,33 +135,53 @@ func (h *Handler) handleGetTB(w http.ResponseWriter, r *http.Request) {
 		Data:        vm,
 	}
 	if err := h.templates.Render(w, "pages/finance/consol_tb.html", data); err != nil {
-		h.logger.Error("render consol tb", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render consol tb", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 	}
 }
 
 func (h *Handler) handleExportCSV(w http.ResponseWriter, r *http.Request) {
 	filter, errors := h.parseFilters(r)
 	if len(errors) > 0 {
-		http.Error(w, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
+		httpx.Error(w, r, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
 		return
 	}
 	tb, err := h.service.GetConsolidatedTB(r.Context(), filter)
 	if err != nil {
-		h.logger.Error("get consol tb csv", slog.Any("error", err))
-		http.Error(w, shared.UserSafeMessage(err), http.StatusBadRequest)
+		h.logger.ErrorContext(r.Context(), "get consol tb csv", slog.Any("error", err))
+		httpx.Error(w, r, shared.UserSafeMessage(err), http.StatusBadRequest)
 		return
 	}
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", "attachment; filename=consolidated_tb.csv")
 	if err := writeTBCsv(w, tb, nil); err != nil {
-		h.logger.Error("stream consol tb csv", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "stream consol tb csv", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) handleExportXLSX(w http.ResponseWriter, r *http.Request) {
+	filter, errors := h.parseFilters(r)
+	if len(errors) > 0 {
+		httpx.Error(w, r, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
+		return
+	}
+	tb, err := h.service.GetConsolidatedTB(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get consol tb xlsx", slog.Any("error", err))
+		httpx.Error(w, r, shared.UserSafeMessage(err), http.StatusBadRequest)
+		return
+	}
+	filename := fmt.Sprintf("consolidated_tb_%d_%s.xlsx", tb.Filters.GroupID, tb.Filters.Period)
+	w.Header().Set("Content-Type", xlsx.ContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+	if err := writeTBXLSX(w, tb, time.Now()); err != nil {
+		h.logger.ErrorContext(r.Context(), "stream consol tb xlsx", slog.Any("error", err))
 	}
 }
 
 func (h *Handler) handleExportPDF(w http.ResponseWriter, r *http.Request) {
 	if h.pdfExporter == nil || !h.pdfExporter.Ready() {
-		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		httpx.Error(w, r, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 		return
 	}
 	h.pdfExporter.Serve(w, r, h)
@@ -177,9 +202,23 @@ func (h *Handler) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		CurrentPath: r.URL.Path,
 	}
 	if err := h.templates.Render(w, "pages/finance/consol_dashboard.html", data); err != nil {
-		h.logger.Error("render consol dashboard", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render consol dashboard", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// scopeEntities narrows the entity filter to the companies the user is
+// assigned to. Restricted users never fall back to "all entities".
+func scopeEntities(r *http.Request, ids []int64, errors map[string]string) []int64 {
+	scope := shared.CompanyScopeFromContext(r.Context())
+	if scope.Unrestricted {
+		return ids
+	}
+	scoped := scope.FilterIDs(ids)
+	if len(scoped) == 0 {
+		errors["entities"] = "Entitas di luar akses perusahaan Anda"
 	}
+	return scoped
 }
 
 func (h *Handler) parseFilters(r *http.Request) (consol.Filters, map[string]string) {
@@ -212,6 +251,7 @@ func (h *Handler) parseFilters(r *http.Request) (consol.Filters, map[string]stri
 			entityIDs = append(entityIDs, id)
 		}
 	}
+	entityIDs = scopeEntities(r, entityIDs, errors)
 	if len(errors) > 0 {
 		return consol.Filters{}, errors
 	}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/web"
 )
 
@@ -42,26 +43,26 @@ func (p *productionPDFExporter) Ready() bool {
 func (p *productionPDFExporter) Serve(w http.ResponseWriter, r *http.Request, h *Handler) {
 	filter, errors := h.parseFilters(r)
 	if len(errors) > 0 {
-		http.Error(w, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
+		httpx.Error(w, r, strings.Join(mapValues(errors), "; "), http.StatusBadRequest)
 		return
 	}
 	tb, err := h.service.GetConsolidatedTB(r.Context(), filter)
 	if err != nil {
-		p.logger.Error("get consol tb pdf", slog.Any("error", err))
-		http.Error(w, "Failed to generate report", http.StatusBadRequest)
+		p.logger.ErrorContext(r.Context(), "get consol tb pdf", slog.Any("error", err))
+		httpx.Error(w, r, "Failed to generate report", http.StatusBadRequest)
 		return
 	}
 	vm := FromDomain(tb)
 	buf := &bytes.Buffer{}
 	if err := p.templates.ExecuteTemplate(buf, "consol_tb_pdf.html", vm); err != nil {
-		p.logger.Error("render consol tb pdf", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		p.logger.ErrorContext(r.Context(), "render consol tb pdf", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	pdf, err := p.client.RenderHTML(r.Context(), buf.String())
 	if err != nil {
-		p.logger.Error("generate consol tb pdf", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		p.logger.ErrorContext(r.Context(), "generate consol tb pdf", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
 		return
 	}
 	w.Header().Set("Content-Type", "application/pdf")
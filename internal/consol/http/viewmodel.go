@@ -29,6 +29,7 @@ type ConsolTBVM struct {
 	GroupName    string
 	ReportingCCY string
 	PeriodLabel  string
+	Unmapped     consol.MappingCheck
 	Errors       map[string]string
 }
 
@@ -46,6 +47,7 @@ func FromDomain(tb consol.TrialBalance) ConsolTBVM {
 	vm.Totals.Group = tb.Totals.Group
 	vm.Totals.Balanced = tb.Totals.Balanced
 	vm.Members = append(vm.Members, tb.Members...)
+	vm.Unmapped = tb.Unmapped
 	vm.Lines = make([]struct {
 		GroupAccount string
 		Name         string
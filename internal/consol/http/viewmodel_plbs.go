@@ -76,10 +76,13 @@ type ConsolBSLine struct {
 
 // ConsolBSTotals stores the aggregated totals for the balance sheet.
 type ConsolBSTotals struct {
-	Assets     float64
-	LiabEquity float64
-	Balanced   bool
-	DeltaFX    float64
+	Assets      float64
+	Liabilities float64
+	Equity      float64
+	LiabEquity  float64
+	Difference  float64
+	Balanced    bool
+	DeltaFX     float64
 }
 
 // ConsolBSEntityContribution holds entity contribution data for the balance sheet.
@@ -163,10 +166,13 @@ func NewConsolBSViewModel(report consol.BalanceSheetReport, warnings []string) C
 		}
 	}
 	vm.Totals = ConsolBSTotals{
-		Assets:     report.Totals.Assets,
-		LiabEquity: report.Totals.LiabEquity,
-		Balanced:   report.Totals.Balanced,
-		DeltaFX:    report.Totals.DeltaFX,
+		Assets:      report.Totals.Assets,
+		Liabilities: report.Totals.Liabilities,
+		Equity:      report.Totals.Equity,
+		LiabEquity:  report.Totals.LiabEquity,
+		Difference:  report.Totals.Difference,
+		Balanced:    report.Totals.Balanced,
+		DeltaFX:     report.Totals.DeltaFX,
 	}
 	vm.Contributions = make([]ConsolBSEntityContribution, len(report.Contributions))
 	for i, contrib := range report.Contributions {
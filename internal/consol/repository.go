@@ -20,6 +20,18 @@ import (
 type Repository struct {
 	pool    *pgxpool.Pool
 	queries *sqlc.Queries
+	daily   DailyRateSource
+}
+
+// DailyRateSource derives monthly quotes from daily exchange rates.
+type DailyRateSource interface {
+	PeriodRates(ctx context.Context, from, to string, monthStart time.Time) (average, closing float64, err error)
+}
+
+// SetDailyRates lets FxRateForPeriod fall back to daily rates when no
+// monthly quote was loaded for the pair.
+func (r *Repository) SetDailyRates(source DailyRateSource) {
+	r.daily = source
 }
 
 // NewRepository constructs a consolidation repository.
@@ -171,6 +183,49 @@ func (r *Repository) ListGroupIDs(ctx context.Context) ([]int64, error) {
 	return r.queries.ListGroupIDs(ctx)
 }
 
+// RefreshWatermark reads the group's source change watermark for the period
+// alongside the watermark recorded by its last refresh.
+func (r *Repository) RefreshWatermark(ctx context.Context, groupID int64, periodCode string) (RefreshWatermark, error) {
+	periodID, err := r.FindPeriodID(ctx, periodCode)
+	if err != nil {
+		return RefreshWatermark{}, err
+	}
+	source, err := r.queries.ConsolGroupSourceWatermark(ctx, sqlc.ConsolGroupSourceWatermarkParams{
+		PeriodID: periodID,
+		GroupID:  groupID,
+	})
+	if err != nil {
+		return RefreshWatermark{}, err
+	}
+	watermark := RefreshWatermark{SourceChangedAt: source.Time}
+	row, err := r.queries.GetConsolRefreshWatermark(ctx, sqlc.GetConsolRefreshWatermarkParams{
+		GroupID:  groupID,
+		PeriodID: periodID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return watermark, nil
+		}
+		return RefreshWatermark{}, err
+	}
+	watermark.RefreshedThrough = row.SourceWatermark.Time
+	watermark.RefreshedAt = row.RefreshedAt.Time
+	return watermark, nil
+}
+
+// MarkRefreshed records the source watermark a refresh was built from.
+func (r *Repository) MarkRefreshed(ctx context.Context, groupID int64, periodCode string, sourceChangedAt time.Time) error {
+	periodID, err := r.FindPeriodID(ctx, periodCode)
+	if err != nil {
+		return err
+	}
+	return r.queries.UpsertConsolRefreshWatermark(ctx, sqlc.UpsertConsolRefreshWatermarkParams{
+		GroupID:         groupID,
+		PeriodID:        periodID,
+		SourceWatermark: pgtype.Timestamptz{Time: sourceChangedAt, Valid: true},
+	})
+}
+
 // ActiveConsolidationPeriod returns the period code flagged as OPEN_CONSOL.
 func (r *Repository) ActiveConsolidationPeriod(ctx context.Context) (string, error) {
 	code, err := r.queries.ActiveConsolidationPeriod(ctx)
@@ -216,6 +271,66 @@ func (r *Repository) Balances(ctx context.Context, groupID, periodID int64) ([]B
 	return balances, nil
 }
 
+// IntercompanyBalances returns intercompany-tagged balances per member and
+// counterparty, classified by group account type.
+func (r *Repository) IntercompanyBalances(ctx context.Context, groupID, periodID int64) ([]ICBalanceRow, error) {
+	const query = `
+		SELECT b.company_id, c.name, b.counterparty_id, COALESCE(cp.name, ''), ga.type::text, b.balance::float8
+		FROM ic_intercompany_balances b
+		JOIN consol_group_accounts ga ON ga.id = b.group_account_id
+		JOIN companies c ON c.id = b.company_id
+		LEFT JOIN companies cp ON cp.id = b.counterparty_id
+		WHERE b.group_id = $1 AND b.period_id = $2
+		ORDER BY b.company_id, b.counterparty_id, ga.code
+	`
+	rows, err := r.pool.Query(ctx, query, groupID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ICBalanceRow
+	for rows.Next() {
+		var row ICBalanceRow
+		if err := rows.Scan(&row.CompanyID, &row.CompanyName, &row.CounterpartyID, &row.CounterpartyName, &row.AccountType, &row.Balance); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// UnmappedBalances returns each member's posted period balance on local
+// accounts that have no account_map entry for the group.
+func (r *Repository) UnmappedBalances(ctx context.Context, groupID, periodID int64) ([]UnmappedBalanceRow, error) {
+	const query = `
+		SELECT cm.company_id, c.name, a.id, a.code, a.name, SUM(jl.debit - jl.credit)::float8
+		FROM journal_lines jl
+		JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED' AND je.period_id = $2
+		JOIN consol_members cm ON cm.company_id = jl.dim_company_id AND cm.group_id = $1 AND cm.enabled
+		JOIN companies c ON c.id = cm.company_id
+		JOIN accounts a ON a.id = jl.account_id
+		LEFT JOIN account_map am ON am.group_id = cm.group_id AND am.company_id = cm.company_id AND am.local_account_id = jl.account_id
+		WHERE am.id IS NULL
+		GROUP BY cm.company_id, c.name, a.id, a.code, a.name
+		HAVING SUM(jl.debit - jl.credit) <> 0
+		ORDER BY cm.company_id, a.code
+	`
+	rows, err := r.pool.Query(ctx, query, groupID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []UnmappedBalanceRow
+	for rows.Next() {
+		var row UnmappedBalanceRow
+		if err := rows.Scan(&row.CompanyID, &row.CompanyName, &row.AccountID, &row.AccountCode, &row.AccountName, &row.Balance); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
 // ConsolBalancesByType fetches balances grouped by their account type classification.
 func (r *Repository) ConsolBalancesByType(ctx context.Context, groupID int64, periodCode string, entities []int64) ([]ConsolBalanceByTypeQueryRow, error) {
 	if groupID <= 0 {
@@ -295,7 +410,7 @@ func (r *Repository) FxRateForPeriod(ctx context.Context, asOf time.Time, pair s
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return zero, ErrFxRateNotFound
+			return r.dailyQuote(ctx, asOf, pair)
 		}
 		return zero, err
 	}
@@ -309,6 +424,18 @@ func (r *Repository) FxRateForPeriod(ctx context.Context, asOf time.Time, pair s
 	}, nil
 }
 
+// dailyQuote builds a quote from daily rates for pairs such as "USDIDR".
+func (r *Repository) dailyQuote(ctx context.Context, monthStart time.Time, pair string) (fx.Quote, error) {
+	if r.daily == nil || len(pair) != 6 {
+		return fx.Quote{}, ErrFxRateNotFound
+	}
+	average, closing, err := r.daily.PeriodRates(ctx, pair[:3], pair[3:], monthStart)
+	if err != nil {
+		return fx.Quote{}, fmt.Errorf("%w: %v", ErrFxRateNotFound, err)
+	}
+	return fx.Quote{Average: average, Closing: closing}, nil
+}
+
 // UpsertFxRates persists FX quotes, replacing existing rows when necessary.
 func (r *Repository) UpsertFxRates(ctx context.Context, rows []FxRateInput) error {
 	if len(rows) == 0 {
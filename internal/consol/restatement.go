@@ -0,0 +1,52 @@
+package consol
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPeriodLocked indicates a rebuild of a period that has been locked.
+var ErrPeriodLocked = errors.New("consol: period is locked")
+
+// Restatement notes that a group's consolidated balances for a past period
+// were rebuilt because a member restated its books.
+type Restatement struct {
+	GroupID     int64
+	Period      string
+	Reason      string
+	RequestedBy int64
+	// LockOverridden is set when the period was locked and the rebuild was
+	// explicitly allowed anyway.
+	LockOverridden bool
+	RestatedAt     time.Time
+}
+
+// PeriodLocked reports whether the period has been locked against changes.
+func (r *Repository) PeriodLocked(ctx context.Context, periodCode string) (bool, error) {
+	var locked bool
+	err := r.pool.QueryRow(ctx, `SELECT status = 'LOCKED' FROM periods WHERE code = $1`, periodCode).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrPeriodNotFound
+		}
+		return false, err
+	}
+	return locked, nil
+}
+
+// RecordRestatement keeps the audit note of a restated consolidation.
+func (r *Repository) RecordRestatement(ctx context.Context, restatement Restatement) error {
+	periodID, err := r.FindPeriodID(ctx, restatement.Period)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO consol_restatements (group_id, period_id, reason, requested_by, lock_overridden, restated_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6)`,
+		restatement.GroupID, periodID, restatement.Reason, restatement.RequestedBy,
+		restatement.LockOverridden, restatement.RestatedAt)
+	return err
+}
@@ -15,6 +15,8 @@ type DBRepository interface {
 	Members(ctx context.Context, groupID int64) ([]MemberRow, error)
 	RebuildConsolidation(ctx context.Context, groupID, periodID int64) error
 	Balances(ctx context.Context, groupID, periodID int64) ([]BalanceRow, error)
+	IntercompanyBalances(ctx context.Context, groupID, periodID int64) ([]ICBalanceRow, error)
+	UnmappedBalances(ctx context.Context, groupID, periodID int64) ([]UnmappedBalanceRow, error)
 }
 
 // Service orchestrates consolidation operations.
@@ -94,6 +96,14 @@ func (s *Service) GetConsolidatedTB(ctx context.Context, filter Filters) (TrialB
 	if err != nil {
 		return TrialBalance{}, err
 	}
+	unmapped, err := s.repo.UnmappedBalances(ctx, filter.GroupID, periodID)
+	if err != nil {
+		return TrialBalance{}, err
+	}
+	gaps := GroupUnmapped(unmapped).Only(filter.Entities)
+	gaps.GroupID = filter.GroupID
+	gaps.Period = filter.Period
+	gaps.GroupName = groupName
 	includeAll := len(filter.Entities) == 0
 	include := make(map[int64]struct{})
 	for _, id := range filter.Entities {
@@ -171,5 +181,6 @@ func (s *Service) GetConsolidatedTB(ctx context.Context, filter Filters) (TrialB
 		Lines:         balances,
 		Contributions: contribList,
 		Members:       tbMembers,
+		Unmapped:      gaps,
 	}, nil
 }
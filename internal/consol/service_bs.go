@@ -28,12 +28,20 @@ type BalanceSheetLine struct {
 }
 
 type BalanceSheetTotals struct {
-	Assets     float64
-	LiabEquity float64
+	Assets      float64
+	Liabilities float64
+	Equity      float64
+	LiabEquity  float64
+	// Difference is Assets minus LiabEquity; Balanced is false when it
+	// exceeds a cent.
+	Difference float64
 	Balanced   bool
 	DeltaFX    float64
 }
 
+// CurrentEarningsName labels the equity line holding the period's net income.
+const CurrentEarningsName = "Current period earnings"
+
 type BalanceSheetContribution struct {
 	EntityName  string
 	GroupAmount float64
@@ -56,11 +64,19 @@ type BalanceSheetRepository interface {
 }
 
 type BalanceSheetService struct {
-	repo BalanceSheetRepository
+	repo       BalanceSheetRepository
+	statements *StatementMapping
 }
 
 func NewBalanceSheetService(repo BalanceSheetRepository) *BalanceSheetService {
-	return &BalanceSheetService{repo: repo}
+	return &BalanceSheetService{repo: repo, statements: DefaultStatementMapping()}
+}
+
+// SetStatementMapping overrides how group accounts map to balance sheet lines.
+func (s *BalanceSheetService) SetStatementMapping(mapping *StatementMapping) {
+	if mapping != nil {
+		s.statements = mapping
+	}
 }
 
 func (s *BalanceSheetService) Build(ctx context.Context, filters BalanceSheetFilters) (BalanceSheetReport, []string, error) {
@@ -92,17 +108,33 @@ func (s *BalanceSheetService) Build(ctx context.Context, filters BalanceSheetFil
 
 	sortLines(assets)
 	sortLines(liabEq)
+	// Income and expense balances are not closed to retained earnings until
+	// year end, so the period's net income is carried as its own equity line.
+	if math.Abs(totals.earningsGroup) > 0.005 || math.Abs(totals.earningsLocal) > 0.005 {
+		liabEq = append(liabEq, BalanceSheetLine{
+			AccountName: CurrentEarningsName,
+			LocalAmount: totals.earningsLocal,
+			GroupAmount: totals.earningsGroup,
+			Section:     StatementEquity,
+		})
+		totals.totalEquity += totals.earningsGroup
+	}
 	contributionList := buildContributionList(contributions, totals.contributionBasis)
 
+	liabEquity := totals.totalLiabilities + totals.totalEquity
+	difference := totals.totalAssets - liabEquity
 	report := BalanceSheetReport{
 		Filters:       filters,
 		Assets:        assets,
 		LiabilitiesEq: liabEq,
 		Totals: BalanceSheetTotals{
-			Assets:     totals.totalAssets,
-			LiabEquity: totals.totalLiabEq,
-			Balanced:   math.Abs(totals.totalAssets-totals.totalLiabEq) <= 0.01,
-			DeltaFX:    totals.deltaFX,
+			Assets:      totals.totalAssets,
+			Liabilities: totals.totalLiabilities,
+			Equity:      totals.totalEquity,
+			LiabEquity:  liabEquity,
+			Difference:  difference,
+			Balanced:    math.Abs(difference) <= 0.01,
+			DeltaFX:     totals.deltaFX,
 		},
 		Contributions: contributionList,
 	}
@@ -129,7 +161,10 @@ func (s *BalanceSheetService) validateFilters(filters BalanceSheetFilters) error
 
 type bsTotals struct {
 	totalAssets       float64
-	totalLiabEq       float64
+	totalLiabilities  float64
+	totalEquity       float64
+	earningsLocal     float64
+	earningsGroup     float64
 	deltaFX           float64
 	contributionBasis float64
 }
@@ -148,6 +183,10 @@ func (s *BalanceSheetService) processRows(
 	var totals bsTotals
 
 	for _, row := range rows {
+		section := s.statements.Classify(row.AccountType, row.GroupAccountCode)
+		if section == "" {
+			continue
+		}
 		members, err := ParseMembers(row.MembersJSON)
 		if err != nil {
 			continue
@@ -162,7 +201,12 @@ func (s *BalanceSheetService) processRows(
 		convertedGroup, delta := s.applyFXConversion(row, mb, fxResult, convertedGroup, period, warnings)
 		totals.deltaFX += delta
 
-		section := strings.ToUpper(row.AccountType)
+		if IsProfitLossLine(section) {
+			// Credit-normal: revenue raises earnings, expenses reduce them.
+			totals.earningsLocal -= mb.localTotal
+			totals.earningsGroup -= convertedGroup
+			continue
+		}
 		displayLocal := math.Abs(mb.localTotal)
 		displayGroup := math.Abs(convertedGroup)
 
@@ -174,12 +218,16 @@ func (s *BalanceSheetService) processRows(
 			Section:     section,
 		}
 
-		if section == "ASSET" {
+		switch section {
+		case StatementAsset:
 			assets = append(assets, line)
 			totals.totalAssets += displayGroup
-		} else {
+		case StatementLiability:
+			liabEq = append(liabEq, line)
+			totals.totalLiabilities += displayGroup
+		default:
 			liabEq = append(liabEq, line)
-			totals.totalLiabEq += displayGroup
+			totals.totalEquity += displayGroup
 		}
 
 		s.updateContributions(mb, displayGroup, contributions)
@@ -163,3 +163,52 @@ func TestBalanceSheetServiceFiltersEntities(t *testing.T) {
 		t.Fatalf("expected contribution for Beta got %s", report.Contributions[0].EntityName)
 	}
 }
+
+func TestBalanceSheetServiceRollsEarningsIntoEquity(t *testing.T) {
+	row := func(code, accountType string, amount float64) ConsolBalanceByTypeQueryRow {
+		return ConsolBalanceByTypeQueryRow{
+			GroupAccountCode: code,
+			GroupAccountName: code,
+			AccountType:      accountType,
+			LocalAmount:      amount,
+			GroupAmount:      amount,
+			MembersJSON:      marshalMembers(map[string]interface{}{"company_id": 1, "company_name": "Alpha", "local_ccy_amt": amount}),
+		}
+	}
+	repo := &fakeBSRepo{rows: []ConsolBalanceByTypeQueryRow{
+		row("1000", "ASSET", 500),
+		row("2000", "LIABILITY", -200),
+		row("3000", "EQUITY", -100),
+		row("4000", "REVENUE", -700),
+		row("5000", "EXPENSE", 500),
+	}}
+
+	svc := NewBalanceSheetService(repo)
+	report, _, err := svc.Build(context.Background(), BalanceSheetFilters{GroupID: 10, Period: "2024-01"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(report.LiabilitiesEq) != 3 {
+		t.Fatalf("expected liability, equity and earnings lines got %d", len(report.LiabilitiesEq))
+	}
+	earnings := report.LiabilitiesEq[2]
+	if earnings.AccountName != CurrentEarningsName || earnings.GroupAmount != 200 {
+		t.Fatalf("expected current earnings 200 got %+v", earnings)
+	}
+	totals := report.Totals
+	if totals.Liabilities != 200 || totals.Equity != 300 || totals.LiabEquity != 500 {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+	if !totals.Balanced || totals.Difference != 0 {
+		t.Fatalf("expected balanced sheet got %+v", totals)
+	}
+
+	repo.rows = repo.rows[:3]
+	report, _, err = svc.Build(context.Background(), BalanceSheetFilters{GroupID: 10, Period: "2024-01"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if report.Totals.Balanced || report.Totals.Difference != 200 {
+		t.Fatalf("expected unbalanced sheet with difference 200 got %+v", report.Totals)
+	}
+}
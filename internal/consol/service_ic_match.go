@@ -0,0 +1,259 @@
+package consol
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// DefaultICMatchTolerance is the absolute difference still treated as matched.
+const DefaultICMatchTolerance = 0.01
+
+// ICMatchKind identifies the intercompany pairing being compared.
+type ICMatchKind string
+
+const (
+	// ICMatchARAP compares one member's receivable with the counterparty's payable.
+	ICMatchARAP ICMatchKind = "AR_AP"
+	// ICMatchSalesPurchases compares one member's sales with the counterparty's purchases.
+	ICMatchSalesPurchases ICMatchKind = "SALES_PURCHASES"
+)
+
+// ICMatchStatus classifies a compared pair.
+type ICMatchStatus string
+
+const (
+	// ICMatchMatched means both sides agree within tolerance.
+	ICMatchMatched ICMatchStatus = "MATCHED"
+	// ICMatchMismatch means both sides are booked but disagree.
+	ICMatchMismatch ICMatchStatus = "MISMATCH"
+	// ICMatchOneSided means only one member booked the transaction.
+	ICMatchOneSided ICMatchStatus = "ONE_SIDED"
+)
+
+// ICMatchFilters scopes the intercompany matching report.
+type ICMatchFilters struct {
+	GroupID   int64
+	Period    string
+	Tolerance float64
+}
+
+// ICBalanceRow is an intercompany-tagged balance for one member towards a counterparty.
+type ICBalanceRow struct {
+	CompanyID        int64
+	CompanyName      string
+	CounterpartyID   int64
+	CounterpartyName string
+	AccountType      string
+	Balance          float64
+}
+
+// ICMatchLine compares company A's side with company B's mirror side.
+type ICMatchLine struct {
+	Kind         ICMatchKind
+	CompanyAID   int64
+	CompanyAName string
+	CompanyBID   int64
+	CompanyBName string
+	AAmount      float64
+	BAmount      float64
+	Matched      float64
+	Difference   float64
+	Status       ICMatchStatus
+}
+
+// ICMatchTotals summarises the report.
+type ICMatchTotals struct {
+	AAmount    float64
+	BAmount    float64
+	Difference float64
+	Mismatches int
+	Refreshed  time.Time
+}
+
+// ICMatchReport lists intercompany pairs ahead of eliminations.
+type ICMatchReport struct {
+	Filters      ICMatchFilters
+	GroupName    string
+	ReportingCCY string
+	Lines        []ICMatchLine
+	Totals       ICMatchTotals
+}
+
+// IntercompanyMatching compares intercompany AR vs AP and sales vs purchases
+// for every member pair in the group so mismatches can be investigated before
+// eliminations are posted. A restricted caller only sees, and only gets
+// totals for, pairs involving one of their companies.
+func (s *Service) IntercompanyMatching(ctx context.Context, filter ICMatchFilters) (ICMatchReport, error) {
+	if s == nil || s.repo == nil {
+		return ICMatchReport{}, fmt.Errorf("consol service not initialised")
+	}
+	if filter.GroupID <= 0 {
+		return ICMatchReport{}, fmt.Errorf("group id wajib diisi")
+	}
+	if filter.Period == "" {
+		return ICMatchReport{}, fmt.Errorf("periode wajib diisi")
+	}
+	if _, err := time.Parse("2006-01", filter.Period); err != nil {
+		return ICMatchReport{}, fmt.Errorf("format periode tidak valid")
+	}
+	if filter.Tolerance <= 0 {
+		filter.Tolerance = DefaultICMatchTolerance
+	}
+	periodID, err := s.repo.FindPeriodID(ctx, filter.Period)
+	if err != nil {
+		return ICMatchReport{}, err
+	}
+	groupName, ccy, err := s.repo.GetGroup(ctx, filter.GroupID)
+	if err != nil {
+		return ICMatchReport{}, err
+	}
+	rows, err := s.repo.IntercompanyBalances(ctx, filter.GroupID, periodID)
+	if err != nil {
+		return ICMatchReport{}, err
+	}
+	lines := scopeICLines(shared.CompanyScopeFromContext(ctx), MatchIntercompany(rows, filter.Tolerance))
+	totals := sumICLines(lines)
+	totals.Refreshed = s.now().UTC()
+	return ICMatchReport{
+		Filters:      filter,
+		GroupName:    groupName,
+		ReportingCCY: ccy,
+		Lines:        lines,
+		Totals:       totals,
+	}, nil
+}
+
+// scopeICLines keeps only pairs involving at least one company of the scope.
+func scopeICLines(scope shared.CompanyScope, lines []ICMatchLine) []ICMatchLine {
+	if scope.Unrestricted {
+		return lines
+	}
+	out := make([]ICMatchLine, 0, len(lines))
+	for _, line := range lines {
+		if scope.Allows(line.CompanyAID) || scope.Allows(line.CompanyBID) {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func sumICLines(lines []ICMatchLine) ICMatchTotals {
+	var totals ICMatchTotals
+	for _, line := range lines {
+		totals.AAmount += line.AAmount
+		totals.BAmount += line.BAmount
+		totals.Difference += line.Difference
+		if line.Status != ICMatchMatched {
+			totals.Mismatches++
+		}
+	}
+	return totals
+}
+
+type icPair struct {
+	company      int64
+	counterparty int64
+}
+
+type icSides struct {
+	receivable float64
+	payable    float64
+	sales      float64
+	purchases  float64
+}
+
+// MatchIntercompany pairs each member's receivable/sales towards a
+// counterparty with the counterparty's payable/purchases back to that member.
+// Lines are ordered by kind, then by largest absolute difference.
+func MatchIntercompany(rows []ICBalanceRow, tolerance float64) []ICMatchLine {
+	sides := make(map[icPair]*icSides)
+	names := make(map[int64]string)
+	for _, row := range rows {
+		names[row.CompanyID] = row.CompanyName
+		if _, ok := names[row.CounterpartyID]; !ok || names[row.CounterpartyID] == "" {
+			names[row.CounterpartyID] = row.CounterpartyName
+		}
+		key := icPair{company: row.CompanyID, counterparty: row.CounterpartyID}
+		side := sides[key]
+		if side == nil {
+			side = &icSides{}
+			sides[key] = side
+		}
+		switch row.AccountType {
+		case "ASSET":
+			side.receivable += math.Max(row.Balance, 0)
+		case "LIABILITY":
+			side.payable += math.Max(-row.Balance, 0)
+		case "REVENUE":
+			side.sales += -row.Balance
+		case "EXPENSE":
+			side.purchases += row.Balance
+		}
+	}
+
+	pairs := make(map[icPair]struct{}, len(sides))
+	for key := range sides {
+		pairs[key] = struct{}{}
+		pairs[icPair{company: key.counterparty, counterparty: key.company}] = struct{}{}
+	}
+
+	lines := make([]ICMatchLine, 0, len(pairs)*2)
+	for key := range pairs {
+		a := sides[key]
+		b := sides[icPair{company: key.counterparty, counterparty: key.company}]
+		if a == nil {
+			a = &icSides{}
+		}
+		if b == nil {
+			b = &icSides{}
+		}
+		lines = appendICLine(lines, ICMatchARAP, key, names, a.receivable, b.payable, tolerance)
+		lines = appendICLine(lines, ICMatchSalesPurchases, key, names, a.sales, b.purchases, tolerance)
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Kind != lines[j].Kind {
+			return lines[i].Kind < lines[j].Kind
+		}
+		di, dj := math.Abs(lines[i].Difference), math.Abs(lines[j].Difference)
+		if di != dj {
+			return di > dj
+		}
+		if lines[i].CompanyAID != lines[j].CompanyAID {
+			return lines[i].CompanyAID < lines[j].CompanyAID
+		}
+		return lines[i].CompanyBID < lines[j].CompanyBID
+	})
+	return lines
+}
+
+func appendICLine(lines []ICMatchLine, kind ICMatchKind, key icPair, names map[int64]string, aAmount, bAmount, tolerance float64) []ICMatchLine {
+	if math.Abs(aAmount) <= tolerance && math.Abs(bAmount) <= tolerance {
+		return lines
+	}
+	diff := aAmount - bAmount
+	status := ICMatchMismatch
+	switch {
+	case math.Abs(diff) <= tolerance:
+		status = ICMatchMatched
+	case math.Abs(aAmount) <= tolerance || math.Abs(bAmount) <= tolerance:
+		status = ICMatchOneSided
+	}
+	return append(lines, ICMatchLine{
+		Kind:         kind,
+		CompanyAID:   key.company,
+		CompanyAName: names[key.company],
+		CompanyBID:   key.counterparty,
+		CompanyBName: names[key.counterparty],
+		AAmount:      aAmount,
+		BAmount:      bAmount,
+		Matched:      math.Min(aAmount, bAmount),
+		Difference:   diff,
+		Status:       status,
+	})
+}
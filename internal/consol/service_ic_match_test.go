@@ -0,0 +1,57 @@
+package consol
+
+import (
+	"testing"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func TestMatchIntercompanyClassifiesPairs(t *testing.T) {
+	rows := []ICBalanceRow{
+		{CompanyID: 1, CompanyName: "Parent", CounterpartyID: 2, CounterpartyName: "Sub", AccountType: "ASSET", Balance: 1000},
+		{CompanyID: 2, CompanyName: "Sub", CounterpartyID: 1, CounterpartyName: "Parent", AccountType: "LIABILITY", Balance: -1000},
+		{CompanyID: 1, CompanyName: "Parent", CounterpartyID: 2, CounterpartyName: "Sub", AccountType: "REVENUE", Balance: -800},
+		{CompanyID: 2, CompanyName: "Sub", CounterpartyID: 1, CounterpartyName: "Parent", AccountType: "EXPENSE", Balance: 750},
+		{CompanyID: 3, CompanyName: "Branch", CounterpartyID: 1, CounterpartyName: "Parent", AccountType: "ASSET", Balance: 200},
+	}
+	lines := MatchIntercompany(rows, DefaultICMatchTolerance)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %+v", len(lines), lines)
+	}
+
+	oneSided := lines[0]
+	if oneSided.Kind != ICMatchARAP || oneSided.CompanyAID != 3 || oneSided.Status != ICMatchOneSided || oneSided.Difference != 200 {
+		t.Fatalf("unexpected one-sided line %+v", oneSided)
+	}
+	if oneSided.CompanyBName != "Parent" {
+		t.Fatalf("expected counterparty name Parent, got %q", oneSided.CompanyBName)
+	}
+
+	matched := lines[1]
+	if matched.Kind != ICMatchARAP || matched.Status != ICMatchMatched || matched.Matched != 1000 {
+		t.Fatalf("unexpected matched line %+v", matched)
+	}
+
+	mismatch := lines[2]
+	if mismatch.Kind != ICMatchSalesPurchases || mismatch.Status != ICMatchMismatch || mismatch.Difference != 50 || mismatch.Matched != 750 {
+		t.Fatalf("unexpected mismatch line %+v", mismatch)
+	}
+}
+
+func TestScopedICLinesTotalOnlyVisiblePairs(t *testing.T) {
+	rows := []ICBalanceRow{
+		{CompanyID: 1, CounterpartyID: 2, AccountType: "ASSET", Balance: 1000},
+		{CompanyID: 2, CounterpartyID: 1, AccountType: "LIABILITY", Balance: -900},
+		{CompanyID: 3, CounterpartyID: 4, AccountType: "ASSET", Balance: 500},
+	}
+	lines := scopeICLines(shared.CompanyScope{CompanyIDs: []int64{2}}, MatchIntercompany(rows, DefaultICMatchTolerance))
+	for _, line := range lines {
+		if line.CompanyAID != 2 && line.CompanyBID != 2 {
+			t.Fatalf("pair outside the scope listed: %+v", line)
+		}
+	}
+	totals := sumICLines(lines)
+	if totals.AAmount != 1000 || totals.BAmount != 900 || totals.Difference != 100 || totals.Mismatches != 1 {
+		t.Fatalf("totals should only cover visible pairs, got %+v", totals)
+	}
+}
@@ -0,0 +1,139 @@
+package consol
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// UnmappedBalanceRow is a member's local account that carries a balance for
+// the period but has no group account in account_map.
+type UnmappedBalanceRow struct {
+	CompanyID   int64
+	CompanyName string
+	AccountID   int64
+	AccountCode string
+	AccountName string
+	Balance     float64
+}
+
+// MappingGap lists the unmapped accounts of one member.
+type MappingGap struct {
+	CompanyID   int64
+	CompanyName string
+	Accounts    []UnmappedBalanceRow
+	// Total is the sum of absolute balances left out of consolidation.
+	Total float64
+}
+
+// MappingCheck reports balances that a consolidation run would drop because
+// their local accounts are not mapped to the group chart.
+type MappingCheck struct {
+	GroupID   int64
+	Period    string
+	GroupName string
+	Members   []MappingGap
+	Accounts  int
+	// Total is the sum of absolute unmapped balances across members, so
+	// debits and credits left out do not cancel each other.
+	Total float64
+}
+
+// Complete reports whether every account with a balance is mapped.
+func (c MappingCheck) Complete() bool {
+	return c.Accounts == 0
+}
+
+// Only keeps the members in include; an empty include keeps all of them.
+func (c MappingCheck) Only(include []int64) MappingCheck {
+	if len(include) == 0 {
+		return c
+	}
+	keep := make(map[int64]struct{}, len(include))
+	for _, id := range include {
+		keep[id] = struct{}{}
+	}
+	out := MappingCheck{GroupID: c.GroupID, Period: c.Period, GroupName: c.GroupName}
+	for _, gap := range c.Members {
+		if _, ok := keep[gap.CompanyID]; !ok {
+			continue
+		}
+		out.Members = append(out.Members, gap)
+		out.Accounts += len(gap.Accounts)
+		out.Total += gap.Total
+	}
+	return out
+}
+
+// CheckMappings lists, per member, the local accounts with a non-zero balance
+// in the period that have no account_map entry. It is meant to be run before
+// a consolidation so the gaps can be mapped first.
+func (s *Service) CheckMappings(ctx context.Context, groupID int64, period string) (MappingCheck, error) {
+	if s == nil || s.repo == nil {
+		return MappingCheck{}, fmt.Errorf("consol service not initialised")
+	}
+	if groupID <= 0 {
+		return MappingCheck{}, fmt.Errorf("group id wajib diisi")
+	}
+	if period == "" {
+		return MappingCheck{}, fmt.Errorf("periode wajib diisi")
+	}
+	if _, err := time.Parse("2006-01", period); err != nil {
+		return MappingCheck{}, fmt.Errorf("format periode tidak valid")
+	}
+	periodID, err := s.repo.FindPeriodID(ctx, period)
+	if err != nil {
+		return MappingCheck{}, err
+	}
+	groupName, _, err := s.repo.GetGroup(ctx, groupID)
+	if err != nil {
+		return MappingCheck{}, err
+	}
+	rows, err := s.repo.UnmappedBalances(ctx, groupID, periodID)
+	if err != nil {
+		return MappingCheck{}, err
+	}
+	check := GroupUnmapped(rows)
+	check.GroupID = groupID
+	check.Period = period
+	check.GroupName = groupName
+	return check, nil
+}
+
+// GroupUnmapped collects unmapped balances by member. Members are ordered by
+// largest unmapped total and accounts by code.
+func GroupUnmapped(rows []UnmappedBalanceRow) MappingCheck {
+	var check MappingCheck
+	index := make(map[int64]int)
+	for _, row := range rows {
+		if math.Abs(row.Balance) < 0.005 {
+			continue
+		}
+		i, ok := index[row.CompanyID]
+		if !ok {
+			i = len(check.Members)
+			index[row.CompanyID] = i
+			check.Members = append(check.Members, MappingGap{CompanyID: row.CompanyID, CompanyName: row.CompanyName})
+		}
+		gap := &check.Members[i]
+		gap.Accounts = append(gap.Accounts, row)
+		gap.Total += math.Abs(row.Balance)
+		check.Accounts++
+		check.Total += math.Abs(row.Balance)
+	}
+	for i := range check.Members {
+		accounts := check.Members[i].Accounts
+		sort.SliceStable(accounts, func(a, b int) bool {
+			return accounts[a].AccountCode < accounts[b].AccountCode
+		})
+	}
+	sort.SliceStable(check.Members, func(i, j int) bool {
+		if check.Members[i].Total != check.Members[j].Total {
+			return check.Members[i].Total > check.Members[j].Total
+		}
+		return check.Members[i].CompanyID < check.Members[j].CompanyID
+	})
+	return check
+}
@@ -0,0 +1,63 @@
+package consol
+
+import (
+	"context"
+	"testing"
+)
+
+type mappingRepo struct {
+	DBRepository
+	rows []UnmappedBalanceRow
+}
+
+func (m mappingRepo) FindPeriodID(context.Context, string) (int64, error) { return 7, nil }
+
+func (m mappingRepo) GetGroup(context.Context, int64) (string, string, error) {
+	return "Odyssey Group", "IDR", nil
+}
+
+func (m mappingRepo) UnmappedBalances(_ context.Context, _, periodID int64) ([]UnmappedBalanceRow, error) {
+	if periodID != 7 {
+		return nil, nil
+	}
+	return m.rows, nil
+}
+
+func TestCheckMappingsGroupsGapsPerMember(t *testing.T) {
+	svc := NewService(mappingRepo{rows: []UnmappedBalanceRow{
+		{CompanyID: 1, CompanyName: "Parent", AccountID: 11, AccountCode: "6100", Balance: 250},
+		{CompanyID: 2, CompanyName: "Sub", AccountID: 21, AccountCode: "2190", Balance: -900},
+		{CompanyID: 1, CompanyName: "Parent", AccountID: 12, AccountCode: "1190", Balance: -250},
+		{CompanyID: 2, CompanyName: "Sub", AccountID: 22, AccountCode: "1000", Balance: 0.001},
+	}})
+
+	check, err := svc.CheckMappings(context.Background(), 3, "2026-09")
+	if err != nil {
+		t.Fatalf("CheckMappings: %v", err)
+	}
+	if check.Complete() || check.Accounts != 3 || check.Total != 1400 {
+		t.Fatalf("expected 3 accounts totalling 1400, got %d / %v", check.Accounts, check.Total)
+	}
+	if check.GroupName != "Odyssey Group" || len(check.Members) != 2 {
+		t.Fatalf("unexpected check %+v", check)
+	}
+	if sub := check.Members[0]; sub.CompanyID != 2 || sub.Total != 900 || len(sub.Accounts) != 1 {
+		t.Fatalf("expected the largest gap first, got %+v", sub)
+	}
+	// Offsetting balances within a member still count in full.
+	if parent := check.Members[1]; parent.Total != 500 || parent.Accounts[0].AccountCode != "1190" {
+		t.Fatalf("unexpected parent gap %+v", parent)
+	}
+
+	scoped := check.Only([]int64{1})
+	if scoped.Accounts != 2 || scoped.Total != 500 || len(scoped.Members) != 1 {
+		t.Fatalf("expected only the parent gap, got %+v", scoped)
+	}
+}
+
+func TestCheckMappingsRequiresPeriod(t *testing.T) {
+	svc := NewService(mappingRepo{})
+	if _, err := svc.CheckMappings(context.Background(), 3, "2026/09"); err == nil {
+		t.Fatal("expected an invalid period to be rejected")
+	}
+}
@@ -57,11 +57,19 @@ type ProfitLossRepository interface {
 }
 
 type ProfitLossService struct {
-	repo ProfitLossRepository
+	repo       ProfitLossRepository
+	statements *StatementMapping
 }
 
 func NewProfitLossService(repo ProfitLossRepository) *ProfitLossService {
-	return &ProfitLossService{repo: repo}
+	return &ProfitLossService{repo: repo, statements: DefaultStatementMapping()}
+}
+
+// SetStatementMapping overrides how group accounts map to P&L lines.
+func (s *ProfitLossService) SetStatementMapping(mapping *StatementMapping) {
+	if mapping != nil {
+		s.statements = mapping
+	}
 }
 
 func (s *ProfitLossService) Build(ctx context.Context, filters ProfitLossFilters) (ProfitLossReport, []string, error) {
@@ -90,6 +98,7 @@ func (s *ProfitLossService) Build(ctx context.Context, filters ProfitLossFilters
 	}
 
 	lines, contributions, totals := s.processRows(rows, included, includeAll, fxResult, filters.Period, &warnings)
+	sortPLLines(lines)
 	contributionList := buildPLContributionList(contributions, totals.contributionBasis)
 
 	grossProfit := totals.totalRevenue - totals.totalCogs
@@ -150,6 +159,10 @@ func (s *ProfitLossService) processRows(
 	var totals plTotals
 
 	for _, row := range rows {
+		section := s.statements.Classify(row.AccountType, row.GroupAccountCode)
+		if !IsProfitLossLine(section) {
+			continue
+		}
 		members, err := ParseMembers(row.MembersJSON)
 		if err != nil {
 			continue
@@ -164,7 +177,6 @@ func (s *ProfitLossService) processRows(
 		convertedGroup, delta := s.applyFXConversion(row, mb, fxResult, convertedGroup, period, warnings)
 		totals.deltaFX += delta
 
-		section := classifyPLSection(row.AccountType, row.GroupAccountCode)
 		displayLocal, displayGroup := normalisePLAmounts(section, mb.localTotal, convertedGroup)
 
 		lines = append(lines, ProfitLossLine{
@@ -176,9 +188,9 @@ func (s *ProfitLossService) processRows(
 		})
 
 		switch section {
-		case "REVENUE":
+		case StatementRevenue:
 			totals.totalRevenue += displayGroup
-		case "COGS":
+		case StatementCOGS:
 			totals.totalCogs += displayGroup
 		default:
 			totals.totalOpex += displayGroup
@@ -259,20 +271,20 @@ func buildPLContributionList(contributions map[int64]ProfitLossContribution, bas
 	return list
 }
 
-func classifyPLSection(accountType, accountCode string) string {
-	switch strings.ToUpper(accountType) {
-	case "REVENUE", "INCOME":
-		return "REVENUE"
-	}
-	if strings.HasPrefix(accountCode, "5") {
-		return "COGS"
-	}
-	return "OPEX"
+// sortPLLines orders lines in statement order: revenue, COGS, then opex.
+func sortPLLines(lines []ProfitLossLine) {
+	rank := map[string]int{StatementRevenue: 0, StatementCOGS: 1, StatementOpex: 2}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if rank[lines[i].Section] != rank[lines[j].Section] {
+			return rank[lines[i].Section] < rank[lines[j].Section]
+		}
+		return lines[i].AccountCode < lines[j].AccountCode
+	})
 }
 
 func normalisePLAmounts(section string, local, group float64) (float64, float64) {
 	switch section {
-	case "REVENUE":
+	case StatementRevenue:
 		return -local, -group
 	default:
 		return local, group
@@ -167,3 +167,46 @@ func TestProfitLossServiceBuildFiltersEntities(t *testing.T) {
 		t.Fatalf("expected contribution for Beta got %s", report.Contributions[0].EntityName)
 	}
 }
+
+func TestProfitLossServiceAppliesStatementMapping(t *testing.T) {
+	row := func(code, accountType string, amount float64) ConsolBalanceByTypeQueryRow {
+		return ConsolBalanceByTypeQueryRow{
+			GroupAccountCode: code,
+			GroupAccountName: code,
+			AccountType:      accountType,
+			LocalAmount:      amount,
+			GroupAmount:      amount,
+			MembersJSON:      marshalMembers(map[string]interface{}{"company_id": 1, "company_name": "Alpha", "local_ccy_amt": amount}),
+		}
+	}
+	repo := &fakePLRepo{rows: []ConsolBalanceByTypeQueryRow{
+		row("1000", "ASSET", 900),
+		row("4000", "REVENUE", -1000),
+		row("6100", "EXPENSE", 300),
+		row("6900", "EXPENSE", 100),
+	}}
+	mapping, err := NewStatementMapping([]StatementRule{{Type: "expense", Prefix: "69", Line: "cogs"}})
+	if err != nil {
+		t.Fatalf("NewStatementMapping() error = %v", err)
+	}
+	svc := NewProfitLossService(repo)
+	svc.SetStatementMapping(mapping)
+
+	report, _, err := svc.Build(context.Background(), ProfitLossFilters{GroupID: 10, Period: "2024-01"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(report.Lines) != 3 {
+		t.Fatalf("expected balance sheet accounts to be left out, got %d lines", len(report.Lines))
+	}
+	if report.Lines[1].AccountCode != "6900" || report.Lines[1].Section != StatementCOGS {
+		t.Fatalf("expected 6900 listed as COGS after revenue, got %+v", report.Lines[1])
+	}
+	if report.Totals.COGS != 100 || report.Totals.Opex != 300 || report.Totals.NetIncome != 600 {
+		t.Fatalf("unexpected totals %+v", report.Totals)
+	}
+
+	if _, err := NewStatementMapping([]StatementRule{{Prefix: "7", Line: "OTHER"}}); err == nil {
+		t.Fatalf("expected unknown statement line to be rejected")
+	}
+}
@@ -0,0 +1,118 @@
+package consol
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Statement lines a group account rolls up to.
+const (
+	StatementRevenue   = "REVENUE"
+	StatementCOGS      = "COGS"
+	StatementOpex      = "OPEX"
+	StatementAsset     = "ASSET"
+	StatementLiability = "LIABILITY"
+	StatementEquity    = "EQUITY"
+)
+
+// StatementRule maps group accounts whose code starts with Prefix to Line.
+// An empty Type matches every account type.
+type StatementRule struct {
+	Type   string `json:"type,omitempty"`
+	Prefix string `json:"prefix"`
+	Line   string `json:"line"`
+}
+
+// StatementMapping classifies group accounts into P&L and balance sheet
+// lines. Rules are tried longest prefix first; accounts matching no rule fall
+// back to their account type.
+type StatementMapping struct {
+	rules []StatementRule
+}
+
+// DefaultStatementMapping treats expense accounts in the 5xxx range as COGS.
+func DefaultStatementMapping() *StatementMapping {
+	mapping, _ := NewStatementMapping([]StatementRule{{Type: "EXPENSE", Prefix: "5", Line: StatementCOGS}})
+	return mapping
+}
+
+// NewStatementMapping validates and orders rules.
+func NewStatementMapping(rules []StatementRule) (*StatementMapping, error) {
+	out := make([]StatementRule, 0, len(rules))
+	for _, rule := range rules {
+		rule.Type = strings.ToUpper(strings.TrimSpace(rule.Type))
+		rule.Prefix = strings.TrimSpace(rule.Prefix)
+		rule.Line = strings.ToUpper(strings.TrimSpace(rule.Line))
+		if rule.Prefix == "" {
+			return nil, fmt.Errorf("consol: statement rule for %s needs a prefix", rule.Line)
+		}
+		switch rule.Line {
+		case StatementRevenue, StatementCOGS, StatementOpex, StatementAsset, StatementLiability, StatementEquity:
+		default:
+			return nil, fmt.Errorf("consol: unknown statement line %q for prefix %s", rule.Line, rule.Prefix)
+		}
+		out = append(out, rule)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return len(out[i].Prefix) > len(out[j].Prefix)
+	})
+	return &StatementMapping{rules: out}, nil
+}
+
+// LoadStatementMapping reads rules from a JSON file holding a list of
+// StatementRule objects. An empty path yields the default mapping.
+func LoadStatementMapping(path string) (*StatementMapping, error) {
+	if strings.TrimSpace(path) == "" {
+		return DefaultStatementMapping(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read statement mapping: %w", err)
+	}
+	var rules []StatementRule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("parse statement mapping: %w", err)
+	}
+	return NewStatementMapping(rules)
+}
+
+// Classify returns the statement line for a group account, or "" when the
+// account type is unknown and no rule matches.
+func (m *StatementMapping) Classify(accountType, accountCode string) string {
+	accountType = strings.ToUpper(strings.TrimSpace(accountType))
+	if m != nil {
+		for _, rule := range m.rules {
+			if rule.Type != "" && rule.Type != accountType {
+				continue
+			}
+			if strings.HasPrefix(accountCode, rule.Prefix) {
+				return rule.Line
+			}
+		}
+	}
+	switch accountType {
+	case "REVENUE", "INCOME":
+		return StatementRevenue
+	case "EXPENSE":
+		return StatementOpex
+	case "ASSET":
+		return StatementAsset
+	case "LIABILITY":
+		return StatementLiability
+	case "EQUITY":
+		return StatementEquity
+	}
+	return ""
+}
+
+// IsProfitLossLine reports whether line belongs on the P&L.
+func IsProfitLossLine(line string) bool {
+	switch line {
+	case StatementRevenue, StatementCOGS, StatementOpex:
+		return true
+	}
+	return false
+}
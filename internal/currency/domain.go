@@ -0,0 +1,51 @@
+package currency
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCurrencyNotFound indicates the code is not in the currency list.
+	ErrCurrencyNotFound = errors.New("currency: not found")
+	// ErrRateNotFound indicates no rate is on file for the pair on or before the date.
+	ErrRateNotFound = errors.New("currency: exchange rate not found")
+	// ErrInvalidCurrency indicates a malformed currency definition.
+	ErrInvalidCurrency = errors.New("currency: invalid currency")
+	// ErrInvalidRate indicates a malformed exchange rate.
+	ErrInvalidRate = errors.New("currency: invalid exchange rate")
+	// ErrProviderDisabled is returned by FetchDaily when no provider is configured.
+	ErrProviderDisabled = errors.New("currency: rate provider not configured")
+)
+
+// Currency is one entry of the currency list.
+type Currency struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Decimals  int       `json:"decimals"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Rate converts one unit of From into To on Date.
+type Rate struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Date      time.Time `json:"date"`
+	Rate      float64   `json:"rate"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RateFilter narrows ListRates. Zero fields match everything.
+type RateFilter struct {
+	From     string
+	To       string
+	DateFrom time.Time
+	DateTo   time.Time
+	Limit    int
+}
+
+// SourceManual marks rates entered through the API.
+const SourceManual = "manual"
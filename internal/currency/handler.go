@@ -0,0 +1,153 @@
+package currency
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// Handler serves the JSON endpoints for the currency list and daily rates.
+type Handler struct {
+	logger  *slog.Logger
+	service *Service
+	rbac    rbac.Middleware
+}
+
+// NewHandler constructs the handler.
+func NewHandler(logger *slog.Logger, service *Service, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, rbac: rbac}
+}
+
+// MountRoutes registers routes. Anyone who can view the ledger can read
+// currencies and rates; maintaining them needs finance.fx.manage.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermFinanceGLView, shared.PermFinanceFXManage))
+		r.Get("/finance/currencies", h.listCurrencies)
+		r.Get("/finance/fx-rates", h.listRates)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermFinanceFXManage))
+		r.Post("/finance/currencies", h.saveCurrency)
+		r.Post("/finance/fx-rates", h.saveRates)
+	})
+}
+
+type currencyRequest struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Decimals *int   `json:"decimals"`
+	Active   *bool  `json:"active"`
+}
+
+type rateRequest struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Date string  `json:"date"`
+	Rate float64 `json:"rate"`
+}
+
+type ratesRequest struct {
+	Rates []rateRequest `json:"rates"`
+}
+
+func (h *Handler) listCurrencies(w http.ResponseWriter, r *http.Request) {
+	currencies, err := h.service.ListCurrencies(r.Context())
+	if err != nil {
+		h.fail(w, "list currencies", err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, map[string]any{"base": h.service.BaseCurrency(), "currencies": currencies})
+}
+
+func (h *Handler) saveCurrency(w http.ResponseWriter, r *http.Request) {
+	var req currencyRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid Body", "Request body must be a JSON currency.")
+		return
+	}
+	c := Currency{Code: req.Code, Name: req.Name, Decimals: 2, Active: true}
+	if req.Decimals != nil {
+		c.Decimals = *req.Decimals
+	}
+	if req.Active != nil {
+		c.Active = *req.Active
+	}
+	saved, err := h.service.SaveCurrency(r.Context(), c)
+	if err != nil {
+		h.fail(w, "save currency", err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, saved)
+}
+
+func (h *Handler) listRates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := RateFilter{From: q.Get("from"), To: q.Get("to"), Limit: 200}
+	for key, target := range map[string]*time.Time{"date_from": &filter.DateFrom, "date_to": &filter.DateTo} {
+		if v := q.Get(key); v != "" {
+			parsed, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				httpx.Problem(w, http.StatusBadRequest, "Invalid Date", key+" must be a date in YYYY-MM-DD format.")
+				return
+			}
+			*target = parsed
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > 1000 {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid Limit", "limit must be between 1 and 1000.")
+			return
+		}
+		filter.Limit = limit
+	}
+	rates, err := h.service.ListRates(r.Context(), filter)
+	if err != nil {
+		h.fail(w, "list fx rates", err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, map[string]any{"rates": rates})
+}
+
+func (h *Handler) saveRates(w http.ResponseWriter, r *http.Request) {
+	var req ratesRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid Body", `Request body must be {"rates":[{"from","to","date","rate"}]}.`)
+		return
+	}
+	rates := make([]Rate, 0, len(req.Rates))
+	for _, in := range req.Rates {
+		date, err := time.Parse("2006-01-02", in.Date)
+		if err != nil {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid Date", "Each rate needs a date in YYYY-MM-DD format.")
+			return
+		}
+		rates = append(rates, Rate{From: in.From, To: in.To, Date: date, Rate: in.Rate, Source: SourceManual})
+	}
+	if err := h.service.SaveRates(r.Context(), rates); err != nil {
+		h.fail(w, "save fx rates", err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, map[string]any{"saved": len(rates)})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCurrency), errors.Is(err, ErrInvalidRate):
+		httpx.Problem(w, http.StatusBadRequest, "Validation Failed", err.Error())
+	case errors.Is(err, ErrCurrencyNotFound):
+		httpx.Problem(w, http.StatusNotFound, "Not Found", err.Error())
+	default:
+		h.logger.Error(action, slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, "Request Failed", shared.UserSafeMessage(err))
+	}
+}
@@ -0,0 +1,78 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Provider fetches daily exchange rates from an external source.
+type Provider interface {
+	// Name is stored as the source of fetched rates.
+	Name() string
+	// Rates returns units of each quote currency per one unit of base on the date.
+	Rates(ctx context.Context, base string, quotes []string, on time.Time) (map[string]float64, error)
+}
+
+// HTTPProvider reads rates from a JSON endpoint answering
+// GET <url>?base=IDR&symbols=USD,SGD&date=2024-01-31 with {"rates":{"USD":0.000064}}.
+// Most free FX APIs accept this shape.
+type HTTPProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPProvider constructs a provider for the endpoint.
+func NewHTTPProvider(endpoint string, timeout time.Duration) *HTTPProvider {
+	return &HTTPProvider{URL: endpoint, Client: &http.Client{Timeout: timeout}}
+}
+
+// Name implements Provider.
+func (p *HTTPProvider) Name() string {
+	if u, err := url.Parse(p.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "http"
+}
+
+// Rates implements Provider.
+func (p *HTTPProvider) Rates(ctx context.Context, base string, quotes []string, on time.Time) (map[string]float64, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse provider url: %w", err)
+	}
+	q := u.Query()
+	q.Set("base", base)
+	q.Set("symbols", strings.Join(quotes, ","))
+	q.Set("date", on.Format("2006-01-02"))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider returned %s", resp.Status)
+	}
+	var body struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode provider response: %w", err)
+	}
+	return body.Rates, nil
+}
@@ -0,0 +1,153 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	currencyColumns = `code, name, decimals, active, created_at, updated_at`
+	rateColumns     = `from_currency, to_currency, rate_date, rate::float8, source, created_at`
+)
+
+// PGRepository stores currencies and rates in Postgres.
+type PGRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs a repository.
+func NewRepository(pool *pgxpool.Pool) *PGRepository {
+	return &PGRepository{pool: pool}
+}
+
+// ListCurrencies returns every currency ordered by code.
+func (r *PGRepository) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+currencyColumns+` FROM currencies ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Currency
+	for rows.Next() {
+		c, err := scanCurrency(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// GetCurrency loads one currency.
+func (r *PGRepository) GetCurrency(ctx context.Context, code string) (Currency, error) {
+	c, err := scanCurrency(r.pool.QueryRow(ctx, `SELECT `+currencyColumns+` FROM currencies WHERE code = $1`, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Currency{}, ErrCurrencyNotFound
+	}
+	return c, err
+}
+
+// UpsertCurrency inserts or updates a currency by code.
+func (r *PGRepository) UpsertCurrency(ctx context.Context, c Currency) (Currency, error) {
+	return scanCurrency(r.pool.QueryRow(ctx, `INSERT INTO currencies (code, name, decimals, active)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (code) DO UPDATE
+SET name = EXCLUDED.name, decimals = EXCLUDED.decimals, active = EXCLUDED.active, updated_at = NOW()
+RETURNING `+currencyColumns, c.Code, c.Name, c.Decimals, c.Active))
+}
+
+// ListRates returns rates matching the filter, newest first.
+func (r *PGRepository) ListRates(ctx context.Context, filter RateFilter) ([]Rate, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(clause string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	if filter.From != "" {
+		add("from_currency = $%d", filter.From)
+	}
+	if filter.To != "" {
+		add("to_currency = $%d", filter.To)
+	}
+	if !filter.DateFrom.IsZero() {
+		add("rate_date >= $%d", filter.DateFrom)
+	}
+	if !filter.DateTo.IsZero() {
+		add("rate_date <= $%d", filter.DateTo)
+	}
+	query := `SELECT ` + rateColumns + ` FROM fx_daily_rates`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY rate_date DESC, from_currency, to_currency`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Rate
+	for rows.Next() {
+		rate, err := scanRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rate)
+	}
+	return out, rows.Err()
+}
+
+// UpsertRates stores rates in one transaction.
+func (r *PGRepository) UpsertRates(ctx context.Context, rates []Rate) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	for _, rate := range rates {
+		if _, err := tx.Exec(ctx, `INSERT INTO fx_daily_rates (from_currency, to_currency, rate_date, rate, source)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (from_currency, to_currency, rate_date) DO UPDATE
+SET rate = EXCLUDED.rate, source = EXCLUDED.source, created_at = NOW()`,
+			rate.From, rate.To, rate.Date, rate.Rate, rate.Source); err != nil {
+			return fmt.Errorf("store rate %s/%s %s: %w", rate.From, rate.To, rate.Date.Format("2006-01-02"), err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// LatestRate returns the newest rate for the pair on or before the date.
+func (r *PGRepository) LatestRate(ctx context.Context, from, to string, on time.Time) (Rate, error) {
+	rate, err := scanRate(r.pool.QueryRow(ctx, `SELECT `+rateColumns+` FROM fx_daily_rates
+WHERE from_currency = $1 AND to_currency = $2 AND rate_date <= $3
+ORDER BY rate_date DESC
+LIMIT 1`, from, to, on))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Rate{}, ErrRateNotFound
+	}
+	return rate, err
+}
+
+func scanCurrency(row pgx.Row) (Currency, error) {
+	var c Currency
+	err := row.Scan(&c.Code, &c.Name, &c.Decimals, &c.Active, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+func scanRate(row pgx.Row) (Rate, error) {
+	var rate Rate
+	err := row.Scan(&rate.From, &rate.To, &rate.Date, &rate.Rate, &rate.Source, &rate.CreatedAt)
+	return rate, err
+}
@@ -0,0 +1,272 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Repository persists currencies and daily rates.
+type Repository interface {
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
+	UpsertCurrency(ctx context.Context, c Currency) (Currency, error)
+	ListRates(ctx context.Context, filter RateFilter) ([]Rate, error)
+	UpsertRates(ctx context.Context, rates []Rate) error
+	// LatestRate returns the newest rate for the pair dated on or before on.
+	LatestRate(ctx context.Context, from, to string, on time.Time) (Rate, error)
+}
+
+// Service maintains the currency list and resolves exchange rates.
+type Service struct {
+	repo     Repository
+	base     string
+	provider Provider
+}
+
+// NewService constructs the service. base is the functional currency amounts
+// are converted into for posting and reporting.
+func NewService(repo Repository, base string) *Service {
+	base = normalizeCode(base)
+	if base == "" {
+		base = "IDR"
+	}
+	return &Service{repo: repo, base: base}
+}
+
+// SetProvider enables FetchDaily.
+func (s *Service) SetProvider(provider Provider) {
+	s.provider = provider
+}
+
+// BaseCurrency returns the functional currency.
+func (s *Service) BaseCurrency() string {
+	return s.base
+}
+
+// ValidateCurrency accepts active currencies only. Blank codes are left to the
+// caller's default.
+func (s *Service) ValidateCurrency(ctx context.Context, code string) error {
+	code = normalizeCode(code)
+	if code == "" {
+		return nil
+	}
+	c, err := s.repo.GetCurrency(ctx, code)
+	if errors.Is(err, ErrCurrencyNotFound) {
+		return fmt.Errorf("%w: %s", shared.ErrInactiveCurrency, code)
+	}
+	if err != nil {
+		return fmt.Errorf("load currency %s: %w", code, err)
+	}
+	if !c.Active {
+		return fmt.Errorf("%w: %s", shared.ErrInactiveCurrency, code)
+	}
+	return nil
+}
+
+// ListCurrencies returns the whole currency list.
+func (s *Service) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	return s.repo.ListCurrencies(ctx)
+}
+
+// SaveCurrency creates or updates a currency. The base currency cannot be
+// deactivated.
+func (s *Service) SaveCurrency(ctx context.Context, c Currency) (Currency, error) {
+	c.Code = normalizeCode(c.Code)
+	c.Name = strings.TrimSpace(c.Name)
+	if !codePattern.MatchString(c.Code) {
+		return Currency{}, fmt.Errorf("%w: code must be three letters", ErrInvalidCurrency)
+	}
+	if c.Name == "" {
+		return Currency{}, fmt.Errorf("%w: name required", ErrInvalidCurrency)
+	}
+	if c.Decimals < 0 || c.Decimals > 4 {
+		return Currency{}, fmt.Errorf("%w: decimals must be between 0 and 4", ErrInvalidCurrency)
+	}
+	if c.Code == s.base && !c.Active {
+		return Currency{}, fmt.Errorf("%w: the base currency %s must stay active", ErrInvalidCurrency, s.base)
+	}
+	return s.repo.UpsertCurrency(ctx, c)
+}
+
+// ListRates returns stored rates, newest first.
+func (s *Service) ListRates(ctx context.Context, filter RateFilter) ([]Rate, error) {
+	filter.From = normalizeCode(filter.From)
+	filter.To = normalizeCode(filter.To)
+	return s.repo.ListRates(ctx, filter)
+}
+
+// SaveRates stores rates, replacing any rate already on file for the same
+// pair and date. Both currencies must be in the list.
+func (s *Service) SaveRates(ctx context.Context, rates []Rate) error {
+	if len(rates) == 0 {
+		return fmt.Errorf("%w: at least one rate required", ErrInvalidRate)
+	}
+	known := make(map[string]bool)
+	for i := range rates {
+		rate := &rates[i]
+		rate.From = normalizeCode(rate.From)
+		rate.To = normalizeCode(rate.To)
+		if rate.Source = strings.TrimSpace(rate.Source); rate.Source == "" {
+			rate.Source = SourceManual
+		}
+		switch {
+		case rate.From == "" || rate.To == "":
+			return fmt.Errorf("%w: from and to currencies required", ErrInvalidRate)
+		case rate.From == rate.To:
+			return fmt.Errorf("%w: %s cannot be quoted against itself", ErrInvalidRate, rate.From)
+		case rate.Date.IsZero():
+			return fmt.Errorf("%w: date required for %s/%s", ErrInvalidRate, rate.From, rate.To)
+		case rate.Rate <= 0 || math.IsInf(rate.Rate, 0) || math.IsNaN(rate.Rate):
+			return fmt.Errorf("%w: rate for %s/%s must be positive", ErrInvalidRate, rate.From, rate.To)
+		}
+		rate.Date = dateOnly(rate.Date)
+		for _, code := range []string{rate.From, rate.To} {
+			if known[code] {
+				continue
+			}
+			if _, err := s.repo.GetCurrency(ctx, code); err != nil {
+				if errors.Is(err, ErrCurrencyNotFound) {
+					return fmt.Errorf("%w: unknown currency %s", ErrInvalidRate, code)
+				}
+				return err
+			}
+			known[code] = true
+		}
+	}
+	return s.repo.UpsertRates(ctx, rates)
+}
+
+// Rate returns how many units of to one unit of from buys on the date. The
+// newest direct quote on or before the date wins; the inverse quote is used
+// when only the opposite pair is on file.
+func (s *Service) Rate(ctx context.Context, from, to string, on time.Time) (float64, error) {
+	from, to = normalizeCode(from), normalizeCode(to)
+	if from == "" || to == "" || from == to {
+		return 1, nil
+	}
+	on = dateOnly(on)
+	direct, err := s.repo.LatestRate(ctx, from, to, on)
+	if err == nil {
+		return direct.Rate, nil
+	}
+	if !errors.Is(err, ErrRateNotFound) {
+		return 0, err
+	}
+	inverse, err := s.repo.LatestRate(ctx, to, from, on)
+	if err != nil {
+		if errors.Is(err, ErrRateNotFound) {
+			return 0, fmt.Errorf("%w: %s/%s on %s", ErrRateNotFound, from, to, on.Format("2006-01-02"))
+		}
+		return 0, err
+	}
+	return 1 / inverse.Rate, nil
+}
+
+// Convert translates amount from one currency into another, rounded to cents.
+func (s *Service) Convert(ctx context.Context, amount float64, from, to string, on time.Time) (float64, error) {
+	rate, err := s.Rate(ctx, from, to, on)
+	if err != nil {
+		return 0, err
+	}
+	return math.Round(amount*rate*100) / 100, nil
+}
+
+// PeriodRates derives a monthly average and closing rate from the daily
+// rates of the month starting at monthStart. It lets consolidation fall back
+// to daily rates when no monthly quote was loaded.
+func (s *Service) PeriodRates(ctx context.Context, from, to string, monthStart time.Time) (float64, float64, error) {
+	from, to = normalizeCode(from), normalizeCode(to)
+	monthStart = time.Date(monthStart.Year(), monthStart.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	closing, err := s.Rate(ctx, from, to, monthEnd)
+	if err != nil {
+		return 0, 0, err
+	}
+	rates, err := s.repo.ListRates(ctx, RateFilter{From: from, To: to, DateFrom: monthStart, DateTo: monthEnd})
+	if err != nil {
+		return 0, 0, err
+	}
+	inverse := false
+	if len(rates) == 0 {
+		if rates, err = s.repo.ListRates(ctx, RateFilter{From: to, To: from, DateFrom: monthStart, DateTo: monthEnd}); err != nil {
+			return 0, 0, err
+		}
+		inverse = true
+	}
+	if len(rates) == 0 {
+		return closing, closing, nil
+	}
+	var sum float64
+	for _, rate := range rates {
+		if inverse {
+			sum += 1 / rate.Rate
+		} else {
+			sum += rate.Rate
+		}
+	}
+	return sum / float64(len(rates)), closing, nil
+}
+
+// FetchDaily pulls the day's rates against the base currency for every
+// active currency from the configured provider and stores them.
+func (s *Service) FetchDaily(ctx context.Context, on time.Time) (int, error) {
+	if s.provider == nil {
+		return 0, ErrProviderDisabled
+	}
+	on = dateOnly(on)
+	currencies, err := s.repo.ListCurrencies(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var quotes []string
+	for _, c := range currencies {
+		if c.Active && c.Code != s.base {
+			quotes = append(quotes, c.Code)
+		}
+	}
+	if len(quotes) == 0 {
+		return 0, nil
+	}
+	fetched, err := s.provider.Rates(ctx, s.base, quotes, on)
+	if err != nil {
+		return 0, fmt.Errorf("fetch rates from %s: %w", s.provider.Name(), err)
+	}
+	wanted := make(map[string]bool, len(quotes))
+	for _, code := range quotes {
+		wanted[code] = true
+	}
+	rates := make([]Rate, 0, len(fetched))
+	for code, perBase := range fetched {
+		code = normalizeCode(code)
+		if !wanted[code] || perBase <= 0 {
+			continue
+		}
+		// Providers quote units of the foreign currency per unit of base;
+		// documents convert foreign amounts into base, so store the inverse.
+		rates = append(rates, Rate{From: code, To: s.base, Date: on, Rate: 1 / perBase, Source: s.provider.Name()})
+	}
+	if len(rates) == 0 {
+		return 0, nil
+	}
+	if err := s.repo.UpsertRates(ctx, rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
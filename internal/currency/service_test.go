@@ -0,0 +1,241 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type memoryRepo struct {
+	currencies map[string]Currency
+	rates      []Rate
+}
+
+func newMemoryRepo(currencies ...Currency) *memoryRepo {
+	repo := &memoryRepo{currencies: map[string]Currency{}}
+	for _, c := range currencies {
+		repo.currencies[c.Code] = c
+	}
+	return repo
+}
+
+func (m *memoryRepo) ListCurrencies(context.Context) ([]Currency, error) {
+	out := make([]Currency, 0, len(m.currencies))
+	for _, c := range m.currencies {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out, nil
+}
+
+func (m *memoryRepo) GetCurrency(_ context.Context, code string) (Currency, error) {
+	c, ok := m.currencies[code]
+	if !ok {
+		return Currency{}, ErrCurrencyNotFound
+	}
+	return c, nil
+}
+
+func (m *memoryRepo) UpsertCurrency(_ context.Context, c Currency) (Currency, error) {
+	m.currencies[c.Code] = c
+	return c, nil
+}
+
+func (m *memoryRepo) ListRates(_ context.Context, filter RateFilter) ([]Rate, error) {
+	var out []Rate
+	for _, rate := range m.rates {
+		if (filter.From == "" || rate.From == filter.From) && (filter.To == "" || rate.To == filter.To) &&
+			(filter.DateFrom.IsZero() || !rate.Date.Before(filter.DateFrom)) &&
+			(filter.DateTo.IsZero() || !rate.Date.After(filter.DateTo)) {
+			out = append(out, rate)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryRepo) UpsertRates(_ context.Context, rates []Rate) error {
+	for _, rate := range rates {
+		replaced := false
+		for i, existing := range m.rates {
+			if existing.From == rate.From && existing.To == rate.To && existing.Date.Equal(rate.Date) {
+				m.rates[i] = rate
+				replaced = true
+			}
+		}
+		if !replaced {
+			m.rates = append(m.rates, rate)
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepo) LatestRate(_ context.Context, from, to string, on time.Time) (Rate, error) {
+	var best *Rate
+	for i, rate := range m.rates {
+		if rate.From == from && rate.To == to && !rate.Date.After(on) && (best == nil || rate.Date.After(best.Date)) {
+			best = &m.rates[i]
+		}
+	}
+	if best == nil {
+		return Rate{}, ErrRateNotFound
+	}
+	return *best, nil
+}
+
+type stubProvider struct {
+	rates map[string]float64
+	base  string
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Rates(_ context.Context, base string, _ []string, _ time.Time) (map[string]float64, error) {
+	p.base = base
+	return p.rates, nil
+}
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func seededRepo() *memoryRepo {
+	return newMemoryRepo(
+		Currency{Code: "IDR", Name: "Indonesian Rupiah", Decimals: 2, Active: true},
+		Currency{Code: "USD", Name: "US Dollar", Decimals: 2, Active: true},
+		Currency{Code: "SGD", Name: "Singapore Dollar", Decimals: 2, Active: true},
+		Currency{Code: "EUR", Name: "Euro", Decimals: 2, Active: false},
+	)
+}
+
+func TestValidateCurrencyRejectsInactiveAndUnknown(t *testing.T) {
+	svc := NewService(seededRepo(), "IDR")
+	ctx := context.Background()
+
+	if err := svc.ValidateCurrency(ctx, "usd"); err != nil {
+		t.Fatalf("active currency rejected: %v", err)
+	}
+	if err := svc.ValidateCurrency(ctx, ""); err != nil {
+		t.Fatalf("blank currency should fall back to the caller default: %v", err)
+	}
+	for _, code := range []string{"EUR", "XYZ"} {
+		if err := svc.ValidateCurrency(ctx, code); !errors.Is(err, shared.ErrInactiveCurrency) {
+			t.Fatalf("%s: expected ErrInactiveCurrency, got %v", code, err)
+		}
+	}
+}
+
+func TestSaveCurrencyKeepsBaseActive(t *testing.T) {
+	svc := NewService(seededRepo(), "IDR")
+	_, err := svc.SaveCurrency(context.Background(), Currency{Code: "IDR", Name: "Rupiah", Active: false})
+	if !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("expected ErrInvalidCurrency, got %v", err)
+	}
+	saved, err := svc.SaveCurrency(context.Background(), Currency{Code: " thb ", Name: "Thai Baht", Decimals: 2, Active: true})
+	if err != nil {
+		t.Fatalf("save currency: %v", err)
+	}
+	if saved.Code != "THB" {
+		t.Fatalf("expected normalised code THB, got %q", saved.Code)
+	}
+}
+
+func TestRateUsesLatestOnOrBeforeDateAndInverse(t *testing.T) {
+	repo := seededRepo()
+	svc := NewService(repo, "IDR")
+	ctx := context.Background()
+	if err := svc.SaveRates(ctx, []Rate{
+		{From: "USD", To: "IDR", Date: day("2024-03-01"), Rate: 15000},
+		{From: "USD", To: "IDR", Date: day("2024-03-05"), Rate: 15500},
+	}); err != nil {
+		t.Fatalf("save rates: %v", err)
+	}
+
+	got, err := svc.Convert(ctx, 10, "USD", "IDR", day("2024-03-04"))
+	if err != nil || got != 150000 {
+		t.Fatalf("expected 150000 at the 1 March rate, got %v (%v)", got, err)
+	}
+	got, err = svc.Convert(ctx, 31000, "IDR", "USD", day("2024-03-10"))
+	if err != nil || got != 2 {
+		t.Fatalf("expected inverse conversion of 2 USD, got %v (%v)", got, err)
+	}
+	if _, err := svc.Rate(ctx, "USD", "IDR", day("2024-02-28")); !errors.Is(err, ErrRateNotFound) {
+		t.Fatalf("expected ErrRateNotFound before the first rate, got %v", err)
+	}
+	if _, err := svc.Rate(ctx, "SGD", "IDR", day("2024-03-10")); !errors.Is(err, ErrRateNotFound) {
+		t.Fatalf("expected ErrRateNotFound for a pair without rates, got %v", err)
+	}
+}
+
+func TestSaveRatesValidates(t *testing.T) {
+	svc := NewService(seededRepo(), "IDR")
+	ctx := context.Background()
+	cases := []Rate{
+		{From: "USD", To: "USD", Date: day("2024-03-01"), Rate: 1},
+		{From: "USD", To: "IDR", Date: day("2024-03-01"), Rate: 0},
+		{From: "USD", To: "IDR", Rate: 15000},
+		{From: "XYZ", To: "IDR", Date: day("2024-03-01"), Rate: 2},
+	}
+	for _, rate := range cases {
+		if err := svc.SaveRates(ctx, []Rate{rate}); !errors.Is(err, ErrInvalidRate) {
+			t.Fatalf("%+v: expected ErrInvalidRate, got %v", rate, err)
+		}
+	}
+}
+
+func TestPeriodRatesAveragesMonthAndClosesAtMonthEnd(t *testing.T) {
+	repo := seededRepo()
+	svc := NewService(repo, "IDR")
+	ctx := context.Background()
+	if err := svc.SaveRates(ctx, []Rate{
+		{From: "USD", To: "IDR", Date: day("2024-01-31"), Rate: 14000},
+		{From: "USD", To: "IDR", Date: day("2024-02-01"), Rate: 15000},
+		{From: "USD", To: "IDR", Date: day("2024-02-15"), Rate: 16000},
+	}); err != nil {
+		t.Fatalf("save rates: %v", err)
+	}
+	average, closing, err := svc.PeriodRates(ctx, "USD", "IDR", day("2024-02-01"))
+	if err != nil {
+		t.Fatalf("period rates: %v", err)
+	}
+	if average != 15500 || closing != 16000 {
+		t.Fatalf("expected average 15500 and closing 16000, got %v and %v", average, closing)
+	}
+}
+
+func TestFetchDailyStoresInverseOfProviderQuotes(t *testing.T) {
+	repo := seededRepo()
+	svc := NewService(repo, "IDR")
+	ctx := context.Background()
+	if _, err := svc.FetchDaily(ctx, day("2024-03-01")); !errors.Is(err, ErrProviderDisabled) {
+		t.Fatalf("expected ErrProviderDisabled, got %v", err)
+	}
+
+	provider := &stubProvider{rates: map[string]float64{"USD": 0.0000625, "SGD": 0.0000845, "EUR": 0.00006}}
+	svc.SetProvider(provider)
+	stored, err := svc.FetchDaily(ctx, day("2024-03-01"))
+	if err != nil {
+		t.Fatalf("fetch daily: %v", err)
+	}
+	if provider.base != "IDR" {
+		t.Fatalf("expected rates requested against IDR, got %q", provider.base)
+	}
+	if stored != 2 {
+		t.Fatalf("expected rates for the two active foreign currencies, got %d", stored)
+	}
+	rate, err := svc.Rate(ctx, "USD", "IDR", day("2024-03-01"))
+	if err != nil || math.Abs(rate-16000) > 1e-6 {
+		t.Fatalf("expected USD/IDR 16000, got %v (%v)", rate, err)
+	}
+	if repo.rates[0].Source != "stub" {
+		t.Fatalf("expected provider name as source, got %q", repo.rates[0].Source)
+	}
+}
@@ -0,0 +1,77 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Key names a KPI widget in the registry and in saved layouts.
+type Key string
+
+const (
+	KeyRevenue       Key = "revenue"
+	KeyAROutstanding Key = "ar_outstanding"
+	KeyOpenOrders    Key = "open_orders"
+	KeyStockValue    Key = "stock_value"
+)
+
+// Format tells the template how to print a widget value.
+type Format string
+
+const (
+	FormatMoney Format = "money"
+	FormatCount Format = "count"
+)
+
+var (
+	// ErrUnknownWidget indicates a layout names a widget that is not registered.
+	ErrUnknownWidget = errors.New("dashboard: unknown widget")
+	// ErrWidgetNotPermitted indicates a layout names a widget the user may not view.
+	ErrWidgetNotPermitted = errors.New("dashboard: widget not permitted")
+)
+
+// Scope is what a widget loads its figure for.
+type Scope struct {
+	CompanyID int64
+	// Period is the YYYY-MM month used by period-based widgets.
+	Period string
+	AsOf   time.Time
+}
+
+// Value is the figure a widget displays. Subtitle is optional context such as
+// the period the figure covers.
+type Value struct {
+	Amount   float64
+	Subtitle string
+}
+
+// Widget is one registry entry: a KPI card, the permission required to see
+// it, and the data source behind it. Link, when set, points at the page the
+// figure drills into.
+type Widget struct {
+	Key        Key
+	Title      string
+	Permission string
+	Format     Format
+	Link       string
+	Load       func(ctx context.Context, scope Scope) (Value, error)
+}
+
+// Card is a widget resolved for display. Err is set instead of aborting the
+// dashboard when the widget's data source fails.
+type Card struct {
+	Key    Key
+	Title  string
+	Format Format
+	Link   string
+	Value  Value
+	Err    error
+}
+
+// Option is a widget offered on the layout page, with its saved state.
+type Option struct {
+	Key     Key
+	Title   string
+	Enabled bool
+}
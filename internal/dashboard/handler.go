@@ -0,0 +1,204 @@
+package dashboard
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// Handler serves the dashboard widgets and the per-user layout page.
+type Handler struct {
+	logger    *slog.Logger
+	service   *Service
+	templates *view.Engine
+	csrf      *shared.CSRFManager
+	rbac      rbac.Middleware
+	now       func() time.Time
+}
+
+// NewHandler builds Handler instance.
+func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, csrf *shared.CSRFManager, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, rbac: rbac, now: time.Now}
+}
+
+// MountRoutes registers the layout routes. Every signed-in user may arrange
+// their own dashboard; widgets are filtered by permission.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Get("/dashboard/layout", h.showLayout)
+	r.Post("/dashboard/layout", h.saveLayout)
+	r.Post("/dashboard/layout/reset", h.resetLayout)
+}
+
+// Cards loads the current user's widgets for the landing page. Failures are
+// logged and yield no cards or a failed card rather than an error page.
+func (h *Handler) Cards(r *http.Request) []Card {
+	userID := currentUser(r)
+	if userID == 0 {
+		return nil
+	}
+	now := h.now()
+	scope := Scope{
+		CompanyID: shared.ScopedCompanyID(r.Context(), 0, 1),
+		Period:    now.Format("2006-01"),
+		AsOf:      time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}
+	cards, err := h.service.Cards(r.Context(), userID, h.allowed(r), scope)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load dashboard layout", slog.Any("error", err), slog.Int64("user_id", userID))
+		return nil
+	}
+	for _, card := range cards {
+		if card.Err != nil {
+			h.logger.ErrorContext(r.Context(), "load dashboard widget", slog.Any("error", card.Err), slog.String("widget", string(card.Key)))
+		}
+	}
+	return cards
+}
+
+func (h *Handler) showLayout(w http.ResponseWriter, r *http.Request) {
+	userID := currentUser(r)
+	if userID == 0 {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+	options, err := h.service.Options(r.Context(), userID, h.allowed(r))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load dashboard layout", slog.Any("error", err), slog.Int64("user_id", userID))
+		h.render(w, r, map[string]any{"Error": shared.UserSafeMessage(err)}, http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, map[string]any{"Options": options}, http.StatusOK)
+}
+
+func (h *Handler) saveLayout(w http.ResponseWriter, r *http.Request) {
+	userID := currentUser(r)
+	if userID == 0 {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	keys := layoutFromForm(r)
+	if err := h.service.SaveLayout(r.Context(), userID, keys, h.allowed(r)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrUnknownWidget) || errors.Is(err, ErrWidgetNotPermitted) {
+			status = http.StatusBadRequest
+		} else {
+			h.logger.ErrorContext(r.Context(), "save dashboard layout", slog.Any("error", err), slog.Int64("user_id", userID))
+		}
+		options, _ := h.service.Options(r.Context(), userID, h.allowed(r))
+		h.render(w, r, map[string]any{"Options": options, "Error": errorMessage(err)}, status)
+		return
+	}
+	h.redirectWithFlash(w, r, "Dashboard layout saved")
+}
+
+func (h *Handler) resetLayout(w http.ResponseWriter, r *http.Request) {
+	userID := currentUser(r)
+	if userID == 0 {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+	if err := h.service.ResetLayout(r.Context(), userID); err != nil {
+		h.logger.ErrorContext(r.Context(), "reset dashboard layout", slog.Any("error", err), slog.Int64("user_id", userID))
+		h.render(w, r, map[string]any{"Error": shared.UserSafeMessage(err)}, http.StatusInternalServerError)
+		return
+	}
+	h.redirectWithFlash(w, r, "Dashboard layout reset to default")
+}
+
+// layoutFromForm reads the checked widgets, ordered by their position inputs.
+// Widgets without a valid position keep their form order after the rest.
+func layoutFromForm(r *http.Request) []Key {
+	type entry struct {
+		key Key
+		pos int
+	}
+	var entries []entry
+	for _, raw := range r.PostForm["widgets"] {
+		key := Key(strings.TrimSpace(raw))
+		if key == "" {
+			continue
+		}
+		pos, err := strconv.Atoi(strings.TrimSpace(r.PostFormValue("position_" + string(key))))
+		if err != nil || pos <= 0 {
+			pos = math.MaxInt
+		}
+		entries = append(entries, entry{key: key, pos: pos})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].pos < entries[j].pos })
+	keys := make([]Key, len(entries))
+	for i, e := range entries {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+// allowed memoizes permission checks for the request so each widget does not
+// trigger its own lookup.
+func (h *Handler) allowed(r *http.Request) Allowed {
+	cache := make(map[string]bool)
+	return func(permission string) bool {
+		ok, seen := cache[permission]
+		if !seen {
+			ok = h.rbac.Allows(r, permission)
+			cache[permission] = ok
+		}
+		return ok
+	}
+}
+
+func errorMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownWidget):
+		return "One of the selected widgets does not exist."
+	case errors.Is(err, ErrWidgetNotPermitted):
+		return "You do not have access to one of the selected widgets."
+	default:
+		return shared.UserSafeMessage(err)
+	}
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, data map[string]any, status int) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{Title: "Dashboard Layout", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, "pages/dashboard/layout.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) redirectWithFlash(w http.ResponseWriter, r *http.Request, message string) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: "success", Message: message})
+	}
+	http.Redirect(w, r, "/dashboard/layout", http.StatusSeeOther)
+}
+
+func currentUser(r *http.Request) int64 {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(sess.User(), 10, 64)
+	return id
+}
@@ -0,0 +1,57 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository stores per-user dashboard layouts in PostgreSQL.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs a Repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Layout returns the user's saved widget keys in display order. The boolean
+// is false when the user never saved a layout.
+func (r *Repository) Layout(ctx context.Context, userID int64) ([]Key, bool, error) {
+	var widgets []string
+	err := r.pool.QueryRow(ctx, `SELECT widgets FROM dashboard_layouts WHERE user_id = $1`, userID).Scan(&widgets)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	keys := make([]Key, len(widgets))
+	for i, w := range widgets {
+		keys[i] = Key(w)
+	}
+	return keys, true, nil
+}
+
+// SaveLayout replaces the user's layout.
+func (r *Repository) SaveLayout(ctx context.Context, userID int64, keys []Key) error {
+	widgets := make([]string, len(keys))
+	for i, k := range keys {
+		widgets[i] = string(k)
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO dashboard_layouts (user_id, widgets, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET widgets = EXCLUDED.widgets, updated_at = NOW()
+	`, userID, widgets)
+	return err
+}
+
+// DeleteLayout drops the user's layout so the default applies again.
+func (r *Repository) DeleteLayout(ctx context.Context, userID int64) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM dashboard_layouts WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,135 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Store persists per-user layouts.
+type Store interface {
+	Layout(ctx context.Context, userID int64) ([]Key, bool, error)
+	SaveLayout(ctx context.Context, userID int64, keys []Key) error
+	DeleteLayout(ctx context.Context, userID int64) error
+}
+
+// Allowed reports whether the current user holds a permission.
+type Allowed func(permission string) bool
+
+// Service resolves layouts against the widget registry.
+type Service struct {
+	store   Store
+	widgets []Widget
+	byKey   map[Key]Widget
+}
+
+// NewService constructs the service with the widgets it can show. The order
+// of widgets is the default layout.
+func NewService(store Store, widgets ...Widget) *Service {
+	s := &Service{store: store, widgets: widgets, byKey: make(map[Key]Widget, len(widgets))}
+	for _, w := range widgets {
+		s.byKey[w.Key] = w
+	}
+	return s
+}
+
+// Widget returns the registered widget for key.
+func (s *Service) Widget(key Key) (Widget, bool) {
+	w, ok := s.byKey[Key(strings.TrimSpace(string(key)))]
+	return w, ok
+}
+
+// Options lists every widget the user may view for the layout page: enabled
+// widgets first in their saved order, then the rest in registry order.
+func (s *Service) Options(ctx context.Context, userID int64, allowed Allowed) ([]Option, error) {
+	layout, err := s.layout(ctx, userID, allowed)
+	if err != nil {
+		return nil, err
+	}
+	enabled := make(map[Key]bool, len(layout))
+	out := make([]Option, 0, len(s.widgets))
+	for _, w := range layout {
+		enabled[w.Key] = true
+		out = append(out, Option{Key: w.Key, Title: w.Title, Enabled: true})
+	}
+	for _, w := range s.widgets {
+		if enabled[w.Key] || !permitted(w, allowed) {
+			continue
+		}
+		out = append(out, Option{Key: w.Key, Title: w.Title})
+	}
+	return out, nil
+}
+
+// SaveLayout stores keys as the user's layout. Unknown and duplicate keys are
+// rejected, as are widgets the user may not view.
+func (s *Service) SaveLayout(ctx context.Context, userID int64, keys []Key, allowed Allowed) error {
+	seen := make(map[Key]bool, len(keys))
+	layout := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		w, ok := s.Widget(key)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownWidget, key)
+		}
+		if !permitted(w, allowed) {
+			return fmt.Errorf("%w: %s", ErrWidgetNotPermitted, w.Key)
+		}
+		if seen[w.Key] {
+			continue
+		}
+		seen[w.Key] = true
+		layout = append(layout, w.Key)
+	}
+	return s.store.SaveLayout(ctx, userID, layout)
+}
+
+// ResetLayout drops the user's layout so every permitted widget shows again.
+func (s *Service) ResetLayout(ctx context.Context, userID int64) error {
+	return s.store.DeleteLayout(ctx, userID)
+}
+
+// Cards loads the user's enabled widgets for scope. A widget whose data
+// source fails is returned with Err set so the rest of the dashboard still
+// renders.
+func (s *Service) Cards(ctx context.Context, userID int64, allowed Allowed, scope Scope) ([]Card, error) {
+	layout, err := s.layout(ctx, userID, allowed)
+	if err != nil {
+		return nil, err
+	}
+	cards := make([]Card, 0, len(layout))
+	for _, w := range layout {
+		card := Card{Key: w.Key, Title: w.Title, Format: w.Format, Link: w.Link}
+		card.Value, card.Err = w.Load(ctx, scope)
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// layout returns the user's saved widgets, or every widget when nothing is
+// saved, keeping only those still registered and permitted.
+func (s *Service) layout(ctx context.Context, userID int64, allowed Allowed) ([]Widget, error) {
+	keys, saved, err := s.store.Layout(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := s.widgets
+	if saved {
+		candidates = make([]Widget, 0, len(keys))
+		for _, key := range keys {
+			if w, ok := s.byKey[key]; ok {
+				candidates = append(candidates, w)
+			}
+		}
+	}
+	out := make([]Widget, 0, len(candidates))
+	for _, w := range candidates {
+		if permitted(w, allowed) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func permitted(w Widget, allowed Allowed) bool {
+	return w.Permission == "" || (allowed != nil && allowed(w.Permission))
+}
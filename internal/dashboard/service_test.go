@@ -0,0 +1,129 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/analytics"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/orders"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type memoryStore struct {
+	layouts map[int64][]Key
+}
+
+func (m *memoryStore) Layout(_ context.Context, userID int64) ([]Key, bool, error) {
+	keys, ok := m.layouts[userID]
+	return keys, ok, nil
+}
+
+func (m *memoryStore) SaveLayout(_ context.Context, userID int64, keys []Key) error {
+	if m.layouts == nil {
+		m.layouts = map[int64][]Key{}
+	}
+	m.layouts[userID] = keys
+	return nil
+}
+
+func (m *memoryStore) DeleteLayout(_ context.Context, userID int64) error {
+	delete(m.layouts, userID)
+	return nil
+}
+
+type stubKPI struct{ summary analytics.KPISummary }
+
+func (s stubKPI) GetKPISummary(context.Context, analytics.KPIFilter) (analytics.KPISummary, error) {
+	return s.summary, nil
+}
+
+type stubOrders map[orders.SalesOrderStatus]int
+
+func (s stubOrders) List(_ context.Context, req orders.ListSalesOrdersRequest) ([]orders.SalesOrderWithDetails, int, error) {
+	return nil, s[*req.Status], nil
+}
+
+func grant(perms ...string) Allowed {
+	return func(permission string) bool {
+		for _, p := range perms {
+			if p == permission {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func testService(store *memoryStore) *Service {
+	failing := Widget{Key: KeyStockValue, Title: "Stock Value", Permission: "inventory.view", Format: FormatMoney,
+		Load: func(context.Context, Scope) (Value, error) { return Value{}, errors.New("valuation down") }}
+	return NewService(store,
+		RevenueWidget(stubKPI{summary: analytics.KPISummary{Revenue: 1500, AROutstanding: 400}}),
+		AROutstandingWidget(stubKPI{summary: analytics.KPISummary{Revenue: 1500, AROutstanding: 400}}),
+		OpenOrdersWidget(stubOrders{orders.SalesOrderStatusConfirmed: 3, orders.SalesOrderStatusProcessing: 2}),
+		failing,
+	)
+}
+
+func keys(cards []Card) []Key {
+	out := make([]Key, len(cards))
+	for i, c := range cards {
+		out[i] = c.Key
+	}
+	return out
+}
+
+func TestCardsDefaultToPermittedWidgetsInRegistryOrder(t *testing.T) {
+	svc := testService(&memoryStore{})
+	scope := Scope{CompanyID: 1, Period: "2026-10", AsOf: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)}
+
+	cards, err := svc.Cards(context.Background(), 7, grant(shared.PermFinanceAnalyticsView, shared.PermSalesOrderView, "inventory.view"), scope)
+	require.NoError(t, err)
+	require.Equal(t, []Key{KeyRevenue, KeyOpenOrders, KeyStockValue}, keys(cards))
+	require.Equal(t, Value{Amount: 1500, Subtitle: "Period 2026-10"}, cards[0].Value)
+	require.Equal(t, 5.0, cards[1].Value.Amount, "confirmed and processing orders are open")
+	require.Error(t, cards[2].Err, "a failing widget does not fail the dashboard")
+
+	cards, err = svc.Cards(context.Background(), 7, grant(), scope)
+	require.NoError(t, err)
+	require.Empty(t, cards)
+}
+
+func TestSaveLayoutOrdersWidgetsAndHidesRevokedOnes(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	svc := testService(store)
+	all := grant(shared.PermFinanceAnalyticsView, shared.PermFinanceARView, shared.PermSalesOrderView)
+
+	require.NoError(t, svc.SaveLayout(ctx, 7, []Key{KeyOpenOrders, " revenue ", KeyOpenOrders}, all))
+	require.Equal(t, []Key{KeyOpenOrders, KeyRevenue}, store.layouts[7])
+
+	options, err := svc.Options(ctx, 7, all)
+	require.NoError(t, err)
+	require.Equal(t, []Option{
+		{Key: KeyOpenOrders, Title: "Open Sales Orders", Enabled: true},
+		{Key: KeyRevenue, Title: "Revenue", Enabled: true},
+		{Key: KeyAROutstanding, Title: "AR Outstanding"},
+	}, options)
+
+	cards, err := svc.Cards(ctx, 7, grant(shared.PermFinanceAnalyticsView), Scope{})
+	require.NoError(t, err)
+	require.Equal(t, []Key{KeyRevenue}, keys(cards), "widgets lose visibility with the permission")
+
+	require.ErrorIs(t, svc.SaveLayout(ctx, 7, []Key{"profit"}, all), ErrUnknownWidget)
+	require.ErrorIs(t, svc.SaveLayout(ctx, 7, []Key{KeyStockValue}, all), ErrWidgetNotPermitted)
+
+	require.NoError(t, svc.SaveLayout(ctx, 7, nil, all))
+	cards, err = svc.Cards(ctx, 7, all, Scope{})
+	require.NoError(t, err)
+	require.Empty(t, cards, "an empty saved layout hides every widget")
+
+	require.NoError(t, svc.ResetLayout(ctx, 7))
+	cards, err = svc.Cards(ctx, 7, all, Scope{})
+	require.NoError(t, err)
+	require.Equal(t, []Key{KeyRevenue, KeyAROutstanding, KeyOpenOrders}, keys(cards))
+}
@@ -0,0 +1,119 @@
+package dashboard
+
+import (
+	"context"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/analytics"
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/orders"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// KPISource serves the cached finance KPI summary.
+type KPISource interface {
+	GetKPISummary(ctx context.Context, filter analytics.KPIFilter) (analytics.KPISummary, error)
+}
+
+// OrderSource lists sales orders; only the total count is used.
+type OrderSource interface {
+	List(ctx context.Context, req orders.ListSalesOrdersRequest) ([]orders.SalesOrderWithDetails, int, error)
+}
+
+// ValuationSource computes the as-of inventory valuation.
+type ValuationSource interface {
+	ValuationAsOf(ctx context.Context, filter inventory.ValuationFilter) (inventory.ValuationReport, error)
+}
+
+// openOrderStatuses are the sales order states still awaiting fulfilment.
+var openOrderStatuses = []orders.SalesOrderStatus{orders.SalesOrderStatusConfirmed, orders.SalesOrderStatusProcessing}
+
+// DefaultWidgets returns the KPI widgets offered on the landing dashboard, in
+// their default order.
+func DefaultWidgets(kpi KPISource, salesOrders OrderSource, valuation ValuationSource) []Widget {
+	return []Widget{
+		RevenueWidget(kpi),
+		AROutstandingWidget(kpi),
+		OpenOrdersWidget(salesOrders),
+		StockValueWidget(valuation),
+	}
+}
+
+// RevenueWidget shows the month-to-date revenue from the analytics KPI summary.
+func RevenueWidget(source KPISource) Widget {
+	return Widget{
+		Key:        KeyRevenue,
+		Title:      "Revenue",
+		Permission: shared.PermFinanceAnalyticsView,
+		Format:     FormatMoney,
+		Link:       "/analytics",
+		Load: func(ctx context.Context, scope Scope) (Value, error) {
+			summary, err := source.GetKPISummary(ctx, kpiFilter(scope))
+			if err != nil {
+				return Value{}, err
+			}
+			return Value{Amount: summary.Revenue, Subtitle: "Period " + scope.Period}, nil
+		},
+	}
+}
+
+// AROutstandingWidget shows open receivables from the analytics KPI summary.
+func AROutstandingWidget(source KPISource) Widget {
+	return Widget{
+		Key:        KeyAROutstanding,
+		Title:      "AR Outstanding",
+		Permission: shared.PermFinanceARView,
+		Format:     FormatMoney,
+		Link:       "/finance/ar/aging",
+		Load: func(ctx context.Context, scope Scope) (Value, error) {
+			summary, err := source.GetKPISummary(ctx, kpiFilter(scope))
+			if err != nil {
+				return Value{}, err
+			}
+			return Value{Amount: summary.AROutstanding, Subtitle: "As of " + scope.AsOf.Format("2006-01-02")}, nil
+		},
+	}
+}
+
+// OpenOrdersWidget counts confirmed and in-process sales orders.
+func OpenOrdersWidget(source OrderSource) Widget {
+	return Widget{
+		Key:        KeyOpenOrders,
+		Title:      "Open Sales Orders",
+		Permission: shared.PermSalesOrderView,
+		Format:     FormatCount,
+		Link:       "/sales/orders",
+		Load: func(ctx context.Context, scope Scope) (Value, error) {
+			var count int
+			for _, status := range openOrderStatuses {
+				_, total, err := source.List(ctx, orders.ListSalesOrdersRequest{CompanyID: scope.CompanyID, Status: &status})
+				if err != nil {
+					return Value{}, err
+				}
+				count += total
+			}
+			return Value{Amount: float64(count), Subtitle: "Confirmed or processing"}, nil
+		},
+	}
+}
+
+// StockValueWidget shows the total inventory value from the valuation report.
+func StockValueWidget(source ValuationSource) Widget {
+	return Widget{
+		Key:        KeyStockValue,
+		Title:      "Stock Value",
+		Permission: "inventory.view",
+		Format:     FormatMoney,
+		Link:       "/inventory/valuation",
+		Load: func(ctx context.Context, scope Scope) (Value, error) {
+			report, err := source.ValuationAsOf(ctx, inventory.ValuationFilter{AsOf: scope.AsOf, CompanyID: scope.CompanyID})
+			if err != nil {
+				return Value{}, err
+			}
+			return Value{Amount: report.TotalValue, Subtitle: "As of " + scope.AsOf.Format("2006-01-02")}, nil
+		},
+	}
+}
+
+func kpiFilter(scope Scope) analytics.KPIFilter {
+	return analytics.KPIFilter{Period: scope.Period, CompanyID: scope.CompanyID, AsOf: scope.AsOf}
+}
@@ -0,0 +1,68 @@
+// Package dataexport uploads incremental table extracts to S3-compatible
+// object storage for the data lake.
+package dataexport
+
+import (
+	"errors"
+	"time"
+)
+
+// FormatCSV is the only extract format available.
+const FormatCSV = "csv"
+
+// CommitLag holds back the newest rows from a run. A transaction that is still
+// open when the run starts stamps updated_at before it commits, so rows newer
+// than the cutoff minus the lag are left for the next run.
+const CommitLag = 5 * time.Minute
+
+// ErrUnsupportedFormat is returned for formats other than FormatCSV.
+var ErrUnsupportedFormat = errors.New("dataexport: only csv extracts are supported")
+
+// Table is an exportable table. Rows are picked up by their updated_at column.
+type Table struct {
+	Name    string
+	OrderBy string
+}
+
+// Tables lists the exportable tables in export order.
+var Tables = []Table{
+	{Name: "journal_entries", OrderBy: "id"},
+	{Name: "journal_lines", OrderBy: "id"},
+	{Name: "ar_invoices", OrderBy: "id"},
+	{Name: "ar_payments", OrderBy: "id"},
+	{Name: "ap_invoices", OrderBy: "id"},
+	{Name: "ap_payments", OrderBy: "id"},
+	{Name: "inventory_balances", OrderBy: "warehouse_id, product_id"},
+}
+
+// LookupTable finds an exportable table by name.
+func LookupTable(name string) (Table, bool) {
+	for _, t := range Tables {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Table{}, false
+}
+
+// ManifestFile describes one table extract in a run.
+type ManifestFile struct {
+	Table string `json:"table"`
+	// Key is empty when no rows changed since the previous run.
+	Key    string `json:"key,omitempty"`
+	Rows   int64  `json:"rows"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256,omitempty"`
+	// Full marks the first export of a table, which holds every row.
+	Full           bool      `json:"full"`
+	UpdatedAfter   time.Time `json:"updated_after"`
+	UpdatedThrough time.Time `json:"updated_through"`
+}
+
+// Manifest is written last in every run and lists its extracts.
+type Manifest struct {
+	RunID       string         `json:"run_id"`
+	Format      string         `json:"format"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Files       []ManifestFile `json:"files"`
+}
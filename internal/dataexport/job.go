@@ -0,0 +1,43 @@
+package dataexport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+)
+
+// Job runs the scheduled export on the worker.
+type Job struct {
+	service *Service
+	logger  *slog.Logger
+}
+
+// NewJob constructs a Job handler.
+func NewJob(service *Service, logger *slog.Logger) *Job {
+	return &Job{service: service, logger: logger}
+}
+
+// Handle fulfils the asynq.HandlerFunc contract for jobs.TaskDataExport.
+// Returning the error lets asynq retry the whole run.
+func (j *Job) Handle(ctx context.Context, _ *asynq.Task) error {
+	if j == nil || j.service == nil {
+		return fmt.Errorf("data export job not configured")
+	}
+	manifest, err := j.service.Run(ctx)
+	if err != nil {
+		if j.logger != nil {
+			j.logger.ErrorContext(ctx, "data export failed", slog.Any("error", err))
+		}
+		return err
+	}
+	if j.logger != nil {
+		var rows int64
+		for _, f := range manifest.Files {
+			rows += f.Rows
+		}
+		j.logger.InfoContext(ctx, "data export uploaded", slog.String("run_id", manifest.RunID), slog.Int("tables", len(manifest.Files)), slog.Int64("rows", rows))
+	}
+	return nil
+}
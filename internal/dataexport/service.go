@@ -0,0 +1,211 @@
+package dataexport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Source reads extracts and tracks how far each table has been exported.
+type Source interface {
+	Now(ctx context.Context) (time.Time, error)
+	Watermarks(ctx context.Context) (map[string]time.Time, error)
+	WriteRows(ctx context.Context, table Table, after, through time.Time, w io.Writer) (int64, error)
+	SaveWatermarks(ctx context.Context, runID string, through time.Time, tables []string) error
+}
+
+// Store uploads objects to the data lake bucket.
+type Store interface {
+	PutObject(ctx context.Context, key string, body io.Reader, size int64, payloadSHA256, contentType string) error
+}
+
+// Config controls what a run exports and where it goes.
+type Config struct {
+	// Prefix is prepended to every object key.
+	Prefix string
+	// Tables to export; empty exports every entry in Tables.
+	Tables []string
+	Format string
+	// Attempts is how often each upload is tried before the run fails.
+	Attempts int
+	// Backoff is the wait after the first failed upload; it grows linearly.
+	Backoff time.Duration
+	// TempDir holds extracts while they upload; empty uses os.TempDir.
+	TempDir string
+}
+
+// Service runs incremental exports.
+type Service struct {
+	source Source
+	store  Store
+	cfg    Config
+	tables []Table
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewService validates cfg and constructs the service.
+func NewService(source Source, store Store, cfg Config) (*Service, error) {
+	if source == nil || store == nil {
+		return nil, fmt.Errorf("dataexport: source and store are required")
+	}
+	cfg.Format = strings.ToLower(strings.TrimSpace(cfg.Format))
+	if cfg.Format == "" {
+		cfg.Format = FormatCSV
+	}
+	if cfg.Format != FormatCSV {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, cfg.Format)
+	}
+	if cfg.Attempts <= 0 {
+		cfg.Attempts = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 5 * time.Second
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	tables := Tables
+	if len(cfg.Tables) > 0 {
+		tables = make([]Table, 0, len(cfg.Tables))
+		for _, name := range cfg.Tables {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			table, ok := LookupTable(name)
+			if !ok {
+				return nil, fmt.Errorf("dataexport: table %q cannot be exported", name)
+			}
+			tables = append(tables, table)
+		}
+	}
+	return &Service{source: source, store: store, cfg: cfg, tables: tables, sleep: sleepContext}, nil
+}
+
+// Run exports every configured table's rows changed since its watermark,
+// uploads a manifest and then advances the watermarks. A failed run leaves
+// the watermarks untouched, so the next run exports the same rows again.
+func (s *Service) Run(ctx context.Context) (Manifest, error) {
+	now, err := s.source.Now(ctx)
+	if err != nil {
+		return Manifest{}, err
+	}
+	through := now.UTC().Add(-CommitLag).Truncate(time.Second)
+	watermarks, err := s.source.Watermarks(ctx)
+	if err != nil {
+		return Manifest{}, err
+	}
+	manifest := Manifest{
+		RunID:       through.Format("20060102T150405Z"),
+		Format:      s.cfg.Format,
+		GeneratedAt: now.UTC(),
+	}
+	names := make([]string, 0, len(s.tables))
+	for _, table := range s.tables {
+		after, seen := watermarks[table.Name]
+		if seen && !after.Before(through) {
+			continue
+		}
+		file, err := s.exportTable(ctx, manifest.RunID, table, after, through)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("dataexport: %s: %w", table.Name, err)
+		}
+		file.Full = !seen
+		manifest.Files = append(manifest.Files, file)
+		names = append(names, table.Name)
+	}
+	if len(names) == 0 {
+		// Every table is already exported through the cutoff.
+		return manifest, nil
+	}
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+	sum := sha256.Sum256(body)
+	if err := s.upload(ctx, s.key(manifest.RunID, "manifest.json"), bytes.NewReader(body), int64(len(body)), hex.EncodeToString(sum[:]), "application/json"); err != nil {
+		return Manifest{}, err
+	}
+	if err := s.source.SaveWatermarks(ctx, manifest.RunID, through, names); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+// exportTable writes the extract to a temporary file, hashing it on the way,
+// and uploads it when it holds any rows.
+func (s *Service) exportTable(ctx context.Context, runID string, table Table, after, through time.Time) (ManifestFile, error) {
+	file := ManifestFile{Table: table.Name, UpdatedAfter: after, UpdatedThrough: through}
+	tmp, err := os.CreateTemp(s.cfg.TempDir, "dataexport-*."+s.cfg.Format)
+	if err != nil {
+		return file, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	buf := bufio.NewWriter(io.MultiWriter(tmp, hash))
+	rows, err := s.source.WriteRows(ctx, table, after, through, buf)
+	if err != nil {
+		return file, err
+	}
+	if err := buf.Flush(); err != nil {
+		return file, err
+	}
+	file.Rows = rows
+	if rows == 0 {
+		return file, nil
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		return file, err
+	}
+	file.Key = s.key(runID, table.Name+"."+s.cfg.Format)
+	file.Bytes = info.Size()
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if err := s.upload(ctx, file.Key, tmp, file.Bytes, file.SHA256, "text/csv"); err != nil {
+		return file, err
+	}
+	return file, nil
+}
+
+// upload puts body, retrying with a growing pause until cfg.Attempts is used up.
+func (s *Service) upload(ctx context.Context, key string, body io.ReadSeeker, size int64, sum, contentType string) error {
+	var err error
+	for attempt := 1; attempt <= s.cfg.Attempts; attempt++ {
+		if _, err = body.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err = s.store.PutObject(ctx, key, body, size, sum, contentType); err == nil {
+			return nil
+		}
+		if attempt < s.cfg.Attempts {
+			if serr := s.sleep(ctx, time.Duration(attempt)*s.cfg.Backoff); serr != nil {
+				return serr
+			}
+		}
+	}
+	return fmt.Errorf("upload %s failed after %d attempts: %w", key, s.cfg.Attempts, err)
+}
+
+func (s *Service) key(runID, name string) string {
+	return path.Join(s.cfg.Prefix, runID, name)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+package dataexport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	now        time.Time
+	watermarks map[string]time.Time
+	rows       map[string]int64
+	asked      map[string]time.Time
+	saved      []string
+	savedAt    time.Time
+}
+
+func (f *fakeSource) Now(context.Context) (time.Time, error) { return f.now, nil }
+
+func (f *fakeSource) Watermarks(context.Context) (map[string]time.Time, error) {
+	return f.watermarks, nil
+}
+
+func (f *fakeSource) WriteRows(_ context.Context, table Table, after, _ time.Time, w io.Writer) (int64, error) {
+	if f.asked == nil {
+		f.asked = make(map[string]time.Time)
+	}
+	f.asked[table.Name] = after
+	fmt.Fprintln(w, "id")
+	for i := int64(1); i <= f.rows[table.Name]; i++ {
+		fmt.Fprintln(w, i)
+	}
+	return f.rows[table.Name], nil
+}
+
+func (f *fakeSource) SaveWatermarks(_ context.Context, _ string, through time.Time, tables []string) error {
+	f.saved = tables
+	f.savedAt = through
+	return nil
+}
+
+type flakyStore struct {
+	failures map[string]int
+	objects  map[string][]byte
+	attempts int
+}
+
+func (s *flakyStore) PutObject(_ context.Context, key string, body io.Reader, size int64, _, _ string) error {
+	s.attempts++
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return fmt.Errorf("size mismatch for %s", key)
+	}
+	if s.failures[key] > 0 {
+		s.failures[key]--
+		return errors.New("503 slow down")
+	}
+	if s.objects == nil {
+		s.objects = make(map[string][]byte)
+	}
+	s.objects[key] = data
+	return nil
+}
+
+func noSleep(context.Context, time.Duration) error { return nil }
+
+func TestRunExportsChangedRowsAndRetriesUploads(t *testing.T) {
+	last := time.Date(2026, 10, 15, 1, 55, 0, 0, time.UTC)
+	source := &fakeSource{
+		now:        time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC),
+		watermarks: map[string]time.Time{"journal_entries": last, "journal_lines": last},
+		rows:       map[string]int64{"journal_entries": 2, "ar_invoices": 1},
+	}
+	store := &flakyStore{failures: map[string]int{"lake/20261016T015500Z/journal_entries.csv": 1}}
+	svc, err := NewService(source, store, Config{Prefix: "/lake/", Tables: []string{"journal_entries", "journal_lines", "ar_invoices"}, TempDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	svc.sleep = noSleep
+
+	manifest, err := svc.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !source.asked["journal_entries"].Equal(last) || !source.asked["ar_invoices"].IsZero() {
+		t.Fatalf("expected incremental and full extracts, got %v", source.asked)
+	}
+	if len(manifest.Files) != 3 || manifest.Files[1].Key != "" || !manifest.Files[2].Full {
+		t.Fatalf("unexpected manifest files %+v", manifest.Files)
+	}
+	if string(store.objects["lake/20261016T015500Z/journal_entries.csv"]) != "id\n1\n2\n" {
+		t.Fatalf("journal entries extract not uploaded after retry")
+	}
+	var uploaded Manifest
+	if err := json.Unmarshal(store.objects["lake/20261016T015500Z/manifest.json"], &uploaded); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if uploaded.RunID != "20261016T015500Z" || uploaded.Files[0].Rows != 2 || uploaded.Files[0].SHA256 == "" {
+		t.Fatalf("unexpected uploaded manifest %+v", uploaded)
+	}
+	if len(source.saved) != 3 || !source.savedAt.Equal(source.now.Add(-CommitLag)) {
+		t.Fatalf("expected all three watermarks advanced to the cutoff, got %v at %v", source.saved, source.savedAt)
+	}
+}
+
+func TestRunKeepsWatermarksWhenUploadKeepsFailing(t *testing.T) {
+	source := &fakeSource{now: time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), rows: map[string]int64{"ap_invoices": 1}}
+	store := &flakyStore{failures: map[string]int{"20261016T015500Z/ap_invoices.csv": 5}}
+	svc, err := NewService(source, store, Config{Tables: []string{"ap_invoices"}, Attempts: 3, TempDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	svc.sleep = noSleep
+
+	if _, err := svc.Run(context.Background()); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	if store.attempts != 3 || source.saved != nil {
+		t.Fatalf("expected 3 attempts and no watermark update, got %d / %v", store.attempts, source.saved)
+	}
+	if _, ok := store.objects["20261016T015500Z/manifest.json"]; ok {
+		t.Fatal("manifest must not be written for a failed run")
+	}
+}
+
+func TestNewServiceRejectsUnknownTablesAndFormats(t *testing.T) {
+	if _, err := NewService(&fakeSource{}, &flakyStore{}, Config{Tables: []string{"users"}}); err == nil {
+		t.Fatal("expected users to be rejected")
+	}
+	if _, err := NewService(&fakeSource{}, &flakyStore{}, Config{Format: "parquet"}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
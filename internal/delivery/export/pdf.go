@@ -11,12 +11,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/i18n"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 	"github.com/odyssey-erp/odyssey-erp/web"
 )
 
 // PackingListPayload aggregates delivery order data for PDF rendering.
 type PackingListPayload struct {
+	// Locale renders the labels, numbers and dates in the customer's
+	// language; the zero value uses the default language.
+	Locale i18n.Locale
+
 	// Header information
 	DocNumber        string
 	SalesOrderNumber string
@@ -36,10 +41,12 @@ type PackingListPayload struct {
 	Lines []PackingListLine
 
 	// Footer information
-	ReceivedBy    *string
-	DeliveryNotes *string
-	CreatedBy     string
-	CreatedAt     time.Time
+	ReceivedBy        *string
+	ReceivedAt        *time.Time
+	ReceiverSignature *string // PNG or JPEG data URL
+	DeliveryNotes     *string
+	CreatedBy         string
+	CreatedAt         time.Time
 }
 
 // PackingListLine represents a single line item in the packing list.
@@ -65,43 +72,15 @@ type PDFExporter struct {
 // NewPDFExporter creates a PDFExporter with parsed templates.
 func NewPDFExporter(endpoint string, client *http.Client) (*PDFExporter, error) {
 	funcMap := template.FuncMap{
-		"formatDate": func(t time.Time) string {
-			if t.IsZero() {
-				return ""
-			}
-			return t.Format("January 2, 2006")
-		},
-		"formatDatePtr": func(t *time.Time) string {
-			if t == nil || t.IsZero() {
-				return ""
-			}
-			return t.Format("January 2, 2006")
-		},
-		"formatDateTime": func(t time.Time) string {
-			if t.IsZero() {
-				return ""
-			}
-			return t.Format("Jan 2, 2006 15:04")
-		},
-		"formatQty": func(qty float64) string {
-			s := fmt.Sprintf("%.4f", qty)
-			s = strings.TrimRight(s, "0")
-			s = strings.TrimRight(s, ".")
-			return s
-		},
 		"deref": func(s *string) string {
 			if s == nil {
 				return ""
 			}
 			return *s
 		},
-		"now": func() string {
-			return time.Now().Format("January 2, 2006 at 3:04 PM")
-		},
-		"lower": strings.ToLower,
-		"replace": func(s, old, new string) string {
-			return strings.ReplaceAll(s, old, new)
-		},
+		"now":          time.Now,
+		"lower":        strings.ToLower,
+		"signatureURL": view.SignatureURL,
 	}
 
 	tpl, err := template.New("packing_list_pdf.html").Funcs(funcMap).ParseFS(
@@ -11,6 +11,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/i18n"
 )
 
 func TestPDFExporter_RenderPackingList_Success(t *testing.T) {
@@ -467,3 +469,56 @@ func createTestPayload() PackingListPayload {
 		CreatedAt:     createdAt,
 	}
 }
+
+func TestBuildPackingListHTML_WithProofOfDelivery(t *testing.T) {
+	exporter, err := NewPDFExporter("http://localhost", nil)
+	require.NoError(t, err)
+
+	payload := createTestPayload()
+	receivedBy := "Jane Smith"
+	receivedAt := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	signature := "data:image/png;base64,iVBORw0KGgo="
+	payload.ReceivedBy = &receivedBy
+	payload.ReceivedAt = &receivedAt
+	payload.ReceiverSignature = &signature
+
+	html, err := exporter.buildPackingListHTML(payload)
+	require.NoError(t, err)
+
+	assert.Contains(t, html, `src="data:image/png;base64,iVBORw0KGgo="`)
+	assert.Contains(t, html, "March 4, 2026")
+}
+
+func TestBuildPackingListHTML_RejectsUnsafeSignature(t *testing.T) {
+	exporter, err := NewPDFExporter("http://localhost", nil)
+	require.NoError(t, err)
+
+	payload := createTestPayload()
+	signature := "javascript:alert(1)"
+	payload.ReceiverSignature = &signature
+
+	html, err := exporter.buildPackingListHTML(payload)
+	require.NoError(t, err)
+
+	assert.NotContains(t, html, "javascript:")
+	assert.NotContains(t, html, "signature-image\"")
+}
+
+func TestBuildPackingListHTML_CustomerLanguage(t *testing.T) {
+	exporter, err := NewPDFExporter("http://localhost", nil)
+	require.NoError(t, err)
+
+	payload := createTestPayload()
+	payload.Locale = i18n.For(i18n.Indonesian)
+	payload.Lines[0].Quantity = 1250.5
+
+	html, err := exporter.buildPackingListHTML(payload)
+	require.NoError(t, err)
+
+	assert.Contains(t, html, `<html lang="id">`)
+	assert.Contains(t, html, "SURAT JALAN")
+	assert.Contains(t, html, "Dalam Pengiriman")
+	assert.Contains(t, html, "15 Januari 2025")
+	assert.Contains(t, html, "1.250,5")
+	assert.NotContains(t, html, "Delivery Order Document")
+}
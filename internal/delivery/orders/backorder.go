@@ -0,0 +1,173 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BackorderStatus represents the lifecycle of a delivery back-order.
+type BackorderStatus string
+
+const (
+	BackorderOpen      BackorderStatus = "OPEN"      // Waiting for stock
+	BackorderFulfilled BackorderStatus = "FULFILLED" // Spawned into a new delivery order
+	BackorderCancelled BackorderStatus = "CANCELLED" // Origin DO cancelled or manually dropped
+)
+
+// IsValid checks if the back-order status is valid.
+func (s BackorderStatus) IsValid() bool {
+	switch s {
+	case BackorderOpen, BackorderFulfilled, BackorderCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Backorder records the quantity of a sales order line that could not be
+// shipped on a delivery order because stock was short.
+type Backorder struct {
+	ID                       int64           `json:"id" db:"id"`
+	CompanyID                int64           `json:"company_id" db:"company_id"`
+	SalesOrderID             int64           `json:"sales_order_id" db:"sales_order_id"`
+	SalesOrderLineID         int64           `json:"sales_order_line_id" db:"sales_order_line_id"`
+	DeliveryOrderID          int64           `json:"delivery_order_id" db:"delivery_order_id"`
+	ProductID                int64           `json:"product_id" db:"product_id"`
+	WarehouseID              int64           `json:"warehouse_id" db:"warehouse_id"`
+	QuantityRequested        float64         `json:"quantity_requested" db:"quantity_requested"`
+	QuantityShort            float64         `json:"quantity_short" db:"quantity_short"`
+	Status                   BackorderStatus `json:"status" db:"status"`
+	FulfilledDeliveryOrderID *int64          `json:"fulfilled_delivery_order_id,omitempty" db:"fulfilled_delivery_order_id"`
+	FulfilledAt              *time.Time      `json:"fulfilled_at,omitempty" db:"fulfilled_at"`
+	CreatedBy                int64           `json:"created_by" db:"created_by"`
+	CreatedAt                time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt                time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// BackorderWithDetails includes joined data for the back-orders report.
+type BackorderWithDetails struct {
+	Backorder
+	DeliveryOrderNumber          string  `json:"delivery_order_number" db:"delivery_order_number"`
+	SalesOrderNumber             string  `json:"sales_order_number" db:"sales_order_number"`
+	CustomerName                 string  `json:"customer_name" db:"customer_name"`
+	WarehouseName                string  `json:"warehouse_name" db:"warehouse_name"`
+	ProductCode                  string  `json:"product_code" db:"product_code"`
+	ProductName                  string  `json:"product_name" db:"product_name"`
+	FulfilledDeliveryOrderNumber *string `json:"fulfilled_delivery_order_number,omitempty" db:"fulfilled_delivery_order_number"`
+}
+
+// shortfall captures the undelivered remainder of a requested line.
+type shortfall struct {
+	line      CreateLineReq
+	requested float64
+	short     float64
+}
+
+// allocateStock caps each requested line at the stock available in the
+// warehouse. Lines sharing a product draw from the same pool in request order.
+// Lines with nothing available are dropped from the delivery and reported as a
+// full shortfall.
+func allocateStock(lines []CreateLineReq, available map[int64]float64) ([]CreateLineReq, []shortfall) {
+	remaining := make(map[int64]float64, len(available))
+	for productID, qty := range available {
+		remaining[productID] = qty
+	}
+	deliver := make([]CreateLineReq, 0, len(lines))
+	var short []shortfall
+	for _, line := range lines {
+		stock := remaining[line.ProductID]
+		if stock < 0 {
+			stock = 0
+		}
+		qty := line.QuantityToDeliver
+		if qty > stock {
+			qty = stock
+		}
+		remaining[line.ProductID] = stock - qty
+		if missing := line.QuantityToDeliver - qty; missing > 0 {
+			short = append(short, shortfall{line: line, requested: line.QuantityToDeliver, short: missing})
+		}
+		if qty > 0 {
+			capped := line
+			capped.QuantityToDeliver = qty
+			deliver = append(deliver, capped)
+		}
+	}
+	return deliver, short
+}
+
+// planBackorders checks stock for each requested line and splits the request
+// into what can ship now and what must be back-ordered. Stock reserved for
+// other sales orders is not available; the order's own reservation is.
+func (s *Service) planBackorders(ctx context.Context, warehouseID, salesOrderID int64, lines []CreateLineReq) ([]CreateLineReq, []shortfall, error) {
+	available := make(map[int64]float64)
+	var productIDs []int64
+	for _, line := range lines {
+		if _, ok := available[line.ProductID]; ok {
+			continue
+		}
+		qty, err := s.repo.GetAvailableStock(ctx, warehouseID, line.ProductID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get available stock: %w", err)
+		}
+		available[line.ProductID] = qty
+		productIDs = append(productIDs, line.ProductID)
+	}
+	reserved, err := s.repo.ReservedStock(ctx, warehouseID, productIDs, []int64{salesOrderID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get reserved stock: %w", err)
+	}
+	for productID, r := range reserved {
+		available[productID] -= r.Open
+	}
+	deliver, short := allocateStock(lines, available)
+	if len(deliver) == 0 {
+		return nil, nil, ErrInsufficientStock
+	}
+	return deliver, short, nil
+}
+
+// ListBackorders returns back-orders for the report view.
+func (s *Service) ListBackorders(ctx context.Context, req BackorderListRequest) ([]BackorderWithDetails, int, error) {
+	return s.repo.ListBackorders(ctx, req)
+}
+
+// CreateFromBackorder spawns a new delivery order for an open back-order. The
+// new DO may itself be short, in which case a fresh back-order is recorded and
+// the original is still marked fulfilled.
+func (s *Service) CreateFromBackorder(ctx context.Context, backorderID int64, req FulfillBackorderRequest, createdBy int64) (*DeliveryOrder, error) {
+	bo, err := s.repo.GetBackorder(ctx, backorderID)
+	if err != nil {
+		return nil, fmt.Errorf("get backorder: %w", err)
+	}
+	if bo.Status != BackorderOpen {
+		return nil, fmt.Errorf("%w: %s", ErrBackorderNotOpen, bo.Status)
+	}
+
+	warehouseID := bo.WarehouseID
+	if req.WarehouseID > 0 {
+		warehouseID = req.WarehouseID
+	}
+	deliveryDate := req.DeliveryDate
+	if deliveryDate.IsZero() {
+		deliveryDate = time.Now()
+	}
+
+	return s.Create(ctx, CreateRequest{
+		CompanyID:      bo.CompanyID,
+		SalesOrderID:   bo.SalesOrderID,
+		WarehouseID:    warehouseID,
+		DeliveryDate:   deliveryDate,
+		DriverName:     req.DriverName,
+		VehicleNumber:  req.VehicleNumber,
+		Notes:          req.Notes,
+		AllowBackorder: true,
+		BackorderID:    &bo.ID,
+		Lines: []CreateLineReq{{
+			SalesOrderLineID:  bo.SalesOrderLineID,
+			ProductID:         bo.ProductID,
+			QuantityToDeliver: bo.QuantityShort,
+		}},
+	}, createdBy)
+}
@@ -0,0 +1,48 @@
+package orders
+
+import "testing"
+
+func TestAllocateStockSplitsShortfall(t *testing.T) {
+	lines := []CreateLineReq{
+		{SalesOrderLineID: 1, ProductID: 10, QuantityToDeliver: 5},
+		{SalesOrderLineID: 2, ProductID: 10, QuantityToDeliver: 4},
+		{SalesOrderLineID: 3, ProductID: 20, QuantityToDeliver: 3},
+		{SalesOrderLineID: 4, ProductID: 30, QuantityToDeliver: 2},
+	}
+	available := map[int64]float64{10: 7, 20: 0, 30: 5}
+
+	deliver, short := allocateStock(lines, available)
+
+	wantDeliver := map[int64]float64{1: 5, 2: 2, 4: 2}
+	if len(deliver) != len(wantDeliver) {
+		t.Fatalf("expected %d deliverable lines, got %d", len(wantDeliver), len(deliver))
+	}
+	for _, line := range deliver {
+		if want := wantDeliver[line.SalesOrderLineID]; line.QuantityToDeliver != want {
+			t.Fatalf("line %d: expected qty %.2f, got %.2f", line.SalesOrderLineID, want, line.QuantityToDeliver)
+		}
+	}
+
+	wantShort := map[int64]float64{2: 2, 3: 3}
+	if len(short) != len(wantShort) {
+		t.Fatalf("expected %d shortfalls, got %d", len(wantShort), len(short))
+	}
+	for _, sf := range short {
+		if want := wantShort[sf.line.SalesOrderLineID]; sf.short != want {
+			t.Fatalf("line %d: expected short %.2f, got %.2f", sf.line.SalesOrderLineID, want, sf.short)
+		}
+	}
+	if available[10] != 7 {
+		t.Fatalf("allocateStock must not mutate the caller's availability map")
+	}
+}
+
+func TestAllocateStockNothingAvailable(t *testing.T) {
+	deliver, short := allocateStock([]CreateLineReq{{SalesOrderLineID: 1, ProductID: 10, QuantityToDeliver: 1}}, nil)
+	if len(deliver) != 0 {
+		t.Fatalf("expected no deliverable lines, got %d", len(deliver))
+	}
+	if len(short) != 1 || short[0].short != 1 {
+		t.Fatalf("expected full shortfall, got %+v", short)
+	}
+}
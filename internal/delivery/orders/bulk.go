@@ -0,0 +1,327 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MaxBulkConfirm caps how many delivery orders one bulk confirmation handles.
+const MaxBulkConfirm = 100
+
+// stockTolerance absorbs float noise when demand is compared with stock.
+const stockTolerance = 1e-9
+
+// CommittedQuantity is stock already promised to confirmed or in-transit
+// deliveries of a product, in the unit of the delivery lines.
+type CommittedQuantity struct {
+	ProductID int64
+	UOM       string
+	Quantity  float64
+}
+
+// ReservedQuantity is the stock that sales order reservations hold for a
+// product in a warehouse, in base units. Uncommitted excludes the part
+// already on confirmed or in-transit deliveries, which CommittedStock
+// counts.
+type ReservedQuantity struct {
+	Open        float64
+	Uncommitted float64
+}
+
+// StockCheck compares the base-unit demand for a product at a warehouse with
+// what is available there: on hand less what confirmed and in-transit
+// deliveries still hold.
+type StockCheck struct {
+	WarehouseID int64
+	ProductID   int64
+	ProductCode string
+	ProductName string
+	Required    float64
+	Available   float64
+}
+
+// Short reports whether the demand exceeds what is available.
+func (c StockCheck) Short() bool {
+	return c.Required > c.Available+stockTolerance
+}
+
+// BulkConfirmed is a delivery order confirmed by a bulk confirmation.
+type BulkConfirmed struct {
+	ID        int64
+	DocNumber string
+}
+
+// BulkConfirmFailure is a delivery order a bulk confirmation left untouched.
+// Shortages lists the products it could not be covered for.
+type BulkConfirmFailure struct {
+	ID        int64
+	DocNumber string
+	Reason    string
+	Shortages []StockCheck
+}
+
+// BulkConfirmResult reports a bulk confirmation. Demand is the combined demand
+// of every eligible order per warehouse and product.
+type BulkConfirmResult struct {
+	Confirmed []BulkConfirmed
+	Failed    []BulkConfirmFailure
+	Demand    []StockCheck
+}
+
+type stockKey struct {
+	warehouseID int64
+	productID   int64
+}
+
+// bulkCandidate is a DRAFT order with its demand in base units.
+type bulkCandidate struct {
+	order  DeliveryOrder
+	demand map[stockKey]float64
+}
+
+// BulkConfirm confirms the selected DRAFT delivery orders after checking
+// their combined stock demand. Orders are served in delivery date order;
+// an order whose demand no longer fits what is left is reported with its
+// shortages and stays in DRAFT, and the rest are confirmed in one
+// transaction.
+func (s *Service) BulkConfirm(ctx context.Context, ids []int64, confirmedBy int64) (*BulkConfirmResult, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	if len(ids) > MaxBulkConfirm {
+		return nil, fmt.Errorf("%w: %d selected", ErrBulkTooMany, len(ids))
+	}
+
+	result := &BulkConfirmResult{}
+	products := make(map[int64]LineWithDetails)
+	var candidates []bulkCandidate
+	for _, id := range ids {
+		existing, err := s.repo.GetByID(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			result.Failed = append(result.Failed, BulkConfirmFailure{ID: id, Reason: ErrNotFound.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get delivery order %d: %w", id, err)
+		}
+		if !existing.Status.CanConfirm() {
+			result.Failed = append(result.Failed, BulkConfirmFailure{ID: id, DocNumber: existing.DocNumber,
+				Reason: fmt.Sprintf("%s: %s", ErrCannotConfirm, existing.Status)})
+			continue
+		}
+		lines, err := s.repo.GetLinesWithDetails(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get lines of delivery order %d: %w", id, err)
+		}
+		if len(lines) == 0 {
+			result.Failed = append(result.Failed, BulkConfirmFailure{ID: id, DocNumber: existing.DocNumber, Reason: ErrNoLines.Error()})
+			continue
+		}
+		candidate := bulkCandidate{order: *existing, demand: make(map[stockKey]float64)}
+		for _, line := range lines {
+			factor, err := s.baseFactor(ctx, line.ProductID, line.UOM)
+			if err != nil {
+				return nil, fmt.Errorf("unit of %s line %d: %w", existing.DocNumber, line.LineOrder, err)
+			}
+			candidate.demand[stockKey{existing.WarehouseID, line.ProductID}] += line.QuantityToDeliver * factor
+			products[line.ProductID] = line
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	available, err := s.bulkAvailability(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	confirm, short := planBulkConfirm(candidates, available)
+	result.Demand = combinedDemand(candidates, available, products)
+
+	for _, c := range short {
+		failure := BulkConfirmFailure{ID: c.order.ID, DocNumber: c.order.DocNumber, Reason: ErrBulkStockShort.Error()}
+		for _, check := range c.shortages {
+			line := products[check.ProductID]
+			check.ProductCode, check.ProductName = line.ProductCode, line.ProductName
+			failure.Shortages = append(failure.Shortages, check)
+		}
+		result.Failed = append(result.Failed, failure)
+	}
+
+	if len(confirm) == 0 {
+		return result, nil
+	}
+	confirmedAt := time.Now()
+	var confirmed []BulkConfirmed
+	var changed []BulkConfirmFailure
+	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		confirmed, changed = nil, nil
+		for _, order := range confirm {
+			ok, err := tx.ConfirmDraft(ctx, order.ID, confirmedBy, confirmedAt)
+			if err != nil {
+				return fmt.Errorf("confirm %s: %w", order.DocNumber, err)
+			}
+			if !ok {
+				changed = append(changed, BulkConfirmFailure{ID: order.ID, DocNumber: order.DocNumber, Reason: ErrCannotConfirm.Error()})
+				continue
+			}
+			confirmed = append(confirmed, BulkConfirmed{ID: order.ID, DocNumber: order.DocNumber})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Confirmed = confirmed
+	result.Failed = append(result.Failed, changed...)
+	return result, nil
+}
+
+// bulkAvailability loads what is available for every warehouse and product
+// the candidates need: on hand, less committed deliveries and less the stock
+// reserved for sales orders other than the candidates' own.
+func (s *Service) bulkAvailability(ctx context.Context, candidates []bulkCandidate) (map[stockKey]float64, error) {
+	byWarehouse := make(map[int64][]int64)
+	available := make(map[stockKey]float64)
+	var salesOrderIDs []int64
+	for _, c := range candidates {
+		salesOrderIDs = append(salesOrderIDs, c.order.SalesOrderID)
+		for key := range c.demand {
+			if _, ok := available[key]; ok {
+				continue
+			}
+			onHand, err := s.repo.GetAvailableStock(ctx, key.warehouseID, key.productID)
+			if err != nil {
+				return nil, fmt.Errorf("get available stock: %w", err)
+			}
+			available[key] = onHand
+			byWarehouse[key.warehouseID] = append(byWarehouse[key.warehouseID], key.productID)
+		}
+	}
+	for warehouseID, productIDs := range byWarehouse {
+		committed, err := s.repo.CommittedStock(ctx, warehouseID, productIDs)
+		if err != nil {
+			return nil, fmt.Errorf("get committed stock: %w", err)
+		}
+		for _, c := range committed {
+			factor, err := s.baseFactor(ctx, c.ProductID, c.UOM)
+			if err != nil {
+				return nil, fmt.Errorf("unit of committed product %d: %w", c.ProductID, err)
+			}
+			available[stockKey{warehouseID, c.ProductID}] -= c.Quantity * factor
+		}
+		reserved, err := s.repo.ReservedStock(ctx, warehouseID, productIDs, salesOrderIDs)
+		if err != nil {
+			return nil, fmt.Errorf("get reserved stock: %w", err)
+		}
+		for productID, r := range reserved {
+			available[stockKey{warehouseID, productID}] -= r.Uncommitted
+		}
+	}
+	for key, qty := range available {
+		if qty < 0 {
+			available[key] = 0
+		}
+	}
+	return available, nil
+}
+
+func (s *Service) baseFactor(ctx context.Context, productID int64, uom string) (float64, error) {
+	if s.units == nil {
+		return 1, nil
+	}
+	return s.units.BaseFactor(ctx, productID, uom)
+}
+
+type shortCandidate struct {
+	order     DeliveryOrder
+	shortages []StockCheck
+}
+
+// planBulkConfirm walks the candidates by delivery date, then ID, and takes
+// each order whose whole demand still fits what is left. Orders that do not
+// fit keep their stock unallocated for the orders after them.
+func planBulkConfirm(candidates []bulkCandidate, available map[stockKey]float64) ([]DeliveryOrder, []shortCandidate) {
+	ordered := append([]bulkCandidate(nil), candidates...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i].order, ordered[j].order
+		if !a.DeliveryDate.Equal(b.DeliveryDate) {
+			return a.DeliveryDate.Before(b.DeliveryDate)
+		}
+		return a.ID < b.ID
+	})
+	remaining := make(map[stockKey]float64, len(available))
+	for key, qty := range available {
+		remaining[key] = qty
+	}
+
+	var confirm []DeliveryOrder
+	var short []shortCandidate
+	for _, c := range ordered {
+		var shortages []StockCheck
+		for key, qty := range c.demand {
+			check := StockCheck{WarehouseID: key.warehouseID, ProductID: key.productID, Required: qty, Available: remaining[key]}
+			if check.Short() {
+				shortages = append(shortages, check)
+			}
+		}
+		if len(shortages) > 0 {
+			sortChecks(shortages)
+			short = append(short, shortCandidate{order: c.order, shortages: shortages})
+			continue
+		}
+		for key, qty := range c.demand {
+			remaining[key] -= qty
+		}
+		confirm = append(confirm, c.order)
+	}
+	return confirm, short
+}
+
+// combinedDemand totals the candidates' demand per warehouse and product.
+func combinedDemand(candidates []bulkCandidate, available map[stockKey]float64, products map[int64]LineWithDetails) []StockCheck {
+	totals := make(map[stockKey]float64)
+	for _, c := range candidates {
+		for key, qty := range c.demand {
+			totals[key] += qty
+		}
+	}
+	out := make([]StockCheck, 0, len(totals))
+	for key, qty := range totals {
+		line := products[key.productID]
+		out = append(out, StockCheck{
+			WarehouseID: key.warehouseID,
+			ProductID:   key.productID,
+			ProductCode: line.ProductCode,
+			ProductName: line.ProductName,
+			Required:    qty,
+			Available:   available[key],
+		})
+	}
+	sortChecks(out)
+	return out
+}
+
+func sortChecks(checks []StockCheck) {
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].WarehouseID != checks[j].WarehouseID {
+			return checks[i].WarehouseID < checks[j].WarehouseID
+		}
+		return checks[i].ProductID < checks[j].ProductID
+	})
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
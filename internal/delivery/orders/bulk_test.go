@@ -0,0 +1,202 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type fakeBulkRepo struct {
+	Repository
+	TxRepository
+	orders    map[int64]*DeliveryOrder
+	lines     map[int64][]LineWithDetails
+	onHand    map[stockKey]float64
+	committed map[int64][]CommittedQuantity
+	reserved  []fakeReservation
+	confirmed []int64
+}
+
+type fakeReservation struct {
+	warehouseID, salesOrderID, productID int64
+	qty                                  ReservedQuantity
+}
+
+func (f *fakeBulkRepo) GetByID(_ context.Context, id int64) (*DeliveryOrder, error) {
+	do, ok := f.orders[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *do
+	return &copied, nil
+}
+
+func (f *fakeBulkRepo) GetLinesWithDetails(_ context.Context, id int64) ([]LineWithDetails, error) {
+	return f.lines[id], nil
+}
+
+func (f *fakeBulkRepo) GetAvailableStock(_ context.Context, warehouseID, productID int64) (float64, error) {
+	return f.onHand[stockKey{warehouseID, productID}], nil
+}
+
+func (f *fakeBulkRepo) CommittedStock(_ context.Context, warehouseID int64, _ []int64) ([]CommittedQuantity, error) {
+	return f.committed[warehouseID], nil
+}
+
+func (f *fakeBulkRepo) ReservedStock(_ context.Context, warehouseID int64, _ []int64, exclude []int64) (map[int64]ReservedQuantity, error) {
+	out := make(map[int64]ReservedQuantity)
+	for _, r := range f.reserved {
+		if r.warehouseID != warehouseID || slices.Contains(exclude, r.salesOrderID) {
+			continue
+		}
+		q := out[r.productID]
+		q.Open += r.qty.Open
+		q.Uncommitted += r.qty.Uncommitted
+		out[r.productID] = q
+	}
+	return out, nil
+}
+
+func (f *fakeBulkRepo) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeBulkRepo) ConfirmDraft(_ context.Context, id, _ int64, _ time.Time) (bool, error) {
+	if f.orders[id].Status != StatusDraft {
+		return false, nil
+	}
+	f.orders[id].Status = StatusConfirmed
+	f.confirmed = append(f.confirmed, id)
+	return true, nil
+}
+
+type fakeBoxUnits struct{}
+
+func (fakeBoxUnits) BaseFactor(_ context.Context, _ int64, uom string) (float64, error) {
+	if uom == "BOX" {
+		return 10, nil
+	}
+	return 1, nil
+}
+
+func bulkLine(productID int64, qty float64, uom string) LineWithDetails {
+	return LineWithDetails{Line: Line{ProductID: productID, QuantityToDeliver: qty, UOM: uom}}
+}
+
+func bulkRepo() *fakeBulkRepo {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	return &fakeBulkRepo{
+		orders: map[int64]*DeliveryOrder{
+			1: {ID: 1, DocNumber: "DO-1", WarehouseID: 5, DeliveryDate: day(18), Status: StatusDraft},
+			2: {ID: 2, DocNumber: "DO-2", WarehouseID: 5, DeliveryDate: day(17), Status: StatusDraft},
+			3: {ID: 3, DocNumber: "DO-3", WarehouseID: 5, DeliveryDate: day(19), Status: StatusDraft},
+			4: {ID: 4, DocNumber: "DO-4", WarehouseID: 5, DeliveryDate: day(17), Status: StatusConfirmed},
+		},
+		lines: map[int64][]LineWithDetails{
+			1: {bulkLine(1, 30, "PCS")},
+			2: {bulkLine(1, 2, "BOX"), bulkLine(2, 4, "PCS")},
+			3: {bulkLine(1, 10, "PCS"), bulkLine(2, 1, "PCS")},
+		},
+		// Product 1: 60 on hand, 15 held by a confirmed delivery, so 45 left.
+		onHand:    map[stockKey]float64{{5, 1}: 60, {5, 2}: 5},
+		committed: map[int64][]CommittedQuantity{5: {{ProductID: 1, UOM: "PCS", Quantity: 15}}},
+	}
+}
+
+func TestBulkConfirmSkipsOrdersTheCombinedStockCannotCover(t *testing.T) {
+	repo := bulkRepo()
+	svc := NewService(repo)
+	svc.SetUnitConverter(fakeBoxUnits{})
+
+	result, err := svc.BulkConfirm(context.Background(), []int64{1, 2, 3, 4, 2, 99}, 7)
+	if err != nil {
+		t.Fatalf("bulk confirm: %v", err)
+	}
+
+	// DO-2 ships first (earliest date): 20 of product 1 and 4 of product 2.
+	// DO-1 then needs 30 of the 25 left and is skipped; DO-3 still fits.
+	if len(result.Confirmed) != 2 || result.Confirmed[0].ID != 2 || result.Confirmed[1].ID != 3 {
+		t.Fatalf("expected DO-2 and DO-3 confirmed, got %+v", result.Confirmed)
+	}
+	if len(repo.confirmed) != 2 {
+		t.Fatalf("expected two orders confirmed in the transaction, got %v", repo.confirmed)
+	}
+
+	failed := map[int64]BulkConfirmFailure{}
+	for _, f := range result.Failed {
+		failed[f.ID] = f
+	}
+	if len(failed) != 3 {
+		t.Fatalf("expected three failures, got %+v", result.Failed)
+	}
+	short := failed[1]
+	if short.Reason != ErrBulkStockShort.Error() {
+		t.Fatalf("DO-1: expected stock shortage, got %q", short.Reason)
+	}
+	if len(short.Shortages) != 1 || short.Shortages[0].ProductID != 1 || short.Shortages[0].Required != 30 || short.Shortages[0].Available != 25 {
+		t.Fatalf("DO-1: unexpected shortages %+v", short.Shortages)
+	}
+	if failed[4].Reason == "" || failed[4].DocNumber != "DO-4" {
+		t.Fatalf("DO-4 is already confirmed and must be reported, got %+v", failed[4])
+	}
+	if failed[99].Reason != ErrNotFound.Error() {
+		t.Fatalf("unknown order must be reported as not found, got %+v", failed[99])
+	}
+
+	if len(result.Demand) != 2 {
+		t.Fatalf("expected demand for two products, got %+v", result.Demand)
+	}
+	if d := result.Demand[0]; d.ProductID != 1 || d.Required != 60 || d.Available != 45 || !d.Short() {
+		t.Fatalf("product 1: unexpected combined demand %+v", d)
+	}
+	if d := result.Demand[1]; d.ProductID != 2 || d.Required != 5 || d.Short() {
+		t.Fatalf("product 2: unexpected combined demand %+v", d)
+	}
+}
+
+func TestBulkConfirmRejectsEmptyAndOversizedSelections(t *testing.T) {
+	svc := NewService(bulkRepo())
+	if _, err := svc.BulkConfirm(context.Background(), []int64{0, -1}, 7); !errors.Is(err, ErrBulkEmpty) {
+		t.Fatalf("expected ErrBulkEmpty, got %v", err)
+	}
+	ids := make([]int64, MaxBulkConfirm+1)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	if _, err := svc.BulkConfirm(context.Background(), ids, 7); !errors.Is(err, ErrBulkTooMany) {
+		t.Fatalf("expected ErrBulkTooMany, got %v", err)
+	}
+}
+
+func TestBulkConfirmHoldsBackStockReservedForOtherOrders(t *testing.T) {
+	repo := bulkRepo()
+	repo.orders[2].SalesOrderID = 20
+	repo.orders[3].SalesOrderID = 30
+	repo.reserved = []fakeReservation{
+		// Another order holds 20 of product 1, 5 of it already on a
+		// confirmed delivery and so counted as committed.
+		{warehouseID: 5, salesOrderID: 77, productID: 1, qty: ReservedQuantity{Open: 25, Uncommitted: 20}},
+		// DO-2's own order reserved product 2; that stock is its to ship.
+		{warehouseID: 5, salesOrderID: 20, productID: 2, qty: ReservedQuantity{Open: 4, Uncommitted: 4}},
+	}
+	svc := NewService(repo)
+	svc.SetUnitConverter(fakeBoxUnits{})
+
+	result, err := svc.BulkConfirm(context.Background(), []int64{1, 2, 3}, 7)
+	if err != nil {
+		t.Fatalf("bulk confirm: %v", err)
+	}
+	// Product 1: 45 unreserved and uncommitted. DO-2 takes 20, leaving 5 for
+	// DO-1 (30) and DO-3 (10).
+	if len(result.Confirmed) != 1 || result.Confirmed[0].ID != 2 {
+		t.Fatalf("expected only DO-2 confirmed, got %+v", result.Confirmed)
+	}
+	if d := result.Demand[0]; d.ProductID != 1 || d.Available != 25 {
+		t.Fatalf("product 1: expected 25 available, got %+v", d)
+	}
+	if d := result.Demand[1]; d.ProductID != 2 || d.Available != 5 {
+		t.Fatalf("product 2: own reservation must not reduce availability, got %+v", d)
+	}
+}
@@ -0,0 +1,104 @@
+package orders
+
+import (
+	"context"
+	"time"
+)
+
+// maxCalendarDays bounds a calendar request to roughly one quarter.
+const maxCalendarDays = 93
+
+// CalendarRequest selects the delivery orders shown on the calendar.
+// WarehouseID 0 covers every warehouse of the company.
+type CalendarRequest struct {
+	CompanyID   int64
+	WarehouseID int64
+	From        time.Time
+	To          time.Time
+}
+
+// CalendarBucket is the raw per-day aggregate read from the database.
+type CalendarBucket struct {
+	Date     time.Time
+	Orders   int
+	Quantity float64
+}
+
+// CalendarDay summarises the delivery orders scheduled on one date.
+type CalendarDay struct {
+	Date     string  `json:"date"`
+	Orders   int     `json:"orders"`
+	Quantity float64 `json:"quantity"`
+	// Capacity is the configured daily limit; 0 means unlimited.
+	Capacity int `json:"capacity"`
+	// Load is Orders divided by Capacity, or 0 when unlimited.
+	Load       float64 `json:"load"`
+	Overbooked bool    `json:"overbooked"`
+}
+
+// Calendar lists every day in the requested range, including empty days, so
+// the frontend can render a heatmap without filling gaps.
+type Calendar struct {
+	From           string        `json:"from"`
+	To             string        `json:"to"`
+	WarehouseID    int64         `json:"warehouse_id,omitempty"`
+	DailyCapacity  int           `json:"daily_capacity"`
+	TotalOrders    int           `json:"total_orders"`
+	TotalQuantity  float64       `json:"total_quantity"`
+	OverbookedDays int           `json:"overbooked_days"`
+	Days           []CalendarDay `json:"days"`
+}
+
+// SetDailyCapacity sets how many delivery orders a day can take before the
+// calendar flags it as overbooked. Zero disables the check.
+func (s *Service) SetDailyCapacity(capacity int) {
+	if capacity < 0 {
+		capacity = 0
+	}
+	s.dailyCapacity = capacity
+}
+
+// Calendar groups the non-cancelled delivery orders in the range by delivery
+// date.
+func (s *Service) Calendar(ctx context.Context, req CalendarRequest) (Calendar, error) {
+	req.From = truncateDay(req.From)
+	req.To = truncateDay(req.To)
+	if req.To.Before(req.From) || req.To.Sub(req.From) >= maxCalendarDays*24*time.Hour {
+		return Calendar{}, ErrInvalidCalendarRange
+	}
+	buckets, err := s.repo.DeliveryCalendar(ctx, req)
+	if err != nil {
+		return Calendar{}, err
+	}
+	cal := buildCalendar(req.From, req.To, buckets, s.dailyCapacity)
+	cal.WarehouseID = req.WarehouseID
+	return cal, nil
+}
+
+func buildCalendar(from, to time.Time, buckets []CalendarBucket, capacity int) Calendar {
+	byDate := make(map[string]CalendarBucket, len(buckets))
+	for _, b := range buckets {
+		byDate[b.Date.Format("2006-01-02")] = b
+	}
+	cal := Calendar{From: from.Format("2006-01-02"), To: to.Format("2006-01-02"), DailyCapacity: capacity}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		b := byDate[key]
+		entry := CalendarDay{Date: key, Orders: b.Orders, Quantity: b.Quantity, Capacity: capacity}
+		if capacity > 0 {
+			entry.Load = float64(b.Orders) / float64(capacity)
+			entry.Overbooked = b.Orders > capacity
+		}
+		if entry.Overbooked {
+			cal.OverbookedDays++
+		}
+		cal.TotalOrders += b.Orders
+		cal.TotalQuantity += b.Quantity
+		cal.Days = append(cal.Days, entry)
+	}
+	return cal
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,40 @@
+package orders
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildCalendarFillsGapsAndFlagsOverbooked(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 0, 0, 0, 0, time.UTC) }
+	buckets := []CalendarBucket{
+		{Date: day(2), Orders: 3, Quantity: 40},
+		{Date: day(4), Orders: 5, Quantity: 12.5},
+	}
+
+	cal := buildCalendar(day(1), day(4), buckets, 4)
+
+	if len(cal.Days) != 4 {
+		t.Fatalf("expected 4 days, got %d", len(cal.Days))
+	}
+	if cal.Days[0].Date != "2026-05-01" || cal.Days[0].Orders != 0 {
+		t.Fatalf("expected an empty first day, got %+v", cal.Days[0])
+	}
+	if got := cal.Days[1]; got.Orders != 3 || got.Quantity != 40 || got.Load != 0.75 || got.Overbooked {
+		t.Fatalf("unexpected 2 May: %+v", got)
+	}
+	if got := cal.Days[3]; !got.Overbooked || got.Load != 1.25 {
+		t.Fatalf("expected 4 May overbooked, got %+v", got)
+	}
+	if cal.TotalOrders != 8 || cal.TotalQuantity != 52.5 || cal.OverbookedDays != 1 {
+		t.Fatalf("unexpected totals: %+v", cal)
+	}
+}
+
+func TestBuildCalendarWithoutCapacity(t *testing.T) {
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	cal := buildCalendar(day, day, []CalendarBucket{{Date: day, Orders: 50}}, 0)
+	if cal.Days[0].Overbooked || cal.Days[0].Load != 0 || cal.OverbookedDays != 0 {
+		t.Fatalf("capacity 0 must never flag a day: %+v", cal.Days[0])
+	}
+}
@@ -4,15 +4,24 @@ import "time"
 
 // CreateRequest represents request to create a delivery order.
 type CreateRequest struct {
-	CompanyID      int64            `json:"company_id" validate:"required,gt=0"`
-	SalesOrderID   int64            `json:"sales_order_id" validate:"required,gt=0"`
-	WarehouseID    int64            `json:"warehouse_id" validate:"required,gt=0"`
-	DeliveryDate   time.Time        `json:"delivery_date" validate:"required"`
-	DriverName     *string          `json:"driver_name,omitempty" validate:"omitempty,max=200"`
-	VehicleNumber  *string          `json:"vehicle_number,omitempty" validate:"omitempty,max=50"`
-	TrackingNumber *string          `json:"tracking_number,omitempty" validate:"omitempty,max=100"`
-	Notes          *string          `json:"notes,omitempty"`
-	Lines          []CreateLineReq  `json:"lines" validate:"required,min=1,dive"`
+	CompanyID      int64           `json:"company_id" validate:"required,gt=0"`
+	SalesOrderID   int64           `json:"sales_order_id" validate:"required,gt=0"`
+	WarehouseID    int64           `json:"warehouse_id" validate:"required,gt=0"`
+	DeliveryDate   time.Time       `json:"delivery_date" validate:"required"`
+	DriverName     *string         `json:"driver_name,omitempty" validate:"omitempty,max=200"`
+	VehicleNumber  *string         `json:"vehicle_number,omitempty" validate:"omitempty,max=50"`
+	TrackingNumber *string         `json:"tracking_number,omitempty" validate:"omitempty,max=100"`
+	Notes          *string         `json:"notes,omitempty"`
+	Lines          []CreateLineReq `json:"lines" validate:"required,min=1,dive"`
+	// DriverID and VehicleID pick from the master lists and take precedence
+	// over the free-text driver name and vehicle number.
+	DriverID  *int64 `json:"driver_id,omitempty"`
+	VehicleID *int64 `json:"vehicle_id,omitempty"`
+	// AllowBackorder ships whatever stock is available and records the
+	// shortfall as a back-order instead of requiring the full quantity.
+	AllowBackorder bool `json:"allow_backorder"`
+	// BackorderID links the new DO to the open back-order it fulfils.
+	BackorderID *int64 `json:"backorder_id,omitempty"`
 }
 
 // CreateLineReq represents a line item in create request.
@@ -34,6 +43,15 @@ type UpdateRequest struct {
 	Lines          *[]CreateLineReq `json:"lines,omitempty" validate:"omitempty,min=1,dive"`
 }
 
+// UpdateLogisticsRequest changes only the logistics details of a delivery
+// order. A blank value clears the field.
+type UpdateLogisticsRequest struct {
+	DriverName     string `json:"driver_name" validate:"max=200"`
+	VehicleNumber  string `json:"vehicle_number" validate:"max=50"`
+	TrackingNumber string `json:"tracking_number" validate:"max=100"`
+	UpdatedBy      int64  `json:"updated_by"`
+}
+
 // ConfirmRequest represents request to confirm delivery order.
 type ConfirmRequest struct {
 	ConfirmedBy int64 `json:"confirmed_by" validate:"required,gt=0"`
@@ -47,8 +65,14 @@ type MarkInTransitRequest struct {
 
 // MarkDeliveredRequest represents request to mark DO as delivered.
 type MarkDeliveredRequest struct {
-	DeliveredAt time.Time `json:"delivered_at" validate:"required"`
-	UpdatedBy   int64     `json:"updated_by" validate:"required,gt=0"`
+	DeliveredAt  time.Time `json:"delivered_at" validate:"required"`
+	ReceiverName string    `json:"receiver_name" validate:"required,max=200"`
+	Signature    *string   `json:"signature,omitempty"` // PNG or JPEG data URL
+	PODNotes     *string   `json:"pod_notes,omitempty" validate:"omitempty,max=1000"`
+	UpdatedBy    int64     `json:"updated_by" validate:"required,gt=0"`
+	// Serials maps delivery order line IDs to the serial numbers shipped on
+	// lines of serial-tracked products.
+	Serials map[int64][]string `json:"serials,omitempty"`
 }
 
 // CancelRequest represents request to cancel delivery order.
@@ -59,18 +83,36 @@ type CancelRequest struct {
 
 // ListRequest represents filters for listing delivery orders.
 type ListRequest struct {
-	CompanyID    int64   `json:"company_id" validate:"required,gt=0"`
-	SalesOrderID *int64  `json:"sales_order_id,omitempty"`
-	WarehouseID  *int64  `json:"warehouse_id,omitempty"`
-	CustomerID   *int64  `json:"customer_id,omitempty"`
-	Status       *Status `json:"status,omitempty"`
+	CompanyID    int64      `json:"company_id" validate:"required,gt=0"`
+	SalesOrderID *int64     `json:"sales_order_id,omitempty"`
+	WarehouseID  *int64     `json:"warehouse_id,omitempty"`
+	CustomerID   *int64     `json:"customer_id,omitempty"`
+	Status       *Status    `json:"status,omitempty"`
 	DateFrom     *time.Time `json:"date_from,omitempty"`
 	DateTo       *time.Time `json:"date_to,omitempty"`
-	Search       *string `json:"search,omitempty"`
-	SortBy       string  `json:"sort_by,omitempty"`
-	SortDir      string  `json:"sort_dir,omitempty"`
-	Limit        int     `json:"limit" validate:"gte=0,lte=1000"`
-	Offset       int     `json:"offset" validate:"gte=0"`
+	Search       *string    `json:"search,omitempty"`
+	SortBy       string     `json:"sort_by,omitempty"`
+	SortDir      string     `json:"sort_dir,omitempty"`
+	Limit        int        `json:"limit" validate:"gte=0,lte=1000"`
+	Offset       int        `json:"offset" validate:"gte=0"`
+}
+
+// BackorderListRequest represents filters for the back-orders report.
+type BackorderListRequest struct {
+	CompanyID    int64            `json:"company_id" validate:"required,gt=0"`
+	SalesOrderID *int64           `json:"sales_order_id,omitempty"`
+	Status       *BackorderStatus `json:"status,omitempty"`
+	Limit        int              `json:"limit" validate:"gte=0,lte=1000"`
+	Offset       int              `json:"offset" validate:"gte=0"`
+}
+
+// FulfillBackorderRequest represents request to spawn a DO from a back-order.
+type FulfillBackorderRequest struct {
+	WarehouseID   int64     `json:"warehouse_id,omitempty"`
+	DeliveryDate  time.Time `json:"delivery_date"`
+	DriverName    *string   `json:"driver_name,omitempty" validate:"omitempty,max=200"`
+	VehicleNumber *string   `json:"vehicle_number,omitempty" validate:"omitempty,max=50"`
+	Notes         *string   `json:"notes,omitempty"`
 }
 
 // ListResponse represents API response for list.
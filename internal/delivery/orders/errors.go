@@ -15,6 +15,8 @@ var (
 	ErrCannotShip    = errors.New("cannot ship delivery order in current status")
 	ErrCannotDeliver = errors.New("cannot deliver order in current status")
 	ErrCannotCancel  = errors.New("cannot cancel delivery order in current status")
+	// ErrCannotEditLogistics is returned once the order is delivered or cancelled.
+	ErrCannotEditLogistics = errors.New("logistics details can only change before delivery")
 
 	// Validation errors.
 	ErrEmptyLines          = errors.New("at least one line is required")
@@ -24,6 +26,11 @@ var (
 	ErrSOLineNotFound      = errors.New("sales order line not found or fully delivered")
 	ErrQuantityExceeds     = errors.New("requested quantity exceeds remaining")
 	ErrProductMismatch     = errors.New("product ID mismatch")
+	ErrReceiverRequired    = errors.New("receiver name is required")
+	ErrReceiverTooLong     = errors.New("receiver name must be at most 200 characters")
+	ErrPODNotesTooLong     = errors.New("delivery notes must be at most 1000 characters")
+	ErrInvalidSignature    = errors.New("signature must be a PNG or JPEG image up to 200 KB")
+	ErrLogisticsTooLong    = errors.New("driver name, vehicle number or tracking number is too long")
 
 	// Business rule errors.
 	ErrNoDeliverableLines = errors.New("no deliverable lines found for sales order")
@@ -31,6 +38,22 @@ var (
 	ErrCompanyMismatch    = errors.New("sales order belongs to different company")
 	ErrWarehouseNotFound  = errors.New("warehouse not found")
 	ErrNoLines            = errors.New("cannot confirm without lines")
+	ErrInsufficientStock  = errors.New("no stock available for any requested line")
+	// ErrWarehouseCompany is returned when the warehouse's branch belongs to
+	// another company than the sales order.
+	ErrWarehouseCompany = errors.New("warehouse belongs to a different company")
+
+	// Bulk confirmation errors.
+	ErrBulkEmpty      = errors.New("select at least one delivery order to confirm")
+	ErrBulkTooMany    = errors.New("too many delivery orders selected for one confirmation")
+	ErrBulkStockShort = errors.New("not enough stock left for this order after earlier orders in the batch")
+
+	// Back-order errors.
+	ErrBackorderNotFound = errors.New("backorder not found")
+	ErrBackorderNotOpen  = errors.New("backorder is not open")
+
+	// Calendar errors.
+	ErrInvalidCalendarRange = errors.New("calendar range must end on or after its start and span at most 93 days")
 
 	// External service errors.
 	ErrInventoryFailed = errors.New("inventory service operation failed")
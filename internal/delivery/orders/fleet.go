@@ -0,0 +1,81 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDriverUnavailable rejects a driver that is unknown or inactive.
+	ErrDriverUnavailable = errors.New("selected driver is not an active driver")
+	// ErrVehicleUnavailable rejects a vehicle that is unknown or inactive.
+	ErrVehicleUnavailable = errors.New("selected vehicle is not an active vehicle")
+)
+
+// DriverOption is an active driver from the master driver list.
+type DriverOption struct {
+	ID   int64
+	Name string
+}
+
+// VehicleOption is an active vehicle from the master vehicle list.
+type VehicleOption struct {
+	ID          int64
+	PlateNumber string
+	Description string
+}
+
+// FleetOptions lists the active drivers and vehicles offered on the
+// delivery order form.
+func (s *Service) FleetOptions(ctx context.Context) ([]DriverOption, []VehicleOption, error) {
+	drivers, err := s.repo.ActiveDrivers(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list drivers: %w", err)
+	}
+	vehicles, err := s.repo.ActiveVehicles(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list vehicles: %w", err)
+	}
+	return drivers, vehicles, nil
+}
+
+// resolveFleet replaces the free-text driver name and vehicle number with
+// the master record when one was picked, so orders from the pool always
+// carry the canonical spelling. Without a pick the free text is kept as an
+// ad-hoc exception.
+func (s *Service) resolveFleet(ctx context.Context, req *CreateRequest) error {
+	if req.DriverID != nil {
+		driver, err := s.repo.GetActiveDriver(ctx, *req.DriverID)
+		if err != nil {
+			return fmt.Errorf("get driver: %w", err)
+		}
+		if driver == nil {
+			return ErrDriverUnavailable
+		}
+		req.DriverName = &driver.Name
+	}
+	if req.VehicleID != nil {
+		vehicle, err := s.repo.GetActiveVehicle(ctx, *req.VehicleID)
+		if err != nil {
+			return fmt.Errorf("get vehicle: %w", err)
+		}
+		if vehicle == nil {
+			return ErrVehicleUnavailable
+		}
+		req.VehicleNumber = &vehicle.PlateNumber
+	}
+	return nil
+}
+
+// fleetUpdates links a new delivery order to the picked master records.
+func fleetUpdates(req CreateRequest) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if req.DriverID != nil {
+		updates["driver_id"] = *req.DriverID
+	}
+	if req.VehicleID != nil {
+		updates["vehicle_id"] = *req.VehicleID
+	}
+	return updates
+}
@@ -0,0 +1,66 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeFleetRepo struct {
+	Repository
+	drivers  map[int64]string
+	vehicles map[int64]string
+}
+
+func (f *fakeFleetRepo) GetActiveDriver(_ context.Context, id int64) (*DriverOption, error) {
+	name, ok := f.drivers[id]
+	if !ok {
+		return nil, nil
+	}
+	return &DriverOption{ID: id, Name: name}, nil
+}
+
+func (f *fakeFleetRepo) GetActiveVehicle(_ context.Context, id int64) (*VehicleOption, error) {
+	plate, ok := f.vehicles[id]
+	if !ok {
+		return nil, nil
+	}
+	return &VehicleOption{ID: id, PlateNumber: plate}, nil
+}
+
+func TestResolveFleetPrefersMasterRecords(t *testing.T) {
+	svc := NewService(&fakeFleetRepo{
+		drivers:  map[int64]string{1: "Budi Santoso"},
+		vehicles: map[int64]string{2: "B 1234 XYZ"},
+	})
+	typed, plate := "budi s.", "b1234xyz"
+	driverID, vehicleID := int64(1), int64(2)
+
+	req := CreateRequest{DriverName: &typed, VehicleNumber: &plate, DriverID: &driverID, VehicleID: &vehicleID}
+	if err := svc.resolveFleet(context.Background(), &req); err != nil {
+		t.Fatalf("resolveFleet: %v", err)
+	}
+	if *req.DriverName != "Budi Santoso" || *req.VehicleNumber != "B 1234 XYZ" {
+		t.Fatalf("expected master names, got %q / %q", *req.DriverName, *req.VehicleNumber)
+	}
+	updates := fleetUpdates(req)
+	if updates["driver_id"] != int64(1) || updates["vehicle_id"] != int64(2) {
+		t.Fatalf("unexpected fleet updates: %v", updates)
+	}
+
+	adhoc := CreateRequest{DriverName: &typed}
+	if err := svc.resolveFleet(context.Background(), &adhoc); err != nil {
+		t.Fatalf("resolveFleet ad-hoc: %v", err)
+	}
+	if *adhoc.DriverName != "budi s." || len(fleetUpdates(adhoc)) != 0 {
+		t.Fatalf("expected free text to be kept, got %q / %v", *adhoc.DriverName, fleetUpdates(adhoc))
+	}
+
+	unknown := int64(9)
+	if err := svc.resolveFleet(context.Background(), &CreateRequest{DriverID: &unknown}); !errors.Is(err, ErrDriverUnavailable) {
+		t.Fatalf("expected ErrDriverUnavailable, got %v", err)
+	}
+	if err := svc.resolveFleet(context.Background(), &CreateRequest{VehicleID: &unknown}); !errors.Is(err, ErrVehicleUnavailable) {
+		t.Fatalf("expected ErrVehicleUnavailable, got %v", err)
+	}
+}
@@ -1,13 +1,19 @@
 package orders
 
 import (
+	"encoding/base64"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -45,6 +51,8 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAny(shared.PermDeliveryOrderView))
 		r.Get("/", h.list)
+		r.Get("/backorders", h.listBackorders)
+		r.Get("/calendar", h.calendar)
 		r.Get("/{id}", h.show)
 	})
 
@@ -53,6 +61,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAll(shared.PermDeliveryOrderCreate))
 		r.Get("/new", h.showForm)
 		r.Post("/", h.create)
+		r.Post("/backorders/{id}/fulfill", h.fulfillBackorder)
 	})
 
 	// Edit routes
@@ -60,11 +69,13 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAll(shared.PermDeliveryOrderEdit))
 		r.Get("/{id}/edit", h.showEditForm)
 		r.Post("/{id}/edit", h.update)
+		r.Post("/{id}/logistics", h.updateLogistics)
 	})
 
 	// Action routes
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAll(shared.PermDeliveryOrderConfirm))
+		r.Post("/bulk-confirm", h.bulkConfirm)
 		r.Post("/{id}/confirm", h.confirm)
 	})
 	r.Group(func(r chi.Router) {
@@ -109,11 +120,11 @@ func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
 		req.Search = &s
 	}
 
-	h.logger.Info("List orders request", "companyID", companyID)
+	h.logger.InfoContext(r.Context(), "List orders request", "companyID", companyID)
 	orders, total, err := h.service.List(ctx, req)
 	if err != nil {
-		h.logger.Error("list orders failed", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list orders failed", "error", err)
+		httpx.Error(w, r, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
@@ -130,14 +141,14 @@ func (h *Handler) show(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
 		return
 	}
 
 	order, err := h.service.GetWithDetails(ctx, id)
 	if err != nil {
-		h.logger.Error("get order failed", "error", err, "id", id)
-		http.Error(w, "Not Found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get order failed", "error", err, "id", id)
+		httpx.Error(w, r, "Not Found", http.StatusNotFound)
 		return
 	}
 
@@ -149,12 +160,110 @@ func (h *Handler) show(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// showForm handles GET /delivery/orders/new
+// listBackorders handles GET /delivery/orders/backorders
+func (h *Handler) listBackorders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	companyID := getCompanyID(r)
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit := 20
+
+	req := BackorderListRequest{
+		CompanyID: companyID,
+		Limit:     limit,
+		Offset:    (page - 1) * limit,
+	}
+	status := BackorderStatus(r.URL.Query().Get("status"))
+	if status == "" {
+		status = BackorderOpen
+	}
+	if status.IsValid() {
+		req.Status = &status
+	}
+	if v := r.URL.Query().Get("sales_order_id"); v != "" {
+		if soID, err := strconv.ParseInt(v, 10, 64); err == nil {
+			req.SalesOrderID = &soID
+		}
+	}
+
+	backorders, total, err := h.service.ListBackorders(ctx, req)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list backorders failed", "error", err)
+		httpx.Error(w, r, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, r, "pages/delivery/backorders_list.html", map[string]interface{}{
+		"Backorders":  backorders,
+		"Status":      string(status),
+		"CurrentPage": page,
+		"TotalPages":  (total + limit - 1) / limit,
+		"TotalCount":  total,
+	})
+}
+
+// fulfillBackorder handles POST /delivery/orders/backorders/{id}/fulfill
+func (h *Handler) fulfillBackorder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	req := FulfillBackorderRequest{}
+	if v := r.FormValue("warehouse_id"); v != "" {
+		req.WarehouseID, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if d := r.FormValue("delivery_date"); d != "" {
+		req.DeliveryDate, _ = time.Parse("2006-01-02", d)
+	}
+	if v := r.FormValue("driver_name"); v != "" {
+		req.DriverName = &v
+	}
+	if v := r.FormValue("vehicle_number"); v != "" {
+		req.VehicleNumber = &v
+	}
+
+	order, err := h.service.CreateFromBackorder(ctx, id, req, getUserID(r))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "fulfill backorder failed", "error", err, "id", id)
+		h.redirect(w, r, "/delivery/orders/backorders", shared.UserSafeMessage(err))
+		return
+	}
+
+	h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(order.ID, 10), "Delivery order created from back-order")
+}
+
+// showForm handles GET /delivery/orders/new. With a sales order the warehouse
+// is prefilled from the branch default; branch_id picks a specific branch.
 func (h *Handler) showForm(w http.ResponseWriter, r *http.Request) {
 	soID := r.URL.Query().Get("sales_order_id")
-	h.render(w, r, "pages/delivery/order_form.html", map[string]interface{}{
+	data := map[string]interface{}{
 		"SalesOrderID": soID,
-	})
+	}
+	if salesOrderID, err := strconv.ParseInt(soID, 10, 64); err == nil && salesOrderID > 0 {
+		var branchID *int64
+		if parsed, err := strconv.ParseInt(r.URL.Query().Get("branch_id"), 10, 64); err == nil && parsed > 0 {
+			branchID = &parsed
+		}
+		warehouseID, err := h.service.DefaultWarehouse(r.Context(), salesOrderID, branchID)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "default delivery warehouse", "error", err, "sales_order_id", salesOrderID)
+		} else if warehouseID != nil {
+			data["WarehouseID"] = *warehouseID
+		}
+	}
+	h.addFleetOptions(r, data)
+	h.render(w, r, "pages/delivery/order_form.html", data)
 }
 
 // create handles POST /delivery/orders
@@ -164,7 +273,7 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 	companyID := getCompanyID(r)
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid form", http.StatusBadRequest)
 		return
 	}
 
@@ -194,10 +303,29 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 	if v := r.FormValue("notes"); v != "" {
 		req.Notes = &v
 	}
+	if id, err := strconv.ParseInt(r.FormValue("driver_id"), 10, 64); err == nil && id > 0 {
+		req.DriverID = &id
+	}
+	if id, err := strconv.ParseInt(r.FormValue("vehicle_id"), 10, 64); err == nil && id > 0 {
+		req.VehicleID = &id
+	}
+	req.AllowBackorder = r.FormValue("allow_backorder") != ""
 
 	order, err := h.service.Create(ctx, req, userID)
 	if err != nil {
-		h.logger.Error("create order failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create order failed", "error", err)
+		if errors.Is(err, ErrWarehouseNotFound) || errors.Is(err, ErrWarehouseCompany) {
+			h.renderFormError(w, r, map[string]string{"warehouse_id": err.Error()})
+			return
+		}
+		if errors.Is(err, ErrDriverUnavailable) {
+			h.renderFormError(w, r, map[string]string{"driver_id": err.Error()})
+			return
+		}
+		if errors.Is(err, ErrVehicleUnavailable) {
+			h.renderFormError(w, r, map[string]string{"vehicle_id": err.Error()})
+			return
+		}
 		h.renderFormError(w, r, map[string]string{"_form": shared.UserSafeMessage(err)})
 		return
 	}
@@ -212,7 +340,7 @@ func (h *Handler) showEditForm(w http.ResponseWriter, r *http.Request) {
 
 	order, err := h.service.GetByID(ctx, id)
 	if err != nil {
-		http.Error(w, "Not Found", http.StatusNotFound)
+		httpx.Error(w, r, "Not Found", http.StatusNotFound)
 		return
 	}
 
@@ -227,7 +355,7 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid form", http.StatusBadRequest)
 		return
 	}
 
@@ -244,7 +372,7 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if _, err := h.service.Update(ctx, id, req); err != nil {
-		h.logger.Error("update failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update failed", "error", err, "id", id)
 		h.renderFormError(w, r, map[string]string{"_form": shared.UserSafeMessage(err)})
 		return
 	}
@@ -252,6 +380,31 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 	h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), "Order updated")
 }
 
+// updateLogistics handles POST /delivery/orders/{id}/logistics
+func (h *Handler) updateLogistics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	req := UpdateLogisticsRequest{
+		DriverName:     r.FormValue("driver_name"),
+		VehicleNumber:  r.FormValue("vehicle_number"),
+		TrackingNumber: r.FormValue("tracking_number"),
+		UpdatedBy:      getUserID(r),
+	}
+	if _, err := h.service.UpdateLogistics(ctx, id, req); err != nil {
+		h.logger.ErrorContext(r.Context(), "update logistics failed", "error", err, "id", id)
+		h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), deliveryErrorMessage(err))
+		return
+	}
+
+	h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), "Logistics updated")
+}
+
 // confirm handles POST /delivery/orders/{id}/confirm
 func (h *Handler) confirm(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -259,7 +412,7 @@ func (h *Handler) confirm(w http.ResponseWriter, r *http.Request) {
 	userID := getUserID(r)
 
 	if _, err := h.service.Confirm(ctx, id, userID); err != nil {
-		h.logger.Error("confirm failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "confirm failed", "error", err, "id", id)
 		h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), shared.UserSafeMessage(err))
 		return
 	}
@@ -267,6 +420,35 @@ func (h *Handler) confirm(w http.ResponseWriter, r *http.Request) {
 	h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), "Order confirmed")
 }
 
+// bulkConfirm handles POST /delivery/orders/bulk-confirm
+func (h *Handler) bulkConfirm(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	var ids []int64
+	for _, raw := range r.PostForm["ids"] {
+		if id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	result, err := h.service.BulkConfirm(r.Context(), ids, getUserID(r))
+	if errors.Is(err, ErrBulkEmpty) || errors.Is(err, ErrBulkTooMany) {
+		h.redirect(w, r, "/delivery/orders", err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "bulk confirm failed", "error", err, "count", len(ids))
+		h.redirect(w, r, "/delivery/orders", shared.UserSafeMessage(err))
+		return
+	}
+
+	h.render(w, r, "pages/delivery/bulk_confirm_result.html", map[string]interface{}{
+		"Result": result,
+	})
+}
+
 // ship handles POST /delivery/orders/{id}/ship
 func (h *Handler) ship(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -279,7 +461,7 @@ func (h *Handler) ship(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if _, err := h.service.MarkInTransit(ctx, id, req); err != nil {
-		h.logger.Error("ship failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "ship failed", "error", err, "id", id)
 		h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), shared.UserSafeMessage(err))
 		return
 	}
@@ -292,6 +474,13 @@ func (h *Handler) complete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	userID := getUserID(r)
+	detailURL := "/delivery/orders/" + strconv.FormatInt(id, 10)
+
+	r.Body = http.MaxBytesReader(w, r.Body, 2*MaxSignatureBytes)
+	if err := r.ParseMultipartForm(2 * MaxSignatureBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		h.redirect(w, r, detailURL, ErrInvalidSignature.Error())
+		return
+	}
 
 	deliveredAt := time.Now()
 	if d := r.FormValue("delivered_at"); d != "" {
@@ -299,17 +488,82 @@ func (h *Handler) complete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req := MarkDeliveredRequest{
-		DeliveredAt: deliveredAt,
-		UpdatedBy:   userID,
+		DeliveredAt:  deliveredAt,
+		ReceiverName: r.FormValue("receiver_name"),
+		UpdatedBy:    userID,
+	}
+	if v := strings.TrimSpace(r.FormValue("pod_notes")); v != "" {
+		req.PODNotes = &v
+	}
+	signature, err := signatureFromForm(r)
+	if err != nil {
+		h.redirect(w, r, detailURL, ErrInvalidSignature.Error())
+		return
+	}
+	req.Signature = signature
+	for key, values := range r.Form {
+		raw, ok := strings.CutPrefix(key, "serials_")
+		if !ok {
+			continue
+		}
+		if lineID, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			if req.Serials == nil {
+				req.Serials = make(map[int64][]string)
+			}
+			req.Serials[lineID] = values
+		}
 	}
 
 	if _, err := h.service.MarkDelivered(ctx, id, req); err != nil {
-		h.logger.Error("complete failed", "error", err, "id", id)
-		h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), shared.UserSafeMessage(err))
+		h.logger.ErrorContext(r.Context(), "complete failed", "error", err, "id", id)
+		h.redirect(w, r, detailURL, deliveryErrorMessage(err))
 		return
 	}
 
-	h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), "Order completed")
+	h.redirect(w, r, detailURL, "Order completed")
+}
+
+// signatureFromForm converts the uploaded signature image into a data URL.
+func signatureFromForm(r *http.Request) (*string, error) {
+	file, header, err := r.FormFile("signature")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	if header.Size == 0 {
+		return nil, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(file, MaxSignatureBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSignatureBytes {
+		return nil, ErrInvalidSignature
+	}
+	contentType := http.DetectContentType(data)
+	if contentType != "image/png" && contentType != "image/jpeg" {
+		return nil, ErrInvalidSignature
+	}
+	url := "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	return &url, nil
+}
+
+func deliveryErrorMessage(err error) string {
+	for _, known := range []error{ErrCannotDeliver, ErrReceiverRequired, ErrReceiverTooLong, ErrPODNotesTooLong, ErrInvalidSignature, ErrCannotEditLogistics, ErrLogisticsTooLong} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	// Serial errors name the offending line, so show them in full.
+	for _, known := range []error{inventory.ErrSerialCount, inventory.ErrDuplicateSerial, inventory.ErrSerialUnavailable} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
 }
 
 // cancel handles POST /delivery/orders/{id}/cancel
@@ -327,7 +581,7 @@ func (h *Handler) cancel(w http.ResponseWriter, r *http.Request) {
 	req := CancelRequest{Reason: reason, CancelledBy: userID}
 
 	if _, err := h.service.Cancel(ctx, id, req); err != nil {
-		h.logger.Error("cancel failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "cancel failed", "error", err, "id", id)
 		h.redirect(w, r, "/delivery/orders/"+strconv.FormatInt(id, 10), shared.UserSafeMessage(err))
 		return
 	}
@@ -353,8 +607,8 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, tmpl string, da
 
 	td := view.TemplateData{CSRFToken: csrfToken, Flash: flash, Data: data}
 	if err := h.templates.Render(w, tmpl, td); err != nil {
-		h.logger.Error("render failed", "error", err, "template", tmpl)
-		http.Error(w, "Render Error", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render failed", "error", err, "template", tmpl)
+		httpx.Error(w, r, "Render Error", http.StatusInternalServerError)
 	}
 }
 
@@ -366,10 +620,24 @@ func (h *Handler) redirect(w http.ResponseWriter, r *http.Request, url, message
 }
 
 func (h *Handler) renderFormError(w http.ResponseWriter, r *http.Request, errors map[string]string) {
-	h.render(w, r, "pages/delivery/order_form.html", map[string]interface{}{
+	data := map[string]interface{}{
 		"Errors":   errors,
 		"FormData": r.Form,
-	})
+	}
+	h.addFleetOptions(r, data)
+	h.render(w, r, "pages/delivery/order_form.html", data)
+}
+
+// addFleetOptions offers the active drivers and vehicles on the order form.
+// The form still works with free text when the lists cannot be loaded.
+func (h *Handler) addFleetOptions(r *http.Request, data map[string]interface{}) {
+	drivers, vehicles, err := h.service.FleetOptions(r.Context())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "load delivery fleet options", "error", err)
+		return
+	}
+	data["Drivers"] = drivers
+	data["Vehicles"] = vehicles
 }
 
 func getUserID(r *http.Request) int64 {
@@ -383,7 +651,7 @@ func getCompanyID(r *http.Request) int64 {
 	if id, ok := r.Context().Value("company_id").(int64); ok {
 		return id
 	}
-	return 1 // Default to 1 (MAIN) if not set
+	return shared.ScopedCompanyID(r.Context(), 0, 1) // Fall back to 1 (MAIN) if unscoped
 }
 
 func parseLines(r *http.Request) ([]CreateLineReq, map[string]string) {
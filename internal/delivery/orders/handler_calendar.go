@@ -0,0 +1,54 @@
+package orders
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// calendar handles GET /delivery/orders/calendar. It returns JSON for the
+// dispatch calendar; from and to default to the current month.
+func (h *Handler) calendar(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+	var err error
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid Date", "from must be a date in YYYY-MM-DD format.")
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid Date", "to must be a date in YYYY-MM-DD format.")
+			return
+		}
+	}
+	req := CalendarRequest{CompanyID: getCompanyID(r), From: from, To: to}
+	if v := q.Get("warehouse_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid Warehouse", "warehouse_id must be a positive number.")
+			return
+		}
+		req.WarehouseID = id
+	}
+
+	cal, err := h.service.Calendar(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCalendarRange) {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid Range", err.Error())
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "delivery calendar", "error", err)
+		httpx.Problem(w, http.StatusInternalServerError, "Calendar Failed", shared.UserSafeMessage(err))
+		return
+	}
+	httpx.JSON(w, http.StatusOK, cal)
+}
@@ -23,7 +23,7 @@ func NewInvoicingAdapter(pool *pgxpool.Pool) *InvoicingAdapter {
 func (a *InvoicingAdapter) GetDeliveryOrderForInvoicing(ctx context.Context, id int64) (*ar.DeliveryOrderInfo, error) {
 	const headerSQL = `
 		SELECT d.id, d.doc_number, d.customer_id, c.name, d.sales_order_id,
-		       d.warehouse_id, d.status, so.currency
+		       d.company_id, d.warehouse_id, d.status, so.currency
 		FROM delivery_orders d
 		INNER JOIN customers c ON c.id = d.customer_id
 		INNER JOIN sales_orders so ON so.id = d.sales_order_id
@@ -38,6 +38,7 @@ func (a *InvoicingAdapter) GetDeliveryOrderForInvoicing(ctx context.Context, id
 		&info.CustomerID,
 		&info.CustomerName,
 		&info.SalesOrderID,
+		&info.CompanyID,
 		&info.WarehouseID,
 		&status,
 		&info.Currency,
@@ -54,7 +55,11 @@ func (a *InvoicingAdapter) GetDeliveryOrderForInvoicing(ctx context.Context, id
 
 	const lineSQL = `
 		SELECT dol.id, dol.product_id, p.name, dol.quantity_delivered, dol.unit_price,
-		       sol.discount_percent, sol.tax_percent
+		       -- Effective percent, so the order's document discount carries over.
+		       CASE WHEN sol.quantity * sol.unit_price > 0
+		            THEN sol.discount_amount / (sol.quantity * sol.unit_price) * 100
+		            ELSE sol.discount_percent END,
+		       sol.tax_percent
 		FROM delivery_order_lines dol
 		INNER JOIN products p ON p.id = dol.product_id
 		INNER JOIN sales_order_lines sol ON sol.id = dol.sales_order_line_id
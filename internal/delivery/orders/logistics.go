@@ -0,0 +1,100 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// AuditPort records delivery order changes.
+type AuditPort interface {
+	Record(ctx context.Context, log shared.AuditLog) error
+}
+
+// SetAuditor enables audit entries for logistics changes.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
+}
+
+// UpdateLogistics changes the driver, vehicle and tracking number of a
+// delivery order that has not been delivered yet. Unlike Update it never
+// touches the delivery date, notes or lines, so it is allowed after confirm.
+func (s *Service) UpdateLogistics(ctx context.Context, id int64, req UpdateLogisticsRequest) (*DeliveryOrder, error) {
+	if err := ValidateUpdateLogisticsRequest(req); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get delivery order: %w", err)
+	}
+	if !existing.Status.CanEditLogistics() {
+		return nil, fmt.Errorf("%w: %s", ErrCannotEditLogistics, existing.Status)
+	}
+
+	updates := make(map[string]interface{})
+	changed := make(map[string]any)
+	for _, field := range []struct {
+		column  string
+		current *string
+		value   string
+	}{
+		{"driver_name", existing.DriverName, req.DriverName},
+		{"vehicle_number", existing.VehicleNumber, req.VehicleNumber},
+		{"tracking_number", existing.TrackingNumber, req.TrackingNumber},
+	} {
+		value := optionalString(field.value)
+		if derefString(field.current) == derefString(value) {
+			continue
+		}
+		updates[field.column] = value
+		changed[field.column] = map[string]any{"from": derefString(field.current), "to": derefString(value)}
+	}
+	if len(updates) == 0 {
+		return existing, nil
+	}
+
+	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		return tx.UpdateDeliveryOrder(ctx, id, updates)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update logistics: %w", err)
+	}
+	updated, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.audit != nil {
+		actor := req.UpdatedBy
+		if actor == 0 {
+			actor = shared.AuditActorFromContext(ctx)
+		}
+		_ = s.audit.Record(ctx, shared.AuditLog{
+			ActorID:  actor,
+			Action:   "delivery_order.logistics_update",
+			Entity:   "delivery_orders",
+			EntityID: strconv.FormatInt(id, 10),
+			Meta:     map[string]any{"number": updated.DocNumber, "status": string(updated.Status), "changes": changed},
+			Before:   existing,
+			After:    updated,
+		})
+	}
+	return updated, nil
+}
+
+func optionalString(v string) *string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func derefString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
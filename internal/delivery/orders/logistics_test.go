@@ -0,0 +1,103 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type fakeLogisticsRepo struct {
+	Repository
+	TxRepository
+	order   DeliveryOrder
+	updates map[string]interface{}
+}
+
+func (f *fakeLogisticsRepo) GetByID(context.Context, int64) (*DeliveryOrder, error) {
+	order := f.order
+	return &order, nil
+}
+
+func (f *fakeLogisticsRepo) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeLogisticsRepo) UpdateDeliveryOrder(_ context.Context, _ int64, updates map[string]interface{}) error {
+	f.updates = updates
+	for column, value := range updates {
+		v := value.(*string)
+		switch column {
+		case "driver_name":
+			f.order.DriverName = v
+		case "vehicle_number":
+			f.order.VehicleNumber = v
+		case "tracking_number":
+			f.order.TrackingNumber = v
+		}
+	}
+	return nil
+}
+
+type recordingAudit struct {
+	logs []shared.AuditLog
+}
+
+func (r *recordingAudit) Record(_ context.Context, log shared.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func TestUpdateLogisticsOnConfirmedOrderWritesOnlyChangedFields(t *testing.T) {
+	driver := "Budi"
+	repo := &fakeLogisticsRepo{order: DeliveryOrder{ID: 5, DocNumber: "DO-202610-00005", Status: StatusConfirmed, DriverName: &driver}}
+	audit := &recordingAudit{}
+	svc := NewService(repo)
+	svc.SetAuditor(audit)
+
+	updated, err := svc.UpdateLogistics(context.Background(), 5, UpdateLogisticsRequest{
+		DriverName:     "Budi",
+		VehicleNumber:  " B 1234 XY ",
+		TrackingNumber: "",
+		UpdatedBy:      9,
+	})
+	if err != nil {
+		t.Fatalf("UpdateLogistics: %v", err)
+	}
+	if len(repo.updates) != 1 || updated.VehicleNumber == nil || *updated.VehicleNumber != "B 1234 XY" {
+		t.Fatalf("expected only the vehicle number to change, got %v", repo.updates)
+	}
+	for _, column := range []string{"delivery_date", "notes", "status"} {
+		if _, ok := repo.updates[column]; ok {
+			t.Fatalf("logistics update must not touch %s", column)
+		}
+	}
+	if len(audit.logs) != 1 || audit.logs[0].Action != "delivery_order.logistics_update" || audit.logs[0].ActorID != 9 {
+		t.Fatalf("expected one audit entry, got %+v", audit.logs)
+	}
+
+	// Submitting the same values again is a no-op without an audit entry.
+	if _, err := svc.UpdateLogistics(context.Background(), 5, UpdateLogisticsRequest{DriverName: "Budi", VehicleNumber: "B 1234 XY"}); err != nil {
+		t.Fatalf("UpdateLogistics: %v", err)
+	}
+	if len(audit.logs) != 1 {
+		t.Fatalf("unchanged logistics should not be audited, got %d entries", len(audit.logs))
+	}
+}
+
+func TestUpdateLogisticsRejectsDeliveredOrder(t *testing.T) {
+	repo := &fakeLogisticsRepo{order: DeliveryOrder{ID: 5, Status: StatusDelivered}}
+	svc := NewService(repo)
+
+	_, err := svc.UpdateLogistics(context.Background(), 5, UpdateLogisticsRequest{TrackingNumber: "JNE-1"})
+	if !errors.Is(err, ErrCannotEditLogistics) {
+		t.Fatalf("expected ErrCannotEditLogistics, got %v", err)
+	}
+	if repo.updates != nil {
+		t.Fatalf("delivered order must not be updated, got %v", repo.updates)
+	}
+	if StatusInTransit.CanEdit() || !StatusInTransit.CanEditLogistics() {
+		t.Fatal("in-transit orders allow logistics edits only")
+	}
+}
@@ -1,8 +1,14 @@
 package orders
 
+import (
+	"github.com/odyssey-erp/odyssey-erp/internal/delivery/export"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/i18n"
+)
+
 // Mapper functions for converting between layers:
 // CreateRequest → DeliveryOrder (domain)
 // WithDetails → DetailResponse (dto)
+// WithDetails → PackingListPayload (packing slip / proof of delivery)
 
 // ToDeliveryOrder maps CreateRequest to domain DeliveryOrder.
 func (r CreateRequest) ToDeliveryOrder(docNumber string, customerID, createdBy int64) DeliveryOrder {
@@ -45,6 +51,40 @@ func ToDetailResponse(wd *WithDetails, lines []LineWithDetails) DetailResponse {
 	}
 }
 
+// ToPackingList maps a delivery order and its lines to the packing slip
+// payload, including the proof of delivery once the order is delivered. It is
+// rendered in the customer's document language.
+func ToPackingList(wd *WithDetails, lines []LineWithDetails) export.PackingListPayload {
+	payload := export.PackingListPayload{
+		Locale:            i18n.For(wd.CustomerLanguage),
+		DocNumber:         wd.DocNumber,
+		SalesOrderNumber:  wd.SalesOrderNumber,
+		CustomerName:      wd.CustomerName,
+		PlannedDate:       wd.DeliveryDate,
+		Status:            string(wd.Status),
+		WarehouseName:     wd.WarehouseName,
+		TrackingNumber:    wd.TrackingNumber,
+		ShippingNotes:     wd.Notes,
+		ReceivedBy:        wd.ReceivedBy,
+		ReceivedAt:        wd.DeliveredAt,
+		ReceiverSignature: wd.PODSignature,
+		DeliveryNotes:     wd.PODNotes,
+		CreatedBy:         wd.CreatedByName,
+		CreatedAt:         wd.CreatedAt,
+	}
+	for i, line := range lines {
+		payload.Lines = append(payload.Lines, export.PackingListLine{
+			LineNumber:  i + 1,
+			ProductCode: line.ProductCode,
+			ProductName: line.ProductName,
+			Quantity:    line.QuantityToDeliver,
+			UOM:         line.UOM,
+			Notes:       line.Notes,
+		})
+	}
+	return payload
+}
+
 // ToListResponse maps list results to ListResponse DTO.
 func ToListResponse(orders []WithDetails, total, limit, offset int) ListResponse {
 	return ListResponse{
@@ -3,6 +3,8 @@ package orders
 
 import (
 	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 // Status represents the lifecycle of a delivery order.
@@ -16,6 +18,15 @@ const (
 	StatusCancelled Status = "CANCELLED"  // Cancelled delivery
 )
 
+// Transitions lists the allowed delivery order status changes. CONFIRMED
+// orders may go straight to DELIVERED for direct deliveries that skip the
+// in-transit step; DELIVERED and CANCELLED are terminal.
+var Transitions = shared.NewStateMachine("delivery order", map[Status][]Status{
+	StatusDraft:     {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusInTransit, StatusDelivered, StatusCancelled},
+	StatusInTransit: {StatusDelivered},
+})
+
 // IsValid checks if the status is valid.
 func (s Status) IsValid() bool {
 	switch s {
@@ -31,14 +42,30 @@ func (s Status) CanEdit() bool {
 	return s == StatusDraft
 }
 
+// CanEditLogistics checks if driver, vehicle and tracking details can still
+// change. Confirmed and in-transit orders allow it; their lines are fixed.
+func (s Status) CanEditLogistics() bool {
+	return s == StatusDraft || s == StatusConfirmed || s == StatusInTransit
+}
+
 // CanConfirm checks if DO can be confirmed.
 func (s Status) CanConfirm() bool {
-	return s == StatusDraft
+	return Transitions.Can(s, StatusConfirmed)
+}
+
+// CanShip checks if DO can be marked in transit.
+func (s Status) CanShip() bool {
+	return Transitions.Can(s, StatusInTransit)
+}
+
+// CanDeliver checks if DO can be marked delivered.
+func (s Status) CanDeliver() bool {
+	return Transitions.Can(s, StatusDelivered)
 }
 
 // CanCancel checks if DO can be cancelled.
 func (s Status) CanCancel() bool {
-	return s == StatusDraft || s == StatusConfirmed
+	return Transitions.Can(s, StatusCancelled)
 }
 
 // DeliveryOrder represents a delivery from warehouse to customer.
@@ -61,6 +88,9 @@ type DeliveryOrder struct {
 	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	ReceivedBy     *string    `json:"received_by,omitempty" db:"pod_receiver_name"`
+	PODSignature   *string    `json:"pod_signature,omitempty" db:"pod_signature"`
+	PODNotes       *string    `json:"pod_notes,omitempty" db:"pod_notes"`
 	Lines          []Line     `json:"lines,omitempty" db:"-"`
 }
 
@@ -86,6 +116,7 @@ type WithDetails struct {
 	SalesOrderNumber string  `json:"sales_order_number" db:"sales_order_number"`
 	WarehouseName    string  `json:"warehouse_name" db:"warehouse_name"`
 	CustomerName     string  `json:"customer_name" db:"customer_name"`
+	CustomerLanguage string  `json:"customer_language,omitempty" db:"customer_language"`
 	CreatedByName    string  `json:"created_by_name" db:"created_by_name"`
 	ConfirmedByName  *string `json:"confirmed_by_name,omitempty" db:"confirmed_by_name"`
 	LineCount        int     `json:"line_count" db:"line_count"`
@@ -100,6 +131,12 @@ type LineWithDetails struct {
 	SOLineQuantity     float64 `json:"so_line_quantity" db:"so_line_quantity"`
 	SOLineDelivered    float64 `json:"so_line_delivered" db:"so_line_delivered"`
 	RemainingToDeliver float64 `json:"remaining_to_deliver" db:"remaining_to_deliver"`
+	// TrackSerial marks lines whose product needs serial numbers on delivery.
+	TrackSerial bool `json:"track_serial"`
+	// AvailableSerials are in stock at the warehouse and can be picked.
+	AvailableSerials []string `json:"available_serials,omitempty"`
+	// Serials were shipped on this line.
+	Serials []string `json:"serials,omitempty"`
 }
 
 // DeliverableSOLine represents a sales order line that can be delivered.
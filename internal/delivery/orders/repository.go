@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -29,7 +30,29 @@ type Repository interface {
 	// Helpers
 	GenerateDocNumber(ctx context.Context, companyID int64, date time.Time) (string, error)
 	GetSalesOrderDetails(ctx context.Context, salesOrderID int64) (*SalesOrderInfo, error)
-	CheckWarehouseExists(ctx context.Context, warehouseID int64) (bool, error)
+	GetWarehouseCompanyID(ctx context.Context, warehouseID int64) (int64, error)
+	GetDefaultWarehouse(ctx context.Context, companyID int64, branchID *int64) (*int64, error)
+	GetAvailableStock(ctx context.Context, warehouseID, productID int64) (float64, error)
+	CommittedStock(ctx context.Context, warehouseID int64, productIDs []int64) ([]CommittedQuantity, error)
+	ReservedStock(ctx context.Context, warehouseID int64, productIDs, excludeSalesOrderIDs []int64) (map[int64]ReservedQuantity, error)
+
+	// Back-orders
+	GetBackorder(ctx context.Context, id int64) (*Backorder, error)
+	ListBackorders(ctx context.Context, req BackorderListRequest) ([]BackorderWithDetails, int, error)
+
+	// Serial numbers
+	SerialTrackedProducts(ctx context.Context, productIDs []int64) (map[int64]bool, error)
+	AvailableSerials(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64][]string, error)
+	DeliveredSerials(ctx context.Context, deliveryOrderID int64) (map[int64][]string, error)
+
+	// Scheduling
+	DeliveryCalendar(ctx context.Context, req CalendarRequest) ([]CalendarBucket, error)
+
+	// Driver and vehicle pool
+	ActiveDrivers(ctx context.Context) ([]DriverOption, error)
+	ActiveVehicles(ctx context.Context) ([]VehicleOption, error)
+	GetActiveDriver(ctx context.Context, id int64) (*DriverOption, error)
+	GetActiveVehicle(ctx context.Context, id int64) (*VehicleOption, error)
 }
 
 // TxRepository exposes transactional write operations.
@@ -38,8 +61,14 @@ type TxRepository interface {
 	InsertLine(ctx context.Context, line Line) (int64, error)
 	UpdateDeliveryOrder(ctx context.Context, id int64, updates map[string]interface{}) error
 	UpdateStatus(ctx context.Context, id int64, status Status, updates map[string]interface{}) error
+	ConfirmDraft(ctx context.Context, id, confirmedBy int64, confirmedAt time.Time) (bool, error)
 	DeleteLines(ctx context.Context, deliveryOrderID int64) error
 	UpdateLineQuantity(ctx context.Context, lineID int64, quantityDelivered float64) error
+	InsertBackorder(ctx context.Context, bo Backorder) (int64, error)
+	FulfillBackorder(ctx context.Context, id, deliveryOrderID int64) error
+	CancelBackordersForDelivery(ctx context.Context, deliveryOrderID int64) error
+	DeliverSerials(ctx context.Context, warehouseID int64, line Line, serials []string, deliveredAt time.Time) error
+	ConsumeReservation(ctx context.Context, salesOrderLineID int64, quantity float64) error
 }
 
 // SalesOrderInfo holds basic sales order data for validation.
@@ -97,6 +126,9 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*DeliveryOrder, err
 		}
 		return nil, err
 	}
+	if !shared.CompanyAllowed(ctx, row.CompanyID) {
+		return nil, ErrNotFound
+	}
 
 	do := &DeliveryOrder{
 		ID:             row.ID,
@@ -117,6 +149,9 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*DeliveryOrder, err
 		DeliveredAt:    timeToPointer(row.DeliveredAt),
 		CreatedAt:      row.CreatedAt.Time,
 		UpdatedAt:      row.UpdatedAt.Time,
+		ReceivedBy:     textToPointer(row.PodReceiverName),
+		PODSignature:   textToPointer(row.PodSignature),
+		PODNotes:       textToPointer(row.PodNotes),
 	}
 
 	linesRows, err := r.queries.GetLines(ctx, id)
@@ -178,6 +213,9 @@ func (r *repository) GetByDocNumber(ctx context.Context, companyID int64, docNum
 		DeliveredAt:    timeToPointer(row.DeliveredAt),
 		CreatedAt:      row.CreatedAt.Time,
 		UpdatedAt:      row.UpdatedAt.Time,
+		ReceivedBy:     textToPointer(row.PodReceiverName),
+		PODSignature:   textToPointer(row.PodSignature),
+		PODNotes:       textToPointer(row.PodNotes),
 	}
 
 	linesRows, err := r.queries.GetLines(ctx, do.ID)
@@ -237,10 +275,14 @@ func (r *repository) GetWithDetails(ctx context.Context, id int64) (*WithDetails
 			DeliveredAt:    timeToPointer(row.DeliveredAt),
 			CreatedAt:      row.CreatedAt.Time,
 			UpdatedAt:      row.UpdatedAt.Time,
+			ReceivedBy:     textToPointer(row.PodReceiverName),
+			PODSignature:   textToPointer(row.PodSignature),
+			PODNotes:       textToPointer(row.PodNotes),
 		},
 		SalesOrderNumber: row.SalesOrderNumber,
 		WarehouseName:    row.WarehouseName,
 		CustomerName:     row.CustomerName,
+		CustomerLanguage: row.CustomerLanguage,
 		CreatedByName:    row.CreatedByName,
 		ConfirmedByName:  textToPointer(row.ConfirmedByName),
 		LineCount:        int(row.LineCount),
@@ -406,14 +448,14 @@ func (r *repository) List(ctx context.Context, req ListRequest) ([]WithDetails,
 	for rows.Next() {
 		var wd WithDetails
 		var (
-			confirmedBy pgtype.Int8
-			confirmedAt pgtype.Timestamptz
-			deliveredAt pgtype.Timestamptz
+			confirmedBy                                      pgtype.Int8
+			confirmedAt                                      pgtype.Timestamptz
+			deliveredAt                                      pgtype.Timestamptz
 			driverName, vehicleNumber, trackingNumber, notes pgtype.Text
-			confirmedByName pgtype.Text
-			totalQty pgtype.Numeric
+			confirmedByName                                  pgtype.Text
+			totalQty                                         pgtype.Numeric
 		)
-		
+
 		err := rows.Scan(
 			&wd.ID, &wd.DocNumber, &wd.CompanyID, &wd.SalesOrderID, &wd.WarehouseID,
 			&wd.CustomerID, &wd.DeliveryDate, &wd.Status, &driverName,
@@ -425,7 +467,7 @@ func (r *repository) List(ctx context.Context, req ListRequest) ([]WithDetails,
 		if err != nil {
 			return nil, 0, err
 		}
-		
+
 		wd.DriverName = textToPointer(driverName)
 		wd.VehicleNumber = textToPointer(vehicleNumber)
 		wd.TrackingNumber = textToPointer(trackingNumber)
@@ -435,7 +477,7 @@ func (r *repository) List(ctx context.Context, req ListRequest) ([]WithDetails,
 		wd.DeliveredAt = timeToPointer(deliveredAt)
 		wd.ConfirmedByName = textToPointer(confirmedByName)
 		wd.TotalQuantity = numericToFloat(totalQty)
-		
+
 		results = append(results, wd)
 	}
 
@@ -494,9 +536,30 @@ func (r *repository) GetSalesOrderDetails(ctx context.Context, salesOrderID int6
 	}, nil
 }
 
-// CheckWarehouseExists validates warehouse existence.
-func (r *repository) CheckWarehouseExists(ctx context.Context, warehouseID int64) (bool, error) {
-	return r.queries.CheckWarehouseExists(ctx, warehouseID)
+// GetWarehouseCompanyID returns the company owning a warehouse through its branch.
+func (r *repository) GetWarehouseCompanyID(ctx context.Context, warehouseID int64) (int64, error) {
+	companyID, err := r.queries.GetWarehouseCompanyID(ctx, warehouseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrWarehouseNotFound
+	}
+	return companyID, err
+}
+
+// GetDefaultWarehouse returns the branch default delivery warehouse, or nil
+// when none is configured.
+func (r *repository) GetDefaultWarehouse(ctx context.Context, companyID int64, branchID *int64) (*int64, error) {
+	params := sqlc.GetDefaultDeliveryWarehouseParams{CompanyID: companyID}
+	if branchID != nil {
+		params.BranchID = pgtype.Int8{Int64: *branchID, Valid: true}
+	}
+	warehouseID, err := r.queries.GetDefaultDeliveryWarehouse(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return int8ToPointer(warehouseID), nil
 }
 
 func numericToFloat(n pgtype.Numeric) float64 {
@@ -0,0 +1,204 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// GetAvailableStock returns on-hand quantity for a product in a warehouse.
+func (r *repository) GetAvailableStock(ctx context.Context, warehouseID, productID int64) (float64, error) {
+	const query = `
+		SELECT COALESCE(qty, 0)
+		FROM inventory_balances
+		WHERE warehouse_id = $1 AND product_id = $2
+	`
+	var qty pgtype.Numeric
+	if err := r.pool.QueryRow(ctx, query, warehouseID, productID).Scan(&qty); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return numericToFloat(qty), nil
+}
+
+// GetBackorder retrieves a back-order by ID.
+func (r *repository) GetBackorder(ctx context.Context, id int64) (*Backorder, error) {
+	const query = `
+		SELECT id, company_id, sales_order_id, sales_order_line_id, delivery_order_id,
+		       product_id, warehouse_id, quantity_requested, quantity_short, status,
+		       fulfilled_delivery_order_id, fulfilled_at, created_by, created_at, updated_at
+		FROM delivery_backorders
+		WHERE id = $1
+	`
+	var (
+		bo                   Backorder
+		requested, short     pgtype.Numeric
+		fulfilledDO          pgtype.Int8
+		fulfilledAt          pgtype.Timestamptz
+		createdAt, updatedAt pgtype.Timestamptz
+	)
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&bo.ID, &bo.CompanyID, &bo.SalesOrderID, &bo.SalesOrderLineID, &bo.DeliveryOrderID,
+		&bo.ProductID, &bo.WarehouseID, &requested, &short, &bo.Status,
+		&fulfilledDO, &fulfilledAt, &bo.CreatedBy, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBackorderNotFound
+		}
+		return nil, err
+	}
+	if !shared.CompanyAllowed(ctx, bo.CompanyID) {
+		return nil, ErrBackorderNotFound
+	}
+	bo.QuantityRequested = numericToFloat(requested)
+	bo.QuantityShort = numericToFloat(short)
+	bo.FulfilledDeliveryOrderID = int8ToPointer(fulfilledDO)
+	bo.FulfilledAt = timeToPointer(fulfilledAt)
+	bo.CreatedAt = createdAt.Time
+	bo.UpdatedAt = updatedAt.Time
+	return &bo, nil
+}
+
+// ListBackorders retrieves back-orders with filters for the report.
+func (r *repository) ListBackorders(ctx context.Context, req BackorderListRequest) ([]BackorderWithDetails, int, error) {
+	conditions := []string{"bo.company_id = $1"}
+	args := []interface{}{req.CompanyID}
+	argPos := 2
+
+	if req.SalesOrderID != nil {
+		conditions = append(conditions, fmt.Sprintf("bo.sales_order_id = $%d", argPos))
+		args = append(args, *req.SalesOrderID)
+		argPos++
+	}
+	if req.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("bo.status = $%d", argPos))
+		args = append(args, *req.Status)
+		argPos++
+	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM delivery_backorders bo %s`, whereClause)
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	query := fmt.Sprintf(`
+		SELECT bo.id, bo.company_id, bo.sales_order_id, bo.sales_order_line_id, bo.delivery_order_id,
+		       bo.product_id, bo.warehouse_id, bo.quantity_requested, bo.quantity_short, bo.status,
+		       bo.fulfilled_delivery_order_id, bo.fulfilled_at, bo.created_by, bo.created_at, bo.updated_at,
+		       dor.doc_number AS delivery_order_number,
+		       so.doc_number AS sales_order_number,
+		       c.name AS customer_name,
+		       w.name AS warehouse_name,
+		       p.sku AS product_code,
+		       p.name AS product_name,
+		       fdo.doc_number AS fulfilled_delivery_order_number
+		FROM delivery_backorders bo
+		INNER JOIN delivery_orders dor ON dor.id = bo.delivery_order_id
+		INNER JOIN sales_orders so ON so.id = bo.sales_order_id
+		INNER JOIN customers c ON c.id = so.customer_id
+		INNER JOIN warehouses w ON w.id = bo.warehouse_id
+		INNER JOIN products p ON p.id = bo.product_id
+		LEFT JOIN delivery_orders fdo ON fdo.id = bo.fulfilled_delivery_order_id
+		%s
+		ORDER BY bo.created_at DESC, bo.id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+	args = append(args, limit, req.Offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var results []BackorderWithDetails
+	for rows.Next() {
+		var (
+			bo                   BackorderWithDetails
+			requested, short     pgtype.Numeric
+			fulfilledDO          pgtype.Int8
+			fulfilledAt          pgtype.Timestamptz
+			createdAt, updatedAt pgtype.Timestamptz
+			fulfilledNumber      pgtype.Text
+		)
+		if err := rows.Scan(
+			&bo.ID, &bo.CompanyID, &bo.SalesOrderID, &bo.SalesOrderLineID, &bo.DeliveryOrderID,
+			&bo.ProductID, &bo.WarehouseID, &requested, &short, &bo.Status,
+			&fulfilledDO, &fulfilledAt, &bo.CreatedBy, &createdAt, &updatedAt,
+			&bo.DeliveryOrderNumber, &bo.SalesOrderNumber, &bo.CustomerName, &bo.WarehouseName,
+			&bo.ProductCode, &bo.ProductName, &fulfilledNumber,
+		); err != nil {
+			return nil, 0, err
+		}
+		bo.QuantityRequested = numericToFloat(requested)
+		bo.QuantityShort = numericToFloat(short)
+		bo.FulfilledDeliveryOrderID = int8ToPointer(fulfilledDO)
+		bo.FulfilledAt = timeToPointer(fulfilledAt)
+		bo.CreatedAt = createdAt.Time
+		bo.UpdatedAt = updatedAt.Time
+		bo.FulfilledDeliveryOrderNumber = textToPointer(fulfilledNumber)
+		results = append(results, bo)
+	}
+	return results, total, rows.Err()
+}
+
+// InsertBackorder records a shortfall against a delivery order.
+func (t *txRepository) InsertBackorder(ctx context.Context, bo Backorder) (int64, error) {
+	const query = `
+		INSERT INTO delivery_backorders (
+		    company_id, sales_order_id, sales_order_line_id, delivery_order_id,
+		    product_id, warehouse_id, quantity_requested, quantity_short, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+	var id int64
+	err := t.tx.QueryRow(ctx, query,
+		bo.CompanyID, bo.SalesOrderID, bo.SalesOrderLineID, bo.DeliveryOrderID,
+		bo.ProductID, bo.WarehouseID, floatToNumeric(bo.QuantityRequested), floatToNumeric(bo.QuantityShort),
+		string(BackorderOpen), bo.CreatedBy,
+	).Scan(&id)
+	return id, err
+}
+
+// FulfillBackorder links an open back-order to the DO that ships it.
+func (t *txRepository) FulfillBackorder(ctx context.Context, id, deliveryOrderID int64) error {
+	const query = `
+		UPDATE delivery_backorders
+		SET status = 'FULFILLED', fulfilled_delivery_order_id = $2, fulfilled_at = NOW()
+		WHERE id = $1 AND status = 'OPEN'
+	`
+	cmdTag, err := t.tx.Exec(ctx, query, id, deliveryOrderID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrBackorderNotOpen
+	}
+	return nil
+}
+
+// CancelBackordersForDelivery drops open back-orders raised by a delivery order.
+func (t *txRepository) CancelBackordersForDelivery(ctx context.Context, deliveryOrderID int64) error {
+	const query = `
+		UPDATE delivery_backorders
+		SET status = 'CANCELLED'
+		WHERE delivery_order_id = $1 AND status = 'OPEN'
+	`
+	_, err := t.tx.Exec(ctx, query, deliveryOrderID)
+	return err
+}
@@ -0,0 +1,100 @@
+package orders
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CommittedStock sums the quantities that confirmed and in-transit deliveries
+// from the warehouse still have to ship, per product and unit. Stock only
+// leaves inventory when a delivery is marked delivered.
+func (r *repository) CommittedStock(ctx context.Context, warehouseID int64, productIDs []int64) ([]CommittedQuantity, error) {
+	const query = `
+		SELECT l.product_id, l.uom, SUM(l.quantity_to_deliver)
+		FROM delivery_order_lines l
+		JOIN delivery_orders d ON d.id = l.delivery_order_id
+		WHERE d.warehouse_id = $1
+		  AND l.product_id = ANY($2)
+		  AND d.status IN ('CONFIRMED', 'IN_TRANSIT')
+		GROUP BY l.product_id, l.uom
+	`
+	rows, err := r.pool.Query(ctx, query, warehouseID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []CommittedQuantity
+	for rows.Next() {
+		var c CommittedQuantity
+		var qty pgtype.Numeric
+		if err := rows.Scan(&c.ProductID, &c.UOM, &qty); err != nil {
+			return nil, err
+		}
+		c.Quantity = numericToFloat(qty)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// ConfirmDraft confirms the delivery order if it is still DRAFT and reports
+// whether it did.
+func (t *txRepository) ConfirmDraft(ctx context.Context, id, confirmedBy int64, confirmedAt time.Time) (bool, error) {
+	tag, err := t.tx.Exec(ctx, `
+		UPDATE delivery_orders
+		SET status = 'CONFIRMED', confirmed_by = $2, confirmed_at = $3, updated_at = NOW()
+		WHERE id = $1 AND status = 'DRAFT'
+	`, id, confirmedBy, confirmedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReservedStock sums the open stock reservations in the warehouse per
+// product, in base units, skipping the reservations of the given sales
+// orders.
+func (r *repository) ReservedStock(ctx context.Context, warehouseID int64, productIDs, excludeSalesOrderIDs []int64) (map[int64]ReservedQuantity, error) {
+	const query = `
+		SELECT product_id, SUM(open_base)::float8, SUM(GREATEST(open_base - committed_base, 0))::float8
+		FROM stock_reservation_open
+		WHERE warehouse_id = $1
+		  AND product_id = ANY($2)
+		  AND NOT (sales_order_id = ANY($3::bigint[]))
+		GROUP BY product_id
+	`
+	if excludeSalesOrderIDs == nil {
+		excludeSalesOrderIDs = []int64{}
+	}
+	rows, err := r.pool.Query(ctx, query, warehouseID, productIDs, excludeSalesOrderIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[int64]ReservedQuantity)
+	for rows.Next() {
+		var productID int64
+		var q ReservedQuantity
+		if err := rows.Scan(&productID, &q.Open, &q.Uncommitted); err != nil {
+			return nil, err
+		}
+		out[productID] = q
+	}
+	return out, rows.Err()
+}
+
+// ConsumeReservation turns delivered quantity of the sales order line into
+// fulfilled reservation, closing the reservation once it is fully delivered.
+// Lines without an active reservation are left alone.
+func (t *txRepository) ConsumeReservation(ctx context.Context, salesOrderLineID int64, quantity float64) error {
+	_, err := t.tx.Exec(ctx, `
+		UPDATE stock_reservations
+		SET delivered_quantity = LEAST(quantity, delivered_quantity + $2),
+		    status = CASE WHEN delivered_quantity + $2 >= quantity THEN 'FULFILLED' ELSE status END,
+		    closed_at = CASE WHEN delivered_quantity + $2 >= quantity THEN NOW() ELSE closed_at END,
+		    updated_at = NOW()
+		WHERE sales_order_line_id = $1 AND status = 'ACTIVE'
+	`, salesOrderLineID, quantity)
+	return err
+}
@@ -0,0 +1,43 @@
+package orders
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DeliveryCalendar counts the non-cancelled delivery orders and their
+// quantities per delivery date.
+func (r *repository) DeliveryCalendar(ctx context.Context, req CalendarRequest) ([]CalendarBucket, error) {
+	const query = `
+		SELECT d.delivery_date, COUNT(*),
+		       COALESCE(SUM((SELECT SUM(l.quantity_to_deliver) FROM delivery_order_lines l WHERE l.delivery_order_id = d.id)), 0)
+		FROM delivery_orders d
+		WHERE d.company_id = $1
+		  AND ($2::bigint = 0 OR d.warehouse_id = $2)
+		  AND d.delivery_date BETWEEN $3 AND $4
+		  AND d.status <> 'CANCELLED'
+		GROUP BY d.delivery_date
+		ORDER BY d.delivery_date
+	`
+	rows, err := r.pool.Query(ctx, query, req.CompanyID, req.WarehouseID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []CalendarBucket
+	for rows.Next() {
+		var (
+			b    CalendarBucket
+			date pgtype.Date
+			qty  pgtype.Numeric
+		)
+		if err := rows.Scan(&date, &b.Orders, &qty); err != nil {
+			return nil, err
+		}
+		b.Date = date.Time
+		b.Quantity = numericToFloat(qty)
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,71 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ActiveDrivers lists the active master drivers by name.
+func (r *repository) ActiveDrivers(ctx context.Context) ([]DriverOption, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, name FROM delivery_drivers WHERE is_active ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []DriverOption
+	for rows.Next() {
+		var d DriverOption
+		if err := rows.Scan(&d.ID, &d.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ActiveVehicles lists the active master vehicles by plate number.
+func (r *repository) ActiveVehicles(ctx context.Context) ([]VehicleOption, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, plate_number, description FROM delivery_vehicles WHERE is_active ORDER BY plate_number`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []VehicleOption
+	for rows.Next() {
+		var v VehicleOption
+		if err := rows.Scan(&v.ID, &v.PlateNumber, &v.Description); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// GetActiveDriver returns the driver, or nil when it is unknown or inactive.
+func (r *repository) GetActiveDriver(ctx context.Context, id int64) (*DriverOption, error) {
+	d := DriverOption{ID: id}
+	err := r.pool.QueryRow(ctx, `SELECT name FROM delivery_drivers WHERE id = $1 AND is_active`, id).Scan(&d.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// GetActiveVehicle returns the vehicle, or nil when it is unknown or inactive.
+func (r *repository) GetActiveVehicle(ctx context.Context, id int64) (*VehicleOption, error) {
+	v := VehicleOption{ID: id}
+	err := r.pool.QueryRow(ctx, `SELECT plate_number, description FROM delivery_vehicles WHERE id = $1 AND is_active`, id).
+		Scan(&v.PlateNumber, &v.Description)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
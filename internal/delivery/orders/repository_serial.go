@@ -0,0 +1,86 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+)
+
+// SerialTrackedProducts reports which of the products require serial numbers.
+func (r *repository) SerialTrackedProducts(ctx context.Context, productIDs []int64) (map[int64]bool, error) {
+	const query = `SELECT id FROM products WHERE id = ANY($1) AND track_serial`
+	rows, err := r.pool.Query(ctx, query, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tracked := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		tracked[id] = true
+	}
+	return tracked, rows.Err()
+}
+
+// AvailableSerials lists in-stock serials per product at the warehouse.
+func (r *repository) AvailableSerials(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64][]string, error) {
+	const query = `
+		SELECT product_id, serial_number
+		FROM inventory_serials
+		WHERE warehouse_id = $1 AND product_id = ANY($2) AND status = 'IN_STOCK'
+		ORDER BY product_id, received_at, serial_number
+	`
+	return r.serialsByKey(ctx, query, warehouseID, productIDs)
+}
+
+// DeliveredSerials lists the serials shipped per delivery order line.
+func (r *repository) DeliveredSerials(ctx context.Context, deliveryOrderID int64) (map[int64][]string, error) {
+	const query = `
+		SELECT delivery_order_line_id, serial_number
+		FROM inventory_serials
+		WHERE delivery_order_id = $1 AND delivery_order_line_id IS NOT NULL
+		ORDER BY delivery_order_line_id, serial_number
+	`
+	return r.serialsByKey(ctx, query, deliveryOrderID)
+}
+
+func (r *repository) serialsByKey(ctx context.Context, query string, args ...any) (map[int64][]string, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[int64][]string)
+	for rows.Next() {
+		var key int64
+		var serial string
+		if err := rows.Scan(&key, &serial); err != nil {
+			return nil, err
+		}
+		out[key] = append(out[key], serial)
+	}
+	return out, rows.Err()
+}
+
+// DeliverSerials marks the line's serials as delivered. Every serial must be
+// in stock at the warehouse.
+func (t *txRepository) DeliverSerials(ctx context.Context, warehouseID int64, line Line, serials []string, deliveredAt time.Time) error {
+	const query = `
+		UPDATE inventory_serials
+		SET status = 'DELIVERED', delivery_order_id = $1, delivery_order_line_id = $2, delivered_at = $3
+		WHERE product_id = $4 AND warehouse_id = $5 AND status = 'IN_STOCK' AND serial_number = ANY($6)
+	`
+	tag, err := t.tx.Exec(ctx, query, line.DeliveryOrderID, line.ID, deliveredAt, line.ProductID, warehouseID, serials)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != int64(len(serials)) {
+		return fmt.Errorf("%w: line %d", inventory.ErrSerialUnavailable, line.LineOrder)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+)
+
+// pickedSerials checks the serials chosen for each serial-tracked line: one
+// distinct serial per unit being delivered. Picks on other lines are ignored.
+func (s *Service) pickedSerials(ctx context.Context, lines []Line, picked map[int64][]string) (map[int64][]string, error) {
+	productIDs := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	tracked, err := s.repo.SerialTrackedProducts(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("check serial tracking: %w", err)
+	}
+	out := make(map[int64][]string)
+	for _, line := range lines {
+		if !tracked[line.ProductID] {
+			continue
+		}
+		serials, err := inventory.NormalizeSerials(picked[line.ID], line.QuantityToDeliver)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.LineOrder, err)
+		}
+		out[line.ID] = serials
+	}
+	return out, nil
+}
+
+// attachSerials flags serial-tracked lines, listing the serials already
+// shipped and, while the order can still be delivered, those in stock.
+func (s *Service) attachSerials(ctx context.Context, doID int64, lines []LineWithDetails) error {
+	productIDs := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	tracked, err := s.repo.SerialTrackedProducts(ctx, productIDs)
+	if err != nil || len(tracked) == 0 {
+		return err
+	}
+	delivered, err := s.repo.DeliveredSerials(ctx, doID)
+	if err != nil {
+		return err
+	}
+	do, err := s.repo.GetByID(ctx, doID)
+	if err != nil {
+		return err
+	}
+	var available map[int64][]string
+	if do.Status.CanDeliver() {
+		if available, err = s.repo.AvailableSerials(ctx, do.WarehouseID, productIDs); err != nil {
+			return err
+		}
+	}
+	for i := range lines {
+		if !tracked[lines[i].ProductID] {
+			continue
+		}
+		lines[i].TrackSerial = true
+		lines[i].Serials = delivered[lines[i].ID]
+		lines[i].AvailableSerials = available[lines[i].ProductID]
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -25,10 +26,18 @@ type InventoryClient interface {
 	Reduce(ctx context.Context, items []InventoryItem) error
 }
 
+// UnitConverter resolves how many base units one unit of a product holds.
+type UnitConverter interface {
+	BaseFactor(ctx context.Context, productID int64, uom string) (float64, error)
+}
+
 // Service provides business logic for delivery orders.
 type Service struct {
-	repo      Repository
-	inventory InventoryClient
+	repo          Repository
+	inventory     InventoryClient
+	units         UnitConverter
+	audit         AuditPort
+	dailyCapacity int
 }
 
 // NewService creates a new service.
@@ -41,6 +50,23 @@ func (s *Service) SetInventory(inv InventoryClient) {
 	s.inventory = inv
 }
 
+// SetUnitConverter reduces stock in base units for lines delivered in an
+// alternate unit.
+func (s *Service) SetUnitConverter(units UnitConverter) {
+	s.units = units
+}
+
+// DefaultWarehouse returns the warehouse to prefill for a delivery of the
+// sales order: the default of branchID when given, otherwise of the first
+// branch of the order's company that has one. Nil means no default.
+func (s *Service) DefaultWarehouse(ctx context.Context, salesOrderID int64, branchID *int64) (*int64, error) {
+	soDetails, err := s.repo.GetSalesOrderDetails(ctx, salesOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("get sales order: %w", err)
+	}
+	return s.repo.GetDefaultWarehouse(ctx, soDetails.CompanyID, branchID)
+}
+
 // Create creates a new delivery order from a sales order.
 func (s *Service) Create(ctx context.Context, req CreateRequest, createdBy int64) (*DeliveryOrder, error) {
 	// Validate SO exists and is in correct status
@@ -57,13 +83,20 @@ func (s *Service) Create(ctx context.Context, req CreateRequest, createdBy int64
 		return nil, fmt.Errorf("sales order belongs to different company")
 	}
 
-	// Validate warehouse
-	exists, err := s.repo.CheckWarehouseExists(ctx, req.WarehouseID)
+	// Validate warehouse belongs to the order's company
+	warehouseCompanyID, err := s.repo.GetWarehouseCompanyID(ctx, req.WarehouseID)
+	if errors.Is(err, ErrWarehouseNotFound) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("check warehouse: %w", err)
 	}
-	if !exists {
-		return nil, fmt.Errorf("warehouse not found")
+	if warehouseCompanyID != soDetails.CompanyID {
+		return nil, ErrWarehouseCompany
+	}
+
+	if err := s.resolveFleet(ctx, &req); err != nil {
+		return nil, err
 	}
 
 	// Validate lines against deliverable quantities
@@ -95,6 +128,16 @@ func (s *Service) Create(ctx context.Context, req CreateRequest, createdBy int64
 		}
 	}
 
+	// Ship what is on hand and back-order the rest when requested
+	lines := req.Lines
+	var shortfalls []shortfall
+	if req.AllowBackorder {
+		lines, shortfalls, err = s.planBackorders(ctx, req.WarehouseID, req.SalesOrderID, req.Lines)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate document number
 	docNumber, err := s.repo.GenerateDocNumber(ctx, req.CompanyID, req.DeliveryDate)
 	if err != nil {
@@ -124,8 +167,11 @@ func (s *Service) Create(ctx context.Context, req CreateRequest, createdBy int64
 			return fmt.Errorf("create delivery order: %w", err)
 		}
 		doID = id
+		if err := tx.UpdateDeliveryOrder(ctx, doID, fleetUpdates(req)); err != nil {
+			return fmt.Errorf("link driver and vehicle: %w", err)
+		}
 
-		for _, reqLine := range req.Lines {
+		for _, reqLine := range lines {
 			deliverable := deliverableMap[reqLine.SalesOrderLineID]
 			line := Line{
 				DeliveryOrderID:   doID,
@@ -143,6 +189,29 @@ func (s *Service) Create(ctx context.Context, req CreateRequest, createdBy int64
 			}
 		}
 
+		for _, sf := range shortfalls {
+			bo := Backorder{
+				CompanyID:         req.CompanyID,
+				SalesOrderID:      req.SalesOrderID,
+				SalesOrderLineID:  sf.line.SalesOrderLineID,
+				DeliveryOrderID:   doID,
+				ProductID:         sf.line.ProductID,
+				WarehouseID:       req.WarehouseID,
+				QuantityRequested: sf.requested,
+				QuantityShort:     sf.short,
+				CreatedBy:         createdBy,
+			}
+			if _, err := tx.InsertBackorder(ctx, bo); err != nil {
+				return fmt.Errorf("insert backorder: %w", err)
+			}
+		}
+
+		if req.BackorderID != nil {
+			if err := tx.FulfillBackorder(ctx, *req.BackorderID, doID); err != nil {
+				return fmt.Errorf("fulfill backorder %d: %w", *req.BackorderID, err)
+			}
+		}
+
 		return nil
 	})
 
@@ -239,8 +308,8 @@ func (s *Service) Confirm(ctx context.Context, id int64, confirmedBy int64) (*De
 		return nil, fmt.Errorf("get delivery order: %w", err)
 	}
 
-	if !existing.Status.CanConfirm() {
-		return nil, fmt.Errorf("%w: %s", ErrCannotConfirm, existing.Status)
+	if err := Transitions.Validate(existing.Status, StatusConfirmed); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCannotConfirm, err)
 	}
 
 	if len(existing.Lines) == 0 {
@@ -275,8 +344,8 @@ func (s *Service) MarkInTransit(ctx context.Context, id int64, req MarkInTransit
 		return nil, fmt.Errorf("get delivery order: %w", err)
 	}
 
-	if existing.Status != StatusConfirmed {
-		return nil, fmt.Errorf("must be CONFIRMED to mark in transit, got: %s", existing.Status)
+	if err := Transitions.Validate(existing.Status, StatusInTransit); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCannotShip, err)
 	}
 
 	updates := map[string]interface{}{}
@@ -302,12 +371,22 @@ func (s *Service) MarkDelivered(ctx context.Context, id int64, req MarkDelivered
 		return nil, fmt.Errorf("get delivery order: %w", err)
 	}
 
-	if existing.Status != StatusInTransit {
-		return nil, fmt.Errorf("must be IN_TRANSIT to mark delivered, got: %s", existing.Status)
+	if err := Transitions.Validate(existing.Status, StatusDelivered); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCannotDeliver, err)
+	}
+	if err := ValidateMarkDeliveredRequest(req); err != nil {
+		return nil, err
+	}
+	serials, err := s.pickedSerials(ctx, existing.Lines, req.Serials)
+	if err != nil {
+		return nil, err
 	}
 
 	updates := map[string]interface{}{
-		"delivered_at": req.DeliveredAt,
+		"delivered_at":      req.DeliveredAt,
+		"pod_receiver_name": strings.TrimSpace(req.ReceiverName),
+		"pod_signature":     pointerToText(req.Signature),
+		"pod_notes":         pointerToText(req.PODNotes),
 	}
 
 	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
@@ -316,9 +395,19 @@ func (s *Service) MarkDelivered(ctx context.Context, id int64, req MarkDelivered
 		}
 
 		for _, line := range existing.Lines {
+			// Consume first: the delivered quantity can complete the sales
+			// order, which releases whatever is still reserved.
+			if err := tx.ConsumeReservation(ctx, line.SalesOrderLineID, line.QuantityToDeliver); err != nil {
+				return fmt.Errorf("consume reservation of line %d: %w", line.ID, err)
+			}
 			if err := tx.UpdateLineQuantity(ctx, line.ID, line.QuantityToDeliver); err != nil {
 				return fmt.Errorf("update line %d: %w", line.ID, err)
 			}
+			if picked, ok := serials[line.ID]; ok && len(picked) > 0 {
+				if err := tx.DeliverSerials(ctx, existing.WarehouseID, line, picked, req.DeliveredAt); err != nil {
+					return err
+				}
+			}
 		}
 
 		return nil
@@ -332,11 +421,17 @@ func (s *Service) MarkDelivered(ctx context.Context, id int64, req MarkDelivered
 	if s.inventory != nil {
 		items := make([]InventoryItem, 0, len(existing.Lines))
 		for _, line := range existing.Lines {
+			factor := 1.0
+			if s.units != nil {
+				if factor, err = s.units.BaseFactor(ctx, line.ProductID, line.UOM); err != nil {
+					return nil, fmt.Errorf("unit of line %d: %w", line.LineOrder, err)
+				}
+			}
 			items = append(items, InventoryItem{
 				WarehouseID: existing.WarehouseID,
 				ProductID:   line.ProductID,
-				Quantity:    line.QuantityToDeliver,
-				UnitCost:    line.UnitPrice,
+				Quantity:    line.QuantityToDeliver * factor,
+				UnitCost:    line.UnitPrice / factor,
 				Code:        fmt.Sprintf("DO-%s-L%d", existing.DocNumber, line.ID),
 				Note:        fmt.Sprintf("Delivery %s Line %d", existing.DocNumber, line.LineOrder),
 				ActorID:     req.UpdatedBy,
@@ -359,8 +454,8 @@ func (s *Service) Cancel(ctx context.Context, id int64, req CancelRequest) (*Del
 		return nil, fmt.Errorf("get delivery order: %w", err)
 	}
 
-	if !existing.Status.CanCancel() {
-		return nil, fmt.Errorf("%w: %s", ErrCannotCancel, existing.Status)
+	if err := Transitions.Validate(existing.Status, StatusCancelled); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCannotCancel, err)
 	}
 
 	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
@@ -372,6 +467,10 @@ func (s *Service) Cancel(ctx context.Context, id int64, req CancelRequest) (*Del
 			}
 		}
 
+		if err := tx.CancelBackordersForDelivery(ctx, id); err != nil {
+			return fmt.Errorf("cancel backorders: %w", err)
+		}
+
 		updates := map[string]interface{}{
 			"notes": req.Reason,
 		}
@@ -404,7 +503,14 @@ func (s *Service) GetWithDetails(ctx context.Context, id int64) (*WithDetails, e
 
 // GetLinesWithDetails retrieves lines with product details.
 func (s *Service) GetLinesWithDetails(ctx context.Context, doID int64) ([]LineWithDetails, error) {
-	return s.repo.GetLinesWithDetails(ctx, doID)
+	lines, err := s.repo.GetLinesWithDetails(ctx, doID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachSerials(ctx, doID, lines); err != nil {
+		return nil, fmt.Errorf("load serials: %w", err)
+	}
+	return lines, nil
 }
 
 // List returns a paginated list of delivery orders.
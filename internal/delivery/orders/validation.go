@@ -1,10 +1,18 @@
 package orders
 
 import (
+	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// MaxSignatureBytes caps the decoded size of a proof-of-delivery signature.
+const MaxSignatureBytes = 200 << 10
+
+var signaturePrefixes = []string{"data:image/png;base64,", "data:image/jpeg;base64,"}
+
 // ValidateCreateRequest validates create request.
 func ValidateCreateRequest(req CreateRequest) error {
 	if len(req.Lines) == 0 {
@@ -36,6 +44,16 @@ func ValidateUpdateRequest(req UpdateRequest) error {
 	return nil
 }
 
+// ValidateUpdateLogisticsRequest checks the logistics field lengths.
+func ValidateUpdateLogisticsRequest(req UpdateLogisticsRequest) error {
+	if utf8.RuneCountInString(req.DriverName) > 200 ||
+		utf8.RuneCountInString(req.VehicleNumber) > 50 ||
+		utf8.RuneCountInString(req.TrackingNumber) > 100 {
+		return ErrLogisticsTooLong
+	}
+	return nil
+}
+
 // ValidateCancelRequest validates cancel request.
 func ValidateCancelRequest(req CancelRequest) error {
 	if len(req.Reason) < 10 {
@@ -43,3 +61,36 @@ func ValidateCancelRequest(req CancelRequest) error {
 	}
 	return nil
 }
+
+// ValidateMarkDeliveredRequest validates the proof of delivery.
+func ValidateMarkDeliveredRequest(req MarkDeliveredRequest) error {
+	name := strings.TrimSpace(req.ReceiverName)
+	if name == "" {
+		return ErrReceiverRequired
+	}
+	if utf8.RuneCountInString(name) > 200 {
+		return ErrReceiverTooLong
+	}
+	if req.PODNotes != nil && utf8.RuneCountInString(*req.PODNotes) > 1000 {
+		return ErrPODNotesTooLong
+	}
+	if req.Signature != nil && *req.Signature != "" {
+		return validateSignature(*req.Signature)
+	}
+	return nil
+}
+
+// validateSignature accepts base64 PNG or JPEG data URLs up to MaxSignatureBytes.
+func validateSignature(sig string) error {
+	for _, prefix := range signaturePrefixes {
+		if !strings.HasPrefix(sig, prefix) {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sig, prefix))
+		if err != nil || len(data) == 0 || len(data) > MaxSignatureBytes {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return ErrInvalidSignature
+}
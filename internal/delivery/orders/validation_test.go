@@ -0,0 +1,72 @@
+package orders
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func TestValidateMarkDeliveredRequest(t *testing.T) {
+	png := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\nsig"))
+	huge := "data:image/png;base64," + base64.StdEncoding.EncodeToString(make([]byte, MaxSignatureBytes+1))
+	gif := "data:image/gif;base64," + base64.StdEncoding.EncodeToString([]byte("GIF89a"))
+	script := "javascript:alert(1)"
+	longNotes := strings.Repeat("n", 1001)
+
+	cases := []struct {
+		name string
+		req  MarkDeliveredRequest
+		want error
+	}{
+		{"valid without signature", MarkDeliveredRequest{ReceiverName: "Budi"}, nil},
+		{"valid with signature", MarkDeliveredRequest{ReceiverName: "Budi", Signature: &png}, nil},
+		{"missing receiver", MarkDeliveredRequest{ReceiverName: "  "}, ErrReceiverRequired},
+		{"receiver too long", MarkDeliveredRequest{ReceiverName: strings.Repeat("a", 201)}, ErrReceiverTooLong},
+		{"notes too long", MarkDeliveredRequest{ReceiverName: "Budi", PODNotes: &longNotes}, ErrPODNotesTooLong},
+		{"signature too large", MarkDeliveredRequest{ReceiverName: "Budi", Signature: &huge}, ErrInvalidSignature},
+		{"unsupported image", MarkDeliveredRequest{ReceiverName: "Budi", Signature: &gif}, ErrInvalidSignature},
+		{"not a data url", MarkDeliveredRequest{ReceiverName: "Budi", Signature: &script}, ErrInvalidSignature},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateMarkDeliveredRequest(tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestStatusCanDeliver(t *testing.T) {
+	for status, want := range map[Status]bool{
+		StatusDraft:     false,
+		StatusConfirmed: true,
+		StatusInTransit: true,
+		StatusDelivered: false,
+		StatusCancelled: false,
+	} {
+		if got := status.CanDeliver(); got != want {
+			t.Fatalf("%s: expected %v, got %v", status, want, got)
+		}
+	}
+}
+
+func TestStatusTransitionsReturnInvalidTransition(t *testing.T) {
+	repo := &fakeLogisticsRepo{order: DeliveryOrder{ID: 7, Status: StatusInTransit}}
+	svc := NewService(repo)
+
+	_, err := svc.Cancel(context.Background(), 7, CancelRequest{Reason: "customer no longer needs it", CancelledBy: 3})
+	if !errors.Is(err, ErrCannotCancel) || !errors.Is(err, shared.ErrInvalidTransition) {
+		t.Fatalf("expected ErrCannotCancel wrapping ErrInvalidTransition, got %v", err)
+	}
+	repo.order.Status = StatusDelivered
+	if _, err := svc.MarkInTransit(context.Background(), 7, MarkInTransitRequest{}); !errors.Is(err, ErrCannotShip) || !errors.Is(err, shared.ErrInvalidTransition) {
+		t.Fatalf("expected ErrCannotShip wrapping ErrInvalidTransition, got %v", err)
+	}
+	if !StatusConfirmed.CanShip() || StatusDraft.CanShip() {
+		t.Fatal("only confirmed orders can be shipped")
+	}
+}
@@ -0,0 +1,67 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeWarehouseRepo struct {
+	Repository
+	warehouseCompany map[int64]int64
+	defaults         map[int64]int64
+	lookedUpBranch   *int64
+}
+
+func (f *fakeWarehouseRepo) GetSalesOrderDetails(_ context.Context, id int64) (*SalesOrderInfo, error) {
+	return &SalesOrderInfo{ID: id, CompanyID: 1, Status: "CONFIRMED"}, nil
+}
+
+func (f *fakeWarehouseRepo) GetWarehouseCompanyID(_ context.Context, warehouseID int64) (int64, error) {
+	companyID, ok := f.warehouseCompany[warehouseID]
+	if !ok {
+		return 0, ErrWarehouseNotFound
+	}
+	return companyID, nil
+}
+
+func (f *fakeWarehouseRepo) GetDefaultWarehouse(_ context.Context, companyID int64, branchID *int64) (*int64, error) {
+	f.lookedUpBranch = branchID
+	warehouseID, ok := f.defaults[companyID]
+	if !ok {
+		return nil, nil
+	}
+	return &warehouseID, nil
+}
+
+func TestCreateRejectsWarehouseOfAnotherCompany(t *testing.T) {
+	svc := NewService(&fakeWarehouseRepo{warehouseCompany: map[int64]int64{7: 2}})
+	req := CreateRequest{CompanyID: 1, SalesOrderID: 3, DeliveryDate: time.Now()}
+
+	req.WarehouseID = 7
+	if _, err := svc.Create(context.Background(), req, 1); !errors.Is(err, ErrWarehouseCompany) {
+		t.Fatalf("expected ErrWarehouseCompany, got %v", err)
+	}
+	req.WarehouseID = 8
+	if _, err := svc.Create(context.Background(), req, 1); !errors.Is(err, ErrWarehouseNotFound) {
+		t.Fatalf("expected ErrWarehouseNotFound, got %v", err)
+	}
+}
+
+func TestDefaultWarehouseUsesOrderCompany(t *testing.T) {
+	repo := &fakeWarehouseRepo{defaults: map[int64]int64{1: 4}}
+	svc := NewService(repo)
+	branchID := int64(9)
+
+	warehouseID, err := svc.DefaultWarehouse(context.Background(), 3, &branchID)
+	if err != nil {
+		t.Fatalf("DefaultWarehouse: %v", err)
+	}
+	if warehouseID == nil || *warehouseID != 4 {
+		t.Fatalf("expected warehouse 4, got %v", warehouseID)
+	}
+	if repo.lookedUpBranch == nil || *repo.lookedUpBranch != 9 {
+		t.Fatalf("expected the branch to narrow the lookup, got %v", repo.lookedUpBranch)
+	}
+}
@@ -8,12 +8,14 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/delivery/orders"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/products"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 )
 
-// MountRoutes wires all delivery domain routes.
+// MountRoutes wires all delivery domain routes. dailyCapacity is the number
+// of delivery orders a day can take before the calendar flags it overbooked.
 func MountRoutes(
 	r chi.Router,
 	pool *pgxpool.Pool,
@@ -21,10 +23,14 @@ func MountRoutes(
 	templates *view.Engine,
 	csrf *shared.CSRFManager,
 	rbacMW rbac.Middleware,
+	dailyCapacity int,
 ) {
 	// Orders entity
 	ordersRepo := orders.NewRepository(pool)
 	ordersSvc := orders.NewService(ordersRepo)
+	ordersSvc.SetDailyCapacity(dailyCapacity)
+	ordersSvc.SetAuditor(shared.NewAuditLogger(pool))
+	ordersSvc.SetUnitConverter(products.NewService(products.NewRepository(pool), nil))
 	ordersHandler := orders.NewHandler(logger, ordersSvc, templates, csrf, rbacMW)
 
 	r.Route("/orders", func(r chi.Router) {
@@ -2,11 +2,15 @@ package e2e
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/hibiken/asynq"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/consol"
 	jobmetrics "github.com/odyssey-erp/odyssey-erp/internal/jobs"
 	"github.com/odyssey-erp/odyssey-erp/jobs"
 )
@@ -28,9 +32,13 @@ func (s *stubConsolService) RebuildConsolidation(_ context.Context, groupID int6
 }
 
 type stubConsolRepo struct {
-	groups []int64
-	period string
-	err    error
+	groups       []int64
+	period       string
+	err          error
+	source       map[int64]time.Time
+	watermarks   map[int64]time.Time
+	locked       map[string]bool
+	restatements []consol.Restatement
 }
 
 func (s *stubConsolRepo) ListGroupIDs(_ context.Context) ([]int64, error) {
@@ -41,6 +49,30 @@ func (s *stubConsolRepo) ActiveConsolidationPeriod(_ context.Context) (string, e
 	return s.period, s.err
 }
 
+func (s *stubConsolRepo) RefreshWatermark(_ context.Context, groupID int64, _ string) (consol.RefreshWatermark, error) {
+	return consol.RefreshWatermark{
+		SourceChangedAt:  s.source[groupID],
+		RefreshedThrough: s.watermarks[groupID],
+	}, nil
+}
+
+func (s *stubConsolRepo) MarkRefreshed(_ context.Context, groupID int64, _ string, sourceChangedAt time.Time) error {
+	if s.watermarks == nil {
+		s.watermarks = make(map[int64]time.Time)
+	}
+	s.watermarks[groupID] = sourceChangedAt
+	return nil
+}
+
+func (s *stubConsolRepo) PeriodLocked(_ context.Context, period string) (bool, error) {
+	return s.locked[period], nil
+}
+
+func (s *stubConsolRepo) RecordRestatement(_ context.Context, restatement consol.Restatement) error {
+	s.restatements = append(s.restatements, restatement)
+	return nil
+}
+
 func TestConsolidateRefreshJob(t *testing.T) {
 	repo := &stubConsolRepo{groups: []int64{11, 22, 33}, period: "2024-02"}
 	service := &stubConsolService{}
@@ -77,6 +109,121 @@ func TestConsolidateRefreshJob(t *testing.T) {
 	}
 }
 
+func TestConsolidateRefreshJobSkipsUnchangedGroups(t *testing.T) {
+	base := time.Date(2024, 2, 10, 8, 0, 0, 0, time.UTC)
+	repo := &stubConsolRepo{
+		groups: []int64{11, 22, 33},
+		period: "2024-02",
+		source: map[int64]time.Time{11: base, 22: base.Add(time.Hour), 33: base},
+		// 11 is up to date, 22 has newer journals, 33 was never refreshed.
+		watermarks: map[int64]time.Time{11: base, 22: base},
+	}
+	service := &stubConsolService{}
+	job := jobs.NewConsolidateRefreshJob(service, repo, nil, jobmetrics.NewMetrics(prometheus.NewRegistry()))
+
+	task, err := jobs.NewConsolidateRefreshTask(jobs.ConsolScopeChanged, "active")
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := job.Handle(context.Background(), task); err != nil {
+		t.Fatalf("job handle: %v", err)
+	}
+	if len(service.calls) != 2 || service.calls[0].group != 22 || service.calls[1].group != 33 {
+		t.Fatalf("expected groups 22 and 33 refreshed, got %+v", service.calls)
+	}
+	if !repo.watermarks[22].Equal(base.Add(time.Hour)) || !repo.watermarks[33].Equal(base) {
+		t.Fatalf("expected watermarks advanced to source, got %+v", repo.watermarks)
+	}
+
+	// A second incremental run has nothing left to do.
+	service.calls = nil
+	if err := job.Handle(context.Background(), task); err != nil {
+		t.Fatalf("job handle: %v", err)
+	}
+	if len(service.calls) != 0 {
+		t.Fatalf("expected no refresh on unchanged data, got %+v", service.calls)
+	}
+
+	// An explicit full refresh ignores watermarks.
+	full, err := jobs.NewConsolidateRefreshTask(jobs.ConsolScopeAll, "active")
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := job.Handle(context.Background(), full); err != nil {
+		t.Fatalf("job handle: %v", err)
+	}
+	if len(service.calls) != 3 {
+		t.Fatalf("expected 3 refresh calls on full refresh, got %d", len(service.calls))
+	}
+}
+
+func TestConsolidateRefreshJobRestatesPastPeriod(t *testing.T) {
+	base := time.Date(2024, 1, 20, 8, 0, 0, 0, time.UTC)
+	repo := &stubConsolRepo{
+		groups:     []int64{11, 22},
+		period:     "2024-02",
+		source:     map[int64]time.Time{11: base, 22: base},
+		watermarks: map[int64]time.Time{11: base, 22: base},
+		locked:     map[string]bool{"2023-12": true},
+	}
+	service := &stubConsolService{}
+	job := jobs.NewConsolidateRefreshJob(service, repo, nil, jobmetrics.NewMetrics(prometheus.NewRegistry()))
+
+	if _, err := jobs.NewConsolidateRestatementTask("11", "2024-01", jobs.ConsolRestatement{}); err == nil {
+		t.Fatalf("expected a restatement without reason to be rejected")
+	}
+	if _, err := jobs.NewConsolidateRestatementTask("11", "active", jobs.ConsolRestatement{Reason: "audit"}); err == nil {
+		t.Fatalf("expected a restatement of the active period to be rejected")
+	}
+
+	// Watermarks are up to date, but a restatement rebuilds anyway.
+	task, err := jobs.NewConsolidateRestatementTask("11", "2024-01", jobs.ConsolRestatement{Reason: "Subsidiary A restated inventory", RequestedBy: 7})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := job.Handle(context.Background(), task); err != nil {
+		t.Fatalf("job handle: %v", err)
+	}
+	if len(service.calls) != 1 || service.calls[0].group != 11 || service.calls[0].period != "2024-01" {
+		t.Fatalf("expected group 11 rebuilt for 2024-01, got %+v", service.calls)
+	}
+	if len(repo.restatements) != 1 || repo.restatements[0].Reason != "Subsidiary A restated inventory" ||
+		repo.restatements[0].RequestedBy != 7 || repo.restatements[0].LockOverridden {
+		t.Fatalf("unexpected restatement notes: %+v", repo.restatements)
+	}
+
+	// A locked period is refused, by plain refreshes and restatements alike.
+	service.calls = nil
+	plain, err := jobs.NewConsolidateRefreshTask("all", "2023-12")
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := job.Handle(context.Background(), plain); !errors.Is(err, consol.ErrPeriodLocked) || !errors.Is(err, asynq.SkipRetry) {
+		t.Fatalf("expected locked period error, got %v", err)
+	}
+	locked, err := jobs.NewConsolidateRestatementTask("all", "2023-12", jobs.ConsolRestatement{Reason: "audit adjustment"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := job.Handle(context.Background(), locked); !errors.Is(err, consol.ErrPeriodLocked) {
+		t.Fatalf("expected locked period error, got %v", err)
+	}
+	if len(service.calls) != 0 {
+		t.Fatalf("expected no rebuild of a locked period, got %+v", service.calls)
+	}
+
+	override, err := jobs.NewConsolidateRestatementTask("all", "2023-12", jobs.ConsolRestatement{Reason: "audit adjustment", OverrideLock: true})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := job.Handle(context.Background(), override); err != nil {
+		t.Fatalf("job handle: %v", err)
+	}
+	if len(service.calls) != 2 || len(repo.restatements) != 3 || !repo.restatements[2].LockOverridden {
+		t.Fatalf("expected both groups restated with the lock overridden, got %+v %+v", service.calls, repo.restatements)
+	}
+}
+
 func assertCounter(t *testing.T, families []*dto.MetricFamily, name string, labels map[string]string, expected float64) bool {
 	t.Helper()
 	for _, fam := range families {
@@ -45,6 +45,35 @@ type Rule struct {
 	UpdatedAt       time.Time
 }
 
+// RuleTemplate describes an account pair that is expanded into concrete rules
+// for every enabled member pair of a consolidation group.
+type RuleTemplate struct {
+	ID            int64
+	GroupID       int64
+	Name          string
+	AccountSource string
+	AccountTarget string
+	MatchCriteria map[string]any
+	Active        bool
+	CreatedBy     int64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Member is an enabled company of a consolidation group.
+type Member struct {
+	CompanyID int64
+	Code      string
+}
+
+// ApplyResult reports the outcome of expanding a template. Skipped counts the
+// ordered member pairs already covered by an existing rule.
+type ApplyResult struct {
+	TemplateID int64
+	Created    []Rule
+	Skipped    int
+}
+
 // Run aggregates simulation/posting metadata for a rule + period.
 type Run struct {
 	ID           int64
@@ -108,6 +137,33 @@ func (in CreateRuleInput) Validate() error {
 	return nil
 }
 
+// CreateTemplateInput captures a new rule template.
+type CreateTemplateInput struct {
+	GroupID       int64
+	Name          string
+	AccountSource string
+	AccountTarget string
+	MatchCriteria map[string]any
+	ActorID       int64
+}
+
+// Validate ensures the template can be expanded.
+func (in CreateTemplateInput) Validate() error {
+	if in.GroupID == 0 {
+		return errors.New("elimination: group required")
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return errors.New("elimination: name required")
+	}
+	if strings.TrimSpace(in.AccountSource) == "" || strings.TrimSpace(in.AccountTarget) == "" {
+		return errors.New("elimination: account codes required")
+	}
+	if in.ActorID == 0 {
+		return errors.New("elimination: actor required")
+	}
+	return nil
+}
+
 // UpdateRuleInput mutates existing rule metadata.
 type UpdateRuleInput struct {
 	Name          string
@@ -148,6 +204,15 @@ func (in CreateRunInput) Validate() error {
 // ErrRuleNotFound occurs when rule lookup fails.
 var ErrRuleNotFound = errors.New("elimination: rule not found")
 
+// ErrTemplateNotFound occurs when template lookup fails.
+var ErrTemplateNotFound = errors.New("elimination: rule template not found")
+
+// ErrTemplateInactive indicates the template is disabled and cannot be applied.
+var ErrTemplateInactive = errors.New("elimination: rule template inactive")
+
+// ErrGroupNotFound indicates the consolidation group is missing.
+var ErrGroupNotFound = errors.New("elimination: consolidation group not found")
+
 // ErrRunNotFound occurs when run lookup fails.
 var ErrRunNotFound = errors.New("elimination: run not found")
 
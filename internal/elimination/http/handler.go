@@ -1,6 +1,7 @@
 package eliminationhttp
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/elimination"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -34,6 +36,8 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAny(shared.PermFinanceConsolManage))
 		r.Get("/rules", h.listRules)
 		r.Post("/rules", h.createRule)
+		r.Post("/templates", h.createTemplate)
+		r.Post("/templates/{id}/apply", h.applyTemplate)
 		r.Get("/runs", h.listRuns)
 		r.Post("/runs", h.createRun)
 		r.Route("/runs/{id}", func(r chi.Router) {
@@ -47,17 +51,23 @@ func (h *Handler) MountRoutes(r chi.Router) {
 func (h *Handler) listRules(w http.ResponseWriter, r *http.Request) {
 	rules, err := h.service.ListRules(r.Context(), 100)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	templates, err := h.service.ListTemplates(r.Context(), 100)
+	if err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	h.render(w, r, "pages/eliminations/rules.html", "Elimination Rules", map[string]any{
-		"Rules": rules,
+		"Rules":     rules,
+		"Templates": templates,
 	}, http.StatusOK)
 }
 
 func (h *Handler) createRule(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	actor := currentUser(r)
@@ -73,13 +83,50 @@ func (h *Handler) createRule(w http.ResponseWriter, r *http.Request) {
 		ActorID:         actor,
 	}
 	if _, err := h.service.CreateRule(r.Context(), input); err != nil {
-		h.logger.Warn("create elimination rule", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "create elimination rule", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/eliminations/rules", "danger", shared.UserSafeMessage(err))
 		return
 	}
 	h.redirectWithFlash(w, r, "/eliminations/rules", "success", "Rule created")
 }
 
+func (h *Handler) createTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	input := elimination.CreateTemplateInput{
+		GroupID:       parseInt64(r.PostFormValue("group_id")),
+		Name:          strings.TrimSpace(r.PostFormValue("name")),
+		AccountSource: strings.TrimSpace(r.PostFormValue("account_src")),
+		AccountTarget: strings.TrimSpace(r.PostFormValue("account_tgt")),
+		MatchCriteria: map[string]any{},
+		ActorID:       currentUser(r),
+	}
+	tpl, result, err := h.service.CreateTemplate(r.Context(), input)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "create elimination template", slog.Any("error", err), slog.Int64("template_id", tpl.ID))
+		h.redirectWithFlash(w, r, "/eliminations/rules", "danger", shared.UserSafeMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, "/eliminations/rules", "success", applyMessage(result))
+}
+
+func (h *Handler) applyTemplate(w http.ResponseWriter, r *http.Request) {
+	id := parseInt64(chi.URLParam(r, "id"))
+	result, err := h.service.ApplyTemplate(r.Context(), id)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "apply elimination template", slog.Any("error", err), slog.Int64("template_id", id))
+		h.redirectWithFlash(w, r, "/eliminations/rules", "danger", shared.UserSafeMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, "/eliminations/rules", "success", applyMessage(result))
+}
+
+func applyMessage(result elimination.ApplyResult) string {
+	return fmt.Sprintf("Template applied: %d rule(s) created, %d pair(s) already covered", len(result.Created), result.Skipped)
+}
+
 func (h *Handler) listRuns(w http.ResponseWriter, r *http.Request) {
 	page := parseInt(r.URL.Query().Get("page"), 1)
 	limit := parseInt(r.URL.Query().Get("limit"), 20)
@@ -95,22 +142,22 @@ func (h *Handler) listRuns(w http.ResponseWriter, r *http.Request) {
 
 	runs, total, err := h.service.ListRuns(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list runs", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list runs", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
 	// Fetch dependencies for creating new runs
 	rules, err := h.service.ListRules(r.Context(), 100)
 	if err != nil {
-		h.logger.Error("list rules", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list rules", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	periods, err := h.service.RecentPeriods(r.Context(), 12)
 	if err != nil {
-		h.logger.Error("list periods", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list periods", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
@@ -125,7 +172,7 @@ func (h *Handler) listRuns(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) createRun(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	actor := currentUser(r)
@@ -135,7 +182,7 @@ func (h *Handler) createRun(w http.ResponseWriter, r *http.Request) {
 		ActorID:  actor,
 	}
 	if _, err := h.service.CreateRun(r.Context(), input); err != nil {
-		h.logger.Warn("create elimination run", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "create elimination run", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/eliminations/runs", "danger", shared.UserSafeMessage(err))
 		return
 	}
@@ -150,7 +197,7 @@ func (h *Handler) showRun(w http.ResponseWriter, r *http.Request) {
 			http.NotFound(w, r)
 			return
 		}
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	h.render(w, r, "pages/eliminations/run_detail.html", "Elimination Run", map[string]any{
@@ -161,11 +208,11 @@ func (h *Handler) showRun(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) simulateRun(w http.ResponseWriter, r *http.Request) {
 	id := parseInt64(chi.URLParam(r, "id"))
 	if id == 0 {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	if _, _, err := h.service.SimulateRun(r.Context(), id); err != nil {
-		h.logger.Warn("simulate elimination run", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.WarnContext(r.Context(), "simulate elimination run", slog.Any("error", err), slog.Int64("id", id))
 		h.redirectWithFlash(w, r, "/eliminations/runs/"+strconv.FormatInt(id, 10), "danger", shared.UserSafeMessage(err))
 		return
 	}
@@ -176,11 +223,11 @@ func (h *Handler) postRun(w http.ResponseWriter, r *http.Request) {
 	id := parseInt64(chi.URLParam(r, "id"))
 	actor := currentUser(r)
 	if actor == 0 {
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		return
 	}
 	if _, err := h.service.PostRun(r.Context(), id, actor); err != nil {
-		h.logger.Warn("post elimination run", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.WarnContext(r.Context(), "post elimination run", slog.Any("error", err), slog.Int64("id", id))
 		h.redirectWithFlash(w, r, "/eliminations/runs/"+strconv.FormatInt(id, 10), "danger", shared.UserSafeMessage(err))
 		return
 	}
@@ -203,7 +250,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, tpl, title stri
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, tpl, viewData); err != nil {
-		h.logger.Error("render template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
 	}
 }
 
@@ -0,0 +1,155 @@
+package elimination
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
+)
+
+// InsertTemplate stores a new rule template.
+func (r *Repository) InsertTemplate(ctx context.Context, input CreateTemplateInput) (RuleTemplate, error) {
+	criteria, err := json.Marshal(input.MatchCriteria)
+	if err != nil {
+		return RuleTemplate{}, err
+	}
+	row, err := r.queries.ElimInsertRuleTemplate(ctx, sqlc.ElimInsertRuleTemplateParams{
+		GroupID:       input.GroupID,
+		Name:          input.Name,
+		AccountSrc:    input.AccountSource,
+		AccountTgt:    input.AccountTarget,
+		MatchCriteria: criteria,
+		CreatedBy:     input.ActorID,
+	})
+	if err != nil {
+		return RuleTemplate{}, err
+	}
+	return mapTemplate(row), nil
+}
+
+// GetTemplate loads a template by id.
+func (r *Repository) GetTemplate(ctx context.Context, id int64) (RuleTemplate, error) {
+	row, err := r.queries.ElimGetRuleTemplate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return RuleTemplate{}, ErrTemplateNotFound
+		}
+		return RuleTemplate{}, err
+	}
+	return mapTemplate(row), nil
+}
+
+// ListTemplates returns configured templates ordered by group.
+func (r *Repository) ListTemplates(ctx context.Context, limit int) ([]RuleTemplate, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := r.queries.ElimListRuleTemplates(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	return mapTemplates(rows), nil
+}
+
+// ListActiveTemplates returns every active template.
+func (r *Repository) ListActiveTemplates(ctx context.Context) ([]RuleTemplate, error) {
+	rows, err := r.queries.ElimListActiveRuleTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mapTemplates(rows), nil
+}
+
+// ApplyTemplate expands the template against the group's enabled members and
+// inserts the missing rules. The group row is locked for the duration so
+// concurrent applies cannot insert the same pair twice.
+func (r *Repository) ApplyTemplate(ctx context.Context, tpl RuleTemplate) (ApplyResult, error) {
+	result := ApplyResult{TemplateID: tpl.ID}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+	q := r.queries.WithTx(tx)
+
+	if _, err := q.ElimLockGroup(ctx, tpl.GroupID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, ErrGroupNotFound
+		}
+		return result, err
+	}
+	memberRows, err := q.ElimListEnabledMembers(ctx, tpl.GroupID)
+	if err != nil {
+		return result, err
+	}
+	members := make([]Member, len(memberRows))
+	for i, row := range memberRows {
+		members[i] = Member{CompanyID: row.CompanyID, Code: row.Code}
+	}
+	ruleRows, err := q.ElimListGroupRules(ctx, int8FromInt64(tpl.GroupID))
+	if err != nil {
+		return result, err
+	}
+	existing := make([]Rule, len(ruleRows))
+	for i, row := range ruleRows {
+		existing[i] = mapRule(row)
+	}
+
+	pending, skipped := ExpandTemplate(tpl, members, existing)
+	result.Skipped = skipped
+	for _, input := range pending {
+		criteria, err := json.Marshal(input.MatchCriteria)
+		if err != nil {
+			return result, err
+		}
+		row, err := q.ElimInsertRule(ctx, sqlc.ElimInsertRuleParams{
+			GroupID:         int8ToPointerInt8Original(input.GroupID),
+			Name:            input.Name,
+			SourceCompanyID: input.SourceCompanyID,
+			TargetCompanyID: input.TargetCompanyID,
+			AccountSrc:      input.AccountSource,
+			AccountTgt:      input.AccountTarget,
+			MatchCriteria:   criteria,
+			CreatedBy:       input.ActorID,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Created = append(result.Created, mapRule(row))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ApplyResult{TemplateID: tpl.ID}, err
+	}
+	return result, nil
+}
+
+func mapTemplates(rows []sqlc.EliminationRuleTemplate) []RuleTemplate {
+	templates := make([]RuleTemplate, len(rows))
+	for i, row := range rows {
+		templates[i] = mapTemplate(row)
+	}
+	return templates
+}
+
+func mapTemplate(row sqlc.EliminationRuleTemplate) RuleTemplate {
+	t := RuleTemplate{
+		ID:            row.ID,
+		GroupID:       row.GroupID,
+		Name:          row.Name,
+		AccountSource: row.AccountSrc,
+		AccountTarget: row.AccountTgt,
+		Active:        row.IsActive,
+		CreatedBy:     row.CreatedBy,
+		CreatedAt:     row.CreatedAt.Time,
+		UpdatedAt:     row.UpdatedAt.Time,
+	}
+	if len(row.MatchCriteria) > 0 {
+		_ = json.Unmarshal(row.MatchCriteria, &t.MatchCriteria)
+	} else {
+		t.MatchCriteria = map[string]any{}
+	}
+	return t
+}
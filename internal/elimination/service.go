@@ -2,6 +2,9 @@ package elimination
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -41,6 +44,66 @@ func (s *Service) CreateRule(ctx context.Context, input CreateRuleInput) (Rule,
 	return s.repo.InsertRule(ctx, input)
 }
 
+// ListTemplates returns configured rule templates.
+func (s *Service) ListTemplates(ctx context.Context, limit int) ([]RuleTemplate, error) {
+	return s.repo.ListTemplates(ctx, limit)
+}
+
+// CreateTemplate validates and persists a template, then applies it to the
+// group's current members.
+func (s *Service) CreateTemplate(ctx context.Context, input CreateTemplateInput) (RuleTemplate, ApplyResult, error) {
+	if err := input.Validate(); err != nil {
+		return RuleTemplate{}, ApplyResult{}, err
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	input.AccountSource = strings.TrimSpace(input.AccountSource)
+	input.AccountTarget = strings.TrimSpace(input.AccountTarget)
+	tpl, err := s.repo.InsertTemplate(ctx, input)
+	if err != nil {
+		return RuleTemplate{}, ApplyResult{}, err
+	}
+	result, err := s.repo.ApplyTemplate(ctx, tpl)
+	return tpl, result, err
+}
+
+// ApplyTemplate generates the rules missing for the template's group. It is
+// safe to repeat: pairs that already have a rule, including ones deactivated
+// by hand, are left alone. Generated rules are attributed to the template
+// author.
+func (s *Service) ApplyTemplate(ctx context.Context, id int64) (ApplyResult, error) {
+	tpl, err := s.repo.GetTemplate(ctx, id)
+	if err != nil {
+		return ApplyResult{}, err
+	}
+	if !tpl.Active {
+		return ApplyResult{TemplateID: id}, ErrTemplateInactive
+	}
+	return s.repo.ApplyTemplate(ctx, tpl)
+}
+
+// ApplyAllTemplates re-applies every active template so membership changes
+// pick up their rules. It returns the number of rules created and keeps going
+// past failing templates.
+func (s *Service) ApplyAllTemplates(ctx context.Context) (int, error) {
+	templates, err := s.repo.ListActiveTemplates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		created int
+		errs    []error
+	)
+	for _, tpl := range templates {
+		result, err := s.repo.ApplyTemplate(ctx, tpl)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("template %d: %w", tpl.ID, err))
+			continue
+		}
+		created += len(result.Created)
+	}
+	return created, errors.Join(errs...)
+}
+
 // ListRuns fetches recent elimination runs.
 func (s *Service) ListRuns(ctx context.Context, filters ListFilters) ([]Run, int, error) {
 	return s.repo.ListRuns(ctx, filters)
@@ -0,0 +1,73 @@
+package elimination
+
+import "fmt"
+
+// ExpandTemplate returns the rules a template implies for the given members,
+// leaving out any already covered by existing. Every ordered member pair gets
+// a rule, so each company's source account is matched against every other
+// member's target account. A rule and its mirror (companies and accounts both
+// swapped) eliminate the same balances and count as one, which collapses
+// templates with identical source and target accounts to one rule per pair.
+// skipped counts the pairs left out because an existing rule covers them;
+// pairs collapsed into a rule generated here are not counted.
+func ExpandTemplate(tpl RuleTemplate, members []Member, existing []Rule) (rules []CreateRuleInput, skipped int) {
+	covered := make(map[ruleKey]bool, len(existing))
+	for _, rule := range existing {
+		covered[keyOf(rule.SourceCompanyID, rule.TargetCompanyID, rule.AccountSource, rule.AccountTarget)] = true
+	}
+	generated := make(map[ruleKey]bool)
+	groupID := tpl.GroupID
+	for _, src := range members {
+		for _, tgt := range members {
+			if src.CompanyID == tgt.CompanyID {
+				continue
+			}
+			key := keyOf(src.CompanyID, tgt.CompanyID, tpl.AccountSource, tpl.AccountTarget)
+			if covered[key] {
+				skipped++
+				continue
+			}
+			if generated[key] {
+				continue
+			}
+			generated[key] = true
+			criteria := make(map[string]any, len(tpl.MatchCriteria)+1)
+			for k, v := range tpl.MatchCriteria {
+				criteria[k] = v
+			}
+			criteria["template_id"] = tpl.ID
+			rules = append(rules, CreateRuleInput{
+				GroupID:         &groupID,
+				Name:            fmt.Sprintf("%s: %s → %s", tpl.Name, memberLabel(src), memberLabel(tgt)),
+				SourceCompanyID: src.CompanyID,
+				TargetCompanyID: tgt.CompanyID,
+				AccountSource:   tpl.AccountSource,
+				AccountTarget:   tpl.AccountTarget,
+				MatchCriteria:   criteria,
+				ActorID:         tpl.CreatedBy,
+			})
+		}
+	}
+	return rules, skipped
+}
+
+type ruleKey struct {
+	srcCompany, tgtCompany int64
+	srcAccount, tgtAccount string
+}
+
+// keyOf normalises a rule so it and its mirror share a key.
+func keyOf(srcCompany, tgtCompany int64, srcAccount, tgtAccount string) ruleKey {
+	if srcCompany > tgtCompany {
+		srcCompany, tgtCompany = tgtCompany, srcCompany
+		srcAccount, tgtAccount = tgtAccount, srcAccount
+	}
+	return ruleKey{srcCompany: srcCompany, tgtCompany: tgtCompany, srcAccount: srcAccount, tgtAccount: tgtAccount}
+}
+
+func memberLabel(m Member) string {
+	if m.Code != "" {
+		return m.Code
+	}
+	return fmt.Sprintf("#%d", m.CompanyID)
+}
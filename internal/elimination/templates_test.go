@@ -0,0 +1,74 @@
+package elimination
+
+import "testing"
+
+func TestExpandTemplateCoversEveryOrderedPair(t *testing.T) {
+	tpl := RuleTemplate{ID: 4, GroupID: 2, Name: "IC AR/AP", AccountSource: "1300", AccountTarget: "2300", CreatedBy: 9}
+	members := []Member{{CompanyID: 1, Code: "HQ"}, {CompanyID: 2, Code: "SUB1"}, {CompanyID: 3, Code: "SUB2"}}
+
+	rules, skipped := ExpandTemplate(tpl, members, nil)
+	if len(rules) != 6 || skipped != 0 {
+		t.Fatalf("expected 6 rules and none skipped for 3 members, got %d and %d", len(rules), skipped)
+	}
+	first := rules[0]
+	if first.Name != "IC AR/AP: HQ → SUB1" || first.SourceCompanyID != 1 || first.TargetCompanyID != 2 {
+		t.Fatalf("unexpected first rule %+v", first)
+	}
+	if first.GroupID == nil || *first.GroupID != 2 || first.ActorID != 9 || first.MatchCriteria["template_id"] != int64(4) {
+		t.Fatalf("rule not linked to template: %+v", first)
+	}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			t.Fatalf("generated rule invalid: %v", err)
+		}
+	}
+}
+
+func TestExpandTemplateSkipsExistingAndMirroredRules(t *testing.T) {
+	tpl := RuleTemplate{ID: 1, GroupID: 2, Name: "IC AR/AP", AccountSource: "1300", AccountTarget: "2300", CreatedBy: 9}
+	members := []Member{{CompanyID: 1, Code: "HQ"}, {CompanyID: 2, Code: "SUB1"}}
+	existing := []Rule{
+		{SourceCompanyID: 1, TargetCompanyID: 2, AccountSource: "1300", AccountTarget: "2300", Active: false},
+	}
+	rules, skipped := ExpandTemplate(tpl, members, existing)
+	if len(rules) != 1 || rules[0].SourceCompanyID != 2 || rules[0].TargetCompanyID != 1 {
+		t.Fatalf("expected only SUB1 → HQ, got %+v", rules)
+	}
+	if skipped != 1 {
+		t.Fatalf("expected HQ → SUB1 counted as skipped, got %d", skipped)
+	}
+
+	// The mirror of HQ→SUB1 (1300/2300) is SUB1→HQ (2300/1300).
+	existing = append(existing, Rule{SourceCompanyID: 2, TargetCompanyID: 1, AccountSource: "2300", AccountTarget: "1300"})
+	if got, _ := ExpandTemplate(tpl, members, existing[1:]); len(got) != 1 || got[0].SourceCompanyID != 2 {
+		t.Fatalf("mirrored rule should cover HQ → SUB1, got %+v", got)
+	}
+
+	// Re-applying after a new member joins only adds that member's pairs.
+	members = append(members, Member{CompanyID: 5})
+	existing = append(existing[:1], Rule{SourceCompanyID: 2, TargetCompanyID: 1, AccountSource: "1300", AccountTarget: "2300"})
+	rules, skipped = ExpandTemplate(tpl, members, existing)
+	if len(rules) != 4 || skipped != 2 {
+		t.Fatalf("expected 4 rules for the new member and 2 skipped, got %d and %d", len(rules), skipped)
+	}
+	for _, rule := range rules {
+		if rule.SourceCompanyID != 5 && rule.TargetCompanyID != 5 {
+			t.Fatalf("unexpected rule for existing pair %+v", rule)
+		}
+	}
+	if rules[0].Name != "IC AR/AP: HQ → #5" {
+		t.Fatalf("expected id fallback label, got %q", rules[0].Name)
+	}
+}
+
+func TestExpandTemplateSameAccountCollapsesPairs(t *testing.T) {
+	tpl := RuleTemplate{ID: 1, GroupID: 2, Name: "IC loans", AccountSource: "1900", AccountTarget: "1900", CreatedBy: 9}
+	members := []Member{{CompanyID: 1}, {CompanyID: 2}, {CompanyID: 3}}
+	got, skipped := ExpandTemplate(tpl, members, nil)
+	if len(got) != 3 {
+		t.Fatalf("expected one rule per unordered pair, got %d", len(got))
+	}
+	if skipped != 0 {
+		t.Fatalf("pairs collapsed into a new rule are not skipped, got %d", skipped)
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/odyssey-erp/odyssey-erp/internal/insights"
 	insightssvg "github.com/odyssey-erp/odyssey-erp/internal/insights/svg"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 )
@@ -69,19 +70,19 @@ func NewHandler(logger *slog.Logger, service Service, templates *view.Engine, rb
 
 func (h *Handler) handleInsights(w http.ResponseWriter, r *http.Request) {
 	if h.templates == nil || h.service == nil || h.chart == nil {
-		http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		httpx.Error(w, r, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
 		return
 	}
 
 	sess := shared.SessionFromContext(r.Context())
 	if err := h.authorize(r.Context(), sess, shared.PermFinanceInsightsView); err != nil {
-		h.respondAuthError(w, err)
+		h.respondAuthError(w, r, err)
 		return
 	}
 
 	filters, err := h.parseFilters(r)
 	if err != nil {
-		h.handleFilterError(w, err)
+		h.handleFilterError(w, r, err)
 		return
 	}
 
@@ -90,13 +91,13 @@ func (h *Handler) handleInsights(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.service.Load(ctx, filters)
 	if err != nil {
-		h.handleServerError(w, "load insights", err)
+		h.handleServerError(w, r, "load insights", err)
 		return
 	}
 
 	vm, err := h.buildViewModel(filters, result)
 	if err != nil {
-		h.handleServerError(w, "build view model", err)
+		h.handleServerError(w, r, "build view model", err)
 		return
 	}
 
@@ -112,7 +113,7 @@ func (h *Handler) handleInsights(w http.ResponseWriter, r *http.Request) {
 		Data:        vm,
 	}
 	if err := h.templates.Render(w, "pages/finance/insights.html", data); err != nil {
-		h.handleServerError(w, "render template", err)
+		h.handleServerError(w, r, "render template", err)
 	}
 }
 
@@ -144,7 +145,7 @@ func (h *Handler) parseFilters(r *http.Request) (insights.CompareFilters, error)
 
 	companyStr := strings.TrimSpace(r.URL.Query().Get("company_id"))
 	if companyStr == "" {
-		companyStr = "1"
+		companyStr = strconv.FormatInt(shared.ScopedCompanyID(r.Context(), 0, 1), 10)
 	}
 	companyID, err := strconv.ParseInt(companyStr, 10, 64)
 	if err != nil || companyID <= 0 {
@@ -205,6 +206,7 @@ func (h *Handler) buildViewModel(filters insights.CompareFilters, result insight
 		Series:       points,
 		Variances:    variances,
 		Contribution: contrib,
+		Narrative:    insights.Narrative(insights.NarrativeInput{Variance: result.Variance, Contribution: result.Contribution}),
 		Chart:        chart,
 		Ready:        len(points) > 0,
 	}, nil
@@ -238,28 +240,28 @@ func (h *Handler) authorize(ctx context.Context, sess *shared.Session, perm stri
 	return errPermissionDenied
 }
 
-func (h *Handler) respondAuthError(w http.ResponseWriter, err error) {
+func (h *Handler) respondAuthError(w http.ResponseWriter, r *http.Request, err error) {
 	if errors.Is(err, errPermissionDenied) {
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		return
 	}
-	h.handleServerError(w, "authorize", err)
+	h.handleServerError(w, r, "authorize", err)
 }
 
-func (h *Handler) handleFilterError(w http.ResponseWriter, err error) {
+func (h *Handler) handleFilterError(w http.ResponseWriter, r *http.Request, err error) {
 	var v validationError
 	if errors.As(err, &v) {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
-	h.handleServerError(w, "validate filters", err)
+	h.handleServerError(w, r, "validate filters", err)
 }
 
-func (h *Handler) handleServerError(w http.ResponseWriter, message string, err error) {
+func (h *Handler) handleServerError(w http.ResponseWriter, r *http.Request, message string, err error) {
 	if h.logger != nil {
 		h.logger.Error(message, slog.Any("error", err))
 	}
-	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 }
 
 type validationError struct {
@@ -86,6 +86,9 @@ func TestInsightsRendersChart(t *testing.T) {
 	if !strings.Contains(body, "<svg>mock</svg>") {
 		t.Fatalf("expected svg chart in response: %s", body)
 	}
+	if !strings.Contains(body, "Net up 5.0% MoM, up 10.0% YoY.") {
+		t.Fatalf("expected narrative summary in response: %s", body)
+	}
 	if service.lastFilters.From != "2024-01" || service.lastFilters.To != "2024-03" {
 		t.Fatalf("unexpected filters passed to service: %+v", service.lastFilters)
 	}
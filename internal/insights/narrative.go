@@ -0,0 +1,130 @@
+package insights
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// NotableMoMPct is the month-on-month move, in percent, a metric needs
+	// before the narrative mentions it.
+	NotableMoMPct = 5.0
+	// NotableYoYPct is the year-on-year move, in percent, a metric needs
+	// before the narrative mentions it.
+	NotableYoYPct = 10.0
+	// ConcentrationPct is the revenue share above which a single branch is
+	// called out.
+	ConcentrationPct = 50.0
+)
+
+// AccountVariance is one account line of a variance snapshot. Flagged lines
+// breached the threshold of their variance rule.
+type AccountVariance struct {
+	Code        string
+	Name        string
+	Variance    float64
+	VariancePct float64
+	Flagged     bool
+}
+
+// NarrativeInput carries the computed figures a narrative is written from.
+// Every part is optional; rules without data stay silent.
+type NarrativeInput struct {
+	Variance     []VarianceMetric
+	Contribution []ContributionShare
+	Accounts     []AccountVariance
+}
+
+// Narrative turns the computed insights into short plain-language sentences,
+// most important first. The rules are fixed, so the same input always gives
+// the same text.
+func Narrative(in NarrativeInput) []string {
+	var sentences []string
+	sentences = append(sentences, metricSentences(in.Variance)...)
+	if s := concentrationSentence(in.Contribution); s != "" {
+		sentences = append(sentences, s)
+	}
+	if s := anomalySentence(in.Accounts); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// metricSentences describes every metric that moved by at least the notable
+// MoM or YoY percentage, or says that none did.
+func metricSentences(metrics []VarianceMetric) []string {
+	var sentences, steady []string
+	for _, m := range metrics {
+		var moves []string
+		if math.Abs(m.MoMPct) >= NotableMoMPct {
+			moves = append(moves, describeMove(m.MoMPct)+" MoM")
+		}
+		if math.Abs(m.YoYPct) >= NotableYoYPct {
+			moves = append(moves, describeMove(m.YoYPct)+" YoY")
+		}
+		if len(moves) == 0 {
+			steady = append(steady, m.Metric)
+			continue
+		}
+		sentences = append(sentences, fmt.Sprintf("%s %s.", m.Metric, strings.Join(moves, ", ")))
+	}
+	if len(sentences) == 0 && len(steady) > 0 {
+		return []string{fmt.Sprintf("%s broadly flat: no move above %.0f%% MoM or %.0f%% YoY.",
+			strings.Join(steady, " and "), NotableMoMPct, NotableYoYPct)}
+	}
+	return sentences
+}
+
+func describeMove(pct float64) string {
+	direction := "up"
+	if pct < 0 {
+		direction = "down"
+	}
+	return fmt.Sprintf("%s %.1f%%", direction, math.Abs(pct))
+}
+
+// concentrationSentence calls out a branch earning more than
+// ConcentrationPct of revenue when several branches contribute.
+func concentrationSentence(shares []ContributionShare) string {
+	if len(shares) < 2 {
+		return ""
+	}
+	top := shares[0]
+	for _, s := range shares[1:] {
+		if s.RevenuePct > top.RevenuePct {
+			top = s
+		}
+	}
+	if top.RevenuePct <= ConcentrationPct {
+		return ""
+	}
+	return fmt.Sprintf("Revenue is concentrated: %s brought in %.1f%% of the total.", top.Branch, top.RevenuePct)
+}
+
+// anomalySentence counts the flagged account variances and names the
+// largest one.
+func anomalySentence(accounts []AccountVariance) string {
+	if len(accounts) == 0 {
+		return ""
+	}
+	var flagged []AccountVariance
+	for _, a := range accounts {
+		if a.Flagged {
+			flagged = append(flagged, a)
+		}
+	}
+	if len(flagged) == 0 {
+		return "No account breached its variance threshold."
+	}
+	sort.SliceStable(flagged, func(i, j int) bool {
+		return math.Abs(flagged[i].Variance) > math.Abs(flagged[j].Variance)
+	})
+	largest := flagged[0]
+	subject := "1 account breached its variance threshold"
+	if len(flagged) > 1 {
+		subject = fmt.Sprintf("%d accounts breached their variance threshold", len(flagged))
+	}
+	return fmt.Sprintf("%s; the largest is %s %s, %s.", subject, largest.Code, largest.Name, describeMove(largest.VariancePct))
+}
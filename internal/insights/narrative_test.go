@@ -0,0 +1,50 @@
+package insights
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNarrativeHighlightsNotableChanges(t *testing.T) {
+	got := Narrative(NarrativeInput{
+		Variance: []VarianceMetric{
+			{Metric: "Net", MoMPct: 2, YoYPct: -4},
+			{Metric: "Revenue", MoMPct: 8.04, YoYPct: -12.5},
+		},
+		Contribution: []ContributionShare{
+			{Branch: "Branch 1", RevenuePct: 35},
+			{Branch: "Branch 2", RevenuePct: 65},
+		},
+		Accounts: []AccountVariance{
+			{Code: "5100", Name: "Salaries", Variance: 1200, VariancePct: 45, Flagged: true},
+			{Code: "4000", Name: "Sales", Variance: -5000, VariancePct: -20, Flagged: true},
+			{Code: "6100", Name: "Rent", Variance: 9000, VariancePct: 3},
+		},
+	})
+	want := []string{
+		"Revenue up 8.0% MoM, down 12.5% YoY.",
+		"Revenue is concentrated: Branch 2 brought in 65.0% of the total.",
+		"2 accounts breached their variance threshold; the largest is 4000 Sales, down 20.0%.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected narrative:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestNarrativeReportsQuietPeriods(t *testing.T) {
+	got := Narrative(NarrativeInput{
+		Variance:     []VarianceMetric{{Metric: "Net", MoMPct: 1}, {Metric: "Revenue", MoMPct: -4.9, YoYPct: 9}},
+		Contribution: []ContributionShare{{Branch: "Branch 1", RevenuePct: 100}},
+		Accounts:     []AccountVariance{{Code: "6100", Name: "Rent", Variance: 10}},
+	})
+	want := []string{
+		"Net and Revenue broadly flat: no move above 5% MoM or 10% YoY.",
+		"No account breached its variance threshold.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected narrative:\n got %q\nwant %q", got, want)
+	}
+	if got := Narrative(NarrativeInput{}); len(got) != 0 {
+		t.Fatalf("expected no sentences without data, got %q", got)
+	}
+}
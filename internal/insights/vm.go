@@ -37,6 +37,7 @@ type ViewModel struct {
         Series       []PointViewModel
         Variances    []VarianceViewModel
         Contribution []ContributionViewModel
+        Narrative    []string
         Chart        template.HTML
         Ready        bool
 }
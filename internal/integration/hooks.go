@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,13 +13,19 @@ import (
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/mappings"
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
 	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
 	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/warehouses"
+	"github.com/odyssey-erp/odyssey-erp/internal/netting"
 	"github.com/odyssey-erp/odyssey-erp/internal/procurement"
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 // Ledger exposes journal posting operations required by integrations.
 type Ledger interface {
 	PostJournal(ctx context.Context, input journals.PostingInput) (journals.JournalEntry, error)
+	SourceState(ctx context.Context, module string, ref uuid.UUID) (journals.SourceState, error)
+	CancelPendingSource(ctx context.Context, module string, ref uuid.UUID, reason string) (journals.SourceState, error)
 }
 
 // PeriodRepository provides period lookups.
@@ -31,11 +38,26 @@ type AccountMappingRepository interface {
 	Get(ctx context.Context, module, key string) (mappings.AccountMapping, error)
 }
 
+// DimensionResolver provides the default GL dimensions configured for a warehouse.
+type DimensionResolver interface {
+	GLDimensions(ctx context.Context, warehouseID int64) (warehouses.GLDimensions, error)
+}
+
+// PostingQueue decides per company and module whether integration journals
+// post straight to the ledger, and stages the others for approval.
+type PostingQueue interface {
+	AutoPostEnabled(ctx context.Context, companyID int64, module string) (bool, error)
+	StageJournal(ctx context.Context, companyID int64, module string, input journals.PostingInput) error
+}
+
 // Hooks wires domain events from operational modules into the general ledger.
 type Hooks struct {
 	ledger      Ledger
 	periodRepo  PeriodRepository
 	mappingRepo AccountMappingRepository
+	dimensions  DimensionResolver
+	rates       coreshared.RateResolver
+	queue       PostingQueue
 }
 
 // NewHooks constructs integration hooks.
@@ -43,6 +65,63 @@ func NewHooks(ledger Ledger, periodRepo PeriodRepository, mappingRepo AccountMap
 	return &Hooks{ledger: ledger, periodRepo: periodRepo, mappingRepo: mappingRepo}
 }
 
+// SetDimensionResolver enables stamping default GL dimensions on warehouse-driven postings.
+func (h *Hooks) SetDimensionResolver(resolver DimensionResolver) {
+	h.dimensions = resolver
+}
+
+// SetRateResolver converts foreign-currency AP amounts into the base currency
+// before they are journalled. Without it amounts post as-is.
+func (h *Hooks) SetRateResolver(rates coreshared.RateResolver) {
+	h.rates = rates
+}
+
+// SetPostingQueue lets companies stage integration journals for approval
+// instead of auto-posting them. Without it every journal auto-posts.
+func (h *Hooks) SetPostingQueue(queue PostingQueue) {
+	h.queue = queue
+}
+
+// toBase converts a document amount into the base currency at the rate
+// effective on the posting date.
+func (h *Hooks) toBase(ctx context.Context, amount float64, currency string, on time.Time) (float64, error) {
+	if h.rates == nil || currency == "" {
+		return amount, nil
+	}
+	base := h.rates.BaseCurrency()
+	if strings.EqualFold(currency, base) {
+		return amount, nil
+	}
+	converted, err := h.rates.Convert(ctx, amount, currency, base, on)
+	if err != nil {
+		return 0, fmt.Errorf("integration: convert %s to %s: %w", currency, base, err)
+	}
+	return converted, nil
+}
+
+// stampDimensions fills company, branch and warehouse dimensions on lines that
+// have none set, using the warehouse defaults.
+func (h *Hooks) stampDimensions(ctx context.Context, warehouseID int64, lines []journals.PostingLineInput) error {
+	if h.dimensions == nil || warehouseID == 0 {
+		return nil
+	}
+	dims, err := h.dimensions.GLDimensions(ctx, warehouseID)
+	if err != nil {
+		return fmt.Errorf("integration: resolve dimensions for warehouse %d: %w", warehouseID, err)
+	}
+	for i := range lines {
+		line := &lines[i]
+		if line.CompanyID != nil || line.BranchID != nil || line.Warehouse != nil {
+			continue
+		}
+		companyID, branchID, whID := dims.CompanyID, dims.BranchID, dims.WarehouseID
+		line.CompanyID = &companyID
+		line.BranchID = &branchID
+		line.Warehouse = &whID
+	}
+	return nil
+}
+
 func (h *Hooks) resolveAccount(ctx context.Context, module, key string) (int64, error) {
 	mapping, err := h.mappingRepo.Get(ctx, module, key)
 	if err != nil {
@@ -51,10 +130,23 @@ func (h *Hooks) resolveAccount(ctx context.Context, module, key string) (int64,
 	return mapping.AccountID, nil
 }
 
-func (h *Hooks) post(ctx context.Context, input journals.PostingInput) error {
+// post sends the journal to the ledger, or stages it for approval when the
+// company has switched auto-posting off for the module. The company is taken
+// from the lines' dimensions, else from the request's company scope.
+func (h *Hooks) post(ctx context.Context, module string, input journals.PostingInput) error {
 	if input.SourceID == uuid.Nil {
 		return errors.New("integration: source id required")
 	}
+	if h.queue != nil {
+		companyID := postingCompany(ctx, input.Lines)
+		autoPost, err := h.queue.AutoPostEnabled(ctx, companyID, module)
+		if err != nil {
+			return err
+		}
+		if !autoPost {
+			return h.queue.StageJournal(ctx, companyID, module, input)
+		}
+	}
 	_, err := h.ledger.PostJournal(ctx, input)
 	if err != nil {
 		if errors.Is(err, shared.ErrSourceAlreadyLinked) {
@@ -64,6 +156,35 @@ func (h *Hooks) post(ctx context.Context, input journals.PostingInput) error {
 	return err
 }
 
+// reverse posts the reversal of a source document's journal, but only when
+// that journal reached the ledger. Documents never journalled, or whose
+// journal was rejected, have nothing to reverse; a journal still awaiting
+// approval is rejected together with the void instead.
+func (h *Hooks) reverse(ctx context.Context, module, originalModule string, originalID uuid.UUID, input journals.PostingInput) error {
+	state, err := h.ledger.SourceState(ctx, originalModule, originalID)
+	if err != nil {
+		return err
+	}
+	if state == journals.SourceStatePending {
+		if state, err = h.ledger.CancelPendingSource(ctx, originalModule, originalID, input.Memo); err != nil {
+			return err
+		}
+	}
+	if state != journals.SourceStatePosted {
+		return nil
+	}
+	return h.post(ctx, module, input)
+}
+
+func postingCompany(ctx context.Context, lines []journals.PostingLineInput) int64 {
+	for _, line := range lines {
+		if line.CompanyID != nil && *line.CompanyID > 0 {
+			return *line.CompanyID
+		}
+	}
+	return coreshared.ScopedCompanyID(ctx, 0, 0)
+}
+
 // HandleGRNPosted posts the accounting entry for a goods receipt.
 func (h *Hooks) HandleGRNPosted(ctx context.Context, evt procurement.GRNPostedEvent) error {
 	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil {
@@ -84,14 +205,48 @@ func (h *Hooks) HandleGRNPosted(ctx context.Context, evt procurement.GRNPostedEv
 	if err != nil {
 		return err
 	}
-	var total float64
+	// Lines held for quality inspection are debited to the inspection
+	// account until they are accepted into stock or rejected. GR/IR is
+	// credited at the GRN cost; when stock is valued at the PO price the
+	// difference goes to purchase price variance.
+	var stocked, inspected, variance float64
 	for _, line := range evt.Lines {
-		total += monetary(line.Qty, line.UnitCost)
+		value := monetary(line.Qty, line.UnitCost) - line.PriceVariance
+		if line.Inspection {
+			inspected += value
+		} else {
+			stocked += value
+		}
+		variance += line.PriceVariance
 	}
-	total = round2(total)
+	stocked, inspected, variance = round2(stocked), round2(inspected), round2(variance)
+	total := round2(stocked + inspected + variance)
 	if total == 0 {
 		return nil
 	}
+	var lines []journals.PostingLineInput
+	if stocked != 0 {
+		lines = append(lines, journals.PostingLineInput{AccountID: inventoryAccount, Debit: stocked})
+	}
+	if inspected != 0 {
+		inspectionAccount, err := h.resolveAccount(ctx, "GRN", "grn.inspection")
+		if err != nil {
+			return err
+		}
+		lines = append(lines, journals.PostingLineInput{AccountID: inspectionAccount, Debit: inspected})
+	}
+	if variance != 0 {
+		ppvAccount, err := h.resolveAccount(ctx, "GRN", "grn.ppv")
+		if err != nil {
+			return err
+		}
+		if variance > 0 {
+			lines = append(lines, journals.PostingLineInput{AccountID: ppvAccount, Debit: variance})
+		} else {
+			lines = append(lines, journals.PostingLineInput{AccountID: ppvAccount, Credit: -variance})
+		}
+	}
+	lines = append(lines, journals.PostingLineInput{AccountID: grirAccount, Credit: total})
 	sourceID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("GRN:%d", evt.ID)))
 	input := journals.PostingInput{
 		PeriodID:     period.ID,
@@ -99,12 +254,59 @@ func (h *Hooks) HandleGRNPosted(ctx context.Context, evt procurement.GRNPostedEv
 		SourceModule: "PROCUREMENT.GRN",
 		SourceID:     sourceID,
 		Memo:         fmt.Sprintf("GRN %s", evt.Number),
+		Lines:        lines,
+	}
+	if err := h.stampDimensions(ctx, evt.WarehouseID, input.Lines); err != nil {
+		return err
+	}
+	return h.post(ctx, journals.IntegrationModuleGRN, input)
+}
+
+// HandleGRNInspectionDecided moves the value of inspected goods out of the
+// inspection account: into inventory when accepted, back against GR/IR when
+// rejected and returned to the supplier.
+func (h *Hooks) HandleGRNInspectionDecided(ctx context.Context, evt procurement.GRNInspectionDecidedEvent) error {
+	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil {
+		return nil
+	}
+	if evt.DecidedAt.IsZero() {
+		return errors.New("integration: inspection decision date required")
+	}
+	amount := round2(monetary(evt.Qty, evt.UnitCost))
+	if amount == 0 {
+		return nil
+	}
+	period, err := h.periodRepo.FindOpenPeriodByDate(ctx, evt.DecidedAt)
+	if err != nil {
+		return err
+	}
+	inspectionAccount, err := h.resolveAccount(ctx, "GRN", "grn.inspection")
+	if err != nil {
+		return err
+	}
+	debitKey, memo := "grn.inventory", "accepted from"
+	if evt.Decision == procurement.InspectionReject {
+		debitKey, memo = "grn.grir", "rejected in"
+	}
+	debitAccount, err := h.resolveAccount(ctx, "GRN", debitKey)
+	if err != nil {
+		return err
+	}
+	input := journals.PostingInput{
+		PeriodID:     period.ID,
+		Date:         evt.DecidedAt,
+		SourceModule: "PROCUREMENT.GRN_QC",
+		SourceID:     uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("GRNQC:%d", evt.DecisionID))),
+		Memo:         fmt.Sprintf("GRN %s %s inspection", evt.GRNNumber, memo),
 		Lines: []journals.PostingLineInput{
-			{AccountID: inventoryAccount, Debit: total},
-			{AccountID: grirAccount, Credit: total},
+			{AccountID: debitAccount, Debit: amount},
+			{AccountID: inspectionAccount, Credit: amount},
 		},
 	}
-	return h.post(ctx, input)
+	if err := h.stampDimensions(ctx, evt.WarehouseID, input.Lines); err != nil {
+		return err
+	}
+	return h.post(ctx, journals.IntegrationModuleGRN, input)
 }
 
 // HandleAPInvoicePosted posts the accounting entry for an AP invoice.
@@ -122,21 +324,19 @@ func (h *Hooks) HandleAPInvoicePosted(ctx context.Context, evt procurement.APInv
 	if err != nil {
 		return err
 	}
-	var debitKey string
-	if evt.GRNID != 0 {
-		debitKey = "ap.invoice.inventory"
-	} else {
-		debitKey = "ap.invoice.expense"
+	debitAccount, apAccount, err := h.apInvoiceAccounts(ctx, evt.GRNID)
+	if err != nil {
+		return err
 	}
-	debitAccount, err := h.resolveAccount(ctx, "AP", debitKey)
+	total, err := h.toBase(ctx, evt.Total, evt.Currency, evt.PostedAt)
 	if err != nil {
 		return err
 	}
-	apAccount, err := h.resolveAccount(ctx, "AP", "ap.invoice.ap")
+	amount := round2(total)
+	lines, err := h.apInvoiceDebits(ctx, debitAccount, evt.TaxAmount, evt.Total, amount, false)
 	if err != nil {
 		return err
 	}
-	amount := round2(evt.Total)
 	sourceID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("APINV:%d", evt.ID)))
 	input := journals.PostingInput{
 		PeriodID:     period.ID,
@@ -144,12 +344,94 @@ func (h *Hooks) HandleAPInvoicePosted(ctx context.Context, evt procurement.APInv
 		SourceModule: "PROCUREMENT.AP_INVOICE",
 		SourceID:     sourceID,
 		Memo:         fmt.Sprintf("AP Invoice %s", evt.Number),
-		Lines: []journals.PostingLineInput{
-			{AccountID: debitAccount, Debit: amount},
-			{AccountID: apAccount, Credit: amount},
-		},
+		Lines:        append(lines, journals.PostingLineInput{AccountID: apAccount, Credit: amount}),
 	}
-	return h.post(ctx, input)
+	return h.post(ctx, journals.IntegrationModuleAP, input)
+}
+
+// HandleAPInvoiceVoided reverses the AP invoice posting in the period the
+// invoice was posted to.
+func (h *Hooks) HandleAPInvoiceVoided(ctx context.Context, evt procurement.APInvoiceVoidedEvent) error {
+	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil {
+		return nil
+	}
+	if evt.PostedAt.IsZero() {
+		return errors.New("integration: AP invoice post date required")
+	}
+	if evt.Total <= 0 {
+		return nil
+	}
+	period, err := h.periodRepo.FindOpenPeriodByDate(ctx, evt.PostedAt)
+	if err != nil {
+		return err
+	}
+	debitAccount, apAccount, err := h.apInvoiceAccounts(ctx, evt.GRNID)
+	if err != nil {
+		return err
+	}
+	// Reverse at the original posting date's rate so the entry nets to zero.
+	total, err := h.toBase(ctx, evt.Total, evt.Currency, evt.PostedAt)
+	if err != nil {
+		return err
+	}
+	amount := round2(total)
+	lines, err := h.apInvoiceDebits(ctx, debitAccount, evt.TaxAmount, evt.Total, amount, true)
+	if err != nil {
+		return err
+	}
+	sourceID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("APINV-VOID:%d", evt.ID)))
+	input := journals.PostingInput{
+		PeriodID:     period.ID,
+		Date:         evt.PostedAt,
+		SourceModule: "PROCUREMENT.AP_INVOICE_VOID",
+		SourceID:     sourceID,
+		Memo:         fmt.Sprintf("Void AP Invoice %s: %s", evt.Number, evt.Reason),
+		Lines:        append([]journals.PostingLineInput{{AccountID: apAccount, Debit: amount}}, lines...),
+	}
+	originalID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("APINV:%d", evt.ID)))
+	return h.reverse(ctx, journals.IntegrationModuleAP, "PROCUREMENT.AP_INVOICE", originalID, input)
+}
+
+// apInvoiceDebits splits the base amount of an AP invoice between the goods
+// or expense account and the input tax account, in the invoice's tax share of
+// the total. Reversed lines are credits, for voids.
+func (h *Hooks) apInvoiceDebits(ctx context.Context, debitAccount int64, tax, total, amount float64, reverse bool) ([]journals.PostingLineInput, error) {
+	side := func(account int64, value float64) journals.PostingLineInput {
+		if reverse {
+			return journals.PostingLineInput{AccountID: account, Credit: value}
+		}
+		return journals.PostingLineInput{AccountID: account, Debit: value}
+	}
+	if tax <= 0 || total <= 0 {
+		return []journals.PostingLineInput{side(debitAccount, amount)}, nil
+	}
+	taxAccount, err := h.resolveAccount(ctx, "AP", "ap.invoice.tax_input")
+	if err != nil {
+		return nil, err
+	}
+	taxAmount := round2(amount * tax / total)
+	return []journals.PostingLineInput{
+		side(debitAccount, round2(amount-taxAmount)),
+		side(taxAccount, taxAmount),
+	}, nil
+}
+
+// apInvoiceAccounts resolves the debit and AP accounts of an AP invoice
+// posting; invoices matched to a goods receipt debit inventory.
+func (h *Hooks) apInvoiceAccounts(ctx context.Context, grnID int64) (int64, int64, error) {
+	debitKey := "ap.invoice.expense"
+	if grnID != 0 {
+		debitKey = "ap.invoice.inventory"
+	}
+	debitAccount, err := h.resolveAccount(ctx, "AP", debitKey)
+	if err != nil {
+		return 0, 0, err
+	}
+	apAccount, err := h.resolveAccount(ctx, "AP", "ap.invoice.ap")
+	if err != nil {
+		return 0, 0, err
+	}
+	return debitAccount, apAccount, nil
 }
 
 // HandleAPPaymentPosted posts the accounting entry for an AP payment.
@@ -167,15 +449,20 @@ func (h *Hooks) HandleAPPaymentPosted(ctx context.Context, evt procurement.APPay
 	if err != nil {
 		return err
 	}
-	apAccount, err := h.resolveAccount(ctx, "AP", "ap.payment.ap")
+	paid, err := h.toBase(ctx, evt.Amount, evt.Currency, evt.PaidAt)
 	if err != nil {
 		return err
 	}
-	cashAccount, err := h.resolveAccount(ctx, "AP", "ap.payment.cash")
+	var withheld float64
+	if evt.Withheld > 0 {
+		if withheld, err = h.toBase(ctx, evt.Withheld, evt.Currency, evt.PaidAt); err != nil {
+			return err
+		}
+	}
+	lines, err := h.apPaymentLines(ctx, paid, withheld)
 	if err != nil {
 		return err
 	}
-	amount := round2(evt.Amount)
 	sourceID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("APPAY:%d", evt.ID)))
 	input := journals.PostingInput{
 		PeriodID:     period.ID,
@@ -183,12 +470,269 @@ func (h *Hooks) HandleAPPaymentPosted(ctx context.Context, evt procurement.APPay
 		SourceModule: "PROCUREMENT.AP_PAYMENT",
 		SourceID:     sourceID,
 		Memo:         fmt.Sprintf("AP Payment %s", evt.Number),
+		Lines:        lines,
+	}
+	return h.post(ctx, journals.IntegrationModuleAP, input)
+}
+
+// HandleAPPaymentRunPosted posts one combined entry for every payment created by an AP payment run.
+func (h *Hooks) HandleAPPaymentRunPosted(ctx context.Context, evt procurement.APPaymentRunPostedEvent) error {
+	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil {
+		return nil
+	}
+	if evt.PaidAt.IsZero() {
+		return errors.New("integration: AP payment run date required")
+	}
+	if evt.Amount <= 0 {
+		return nil
+	}
+	period, err := h.periodRepo.FindOpenPeriodByDate(ctx, evt.PaidAt)
+	if err != nil {
+		return err
+	}
+	paid, err := h.sumToBase(ctx, evt.Amount, evt.ByCurrency, evt.PaidAt)
+	if err != nil {
+		return err
+	}
+	withheld, err := h.sumToBase(ctx, evt.Withheld, evt.WithheldByCurrency, evt.PaidAt)
+	if err != nil {
+		return err
+	}
+	lines, err := h.apPaymentLines(ctx, paid, withheld)
+	if err != nil {
+		return err
+	}
+	sourceID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("APPAYRUN:%d", evt.ID)))
+	input := journals.PostingInput{
+		PeriodID:     period.ID,
+		Date:         evt.PaidAt,
+		SourceModule: "PROCUREMENT.AP_PAYMENT_RUN",
+		SourceID:     sourceID,
+		Memo:         fmt.Sprintf("AP Payment Run %s", evt.Number),
+		Lines:        lines,
+	}
+	return h.post(ctx, journals.IntegrationModuleAP, input)
+}
+
+// sumToBase converts amounts split by currency into the base currency. An
+// empty split means total is already in the base currency.
+func (h *Hooks) sumToBase(ctx context.Context, total float64, byCurrency map[string]float64, date time.Time) (float64, error) {
+	if len(byCurrency) == 0 {
+		return total, nil
+	}
+	var sum float64
+	for currency, value := range byCurrency {
+		converted, err := h.toBase(ctx, value, currency, date)
+		if err != nil {
+			return 0, err
+		}
+		sum += converted
+	}
+	return sum, nil
+}
+
+// apPaymentLines debits AP with what the payment settled and credits cash
+// with what was paid. Tax withheld from the payment is credited to the
+// withholding payable account until it is remitted to the tax office.
+func (h *Hooks) apPaymentLines(ctx context.Context, paid, withheld float64) ([]journals.PostingLineInput, error) {
+	apAccount, err := h.resolveAccount(ctx, "AP", "ap.payment.ap")
+	if err != nil {
+		return nil, err
+	}
+	cashAccount, err := h.resolveAccount(ctx, "AP", "ap.payment.cash")
+	if err != nil {
+		return nil, err
+	}
+	cash := round2(paid)
+	tax := round2(withheld)
+	lines := []journals.PostingLineInput{
+		{AccountID: apAccount, Debit: round2(cash + tax)},
+		{AccountID: cashAccount, Credit: cash},
+	}
+	if tax <= 0 {
+		return lines, nil
+	}
+	taxAccount, err := h.resolveAccount(ctx, "AP", "ap.payment.withholding")
+	if err != nil {
+		return nil, err
+	}
+	return append(lines, journals.PostingLineInput{AccountID: taxAccount, Credit: tax}), nil
+}
+
+// CreateARPostingJournal posts the accounting entry for an AR invoice: the
+// total is debited to accounts receivable and credited to revenue and output
+// tax.
+func (h *Hooks) CreateARPostingJournal(ctx context.Context, invoice *ar.ARInvoice) error {
+	return h.postARInvoice(ctx, invoice, "", false)
+}
+
+// CreateARVoidJournal reverses the AR invoice posting in the period the
+// invoice was posted to.
+func (h *Hooks) CreateARVoidJournal(ctx context.Context, invoice *ar.ARInvoice, reason string) error {
+	return h.postARInvoice(ctx, invoice, reason, true)
+}
+
+func (h *Hooks) postARInvoice(ctx context.Context, invoice *ar.ARInvoice, reason string, void bool) error {
+	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil || invoice == nil {
+		return nil
+	}
+	if invoice.PostedAt == nil || invoice.PostedAt.IsZero() {
+		return errors.New("integration: AR invoice post date required")
+	}
+	if invoice.Total <= 0 {
+		return nil
+	}
+	postedAt := *invoice.PostedAt
+	period, err := h.periodRepo.FindOpenPeriodByDate(ctx, postedAt)
+	if err != nil {
+		return err
+	}
+	// Voids reverse at the original posting date's rate so the entry nets
+	// to zero.
+	total, err := h.toBase(ctx, invoice.Total, invoice.Currency, postedAt)
+	if err != nil {
+		return err
+	}
+	amount := round2(total)
+	lines, err := h.arInvoiceLines(ctx, invoice.TaxAmount, invoice.Total, amount, void)
+	if err != nil {
+		return err
+	}
+	input := journals.PostingInput{
+		PeriodID:     period.ID,
+		Date:         postedAt,
+		SourceModule: "AR.INVOICE",
+		SourceID:     uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("ARINV:%d", invoice.ID))),
+		Memo:         fmt.Sprintf("AR Invoice %s", invoice.Number),
+		Lines:        lines,
+	}
+	if invoice.PostedBy != nil {
+		input.PostedBy = *invoice.PostedBy
+	}
+	if !void {
+		return h.post(ctx, journals.IntegrationModuleAR, input)
+	}
+	originalModule, originalID := input.SourceModule, input.SourceID
+	input.SourceModule = "AR.INVOICE_VOID"
+	input.SourceID = uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("ARINV-VOID:%d", invoice.ID)))
+	input.Memo = fmt.Sprintf("Void AR Invoice %s: %s", invoice.Number, reason)
+	if invoice.VoidedBy != nil {
+		input.PostedBy = *invoice.VoidedBy
+	}
+	return h.reverse(ctx, journals.IntegrationModuleAR, originalModule, originalID, input)
+}
+
+// CreateARCreditNoteJournal reverses receivables and revenue by the credit
+// note's amount, on the date it was raised.
+func (h *Hooks) CreateARCreditNoteJournal(ctx context.Context, note *ar.ARCreditNote) error {
+	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil || note == nil {
+		return nil
+	}
+	if note.CreatedAt.IsZero() {
+		return errors.New("integration: AR credit note date required")
+	}
+	if note.Total <= 0 {
+		return nil
+	}
+	period, err := h.periodRepo.FindOpenPeriodByDate(ctx, note.CreatedAt)
+	if err != nil {
+		return err
+	}
+	total, err := h.toBase(ctx, note.Total, note.Currency, note.CreatedAt)
+	if err != nil {
+		return err
+	}
+	lines, err := h.arInvoiceLines(ctx, note.TaxAmount, note.Total, round2(total), true)
+	if err != nil {
+		return err
+	}
+	input := journals.PostingInput{
+		PeriodID:     period.ID,
+		Date:         note.CreatedAt,
+		SourceModule: "AR.CREDIT_NOTE",
+		SourceID:     uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("ARCN:%d", note.ID))),
+		Memo:         fmt.Sprintf("AR Credit Note %s", note.Number),
+		PostedBy:     note.CreatedBy,
+		Lines:        lines,
+	}
+	return h.post(ctx, journals.IntegrationModuleAR, input)
+}
+
+// arInvoiceLines debits accounts receivable with the base amount of an AR
+// invoice and credits revenue and output tax, in the invoice's tax share of
+// the total. Reversed lines swap sides, for voids.
+func (h *Hooks) arInvoiceLines(ctx context.Context, tax, total, amount float64, reverse bool) ([]journals.PostingLineInput, error) {
+	side := func(account int64, value float64, debit bool) journals.PostingLineInput {
+		if debit != reverse {
+			return journals.PostingLineInput{AccountID: account, Debit: value}
+		}
+		return journals.PostingLineInput{AccountID: account, Credit: value}
+	}
+	arAccount, err := h.resolveAccount(ctx, "AR", "ar.invoice.ar")
+	if err != nil {
+		return nil, err
+	}
+	revenueAccount, err := h.resolveAccount(ctx, "AR", "ar.invoice.revenue")
+	if err != nil {
+		return nil, err
+	}
+	lines := []journals.PostingLineInput{side(arAccount, amount, true)}
+	if tax <= 0 || total <= 0 {
+		return append(lines, side(revenueAccount, amount, false)), nil
+	}
+	taxAccount, err := h.resolveAccount(ctx, "AR", "ar.invoice.tax_output")
+	if err != nil {
+		return nil, err
+	}
+	taxAmount := round2(amount * tax / total)
+	return append(lines,
+		side(revenueAccount, round2(amount-taxAmount), false),
+		side(taxAccount, taxAmount, false),
+	), nil
+}
+
+// HandleNettingSettled posts the entry for an AR/AP netting settlement: the
+// offset amount is debited to accounts payable and credited to accounts
+// receivable, with no cash movement.
+func (h *Hooks) HandleNettingSettled(ctx context.Context, evt netting.SettledEvent) error {
+	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil {
+		return nil
+	}
+	if evt.SettledAt.IsZero() {
+		return errors.New("integration: netting settlement date required")
+	}
+	if evt.Amount <= 0 {
+		return nil
+	}
+	period, err := h.periodRepo.FindOpenPeriodByDate(ctx, evt.SettledAt)
+	if err != nil {
+		return err
+	}
+	amount, err := h.toBase(ctx, evt.Amount, evt.Currency, evt.SettledAt)
+	if err != nil {
+		return err
+	}
+	apAccount, err := h.resolveAccount(ctx, "NETTING", "netting.ap")
+	if err != nil {
+		return err
+	}
+	arAccount, err := h.resolveAccount(ctx, "NETTING", "netting.ar")
+	if err != nil {
+		return err
+	}
+	amount = round2(amount)
+	input := journals.PostingInput{
+		PeriodID:     period.ID,
+		Date:         evt.SettledAt,
+		SourceModule: netting.JournalSourceModule,
+		SourceID:     netting.JournalSourceID(evt.ID),
+		Memo:         fmt.Sprintf("AR/AP Netting %s", evt.Number),
 		Lines: []journals.PostingLineInput{
 			{AccountID: apAccount, Debit: amount},
-			{AccountID: cashAccount, Credit: amount},
+			{AccountID: arAccount, Credit: amount},
 		},
 	}
-	return h.post(ctx, input)
+	return h.post(ctx, journals.IntegrationModuleNetting, input)
 }
 
 // HandleInventoryAdjustmentPosted posts the accounting entry for inventory adjustments.
@@ -244,8 +788,139 @@ func (h *Hooks) HandleInventoryAdjustmentPosted(ctx context.Context, evt invento
 		Memo:         memo,
 		Lines:        lines,
 	}
-	return h.post(ctx, input)
+	if err := h.stampDimensions(ctx, evt.WarehouseID, input.Lines); err != nil {
+		return err
+	}
+	return h.post(ctx, journals.IntegrationModuleInventory, input)
+}
+
+// HandleInventoryAdjustmentBatchPosted posts the adjustments of a stock count
+// as one journal. Amounts are netted per account, so the entry has at most one
+// line each for inventory, gain and loss, stamped with the warehouse
+// dimensions.
+func (h *Hooks) HandleInventoryAdjustmentBatchPosted(ctx context.Context, evt inventory.AdjustmentBatchPostedEvent) error {
+	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil {
+		return nil
+	}
+	if evt.PostedAt.IsZero() {
+		return errors.New("integration: adjustment post date required")
+	}
+	inventoryAccount, err := h.resolveAccount(ctx, "INVENTORY", "inventory.adjustment.inventory")
+	if err != nil {
+		return err
+	}
+	gainAccount, err := h.resolveAccount(ctx, "INVENTORY", "inventory.adjustment.gain")
+	if err != nil {
+		return err
+	}
+	lossAccount, err := h.resolveAccount(ctx, "INVENTORY", "inventory.adjustment.loss")
+	if err != nil {
+		return err
+	}
+	net := make(map[int64]float64, 3)
+	for _, line := range evt.Lines {
+		amount := round2(abs(line.Qty) * line.UnitCost)
+		if amount == 0 {
+			continue
+		}
+		if line.Qty > 0 {
+			net[inventoryAccount] += amount
+			net[gainAccount] -= amount
+		} else {
+			net[lossAccount] += amount
+			net[inventoryAccount] -= amount
+		}
+	}
+	lines := make([]journals.PostingLineInput, 0, 3)
+	for _, accountID := range []int64{inventoryAccount, gainAccount, lossAccount} {
+		amount, ok := net[accountID]
+		if !ok {
+			continue
+		}
+		delete(net, accountID)
+		switch amount = round2(amount); {
+		case amount > 0:
+			lines = append(lines, journals.PostingLineInput{AccountID: accountID, Debit: amount})
+		case amount < 0:
+			lines = append(lines, journals.PostingLineInput{AccountID: accountID, Credit: -amount})
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	period, err := h.periodRepo.FindOpenPeriodByDate(ctx, evt.PostedAt)
+	if err != nil {
+		return err
+	}
+	input := journals.PostingInput{
+		PeriodID:     period.ID,
+		Date:         evt.PostedAt,
+		SourceModule: "INVENTORY.ADJUSTMENT",
+		SourceID:     uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("STOCKCOUNT:%s", evt.Code))),
+		Memo:         fmt.Sprintf("Stock Count %s (%d items)", evt.Code, len(evt.Lines)),
+		Lines:        lines,
+	}
+	if err := h.stampDimensions(ctx, evt.WarehouseID, input.Lines); err != nil {
+		return err
+	}
+	return h.post(ctx, journals.IntegrationModuleInventory, input)
+}
+
+// HandleInventoryRevaluationPosted posts the net value change of an inventory
+// cost recalculation against the adjustment gain or loss account.
+func (h *Hooks) HandleInventoryRevaluationPosted(ctx context.Context, evt inventory.RevaluationPostedEvent) error {
+	if h == nil || h.ledger == nil || h.periodRepo == nil || h.mappingRepo == nil {
+		return nil
+	}
+	if evt.PostedAt.IsZero() {
+		return errors.New("integration: revaluation post date required")
+	}
+	amount := round2(abs(evt.Amount))
+	if amount == 0 {
+		return nil
+	}
+	period, err := h.periodRepo.FindOpenPeriodByDate(ctx, evt.PostedAt)
+	if err != nil {
+		return err
+	}
+	inventoryAccount, err := h.resolveAccount(ctx, "INVENTORY", "inventory.adjustment.inventory")
+	if err != nil {
+		return err
+	}
+	var lines []journals.PostingLineInput
+	if evt.Amount > 0 {
+		gainAccount, err := h.resolveAccount(ctx, "INVENTORY", "inventory.adjustment.gain")
+		if err != nil {
+			return err
+		}
+		lines = []journals.PostingLineInput{
+			{AccountID: inventoryAccount, Debit: amount},
+			{AccountID: gainAccount, Credit: amount},
+		}
+	} else {
+		lossAccount, err := h.resolveAccount(ctx, "INVENTORY", "inventory.adjustment.loss")
+		if err != nil {
+			return err
+		}
+		lines = []journals.PostingLineInput{
+			{AccountID: lossAccount, Debit: amount},
+			{AccountID: inventoryAccount, Credit: amount},
+		}
+	}
+	input := journals.PostingInput{
+		PeriodID:     period.ID,
+		Date:         evt.PostedAt,
+		SourceModule: "INVENTORY.REVALUATION",
+		SourceID:     uuid.NewSHA1(uuid.Nil, []byte("REVAL:"+evt.Code)),
+		Memo:         fmt.Sprintf("Inventory Cost Recalculation %s", evt.Code),
+		Lines:        lines,
+	}
+	if err := h.stampDimensions(ctx, evt.WarehouseID, input.Lines); err != nil {
+		return err
+	}
+	return h.post(ctx, journals.IntegrationModuleInventory, input)
 }
 
 var _ procurement.IntegrationHandler = (*Hooks)(nil)
 var _ inventory.IntegrationHandler = (*Hooks)(nil)
+var _ netting.IntegrationHandler = (*Hooks)(nil)
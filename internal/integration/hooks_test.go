@@ -0,0 +1,334 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/journals"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/mappings"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/periods"
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/warehouses"
+)
+
+type recordingLedger struct {
+	posted    []journals.PostingInput
+	staged    map[uuid.UUID]journals.SourceState
+	cancelled []uuid.UUID
+}
+
+func (l *recordingLedger) PostJournal(ctx context.Context, input journals.PostingInput) (journals.JournalEntry, error) {
+	l.posted = append(l.posted, input)
+	return journals.JournalEntry{ID: int64(len(l.posted))}, nil
+}
+
+func (l *recordingLedger) SourceState(ctx context.Context, module string, ref uuid.UUID) (journals.SourceState, error) {
+	for _, input := range l.posted {
+		if input.SourceModule == module && input.SourceID == ref {
+			return journals.SourceStatePosted, nil
+		}
+	}
+	return l.staged[ref], nil
+}
+
+func (l *recordingLedger) CancelPendingSource(ctx context.Context, module string, ref uuid.UUID, reason string) (journals.SourceState, error) {
+	if l.staged[ref] != journals.SourceStatePending {
+		return l.staged[ref], nil
+	}
+	l.cancelled = append(l.cancelled, ref)
+	l.staged[ref] = journals.SourceStateRejected
+	return journals.SourceStateRejected, nil
+}
+
+type openPeriods struct{}
+
+func (openPeriods) FindOpenPeriodByDate(ctx context.Context, date time.Time) (periods.Period, error) {
+	return periods.Period{ID: 9, Status: periods.PeriodStatusOpen}, nil
+}
+
+type mappingTable map[string]int64
+
+func (m mappingTable) Get(ctx context.Context, module, key string) (mappings.AccountMapping, error) {
+	id, ok := m[module+"/"+key]
+	if !ok {
+		return mappings.AccountMapping{}, shared.ErrMappingNotFound
+	}
+	return mappings.AccountMapping{Module: module, Key: key, AccountID: id}, nil
+}
+
+func arMappings() mappingTable {
+	return mappingTable{
+		"AR/ar.invoice.ar":         1100,
+		"AR/ar.invoice.revenue":    4100,
+		"AR/ar.invoice.tax_output": 2300,
+	}
+}
+
+func TestCreateARVoidJournalReversesThePosting(t *testing.T) {
+	ledger := &recordingLedger{}
+	hooks := NewHooks(ledger, openPeriods{}, arMappings())
+	postedAt := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	postedBy, voidedBy := int64(3), int64(4)
+	invoice := &ar.ARInvoice{
+		ID:        42,
+		Number:    "INV-042",
+		Subtotal:  100,
+		TaxAmount: 11,
+		Total:     111,
+		Status:    ar.ARStatusPosted,
+		PostedAt:  &postedAt,
+		PostedBy:  &postedBy,
+	}
+
+	if err := hooks.CreateARPostingJournal(context.Background(), invoice); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	invoice.VoidedBy = &voidedBy
+	if err := hooks.CreateARVoidJournal(context.Background(), invoice, "Customer cancelled"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	if len(ledger.posted) != 2 {
+		t.Fatalf("expected posting and reversal, got %d journals", len(ledger.posted))
+	}
+	posting, reversal := ledger.posted[0], ledger.posted[1]
+	if reversal.SourceModule != "AR.INVOICE_VOID" || reversal.SourceID == posting.SourceID {
+		t.Fatalf("expected a separate void source, got %s %s", reversal.SourceModule, reversal.SourceID)
+	}
+	if !reversal.Date.Equal(postedAt) || reversal.PeriodID != 9 || reversal.PostedBy != voidedBy {
+		t.Fatalf("expected the reversal on the posting date by the voiding user, got %+v", reversal)
+	}
+	if reversal.Memo != "Void AR Invoice INV-042: Customer cancelled" {
+		t.Fatalf("unexpected memo %q", reversal.Memo)
+	}
+
+	want := []journals.PostingLineInput{
+		{AccountID: 1100, Debit: 111},
+		{AccountID: 4100, Credit: 100},
+		{AccountID: 2300, Credit: 11},
+	}
+	if len(posting.Lines) != len(want) || len(reversal.Lines) != len(want) {
+		t.Fatalf("expected %d lines each, got %+v and %+v", len(want), posting.Lines, reversal.Lines)
+	}
+	net := map[int64]float64{}
+	for i, line := range posting.Lines {
+		if line.AccountID != want[i].AccountID || line.Debit != want[i].Debit || line.Credit != want[i].Credit {
+			t.Fatalf("posting line %d: expected %+v, got %+v", i+1, want[i], line)
+		}
+		undo := reversal.Lines[i]
+		if undo.AccountID != line.AccountID || undo.Debit != line.Credit || undo.Credit != line.Debit {
+			t.Fatalf("reversal line %d: expected the opposite of %+v, got %+v", i+1, line, undo)
+		}
+		net[line.AccountID] += line.Debit - line.Credit + undo.Debit - undo.Credit
+	}
+	for account, balance := range net {
+		if balance != 0 {
+			t.Fatalf("account %d should net to zero, got %v", account, balance)
+		}
+	}
+}
+
+func TestCreateARVoidJournalSkipsUnjournalledInvoice(t *testing.T) {
+	ledger := &recordingLedger{}
+	hooks := NewHooks(ledger, openPeriods{}, arMappings())
+	postedAt := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	invoice := &ar.ARInvoice{ID: 43, Number: "INV-043", Total: 111, Status: ar.ARStatusPosted, PostedAt: &postedAt}
+
+	if err := hooks.CreateARVoidJournal(context.Background(), invoice, "Customer cancelled"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	if len(ledger.posted) != 0 {
+		t.Fatalf("an invoice never journalled has nothing to reverse, got %+v", ledger.posted)
+	}
+}
+
+func TestCreateARVoidJournalCancelsPendingPosting(t *testing.T) {
+	original := uuid.NewSHA1(uuid.Nil, []byte("ARINV:44"))
+	ledger := &recordingLedger{staged: map[uuid.UUID]journals.SourceState{original: journals.SourceStatePending}}
+	hooks := NewHooks(ledger, openPeriods{}, arMappings())
+	postedAt := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	invoice := &ar.ARInvoice{ID: 44, Number: "INV-044", Total: 111, Status: ar.ARStatusPosted, PostedAt: &postedAt}
+
+	if err := hooks.CreateARVoidJournal(context.Background(), invoice, "Customer cancelled"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	if len(ledger.posted) != 0 {
+		t.Fatalf("a pending posting should be cancelled, not reversed, got %+v", ledger.posted)
+	}
+	if len(ledger.cancelled) != 1 || ledger.cancelled[0] != original {
+		t.Fatalf("expected the pending posting to be cancelled, got %v", ledger.cancelled)
+	}
+}
+
+func TestCreateARVoidJournalSkipsRejectedPosting(t *testing.T) {
+	original := uuid.NewSHA1(uuid.Nil, []byte("ARINV:45"))
+	ledger := &recordingLedger{staged: map[uuid.UUID]journals.SourceState{original: journals.SourceStateRejected}}
+	hooks := NewHooks(ledger, openPeriods{}, arMappings())
+	postedAt := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	invoice := &ar.ARInvoice{ID: 45, Number: "INV-045", Total: 111, Status: ar.ARStatusPosted, PostedAt: &postedAt}
+
+	if err := hooks.CreateARVoidJournal(context.Background(), invoice, "Customer cancelled"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	if len(ledger.posted) != 0 || len(ledger.cancelled) != 0 {
+		t.Fatalf("a rejected posting has nothing to reverse, got %+v %v", ledger.posted, ledger.cancelled)
+	}
+}
+
+func TestCreateARCreditNoteJournalReversesRevenue(t *testing.T) {
+	ledger := &recordingLedger{}
+	hooks := NewHooks(ledger, openPeriods{}, arMappings())
+	note := &ar.ARCreditNote{
+		ID:        5,
+		Number:    "CN-005",
+		Subtotal:  270,
+		TaxAmount: 29.7,
+		Total:     299.7,
+		CreatedBy: 4,
+		CreatedAt: time.Date(2026, 9, 12, 0, 0, 0, 0, time.UTC),
+	}
+	if err := hooks.CreateARCreditNoteJournal(context.Background(), note); err != nil {
+		t.Fatalf("credit note: %v", err)
+	}
+	if len(ledger.posted) != 1 {
+		t.Fatalf("expected one journal, got %d", len(ledger.posted))
+	}
+	got := ledger.posted[0]
+	if got.SourceModule != "AR.CREDIT_NOTE" || got.PostedBy != 4 || !got.Date.Equal(note.CreatedAt) {
+		t.Fatalf("unexpected journal header %+v", got)
+	}
+	want := []journals.PostingLineInput{
+		{AccountID: 1100, Credit: 299.7},
+		{AccountID: 4100, Debit: 270},
+		{AccountID: 2300, Debit: 29.7},
+	}
+	if len(got.Lines) != len(want) {
+		t.Fatalf("expected %d lines, got %+v", len(want), got.Lines)
+	}
+	for i, line := range got.Lines {
+		if line.AccountID != want[i].AccountID || line.Debit != want[i].Debit || line.Credit != want[i].Credit {
+			t.Fatalf("line %d: expected %+v, got %+v", i+1, want[i], line)
+		}
+	}
+}
+
+func TestCreateARPostingJournalNeedsPostDate(t *testing.T) {
+	ledger := &recordingLedger{}
+	hooks := NewHooks(ledger, openPeriods{}, arMappings())
+	err := hooks.CreateARPostingJournal(context.Background(), &ar.ARInvoice{ID: 1, Total: 50})
+	if err == nil {
+		t.Fatalf("expected an error without a post date")
+	}
+	if len(ledger.posted) != 0 {
+		t.Fatalf("nothing should be posted, got %+v", ledger.posted)
+	}
+}
+
+// dimensionMaster resolves warehouse dimensions the way
+// GetWarehouseGLDimensions does: warehouse override, then branch override,
+// then the warehouse's own branch and that branch's company.
+type dimensionMaster struct {
+	warehouseBranch     map[int64]int64
+	warehouseCompanyDim map[int64]int64 // warehouses.gl_dim_company_id
+	warehouseBranchDim  map[int64]int64 // warehouses.gl_dim_branch_id
+	branchCompany       map[int64]int64
+	branchCompanyDim    map[int64]int64 // branches.gl_dim_company_id
+}
+
+func (m dimensionMaster) GLDimensions(ctx context.Context, warehouseID int64) (warehouses.GLDimensions, error) {
+	branchID, ok := m.warehouseBranch[warehouseID]
+	if !ok {
+		return warehouses.GLDimensions{}, errors.New("no rows in result set")
+	}
+	dims := warehouses.GLDimensions{CompanyID: m.branchCompany[branchID], BranchID: branchID, WarehouseID: warehouseID}
+	if company, ok := m.branchCompanyDim[branchID]; ok {
+		dims.CompanyID = company
+	}
+	if company, ok := m.warehouseCompanyDim[warehouseID]; ok {
+		dims.CompanyID = company
+	}
+	if branch, ok := m.warehouseBranchDim[warehouseID]; ok {
+		dims.BranchID = branch
+	}
+	return dims, nil
+}
+
+func TestStampDimensions(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	// Warehouse 100 sits in branch 10 of company 1 with no overrides.
+	// Warehouse 200 sits in branch 20, whose company dimension is overridden
+	// to 5. Warehouse 300 sits in branch 20 too but overrides both its
+	// company (7) and branch (70).
+	master := dimensionMaster{
+		warehouseBranch:     map[int64]int64{100: 10, 200: 20, 300: 20},
+		warehouseCompanyDim: map[int64]int64{300: 7},
+		warehouseBranchDim:  map[int64]int64{300: 70},
+		branchCompany:       map[int64]int64{10: 1, 20: 2},
+		branchCompanyDim:    map[int64]int64{20: 5},
+	}
+
+	type dims struct{ company, branch, warehouse *int64 }
+	cases := []struct {
+		name      string
+		warehouse int64
+		line      journals.PostingLineInput
+		want      dims
+	}{
+		{"branch and company of the warehouse", 100, journals.PostingLineInput{}, dims{id(1), id(10), id(100)}},
+		{"branch company override", 200, journals.PostingLineInput{}, dims{id(5), id(20), id(200)}},
+		{"warehouse overrides win", 300, journals.PostingLineInput{}, dims{id(7), id(70), id(300)}},
+		{"explicit company is kept", 100, journals.PostingLineInput{CompanyID: id(9)}, dims{id(9), nil, nil}},
+		{"explicit branch is kept", 100, journals.PostingLineInput{BranchID: id(11)}, dims{nil, id(11), nil}},
+		{"explicit warehouse is kept", 100, journals.PostingLineInput{Warehouse: id(101)}, dims{nil, nil, id(101)}},
+		{"no warehouse leaves the line alone", 0, journals.PostingLineInput{}, dims{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			hooks := NewHooks(&recordingLedger{}, openPeriods{}, mappingTable{})
+			hooks.SetDimensionResolver(master)
+			lines := []journals.PostingLineInput{tc.line}
+			if err := hooks.stampDimensions(context.Background(), tc.warehouse, lines); err != nil {
+				t.Fatalf("stampDimensions: %v", err)
+			}
+			got := lines[0]
+			for _, field := range []struct {
+				name      string
+				got, want *int64
+			}{
+				{"company", got.CompanyID, tc.want.company},
+				{"branch", got.BranchID, tc.want.branch},
+				{"warehouse", got.Warehouse, tc.want.warehouse},
+			} {
+				if (field.got == nil) != (field.want == nil) || (field.got != nil && *field.got != *field.want) {
+					t.Fatalf("%s: expected %v, got %v", field.name, deref(field.want), deref(field.got))
+				}
+			}
+		})
+	}
+}
+
+func TestStampDimensionsWithoutResolverOrWarehouse(t *testing.T) {
+	hooks := NewHooks(&recordingLedger{}, openPeriods{}, mappingTable{})
+	lines := []journals.PostingLineInput{{AccountID: 1, Debit: 10}}
+	if err := hooks.stampDimensions(context.Background(), 100, lines); err != nil {
+		t.Fatalf("without resolver: %v", err)
+	}
+	if lines[0].CompanyID != nil || lines[0].BranchID != nil || lines[0].Warehouse != nil {
+		t.Fatalf("expected no dimensions without a resolver, got %+v", lines[0])
+	}
+
+	hooks.SetDimensionResolver(dimensionMaster{})
+	if err := hooks.stampDimensions(context.Background(), 999, lines); err == nil {
+		t.Fatalf("expected an error for an unknown warehouse")
+	}
+}
+
+func deref(v *int64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,221 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ABCClass ranks a product by its share of consumption value.
+type ABCClass string
+
+const (
+	// ABCClassA covers the few products making up most of the consumption value.
+	ABCClassA ABCClass = "A"
+	// ABCClassB covers the middle band.
+	ABCClassB ABCClass = "B"
+	// ABCClassC covers the long tail, including products without consumption.
+	ABCClassC ABCClass = "C"
+)
+
+// DefaultABCMonths is the trailing period used when none is given.
+const DefaultABCMonths = 12
+
+// ABCThresholds are the cumulative value percentages closing class A and B;
+// whatever remains is class C.
+type ABCThresholds struct {
+	A float64
+	B float64
+}
+
+// DefaultABCThresholds is the usual 80/15/5 split.
+var DefaultABCThresholds = ABCThresholds{A: 80, B: 15}
+
+// ErrABCCompanyRequired indicates an analysis requested without a company.
+var ErrABCCompanyRequired = errors.New("inventory: ABC analysis requires a company")
+
+// ErrInvalidABCThresholds indicates thresholds that leave no room for a class.
+var ErrInvalidABCThresholds = errors.New("inventory: ABC thresholds must be positive and total below 100%")
+
+// Validate ensures every class gets a non-empty band.
+func (t ABCThresholds) Validate() error {
+	if t.A <= 0 || t.B <= 0 || t.A+t.B >= 100 {
+		return ErrInvalidABCThresholds
+	}
+	return nil
+}
+
+// String renders the split as e.g. 80/15/5.
+func (t ABCThresholds) String() string {
+	return fmt.Sprintf("%g/%g/%g", t.A, t.B, 100-t.A-t.B)
+}
+
+// ABCFilter selects the consumption window for the analysis. The window ends
+// on AsOf and reaches back Months months. WarehouseID zero covers all
+// warehouses of CompanyID.
+type ABCFilter struct {
+	AsOf        time.Time
+	Months      int
+	CompanyID   int64
+	WarehouseID int64
+	Thresholds  ABCThresholds
+}
+
+// ABCLine is a product's consumption over the window and its resulting class.
+type ABCLine struct {
+	Rank            int
+	ProductID       int64
+	SKU             string
+	ProductName     string
+	CategoryID      int64
+	Qty             float64
+	Value           float64
+	Share           float64
+	CumulativeShare float64
+	Class           ABCClass
+}
+
+// ABCSummary totals one class.
+type ABCSummary struct {
+	Class    ABCClass
+	Products int
+	Value    float64
+	Share    float64
+}
+
+// ABCReport is the ranked classification for a window.
+type ABCReport struct {
+	From        time.Time
+	To          time.Time
+	CompanyID   int64
+	WarehouseID int64
+	Thresholds  ABCThresholds
+	Lines       []ABCLine
+	Summary     []ABCSummary
+	TotalValue  float64
+}
+
+// ABCClassification is a stored product class read by cycle-count planning.
+type ABCClassification struct {
+	ProductID        int64
+	Class            ABCClass
+	ConsumptionValue float64
+	Rank             int
+	PeriodStart      time.Time
+	PeriodEnd        time.Time
+	ClassifiedAt     time.Time
+}
+
+// ClassifyABC ranks lines by value and assigns classes. A product belongs to
+// class A while the cumulative share before it is under the A threshold, so
+// the product crossing the boundary stays in the higher class; the same rule
+// closes class B at A+B. Products without consumption value are always C.
+func ClassifyABC(lines []ABCLine, t ABCThresholds) ([]ABCLine, []ABCSummary, float64) {
+	ranked := append([]ABCLine(nil), lines...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Value != ranked[j].Value {
+			return ranked[i].Value > ranked[j].Value
+		}
+		return ranked[i].SKU < ranked[j].SKU
+	})
+	var total float64
+	for _, line := range ranked {
+		if line.Value > 0 {
+			total += line.Value
+		}
+	}
+	summary := []ABCSummary{{Class: ABCClassA}, {Class: ABCClassB}, {Class: ABCClassC}}
+	var cumulative float64
+	for i := range ranked {
+		line := &ranked[i]
+		line.Rank = i + 1
+		before := cumulative
+		if total > 0 && line.Value > 0 {
+			line.Share = line.Value / total * 100
+			cumulative += line.Share
+		}
+		line.CumulativeShare = cumulative
+		switch {
+		case line.Value <= 0:
+			line.Class = ABCClassC
+		case before < t.A:
+			line.Class = ABCClassA
+		case before < t.A+t.B:
+			line.Class = ABCClassB
+		default:
+			line.Class = ABCClassC
+		}
+		s := &summary[classIndex(line.Class)]
+		s.Products++
+		s.Value += line.Value
+		s.Share += line.Share
+	}
+	for i := range summary {
+		summary[i].Value = roundCurrency(summary[i].Value)
+	}
+	return ranked, summary, roundCurrency(total)
+}
+
+func classIndex(c ABCClass) int {
+	switch c {
+	case ABCClassA:
+		return 0
+	case ABCClassB:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// ABCAnalysis classifies products by consumption value over the trailing
+// window ending on filter.AsOf.
+func (s *Service) ABCAnalysis(ctx context.Context, filter ABCFilter) (ABCReport, error) {
+	if filter.AsOf.IsZero() {
+		return ABCReport{}, errors.New("inventory: analysis date required")
+	}
+	if filter.CompanyID <= 0 {
+		return ABCReport{}, ErrABCCompanyRequired
+	}
+	if filter.Months <= 0 {
+		filter.Months = DefaultABCMonths
+	}
+	if filter.Thresholds == (ABCThresholds{}) {
+		filter.Thresholds = DefaultABCThresholds
+	}
+	if err := filter.Thresholds.Validate(); err != nil {
+		return ABCReport{}, err
+	}
+	day := time.Date(filter.AsOf.Year(), filter.AsOf.Month(), filter.AsOf.Day(), 0, 0, 0, 0, filter.AsOf.Location())
+	from := day.AddDate(0, -filter.Months, 1)
+	to := day.Add(24*time.Hour - time.Nanosecond)
+	lines, err := s.repo.InventoryConsumption(ctx, from, to, filter.CompanyID, filter.WarehouseID)
+	if err != nil {
+		return ABCReport{}, err
+	}
+	ranked, summary, total := ClassifyABC(lines, filter.Thresholds)
+	return ABCReport{
+		From:        from,
+		To:          day,
+		CompanyID:   filter.CompanyID,
+		WarehouseID: filter.WarehouseID,
+		Thresholds:  filter.Thresholds,
+		Lines:       ranked,
+		Summary:     summary,
+		TotalValue:  total,
+	}, nil
+}
+
+// SaveABCClassification replaces the stored classification for the report's
+// company and warehouse scope.
+func (s *Service) SaveABCClassification(ctx context.Context, report ABCReport) error {
+	return s.repo.ReplaceABCClasses(ctx, report)
+}
+
+// ABCClassifications returns the company's stored classification for a
+// warehouse scope, zero meaning all its warehouses. Products missing from it
+// should be treated as C.
+func (s *Service) ABCClassifications(ctx context.Context, companyID, warehouseID int64) ([]ABCClassification, error) {
+	return s.repo.ListABCClasses(ctx, companyID, warehouseID)
+}
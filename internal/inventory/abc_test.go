@@ -0,0 +1,76 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (r *memoryRepo) InventoryConsumption(ctx context.Context, from, to time.Time, companyID, warehouseID int64) ([]ABCLine, error) {
+	r.consumptionFrom, r.consumptionTo = from, to
+	r.consumptionCompany = companyID
+	return r.consumption, nil
+}
+
+func (r *memoryRepo) ReplaceABCClasses(ctx context.Context, report ABCReport) error {
+	r.abcReport = report
+	return nil
+}
+
+func (r *memoryRepo) ListABCClasses(ctx context.Context, companyID, warehouseID int64) ([]ABCClassification, error) {
+	return nil, nil
+}
+
+func TestClassifyABCUsesCumulativeThresholds(t *testing.T) {
+	lines := []ABCLine{
+		{ProductID: 1, SKU: "P4", Value: 50},
+		{ProductID: 2, SKU: "P1", Value: 700},
+		{ProductID: 3, SKU: "P2", Value: 150},
+		{ProductID: 4, SKU: "P3", Value: 60},
+		{ProductID: 5, SKU: "P5", Value: 40},
+		{ProductID: 6, SKU: "P0", Value: 0},
+	}
+	ranked, summary, total := ClassifyABC(lines, DefaultABCThresholds)
+	require.InDelta(t, 1000, total, 1e-9)
+
+	got := map[string]ABCClass{}
+	for _, line := range ranked {
+		got[line.SKU] = line.Class
+	}
+	// P2 starts at 70% cumulative, so it crosses the 80% line and stays A.
+	require.Equal(t, map[string]ABCClass{"P1": "A", "P2": "A", "P3": "B", "P4": "B", "P5": "C", "P0": "C"}, got)
+	require.Equal(t, "P1", ranked[0].SKU)
+	require.Equal(t, 1, ranked[0].Rank)
+	require.InDelta(t, 91, ranked[2].CumulativeShare, 1e-9)
+	require.Equal(t, 2, summary[0].Products)
+	require.InDelta(t, 850, summary[0].Value, 1e-9)
+	require.Equal(t, 2, summary[2].Products)
+}
+
+func TestABCAnalysisWindowAndThresholds(t *testing.T) {
+	repo := newMemoryRepo()
+	repo.consumption = []ABCLine{{ProductID: 1, SKU: "X", Value: 10}}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+	ctx := context.Background()
+
+	asOf := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	_, err := svc.ABCAnalysis(ctx, ABCFilter{AsOf: asOf})
+	require.ErrorIs(t, err, ErrABCCompanyRequired)
+
+	report, err := svc.ABCAnalysis(ctx, ABCFilter{AsOf: asOf, CompanyID: 3})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), repo.consumptionCompany, "consumption must be limited to the company's warehouses")
+	require.Equal(t, int64(3), report.CompanyID)
+	require.Equal(t, time.Date(2025, 10, 17, 0, 0, 0, 0, time.UTC), repo.consumptionFrom)
+	require.Equal(t, time.Date(2026, 10, 16, 23, 59, 59, 999999999, time.UTC), repo.consumptionTo)
+	require.Equal(t, DefaultABCThresholds, report.Thresholds)
+	require.Equal(t, ABCClassA, report.Lines[0].Class)
+
+	_, err = svc.ABCAnalysis(ctx, ABCFilter{AsOf: asOf, CompanyID: 3, Thresholds: ABCThresholds{A: 90, B: 10}})
+	require.ErrorIs(t, err, ErrInvalidABCThresholds)
+
+	require.NoError(t, svc.SaveABCClassification(ctx, report))
+	require.Len(t, repo.abcReport.Lines, 1)
+}
@@ -0,0 +1,102 @@
+package inventory
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultAvailabilityLimit caps the availability view.
+	defaultAvailabilityLimit = 500
+	// maxAvailabilityLimit is the most lines the availability view returns.
+	maxAvailabilityLimit = 2000
+)
+
+// AvailabilityFilter narrows the stock availability view. Zero values match
+// everything.
+type AvailabilityFilter struct {
+	WarehouseID int64
+	// Query matches the SKU or product name, case-insensitively.
+	Query string
+	// ReservedOnly keeps the lines with stock reserved for sales orders.
+	ReservedOnly bool
+	Limit        int
+}
+
+// AvailabilityLine compares what is on hand with what sales order
+// reservations hold, in base units. Available may go negative when stock
+// left the warehouse after it was reserved.
+type AvailabilityLine struct {
+	WarehouseID   int64
+	WarehouseCode string
+	WarehouseName string
+	ProductID     int64
+	SKU           string
+	ProductName   string
+	OnHand        float64
+	Reserved      float64
+	Available     float64
+}
+
+// OverReserved reports whether reservations exceed the stock on hand.
+func (l AvailabilityLine) OverReserved() bool {
+	return l.Available < 0
+}
+
+// StockReservation is an open sales order reservation of a product in a
+// warehouse. Open is in base units.
+type StockReservation struct {
+	ID           int64
+	SalesOrderID int64
+	DocNumber    string
+	CustomerName string
+	OrderLineID  int64
+	Open         float64
+	CreatedAt    time.Time
+}
+
+// AvailabilityReport lists the availability lines with their totals.
+type AvailabilityReport struct {
+	Lines          []AvailabilityLine
+	TotalOnHand    float64
+	TotalReserved  float64
+	TotalAvailable float64
+	Truncated      bool
+}
+
+// StockAvailability returns on-hand, reserved and available quantities per
+// warehouse and product.
+func (s *Service) StockAvailability(ctx context.Context, filter AvailabilityFilter) (AvailabilityReport, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAvailabilityLimit
+	}
+	if filter.Limit > maxAvailabilityLimit {
+		filter.Limit = maxAvailabilityLimit
+	}
+	limit := filter.Limit
+	filter.Limit++
+	lines, err := s.repo.StockAvailability(ctx, filter)
+	if err != nil {
+		return AvailabilityReport{}, err
+	}
+	report := AvailabilityReport{Lines: lines}
+	if len(report.Lines) > limit {
+		report.Lines, report.Truncated = report.Lines[:limit], true
+	}
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		line.Available = line.OnHand - line.Reserved
+		report.TotalOnHand += line.OnHand
+		report.TotalReserved += line.Reserved
+	}
+	report.TotalAvailable = report.TotalOnHand - report.TotalReserved
+	return report, nil
+}
+
+// StockReservations lists the open reservations behind the reserved quantity
+// of a product in a warehouse, oldest first.
+func (s *Service) StockReservations(ctx context.Context, warehouseID, productID int64) ([]StockReservation, error) {
+	return s.repo.StockReservations(ctx, warehouseID, productID)
+}
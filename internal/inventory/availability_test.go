@@ -0,0 +1,56 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (m *memoryRepo) StockAvailability(_ context.Context, filter AvailabilityFilter) ([]AvailabilityLine, error) {
+	m.availabilityFilter = filter
+	if len(m.availability) > filter.Limit {
+		return m.availability[:filter.Limit], nil
+	}
+	return m.availability, nil
+}
+
+func (m *memoryRepo) StockReservations(context.Context, int64, int64) ([]StockReservation, error) {
+	return nil, nil
+}
+
+func TestStockAvailabilitySubtractsReservations(t *testing.T) {
+	repo := newMemoryRepo()
+	repo.availability = []AvailabilityLine{
+		{WarehouseID: 1, ProductID: 10, OnHand: 40, Reserved: 15},
+		{WarehouseID: 1, ProductID: 11, OnHand: 5, Reserved: 8},
+		{WarehouseID: 1, ProductID: 12, OnHand: 3},
+	}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+
+	report, err := svc.StockAvailability(context.Background(), AvailabilityFilter{Query: "  bolt ", Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, "bolt", repo.availabilityFilter.Query)
+	require.Equal(t, 3, repo.availabilityFilter.Limit, "one extra line detects truncation")
+	require.True(t, report.Truncated)
+	require.Len(t, report.Lines, 2)
+	require.Equal(t, 25.0, report.Lines[0].Available)
+	require.Equal(t, -3.0, report.Lines[1].Available)
+	require.True(t, report.Lines[1].OverReserved())
+	require.Equal(t, 45.0, report.TotalOnHand)
+	require.Equal(t, 23.0, report.TotalReserved)
+	require.Equal(t, 22.0, report.TotalAvailable)
+}
+
+func TestStockAvailabilityCapsLimit(t *testing.T) {
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+
+	_, err := svc.StockAvailability(context.Background(), AvailabilityFilter{})
+	require.NoError(t, err)
+	require.Equal(t, defaultAvailabilityLimit+1, repo.availabilityFilter.Limit)
+
+	_, err = svc.StockAvailability(context.Background(), AvailabilityFilter{Limit: 1 << 20})
+	require.NoError(t, err)
+	require.Equal(t, maxAvailabilityLimit+1, repo.availabilityFilter.Limit)
+}
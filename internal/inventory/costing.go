@@ -0,0 +1,206 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// costTolerance is the smallest cost difference worth correcting; stock card
+// costs are stored with four decimals.
+const costTolerance = 0.00005
+
+// CostCard is a stock card entry with its row ID, as replayed by the cost
+// recalculation.
+type CostCard struct {
+	ID int64
+	StockCardEntry
+}
+
+// CostRecalcInput selects the product, warehouse and start date to replay.
+type CostRecalcInput struct {
+	WarehouseID int64
+	ProductID   int64
+	From        time.Time
+	// DryRun computes the corrections without writing them or posting to
+	// the ledger.
+	DryRun  bool
+	ActorID int64
+}
+
+// CostCorrection is one stock card entry whose costs change.
+type CostCorrection struct {
+	CardID         int64           `json:"card_id"`
+	TxCode         string          `json:"tx_code"`
+	TxType         TransactionType `json:"tx_type"`
+	PostedAt       time.Time       `json:"posted_at"`
+	QtyIn          float64         `json:"qty_in"`
+	QtyOut         float64         `json:"qty_out"`
+	BalanceQty     float64         `json:"balance_qty"`
+	OldUnitCost    float64         `json:"old_unit_cost"`
+	NewUnitCost    float64         `json:"new_unit_cost"`
+	OldBalanceCost float64         `json:"old_balance_cost"`
+	NewBalanceCost float64         `json:"new_balance_cost"`
+}
+
+// CostRecalcResult reports what a recalculation changed or, for a dry run,
+// would change.
+type CostRecalcResult struct {
+	WarehouseID int64            `json:"warehouse_id"`
+	ProductID   int64            `json:"product_id"`
+	From        time.Time        `json:"from"`
+	DryRun      bool             `json:"dry_run"`
+	Replayed    int              `json:"replayed"`
+	Corrections []CostCorrection `json:"corrections"`
+	Qty         float64          `json:"qty"`
+	OldAvgCost  float64          `json:"old_avg_cost"`
+	NewAvgCost  float64          `json:"new_avg_cost"`
+	// ValuationAdjustment is the change in stock value, on hand quantity
+	// times the avg cost difference. Positive raises inventory.
+	ValuationAdjustment float64 `json:"valuation_adjustment"`
+	// JournalCode identifies the ledger posting; empty when nothing was posted.
+	JournalCode string `json:"journal_code,omitempty"`
+}
+
+// ErrRecalcInput is returned when warehouse, product or start date are missing.
+var ErrRecalcInput = errors.New("inventory: warehouse, product and start date required")
+
+// RecalculateCost replays the stock card of a product in a warehouse in
+// chronological order from input.From, recomputing the moving average cost.
+// Inbound entries keep their unit cost; outbound entries are re-costed at the
+// running average. Changed card costs and the balance avg cost are written in
+// one transaction and the net valuation change is posted to the ledger. A
+// dry run only returns the corrections.
+func (s *Service) RecalculateCost(ctx context.Context, input CostRecalcInput) (CostRecalcResult, error) {
+	if input.WarehouseID == 0 || input.ProductID == 0 || input.From.IsZero() {
+		return CostRecalcResult{}, ErrRecalcInput
+	}
+	now := time.Now().UTC()
+	result := CostRecalcResult{
+		WarehouseID: input.WarehouseID,
+		ProductID:   input.ProductID,
+		From:        input.From,
+		DryRun:      input.DryRun,
+	}
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		balance, err := tx.GetBalanceForUpdate(ctx, input.WarehouseID, input.ProductID)
+		if errors.Is(err, ErrBalanceNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		cards, err := tx.ListCostCards(ctx, input.WarehouseID, input.ProductID, input.From)
+		if err != nil {
+			return err
+		}
+		replayed, corrections, avg := replayCosts(cards, input.From)
+		result.Replayed = replayed
+		result.Corrections = corrections
+		result.Qty = balance.Qty
+		result.OldAvgCost = balance.AvgCost
+		result.NewAvgCost = balance.AvgCost
+		if replayed > 0 {
+			result.NewAvgCost = avg
+		}
+		result.ValuationAdjustment = math.Round(balance.Qty*(result.NewAvgCost-result.OldAvgCost)*100) / 100
+		if input.DryRun {
+			return nil
+		}
+
+		for _, c := range corrections {
+			if err := tx.UpdateCardCost(ctx, c.CardID, c.NewUnitCost, c.NewBalanceCost); err != nil {
+				return err
+			}
+		}
+		if math.Abs(result.NewAvgCost-result.OldAvgCost) > costTolerance {
+			balance.AvgCost = result.NewAvgCost
+			if err := tx.UpsertBalance(ctx, balance); err != nil {
+				return err
+			}
+		}
+		if result.ValuationAdjustment == 0 || s.integration == nil {
+			return nil
+		}
+		// Posting inside the transaction rolls the corrections back when the
+		// ledger rejects the entry, e.g. because the period is closed.
+		result.JournalCode = fmt.Sprintf("RECALC-%d-%d-%d", input.WarehouseID, input.ProductID, now.UnixNano())
+		return s.integration.HandleInventoryRevaluationPosted(ctx, RevaluationPostedEvent{
+			Code:        result.JournalCode,
+			WarehouseID: input.WarehouseID,
+			ProductID:   input.ProductID,
+			Amount:      result.ValuationAdjustment,
+			PostedAt:    now,
+		})
+	})
+	if err != nil {
+		return CostRecalcResult{}, err
+	}
+	if !input.DryRun && s.audit != nil && (len(result.Corrections) > 0 || result.JournalCode != "") {
+		_ = s.audit.Record(ctx, shared.AuditLog{
+			ActorID:  input.ActorID,
+			Action:   "inventory:cost_recalc",
+			Entity:   "inventory_balance",
+			EntityID: fmt.Sprintf("%d:%d", input.WarehouseID, input.ProductID),
+			Meta: map[string]any{
+				"from":                 input.From.Format(time.RFC3339),
+				"corrections":          len(result.Corrections),
+				"old_avg_cost":         result.OldAvgCost,
+				"new_avg_cost":         result.NewAvgCost,
+				"valuation_adjustment": result.ValuationAdjustment,
+				"journal_code":         result.JournalCode,
+			},
+		})
+	}
+	return result, nil
+}
+
+// replayCosts re-runs the moving average over cards ordered by posting time.
+// A first card dated before from is the opening balance and is not changed.
+// It returns the number of cards replayed, the cards whose costs differ and
+// the closing avg cost.
+func replayCosts(cards []CostCard, from time.Time) (int, []CostCorrection, float64) {
+	var qty, avg float64
+	if len(cards) > 0 && cards[0].PostedAt.Before(from) {
+		qty, avg = cards[0].BalanceQty, cards[0].BalanceCost
+		cards = cards[1:]
+	}
+	var corrections []CostCorrection
+	for _, card := range cards {
+		unitCost := card.UnitCost
+		newQty := card.BalanceQty
+		if card.QtyIn > 0 {
+			if newQty != 0 {
+				avg = (qty*avg + card.QtyIn*unitCost) / newQty
+			} else {
+				avg = 0
+			}
+		} else {
+			unitCost = avg
+			if newQty <= 0 {
+				avg = 0
+			}
+		}
+		qty = newQty
+		if math.Abs(unitCost-card.UnitCost) > costTolerance || math.Abs(avg-card.BalanceCost) > costTolerance {
+			corrections = append(corrections, CostCorrection{
+				CardID:         card.ID,
+				TxCode:         card.TxCode,
+				TxType:         card.TxType,
+				PostedAt:       card.PostedAt,
+				QtyIn:          card.QtyIn,
+				QtyOut:         card.QtyOut,
+				BalanceQty:     card.BalanceQty,
+				OldUnitCost:    card.UnitCost,
+				NewUnitCost:    unitCost,
+				OldBalanceCost: card.BalanceCost,
+				NewBalanceCost: avg,
+			})
+		}
+	}
+	return len(cards), corrections, avg
+}
@@ -0,0 +1,108 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (tx *memoryTx) ListCostCards(_ context.Context, _, _ int64, from time.Time) ([]CostCard, error) {
+	var out []CostCard
+	for i, card := range tx.repo.cards {
+		entry := CostCard{ID: int64(i + 1), StockCardEntry: card}
+		if card.PostedAt.Before(from) {
+			out = []CostCard{entry}
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func (tx *memoryTx) UpdateCardCost(_ context.Context, cardID int64, unitCost, balanceCost float64) error {
+	tx.repo.cards[cardID-1].UnitCost = unitCost
+	tx.repo.cards[cardID-1].BalanceCost = balanceCost
+	return nil
+}
+
+type recordingRevaluations struct {
+	recordingAdjustments
+	revaluations []RevaluationPostedEvent
+}
+
+func (r *recordingRevaluations) HandleInventoryRevaluationPosted(_ context.Context, evt RevaluationPostedEvent) error {
+	r.revaluations = append(r.revaluations, evt)
+	return nil
+}
+
+// costedRepo holds a stock card whose first receipt was corrected from 100
+// to 80 after the later movements were costed.
+func costedRepo() *memoryRepo {
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 10, 0, 0, 0, time.UTC) }
+	repo := newMemoryRepo()
+	repo.cards = []StockCardEntry{
+		{TxCode: "OPEN", TxType: TransactionTypeIn, PostedAt: day(1), QtyIn: 2, BalanceQty: 2, UnitCost: 50, BalanceCost: 50},
+		{TxCode: "GRN-1", TxType: TransactionTypeIn, PostedAt: day(2), QtyIn: 8, BalanceQty: 10, UnitCost: 80, BalanceCost: 90},
+		{TxCode: "DO-1", TxType: TransactionTypeOut, PostedAt: day(3), QtyOut: 4, BalanceQty: 6, UnitCost: 90, BalanceCost: 90},
+		{TxCode: "GRN-2", TxType: TransactionTypeIn, PostedAt: day(4), QtyIn: 10, BalanceQty: 16, UnitCost: 130, BalanceCost: 115},
+		{TxCode: "DO-2", TxType: TransactionTypeOut, PostedAt: day(5), QtyOut: 6, BalanceQty: 10, UnitCost: 115, BalanceCost: 115},
+	}
+	repo.balances[key(1, 2)] = Balance{WarehouseID: 1, ProductID: 2, Qty: 10, AvgCost: 115}
+	return repo
+}
+
+func TestRecalculateCostDryRunLeavesDataUntouched(t *testing.T) {
+	repo := costedRepo()
+	ledger := &recordingRevaluations{}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, ledger)
+
+	result, err := svc.RecalculateCost(context.Background(), CostRecalcInput{
+		WarehouseID: 1, ProductID: 2, From: time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC), DryRun: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Replayed)
+	require.Len(t, result.Corrections, 4)
+	require.InDelta(t, 74.0, result.Corrections[0].NewBalanceCost, 0.0001)
+	require.InDelta(t, 74.0, result.Corrections[1].NewUnitCost, 0.0001)
+	require.InDelta(t, 109.0, result.NewAvgCost, 0.0001)
+	require.InDelta(t, -60.0, result.ValuationAdjustment, 0.001)
+	require.Empty(t, result.JournalCode)
+
+	require.Equal(t, 90.0, repo.cards[1].BalanceCost)
+	require.Equal(t, 115.0, repo.balances[key(1, 2)].AvgCost)
+	require.Empty(t, ledger.revaluations)
+}
+
+func TestRecalculateCostWritesCorrectionsAndPostsAdjustment(t *testing.T) {
+	repo := costedRepo()
+	ledger := &recordingRevaluations{}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, ledger)
+
+	result, err := svc.RecalculateCost(context.Background(), CostRecalcInput{
+		WarehouseID: 1, ProductID: 2, From: time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 50.0, repo.cards[0].BalanceCost, "opening card must not change")
+	require.InDelta(t, 74.0, repo.cards[2].UnitCost, 0.0001)
+	require.InDelta(t, 109.0, repo.cards[4].UnitCost, 0.0001)
+	require.InDelta(t, 109.0, repo.balances[key(1, 2)].AvgCost, 0.0001)
+	require.Len(t, ledger.revaluations, 1)
+	require.InDelta(t, -60.0, ledger.revaluations[0].Amount, 0.001)
+	require.Equal(t, result.JournalCode, ledger.revaluations[0].Code)
+
+	again, err := svc.RecalculateCost(context.Background(), CostRecalcInput{
+		WarehouseID: 1, ProductID: 2, From: time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Empty(t, again.Corrections)
+	require.Zero(t, again.ValuationAdjustment)
+	require.Len(t, ledger.revaluations, 1)
+}
+
+func TestRecalculateCostRequiresScope(t *testing.T) {
+	svc := NewService(newMemoryRepo(), nil, nil, ServiceConfig{}, nil)
+	_, err := svc.RecalculateCost(context.Background(), CostRecalcInput{WarehouseID: 1, ProductID: 2})
+	require.ErrorIs(t, err, ErrRecalcInput)
+}
@@ -91,6 +91,12 @@ type TransferInput struct {
 	ActorID      int64
 	RefModule    string
 	RefID        string
+	// RequireApproval holds the transfer for approval even when its value is
+	// below the configured threshold.
+	RequireApproval bool
+	// InTransit posts only the outbound leg; ReceiveTransfer books the stock
+	// into the destination when it arrives.
+	InTransit bool
 }
 
 // InboundInput is used for GRN posting.
@@ -106,6 +112,31 @@ type InboundInput struct {
 	RefID       string
 }
 
+// InboundLine is a single product received in a batch inbound posting.
+type InboundLine struct {
+	ProductID int64
+	Qty       float64
+	UnitCost  float64
+}
+
+// InboundBatchInput posts many inbound lines (e.g. a whole GRN) to one
+// warehouse as a single inventory transaction.
+type InboundBatchInput struct {
+	Code        string
+	WarehouseID int64
+	Lines       []InboundLine
+	Note        string
+	ActorID     int64
+	RefModule   string
+	RefID       string
+}
+
+// ProductCardEntry pairs a stock card entry with its product for batch writes.
+type ProductCardEntry struct {
+	ProductID int64
+	StockCardEntry
+}
+
 // StockCardFilter filters card entries.
 type StockCardFilter struct {
 	WarehouseID int64
@@ -11,3 +11,22 @@ type AdjustmentPostedEvent struct {
 	UnitCost    float64
 	PostedAt    time.Time
 }
+
+// RevaluationPostedEvent carries the net stock value change of a cost
+// recalculation. A positive Amount raises the inventory value.
+type RevaluationPostedEvent struct {
+	Code        string
+	WarehouseID int64
+	ProductID   int64
+	Amount      float64
+	PostedAt    time.Time
+}
+
+// AdjustmentBatchPostedEvent carries every adjustment of a stock count so the
+// ledger can post them as a single journal.
+type AdjustmentBatchPostedEvent struct {
+	Code        string
+	WarehouseID int64
+	Lines       []AdjustmentPostedEvent
+	PostedAt    time.Time
+}
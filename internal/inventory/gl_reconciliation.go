@@ -0,0 +1,363 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReconcilingKind classifies a difference between the stock subledger and the
+// inventory GL account.
+type ReconcilingKind string
+
+const (
+	// ReconcilingInTransit is stock dispatched but not yet received. It has
+	// left the source warehouse balance while the GL still carries it.
+	ReconcilingInTransit ReconcilingKind = "IN_TRANSIT"
+	// ReconcilingUnposted is a stock movement without a posted journal.
+	ReconcilingUnposted ReconcilingKind = "UNPOSTED"
+	// ReconcilingTiming is a movement and its journal dated on different
+	// sides of the reconciliation date.
+	ReconcilingTiming ReconcilingKind = "TIMING"
+	// ReconcilingGLOnly is a journal on the inventory account that no stock
+	// movement produced, such as a manual entry or a reversal.
+	ReconcilingGLOnly ReconcilingKind = "GL_ONLY"
+)
+
+var reconcilingKinds = []ReconcilingKind{ReconcilingInTransit, ReconcilingUnposted, ReconcilingTiming, ReconcilingGLOnly}
+
+// Label returns the display name of the kind.
+func (k ReconcilingKind) Label() string {
+	switch k {
+	case ReconcilingInTransit:
+		return "In transit"
+	case ReconcilingUnposted:
+		return "Unposted movement"
+	case ReconcilingTiming:
+		return "Timing"
+	case ReconcilingGLOnly:
+		return "Other GL entry"
+	}
+	return string(k)
+}
+
+// GLReconciliationFilter selects the reconciliation date and warehouse.
+type GLReconciliationFilter struct {
+	AsOf        time.Time
+	WarehouseID int64
+}
+
+// ReconcilingItem explains part of the difference. Amount is signed so that
+// the subledger value plus every item equals the GL balance.
+type ReconcilingItem struct {
+	Kind          ReconcilingKind
+	Reference     string
+	WarehouseName string
+	Description   string
+	StockDate     time.Time
+	JournalDate   time.Time
+	Amount        float64
+}
+
+// ReconcilingTotal sums the items of one kind.
+type ReconcilingTotal struct {
+	Kind   ReconcilingKind
+	Count  int
+	Amount float64
+}
+
+// GLReconciliation ties the stock subledger to the inventory GL account.
+type GLReconciliation struct {
+	AsOf           time.Time
+	WarehouseID    int64
+	SubledgerValue float64
+	GLBalance      float64
+	// Difference is GL balance minus subledger value.
+	Difference  float64
+	Items       []ReconcilingItem
+	Totals      []ReconcilingTotal
+	Explained   float64
+	Unexplained float64
+	Tolerance   float64
+}
+
+// WithinTolerance reports whether the unexplained difference is small enough
+// to sign off.
+func (r GLReconciliation) WithinTolerance() bool {
+	return math.Abs(r.Unexplained) <= r.Tolerance+0.005
+}
+
+// StockMovement is a stock card entry that should reach the inventory GL
+// account. Value is signed: positive for stock in.
+type StockMovement struct {
+	TxCode        string
+	TxType        TransactionType
+	RefModule     string
+	RefID         string
+	WarehouseName string
+	ProductID     int64
+	SKU           string
+	PostedAt      time.Time
+	Value         float64
+}
+
+// GLOnlyEntry is a posted journal on the inventory account from a source that
+// does not move stock.
+type GLOnlyEntry struct {
+	Number        int64
+	Date          time.Time
+	SourceModule  string
+	Memo          string
+	WarehouseName string
+	Amount        float64
+}
+
+// EarlyJournal is a stock movement posted after the reconciliation date whose
+// journal is dated on or before it.
+type EarlyJournal struct {
+	TxCode        string
+	WarehouseName string
+	PostedAt      time.Time
+	JournalDate   time.Time
+	Value         float64
+}
+
+// ReconcileGL compares the stock value at the end of the AsOf day with the
+// inventory GL balance and lists the reconciling items.
+func (s *Service) ReconcileGL(ctx context.Context, filter GLReconciliationFilter) (GLReconciliation, error) {
+	if filter.AsOf.IsZero() {
+		return GLReconciliation{}, errors.New("inventory: reconciliation date required")
+	}
+	day := time.Date(filter.AsOf.Year(), filter.AsOf.Month(), filter.AsOf.Day(), 0, 0, 0, 0, filter.AsOf.Location())
+	endOfDay := day.Add(24*time.Hour - time.Nanosecond)
+
+	lines, err := s.repo.InventoryValuation(ctx, ValuationFilter{AsOf: endOfDay, WarehouseID: filter.WarehouseID})
+	if err != nil {
+		return GLReconciliation{}, err
+	}
+	glBalance, err := s.repo.InventoryGLBalance(ctx, day, filter.WarehouseID, 0)
+	if err != nil {
+		return GLReconciliation{}, err
+	}
+	report := GLReconciliation{AsOf: day, WarehouseID: filter.WarehouseID, GLBalance: roundCurrency(glBalance), Tolerance: s.glTolerance}
+	for _, line := range lines {
+		report.SubledgerValue += line.Value
+	}
+	report.SubledgerValue = roundCurrency(report.SubledgerValue)
+
+	inTransit, err := s.repo.TransfersInTransitAsOf(ctx, endOfDay, filter.WarehouseID)
+	if err != nil {
+		return GLReconciliation{}, err
+	}
+	for _, t := range inTransit {
+		report.Items = append(report.Items, ReconcilingItem{
+			Kind:          ReconcilingInTransit,
+			Reference:     t.Code,
+			WarehouseName: t.SrcWarehouseName,
+			Description:   fmt.Sprintf("%s to %s", t.SKU, t.DstWarehouseName),
+			StockDate:     t.DispatchedAt,
+			Amount:        t.Value,
+		})
+	}
+
+	movements, err := s.repo.StockMovementsAsOf(ctx, endOfDay, filter.WarehouseID)
+	if err != nil {
+		return GLReconciliation{}, err
+	}
+	groups := groupMovements(movements)
+	ids := make([]uuid.UUID, 0, len(groups))
+	for _, g := range groups {
+		if g.source != uuid.Nil {
+			ids = append(ids, g.source)
+		}
+	}
+	journals := map[uuid.UUID]time.Time{}
+	if len(ids) > 0 {
+		if journals, err = s.repo.PostedJournalDates(ctx, ids); err != nil {
+			return GLReconciliation{}, err
+		}
+	}
+	report.Items = append(report.Items, movementItems(groups, journals, day)...)
+
+	early, err := s.repo.EarlyGRNJournals(ctx, endOfDay, filter.WarehouseID)
+	if err != nil {
+		return GLReconciliation{}, err
+	}
+	for _, e := range early {
+		report.Items = append(report.Items, ReconcilingItem{
+			Kind:          ReconcilingTiming,
+			Reference:     e.TxCode,
+			WarehouseName: e.WarehouseName,
+			Description:   "Journal dated before the stock was received",
+			StockDate:     e.PostedAt,
+			JournalDate:   e.JournalDate,
+			Amount:        roundCurrency(e.Value),
+		})
+	}
+
+	glOnly, err := s.repo.GLOnlyInventoryEntries(ctx, day, filter.WarehouseID)
+	if err != nil {
+		return GLReconciliation{}, err
+	}
+	for _, e := range glOnly {
+		description := e.SourceModule
+		if e.Memo != "" {
+			description += ": " + e.Memo
+		}
+		report.Items = append(report.Items, ReconcilingItem{
+			Kind:          ReconcilingGLOnly,
+			Reference:     fmt.Sprintf("JE %d", e.Number),
+			WarehouseName: e.WarehouseName,
+			Description:   description,
+			JournalDate:   e.Date,
+			Amount:        roundCurrency(e.Amount),
+		})
+	}
+
+	summarizeReconciliation(&report)
+	return report, nil
+}
+
+// movementGroup is the set of card entries covered by one journal, or by one
+// transaction when no journal is expected.
+type movementGroup struct {
+	source        uuid.UUID
+	code          string
+	warehouseName string
+	description   string
+	postedAt      time.Time
+	value         float64
+}
+
+// movementSource returns the journal source id the integration hooks use for
+// a movement, or uuid.Nil when the movement is never journalled.
+func movementSource(m StockMovement) uuid.UUID {
+	switch {
+	case m.TxType == TransactionTypeAdjust && m.RefModule == StockCountRefModule && !strings.HasSuffix(m.TxCode, reversalSuffix):
+		// Batched stock counts reference their journal; unbatched ones fall
+		// through to the per-adjustment source.
+		if id, err := uuid.Parse(m.RefID); err == nil {
+			return id
+		}
+		return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("ADJ:%s:%d", m.TxCode, m.ProductID)))
+	case m.TxType == TransactionTypeAdjust:
+		return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("ADJ:%s:%d", m.TxCode, m.ProductID)))
+	case m.TxType == TransactionTypeIn && m.RefModule == "PROCUREMENT":
+		if id, err := uuid.Parse(m.RefID); err == nil {
+			return id
+		}
+	}
+	return uuid.Nil
+}
+
+func groupMovements(movements []StockMovement) []*movementGroup {
+	var groups []*movementGroup
+	index := map[string]*movementGroup{}
+	for _, m := range movements {
+		source := movementSource(m)
+		key := m.TxCode
+		if source != uuid.Nil {
+			key = source.String()
+		}
+		g, ok := index[key]
+		if !ok {
+			g = &movementGroup{source: source, code: m.TxCode, warehouseName: m.WarehouseName, postedAt: m.PostedAt}
+			switch {
+			case m.TxType == TransactionTypeAdjust:
+				g.description = "Adjustment " + m.SKU
+			case source != uuid.Nil:
+				g.description = "Goods receipt"
+			case m.RefModule != "":
+				g.description = fmt.Sprintf("Inbound from %s, not journalled", m.RefModule)
+			default:
+				g.description = "Inbound, not journalled"
+			}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.value += m.Value
+	}
+	return groups
+}
+
+// movementItems turns movements on or before day into reconciling items: no
+// journal at all, or a journal dated after day.
+func movementItems(groups []*movementGroup, journals map[uuid.UUID]time.Time, day time.Time) []ReconcilingItem {
+	var items []ReconcilingItem
+	for _, g := range groups {
+		value := roundCurrency(g.value)
+		if value == 0 {
+			continue
+		}
+		item := ReconcilingItem{
+			Reference:     g.code,
+			WarehouseName: g.warehouseName,
+			Description:   g.description,
+			StockDate:     g.postedAt,
+			Amount:        -value,
+		}
+		journalDate, posted := journals[g.source]
+		switch {
+		case g.source == uuid.Nil || !posted:
+			item.Kind = ReconcilingUnposted
+			if g.source != uuid.Nil {
+				item.Description += ", journal missing"
+			}
+		case journalDate.After(day):
+			item.Kind = ReconcilingTiming
+			item.JournalDate = journalDate
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func summarizeReconciliation(report *GLReconciliation) {
+	report.Difference = roundCurrency(report.GLBalance - report.SubledgerValue)
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if a.Kind != b.Kind {
+			return kindOrder(a.Kind) < kindOrder(b.Kind)
+		}
+		return itemDate(a).Before(itemDate(b))
+	})
+	report.Totals = report.Totals[:0]
+	for _, kind := range reconcilingKinds {
+		total := ReconcilingTotal{Kind: kind}
+		for _, item := range report.Items {
+			if item.Kind == kind {
+				total.Count++
+				total.Amount += item.Amount
+			}
+		}
+		total.Amount = roundCurrency(total.Amount)
+		report.Explained += total.Amount
+		report.Totals = append(report.Totals, total)
+	}
+	report.Explained = roundCurrency(report.Explained)
+	report.Unexplained = roundCurrency(report.Difference - report.Explained)
+}
+
+func kindOrder(kind ReconcilingKind) int {
+	for i, k := range reconcilingKinds {
+		if k == kind {
+			return i
+		}
+	}
+	return len(reconcilingKinds)
+}
+
+func itemDate(item ReconcilingItem) time.Time {
+	if item.StockDate.IsZero() {
+		return item.JournalDate
+	}
+	return item.StockDate
+}
@@ -0,0 +1,100 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *memoryRepo) TransfersInTransitAsOf(ctx context.Context, asOf time.Time, warehouseID int64) ([]Transfer, error) {
+	return r.inTransitAsOf, nil
+}
+
+func (r *memoryRepo) StockMovementsAsOf(ctx context.Context, asOf time.Time, warehouseID int64) ([]StockMovement, error) {
+	return r.movements, nil
+}
+
+func (r *memoryRepo) PostedJournalDates(ctx context.Context, sourceIDs []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	out := map[uuid.UUID]time.Time{}
+	for _, id := range sourceIDs {
+		if date, ok := r.journalDates[id]; ok {
+			out[id] = date
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepo) EarlyGRNJournals(ctx context.Context, asOf time.Time, warehouseID int64) ([]EarlyJournal, error) {
+	return r.earlyJournals, nil
+}
+
+func (r *memoryRepo) GLOnlyInventoryEntries(ctx context.Context, asOf time.Time, warehouseID int64) ([]GLOnlyEntry, error) {
+	return r.glOnly, nil
+}
+
+func TestReconcileGLExplainsDifference(t *testing.T) {
+	day := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	grnRef := uuid.NewSHA1(uuid.Nil, []byte("GRN:7"))
+	posted := StockMovement{TxCode: "ADJ-1", TxType: TransactionTypeAdjust, ProductID: 10, PostedAt: day, Value: -200}
+	missing := StockMovement{TxCode: "ADJ-2", TxType: TransactionTypeAdjust, ProductID: 10, PostedAt: day, Value: -50}
+	late := StockMovement{TxCode: "GRN-7", TxType: TransactionTypeIn, RefModule: "PROCUREMENT", RefID: grnRef.String(), ProductID: 11, PostedAt: day, Value: 300}
+	lateSecondLine := StockMovement{TxCode: "GRN-7", TxType: TransactionTypeIn, RefModule: "PROCUREMENT", RefID: grnRef.String(), ProductID: 12, PostedAt: day, Value: 100}
+	restock := StockMovement{TxCode: "RET-1-L1", TxType: TransactionTypeIn, RefModule: "SALES_RETURN", ProductID: 10, PostedAt: day, Value: 80}
+
+	repo := newMemoryRepo()
+	repo.valuation = []ValuationLine{{WarehouseID: 1, ProductID: 10, Value: 10000}}
+	// Subledger plus in transit, the unjournalled write-off and the manual
+	// entry, less the late receipt and the restock, leaves 0.40 unexplained.
+	repo.glBalance = 10000 + 500 + 50 - 400 - 80 + 20 + 0.40
+	repo.inTransitAsOf = []Transfer{{Code: "TRF-1", SKU: "SKU-10", SrcWarehouseName: "Main", DstWarehouseName: "Branch", DispatchedAt: day, Value: 500}}
+	repo.movements = []StockMovement{posted, missing, late, lateSecondLine, restock}
+	repo.journalDates = map[uuid.UUID]time.Time{
+		movementSource(posted): day,
+		grnRef:                 day.AddDate(0, 0, 1),
+	}
+	repo.glOnly = []GLOnlyEntry{{Number: 42, Date: day, SourceModule: "MANUAL", Amount: 20}}
+	svc := NewService(repo, nil, nil, ServiceConfig{GLTolerance: 0.5}, nil)
+
+	report, err := svc.ReconcileGL(context.Background(), GLReconciliationFilter{AsOf: day.Add(15 * time.Hour)})
+	require.NoError(t, err)
+	require.Equal(t, day, report.AsOf)
+	require.InDelta(t, 10000, report.SubledgerValue, 1e-9)
+	require.InDelta(t, 90.40, report.Difference, 1e-9)
+
+	byKind := map[ReconcilingKind]ReconcilingTotal{}
+	for _, total := range report.Totals {
+		byKind[total.Kind] = total
+	}
+	require.Equal(t, ReconcilingTotal{Kind: ReconcilingInTransit, Count: 1, Amount: 500}, byKind[ReconcilingInTransit])
+	require.Equal(t, ReconcilingTotal{Kind: ReconcilingUnposted, Count: 2, Amount: -30}, byKind[ReconcilingUnposted])
+	require.Equal(t, ReconcilingTotal{Kind: ReconcilingTiming, Count: 1, Amount: -400}, byKind[ReconcilingTiming])
+	require.Equal(t, ReconcilingTotal{Kind: ReconcilingGLOnly, Count: 1, Amount: 20}, byKind[ReconcilingGLOnly])
+	require.InDelta(t, 90, report.Explained, 1e-9)
+	require.InDelta(t, 0.40, report.Unexplained, 1e-9)
+	require.True(t, report.WithinTolerance())
+
+	require.Equal(t, ReconcilingInTransit, report.Items[0].Kind)
+	require.Equal(t, ReconcilingGLOnly, report.Items[len(report.Items)-1].Kind)
+	for _, item := range report.Items {
+		require.NotEqual(t, "ADJ-1", item.Reference, "journalled movements are not reconciling items")
+	}
+}
+
+func TestReconcileGLOutsideTolerance(t *testing.T) {
+	repo := newMemoryRepo()
+	repo.valuation = []ValuationLine{{WarehouseID: 1, ProductID: 10, Value: 1000}}
+	repo.glBalance = 1010
+	svc := NewService(repo, nil, nil, ServiceConfig{GLTolerance: 1}, nil)
+
+	report, err := svc.ReconcileGL(context.Background(), GLReconciliationFilter{AsOf: time.Now()})
+	require.NoError(t, err)
+	require.Empty(t, report.Items)
+	require.InDelta(t, 10, report.Unexplained, 1e-9)
+	require.False(t, report.WithinTolerance())
+
+	_, err = svc.ReconcileGL(context.Background(), GLReconciliationFilter{})
+	require.Error(t, err)
+}
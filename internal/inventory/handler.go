@@ -1,6 +1,7 @@
 package inventory
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -33,14 +35,37 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAny("inventory.view"))
 		r.Get("/stock-card", h.handleStockCard)
+		r.Get("/valuation", h.showValuation)
+		r.Get("/availability", h.showAvailability)
+		r.Get("/replenishment", h.showReplenishment)
+		r.Get("/abc", h.showABC)
+		r.Get("/serials", h.showSerialLookup)
+		r.Get("/transactions", h.showTransactionSearch)
+		r.Get("/transfers/in-transit", h.showInTransit)
+		r.Get("/transfers/history", h.showTransferHistory)
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAll("inventory.edit"))
 		r.Get("/adjustments", h.showAdjustmentForm)
 		r.Post("/adjustments", h.handleAdjustment)
+		r.Post("/adjustments/{code}/reverse", h.handleReverseAdjustment)
+		r.Get("/stock-counts", h.showStockCountForm)
+		r.Post("/stock-counts", h.handleStockCount)
 		r.Get("/transfers", h.showTransferForm)
 		r.Post("/transfers", h.handleTransfer)
+		r.Post("/transfers/{code}/receive", h.handleReceiveTransfer)
+		r.Post("/abc", h.saveABC)
+		r.Post("/replenishment/rules", h.saveReplenishmentRule)
 	})
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAll("inventory.approve"))
+		r.Get("/transfers/approvals", h.showTransferApprovals)
+		r.Post("/transfers/requests/{id}/approve", h.handleApproveTransfer)
+		r.Post("/transfers/requests/{id}/reject", h.handleRejectTransfer)
+		r.Get("/cost-recalc", h.showCostRecalc)
+		r.Post("/cost-recalc", h.handleCostRecalc)
+	})
+	r.With(h.rbac.RequireAny(shared.PermFinanceGLView)).Get("/gl-reconciliation", h.showGLReconciliation)
 }
 
 type stockCardPageData struct {
@@ -49,6 +74,7 @@ type stockCardPageData struct {
 	From        string
 	To          string
 	Entries     []StockCardEntry
+	CanReverse  bool
 	Errors      map[string]string
 	AppEnv      string
 }
@@ -70,12 +96,13 @@ type transferForm struct {
 	UnitCost     float64
 	Note         string
 	Code         string
+	InTransit    bool
 }
 
 func (h *Handler) handleStockCard(w http.ResponseWriter, r *http.Request) {
 	sess := shared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
-	data := stockCardPageData{Errors: map[string]string{}}
+	data := stockCardPageData{Errors: map[string]string{}, CanReverse: h.rbac.Allows(r, "inventory.edit")}
 	q := r.URL.Query()
 	if warehouseStr := q.Get("warehouse_id"); warehouseStr != "" {
 		if id, err := strconv.ParseInt(warehouseStr, 10, 64); err == nil {
@@ -115,17 +142,17 @@ func (h *Handler) handleStockCard(w http.ResponseWriter, r *http.Request) {
 			entries, err := h.service.GetStockCard(r.Context(), StockCardFilter{WarehouseID: data.WarehouseID, ProductID: data.ProductID, From: fromTime, To: toTime, Limit: 500})
 			if err != nil {
 				data.Errors["general"] = shared.UserSafeMessage(err)
-				h.logger.Error("failed to get stock card", slog.Any("error", err))
+				h.logger.ErrorContext(r.Context(), "failed to get stock card", slog.Any("error", err))
 			} else {
 				data.Entries = entries
-				h.logger.Info("got stock card",
+				h.logger.InfoContext(r.Context(), "got stock card",
 					slog.Int("count", len(entries)),
 					slog.Int64("warehouse_id", data.WarehouseID),
 					slog.Int64("product_id", data.ProductID))
 			}
 		}
 	} else {
-		h.logger.Info("stock card missing filters",
+		h.logger.InfoContext(r.Context(), "stock card missing filters",
 			slog.Int64("warehouse_id", data.WarehouseID),
 			slog.Int64("product_id", data.ProductID))
 	}
@@ -135,8 +162,8 @@ func (h *Handler) handleStockCard(w http.ResponseWriter, r *http.Request) {
 	}
 	viewData := view.TemplateData{Title: "Kartu Stok", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
 	if err := h.templates.Render(w, "pages/inventory/stock_card.html", viewData); err != nil {
-		h.logger.Error("render stock card", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "render stock card", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 	}
 }
 
@@ -146,7 +173,7 @@ func (h *Handler) showAdjustmentForm(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) handleAdjustment(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	sess := shared.SessionFromContext(r.Context())
@@ -163,7 +190,7 @@ func (h *Handler) handleAdjustment(w http.ResponseWriter, r *http.Request) {
 			RefModule:   "INVENTORY",
 		})
 		if err != nil {
-			h.logger.Error("post adjustment failed", slog.Any("error", err))
+			h.logger.ErrorContext(r.Context(), "post adjustment failed", slog.Any("error", err))
 			errors["general"] = shared.UserSafeMessage(err)
 		} else {
 			if sess != nil {
@@ -182,13 +209,13 @@ func (h *Handler) showTransferForm(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) handleTransfer(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	sess := shared.SessionFromContext(r.Context())
 	form, errors := parseTransferForm(r)
 	if len(errors) == 0 {
-		_, _, err := h.service.PostTransfer(r.Context(), TransferInput{
+		result, err := h.service.RequestTransfer(r.Context(), TransferInput{
 			Code:         form.Code,
 			ProductID:    form.ProductID,
 			Qty:          form.Qty,
@@ -198,13 +225,20 @@ func (h *Handler) handleTransfer(w http.ResponseWriter, r *http.Request) {
 			Note:         form.Note,
 			ActorID:      currentUserID(sess),
 			RefModule:    "INVENTORY",
+			InTransit:    form.InTransit,
 		})
 		if err != nil {
-			h.logger.Error("post transfer failed", slog.Any("error", err))
-			errors["general"] = shared.UserSafeMessage(err)
+			h.logger.ErrorContext(r.Context(), "post transfer failed", slog.Any("error", err))
+			errors["general"] = transferErrorMessage(err)
 		} else {
 			if sess != nil {
-				sess.AddFlash(shared.FlashMessage{Kind: "success", Message: "Transfer stok berhasil"})
+				if result.Pending != nil {
+					sess.AddFlash(shared.FlashMessage{Kind: "info", Message: fmt.Sprintf("Transfer %s bernilai %.2f menunggu persetujuan", result.Pending.Code, result.Pending.Value)})
+				} else if form.InTransit {
+					sess.AddFlash(shared.FlashMessage{Kind: "success", Message: "Transfer dikirim dan menunggu penerimaan di gudang tujuan"})
+				} else {
+					sess.AddFlash(shared.FlashMessage{Kind: "success", Message: "Transfer stok berhasil"})
+				}
 			}
 			http.Redirect(w, r, "/inventory/transfers", http.StatusSeeOther)
 			return
@@ -223,7 +257,7 @@ func (h *Handler) renderAdjustment(w http.ResponseWriter, r *http.Request, form
 	viewData := view.TemplateData{Title: "Penyesuaian Stok", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: map[string]any{"Form": form, "Errors": errors}}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, "pages/inventory/adjustment_form.html", viewData); err != nil {
-		h.logger.Error("render adjustment", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render adjustment", slog.Any("error", err))
 	}
 }
 
@@ -234,10 +268,10 @@ func (h *Handler) renderTransfer(w http.ResponseWriter, r *http.Request, form tr
 	if sess != nil {
 		flash = sess.PopFlash()
 	}
-	viewData := view.TemplateData{Title: "Transfer Stok", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: map[string]any{"Form": form, "Errors": errors}}
+	viewData := view.TemplateData{Title: "Transfer Stok", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: map[string]any{"Form": form, "Errors": errors, "ApprovalThreshold": h.service.TransferApprovalThreshold()}}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, "pages/inventory/transfer_form.html", viewData); err != nil {
-		h.logger.Error("render transfer", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render transfer", slog.Any("error", err))
 	}
 }
 
@@ -271,7 +305,7 @@ func parseAdjustmentForm(r *http.Request) (adjustmentForm, map[string]string) {
 
 func parseTransferForm(r *http.Request) (transferForm, map[string]string) {
 	errors := make(map[string]string)
-	form := transferForm{Note: r.PostFormValue("note"), Code: r.PostFormValue("code")}
+	form := transferForm{Note: r.PostFormValue("note"), Code: r.PostFormValue("code"), InTransit: r.PostFormValue("in_transit") != ""}
 	if src, err := strconv.ParseInt(r.PostFormValue("src_warehouse"), 10, 64); err == nil {
 		form.SrcWarehouse = src
 	} else {
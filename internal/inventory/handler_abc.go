@@ -0,0 +1,168 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+type abcPageData struct {
+	AsOf        string
+	Months      int
+	CompanyID   int64
+	WarehouseID int64
+	ThresholdA  float64
+	ThresholdB  float64
+	Query       string
+	CanSave     bool
+	Report      *ABCReport
+	Errors      map[string]string
+}
+
+func (h *Handler) showABC(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	filter, data, err := parseABCFilter(r.Context(), r.URL.Query())
+	if err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	data.CanSave = h.rbac.Allows(r, "inventory.edit")
+	if len(data.Errors) == 0 {
+		report, err := h.service.ABCAnalysis(r.Context(), filter)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "inventory abc analysis", slog.Any("error", err))
+			data.Errors["general"] = abcErrorMessage(err)
+		} else {
+			data.Report = &report
+		}
+	}
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{Title: "Klasifikasi ABC", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
+	if err := h.templates.Render(w, "pages/inventory/abc.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render inventory abc", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// saveABC recomputes the analysis for the submitted filter and stores it as
+// the classification used for cycle counts.
+func (h *Handler) saveABC(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	filter, data, err := parseABCFilter(r.Context(), r.PostForm)
+	if err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	location := "/inventory/abc?" + data.Query
+	if len(data.Errors) > 0 {
+		h.redirectABC(w, r, location, "error", "Parameter klasifikasi tidak valid")
+		return
+	}
+	report, err := h.service.ABCAnalysis(r.Context(), filter)
+	if err == nil {
+		err = h.service.SaveABCClassification(r.Context(), report)
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "save inventory abc", slog.Any("error", err))
+		h.redirectABC(w, r, location, "error", abcErrorMessage(err))
+		return
+	}
+	h.redirectABC(w, r, location, "success", "Klasifikasi ABC disimpan untuk "+strconv.Itoa(len(report.Lines))+" produk")
+}
+
+// parseABCFilter reads the analysis parameters. The company is resolved
+// against the caller's scope; ErrForbidden means the scope offers none.
+func parseABCFilter(ctx context.Context, values url.Values) (ABCFilter, abcPageData, error) {
+	data := abcPageData{
+		AsOf:       values.Get("as_of"),
+		Months:     DefaultABCMonths,
+		ThresholdA: DefaultABCThresholds.A,
+		ThresholdB: DefaultABCThresholds.B,
+		Errors:     map[string]string{},
+	}
+	if data.AsOf == "" {
+		data.AsOf = time.Now().Format("2006-01-02")
+	}
+	asOf, err := time.Parse("2006-01-02", data.AsOf)
+	if err != nil {
+		data.Errors["as_of"] = "Tanggal tidak valid"
+	}
+	if raw := values.Get("months"); raw != "" {
+		if m, err := strconv.Atoi(raw); err == nil && m > 0 && m <= 60 {
+			data.Months = m
+		} else {
+			data.Errors["months"] = "Periode harus 1-60 bulan"
+		}
+	}
+	requested, _ := strconv.ParseInt(values.Get("company_id"), 10, 64)
+	companyID, err := shared.RequireCompanyID(ctx, requested, 0)
+	if err != nil {
+		return ABCFilter{}, data, err
+	}
+	data.CompanyID = companyID
+	if companyID == 0 {
+		data.Errors["company_id"] = "Company wajib diisi"
+	}
+	if raw := values.Get("warehouse_id"); raw != "" {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil && id > 0 {
+			data.WarehouseID = id
+		} else {
+			data.Errors["warehouse_id"] = "Warehouse tidak valid"
+		}
+	}
+	for key, dst := range map[string]*float64{"threshold_a": &data.ThresholdA, "threshold_b": &data.ThresholdB} {
+		if raw := values.Get(key); raw != "" {
+			if v, err := strconv.ParseFloat(raw, 64); err == nil {
+				*dst = v
+			} else {
+				data.Errors[key] = "Persentase tidak valid"
+			}
+		}
+	}
+	thresholds := ABCThresholds{A: data.ThresholdA, B: data.ThresholdB}
+	if _, bad := data.Errors["threshold_a"]; !bad && thresholds.Validate() != nil {
+		data.Errors["threshold_a"] = "Batas A dan B harus positif dan totalnya di bawah 100%"
+	}
+	query := url.Values{}
+	query.Set("as_of", data.AsOf)
+	query.Set("months", strconv.Itoa(data.Months))
+	if data.CompanyID != 0 {
+		query.Set("company_id", strconv.FormatInt(data.CompanyID, 10))
+	}
+	query.Set("threshold_a", strconv.FormatFloat(data.ThresholdA, 'f', -1, 64))
+	query.Set("threshold_b", strconv.FormatFloat(data.ThresholdB, 'f', -1, 64))
+	if data.WarehouseID != 0 {
+		query.Set("warehouse_id", strconv.FormatInt(data.WarehouseID, 10))
+	}
+	data.Query = query.Encode()
+	return ABCFilter{AsOf: asOf, Months: data.Months, CompanyID: data.CompanyID, WarehouseID: data.WarehouseID, Thresholds: thresholds}, data, nil
+}
+
+func (h *Handler) redirectABC(w http.ResponseWriter, r *http.Request, location, kind, message string) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, location, http.StatusSeeOther)
+}
+
+func abcErrorMessage(err error) string {
+	if errors.Is(err, ErrInvalidABCThresholds) || errors.Is(err, ErrABCCompanyRequired) {
+		return err.Error()
+	}
+	return shared.UserSafeMessage(err)
+}
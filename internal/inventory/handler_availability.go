@@ -0,0 +1,69 @@
+package inventory
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type availabilityPageData struct {
+	WarehouseID  string
+	Query        string
+	ReservedOnly bool
+	Report       *AvailabilityReport
+	// ProductID and Reservations hold the drill-down into one product's
+	// reservations when both warehouse and product are given.
+	ProductID    int64
+	Reservations []StockReservation
+	Limit        int
+	Errors       map[string]string
+}
+
+// showAvailability compares on-hand stock with what sales order
+// reservations hold, per warehouse and product.
+func (h *Handler) showAvailability(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := availabilityPageData{
+		WarehouseID:  strings.TrimSpace(q.Get("warehouse_id")),
+		Query:        strings.TrimSpace(q.Get("q")),
+		ReservedOnly: q.Get("reserved") == "1",
+		Limit:        defaultAvailabilityLimit,
+		Errors:       map[string]string{},
+	}
+	filter := AvailabilityFilter{Query: data.Query, ReservedOnly: data.ReservedOnly, Limit: data.Limit}
+	if data.WarehouseID != "" {
+		id, err := strconv.ParseInt(data.WarehouseID, 10, 64)
+		if err != nil || id <= 0 {
+			data.Errors["warehouse_id"] = "Warehouse tidak valid"
+		}
+		filter.WarehouseID = id
+	}
+	if raw := q.Get("product_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			data.Errors["product_id"] = "Produk tidak valid"
+		}
+		data.ProductID = id
+	}
+	if len(data.Errors) == 0 {
+		report, err := h.service.StockAvailability(r.Context(), filter)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "inventory availability", slog.Any("error", err))
+			data.Errors["general"] = shared.UserSafeMessage(err)
+		} else {
+			data.Report = &report
+		}
+		if data.ProductID != 0 && filter.WarehouseID != 0 {
+			reservations, err := h.service.StockReservations(r.Context(), filter.WarehouseID, data.ProductID)
+			if err != nil {
+				h.logger.ErrorContext(r.Context(), "inventory reservations", slog.Any("error", err))
+				data.Errors["general"] = shared.UserSafeMessage(err)
+			}
+			data.Reservations = reservations
+		}
+	}
+	h.renderInventoryPage(w, r, "Ketersediaan Stok", "pages/inventory/availability.html", data)
+}
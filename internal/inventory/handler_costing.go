@@ -0,0 +1,127 @@
+package inventory
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+type costRecalcPageData struct {
+	WarehouseID int64
+	ProductID   int64
+	From        string
+	Preview     *CostRecalcResult
+	Errors      map[string]string
+}
+
+// showCostRecalc renders the recalculation form and, once warehouse, product
+// and start date are given, a dry-run preview of the corrections.
+func (h *Handler) showCostRecalc(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	q := r.URL.Query()
+	data := costRecalcPageData{Errors: map[string]string{}}
+	input, ok := parseCostRecalcInput(q, &data)
+	if ok {
+		input.DryRun = true
+		preview, err := h.service.RecalculateCost(r.Context(), input)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "inventory cost recalculation preview", slog.Any("error", err))
+			data.Errors["general"] = costRecalcErrorMessage(err)
+		} else {
+			data.Preview = &preview
+		}
+	}
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{Title: "Hitung Ulang Biaya", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
+	if err := h.templates.Render(w, "pages/inventory/cost_recalc.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render inventory cost recalculation", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// handleCostRecalc applies the recalculation previewed on the form.
+func (h *Handler) handleCostRecalc(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	back := url.Values{}
+	for _, field := range []string{"warehouse_id", "product_id", "from"} {
+		back.Set(field, r.PostFormValue(field))
+	}
+	target := "/inventory/cost-recalc?" + back.Encode()
+
+	sess := shared.SessionFromContext(r.Context())
+	data := costRecalcPageData{Errors: map[string]string{}}
+	kind, message := "error", "Data hitung ulang tidak valid"
+	if input, ok := parseCostRecalcInput(r.PostForm, &data); ok {
+		input.ActorID = currentUserID(sess)
+		result, err := h.service.RecalculateCost(r.Context(), input)
+		switch {
+		case err != nil:
+			h.logger.ErrorContext(r.Context(), "inventory cost recalculation", slog.Any("error", err))
+			message = costRecalcErrorMessage(err)
+		case len(result.Corrections) == 0 && result.ValuationAdjustment == 0:
+			kind, message = "success", "Biaya sudah benar, tidak ada koreksi"
+		default:
+			kind = "success"
+			message = "Biaya dihitung ulang: " + strconv.Itoa(len(result.Corrections)) + " entri kartu stok dikoreksi"
+			if result.JournalCode != "" {
+				message += ", jurnal " + result.JournalCode
+			}
+		}
+	}
+	if sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func parseCostRecalcInput(values url.Values, data *costRecalcPageData) (CostRecalcInput, bool) {
+	data.From = values.Get("from")
+	rawWarehouse, rawProduct := values.Get("warehouse_id"), values.Get("product_id")
+	if rawWarehouse == "" && rawProduct == "" && data.From == "" {
+		return CostRecalcInput{}, false
+	}
+	var input CostRecalcInput
+	if id, err := strconv.ParseInt(rawWarehouse, 10, 64); err == nil && id > 0 {
+		data.WarehouseID, input.WarehouseID = id, id
+	} else {
+		data.Errors["warehouse_id"] = "Warehouse tidak valid"
+	}
+	if id, err := strconv.ParseInt(rawProduct, 10, 64); err == nil && id > 0 {
+		data.ProductID, input.ProductID = id, id
+	} else {
+		data.Errors["product_id"] = "Produk tidak valid"
+	}
+	if from, err := time.Parse("2006-01-02", data.From); err == nil {
+		input.From = from
+	} else {
+		data.Errors["from"] = "Tanggal tidak valid"
+	}
+	return input, len(data.Errors) == 0
+}
+
+func costRecalcErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrRecalcInput):
+		return err.Error()
+	case errors.Is(err, accounting.ErrPeriodLocked):
+		return "Periode akuntansi untuk jurnal penyesuaian sudah ditutup"
+	case errors.Is(err, accounting.ErrInvalidPeriod):
+		return "Tidak ada periode akuntansi terbuka untuk jurnal penyesuaian"
+	}
+	return shared.UserSafeMessage(err)
+}
@@ -0,0 +1,58 @@
+package inventory
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+type glReconciliationPageData struct {
+	AsOf        string
+	WarehouseID int64
+	Report      *GLReconciliation
+	Errors      map[string]string
+}
+
+func (h *Handler) showGLReconciliation(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	q := r.URL.Query()
+	data := glReconciliationPageData{AsOf: q.Get("as_of"), Errors: map[string]string{}}
+	if data.AsOf == "" {
+		data.AsOf = time.Now().Format("2006-01-02")
+	}
+	asOf, err := time.Parse("2006-01-02", data.AsOf)
+	if err != nil {
+		data.Errors["as_of"] = "Tanggal tidak valid"
+	}
+	if raw := q.Get("warehouse_id"); raw != "" {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil && id > 0 {
+			data.WarehouseID = id
+		} else {
+			data.Errors["warehouse_id"] = "Warehouse tidak valid"
+		}
+	}
+	if len(data.Errors) == 0 {
+		report, err := h.service.ReconcileGL(r.Context(), GLReconciliationFilter{AsOf: asOf, WarehouseID: data.WarehouseID})
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "inventory gl reconciliation", slog.Any("error", err))
+			data.Errors["general"] = shared.UserSafeMessage(err)
+		} else {
+			data.Report = &report
+		}
+	}
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{Title: "Rekonsiliasi Persediaan", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
+	if err := h.templates.Render(w, "pages/inventory/gl_reconciliation.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render inventory gl reconciliation", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
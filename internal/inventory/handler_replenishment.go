@@ -0,0 +1,129 @@
+package inventory
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type replenishmentPageData struct {
+	WarehouseID string
+	SupplierID  string
+	All         bool
+	Params      ReplenishmentParams
+	Suggestions []ReplenishmentSuggestion
+	CanEdit     bool
+	CanOrder    bool
+	Errors      map[string]string
+}
+
+// showReplenishment lists the min/max replenishment suggestions.
+func (h *Handler) showReplenishment(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := replenishmentPageData{
+		WarehouseID: strings.TrimSpace(q.Get("warehouse_id")),
+		SupplierID:  strings.TrimSpace(q.Get("supplier_id")),
+		All:         q.Get("all") == "1",
+		Params:      h.service.ReplenishmentParams(),
+		CanEdit:     h.rbac.Allows(r, "inventory.edit"),
+		CanOrder:    h.rbac.Allows(r, "procurement.edit"),
+		Errors:      map[string]string{},
+	}
+	filter := ReplenishmentFilter{All: data.All}
+	if data.WarehouseID != "" {
+		id, err := strconv.ParseInt(data.WarehouseID, 10, 64)
+		if err != nil || id <= 0 {
+			data.Errors["warehouse_id"] = "Warehouse tidak valid"
+		}
+		filter.WarehouseID = id
+	}
+	if data.SupplierID != "" {
+		id, err := strconv.ParseInt(data.SupplierID, 10, 64)
+		if err != nil || id <= 0 {
+			data.Errors["supplier_id"] = "Supplier tidak valid"
+		}
+		filter.SupplierID = id
+	}
+	if len(data.Errors) == 0 {
+		suggestions, err := h.service.ReplenishmentSuggestions(r.Context(), filter)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "inventory replenishment", slog.Any("error", err))
+			data.Errors["general"] = shared.UserSafeMessage(err)
+		}
+		data.Suggestions = suggestions
+	}
+	h.renderInventoryPage(w, r, "Saran Pengadaan", "pages/inventory/replenishment.html", data)
+}
+
+// saveReplenishmentRule creates or replaces the min/max rule of a warehouse
+// and product.
+func (h *Handler) saveReplenishmentRule(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	rule := ReplenishmentRule{UpdatedBy: currentUserID(shared.SessionFromContext(r.Context()))}
+	var parseErr bool
+	parseInt := func(key string) int64 {
+		raw := strings.TrimSpace(r.PostFormValue(key))
+		if raw == "" {
+			return 0
+		}
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			parseErr = true
+		}
+		return v
+	}
+	parseFloat := func(key string) float64 {
+		raw := strings.TrimSpace(r.PostFormValue(key))
+		if raw == "" {
+			return 0
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			parseErr = true
+		}
+		return v
+	}
+	rule.WarehouseID = parseInt("warehouse_id")
+	rule.ProductID = parseInt("product_id")
+	rule.SupplierID = parseInt("supplier_id")
+	rule.MinQty = parseFloat("min_qty")
+	rule.MaxQty = parseFloat("max_qty")
+	rule.SafetyStock = parseFloat("safety_stock")
+	if strings.TrimSpace(r.PostFormValue("lead_time_days")) != "" {
+		days := int(parseInt("lead_time_days"))
+		rule.LeadTimeDays = &days
+	}
+	location := "/inventory/replenishment?all=1"
+	if rule.WarehouseID > 0 {
+		location += "&warehouse_id=" + strconv.FormatInt(rule.WarehouseID, 10)
+	}
+	err := ErrInvalidReplenishmentRule
+	if !parseErr {
+		err = h.service.SaveReplenishmentRule(r.Context(), rule)
+	}
+	if err != nil {
+		message := ErrInvalidReplenishmentRule.Error()
+		if !errors.Is(err, ErrInvalidReplenishmentRule) {
+			h.logger.ErrorContext(r.Context(), "save replenishment rule", slog.Any("error", err))
+			message = shared.UserSafeMessage(err)
+		}
+		h.redirectReplenishment(w, r, location, "error", message)
+		return
+	}
+	h.redirectReplenishment(w, r, location, "success", "Aturan min/max disimpan")
+}
+
+func (h *Handler) redirectReplenishment(w http.ResponseWriter, r *http.Request, location, kind, message string) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, location, http.StatusSeeOther)
+}
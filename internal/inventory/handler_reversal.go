@@ -0,0 +1,56 @@
+package inventory
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func (h *Handler) handleReverseAdjustment(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	code := chi.URLParam(r, "code")
+	back := url.Values{}
+	back.Set("warehouse_id", r.PostFormValue("warehouse_id"))
+	back.Set("product_id", r.PostFormValue("product_id"))
+	target := "/inventory/stock-card?" + back.Encode()
+
+	kind, message := "success", "Penyesuaian "+code+" berhasil dibatalkan"
+	sess := shared.SessionFromContext(r.Context())
+	if _, err := h.service.ReverseAdjustment(r.Context(), ReversalInput{
+		Code:    code,
+		Reason:  r.PostFormValue("reason"),
+		ActorID: currentUserID(sess),
+	}); err != nil {
+		h.logger.ErrorContext(r.Context(), "reverse adjustment failed", slog.Any("error", err), slog.String("code", code))
+		kind, message = "error", reversalErrorMessage(err)
+	}
+	if sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func reversalErrorMessage(err error) string {
+	for _, known := range []error{ErrReversalReasonRequired, ErrTransactionNotFound, ErrNotReversible, ErrAlreadyReversed, ErrNegativeStock} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	switch {
+	case errors.Is(err, accounting.ErrPeriodLocked):
+		return "Periode akuntansi transaksi ini sudah ditutup"
+	case errors.Is(err, accounting.ErrInvalidPeriod):
+		return "Tidak ada periode akuntansi untuk tanggal transaksi ini"
+	}
+	return shared.UserSafeMessage(err)
+}
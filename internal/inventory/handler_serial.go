@@ -0,0 +1,41 @@
+package inventory
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+type serialLookupPageData struct {
+	Query   string
+	Records []SerialRecord
+	Error   string
+}
+
+// showSerialLookup finds where a serial number is in stock or was shipped.
+func (h *Handler) showSerialLookup(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	data := serialLookupPageData{Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+	if data.Query != "" {
+		records, err := h.service.LookupSerial(r.Context(), data.Query)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "inventory serial lookup", slog.Any("error", err))
+			data.Error = shared.UserSafeMessage(err)
+		}
+		data.Records = records
+	}
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{Title: "Serial Lookup", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
+	if err := h.templates.Render(w, "pages/inventory/serials.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render inventory serials", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,131 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// stockCountBlankRows is how many empty variance rows the form offers.
+const stockCountBlankRows = 5
+
+type stockCountForm struct {
+	Code        string
+	WarehouseID int64
+	Note        string
+	Lines       []StockCountLine
+}
+
+func (h *Handler) showStockCountForm(w http.ResponseWriter, r *http.Request) {
+	h.renderStockCount(w, r, stockCountForm{}, map[string]string{}, http.StatusOK)
+}
+
+func (h *Handler) handleStockCount(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	sess := shared.SessionFromContext(r.Context())
+	form, errs := parseStockCountForm(r)
+	if len(errs) == 0 {
+		entries, err := h.service.PostStockCount(r.Context(), StockCountInput{
+			Code:        form.Code,
+			WarehouseID: form.WarehouseID,
+			Lines:       form.Lines,
+			Note:        form.Note,
+			ActorID:     currentUserID(sess),
+		})
+		if err == nil {
+			if sess != nil {
+				sess.AddFlash(shared.FlashMessage{Kind: "success", Message: fmt.Sprintf("Stock opname berhasil diposting (%d produk)", len(entries))})
+			}
+			http.Redirect(w, r, "/inventory/stock-counts", http.StatusSeeOther)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "post stock count failed", slog.Any("error", err))
+		errs["general"] = stockCountErrorMessage(err)
+		if len(entries) > 0 {
+			errs["general"] = fmt.Sprintf("%d produk sudah diposting sebelum gagal: %s", len(entries), errs["general"])
+		}
+	}
+	h.renderStockCount(w, r, form, errs, http.StatusBadRequest)
+}
+
+func (h *Handler) renderStockCount(w http.ResponseWriter, r *http.Request, form stockCountForm, errs map[string]string, status int) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	rows := append(append([]StockCountLine(nil), form.Lines...), make([]StockCountLine, stockCountBlankRows)...)
+	viewData := view.TemplateData{Title: "Stock Opname", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: map[string]any{"Form": form, "Rows": rows, "Errors": errs}}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, "pages/inventory/stock_count_form.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render stock count", slog.Any("error", err))
+	}
+}
+
+// parseStockCountForm reads the variance rows. Rows without a product are
+// ignored so the blank rows can be left empty.
+func parseStockCountForm(r *http.Request) (stockCountForm, map[string]string) {
+	errs := make(map[string]string)
+	form := stockCountForm{Code: strings.TrimSpace(r.PostFormValue("code")), Note: r.PostFormValue("note")}
+	if warehouseID, err := strconv.ParseInt(r.PostFormValue("warehouse_id"), 10, 64); err == nil && warehouseID > 0 {
+		form.WarehouseID = warehouseID
+	} else {
+		errs["warehouse_id"] = "Warehouse wajib diisi"
+	}
+	productIDs := r.PostForm["product_id"]
+	for i, raw := range productIDs {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		productID, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil || productID <= 0 {
+			errs["lines"] = fmt.Sprintf("Baris %d: produk tidak valid", i+1)
+			continue
+		}
+		qty, err := strconv.ParseFloat(strings.TrimSpace(postFormAt(r, "qty", i)), 64)
+		if err != nil {
+			errs["lines"] = fmt.Sprintf("Baris %d: selisih qty tidak valid", i+1)
+			continue
+		}
+		line := StockCountLine{ProductID: productID, Qty: qty}
+		if raw := strings.TrimSpace(postFormAt(r, "unit_cost", i)); raw != "" {
+			if line.UnitCost, err = strconv.ParseFloat(raw, 64); err != nil {
+				errs["lines"] = fmt.Sprintf("Baris %d: unit cost tidak valid", i+1)
+				continue
+			}
+		}
+		form.Lines = append(form.Lines, line)
+	}
+	if len(form.Lines) == 0 && errs["lines"] == "" {
+		errs["lines"] = "Isi minimal satu selisih stok"
+	}
+	return form, errs
+}
+
+func postFormAt(r *http.Request, field string, i int) string {
+	values := r.PostForm[field]
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+func stockCountErrorMessage(err error) string {
+	for _, known := range []error{ErrStockCountEmpty, ErrStockCountDuplicate, ErrInvalidUnitCost, ErrNegativeStock} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
@@ -0,0 +1,93 @@
+package inventory
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// transactionRefModules are the source modules offered by the search filter.
+var transactionRefModules = []string{"PROCUREMENT", "DELIVERY", "SALES_RETURN", "INVENTORY", StockCountRefModule}
+
+type transactionSearchPageData struct {
+	RefModule   string
+	RefID       string
+	ProductID   string
+	WarehouseID string
+	From        string
+	To          string
+	Query       string
+	Modules     []string
+	Searched    bool
+	Matches     []TransactionMatch
+	Limit       int
+	Errors      map[string]string
+}
+
+// showTransactionSearch finds inventory movements by source, product,
+// warehouse and date and links each back to its originating document.
+func (h *Handler) showTransactionSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := transactionSearchPageData{
+		RefModule:   strings.TrimSpace(q.Get("ref_module")),
+		RefID:       strings.TrimSpace(q.Get("ref_id")),
+		ProductID:   strings.TrimSpace(q.Get("product_id")),
+		WarehouseID: strings.TrimSpace(q.Get("warehouse_id")),
+		From:        q.Get("from"),
+		To:          q.Get("to"),
+		Query:       strings.TrimSpace(q.Get("q")),
+		Modules:     transactionRefModules,
+		Limit:       defaultTransactionSearchLimit,
+		Errors:      map[string]string{},
+	}
+	filter := TransactionSearchFilter{RefModule: data.RefModule, RefID: data.RefID, Query: data.Query, Limit: data.Limit}
+	if data.ProductID != "" {
+		id, err := strconv.ParseInt(data.ProductID, 10, 64)
+		if err != nil {
+			data.Errors["product_id"] = "Produk tidak valid"
+		}
+		filter.ProductID = id
+	}
+	if data.WarehouseID != "" {
+		id, err := strconv.ParseInt(data.WarehouseID, 10, 64)
+		if err != nil {
+			data.Errors["warehouse_id"] = "Warehouse tidak valid"
+		}
+		filter.WarehouseID = id
+	}
+	if data.From != "" {
+		from, err := time.Parse("2006-01-02", data.From)
+		if err != nil {
+			data.Errors["from"] = "Tanggal mulai tidak valid"
+		}
+		filter.From = from
+	}
+	if data.To != "" {
+		to, err := time.Parse("2006-01-02", data.To)
+		if err != nil {
+			data.Errors["to"] = "Tanggal akhir tidak valid"
+		} else {
+			filter.To = to.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+	data.Searched = len(q) > 0
+	if data.Searched && len(data.Errors) == 0 {
+		matches, err := h.service.SearchTransactions(r.Context(), filter)
+		switch {
+		case errors.Is(err, ErrInvalidRefID):
+			data.Errors["ref_id"] = "Ref ID harus berupa UUID"
+		case errors.Is(err, ErrInvalidDateRange):
+			data.Errors["to"] = "Tanggal akhir sebelum tanggal mulai"
+		case err != nil:
+			h.logger.ErrorContext(r.Context(), "inventory transaction search", slog.Any("error", err))
+			data.Errors["general"] = shared.UserSafeMessage(err)
+		}
+		data.Matches = matches
+	}
+	h.renderInventoryPage(w, r, "Transaksi Inventory", "pages/inventory/transactions.html", data)
+}
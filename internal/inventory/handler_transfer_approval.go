@@ -0,0 +1,114 @@
+package inventory
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+const transferApprovalsPath = "/inventory/transfers/approvals"
+
+func (h *Handler) showTransferApprovals(w http.ResponseWriter, r *http.Request) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	pending, err := h.service.ListTransferRequests(r.Context(), TransferStatusPending)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list transfer requests", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	all, err := h.service.ListTransferRequests(r.Context(), "")
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list transfer requests", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	var decided []TransferRequest
+	for _, req := range all {
+		if req.Status != TransferStatusPending {
+			decided = append(decided, req)
+		}
+	}
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{
+		Title:       "Persetujuan Transfer",
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data: map[string]any{
+			"Pending":   pending,
+			"Decided":   decided,
+			"Threshold": h.service.TransferApprovalThreshold(),
+		},
+	}
+	if err := h.templates.Render(w, "pages/inventory/transfer_approvals.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render transfer approvals", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) handleApproveTransfer(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.transferRequestID(w, r)
+	if !ok {
+		return
+	}
+	sess := shared.SessionFromContext(r.Context())
+	if _, err := h.service.ApproveTransfer(r.Context(), id, currentUserID(sess), r.PostFormValue("reason")); err != nil {
+		h.logger.ErrorContext(r.Context(), "approve transfer failed", slog.Any("error", err), slog.Int64("request_id", id))
+		h.redirectTransferApprovals(w, r, "error", transferErrorMessage(err))
+		return
+	}
+	h.redirectTransferApprovals(w, r, "success", "Transfer disetujui dan diposting")
+}
+
+func (h *Handler) handleRejectTransfer(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.transferRequestID(w, r)
+	if !ok {
+		return
+	}
+	sess := shared.SessionFromContext(r.Context())
+	if err := h.service.RejectTransfer(r.Context(), id, currentUserID(sess), r.PostFormValue("reason")); err != nil {
+		h.logger.ErrorContext(r.Context(), "reject transfer failed", slog.Any("error", err), slog.Int64("request_id", id))
+		h.redirectTransferApprovals(w, r, "error", transferErrorMessage(err))
+		return
+	}
+	h.redirectTransferApprovals(w, r, "success", "Transfer ditolak")
+}
+
+func (h *Handler) transferRequestID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return 0, false
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) redirectTransferApprovals(w http.ResponseWriter, r *http.Request, kind, message string) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, transferApprovalsPath, http.StatusSeeOther)
+}
+
+func transferErrorMessage(err error) string {
+	for _, known := range []error{ErrNegativeStock, ErrInvalidQuantity, ErrInvalidUnitCost, ErrTransferRequestNotFound, ErrTransferNotPending, ErrTransferSelfApproval, ErrTransferReasonRequired, ErrTransferNotFound, ErrTransferNotInTransit} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
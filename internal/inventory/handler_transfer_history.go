@@ -0,0 +1,100 @@
+package inventory
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+const inTransitPath = "/inventory/transfers/in-transit"
+
+type inTransitPageData struct {
+	Report     InTransitReport
+	CanReceive bool
+	Error      string
+}
+
+type transferHistoryPageData struct {
+	ProductID int64
+	Transfers []Transfer
+	Now       time.Time
+	Error     string
+}
+
+// showInTransit lists transfers dispatched but not yet received.
+func (h *Handler) showInTransit(w http.ResponseWriter, r *http.Request) {
+	data := inTransitPageData{CanReceive: h.rbac.Allows(r, "inventory.edit")}
+	var warehouseID int64
+	if raw := r.URL.Query().Get("warehouse_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			data.Error = "Warehouse tidak valid"
+		}
+		warehouseID = id
+	}
+	if data.Error == "" {
+		report, err := h.service.InTransit(r.Context(), warehouseID, time.Now().UTC())
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "inventory in-transit report", slog.Any("error", err))
+			data.Error = shared.UserSafeMessage(err)
+		}
+		data.Report = report
+	}
+	h.renderInventoryPage(w, r, "Transfer Dalam Perjalanan", "pages/inventory/transfers_in_transit.html", data)
+}
+
+// showTransferHistory lists every transfer of one product.
+func (h *Handler) showTransferHistory(w http.ResponseWriter, r *http.Request) {
+	data := transferHistoryPageData{Now: time.Now().UTC()}
+	if raw := r.URL.Query().Get("product_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			data.Error = "Produk tidak valid"
+		} else {
+			data.ProductID = id
+			transfers, err := h.service.TransferHistory(r.Context(), id)
+			if err != nil {
+				h.logger.ErrorContext(r.Context(), "inventory transfer history", slog.Any("error", err))
+				data.Error = shared.UserSafeMessage(err)
+			}
+			data.Transfers = transfers
+		}
+	}
+	h.renderInventoryPage(w, r, "Riwayat Transfer", "pages/inventory/transfers_list.html", data)
+}
+
+// handleReceiveTransfer books an in-transit transfer into its destination.
+func (h *Handler) handleReceiveTransfer(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	sess := shared.SessionFromContext(r.Context())
+	kind, message := "success", "Transfer "+code+" diterima di gudang tujuan"
+	if _, err := h.service.ReceiveTransfer(r.Context(), code, currentUserID(sess)); err != nil {
+		h.logger.ErrorContext(r.Context(), "receive transfer failed", slog.Any("error", err), slog.String("code", code))
+		kind, message = "error", transferErrorMessage(err)
+	}
+	if sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, inTransitPath, http.StatusSeeOther)
+}
+
+func (h *Handler) renderInventoryPage(w http.ResponseWriter, r *http.Request, title, page string, data any) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{Title: title, CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
+	if err := h.templates.Render(w, page, viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render inventory page", slog.Any("error", err), slog.String("page", page))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -5,4 +5,6 @@ import "context"
 // IntegrationHandler receives inventory events for financial integration.
 type IntegrationHandler interface {
 	HandleInventoryAdjustmentPosted(ctx context.Context, evt AdjustmentPostedEvent) error
+	HandleInventoryAdjustmentBatchPosted(ctx context.Context, evt AdjustmentBatchPostedEvent) error
+	HandleInventoryRevaluationPosted(ctx context.Context, evt RevaluationPostedEvent) error
 }
@@ -0,0 +1,167 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	// DefaultReplenishmentLeadTimeDays is the supplier lead time used for
+	// rules without their own.
+	DefaultReplenishmentLeadTimeDays = 7
+	// DefaultReplenishmentDemandDays is the outbound history averaged into
+	// daily demand.
+	DefaultReplenishmentDemandDays = 90
+)
+
+// ErrInvalidReplenishmentRule indicates a rule with missing or inconsistent
+// levels.
+var ErrInvalidReplenishmentRule = errors.New("inventory: replenishment rule needs warehouse, product, 0 <= min <= max and non-negative safety stock and lead time")
+
+// ReplenishmentRule holds the min/max levels of a product in a warehouse, in
+// base units.
+type ReplenishmentRule struct {
+	ID          int64
+	WarehouseID int64
+	ProductID   int64
+	MinQty      float64
+	MaxQty      float64
+	SafetyStock float64
+	// LeadTimeDays overrides the default lead time when set.
+	LeadTimeDays *int
+	// SupplierID is the preferred supplier suggestions are ordered from.
+	SupplierID int64
+	UpdatedBy  int64
+	UpdatedAt  time.Time
+}
+
+// ReplenishmentPosition is a rule with the stock position it is checked
+// against. OnOrder is the open quantity of replenishment POs for the
+// warehouse; Committed is what open sales order reservations hold; Demand is
+// the quantity issued over the demand window.
+type ReplenishmentPosition struct {
+	Rule ReplenishmentRule
+	// CompanyID owns the warehouse through its branch.
+	CompanyID     int64
+	WarehouseCode string
+	SKU           string
+	ProductName   string
+	SupplierName  string
+	OnHand        float64
+	OnOrder       float64
+	Committed     float64
+	Demand        float64
+}
+
+// ReplenishmentSuggestion is the order quantity worked out for a position.
+type ReplenishmentSuggestion struct {
+	ReplenishmentPosition
+	LeadTimeDays int
+	DailyDemand  float64
+	// ReorderPoint is the larger of MinQty and the safety stock plus the
+	// demand expected during the lead time.
+	ReorderPoint float64
+	// Target is the level an order tops up to: MaxQty, raised to the reorder
+	// point when that is higher.
+	Target float64
+	// Projected is OnHand + OnOrder - Committed.
+	Projected float64
+	// SuggestedQty is Target - Projected once Projected is at or below the
+	// reorder point, else zero.
+	SuggestedQty float64
+}
+
+// ReplenishmentFilter narrows the suggestions. Zero values match everything.
+type ReplenishmentFilter struct {
+	WarehouseID int64
+	SupplierID  int64
+	// All keeps the rules that need no order.
+	All bool
+}
+
+// ReplenishmentParams configures the suggestion calculation.
+type ReplenishmentParams struct {
+	LeadTimeDays int
+	DemandDays   int
+}
+
+func (p ReplenishmentParams) withDefaults() ReplenishmentParams {
+	if p.LeadTimeDays <= 0 {
+		p.LeadTimeDays = DefaultReplenishmentLeadTimeDays
+	}
+	if p.DemandDays <= 0 {
+		p.DemandDays = DefaultReplenishmentDemandDays
+	}
+	return p
+}
+
+// ReplenishmentParams reports the lead time and demand window in use.
+func (s *Service) ReplenishmentParams() ReplenishmentParams {
+	return s.replenishment.withDefaults()
+}
+
+// Validate checks the rule's levels.
+func (r ReplenishmentRule) Validate() error {
+	switch {
+	case r.WarehouseID <= 0, r.ProductID <= 0,
+		r.MinQty < 0, r.MaxQty < r.MinQty, r.SafetyStock < 0,
+		r.LeadTimeDays != nil && *r.LeadTimeDays < 0:
+		return ErrInvalidReplenishmentRule
+	}
+	return nil
+}
+
+// SaveReplenishmentRule creates or replaces the rule of a warehouse and
+// product.
+func (s *Service) SaveReplenishmentRule(ctx context.Context, rule ReplenishmentRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	return s.repo.UpsertReplenishmentRule(ctx, rule)
+}
+
+// ReplenishmentSuggestions checks every replenishment rule against the stock
+// position and returns what to order, by warehouse code then SKU.
+func (s *Service) ReplenishmentSuggestions(ctx context.Context, filter ReplenishmentFilter) ([]ReplenishmentSuggestion, error) {
+	params := s.ReplenishmentParams()
+	since := time.Now().AddDate(0, 0, -params.DemandDays)
+	positions, err := s.repo.ReplenishmentPositions(ctx, filter.WarehouseID, since)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ReplenishmentSuggestion, 0, len(positions))
+	for _, pos := range positions {
+		if filter.SupplierID != 0 && pos.Rule.SupplierID != filter.SupplierID {
+			continue
+		}
+		suggestion := suggestReplenishment(pos, params)
+		if suggestion.SuggestedQty <= 0 && !filter.All {
+			continue
+		}
+		out = append(out, suggestion)
+	}
+	return out, nil
+}
+
+// suggestReplenishment applies max - (on hand + on order - committed) once
+// the projected stock reaches the reorder point.
+func suggestReplenishment(pos ReplenishmentPosition, params ReplenishmentParams) ReplenishmentSuggestion {
+	s := ReplenishmentSuggestion{ReplenishmentPosition: pos, LeadTimeDays: params.LeadTimeDays}
+	if pos.Rule.LeadTimeDays != nil {
+		s.LeadTimeDays = *pos.Rule.LeadTimeDays
+	}
+	s.DailyDemand = roundQty(pos.Demand / float64(params.DemandDays))
+	s.ReorderPoint = roundQty(math.Max(pos.Rule.MinQty, pos.Rule.SafetyStock+s.DailyDemand*float64(s.LeadTimeDays)))
+	s.Target = math.Max(pos.Rule.MaxQty, s.ReorderPoint)
+	s.Projected = roundQty(pos.OnHand + pos.OnOrder - pos.Committed)
+	if s.Projected <= s.ReorderPoint {
+		s.SuggestedQty = roundQty(math.Max(s.Target-s.Projected, 0))
+	}
+	return s
+}
+
+func roundQty(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
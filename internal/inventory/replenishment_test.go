@@ -0,0 +1,86 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (m *memoryRepo) ReplenishmentPositions(_ context.Context, warehouseID int64, demandSince time.Time) ([]ReplenishmentPosition, error) {
+	m.demandSince = demandSince
+	var out []ReplenishmentPosition
+	for _, pos := range m.positions {
+		if warehouseID == 0 || pos.Rule.WarehouseID == warehouseID {
+			out = append(out, pos)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryRepo) UpsertReplenishmentRule(_ context.Context, rule ReplenishmentRule) error {
+	m.savedRules = append(m.savedRules, rule)
+	return nil
+}
+
+func TestReplenishmentSuggestionsTopUpToMax(t *testing.T) {
+	leadTime := 10
+	repo := newMemoryRepo()
+	repo.positions = []ReplenishmentPosition{
+		// Projected 40 + 20 - 35 = 25 is below min 30: order 100 - 25.
+		{Rule: ReplenishmentRule{WarehouseID: 1, ProductID: 10, MinQty: 30, MaxQty: 100, SupplierID: 5}, OnHand: 40, OnOrder: 20, Committed: 35},
+		// Projected 50 is above min 30: nothing to order.
+		{Rule: ReplenishmentRule{WarehouseID: 1, ProductID: 11, MinQty: 30, MaxQty: 100, SupplierID: 5}, OnHand: 50},
+		// 300 issued over 30 days is 10 a day; over a 10 day lead time plus
+		// safety stock 20 the reorder point is 120, above min and max.
+		{Rule: ReplenishmentRule{WarehouseID: 2, ProductID: 10, MinQty: 10, MaxQty: 80, SafetyStock: 20, LeadTimeDays: &leadTime, SupplierID: 6}, OnHand: 90, Demand: 300},
+	}
+	svc := NewService(repo, nil, nil, ServiceConfig{Replenishment: ReplenishmentParams{DemandDays: 30}}, nil)
+	ctx := context.Background()
+
+	suggestions, err := svc.ReplenishmentSuggestions(ctx, ReplenishmentFilter{})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+	require.Equal(t, 25.0, suggestions[0].Projected)
+	require.Equal(t, 75.0, suggestions[0].SuggestedQty)
+	require.Equal(t, DefaultReplenishmentLeadTimeDays, suggestions[0].LeadTimeDays)
+
+	second := suggestions[1]
+	require.Equal(t, 10.0, second.DailyDemand)
+	require.Equal(t, 120.0, second.ReorderPoint)
+	require.Equal(t, 120.0, second.Target, "max is raised to the reorder point")
+	require.Equal(t, 30.0, second.SuggestedQty)
+	require.WithinDuration(t, time.Now().AddDate(0, 0, -30), repo.demandSince, time.Minute)
+
+	all, err := svc.ReplenishmentSuggestions(ctx, ReplenishmentFilter{WarehouseID: 1, All: true})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	require.Zero(t, all[1].SuggestedQty)
+
+	bySupplier, err := svc.ReplenishmentSuggestions(ctx, ReplenishmentFilter{SupplierID: 6})
+	require.NoError(t, err)
+	require.Len(t, bySupplier, 1)
+	require.Equal(t, int64(2), bySupplier[0].Rule.WarehouseID)
+}
+
+func TestSaveReplenishmentRuleValidatesLevels(t *testing.T) {
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+	ctx := context.Background()
+	negative := -1
+
+	for _, rule := range []ReplenishmentRule{
+		{ProductID: 10, MinQty: 1, MaxQty: 5},
+		{WarehouseID: 1, ProductID: 10, MinQty: 6, MaxQty: 5},
+		{WarehouseID: 1, ProductID: 10, MaxQty: 5, SafetyStock: -1},
+		{WarehouseID: 1, ProductID: 10, MaxQty: 5, LeadTimeDays: &negative},
+	} {
+		require.ErrorIs(t, svc.SaveReplenishmentRule(ctx, rule), ErrInvalidReplenishmentRule)
+	}
+	require.Empty(t, repo.savedRules)
+
+	require.NoError(t, svc.SaveReplenishmentRule(ctx, ReplenishmentRule{WarehouseID: 1, ProductID: 10, MinQty: 5, MaxQty: 5}))
+	require.Len(t, repo.savedRules, 1)
+	require.Equal(t, ReplenishmentParams{LeadTimeDays: 7, DemandDays: 90}, svc.ReplenishmentParams())
+}
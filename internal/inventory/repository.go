@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -33,6 +34,11 @@ type TxRepository interface {
 	GetBalanceForUpdate(ctx context.Context, warehouseID, productID int64) (Balance, error)
 	UpsertBalance(ctx context.Context, balance Balance) error
 	InsertCardEntry(ctx context.Context, card StockCardEntry, warehouseID, productID int64, txID int64) error
+	LockBalances(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64]Balance, error)
+	ApplyInboundBalances(ctx context.Context, warehouseID int64, lines []TransactionLine) ([]Balance, error)
+	InsertCardEntries(ctx context.Context, warehouseID, txID int64, header StockCardEntry, entries []ProductCardEntry) error
+	ListCostCards(ctx context.Context, warehouseID, productID int64, from time.Time) ([]CostCard, error)
+	UpdateCardCost(ctx context.Context, cardID int64, unitCost, balanceCost float64) error
 }
 
 type txRepo struct {
@@ -95,6 +101,42 @@ func (r *Repository) GetStockCard(ctx context.Context, filter StockCardFilter) (
 	return cards, nil
 }
 
+// GetTransactionByCode loads a transaction header and its lines.
+func (r *Repository) GetTransactionByCode(ctx context.Context, code string) (Transaction, []TransactionLine, error) {
+	var tx Transaction
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, code, tx_type, COALESCE(warehouse_id, 0), ref_module, COALESCE(ref_id::text, ''), note,
+		       posted_at, COALESCE(created_by, 0), created_at
+		FROM inventory_tx WHERE code = $1
+	`, code).Scan(&tx.ID, &tx.Code, &tx.Type, &tx.WarehouseID, &tx.RefModule, &tx.RefID, &tx.Note,
+		&tx.PostedAt, &tx.CreatedBy, &tx.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Transaction{}, nil, ErrTransactionNotFound
+		}
+		return Transaction{}, nil, err
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, tx_id, product_id, qty::float8, COALESCE(unit_cost, 0)::float8,
+		       COALESCE(src_warehouse_id, 0), COALESCE(dst_warehouse_id, 0)
+		FROM inventory_tx_lines WHERE tx_id = $1 ORDER BY id
+	`, tx.ID)
+	if err != nil {
+		return Transaction{}, nil, err
+	}
+	defer rows.Close()
+	var lines []TransactionLine
+	for rows.Next() {
+		var line TransactionLine
+		if err := rows.Scan(&line.ID, &line.TransactionID, &line.ProductID, &line.Qty, &line.UnitCost,
+			&line.SrcWarehouseID, &line.DstWarehouseID); err != nil {
+			return Transaction{}, nil, err
+		}
+		lines = append(lines, line)
+	}
+	return tx, lines, rows.Err()
+}
+
 func (r *txRepo) InsertTransaction(ctx context.Context, tx Transaction) (int64, error) {
 	return r.queries.InsertTransaction(ctx, sqlc.InsertTransactionParams{
 		Code:        tx.Code,
@@ -109,20 +151,25 @@ func (r *txRepo) InsertTransaction(ctx context.Context, tx Transaction) (int64,
 }
 
 func (r *txRepo) InsertTransactionLines(ctx context.Context, txID int64, lines []TransactionLine) error {
-	for _, line := range lines {
-		err := r.queries.InsertTransactionLine(ctx, sqlc.InsertTransactionLineParams{
-			TxID:           txID,
-			ProductID:      line.ProductID,
-			Qty:            floatToNumeric(line.Qty),
-			UnitCost:       floatToNumeric(line.UnitCost),
-			SrcWarehouseID: pgtype.Int8{Int64: line.SrcWarehouseID, Valid: line.SrcWarehouseID != 0},
-			DstWarehouseID: pgtype.Int8{Int64: line.DstWarehouseID, Valid: line.DstWarehouseID != 0},
-		})
-		if err != nil {
-			return err
-		}
+	if len(lines) == 0 {
+		return nil
+	}
+	arg := sqlc.InsertTransactionLinesParams{
+		TxID:            txID,
+		ProductIds:      make([]int64, len(lines)),
+		Qtys:            make([]float64, len(lines)),
+		UnitCosts:       make([]float64, len(lines)),
+		SrcWarehouseIds: make([]int64, len(lines)),
+		DstWarehouseIds: make([]int64, len(lines)),
 	}
-	return nil
+	for i, line := range lines {
+		arg.ProductIds[i] = line.ProductID
+		arg.Qtys[i] = line.Qty
+		arg.UnitCosts[i] = line.UnitCost
+		arg.SrcWarehouseIds[i] = line.SrcWarehouseID
+		arg.DstWarehouseIds[i] = line.DstWarehouseID
+	}
+	return r.queries.InsertTransactionLines(ctx, arg)
 }
 
 func (r *txRepo) GetBalanceForUpdate(ctx context.Context, warehouseID, productID int64) (Balance, error) {
@@ -171,6 +218,128 @@ func (r *txRepo) InsertCardEntry(ctx context.Context, card StockCardEntry, wareh
 	})
 }
 
+// LockBalances locks the existing balance rows for the products in a single
+// statement, ordered by product to avoid deadlocks between concurrent batches.
+func (r *txRepo) LockBalances(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64]Balance, error) {
+	rows, err := r.queries.LockBalancesForUpdate(ctx, sqlc.LockBalancesForUpdateParams{
+		WarehouseID: warehouseID,
+		ProductIds:  productIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[int64]Balance, len(rows))
+	for _, row := range rows {
+		balances[row.ProductID] = balanceFromRow(row)
+	}
+	return balances, nil
+}
+
+// ApplyInboundBalances adds the inbound lines to the warehouse balances and
+// recomputes the moving average cost set-based, returning the new balances.
+func (r *txRepo) ApplyInboundBalances(ctx context.Context, warehouseID int64, lines []TransactionLine) ([]Balance, error) {
+	arg := sqlc.ApplyInboundBalancesParams{
+		ProductIds:  make([]int64, len(lines)),
+		Qtys:        make([]float64, len(lines)),
+		UnitCosts:   make([]float64, len(lines)),
+		WarehouseID: warehouseID,
+	}
+	for i, line := range lines {
+		arg.ProductIds[i] = line.ProductID
+		arg.Qtys[i] = line.Qty
+		arg.UnitCosts[i] = line.UnitCost
+	}
+	rows, err := r.queries.ApplyInboundBalances(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]Balance, len(rows))
+	for i, row := range rows {
+		balances[i] = balanceFromRow(row)
+	}
+	return balances, nil
+}
+
+// InsertCardEntries writes the stock card entries of one transaction in a
+// single statement, preserving line order.
+func (r *txRepo) InsertCardEntries(ctx context.Context, warehouseID, txID int64, header StockCardEntry, entries []ProductCardEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	arg := sqlc.InsertCardEntriesParams{
+		WarehouseID:  warehouseID,
+		TxID:         txID,
+		TxCode:       header.TxCode,
+		TxType:       string(header.TxType),
+		PostedAt:     pgtype.Timestamptz{Time: header.PostedAt, Valid: true},
+		Note:         header.Note,
+		ProductIds:   make([]int64, len(entries)),
+		QtyIns:       make([]float64, len(entries)),
+		QtyOuts:      make([]float64, len(entries)),
+		BalanceQtys:  make([]float64, len(entries)),
+		UnitCosts:    make([]float64, len(entries)),
+		BalanceCosts: make([]float64, len(entries)),
+	}
+	for i, entry := range entries {
+		arg.ProductIds[i] = entry.ProductID
+		arg.QtyIns[i] = entry.QtyIn
+		arg.QtyOuts[i] = entry.QtyOut
+		arg.BalanceQtys[i] = entry.BalanceQty
+		arg.UnitCosts[i] = entry.UnitCost
+		arg.BalanceCosts[i] = entry.BalanceCost
+	}
+	return r.queries.InsertCardEntries(ctx, arg)
+}
+
+// ListCostCards returns the last card before from followed by every card
+// from then on, in posting order.
+func (r *txRepo) ListCostCards(ctx context.Context, warehouseID, productID int64, from time.Time) ([]CostCard, error) {
+	rows, err := r.queries.ListCostCards(ctx, sqlc.ListCostCardsParams{
+		WarehouseID: warehouseID,
+		ProductID:   productID,
+		FromDate:    pgtype.Timestamptz{Time: from, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cards := make([]CostCard, len(rows))
+	for i, row := range rows {
+		cards[i] = CostCard{
+			ID: row.ID,
+			StockCardEntry: StockCardEntry{
+				TxCode:      row.TxCode,
+				TxType:      TransactionType(row.TxType),
+				PostedAt:    row.PostedAt.Time,
+				QtyIn:       numericToFloat(row.QtyIn),
+				QtyOut:      numericToFloat(row.QtyOut),
+				BalanceQty:  numericToFloat(row.BalanceQty),
+				UnitCost:    numericToFloat(row.UnitCost),
+				BalanceCost: numericToFloat(row.BalanceCost),
+				Note:        row.Note,
+			},
+		}
+	}
+	return cards, nil
+}
+
+func (r *txRepo) UpdateCardCost(ctx context.Context, cardID int64, unitCost, balanceCost float64) error {
+	return r.queries.UpdateCardCost(ctx, sqlc.UpdateCardCostParams{
+		ID:          cardID,
+		UnitCost:    floatToNumeric(unitCost),
+		BalanceCost: floatToNumeric(balanceCost),
+	})
+}
+
+func balanceFromRow(row sqlc.InventoryBalance) Balance {
+	return Balance{
+		WarehouseID: row.WarehouseID,
+		ProductID:   row.ProductID,
+		Qty:         numericToFloat(row.Qty),
+		AvgCost:     numericToFloat(row.AvgCost),
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+}
+
 func parseUUID(s string) [16]byte {
 	if s == "" {
 		return [16]byte{}
@@ -0,0 +1,95 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
+)
+
+// InventoryConsumption returns issued quantity and value per product between
+// from and to, counting only the company's warehouses.
+func (r *Repository) InventoryConsumption(ctx context.Context, from, to time.Time, companyID, warehouseID int64) ([]ABCLine, error) {
+	rows, err := r.queries.InventoryConsumptionValue(ctx, sqlc.InventoryConsumptionValueParams{
+		FromTs:      pgtype.Timestamptz{Time: from, Valid: true},
+		ToTs:        pgtype.Timestamptz{Time: to, Valid: true},
+		WarehouseID: pgtype.Int8{Int64: warehouseID, Valid: warehouseID != 0},
+		CompanyID:   companyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]ABCLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, ABCLine{
+			ProductID:   row.ProductID,
+			SKU:         row.Sku,
+			ProductName: row.ProductName,
+			CategoryID:  row.CategoryID,
+			Qty:         row.Qty,
+			Value:       roundCurrency(row.Value),
+		})
+	}
+	return lines, nil
+}
+
+// ReplaceABCClasses swaps the stored classification of the report's company
+// and warehouse scope in one transaction.
+func (r *Repository) ReplaceABCClasses(ctx context.Context, report ABCReport) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := r.queries.WithTx(tx)
+	if err := q.ClearABCClasses(ctx, sqlc.ClearABCClassesParams{CompanyID: report.CompanyID, WarehouseID: report.WarehouseID}); err != nil {
+		return err
+	}
+	arg := sqlc.InsertABCClassesParams{
+		CompanyID:         report.CompanyID,
+		WarehouseID:       report.WarehouseID,
+		PeriodStart:       pgtype.Date{Time: report.From, Valid: true},
+		PeriodEnd:         pgtype.Date{Time: report.To, Valid: true},
+		ProductIds:        make([]int64, len(report.Lines)),
+		Classes:           make([]string, len(report.Lines)),
+		ConsumptionValues: make([]float64, len(report.Lines)),
+		CumulativeShares:  make([]float64, len(report.Lines)),
+		Ranks:             make([]int32, len(report.Lines)),
+	}
+	for i, line := range report.Lines {
+		arg.ProductIds[i] = line.ProductID
+		arg.Classes[i] = string(line.Class)
+		arg.ConsumptionValues[i] = line.Value
+		arg.CumulativeShares[i] = line.CumulativeShare
+		arg.Ranks[i] = int32(line.Rank)
+	}
+	if len(report.Lines) > 0 {
+		if err := q.InsertABCClasses(ctx, arg); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListABCClasses returns the stored classification ordered by rank.
+func (r *Repository) ListABCClasses(ctx context.Context, companyID, warehouseID int64) ([]ABCClassification, error) {
+	rows, err := r.queries.ListABCClasses(ctx, sqlc.ListABCClassesParams{CompanyID: companyID, WarehouseID: warehouseID})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ABCClassification, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, ABCClassification{
+			ProductID:        row.ProductID,
+			Class:            ABCClass(row.AbcClass),
+			ConsumptionValue: row.ConsumptionValue,
+			Rank:             int(row.Rank),
+			PeriodStart:      row.PeriodStart.Time,
+			PeriodEnd:        row.PeriodEnd.Time,
+			ClassifiedAt:     row.ClassifiedAt.Time,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,96 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// StockAvailability lists the balance rows matching filter with the stock
+// reserved against them, by warehouse code then SKU. Reservations on
+// products without a balance row are listed with nothing on hand.
+func (r *Repository) StockAvailability(ctx context.Context, filter AvailabilityFilter) ([]AvailabilityLine, error) {
+	var conditions []string
+	var args []any
+	if filter.WarehouseID != 0 {
+		args = append(args, filter.WarehouseID)
+		conditions = append(conditions, fmt.Sprintf("s.warehouse_id = $%d", len(args)))
+	}
+	if filter.Query != "" {
+		args = append(args, "%"+filter.Query+"%")
+		conditions = append(conditions, fmt.Sprintf("(p.sku ILIKE $%d OR p.name ILIKE $%d)", len(args), len(args)))
+	}
+	if filter.ReservedOnly {
+		conditions = append(conditions, "s.reserved > 0")
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := r.pool.Query(ctx, `
+		WITH reserved AS (
+			SELECT warehouse_id, product_id, SUM(open_base) AS qty
+			FROM stock_reservation_open
+			GROUP BY warehouse_id, product_id
+		), stock AS (
+			SELECT COALESCE(b.warehouse_id, rs.warehouse_id) AS warehouse_id,
+			       COALESCE(b.product_id, rs.product_id) AS product_id,
+			       COALESCE(b.qty, 0) AS on_hand,
+			       COALESCE(rs.qty, 0) AS reserved
+			FROM inventory_balances b
+			FULL JOIN reserved rs ON rs.warehouse_id = b.warehouse_id AND rs.product_id = b.product_id
+		)
+		SELECT s.warehouse_id, w.code, w.name, s.product_id, p.sku, p.name,
+		       s.on_hand::float8, s.reserved::float8
+		FROM stock s
+		JOIN warehouses w ON w.id = s.warehouse_id
+		JOIN products p ON p.id = s.product_id
+		`+where+`
+		ORDER BY w.code, p.sku
+		LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []AvailabilityLine
+	for rows.Next() {
+		var line AvailabilityLine
+		if err := rows.Scan(&line.WarehouseID, &line.WarehouseCode, &line.WarehouseName, &line.ProductID,
+			&line.SKU, &line.ProductName, &line.OnHand, &line.Reserved); err != nil {
+			return nil, err
+		}
+		out = append(out, line)
+	}
+	return out, rows.Err()
+}
+
+// StockReservations lists the open reservations of a product in a
+// warehouse, oldest first.
+func (r *Repository) StockReservations(ctx context.Context, warehouseID, productID int64) ([]StockReservation, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT o.id, o.sales_order_id, so.doc_number, COALESCE(c.name, ''), o.sales_order_line_id,
+		       o.open_base::float8, r.created_at
+		FROM stock_reservation_open o
+		JOIN stock_reservations r ON r.id = o.id
+		JOIN sales_orders so ON so.id = o.sales_order_id
+		LEFT JOIN customers c ON c.id = so.customer_id
+		WHERE o.warehouse_id = $1 AND o.product_id = $2
+		ORDER BY r.created_at, o.id
+	`, warehouseID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []StockReservation
+	for rows.Next() {
+		var res StockReservation
+		if err := rows.Scan(&res.ID, &res.SalesOrderID, &res.DocNumber, &res.CustomerName, &res.OrderLineID,
+			&res.Open, &res.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, rows.Err()
+}
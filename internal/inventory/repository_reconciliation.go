@@ -0,0 +1,169 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// glInventorySources are the journal sources written for stock movements.
+// Journals on the inventory account from any other source have no movement.
+var glInventorySources = []string{"PROCUREMENT.GRN", "INVENTORY.ADJUSTMENT", "INVENTORY.REVALUATION"}
+
+// TransfersInTransitAsOf lists transfers dispatched by asOf and not received
+// by then, optionally from one source warehouse.
+func (r *Repository) TransfersInTransitAsOf(ctx context.Context, asOf time.Time, warehouseID int64) ([]Transfer, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+transferColumns+transferFrom+`
+		WHERE t.dispatched_at <= $1
+		  AND (t.status = 'IN_TRANSIT' OR t.received_at > $1)
+		  AND ($2 = 0 OR t.src_warehouse_id = $2)
+		ORDER BY t.dispatched_at, t.id
+	`, asOf, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Transfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, transfer)
+	}
+	return out, rows.Err()
+}
+
+// StockMovementsAsOf lists inbound and adjustment stock card entries posted by
+// asOf, valued at their card cost.
+func (r *Repository) StockMovementsAsOf(ctx context.Context, asOf time.Time, warehouseID int64) ([]StockMovement, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.tx_code, c.tx_type, t.ref_module, COALESCE(t.ref_id::text, ''), COALESCE(w.name, ''),
+		       c.product_id, COALESCE(p.sku, ''), c.posted_at,
+		       ((c.qty_in - c.qty_out) * c.unit_cost)::float8
+		FROM inventory_cards c
+		JOIN inventory_tx t ON t.id = c.tx_id
+		LEFT JOIN warehouses w ON w.id = c.warehouse_id
+		LEFT JOIN products p ON p.id = c.product_id
+		WHERE c.tx_type IN ('IN', 'ADJUST')
+		  AND c.posted_at <= $1
+		  AND ($2 = 0 OR c.warehouse_id = $2)
+		ORDER BY c.posted_at, c.id
+	`, asOf, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []StockMovement
+	for rows.Next() {
+		var m StockMovement
+		var txType string
+		if err := rows.Scan(&m.TxCode, &txType, &m.RefModule, &m.RefID, &m.WarehouseName,
+			&m.ProductID, &m.SKU, &m.PostedAt, &m.Value); err != nil {
+			return nil, err
+		}
+		m.TxType = TransactionType(txType)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// PostedJournalDates returns the date of the posted journal for each source id
+// that has one.
+func (r *Repository) PostedJournalDates(ctx context.Context, sourceIDs []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	ids := make([]string, len(sourceIDs))
+	for i, id := range sourceIDs {
+		ids[i] = id.String()
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT source_id::text, MIN(date)
+		FROM journal_entries
+		WHERE status = 'POSTED' AND source_module = ANY($1) AND source_id = ANY($2::uuid[])
+		GROUP BY source_id
+	`, glInventorySources, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[uuid.UUID]time.Time)
+	for rows.Next() {
+		var raw string
+		var date time.Time
+		if err := rows.Scan(&raw, &date); err != nil {
+			return nil, err
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = date
+	}
+	return out, rows.Err()
+}
+
+// EarlyGRNJournals lists goods receipts stocked after asOf whose journal is
+// dated on or before it.
+func (r *Repository) EarlyGRNJournals(ctx context.Context, asOf time.Time, warehouseID int64) ([]EarlyJournal, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT t.code, COALESCE(w.name, ''), t.posted_at, MIN(je.date),
+		       COALESCE((SELECT SUM(l.amount) FROM inventory_tx_lines l WHERE l.tx_id = t.id), 0)::float8
+		FROM inventory_tx t
+		JOIN journal_entries je ON je.source_module = 'PROCUREMENT.GRN' AND je.source_id = t.ref_id
+			AND je.status = 'POSTED'
+		LEFT JOIN warehouses w ON w.id = t.warehouse_id
+		WHERE t.tx_type = 'IN' AND t.ref_module = 'PROCUREMENT'
+		  AND t.posted_at > $1
+		  AND je.date <= $3::date
+		  AND ($2 = 0 OR t.warehouse_id = $2)
+		GROUP BY t.id, t.code, w.name, t.posted_at
+		ORDER BY t.posted_at
+	`, asOf, warehouseID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []EarlyJournal
+	for rows.Next() {
+		var e EarlyJournal
+		if err := rows.Scan(&e.TxCode, &e.WarehouseName, &e.PostedAt, &e.JournalDate, &e.Value); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// GLOnlyInventoryEntries lists posted journals on the inventory account up to
+// asOf whose source does not move stock.
+func (r *Repository) GLOnlyInventoryEntries(ctx context.Context, asOf time.Time, warehouseID int64) ([]GLOnlyEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT je.number, je.date, je.source_module, COALESCE(je.memo, ''), COALESCE(w.name, ''),
+		       SUM(jl.debit - jl.credit)::float8
+		FROM journal_lines jl
+		JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
+		JOIN account_mappings am ON am.account_id = jl.account_id
+			AND am.module = 'GRN' AND am.key = 'grn.inventory'
+		LEFT JOIN warehouses w ON w.id = jl.dim_warehouse_id
+		WHERE je.date <= $1::date
+		  AND je.source_module <> ALL($3)
+		  AND ($2 = 0 OR jl.dim_warehouse_id = $2)
+		GROUP BY je.id, je.number, je.date, je.source_module, je.memo, w.name
+		HAVING SUM(jl.debit - jl.credit) <> 0
+		ORDER BY je.date, je.number
+	`, asOf, warehouseID, glInventorySources)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []GLOnlyEntry
+	for rows.Next() {
+		var e GLOnlyEntry
+		if err := rows.Scan(&e.Number, &e.Date, &e.SourceModule, &e.Memo, &e.WarehouseName, &e.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
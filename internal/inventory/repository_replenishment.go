@@ -0,0 +1,117 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ReplenishmentPositions lists every replenishment rule, optionally of one
+// warehouse, with its stock position in base units. On order counts the
+// unreceived quantity of open POs raised for the warehouse; receipts entered
+// in alternate units are converted with the product's unit conversions.
+// Demand sums outbound movements since demandSince. A restricted caller only
+// sees the rules of warehouses of their companies.
+func (r *Repository) ReplenishmentPositions(ctx context.Context, warehouseID int64, demandSince time.Time) ([]ReplenishmentPosition, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
+	rows, err := r.pool.Query(ctx, `
+		WITH ordered AS (
+			SELECT po.warehouse_id, pl.product_id, SUM(pl.qty) AS qty
+			FROM po_lines pl
+			JOIN pos po ON po.id = pl.po_id
+			WHERE po.warehouse_id IS NOT NULL AND po.status IN ('DRAFT', 'APPROVAL', 'APPROVED')
+			GROUP BY po.warehouse_id, pl.product_id
+		), received AS (
+			SELECT po.warehouse_id, gl.product_id,
+			       SUM(gl.qty * COALESCE(c.base_factor, 1)) AS qty
+			FROM grn_lines gl
+			JOIN grns g ON g.id = gl.grn_id AND g.status = 'POSTED'
+			JOIN pos po ON po.id = g.po_id
+			LEFT JOIN units u ON u.code = gl.uom
+			LEFT JOIN product_unit_conversions c ON c.product_id = gl.product_id AND c.unit_id = u.id
+			WHERE po.warehouse_id IS NOT NULL AND po.status IN ('DRAFT', 'APPROVAL', 'APPROVED')
+			GROUP BY po.warehouse_id, gl.product_id
+		), committed AS (
+			SELECT warehouse_id, product_id, SUM(open_base) AS qty
+			FROM stock_reservation_open
+			GROUP BY warehouse_id, product_id
+		), demand AS (
+			SELECT warehouse_id, product_id, SUM(qty_out) AS qty
+			FROM inventory_cards
+			WHERE tx_type = 'OUT' AND posted_at >= $2
+			GROUP BY warehouse_id, product_id
+		)
+		SELECT r.id, r.warehouse_id, r.product_id, r.min_qty::float8, r.max_qty::float8, r.safety_stock::float8,
+		       r.lead_time_days, COALESCE(r.supplier_id, 0), COALESCE(r.updated_by, 0), r.updated_at,
+		       COALESCE(br.company_id, 0), w.code, p.sku, p.name, COALESCE(s.name, ''),
+		       COALESCE(b.qty, 0)::float8,
+		       GREATEST(COALESCE(o.qty, 0) - COALESCE(rc.qty, 0), 0)::float8,
+		       COALESCE(cm.qty, 0)::float8,
+		       COALESCE(d.qty, 0)::float8
+		FROM inventory_replenishment_rules r
+		JOIN warehouses w ON w.id = r.warehouse_id
+		LEFT JOIN branches br ON br.id = w.branch_id
+		JOIN products p ON p.id = r.product_id
+		LEFT JOIN suppliers s ON s.id = r.supplier_id
+		LEFT JOIN inventory_balances b ON b.warehouse_id = r.warehouse_id AND b.product_id = r.product_id
+		LEFT JOIN ordered o ON o.warehouse_id = r.warehouse_id AND o.product_id = r.product_id
+		LEFT JOIN received rc ON rc.warehouse_id = r.warehouse_id AND rc.product_id = r.product_id
+		LEFT JOIN committed cm ON cm.warehouse_id = r.warehouse_id AND cm.product_id = r.product_id
+		LEFT JOIN demand d ON d.warehouse_id = r.warehouse_id AND d.product_id = r.product_id
+		WHERE ($1::BIGINT = 0 OR r.warehouse_id = $1)
+		  AND ($3 OR r.warehouse_id IN (`+fmt.Sprintf(scopedWarehouses, 4)+`))
+		ORDER BY w.code, p.sku
+	`, warehouseID, demandSince, scope.Unrestricted, scope.CompanyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ReplenishmentPosition
+	for rows.Next() {
+		var (
+			pos      ReplenishmentPosition
+			leadTime *int32
+		)
+		rule := &pos.Rule
+		if err := rows.Scan(&rule.ID, &rule.WarehouseID, &rule.ProductID, &rule.MinQty, &rule.MaxQty, &rule.SafetyStock,
+			&leadTime, &rule.SupplierID, &rule.UpdatedBy, &rule.UpdatedAt,
+			&pos.CompanyID, &pos.WarehouseCode, &pos.SKU, &pos.ProductName, &pos.SupplierName,
+			&pos.OnHand, &pos.OnOrder, &pos.Committed, &pos.Demand); err != nil {
+			return nil, err
+		}
+		if leadTime != nil {
+			days := int(*leadTime)
+			rule.LeadTimeDays = &days
+		}
+		out = append(out, pos)
+	}
+	return out, rows.Err()
+}
+
+// UpsertReplenishmentRule creates or replaces the rule of the rule's
+// warehouse and product.
+func (r *Repository) UpsertReplenishmentRule(ctx context.Context, rule ReplenishmentRule) error {
+	var supplierID, updatedBy any
+	if rule.SupplierID != 0 {
+		supplierID = rule.SupplierID
+	}
+	if rule.UpdatedBy != 0 {
+		updatedBy = rule.UpdatedBy
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO inventory_replenishment_rules
+			(warehouse_id, product_id, min_qty, max_qty, safety_stock, lead_time_days, supplier_id, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		ON CONFLICT (warehouse_id, product_id) DO UPDATE SET
+			min_qty = EXCLUDED.min_qty,
+			max_qty = EXCLUDED.max_qty,
+			safety_stock = EXCLUDED.safety_stock,
+			lead_time_days = EXCLUDED.lead_time_days,
+			supplier_id = EXCLUDED.supplier_id,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+	`, rule.WarehouseID, rule.ProductID, rule.MinQty, rule.MaxQty, rule.SafetyStock, rule.LeadTimeDays, supplierID, updatedBy)
+	return err
+}
@@ -0,0 +1,43 @@
+package inventory
+
+import (
+	"context"
+	"time"
+)
+
+// LookupSerials returns every unit with the serial number, with the goods
+// receipt that brought it in and the delivery order that shipped it.
+func (r *Repository) LookupSerials(ctx context.Context, serial string) ([]SerialRecord, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.id, s.product_id, p.sku, p.name, s.serial_number, s.status,
+		       s.warehouse_id, COALESCE(w.name, ''), COALESCE(s.grn_id, 0), COALESCE(g.number, ''), s.received_at,
+		       COALESCE(s.delivery_order_id, 0), COALESCE(d.doc_number, ''), COALESCE(c.name, ''), s.delivered_at
+		FROM inventory_serials s
+		JOIN products p ON p.id = s.product_id
+		LEFT JOIN warehouses w ON w.id = s.warehouse_id
+		LEFT JOIN grns g ON g.id = s.grn_id
+		LEFT JOIN delivery_orders d ON d.id = s.delivery_order_id
+		LEFT JOIN customers c ON c.id = d.customer_id
+		WHERE s.serial_number = $1
+		ORDER BY p.sku, s.id
+	`, serial)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []SerialRecord
+	for rows.Next() {
+		var rec SerialRecord
+		var status string
+		var deliveredAt *time.Time
+		if err := rows.Scan(&rec.ID, &rec.ProductID, &rec.SKU, &rec.ProductName, &rec.SerialNumber, &status,
+			&rec.WarehouseID, &rec.WarehouseName, &rec.GRNID, &rec.GRNNumber, &rec.ReceivedAt,
+			&rec.DeliveryOrderID, &rec.DeliveryDocNumber, &rec.CustomerName, &deliveredAt); err != nil {
+			return nil, err
+		}
+		rec.Status = SerialStatus(status)
+		rec.DeliveredAt = deliveredAt
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,91 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// scopedWarehouses selects the warehouses whose branch belongs to one of the
+// companies bound to the placeholder.
+const scopedWarehouses = `SELECT sw.id FROM warehouses sw JOIN branches sb ON sb.id = sw.branch_id WHERE sb.company_id = ANY($%d)`
+
+// SearchTransactions lists transaction lines matching filter, newest first.
+// Only the filters that are set are added to the query so the planner can
+// pick the matching index. The originating delivery order, sales return or
+// goods receipt is resolved from the transaction code. Transactions in
+// warehouses of companies outside the caller's scope are left out.
+func (r *Repository) SearchTransactions(ctx context.Context, filter TransactionSearchFilter) ([]TransactionMatch, error) {
+	var conditions []string
+	var args []any
+	add := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+	if filter.RefModule != "" {
+		add("t.ref_module = $%d", filter.RefModule)
+	}
+	if filter.RefID != "" {
+		add("t.ref_id = $%d::uuid", filter.RefID)
+	}
+	if filter.ProductID != 0 {
+		add("l.product_id = $%d", filter.ProductID)
+	}
+	if filter.WarehouseID != 0 {
+		add("t.warehouse_id = $%d", filter.WarehouseID)
+	}
+	if !filter.From.IsZero() {
+		add("t.posted_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("t.posted_at <= $%d", filter.To)
+	}
+	if filter.Query != "" {
+		args = append(args, "%"+filter.Query+"%")
+		conditions = append(conditions, fmt.Sprintf("(t.code ILIKE $%d OR t.note ILIKE $%d)", len(args), len(args)))
+	}
+	if scope := shared.CompanyScopeFromContext(ctx); !scope.Unrestricted {
+		add("(t.warehouse_id IS NULL OR t.warehouse_id IN ("+scopedWarehouses+"))", scope.CompanyIDs)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT t.id, t.code, t.tx_type, COALESCE(t.warehouse_id, 0), COALESCE(w.name, ''),
+		       l.product_id, COALESCE(p.sku, ''), COALESCE(p.name, ''),
+		       l.qty::float8, COALESCE(l.unit_cost, 0)::float8,
+		       t.ref_module, COALESCE(t.ref_id::text, ''), t.note, t.posted_at,
+		       COALESCE(d.id, sr.id, g.id, 0), COALESCE(d.doc_number, sr.number, g.number, '')
+		FROM inventory_tx t
+		JOIN inventory_tx_lines l ON l.tx_id = t.id
+		LEFT JOIN warehouses w ON w.id = t.warehouse_id
+		LEFT JOIN products p ON p.id = l.product_id
+		LEFT JOIN grns g ON t.ref_module = 'PROCUREMENT' AND g.number = regexp_replace(substring(t.code FROM '^GRN-(.*)$'), '-QC-[0-9]+$', '')
+		LEFT JOIN delivery_orders d ON t.ref_module = 'DELIVERY' AND d.doc_number = substring(t.code FROM '^DO-(.*)-L[0-9]+$')
+		LEFT JOIN sales_returns sr ON t.ref_module = 'SALES_RETURN' AND sr.number = substring(t.code FROM '^(.*)-L[0-9]+$')
+		`+where+`
+		ORDER BY t.posted_at DESC, t.id DESC, l.id
+		LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []TransactionMatch
+	for rows.Next() {
+		var m TransactionMatch
+		var txType string
+		if err := rows.Scan(&m.TxID, &m.Code, &txType, &m.WarehouseID, &m.WarehouseName,
+			&m.ProductID, &m.SKU, &m.ProductName, &m.Qty, &m.UnitCost,
+			&m.RefModule, &m.RefID, &m.Note, &m.PostedAt, &m.SourceID, &m.SourceNumber); err != nil {
+			return nil, err
+		}
+		m.Type = TransactionType(txType)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
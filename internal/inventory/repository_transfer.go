@@ -0,0 +1,225 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+const transferRequestColumns = `id, code, product_id, qty::float8, src_warehouse_id, dst_warehouse_id,
+	unit_cost::float8, value::float8, note, ref_module, COALESCE(ref_id::text, ''), status,
+	requested_by, requested_at, decided_by, decided_at, decision_reason, in_transit, posted_at`
+
+// CreateTransferRequest stores a transfer waiting for approval.
+func (r *Repository) CreateTransferRequest(ctx context.Context, req TransferRequest) (int64, error) {
+	var refID *string
+	if req.RefID != "" {
+		refID = &req.RefID
+	}
+	var id int64
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO inventory_transfer_requests
+			(code, product_id, qty, src_warehouse_id, dst_warehouse_id, unit_cost, value, note, ref_module, ref_id, status, requested_by, requested_at, in_transit)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::uuid, $11, $12, $13, $14)
+		RETURNING id
+	`, req.Code, req.ProductID, req.Qty, req.SrcWarehouse, req.DstWarehouse, req.UnitCost, req.Value,
+		req.Note, req.RefModule, refID, string(req.Status), req.RequestedBy, req.RequestedAt, req.InTransit).Scan(&id)
+	return id, err
+}
+
+// GetTransferRequest loads a transfer request by ID.
+func (r *Repository) GetTransferRequest(ctx context.Context, id int64) (TransferRequest, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+transferRequestColumns+` FROM inventory_transfer_requests WHERE id = $1`, id)
+	req, err := scanTransferRequest(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TransferRequest{}, ErrTransferRequestNotFound
+	}
+	return req, err
+}
+
+// ListTransferRequests lists requests by status, newest first; all when status is empty.
+func (r *Repository) ListTransferRequests(ctx context.Context, status TransferStatus) ([]TransferRequest, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+transferRequestColumns+`
+		FROM inventory_transfer_requests
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY requested_at DESC, id DESC
+		LIMIT 200
+	`, string(status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []TransferRequest
+	for rows.Next() {
+		req, err := scanTransferRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, req)
+	}
+	return out, rows.Err()
+}
+
+// DecideTransferRequest moves a pending request to approved or rejected.
+func (r *Repository) DecideTransferRequest(ctx context.Context, id int64, status TransferStatus, actorID int64, reason string) error {
+	var decidedBy *int64
+	if actorID > 0 {
+		decidedBy = &actorID
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE inventory_transfer_requests
+		SET status = $2, decided_by = $3, decided_at = NOW(), decision_reason = $4
+		WHERE id = $1 AND status = 'PENDING'
+	`, id, string(status), decidedBy, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTransferNotPending
+	}
+	return nil
+}
+
+// ReopenTransferRequest returns an approved request to pending after its
+// posting failed.
+func (r *Repository) ReopenTransferRequest(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE inventory_transfer_requests
+		SET status = 'PENDING', decided_by = NULL, decided_at = NULL, decision_reason = ''
+		WHERE id = $1 AND status = 'APPROVED'
+	`, id)
+	return err
+}
+
+// MarkTransferRequestPosted records that an approved request's transfer was
+// posted.
+func (r *Repository) MarkTransferRequestPosted(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE inventory_transfer_requests
+		SET posted_at = NOW()
+		WHERE id = $1 AND status = 'APPROVED' AND posted_at IS NULL
+	`, id)
+	return err
+}
+
+func scanTransferRequest(row pgx.Row) (TransferRequest, error) {
+	var req TransferRequest
+	var status string
+	err := row.Scan(&req.ID, &req.Code, &req.ProductID, &req.Qty, &req.SrcWarehouse, &req.DstWarehouse,
+		&req.UnitCost, &req.Value, &req.Note, &req.RefModule, &req.RefID, &status,
+		&req.RequestedBy, &req.RequestedAt, &req.DecidedBy, &req.DecidedAt, &req.DecisionReason, &req.InTransit, &req.PostedAt)
+	req.Status = TransferStatus(status)
+	return req, err
+}
+
+const transferColumns = `t.id, t.code, t.product_id, COALESCE(p.sku, ''), COALESCE(p.name, ''), t.qty::float8,
+	t.src_warehouse_id, COALESCE(src.name, ''), t.dst_warehouse_id, COALESCE(dst.name, ''),
+	t.unit_cost::float8, t.value::float8, t.note, t.status, COALESCE(t.dispatched_by, 0), t.dispatched_at,
+	t.received_by, t.received_at`
+
+const transferFrom = `
+	FROM inventory_transfers t
+	LEFT JOIN products p ON p.id = t.product_id
+	LEFT JOIN warehouses src ON src.id = t.src_warehouse_id
+	LEFT JOIN warehouses dst ON dst.id = t.dst_warehouse_id`
+
+// CreateTransfer stores a transfer when its outbound leg is posted.
+func (r *Repository) CreateTransfer(ctx context.Context, transfer Transfer) (int64, error) {
+	var dispatchedBy *int64
+	if transfer.DispatchedBy > 0 {
+		dispatchedBy = &transfer.DispatchedBy
+	}
+	var id int64
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO inventory_transfers
+			(code, product_id, qty, src_warehouse_id, dst_warehouse_id, unit_cost, value, note, status, dispatched_by, dispatched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, transfer.Code, transfer.ProductID, transfer.Qty, transfer.SrcWarehouse, transfer.DstWarehouse, transfer.UnitCost,
+		transfer.Value, transfer.Note, string(transfer.Status), dispatchedBy, transfer.DispatchedAt).Scan(&id)
+	return id, err
+}
+
+// GetTransfer loads a posted transfer by its base code.
+func (r *Repository) GetTransfer(ctx context.Context, code string) (Transfer, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+transferColumns+transferFrom+` WHERE t.code = $1`, code)
+	transfer, err := scanTransfer(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Transfer{}, ErrTransferNotFound
+	}
+	return transfer, err
+}
+
+// MarkTransferReceived closes an in-transit transfer.
+func (r *Repository) MarkTransferReceived(ctx context.Context, id, actorID int64, at time.Time) error {
+	var receivedBy *int64
+	if actorID > 0 {
+		receivedBy = &actorID
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE inventory_transfers
+		SET status = 'RECEIVED', received_by = $2, received_at = $3
+		WHERE id = $1 AND status = 'IN_TRANSIT'
+	`, id, receivedBy, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTransferNotInTransit
+	}
+	return nil
+}
+
+// ListTransfers lists transfers matching filter. In-transit listings run
+// oldest first so the longest-waiting stock leads; everything else newest first.
+// A restricted caller sees transfers touching a warehouse of their companies.
+func (r *Repository) ListTransfers(ctx context.Context, filter TransferFilter) ([]Transfer, error) {
+	order := "t.dispatched_at DESC, t.id DESC"
+	if filter.Status == TransferStateInTransit {
+		order = "t.dispatched_at, t.id"
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+	scope := shared.CompanyScopeFromContext(ctx)
+	scoped := fmt.Sprintf(scopedWarehouses, 6)
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+transferColumns+transferFrom+`
+		WHERE ($1 = '' OR t.status = $1)
+		  AND ($2 = 0 OR t.product_id = $2)
+		  AND ($3 = 0 OR t.src_warehouse_id = $3 OR t.dst_warehouse_id = $3)
+		  AND ($5 OR t.src_warehouse_id IN (`+scoped+`) OR t.dst_warehouse_id IN (`+scoped+`))
+		ORDER BY `+order+`
+		LIMIT $4
+	`, string(filter.Status), filter.ProductID, filter.WarehouseID, limit, scope.Unrestricted, scope.CompanyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Transfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, transfer)
+	}
+	return out, rows.Err()
+}
+
+func scanTransfer(row pgx.Row) (Transfer, error) {
+	var transfer Transfer
+	var status string
+	err := row.Scan(&transfer.ID, &transfer.Code, &transfer.ProductID, &transfer.SKU, &transfer.ProductName, &transfer.Qty,
+		&transfer.SrcWarehouse, &transfer.SrcWarehouseName, &transfer.DstWarehouse, &transfer.DstWarehouseName,
+		&transfer.UnitCost, &transfer.Value, &transfer.Note, &status, &transfer.DispatchedBy, &transfer.DispatchedAt,
+		&transfer.ReceivedBy, &transfer.ReceivedAt)
+	transfer.Status = TransferState(status)
+	return transfer, err
+}
@@ -0,0 +1,51 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
+)
+
+// InventoryValuation returns on-hand quantity and value per warehouse/product
+// as of filter.AsOf.
+func (r *Repository) InventoryValuation(ctx context.Context, filter ValuationFilter) ([]ValuationLine, error) {
+	rows, err := r.queries.InventoryValuationAsOf(ctx, sqlc.InventoryValuationAsOfParams{
+		AsOf:        pgtype.Timestamptz{Time: filter.AsOf, Valid: true},
+		WarehouseID: pgtype.Int8{Int64: filter.WarehouseID, Valid: filter.WarehouseID != 0},
+		CategoryID:  pgtype.Int8{Int64: filter.CategoryID, Valid: filter.CategoryID != 0},
+		CompanyID:   pgtype.Int8{Int64: filter.CompanyID, Valid: filter.CompanyID != 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]ValuationLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, ValuationLine{
+			WarehouseID:   row.WarehouseID,
+			WarehouseCode: row.WarehouseCode,
+			WarehouseName: row.WarehouseName,
+			ProductID:     row.ProductID,
+			SKU:           row.Sku,
+			ProductName:   row.ProductName,
+			CategoryID:    row.CategoryID,
+			Qty:           row.Qty,
+			AvgCost:       row.AvgCost,
+			Value:         row.Value,
+		})
+	}
+	return lines, nil
+}
+
+// InventoryGLBalance returns the inventory asset account balance up to asOf,
+// optionally limited to journal lines stamped with the warehouse dimension
+// and to one company's lines.
+func (r *Repository) InventoryGLBalance(ctx context.Context, asOf time.Time, warehouseID, companyID int64) (float64, error) {
+	return r.queries.InventoryGLBalanceAsOf(ctx, sqlc.InventoryGLBalanceAsOfParams{
+		AsOf:        pgtype.Date{Time: asOf, Valid: true},
+		WarehouseID: pgtype.Int8{Int64: warehouseID, Valid: warehouseID != 0},
+		CompanyID:   pgtype.Int8{Int64: companyID, Valid: companyID != 0},
+	})
+}
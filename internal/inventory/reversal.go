@@ -0,0 +1,93 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTransactionNotFound indicates no inventory transaction has the code.
+	ErrTransactionNotFound = errors.New("inventory: transaction not found")
+	// ErrNotReversible indicates the transaction is not a single-line adjustment.
+	ErrNotReversible = errors.New("inventory: only adjustments can be reversed")
+	// ErrAlreadyReversed indicates the transaction already has a reversal.
+	ErrAlreadyReversed = errors.New("inventory: transaction already reversed")
+	// ErrReversalReasonRequired indicates a reversal without a reason.
+	ErrReversalReasonRequired = errors.New("inventory: reversal reason required")
+)
+
+// reversalSuffix marks the code of the transaction reversing another one.
+const reversalSuffix = "-REV"
+
+// PeriodGuard checks that the accounting period covering a date still
+// accepts postings.
+type PeriodGuard interface {
+	EnsureOpen(ctx context.Context, date time.Time) error
+}
+
+// ReversalInput identifies the adjustment to reverse.
+type ReversalInput struct {
+	Code    string
+	Reason  string
+	ActorID int64
+}
+
+// SetPeriodGuard restricts reversals to transactions in open periods.
+func (s *Service) SetPeriodGuard(guard PeriodGuard) {
+	s.periods = guard
+}
+
+// ReverseAdjustment posts the opposite movement of an adjustment under the
+// code <code>-REV, which also reverses its ledger entry. The adjustment's
+// period must still be open and each adjustment can be reversed once.
+func (s *Service) ReverseAdjustment(ctx context.Context, input ReversalInput) (StockCardEntry, error) {
+	code := strings.TrimSpace(input.Code)
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return StockCardEntry{}, ErrReversalReasonRequired
+	}
+	if strings.HasSuffix(code, reversalSuffix) {
+		return StockCardEntry{}, ErrNotReversible
+	}
+	orig, lines, err := s.repo.GetTransactionByCode(ctx, code)
+	if err != nil {
+		return StockCardEntry{}, err
+	}
+	if orig.Type != TransactionTypeAdjust || len(lines) != 1 {
+		return StockCardEntry{}, ErrNotReversible
+	}
+	_, _, err = s.repo.GetTransactionByCode(ctx, code+reversalSuffix)
+	switch {
+	case err == nil:
+		return StockCardEntry{}, ErrAlreadyReversed
+	case !errors.Is(err, ErrTransactionNotFound):
+		return StockCardEntry{}, err
+	}
+	if s.periods != nil {
+		if err := s.periods.EnsureOpen(ctx, orig.PostedAt); err != nil {
+			return StockCardEntry{}, err
+		}
+	}
+	line := lines[0]
+	return s.PostAdjustment(ctx, AdjustmentInput{
+		Code:        code + reversalSuffix,
+		WarehouseID: orig.WarehouseID,
+		ProductID:   line.ProductID,
+		Qty:         -line.Qty,
+		UnitCost:    line.UnitCost,
+		Note:        fmt.Sprintf("Reversal of %s: %s", code, reason),
+		ActorID:     input.ActorID,
+		RefModule:   orig.RefModule,
+		RefID:       orig.RefID,
+	})
+}
+
+// Reversible reports whether the entry is an adjustment that can be reversed.
+// The stock card does not know about existing reversals; the service rejects
+// those.
+func (e StockCardEntry) Reversible() bool {
+	return e.TxType == TransactionTypeAdjust && !strings.HasSuffix(e.TxCode, reversalSuffix)
+}
@@ -0,0 +1,91 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+func (r *memoryRepo) GetTransactionByCode(ctx context.Context, code string) (Transaction, []TransactionLine, error) {
+	header, ok := r.txs[code]
+	if !ok {
+		return Transaction{}, nil, ErrTransactionNotFound
+	}
+	return header, r.txLines[header.ID], nil
+}
+
+type stubPeriodGuard struct {
+	err error
+}
+
+func (g stubPeriodGuard) EnsureOpen(context.Context, time.Time) error {
+	return g.err
+}
+
+type recordingAdjustments struct {
+	events  []AdjustmentPostedEvent
+	batches []AdjustmentBatchPostedEvent
+}
+
+func (r *recordingAdjustments) HandleInventoryAdjustmentPosted(_ context.Context, evt AdjustmentPostedEvent) error {
+	r.events = append(r.events, evt)
+	return nil
+}
+
+func (r *recordingAdjustments) HandleInventoryAdjustmentBatchPosted(_ context.Context, evt AdjustmentBatchPostedEvent) error {
+	r.batches = append(r.batches, evt)
+	return nil
+}
+
+func (r *recordingAdjustments) HandleInventoryRevaluationPosted(context.Context, RevaluationPostedEvent) error {
+	return nil
+}
+
+func TestReverseAdjustment(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	ledger := &recordingAdjustments{}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, ledger)
+
+	_, err := svc.PostAdjustment(ctx, AdjustmentInput{Code: "ADJ-1", WarehouseID: 1, ProductID: 2, Qty: 5, UnitCost: 10})
+	require.NoError(t, err)
+
+	_, err = svc.ReverseAdjustment(ctx, ReversalInput{Code: "ADJ-1"})
+	require.ErrorIs(t, err, ErrReversalReasonRequired)
+
+	svc.SetPeriodGuard(stubPeriodGuard{err: accounting.ErrPeriodLocked})
+	_, err = svc.ReverseAdjustment(ctx, ReversalInput{Code: "ADJ-1", Reason: "counted twice"})
+	require.ErrorIs(t, err, accounting.ErrPeriodLocked)
+
+	svc.SetPeriodGuard(stubPeriodGuard{})
+	entry, err := svc.ReverseAdjustment(ctx, ReversalInput{Code: "ADJ-1", Reason: "counted twice"})
+	require.NoError(t, err)
+	require.Equal(t, "ADJ-1-REV", entry.TxCode)
+	require.Equal(t, 5.0, entry.QtyOut)
+	require.Equal(t, 0.0, repo.balances[key(1, 2)].Qty)
+	require.Len(t, ledger.events, 2)
+	require.Equal(t, -5.0, ledger.events[1].Qty)
+
+	_, err = svc.ReverseAdjustment(ctx, ReversalInput{Code: "ADJ-1", Reason: "again"})
+	require.ErrorIs(t, err, ErrAlreadyReversed)
+	_, err = svc.ReverseAdjustment(ctx, ReversalInput{Code: "ADJ-1-REV", Reason: "undo"})
+	require.ErrorIs(t, err, ErrNotReversible)
+}
+
+func TestReverseAdjustmentRejectsOtherMovements(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+
+	_, err := svc.PostInbound(ctx, InboundInput{Code: "GRN-1", WarehouseID: 1, ProductID: 2, Qty: 5, UnitCost: 10})
+	require.NoError(t, err)
+
+	_, err = svc.ReverseAdjustment(ctx, ReversalInput{Code: "GRN-1", Reason: "wrong"})
+	require.ErrorIs(t, err, ErrNotReversible)
+	_, err = svc.ReverseAdjustment(ctx, ReversalInput{Code: "NOPE", Reason: "wrong"})
+	require.ErrorIs(t, err, ErrTransactionNotFound)
+}
@@ -0,0 +1,94 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// SerialStatus tracks where a serialised unit currently is.
+type SerialStatus string
+
+const (
+	SerialStatusInStock   SerialStatus = "IN_STOCK"
+	SerialStatusDelivered SerialStatus = "DELIVERED"
+)
+
+var (
+	// ErrSerialCount indicates the serials entered do not match the quantity.
+	ErrSerialCount = errors.New("inventory: serial numbers must match the quantity")
+	// ErrDuplicateSerial indicates a serial number is already used for the product.
+	ErrDuplicateSerial = errors.New("inventory: serial number already exists for product")
+	// ErrSerialUnavailable indicates a serial is not in stock at the warehouse.
+	ErrSerialUnavailable = errors.New("inventory: serial number not in stock")
+)
+
+// SerialRecord is one serialised unit with its receipt and shipment history.
+type SerialRecord struct {
+	ID                int64
+	ProductID         int64
+	SKU               string
+	ProductName       string
+	SerialNumber      string
+	Status            SerialStatus
+	WarehouseID       int64
+	WarehouseName     string
+	GRNID             int64
+	GRNNumber         string
+	ReceivedAt        time.Time
+	DeliveryOrderID   int64
+	DeliveryDocNumber string
+	CustomerName      string
+	DeliveredAt       *time.Time
+}
+
+// ParseSerials splits free-form input on newlines, commas and semicolons.
+func ParseSerials(raw string) []string {
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ',' || r == ';'
+	})
+	serials := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			serials = append(serials, field)
+		}
+	}
+	return serials
+}
+
+// NormalizeSerials trims the serials for qty units of a serialised product and
+// checks there is exactly one distinct serial per unit.
+func NormalizeSerials(serials []string, qty float64) ([]string, error) {
+	if qty != math.Trunc(qty) {
+		return nil, fmt.Errorf("%w: quantity %.4g is not a whole number of units", ErrSerialCount, qty)
+	}
+	out := make([]string, 0, len(serials))
+	seen := make(map[string]struct{}, len(serials))
+	for _, serial := range serials {
+		serial = strings.TrimSpace(serial)
+		if serial == "" {
+			continue
+		}
+		if _, dup := seen[serial]; dup {
+			return nil, fmt.Errorf("%w: %s entered twice", ErrDuplicateSerial, serial)
+		}
+		seen[serial] = struct{}{}
+		out = append(out, serial)
+	}
+	if float64(len(out)) != qty {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrSerialCount, int64(qty), len(out))
+	}
+	return out, nil
+}
+
+// LookupSerial finds every unit carrying the serial number, across products.
+func (s *Service) LookupSerial(ctx context.Context, serial string) ([]SerialRecord, error) {
+	serial = strings.TrimSpace(serial)
+	if serial == "" {
+		return nil, nil
+	}
+	return s.repo.LookupSerials(ctx, serial)
+}
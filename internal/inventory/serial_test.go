@@ -0,0 +1,56 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (r *memoryRepo) LookupSerials(ctx context.Context, serial string) ([]SerialRecord, error) {
+	var out []SerialRecord
+	for _, rec := range r.serials {
+		if rec.SerialNumber == serial {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func TestParseSerialsSplitsLinesAndCommas(t *testing.T) {
+	got := ParseSerials(" SN-1\r\nSN-2, SN-3;\n\n SN-4 ")
+	require.Equal(t, []string{"SN-1", "SN-2", "SN-3", "SN-4"}, got)
+}
+
+func TestNormalizeSerials(t *testing.T) {
+	got, err := NormalizeSerials([]string{" A ", "B", ""}, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"A", "B"}, got)
+
+	_, err = NormalizeSerials([]string{"A"}, 2)
+	require.ErrorIs(t, err, ErrSerialCount)
+
+	_, err = NormalizeSerials([]string{"A", "A"}, 2)
+	require.ErrorIs(t, err, ErrDuplicateSerial)
+
+	_, err = NormalizeSerials([]string{"A", "B"}, 1.5)
+	require.ErrorIs(t, err, ErrSerialCount)
+}
+
+func TestLookupSerialTrimsInput(t *testing.T) {
+	repo := newMemoryRepo()
+	repo.serials = []SerialRecord{
+		{ID: 1, ProductID: 7, SerialNumber: "SN-9", Status: SerialStatusDelivered, DeliveryDocNumber: "DO-1"},
+		{ID: 2, ProductID: 8, SerialNumber: "SN-10", Status: SerialStatusInStock},
+	}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+
+	got, err := svc.LookupSerial(context.Background(), "  SN-9 ")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, "DO-1", got[0].DeliveryDocNumber)
+
+	got, err = svc.LookupSerial(context.Background(), " ")
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
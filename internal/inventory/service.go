@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +17,33 @@ import (
 type RepositoryPort interface {
 	WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error
 	GetStockCard(ctx context.Context, filter StockCardFilter) ([]StockCardEntry, error)
+	CreateTransferRequest(ctx context.Context, req TransferRequest) (int64, error)
+	GetTransferRequest(ctx context.Context, id int64) (TransferRequest, error)
+	ListTransferRequests(ctx context.Context, status TransferStatus) ([]TransferRequest, error)
+	DecideTransferRequest(ctx context.Context, id int64, status TransferStatus, actorID int64, reason string) error
+	ReopenTransferRequest(ctx context.Context, id int64) error
+	MarkTransferRequestPosted(ctx context.Context, id int64) error
+	InventoryValuation(ctx context.Context, filter ValuationFilter) ([]ValuationLine, error)
+	InventoryGLBalance(ctx context.Context, asOf time.Time, warehouseID, companyID int64) (float64, error)
+	InventoryConsumption(ctx context.Context, from, to time.Time, companyID, warehouseID int64) ([]ABCLine, error)
+	ReplaceABCClasses(ctx context.Context, report ABCReport) error
+	ListABCClasses(ctx context.Context, companyID, warehouseID int64) ([]ABCClassification, error)
+	LookupSerials(ctx context.Context, serial string) ([]SerialRecord, error)
+	GetTransactionByCode(ctx context.Context, code string) (Transaction, []TransactionLine, error)
+	CreateTransfer(ctx context.Context, transfer Transfer) (int64, error)
+	GetTransfer(ctx context.Context, code string) (Transfer, error)
+	MarkTransferReceived(ctx context.Context, id, actorID int64, at time.Time) error
+	ListTransfers(ctx context.Context, filter TransferFilter) ([]Transfer, error)
+	TransfersInTransitAsOf(ctx context.Context, asOf time.Time, warehouseID int64) ([]Transfer, error)
+	StockMovementsAsOf(ctx context.Context, asOf time.Time, warehouseID int64) ([]StockMovement, error)
+	PostedJournalDates(ctx context.Context, sourceIDs []uuid.UUID) (map[uuid.UUID]time.Time, error)
+	EarlyGRNJournals(ctx context.Context, asOf time.Time, warehouseID int64) ([]EarlyJournal, error)
+	GLOnlyInventoryEntries(ctx context.Context, asOf time.Time, warehouseID int64) ([]GLOnlyEntry, error)
+	SearchTransactions(ctx context.Context, filter TransactionSearchFilter) ([]TransactionMatch, error)
+	StockAvailability(ctx context.Context, filter AvailabilityFilter) ([]AvailabilityLine, error)
+	StockReservations(ctx context.Context, warehouseID, productID int64) ([]StockReservation, error)
+	ReplenishmentPositions(ctx context.Context, warehouseID int64, demandSince time.Time) ([]ReplenishmentPosition, error)
+	UpsertReplenishmentRule(ctx context.Context, rule ReplenishmentRule) error
 }
 
 // AuditPort abstracts audit logging functionality.
@@ -30,16 +58,36 @@ type Service struct {
 	idempotency *shared.IdempotencyStore
 	allowNeg    bool
 	integration IntegrationHandler
+	approvals   ApprovalPort
+	router      shared.ApprovalRouter
+	threshold   float64
+	periods     PeriodGuard
+	glTolerance float64
+	batchCounts bool
+	// replenishment configures the min/max suggestions.
+	replenishment ReplenishmentParams
 }
 
 // ServiceConfig groups optional settings.
 type ServiceConfig struct {
 	AllowNegativeStock bool
+	// TransferApprovalThreshold is the transfer value at or above which a
+	// transfer waits for approval. Zero disables the approval step.
+	TransferApprovalThreshold float64
+	// GLTolerance is the unexplained inventory GL difference accepted by the
+	// reconciliation report.
+	GLTolerance float64
+	// BatchStockCountJournals posts the ledger impact of a stock count as one
+	// journal instead of one journal per adjusted product.
+	BatchStockCountJournals bool
+	// Replenishment sets the default lead time and demand window of
+	// replenishment suggestions.
+	Replenishment ReplenishmentParams
 }
 
 // NewService builds Service.
 func NewService(repo RepositoryPort, audit AuditPort, idem *shared.IdempotencyStore, cfg ServiceConfig, integration IntegrationHandler) *Service {
-	return &Service{repo: repo, audit: audit, idempotency: idem, allowNeg: cfg.AllowNegativeStock, integration: integration}
+	return &Service{repo: repo, audit: audit, idempotency: idem, allowNeg: cfg.AllowNegativeStock, integration: integration, threshold: cfg.TransferApprovalThreshold, glTolerance: cfg.GLTolerance, batchCounts: cfg.BatchStockCountJournals, replenishment: cfg.Replenishment}
 }
 
 // PostInbound posts an inbound movement (e.g. GRN).
@@ -68,6 +116,133 @@ func (s *Service) PostInbound(ctx context.Context, input InboundInput) (StockCar
 	return s.postMovement(ctx, params)
 }
 
+// PostInboundBatch posts all inbound lines of a receipt as one transaction.
+// Balance rows are locked once and lines, balances and stock cards are each
+// written with a single set-based statement instead of one round-trip per line.
+func (s *Service) PostInboundBatch(ctx context.Context, input InboundBatchInput) ([]StockCardEntry, error) {
+	if input.WarehouseID == 0 || len(input.Lines) == 0 {
+		return nil, errors.New("inventory: warehouse and lines required")
+	}
+	productIDs := make([]int64, 0, len(input.Lines))
+	seen := make(map[int64]struct{}, len(input.Lines))
+	for _, line := range input.Lines {
+		if line.ProductID == 0 {
+			return nil, errors.New("inventory: warehouse and product required")
+		}
+		if line.Qty <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		if line.UnitCost < 0 {
+			return nil, ErrInvalidUnitCost
+		}
+		if _, ok := seen[line.ProductID]; !ok {
+			seen[line.ProductID] = struct{}{}
+			productIDs = append(productIDs, line.ProductID)
+		}
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+	if input.RefID != "" {
+		if _, err := uuid.Parse(input.RefID); err != nil {
+			return nil, fmt.Errorf("inventory: invalid ref id: %w", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	code := input.Code
+	if code == "" {
+		code = fmt.Sprintf("INV-%d", now.UnixNano())
+	}
+	key := fmt.Sprintf("%s:%s:%d", TransactionTypeIn, code, input.WarehouseID)
+	insertedKey := false
+	if s.idempotency != nil {
+		if err := s.idempotency.CheckAndInsert(ctx, key, "inventory"); err != nil {
+			return nil, err
+		}
+		insertedKey = true
+	}
+
+	var cards []StockCardEntry
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		running, err := tx.LockBalances(ctx, input.WarehouseID, productIDs)
+		if err != nil {
+			return err
+		}
+		txID, err := tx.InsertTransaction(ctx, Transaction{
+			Code:        code,
+			Type:        TransactionTypeIn,
+			WarehouseID: input.WarehouseID,
+			RefModule:   input.RefModule,
+			RefID:       input.RefID,
+			Note:        input.Note,
+			PostedAt:    now,
+			CreatedBy:   input.ActorID,
+		})
+		if err != nil {
+			return err
+		}
+		lines := make([]TransactionLine, len(input.Lines))
+		for i, line := range input.Lines {
+			lines[i] = TransactionLine{
+				TransactionID:  txID,
+				ProductID:      line.ProductID,
+				Qty:            line.Qty,
+				UnitCost:       line.UnitCost,
+				DstWarehouseID: input.WarehouseID,
+			}
+		}
+		if err := tx.InsertTransactionLines(ctx, txID, lines); err != nil {
+			return err
+		}
+		if _, err := tx.ApplyInboundBalances(ctx, input.WarehouseID, lines); err != nil {
+			return err
+		}
+
+		header := StockCardEntry{TxCode: code, TxType: TransactionTypeIn, PostedAt: now, Note: input.Note}
+		entries := make([]ProductCardEntry, len(lines))
+		cards = make([]StockCardEntry, len(lines))
+		for i, line := range lines {
+			balance := running[line.ProductID]
+			newQty := balance.Qty + line.Qty
+			if newQty != 0 {
+				balance.AvgCost = (balance.Qty*balance.AvgCost + line.Qty*line.UnitCost) / newQty
+			} else {
+				balance.AvgCost = 0
+			}
+			balance.Qty = newQty
+			running[line.ProductID] = balance
+
+			card := header
+			card.QtyIn = line.Qty
+			card.BalanceQty = balance.Qty
+			card.UnitCost = line.UnitCost
+			card.BalanceCost = balance.AvgCost
+			cards[i] = card
+			entries[i] = ProductCardEntry{ProductID: line.ProductID, StockCardEntry: card}
+		}
+		return tx.InsertCardEntries(ctx, input.WarehouseID, txID, header, entries)
+	})
+	if err != nil {
+		if insertedKey {
+			_ = s.idempotency.Delete(ctx, key)
+		}
+		return nil, err
+	}
+	if s.audit != nil {
+		_ = s.audit.Record(ctx, shared.AuditLog{
+			ActorID:  input.ActorID,
+			Action:   fmt.Sprintf("inventory:%s", TransactionTypeIn),
+			Entity:   "inventory_tx",
+			EntityID: code,
+			Meta: map[string]any{
+				"warehouse_id": input.WarehouseID,
+				"lines":        len(input.Lines),
+				"note":         input.Note,
+			},
+		})
+	}
+	return cards, nil
+}
+
 // PostAdjustment posts an adjustment which may be positive or negative.
 func (s *Service) PostAdjustment(ctx context.Context, input AdjustmentInput) (StockCardEntry, error) {
 	if input.WarehouseID == 0 || input.ProductID == 0 {
@@ -111,22 +286,43 @@ func (s *Service) PostAdjustment(ctx context.Context, input AdjustmentInput) (St
 	return entry, nil
 }
 
-// PostTransfer moves stock between warehouses using OUT + IN.
+// PostTransfer moves stock between warehouses using OUT + IN. With
+// input.InTransit only the OUT leg is posted and the transfer stays open.
 func (s *Service) PostTransfer(ctx context.Context, input TransferInput) (StockCardEntry, StockCardEntry, error) {
-	if input.SrcWarehouse == 0 || input.DstWarehouse == 0 || input.ProductID == 0 {
-		return StockCardEntry{}, StockCardEntry{}, errors.New("inventory: warehouse and product required")
+	if err := validateTransfer(input); err != nil {
+		return StockCardEntry{}, StockCardEntry{}, err
 	}
-	if input.SrcWarehouse == input.DstWarehouse {
-		return StockCardEntry{}, StockCardEntry{}, errors.New("inventory: source and destination warehouse must differ")
+	input.Code = baseCode(input.Code)
+	outCard, err := s.postMovement(ctx, transferOutParams(input))
+	if err != nil {
+		return StockCardEntry{}, StockCardEntry{}, err
 	}
-	if input.Qty <= 0 {
-		return StockCardEntry{}, StockCardEntry{}, ErrInvalidQuantity
+	// Stock arrives at the cost it left the source with, so the destination
+	// average blends it in at that cost. The entered cost only applies when
+	// the source has no average cost.
+	if outCard.UnitCost > 0 {
+		input.UnitCost = outCard.UnitCost
 	}
-	if input.UnitCost < 0 {
-		return StockCardEntry{}, StockCardEntry{}, ErrInvalidUnitCost
+	transfer, err := s.recordDispatch(ctx, input, outCard)
+	if err != nil {
+		return StockCardEntry{}, StockCardEntry{}, err
 	}
-	outParams := movementParams{
-		Code:        fmt.Sprintf("%s-OUT", baseCode(input.Code)),
+	if input.InTransit {
+		return outCard, StockCardEntry{}, nil
+	}
+	inCard, err := s.postMovement(ctx, transferInParams(input))
+	if err != nil {
+		return StockCardEntry{}, StockCardEntry{}, err
+	}
+	if err := s.repo.MarkTransferReceived(ctx, transfer.ID, input.ActorID, inCard.PostedAt); err != nil {
+		return StockCardEntry{}, StockCardEntry{}, err
+	}
+	return outCard, inCard, nil
+}
+
+func transferOutParams(input TransferInput) movementParams {
+	return movementParams{
+		Code:        fmt.Sprintf("%s-OUT", input.Code),
 		WarehouseID: input.SrcWarehouse,
 		ProductID:   input.ProductID,
 		QtyChange:   -input.Qty,
@@ -137,8 +333,11 @@ func (s *Service) PostTransfer(ctx context.Context, input TransferInput) (StockC
 		RefModule:   input.RefModule,
 		RefID:       input.RefID,
 	}
-	inParams := movementParams{
-		Code:        fmt.Sprintf("%s-IN", baseCode(input.Code)),
+}
+
+func transferInParams(input TransferInput) movementParams {
+	return movementParams{
+		Code:        fmt.Sprintf("%s-IN", input.Code),
 		WarehouseID: input.DstWarehouse,
 		ProductID:   input.ProductID,
 		QtyChange:   input.Qty,
@@ -149,15 +348,22 @@ func (s *Service) PostTransfer(ctx context.Context, input TransferInput) (StockC
 		RefModule:   input.RefModule,
 		RefID:       input.RefID,
 	}
-	outCard, err := s.postMovement(ctx, outParams)
-	if err != nil {
-		return StockCardEntry{}, StockCardEntry{}, err
+}
+
+func validateTransfer(input TransferInput) error {
+	if input.SrcWarehouse == 0 || input.DstWarehouse == 0 || input.ProductID == 0 {
+		return errors.New("inventory: warehouse and product required")
 	}
-	inCard, err := s.postMovement(ctx, inParams)
-	if err != nil {
-		return StockCardEntry{}, StockCardEntry{}, err
+	if input.SrcWarehouse == input.DstWarehouse {
+		return errors.New("inventory: source and destination warehouse must differ")
 	}
-	return outCard, inCard, nil
+	if input.Qty <= 0 {
+		return ErrInvalidQuantity
+	}
+	if input.UnitCost < 0 {
+		return ErrInvalidUnitCost
+	}
+	return nil
 }
 
 // GetStockCard lists stock card entries.
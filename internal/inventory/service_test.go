@@ -4,16 +4,52 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	_ "github.com/odyssey-erp/odyssey-erp/testing"
 )
 
 type memoryRepo struct {
-	balances map[string]Balance
-	cards    []StockCardEntry
-	nextID   int64
+	balances  map[string]Balance
+	cards     []StockCardEntry
+	nextID    int64
+	transfers map[int64]TransferRequest
+
+	valuation       []ValuationLine
+	valuationFilter ValuationFilter
+	glBalance       float64
+	glCompanyID     int64
+
+	consumption        []ABCLine
+	consumptionFrom    time.Time
+	consumptionTo      time.Time
+	consumptionCompany int64
+	abcReport          ABCReport
+
+	serials []SerialRecord
+
+	txs     map[string]Transaction
+	txLines map[int64][]TransactionLine
+
+	posted map[string]Transfer
+
+	inTransitAsOf []Transfer
+	movements     []StockMovement
+	journalDates  map[uuid.UUID]time.Time
+	earlyJournals []EarlyJournal
+	glOnly        []GLOnlyEntry
+
+	searchFilter TransactionSearchFilter
+
+	availability       []AvailabilityLine
+	availabilityFilter AvailabilityFilter
+
+	positions   []ReplenishmentPosition
+	demandSince time.Time
+	savedRules  []ReplenishmentRule
 }
 
 type memoryTx struct {
@@ -21,7 +57,13 @@ type memoryTx struct {
 }
 
 func newMemoryRepo() *memoryRepo {
-	return &memoryRepo{balances: make(map[string]Balance)}
+	return &memoryRepo{
+		balances:  make(map[string]Balance),
+		transfers: make(map[int64]TransferRequest),
+		txs:       make(map[string]Transaction),
+		txLines:   make(map[int64][]TransactionLine),
+		posted:    make(map[string]Transfer),
+	}
 }
 
 func (r *memoryRepo) balanceKey(warehouseID, productID int64) string {
@@ -43,12 +85,15 @@ func (r *memoryRepo) GetStockCard(ctx context.Context, filter StockCardFilter) (
 	return result, nil
 }
 
-func (tx *memoryTx) InsertTransaction(ctx context.Context, _ Transaction) (int64, error) {
+func (tx *memoryTx) InsertTransaction(ctx context.Context, header Transaction) (int64, error) {
 	tx.repo.nextID++
+	header.ID = tx.repo.nextID
+	tx.repo.txs[header.Code] = header
 	return tx.repo.nextID, nil
 }
 
 func (tx *memoryTx) InsertTransactionLines(ctx context.Context, txID int64, lines []TransactionLine) error {
+	tx.repo.txLines[txID] = append(tx.repo.txLines[txID], lines...)
 	return nil
 }
 
@@ -71,6 +116,38 @@ func (tx *memoryTx) InsertCardEntry(ctx context.Context, card StockCardEntry, wa
 	return nil
 }
 
+func (tx *memoryTx) LockBalances(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64]Balance, error) {
+	out := make(map[int64]Balance, len(productIDs))
+	for _, id := range productIDs {
+		if bal, ok := tx.repo.balances[tx.repo.balanceKey(warehouseID, id)]; ok {
+			out[id] = bal
+		}
+	}
+	return out, nil
+}
+
+func (tx *memoryTx) ApplyInboundBalances(ctx context.Context, warehouseID int64, lines []TransactionLine) ([]Balance, error) {
+	var out []Balance
+	for _, line := range lines {
+		key := tx.repo.balanceKey(warehouseID, line.ProductID)
+		bal := tx.repo.balances[key]
+		bal.WarehouseID, bal.ProductID = warehouseID, line.ProductID
+		newQty := bal.Qty + line.Qty
+		bal.AvgCost = (bal.Qty*bal.AvgCost + line.Qty*line.UnitCost) / newQty
+		bal.Qty = newQty
+		tx.repo.balances[key] = bal
+		out = append(out, bal)
+	}
+	return out, nil
+}
+
+func (tx *memoryTx) InsertCardEntries(ctx context.Context, warehouseID, txID int64, header StockCardEntry, entries []ProductCardEntry) error {
+	for _, entry := range entries {
+		tx.repo.cards = append(tx.repo.cards, entry.StockCardEntry)
+	}
+	return nil
+}
+
 func TestAverageMovingCost(t *testing.T) {
 	repo := newMemoryRepo()
 	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
@@ -110,6 +187,46 @@ func TestTransfer(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestTransferBlendsSourceCostIntoDestinationAverage(t *testing.T) {
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+	ctx := context.Background()
+
+	_, err := svc.PostInbound(ctx, InboundInput{WarehouseID: 1, ProductID: 1, Qty: 10, UnitCost: 100, Note: "GRN src"})
+	require.NoError(t, err)
+	_, err = svc.PostInbound(ctx, InboundInput{WarehouseID: 2, ProductID: 1, Qty: 10, UnitCost: 200, Note: "GRN dst"})
+	require.NoError(t, err)
+
+	// The entered cost is ignored in favour of the source's average.
+	out, in, err := svc.PostTransfer(ctx, TransferInput{SrcWarehouse: 1, DstWarehouse: 2, ProductID: 1, Qty: 5, UnitCost: 999, Note: "Move"})
+	require.NoError(t, err)
+	require.InDelta(t, 100, out.UnitCost, 0.0001)
+	require.InDelta(t, 100, out.BalanceCost, 0.0001)
+	require.InDelta(t, 100, in.UnitCost, 0.0001)
+	require.InDelta(t, 15, in.BalanceQty, 0.0001)
+	require.InDelta(t, 166.6667, in.BalanceCost, 0.0001)
+	require.InDelta(t, 166.6667, repo.balances[key(2, 1)].AvgCost, 0.0001)
+	require.InDelta(t, 100, repo.balances[key(1, 1)].AvgCost, 0.0001)
+
+	// Moving it back blends the destination's average into the source.
+	_, back, err := svc.PostTransfer(ctx, TransferInput{SrcWarehouse: 2, DstWarehouse: 1, ProductID: 1, Qty: 3, Note: "Return"})
+	require.NoError(t, err)
+	require.InDelta(t, 166.6667, back.UnitCost, 0.0001)
+	require.InDelta(t, (5*100+3*166.6667)/8, repo.balances[key(1, 1)].AvgCost, 0.0001)
+	require.InDelta(t, 166.6667, repo.balances[key(2, 1)].AvgCost, 0.0001)
+}
+
+func TestTransferUsesEnteredCostWhenSourceHasNone(t *testing.T) {
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{AllowNegativeStock: true}, nil)
+	ctx := context.Background()
+
+	_, in, err := svc.PostTransfer(ctx, TransferInput{SrcWarehouse: 1, DstWarehouse: 2, ProductID: 1, Qty: 2, UnitCost: 40, Note: "Unstocked"})
+	require.NoError(t, err)
+	require.InDelta(t, 40, in.UnitCost, 0.0001)
+	require.InDelta(t, 40, repo.balances[key(2, 1)].AvgCost, 0.0001)
+}
+
 func TestNegativeStockGuard(t *testing.T) {
 	repo := newMemoryRepo()
 	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
@@ -118,3 +235,144 @@ func TestNegativeStockGuard(t *testing.T) {
 	_, err := svc.PostAdjustment(ctx, AdjustmentInput{WarehouseID: 1, ProductID: 1, Qty: -1, Note: "negative"})
 	require.ErrorIs(t, err, ErrNegativeStock)
 }
+
+func TestPostInboundBatchMatchesSequentialAverage(t *testing.T) {
+	ctx := context.Background()
+	lines := []InboundLine{
+		{ProductID: 1, Qty: 10, UnitCost: 100000},
+		{ProductID: 2, Qty: 3, UnitCost: 5000},
+		{ProductID: 1, Qty: 5, UnitCost: 120000},
+	}
+
+	seqRepo := newMemoryRepo()
+	seq := NewService(seqRepo, nil, nil, ServiceConfig{}, nil)
+	for _, line := range lines {
+		_, err := seq.PostInbound(ctx, InboundInput{WarehouseID: 1, ProductID: line.ProductID, Qty: line.Qty, UnitCost: line.UnitCost})
+		require.NoError(t, err)
+	}
+
+	batchRepo := newMemoryRepo()
+	batch := NewService(batchRepo, nil, nil, ServiceConfig{}, nil)
+	cards, err := batch.PostInboundBatch(ctx, InboundBatchInput{Code: "GRN-1", WarehouseID: 1, Lines: lines, Note: "GRN 1"})
+	require.NoError(t, err)
+	require.Len(t, cards, 3)
+	require.InDelta(t, 15.0, cards[2].BalanceQty, 0.0001)
+	require.InDelta(t, 106666.6667, cards[2].BalanceCost, 0.1)
+
+	for _, productID := range []int64{1, 2} {
+		want := seqRepo.balances[key(1, productID)]
+		got := batchRepo.balances[key(1, productID)]
+		require.InDelta(t, want.Qty, got.Qty, 0.0001)
+		require.InDelta(t, want.AvgCost, got.AvgCost, 0.0001)
+	}
+	require.Len(t, batchRepo.cards, 3)
+}
+
+func TestPostInboundBatchValidatesLines(t *testing.T) {
+	svc := NewService(newMemoryRepo(), nil, nil, ServiceConfig{}, nil)
+	_, err := svc.PostInboundBatch(context.Background(), InboundBatchInput{WarehouseID: 1, Lines: []InboundLine{{ProductID: 1, Qty: 0}}})
+	require.ErrorIs(t, err, ErrInvalidQuantity)
+}
+
+// latencyTx simulates a database round-trip per repository call so the
+// benchmarks reflect the number of statements issued per posting.
+type latencyTx struct {
+	TxRepository
+	trips *int
+}
+
+const simulatedRoundTrip = 50 * time.Microsecond
+
+func (l latencyTx) trip() {
+	*l.trips++
+	time.Sleep(simulatedRoundTrip)
+}
+
+func (l latencyTx) InsertTransaction(ctx context.Context, tx Transaction) (int64, error) {
+	l.trip()
+	return l.TxRepository.InsertTransaction(ctx, tx)
+}
+
+func (l latencyTx) InsertTransactionLines(ctx context.Context, txID int64, lines []TransactionLine) error {
+	l.trip()
+	return l.TxRepository.InsertTransactionLines(ctx, txID, lines)
+}
+
+func (l latencyTx) GetBalanceForUpdate(ctx context.Context, warehouseID, productID int64) (Balance, error) {
+	l.trip()
+	return l.TxRepository.GetBalanceForUpdate(ctx, warehouseID, productID)
+}
+
+func (l latencyTx) UpsertBalance(ctx context.Context, balance Balance) error {
+	l.trip()
+	return l.TxRepository.UpsertBalance(ctx, balance)
+}
+
+func (l latencyTx) InsertCardEntry(ctx context.Context, card StockCardEntry, warehouseID, productID int64, txID int64) error {
+	l.trip()
+	return l.TxRepository.InsertCardEntry(ctx, card, warehouseID, productID, txID)
+}
+
+func (l latencyTx) LockBalances(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64]Balance, error) {
+	l.trip()
+	return l.TxRepository.LockBalances(ctx, warehouseID, productIDs)
+}
+
+func (l latencyTx) ApplyInboundBalances(ctx context.Context, warehouseID int64, lines []TransactionLine) ([]Balance, error) {
+	l.trip()
+	return l.TxRepository.ApplyInboundBalances(ctx, warehouseID, lines)
+}
+
+func (l latencyTx) InsertCardEntries(ctx context.Context, warehouseID, txID int64, header StockCardEntry, entries []ProductCardEntry) error {
+	l.trip()
+	return l.TxRepository.InsertCardEntries(ctx, warehouseID, txID, header, entries)
+}
+
+type latencyRepo struct {
+	*memoryRepo
+	trips int
+}
+
+func (r *latencyRepo) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
+	return r.memoryRepo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		return fn(ctx, latencyTx{TxRepository: tx, trips: &r.trips})
+	})
+}
+
+func grnLines(n int) []InboundLine {
+	lines := make([]InboundLine, n)
+	for i := range lines {
+		lines[i] = InboundLine{ProductID: int64(i + 1), Qty: 10, UnitCost: 1000}
+	}
+	return lines
+}
+
+func BenchmarkPostInboundPerLine200(b *testing.B) {
+	ctx := context.Background()
+	lines := grnLines(200)
+	repo := &latencyRepo{memoryRepo: newMemoryRepo()}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			if _, err := svc.PostInbound(ctx, InboundInput{WarehouseID: 1, ProductID: line.ProductID, Qty: line.Qty, UnitCost: line.UnitCost}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(repo.trips)/float64(b.N), "roundtrips/op")
+}
+
+func BenchmarkPostInboundBatch200(b *testing.B) {
+	ctx := context.Background()
+	lines := grnLines(200)
+	repo := &latencyRepo{memoryRepo: newMemoryRepo()}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.PostInboundBatch(ctx, InboundBatchInput{WarehouseID: 1, Lines: lines}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(repo.trips)/float64(b.N), "roundtrips/op")
+}
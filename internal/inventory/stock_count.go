@@ -0,0 +1,142 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockCountRefModule marks the adjustments posted by a stock count.
+const StockCountRefModule = "INVENTORY.STOCK_COUNT"
+
+var (
+	// ErrStockCountEmpty indicates a stock count without variances.
+	ErrStockCountEmpty = errors.New("inventory: stock count has no variances")
+	// ErrStockCountDuplicate indicates a product counted twice in one count.
+	ErrStockCountDuplicate = errors.New("inventory: product listed twice in stock count")
+)
+
+// StockCountInput describes the variances found by a stock count in one
+// warehouse.
+type StockCountInput struct {
+	Code        string
+	WarehouseID int64
+	Lines       []StockCountLine
+	Note        string
+	ActorID     int64
+}
+
+// StockCountLine is the variance of one product. A positive Qty is a surplus
+// valued at UnitCost; a shortage is valued at the moving average cost.
+type StockCountLine struct {
+	ProductID int64
+	Qty       float64
+	UnitCost  float64
+}
+
+// stockCountSource is the journal source id of a batched stock count. The
+// adjustments carry it as their ref id so the GL reconciliation can match
+// them to the journal.
+func stockCountSource(code string) uuid.UUID {
+	return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("STOCKCOUNT:%s", code)))
+}
+
+// PostStockCount posts each variance as its own adjustment, coded
+// <code>-<line>, so stock cards and reversals stay per product. With
+// BatchStockCountJournals the ledger receives the whole count as one event;
+// otherwise every adjustment is journalled on its own. When a line fails the
+// adjustments already posted are still sent to the ledger.
+func (s *Service) PostStockCount(ctx context.Context, input StockCountInput) ([]StockCardEntry, error) {
+	if input.WarehouseID == 0 {
+		return nil, errors.New("inventory: warehouse and product required")
+	}
+	seen := make(map[int64]struct{}, len(input.Lines))
+	lines := make([]StockCountLine, 0, len(input.Lines))
+	for _, line := range input.Lines {
+		if math.Abs(line.Qty) < 1e-9 {
+			continue
+		}
+		if line.ProductID == 0 {
+			return nil, errors.New("inventory: warehouse and product required")
+		}
+		if line.Qty > 0 && line.UnitCost < 0 {
+			return nil, ErrInvalidUnitCost
+		}
+		if _, ok := seen[line.ProductID]; ok {
+			return nil, ErrStockCountDuplicate
+		}
+		seen[line.ProductID] = struct{}{}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return nil, ErrStockCountEmpty
+	}
+	code := strings.TrimSpace(input.Code)
+	if code == "" {
+		code = fmt.Sprintf("SC-%d", time.Now().UTC().UnixNano())
+	}
+	refID := ""
+	if s.batchCounts {
+		refID = stockCountSource(code).String()
+	}
+
+	entries := make([]StockCardEntry, 0, len(lines))
+	events := make([]AdjustmentPostedEvent, 0, len(lines))
+	var postErr error
+	for i, line := range lines {
+		entry, err := s.postMovement(ctx, movementParams{
+			Code:        fmt.Sprintf("%s-%d", code, i+1),
+			WarehouseID: input.WarehouseID,
+			ProductID:   line.ProductID,
+			QtyChange:   line.Qty,
+			UnitCost:    line.UnitCost,
+			TxType:      TransactionTypeAdjust,
+			Note:        input.Note,
+			ActorID:     input.ActorID,
+			RefModule:   StockCountRefModule,
+			RefID:       refID,
+		})
+		if err != nil {
+			postErr = fmt.Errorf("inventory: stock count line %d: %w", i+1, err)
+			break
+		}
+		entries = append(entries, entry)
+		events = append(events, AdjustmentPostedEvent{
+			Code:        entry.TxCode,
+			WarehouseID: input.WarehouseID,
+			ProductID:   line.ProductID,
+			Qty:         line.Qty,
+			UnitCost:    entry.UnitCost,
+			PostedAt:    entry.PostedAt,
+		})
+	}
+	if err := s.postStockCountLedger(ctx, code, input.WarehouseID, events); err != nil {
+		return entries, errors.Join(postErr, err)
+	}
+	return entries, postErr
+}
+
+func (s *Service) postStockCountLedger(ctx context.Context, code string, warehouseID int64, events []AdjustmentPostedEvent) error {
+	if s.integration == nil || len(events) == 0 {
+		return nil
+	}
+	if !s.batchCounts {
+		for _, evt := range events {
+			if err := s.integration.HandleInventoryAdjustmentPosted(ctx, evt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return s.integration.HandleInventoryAdjustmentBatchPosted(ctx, AdjustmentBatchPostedEvent{
+		Code:        code,
+		WarehouseID: warehouseID,
+		Lines:       events,
+		PostedAt:    events[len(events)-1].PostedAt,
+	})
+}
@@ -0,0 +1,87 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostStockCountBatchesLedger(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	ledger := &recordingAdjustments{}
+	svc := NewService(repo, nil, nil, ServiceConfig{BatchStockCountJournals: true}, ledger)
+	_, err := svc.PostInbound(ctx, InboundInput{Code: "GRN-1", WarehouseID: 1, ProductID: 2, Qty: 10, UnitCost: 30})
+	require.NoError(t, err)
+
+	entries, err := svc.PostStockCount(ctx, StockCountInput{
+		Code:        "SC-1",
+		WarehouseID: 1,
+		Lines: []StockCountLine{
+			{ProductID: 2, Qty: -4},
+			{ProductID: 3, Qty: 0},
+			{ProductID: 5, Qty: 2, UnitCost: 12.5},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "SC-1-1", entries[0].TxCode)
+	require.Equal(t, "SC-1-2", entries[1].TxCode)
+	require.Equal(t, 6.0, repo.balances[key(1, 2)].Qty)
+	require.Equal(t, 2.0, repo.balances[key(1, 5)].Qty)
+
+	require.Empty(t, ledger.events)
+	require.Len(t, ledger.batches, 1)
+	batch := ledger.batches[0]
+	require.Equal(t, "SC-1", batch.Code)
+	require.Len(t, batch.Lines, 2)
+	require.Equal(t, 30.0, batch.Lines[0].UnitCost)
+	require.Equal(t, 12.5, batch.Lines[1].UnitCost)
+
+	tx := repo.txs["SC-1-1"]
+	require.Equal(t, StockCountRefModule, tx.RefModule)
+	require.Equal(t, stockCountSource("SC-1"), movementSource(StockMovement{
+		TxCode: "SC-1-1", TxType: TransactionTypeAdjust, RefModule: tx.RefModule, RefID: tx.RefID, ProductID: 2,
+	}))
+}
+
+func TestPostStockCountWithoutBatching(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	ledger := &recordingAdjustments{}
+	svc := NewService(repo, nil, nil, ServiceConfig{}, ledger)
+
+	_, err := svc.PostStockCount(ctx, StockCountInput{
+		Code:        "SC-2",
+		WarehouseID: 1,
+		Lines:       []StockCountLine{{ProductID: 2, Qty: 1, UnitCost: 10}, {ProductID: 3, Qty: 3, UnitCost: 4}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, ledger.batches)
+	require.Len(t, ledger.events, 2)
+	require.Equal(t, "SC-2-2", ledger.events[1].Code)
+	require.Empty(t, repo.txs["SC-2-1"].RefID)
+}
+
+func TestPostStockCountJournalsPostedLinesOnFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	ledger := &recordingAdjustments{}
+	svc := NewService(repo, nil, nil, ServiceConfig{BatchStockCountJournals: true}, ledger)
+
+	_, err := svc.PostStockCount(ctx, StockCountInput{WarehouseID: 1, Lines: []StockCountLine{{ProductID: 2}}})
+	require.ErrorIs(t, err, ErrStockCountEmpty)
+	_, err = svc.PostStockCount(ctx, StockCountInput{WarehouseID: 1, Lines: []StockCountLine{{ProductID: 2, Qty: 1}, {ProductID: 2, Qty: 2}}})
+	require.ErrorIs(t, err, ErrStockCountDuplicate)
+
+	entries, err := svc.PostStockCount(ctx, StockCountInput{
+		Code:        "SC-3",
+		WarehouseID: 1,
+		Lines:       []StockCountLine{{ProductID: 2, Qty: 1, UnitCost: 10}, {ProductID: 3, Qty: -1}},
+	})
+	require.ErrorIs(t, err, ErrNegativeStock)
+	require.Len(t, entries, 1)
+	require.Len(t, ledger.batches, 1)
+	require.Len(t, ledger.batches[0].Lines, 1)
+}
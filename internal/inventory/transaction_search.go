@@ -0,0 +1,115 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultTransactionSearchLimit caps a search without an explicit limit.
+	defaultTransactionSearchLimit = 200
+	// maxTransactionSearchLimit is the most lines a search returns.
+	maxTransactionSearchLimit = 1000
+)
+
+var (
+	// ErrInvalidRefID indicates a source reference that is not a UUID.
+	ErrInvalidRefID = errors.New("inventory: ref id must be a UUID")
+	// ErrInvalidDateRange indicates a search range that ends before it starts.
+	ErrInvalidDateRange = errors.New("inventory: date range ends before it starts")
+)
+
+// TransactionSearchFilter narrows the transaction search. Zero values match
+// everything; To is inclusive.
+type TransactionSearchFilter struct {
+	RefModule   string
+	RefID       string
+	ProductID   int64
+	WarehouseID int64
+	From        time.Time
+	To          time.Time
+	// Query matches the transaction code or note, case-insensitively.
+	Query string
+	Limit int
+}
+
+// TransactionMatch is one transaction line found by the search.
+type TransactionMatch struct {
+	TxID          int64
+	Code          string
+	Type          TransactionType
+	WarehouseID   int64
+	WarehouseName string
+	ProductID     int64
+	SKU           string
+	ProductName   string
+	Qty           float64
+	UnitCost      float64
+	RefModule     string
+	RefID         string
+	Note          string
+	PostedAt      time.Time
+	// SourceID and SourceNumber identify the originating document when it
+	// could be resolved (goods receipt, delivery order or sales return).
+	SourceID     int64
+	SourceNumber string
+}
+
+// SourceLink returns the page of the document that caused the movement, or
+// the stock card for movements raised inside inventory. It is empty when the
+// source is unknown.
+func (m TransactionMatch) SourceLink() string {
+	switch m.RefModule {
+	case "PROCUREMENT":
+		if m.SourceNumber != "" {
+			return "/procurement/grns?search=" + url.QueryEscape(m.SourceNumber)
+		}
+	case "DELIVERY":
+		if m.SourceID != 0 {
+			return fmt.Sprintf("/delivery/orders/%d", m.SourceID)
+		}
+	case "SALES_RETURN":
+		if m.SourceID != 0 {
+			return fmt.Sprintf("/sales/returns/%d", m.SourceID)
+		}
+	case "INVENTORY", StockCountRefModule:
+		if m.Type == TransactionTypeTransfer {
+			return fmt.Sprintf("/inventory/transfers/history?product_id=%d", m.ProductID)
+		}
+		if m.WarehouseID != 0 {
+			return fmt.Sprintf("/inventory/stock-card?warehouse_id=%d&product_id=%d", m.WarehouseID, m.ProductID)
+		}
+	}
+	return ""
+}
+
+// SearchTransactions finds transaction lines by source reference, product,
+// warehouse, posting date and code or note, newest first.
+func (s *Service) SearchTransactions(ctx context.Context, filter TransactionSearchFilter) ([]TransactionMatch, error) {
+	filter.RefModule = strings.ToUpper(strings.TrimSpace(filter.RefModule))
+	filter.RefID = strings.TrimSpace(filter.RefID)
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.RefID != "" {
+		id, err := uuid.Parse(filter.RefID)
+		if err != nil {
+			return nil, ErrInvalidRefID
+		}
+		filter.RefID = id.String()
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, ErrInvalidDateRange
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTransactionSearchLimit
+	}
+	if filter.Limit > maxTransactionSearchLimit {
+		filter.Limit = maxTransactionSearchLimit
+	}
+	return s.repo.SearchTransactions(ctx, filter)
+}
@@ -0,0 +1,59 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (m *memoryRepo) SearchTransactions(_ context.Context, filter TransactionSearchFilter) ([]TransactionMatch, error) {
+	m.searchFilter = filter
+	return nil, nil
+}
+
+func TestSearchTransactionsNormalizesFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+
+	_, err := svc.SearchTransactions(ctx, TransactionSearchFilter{RefID: "GRN-1"})
+	require.ErrorIs(t, err, ErrInvalidRefID)
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	_, err = svc.SearchTransactions(ctx, TransactionSearchFilter{From: from, To: from.AddDate(0, 0, -1)})
+	require.ErrorIs(t, err, ErrInvalidDateRange)
+
+	_, err = svc.SearchTransactions(ctx, TransactionSearchFilter{
+		RefModule: " procurement ",
+		RefID:     "6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+		Query:     "  GRN ",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "PROCUREMENT", repo.searchFilter.RefModule)
+	require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", repo.searchFilter.RefID)
+	require.Equal(t, "GRN", repo.searchFilter.Query)
+	require.Equal(t, defaultTransactionSearchLimit, repo.searchFilter.Limit)
+
+	_, err = svc.SearchTransactions(ctx, TransactionSearchFilter{Limit: 50000})
+	require.NoError(t, err)
+	require.Equal(t, maxTransactionSearchLimit, repo.searchFilter.Limit)
+}
+
+func TestTransactionMatchSourceLink(t *testing.T) {
+	cases := []struct {
+		match TransactionMatch
+		want  string
+	}{
+		{TransactionMatch{RefModule: "PROCUREMENT", SourceNumber: "GRN 2026/01"}, "/procurement/grns?search=GRN+2026%2F01"},
+		{TransactionMatch{RefModule: "DELIVERY", SourceID: 12}, "/delivery/orders/12"},
+		{TransactionMatch{RefModule: "DELIVERY"}, ""},
+		{TransactionMatch{RefModule: "SALES_RETURN", SourceID: 4}, "/sales/returns/4"},
+		{TransactionMatch{RefModule: "INVENTORY", Type: TransactionTypeTransfer, ProductID: 3}, "/inventory/transfers/history?product_id=3"},
+		{TransactionMatch{RefModule: StockCountRefModule, Type: TransactionTypeAdjust, WarehouseID: 1, ProductID: 3}, "/inventory/stock-card?warehouse_id=1&product_id=3"},
+		{TransactionMatch{RefModule: "UNKNOWN", WarehouseID: 1, ProductID: 3}, ""},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.want, tc.match.SourceLink(), "%+v", tc.match)
+	}
+}
@@ -0,0 +1,308 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// TransferApprovalModule identifies transfer requests in the approvals log.
+const TransferApprovalModule = "INVENTORY_TRANSFER"
+
+// TransferStatus tracks a transfer request through approval.
+type TransferStatus string
+
+const (
+	// TransferStatusPending waits for an approver; no stock has moved yet.
+	TransferStatusPending TransferStatus = "PENDING"
+	// TransferStatusApproved has been approved; PostedAt is set once the
+	// transfer is posted.
+	TransferStatusApproved TransferStatus = "APPROVED"
+	// TransferStatusRejected was declined; no stock moved.
+	TransferStatusRejected TransferStatus = "REJECTED"
+)
+
+var (
+	// ErrTransferRequestNotFound indicates the transfer request does not exist.
+	ErrTransferRequestNotFound = errors.New("inventory: transfer request not found")
+	// ErrTransferNotPending is returned when deciding an already decided request.
+	ErrTransferNotPending = errors.New("inventory: transfer request is not pending approval")
+	// ErrTransferSelfApproval prevents requesters from approving their own transfer.
+	ErrTransferSelfApproval = errors.New("inventory: requester cannot approve their own transfer")
+	// ErrTransferReasonRequired is returned when rejecting without a reason.
+	ErrTransferReasonRequired = errors.New("inventory: rejection reason required")
+)
+
+// ApprovalPort records approval history; satisfied by shared.ApprovalRecorder.
+type ApprovalPort interface {
+	Record(ctx context.Context, log shared.ApprovalLog) error
+}
+
+// TransferRequest is a transfer held for approval.
+type TransferRequest struct {
+	ID             int64
+	Code           string
+	ProductID      int64
+	Qty            float64
+	SrcWarehouse   int64
+	DstWarehouse   int64
+	UnitCost       float64
+	Value          float64
+	Note           string
+	RefModule      string
+	RefID          string
+	Status         TransferStatus
+	RequestedBy    int64
+	RequestedAt    time.Time
+	DecidedBy      *int64
+	DecidedAt      *time.Time
+	DecisionReason string
+	InTransit      bool
+	PostedAt       *time.Time
+}
+
+// AwaitingPost reports whether the request was approved but its transfer has
+// not been posted yet, so approving it again retries the posting.
+func (r TransferRequest) AwaitingPost() bool {
+	return r.Status == TransferStatusApproved && r.PostedAt == nil
+}
+
+// Input rebuilds the transfer to post once approved.
+func (r TransferRequest) Input() TransferInput {
+	return TransferInput{
+		Code:         r.Code,
+		ProductID:    r.ProductID,
+		Qty:          r.Qty,
+		SrcWarehouse: r.SrcWarehouse,
+		DstWarehouse: r.DstWarehouse,
+		UnitCost:     r.UnitCost,
+		Note:         r.Note,
+		RefModule:    r.RefModule,
+		RefID:        r.RefID,
+		InTransit:    r.InTransit,
+	}
+}
+
+// TransferResult reports either the posted stock card entries or the request
+// waiting for approval.
+type TransferResult struct {
+	Out     StockCardEntry
+	In      StockCardEntry
+	Pending *TransferRequest
+}
+
+// SetApprovals enables approval history for held transfers.
+func (s *Service) SetApprovals(approvals ApprovalPort) {
+	s.approvals = approvals
+}
+
+// SetApprovalRouter routes transfers through the approval matrix as well as
+// the threshold: a transfer whose value falls in a matrix band waits for an
+// approver holding one of the band's roles.
+func (s *Service) SetApprovalRouter(router shared.ApprovalRouter) {
+	s.router = router
+}
+
+// TransferApprovalThreshold returns the configured threshold, zero when disabled.
+func (s *Service) TransferApprovalThreshold() float64 {
+	return s.threshold
+}
+
+// RequestTransfer posts the transfer immediately when its value is below the
+// approval threshold. Otherwise the transfer is stored as a pending request and
+// nothing leaves the source warehouse until it is approved.
+func (s *Service) RequestTransfer(ctx context.Context, input TransferInput) (TransferResult, error) {
+	if err := validateTransfer(input); err != nil {
+		return TransferResult{}, err
+	}
+	value, err := s.transferValue(ctx, input)
+	if err != nil {
+		return TransferResult{}, err
+	}
+	needsApproval := input.RequireApproval || (s.threshold > 0 && value >= s.threshold)
+	if !needsApproval && s.router != nil {
+		roles, err := s.router.RequiredRoles(ctx, TransferApprovalModule, value, "")
+		if err != nil {
+			return TransferResult{}, err
+		}
+		needsApproval = len(roles) > 0
+	}
+	if !needsApproval {
+		out, in, err := s.PostTransfer(ctx, input)
+		if err != nil {
+			return TransferResult{}, err
+		}
+		return TransferResult{Out: out, In: in}, nil
+	}
+
+	req := TransferRequest{
+		Code:         baseCode(input.Code),
+		ProductID:    input.ProductID,
+		Qty:          input.Qty,
+		SrcWarehouse: input.SrcWarehouse,
+		DstWarehouse: input.DstWarehouse,
+		UnitCost:     input.UnitCost,
+		Value:        value,
+		Note:         input.Note,
+		RefModule:    input.RefModule,
+		RefID:        input.RefID,
+		Status:       TransferStatusPending,
+		RequestedBy:  input.ActorID,
+		RequestedAt:  time.Now().UTC(),
+		InTransit:    input.InTransit,
+	}
+	id, err := s.repo.CreateTransferRequest(ctx, req)
+	if err != nil {
+		return TransferResult{}, err
+	}
+	req.ID = id
+	if s.router != nil {
+		if _, err := s.router.Route(ctx, TransferApprovalModule, transferApprovalRef(id), value, ""); err != nil {
+			return TransferResult{}, err
+		}
+	}
+	s.recordApproval(ctx, req, input.ActorID, shared.ApprovalSubmit, fmt.Sprintf("Transfer %s submitted (value %.2f)", req.Code, value))
+	return TransferResult{Pending: &req}, nil
+}
+
+// ApproveTransfer approves a pending request and posts the transfer. When the
+// posting fails before any stock moved (e.g. insufficient stock) the request
+// goes back to pending. A request left approved but unposted, because the
+// posting failed part way or never ran, is posted again by approving it again.
+func (s *Service) ApproveTransfer(ctx context.Context, id, actorID int64, reason string) (TransferResult, error) {
+	req, err := s.repo.GetTransferRequest(ctx, id)
+	if err != nil {
+		return TransferResult{}, err
+	}
+	if req.Status != TransferStatusPending && !req.AwaitingPost() {
+		return TransferResult{}, ErrTransferNotPending
+	}
+	if actorID != 0 && actorID == req.RequestedBy {
+		return TransferResult{}, ErrTransferSelfApproval
+	}
+	if req.AwaitingPost() {
+		return s.postApprovedTransfer(ctx, req, actorID)
+	}
+	if s.router != nil {
+		if err := s.router.Authorize(ctx, TransferApprovalModule, transferApprovalRef(id), actorID); err != nil {
+			return TransferResult{}, err
+		}
+	}
+	reason = strings.TrimSpace(reason)
+	if err := s.repo.DecideTransferRequest(ctx, id, TransferStatusApproved, actorID, reason); err != nil {
+		return TransferResult{}, err
+	}
+	result, err := s.postApprovedTransfer(ctx, req, actorID)
+	if err != nil {
+		return TransferResult{}, err
+	}
+	note := fmt.Sprintf("Transfer %s approved", req.Code)
+	if reason != "" {
+		note += ": " + reason
+	}
+	s.recordApproval(ctx, req, actorID, shared.ApprovalApprove, note)
+	return result, nil
+}
+
+// postApprovedTransfer posts an approved request, dispatching it in transit
+// when it was requested that way, and marks the request posted. It picks up
+// after a dispatch that was already posted instead of posting it twice.
+func (s *Service) postApprovedTransfer(ctx context.Context, req TransferRequest, actorID int64) (TransferResult, error) {
+	input := req.Input()
+	input.ActorID = actorID
+	var result TransferResult
+	transfer, err := s.repo.GetTransfer(ctx, req.Code)
+	switch {
+	case errors.Is(err, ErrTransferNotFound):
+		result.Out, result.In, err = s.PostTransfer(ctx, input)
+		if err != nil {
+			if _, lookupErr := s.repo.GetTransfer(ctx, req.Code); !errors.Is(lookupErr, ErrTransferNotFound) {
+				return TransferResult{}, err
+			}
+			if reopenErr := s.repo.ReopenTransferRequest(ctx, req.ID); reopenErr != nil {
+				return TransferResult{}, errors.Join(err, reopenErr)
+			}
+			return TransferResult{}, err
+		}
+	case err != nil:
+		return TransferResult{}, err
+	case !req.InTransit && transfer.Status == TransferStateInTransit:
+		if result.In, err = s.ReceiveTransfer(ctx, req.Code, actorID); err != nil {
+			return TransferResult{}, err
+		}
+	}
+	if err := s.repo.MarkTransferRequestPosted(ctx, req.ID); err != nil {
+		return TransferResult{}, err
+	}
+	return result, nil
+}
+
+// RejectTransfer declines a pending request. A reason is mandatory.
+func (s *Service) RejectTransfer(ctx context.Context, id, actorID int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrTransferReasonRequired
+	}
+	req, err := s.repo.GetTransferRequest(ctx, id)
+	if err != nil {
+		return err
+	}
+	if req.Status != TransferStatusPending {
+		return ErrTransferNotPending
+	}
+	if err := s.repo.DecideTransferRequest(ctx, id, TransferStatusRejected, actorID, reason); err != nil {
+		return err
+	}
+	s.recordApproval(ctx, req, actorID, shared.ApprovalReject, fmt.Sprintf("Transfer %s rejected: %s", req.Code, reason))
+	return nil
+}
+
+// ListTransferRequests lists requests with the given status, all when empty.
+func (s *Service) ListTransferRequests(ctx context.Context, status TransferStatus) ([]TransferRequest, error) {
+	return s.repo.ListTransferRequests(ctx, status)
+}
+
+// transferValue values the transfer at the higher of the entered unit cost and
+// the source warehouse average cost, so an understated cost cannot dodge approval.
+func (s *Service) transferValue(ctx context.Context, input TransferInput) (float64, error) {
+	unitCost := input.UnitCost
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		balance, err := tx.GetBalanceForUpdate(ctx, input.SrcWarehouse, input.ProductID)
+		if err != nil {
+			if errors.Is(err, ErrBalanceNotFound) {
+				return nil
+			}
+			return err
+		}
+		unitCost = math.Max(unitCost, balance.AvgCost)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return math.Round(input.Qty*unitCost*100) / 100, nil
+}
+
+func (s *Service) recordApproval(ctx context.Context, req TransferRequest, actorID int64, action shared.ApprovalAction, note string) {
+	if s.approvals == nil || actorID == 0 {
+		return
+	}
+	_ = s.approvals.Record(ctx, shared.ApprovalLog{
+		Module:  TransferApprovalModule,
+		RefID:   transferApprovalRef(req.ID),
+		ActorID: actorID,
+		Action:  action,
+		Note:    note,
+	})
+}
+
+func transferApprovalRef(id int64) uuid.UUID {
+	return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("%s:%d", TransferApprovalModule, id)))
+}
@@ -0,0 +1,200 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func (r *memoryRepo) CreateTransferRequest(ctx context.Context, req TransferRequest) (int64, error) {
+	req.ID = int64(len(r.transfers) + 1)
+	r.transfers[req.ID] = req
+	return req.ID, nil
+}
+
+func (r *memoryRepo) GetTransferRequest(ctx context.Context, id int64) (TransferRequest, error) {
+	req, ok := r.transfers[id]
+	if !ok {
+		return TransferRequest{}, ErrTransferRequestNotFound
+	}
+	return req, nil
+}
+
+func (r *memoryRepo) ListTransferRequests(ctx context.Context, status TransferStatus) ([]TransferRequest, error) {
+	var out []TransferRequest
+	for _, req := range r.transfers {
+		if status == "" || req.Status == status {
+			out = append(out, req)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepo) DecideTransferRequest(ctx context.Context, id int64, status TransferStatus, actorID int64, reason string) error {
+	req, ok := r.transfers[id]
+	if !ok || req.Status != TransferStatusPending {
+		return ErrTransferNotPending
+	}
+	req.Status, req.DecidedBy, req.DecisionReason = status, &actorID, reason
+	r.transfers[id] = req
+	return nil
+}
+
+func (r *memoryRepo) ReopenTransferRequest(ctx context.Context, id int64) error {
+	req := r.transfers[id]
+	req.Status, req.DecidedBy, req.DecisionReason = TransferStatusPending, nil, ""
+	r.transfers[id] = req
+	return nil
+}
+
+func (r *memoryRepo) MarkTransferRequestPosted(ctx context.Context, id int64) error {
+	req := r.transfers[id]
+	if req.AwaitingPost() {
+		now := time.Now()
+		req.PostedAt = &now
+		r.transfers[id] = req
+	}
+	return nil
+}
+
+type recordingApprovals struct {
+	logs []shared.ApprovalLog
+}
+
+func (a *recordingApprovals) Record(ctx context.Context, log shared.ApprovalLog) error {
+	a.logs = append(a.logs, log)
+	return nil
+}
+
+func newApprovalService(t *testing.T, threshold float64) (*Service, *memoryRepo, *recordingApprovals) {
+	t.Helper()
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{TransferApprovalThreshold: threshold}, nil)
+	approvals := &recordingApprovals{}
+	svc.SetApprovals(approvals)
+	_, err := svc.PostInbound(context.Background(), InboundInput{WarehouseID: 1, ProductID: 1, Qty: 20, UnitCost: 1000})
+	require.NoError(t, err)
+	return svc, repo, approvals
+}
+
+func TestRequestTransferBelowThresholdPostsImmediately(t *testing.T) {
+	svc, repo, approvals := newApprovalService(t, 10000)
+
+	result, err := svc.RequestTransfer(context.Background(), TransferInput{SrcWarehouse: 1, DstWarehouse: 2, ProductID: 1, Qty: 5, UnitCost: 1000, ActorID: 7})
+	require.NoError(t, err)
+	require.Nil(t, result.Pending)
+	require.InDelta(t, 5, result.In.BalanceQty, 0.0001)
+	require.Empty(t, repo.transfers)
+	require.Empty(t, approvals.logs)
+}
+
+func TestRequestTransferAboveThresholdWaitsForApproval(t *testing.T) {
+	svc, repo, approvals := newApprovalService(t, 10000)
+	ctx := context.Background()
+
+	// An understated unit cost is valued at the source average cost.
+	result, err := svc.RequestTransfer(ctx, TransferInput{SrcWarehouse: 1, DstWarehouse: 2, ProductID: 1, Qty: 12, UnitCost: 1, ActorID: 7})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	require.InDelta(t, 12000, result.Pending.Value, 0.001)
+	require.Equal(t, TransferStatusPending, result.Pending.Status)
+	require.InDelta(t, 20, repo.balances[key(1, 1)].Qty, 0.0001, "no stock may leave before approval")
+
+	_, err = svc.ApproveTransfer(ctx, result.Pending.ID, 7, "")
+	require.ErrorIs(t, err, ErrTransferSelfApproval)
+
+	posted, err := svc.ApproveTransfer(ctx, result.Pending.ID, 9, "month-end rebalancing")
+	require.NoError(t, err)
+	require.InDelta(t, 8, posted.Out.BalanceQty, 0.0001)
+	require.InDelta(t, 12, repo.balances[key(2, 1)].Qty, 0.0001)
+
+	req, err := repo.GetTransferRequest(ctx, result.Pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusApproved, req.Status)
+	require.Equal(t, int64(9), *req.DecidedBy)
+	require.Equal(t, "month-end rebalancing", req.DecisionReason)
+	require.NotNil(t, req.PostedAt)
+
+	require.Len(t, approvals.logs, 2)
+	require.Equal(t, shared.ApprovalSubmit, approvals.logs[0].Action)
+	require.Equal(t, shared.ApprovalApprove, approvals.logs[1].Action)
+	require.Equal(t, int64(9), approvals.logs[1].ActorID)
+
+	_, err = svc.ApproveTransfer(ctx, result.Pending.ID, 9, "")
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestApproveTransferReopensWhenPostingFails(t *testing.T) {
+	svc, repo, _ := newApprovalService(t, 0)
+	ctx := context.Background()
+
+	result, err := svc.RequestTransfer(ctx, TransferInput{SrcWarehouse: 1, DstWarehouse: 2, ProductID: 1, Qty: 5, UnitCost: 1000, ActorID: 7, RequireApproval: true})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+
+	_, err = svc.PostAdjustment(ctx, AdjustmentInput{WarehouseID: 1, ProductID: 1, Qty: -18})
+	require.NoError(t, err)
+
+	_, err = svc.ApproveTransfer(ctx, result.Pending.ID, 9, "")
+	require.ErrorIs(t, err, ErrNegativeStock)
+	req, _ := repo.GetTransferRequest(ctx, result.Pending.ID)
+	require.Equal(t, TransferStatusPending, req.Status)
+}
+
+func TestApproveTransferDispatchesInTransit(t *testing.T) {
+	svc, repo, _ := newApprovalService(t, 0)
+	ctx := context.Background()
+
+	result, err := svc.RequestTransfer(ctx, TransferInput{Code: "TRF-1", SrcWarehouse: 1, DstWarehouse: 2, ProductID: 1, Qty: 5, UnitCost: 1000, ActorID: 7, RequireApproval: true, InTransit: true})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+
+	posted, err := svc.ApproveTransfer(ctx, result.Pending.ID, 9, "")
+	require.NoError(t, err)
+	require.InDelta(t, 15, posted.Out.BalanceQty, 0.0001)
+	require.Zero(t, repo.balances[key(2, 1)].Qty, "in-transit stock arrives only when received")
+	require.Equal(t, TransferStateInTransit, repo.posted["TRF-1"].Status)
+}
+
+func TestApproveTransferRetriesUnpostedApproval(t *testing.T) {
+	svc, repo, approvals := newApprovalService(t, 0)
+	ctx := context.Background()
+
+	result, err := svc.RequestTransfer(ctx, TransferInput{Code: "TRF-2", SrcWarehouse: 1, DstWarehouse: 2, ProductID: 1, Qty: 5, UnitCost: 1000, ActorID: 7, RequireApproval: true})
+	require.NoError(t, err)
+	// The decision was stored but the posting never ran.
+	require.NoError(t, repo.DecideTransferRequest(ctx, result.Pending.ID, TransferStatusApproved, 9, ""))
+
+	posted, err := svc.ApproveTransfer(ctx, result.Pending.ID, 9, "")
+	require.NoError(t, err)
+	require.InDelta(t, 5, posted.In.BalanceQty, 0.0001)
+	req, _ := repo.GetTransferRequest(ctx, result.Pending.ID)
+	require.Equal(t, TransferStatusApproved, req.Status)
+	require.NotNil(t, req.PostedAt)
+	require.Len(t, approvals.logs, 1, "a retried posting is not a second approval")
+
+	_, err = svc.ApproveTransfer(ctx, result.Pending.ID, 9, "")
+	require.ErrorIs(t, err, ErrTransferNotPending)
+	require.InDelta(t, 5, repo.balances[key(2, 1)].Qty, 0.0001)
+}
+
+func TestRejectTransferRequiresReason(t *testing.T) {
+	svc, repo, approvals := newApprovalService(t, 100)
+	ctx := context.Background()
+
+	result, err := svc.RequestTransfer(ctx, TransferInput{SrcWarehouse: 1, DstWarehouse: 2, ProductID: 1, Qty: 5, UnitCost: 1000, ActorID: 7})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, svc.RejectTransfer(ctx, result.Pending.ID, 9, " "), ErrTransferReasonRequired)
+	require.NoError(t, svc.RejectTransfer(ctx, result.Pending.ID, 9, "wrong destination"))
+
+	req, _ := repo.GetTransferRequest(ctx, result.Pending.ID)
+	require.Equal(t, TransferStatusRejected, req.Status)
+	require.Equal(t, "wrong destination", req.DecisionReason)
+	require.InDelta(t, 20, repo.balances[key(1, 1)].Qty, 0.0001)
+	require.Equal(t, shared.ApprovalReject, approvals.logs[len(approvals.logs)-1].Action)
+}
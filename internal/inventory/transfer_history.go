@@ -0,0 +1,164 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// TransferState tracks a posted transfer between dispatch and receipt.
+type TransferState string
+
+const (
+	// TransferStateInTransit has left the source warehouse but not arrived.
+	TransferStateInTransit TransferState = "IN_TRANSIT"
+	// TransferStateReceived has been booked into the destination warehouse.
+	TransferStateReceived TransferState = "RECEIVED"
+)
+
+var (
+	// ErrTransferNotFound indicates no posted transfer carries the code.
+	ErrTransferNotFound = errors.New("inventory: transfer not found")
+	// ErrTransferNotInTransit is returned when receiving an already received transfer.
+	ErrTransferNotInTransit = errors.New("inventory: transfer is not in transit")
+)
+
+// Transfer is a posted stock transfer with its dispatch and receipt.
+type Transfer struct {
+	ID               int64
+	Code             string
+	ProductID        int64
+	SKU              string
+	ProductName      string
+	Qty              float64
+	SrcWarehouse     int64
+	SrcWarehouseName string
+	DstWarehouse     int64
+	DstWarehouseName string
+	UnitCost         float64
+	Value            float64
+	Note             string
+	Status           TransferState
+	DispatchedBy     int64
+	DispatchedAt     time.Time
+	ReceivedBy       *int64
+	ReceivedAt       *time.Time
+}
+
+// DaysInTransit counts whole days from dispatch to receipt, or to now while
+// the transfer is still in transit.
+func (t Transfer) DaysInTransit(now time.Time) int {
+	end := now
+	if t.ReceivedAt != nil {
+		end = *t.ReceivedAt
+	}
+	if end.Before(t.DispatchedAt) {
+		return 0
+	}
+	return int(end.Sub(t.DispatchedAt).Hours() / 24)
+}
+
+// TransferFilter narrows ListTransfers. Zero fields match everything.
+type TransferFilter struct {
+	Status      TransferState
+	ProductID   int64
+	WarehouseID int64
+	Limit       int
+}
+
+// InTransitReport lists transfers dispatched but not yet received.
+type InTransitReport struct {
+	AsOf        time.Time
+	WarehouseID int64
+	Lines       []InTransitLine
+	TotalValue  float64
+}
+
+// InTransitLine is one open transfer with its age.
+type InTransitLine struct {
+	Transfer
+	Days int
+}
+
+// ReceiveTransfer books an in-transit transfer into its destination warehouse.
+func (s *Service) ReceiveTransfer(ctx context.Context, code string, actorID int64) (StockCardEntry, error) {
+	transfer, err := s.repo.GetTransfer(ctx, strings.TrimSpace(code))
+	if err != nil {
+		return StockCardEntry{}, err
+	}
+	if transfer.Status != TransferStateInTransit {
+		return StockCardEntry{}, ErrTransferNotInTransit
+	}
+	in, err := s.postMovement(ctx, transferInParams(TransferInput{
+		Code:         transfer.Code,
+		ProductID:    transfer.ProductID,
+		Qty:          transfer.Qty,
+		SrcWarehouse: transfer.SrcWarehouse,
+		DstWarehouse: transfer.DstWarehouse,
+		UnitCost:     transfer.UnitCost,
+		Note:         transfer.Note,
+		ActorID:      actorID,
+		RefModule:    "INVENTORY",
+	}))
+	if err != nil {
+		return StockCardEntry{}, err
+	}
+	if err := s.repo.MarkTransferReceived(ctx, transfer.ID, actorID, in.PostedAt); err != nil {
+		return StockCardEntry{}, err
+	}
+	return in, nil
+}
+
+// InTransit reports transfers still on the way, oldest first. warehouseID
+// limits the report to transfers leaving or entering that warehouse.
+func (s *Service) InTransit(ctx context.Context, warehouseID int64, now time.Time) (InTransitReport, error) {
+	transfers, err := s.repo.ListTransfers(ctx, TransferFilter{Status: TransferStateInTransit, WarehouseID: warehouseID})
+	if err != nil {
+		return InTransitReport{}, err
+	}
+	return buildInTransitReport(transfers, warehouseID, now), nil
+}
+
+// TransferHistory lists a product's transfers, newest first.
+func (s *Service) TransferHistory(ctx context.Context, productID int64) ([]Transfer, error) {
+	if productID == 0 {
+		return nil, errors.New("inventory: product required")
+	}
+	return s.repo.ListTransfers(ctx, TransferFilter{ProductID: productID, Limit: 500})
+}
+
+func buildInTransitReport(transfers []Transfer, warehouseID int64, now time.Time) InTransitReport {
+	report := InTransitReport{AsOf: now, WarehouseID: warehouseID, Lines: make([]InTransitLine, 0, len(transfers))}
+	for _, transfer := range transfers {
+		report.Lines = append(report.Lines, InTransitLine{Transfer: transfer, Days: transfer.DaysInTransit(now)})
+		report.TotalValue += transfer.Value
+	}
+	report.TotalValue = math.Round(report.TotalValue*100) / 100
+	return report
+}
+
+// recordDispatch stores the transfer once its outbound leg is posted.
+func (s *Service) recordDispatch(ctx context.Context, input TransferInput, out StockCardEntry) (Transfer, error) {
+	transfer := Transfer{
+		Code:         baseCode(input.Code),
+		ProductID:    input.ProductID,
+		Qty:          input.Qty,
+		SrcWarehouse: input.SrcWarehouse,
+		DstWarehouse: input.DstWarehouse,
+		UnitCost:     input.UnitCost,
+		Value:        math.Round(input.Qty*out.UnitCost*100) / 100,
+		Note:         input.Note,
+		Status:       TransferStateInTransit,
+		DispatchedBy: input.ActorID,
+		DispatchedAt: out.PostedAt,
+	}
+	id, err := s.repo.CreateTransfer(ctx, transfer)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("record transfer %s: %w", transfer.Code, err)
+	}
+	transfer.ID = id
+	return transfer, nil
+}
@@ -0,0 +1,122 @@
+package inventory
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (r *memoryRepo) CreateTransfer(ctx context.Context, transfer Transfer) (int64, error) {
+	r.nextID++
+	transfer.ID = r.nextID
+	r.posted[transfer.Code] = transfer
+	return transfer.ID, nil
+}
+
+func (r *memoryRepo) GetTransfer(ctx context.Context, code string) (Transfer, error) {
+	transfer, ok := r.posted[code]
+	if !ok {
+		return Transfer{}, ErrTransferNotFound
+	}
+	return transfer, nil
+}
+
+func (r *memoryRepo) MarkTransferReceived(ctx context.Context, id, actorID int64, at time.Time) error {
+	for code, transfer := range r.posted {
+		if transfer.ID == id {
+			if transfer.Status != TransferStateInTransit {
+				return ErrTransferNotInTransit
+			}
+			transfer.Status = TransferStateReceived
+			transfer.ReceivedBy = &actorID
+			transfer.ReceivedAt = &at
+			r.posted[code] = transfer
+			return nil
+		}
+	}
+	return ErrTransferNotFound
+}
+
+func (r *memoryRepo) ListTransfers(ctx context.Context, filter TransferFilter) ([]Transfer, error) {
+	var out []Transfer
+	for _, transfer := range r.posted {
+		if filter.Status != "" && transfer.Status != filter.Status {
+			continue
+		}
+		if filter.ProductID != 0 && transfer.ProductID != filter.ProductID {
+			continue
+		}
+		if filter.WarehouseID != 0 && transfer.SrcWarehouse != filter.WarehouseID && transfer.DstWarehouse != filter.WarehouseID {
+			continue
+		}
+		out = append(out, transfer)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func TestTwoPhaseTransferStaysInTransitUntilReceived(t *testing.T) {
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+	ctx := context.Background()
+
+	_, err := svc.PostInbound(ctx, InboundInput{WarehouseID: 1, ProductID: 7, Qty: 10, UnitCost: 2000})
+	require.NoError(t, err)
+
+	out, in, err := svc.PostTransfer(ctx, TransferInput{Code: "TRF-1", SrcWarehouse: 1, DstWarehouse: 2, ProductID: 7, Qty: 4, UnitCost: 1, InTransit: true})
+	require.NoError(t, err)
+	require.InDelta(t, 6, out.BalanceQty, 0.0001)
+	require.Empty(t, in.TxCode)
+	_, ok := repo.balances[key(2, 7)]
+	require.False(t, ok, "destination must not receive stock before the transfer arrives")
+
+	report, err := svc.InTransit(ctx, 2, out.PostedAt.Add(72*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 1)
+	require.Equal(t, 3, report.Lines[0].Days)
+	require.InDelta(t, 8000, report.TotalValue, 0.01)
+
+	_, err = svc.PostInbound(ctx, InboundInput{WarehouseID: 2, ProductID: 7, Qty: 4, UnitCost: 3000})
+	require.NoError(t, err)
+
+	received, err := svc.ReceiveTransfer(ctx, "TRF-1", 9)
+	require.NoError(t, err)
+	require.Equal(t, "TRF-1-IN", received.TxCode)
+	require.InDelta(t, 8, repo.balances[key(2, 7)].Qty, 0.0001)
+	require.InDelta(t, 2000, received.UnitCost, 0.0001)
+	require.InDelta(t, 2500, repo.balances[key(2, 7)].AvgCost, 0.0001)
+
+	_, err = svc.ReceiveTransfer(ctx, "TRF-1", 9)
+	require.ErrorIs(t, err, ErrTransferNotInTransit)
+
+	report, err = svc.InTransit(ctx, 0, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, report.Lines)
+
+	history, err := svc.TransferHistory(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, TransferStateReceived, history[0].Status)
+	require.NotNil(t, history[0].ReceivedAt)
+}
+
+func TestSinglePhaseTransferIsRecordedAsReceived(t *testing.T) {
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+	ctx := context.Background()
+
+	_, err := svc.PostInbound(ctx, InboundInput{WarehouseID: 1, ProductID: 7, Qty: 10, UnitCost: 2000})
+	require.NoError(t, err)
+	out, in, err := svc.PostTransfer(ctx, TransferInput{SrcWarehouse: 1, DstWarehouse: 2, ProductID: 7, Qty: 1, UnitCost: 2000})
+	require.NoError(t, err)
+	require.Equal(t, out.TxCode[:len(out.TxCode)-len("-OUT")], in.TxCode[:len(in.TxCode)-len("-IN")])
+
+	history, err := svc.TransferHistory(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, TransferStateReceived, history[0].Status)
+	require.Equal(t, 0, history[0].DaysInTransit(time.Now().Add(48*time.Hour)))
+}
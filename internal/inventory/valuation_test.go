@@ -0,0 +1,62 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (r *memoryRepo) InventoryValuation(ctx context.Context, filter ValuationFilter) ([]ValuationLine, error) {
+	r.valuationFilter = filter
+	return r.valuation, nil
+}
+
+func (r *memoryRepo) InventoryGLBalance(ctx context.Context, asOf time.Time, warehouseID, companyID int64) (float64, error) {
+	r.glCompanyID = companyID
+	return r.glBalance, nil
+}
+
+func TestValuationAsOfTiesOutToGL(t *testing.T) {
+	repo := newMemoryRepo()
+	repo.valuation = []ValuationLine{
+		{WarehouseID: 1, ProductID: 10, Qty: 5, AvgCost: 1000, Value: 5000},
+		{WarehouseID: 2, ProductID: 10, Qty: 2.5, AvgCost: 1000.004, Value: 2500.01},
+	}
+	repo.glBalance = 7500.01
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+
+	asOf := time.Date(2026, 6, 30, 15, 0, 0, 0, time.UTC)
+	report, err := svc.ValuationAsOf(context.Background(), ValuationFilter{AsOf: asOf})
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 6, 30, 23, 59, 59, 999999999, time.UTC), repo.valuationFilter.AsOf)
+	require.InDelta(t, 7.5, report.TotalQty, 1e-9)
+	require.InDelta(t, 7500.01, report.TotalValue, 1e-9)
+	require.True(t, report.Reconciled())
+}
+
+func TestValuationAsOfSkipsGLForCategoryFilter(t *testing.T) {
+	repo := newMemoryRepo()
+	repo.valuation = []ValuationLine{{WarehouseID: 1, ProductID: 10, Qty: 1, Value: 100}}
+	repo.glBalance = 999
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+
+	report, err := svc.ValuationAsOf(context.Background(), ValuationFilter{AsOf: time.Now(), CategoryID: 3})
+	require.NoError(t, err)
+	require.False(t, report.GLComparable)
+	require.False(t, report.Reconciled())
+
+	_, err = svc.ValuationAsOf(context.Background(), ValuationFilter{})
+	require.Error(t, err)
+}
+
+func TestValuationAsOfTiesOutOneCompany(t *testing.T) {
+	repo := newMemoryRepo()
+	svc := NewService(repo, nil, nil, ServiceConfig{}, nil)
+
+	_, err := svc.ValuationAsOf(context.Background(), ValuationFilter{AsOf: time.Now(), CompanyID: 2})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), repo.valuationFilter.CompanyID)
+	require.Equal(t, int64(2), repo.glCompanyID, "the GL side must be limited to the same company")
+}
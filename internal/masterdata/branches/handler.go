@@ -1,14 +1,17 @@
 package branches
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/companies"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -51,12 +54,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		if parsed, err := strconv.ParseInt(companyIDStr, 10, 64); err == nil {
 			filters.CompanyID = &parsed
 		}
+	} else if scoped := internalShared.ScopedCompanyID(r.Context(), 0, 0); scoped > 0 {
+		filters.CompanyID = &scoped
 	}
 
 	branches, total, err := h.service.List(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list branches failed", "error", err)
-		http.Error(w, "Failed to load branches", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list branches failed", "error", err)
+		httpx.Error(w, r, "Failed to load branches", http.StatusInternalServerError)
 		return
 	}
 
@@ -70,14 +75,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid branch ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid branch ID", http.StatusBadRequest)
 		return
 	}
 
 	branch, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get branch failed", "error", err, "id", id)
-		http.Error(w, "Branch not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get branch failed", "error", err, "id", id)
+		httpx.Error(w, r, "Branch not found", http.StatusNotFound)
 		return
 	}
 
@@ -89,7 +94,7 @@ func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 	companiesList, _, err := h.companyService.List(r.Context(), shared.ListFilters{})
 	if err != nil {
-		h.logger.Error("list companies failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "list companies failed", "error", err)
 		companiesList = []companies.Company{}
 	}
 
@@ -102,7 +107,7 @@ func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -116,7 +121,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	created, err := h.service.Create(r.Context(), branch)
 	if err != nil {
-		h.logger.Error("create branch failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create branch failed", "error", err)
 		companiesList, _, _ := h.companyService.List(r.Context(), shared.ListFilters{})
 		h.render(w, r, "pages/masterdata/branch_form.html", map[string]any{
 			"Errors":    map[string]string{"general": internalShared.UserSafeMessage(err)},
@@ -132,20 +137,20 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid branch ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid branch ID", http.StatusBadRequest)
 		return
 	}
 
 	branch, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get branch failed", "error", err, "id", id)
-		http.Error(w, "Branch not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get branch failed", "error", err, "id", id)
+		httpx.Error(w, r, "Branch not found", http.StatusNotFound)
 		return
 	}
 
 	companiesList, _, err := h.companyService.List(r.Context(), shared.ListFilters{})
 	if err != nil {
-		h.logger.Error("list companies failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "list companies failed", "error", err)
 		companiesList = []companies.Company{}
 	}
 
@@ -159,12 +164,12 @@ func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid branch ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid branch ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -178,7 +183,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	err = h.service.Update(r.Context(), id, branch)
 	if err != nil {
-		h.logger.Error("update branch failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update branch failed", "error", err, "id", id)
 		companiesList, _, _ := h.companyService.List(r.Context(), shared.ListFilters{})
 		h.render(w, r, "pages/masterdata/branch_form.html", map[string]any{
 			"Errors":    map[string]string{"general": internalShared.UserSafeMessage(err)},
@@ -194,13 +199,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid branch ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid branch ID", http.StatusBadRequest)
 		return
 	}
 
 	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error("delete branch failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "delete branch failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/masterdata/branches", "error", internalShared.UserSafeMessage(err))
 		return
 	}
@@ -208,6 +213,74 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.redirectWithFlash(w, r, "/masterdata/branches", "success", "Branch deleted successfully")
 }
 
+func (h *Handler) UpdateGLDimensions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid branch ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	location := "/masterdata/branches/" + strconv.FormatInt(id, 10)
+	var companyID *int64
+	if raw := strings.TrimSpace(r.PostFormValue("gl_dim_company_id")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			h.redirectWithFlash(w, r, location, "error", "Invalid GL company dimension")
+			return
+		}
+		companyID = &parsed
+	}
+
+	if err := h.service.SetGLDimensions(r.Context(), id, companyID); err != nil {
+		h.logger.ErrorContext(r.Context(), "update branch gl dimensions failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, location, "error", internalShared.UserSafeMessage(err))
+		return
+	}
+
+	h.redirectWithFlash(w, r, location, "success", "GL dimensions updated successfully")
+}
+
+func (h *Handler) UpdateDefaultWarehouse(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid branch ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	location := "/masterdata/branches/" + strconv.FormatInt(id, 10)
+	var warehouseID *int64
+	if raw := strings.TrimSpace(r.PostFormValue("default_warehouse_id")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			h.redirectWithFlash(w, r, location, "error", "Invalid default warehouse")
+			return
+		}
+		warehouseID = &parsed
+	}
+
+	if err := h.service.SetDefaultWarehouse(r.Context(), id, warehouseID); err != nil {
+		h.logger.ErrorContext(r.Context(), "update branch default warehouse failed", "error", err, "id", id)
+		msg := internalShared.UserSafeMessage(err)
+		if errors.Is(err, ErrWarehouseNotFound) || errors.Is(err, ErrWarehouseCompanyMismatch) {
+			msg = err.Error()
+		}
+		h.redirectWithFlash(w, r, location, "error", msg)
+		return
+	}
+
+	h.redirectWithFlash(w, r, location, "success", "Default warehouse updated successfully")
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
 	sess := internalShared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -224,7 +297,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", "error", err, "template", template)
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
 	}
 }
 
@@ -13,4 +13,8 @@ type Branch struct {
 	Address   string    `json:"address"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// GL company dimension override; nil uses CompanyID
+	GLDimCompanyID *int64 `json:"gl_dim_company_id,omitempty"`
+	// Warehouse prefilled on new delivery orders; nil means none
+	DefaultWarehouseID *int64 `json:"default_warehouse_id,omitempty"`
 }
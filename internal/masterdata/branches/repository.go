@@ -17,6 +17,9 @@ type Repository interface {
 	Create(ctx context.Context, branch Branch) (Branch, error)
 	Update(ctx context.Context, id int64, branch Branch) error
 	Delete(ctx context.Context, id int64) error
+	SetGLDimensions(ctx context.Context, id int64, companyID *int64) error
+	SetDefaultWarehouse(ctx context.Context, id int64, warehouseID *int64) error
+	WarehouseCompanyID(ctx context.Context, warehouseID int64) (int64, error)
 }
 
 type repository struct {
@@ -113,13 +116,22 @@ func (r *repository) Get(ctx context.Context, id int64) (Branch, error) {
 	if err != nil {
 		return Branch{}, err
 	}
-	return Branch{
+	branch := Branch{
 		ID:        row.ID,
 		CompanyID: row.CompanyID,
 		Code:      row.Code,
 		Name:      row.Name,
 		Address:   row.Address,
-	}, nil
+	}
+	if row.GlDimCompanyID.Valid {
+		companyID := row.GlDimCompanyID.Int64
+		branch.GLDimCompanyID = &companyID
+	}
+	if row.DefaultWarehouseID.Valid {
+		warehouseID := row.DefaultWarehouseID.Int64
+		branch.DefaultWarehouseID = &warehouseID
+	}
+	return branch, nil
 }
 
 // Create uses sqlc generated query
@@ -162,6 +174,35 @@ func (r *repository) Delete(ctx context.Context, id int64) error {
 	return r.queries.DeleteBranch(ctx, id)
 }
 
+// SetGLDimensions uses sqlc generated query
+func (r *repository) SetGLDimensions(ctx context.Context, id int64, companyID *int64) error {
+	params := sqlc.SetBranchGLDimensionsParams{
+		UpdatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ID:        id,
+	}
+	if companyID != nil {
+		params.GlDimCompanyID = pgtype.Int8{Int64: *companyID, Valid: true}
+	}
+	return r.queries.SetBranchGLDimensions(ctx, params)
+}
+
+// SetDefaultWarehouse uses sqlc generated query
+func (r *repository) SetDefaultWarehouse(ctx context.Context, id int64, warehouseID *int64) error {
+	params := sqlc.SetBranchDefaultWarehouseParams{
+		UpdatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ID:        id,
+	}
+	if warehouseID != nil {
+		params.DefaultWarehouseID = pgtype.Int8{Int64: *warehouseID, Valid: true}
+	}
+	return r.queries.SetBranchDefaultWarehouse(ctx, params)
+}
+
+// WarehouseCompanyID uses sqlc generated query
+func (r *repository) WarehouseCompanyID(ctx context.Context, warehouseID int64) (int64, error) {
+	return r.queries.GetWarehouseCompanyID(ctx, warehouseID)
+}
+
 func sortOrder(sortBy, sortDir string) string {
 	dir := "ASC"
 	if sortDir == "desc" {
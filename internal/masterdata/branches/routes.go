@@ -15,5 +15,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Get("/{id}/edit", h.EditForm)
 		r.Post("/{id}/edit", h.Update)
 		r.Post("/{id}/delete", h.Delete)
+		r.Post("/{id}/gl-dimensions", h.UpdateGLDimensions)
+		r.Post("/{id}/default-warehouse", h.UpdateDefaultWarehouse)
 	})
 }
@@ -4,9 +4,19 @@ import (
 	"context"
 	"errors"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
 )
 
+var (
+	// ErrWarehouseNotFound is returned when a default warehouse does not exist.
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	// ErrWarehouseCompanyMismatch is returned when a default warehouse belongs
+	// to another company than the branch.
+	ErrWarehouseCompanyMismatch = errors.New("warehouse belongs to a different company")
+)
+
 type Service struct {
 	repo Repository
 }
@@ -49,3 +59,45 @@ func (s *Service) Delete(ctx context.Context, id int64) error {
 	}
 	return s.repo.Delete(ctx, id)
 }
+
+// SetGLDimensions stores the company dimension override used for journals
+// posted from the branch's warehouses. Nil clears the override.
+func (s *Service) SetGLDimensions(ctx context.Context, id int64, companyID *int64) error {
+	if id <= 0 {
+		return errors.New("invalid branch ID")
+	}
+	if companyID != nil && *companyID <= 0 {
+		return errors.New("invalid GL dimension")
+	}
+	return s.repo.SetGLDimensions(ctx, id, companyID)
+}
+
+// SetDefaultWarehouse stores the warehouse prefilled on new delivery orders
+// for the branch. The warehouse must belong to the branch's company. Nil
+// clears the default.
+func (s *Service) SetDefaultWarehouse(ctx context.Context, id int64, warehouseID *int64) error {
+	if id <= 0 {
+		return errors.New("invalid branch ID")
+	}
+	if warehouseID == nil {
+		return s.repo.SetDefaultWarehouse(ctx, id, nil)
+	}
+	if *warehouseID <= 0 {
+		return errors.New("invalid warehouse ID")
+	}
+	branch, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	companyID, err := s.repo.WarehouseCompanyID(ctx, *warehouseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrWarehouseNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if companyID != branch.CompanyID {
+		return ErrWarehouseCompanyMismatch
+	}
+	return s.repo.SetDefaultWarehouse(ctx, id, warehouseID)
+}
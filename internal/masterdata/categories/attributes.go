@@ -0,0 +1,154 @@
+package categories
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AttributeType is the value type of a custom product attribute.
+type AttributeType string
+
+const (
+	AttributeText    AttributeType = "text"
+	AttributeNumber  AttributeType = "number"
+	AttributeBoolean AttributeType = "boolean"
+	AttributeSelect  AttributeType = "select"
+)
+
+// AttributeDefinition describes one custom attribute carried by the products
+// of a category, e.g. voltage for electronics or width for furniture.
+type AttributeDefinition struct {
+	Key      string        `json:"key"`
+	Label    string        `json:"label"`
+	Type     AttributeType `json:"type"`
+	Required bool          `json:"required,omitempty"`
+	Options  []string      `json:"options,omitempty"`
+	Unit     string        `json:"unit,omitempty"`
+}
+
+// AttributeError reports an invalid attribute schema or attribute value. The
+// message is safe to show to users.
+type AttributeError struct {
+	Field   string
+	Message string
+}
+
+func (e *AttributeError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// ParseAttributeSchema decodes the JSON schema entered on the category form.
+// Blank input means the category has no custom attributes.
+func ParseAttributeSchema(raw string) ([]AttributeDefinition, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var schema []AttributeDefinition
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return nil, &AttributeError{Message: "attribute schema must be a JSON list of attribute definitions"}
+	}
+	return schema, nil
+}
+
+// ValidateAttributeSchema checks keys are unique identifiers, types are known
+// and select attributes list their options.
+func ValidateAttributeSchema(schema []AttributeDefinition) error {
+	seen := make(map[string]struct{}, len(schema))
+	for _, def := range schema {
+		if !attributeKeyPattern.MatchString(def.Key) {
+			return &AttributeError{Field: def.Key, Message: "key must start with a letter and use lowercase letters, digits or underscores"}
+		}
+		if _, ok := seen[def.Key]; ok {
+			return &AttributeError{Field: def.Key, Message: "duplicate attribute key"}
+		}
+		seen[def.Key] = struct{}{}
+		if strings.TrimSpace(def.Label) == "" {
+			return &AttributeError{Field: def.Key, Message: "label is required"}
+		}
+		switch def.Type {
+		case AttributeText, AttributeNumber, AttributeBoolean:
+		case AttributeSelect:
+			if len(def.Options) == 0 {
+				return &AttributeError{Field: def.Key, Message: "select attributes need at least one option"}
+			}
+		default:
+			return &AttributeError{Field: def.Key, Message: fmt.Sprintf("unknown attribute type %q", def.Type)}
+		}
+	}
+	return nil
+}
+
+// ValidateAttributes checks product attribute values against the category
+// schema and returns them typed for storage. Form values arrive as strings and
+// are converted; keys outside the schema are dropped.
+func ValidateAttributes(schema []AttributeDefinition, values map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(schema))
+	for _, def := range schema {
+		raw, ok := values[def.Key]
+		if s, isString := raw.(string); isString {
+			raw = strings.TrimSpace(s)
+			ok = ok && raw != ""
+		}
+		if !ok || raw == nil {
+			if def.Required && def.Type != AttributeBoolean {
+				return nil, &AttributeError{Field: def.Label, Message: "is required"}
+			}
+			if def.Type == AttributeBoolean {
+				out[def.Key] = false
+			}
+			continue
+		}
+		value, err := coerceAttribute(def, raw)
+		if err != nil {
+			return nil, err
+		}
+		out[def.Key] = value
+	}
+	return out, nil
+}
+
+func coerceAttribute(def AttributeDefinition, raw any) (any, error) {
+	switch def.Type {
+	case AttributeNumber:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err == nil {
+				return f, nil
+			}
+		}
+		return nil, &AttributeError{Field: def.Label, Message: "must be a number"}
+	case AttributeBoolean:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return v == "on" || v == "true" || v == "1", nil
+		}
+		return nil, &AttributeError{Field: def.Label, Message: "must be yes or no"}
+	case AttributeSelect:
+		v, _ := raw.(string)
+		for _, option := range def.Options {
+			if v == option {
+				return v, nil
+			}
+		}
+		return nil, &AttributeError{Field: def.Label, Message: "must be one of " + strings.Join(def.Options, ", ")}
+	default:
+		v, ok := raw.(string)
+		if !ok {
+			return nil, &AttributeError{Field: def.Label, Message: "must be text"}
+		}
+		return v, nil
+	}
+}
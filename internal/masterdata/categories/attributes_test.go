@@ -0,0 +1,200 @@
+package categories
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseAttributeSchema(t *testing.T) {
+	schema, err := ParseAttributeSchema("  ")
+	if err != nil || schema != nil {
+		t.Fatalf("blank schema: expected nil, got %v %v", schema, err)
+	}
+	schema, err = ParseAttributeSchema(`[{"key":"voltage","label":"Voltage","type":"number","unit":"V"}]`)
+	if err != nil {
+		t.Fatalf("ParseAttributeSchema: %v", err)
+	}
+	want := []AttributeDefinition{{Key: "voltage", Label: "Voltage", Type: AttributeNumber, Unit: "V"}}
+	if !reflect.DeepEqual(schema, want) {
+		t.Fatalf("expected %+v, got %+v", want, schema)
+	}
+	var attrErr *AttributeError
+	if _, err := ParseAttributeSchema(`{"key":"voltage"}`); !errors.As(err, &attrErr) {
+		t.Fatalf("expected AttributeError for a JSON object, got %v", err)
+	}
+}
+
+func TestValidateAttributeSchema(t *testing.T) {
+	cases := []struct {
+		name   string
+		schema []AttributeDefinition
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"every type", []AttributeDefinition{
+			{Key: "colour", Label: "Colour", Type: AttributeText},
+			{Key: "width_cm", Label: "Width", Type: AttributeNumber, Unit: "cm"},
+			{Key: "fragile", Label: "Fragile", Type: AttributeBoolean},
+			{Key: "size", Label: "Size", Type: AttributeSelect, Options: []string{"S", "M"}},
+		}, ""},
+		{"blank key", []AttributeDefinition{{Label: "Colour", Type: AttributeText}},
+			"key must start with a letter and use lowercase letters, digits or underscores"},
+		{"uppercase key", []AttributeDefinition{{Key: "Colour", Label: "Colour", Type: AttributeText}},
+			"Colour: key must start with a letter and use lowercase letters, digits or underscores"},
+		{"key starting with digit", []AttributeDefinition{{Key: "1st", Label: "First", Type: AttributeText}},
+			"1st: key must start with a letter and use lowercase letters, digits or underscores"},
+		{"duplicate key", []AttributeDefinition{
+			{Key: "colour", Label: "Colour", Type: AttributeText},
+			{Key: "colour", Label: "Color", Type: AttributeText},
+		}, "colour: duplicate attribute key"},
+		{"blank label", []AttributeDefinition{{Key: "colour", Label: " ", Type: AttributeText}},
+			"colour: label is required"},
+		{"select without options", []AttributeDefinition{{Key: "size", Label: "Size", Type: AttributeSelect}},
+			"size: select attributes need at least one option"},
+		{"unknown type", []AttributeDefinition{{Key: "made_on", Label: "Made on", Type: "date"}},
+			`made_on: unknown attribute type "date"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAttributeSchema(tc.schema)
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("expected a valid schema, got %v", err)
+				}
+				return
+			}
+			var attrErr *AttributeError
+			if !errors.As(err, &attrErr) {
+				t.Fatalf("expected AttributeError, got %v", err)
+			}
+			if err.Error() != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestCoerceAttribute(t *testing.T) {
+	text := AttributeDefinition{Key: "colour", Label: "Colour", Type: AttributeText}
+	number := AttributeDefinition{Key: "width", Label: "Width", Type: AttributeNumber}
+	boolean := AttributeDefinition{Key: "fragile", Label: "Fragile", Type: AttributeBoolean}
+	choice := AttributeDefinition{Key: "size", Label: "Size", Type: AttributeSelect, Options: []string{"S", "M", "L"}}
+
+	cases := []struct {
+		name string
+		def  AttributeDefinition
+		raw  any
+		want any
+		err  string
+	}{
+		{"text", text, "red", "red", ""},
+		{"text from number", text, 3.0, nil, "Colour: must be text"},
+		{"number from string", number, "12.5", 12.5, ""},
+		{"number from JSON", number, 40.0, 40.0, ""},
+		{"number from word", number, "wide", nil, "Width: must be a number"},
+		{"number from bool", number, true, nil, "Width: must be a number"},
+		{"boolean from checkbox", boolean, "on", true, ""},
+		{"boolean from true", boolean, "true", true, ""},
+		{"boolean from 1", boolean, "1", true, ""},
+		{"boolean from other string", boolean, "no", false, ""},
+		{"boolean from JSON", boolean, true, true, ""},
+		{"boolean from number", boolean, 1.0, nil, "Fragile: must be yes or no"},
+		{"select option", choice, "M", "M", ""},
+		{"select other value", choice, "XL", nil, "Size: must be one of S, M, L"},
+		{"select is case sensitive", choice, "m", nil, "Size: must be one of S, M, L"},
+		{"select from number", choice, 1.0, nil, "Size: must be one of S, M, L"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := coerceAttribute(tc.def, tc.raw)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v (value %v)", tc.err, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("coerceAttribute: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %#v, got %#v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateAttributes(t *testing.T) {
+	schema := []AttributeDefinition{
+		{Key: "voltage", Label: "Voltage", Type: AttributeNumber, Required: true},
+		{Key: "colour", Label: "Colour", Type: AttributeText},
+		{Key: "fragile", Label: "Fragile", Type: AttributeBoolean, Required: true},
+		{Key: "size", Label: "Size", Type: AttributeSelect, Options: []string{"S", "M"}},
+	}
+
+	cases := []struct {
+		name   string
+		values map[string]any
+		want   map[string]any
+		err    string
+	}{
+		{
+			name:   "form values are typed",
+			values: map[string]any{"voltage": " 220 ", "colour": "red", "fragile": "on", "size": "M"},
+			want:   map[string]any{"voltage": 220.0, "colour": "red", "fragile": true, "size": "M"},
+		},
+		{
+			name:   "optional values may be missing or blank",
+			values: map[string]any{"voltage": 110.0, "colour": "  "},
+			want:   map[string]any{"voltage": 110.0, "fragile": false},
+		},
+		{
+			name:   "unknown keys are dropped",
+			values: map[string]any{"voltage": "5", "weight": "3", "notes": "spare"},
+			want:   map[string]any{"voltage": 5.0, "fragile": false},
+		},
+		{
+			name:   "missing required value",
+			values: map[string]any{"colour": "red"},
+			err:    "Voltage: is required",
+		},
+		{
+			name:   "blank required value",
+			values: map[string]any{"voltage": "   "},
+			err:    "Voltage: is required",
+		},
+		{
+			name:   "nil required value",
+			values: map[string]any{"voltage": nil},
+			err:    "Voltage: is required",
+		},
+		{
+			name:   "invalid value",
+			values: map[string]any{"voltage": "5", "size": "XL"},
+			err:    "Size: must be one of S, M",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ValidateAttributes(schema, tc.values)
+			if tc.err != "" {
+				var attrErr *AttributeError
+				if !errors.As(err, &attrErr) || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateAttributes: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %#v, got %#v", tc.want, got)
+			}
+		})
+	}
+
+	got, err := ValidateAttributes(nil, map[string]any{"voltage": "5"})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("a category without schema keeps no attributes, got %v %v", got, err)
+	}
+}
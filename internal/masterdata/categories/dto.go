@@ -3,4 +3,6 @@ package categories
 type CategoryForm struct {
 	Code string `json:"code"`
 	Name string `json:"name"`
+	// AttributeSchema is the JSON list of attribute definitions.
+	AttributeSchema string `json:"attribute_schema"`
 }
@@ -1,6 +1,7 @@
 package categories
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -46,8 +48,8 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	categories, total, err := h.service.List(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list categories failed", "error", err)
-		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list categories failed", "error", err)
+		httpx.Error(w, r, "Failed to load categories", http.StatusInternalServerError)
 		return
 	}
 
@@ -61,14 +63,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid category ID", http.StatusBadRequest)
 		return
 	}
 
 	category, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get category failed", "error", err, "id", id)
-		http.Error(w, "Category not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get category failed", "error", err, "id", id)
+		httpx.Error(w, r, "Category not found", http.StatusNotFound)
 		return
 	}
 
@@ -86,71 +88,71 @@ func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
-	category := Category{
-		Code: r.PostFormValue("code"),
-		Name: r.PostFormValue("name"),
+	category, err := categoryFromForm(r)
+	if err == nil {
+		category, err = h.service.Create(r.Context(), category)
 	}
-
-	created, err := h.service.Create(r.Context(), category)
 	if err != nil {
-		h.logger.Error("create category failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create category failed", "error", err)
 		h.render(w, r, "pages/masterdata/category_form.html", map[string]any{
-			"Errors":   map[string]string{"general": internalShared.UserSafeMessage(err)},
-			"Category": nil,
+			"Errors":     map[string]string{"general": formErrorMessage(err)},
+			"Category":   nil,
+			"SchemaText": r.PostFormValue("attribute_schema"),
 		}, http.StatusBadRequest)
 		return
 	}
 
-	h.redirectWithFlash(w, r, "/masterdata/categories/"+strconv.FormatInt(created.ID, 10), "success", "Category created successfully")
+	h.redirectWithFlash(w, r, "/masterdata/categories/"+strconv.FormatInt(category.ID, 10), "success", "Category created successfully")
 }
 
 func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid category ID", http.StatusBadRequest)
 		return
 	}
 
 	category, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get category failed", "error", err, "id", id)
-		http.Error(w, "Category not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get category failed", "error", err, "id", id)
+		httpx.Error(w, r, "Category not found", http.StatusNotFound)
 		return
 	}
 
 	h.render(w, r, "pages/masterdata/category_form.html", map[string]any{
-		"Errors":   map[string]string{},
-		"Category": category,
+		"Errors":     map[string]string{},
+		"Category":   category,
+		"SchemaText": category.AttributeSchemaJSON(),
 	}, http.StatusOK)
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid category ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
-	category := Category{
-		Code: r.PostFormValue("code"),
-		Name: r.PostFormValue("name"),
+	category, err := categoryFromForm(r)
+	if err == nil {
+		err = h.service.Update(r.Context(), id, category)
 	}
-
-	err = h.service.Update(r.Context(), id, category)
 	if err != nil {
-		h.logger.Error("update category failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update category failed", "error", err, "id", id)
+		category.ID = id
 		h.render(w, r, "pages/masterdata/category_form.html", map[string]any{
-			"Errors":   map[string]string{"general": internalShared.UserSafeMessage(err)},
-			"Category": category,
+			"Errors":     map[string]string{"general": formErrorMessage(err)},
+			"Category":   category,
+			"SchemaText": r.PostFormValue("attribute_schema"),
 		}, http.StatusBadRequest)
 		return
 	}
@@ -161,13 +163,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid category ID", http.StatusBadRequest)
 		return
 	}
 
 	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error("delete category failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "delete category failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/masterdata/categories", "error", internalShared.UserSafeMessage(err))
 		return
 	}
@@ -175,6 +177,29 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.redirectWithFlash(w, r, "/masterdata/categories", "success", "Category deleted successfully")
 }
 
+func categoryFromForm(r *http.Request) (Category, error) {
+	category := Category{
+		Code: r.PostFormValue("code"),
+		Name: r.PostFormValue("name"),
+	}
+	schema, err := ParseAttributeSchema(r.PostFormValue("attribute_schema"))
+	if err != nil {
+		return category, err
+	}
+	category.AttributeSchema = schema
+	return category, nil
+}
+
+// formErrorMessage shows attribute schema errors verbatim and hides
+// everything else behind the generic user-safe message.
+func formErrorMessage(err error) string {
+	var attrErr *AttributeError
+	if errors.As(err, &attrErr) {
+		return attrErr.Error()
+	}
+	return internalShared.UserSafeMessage(err)
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
 	sess := internalShared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -191,7 +216,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", "error", err, "template", template)
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
 	}
 }
 
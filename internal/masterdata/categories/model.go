@@ -1,8 +1,23 @@
 package categories
 
+import "encoding/json"
+
 // Category represents a product category
 type Category struct {
-	ID   int64  `json:"id"`
-	Code string `json:"code"`
-	Name string `json:"name"`
+	ID              int64                 `json:"id"`
+	Code            string                `json:"code"`
+	Name            string                `json:"name"`
+	AttributeSchema []AttributeDefinition `json:"attribute_schema"`
+}
+
+// AttributeSchemaJSON renders the schema for editing on the category form.
+func (c Category) AttributeSchemaJSON() string {
+	if len(c.AttributeSchema) == 0 {
+		return ""
+	}
+	raw, err := json.MarshalIndent(c.AttributeSchema, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(raw)
 }
@@ -2,6 +2,7 @@ package categories
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"time"
 
@@ -33,7 +34,7 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 
 // List uses dynamic query (not sqlc) due to filter complexity
 func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Category, int, error) {
-	query := `SELECT id, code, name FROM categories WHERE 1=1`
+	query := `SELECT id, code, name, attribute_schema FROM categories WHERE 1=1`
 	args := []interface{}{}
 	argCount := 0
 
@@ -82,10 +83,14 @@ func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Ca
 	var categories []Category
 	for rows.Next() {
 		var c Category
-		err := rows.Scan(&c.ID, &c.Code, &c.Name)
+		var schema []byte
+		err := rows.Scan(&c.ID, &c.Code, &c.Name, &schema)
 		if err != nil {
 			return nil, 0, err
 		}
+		if c.AttributeSchema, err = decodeAttributeSchema(schema); err != nil {
+			return nil, 0, err
+		}
 		categories = append(categories, c)
 	}
 	return categories, total, rows.Err()
@@ -97,39 +102,55 @@ func (r *repository) Get(ctx context.Context, id int64) (Category, error) {
 	if err != nil {
 		return Category{}, err
 	}
+	schema, err := decodeAttributeSchema(row.AttributeSchema)
+	if err != nil {
+		return Category{}, err
+	}
 	return Category{
-		ID:   row.ID,
-		Code: row.Code,
-		Name: row.Name,
+		ID:              row.ID,
+		Code:            row.Code,
+		Name:            row.Name,
+		AttributeSchema: schema,
 	}, nil
 }
 
 // Create uses sqlc generated query
 func (r *repository) Create(ctx context.Context, category Category) (Category, error) {
+	schema, err := encodeAttributeSchema(category.AttributeSchema)
+	if err != nil {
+		return Category{}, err
+	}
 	now := time.Now()
 	row, err := r.queries.CreateCategory(ctx, sqlc.CreateCategoryParams{
-		Code:      category.Code,
-		Name:      category.Name,
-		CreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
-		UpdatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+		Code:            category.Code,
+		Name:            category.Name,
+		AttributeSchema: schema,
+		CreatedAt:       pgtype.Timestamptz{Time: now, Valid: true},
+		UpdatedAt:       pgtype.Timestamptz{Time: now, Valid: true},
 	})
 	if err != nil {
 		return Category{}, err
 	}
 	return Category{
-		ID:   row.ID,
-		Code: row.Code,
-		Name: row.Name,
+		ID:              row.ID,
+		Code:            row.Code,
+		Name:            row.Name,
+		AttributeSchema: category.AttributeSchema,
 	}, nil
 }
 
 // Update uses sqlc generated query
 func (r *repository) Update(ctx context.Context, id int64, category Category) error {
+	schema, err := encodeAttributeSchema(category.AttributeSchema)
+	if err != nil {
+		return err
+	}
 	return r.queries.UpdateCategory(ctx, sqlc.UpdateCategoryParams{
-		Code:      category.Code,
-		Name:      category.Name,
-		UpdatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
-		ID:        id,
+		Code:            category.Code,
+		Name:            category.Name,
+		AttributeSchema: schema,
+		UpdatedAt:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ID:              id,
 	})
 }
 
@@ -152,3 +173,21 @@ func sortOrder(sortBy, sortDir string) string {
 		return "name " + dir
 	}
 }
+
+func encodeAttributeSchema(schema []AttributeDefinition) ([]byte, error) {
+	if schema == nil {
+		schema = []AttributeDefinition{}
+	}
+	return json.Marshal(schema)
+}
+
+func decodeAttributeSchema(raw []byte) ([]AttributeDefinition, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var schema []AttributeDefinition
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
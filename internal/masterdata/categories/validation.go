@@ -12,5 +12,5 @@ func (s *Service) validate(c Category) error {
 	if strings.TrimSpace(c.Name) == "" {
 		return errors.New("category name is required")
 	}
-	return nil
+	return ValidateAttributeSchema(c.AttributeSchema)
 }
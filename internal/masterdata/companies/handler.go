@@ -1,13 +1,16 @@
 package companies
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -45,8 +48,8 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	companies, total, err := h.service.List(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list companies failed", "error", err)
-		http.Error(w, "Failed to load companies", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list companies failed", "error", err)
+		httpx.Error(w, r, "Failed to load companies", http.StatusInternalServerError)
 		return
 	}
 
@@ -60,19 +63,31 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid company ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid company ID", http.StatusBadRequest)
 		return
 	}
 
 	company, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get company failed", "error", err, "id", id)
-		http.Error(w, "Company not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get company failed", "error", err, "id", id)
+		httpx.Error(w, r, "Company not found", http.StatusNotFound)
 		return
 	}
 
+	policy, err := h.service.DueDatePolicy(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get company due date policy failed", "error", err, "id", id)
+	}
+
+	minOrder, err := h.service.MinOrderPolicy(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get company minimum order value failed", "error", err, "id", id)
+	}
+
 	h.render(w, r, "pages/masterdata/company_detail.html", map[string]any{
-		"Company": company,
+		"Company":        company,
+		"DueDatePolicy":  policy,
+		"MinOrderPolicy": minOrder,
 	}, http.StatusOK)
 }
 
@@ -85,7 +100,7 @@ func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -98,7 +113,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	created, err := h.service.Create(r.Context(), company)
 	if err != nil {
-		h.logger.Error("create company failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create company failed", "error", err)
 		h.render(w, r, "pages/masterdata/company_form.html", map[string]any{
 			"Errors":  map[string]string{"general": internalShared.UserSafeMessage(err)},
 			"Company": nil,
@@ -112,14 +127,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid company ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid company ID", http.StatusBadRequest)
 		return
 	}
 
 	company, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get company failed", "error", err, "id", id)
-		http.Error(w, "Company not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get company failed", "error", err, "id", id)
+		httpx.Error(w, r, "Company not found", http.StatusNotFound)
 		return
 	}
 
@@ -132,12 +147,12 @@ func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid company ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid company ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -150,7 +165,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	err = h.service.Update(r.Context(), id, company)
 	if err != nil {
-		h.logger.Error("update company failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update company failed", "error", err, "id", id)
 		h.render(w, r, "pages/masterdata/company_form.html", map[string]any{
 			"Errors":  map[string]string{"general": internalShared.UserSafeMessage(err)},
 			"Company": company,
@@ -164,13 +179,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid company ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid company ID", http.StatusBadRequest)
 		return
 	}
 
 	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error("delete company failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "delete company failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/masterdata/companies", "error", internalShared.UserSafeMessage(err))
 		return
 	}
@@ -178,6 +193,77 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.redirectWithFlash(w, r, "/masterdata/companies", "success", "Company deleted successfully")
 }
 
+func (h *Handler) UpdateDueDatePolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid company ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	location := "/masterdata/companies/" + strconv.FormatInt(id, 10)
+	policy := DueDatePolicy{
+		CompanyID:       id,
+		BusinessDays:    r.PostFormValue("business_days") != "",
+		HolidayCalendar: r.PostFormValue("holiday_calendar"),
+	}
+	if err := h.service.SetDueDatePolicy(r.Context(), policy); err != nil {
+		if errors.Is(err, ErrUnknownHolidayCalendar) {
+			h.redirectWithFlash(w, r, location, "error", "Unknown holiday calendar")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "update company due date policy failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, location, "error", internalShared.UserSafeMessage(err))
+		return
+	}
+
+	h.redirectWithFlash(w, r, location, "success", "Due date policy updated successfully")
+}
+
+// UpdateMinOrderPolicy sets the minimum value below which the company's sales
+// orders need an override to be confirmed.
+func (h *Handler) UpdateMinOrderPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid company ID", http.StatusBadRequest)
+		return
+	}
+	if !internalShared.CompanyAllowed(r.Context(), id) {
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	location := "/masterdata/companies/" + strconv.FormatInt(id, 10)
+	policy := MinOrderPolicy{CompanyID: id}
+	if raw := strings.TrimSpace(r.PostFormValue("min_order_value")); raw != "" {
+		policy.MinOrderValue, err = strconv.ParseFloat(raw, 64)
+		if err != nil {
+			h.redirectWithFlash(w, r, location, "error", "Invalid minimum order value")
+			return
+		}
+	}
+	if err := h.service.SetMinOrderPolicy(r.Context(), policy); err != nil {
+		if errors.Is(err, ErrInvalidMinOrderValue) {
+			h.redirectWithFlash(w, r, location, "error", "Minimum order value cannot be negative")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "update company minimum order value failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, location, "error", internalShared.UserSafeMessage(err))
+		return
+	}
+
+	h.redirectWithFlash(w, r, location, "success", "Minimum order value updated successfully")
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
 	sess := internalShared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -194,7 +280,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", "error", err, "template", template)
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
 	}
 }
 
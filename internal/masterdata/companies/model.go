@@ -14,3 +14,20 @@ type Company struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// DueDatePolicy controls whether AP/AR invoice due dates for the company
+// roll forward to the next business day of HolidayCalendar.
+type DueDatePolicy struct {
+	CompanyID       int64     `json:"company_id"`
+	BusinessDays    bool      `json:"business_days"`
+	HolidayCalendar string    `json:"holiday_calendar"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// MinOrderPolicy is the smallest sales order value, in the base currency,
+// the company confirms without an override. Zero means no minimum.
+type MinOrderPolicy struct {
+	CompanyID     int64     `json:"company_id"`
+	MinOrderValue float64   `json:"min_order_value"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -2,12 +2,15 @@ package companies
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -17,6 +20,11 @@ type Repository interface {
 	Create(ctx context.Context, company Company) (Company, error)
 	Update(ctx context.Context, id int64, company Company) error
 	Delete(ctx context.Context, id int64) error
+	DueDatePolicy(ctx context.Context, id int64) (DueDatePolicy, error)
+	SetDueDatePolicy(ctx context.Context, policy DueDatePolicy) error
+	HolidayCalendarExists(ctx context.Context, code string) (bool, error)
+	MinOrderPolicy(ctx context.Context, id int64) (MinOrderPolicy, error)
+	SetMinOrderPolicy(ctx context.Context, policy MinOrderPolicy) error
 }
 
 type repository struct {
@@ -164,6 +172,38 @@ func (r *repository) Delete(ctx context.Context, id int64) error {
 	return r.queries.DeleteCompany(ctx, id)
 }
 
+// DueDatePolicy uses sqlc generated query; companies without a stored policy
+// get the calendar-day default.
+func (r *repository) DueDatePolicy(ctx context.Context, id int64) (DueDatePolicy, error) {
+	row, err := r.queries.GetCompanyDueDatePolicy(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DueDatePolicy{CompanyID: id, HolidayCalendar: internalShared.DefaultHolidayCalendar}, nil
+	}
+	if err != nil {
+		return DueDatePolicy{}, err
+	}
+	policy := DueDatePolicy{CompanyID: row.CompanyID, BusinessDays: row.BusinessDays, HolidayCalendar: row.HolidayCalendar}
+	if row.UpdatedAt.Valid {
+		policy.UpdatedAt = row.UpdatedAt.Time
+	}
+	return policy, nil
+}
+
+// SetDueDatePolicy uses sqlc generated query
+func (r *repository) SetDueDatePolicy(ctx context.Context, policy DueDatePolicy) error {
+	return r.queries.SetCompanyDueDatePolicy(ctx, sqlc.SetCompanyDueDatePolicyParams{
+		CompanyID:       policy.CompanyID,
+		BusinessDays:    policy.BusinessDays,
+		HolidayCalendar: policy.HolidayCalendar,
+		UpdatedAt:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+}
+
+// HolidayCalendarExists uses sqlc generated query
+func (r *repository) HolidayCalendarExists(ctx context.Context, code string) (bool, error) {
+	return r.queries.HolidayCalendarExists(ctx, code)
+}
+
 func sortOrder(sortBy, sortDir string) string {
 	dir := "ASC"
 	if sortDir == "desc" {
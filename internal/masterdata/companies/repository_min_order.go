@@ -0,0 +1,32 @@
+package companies
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MinOrderPolicy reads the company's minimum order value; companies without
+// a row have none.
+func (r *repository) MinOrderPolicy(ctx context.Context, id int64) (MinOrderPolicy, error) {
+	policy := MinOrderPolicy{CompanyID: id}
+	err := r.pool.QueryRow(ctx, `SELECT min_order_value::float8, updated_at
+FROM company_min_order_values WHERE company_id = $1`, id).Scan(&policy.MinOrderValue, &policy.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return policy, nil
+	}
+	if err != nil {
+		return MinOrderPolicy{}, err
+	}
+	return policy, nil
+}
+
+// SetMinOrderPolicy stores the company's minimum order value.
+func (r *repository) SetMinOrderPolicy(ctx context.Context, policy MinOrderPolicy) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO company_min_order_values (company_id, min_order_value, updated_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (company_id) DO UPDATE SET min_order_value = EXCLUDED.min_order_value, updated_at = EXCLUDED.updated_at`,
+		policy.CompanyID, policy.MinOrderValue)
+	return err
+}
@@ -3,10 +3,19 @@ package companies
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
+// ErrUnknownHolidayCalendar is returned when no holidays are loaded for the
+// requested calendar code.
+var ErrUnknownHolidayCalendar = errors.New("unknown holiday calendar")
+
+// ErrInvalidMinOrderValue is returned for a negative minimum order value.
+var ErrInvalidMinOrderValue = errors.New("minimum order value cannot be negative")
+
 type Service struct {
 	repo Repository
 }
@@ -49,3 +58,52 @@ func (s *Service) Delete(ctx context.Context, id int64) error {
 	}
 	return s.repo.Delete(ctx, id)
 }
+
+// DueDatePolicy returns the company's business-day setting for invoice due
+// dates.
+func (s *Service) DueDatePolicy(ctx context.Context, id int64) (DueDatePolicy, error) {
+	if id <= 0 {
+		return DueDatePolicy{}, errors.New("invalid company ID")
+	}
+	return s.repo.DueDatePolicy(ctx, id)
+}
+
+// SetDueDatePolicy stores whether invoice due dates observe business days and
+// which holiday calendar applies. The calendar must have holidays loaded.
+func (s *Service) SetDueDatePolicy(ctx context.Context, policy DueDatePolicy) error {
+	if policy.CompanyID <= 0 {
+		return errors.New("invalid company ID")
+	}
+	policy.HolidayCalendar = strings.ToUpper(strings.TrimSpace(policy.HolidayCalendar))
+	if policy.HolidayCalendar == "" {
+		policy.HolidayCalendar = internalShared.DefaultHolidayCalendar
+	}
+	ok, err := s.repo.HolidayCalendarExists(ctx, policy.HolidayCalendar)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUnknownHolidayCalendar
+	}
+	return s.repo.SetDueDatePolicy(ctx, policy)
+}
+
+// MinOrderPolicy returns the company's minimum sales order value.
+func (s *Service) MinOrderPolicy(ctx context.Context, id int64) (MinOrderPolicy, error) {
+	if id <= 0 {
+		return MinOrderPolicy{}, errors.New("invalid company ID")
+	}
+	return s.repo.MinOrderPolicy(ctx, id)
+}
+
+// SetMinOrderPolicy stores the company's minimum sales order value. Zero
+// removes the minimum.
+func (s *Service) SetMinOrderPolicy(ctx context.Context, policy MinOrderPolicy) error {
+	if policy.CompanyID <= 0 {
+		return errors.New("invalid company ID")
+	}
+	if policy.MinOrderValue < 0 {
+		return ErrInvalidMinOrderValue
+	}
+	return s.repo.SetMinOrderPolicy(ctx, policy)
+}
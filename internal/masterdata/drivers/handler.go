@@ -0,0 +1,199 @@
+package drivers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	service   *Service
+	templates *view.Engine
+	csrf      *internalShared.CSRFManager
+	sessions  *internalShared.SessionManager
+	rbac      rbac.Middleware
+}
+
+func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, csrf *internalShared.CSRFManager, sessions *internalShared.SessionManager, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, sessions: sessions, rbac: rbac}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 20
+	}
+
+	filters := shared.ListFilters{
+		Page:    page,
+		Limit:   limit,
+		Search:  r.URL.Query().Get("search"),
+		SortBy:  r.URL.Query().Get("sort"),
+		SortDir: r.URL.Query().Get("dir"),
+	}
+
+	drivers, total, err := h.service.List(r.Context(), filters)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list drivers failed", "error", err)
+		httpx.Error(w, r, "Failed to load drivers", http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, r, "pages/masterdata/drivers_list.html", map[string]any{
+		"Drivers": drivers,
+		"Filters": filters,
+		"Total":   total,
+	}, http.StatusOK)
+}
+
+func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, "pages/masterdata/driver_form.html", map[string]any{
+		"Errors": map[string]string{},
+		"Driver": nil,
+	}, http.StatusOK)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	driver := driverFromForm(r)
+	if _, err := h.service.Create(r.Context(), driver); err != nil {
+		h.logger.ErrorContext(r.Context(), "create driver failed", "error", err)
+		h.render(w, r, "pages/masterdata/driver_form.html", map[string]any{
+			"Errors": map[string]string{"general": formErrorMessage(err)},
+			"Driver": nil,
+			"Input":  driver,
+		}, http.StatusBadRequest)
+		return
+	}
+
+	h.redirectWithFlash(w, r, "/masterdata/drivers", "success", "Driver created successfully")
+}
+
+func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid driver ID", http.StatusBadRequest)
+		return
+	}
+
+	driver, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get driver failed", "error", err, "id", id)
+		httpx.Error(w, r, "Driver not found", http.StatusNotFound)
+		return
+	}
+
+	h.render(w, r, "pages/masterdata/driver_form.html", map[string]any{
+		"Errors": map[string]string{},
+		"Driver": driver,
+		"Input":  driver,
+	}, http.StatusOK)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid driver ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	driver := driverFromForm(r)
+	driver.ID = id
+	if err := h.service.Update(r.Context(), id, driver); err != nil {
+		h.logger.ErrorContext(r.Context(), "update driver failed", "error", err, "id", id)
+		h.render(w, r, "pages/masterdata/driver_form.html", map[string]any{
+			"Errors": map[string]string{"general": formErrorMessage(err)},
+			"Driver": driver,
+			"Input":  driver,
+		}, http.StatusBadRequest)
+		return
+	}
+
+	h.redirectWithFlash(w, r, "/masterdata/drivers", "success", "Driver updated successfully")
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid driver ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.logger.ErrorContext(r.Context(), "delete driver failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, "/masterdata/drivers", "error", internalShared.UserSafeMessage(err))
+		return
+	}
+
+	h.redirectWithFlash(w, r, "/masterdata/drivers", "success", "Driver deleted successfully")
+}
+
+func driverFromForm(r *http.Request) Driver {
+	return Driver{
+		Name:          r.PostFormValue("name"),
+		Phone:         r.PostFormValue("phone"),
+		LicenseNumber: r.PostFormValue("license_number"),
+		Active:        r.PostFormValue("active") != "",
+	}
+}
+
+// formErrorMessage shows driver validation errors verbatim and hides
+// everything else behind the generic user-safe message.
+func formErrorMessage(err error) string {
+	for _, known := range []error{ErrNameRequired, ErrFieldTooLong, ErrDuplicateName} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return internalShared.UserSafeMessage(err)
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
+	sess := internalShared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *internalShared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{
+		Title:       "Master Data",
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data:        data,
+	}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, template, viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
+	}
+}
+
+func (h *Handler) redirectWithFlash(w http.ResponseWriter, r *http.Request, location, kind, message string) {
+	if sess := internalShared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(internalShared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, location, http.StatusSeeOther)
+}
@@ -0,0 +1,10 @@
+package drivers
+
+// Driver is a delivery driver that delivery orders can be assigned to.
+type Driver struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Phone         string `json:"phone"`
+	LicenseNumber string `json:"license_number"`
+	Active        bool   `json:"active"`
+}
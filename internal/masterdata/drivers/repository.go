@@ -0,0 +1,135 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+)
+
+type Repository interface {
+	List(ctx context.Context, filters shared.ListFilters) ([]Driver, int, error)
+	Get(ctx context.Context, id int64) (Driver, error)
+	Create(ctx context.Context, driver Driver) (Driver, error)
+	Update(ctx context.Context, id int64, driver Driver) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repository{pool: pool}
+}
+
+func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Driver, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	if filters.Search != "" {
+		args = append(args, "%"+filters.Search+"%")
+		where += ` AND (name ILIKE $1 OR phone ILIKE $1 OR license_number ILIKE $1)`
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM delivery_drivers`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, name, phone, license_number, is_active FROM delivery_drivers` + where +
+		` ORDER BY ` + sortOrder(filters.SortBy, filters.SortDir)
+	if filters.Limit > 0 {
+		offset := (filters.Page - 1) * filters.Limit
+		if offset < 0 {
+			offset = 0
+		}
+		args = append(args, filters.Limit, offset)
+		query += ` LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var drivers []Driver
+	for rows.Next() {
+		var d Driver
+		if err := rows.Scan(&d.ID, &d.Name, &d.Phone, &d.LicenseNumber, &d.Active); err != nil {
+			return nil, 0, err
+		}
+		drivers = append(drivers, d)
+	}
+	return drivers, total, rows.Err()
+}
+
+func (r *repository) Get(ctx context.Context, id int64) (Driver, error) {
+	var d Driver
+	err := r.pool.QueryRow(ctx, `SELECT id, name, phone, license_number, is_active FROM delivery_drivers WHERE id = $1`, id).
+		Scan(&d.ID, &d.Name, &d.Phone, &d.LicenseNumber, &d.Active)
+	return d, err
+}
+
+func (r *repository) Create(ctx context.Context, driver Driver) (Driver, error) {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO delivery_drivers (name, phone, license_number, is_active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		driver.Name, driver.Phone, driver.LicenseNumber, driver.Active,
+	).Scan(&driver.ID)
+	if err != nil {
+		return Driver{}, mapUniqueViolation(err)
+	}
+	return driver, nil
+}
+
+func (r *repository) Update(ctx context.Context, id int64, driver Driver) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE delivery_drivers
+		SET name = $2, phone = $3, license_number = $4, is_active = $5, updated_at = NOW()
+		WHERE id = $1`,
+		id, driver.Name, driver.Phone, driver.LicenseNumber, driver.Active,
+	)
+	if err != nil {
+		return mapUniqueViolation(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Delete removes the driver. Delivery orders that used it keep their
+// driver name; only the link to the master record is cleared.
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM delivery_drivers WHERE id = $1`, id)
+	return err
+}
+
+func mapUniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateName
+	}
+	return err
+}
+
+func sortOrder(sortBy, sortDir string) string {
+	dir := "ASC"
+	if sortDir == "desc" {
+		dir = "DESC"
+	}
+	switch sortBy {
+	case "phone":
+		return "phone " + dir
+	case "active":
+		return "is_active " + dir + ", name ASC"
+	default:
+		return "name " + dir
+	}
+}
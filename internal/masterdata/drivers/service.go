@@ -0,0 +1,53 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) List(ctx context.Context, filters shared.ListFilters) ([]Driver, int, error) {
+	return s.repo.List(ctx, filters)
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (Driver, error) {
+	if id <= 0 {
+		return Driver{}, errors.New("invalid driver ID")
+	}
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) Create(ctx context.Context, driver Driver) (Driver, error) {
+	driver = normalize(driver)
+	if err := s.validate(driver); err != nil {
+		return Driver{}, err
+	}
+	return s.repo.Create(ctx, driver)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, driver Driver) error {
+	if id <= 0 {
+		return errors.New("invalid driver ID")
+	}
+	driver = normalize(driver)
+	if err := s.validate(driver); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, id, driver)
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("invalid driver ID")
+	}
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,36 @@
+package drivers
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNameRequired rejects a driver without a name.
+	ErrNameRequired = errors.New("driver name is required")
+	// ErrFieldTooLong rejects names over 200 characters, or phone and
+	// license numbers over 50.
+	ErrFieldTooLong = errors.New("driver name must be at most 200 characters, phone and license number at most 50")
+	// ErrDuplicateName indicates another driver already has the name.
+	ErrDuplicateName = errors.New("a driver with this name already exists")
+)
+
+func normalize(d Driver) Driver {
+	d.Name = strings.Join(strings.Fields(d.Name), " ")
+	d.Phone = strings.TrimSpace(d.Phone)
+	d.LicenseNumber = strings.ToUpper(strings.TrimSpace(d.LicenseNumber))
+	return d
+}
+
+func (s *Service) validate(d Driver) error {
+	if d.Name == "" {
+		return ErrNameRequired
+	}
+	if utf8.RuneCountInString(d.Name) > 200 ||
+		utf8.RuneCountInString(d.Phone) > 50 ||
+		utf8.RuneCountInString(d.LicenseNumber) > 50 {
+		return ErrFieldTooLong
+	}
+	return nil
+}
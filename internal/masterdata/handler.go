@@ -9,10 +9,12 @@ import (
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/branches"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/categories"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/companies"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/drivers"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/products"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/suppliers"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/taxes"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/units"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/vehicles"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/warehouses"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
@@ -21,15 +23,18 @@ import (
 
 // Handler manages master data endpoints.
 type Handler struct {
-	logger           *slog.Logger
-	companiesHandler *companies.Handler
-	branchesHandler  *branches.Handler
+	logger            *slog.Logger
+	companiesHandler  *companies.Handler
+	branchesHandler   *branches.Handler
 	warehousesHandler *warehouses.Handler
-	unitsHandler     *units.Handler
-	taxesHandler     *taxes.Handler
+	unitsHandler      *units.Handler
+	taxesHandler      *taxes.Handler
 	categoriesHandler *categories.Handler
-	suppliersHandler *suppliers.Handler
-	productsHandler  *products.Handler
+	suppliersHandler  *suppliers.Handler
+	productsHandler   *products.Handler
+	driversHandler    *drivers.Handler
+	vehiclesHandler   *vehicles.Handler
+	supplierService   *suppliers.Service
 }
 
 // NewHandler builds Handler instance.
@@ -43,6 +48,8 @@ func NewHandler(logger *slog.Logger, db *pgxpool.Pool, templates *view.Engine, c
 	categoryRepo := categories.NewRepository(db)
 	supplierRepo := suppliers.NewRepository(db)
 	productRepo := products.NewRepository(db)
+	driverRepo := drivers.NewRepository(db)
+	vehicleRepo := vehicles.NewRepository(db)
 
 	// Services
 	companyService := companies.NewService(companyRepo)
@@ -52,34 +59,50 @@ func NewHandler(logger *slog.Logger, db *pgxpool.Pool, templates *view.Engine, c
 	taxService := taxes.NewService(taxRepo)
 	categoryService := categories.NewService(categoryRepo)
 	supplierService := suppliers.NewService(supplierRepo)
-	productService := products.NewService(productRepo)
+	productService := products.NewService(productRepo, categoryService)
+	driverService := drivers.NewService(driverRepo)
+	vehicleService := vehicles.NewService(vehicleRepo)
+
+	auditLogger := shared.NewAuditLogger(db)
+	supplierService.SetAuditor(auditLogger)
+	productService.SetAuditor(auditLogger)
 
 	// Handlers
 	companiesHandler := companies.NewHandler(logger, companyService, templates, csrf, sessions, rbac)
 	branchesHandler := branches.NewHandler(logger, branchService, companyService, templates, csrf, sessions, rbac)
 	warehousesHandler := warehouses.NewHandler(logger, warehouseService, branchService, templates, csrf, sessions, rbac)
 	// (Note: warehouse handler needs branchService for list options)
-	
+
 	unitsHandler := units.NewHandler(logger, unitService, templates, csrf, sessions, rbac)
 	taxesHandler := taxes.NewHandler(logger, taxService, templates, csrf, sessions, rbac)
 	categoriesHandler := categories.NewHandler(logger, categoryService, templates, csrf, sessions, rbac)
 	suppliersHandler := suppliers.NewHandler(logger, supplierService, templates, csrf, sessions, rbac)
-	
+
 	productsHandler := products.NewHandler(logger, productService, categoryService, unitService, taxService, templates, csrf, sessions, rbac)
+	driversHandler := drivers.NewHandler(logger, driverService, templates, csrf, sessions, rbac)
+	vehiclesHandler := vehicles.NewHandler(logger, vehicleService, templates, csrf, sessions, rbac)
 
 	return &Handler{
-		logger:           logger,
-		companiesHandler: companiesHandler,
-		branchesHandler:  branchesHandler,
+		logger:            logger,
+		companiesHandler:  companiesHandler,
+		branchesHandler:   branchesHandler,
 		warehousesHandler: warehousesHandler,
-		unitsHandler:     unitsHandler,
-		taxesHandler:     taxesHandler,
+		unitsHandler:      unitsHandler,
+		taxesHandler:      taxesHandler,
 		categoriesHandler: categoriesHandler,
-		suppliersHandler: suppliersHandler,
-		productsHandler:  productsHandler,
+		suppliersHandler:  suppliersHandler,
+		productsHandler:   productsHandler,
+		driversHandler:    driversHandler,
+		vehiclesHandler:   vehiclesHandler,
+		supplierService:   supplierService,
 	}
 }
 
+// SetTaxIDValidator applies configured tax ID formats to supplier validation.
+func (h *Handler) SetTaxIDValidator(v *shared.TaxIDValidator) {
+	h.supplierService.SetTaxIDValidator(v)
+}
+
 // MountRoutes registers master data routes.
 func (h *Handler) MountRoutes(r chi.Router) {
 	r.Route("/companies", func(r chi.Router) {
@@ -106,4 +129,10 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	r.Route("/products", func(r chi.Router) {
 		h.productsHandler.MountRoutes(r)
 	})
+	r.Route("/drivers", func(r chi.Router) {
+		h.driversHandler.MountRoutes(r)
+	})
+	r.Route("/vehicles", func(r chi.Router) {
+		h.vehiclesHandler.MountRoutes(r)
+	})
 }
@@ -0,0 +1,109 @@
+package products
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// UnitConversion defines an alternate unit of a product: one UnitID equals
+// Factor of PerUnitID. PerUnitID is the product's base unit or another
+// alternate unit, so a carton can be defined in boxes and a box in pieces.
+type UnitConversion struct {
+	UnitID      int64   `json:"unit_id"`
+	UnitCode    string  `json:"unit_code"`
+	PerUnitID   int64   `json:"per_unit_id"`
+	PerUnitCode string  `json:"per_unit_code"`
+	Factor      float64 `json:"factor"`
+	// BaseFactor is the number of base units in one UnitID. It is resolved
+	// from the chain of conversions when the product is saved.
+	BaseFactor float64 `json:"base_factor"`
+}
+
+// ConversionError reports an invalid unit conversion. The message is safe to
+// show to users.
+type ConversionError struct {
+	Message string
+}
+
+func (e *ConversionError) Error() string {
+	return e.Message
+}
+
+// ErrUnknownUnit is returned when a document uses a unit the product has no
+// conversion for.
+var ErrUnknownUnit = fmt.Errorf("%w: unit is not defined for the product", internalShared.ErrValidation)
+
+// ResolveConversions checks the conversions against the product's base unit
+// and fills in each BaseFactor. Factors must be positive, every unit may be
+// defined once, and each chain of conversions must end at the base unit.
+func ResolveConversions(baseUnitID int64, conversions []UnitConversion) error {
+	byUnit := make(map[int64]int, len(conversions))
+	for i, c := range conversions {
+		switch {
+		case c.UnitID <= 0 || c.PerUnitID <= 0:
+			return &ConversionError{Message: "each conversion needs a unit and the unit it is counted in"}
+		case c.Factor <= 0:
+			return &ConversionError{Message: fmt.Sprintf("conversion factor for %s must be positive", conversionUnit(c.UnitCode, c.UnitID))}
+		case c.UnitID == baseUnitID:
+			return &ConversionError{Message: fmt.Sprintf("%s is the base unit and cannot be converted", conversionUnit(c.UnitCode, c.UnitID))}
+		case c.UnitID == c.PerUnitID:
+			return &ConversionError{Message: fmt.Sprintf("%s cannot be converted to itself", conversionUnit(c.UnitCode, c.UnitID))}
+		}
+		if _, dup := byUnit[c.UnitID]; dup {
+			return &ConversionError{Message: fmt.Sprintf("%s is converted more than once", conversionUnit(c.UnitCode, c.UnitID))}
+		}
+		byUnit[c.UnitID] = i
+	}
+	for i := range conversions {
+		factor := 1.0
+		visited := make(map[int64]bool)
+		for unitID := conversions[i].UnitID; unitID != baseUnitID; {
+			if visited[unitID] {
+				return &ConversionError{Message: fmt.Sprintf("conversions for %s are circular", conversionUnit(conversions[i].UnitCode, conversions[i].UnitID))}
+			}
+			visited[unitID] = true
+			c := conversions[byUnit[unitID]]
+			if _, known := byUnit[c.PerUnitID]; !known && c.PerUnitID != baseUnitID {
+				return &ConversionError{Message: fmt.Sprintf("%s is counted in %s, which is not the base unit or an alternate unit of this product", conversionUnit(c.UnitCode, c.UnitID), conversionUnit(c.PerUnitCode, c.PerUnitID))}
+			}
+			factor *= c.Factor
+			unitID = c.PerUnitID
+		}
+		conversions[i].BaseFactor = factor
+	}
+	return nil
+}
+
+func conversionUnit(code string, id int64) string {
+	if code != "" {
+		return code
+	}
+	return "unit #" + strconv.FormatInt(id, 10)
+}
+
+// BaseFactor returns how many base units one uom of the product holds. A
+// blank uom or the base unit's code is 1. Products without alternate units
+// keep accepting any unit label at 1:1, as documents did before conversions
+// existed; otherwise an unknown uom fails with ErrUnknownUnit.
+func (s *Service) BaseFactor(ctx context.Context, productID int64, uom string) (float64, error) {
+	uom = strings.ToUpper(strings.TrimSpace(uom))
+	if uom == "" {
+		return 1, nil
+	}
+	factors, err := s.repo.UnitFactors(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+	if len(factors) <= 1 {
+		return 1, nil
+	}
+	factor, ok := factors[uom]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s on product %d", ErrUnknownUnit, uom, productID)
+	}
+	return factor, nil
+}
@@ -0,0 +1,76 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const (
+	pcs int64 = iota + 1
+	box
+	ctn
+	pallet
+)
+
+func TestResolveConversionsFollowsChainToBaseUnit(t *testing.T) {
+	conversions := []UnitConversion{
+		{UnitID: ctn, UnitCode: "CTN", PerUnitID: box, Factor: 10},
+		{UnitID: box, UnitCode: "BOX", PerUnitID: pcs, Factor: 12},
+	}
+	if err := ResolveConversions(pcs, conversions); err != nil {
+		t.Fatalf("ResolveConversions: %v", err)
+	}
+	if conversions[0].BaseFactor != 120 || conversions[1].BaseFactor != 12 {
+		t.Fatalf("unexpected base factors %+v", conversions)
+	}
+}
+
+func TestResolveConversionsRejectsInvalidDefinitions(t *testing.T) {
+	cases := map[string][]UnitConversion{
+		"zero factor":      {{UnitID: box, PerUnitID: pcs, Factor: 0}},
+		"negative factor":  {{UnitID: box, PerUnitID: pcs, Factor: -12}},
+		"base unit":        {{UnitID: pcs, PerUnitID: box, Factor: 12}},
+		"duplicate":        {{UnitID: box, PerUnitID: pcs, Factor: 12}, {UnitID: box, PerUnitID: pcs, Factor: 10}},
+		"unknown per unit": {{UnitID: ctn, PerUnitID: pallet, Factor: 10}},
+		"circular": {
+			{UnitID: box, UnitCode: "BOX", PerUnitID: ctn, Factor: 0.1},
+			{UnitID: ctn, UnitCode: "CTN", PerUnitID: box, Factor: 10},
+		},
+	}
+	for name, conversions := range cases {
+		var convErr *ConversionError
+		if err := ResolveConversions(pcs, conversions); !errors.As(err, &convErr) {
+			t.Errorf("%s: expected a conversion error, got %v", name, err)
+		}
+	}
+}
+
+type stubFactorRepo struct {
+	Repository
+	factors map[string]float64
+}
+
+func (s stubFactorRepo) UnitFactors(context.Context, int64) (map[string]float64, error) {
+	return s.factors, nil
+}
+
+func TestBaseFactor(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(stubFactorRepo{factors: map[string]float64{"PCS": 1, "BOX": 12}}, nil)
+	if factor, err := svc.BaseFactor(ctx, 1, " box "); err != nil || factor != 12 {
+		t.Fatalf("BOX: got %v, %v", factor, err)
+	}
+	if factor, err := svc.BaseFactor(ctx, 1, ""); err != nil || factor != 1 {
+		t.Fatalf("blank unit: got %v, %v", factor, err)
+	}
+	if _, err := svc.BaseFactor(ctx, 1, "CTN"); !errors.Is(err, ErrUnknownUnit) {
+		t.Fatalf("expected ErrUnknownUnit, got %v", err)
+	}
+
+	// Without alternate units any label counts as the base unit.
+	legacy := NewService(stubFactorRepo{factors: map[string]float64{"EA": 1}}, nil)
+	if factor, err := legacy.BaseFactor(ctx, 1, "PCS"); err != nil || factor != 1 {
+		t.Fatalf("legacy label: got %v, %v", factor, err)
+	}
+}
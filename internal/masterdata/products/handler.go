@@ -1,9 +1,12 @@
 package products
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/taxes"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/units"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -79,32 +83,44 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		isActive := r.URL.Query().Get("is_active") == "true"
 		filters.IsActive = &isActive
 	}
+	filters.Attributes = attributeFilters(r.URL.Query())
 
 	products, total, err := h.service.List(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list products failed", "error", err)
-		http.Error(w, "Failed to load products", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list products failed", "error", err)
+		httpx.Error(w, r, "Failed to load products", http.StatusInternalServerError)
 		return
 	}
 
+	// The selected category's schema drives the attribute filters and columns.
+	var category *categories.Category
+	if filters.CategoryID != nil {
+		if c, err := h.categoryService.Get(r.Context(), *filters.CategoryID); err == nil {
+			category = &c
+		}
+	}
+	cats, _, _ := h.categoryService.List(r.Context(), shared.ListFilters{})
+
 	h.render(w, r, "pages/masterdata/products_list.html", map[string]any{
-		"Products": products,
-		"Filters":  filters,
-		"Total":    total,
+		"Products":   products,
+		"Filters":    filters,
+		"Total":      total,
+		"Categories": cats,
+		"Category":   category,
 	}, http.StatusOK)
 }
 
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid product ID", http.StatusBadRequest)
 		return
 	}
 
 	product, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get product failed", "error", err, "id", id)
-		http.Error(w, "Product not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get product failed", "error", err, "id", id)
+		httpx.Error(w, r, "Product not found", http.StatusNotFound)
 		return
 	}
 
@@ -119,17 +135,19 @@ func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 	ts, _, _ := h.taxService.List(r.Context(), shared.ListFilters{})
 
 	h.render(w, r, "pages/masterdata/product_form.html", map[string]any{
-		"Errors":     map[string]string{},
-		"Product":    nil,
-		"Categories": cats,
-		"Units":      us,
-		"Taxes":      ts,
+		"Errors":      map[string]string{},
+		"Product":     nil,
+		"Attributes":  map[string]any{},
+		"Conversions": conversionRows(nil),
+		"Categories":  cats,
+		"Units":       us,
+		"Taxes":       ts,
 	}, http.StatusOK)
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -141,28 +159,35 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	active := r.PostFormValue("is_active") == "on"
 
 	product := Product{
-		Code:       r.PostFormValue("code"),
-		Name:       r.PostFormValue("name"),
-		CategoryID: catID,
-		UnitID:     unitID,
-		TaxID:      taxID,
-		Price:      price,
-		Cost:       cost,
-		IsActive:   active,
+		Code:        r.PostFormValue("code"),
+		Name:        r.PostFormValue("name"),
+		CategoryID:  catID,
+		UnitID:      unitID,
+		TaxID:       taxID,
+		Price:       price,
+		Cost:        cost,
+		IsActive:    active,
+		Attributes:  attributesFromForm(r.PostForm),
+		TrackSerial: r.PostFormValue("track_serial") == "on",
+		Conversions: h.conversionsFromForm(r, unitID),
+
+		RequiresInspection: r.PostFormValue("requires_inspection") == "on",
 	}
 
 	created, err := h.service.Create(r.Context(), product)
 	if err != nil {
-		h.logger.Error("create product failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create product failed", "error", err)
 		cats, _, _ := h.categoryService.List(r.Context(), shared.ListFilters{})
 		us, _, _ := h.unitService.List(r.Context(), shared.ListFilters{})
 		ts, _, _ := h.taxService.List(r.Context(), shared.ListFilters{})
 		h.render(w, r, "pages/masterdata/product_form.html", map[string]any{
-			"Errors":     map[string]string{"general": internalShared.UserSafeMessage(err)},
-			"Product":    nil,
-			"Categories": cats,
-			"Units":      us,
-			"Taxes":      ts,
+			"Errors":      map[string]string{"general": formErrorMessage(err)},
+			"Product":     nil,
+			"Attributes":  product.Attributes,
+			"Conversions": conversionRows(product.Conversions),
+			"Categories":  cats,
+			"Units":       us,
+			"Taxes":       ts,
 		}, http.StatusBadRequest)
 		return
 	}
@@ -173,14 +198,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid product ID", http.StatusBadRequest)
 		return
 	}
 
 	product, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get product failed", "error", err, "id", id)
-		http.Error(w, "Product not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get product failed", "error", err, "id", id)
+		httpx.Error(w, r, "Product not found", http.StatusNotFound)
 		return
 	}
 
@@ -189,23 +214,31 @@ func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	ts, _, _ := h.taxService.List(r.Context(), shared.ListFilters{})
 
 	h.render(w, r, "pages/masterdata/product_form.html", map[string]any{
-		"Errors":     map[string]string{},
-		"Product":    product,
-		"Categories": cats,
-		"Units":      us,
-		"Taxes":      ts,
+		"Errors":      map[string]string{},
+		"Product":     product,
+		"Attributes":  product.Attributes,
+		"Conversions": conversionRows(product.Conversions),
+		"Categories":  cats,
+		"Units":       us,
+		"Taxes":       ts,
 	}, http.StatusOK)
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid product ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	expected, err := internalShared.ParseVersionToken(r.PostFormValue(internalShared.VersionFormField))
+	if err != nil {
+		httpx.Error(w, r, "Invalid version", http.StatusBadRequest)
 		return
 	}
 
@@ -217,29 +250,49 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	active := r.PostFormValue("is_active") == "on"
 
 	product := Product{
-		Code:       r.PostFormValue("code"),
-		Name:       r.PostFormValue("name"),
-		CategoryID: catID,
-		UnitID:     unitID,
-		TaxID:      taxID,
-		Price:      price,
-		Cost:       cost,
-		IsActive:   active,
+		Code:        r.PostFormValue("code"),
+		Name:        r.PostFormValue("name"),
+		CategoryID:  catID,
+		UnitID:      unitID,
+		TaxID:       taxID,
+		Price:       price,
+		Cost:        cost,
+		IsActive:    active,
+		Attributes:  attributesFromForm(r.PostForm),
+		TrackSerial: r.PostFormValue("track_serial") == "on",
+		Conversions: h.conversionsFromForm(r, unitID),
+
+		RequiresInspection: r.PostFormValue("requires_inspection") == "on",
+	}
+	if expected != nil {
+		product.UpdatedAt = *expected
 	}
 
 	err = h.service.Update(r.Context(), id, product)
 	if err != nil {
-		h.logger.Error("update product failed", "error", err, "id", id)
+		status := http.StatusBadRequest
+		product.ID = id
+		if errors.Is(err, internalShared.ErrStaleRecord) {
+			// Start the user again from the latest version.
+			status = http.StatusConflict
+			if latest, getErr := h.service.Get(r.Context(), id); getErr == nil {
+				product = latest
+			}
+		} else {
+			h.logger.ErrorContext(r.Context(), "update product failed", "error", err, "id", id)
+		}
 		cats, _, _ := h.categoryService.List(r.Context(), shared.ListFilters{})
 		us, _, _ := h.unitService.List(r.Context(), shared.ListFilters{})
 		ts, _, _ := h.taxService.List(r.Context(), shared.ListFilters{})
 		h.render(w, r, "pages/masterdata/product_form.html", map[string]any{
-			"Errors":     map[string]string{"general": internalShared.UserSafeMessage(err)},
-			"Product":    product,
-			"Categories": cats,
-			"Units":      us,
-			"Taxes":      ts,
-		}, http.StatusBadRequest)
+			"Errors":      map[string]string{"general": formErrorMessage(err)},
+			"Product":     product,
+			"Attributes":  product.Attributes,
+			"Conversions": conversionRows(product.Conversions),
+			"Categories":  cats,
+			"Units":       us,
+			"Taxes":       ts,
+		}, status)
 		return
 	}
 
@@ -249,13 +302,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid product ID", http.StatusBadRequest)
 		return
 	}
 
 	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error("delete product failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "delete product failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/masterdata/products", "error", internalShared.UserSafeMessage(err))
 		return
 	}
@@ -263,6 +316,91 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.redirectWithFlash(w, r, "/masterdata/products", "success", "Product deleted successfully")
 }
 
+// attributesFromForm collects the attr_<key> fields rendered for the selected
+// category. Values stay strings; the service converts them per the schema.
+func attributesFromForm(values url.Values) map[string]any {
+	attrs := make(map[string]any)
+	for name := range values {
+		if key, ok := strings.CutPrefix(name, "attr_"); ok && key != "" {
+			attrs[key] = values.Get(name)
+		}
+	}
+	return attrs
+}
+
+// attributeFilters collects the non-empty attr_<key> list filters.
+func attributeFilters(values url.Values) map[string]string {
+	var filters map[string]string
+	for name := range values {
+		key, ok := strings.CutPrefix(name, "attr_")
+		value := strings.TrimSpace(values.Get(name))
+		if !ok || key == "" || value == "" {
+			continue
+		}
+		if filters == nil {
+			filters = make(map[string]string)
+		}
+		filters[key] = value
+	}
+	return filters
+}
+
+// conversionsFromForm collects the unit conversion rows. Rows without a unit
+// are the blank rows of the form and are skipped; a blank "per" unit means the
+// base unit. Unit codes are filled in for validation messages.
+func (h *Handler) conversionsFromForm(r *http.Request, baseUnitID int64) []UnitConversion {
+	codes := make(map[int64]string)
+	if us, _, err := h.unitService.List(r.Context(), shared.ListFilters{}); err == nil {
+		for _, u := range us {
+			codes[u.ID] = u.Code
+		}
+	}
+	unitIDs := r.PostForm["conv_unit_id"]
+	factors := r.PostForm["conv_factor"]
+	perUnitIDs := r.PostForm["conv_per_unit_id"]
+	var conversions []UnitConversion
+	for i, raw := range unitIDs {
+		unitID, _ := strconv.ParseInt(raw, 10, 64)
+		if unitID == 0 {
+			continue
+		}
+		c := UnitConversion{UnitID: unitID}
+		if i < len(factors) {
+			c.Factor, _ = strconv.ParseFloat(factors[i], 64)
+		}
+		if i < len(perUnitIDs) {
+			c.PerUnitID, _ = strconv.ParseInt(perUnitIDs[i], 10, 64)
+		}
+		if c.PerUnitID == 0 {
+			c.PerUnitID = baseUnitID
+		}
+		c.UnitCode, c.PerUnitCode = codes[c.UnitID], codes[c.PerUnitID]
+		conversions = append(conversions, c)
+	}
+	return conversions
+}
+
+// conversionRows returns the conversions to edit followed by two blank rows
+// for new units.
+func conversionRows(conversions []UnitConversion) []UnitConversion {
+	rows := append([]UnitConversion{}, conversions...)
+	return append(rows, UnitConversion{}, UnitConversion{})
+}
+
+// formErrorMessage shows attribute and conversion validation errors verbatim
+// and hides everything else behind the generic user-safe message.
+func formErrorMessage(err error) string {
+	var attrErr *categories.AttributeError
+	if errors.As(err, &attrErr) {
+		return attrErr.Error()
+	}
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return convErr.Error()
+	}
+	return internalShared.UserSafeMessage(err)
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
 	sess := internalShared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -279,7 +417,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", "error", err, "template", template)
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
 	}
 }
 
@@ -6,7 +6,7 @@ import (
 
 // Product represents a product entity
 // Note: DB uses 'sku' column, maps to 'Code' field for backward compatibility
-// Note: DB does not have 'cost', 'created_at', 'updated_at' columns but has 'deleted_at'
+// Note: DB does not have 'cost', 'created_at' columns but has 'deleted_at'
 type Product struct {
 	ID         int64      `json:"id"`
 	Code       string     `json:"code"` // maps to 'sku' in database
@@ -19,5 +19,19 @@ type Product struct {
 	IsActive   bool       `json:"is_active"`
 	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 	CreatedAt  time.Time  `json:"created_at"` // not in DB, kept for backward compat
-	UpdatedAt  time.Time  `json:"updated_at"` // not in DB, kept for backward compat
+	UpdatedAt  time.Time  `json:"updated_at"` // optimistic locking version on update
+
+	// Attributes holds the custom attribute values defined by the category schema.
+	Attributes map[string]any `json:"attributes"`
+
+	// TrackSerial requires a serial number per unit on receipt and delivery.
+	TrackSerial bool `json:"track_serial"`
+
+	// RequiresInspection holds received goods in quality inspection instead
+	// of booking them straight into stock.
+	RequiresInspection bool `json:"requires_inspection"`
+
+	// Conversions lists the alternate units the product is bought or sold
+	// in. Stock is always kept in UnitID, the base unit.
+	Conversions []UnitConversion `json:"conversions"`
 }
@@ -2,11 +2,16 @@ package products
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -16,6 +21,7 @@ type Repository interface {
 	Create(ctx context.Context, product Product) (Product, error)
 	Update(ctx context.Context, id int64, product Product) error
 	Delete(ctx context.Context, id int64) error
+	UnitFactors(ctx context.Context, id int64) (map[string]float64, error)
 }
 
 type repository struct {
@@ -33,7 +39,7 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 // List uses dynamic query (not sqlc) due to filter complexity
 func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Product, int, error) {
 	// Note: DB uses 'sku' column, but we map to 'code' for backward compatibility
-	query := `SELECT id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at FROM products WHERE 1=1`
+	query := `SELECT id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, attributes FROM products WHERE 1=1`
 	args := []interface{}{}
 	argCount := 0
 
@@ -45,10 +51,16 @@ func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Pr
 
 	if filters.Search != "" {
 		argCount++
-		query += ` AND (name ILIKE $` + strconv.Itoa(argCount) + ` OR sku ILIKE $` + strconv.Itoa(argCount) + `)`
+		query += ` AND (name ILIKE $` + strconv.Itoa(argCount) + ` OR sku ILIKE $` + strconv.Itoa(argCount) + ` OR attributes::text ILIKE $` + strconv.Itoa(argCount) + `)`
 		args = append(args, "%"+filters.Search+"%")
 	}
 
+	for _, key := range attributeKeys(filters.Attributes) {
+		query += ` AND attributes->>$` + strconv.Itoa(argCount+1) + ` ILIKE $` + strconv.Itoa(argCount+2)
+		args = append(args, key, filters.Attributes[key])
+		argCount += 2
+	}
+
 	if filters.IsActive != nil {
 		argCount++
 		query += ` AND is_active = $` + strconv.Itoa(argCount)
@@ -67,9 +79,14 @@ func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Pr
 	}
 	if filters.Search != "" {
 		countArgCount++
-		countQuery += ` AND (name ILIKE $` + strconv.Itoa(countArgCount) + ` OR sku ILIKE $` + strconv.Itoa(countArgCount) + `)`
+		countQuery += ` AND (name ILIKE $` + strconv.Itoa(countArgCount) + ` OR sku ILIKE $` + strconv.Itoa(countArgCount) + ` OR attributes::text ILIKE $` + strconv.Itoa(countArgCount) + `)`
 		countArgs = append(countArgs, "%"+filters.Search+"%")
 	}
+	for _, key := range attributeKeys(filters.Attributes) {
+		countQuery += ` AND attributes->>$` + strconv.Itoa(countArgCount+1) + ` ILIKE $` + strconv.Itoa(countArgCount+2)
+		countArgs = append(countArgs, key, filters.Attributes[key])
+		countArgCount += 2
+	}
 	if filters.IsActive != nil {
 		countArgCount++
 		countQuery += ` AND is_active = $` + strconv.Itoa(countArgCount)
@@ -110,10 +127,14 @@ func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Pr
 		var price pgtype.Numeric
 		var taxID pgtype.Int8
 		var deletedAt pgtype.Timestamptz
-		err := rows.Scan(&p.ID, &p.Code, &p.Name, &p.CategoryID, &p.UnitID, &price, &taxID, &p.IsActive, &deletedAt)
+		var attrs []byte
+		err := rows.Scan(&p.ID, &p.Code, &p.Name, &p.CategoryID, &p.UnitID, &price, &taxID, &p.IsActive, &deletedAt, &attrs)
 		if err != nil {
 			return nil, 0, err
 		}
+		if p.Attributes, err = decodeAttributes(attrs); err != nil {
+			return nil, 0, err
+		}
 		if price.Valid {
 			f8, _ := price.Float64Value()
 			p.Price = f8.Float64
@@ -137,12 +158,13 @@ func (r *repository) Get(ctx context.Context, id int64) (Product, error) {
 		return Product{}, err
 	}
 	p := Product{
-		ID:         row.ID,
-		Code:       row.Sku, // map sku -> code
-		Name:       row.Name,
-		CategoryID: row.CategoryID,
-		UnitID:     row.UnitID,
-		IsActive:   row.IsActive,
+		ID:          row.ID,
+		Code:        row.Sku, // map sku -> code
+		Name:        row.Name,
+		CategoryID:  row.CategoryID,
+		UnitID:      row.UnitID,
+		IsActive:    row.IsActive,
+		TrackSerial: row.TrackSerial,
 	}
 	if row.Price.Valid {
 		f8, _ := row.Price.Float64Value()
@@ -155,6 +177,19 @@ func (r *repository) Get(ctx context.Context, id int64) (Product, error) {
 		t := row.DeletedAt.Time
 		p.DeletedAt = &t
 	}
+	if row.UpdatedAt.Valid {
+		p.UpdatedAt = row.UpdatedAt.Time
+	}
+	if p.Attributes, err = decodeAttributes(row.Attributes); err != nil {
+		return Product{}, err
+	}
+	if p.Conversions, err = r.conversions(ctx, id); err != nil {
+		return Product{}, err
+	}
+	// requires_inspection postdates the generated product queries.
+	if err := r.pool.QueryRow(ctx, `SELECT requires_inspection FROM products WHERE id = $1`, id).Scan(&p.RequiresInspection); err != nil {
+		return Product{}, err
+	}
 	return p, nil
 }
 
@@ -170,24 +205,51 @@ func (r *repository) Create(ctx context.Context, product Product) (Product, erro
 		taxID = pgtype.Int8{Int64: product.TaxID, Valid: true}
 	}
 
-	row, err := r.queries.CreateProduct(ctx, sqlc.CreateProductParams{
-		Sku:        product.Code, // map code -> sku
-		Name:       product.Name,
-		CategoryID: product.CategoryID,
-		UnitID:     product.UnitID,
-		Price:      price,
-		TaxID:      taxID,
-		IsActive:   product.IsActive,
+	attrs, err := encodeAttributes(product.Attributes)
+	if err != nil {
+		return Product{}, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Product{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	row, err := r.queries.WithTx(tx).CreateProduct(ctx, sqlc.CreateProductParams{
+		Sku:         product.Code, // map code -> sku
+		Name:        product.Name,
+		CategoryID:  product.CategoryID,
+		UnitID:      product.UnitID,
+		Price:       price,
+		TaxID:       taxID,
+		IsActive:    product.IsActive,
+		Attributes:  attrs,
+		TrackSerial: product.TrackSerial,
 	})
 	if err != nil {
 		return Product{}, err
 	}
+	if err := saveConversions(ctx, tx, row.ID, product.Conversions); err != nil {
+		return Product{}, err
+	}
+	if err := saveInspectionFlag(ctx, tx, row.ID, product.RequiresInspection); err != nil {
+		return Product{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Product{}, err
+	}
 
 	product.ID = row.ID
+	if row.UpdatedAt.Valid {
+		product.UpdatedAt = row.UpdatedAt.Time
+	}
 	return product, nil
 }
 
-// Update uses sqlc generated query
+// Update uses sqlc generated query. A non-zero product.UpdatedAt is the
+// version the caller loaded; the update fails with ErrStaleRecord if the row
+// has changed since.
 func (r *repository) Update(ctx context.Context, id int64, product Product) error {
 	priceStr := strconv.FormatFloat(product.Price, 'f', 2, 64)
 	var price pgtype.Numeric
@@ -198,16 +260,50 @@ func (r *repository) Update(ctx context.Context, id int64, product Product) erro
 		taxID = pgtype.Int8{Int64: product.TaxID, Valid: true}
 	}
 
-	return r.queries.UpdateProduct(ctx, sqlc.UpdateProductParams{
-		Sku:        product.Code, // map code -> sku
-		Name:       product.Name,
-		CategoryID: product.CategoryID,
-		UnitID:     product.UnitID,
-		Price:      price,
-		TaxID:      taxID,
-		IsActive:   product.IsActive,
-		ID:         id,
+	attrs, err := encodeAttributes(product.Attributes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := r.queries.WithTx(tx).UpdateProduct(ctx, sqlc.UpdateProductParams{
+		Sku:               product.Code, // map code -> sku
+		Name:              product.Name,
+		CategoryID:        product.CategoryID,
+		UnitID:            product.UnitID,
+		Price:             price,
+		TaxID:             taxID,
+		IsActive:          product.IsActive,
+		Attributes:        attrs,
+		TrackSerial:       product.TrackSerial,
+		ID:                id,
+		ExpectedUpdatedAt: pgtype.Timestamptz{Time: product.UpdatedAt, Valid: !product.UpdatedAt.IsZero()},
 	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 && !product.UpdatedAt.IsZero() {
+		return internalShared.ErrStaleRecord
+	}
+	if err := saveConversions(ctx, tx, id, product.Conversions); err != nil {
+		return err
+	}
+	if err := saveInspectionFlag(ctx, tx, id, product.RequiresInspection); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// saveInspectionFlag stores requires_inspection, which postdates the
+// generated product queries.
+func saveInspectionFlag(ctx context.Context, tx pgx.Tx, id int64, requires bool) error {
+	_, err := tx.Exec(ctx, `UPDATE products SET requires_inspection = $2 WHERE id = $1`, id, requires)
+	return err
 }
 
 // Delete uses sqlc generated query
@@ -215,6 +311,70 @@ func (r *repository) Delete(ctx context.Context, id int64) error {
 	return r.queries.DeleteProduct(ctx, id)
 }
 
+// UnitFactors maps the product's unit codes, base and alternate, to the
+// number of base units they hold.
+func (r *repository) UnitFactors(ctx context.Context, id int64) (map[string]float64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT u.code, 1::float8 FROM products p JOIN units u ON u.id = p.unit_id WHERE p.id = $1
+		UNION ALL
+		SELECT u.code, c.base_factor::float8
+		FROM product_unit_conversions c JOIN units u ON u.id = c.unit_id
+		WHERE c.product_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	factors := make(map[string]float64)
+	for rows.Next() {
+		var code string
+		var factor float64
+		if err := rows.Scan(&code, &factor); err != nil {
+			return nil, err
+		}
+		factors[strings.ToUpper(code)] = factor
+	}
+	return factors, rows.Err()
+}
+
+func (r *repository) conversions(ctx context.Context, id int64) ([]UnitConversion, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.unit_id, u.code, c.per_unit_id, pu.code, c.factor::float8, c.base_factor::float8
+		FROM product_unit_conversions c
+		JOIN units u ON u.id = c.unit_id
+		JOIN units pu ON pu.id = c.per_unit_id
+		WHERE c.product_id = $1
+		ORDER BY c.base_factor, u.code`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var conversions []UnitConversion
+	for rows.Next() {
+		var c UnitConversion
+		if err := rows.Scan(&c.UnitID, &c.UnitCode, &c.PerUnitID, &c.PerUnitCode, &c.Factor, &c.BaseFactor); err != nil {
+			return nil, err
+		}
+		conversions = append(conversions, c)
+	}
+	return conversions, rows.Err()
+}
+
+// saveConversions replaces the product's conversions with the resolved set.
+func saveConversions(ctx context.Context, tx pgx.Tx, productID int64, conversions []UnitConversion) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM product_unit_conversions WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+	for _, c := range conversions {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO product_unit_conversions (product_id, unit_id, per_unit_id, factor, base_factor)
+			VALUES ($1, $2, $3, $4, $5)`, productID, c.UnitID, c.PerUnitID, c.Factor, c.BaseFactor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func sortOrder(sortBy, sortDir string) string {
 	dir := "ASC"
 	if sortDir == "desc" {
@@ -231,3 +391,32 @@ func sortOrder(sortBy, sortDir string) string {
 		return "name " + dir
 	}
 }
+
+// attributeKeys returns the filter keys in a stable order so generated SQL
+// is deterministic.
+func attributeKeys(attrs map[string]string) []string {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func encodeAttributes(attrs map[string]any) ([]byte, error) {
+	if attrs == nil {
+		attrs = map[string]any{}
+	}
+	return json.Marshal(attrs)
+}
+
+func decodeAttributes(raw []byte) (map[string]any, error) {
+	attrs := map[string]any{}
+	if len(raw) == 0 {
+		return attrs, nil
+	}
+	if err := json.Unmarshal(raw, &attrs); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
@@ -3,22 +3,78 @@ package products
 import (
 	"context"
 	"errors"
+	"strconv"
+	"strings"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/categories"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
+// CategoryLookup resolves the category whose attribute schema governs a product.
+type CategoryLookup interface {
+	Get(ctx context.Context, id int64) (categories.Category, error)
+}
+
+// AuditPort records product changes.
+type AuditPort interface {
+	Record(ctx context.Context, log internalShared.AuditLog) error
+}
+
 type Service struct {
-	repo Repository
+	repo       Repository
+	categories CategoryLookup
+	audit      AuditPort
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, categories CategoryLookup) *Service {
+	return &Service{repo: repo, categories: categories}
 }
 
+// List returns products matching the filters. Attribute filters are only
+// applied when a category is selected: text attributes match partially, other
+// types match the whole value, and keys outside the schema are ignored.
 func (s *Service) List(ctx context.Context, filters shared.ListFilters) ([]Product, int, error) {
+	if len(filters.Attributes) > 0 {
+		attrs, err := s.attributeFilters(ctx, filters)
+		if err != nil {
+			return nil, 0, err
+		}
+		filters.Attributes = attrs
+	}
 	return s.repo.List(ctx, filters)
 }
 
+func (s *Service) attributeFilters(ctx context.Context, filters shared.ListFilters) (map[string]string, error) {
+	if filters.CategoryID == nil {
+		return nil, nil
+	}
+	category, err := s.categories.Get(ctx, *filters.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(filters.Attributes))
+	for _, def := range category.AttributeSchema {
+		value := strings.TrimSpace(filters.Attributes[def.Key])
+		if value == "" {
+			continue
+		}
+		value = likeEscaper.Replace(value)
+		if def.Type == categories.AttributeText {
+			value = "%" + value + "%"
+		}
+		out[def.Key] = value
+	}
+	return out, nil
+}
+
+// SetAuditor enables before/after audit snapshots on updates.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (s *Service) Get(ctx context.Context, id int64) (Product, error) {
 	if id <= 0 {
 		return Product{}, errors.New("invalid product ID")
@@ -27,7 +83,7 @@ func (s *Service) Get(ctx context.Context, id int64) (Product, error) {
 }
 
 func (s *Service) Create(ctx context.Context, product Product) (Product, error) {
-	if err := s.validate(product); err != nil {
+	if err := s.validate(ctx, &product); err != nil {
 		return Product{}, err
 	}
 	return s.repo.Create(ctx, product)
@@ -37,10 +93,36 @@ func (s *Service) Update(ctx context.Context, id int64, product Product) error {
 	if id <= 0 {
 		return errors.New("invalid product ID")
 	}
-	if err := s.validate(product); err != nil {
+	if err := s.validate(ctx, &product); err != nil {
+		return err
+	}
+	if s.audit == nil {
+		return s.repo.Update(ctx, id, product)
+	}
+	before, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !product.UpdatedAt.IsZero() && !product.UpdatedAt.Equal(before.UpdatedAt) {
+		return internalShared.ErrStaleRecord
+	}
+	if err := s.repo.Update(ctx, id, product); err != nil {
+		return err
+	}
+	after, err := s.repo.Get(ctx, id)
+	if err != nil {
 		return err
 	}
-	return s.repo.Update(ctx, id, product)
+	_ = s.audit.Record(ctx, internalShared.AuditLog{
+		ActorID:  internalShared.AuditActorFromContext(ctx),
+		Action:   "product.update",
+		Entity:   "products",
+		EntityID: strconv.FormatInt(id, 10),
+		Meta:     map[string]any{"code": after.Code},
+		Before:   before,
+		After:    after,
+	})
+	return nil
 }
 
 func (s *Service) Delete(ctx context.Context, id int64) error {
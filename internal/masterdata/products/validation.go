@@ -1,16 +1,41 @@
 package products
 
 import (
+	"context"
 	"errors"
 	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/categories"
 )
 
-func (s *Service) validate(p Product) error {
+func (s *Service) validate(ctx context.Context, p *Product) error {
 	if strings.TrimSpace(p.Code) == "" {
 		return errors.New("product code is required")
 	}
 	if strings.TrimSpace(p.Name) == "" {
 		return errors.New("product name is required")
 	}
+	if err := ResolveConversions(p.UnitID, p.Conversions); err != nil {
+		return err
+	}
+	return s.validateAttributes(ctx, p)
+}
+
+// validateAttributes checks the product's attributes against its category
+// schema and replaces them with the typed values to store.
+func (s *Service) validateAttributes(ctx context.Context, p *Product) error {
+	var schema []categories.AttributeDefinition
+	if p.CategoryID > 0 {
+		category, err := s.categories.Get(ctx, p.CategoryID)
+		if err != nil {
+			return err
+		}
+		schema = category.AttributeSchema
+	}
+	attrs, err := categories.ValidateAttributes(schema, p.Attributes)
+	if err != nil {
+		return err
+	}
+	p.Attributes = attrs
 	return nil
 }
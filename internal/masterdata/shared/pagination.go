@@ -13,4 +13,6 @@ type ListFilters struct {
 	CompanyID  *int64
 	BranchID   *int64
 	CategoryID *int64
+	// Attributes filters products by custom attribute key and value
+	Attributes map[string]string
 }
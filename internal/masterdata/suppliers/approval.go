@@ -0,0 +1,130 @@
+package suppliers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ErrBlockReasonRequired rejects blocking a supplier without saying why.
+var ErrBlockReasonRequired = errors.New("a reason is required to block a supplier")
+
+// Approve clears a pending or blocked supplier for purchasing.
+func (s *Service) Approve(ctx context.Context, id int64, note string) error {
+	return s.setApproval(ctx, id, ApprovalApproved, note)
+}
+
+// Block stops new purchase orders for the supplier until it is approved
+// again. Orders already raised are left as they are.
+func (s *Service) Block(ctx context.Context, id int64, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrBlockReasonRequired
+	}
+	return s.setApproval(ctx, id, ApprovalBlocked, reason)
+}
+
+func (s *Service) setApproval(ctx context.Context, id int64, status ApprovalStatus, note string) error {
+	if id <= 0 {
+		return errors.New("invalid supplier ID")
+	}
+	before, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	actorID := internalShared.AuditActorFromContext(ctx)
+	if err := s.repo.SetApprovalStatus(ctx, id, status, strings.TrimSpace(note), actorID); err != nil {
+		return err
+	}
+	if s.audit == nil {
+		return nil
+	}
+	action := "supplier.approve"
+	if status == ApprovalBlocked {
+		action = "supplier.block"
+	}
+	_ = s.audit.Record(ctx, internalShared.AuditLog{
+		ActorID:  actorID,
+		Action:   action,
+		Entity:   "suppliers",
+		EntityID: strconv.FormatInt(id, 10),
+		Meta: map[string]any{
+			"code": before.Code,
+			"from": string(before.ApprovalStatus),
+			"to":   string(status),
+			"note": strings.TrimSpace(note),
+		},
+	})
+	return nil
+}
+
+// SetDefaultTax sets the input tax applied to AP invoices created from the
+// supplier's receipts and orders; nil clears it.
+func (s *Service) SetDefaultTax(ctx context.Context, id int64, taxID *int64) error {
+	if id <= 0 {
+		return errors.New("invalid supplier ID")
+	}
+	before, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetDefaultTax(ctx, id, taxID); err != nil {
+		return err
+	}
+	if s.audit == nil {
+		return nil
+	}
+	after, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	_ = s.audit.Record(ctx, internalShared.AuditLog{
+		ActorID:  internalShared.AuditActorFromContext(ctx),
+		Action:   "supplier.default_tax",
+		Entity:   "suppliers",
+		EntityID: strconv.FormatInt(id, 10),
+		Meta: map[string]any{
+			"code": before.Code,
+			"from": before.DefaultTaxCode,
+			"to":   after.DefaultTaxCode,
+		},
+	})
+	return nil
+}
+
+// PurchaseTaxes lists the taxes offered as a supplier's default tax.
+func (s *Service) PurchaseTaxes(ctx context.Context) ([]internalShared.TaxRate, error) {
+	return s.repo.ListPurchaseTaxes(ctx)
+}
+
+// SetRequiresInspection turns the quality inspection hold on or off for
+// future receipts from the supplier.
+func (s *Service) SetRequiresInspection(ctx context.Context, id int64, requires bool) error {
+	if id <= 0 {
+		return errors.New("invalid supplier ID")
+	}
+	before, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetRequiresInspection(ctx, id, requires); err != nil {
+		return err
+	}
+	if s.audit == nil {
+		return nil
+	}
+	_ = s.audit.Record(ctx, internalShared.AuditLog{
+		ActorID:  internalShared.AuditActorFromContext(ctx),
+		Action:   "supplier.inspection",
+		Entity:   "suppliers",
+		EntityID: strconv.FormatInt(id, 10),
+		Meta: map[string]any{
+			"code": before.Code,
+			"from": before.RequiresInspection,
+			"to":   requires,
+		},
+	})
+	return nil
+}
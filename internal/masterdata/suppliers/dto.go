@@ -6,4 +6,8 @@ type SupplierForm struct {
 	Address string `json:"address"`
 	Email   string `json:"email"`
 	Phone   string `json:"phone"`
+	// PaymentTermsDays is the supplier's net terms, 0 to 365 days.
+	PaymentTermsDays int `json:"payment_terms_days"`
+	// WithholdingTaxID is the supplier's default withholding tax, if any.
+	WithholdingTaxID *int64 `json:"withholding_tax_id"`
 }
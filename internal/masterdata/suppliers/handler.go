@@ -1,13 +1,16 @@
 package suppliers
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -46,8 +49,8 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	suppliers, total, err := h.service.List(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list suppliers failed", "error", err)
-		http.Error(w, "Failed to load suppliers", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list suppliers failed", "error", err)
+		httpx.Error(w, r, "Failed to load suppliers", http.StatusInternalServerError)
 		return
 	}
 
@@ -61,20 +64,62 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid supplier ID", http.StatusBadRequest)
 		return
 	}
 
 	supplier, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get supplier failed", "error", err, "id", id)
-		http.Error(w, "Supplier not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get supplier failed", "error", err, "id", id)
+		httpx.Error(w, r, "Supplier not found", http.StatusNotFound)
 		return
 	}
 
-	h.render(w, r, "pages/masterdata/supplier_detail.html", map[string]any{
+	taxes, err := h.service.PurchaseTaxes(r.Context())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "list purchase taxes failed", "error", err)
+	}
+	data := map[string]any{
 		"Supplier": supplier,
-	}, http.StatusOK)
+		"Tab":      "details",
+		"Taxes":    taxes,
+	}
+	if r.URL.Query().Get("tab") == "activity" {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		activity, err := h.service.Activity(r.Context(), id, page, internalShared.ActivityPerPage)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "load supplier activity failed", "error", err, "id", id)
+			httpx.Error(w, r, "Failed to load activity", http.StatusInternalServerError)
+			return
+		}
+		data["Tab"] = "activity"
+		data["Activity"] = activity
+		data["ActivityBase"] = "/masterdata/suppliers/" + strconv.FormatInt(id, 10)
+	}
+
+	h.render(w, r, "pages/masterdata/supplier_detail.html", data, http.StatusOK)
+}
+
+// Activity returns one page of the supplier's document timeline as JSON.
+func (h *Handler) Activity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid supplier", "supplier id must be a number")
+		return
+	}
+	if _, err := h.service.Get(r.Context(), id); err != nil {
+		httpx.Problem(w, http.StatusNotFound, "Supplier not found", "")
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	activity, err := h.service.Activity(r.Context(), id, page, perPage)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load supplier activity failed", "error", err, "id", id)
+		httpx.Problem(w, http.StatusInternalServerError, "Failed to load activity", "")
+		return
+	}
+	httpx.JSON(w, http.StatusOK, activity)
 }
 
 func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
@@ -86,23 +131,26 @@ func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
-	supplier := Supplier{
-		Code:    r.PostFormValue("code"),
-		Name:    r.PostFormValue("name"),
-		Address: r.PostFormValue("address"),
-		Email:   r.PostFormValue("email"),
-		Phone:   r.PostFormValue("phone"),
-	}
+	supplier := supplierFromForm(r)
+	supplier.ConfirmDuplicate = r.PostFormValue("confirm_duplicate") == "true"
 
 	created, err := h.service.Create(r.Context(), supplier)
+	var dupErr *internalShared.DuplicateError
+	if errors.As(err, &dupErr) {
+		h.render(w, r, "pages/masterdata/supplier_duplicates.html", map[string]any{
+			"Candidates": dupErr.Candidates,
+			"Fields":     internalShared.ResubmitFields(r.PostForm, "confirm_duplicate"),
+		}, http.StatusConflict)
+		return
+	}
 	if err != nil {
-		h.logger.Error("create supplier failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create supplier failed", "error", err)
 		h.render(w, r, "pages/masterdata/supplier_form.html", map[string]any{
-			"Errors":   map[string]string{"general": internalShared.UserSafeMessage(err)},
+			"Errors":   supplierFormErrors(err),
 			"Supplier": nil,
 		}, http.StatusBadRequest)
 		return
@@ -114,14 +162,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid supplier ID", http.StatusBadRequest)
 		return
 	}
 
 	supplier, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get supplier failed", "error", err, "id", id)
-		http.Error(w, "Supplier not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get supplier failed", "error", err, "id", id)
+		httpx.Error(w, r, "Supplier not found", http.StatusNotFound)
 		return
 	}
 
@@ -134,28 +182,22 @@ func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid supplier ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
-	supplier := Supplier{
-		Code:    r.PostFormValue("code"),
-		Name:    r.PostFormValue("name"),
-		Address: r.PostFormValue("address"),
-		Email:   r.PostFormValue("email"),
-		Phone:   r.PostFormValue("phone"),
-	}
+	supplier := supplierFromForm(r)
 
 	err = h.service.Update(r.Context(), id, supplier)
 	if err != nil {
-		h.logger.Error("update supplier failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update supplier failed", "error", err, "id", id)
 		h.render(w, r, "pages/masterdata/supplier_form.html", map[string]any{
-			"Errors":   map[string]string{"general": internalShared.UserSafeMessage(err)},
+			"Errors":   supplierFormErrors(err),
 			"Supplier": supplier,
 		}, http.StatusBadRequest)
 		return
@@ -167,13 +209,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid supplier ID", http.StatusBadRequest)
 		return
 	}
 
 	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error("delete supplier failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "delete supplier failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/masterdata/suppliers", "error", internalShared.UserSafeMessage(err))
 		return
 	}
@@ -181,6 +223,147 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.redirectWithFlash(w, r, "/masterdata/suppliers", "success", "Supplier deleted successfully")
 }
 
+// Approve clears the supplier for purchase orders.
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.changeApproval(w, r, ApprovalApproved)
+}
+
+// Block stops new purchase orders for the supplier.
+func (h *Handler) Block(w http.ResponseWriter, r *http.Request) {
+	h.changeApproval(w, r, ApprovalBlocked)
+}
+
+func (h *Handler) changeApproval(w http.ResponseWriter, r *http.Request, status ApprovalStatus) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+	location := "/masterdata/suppliers/" + strconv.FormatInt(id, 10)
+	note := r.PostFormValue("note")
+	message := "Supplier approved"
+	if status == ApprovalBlocked {
+		err = h.service.Block(r.Context(), id, note)
+		message = "Supplier blocked"
+	} else {
+		err = h.service.Approve(r.Context(), id, note)
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "change supplier approval failed", "error", err, "id", id, "status", status)
+		msg := internalShared.UserSafeMessage(err)
+		if errors.Is(err, ErrBlockReasonRequired) {
+			msg = err.Error()
+		}
+		h.redirectWithFlash(w, r, location, "error", msg)
+		return
+	}
+	h.redirectWithFlash(w, r, location, "success", message)
+}
+
+// SetInspection turns the quality inspection hold on receipts on or off.
+func (h *Handler) SetInspection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+	location := "/masterdata/suppliers/" + strconv.FormatInt(id, 10)
+	requires := r.PostFormValue("requires_inspection") == "on"
+	if err := h.service.SetRequiresInspection(r.Context(), id, requires); err != nil {
+		h.logger.ErrorContext(r.Context(), "set supplier inspection failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, location, "error", internalShared.UserSafeMessage(err))
+		return
+	}
+	message := "Receipts from this supplier are no longer held for inspection"
+	if requires {
+		message = "Receipts from this supplier will be held for quality inspection"
+	}
+	h.redirectWithFlash(w, r, location, "success", message)
+}
+
+// SetDefaultTax sets or clears the supplier's default input tax.
+func (h *Handler) SetDefaultTax(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+	location := "/masterdata/suppliers/" + strconv.FormatInt(id, 10)
+	var taxID *int64
+	if v, err := strconv.ParseInt(r.PostFormValue("default_tax_id"), 10, 64); err == nil && v > 0 {
+		taxID = &v
+	}
+	if err := h.service.SetDefaultTax(r.Context(), id, taxID); err != nil {
+		h.logger.ErrorContext(r.Context(), "set supplier default tax failed", "error", err, "id", id)
+		msg := internalShared.UserSafeMessage(err)
+		if errors.Is(err, ErrInvalidDefaultTax) {
+			msg = err.Error()
+		}
+		h.redirectWithFlash(w, r, location, "error", msg)
+		return
+	}
+	message := "Default tax cleared; AP invoices from this supplier carry no input tax"
+	if taxID != nil {
+		message = "Default tax saved; it applies to new AP invoices from this supplier"
+	}
+	h.redirectWithFlash(w, r, location, "success", message)
+}
+
+func supplierFromForm(r *http.Request) Supplier {
+	country := strings.ToUpper(strings.TrimSpace(r.PostFormValue("country")))
+	if country == "" {
+		country = "ID"
+	}
+	paymentTerms := DefaultPaymentTermsDays
+	if raw := strings.TrimSpace(r.PostFormValue("payment_terms_days")); raw != "" {
+		if val, err := strconv.Atoi(raw); err == nil {
+			paymentTerms = val
+		} else {
+			paymentTerms = -1
+		}
+	}
+	var withholdingTaxID *int64
+	if id, err := strconv.ParseInt(strings.TrimSpace(r.PostFormValue("withholding_tax_id")), 10, 64); err == nil && id > 0 {
+		withholdingTaxID = &id
+	}
+	return Supplier{
+		Code:             r.PostFormValue("code"),
+		Name:             r.PostFormValue("name"),
+		Address:          r.PostFormValue("address"),
+		Email:            r.PostFormValue("email"),
+		Phone:            r.PostFormValue("phone"),
+		TaxID:            strings.TrimSpace(r.PostFormValue("tax_id")),
+		Country:          country,
+		TaxIDOverride:    r.PostFormValue("tax_id_override") == "true",
+		PaymentTermsDays: paymentTerms,
+		WithholdingTaxID: withholdingTaxID,
+	}
+}
+
+// supplierFormErrors keeps tax ID and withholding tax errors visible next to
+// their fields.
+func supplierFormErrors(err error) map[string]string {
+	if errors.Is(err, internalShared.ErrInvalidTaxID) {
+		return map[string]string{"general": err.Error(), "tax_id": err.Error()}
+	}
+	if errors.Is(err, ErrNotWithholdingTax) {
+		return map[string]string{"general": err.Error(), "withholding_tax_id": err.Error()}
+	}
+	return map[string]string{"general": internalShared.UserSafeMessage(err)}
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
 	sess := internalShared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -197,7 +380,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", "error", err, "template", template)
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
 	}
 }
 
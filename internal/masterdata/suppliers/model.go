@@ -1,5 +1,20 @@
 package suppliers
 
+import "time"
+
+// DefaultPaymentTermsDays matches the column default for new suppliers.
+const DefaultPaymentTermsDays = 30
+
+// ApprovalStatus tracks the vetting of a supplier. Purchase orders can only be
+// raised for approved suppliers.
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "PENDING"
+	ApprovalApproved ApprovalStatus = "APPROVED"
+	ApprovalBlocked  ApprovalStatus = "BLOCKED"
+)
+
 // Supplier represents a supplier entity
 // Note: database schema does not have created_at/updated_at columns
 type Supplier struct {
@@ -9,5 +24,30 @@ type Supplier struct {
 	Address  string `json:"address"`
 	Email    string `json:"email"`
 	Phone    string `json:"phone"`
+	TaxID    string `json:"tax_id"`
+	Country  string `json:"country"`
 	IsActive bool   `json:"is_active"`
+	// PaymentTermsDays is the default net terms used to date AP invoices.
+	PaymentTermsDays int `json:"payment_terms_days"`
+	// WithholdingTaxID is the withholding tax (PPh) deducted from payments to
+	// the supplier; nil when payments are made in full.
+	WithholdingTaxID   *int64 `json:"withholding_tax_id"`
+	WithholdingTaxCode string `json:"withholding_tax_code"`
+	// DefaultTaxID is the input tax (e.g. PPN) applied to AP invoices created
+	// from the supplier's receipts and orders; it is set from the detail
+	// page, not the edit form.
+	DefaultTaxID   *int64 `json:"default_tax_id"`
+	DefaultTaxCode string `json:"default_tax_code"`
+	// ApprovalStatus is set by the approve/block workflow, not the edit form.
+	ApprovalStatus    ApprovalStatus `json:"approval_status"`
+	ApprovalNote      string         `json:"approval_note"`
+	ApprovalChangedAt *time.Time     `json:"approval_changed_at"`
+	// RequiresInspection holds every receipt from the supplier in quality
+	// inspection; it is set from the detail page, not the edit form.
+	RequiresInspection bool `json:"requires_inspection"`
+	// TaxIDOverride skips the country tax ID format check; it is not stored.
+	TaxIDOverride bool `json:"-"`
+	// ConfirmDuplicate creates the supplier even when similar ones exist; it
+	// is not stored.
+	ConfirmDuplicate bool `json:"-"`
 }
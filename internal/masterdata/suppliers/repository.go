@@ -4,8 +4,12 @@ import (
 	"context"
 	"strconv"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -15,6 +19,16 @@ type Repository interface {
 	Create(ctx context.Context, supplier Supplier) (Supplier, error)
 	Update(ctx context.Context, id int64, supplier Supplier) error
 	Delete(ctx context.Context, id int64) error
+	FindSimilar(ctx context.Context, name, taxID, phone string, minSimilarity float64, limit int) ([]internalShared.DuplicateCandidate, error)
+	ListActivity(ctx context.Context, supplierID int64, limit, offset int) ([]internalShared.ActivityEvent, error)
+	// IsWithholdingTax reports whether the tax exists and is a withholding tax.
+	IsWithholdingTax(ctx context.Context, taxID int64) (bool, error)
+	SetApprovalStatus(ctx context.Context, id int64, status ApprovalStatus, note string, actorID int64) error
+	SetRequiresInspection(ctx context.Context, id int64, requires bool) error
+	// ListPurchaseTaxes returns the taxes that are not withheld, at their
+	// current rate.
+	ListPurchaseTaxes(ctx context.Context) ([]internalShared.TaxRate, error)
+	SetDefaultTax(ctx context.Context, id int64, taxID *int64) error
 }
 
 type repository struct {
@@ -31,7 +45,9 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 
 // List uses dynamic query (not sqlc) due to filter complexity
 func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Supplier, int, error) {
-	query := `SELECT id, code, name, address, email, phone, is_active FROM suppliers WHERE 1=1`
+	query := `SELECT id, code, name, address, email, phone, tax_id, country, is_active, payment_terms_days, withholding_tax_id,
+		COALESCE((SELECT t.code FROM taxes t WHERE t.id = suppliers.withholding_tax_id), ''),
+		approval_status, approval_note, approval_changed_at FROM suppliers WHERE 1=1`
 	args := []interface{}{}
 	argCount := 0
 
@@ -80,7 +96,8 @@ func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Su
 	var suppliers []Supplier
 	for rows.Next() {
 		var s Supplier
-		err := rows.Scan(&s.ID, &s.Code, &s.Name, &s.Address, &s.Email, &s.Phone, &s.IsActive)
+		err := rows.Scan(&s.ID, &s.Code, &s.Name, &s.Address, &s.Email, &s.Phone, &s.TaxID, &s.Country, &s.IsActive, &s.PaymentTermsDays, &s.WithholdingTaxID, &s.WithholdingTaxCode,
+			&s.ApprovalStatus, &s.ApprovalNote, &s.ApprovalChangedAt)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -95,26 +112,48 @@ func (r *repository) Get(ctx context.Context, id int64) (Supplier, error) {
 	if err != nil {
 		return Supplier{}, err
 	}
-	return Supplier{
-		ID:       row.ID,
-		Code:     row.Code,
-		Name:     row.Name,
-		Phone:    row.Phone,
-		Email:    row.Email,
-		Address:  row.Address,
-		IsActive: row.IsActive,
-	}, nil
+	supplier := Supplier{
+		ID:               row.ID,
+		Code:             row.Code,
+		Name:             row.Name,
+		Phone:            row.Phone,
+		Email:            row.Email,
+		Address:          row.Address,
+		TaxID:            row.TaxID,
+		Country:          row.Country,
+		IsActive:         row.IsActive,
+		PaymentTermsDays: int(row.PaymentTermsDays),
+	}
+	if err := r.pool.QueryRow(ctx, `SELECT approval_status, approval_note, approval_changed_at, requires_inspection, default_tax_id,
+		COALESCE((SELECT t.code FROM taxes t WHERE t.id = suppliers.default_tax_id), '')
+		FROM suppliers WHERE id = $1`, id).
+		Scan(&supplier.ApprovalStatus, &supplier.ApprovalNote, &supplier.ApprovalChangedAt, &supplier.RequiresInspection,
+			&supplier.DefaultTaxID, &supplier.DefaultTaxCode); err != nil {
+		return Supplier{}, err
+	}
+	if row.WithholdingTaxID.Valid {
+		taxID := row.WithholdingTaxID.Int64
+		supplier.WithholdingTaxID = &taxID
+		if err := r.pool.QueryRow(ctx, `SELECT code FROM taxes WHERE id = $1`, taxID).Scan(&supplier.WithholdingTaxCode); err != nil {
+			return Supplier{}, err
+		}
+	}
+	return supplier, nil
 }
 
 // Create uses sqlc generated query
 func (r *repository) Create(ctx context.Context, supplier Supplier) (Supplier, error) {
 	row, err := r.queries.CreateSupplier(ctx, sqlc.CreateSupplierParams{
-		Code:     supplier.Code,
-		Name:     supplier.Name,
-		Phone:    supplier.Phone,
-		Email:    supplier.Email,
-		Address:  supplier.Address,
-		IsActive: supplier.IsActive,
+		Code:             supplier.Code,
+		Name:             supplier.Name,
+		Phone:            supplier.Phone,
+		Email:            supplier.Email,
+		Address:          supplier.Address,
+		IsActive:         supplier.IsActive,
+		TaxID:            supplier.TaxID,
+		Country:          supplier.Country,
+		PaymentTermsDays: int32(supplier.PaymentTermsDays),
+		WithholdingTaxID: nullTaxID(supplier.WithholdingTaxID),
 	})
 	if err != nil {
 		return Supplier{}, err
@@ -127,16 +166,106 @@ func (r *repository) Create(ctx context.Context, supplier Supplier) (Supplier, e
 // Update uses sqlc generated query
 func (r *repository) Update(ctx context.Context, id int64, supplier Supplier) error {
 	return r.queries.UpdateSupplier(ctx, sqlc.UpdateSupplierParams{
-		Code:     supplier.Code,
-		Name:     supplier.Name,
-		Phone:    supplier.Phone,
-		Email:    supplier.Email,
-		Address:  supplier.Address,
-		IsActive: supplier.IsActive,
-		ID:       id,
+		Code:             supplier.Code,
+		Name:             supplier.Name,
+		Phone:            supplier.Phone,
+		Email:            supplier.Email,
+		Address:          supplier.Address,
+		IsActive:         supplier.IsActive,
+		TaxID:            supplier.TaxID,
+		Country:          supplier.Country,
+		ID:               id,
+		PaymentTermsDays: int32(supplier.PaymentTermsDays),
+		WithholdingTaxID: nullTaxID(supplier.WithholdingTaxID),
 	})
 }
 
+// IsWithholdingTax uses a raw query; taxes are global, not per company.
+func (r *repository) IsWithholdingTax(ctx context.Context, taxID int64) (bool, error) {
+	var ok bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM taxes WHERE id = $1 AND is_withholding)`, taxID).Scan(&ok)
+	return ok, err
+}
+
+// SetApprovalStatus uses a raw query; the sqlc supplier queries predate the
+// approval columns.
+func (r *repository) SetApprovalStatus(ctx context.Context, id int64, status ApprovalStatus, note string, actorID int64) error {
+	var actor pgtype.Int8
+	if actorID > 0 {
+		actor = pgtype.Int8{Int64: actorID, Valid: true}
+	}
+	tag, err := r.pool.Exec(ctx, `UPDATE suppliers
+SET approval_status = $2, approval_note = $3, approval_changed_by = $4, approval_changed_at = NOW()
+WHERE id = $1`, id, string(status), note, actor)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// SetRequiresInspection uses a raw query for the same reason as
+// SetApprovalStatus.
+func (r *repository) SetRequiresInspection(ctx context.Context, id int64, requires bool) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE suppliers SET requires_inspection = $2 WHERE id = $1`, id, requires)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ListPurchaseTaxes uses a raw query; taxes are global, not per company.
+func (r *repository) ListPurchaseTaxes(ctx context.Context) ([]internalShared.TaxRate, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, code, name, rate::float8 FROM taxes WHERE NOT is_withholding ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var taxes []internalShared.TaxRate
+	for rows.Next() {
+		var tax internalShared.TaxRate
+		if err := rows.Scan(&tax.TaxID, &tax.Code, &tax.Name, &tax.Rate); err != nil {
+			return nil, err
+		}
+		taxes = append(taxes, tax)
+	}
+	return taxes, rows.Err()
+}
+
+// SetDefaultTax uses a raw query for the same reason as SetApprovalStatus.
+// Only taxes that are not withheld are accepted.
+func (r *repository) SetDefaultTax(ctx context.Context, id int64, taxID *int64) error {
+	if taxID != nil {
+		var ok bool
+		if err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM taxes WHERE id = $1 AND NOT is_withholding)`, *taxID).Scan(&ok); err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidDefaultTax
+		}
+	}
+	tag, err := r.pool.Exec(ctx, `UPDATE suppliers SET default_tax_id = $2 WHERE id = $1`, id, nullTaxID(taxID))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func nullTaxID(id *int64) pgtype.Int8 {
+	if id == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *id, Valid: true}
+}
+
 // Delete uses sqlc generated query
 func (r *repository) Delete(ctx context.Context, id int64) error {
 	return r.queries.DeleteSupplier(ctx, id)
@@ -156,3 +285,78 @@ func sortOrder(sortBy, sortDir string) string {
 		return "name " + dir
 	}
 }
+
+// FindSimilar uses a raw query for pg_trgm similarity. taxID and phone must
+// already be normalized with internalShared.NormalizeIdentifier. The name
+// match uses the % operator so the trigram index applies; minSimilarity is
+// set as pg_trgm.similarity_threshold for the query's transaction only.
+func (r *repository) FindSimilar(ctx context.Context, name, taxID, phone string, minSimilarity float64, limit int) ([]internalShared.DuplicateCandidate, error) {
+	var candidates []internalShared.DuplicateCandidate
+	err := db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1::float8::text, true)`, minSimilarity); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, `SELECT id, code, name, tax_id, phone, similarity(name, $1)::float8 AS score
+FROM suppliers
+WHERE name % $1
+   OR ($2 <> '' AND regexp_replace(lower(tax_id), '[^a-z0-9]', '', 'g') = $2)
+   OR ($3 <> '' AND regexp_replace(lower(phone), '[^a-z0-9]', '', 'g') = $3)
+ORDER BY score DESC, id
+LIMIT $4`, name, taxID, phone, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c internalShared.DuplicateCandidate
+			if err := rows.Scan(&c.ID, &c.Code, &c.Name, &c.TaxID, &c.Phone, &c.Similarity); err != nil {
+				return err
+			}
+			candidates = append(candidates, c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// ListActivity returns the supplier's purchase orders, goods receipts, AP
+// invoices and AP payments, newest first.
+func (r *repository) ListActivity(ctx context.Context, supplierID int64, limit, offset int) ([]internalShared.ActivityEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT type, id, occurred_at, reference, status, amount, note, link_type, link_id
+		FROM (
+			SELECT 'PURCHASE_ORDER' AS type, po.id, po.created_at AS occurred_at, po.number AS reference,
+			       po.status AS status,
+			       (SELECT COALESCE(SUM(l.qty * l.price), 0) FROM po_lines l WHERE l.po_id = po.id)::float8 AS amount,
+			       po.note AS note, 'PURCHASE_ORDER' AS link_type, po.id AS link_id
+			FROM pos po
+			WHERE po.supplier_id = $1
+			UNION ALL
+			SELECT 'GOODS_RECEIPT', g.id, g.received_at, g.number, g.status, NULL::float8, g.note,
+			       'GOODS_RECEIPT', g.id
+			FROM grns g
+			WHERE g.supplier_id = $1
+			UNION ALL
+			SELECT 'AP_INVOICE', i.id, i.issued_at::timestamptz, i.number, i.status, i.total::float8, '',
+			       'AP_INVOICE', i.id
+			FROM ap_invoices i
+			WHERE i.supplier_id = $1
+			UNION ALL
+			SELECT 'AP_PAYMENT', p.id, p.paid_at::timestamptz, p.number, p.method, p.amount::float8, p.note,
+			       'AP_PAYMENT', p.id
+			FROM ap_payments p
+			LEFT JOIN ap_invoices i ON i.id = p.ap_invoice_id
+			WHERE COALESCE(p.supplier_id, i.supplier_id) = $1
+		) events
+		ORDER BY occurred_at DESC, type, id DESC
+		LIMIT $2 OFFSET $3
+	`, supplierID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return internalShared.ScanActivity(rows)
+}
@@ -7,6 +7,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAny("master.view"))
 		r.Get("/", h.List)
 		r.Get("/{id}", h.Show)
+		r.Get("/{id}/activity", h.Activity)
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAll("master.edit"))
@@ -15,5 +16,9 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Get("/{id}/edit", h.EditForm)
 		r.Post("/{id}/edit", h.Update)
 		r.Post("/{id}/delete", h.Delete)
+		r.Post("/{id}/approve", h.Approve)
+		r.Post("/{id}/block", h.Block)
+		r.Post("/{id}/inspection", h.SetInspection)
+		r.Post("/{id}/default-tax", h.SetDefaultTax)
 	})
 }
@@ -3,16 +3,38 @@ package suppliers
 import (
 	"context"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
+// AuditPort records supplier changes.
+type AuditPort interface {
+	Record(ctx context.Context, log internalShared.AuditLog) error
+}
+
 type Service struct {
-	repo Repository
+	repo   Repository
+	audit  AuditPort
+	taxIDs *internalShared.TaxIDValidator
 }
 
 func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+	return &Service{repo: repo, taxIDs: internalShared.DefaultTaxIDValidator()}
+}
+
+// SetTaxIDValidator replaces the default per-country tax ID formats.
+func (s *Service) SetTaxIDValidator(v *internalShared.TaxIDValidator) {
+	if v != nil {
+		s.taxIDs = v
+	}
+}
+
+// SetAuditor enables before/after audit snapshots on updates.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
 }
 
 func (s *Service) List(ctx context.Context, filters shared.ListFilters) ([]Supplier, int, error) {
@@ -26,10 +48,51 @@ func (s *Service) Get(ctx context.Context, id int64) (Supplier, error) {
 	return s.repo.Get(ctx, id)
 }
 
+// Activity returns one page of the supplier's document timeline.
+func (s *Service) Activity(ctx context.Context, supplierID int64, page, perPage int) (internalShared.ActivityPage, error) {
+	page, perPage, limit, offset := internalShared.ActivityWindow(page, perPage)
+	events, err := s.repo.ListActivity(ctx, supplierID, limit, offset)
+	if err != nil {
+		return internalShared.ActivityPage{}, err
+	}
+	return internalShared.NewActivityPage(events, page, perPage), nil
+}
+
+// FindSimilar returns existing suppliers that are likely duplicates: a
+// trigram-similar name or the same tax ID or phone number.
+func (s *Service) FindSimilar(ctx context.Context, name, taxID, phone string) ([]internalShared.DuplicateCandidate, error) {
+	name = strings.TrimSpace(name)
+	taxID = internalShared.NormalizeIdentifier(taxID)
+	phone = internalShared.NormalizeIdentifier(phone)
+	if name == "" && taxID == "" && phone == "" {
+		return nil, nil
+	}
+	candidates, err := s.repo.FindSimilar(ctx, name, taxID, phone, internalShared.DuplicateNameSimilarity, internalShared.DuplicateLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		candidates[i].Explain(taxID, phone)
+	}
+	return candidates, nil
+}
+
 func (s *Service) Create(ctx context.Context, supplier Supplier) (Supplier, error) {
 	if err := s.validate(supplier); err != nil {
 		return Supplier{}, err
 	}
+	if err := s.validateWithholding(ctx, supplier); err != nil {
+		return Supplier{}, err
+	}
+	if !supplier.ConfirmDuplicate {
+		similar, err := s.FindSimilar(ctx, supplier.Name, supplier.TaxID, supplier.Phone)
+		if err != nil {
+			return Supplier{}, err
+		}
+		if len(similar) > 0 {
+			return Supplier{}, &internalShared.DuplicateError{Candidates: similar}
+		}
+	}
 	return s.repo.Create(ctx, supplier)
 }
 
@@ -40,7 +103,33 @@ func (s *Service) Update(ctx context.Context, id int64, supplier Supplier) error
 	if err := s.validate(supplier); err != nil {
 		return err
 	}
-	return s.repo.Update(ctx, id, supplier)
+	if err := s.validateWithholding(ctx, supplier); err != nil {
+		return err
+	}
+	if s.audit == nil {
+		return s.repo.Update(ctx, id, supplier)
+	}
+	before, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, id, supplier); err != nil {
+		return err
+	}
+	after, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	_ = s.audit.Record(ctx, internalShared.AuditLog{
+		ActorID:  internalShared.AuditActorFromContext(ctx),
+		Action:   "supplier.update",
+		Entity:   "suppliers",
+		EntityID: strconv.FormatInt(id, 10),
+		Meta:     map[string]any{"code": after.Code},
+		Before:   before,
+		After:    after,
+	})
+	return nil
 }
 
 func (s *Service) Delete(ctx context.Context, id int64) error {
@@ -1,10 +1,19 @@
 package suppliers
 
 import (
+	"context"
 	"errors"
 	"strings"
 )
 
+// ErrNotWithholdingTax rejects a supplier withholding tax that is not marked
+// as a withholding tax.
+var ErrNotWithholdingTax = errors.New("withholding tax must be a tax marked as withholding")
+
+// ErrInvalidDefaultTax rejects a default input tax that does not exist or is
+// a withholding tax.
+var ErrInvalidDefaultTax = errors.New("default tax must be a purchase tax, not a withholding tax")
+
 func (s *Service) validate(sup Supplier) error {
 	if strings.TrimSpace(sup.Code) == "" {
 		return errors.New("supplier code is required")
@@ -12,5 +21,27 @@ func (s *Service) validate(sup Supplier) error {
 	if strings.TrimSpace(sup.Name) == "" {
 		return errors.New("supplier name is required")
 	}
+	if sup.PaymentTermsDays < 0 || sup.PaymentTermsDays > 365 {
+		return errors.New("payment terms must be between 0 and 365 days")
+	}
+	if !sup.TaxIDOverride {
+		if err := s.taxIDs.Validate(sup.Country, sup.TaxID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) validateWithholding(ctx context.Context, sup Supplier) error {
+	if sup.WithholdingTaxID == nil {
+		return nil
+	}
+	ok, err := s.repo.IsWithholdingTax(ctx, *sup.WithholdingTaxID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotWithholdingTax
+	}
 	return nil
 }
@@ -4,4 +4,6 @@ type TaxForm struct {
 	Code string  `json:"code"`
 	Name string  `json:"name"`
 	Rate float64 `json:"rate"`
+	// IsWithholding marks a tax withheld from supplier payments.
+	IsWithholding bool `json:"is_withholding"`
 }
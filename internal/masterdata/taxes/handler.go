@@ -4,10 +4,12 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -46,8 +48,8 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	taxes, total, err := h.service.List(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list taxes failed", "error", err)
-		http.Error(w, "Failed to load taxes", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list taxes failed", "error", err)
+		httpx.Error(w, r, "Failed to load taxes", http.StatusInternalServerError)
 		return
 	}
 
@@ -61,14 +63,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid tax ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid tax ID", http.StatusBadRequest)
 		return
 	}
 
 	tax, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get tax failed", "error", err, "id", id)
-		http.Error(w, "Tax not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get tax failed", "error", err, "id", id)
+		httpx.Error(w, r, "Tax not found", http.StatusNotFound)
 		return
 	}
 
@@ -86,20 +88,21 @@ func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
 	rate, _ := strconv.ParseFloat(r.PostFormValue("rate"), 64)
 	tax := Tax{
-		Code: r.PostFormValue("code"),
-		Name: r.PostFormValue("name"),
-		Rate: rate,
+		Code:          r.PostFormValue("code"),
+		Name:          r.PostFormValue("name"),
+		Rate:          rate,
+		IsWithholding: r.PostFormValue("is_withholding") == "true",
 	}
 
 	created, err := h.service.Create(r.Context(), tax)
 	if err != nil {
-		h.logger.Error("create tax failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create tax failed", "error", err)
 		h.render(w, r, "pages/masterdata/tax_form.html", map[string]any{
 			"Errors": map[string]string{"general": internalShared.UserSafeMessage(err)},
 			"Tax":    nil,
@@ -113,14 +116,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid tax ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid tax ID", http.StatusBadRequest)
 		return
 	}
 
 	tax, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get tax failed", "error", err, "id", id)
-		http.Error(w, "Tax not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get tax failed", "error", err, "id", id)
+		httpx.Error(w, r, "Tax not found", http.StatusNotFound)
 		return
 	}
 
@@ -133,25 +136,30 @@ func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid tax ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid tax ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
 	rate, _ := strconv.ParseFloat(r.PostFormValue("rate"), 64)
 	tax := Tax{
-		Code: r.PostFormValue("code"),
-		Name: r.PostFormValue("name"),
-		Rate: rate,
+		Code:          r.PostFormValue("code"),
+		Name:          r.PostFormValue("name"),
+		Rate:          rate,
+		IsWithholding: r.PostFormValue("is_withholding") == "true",
+	}
+	// A changed rate applies to documents dated from effective_from.
+	if from, err := time.Parse("2006-01-02", r.PostFormValue("effective_from")); err == nil {
+		tax.EffectiveFrom = from
 	}
 
 	err = h.service.Update(r.Context(), id, tax)
 	if err != nil {
-		h.logger.Error("update tax failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update tax failed", "error", err, "id", id)
 		h.render(w, r, "pages/masterdata/tax_form.html", map[string]any{
 			"Errors": map[string]string{"general": internalShared.UserSafeMessage(err)},
 			"Tax":    tax,
@@ -165,13 +173,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid tax ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid tax ID", http.StatusBadRequest)
 		return
 	}
 
 	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error("delete tax failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "delete tax failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/masterdata/taxes", "error", internalShared.UserSafeMessage(err))
 		return
 	}
@@ -195,7 +203,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", "error", err, "template", template)
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
 	}
 }
 
@@ -1,9 +1,17 @@
 package taxes
 
+import "time"
+
 // Tax represents a tax configuration
 type Tax struct {
 	ID   int64   `json:"id"`
 	Code string  `json:"code"`
 	Name string  `json:"name"`
 	Rate float64 `json:"rate"`
+	// IsWithholding marks taxes such as PPh that are deducted from supplier
+	// payments rather than added to invoices.
+	IsWithholding bool `json:"is_withholding"`
+	// EffectiveFrom dates a rate change on update; zero means today. It is
+	// kept in the rate history, not on the tax.
+	EffectiveFrom time.Time `json:"-"`
 }
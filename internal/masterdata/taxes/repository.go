@@ -3,13 +3,20 @@ package taxes
 import (
 	"context"
 	"strconv"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
+// historyStart dates the first recorded rate of a tax so it covers every
+// document before the first rate change.
+var historyStart = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
 type Repository interface {
 	List(ctx context.Context, filters shared.ListFilters) ([]Tax, int, error)
 	Get(ctx context.Context, id int64) (Tax, error)
@@ -32,7 +39,7 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 
 // List uses dynamic query (not sqlc) due to filter complexity
 func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Tax, int, error) {
-	query := `SELECT id, code, name, rate FROM taxes WHERE 1=1`
+	query := `SELECT id, code, name, rate, is_withholding FROM taxes WHERE 1=1`
 	args := []interface{}{}
 	argCount := 0
 
@@ -81,7 +88,7 @@ func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Ta
 	var taxes []Tax
 	for rows.Next() {
 		var t Tax
-		err := rows.Scan(&t.ID, &t.Code, &t.Name, &t.Rate)
+		err := rows.Scan(&t.ID, &t.Code, &t.Name, &t.Rate, &t.IsWithholding)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -102,19 +109,29 @@ func (r *repository) Get(ctx context.Context, id int64) (Tax, error) {
 		rate = f8.Float64
 	}
 	return Tax{
-		ID:   row.ID,
-		Code: row.Code,
-		Name: row.Name,
-		Rate: rate,
+		ID:            row.ID,
+		Code:          row.Code,
+		Name:          row.Name,
+		Rate:          rate,
+		IsWithholding: row.IsWithholding,
 	}, nil
 }
 
-// Create uses sqlc generated query
+// Create uses sqlc generated query and starts the tax's rate history.
 func (r *repository) Create(ctx context.Context, tax Tax) (Tax, error) {
-	row, err := r.queries.CreateTax(ctx, sqlc.CreateTaxParams{
-		Code: tax.Code,
-		Name: tax.Name,
-		Rate: pgtype.Numeric{Valid: true},
+	var row sqlc.Tax
+	err := db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		row, err = r.queries.WithTx(tx).CreateTax(ctx, sqlc.CreateTaxParams{
+			Code:          tax.Code,
+			Name:          tax.Name,
+			Rate:          numericRate(tax.Rate),
+			IsWithholding: tax.IsWithholding,
+		})
+		if err != nil {
+			return err
+		}
+		return insertRate(ctx, tx, row.ID, tax.Rate, historyStart)
 	})
 	if err != nil {
 		return Tax{}, err
@@ -125,23 +142,63 @@ func (r *repository) Create(ctx context.Context, tax Tax) (Tax, error) {
 		rate = f8.Float64
 	}
 	return Tax{
-		ID:   row.ID,
-		Code: row.Code,
-		Name: row.Name,
-		Rate: rate,
+		ID:            row.ID,
+		Code:          row.Code,
+		Name:          row.Name,
+		Rate:          rate,
+		IsWithholding: row.IsWithholding,
 	}, nil
 }
 
-// Update uses sqlc generated query
+// Update uses sqlc generated query. A rate change is added to the rate
+// history from tax.EffectiveFrom, so documents dated earlier keep the old
+// rate.
 func (r *repository) Update(ctx context.Context, id int64, tax Tax) error {
-	return r.queries.UpdateTax(ctx, sqlc.UpdateTaxParams{
-		Code: tax.Code,
-		Name: tax.Name,
-		Rate: pgtype.Numeric{Valid: true},
-		ID:   id,
+	return db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		var (
+			current float64
+			history bool
+		)
+		err := tx.QueryRow(ctx, `SELECT rate::float8, EXISTS (SELECT 1 FROM tax_rates WHERE tax_id = $1)
+FROM taxes WHERE id = $1 FOR UPDATE`, id).Scan(&current, &history)
+		if err != nil {
+			return err
+		}
+		if err := r.queries.WithTx(tx).UpdateTax(ctx, sqlc.UpdateTaxParams{
+			Code:          tax.Code,
+			Name:          tax.Name,
+			Rate:          numericRate(tax.Rate),
+			IsWithholding: tax.IsWithholding,
+			ID:            id,
+		}); err != nil {
+			return err
+		}
+		if current == tax.Rate {
+			return nil
+		}
+		if !history {
+			if err := insertRate(ctx, tx, id, current, historyStart); err != nil {
+				return err
+			}
+		}
+		return insertRate(ctx, tx, id, tax.Rate, tax.EffectiveFrom)
 	})
 }
 
+// insertRate records the rate effective from the date, replacing a rate
+// already recorded for that date.
+func insertRate(ctx context.Context, tx pgx.Tx, taxID int64, rate float64, from time.Time) error {
+	_, err := tx.Exec(ctx, `INSERT INTO tax_rates (tax_id, rate, effective_from) VALUES ($1, $2, $3)
+ON CONFLICT (tax_id, effective_from) DO UPDATE SET rate = EXCLUDED.rate`, taxID, rate, from)
+	return err
+}
+
+func numericRate(rate float64) pgtype.Numeric {
+	var n pgtype.Numeric
+	_ = n.Scan(strconv.FormatFloat(rate, 'f', 2, 64))
+	return n
+}
+
 // Delete uses sqlc generated query
 func (r *repository) Delete(ctx context.Context, id int64) error {
 	return r.queries.DeleteTax(ctx, id)
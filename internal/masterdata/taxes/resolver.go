@@ -0,0 +1,70 @@
+package taxes
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// rateOn picks the latest rate effective on $2, falling back to the tax's
+// rate for taxes without history.
+const rateOn = `COALESCE((SELECT r.rate FROM tax_rates r
+	WHERE r.tax_id = t.id AND r.effective_from <= $2::date
+	ORDER BY r.effective_from DESC LIMIT 1), t.rate)::float8`
+
+// Resolver looks up customer and supplier default taxes. It implements
+// shared.DefaultTaxResolver.
+type Resolver struct {
+	pool *pgxpool.Pool
+}
+
+// NewResolver builds a default tax resolver on the pool.
+func NewResolver(pool *pgxpool.Pool) *Resolver {
+	return &Resolver{pool: pool}
+}
+
+// CustomerDefaultTax returns the customer's default output tax at the rate
+// effective on the date, nil when none is set.
+func (r *Resolver) CustomerDefaultTax(ctx context.Context, customerID int64, on time.Time) (*shared.TaxRate, error) {
+	return r.lookup(ctx, `SELECT t.id, t.code, t.name, `+rateOn+`
+FROM customers c JOIN taxes t ON t.id = c.default_tax_id
+WHERE c.id = $1`, customerID, on)
+}
+
+// SupplierDefaultTax returns the supplier's default input tax at the rate
+// effective on the date, nil when none is set.
+func (r *Resolver) SupplierDefaultTax(ctx context.Context, supplierID int64, on time.Time) (*shared.TaxRate, error) {
+	return r.lookup(ctx, `SELECT t.id, t.code, t.name, `+rateOn+`
+FROM suppliers s JOIN taxes t ON t.id = s.default_tax_id
+WHERE s.id = $1`, supplierID, on)
+}
+
+// RateOn returns the tax at the rate effective on the date, nil when it does
+// not exist.
+func (r *Resolver) RateOn(ctx context.Context, taxID int64, on time.Time) (*shared.TaxRate, error) {
+	return r.lookup(ctx, `SELECT t.id, t.code, t.name, `+rateOn+`
+FROM taxes t
+WHERE t.id = $1`, taxID, on)
+}
+
+func (r *Resolver) lookup(ctx context.Context, query string, id int64, on time.Time) (*shared.TaxRate, error) {
+	if on.IsZero() {
+		on = time.Now()
+	}
+	var tax shared.TaxRate
+	err := r.pool.QueryRow(ctx, query, id, on).Scan(&tax.TaxID, &tax.Code, &tax.Name, &tax.Rate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tax, nil
+}
+
+var _ shared.DefaultTaxResolver = (*Resolver)(nil)
@@ -3,6 +3,7 @@ package taxes
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
 )
@@ -40,6 +41,10 @@ func (s *Service) Update(ctx context.Context, id int64, tax Tax) error {
 	if err := s.validate(tax); err != nil {
 		return err
 	}
+	if tax.EffectiveFrom.IsZero() {
+		now := time.Now()
+		tax.EffectiveFrom = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
 	return s.repo.Update(ctx, id, tax)
 }
 
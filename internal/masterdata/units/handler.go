@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -46,8 +47,8 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	units, total, err := h.service.List(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list units failed", "error", err)
-		http.Error(w, "Failed to load units", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list units failed", "error", err)
+		httpx.Error(w, r, "Failed to load units", http.StatusInternalServerError)
 		return
 	}
 
@@ -61,14 +62,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid unit ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid unit ID", http.StatusBadRequest)
 		return
 	}
 
 	unit, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get unit failed", "error", err, "id", id)
-		http.Error(w, "Unit not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get unit failed", "error", err, "id", id)
+		httpx.Error(w, r, "Unit not found", http.StatusNotFound)
 		return
 	}
 
@@ -86,7 +87,7 @@ func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -97,7 +98,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	created, err := h.service.Create(r.Context(), unit)
 	if err != nil {
-		h.logger.Error("create unit failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create unit failed", "error", err)
 		h.render(w, r, "pages/masterdata/unit_form.html", map[string]any{
 			"Errors": map[string]string{"general": internalShared.UserSafeMessage(err)},
 			"Unit":   nil,
@@ -111,14 +112,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid unit ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid unit ID", http.StatusBadRequest)
 		return
 	}
 
 	unit, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get unit failed", "error", err, "id", id)
-		http.Error(w, "Unit not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get unit failed", "error", err, "id", id)
+		httpx.Error(w, r, "Unit not found", http.StatusNotFound)
 		return
 	}
 
@@ -131,12 +132,12 @@ func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid unit ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid unit ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -147,7 +148,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	err = h.service.Update(r.Context(), id, unit)
 	if err != nil {
-		h.logger.Error("update unit failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update unit failed", "error", err, "id", id)
 		h.render(w, r, "pages/masterdata/unit_form.html", map[string]any{
 			"Errors": map[string]string{"general": internalShared.UserSafeMessage(err)},
 			"Unit":   unit,
@@ -161,13 +162,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid unit ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid unit ID", http.StatusBadRequest)
 		return
 	}
 
 	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error("delete unit failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "delete unit failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/masterdata/units", "error", internalShared.UserSafeMessage(err))
 		return
 	}
@@ -191,7 +192,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", "error", err, "template", template)
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
 	}
 }
 
@@ -0,0 +1,9 @@
+package vehicles
+
+// Vehicle is a delivery vehicle that delivery orders can be assigned to.
+type Vehicle struct {
+	ID          int64  `json:"id"`
+	PlateNumber string `json:"plate_number"`
+	Description string `json:"description"`
+	Active      bool   `json:"active"`
+}
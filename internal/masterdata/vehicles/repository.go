@@ -0,0 +1,133 @@
+package vehicles
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+)
+
+type Repository interface {
+	List(ctx context.Context, filters shared.ListFilters) ([]Vehicle, int, error)
+	Get(ctx context.Context, id int64) (Vehicle, error)
+	Create(ctx context.Context, vehicle Vehicle) (Vehicle, error)
+	Update(ctx context.Context, id int64, vehicle Vehicle) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repository{pool: pool}
+}
+
+func (r *repository) List(ctx context.Context, filters shared.ListFilters) ([]Vehicle, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	if filters.Search != "" {
+		args = append(args, "%"+filters.Search+"%")
+		where += ` AND (plate_number ILIKE $1 OR description ILIKE $1)`
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM delivery_vehicles`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, plate_number, description, is_active FROM delivery_vehicles` + where +
+		` ORDER BY ` + sortOrder(filters.SortBy, filters.SortDir)
+	if filters.Limit > 0 {
+		offset := (filters.Page - 1) * filters.Limit
+		if offset < 0 {
+			offset = 0
+		}
+		args = append(args, filters.Limit, offset)
+		query += ` LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var vehicles []Vehicle
+	for rows.Next() {
+		var v Vehicle
+		if err := rows.Scan(&v.ID, &v.PlateNumber, &v.Description, &v.Active); err != nil {
+			return nil, 0, err
+		}
+		vehicles = append(vehicles, v)
+	}
+	return vehicles, total, rows.Err()
+}
+
+func (r *repository) Get(ctx context.Context, id int64) (Vehicle, error) {
+	var v Vehicle
+	err := r.pool.QueryRow(ctx, `SELECT id, plate_number, description, is_active FROM delivery_vehicles WHERE id = $1`, id).
+		Scan(&v.ID, &v.PlateNumber, &v.Description, &v.Active)
+	return v, err
+}
+
+func (r *repository) Create(ctx context.Context, vehicle Vehicle) (Vehicle, error) {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO delivery_vehicles (plate_number, description, is_active)
+		VALUES ($1, $2, $3)
+		RETURNING id`,
+		vehicle.PlateNumber, vehicle.Description, vehicle.Active,
+	).Scan(&vehicle.ID)
+	if err != nil {
+		return Vehicle{}, mapUniqueViolation(err)
+	}
+	return vehicle, nil
+}
+
+func (r *repository) Update(ctx context.Context, id int64, vehicle Vehicle) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE delivery_vehicles
+		SET plate_number = $2, description = $3, is_active = $4, updated_at = NOW()
+		WHERE id = $1`,
+		id, vehicle.PlateNumber, vehicle.Description, vehicle.Active,
+	)
+	if err != nil {
+		return mapUniqueViolation(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Delete removes the vehicle. Delivery orders that used it keep their
+// vehicle number; only the link to the master record is cleared.
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM delivery_vehicles WHERE id = $1`, id)
+	return err
+}
+
+func mapUniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicatePlate
+	}
+	return err
+}
+
+func sortOrder(sortBy, sortDir string) string {
+	dir := "ASC"
+	if sortDir == "desc" {
+		dir = "DESC"
+	}
+	switch sortBy {
+	case "active":
+		return "is_active " + dir + ", plate_number ASC"
+	default:
+		return "plate_number " + dir
+	}
+}
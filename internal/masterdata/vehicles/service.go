@@ -0,0 +1,53 @@
+package vehicles
+
+import (
+	"context"
+	"errors"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) List(ctx context.Context, filters shared.ListFilters) ([]Vehicle, int, error) {
+	return s.repo.List(ctx, filters)
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (Vehicle, error) {
+	if id <= 0 {
+		return Vehicle{}, errors.New("invalid vehicle ID")
+	}
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) Create(ctx context.Context, vehicle Vehicle) (Vehicle, error) {
+	vehicle = normalize(vehicle)
+	if err := s.validate(vehicle); err != nil {
+		return Vehicle{}, err
+	}
+	return s.repo.Create(ctx, vehicle)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, vehicle Vehicle) error {
+	if id <= 0 {
+		return errors.New("invalid vehicle ID")
+	}
+	vehicle = normalize(vehicle)
+	if err := s.validate(vehicle); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, id, vehicle)
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("invalid vehicle ID")
+	}
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,35 @@
+package vehicles
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrPlateRequired rejects a vehicle without a plate number.
+	ErrPlateRequired = errors.New("plate number is required")
+	// ErrFieldTooLong rejects plate numbers over 50 characters or
+	// descriptions over 200.
+	ErrFieldTooLong = errors.New("plate number must be at most 50 characters, description at most 200")
+	// ErrDuplicatePlate indicates another vehicle already has the plate.
+	ErrDuplicatePlate = errors.New("a vehicle with this plate number already exists")
+)
+
+// normalize upper-cases the plate and collapses its spacing so "b 1234  xy"
+// and "B 1234 XY" are the same vehicle.
+func normalize(v Vehicle) Vehicle {
+	v.PlateNumber = strings.ToUpper(strings.Join(strings.Fields(v.PlateNumber), " "))
+	v.Description = strings.TrimSpace(v.Description)
+	return v
+}
+
+func (s *Service) validate(v Vehicle) error {
+	if v.PlateNumber == "" {
+		return ErrPlateRequired
+	}
+	if utf8.RuneCountInString(v.PlateNumber) > 50 || utf8.RuneCountInString(v.Description) > 200 {
+		return ErrFieldTooLong
+	}
+	return nil
+}
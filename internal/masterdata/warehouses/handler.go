@@ -1,14 +1,17 @@
 package warehouses
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/branches"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -55,8 +58,8 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	warehouses, total, err := h.service.List(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list warehouses failed", "error", err)
-		http.Error(w, "Failed to load warehouses", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list warehouses failed", "error", err)
+		httpx.Error(w, r, "Failed to load warehouses", http.StatusInternalServerError)
 		return
 	}
 
@@ -70,14 +73,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid warehouse ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid warehouse ID", http.StatusBadRequest)
 		return
 	}
 
 	warehouse, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get warehouse failed", "error", err, "id", id)
-		http.Error(w, "Warehouse not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get warehouse failed", "error", err, "id", id)
+		httpx.Error(w, r, "Warehouse not found", http.StatusNotFound)
 		return
 	}
 
@@ -89,7 +92,7 @@ func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 	branchesList, _, err := h.branchService.List(r.Context(), shared.ListFilters{})
 	if err != nil {
-		h.logger.Error("list branches failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "list branches failed", "error", err)
 		branchesList = []branches.Branch{}
 	}
 
@@ -102,7 +105,7 @@ func (h *Handler) Form(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -116,7 +119,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	created, err := h.service.Create(r.Context(), warehouse)
 	if err != nil {
-		h.logger.Error("create warehouse failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create warehouse failed", "error", err)
 		branchesList, _, _ := h.branchService.List(r.Context(), shared.ListFilters{})
 		h.render(w, r, "pages/masterdata/warehouse_form.html", map[string]any{
 			"Errors":    map[string]string{"general": internalShared.UserSafeMessage(err)},
@@ -132,20 +135,20 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid warehouse ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid warehouse ID", http.StatusBadRequest)
 		return
 	}
 
 	warehouse, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get warehouse failed", "error", err, "id", id)
-		http.Error(w, "Warehouse not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get warehouse failed", "error", err, "id", id)
+		httpx.Error(w, r, "Warehouse not found", http.StatusNotFound)
 		return
 	}
 
 	branchesList, _, err := h.branchService.List(r.Context(), shared.ListFilters{})
 	if err != nil {
-		h.logger.Error("list branches failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "list branches failed", "error", err)
 		branchesList = []branches.Branch{}
 	}
 
@@ -159,12 +162,12 @@ func (h *Handler) EditForm(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid warehouse ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid warehouse ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -178,7 +181,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	err = h.service.Update(r.Context(), id, warehouse)
 	if err != nil {
-		h.logger.Error("update warehouse failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update warehouse failed", "error", err, "id", id)
 		branchesList, _, _ := h.branchService.List(r.Context(), shared.ListFilters{})
 		h.render(w, r, "pages/masterdata/warehouse_form.html", map[string]any{
 			"Errors":    map[string]string{"general": internalShared.UserSafeMessage(err)},
@@ -194,13 +197,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid warehouse ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid warehouse ID", http.StatusBadRequest)
 		return
 	}
 
 	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error("delete warehouse failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "delete warehouse failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/masterdata/warehouses", "error", internalShared.UserSafeMessage(err))
 		return
 	}
@@ -208,6 +211,51 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.redirectWithFlash(w, r, "/masterdata/warehouses", "success", "Warehouse deleted successfully")
 }
 
+func (h *Handler) UpdateGLDimensions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid warehouse ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	location := "/masterdata/warehouses/" + strconv.FormatInt(id, 10)
+	companyID, err := optionalID(r.PostFormValue("gl_dim_company_id"))
+	if err != nil {
+		h.redirectWithFlash(w, r, location, "error", "Invalid GL company dimension")
+		return
+	}
+	branchID, err := optionalID(r.PostFormValue("gl_dim_branch_id"))
+	if err != nil {
+		h.redirectWithFlash(w, r, location, "error", "Invalid GL branch dimension")
+		return
+	}
+
+	if err := h.service.SetGLDimensions(r.Context(), id, companyID, branchID); err != nil {
+		h.logger.ErrorContext(r.Context(), "update warehouse gl dimensions failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, location, "error", internalShared.UserSafeMessage(err))
+		return
+	}
+
+	h.redirectWithFlash(w, r, location, "success", "GL dimensions updated successfully")
+}
+
+func optionalID(raw string) (*int64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, errors.New("invalid id")
+	}
+	return &id, nil
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
 	sess := internalShared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -224,7 +272,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", "error", err, "template", template)
+		h.logger.ErrorContext(r.Context(), "render template", "error", err, "template", template)
 	}
 }
 
@@ -13,4 +13,15 @@ type Warehouse struct {
 	Address   string    `json:"address"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// GL dimension overrides; nil falls back to the branch defaults
+	GLDimCompanyID *int64 `json:"gl_dim_company_id,omitempty"`
+	GLDimBranchID  *int64 `json:"gl_dim_branch_id,omitempty"`
+}
+
+// GLDimensions holds the effective dimensions stamped on journal lines
+// posted for a warehouse.
+type GLDimensions struct {
+	CompanyID   int64 `json:"company_id"`
+	BranchID    int64 `json:"branch_id"`
+	WarehouseID int64 `json:"warehouse_id"`
 }
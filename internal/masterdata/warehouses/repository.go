@@ -17,6 +17,8 @@ type Repository interface {
 	Create(ctx context.Context, warehouse Warehouse) (Warehouse, error)
 	Update(ctx context.Context, id int64, warehouse Warehouse) error
 	Delete(ctx context.Context, id int64) error
+	SetGLDimensions(ctx context.Context, id int64, companyID, branchID *int64) error
+	GLDimensions(ctx context.Context, id int64) (GLDimensions, error)
 }
 
 type repository struct {
@@ -114,11 +116,13 @@ func (r *repository) Get(ctx context.Context, id int64) (Warehouse, error) {
 		return Warehouse{}, err
 	}
 	return Warehouse{
-		ID:       row.ID,
-		BranchID: row.BranchID,
-		Code:     row.Code,
-		Name:     row.Name,
-		Address:  row.Address,
+		ID:             row.ID,
+		BranchID:       row.BranchID,
+		Code:           row.Code,
+		Name:           row.Name,
+		Address:        row.Address,
+		GLDimCompanyID: int8Ptr(row.GlDimCompanyID),
+		GLDimBranchID:  int8Ptr(row.GlDimBranchID),
 	}, nil
 }
 
@@ -162,6 +166,40 @@ func (r *repository) Delete(ctx context.Context, id int64) error {
 	return r.queries.DeleteWarehouse(ctx, id)
 }
 
+// SetGLDimensions uses sqlc generated query
+func (r *repository) SetGLDimensions(ctx context.Context, id int64, companyID, branchID *int64) error {
+	return r.queries.SetWarehouseGLDimensions(ctx, sqlc.SetWarehouseGLDimensionsParams{
+		GlDimCompanyID: int8Param(companyID),
+		GlDimBranchID:  int8Param(branchID),
+		UpdatedAt:      pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ID:             id,
+	})
+}
+
+// GLDimensions uses sqlc generated query
+func (r *repository) GLDimensions(ctx context.Context, id int64) (GLDimensions, error) {
+	row, err := r.queries.GetWarehouseGLDimensions(ctx, id)
+	if err != nil {
+		return GLDimensions{}, err
+	}
+	return GLDimensions{CompanyID: row.CompanyID, BranchID: row.BranchID, WarehouseID: row.WarehouseID}, nil
+}
+
+func int8Ptr(v pgtype.Int8) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	out := v.Int64
+	return &out
+}
+
+func int8Param(v *int64) pgtype.Int8 {
+	if v == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *v, Valid: true}
+}
+
 func sortOrder(sortBy, sortDir string) string {
 	dir := "ASC"
 	if sortDir == "desc" {
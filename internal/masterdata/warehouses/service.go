@@ -49,3 +49,24 @@ func (s *Service) Delete(ctx context.Context, id int64) error {
 	}
 	return s.repo.Delete(ctx, id)
 }
+
+// SetGLDimensions stores the company/branch dimension overrides used when
+// posting journals for the warehouse. Nil clears the override.
+func (s *Service) SetGLDimensions(ctx context.Context, id int64, companyID, branchID *int64) error {
+	if id <= 0 {
+		return errors.New("invalid warehouse ID")
+	}
+	if (companyID != nil && *companyID <= 0) || (branchID != nil && *branchID <= 0) {
+		return errors.New("invalid GL dimension")
+	}
+	return s.repo.SetGLDimensions(ctx, id, companyID, branchID)
+}
+
+// GLDimensions resolves the effective dimensions for a warehouse, falling
+// back from warehouse overrides to branch overrides to the owning company.
+func (s *Service) GLDimensions(ctx context.Context, id int64) (GLDimensions, error) {
+	if id <= 0 {
+		return GLDimensions{}, errors.New("invalid warehouse ID")
+	}
+	return s.repo.GLDimensions(ctx, id)
+}
@@ -0,0 +1,252 @@
+package netting
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// PaymentMethod marks the AR and AP payments created by a settlement.
+const PaymentMethod = "NETTING"
+
+// settleTolerance treats balances below a cent as settled.
+const settleTolerance = 0.005
+
+var (
+	// ErrLinkNotFound indicates an unknown counterparty link.
+	ErrLinkNotFound = errors.New("netting: counterparty link not found")
+	// ErrSettlementNotFound indicates an unknown netting settlement.
+	ErrSettlementNotFound = errors.New("netting: settlement not found")
+	// ErrLinkRequired rejects a link without both a customer and a supplier.
+	ErrLinkRequired = fmt.Errorf("%w: choose both a customer and a supplier", shared.ErrValidation)
+	// ErrAlreadyLinked rejects linking a customer or supplier that is already linked.
+	ErrAlreadyLinked = fmt.Errorf("%w: customer or supplier is already linked", shared.ErrValidation)
+	// ErrLinkInUse rejects removing a link that has settlements.
+	ErrLinkInUse = fmt.Errorf("%w: link has settlements and cannot be removed", shared.ErrValidation)
+	// ErrNothingToNet indicates there are no open balances on one of the sides.
+	ErrNothingToNet = fmt.Errorf("%w: no open receivables and payables to net in this currency", shared.ErrValidation)
+	// ErrSettleDateRequired rejects a settlement without a date.
+	ErrSettleDateRequired = fmt.Errorf("%w: settlement date is required", shared.ErrValidation)
+	// ErrJournalPending indicates the settlement was recorded but its journal
+	// could not be posted yet.
+	ErrJournalPending = errors.New("netting: settlement recorded but journal posting pending")
+)
+
+// Side tells which subledger an open document belongs to.
+type Side string
+
+const (
+	SideAR Side = "AR"
+	SideAP Side = "AP"
+)
+
+// Link pairs a customer with a supplier that is the same legal entity.
+type Link struct {
+	ID           int64
+	CustomerID   int64
+	CustomerName string
+	SupplierID   int64
+	SupplierName string
+	CreatedBy    int64
+	CreatedAt    time.Time
+}
+
+// LinkInput creates a counterparty link.
+type LinkInput struct {
+	CustomerID int64
+	SupplierID int64
+	CreatedBy  int64
+}
+
+// Party is a customer or supplier offered when creating a link.
+type Party struct {
+	ID   int64
+	Code string
+	Name string
+}
+
+// OpenDocument is a posted invoice with an open balance on either side.
+type OpenDocument struct {
+	Side     Side
+	ID       int64
+	Number   string
+	DueAt    time.Time
+	Currency string
+	Balance  float64
+}
+
+// Position summarises the open balances of a link in one currency.
+type Position struct {
+	Currency    string
+	Receivables []OpenDocument
+	Payables    []OpenDocument
+	ARTotal     float64
+	APTotal     float64
+}
+
+// Net is what the counterparty owes us after netting; negative when we owe
+// them.
+func (p Position) Net() float64 {
+	return roundAmount(p.ARTotal - p.APTotal)
+}
+
+// NettingAmount is the amount a settlement would offset: the smaller side.
+func (p Position) NettingAmount() float64 {
+	return roundAmount(math.Min(p.ARTotal, p.APTotal))
+}
+
+// Allocation is the amount a settlement applies to one invoice.
+type Allocation struct {
+	Side          Side
+	InvoiceID     int64
+	InvoiceNumber string
+	DueAt         time.Time
+	Balance       float64
+	Amount        float64
+}
+
+// Remaining is the invoice balance left open after the allocation.
+func (a Allocation) Remaining() float64 {
+	return roundAmount(a.Balance - a.Amount)
+}
+
+// Settled reports whether the allocation clears the invoice.
+func (a Allocation) Settled() bool {
+	return a.Remaining() < settleTolerance
+}
+
+// Plan is the proposed offset of a position.
+type Plan struct {
+	Currency    string
+	Amount      float64
+	ARTotal     float64
+	APTotal     float64
+	Allocations []Allocation
+}
+
+// Net is the balance left on the larger side after settling.
+func (p Plan) Net() float64 {
+	return roundAmount(p.ARTotal - p.APTotal)
+}
+
+// SettleInput requests a netting settlement for one currency of a link.
+type SettleInput struct {
+	LinkID    int64
+	Currency  string
+	SettledAt time.Time
+	Note      string
+	CreatedBy int64
+}
+
+// Settlement records a completed offset and the payments that carry it.
+type Settlement struct {
+	ID            int64
+	Number        string
+	LinkID        int64
+	CustomerID    int64
+	CustomerName  string
+	SupplierID    int64
+	SupplierName  string
+	Currency      string
+	Amount        float64
+	SettledAt     time.Time
+	ARPaymentID   int64
+	APPaymentID   int64
+	Note          string
+	CreatedBy     int64
+	CreatedAt     time.Time
+	Allocations   []Allocation
+	JournalPosted bool
+}
+
+// SettledEvent is raised after a settlement commits so the ledger can move
+// the offset amount from accounts payable to accounts receivable.
+type SettledEvent struct {
+	ID        int64
+	Number    string
+	Currency  string
+	Amount    float64
+	SettledAt time.Time
+}
+
+// positions groups open documents by currency, sides ordered oldest due date
+// first.
+func positions(docs []OpenDocument) []Position {
+	index := make(map[string]int)
+	var out []Position
+	for _, doc := range docs {
+		i, ok := index[doc.Currency]
+		if !ok {
+			i = len(out)
+			index[doc.Currency] = i
+			out = append(out, Position{Currency: doc.Currency})
+		}
+		p := &out[i]
+		if doc.Side == SideAR {
+			p.Receivables = append(p.Receivables, doc)
+			p.ARTotal = roundAmount(p.ARTotal + doc.Balance)
+		} else {
+			p.Payables = append(p.Payables, doc)
+			p.APTotal = roundAmount(p.APTotal + doc.Balance)
+		}
+	}
+	for i := range out {
+		sortDocuments(out[i].Receivables)
+		sortDocuments(out[i].Payables)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out
+}
+
+// plan offsets the smaller side of the position in full against the larger
+// side, applying the amount to the oldest invoices first on both sides.
+func plan(p Position) Plan {
+	out := Plan{Currency: p.Currency, ARTotal: p.ARTotal, APTotal: p.APTotal, Amount: p.NettingAmount()}
+	if out.Amount < settleTolerance {
+		out.Amount = 0
+		return out
+	}
+	out.Allocations = append(allocate(p.Receivables, out.Amount), allocate(p.Payables, out.Amount)...)
+	return out
+}
+
+func allocate(docs []OpenDocument, amount float64) []Allocation {
+	var out []Allocation
+	remaining := amount
+	for _, doc := range docs {
+		if remaining < settleTolerance {
+			break
+		}
+		applied := roundAmount(math.Min(doc.Balance, remaining))
+		if applied <= 0 {
+			continue
+		}
+		out = append(out, Allocation{
+			Side:          doc.Side,
+			InvoiceID:     doc.ID,
+			InvoiceNumber: doc.Number,
+			DueAt:         doc.DueAt,
+			Balance:       doc.Balance,
+			Amount:        applied,
+		})
+		remaining = roundAmount(remaining - applied)
+	}
+	return out
+}
+
+func sortDocuments(docs []OpenDocument) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		if !docs[i].DueAt.Equal(docs[j].DueAt) {
+			return docs[i].DueAt.Before(docs[j].DueAt)
+		}
+		return docs[i].Number < docs[j].Number
+	})
+}
+
+func roundAmount(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,257 @@
+package netting
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// Netting touches both subledgers, so changes need rights on both sides.
+var (
+	viewPermissions   = []string{shared.PermFinanceARView, "finance.ap.view"}
+	linkPermissions   = []string{shared.PermFinanceAREdit, "finance.ap.edit"}
+	settlePermissions = []string{shared.PermFinanceAREdit, "finance.ap.payment"}
+)
+
+// Handler wires the AR/AP netting SSR endpoints.
+type Handler struct {
+	logger    *slog.Logger
+	service   *Service
+	templates *view.Engine
+	csrf      *shared.CSRFManager
+	rbac      rbac.Middleware
+}
+
+// NewHandler constructs handler.
+func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, csrf *shared.CSRFManager, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, rbac: rbac}
+}
+
+// MountRoutes registers routes.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Route("/finance/netting", func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(viewPermissions...))
+		r.Get("/", h.listLinks)
+		r.With(h.rbac.RequireAll(linkPermissions...)).Post("/links", h.createLink)
+		r.Get("/links/{id}", h.showLink)
+		r.With(h.rbac.RequireAll(linkPermissions...)).Post("/links/{id}/delete", h.deleteLink)
+		r.With(h.rbac.RequireAll(settlePermissions...)).Post("/links/{id}/settle", h.settle)
+		r.Get("/settlements/{id}", h.showSettlement)
+		r.With(h.rbac.RequireAll(settlePermissions...)).Post("/settlements/{id}/post", h.retryJournal)
+	})
+}
+
+func (h *Handler) listLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.service.ListLinks(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list netting links", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	customers, suppliers, err := h.service.UnlinkedParties(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list unlinked parties", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/netting/links.html", "AR/AP Netting", map[string]any{
+		"Links":     links,
+		"Customers": customers,
+		"Suppliers": suppliers,
+	}, http.StatusOK)
+}
+
+func (h *Handler) createLink(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	id, err := h.service.CreateLink(r.Context(), LinkInput{
+		CustomerID: parseInt64(r.PostFormValue("customer_id")),
+		SupplierID: parseInt64(r.PostFormValue("supplier_id")),
+		CreatedBy:  currentUser(r),
+	})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "create netting link", slog.Any("error", err))
+		h.redirectWithFlash(w, r, "/finance/netting", "danger", errorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, fmt.Sprintf("/finance/netting/links/%d", id), "success", "Customer and supplier linked")
+}
+
+func (h *Handler) deleteLink(w http.ResponseWriter, r *http.Request) {
+	id := parseInt64(chi.URLParam(r, "id"))
+	if err := h.service.DeleteLink(r.Context(), id); err != nil {
+		if errors.Is(err, ErrLinkNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.WarnContext(r.Context(), "delete netting link", slog.Any("error", err))
+		h.redirectWithFlash(w, r, fmt.Sprintf("/finance/netting/links/%d", id), "danger", errorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, "/finance/netting", "success", "Link removed")
+}
+
+func (h *Handler) showLink(w http.ResponseWriter, r *http.Request) {
+	id := parseInt64(chi.URLParam(r, "id"))
+	link, list, err := h.service.Positions(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrLinkNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "netting positions", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	plans := make([]Plan, len(list))
+	for i, p := range list {
+		plans[i] = plan(p)
+	}
+	settlements, err := h.service.ListSettlements(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list netting settlements", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/netting/link_detail.html", "Netting: "+link.CustomerName, map[string]any{
+		"Link":        link,
+		"Positions":   list,
+		"Plans":       plans,
+		"Settlements": settlements,
+		"Today":       time.Now().Format("2006-01-02"),
+	}, http.StatusOK)
+}
+
+func (h *Handler) settle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	id := parseInt64(chi.URLParam(r, "id"))
+	back := fmt.Sprintf("/finance/netting/links/%d", id)
+	settledAt, _ := time.Parse("2006-01-02", strings.TrimSpace(r.PostFormValue("settled_at")))
+	settlement, err := h.service.Settle(r.Context(), SettleInput{
+		LinkID:    id,
+		Currency:  r.PostFormValue("currency"),
+		SettledAt: settledAt,
+		Note:      r.PostFormValue("note"),
+		CreatedBy: currentUser(r),
+	})
+	if err != nil {
+		if errors.Is(err, ErrJournalPending) && settlement.ID != 0 {
+			h.logger.WarnContext(r.Context(), "post netting journal", slog.Any("error", err))
+			h.redirectWithFlash(w, r, fmt.Sprintf("/finance/netting/settlements/%d", settlement.ID), "warning",
+				"Settlement recorded but journal posting is pending. Retry posting after updating the ledger period or account mapping.")
+			return
+		}
+		if errors.Is(err, ErrLinkNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.WarnContext(r.Context(), "settle netting", slog.Any("error", err))
+		h.redirectWithFlash(w, r, back, "danger", errorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, fmt.Sprintf("/finance/netting/settlements/%d", settlement.ID), "success",
+		fmt.Sprintf("Settlement %s netted %s %.2f", settlement.Number, settlement.Currency, settlement.Amount))
+}
+
+func (h *Handler) showSettlement(w http.ResponseWriter, r *http.Request) {
+	settlement, err := h.service.GetSettlement(r.Context(), parseInt64(chi.URLParam(r, "id")))
+	if err != nil {
+		if errors.Is(err, ErrSettlementNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "get netting settlement", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/netting/settlement_detail.html", "Settlement "+settlement.Number, map[string]any{
+		"Settlement": settlement,
+	}, http.StatusOK)
+}
+
+func (h *Handler) retryJournal(w http.ResponseWriter, r *http.Request) {
+	id := parseInt64(chi.URLParam(r, "id"))
+	location := fmt.Sprintf("/finance/netting/settlements/%d", id)
+	if err := h.service.RetryJournal(r.Context(), id); err != nil {
+		if errors.Is(err, ErrSettlementNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.WarnContext(r.Context(), "retry netting journal", slog.Any("error", err))
+		h.redirectWithFlash(w, r, location, "danger", "Journal posting failed; check the ledger period and netting account mappings.")
+		return
+	}
+	h.redirectWithFlash(w, r, location, "success", "Journal posted")
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, tpl, title string, data any, status int) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{
+		Title:       title,
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data:        data,
+	}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, tpl, viewData); err != nil && h.logger != nil {
+		h.logger.ErrorContext(r.Context(), "render netting template", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) redirectWithFlash(w http.ResponseWriter, r *http.Request, location, kind, message string) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, location, http.StatusSeeOther)
+}
+
+func errorMessage(err error) string {
+	known := []error{ErrLinkRequired, ErrAlreadyLinked, ErrLinkInUse, ErrNothingToNet, ErrSettleDateRequired,
+		accounting.ErrPeriodLocked, accounting.ErrInvalidPeriod}
+	for _, k := range known {
+		if errors.Is(err, k) {
+			return err.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
+
+func parseInt64(value string) int64 {
+	v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func currentUser(r *http.Request) int64 {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil {
+		return 0
+	}
+	v, _ := strconv.ParseInt(sess.User(), 10, 64)
+	return v
+}
@@ -0,0 +1,402 @@
+package netting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
+)
+
+// JournalSourceModule tags the journal entries posted for settlements.
+const JournalSourceModule = "FINANCE.NETTING"
+
+// JournalSourceID derives the journal source of a settlement.
+func JournalSourceID(settlementID int64) uuid.UUID {
+	return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("NETTING:%d", settlementID)))
+}
+
+// openARQuery and openAPQuery select posted invoices of a counterparty with
+// an open balance, optionally in one currency. AP invoices with withholding
+// tax still due are left out: the tax must be withheld and reported through
+// an AP payment, which a netting offset cannot do.
+const openARQuery = `
+SELECT i.id, i.number, i.due_at, i.currency,
+       (i.total
+        - COALESCE((SELECT SUM(pa.amount) FROM ar_payment_allocations pa WHERE pa.ar_invoice_id = i.id), 0)
+        - COALESCE((SELECT SUM(cn.total) FROM ar_credit_notes cn WHERE cn.ar_invoice_id = i.id), 0))::float8
+FROM ar_invoices i
+WHERE i.customer_id = $1 AND i.status = 'POSTED' AND ($2::text = '' OR i.currency = $2)
+ORDER BY i.due_at, i.number`
+
+const openAPQuery = `
+SELECT i.id, i.number, COALESCE(i.due_at, i.issued_at)::timestamptz, i.currency,
+       (i.total
+        - COALESCE((SELECT SUM(pa.amount) FROM ap_payment_allocations pa WHERE pa.ap_invoice_id = i.id), 0))::float8
+FROM ap_invoices i
+WHERE i.supplier_id = $1 AND i.status = 'POSTED' AND ($2::text = '' OR i.currency = $2)
+  AND i.withholding_amount <= COALESCE((SELECT SUM(pa.withheld_amount) FROM ap_payment_allocations pa WHERE pa.ap_invoice_id = i.id), 0)
+ORDER BY COALESCE(i.due_at, i.issued_at), i.number`
+
+const linkColumns = `
+SELECT l.id, l.customer_id, c.name, l.supplier_id, s.name, COALESCE(l.created_by, 0), l.created_at
+FROM counterparty_links l
+JOIN customers c ON c.id = l.customer_id
+JOIN suppliers s ON s.id = l.supplier_id`
+
+const settlementColumns = `
+SELECT ns.id, ns.number, ns.link_id, ns.customer_id, c.name, ns.supplier_id, s.name,
+       ns.currency, ns.amount::float8, ns.settled_at::timestamptz, ns.ar_payment_id, ns.ap_payment_id,
+       ns.note, COALESCE(ns.created_by, 0), ns.created_at
+FROM netting_settlements ns
+JOIN customers c ON c.id = ns.customer_id
+JOIN suppliers s ON s.id = ns.supplier_id`
+
+// allocationSQL holds the statements that apply a settlement to an invoice
+// on each side: the payment allocation, the netting allocation and the status
+// change once the invoice is cleared.
+var allocationSQL = map[Side]struct{ payment, netting, paid string }{
+	SideAR: {
+		payment: `INSERT INTO ar_payment_allocations (ar_payment_id, ar_invoice_id, amount) VALUES ($1, $2, $3)`,
+		netting: `INSERT INTO netting_allocations (settlement_id, ar_invoice_id, amount) VALUES ($1, $2, $3)`,
+		paid:    `UPDATE ar_invoices SET status = 'PAID', updated_at = NOW() WHERE id = $1`,
+	},
+	SideAP: {
+		payment: `INSERT INTO ap_payment_allocations (ap_payment_id, ap_invoice_id, amount) VALUES ($1, $2, $3)`,
+		netting: `INSERT INTO netting_allocations (settlement_id, ap_invoice_id, amount) VALUES ($1, $2, $3)`,
+		paid:    `UPDATE ap_invoices SET status = 'PAID', updated_at = NOW() WHERE id = $1`,
+	},
+}
+
+type dbtx interface {
+	Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
+	Query(context.Context, string, ...interface{}) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...interface{}) pgx.Row
+}
+
+// Repository persists netting data in Postgres.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs the repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// WithTx runs fn inside a transaction.
+func (r *Repository) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
+	return db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		return fn(ctx, &txRepository{db: tx})
+	})
+}
+
+// ListLinks returns every link ordered by customer name.
+func (r *Repository) ListLinks(ctx context.Context) ([]Link, error) {
+	rows, err := r.pool.Query(ctx, linkColumns+` ORDER BY c.name, l.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Link
+	for rows.Next() {
+		var link Link
+		if err := scanLink(rows, &link); err != nil {
+			return nil, err
+		}
+		out = append(out, link)
+	}
+	return out, rows.Err()
+}
+
+// GetLink returns a link, or nil when it does not exist.
+func (r *Repository) GetLink(ctx context.Context, id int64) (*Link, error) {
+	var link Link
+	err := scanLink(r.pool.QueryRow(ctx, linkColumns+` WHERE l.id = $1`, id), &link)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// CreateLink inserts a link, mapping a repeated customer or supplier to
+// ErrAlreadyLinked.
+func (r *Repository) CreateLink(ctx context.Context, input LinkInput) (int64, error) {
+	var createdBy *int64
+	if input.CreatedBy > 0 {
+		createdBy = &input.CreatedBy
+	}
+	var id int64
+	err := r.pool.QueryRow(ctx, `
+INSERT INTO counterparty_links (customer_id, supplier_id, created_by)
+VALUES ($1, $2, $3)
+RETURNING id`, input.CustomerID, input.SupplierID, createdBy).Scan(&id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return 0, ErrAlreadyLinked
+	}
+	return id, err
+}
+
+// DeleteLink removes a link. Links with settlements are kept for the audit
+// trail.
+func (r *Repository) DeleteLink(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM counterparty_links WHERE id = $1`, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrLinkInUse
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+// UnlinkedParties returns the active customers and suppliers without a link.
+func (r *Repository) UnlinkedParties(ctx context.Context) ([]Party, []Party, error) {
+	customers, err := r.parties(ctx, `
+SELECT c.id, c.code, c.name FROM customers c
+WHERE c.is_active AND NOT EXISTS (SELECT 1 FROM counterparty_links l WHERE l.customer_id = c.id)
+ORDER BY c.name`)
+	if err != nil {
+		return nil, nil, err
+	}
+	suppliers, err := r.parties(ctx, `
+SELECT s.id, s.code, s.name FROM suppliers s
+WHERE s.is_active AND NOT EXISTS (SELECT 1 FROM counterparty_links l WHERE l.supplier_id = s.id)
+ORDER BY s.name`)
+	if err != nil {
+		return nil, nil, err
+	}
+	return customers, suppliers, nil
+}
+
+func (r *Repository) parties(ctx context.Context, query string) ([]Party, error) {
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Party
+	for rows.Next() {
+		var p Party
+		if err := rows.Scan(&p.ID, &p.Code, &p.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// OpenDocuments returns the link's open invoices in every currency.
+func (r *Repository) OpenDocuments(ctx context.Context, link Link) ([]OpenDocument, error) {
+	return openDocuments(ctx, r.pool, link, "", "")
+}
+
+// ListSettlements returns the settlements of a link, newest first.
+func (r *Repository) ListSettlements(ctx context.Context, linkID int64) ([]Settlement, error) {
+	rows, err := r.pool.Query(ctx, settlementColumns+`
+WHERE ns.link_id = $1
+ORDER BY ns.settled_at DESC, ns.id DESC`, linkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Settlement
+	for rows.Next() {
+		var s Settlement
+		if err := scanSettlement(rows, &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// GetSettlement returns a settlement with its allocations and journal
+// status, or nil when it does not exist.
+func (r *Repository) GetSettlement(ctx context.Context, id int64) (*Settlement, error) {
+	var s Settlement
+	err := scanSettlement(r.pool.QueryRow(ctx, settlementColumns+` WHERE ns.id = $1`, id), &s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+SELECT CASE WHEN na.ar_invoice_id IS NOT NULL THEN 'AR' ELSE 'AP' END,
+       COALESCE(na.ar_invoice_id, na.ap_invoice_id),
+       COALESCE(ar.number, ap.number),
+       COALESCE(ar.due_at, ap.due_at::timestamptz, ap.issued_at::timestamptz),
+       na.amount::float8
+FROM netting_allocations na
+LEFT JOIN ar_invoices ar ON ar.id = na.ar_invoice_id
+LEFT JOIN ap_invoices ap ON ap.id = na.ap_invoice_id
+WHERE na.settlement_id = $1
+ORDER BY na.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var alloc Allocation
+		var side string
+		if err := rows.Scan(&side, &alloc.InvoiceID, &alloc.InvoiceNumber, &alloc.DueAt, &alloc.Amount); err != nil {
+			return nil, err
+		}
+		alloc.Side = Side(side)
+		s.Allocations = append(s.Allocations, alloc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.pool.QueryRow(ctx, `
+SELECT EXISTS (
+    SELECT 1
+    FROM journal_entries
+    WHERE source_module = $1 AND source_id = $2 AND status = 'POSTED'
+)`, JournalSourceModule, JournalSourceID(s.ID)).Scan(&s.JournalPosted)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+type txRepository struct {
+	db dbtx
+}
+
+// LockOpenDocuments locks and returns the link's open invoices in currency.
+func (tx *txRepository) LockOpenDocuments(ctx context.Context, link Link, currency string) ([]OpenDocument, error) {
+	return openDocuments(ctx, tx.db, link, currency, " FOR UPDATE OF i")
+}
+
+// CreateSettlement writes the settlement as one AR payment and one AP
+// payment, each allocated to the planned invoices, and marks invoices paid
+// once nothing is left open on them.
+func (tx *txRepository) CreateSettlement(ctx context.Context, s *Settlement) error {
+	if err := tx.db.QueryRow(ctx, `
+SELECT 'NET-' || TO_CHAR(NOW(), 'YYYYMMDD') || '-' || LPAD(NEXTVAL('netting_settlements_id_seq')::TEXT, 4, '0')`,
+	).Scan(&s.Number); err != nil {
+		return err
+	}
+	var createdBy *int64
+	if s.CreatedBy > 0 {
+		createdBy = &s.CreatedBy
+	}
+	note := "Netting " + s.Number
+	if s.Note != "" {
+		note += ": " + s.Note
+	}
+
+	var firstAR int64
+	for _, alloc := range s.Allocations {
+		if alloc.Side == SideAR {
+			firstAR = alloc.InvoiceID
+			break
+		}
+	}
+	// ar_payments still carries the legacy single-invoice reference; the
+	// allocations below are what settle the invoices.
+	if err := tx.db.QueryRow(ctx, `
+INSERT INTO ar_payments (number, ar_invoice_id, amount, paid_at, method, note, created_by)
+VALUES (generate_ar_payment_number(), $1, $2, $3, $4, $5, $6)
+RETURNING id`, firstAR, s.Amount, s.SettledAt, PaymentMethod, note, createdBy).Scan(&s.ARPaymentID); err != nil {
+		return fmt.Errorf("create netting AR payment: %w", err)
+	}
+	if err := tx.db.QueryRow(ctx, `
+INSERT INTO ap_payments (number, supplier_id, amount, paid_at, method, note, created_by)
+VALUES ('PAY-' || TO_CHAR(NOW(), 'YYYYMMDD') || '-' || LPAD(NEXTVAL('ap_payments_id_seq')::TEXT, 4, '0'), $1, $2, $3, $4, $5, $6)
+RETURNING id`, s.SupplierID, s.Amount, s.SettledAt, PaymentMethod, note, createdBy).Scan(&s.APPaymentID); err != nil {
+		return fmt.Errorf("create netting AP payment: %w", err)
+	}
+	if err := tx.db.QueryRow(ctx, `
+INSERT INTO netting_settlements (number, link_id, customer_id, supplier_id, currency, amount, settled_at,
+                                 ar_payment_id, ap_payment_id, note, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, created_at`,
+		s.Number, s.LinkID, s.CustomerID, s.SupplierID, s.Currency, s.Amount, s.SettledAt,
+		s.ARPaymentID, s.APPaymentID, s.Note, createdBy,
+	).Scan(&s.ID, &s.CreatedAt); err != nil {
+		return err
+	}
+
+	for _, alloc := range s.Allocations {
+		stmt := allocationSQL[alloc.Side]
+		paymentID := s.ARPaymentID
+		if alloc.Side == SideAP {
+			paymentID = s.APPaymentID
+		}
+		if _, err := tx.db.Exec(ctx, stmt.payment, paymentID, alloc.InvoiceID, alloc.Amount); err != nil {
+			return fmt.Errorf("allocate %s: %w", alloc.InvoiceNumber, err)
+		}
+		if _, err := tx.db.Exec(ctx, stmt.netting, s.ID, alloc.InvoiceID, alloc.Amount); err != nil {
+			return err
+		}
+		if !alloc.Settled() {
+			continue
+		}
+		if _, err := tx.db.Exec(ctx, stmt.paid, alloc.InvoiceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func openDocuments(ctx context.Context, q dbtx, link Link, currency, lock string) ([]OpenDocument, error) {
+	ar, err := queryOpen(ctx, q, SideAR, openARQuery+lock, link.CustomerID, currency)
+	if err != nil {
+		return nil, err
+	}
+	ap, err := queryOpen(ctx, q, SideAP, openAPQuery+lock, link.SupplierID, currency)
+	if err != nil {
+		return nil, err
+	}
+	return append(ar, ap...), nil
+}
+
+func queryOpen(ctx context.Context, q dbtx, side Side, query string, partyID int64, currency string) ([]OpenDocument, error) {
+	rows, err := q.Query(ctx, query, partyID, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []OpenDocument
+	for rows.Next() {
+		doc := OpenDocument{Side: side}
+		if err := rows.Scan(&doc.ID, &doc.Number, &doc.DueAt, &doc.Currency, &doc.Balance); err != nil {
+			return nil, err
+		}
+		doc.Balance = roundAmount(doc.Balance)
+		if doc.Balance < settleTolerance {
+			continue
+		}
+		out = append(out, doc)
+	}
+	return out, rows.Err()
+}
+
+func scanLink(row pgx.Row, link *Link) error {
+	return row.Scan(&link.ID, &link.CustomerID, &link.CustomerName, &link.SupplierID, &link.SupplierName,
+		&link.CreatedBy, &link.CreatedAt)
+}
+
+func scanSettlement(row pgx.Row, s *Settlement) error {
+	return row.Scan(&s.ID, &s.Number, &s.LinkID, &s.CustomerID, &s.CustomerName, &s.SupplierID, &s.SupplierName,
+		&s.Currency, &s.Amount, &s.SettledAt, &s.ARPaymentID, &s.APPaymentID,
+		&s.Note, &s.CreatedBy, &s.CreatedAt)
+}
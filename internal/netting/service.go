@@ -0,0 +1,230 @@
+package netting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RepositoryPort persists links and settlements and reads open balances.
+type RepositoryPort interface {
+	ListLinks(ctx context.Context) ([]Link, error)
+	GetLink(ctx context.Context, id int64) (*Link, error)
+	CreateLink(ctx context.Context, input LinkInput) (int64, error)
+	DeleteLink(ctx context.Context, id int64) error
+	UnlinkedParties(ctx context.Context) (customers, suppliers []Party, err error)
+	OpenDocuments(ctx context.Context, link Link) ([]OpenDocument, error)
+	ListSettlements(ctx context.Context, linkID int64) ([]Settlement, error)
+	GetSettlement(ctx context.Context, id int64) (*Settlement, error)
+	WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error
+}
+
+// TxRepository is the transactional part of a settlement.
+type TxRepository interface {
+	// LockOpenDocuments locks the link's open invoices in one currency.
+	LockOpenDocuments(ctx context.Context, link Link, currency string) ([]OpenDocument, error)
+	// CreateSettlement records the settlement, its AR and AP payments and
+	// allocations, and marks cleared invoices paid. It fills in the ID,
+	// number and payment IDs.
+	CreateSettlement(ctx context.Context, settlement *Settlement) error
+}
+
+// PeriodGuard checks that the accounting period covering a date is open.
+type PeriodGuard interface {
+	EnsureOpen(ctx context.Context, date time.Time) error
+}
+
+// IntegrationHandler journals committed settlements.
+type IntegrationHandler interface {
+	HandleNettingSettled(ctx context.Context, evt SettledEvent) error
+}
+
+// Service nets receivables against payables for linked counterparties.
+type Service struct {
+	repo        RepositoryPort
+	periods     PeriodGuard
+	integration IntegrationHandler
+}
+
+// NewService builds the service.
+func NewService(repo RepositoryPort) *Service {
+	return &Service{repo: repo}
+}
+
+// SetPeriodGuard rejects settlements dated in a closed or locked period.
+func (s *Service) SetPeriodGuard(guard PeriodGuard) {
+	s.periods = guard
+}
+
+// SetIntegrationHandler enables journalling settlements.
+func (s *Service) SetIntegrationHandler(handler IntegrationHandler) {
+	s.integration = handler
+}
+
+// ListLinks returns every counterparty link.
+func (s *Service) ListLinks(ctx context.Context) ([]Link, error) {
+	return s.repo.ListLinks(ctx)
+}
+
+// UnlinkedParties returns the customers and suppliers not linked yet.
+func (s *Service) UnlinkedParties(ctx context.Context) ([]Party, []Party, error) {
+	return s.repo.UnlinkedParties(ctx)
+}
+
+// CreateLink pairs a customer with a supplier. Each record can be linked
+// once.
+func (s *Service) CreateLink(ctx context.Context, input LinkInput) (int64, error) {
+	if input.CustomerID <= 0 || input.SupplierID <= 0 {
+		return 0, ErrLinkRequired
+	}
+	return s.repo.CreateLink(ctx, input)
+}
+
+// DeleteLink removes a link that has not been settled against.
+func (s *Service) DeleteLink(ctx context.Context, id int64) error {
+	return s.repo.DeleteLink(ctx, id)
+}
+
+// Positions returns the link with its open balances per currency.
+func (s *Service) Positions(ctx context.Context, linkID int64) (Link, []Position, error) {
+	link, err := s.link(ctx, linkID)
+	if err != nil {
+		return Link{}, nil, err
+	}
+	docs, err := s.repo.OpenDocuments(ctx, link)
+	if err != nil {
+		return Link{}, nil, err
+	}
+	return link, positions(docs), nil
+}
+
+// Preview returns the offset a settlement in currency would make now.
+func (s *Service) Preview(ctx context.Context, linkID int64, currency string) (Plan, error) {
+	_, list, err := s.Positions(ctx, linkID)
+	if err != nil {
+		return Plan{}, err
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	for _, p := range list {
+		if p.Currency == currency {
+			return plan(p), nil
+		}
+	}
+	return Plan{Currency: currency}, nil
+}
+
+// ListSettlements returns the settlements of a link, newest first.
+func (s *Service) ListSettlements(ctx context.Context, linkID int64) ([]Settlement, error) {
+	return s.repo.ListSettlements(ctx, linkID)
+}
+
+// GetSettlement returns a settlement with its allocations.
+func (s *Service) GetSettlement(ctx context.Context, id int64) (Settlement, error) {
+	settlement, err := s.repo.GetSettlement(ctx, id)
+	if err != nil {
+		return Settlement{}, err
+	}
+	if settlement == nil {
+		return Settlement{}, ErrSettlementNotFound
+	}
+	return *settlement, nil
+}
+
+// Settle offsets the smaller side of the link's open balances in one
+// currency against the larger side. Invoices are locked and re-read inside
+// the transaction so the offset reflects payments made since the preview.
+// The journal is posted after commit; when that fails the settlement stands
+// and the returned error wraps ErrJournalPending.
+func (s *Service) Settle(ctx context.Context, input SettleInput) (Settlement, error) {
+	if input.SettledAt.IsZero() {
+		return Settlement{}, ErrSettleDateRequired
+	}
+	input.Currency = strings.ToUpper(strings.TrimSpace(input.Currency))
+	link, err := s.link(ctx, input.LinkID)
+	if err != nil {
+		return Settlement{}, err
+	}
+	if s.periods != nil {
+		if err := s.periods.EnsureOpen(ctx, input.SettledAt); err != nil {
+			return Settlement{}, err
+		}
+	}
+
+	settlement := Settlement{
+		LinkID:       link.ID,
+		CustomerID:   link.CustomerID,
+		CustomerName: link.CustomerName,
+		SupplierID:   link.SupplierID,
+		SupplierName: link.SupplierName,
+		Currency:     input.Currency,
+		SettledAt:    input.SettledAt,
+		Note:         strings.TrimSpace(input.Note),
+		CreatedBy:    input.CreatedBy,
+	}
+	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		docs, err := tx.LockOpenDocuments(ctx, link, input.Currency)
+		if err != nil {
+			return err
+		}
+		list := positions(docs)
+		if len(list) == 0 {
+			return ErrNothingToNet
+		}
+		offset := plan(list[0])
+		if offset.Amount <= 0 {
+			return ErrNothingToNet
+		}
+		settlement.Amount = offset.Amount
+		settlement.Allocations = offset.Allocations
+		return tx.CreateSettlement(ctx, &settlement)
+	})
+	if err != nil {
+		return Settlement{}, err
+	}
+
+	if s.integration != nil {
+		if err := s.integration.HandleNettingSettled(ctx, settledEvent(settlement)); err != nil {
+			return settlement, fmt.Errorf("%w: %v", ErrJournalPending, err)
+		}
+		settlement.JournalPosted = true
+	}
+	return settlement, nil
+}
+
+// RetryJournal posts the journal of a settlement whose posting failed.
+// Posting is idempotent, so retrying a posted settlement is harmless.
+func (s *Service) RetryJournal(ctx context.Context, id int64) error {
+	settlement, err := s.GetSettlement(ctx, id)
+	if err != nil {
+		return err
+	}
+	if s.integration == nil || settlement.JournalPosted {
+		return nil
+	}
+	if err := s.integration.HandleNettingSettled(ctx, settledEvent(settlement)); err != nil {
+		return fmt.Errorf("%w: %v", ErrJournalPending, err)
+	}
+	return nil
+}
+
+func (s *Service) link(ctx context.Context, id int64) (Link, error) {
+	link, err := s.repo.GetLink(ctx, id)
+	if err != nil {
+		return Link{}, err
+	}
+	if link == nil {
+		return Link{}, ErrLinkNotFound
+	}
+	return *link, nil
+}
+
+func settledEvent(settlement Settlement) SettledEvent {
+	return SettledEvent{
+		ID:        settlement.ID,
+		Number:    settlement.Number,
+		Currency:  settlement.Currency,
+		Amount:    settlement.Amount,
+		SettledAt: settlement.SettledAt,
+	}
+}
@@ -0,0 +1,191 @@
+package netting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	accounting "github.com/odyssey-erp/odyssey-erp/internal/accounting/shared"
+)
+
+type memoryRepo struct {
+	RepositoryPort
+	link        *Link
+	docs        []OpenDocument
+	settlements []Settlement
+}
+
+func (r *memoryRepo) GetLink(_ context.Context, id int64) (*Link, error) {
+	if r.link == nil || r.link.ID != id {
+		return nil, nil
+	}
+	copied := *r.link
+	return &copied, nil
+}
+
+func (r *memoryRepo) OpenDocuments(context.Context, Link) ([]OpenDocument, error) {
+	return append([]OpenDocument(nil), r.docs...), nil
+}
+
+func (r *memoryRepo) GetSettlement(_ context.Context, id int64) (*Settlement, error) {
+	for _, s := range r.settlements {
+		if s.ID == id {
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepo) WithTx(ctx context.Context, fn func(context.Context, TxRepository) error) error {
+	return fn(ctx, r)
+}
+
+func (r *memoryRepo) LockOpenDocuments(_ context.Context, _ Link, currency string) ([]OpenDocument, error) {
+	var out []OpenDocument
+	for _, doc := range r.docs {
+		if doc.Currency == currency {
+			out = append(out, doc)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepo) CreateSettlement(_ context.Context, s *Settlement) error {
+	s.ID = int64(len(r.settlements) + 1)
+	s.Number = "NET-1"
+	for _, alloc := range s.Allocations {
+		for i := range r.docs {
+			if r.docs[i].Side == alloc.Side && r.docs[i].ID == alloc.InvoiceID {
+				r.docs[i].Balance = roundAmount(r.docs[i].Balance - alloc.Amount)
+			}
+		}
+	}
+	r.settlements = append(r.settlements, *s)
+	return nil
+}
+
+type fakeLedger struct {
+	events []SettledEvent
+	err    error
+}
+
+func (f *fakeLedger) HandleNettingSettled(_ context.Context, evt SettledEvent) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, evt)
+	return nil
+}
+
+type stubPeriodGuard struct{ err error }
+
+func (g stubPeriodGuard) EnsureOpen(context.Context, time.Time) error { return g.err }
+
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+}
+
+func nettingRepo() *memoryRepo {
+	return &memoryRepo{
+		link: &Link{ID: 1, CustomerID: 10, CustomerName: "PT Mitra", SupplierID: 20, SupplierName: "PT Mitra"},
+		docs: []OpenDocument{
+			{Side: SideAR, ID: 1, Number: "INV-2", DueAt: day(20), Currency: "IDR", Balance: 700},
+			{Side: SideAR, ID: 2, Number: "INV-1", DueAt: day(5), Currency: "IDR", Balance: 500},
+			{Side: SideAP, ID: 7, Number: "APV-1", DueAt: day(10), Currency: "IDR", Balance: 400},
+			{Side: SideAP, ID: 8, Number: "APV-2", DueAt: day(12), Currency: "IDR", Balance: 350.5},
+			{Side: SideAR, ID: 3, Number: "INV-USD", DueAt: day(1), Currency: "USD", Balance: 90},
+		},
+	}
+}
+
+func TestPlanClearsSmallerSideOldestFirst(t *testing.T) {
+	list := positions(nettingRepo().docs)
+	require.Len(t, list, 2)
+	idr := list[0]
+	require.Equal(t, "IDR", idr.Currency)
+	require.Equal(t, 1200.0, idr.ARTotal)
+	require.Equal(t, 750.5, idr.APTotal)
+	require.Equal(t, 449.5, idr.Net())
+	require.Equal(t, "INV-1", idr.Receivables[0].Number)
+
+	offset := plan(idr)
+	require.Equal(t, 750.5, offset.Amount)
+	require.Equal(t, []Allocation{
+		{Side: SideAR, InvoiceID: 2, InvoiceNumber: "INV-1", DueAt: day(5), Balance: 500, Amount: 500},
+		{Side: SideAR, InvoiceID: 1, InvoiceNumber: "INV-2", DueAt: day(20), Balance: 700, Amount: 250.5},
+		{Side: SideAP, InvoiceID: 7, InvoiceNumber: "APV-1", DueAt: day(10), Balance: 400, Amount: 400},
+		{Side: SideAP, InvoiceID: 8, InvoiceNumber: "APV-2", DueAt: day(12), Balance: 350.5, Amount: 350.5},
+	}, offset.Allocations)
+	require.True(t, offset.Allocations[0].Settled())
+	require.False(t, offset.Allocations[1].Settled())
+	require.Equal(t, 449.5, offset.Allocations[1].Remaining())
+
+	usd := plan(list[1])
+	require.Zero(t, usd.Amount, "a one-sided position has nothing to net")
+	require.Empty(t, usd.Allocations)
+}
+
+func TestSettleRecordsAllocationsAndPostsJournal(t *testing.T) {
+	ctx := context.Background()
+	repo := nettingRepo()
+	ledger := &fakeLedger{}
+	svc := NewService(repo)
+	svc.SetIntegrationHandler(ledger)
+
+	_, err := svc.Settle(ctx, SettleInput{LinkID: 1, Currency: "IDR"})
+	require.ErrorIs(t, err, ErrSettleDateRequired)
+	_, err = svc.Settle(ctx, SettleInput{LinkID: 2, Currency: "IDR", SettledAt: day(16)})
+	require.ErrorIs(t, err, ErrLinkNotFound)
+	_, err = svc.Settle(ctx, SettleInput{LinkID: 1, Currency: "usd", SettledAt: day(16)})
+	require.ErrorIs(t, err, ErrNothingToNet)
+
+	settlement, err := svc.Settle(ctx, SettleInput{LinkID: 1, Currency: " idr ", SettledAt: day(16), Note: "Q3 offset"})
+	require.NoError(t, err)
+	require.Equal(t, "IDR", settlement.Currency)
+	require.Equal(t, 750.5, settlement.Amount)
+	require.Len(t, settlement.Allocations, 4)
+	require.True(t, settlement.JournalPosted)
+	require.Equal(t, []SettledEvent{{ID: 1, Number: "NET-1", Currency: "IDR", Amount: 750.5, SettledAt: day(16)}}, ledger.events)
+
+	_, list, err := svc.Positions(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 449.5, list[0].ARTotal)
+	require.Zero(t, list[0].APTotal)
+
+	_, err = svc.Settle(ctx, SettleInput{LinkID: 1, Currency: "IDR", SettledAt: day(17)})
+	require.ErrorIs(t, err, ErrNothingToNet, "payables are cleared")
+}
+
+func TestSettleRespectsPeriodGuardAndKeepsSettlementWhenJournalFails(t *testing.T) {
+	ctx := context.Background()
+	repo := nettingRepo()
+	ledger := &fakeLedger{err: accounting.ErrMappingNotFound}
+	svc := NewService(repo)
+	svc.SetIntegrationHandler(ledger)
+
+	svc.SetPeriodGuard(stubPeriodGuard{err: accounting.ErrPeriodLocked})
+	_, err := svc.Settle(ctx, SettleInput{LinkID: 1, Currency: "IDR", SettledAt: day(16)})
+	require.ErrorIs(t, err, accounting.ErrPeriodLocked)
+	require.Empty(t, repo.settlements)
+
+	svc.SetPeriodGuard(stubPeriodGuard{})
+	settlement, err := svc.Settle(ctx, SettleInput{LinkID: 1, Currency: "IDR", SettledAt: day(16)})
+	require.ErrorIs(t, err, ErrJournalPending)
+	require.NotZero(t, settlement.ID)
+	require.False(t, settlement.JournalPosted)
+	require.Len(t, repo.settlements, 1)
+
+	require.ErrorIs(t, svc.RetryJournal(ctx, settlement.ID), ErrJournalPending)
+	ledger.err = nil
+	require.NoError(t, svc.RetryJournal(ctx, settlement.ID))
+	require.Len(t, ledger.events, 1)
+	require.ErrorIs(t, svc.RetryJournal(ctx, 99), ErrSettlementNotFound)
+}
+
+func TestCreateLinkRequiresBothSides(t *testing.T) {
+	svc := NewService(&memoryRepo{})
+	_, err := svc.CreateLink(context.Background(), LinkInput{CustomerID: 10})
+	require.ErrorIs(t, err, ErrLinkRequired)
+}
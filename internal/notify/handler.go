@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// Handler exposes the per-user notification settings page.
+type Handler struct {
+	logger    *slog.Logger
+	service   *Service
+	templates *view.Engine
+	csrf      *shared.CSRFManager
+}
+
+// NewHandler builds Handler instance.
+func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, csrf *shared.CSRFManager) *Handler {
+	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf}
+}
+
+// MountRoutes registers notification settings routes.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Get("/notifications", h.showSettings)
+	r.Post("/notifications", h.saveSettings)
+}
+
+func (h *Handler) showSettings(w http.ResponseWriter, r *http.Request) {
+	userID := currentUser(r)
+	if userID == 0 {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+	enabled, err := h.service.ApprovalEmailsEnabled(r.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load notification settings", slog.Any("error", err), slog.Int64("user_id", userID))
+		h.render(w, r, map[string]any{"Error": shared.UserSafeMessage(err)}, http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, map[string]any{"ApprovalEmails": enabled}, http.StatusOK)
+}
+
+func (h *Handler) saveSettings(w http.ResponseWriter, r *http.Request) {
+	userID := currentUser(r)
+	if userID == 0 {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	enabled := r.PostFormValue("approval_emails") == "on"
+	if err := h.service.SetApprovalEmails(r.Context(), userID, enabled); err != nil {
+		h.logger.ErrorContext(r.Context(), "save notification settings", slog.Any("error", err), slog.Int64("user_id", userID))
+		h.render(w, r, map[string]any{"ApprovalEmails": enabled, "Error": shared.UserSafeMessage(err)}, http.StatusInternalServerError)
+		return
+	}
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: "success", Message: "Notification settings saved"})
+	}
+	http.Redirect(w, r, "/account/notifications", http.StatusSeeOther)
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, data map[string]any, status int) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{Title: "Notifications", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, "pages/account/notifications.html", viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
+	}
+}
+
+func currentUser(r *http.Request) int64 {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(sess.User(), 10, 64)
+	return id
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository reads recipients and notification preferences from PostgreSQL.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs a Repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// ApproverRecipients returns active, opted-in users granted the permission.
+func (r *Repository) ApproverRecipients(ctx context.Context, permission string) ([]Recipient, error) {
+	const query = `
+		SELECT DISTINCT u.id, u.email, u.name
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE p.name = $1 AND u.is_active AND u.notify_approval_emails
+		ORDER BY u.id
+	`
+	rows, err := r.pool.Query(ctx, query, permission)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Recipient
+	for rows.Next() {
+		var rcpt Recipient
+		if err := rows.Scan(&rcpt.UserID, &rcpt.Email, &rcpt.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, rcpt)
+	}
+	return out, rows.Err()
+}
+
+// Recipient returns the user when active and opted in to approval emails.
+func (r *Repository) Recipient(ctx context.Context, userID int64) (Recipient, bool, error) {
+	const query = `
+		SELECT id, email, name
+		FROM users
+		WHERE id = $1 AND is_active AND notify_approval_emails
+	`
+	var rcpt Recipient
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&rcpt.UserID, &rcpt.Email, &rcpt.Name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Recipient{}, false, nil
+		}
+		return Recipient{}, false, err
+	}
+	return rcpt, true, nil
+}
+
+// ApprovalEmailsEnabled reports the user's approval email preference.
+func (r *Repository) ApprovalEmailsEnabled(ctx context.Context, userID int64) (bool, error) {
+	var enabled bool
+	err := r.pool.QueryRow(ctx, `SELECT notify_approval_emails FROM users WHERE id = $1`, userID).Scan(&enabled)
+	return enabled, err
+}
+
+// SetApprovalEmails stores the user's approval email preference.
+func (r *Repository) SetApprovalEmails(ctx context.Context, userID int64, enabled bool) error {
+	_, err := r.pool.Exec(ctx, `UPDATE users SET notify_approval_emails = $2, updated_at = NOW() WHERE id = $1`, userID, enabled)
+	return err
+}
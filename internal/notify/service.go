@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+// Recipient is a user that accepts approval emails.
+type Recipient struct {
+	UserID int64
+	Email  string
+	Name   string
+}
+
+// Store resolves recipients while honouring per-user opt-out.
+type Store interface {
+	ApproverRecipients(ctx context.Context, permission string) ([]Recipient, error)
+	Recipient(ctx context.Context, userID int64) (Recipient, bool, error)
+	ApprovalEmailsEnabled(ctx context.Context, userID int64) (bool, error)
+	SetApprovalEmails(ctx context.Context, userID int64, enabled bool) error
+}
+
+// EmailQueue enqueues outbound emails for the worker.
+type EmailQueue interface {
+	EnqueueSendEmail(ctx context.Context, payload jobs.SendEmailPayload) (*asynq.TaskInfo, error)
+}
+
+// Service renders approval notices into emails and queues them for delivery.
+type Service struct {
+	store   Store
+	queue   EmailQueue
+	baseURL string
+	logger  *slog.Logger
+}
+
+// NewService constructs the approval notification service.
+func NewService(store Store, queue EmailQueue, baseURL string, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{store: store, queue: queue, baseURL: strings.TrimRight(baseURL, "/"), logger: logger}
+}
+
+// NotifyApproval implements shared.ApprovalNotifier. Submissions go to every
+// approver holding the approve permission except the submitter; approvals and
+// rejections go back to the requester, as do comments left by someone else.
+func (s *Service) NotifyApproval(ctx context.Context, notice shared.ApprovalNotice) error {
+	if s == nil || s.queue == nil {
+		return nil
+	}
+	recipients, err := s.recipients(ctx, notice)
+	if err != nil {
+		return fmt.Errorf("resolve recipients: %w", err)
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, rcpt := range recipients {
+		msg, err := renderApprovalEmail(notice, rcpt, s.baseURL)
+		if err != nil {
+			return err
+		}
+		if _, err := s.queue.EnqueueSendEmail(ctx, jobs.SendEmailPayload{To: rcpt.Email, Subject: msg.Subject, Body: msg.Body}); err != nil {
+			s.logger.WarnContext(ctx, "enqueue approval email", slog.String("to", rcpt.Email), slog.Any("error", err))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) recipients(ctx context.Context, notice shared.ApprovalNotice) ([]Recipient, error) {
+	switch notice.Action {
+	case shared.ApprovalSubmit:
+		if notice.ApprovePermission == "" {
+			return nil, nil
+		}
+		approvers, err := s.store.ApproverRecipients(ctx, notice.ApprovePermission)
+		if err != nil {
+			return nil, err
+		}
+		out := approvers[:0]
+		for _, rcpt := range approvers {
+			if rcpt.UserID != notice.ActorID {
+				out = append(out, rcpt)
+			}
+		}
+		return out, nil
+	case shared.ApprovalApprove, shared.ApprovalReject:
+		if notice.RequesterID == 0 {
+			return nil, nil
+		}
+		rcpt, ok, err := s.store.Recipient(ctx, notice.RequesterID)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []Recipient{rcpt}, nil
+	case shared.ApprovalComment:
+		if notice.RequesterID == 0 || notice.RequesterID == notice.ActorID {
+			return nil, nil
+		}
+		rcpt, ok, err := s.store.Recipient(ctx, notice.RequesterID)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []Recipient{rcpt}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// ApprovalEmailsEnabled reports whether the user receives approval emails.
+func (s *Service) ApprovalEmailsEnabled(ctx context.Context, userID int64) (bool, error) {
+	return s.store.ApprovalEmailsEnabled(ctx, userID)
+}
+
+// SetApprovalEmails toggles the user's approval email opt-out.
+func (s *Service) SetApprovalEmails(ctx context.Context, userID int64, enabled bool) error {
+	return s.store.SetApprovalEmails(ctx, userID, enabled)
+}
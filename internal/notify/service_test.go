@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+type fakeStore struct {
+	approvers map[string][]Recipient
+	users     map[int64]Recipient
+}
+
+func (f *fakeStore) ApproverRecipients(_ context.Context, permission string) ([]Recipient, error) {
+	return append([]Recipient(nil), f.approvers[permission]...), nil
+}
+
+func (f *fakeStore) Recipient(_ context.Context, userID int64) (Recipient, bool, error) {
+	rcpt, ok := f.users[userID]
+	return rcpt, ok, nil
+}
+
+func (f *fakeStore) ApprovalEmailsEnabled(_ context.Context, userID int64) (bool, error) {
+	_, ok := f.users[userID]
+	return ok, nil
+}
+
+func (f *fakeStore) SetApprovalEmails(context.Context, int64, bool) error { return nil }
+
+type fakeQueue struct {
+	sent []jobs.SendEmailPayload
+}
+
+func (f *fakeQueue) EnqueueSendEmail(_ context.Context, payload jobs.SendEmailPayload) (*asynq.TaskInfo, error) {
+	f.sent = append(f.sent, payload)
+	return &asynq.TaskInfo{}, nil
+}
+
+func TestNotifyApprovalSubmitSkipsSubmitter(t *testing.T) {
+	store := &fakeStore{approvers: map[string][]Recipient{
+		shared.PermQuotationApprove: {
+			{UserID: 1, Email: "requester@example.com"},
+			{UserID: 2, Email: "manager@example.com", Name: "Manager"},
+		},
+	}}
+	queue := &fakeQueue{}
+	svc := NewService(store, queue, "https://erp.example.com/", nil)
+
+	err := svc.NotifyApproval(context.Background(), shared.ApprovalNotice{
+		Action:            shared.ApprovalSubmit,
+		DocumentLabel:     "Quotation",
+		DocumentNumber:    "QT-001",
+		Path:              "/sales/quotations/9",
+		Summary:           "Customer #3, total IDR 1500.00",
+		ActorID:           1,
+		RequesterID:       1,
+		ApprovePermission: shared.PermQuotationApprove,
+	})
+	if err != nil {
+		t.Fatalf("NotifyApproval: %v", err)
+	}
+	if len(queue.sent) != 1 || queue.sent[0].To != "manager@example.com" {
+		t.Fatalf("expected single email to manager, got %+v", queue.sent)
+	}
+	msg := queue.sent[0]
+	if msg.Subject != "[Approval needed] Quotation QT-001" {
+		t.Fatalf("unexpected subject %q", msg.Subject)
+	}
+	for _, want := range []string{"Hello Manager,", "https://erp.example.com/sales/quotations/9", "Customer #3, total IDR 1500.00"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Fatalf("body missing %q:\n%s", want, msg.Body)
+		}
+	}
+}
+
+func TestNotifyApprovalRejectGoesToRequester(t *testing.T) {
+	store := &fakeStore{users: map[int64]Recipient{7: {UserID: 7, Email: "sales@example.com"}}}
+	queue := &fakeQueue{}
+	svc := NewService(store, queue, "http://localhost:8080", nil)
+
+	err := svc.NotifyApproval(context.Background(), shared.ApprovalNotice{
+		Action:         shared.ApprovalReject,
+		DocumentLabel:  "Quotation",
+		DocumentNumber: "QT-002",
+		Path:           "/sales/quotations/10",
+		ActorID:        2,
+		RequesterID:    7,
+		Reason:         "Discount too high",
+	})
+	if err != nil {
+		t.Fatalf("NotifyApproval: %v", err)
+	}
+	if len(queue.sent) != 1 || queue.sent[0].To != "sales@example.com" {
+		t.Fatalf("expected email to requester, got %+v", queue.sent)
+	}
+	if !strings.Contains(queue.sent[0].Body, "Reason:   Discount too high") {
+		t.Fatalf("body missing reason:\n%s", queue.sent[0].Body)
+	}
+}
+
+func TestNotifyCommentGoesToCreatorUnlessSelf(t *testing.T) {
+	store := &fakeStore{users: map[int64]Recipient{7: {UserID: 7, Email: "sales@example.com"}}}
+	queue := &fakeQueue{}
+	svc := NewService(store, queue, "http://localhost:8080", nil)
+	notice := shared.ApprovalNotice{
+		Action:         shared.ApprovalComment,
+		DocumentLabel:  "Sales Order",
+		DocumentNumber: "SO-003",
+		Path:           "/sales/orders/11",
+		ActorID:        7,
+		RequesterID:    7,
+		Comment:        "Customer asked to split delivery",
+		CommentAuthor:  "Sales Rep",
+	}
+
+	if err := svc.NotifyApproval(context.Background(), notice); err != nil {
+		t.Fatalf("NotifyApproval: %v", err)
+	}
+	if len(queue.sent) != 0 {
+		t.Fatalf("expected no email for own comment, got %+v", queue.sent)
+	}
+
+	notice.ActorID = 2
+	notice.CommentAuthor = "Manager"
+	if err := svc.NotifyApproval(context.Background(), notice); err != nil {
+		t.Fatalf("NotifyApproval: %v", err)
+	}
+	if len(queue.sent) != 1 || queue.sent[0].Subject != "[Comment] Sales Order SO-003" {
+		t.Fatalf("expected comment email to creator, got %+v", queue.sent)
+	}
+	for _, want := range []string{"Manager commented on your Sales Order SO-003", "Customer asked to split delivery"} {
+		if !strings.Contains(queue.sent[0].Body, want) {
+			t.Fatalf("body missing %q:\n%s", want, queue.sent[0].Body)
+		}
+	}
+}
+
+func TestNotifyApprovalOptedOutRequesterSkipped(t *testing.T) {
+	queue := &fakeQueue{}
+	svc := NewService(&fakeStore{}, queue, "", nil)
+	err := svc.NotifyApproval(context.Background(), shared.ApprovalNotice{
+		Action:      shared.ApprovalApprove,
+		RequesterID: 7,
+	})
+	if err != nil {
+		t.Fatalf("NotifyApproval: %v", err)
+	}
+	if len(queue.sent) != 0 {
+		t.Fatalf("expected no email for opted-out requester, got %+v", queue.sent)
+	}
+}
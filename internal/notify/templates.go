@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type approvalEmail struct {
+	Subject string
+	Body    string
+}
+
+type approvalEmailData struct {
+	Notice    shared.ApprovalNotice
+	Recipient Recipient
+	Link      string
+}
+
+var approvalTemplates = template.Must(template.New("approval").Parse(`
+{{- define "greeting" }}Hello {{ if .Recipient.Name }}{{ .Recipient.Name }}{{ else }}{{ .Recipient.Email }}{{ end }},{{ end }}
+{{- define "details" }}
+Document: {{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }}
+{{- if .Notice.Summary }}
+Summary:  {{ .Notice.Summary }}{{ end }}
+Link:     {{ .Link }}
+{{ end }}
+{{- define "footer" }}
+You can turn off approval emails from your notification settings.
+{{- end }}
+
+{{- define "subject:SUBMIT" }}[Approval needed] {{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }}{{ end }}
+{{- define "body:SUBMIT" }}{{ template "greeting" . }}
+
+{{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }} has been submitted and is waiting for your approval.
+{{ template "details" . }}{{ template "footer" . }}{{ end }}
+
+{{- define "subject:APPROVE" }}[Approved] {{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }}{{ end }}
+{{- define "body:APPROVE" }}{{ template "greeting" . }}
+
+Your {{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }} has been approved.
+{{ template "details" . }}{{ template "footer" . }}{{ end }}
+
+{{- define "subject:REJECT" }}[Rejected] {{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }}{{ end }}
+{{- define "body:REJECT" }}{{ template "greeting" . }}
+
+Your {{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }} has been rejected.
+{{- if .Notice.Reason }}
+Reason:   {{ .Notice.Reason }}{{ end }}
+{{ template "details" . }}{{ template "footer" . }}{{ end }}
+
+{{- define "subject:COMMENT" }}[Comment] {{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }}{{ end }}
+{{- define "body:COMMENT" }}{{ template "greeting" . }}
+
+{{ if .Notice.CommentAuthor }}{{ .Notice.CommentAuthor }}{{ else }}Someone{{ end }} commented on your {{ .Notice.DocumentLabel }} {{ .Notice.DocumentNumber }}:
+
+{{ .Notice.Comment }}
+{{ template "details" . }}{{ template "footer" . }}{{ end }}
+`))
+
+// renderApprovalEmail builds the subject and plain-text body for a notice.
+func renderApprovalEmail(notice shared.ApprovalNotice, rcpt Recipient, baseURL string) (approvalEmail, error) {
+	data := approvalEmailData{Notice: notice, Recipient: rcpt, Link: baseURL + notice.Path}
+	var subject, body strings.Builder
+	if err := approvalTemplates.ExecuteTemplate(&subject, "subject:"+string(notice.Action), data); err != nil {
+		return approvalEmail{}, fmt.Errorf("render approval subject: %w", err)
+	}
+	if err := approvalTemplates.ExecuteTemplate(&body, "body:"+string(notice.Action), data); err != nil {
+		return approvalEmail{}, fmt.Errorf("render approval body: %w", err)
+	}
+	return approvalEmail{Subject: subject.String(), Body: body.String() + "\n"}, nil
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 )
 
 // Metrics mengumpulkan metrik Prometheus untuk aplikasi.
@@ -43,7 +45,7 @@ func NewMetrics() *Metrics {
 func (m *Metrics) Handler() http.Handler {
 	if m == nil {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			httpx.Error(w, r, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 		})
 	}
 	return m.handler
@@ -0,0 +1,95 @@
+package openitems
+
+import (
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/ap"
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+	deliveryorders "github.com/odyssey-erp/odyssey-erp/internal/delivery/orders"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/orders"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
+)
+
+// DocumentType identifies the module an open item comes from.
+type DocumentType string
+
+const (
+	DocQuotation     DocumentType = "QUOTATION"
+	DocSalesOrder    DocumentType = "SALES_ORDER"
+	DocDeliveryOrder DocumentType = "DELIVERY_ORDER"
+	DocAPInvoice     DocumentType = "AP_INVOICE"
+	DocARInvoice     DocumentType = "AR_INVOICE"
+)
+
+// DocumentTypes lists the covered documents in report order.
+var DocumentTypes = []DocumentType{DocQuotation, DocSalesOrder, DocDeliveryOrder, DocAPInvoice, DocARInvoice}
+
+// Label returns a human readable document name.
+func (t DocumentType) Label() string {
+	switch t {
+	case DocQuotation:
+		return "Quotation"
+	case DocSalesOrder:
+		return "Sales Order"
+	case DocDeliveryOrder:
+		return "Delivery Order"
+	case DocAPInvoice:
+		return "AP Invoice"
+	case DocARInvoice:
+		return "AR Invoice"
+	default:
+		return string(t)
+	}
+}
+
+// OpenStates is the shared definition of which statuses keep a document open,
+// built from each module's own status constants.
+var OpenStates = map[DocumentType][]string{
+	DocQuotation: {
+		string(quotations.QuotationStatusDraft),
+		string(quotations.QuotationStatusSubmitted),
+		string(quotations.QuotationStatusApproved),
+	},
+	DocSalesOrder: {
+		string(orders.SalesOrderStatusDraft),
+		string(orders.SalesOrderStatusConfirmed),
+		string(orders.SalesOrderStatusProcessing),
+	},
+	DocDeliveryOrder: {
+		string(deliveryorders.StatusDraft),
+		string(deliveryorders.StatusConfirmed),
+		string(deliveryorders.StatusInTransit),
+	},
+	DocAPInvoice: {string(ap.APStatusDraft)},
+	DocARInvoice: {string(ar.ARStatusDraft)},
+}
+
+// Item is one open document.
+type Item struct {
+	Type         DocumentType
+	ID           int64
+	Number       string
+	Counterparty string
+	Status       string
+	DocumentDate time.Time
+	DueDate      *time.Time
+	Currency     string
+	Amount       float64
+	AgeDays      int
+}
+
+// Summary totals the open items of one document type in one currency.
+type Summary struct {
+	Type     DocumentType
+	Currency string
+	Count    int
+	Amount   float64
+}
+
+// Report aggregates open documents for a company as of a date.
+type Report struct {
+	CompanyID int64
+	AsOf      time.Time
+	Items     []Item
+	Summaries []Summary
+}
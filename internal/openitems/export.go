@@ -0,0 +1,81 @@
+package openitems
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/xlsx"
+)
+
+var exportHeader = []string{"Type", "Number", "Counterparty", "Status", "Document Date", "Due Date", "Age (Days)", "Currency", "Amount"}
+
+// WriteCSV streams the open items as a flat CSV list.
+func WriteCSV(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeader); err != nil {
+		return err
+	}
+	for _, item := range report.Items {
+		record := []string{
+			item.Type.Label(),
+			item.Number,
+			item.Counterparty,
+			item.Status,
+			item.DocumentDate.Format("2006-01-02"),
+			formatDueDate(item.DueDate),
+			strconv.Itoa(item.AgeDays),
+			item.Currency,
+			strconv.FormatFloat(item.Amount, 'f', 2, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteXLSX renders a summary sheet with counts and amounts per document type
+// and currency followed by the full open items list.
+func WriteXLSX(w io.Writer, report Report, generatedAt time.Time) error {
+	summary := xlsx.Sheet{Name: "Summary", Rows: [][]xlsx.Cell{
+		{xlsx.Text("Report"), xlsx.Text("Open Items")},
+		{xlsx.Text("Company ID"), xlsx.Textf("%d", report.CompanyID)},
+		{xlsx.Text("As Of"), xlsx.Text(report.AsOf.Format("2006-01-02"))},
+		{xlsx.Text("Generated At"), xlsx.Text(generatedAt.UTC().Format(time.RFC3339))},
+		{},
+		{xlsx.Text("Type"), xlsx.Text("Currency"), xlsx.Text("Open Documents"), xlsx.Text("Amount")},
+	}}
+	for _, s := range report.Summaries {
+		summary.Rows = append(summary.Rows, []xlsx.Cell{xlsx.Text(s.Type.Label()), xlsx.Text(s.Currency), xlsx.Textf("%d", s.Count), xlsx.Num(s.Amount)})
+	}
+
+	header := make([]xlsx.Cell, len(exportHeader))
+	for i, title := range exportHeader {
+		header[i] = xlsx.Text(title)
+	}
+	items := xlsx.Sheet{Name: "Open Items", Rows: [][]xlsx.Cell{header}}
+	for _, item := range report.Items {
+		items.Rows = append(items.Rows, []xlsx.Cell{
+			xlsx.Text(item.Type.Label()),
+			xlsx.Text(item.Number),
+			xlsx.Text(item.Counterparty),
+			xlsx.Text(item.Status),
+			xlsx.Text(item.DocumentDate.Format("2006-01-02")),
+			xlsx.Text(formatDueDate(item.DueDate)),
+			xlsx.Textf("%d", item.AgeDays),
+			xlsx.Text(item.Currency),
+			xlsx.Num(item.Amount),
+		})
+	}
+	return xlsx.Write(w, []xlsx.Sheet{summary, items})
+}
+
+func formatDueDate(due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+	return due.Format("2006-01-02")
+}
@@ -0,0 +1,95 @@
+package openitems
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/xlsx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// Handler serves the open items export.
+type Handler struct {
+	logger  *slog.Logger
+	service *Service
+	rbac    rbac.Middleware
+}
+
+// NewHandler constructs the handler.
+func NewHandler(logger *slog.Logger, service *Service, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, rbac: rbac}
+}
+
+// MountRoutes registers routes.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Route("/reports/open-items", func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermReportView))
+		r.Get("/export.csv", h.exportCSV)
+		r.Get("/export.xlsx", h.exportXLSX)
+	})
+}
+
+func (h *Handler) exportCSV(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename="+exportFilename(report, "csv"))
+	if err := WriteCSV(w, report); err != nil {
+		h.logger.ErrorContext(r.Context(), "stream open items csv", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) exportXLSX(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", xlsx.ContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+exportFilename(report, "xlsx"))
+	if err := WriteXLSX(w, report, time.Now()); err != nil {
+		h.logger.ErrorContext(r.Context(), "stream open items xlsx", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) report(w http.ResponseWriter, r *http.Request) (Report, bool) {
+	query := r.URL.Query()
+	requested, _ := strconv.ParseInt(strings.TrimSpace(query.Get("company_id")), 10, 64)
+	companyID := shared.ScopedCompanyID(r.Context(), requested, 0)
+
+	var asOf time.Time
+	if raw := strings.TrimSpace(query.Get("as_of")); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			httpx.Error(w, r, "as_of must be YYYY-MM-DD", http.StatusBadRequest)
+			return Report{}, false
+		}
+		asOf = parsed
+	}
+
+	report, err := h.service.Report(r.Context(), companyID, asOf)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCompany) {
+			httpx.Error(w, r, "company_id is required", http.StatusBadRequest)
+			return Report{}, false
+		}
+		h.logger.ErrorContext(r.Context(), "open items report", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return Report{}, false
+	}
+	return report, true
+}
+
+func exportFilename(report Report, ext string) string {
+	return fmt.Sprintf("open_items_%d_%s.%s", report.CompanyID, report.AsOf.Format("20060102"), ext)
+}
@@ -0,0 +1,85 @@
+package openitems
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openItemQueries select the open sales documents of each type for a company.
+// Every query takes the company ID, the open statuses from OpenStates and the
+// as-of cutoff; documents created after the cutoff are left out. AP and AR
+// invoices come from their own modules' list queries instead.
+var openItemQueries = map[DocumentType]string{
+	DocQuotation: `
+SELECT q.id, q.doc_number, c.name, q.status::text, q.quote_date, q.valid_until::timestamptz,
+       q.currency, q.total_amount::float8
+FROM quotations q
+JOIN customers c ON c.id = q.customer_id
+WHERE q.company_id = $1 AND q.status::text = ANY($2::text[]) AND q.created_at <= $3
+ORDER BY q.quote_date, q.id`,
+	DocSalesOrder: `
+SELECT so.id, so.doc_number, c.name, so.status::text, so.order_date, so.expected_delivery_date::timestamptz,
+       so.currency, so.total_amount::float8
+FROM sales_orders so
+JOIN customers c ON c.id = so.customer_id
+WHERE so.company_id = $1 AND so.status::text = ANY($2::text[]) AND so.created_at <= $3
+ORDER BY so.order_date, so.id`,
+	DocDeliveryOrder: `
+SELECT dlv.id, dlv.doc_number, c.name, dlv.status::text, dlv.delivery_date, NULL::timestamptz,
+       so.currency, COALESCE((
+           SELECT SUM(dol.quantity_to_deliver * dol.unit_price)
+           FROM delivery_order_lines dol
+           WHERE dol.delivery_order_id = dlv.id
+       ), 0)::float8
+FROM delivery_orders dlv
+JOIN customers c ON c.id = dlv.customer_id
+JOIN sales_orders so ON so.id = dlv.sales_order_id
+WHERE dlv.company_id = $1 AND dlv.status::text = ANY($2::text[]) AND dlv.created_at <= $3
+ORDER BY dlv.delivery_date, dlv.id`,
+}
+
+// Repository reads open documents across modules.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs the repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// ListOpenItems returns the company's documents of docType whose status is in
+// states and that were created by cutoff.
+func (r *Repository) ListOpenItems(ctx context.Context, companyID int64, docType DocumentType, states []string, cutoff time.Time) ([]Item, error) {
+	query, ok := openItemQueries[docType]
+	if !ok {
+		return nil, fmt.Errorf("openitems: unknown document type %q", docType)
+	}
+	rows, err := r.pool.Query(ctx, query, companyID, states, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		item := Item{Type: docType}
+		if err := rows.Scan(
+			&item.ID,
+			&item.Number,
+			&item.Counterparty,
+			&item.Status,
+			&item.DocumentDate,
+			&item.DueDate,
+			&item.Currency,
+			&item.Amount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,188 @@
+package openitems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/ap"
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+)
+
+// ErrInvalidCompany is returned when the report is requested without a company.
+var ErrInvalidCompany = errors.New("openitems: company is required")
+
+// RepositoryPort lists open sales documents of one type.
+type RepositoryPort interface {
+	ListOpenItems(ctx context.Context, companyID int64, docType DocumentType, states []string, cutoff time.Time) ([]Item, error)
+}
+
+// ARInvoiceLister is the AR invoice list the report reads receivables from.
+type ARInvoiceLister interface {
+	ListARInvoices(ctx context.Context, req ar.ListARInvoicesRequest) ([]ar.ARInvoice, error)
+}
+
+// APInvoiceLister is the AP invoice list the report reads payables from.
+type APInvoiceLister interface {
+	ListAPInvoices(ctx context.Context, req ap.ListAPInvoicesRequest) ([]ap.APInvoice, error)
+}
+
+// Service assembles the cross-module open items report.
+type Service struct {
+	repo       RepositoryPort
+	arInvoices ARInvoiceLister
+	apInvoices APInvoiceLister
+	now        func() time.Time
+}
+
+// NewService builds the service.
+func NewService(repo RepositoryPort, arInvoices ARInvoiceLister, apInvoices APInvoiceLister) *Service {
+	return &Service{repo: repo, arInvoices: arInvoices, apInvoices: apInvoices, now: time.Now}
+}
+
+// Report collects every document of the company that was open at the end of
+// asOf (today when zero), ages it against asOf and orders items oldest first
+// within each document type. Summaries total each type per currency.
+func (s *Service) Report(ctx context.Context, companyID int64, asOf time.Time) (Report, error) {
+	if companyID <= 0 {
+		return Report{}, ErrInvalidCompany
+	}
+	if asOf.IsZero() {
+		asOf = s.now()
+	}
+	asOf = truncateDay(asOf)
+	cutoff := asOf.AddDate(0, 0, 1).Add(-time.Microsecond)
+
+	report := Report{CompanyID: companyID, AsOf: asOf}
+	for _, docType := range DocumentTypes {
+		items, err := s.listOpenItems(ctx, companyID, docType, cutoff)
+		if err != nil {
+			return Report{}, fmt.Errorf("list open %s: %w", docType.Label(), err)
+		}
+		for i := range items {
+			items[i].Type = docType
+			items[i].AgeDays = ageDays(items[i].DocumentDate, asOf)
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].AgeDays > items[j].AgeDays
+		})
+		report.Items = append(report.Items, items...)
+		report.Summaries = append(report.Summaries, summarise(docType, items)...)
+	}
+	return report, nil
+}
+
+func (s *Service) listOpenItems(ctx context.Context, companyID int64, docType DocumentType, cutoff time.Time) ([]Item, error) {
+	switch docType {
+	case DocARInvoice:
+		invoices, err := s.arInvoices.ListARInvoices(ctx, ar.ListARInvoicesRequest{
+			CompanyID:       companyID,
+			ToDate:          cutoff,
+			IncludeArchived: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var items []Item
+		for _, inv := range invoices {
+			if !draftAt(string(inv.Status), OpenStates[docType], inv.PostedAt, inv.VoidedAt, cutoff) {
+				continue
+			}
+			due := inv.DueAt
+			items = append(items, Item{
+				ID:           inv.ID,
+				Number:       inv.Number,
+				Counterparty: inv.CustomerName,
+				Status:       string(ar.ARStatusDraft),
+				DocumentDate: inv.CreatedAt,
+				DueDate:      &due,
+				Currency:     inv.Currency,
+				Amount:       inv.Total,
+			})
+		}
+		return items, nil
+	case DocAPInvoice:
+		invoices, err := s.apInvoices.ListAPInvoices(ctx, ap.ListAPInvoicesRequest{
+			CompanyID: companyID,
+			ToDate:    cutoff,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var items []Item
+		for _, inv := range invoices {
+			if !draftAt(string(inv.Status), OpenStates[docType], inv.PostedAt, inv.VoidedAt, cutoff) {
+				continue
+			}
+			due := inv.DueAt
+			items = append(items, Item{
+				ID:           inv.ID,
+				Number:       inv.Number,
+				Counterparty: inv.SupplierName,
+				Status:       string(ap.APStatusDraft),
+				DocumentDate: inv.IssuedAt,
+				DueDate:      &due,
+				Currency:     inv.Currency,
+				Amount:       inv.Total,
+			})
+		}
+		return items, nil
+	default:
+		return s.repo.ListOpenItems(ctx, companyID, docType, OpenStates[docType], cutoff)
+	}
+}
+
+// draftAt reports whether an invoice was still unposted at cutoff. Invoices
+// only leave draft by being posted or voided, so one that is closed now was
+// open at cutoff when that happened later.
+func draftAt(status string, openStates []string, postedAt, voidedAt *time.Time, cutoff time.Time) bool {
+	if slices.Contains(openStates, status) {
+		return true
+	}
+	closedAt := postedAt
+	if closedAt == nil || (voidedAt != nil && voidedAt.Before(*closedAt)) {
+		closedAt = voidedAt
+	}
+	return closedAt != nil && closedAt.After(cutoff)
+}
+
+// summarise totals items per currency, in currency order. A type without open
+// items still gets an empty row so every type shows up in the summary.
+func summarise(docType DocumentType, items []Item) []Summary {
+	if len(items) == 0 {
+		return []Summary{{Type: docType}}
+	}
+	byCurrency := make(map[string]*Summary)
+	var currencies []string
+	for _, item := range items {
+		summary, ok := byCurrency[item.Currency]
+		if !ok {
+			summary = &Summary{Type: docType, Currency: item.Currency}
+			byCurrency[item.Currency] = summary
+			currencies = append(currencies, item.Currency)
+		}
+		summary.Count++
+		summary.Amount += item.Amount
+	}
+	sort.Strings(currencies)
+	summaries := make([]Summary, 0, len(currencies))
+	for _, currency := range currencies {
+		summaries = append(summaries, *byCurrency[currency])
+	}
+	return summaries
+}
+
+func ageDays(docDate, asOf time.Time) int {
+	days := int(asOf.Sub(truncateDay(docDate)).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
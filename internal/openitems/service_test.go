@@ -0,0 +1,164 @@
+package openitems
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/ap"
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+)
+
+type stubRepo struct {
+	items  map[DocumentType][]Item
+	states map[DocumentType][]string
+	cutoff time.Time
+	err    error
+}
+
+func (s *stubRepo) ListOpenItems(_ context.Context, _ int64, docType DocumentType, states []string, cutoff time.Time) ([]Item, error) {
+	if s.states == nil {
+		s.states = make(map[DocumentType][]string)
+	}
+	s.states[docType] = states
+	s.cutoff = cutoff
+	return s.items[docType], s.err
+}
+
+type stubARInvoices struct {
+	invoices []ar.ARInvoice
+	req      ar.ListARInvoicesRequest
+}
+
+func (s *stubARInvoices) ListARInvoices(_ context.Context, req ar.ListARInvoicesRequest) ([]ar.ARInvoice, error) {
+	s.req = req
+	return s.invoices, nil
+}
+
+type stubAPInvoices struct {
+	invoices []ap.APInvoice
+	req      ap.ListAPInvoicesRequest
+}
+
+func (s *stubAPInvoices) ListAPInvoices(_ context.Context, req ap.ListAPInvoicesRequest) ([]ap.APInvoice, error) {
+	s.req = req
+	return s.invoices, nil
+}
+
+func TestReportAgesAndSummarisesOpenItems(t *testing.T) {
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	due := asOf.AddDate(0, 0, 5)
+	repo := &stubRepo{items: map[DocumentType][]Item{
+		DocSalesOrder: {
+			{ID: 1, Number: "SO-1", DocumentDate: asOf.AddDate(0, 0, -3), Amount: 100},
+			{ID: 2, Number: "SO-2", DocumentDate: asOf.AddDate(0, 0, -10), Amount: 250, DueDate: &due},
+		},
+	}}
+	arInvoices := &stubARInvoices{invoices: []ar.ARInvoice{
+		{ID: 9, Number: "INV-9", Status: ar.ARStatusDraft, CreatedAt: asOf.Add(-30 * time.Hour), Total: 40},
+	}}
+
+	report, err := NewService(repo, arInvoices, &stubAPInvoices{}).Report(context.Background(), 1, asOf.Add(15*time.Hour))
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(repo.states) != 3 {
+		t.Fatalf("expected every sales document type to be queried, got %v", repo.states)
+	}
+	if want := asOf.AddDate(0, 0, 1).Add(-time.Microsecond); !repo.cutoff.Equal(want) || !arInvoices.req.ToDate.Equal(want) {
+		t.Fatalf("expected documents cut off at the end of the as-of day, got %v and %v", repo.cutoff, arInvoices.req.ToDate)
+	}
+	if arInvoices.req.CompanyID != 1 {
+		t.Fatalf("expected AR invoices listed for the company, got %+v", arInvoices.req)
+	}
+	if got := repo.states[DocSalesOrder]; len(got) != 3 || got[2] != "PROCESSING" {
+		t.Fatalf("sales orders must use the shared open states, got %v", got)
+	}
+	if len(report.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(report.Items))
+	}
+	if report.Items[0].Number != "SO-2" || report.Items[0].AgeDays != 10 || report.Items[0].Type != DocSalesOrder {
+		t.Fatalf("expected oldest sales order first, got %+v", report.Items[0])
+	}
+	if report.Items[2].AgeDays != 2 {
+		t.Fatalf("expected AR invoice aged 2 days, got %d", report.Items[2].AgeDays)
+	}
+	if s := report.Summaries[1]; s.Type != DocSalesOrder || s.Count != 2 || s.Amount != 350 {
+		t.Fatalf("unexpected sales order summary %+v", s)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, report); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 4 || records[1][1] != "SO-2" || records[1][5] != "2026-10-21" || records[1][8] != "250.00" {
+		t.Fatalf("unexpected csv %v", records)
+	}
+}
+
+func TestReportRequiresCompany(t *testing.T) {
+	_, err := NewService(&stubRepo{}, &stubARInvoices{}, &stubAPInvoices{}).Report(context.Background(), 0, time.Time{})
+	if !errors.Is(err, ErrInvalidCompany) {
+		t.Fatalf("expected ErrInvalidCompany, got %v", err)
+	}
+}
+
+func TestReportSummarisesPerCurrency(t *testing.T) {
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	repo := &stubRepo{items: map[DocumentType][]Item{
+		DocSalesOrder: {
+			{ID: 1, Currency: "USD", DocumentDate: asOf, Amount: 10},
+			{ID: 2, Currency: "IDR", DocumentDate: asOf, Amount: 150000},
+			{ID: 3, Currency: "USD", DocumentDate: asOf, Amount: 5},
+		},
+	}}
+
+	report, err := NewService(repo, &stubARInvoices{}, &stubAPInvoices{}).Report(context.Background(), 1, asOf)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	var orders []Summary
+	for _, s := range report.Summaries {
+		if s.Type == DocSalesOrder {
+			orders = append(orders, s)
+		}
+	}
+	if len(orders) != 2 {
+		t.Fatalf("expected one sales order summary per currency, got %+v", orders)
+	}
+	if orders[0].Currency != "IDR" || orders[0].Count != 1 || orders[0].Amount != 150000 {
+		t.Fatalf("unexpected IDR summary %+v", orders[0])
+	}
+	if orders[1].Currency != "USD" || orders[1].Count != 2 || orders[1].Amount != 15 {
+		t.Fatalf("unexpected USD summary %+v", orders[1])
+	}
+}
+
+func TestReportKeepsInvoicesPostedAfterAsOf(t *testing.T) {
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	postedLater := asOf.AddDate(0, 0, 2)
+	postedBefore := asOf.Add(-time.Hour)
+	apInvoices := &stubAPInvoices{invoices: []ap.APInvoice{
+		{ID: 1, Number: "APINV-1", Status: ap.APStatusPosted, IssuedAt: asOf, PostedAt: &postedLater, Total: 100},
+		{ID: 2, Number: "APINV-2", Status: ap.APStatusPosted, IssuedAt: asOf, PostedAt: &postedBefore, Total: 200},
+		{ID: 3, Number: "APINV-3", Status: ap.APStatusDraft, IssuedAt: asOf, Total: 300},
+	}}
+
+	report, err := NewService(&stubRepo{}, &stubARInvoices{}, apInvoices).Report(context.Background(), 1, asOf)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(report.Items) != 2 || report.Items[0].Number != "APINV-1" || report.Items[1].Number != "APINV-3" {
+		t.Fatalf("expected the invoices still unposted at the as-of date, got %+v", report.Items)
+	}
+	if report.Items[0].Status != string(ap.APStatusDraft) {
+		t.Fatalf("expected the as-of status, got %q", report.Items[0].Status)
+	}
+}
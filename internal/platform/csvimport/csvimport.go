@@ -0,0 +1,243 @@
+// Package csvimport guards CSV upload endpoints: it caps the request body,
+// rate limits each user, and stream-parses rows up to a row limit.
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/httprate"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+var (
+	// ErrTooLarge indicates the upload exceeds Limits.MaxBytes.
+	ErrTooLarge = errors.New("csvimport: file too large")
+	// ErrTooManyRows indicates the file has more data rows than Limits.MaxRows.
+	ErrTooManyRows = errors.New("csvimport: too many rows")
+	// ErrEmpty indicates the file has no header row.
+	ErrEmpty = errors.New("csvimport: file is empty")
+	// ErrNoFile indicates a multipart request without the expected file.
+	ErrNoFile = errors.New("csvimport: no file uploaded")
+)
+
+// Limits bounds a single import endpoint.
+type Limits struct {
+	// MaxBytes caps the request body, including multipart overhead.
+	MaxBytes int64
+	// MaxRows caps the data rows after the header.
+	MaxRows int
+	// PerMinute is how many imports one user may start per minute.
+	PerMinute int
+}
+
+// DefaultLimits allows 10 MiB, 10,000 rows and 5 imports a minute.
+func DefaultLimits() Limits {
+	return Limits{MaxBytes: 10 << 20, MaxRows: 10000, PerMinute: 5}
+}
+
+func (l Limits) withDefaults() Limits {
+	def := DefaultLimits()
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = def.MaxBytes
+	}
+	if l.MaxRows <= 0 {
+		l.MaxRows = def.MaxRows
+	}
+	if l.PerMinute <= 0 {
+		l.PerMinute = def.PerMinute
+	}
+	return l
+}
+
+// Guard rate limits import requests per user and caps their body size.
+// Requests whose Content-Length already exceeds the cap get 413 before the
+// handler runs; bodies without a length fail on read with ErrTooLarge.
+func Guard(limits Limits) func(http.Handler) http.Handler {
+	limits = limits.withDefaults()
+	limiter := httprate.Limit(limits.PerMinute, time.Minute,
+		httprate.WithKeyFuncs(rateLimitKey),
+		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
+			httpx.Problem(w, http.StatusTooManyRequests, "Too Many Imports",
+				fmt.Sprintf("Only %d imports per minute are allowed. Wait a moment and try again.", limits.PerMinute))
+		}),
+	)
+	return func(next http.Handler) http.Handler {
+		capped := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limits.MaxBytes {
+				WriteError(w, limits, ErrTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBytes)
+			next.ServeHTTP(w, r)
+		})
+		return limiter(capped)
+	}
+}
+
+func rateLimitKey(r *http.Request) (string, error) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		if user := strings.TrimSpace(sess.User()); user != "" {
+			return "import:user:" + user, nil
+		}
+	}
+	key, err := httprate.KeyByIP(r)
+	if err != nil {
+		return "", err
+	}
+	return "import:ip:" + key, nil
+}
+
+// OpenUpload returns the CSV stream of a request without buffering it. For
+// multipart forms it is the first file part named field; any other body is
+// read as raw CSV. A form already parsed upstream, for example to read the
+// CSRF field, is served from the parsed file instead.
+func OpenUpload(r *http.Request, field string) (io.Reader, error) {
+	if r.MultipartForm != nil {
+		files := r.MultipartForm.File[field]
+		if len(files) == 0 {
+			return nil, fmt.Errorf("%w: expected a file in %q", ErrNoFile, field)
+		}
+		file, err := files[0].Open()
+		if err != nil {
+			return nil, readError(err)
+		}
+		return file, nil
+	}
+	mr, err := r.MultipartReader()
+	if errors.Is(err, http.ErrNotMultipart) {
+		return r.Body, nil
+	}
+	if err != nil {
+		return nil, readError(err)
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: expected a file in %q", ErrNoFile, field)
+		}
+		if err != nil {
+			return nil, readError(err)
+		}
+		if part.FormName() == field && part.FileName() != "" {
+			return part, nil
+		}
+	}
+}
+
+// Reader streams a CSV file one record at a time. The first record is the
+// header; Next returns data rows until io.EOF.
+type Reader struct {
+	csv     *csv.Reader
+	maxRows int
+	header  []string
+	rows    int
+}
+
+// NewReader wraps r. maxRows <= 0 uses the default row limit.
+func NewReader(r io.Reader, maxRows int) *Reader {
+	if maxRows <= 0 {
+		maxRows = DefaultLimits().MaxRows
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+	return &Reader{csv: cr, maxRows: maxRows}
+}
+
+// Header reads and returns the header row with names trimmed and lowercased.
+func (r *Reader) Header() ([]string, error) {
+	if r.header != nil {
+		return r.header, nil
+	}
+	record, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmpty
+	}
+	if err != nil {
+		return nil, readError(err)
+	}
+	header := make([]string, len(record))
+	for i, name := range record {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	}
+	r.header = header
+	return header, nil
+}
+
+// Next returns the next data row and its 1-based line number in the file.
+// The returned slice is reused by the following call.
+func (r *Reader) Next() ([]string, int, error) {
+	if r.header == nil {
+		if _, err := r.Header(); err != nil {
+			return nil, 0, err
+		}
+	}
+	record, err := r.csv.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, readError(err)
+	}
+	r.rows++
+	if r.rows > r.maxRows {
+		return nil, 0, fmt.Errorf("%w: the limit is %d rows; split the file and import each part", ErrTooManyRows, r.maxRows)
+	}
+	line, _ := r.csv.FieldPos(0)
+	return record, line, nil
+}
+
+// Rows reports how many data rows have been read.
+func (r *Reader) Rows() int {
+	return r.rows
+}
+
+func readError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return fmt.Errorf("%w: the limit is %d bytes", ErrTooLarge, maxErr.Limit)
+	}
+	return err
+}
+
+// WriteError answers an import failure: 413 for oversized uploads, 422 for
+// too many rows or malformed CSV.
+func WriteError(w http.ResponseWriter, limits Limits, err error) {
+	limits = limits.withDefaults()
+	var parseErr *csv.ParseError
+	switch {
+	case errors.Is(err, ErrTooLarge):
+		httpx.Problem(w, http.StatusRequestEntityTooLarge, "File Too Large",
+			fmt.Sprintf("Uploads are limited to %s. Split the file and import each part.", formatBytes(limits.MaxBytes)))
+	case errors.Is(err, ErrTooManyRows):
+		httpx.Problem(w, http.StatusUnprocessableEntity, "Too Many Rows",
+			fmt.Sprintf("Imports are limited to %d rows. Split the file and import each part.", limits.MaxRows))
+	case errors.Is(err, ErrNoFile):
+		httpx.Problem(w, http.StatusBadRequest, "No File", "Choose a CSV file to import.")
+	case errors.Is(err, ErrEmpty):
+		httpx.Problem(w, http.StatusUnprocessableEntity, "Empty File", "The file has no header row.")
+	case errors.As(err, &parseErr):
+		httpx.Problem(w, http.StatusUnprocessableEntity, "Invalid CSV", parseErr.Error())
+	default:
+		httpx.Problem(w, http.StatusBadRequest, "Import Failed", shared.UserSafeMessage(err))
+	}
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MiB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KiB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,136 @@
+package csvimport
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReaderStreamsRowsWithLineNumbers(t *testing.T) {
+	r := NewReader(strings.NewReader("\ufeffCode, Name\nA1,Alpha\n\nB2,Beta\n"), 10)
+	header, err := r.Header()
+	if err != nil {
+		t.Fatalf("Header() error = %v", err)
+	}
+	if strings.Join(header, "|") != "code|name" {
+		t.Fatalf("unexpected header %v", header)
+	}
+	var got []string
+	var lines []int
+	for {
+		row, line, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, row[0])
+		lines = append(lines, line)
+	}
+	if strings.Join(got, ",") != "A1,B2" || lines[0] != 2 || lines[1] != 4 {
+		t.Fatalf("unexpected rows %v at lines %v", got, lines)
+	}
+}
+
+func TestReaderStopsAtMaxRows(t *testing.T) {
+	r := NewReader(strings.NewReader("code\n1\n2\n3\n"), 2)
+	var err error
+	for err == nil {
+		_, _, err = r.Next()
+	}
+	if !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("expected ErrTooManyRows got %v", err)
+	}
+	rec := httptest.NewRecorder()
+	WriteError(rec, Limits{MaxRows: 2}, err)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "limited to 2 rows") {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGuardRejectsOversizedUploads(t *testing.T) {
+	limits := Limits{MaxBytes: 64, MaxRows: 100, PerMinute: 100}
+	handler := Guard(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := OpenUpload(r, "file")
+		if err != nil {
+			WriteError(w, limits, err)
+			return
+		}
+		reader := NewReader(body, limits.MaxRows)
+		for {
+			if _, _, err := reader.Next(); err != nil {
+				if !errors.Is(err, io.EOF) {
+					WriteError(w, limits, err)
+					return
+				}
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(strings.Repeat("a,b\n", 40)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for declared length got %d", rec.Code)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, _ := mw.CreateFormFile("file", "big.csv")
+	_, _ = part.Write([]byte(strings.Repeat("a,b\n", 40)))
+	_ = mw.Close()
+	req = httptest.NewRequest(http.MethodPost, "/import", io.MultiReader(&buf))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for streamed upload got %d", rec.Code)
+	}
+}
+
+func TestOpenUploadReadsParsedMultipartForm(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("csrf_token", "token")
+	part, _ := mw.CreateFormFile("file", "rows.csv")
+	_, _ = part.Write([]byte("sku,qty\nA,1\n"))
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/import", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	_ = req.PostFormValue("csrf_token")
+
+	body, err := OpenUpload(req, "file")
+	if err != nil {
+		t.Fatalf("open upload: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	if string(data) != "sku,qty\nA,1\n" {
+		t.Fatalf("unexpected body %q", data)
+	}
+	if _, err := OpenUpload(req, "other"); !errors.Is(err, ErrNoFile) {
+		t.Fatalf("expected ErrNoFile got %v", err)
+	}
+}
+
+func TestGuardRateLimitsPerUser(t *testing.T) {
+	handler := Guard(Limits{PerMinute: 1})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for i, want := range []int{http.StatusNoContent, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader("code\n"))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("request %d: expected %d got %d", i+1, want, rec.Code)
+		}
+	}
+}
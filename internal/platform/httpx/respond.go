@@ -4,14 +4,18 @@ package httpx
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/requestid"
 )
 
-// ProblemDetail represents RFC7807 problem details.
+// ProblemDetail represents RFC7807 problem details. RequestID is the
+// correlation ID users can quote to support.
 type ProblemDetail struct {
-	Type   string `json:"type,omitempty"`
-	Title  string `json:"title"`
-	Status int    `json:"status"`
-	Detail string `json:"detail,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // JSON sends a JSON response with the given status code.
@@ -21,12 +25,14 @@ func JSON(w http.ResponseWriter, status int, data any) {
 	_ = json.NewEncoder(w).Encode(data)
 }
 
-// Problem sends an RFC7807 problem details response.
+// Problem sends an RFC7807 problem details response, quoting the request ID
+// the request ID middleware set on the response.
 func Problem(w http.ResponseWriter, status int, title, detail string) {
 	JSON(w, status, ProblemDetail{
-		Title:  title,
-		Status: status,
-		Detail: detail,
+		Title:     title,
+		Status:    status,
+		Detail:    detail,
+		RequestID: w.Header().Get(requestid.Header),
 	})
 }
 
@@ -34,3 +40,12 @@ func Problem(w http.ResponseWriter, status int, title, detail string) {
 func DecodeJSON(r *http.Request, target any) error {
 	return json.NewDecoder(r.Body).Decode(target)
 }
+
+// Error replies with a plain-text error like http.Error, appending the
+// request ID as "(Ref: ...)" so users can quote it to support.
+func Error(w http.ResponseWriter, r *http.Request, message string, status int) {
+	if id := requestid.FromContext(r.Context()); id != "" {
+		message += " (Ref: " + id + ")"
+	}
+	http.Error(w, message, status)
+}
@@ -0,0 +1,28 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/requestid"
+)
+
+func TestErrorQuotesRequestID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	Error(rec, req.WithContext(requestid.NewContext(req.Context(), "abc123")), "Forbidden", http.StatusForbidden)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", rec.Code)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != "Forbidden (Ref: abc123)" {
+		t.Fatalf("unexpected body %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	Error(rec, req, "Forbidden", http.StatusForbidden)
+	if got := strings.TrimSpace(rec.Body.String()); got != "Forbidden" {
+		t.Fatalf("expected no reference without an ID, got %q", got)
+	}
+}
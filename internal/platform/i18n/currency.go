@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"math"
+	"strings"
+)
+
+type currencyFormat struct {
+	symbol string
+	// decimals is the number of decimals shown, which may be fewer than are
+	// stored: rupiah amounts are kept to the sen but shown whole.
+	decimals int
+}
+
+var currencyFormats = map[string]currencyFormat{
+	"IDR": {symbol: "Rp", decimals: 0},
+	"USD": {symbol: "US$", decimals: 2},
+	"SGD": {symbol: "S$", decimals: 2},
+	"EUR": {symbol: "€", decimals: 2},
+	"JPY": {symbol: "¥", decimals: 0},
+	"CNY": {symbol: "CN¥", decimals: 2},
+	"MYR": {symbol: "RM", decimals: 2},
+	"AUD": {symbol: "A$", decimals: 2},
+}
+
+// CurrencySymbol returns the display symbol of a currency, or the code itself
+// for currencies without one.
+func CurrencySymbol(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if f, ok := currencyFormats[code]; ok {
+		return f.symbol
+	}
+	return code
+}
+
+// CurrencyDecimals returns how many decimals amounts in the currency show.
+// Unknown currencies show two.
+func CurrencyDecimals(code string) int {
+	if f, ok := currencyFormats[strings.ToUpper(strings.TrimSpace(code))]; ok {
+		return f.decimals
+	}
+	return 2
+}
+
+// Amount formats v with the currency's decimals but no symbol, for table
+// columns that name the currency once.
+func (l Locale) Amount(v float64, currency string) string {
+	decimals := CurrencyDecimals(currency)
+	return l.Number(roundHalfAway(v, decimals), decimals)
+}
+
+// Currency formats v with the currency's symbol and decimals, e.g.
+// "Rp 1.000.000" in Indonesian or "-US$ 12.50" in English.
+func (l Locale) Currency(v float64, currency string) string {
+	decimals := CurrencyDecimals(currency)
+	v = roundHalfAway(v, decimals)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	symbol := CurrencySymbol(currency)
+	if symbol == "" {
+		return sign + l.Number(v, decimals)
+	}
+	return sign + symbol + " " + l.Number(v, decimals)
+}
+
+// roundHalfAway rounds to the given decimals and clears negative zero, so a
+// small negative amount never renders as "-0".
+func roundHalfAway(v float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+	r := math.Round(v*p) / p
+	if r == 0 {
+		return 0
+	}
+	return r
+}
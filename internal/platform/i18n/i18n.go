@@ -0,0 +1,150 @@
+// Package i18n translates the fixed labels of customer-facing documents and
+// formats numbers and dates for the customer's language.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Supported document languages.
+const (
+	English    = "en"
+	Indonesian = "id"
+	// Default is used for customers without a language and for labels a
+	// language does not translate.
+	Default = English
+)
+
+type localeData struct {
+	tag         language.Tag
+	labels      map[string]string
+	months      [12]string
+	shortMonths [12]string
+	// dateLayout and dateTimeLayout are Go layouts with {month} standing in
+	// for the localized month name, since Go only formats English names.
+	dateLayout     string
+	dateTimeLayout string
+}
+
+var locales = map[string]localeData{
+	English: {
+		tag:    language.English,
+		labels: englishLabels,
+		months: [12]string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		shortMonths:    [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		dateLayout:     "{month} 2, 2006",
+		dateTimeLayout: "{month} 2, 2006 15:04",
+	},
+	Indonesian: {
+		tag:    language.Indonesian,
+		labels: indonesianLabels,
+		months: [12]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+			"Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+		shortMonths:    [12]string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"},
+		dateLayout:     "2 {month} 2006",
+		dateTimeLayout: "2 {month} 2006 15.04",
+	},
+}
+
+// Languages returns the supported language codes.
+func Languages() []string {
+	return []string{English, Indonesian}
+}
+
+// Supported reports whether lang is a supported document language. The empty
+// string is supported and means Default.
+func Supported(lang string) bool {
+	if lang == "" {
+		return true
+	}
+	_, ok := locales[lang]
+	return ok
+}
+
+// Locale renders labels, numbers and dates in one language. The zero value
+// uses Default.
+type Locale struct {
+	lang string
+}
+
+// For returns the locale for lang, falling back to Default for an empty or
+// unsupported code.
+func For(lang string) Locale {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if _, ok := locales[lang]; !ok {
+		lang = Default
+	}
+	return Locale{lang: lang}
+}
+
+// Language returns the language code, e.g. for the html lang attribute.
+func (l Locale) Language() string {
+	if l.lang == "" {
+		return Default
+	}
+	return l.lang
+}
+
+func (l Locale) data() localeData {
+	return locales[l.Language()]
+}
+
+// T translates a label key. Keys missing from the language fall back to
+// Default, and keys missing there render as the key itself.
+func (l Locale) T(key string) string {
+	if label, ok := l.data().labels[key]; ok {
+		return label
+	}
+	if label, ok := locales[Default].labels[key]; ok {
+		return label
+	}
+	return key
+}
+
+// Number formats v with the given decimals and the language's grouping and
+// decimal separators.
+func (l Locale) Number(v float64, decimals int) string {
+	return message.NewPrinter(l.data().tag).Sprintf("%.*f", decimals, v)
+}
+
+// Money formats an amount with two decimals.
+func (l Locale) Money(v float64) string {
+	return l.Number(v, 2)
+}
+
+// Qty formats a quantity with up to four decimals, dropping trailing zeros.
+func (l Locale) Qty(v float64) string {
+	decimals := 4
+	for decimals > 0 {
+		s := fmt.Sprintf("%.*f", decimals, v)
+		if s[len(s)-1] != '0' {
+			break
+		}
+		decimals--
+	}
+	return l.Number(v, decimals)
+}
+
+// Date formats a date with the month name spelled out.
+func (l Locale) Date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := l.data()
+	return strings.Replace(t.Format(d.dateLayout), "{month}", d.months[t.Month()-1], 1)
+}
+
+// DateTime formats a timestamp with the short month name.
+func (l Locale) DateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := l.data()
+	return strings.Replace(t.Format(d.dateTimeLayout), "{month}", d.shortMonths[t.Month()-1], 1)
+}
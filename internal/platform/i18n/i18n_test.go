@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocaleFormatsPerLanguage(t *testing.T) {
+	at := time.Date(2026, time.March, 5, 14, 30, 0, 0, time.UTC)
+	cases := []struct {
+		lang                             string
+		date, dateTime, money, qty, zero string
+	}{
+		{lang: "en", date: "March 5, 2026", dateTime: "Mar 5, 2026 14:30", money: "1,234,567.50", qty: "12.5", zero: "0"},
+		{lang: "id", date: "5 Maret 2026", dateTime: "5 Mar 2026 14.30", money: "1.234.567,50", qty: "12,5", zero: "0"},
+	}
+	for _, tc := range cases {
+		l := For(tc.lang)
+		if got := l.Date(at); got != tc.date {
+			t.Errorf("%s Date = %q, want %q", tc.lang, got, tc.date)
+		}
+		if got := l.DateTime(at); got != tc.dateTime {
+			t.Errorf("%s DateTime = %q, want %q", tc.lang, got, tc.dateTime)
+		}
+		if got := l.Money(1234567.5); got != tc.money {
+			t.Errorf("%s Money = %q, want %q", tc.lang, got, tc.money)
+		}
+		if got := l.Qty(12.5); got != tc.qty {
+			t.Errorf("%s Qty = %q, want %q", tc.lang, got, tc.qty)
+		}
+		if got := l.Qty(0); got != tc.zero {
+			t.Errorf("%s Qty(0) = %q, want %q", tc.lang, got, tc.zero)
+		}
+	}
+}
+
+func TestLocaleFallsBackToDefault(t *testing.T) {
+	if got := For("fr").Language(); got != Default {
+		t.Fatalf("unsupported language should use default, got %q", got)
+	}
+	if got := (Locale{}).T("packing_list.title"); got != "PACKING LIST" {
+		t.Fatalf("zero locale should use default labels, got %q", got)
+	}
+
+	englishLabels["test.only_english"] = "Only English"
+	defer delete(englishLabels, "test.only_english")
+	if got := For(Indonesian).T("test.only_english"); got != "Only English" {
+		t.Fatalf("missing translation should fall back to default, got %q", got)
+	}
+	if got := For(Indonesian).T("test.unknown"); got != "test.unknown" {
+		t.Fatalf("unknown key should render as key, got %q", got)
+	}
+}
+
+func TestCatalogsCoverDefaultKeys(t *testing.T) {
+	for key := range englishLabels {
+		if _, ok := indonesianLabels[key]; !ok {
+			t.Errorf("indonesian catalog missing %q", key)
+		}
+	}
+}
+
+func TestLocaleFormatsCurrency(t *testing.T) {
+	cases := []struct {
+		lang, currency string
+		v              float64
+		want, amount   string
+	}{
+		{lang: "id", currency: "IDR", v: 1000000, want: "Rp 1.000.000", amount: "1.000.000"},
+		{lang: "id", currency: "idr", v: 1250.6, want: "Rp 1.251", amount: "1.251"},
+		{lang: "en", currency: "USD", v: -1234.5, want: "-US$ 1,234.50", amount: "-1,234.50"},
+		{lang: "id", currency: "USD", v: 1234.5, want: "US$ 1.234,50", amount: "1.234,50"},
+		{lang: "en", currency: "JPY", v: 1500, want: "¥ 1,500", amount: "1,500"},
+		{lang: "en", currency: "THB", v: 99.999, want: "THB 100.00", amount: "100.00"},
+		{lang: "id", currency: "IDR", v: -0.2, want: "Rp 0", amount: "0"},
+	}
+	for _, tc := range cases {
+		l := For(tc.lang)
+		if got := l.Currency(tc.v, tc.currency); got != tc.want {
+			t.Errorf("%s Currency(%v, %s) = %q, want %q", tc.lang, tc.v, tc.currency, got, tc.want)
+		}
+		if got := l.Amount(tc.v, tc.currency); got != tc.amount {
+			t.Errorf("%s Amount(%v, %s) = %q, want %q", tc.lang, tc.v, tc.currency, got, tc.amount)
+		}
+	}
+}
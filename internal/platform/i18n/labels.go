@@ -0,0 +1,137 @@
+package i18n
+
+// englishLabels is the Default catalog; every key used by a document
+// template must be present here.
+var englishLabels = map[string]string{
+	"doc.document_information": "Document Information",
+	"doc.document_no":          "Document No",
+	"doc.sales_order":          "Sales Order",
+	"doc.status":               "Status",
+	"doc.customer_information": "Customer Information",
+	"doc.customer":             "Customer",
+	"doc.product_code":         "Product Code",
+	"doc.product_name":         "Product Name",
+	"doc.quantity":             "Quantity",
+	"doc.uom":                  "UOM",
+	"doc.no_items":             "No items.",
+	"doc.prepared_by":          "Prepared By",
+	"doc.received_by":          "Received By",
+	"doc.signature_date":       "Signature & Date",
+	"doc.generated":            "Generated",
+
+	"status.draft":      "Draft",
+	"status.confirmed":  "Confirmed",
+	"status.in_transit": "In Transit",
+	"status.delivered":  "Delivered",
+	"status.cancelled":  "Cancelled",
+	"status.submitted":  "Submitted",
+	"status.approved":   "Approved",
+	"status.rejected":   "Rejected",
+	"status.converted":  "Converted",
+	"status.processing": "Processing",
+	"status.completed":  "Completed",
+
+	"packing_list.title":                "PACKING LIST",
+	"packing_list.document_title":       "Packing List",
+	"packing_list.subtitle":             "Delivery Order Document",
+	"packing_list.planned_date":         "Planned Date",
+	"packing_list.ship_date":            "Ship Date",
+	"packing_list.ship_to":              "Ship To",
+	"packing_list.shipping_information": "Shipping Information",
+	"packing_list.warehouse":            "Warehouse",
+	"packing_list.carrier":              "Carrier",
+	"packing_list.tracking":             "Tracking",
+	"packing_list.batch_serial":         "Batch/Serial",
+	"packing_list.batch":                "Batch",
+	"packing_list.serial":               "Serial",
+	"packing_list.shipping_notes":       "Shipping Notes",
+	"packing_list.delivery_notes":       "Delivery Notes",
+	"packing_list.footer_verify":        "This is a computer-generated packing list. Please verify all items upon receipt.",
+	"packing_list.footer_contact":       "For questions or discrepancies, contact customer service immediately.",
+
+	"sales_doc.quotation_title":          "QUOTATION",
+	"sales_doc.quotation_document_title": "Quotation",
+	"sales_doc.order_title":              "SALES ORDER",
+	"sales_doc.order_document_title":     "Sales Order",
+	"sales_doc.date":                     "Date",
+	"sales_doc.valid_until":              "Valid Until",
+	"sales_doc.expected_delivery":        "Expected Delivery",
+	"sales_doc.currency":                 "Currency",
+	"sales_doc.address":                  "Address",
+	"sales_doc.unit_price":               "Unit Price",
+	"sales_doc.discount_percent":         "Disc %",
+	"sales_doc.tax_percent":              "Tax %",
+	"sales_doc.amount":                   "Amount",
+	"sales_doc.document_discount":        "Document Discount",
+	"sales_doc.subtotal":                 "Subtotal",
+	"sales_doc.tax":                      "Tax",
+	"sales_doc.total":                    "Total",
+	"sales_doc.notes":                    "Notes",
+}
+
+var indonesianLabels = map[string]string{
+	"doc.document_information": "Informasi Dokumen",
+	"doc.document_no":          "No. Dokumen",
+	"doc.sales_order":          "Pesanan Penjualan",
+	"doc.status":               "Status",
+	"doc.customer_information": "Informasi Pelanggan",
+	"doc.customer":             "Pelanggan",
+	"doc.product_code":         "Kode Produk",
+	"doc.product_name":         "Nama Produk",
+	"doc.quantity":             "Jumlah",
+	"doc.uom":                  "Satuan",
+	"doc.no_items":             "Tidak ada barang.",
+	"doc.prepared_by":          "Disiapkan Oleh",
+	"doc.received_by":          "Diterima Oleh",
+	"doc.signature_date":       "Tanda Tangan & Tanggal",
+	"doc.generated":            "Dibuat",
+
+	"status.draft":      "Draf",
+	"status.confirmed":  "Dikonfirmasi",
+	"status.in_transit": "Dalam Pengiriman",
+	"status.delivered":  "Terkirim",
+	"status.cancelled":  "Dibatalkan",
+	"status.submitted":  "Diajukan",
+	"status.approved":   "Disetujui",
+	"status.rejected":   "Ditolak",
+	"status.converted":  "Dikonversi",
+	"status.processing": "Diproses",
+	"status.completed":  "Selesai",
+
+	"packing_list.title":                "SURAT JALAN",
+	"packing_list.document_title":       "Surat Jalan",
+	"packing_list.subtitle":             "Dokumen Pengiriman Barang",
+	"packing_list.planned_date":         "Tanggal Rencana",
+	"packing_list.ship_date":            "Tanggal Kirim",
+	"packing_list.ship_to":              "Dikirim Ke",
+	"packing_list.shipping_information": "Informasi Pengiriman",
+	"packing_list.warehouse":            "Gudang",
+	"packing_list.carrier":              "Ekspedisi",
+	"packing_list.tracking":             "No. Resi",
+	"packing_list.batch_serial":         "Batch/Serial",
+	"packing_list.batch":                "Batch",
+	"packing_list.serial":               "Serial",
+	"packing_list.shipping_notes":       "Catatan Pengiriman",
+	"packing_list.delivery_notes":       "Catatan Penerimaan",
+	"packing_list.footer_verify":        "Surat jalan ini dibuat oleh sistem. Mohon periksa semua barang saat diterima.",
+	"packing_list.footer_contact":       "Untuk pertanyaan atau ketidaksesuaian, segera hubungi layanan pelanggan.",
+
+	"sales_doc.quotation_title":          "PENAWARAN HARGA",
+	"sales_doc.quotation_document_title": "Penawaran Harga",
+	"sales_doc.order_title":              "PESANAN PENJUALAN",
+	"sales_doc.order_document_title":     "Pesanan Penjualan",
+	"sales_doc.date":                     "Tanggal",
+	"sales_doc.valid_until":              "Berlaku Sampai",
+	"sales_doc.expected_delivery":        "Perkiraan Pengiriman",
+	"sales_doc.currency":                 "Mata Uang",
+	"sales_doc.address":                  "Alamat",
+	"sales_doc.unit_price":               "Harga Satuan",
+	"sales_doc.discount_percent":         "Disk %",
+	"sales_doc.tax_percent":              "Pajak %",
+	"sales_doc.amount":                   "Jumlah",
+	"sales_doc.document_discount":        "Diskon Dokumen",
+	"sales_doc.subtotal":                 "Subtotal",
+	"sales_doc.tax":                      "Pajak",
+	"sales_doc.total":                    "Total",
+	"sales_doc.notes":                    "Catatan",
+}
@@ -0,0 +1,70 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigningKeyMatchesAWSExample(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation.
+	key := SigningKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	want := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"
+	if got := hex.EncodeToString(key); got != want {
+		t.Fatalf("signing key = %s, want %s", got, want)
+	}
+}
+
+func TestPutObjectSendsSignedPathStyleRequest(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	client, err := New(Config{Endpoint: srv.URL, Bucket: "lake", AccessKey: "AKID", SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	client.now = func() time.Time { return time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC) }
+
+	payload := []byte("id,name\n1,Kas\n")
+	sum := hexSHA256(payload)
+	if err := client.PutObject(context.Background(), "odyssey/run 1/accounts.csv", bytes.NewReader(payload), int64(len(payload)), sum, "text/csv"); err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	if got.Method != http.MethodPut || got.URL.EscapedPath() != "/lake/odyssey/run%201/accounts.csv" {
+		t.Fatalf("unexpected request %s %s", got.Method, got.URL.EscapedPath())
+	}
+	if !bytes.Equal(body, payload) || got.Header.Get("X-Amz-Content-Sha256") != sum {
+		t.Fatalf("payload not sent intact")
+	}
+	auth := got.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20261016/us-east-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Fatalf("unexpected authorization %q", auth)
+	}
+}
+
+func TestPutObjectReportsErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	client, err := New(Config{Endpoint: srv.URL, Bucket: "lake", AccessKey: "AKID", SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	err = client.PutObject(context.Background(), "a.csv", strings.NewReader("x"), 1, hexSHA256([]byte("x")), "")
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Fatalf("expected access denied error, got %v", err)
+	}
+}
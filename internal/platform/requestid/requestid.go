@@ -0,0 +1,105 @@
+// Package requestid assigns each request a correlation ID and carries it
+// through the context into log lines, queued tasks and error responses.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+)
+
+// Header carries the correlation ID on requests and responses.
+const Header = "X-Request-ID"
+
+// fallbackHeader is accepted from callers that name the ID differently.
+const fallbackHeader = "X-Correlation-ID"
+
+// LogKey is the attribute name the ID is logged under.
+const LogKey = "request_id"
+
+// maxLength bounds inbound IDs so a caller cannot bloat every log line.
+const maxLength = 128
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the correlation ID, or "" when ctx has none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// New generates a random correlation ID.
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Middleware reuses a well-formed inbound ID or assigns a new one, stores it
+// in the request context and echoes it on the response so clients and
+// support can quote it.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !Valid(id) {
+			id = r.Header.Get(fallbackHeader)
+		}
+		if !Valid(id) {
+			id = New()
+		}
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// Valid reports whether an inbound ID is safe to adopt: 1 to 128 letters,
+// digits, dots, dashes, underscores or colons.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '-', c == '_', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// logHandler adds the context's correlation ID to every record.
+type logHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps h so records logged with a context carrying an ID,
+// e.g. through Logger.ErrorContext, include it as request_id.
+func NewLogHandler(h slog.Handler) slog.Handler {
+	return logHandler{Handler: h}
+}
+
+func (h logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if id := FromContext(ctx); id != "" {
+			record.AddAttrs(slog.String(LogKey, id))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,72 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareAdoptsOrAssignsID(t *testing.T) {
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+	}))
+
+	cases := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"inbound header", map[string]string{Header: "edge-42"}, "edge-42"},
+		{"correlation header", map[string]string{"X-Correlation-ID": "corr:7"}, "corr:7"},
+		{"unsafe header", map[string]string{Header: "bad id\nforged=1"}, ""},
+		{"none", nil, ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for key, value := range tc.headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if tc.want != "" && seen != tc.want {
+			t.Fatalf("%s: expected id %q, got %q", tc.name, tc.want, seen)
+		}
+		if tc.want == "" && (len(seen) != 32 || !Valid(seen)) {
+			t.Fatalf("%s: expected a generated id, got %q", tc.name, seen)
+		}
+		if got := rec.Header().Get(Header); got != seen {
+			t.Fatalf("%s: response header %q does not match context id %q", tc.name, got, seen)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	if Valid("") || Valid(strings.Repeat("a", maxLength+1)) || Valid("a b") {
+		t.Fatalf("expected empty, oversized and spaced ids to be rejected")
+	}
+	if !Valid("0f3c-9A_b.c:1") {
+		t.Fatalf("expected well-formed id to be accepted")
+	}
+}
+
+func TestLogHandlerAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	logger.InfoContext(NewContext(context.Background(), "abc123"), "posted")
+	if !strings.Contains(buf.String(), "request_id=abc123") || !strings.Contains(buf.String(), "component=test") {
+		t.Fatalf("expected request_id and inherited attrs, got %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.Info("no request")
+	if strings.Contains(buf.String(), "request_id") {
+		t.Fatalf("expected no request_id without a context id, got %q", buf.String())
+	}
+}
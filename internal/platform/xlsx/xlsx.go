@@ -0,0 +1,169 @@
+// Package xlsx writes minimal SpreadsheetML workbooks without a third-party dependency.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the MIME type of an .xlsx workbook.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+const bufferSize = 32 * 1024
+
+// Cell is a single spreadsheet cell; numeric cells are written as numbers so
+// spreadsheet users can pivot and sum without conversion.
+type Cell struct {
+	text    string
+	number  float64
+	numeric bool
+}
+
+// Text builds a string cell.
+func Text(v string) Cell { return Cell{text: v} }
+
+// Num builds a numeric cell rendered with two decimals.
+func Num(v float64) Cell { return Cell{number: v, numeric: true} }
+
+// Blank builds an empty cell.
+func Blank() Cell { return Cell{} }
+
+// Textf builds a formatted string cell.
+func Textf(format string, args ...any) Cell {
+	return Cell{text: fmt.Sprintf(format, args...)}
+}
+
+// Sheet is one named worksheet.
+type Sheet struct {
+	Name string
+	Rows [][]Cell
+}
+
+type part struct {
+	name string
+	body func(io.Writer) error
+}
+
+// Write streams a workbook using inline strings so no shared string table has
+// to be buffered.
+func Write(w io.Writer, sheets []Sheet) error {
+	buf := bufio.NewWriterSize(w, bufferSize)
+	zw := zip.NewWriter(buf)
+
+	parts := []part{
+		{"[Content_Types].xml", func(out io.Writer) error { return writeContentTypes(out, len(sheets)) }},
+		{"_rels/.rels", writeRootRels},
+		{"xl/workbook.xml", func(out io.Writer) error { return writeWorkbook(out, sheets) }},
+		{"xl/_rels/workbook.xml.rels", func(out io.Writer) error { return writeWorkbookRels(out, len(sheets)) }},
+	}
+	for i := range sheets {
+		sheet := sheets[i]
+		parts = append(parts, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), func(out io.Writer) error { return writeSheet(out, sheet) }})
+	}
+	for _, p := range parts {
+		fw, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if err := p.body(fw); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+const header = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+func writeContentTypes(w io.Writer, sheetCount int) error {
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeRootRels(w io.Writer) error {
+	_, err := io.WriteString(w, header+
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>`+
+		`</Relationships>`)
+	return err
+}
+
+func writeWorkbook(w io.Writer, sheets []Sheet) error {
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheet.Name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeWorkbookRels(w io.Writer, sheetCount int) error {
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	b.WriteString(`</Relationships>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeSheet(w io.Writer, sheet Sheet) error {
+	out := bufio.NewWriter(w)
+	out.WriteString(header)
+	out.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range sheet.Rows {
+		fmt.Fprintf(out, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := Column(c) + strconv.Itoa(r+1)
+			switch {
+			case cell.numeric:
+				fmt.Fprintf(out, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(cell.number, 'f', 2, 64))
+			case cell.text != "":
+				fmt.Fprintf(out, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(cell.text))
+			}
+		}
+		out.WriteString(`</row>`)
+	}
+	out.WriteString(`</sheetData></worksheet>`)
+	return out.Flush()
+}
+
+// Column converts a zero-based column index into spreadsheet letters (A, B, ..., AA).
+func Column(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+func escape(v string) string {
+	var b strings.Builder
+	if err := xml.EscapeText(&b, []byte(v)); err != nil {
+		return ""
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestColumn(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for idx, want := range cases {
+		if got := Column(idx); got != want {
+			t.Fatalf("Column(%d) = %q, want %q", idx, got, want)
+		}
+	}
+}
+
+func TestWriteSheetCells(t *testing.T) {
+	var buf bytes.Buffer
+	sheet := Sheet{Name: "Data & More", Rows: [][]Cell{
+		{Text("Name"), Text("Amount")},
+		{Text("A < B"), Num(12.5), Blank()},
+	}}
+	if err := Write(&buf, []Sheet{sheet}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	parts := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open part %s: %v", f.Name, err)
+		}
+		body, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		parts[f.Name] = string(body)
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Data &amp; More"`) {
+		t.Fatalf("sheet name not escaped: %s", parts["xl/workbook.xml"])
+	}
+	data := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">A &lt; B</t></is></c>`,
+		`<c r="B2"><v>12.50</v></c>`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("sheet missing %q", want)
+		}
+	}
+	if strings.Contains(data, `r="C2"`) {
+		t.Fatal("blank cells must be omitted")
+	}
+}
@@ -0,0 +1,127 @@
+package procurement
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConsolidatePOInput selects submitted purchase requests to merge into one PO.
+type ConsolidatePOInput struct {
+	PRIDs        []int64
+	Number       string
+	ExpectedDate time.Time
+	Note         string
+}
+
+// ConsolidatePurchaseRequests creates a single PO from several SUBMITTED
+// purchase requests of the same supplier and currency. Quantities are summed
+// per product, every source PR is linked to the PO and then closed.
+func (s *Service) ConsolidatePurchaseRequests(ctx context.Context, input ConsolidatePOInput) (PurchaseOrder, error) {
+	ids := uniqueIDs(input.PRIDs)
+	if len(ids) < 2 {
+		return PurchaseOrder{}, fmt.Errorf("%w: select at least two purchase requests", ErrValidation)
+	}
+	prs := make([]PurchaseRequest, 0, len(ids))
+	var lines []PRLine
+	for _, id := range ids {
+		pr, prLines, err := s.repo.GetPR(ctx, id)
+		if err != nil {
+			return PurchaseOrder{}, err
+		}
+		if pr.Status != PRStatusSubmitted {
+			return PurchaseOrder{}, fmt.Errorf("%w: PR %s is %s", ErrInvalidState, pr.Number, pr.Status)
+		}
+		prs = append(prs, pr)
+		lines = append(lines, prLines...)
+	}
+	first := prs[0]
+	if first.SupplierID == 0 {
+		return PurchaseOrder{}, fmt.Errorf("%w: PR %s has no supplier", ErrSupplierMismatch, first.Number)
+	}
+	currency := defaultString(first.Currency, "IDR")
+	for _, pr := range prs[1:] {
+		if pr.SupplierID != first.SupplierID {
+			return PurchaseOrder{}, fmt.Errorf("%w (%s, %s)", ErrSupplierMismatch, first.Number, pr.Number)
+		}
+		if defaultString(pr.Currency, "IDR") != currency {
+			return PurchaseOrder{}, fmt.Errorf("%w (%s, %s)", ErrCurrencyMismatch, first.Number, pr.Number)
+		}
+	}
+	if err := s.validateCurrency(ctx, currency); err != nil {
+		return PurchaseOrder{}, err
+	}
+	if err := s.ensureSupplierApproved(ctx, first.SupplierID); err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	if input.Number == "" {
+		input.Number = generateNumber("PO")
+	}
+	numbers := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		numbers = append(numbers, pr.Number)
+	}
+	note := input.Note
+	if note == "" {
+		note = "Consolidated from " + strings.Join(numbers, ", ")
+	}
+	po := PurchaseOrder{Number: input.Number, SupplierID: first.SupplierID, Status: POStatusDraft, Currency: currency, ExpectedDate: input.ExpectedDate, Note: note}
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		poID, err := tx.CreatePO(ctx, po)
+		if err != nil {
+			return err
+		}
+		for _, line := range aggregatePRLines(lines) {
+			line.POID = poID
+			if err := tx.InsertPOLine(ctx, line); err != nil {
+				return err
+			}
+		}
+		for _, pr := range prs {
+			if err := tx.LinkPOSourcePR(ctx, poID, pr.ID); err != nil {
+				return err
+			}
+			if err := tx.UpdatePRStatus(ctx, pr.ID, PRStatusClosed); err != nil {
+				return err
+			}
+		}
+		po.ID = poID
+		return nil
+	})
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+	s.recordAudit(ctx, "PO_CONSOLIDATE", po.ID, map[string]any{"number": po.Number, "from_prs": ids})
+	return po, nil
+}
+
+// aggregatePRLines sums requested quantities per product, keeping the order in
+// which products first appear.
+func aggregatePRLines(lines []PRLine) []POLine {
+	var out []POLine
+	index := make(map[int64]int)
+	for _, line := range lines {
+		if i, ok := index[line.ProductID]; ok {
+			out[i].Qty += line.Qty
+			continue
+		}
+		index[line.ProductID] = len(out)
+		out = append(out, POLine{ProductID: line.ProductID, Qty: line.Qty})
+	}
+	return out
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -52,6 +52,7 @@ type PurchaseRequest struct {
 	RequestBy  int64
 	Status     PRStatus
 	Note       string
+	Currency   string
 }
 
 // PRLine represents requested item.
@@ -72,6 +73,9 @@ type PurchaseOrder struct {
 	Currency     string
 	ExpectedDate time.Time
 	Note         string
+	// WarehouseID is the warehouse a replenishment PO restocks. Zero for POs
+	// raised from purchase requests.
+	WarehouseID int64
 }
 
 // POLine represents PO lines.
@@ -104,6 +108,13 @@ type GRNLine struct {
 	ProductID int64
 	Qty       float64
 	UnitCost  float64
+	// UOM is the unit Qty and UnitCost are in; blank means the base unit.
+	UOM string
+	// Serials lists one serial number per unit for serial-tracked products.
+	Serials []string
+	// RejectedQty is the part of Qty, in UOM, rejected by quality inspection
+	// and returned to the supplier.
+	RejectedQty float64
 }
 
 // APInvoice model.
@@ -170,4 +181,11 @@ var (
 	ErrNotFound = errors.New("procurement: not found")
 	// ErrValidation indicates invalid input.
 	ErrValidation = errors.New("procurement: invalid input")
+	// ErrSupplierMismatch indicates consolidated PRs target different suppliers.
+	ErrSupplierMismatch = errors.New("procurement: purchase requests must target the same supplier")
+	// ErrCurrencyMismatch indicates consolidated PRs use different currencies.
+	ErrCurrencyMismatch = errors.New("procurement: purchase requests must use the same currency")
+	// ErrSupplierNotApproved indicates a PO for a supplier pending vetting or
+	// blocked.
+	ErrSupplierNotApproved = errors.New("procurement: supplier is not approved for purchasing")
 )
@@ -11,6 +11,14 @@ type GRNLineEvent struct {
 	ProductID int64
 	Qty       float64
 	UnitCost  float64
+	// POUnitCost is the purchase order price per base unit, zero when the
+	// product has no PO price.
+	POUnitCost float64
+	// PriceVariance is the part of Qty x UnitCost posted to the purchase price
+	// variance account instead of stock. It is zero under CostPolicyActual.
+	PriceVariance float64
+	// Inspection marks lines held in quality inspection instead of stock.
+	Inspection bool
 }
 
 // GRNPostedEvent captures details required to post a GRN to the ledger.
@@ -23,14 +31,45 @@ type GRNPostedEvent struct {
 	Lines       []GRNLineEvent
 }
 
+// GRNInspectionDecidedEvent describes goods released from quality
+// inspection, either accepted into stock or rejected back to the supplier.
+type GRNInspectionDecidedEvent struct {
+	DecisionID  int64
+	GRNID       int64
+	GRNNumber   string
+	SupplierID  int64
+	WarehouseID int64
+	ProductID   int64
+	Decision    InspectionDecision
+	Qty         float64
+	UnitCost    float64
+	DecidedAt   time.Time
+}
+
 // APInvoicePostedEvent contains metadata for AP invoice postings.
 type APInvoicePostedEvent struct {
 	ID         int64
 	Number     string
 	SupplierID int64
 	GRNID      int64
+	Currency   string
+	Total      float64
+	TaxAmount  float64
+	PostedAt   time.Time
+}
+
+// APInvoiceVoidedEvent describes a posted AP invoice being voided, so the
+// ledger can reverse its posting.
+type APInvoiceVoidedEvent struct {
+	ID         int64
+	Number     string
+	SupplierID int64
+	GRNID      int64
+	Currency   string
 	Total      float64
+	TaxAmount  float64
 	PostedAt   time.Time
+	Reason     string
 }
 
 // APPaymentPostedEvent describes AP payment details for integration.
@@ -38,13 +77,36 @@ type APPaymentPostedEvent struct {
 	ID          int64
 	Number      string
 	APInvoiceID int64
+	Currency    string
 	Amount      float64
 	PaidAt      time.Time
+	// Withheld is tax deducted from the payment on top of the cash Amount;
+	// the invoices are settled by Amount plus Withheld.
+	Withheld float64
+}
+
+// APPaymentRunPostedEvent describes a batch of AP payments journalled as one
+// combined cash/AP entry.
+type APPaymentRunPostedEvent struct {
+	ID     int64
+	Number string
+	Amount float64
+	PaidAt time.Time
+	// ByCurrency splits Amount by invoice currency. Nil means Amount is
+	// already in the base currency.
+	ByCurrency map[string]float64
+	// Withheld is tax deducted from the payments on top of the cash Amount,
+	// split by currency like Amount.
+	Withheld           float64
+	WithheldByCurrency map[string]float64
 }
 
 // IntegrationHandler receives procurement domain events for ledger integration.
 type IntegrationHandler interface {
 	HandleGRNPosted(ctx context.Context, evt GRNPostedEvent) error
+	HandleGRNInspectionDecided(ctx context.Context, evt GRNInspectionDecidedEvent) error
 	HandleAPInvoicePosted(ctx context.Context, evt APInvoicePostedEvent) error
+	HandleAPInvoiceVoided(ctx context.Context, evt APInvoiceVoidedEvent) error
 	HandleAPPaymentPosted(ctx context.Context, evt APPaymentPostedEvent) error
+	HandleAPPaymentRunPosted(ctx context.Context, evt APPaymentRunPostedEvent) error
 }
@@ -1,13 +1,18 @@
 package procurement
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -37,6 +42,8 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Get("/pos/new", h.showPOForm)
 		r.Get("/grns", h.handleListGRNs)
 		r.Get("/grns/new", h.showGRNForm)
+		r.Get("/grns/{id}/variances", h.grnVariances)
+		r.Get("/inspections", h.listInspections)
 
 	})
 	r.Group(func(r chi.Router) {
@@ -44,12 +51,18 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Post("/prs", h.createPR)
 		r.Post("/prs/{id}/submit", h.submitPR)
 		r.Post("/pos", h.createPO)
+		r.Post("/pos/consolidate", h.consolidatePRs)
+		r.Post("/pos/replenish", h.replenish)
 		r.Post("/pos/{id}/submit", h.submitPO)
 		r.Post("/pos/{id}/approve", h.approvePO)
 		r.Post("/grns", h.createGRN)
 		r.Post("/grns/{id}/post", h.postGRN)
 
 	})
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAll("procurement.inspect"))
+		r.Post("/inspections/{id}/decide", h.decideInspection)
+	})
 }
 
 type formErrors map[string]string
@@ -82,8 +95,8 @@ func (h *Handler) handleListPOs(w http.ResponseWriter, r *http.Request) {
 	}
 	items, total, err := h.service.ListPOs(r.Context(), limit, offset, filters)
 	if err != nil {
-		h.logger.Error("list POs", slog.Any("error", err))
-		http.Error(w, "Failed to load purchase orders", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list POs", slog.Any("error", err))
+		httpx.Error(w, r, "Failed to load purchase orders", http.StatusInternalServerError)
 		return
 	}
 	h.render(w, r, "pages/procurement/pos_list.html", map[string]any{
@@ -111,8 +124,8 @@ func (h *Handler) handleListGRNs(w http.ResponseWriter, r *http.Request) {
 	}
 	items, total, err := h.service.ListGRNs(r.Context(), limit, offset, filters)
 	if err != nil {
-		h.logger.Error("list GRNs", slog.Any("error", err))
-		http.Error(w, "Failed to load goods receipts", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list GRNs", slog.Any("error", err))
+		httpx.Error(w, r, "Failed to load goods receipts", http.StatusInternalServerError)
 		return
 	}
 	h.render(w, r, "pages/procurement/grns_list.html", map[string]any{
@@ -126,7 +139,7 @@ func (h *Handler) handleListGRNs(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) createPR(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	lines := []PRLineInput{}
@@ -147,10 +160,11 @@ func (h *Handler) createPR(w http.ResponseWriter, r *http.Request) {
 		SupplierID: supplierID,
 		RequestBy:  reqBy,
 		Note:       r.PostFormValue("note"),
+		Currency:   r.PostFormValue("currency"),
 		Lines:      lines,
 	})
 	if err != nil {
-		h.logger.Error("create PR", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "create PR", slog.Any("error", err))
 		h.render(w, r, "pages/procurement/pr_form.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusBadRequest)
 		return
 	}
@@ -160,7 +174,7 @@ func (h *Handler) createPR(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) submitPR(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err := h.service.SubmitPurchaseRequest(r.Context(), id, currentUser(r)); err != nil {
-		h.logger.Error("submit PR", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.ErrorContext(r.Context(), "submit PR", slog.Any("error", err), slog.Int64("id", id))
 		h.render(w, r, "pages/procurement/pr_form.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusBadRequest)
 		return
 	}
@@ -169,7 +183,7 @@ func (h *Handler) submitPR(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) createPO(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	prID, _ := strconv.ParseInt(r.PostFormValue("pr_id"), 10, 64)
@@ -182,18 +196,91 @@ func (h *Handler) createPO(w http.ResponseWriter, r *http.Request) {
 		Note:         r.PostFormValue("note"),
 	})
 	if err != nil {
-		h.logger.Error("create PO", slog.Any("error", err))
-		h.render(w, r, "pages/procurement/po_form.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusBadRequest)
+		h.logger.ErrorContext(r.Context(), "create PO", slog.Any("error", err))
+		h.render(w, r, "pages/procurement/po_form.html", map[string]any{"Errors": formErrors{"general": poErrorMessage(err)}}, http.StatusBadRequest)
 		return
 	}
 	h.redirectWithFlash(w, r, "/procurement/pos", "success", "PO berhasil dibuat")
 }
 
+func (h *Handler) consolidatePRs(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	var prIDs []int64
+	for _, raw := range r.PostForm["pr_ids"] {
+		for _, part := range strings.Split(raw, ",") {
+			if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+				prIDs = append(prIDs, id)
+			}
+		}
+	}
+	expectedDate, _ := time.Parse("2006-01-02", r.PostFormValue("expected_date"))
+	po, err := h.service.ConsolidatePurchaseRequests(r.Context(), ConsolidatePOInput{
+		PRIDs:        prIDs,
+		Number:       r.PostFormValue("number"),
+		ExpectedDate: expectedDate,
+		Note:         r.PostFormValue("note"),
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "consolidate PRs", slog.Any("error", err))
+		h.render(w, r, "pages/procurement/po_form.html", map[string]any{"Errors": formErrors{"consolidate": consolidateErrorMessage(err)}}, http.StatusBadRequest)
+		return
+	}
+	h.redirectWithFlash(w, r, "/procurement/pos", "success", fmt.Sprintf("PO %s dibuat dari %d PR", po.Number, len(prIDs)))
+}
+
+// replenish creates draft POs from the replenishment suggestions selected as
+// "warehouse:product" pairs.
+func (h *Handler) replenish(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	var selections []ReplenishmentSelection
+	for _, raw := range r.PostForm["lines"] {
+		warehouse, product, ok := strings.Cut(raw, ":")
+		if !ok {
+			continue
+		}
+		warehouseID, err1 := strconv.ParseInt(strings.TrimSpace(warehouse), 10, 64)
+		productID, err2 := strconv.ParseInt(strings.TrimSpace(product), 10, 64)
+		if err1 == nil && err2 == nil {
+			selections = append(selections, ReplenishmentSelection{WarehouseID: warehouseID, ProductID: productID})
+		}
+	}
+	pos, err := h.service.CreateReplenishmentPOs(r.Context(), ReplenishmentPOInput{Selections: selections, Currency: r.PostFormValue("currency")})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "replenishment POs", slog.Any("error", err))
+		h.redirectWithFlash(w, r, "/inventory/replenishment", "error", consolidateErrorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, "/procurement/pos?status=DRAFT", "success", fmt.Sprintf("%d draft PO dibuat dari saran pengadaan", len(pos)))
+}
+
+func consolidateErrorMessage(err error) string {
+	for _, known := range []error{ErrSupplierMismatch, ErrCurrencyMismatch, ErrSupplierNotApproved, ErrInvalidState, ErrValidation, ErrNotFound, shared.ErrForbidden} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
+
+// poErrorMessage explains supplier approval failures and hides other errors.
+func poErrorMessage(err error) string {
+	if errors.Is(err, ErrSupplierNotApproved) {
+		return err.Error()
+	}
+	return shared.UserSafeMessage(err)
+}
+
 func (h *Handler) submitPO(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err := h.service.SubmitPurchaseOrder(r.Context(), id, currentUser(r)); err != nil {
-		h.logger.Error("submit PO", slog.Any("error", err), slog.Int64("id", id))
-		h.render(w, r, "pages/procurement/po_form.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusBadRequest)
+		h.logger.ErrorContext(r.Context(), "submit PO", slog.Any("error", err), slog.Int64("id", id))
+		h.render(w, r, "pages/procurement/po_form.html", map[string]any{"Errors": formErrors{"general": poErrorMessage(err)}}, http.StatusBadRequest)
 		return
 	}
 	h.redirectWithFlash(w, r, "/procurement/pos", "success", "PO diajukan")
@@ -202,7 +289,7 @@ func (h *Handler) submitPO(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) approvePO(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err := h.service.ApprovePurchaseOrder(r.Context(), id, currentUser(r)); err != nil {
-		h.logger.Error("approve PO", slog.Any("error", err), slog.Int64("id", id))
+		h.logger.ErrorContext(r.Context(), "approve PO", slog.Any("error", err), slog.Int64("id", id))
 		h.render(w, r, "pages/procurement/po_form.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusBadRequest)
 		return
 	}
@@ -211,7 +298,7 @@ func (h *Handler) approvePO(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) createGRN(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	poID, _ := strconv.ParseInt(r.PostFormValue("po_id"), 10, 64)
@@ -221,6 +308,8 @@ func (h *Handler) createGRN(w http.ResponseWriter, r *http.Request) {
 	productIDs := r.PostForm["product_id"]
 	qtys := r.PostForm["qty"]
 	costs := r.PostForm["unit_cost"]
+	serials := r.PostForm["serials"]
+	uoms := r.PostForm["uom"]
 	var lines []GRNLineInput
 	for i := range productIDs {
 		pid, _ := strconv.ParseInt(productIDs[i], 10, 64)
@@ -229,7 +318,14 @@ func (h *Handler) createGRN(w http.ResponseWriter, r *http.Request) {
 		if pid == 0 || qty <= 0 {
 			continue
 		}
-		lines = append(lines, GRNLineInput{ProductID: pid, Qty: qty, UnitCost: cost})
+		line := GRNLineInput{ProductID: pid, Qty: qty, UnitCost: cost}
+		if i < len(uoms) {
+			line.UOM = uoms[i]
+		}
+		if i < len(serials) {
+			line.Serials = inventory.ParseSerials(serials[i])
+		}
+		lines = append(lines, line)
 	}
 	_, err := h.service.CreateGoodsReceipt(r.Context(), CreateGRNInput{
 		POID:        poID,
@@ -241,8 +337,8 @@ func (h *Handler) createGRN(w http.ResponseWriter, r *http.Request) {
 		Lines:       lines,
 	})
 	if err != nil {
-		h.logger.Error("create GRN", slog.Any("error", err))
-		h.render(w, r, "pages/procurement/grn_form.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusBadRequest)
+		h.logger.ErrorContext(r.Context(), "create GRN", slog.Any("error", err))
+		h.render(w, r, "pages/procurement/grn_form.html", map[string]any{"Errors": formErrors{"general": grnErrorMessage(err)}}, http.StatusBadRequest)
 		return
 	}
 	h.redirectWithFlash(w, r, "/procurement/grns", "success", "GRN dibuat")
@@ -250,14 +346,107 @@ func (h *Handler) createGRN(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) postGRN(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
-	if err := h.service.PostGoodsReceipt(r.Context(), id); err != nil {
-		h.logger.Error("post GRN", slog.Any("error", err), slog.Int64("id", id))
-		h.render(w, r, "pages/procurement/grn_form.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusBadRequest)
+	opts := PostGRNOptions{HoldForInspection: r.FormValue("hold_for_inspection") != ""}
+	if err := h.service.PostGoodsReceiptWithOptions(r.Context(), id, opts); err != nil {
+		h.logger.ErrorContext(r.Context(), "post GRN", slog.Any("error", err), slog.Int64("id", id))
+		h.render(w, r, "pages/procurement/grn_form.html", map[string]any{"Errors": formErrors{"general": grnErrorMessage(err)}}, http.StatusBadRequest)
 		return
 	}
 	h.redirectWithFlash(w, r, "/procurement/grns", "success", "GRN diposting")
 }
 
+// grnVariances lists each GRN line's cost against the PO price as JSON.
+func (h *Handler) grnVariances(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	variances, err := h.service.GRNPriceVariances(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			httpx.Problem(w, http.StatusNotFound, "Not found", "goods receipt not found")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "GRN price variances", slog.Any("error", err), slog.Int64("id", id))
+		httpx.Problem(w, http.StatusInternalServerError, "Variance unavailable", shared.UserSafeMessage(err))
+		return
+	}
+	var total float64
+	for _, v := range variances {
+		total += v.Variance
+	}
+	httpx.JSON(w, http.StatusOK, map[string]any{
+		"grn_id":         id,
+		"cost_policy":    h.service.CostPolicy(),
+		"lines":          variances,
+		"total_variance": roundCents(total),
+	})
+}
+
+func (h *Handler) listInspections(w http.ResponseWriter, r *http.Request) {
+	filter := InspectionFilter{Status: InspectionStatus(r.URL.Query().Get("status"))}
+	items, err := h.service.ListInspections(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list inspections", slog.Any("error", err))
+		httpx.Error(w, r, "Failed to load inspections", http.StatusInternalServerError)
+		return
+	}
+	if filter.Status == "" {
+		filter.Status = InspectionPending
+	}
+	h.render(w, r, "pages/procurement/inspections_list.html", map[string]any{
+		"Inspections": items,
+		"Filter":      filter,
+	}, http.StatusOK)
+}
+
+func (h *Handler) decideInspection(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	qty, _ := strconv.ParseFloat(r.PostFormValue("qty"), 64)
+	decision := InspectionDecision(strings.ToUpper(r.PostFormValue("decision")))
+	_, err := h.service.DecideInspection(r.Context(), DecideInspectionInput{
+		InspectionID:    id,
+		Decision:        decision,
+		Qty:             qty,
+		Reason:          r.PostFormValue("reason"),
+		ReturnReference: r.PostFormValue("return_reference"),
+		ActorID:         currentUser(r),
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "decide inspection", slog.Any("error", err), slog.Int64("id", id))
+		h.redirectWithFlash(w, r, "/procurement/inspections", "error", inspectionErrorMessage(err))
+		return
+	}
+	message := "Barang diterima ke stok"
+	if decision == InspectionReject {
+		message = "Barang ditolak dan dicatat untuk retur ke supplier"
+	}
+	h.redirectWithFlash(w, r, "/procurement/inspections", "success", message)
+}
+
+// inspectionErrorMessage explains quantity and state problems with an
+// inspection decision and hides other errors.
+func inspectionErrorMessage(err error) string {
+	for _, known := range []error{ErrInspectionQty, ErrInvalidState, ErrValidation, ErrNotFound} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
+
+// grnErrorMessage spells out serial number problems so the receiver can fix
+// the entry.
+func grnErrorMessage(err error) string {
+	for _, known := range []error{inventory.ErrSerialCount, inventory.ErrDuplicateSerial, ErrValidation} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	return shared.UserSafeMessage(err)
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
 	sess := shared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -268,7 +457,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	viewData := view.TemplateData{Title: "Procurement", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
 	}
 }
 
@@ -0,0 +1,220 @@
+package procurement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+)
+
+// InspectionStatus tracks whether a held GRN line still awaits a decision.
+type InspectionStatus string
+
+const (
+	InspectionPending   InspectionStatus = "PENDING"
+	InspectionCompleted InspectionStatus = "COMPLETED"
+)
+
+// InspectionDecision is the outcome of one inspection step.
+type InspectionDecision string
+
+const (
+	// InspectionAccept moves goods from inspection into the GRN warehouse.
+	InspectionAccept InspectionDecision = "ACCEPT"
+	// InspectionReject returns goods to the supplier; they never enter stock.
+	InspectionReject InspectionDecision = "REJECT"
+)
+
+// ErrInspectionQty indicates a decision for more than is awaiting inspection.
+var ErrInspectionQty = errors.New("procurement: quantity exceeds what is awaiting inspection")
+
+// inspectionEpsilon absorbs float rounding when comparing base quantities.
+const inspectionEpsilon = 1e-6
+
+// GRNInspection is a goods receipt line held in quality inspection. Qty,
+// UnitCost and the decided quantities are in the product's base unit.
+type GRNInspection struct {
+	ID            int64
+	GRNID         int64
+	GRNLineID     int64
+	GRNNumber     string
+	SupplierID    int64
+	SupplierName  string
+	ProductID     int64
+	ProductName   string
+	WarehouseID   int64
+	WarehouseName string
+	Qty           float64
+	UnitCost      float64
+	BaseFactor    float64
+	AcceptedQty   float64
+	RejectedQty   float64
+	Status        InspectionStatus
+	CreatedAt     time.Time
+}
+
+// Pending returns the quantity still awaiting a decision.
+func (i GRNInspection) Pending() float64 {
+	return i.Qty - i.AcceptedQty - i.RejectedQty
+}
+
+// InspectionFilter narrows the inspection list. A blank Status lists
+// pending inspections.
+type InspectionFilter struct {
+	Status InspectionStatus
+}
+
+// PostGRNOptions tunes goods receipt posting.
+type PostGRNOptions struct {
+	// HoldForInspection books every line into quality inspection, on top of
+	// the products and suppliers flagged as requiring inspection.
+	HoldForInspection bool
+}
+
+// DecideInspectionInput records one accept or reject step. Qty is in the
+// product's base unit.
+type DecideInspectionInput struct {
+	InspectionID    int64
+	Decision        InspectionDecision
+	Qty             float64
+	Reason          string
+	ReturnReference string
+	ActorID         int64
+	DecidedAt       time.Time
+}
+
+// ListInspections returns goods receipt lines held in quality inspection.
+func (s *Service) ListInspections(ctx context.Context, filter InspectionFilter) ([]GRNInspection, error) {
+	if filter.Status == "" {
+		filter.Status = InspectionPending
+	}
+	return s.repo.ListInspections(ctx, filter)
+}
+
+// heldForInspection returns the IDs of the lines to book into quality
+// inspection instead of stock. Serial-tracked lines are never held: their
+// serials are registered as in stock when the receipt posts.
+func (s *Service) heldForInspection(ctx context.Context, grn GoodsReceipt, lines []GRNLine, opts PostGRNOptions) (map[int64]bool, error) {
+	productIDs := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	required, err := s.repo.InspectionRequirements(ctx, grn.SupplierID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	held := make(map[int64]bool)
+	for _, line := range lines {
+		if len(line.Serials) > 0 {
+			continue
+		}
+		if opts.HoldForInspection || required[line.ProductID] {
+			held[line.ID] = true
+		}
+	}
+	return held, nil
+}
+
+// DecideInspection accepts or rejects part or all of an inspected line.
+// Accepted goods are posted into the GRN warehouse at the receipt's cost;
+// rejected goods are recorded against the supplier return and reduce what
+// the GRN can be invoiced for. The line completes once nothing is pending.
+func (s *Service) DecideInspection(ctx context.Context, input DecideInspectionInput) (GRNInspection, error) {
+	input.Reason = strings.TrimSpace(input.Reason)
+	input.ReturnReference = strings.TrimSpace(input.ReturnReference)
+	if input.InspectionID == 0 || input.Qty <= 0 {
+		return GRNInspection{}, fmt.Errorf("%w: inspection and a positive quantity are required", ErrValidation)
+	}
+	if input.Decision != InspectionAccept && input.Decision != InspectionReject {
+		return GRNInspection{}, fmt.Errorf("%w: unknown decision %q", ErrValidation, input.Decision)
+	}
+	if input.Decision == InspectionReject && input.Reason == "" {
+		return GRNInspection{}, fmt.Errorf("%w: a reason is required to reject goods", ErrValidation)
+	}
+	if input.DecidedAt.IsZero() {
+		input.DecidedAt = time.Now()
+	}
+	if input.Decision == InspectionAccept && s.inventory == nil {
+		return GRNInspection{}, errors.New("inventory integration not configured")
+	}
+	var (
+		inspection GRNInspection
+		decisionID int64
+	)
+	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		var err error
+		inspection, err = tx.LockInspection(ctx, input.InspectionID)
+		if err != nil {
+			return err
+		}
+		if inspection.Status != InspectionPending {
+			return ErrInvalidState
+		}
+		pending := inspection.Pending()
+		if input.Qty > pending+inspectionEpsilon {
+			return fmt.Errorf("%w (%.4f pending)", ErrInspectionQty, pending)
+		}
+		if math.Abs(input.Qty-pending) <= inspectionEpsilon {
+			input.Qty = pending
+		}
+		if input.Decision == InspectionAccept {
+			inspection.AcceptedQty += input.Qty
+		} else {
+			inspection.RejectedQty += input.Qty
+		}
+		if inspection.Pending() <= inspectionEpsilon {
+			inspection.Status = InspectionCompleted
+		}
+		decisionID, err = tx.RecordInspectionDecision(ctx, inspection, input)
+		if err != nil {
+			return err
+		}
+		if input.Decision != InspectionAccept {
+			return nil
+		}
+		_, err = s.inventory.PostInboundBatch(ctx, inventory.InboundBatchInput{
+			Code:        fmt.Sprintf("GRN-%s-QC-%d", inspection.GRNNumber, decisionID),
+			WarehouseID: inspection.WarehouseID,
+			Lines:       []inventory.InboundLine{{ProductID: inspection.ProductID, Qty: input.Qty, UnitCost: inspection.UnitCost}},
+			Note:        fmt.Sprintf("GRN %s accepted from inspection", inspection.GRNNumber),
+			RefModule:   "PROCUREMENT",
+			RefID:       uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("GRNQC:%d", decisionID))).String(),
+		})
+		return err
+	})
+	if err != nil {
+		return GRNInspection{}, err
+	}
+	s.recordAudit(ctx, "GRN_INSPECTION_"+string(input.Decision), inspection.GRNID, map[string]any{
+		"number":           inspection.GRNNumber,
+		"inspection_id":    inspection.ID,
+		"product_id":       inspection.ProductID,
+		"qty":              input.Qty,
+		"reason":           input.Reason,
+		"return_reference": input.ReturnReference,
+		"actor_id":         input.ActorID,
+	})
+	if s.integration != nil {
+		if err := s.integration.HandleGRNInspectionDecided(ctx, GRNInspectionDecidedEvent{
+			DecisionID:  decisionID,
+			GRNID:       inspection.GRNID,
+			GRNNumber:   inspection.GRNNumber,
+			SupplierID:  inspection.SupplierID,
+			WarehouseID: inspection.WarehouseID,
+			ProductID:   inspection.ProductID,
+			Decision:    input.Decision,
+			Qty:         input.Qty,
+			UnitCost:    inspection.UnitCost,
+			DecidedAt:   input.DecidedAt,
+		}); err != nil {
+			return GRNInspection{}, err
+		}
+	}
+	return inspection, nil
+}
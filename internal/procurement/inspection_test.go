@@ -0,0 +1,147 @@
+package procurement
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHandler struct {
+	posted    []GRNPostedEvent
+	decisions []GRNInspectionDecidedEvent
+}
+
+func (h *recordingHandler) HandleGRNPosted(_ context.Context, evt GRNPostedEvent) error {
+	h.posted = append(h.posted, evt)
+	return nil
+}
+
+func (h *recordingHandler) HandleGRNInspectionDecided(_ context.Context, evt GRNInspectionDecidedEvent) error {
+	h.decisions = append(h.decisions, evt)
+	return nil
+}
+
+func (h *recordingHandler) HandleAPInvoicePosted(context.Context, APInvoicePostedEvent) error {
+	return nil
+}
+
+func (h *recordingHandler) HandleAPInvoiceVoided(context.Context, APInvoiceVoidedEvent) error {
+	return nil
+}
+
+func (h *recordingHandler) HandleAPPaymentPosted(context.Context, APPaymentPostedEvent) error {
+	return nil
+}
+
+func (h *recordingHandler) HandleAPPaymentRunPosted(context.Context, APPaymentRunPostedEvent) error {
+	return nil
+}
+
+func heldReceipt(t *testing.T, svc *Service, lines ...GRNLineInput) GoodsReceipt {
+	t.Helper()
+	grn, err := svc.CreateGoodsReceipt(context.Background(), CreateGRNInput{POID: 1, SupplierID: 1, WarehouseID: 2, Number: "GRN-QC", Lines: lines})
+	require.NoError(t, err)
+	return grn
+}
+
+func TestPostGoodsReceiptHoldsFlaggedProductsForInspection(t *testing.T) {
+	repo := newMemoryProcRepo()
+	repo.pos[1] = PurchaseOrder{ID: 1, SupplierID: 1, Status: POStatusApproved}
+	repo.nextID = 1
+	repo.inspectProducts[12] = true
+	inv := &stubInventory{}
+	events := &recordingHandler{}
+	svc := NewService(repo, inv, nil, nil, nil, events)
+	svc.SetUnitConverter(stubUnits{"BOX": 10})
+	ctx := context.Background()
+
+	grn := heldReceipt(t, svc,
+		GRNLineInput{ProductID: 11, Qty: 5, UnitCost: 100},
+		GRNLineInput{ProductID: 12, Qty: 2, UnitCost: 500, UOM: "BOX"},
+	)
+	require.NoError(t, svc.PostGoodsReceipt(ctx, grn.ID))
+
+	require.Len(t, inv.records, 1)
+	require.Len(t, inv.records[0].Lines, 1, "the flagged product stays out of stock")
+	require.Equal(t, int64(11), inv.records[0].Lines[0].ProductID)
+
+	pending, err := svc.ListInspections(ctx, InspectionFilter{})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, int64(12), pending[0].ProductID)
+	require.Equal(t, 20.0, pending[0].Qty)
+	require.Equal(t, 50.0, pending[0].UnitCost)
+
+	require.Len(t, events.posted, 1)
+	require.False(t, events.posted[0].Lines[0].Inspection)
+	require.True(t, events.posted[0].Lines[1].Inspection)
+}
+
+func TestPostGoodsReceiptHoldForInspectionSkipsSerialLines(t *testing.T) {
+	repo := newMemoryProcRepo()
+	repo.pos[1] = PurchaseOrder{ID: 1, SupplierID: 1, Status: POStatusApproved}
+	repo.nextID = 1
+	repo.trackSerial[11] = true
+	inv := &stubInventory{}
+	svc := NewService(repo, inv, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	grn := heldReceipt(t, svc,
+		GRNLineInput{ProductID: 11, Qty: 1, UnitCost: 100, Serials: []string{"SN-1"}},
+		GRNLineInput{ProductID: 12, Qty: 3, UnitCost: 100},
+	)
+	require.NoError(t, svc.PostGoodsReceiptWithOptions(ctx, grn.ID, PostGRNOptions{HoldForInspection: true}))
+	require.Len(t, inv.records, 1)
+	require.Equal(t, int64(11), inv.records[0].Lines[0].ProductID)
+	require.Equal(t, []string{"SN-1"}, repo.serials[11])
+	require.Len(t, repo.inspections, 1)
+}
+
+func TestDecideInspectionAcceptsAndRejectsInSteps(t *testing.T) {
+	repo := newMemoryProcRepo()
+	repo.pos[1] = PurchaseOrder{ID: 1, SupplierID: 1, Status: POStatusApproved}
+	repo.nextID = 1
+	repo.inspectSuppliers[1] = true
+	inv := &stubInventory{}
+	events := &recordingHandler{}
+	svc := NewService(repo, inv, nil, nil, nil, events)
+	ctx := context.Background()
+
+	grn := heldReceipt(t, svc, GRNLineInput{ProductID: 11, Qty: 10, UnitCost: 100})
+	require.NoError(t, svc.PostGoodsReceipt(ctx, grn.ID))
+	require.Empty(t, inv.records, "a supplier flagged for inspection books nothing into stock")
+	pending, err := svc.ListInspections(ctx, InspectionFilter{})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	id := pending[0].ID
+
+	_, err = svc.DecideInspection(ctx, DecideInspectionInput{InspectionID: id, Decision: InspectionReject, Qty: 2})
+	require.ErrorIs(t, err, ErrValidation, "a rejection needs a reason")
+
+	inspection, err := svc.DecideInspection(ctx, DecideInspectionInput{InspectionID: id, Decision: InspectionAccept, Qty: 7})
+	require.NoError(t, err)
+	require.Equal(t, InspectionPending, inspection.Status)
+	require.Len(t, inv.records, 1)
+	require.Equal(t, 7.0, inv.records[0].Lines[0].Qty)
+	require.Equal(t, 100.0, inv.records[0].Lines[0].UnitCost)
+	require.Contains(t, inv.records[0].Code, "GRN-QC-QC-")
+
+	_, err = svc.DecideInspection(ctx, DecideInspectionInput{InspectionID: id, Decision: InspectionReject, Qty: 4, Reason: "damaged"})
+	require.ErrorIs(t, err, ErrInspectionQty)
+
+	inspection, err = svc.DecideInspection(ctx, DecideInspectionInput{InspectionID: id, Decision: InspectionReject, Qty: 3, Reason: "damaged", ReturnReference: "RMA-9"})
+	require.NoError(t, err)
+	require.Equal(t, InspectionCompleted, inspection.Status)
+	require.Equal(t, 3.0, inspection.RejectedQty)
+	require.Len(t, inv.records, 1, "rejected goods never enter stock")
+
+	_, err = svc.DecideInspection(ctx, DecideInspectionInput{InspectionID: id, Decision: InspectionAccept, Qty: 1})
+	require.ErrorIs(t, err, ErrInvalidState)
+
+	require.Len(t, events.decisions, 2)
+	require.Equal(t, InspectionAccept, events.decisions[0].Decision)
+	require.Equal(t, InspectionReject, events.decisions[1].Decision)
+	require.Equal(t, 3.0, events.decisions[1].Qty)
+	require.Equal(t, 100.0, events.decisions[1].UnitCost)
+}
@@ -0,0 +1,134 @@
+package procurement
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CostPolicy decides the cost received goods are valued at when the GRN unit
+// cost differs from the purchase order price.
+type CostPolicy string
+
+const (
+	// CostPolicyActual values stock at the GRN unit cost. No variance is posted.
+	CostPolicyActual CostPolicy = "actual"
+	// CostPolicyPOPrice values stock at the PO price and posts the difference
+	// to the purchase price variance (PPV) account.
+	CostPolicyPOPrice CostPolicy = "po_price"
+)
+
+// ParseCostPolicy validates a cost policy. Blank is CostPolicyActual.
+func ParseCostPolicy(raw string) (CostPolicy, error) {
+	switch p := CostPolicy(strings.ToLower(strings.TrimSpace(raw))); p {
+	case "":
+		return CostPolicyActual, nil
+	case CostPolicyActual, CostPolicyPOPrice:
+		return p, nil
+	}
+	return "", fmt.Errorf("%w: unknown GRN cost policy %q", ErrValidation, raw)
+}
+
+// SetCostPolicy chooses how goods receipts are valued. The default is
+// CostPolicyActual.
+func (s *Service) SetCostPolicy(policy CostPolicy) {
+	s.costPolicy = policy
+}
+
+// CostPolicy reports how goods receipts are valued.
+func (s *Service) CostPolicy() CostPolicy {
+	if s.costPolicy == "" {
+		return CostPolicyActual
+	}
+	return s.costPolicy
+}
+
+// GRNLineVariance compares a GRN line with the price on its purchase order.
+// Quantities and costs are per base unit.
+type GRNLineVariance struct {
+	GRNLineID  int64   `json:"grn_line_id"`
+	ProductID  int64   `json:"product_id"`
+	Qty        float64 `json:"qty"`
+	UnitCost   float64 `json:"unit_cost"`
+	POUnitCost float64 `json:"po_unit_cost"`
+	// Variance is (UnitCost - POUnitCost) x Qty: positive when the goods cost
+	// more than ordered. Zero when the product has no PO price.
+	Variance float64 `json:"variance"`
+	// StockUnitCost is the cost the line is valued at under the current policy.
+	StockUnitCost float64 `json:"stock_unit_cost"`
+}
+
+// GRNPriceVariances lists the price variance of each line of a goods receipt
+// against its purchase order.
+func (s *Service) GRNPriceVariances(ctx context.Context, grnID int64) ([]GRNLineVariance, error) {
+	grn, lines, err := s.repo.GetGRN(ctx, grnID)
+	if err != nil {
+		return nil, err
+	}
+	variances := make([]GRNLineVariance, len(lines))
+	prices, err := s.poPrices(ctx, grn.POID)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		factor, err := s.baseFactor(ctx, line.ProductID, line.UOM)
+		if err != nil {
+			return nil, err
+		}
+		line.Qty *= factor
+		line.UnitCost /= factor
+		variances[i] = s.lineVariance(line, prices)
+	}
+	return variances, nil
+}
+
+// lineVariance values a base-unit GRN line against the PO prices.
+func (s *Service) lineVariance(line GRNLine, prices map[int64]float64) GRNLineVariance {
+	v := GRNLineVariance{
+		GRNLineID:     line.ID,
+		ProductID:     line.ProductID,
+		Qty:           line.Qty,
+		UnitCost:      line.UnitCost,
+		StockUnitCost: line.UnitCost,
+	}
+	price, ok := prices[line.ProductID]
+	if !ok {
+		return v
+	}
+	v.POUnitCost = price
+	v.Variance = roundCents((line.UnitCost - price) * line.Qty)
+	if s.costPolicy == CostPolicyPOPrice {
+		v.StockUnitCost = price
+	}
+	return v
+}
+
+// poPrices returns the quantity-weighted PO price of each product, per base
+// unit. Lines without a price are left out so they keep the GRN cost.
+func (s *Service) poPrices(ctx context.Context, poID int64) (map[int64]float64, error) {
+	prices := make(map[int64]float64)
+	if poID == 0 {
+		return prices, nil
+	}
+	_, lines, err := s.repo.GetPO(ctx, poID)
+	if err != nil {
+		return nil, fmt.Errorf("load purchase order %d: %w", poID, err)
+	}
+	qty := make(map[int64]float64)
+	for _, line := range lines {
+		if line.Price <= 0 || line.Qty <= 0 {
+			continue
+		}
+		prices[line.ProductID] += line.Price * line.Qty
+		qty[line.ProductID] += line.Qty
+	}
+	for id, total := range prices {
+		prices[id] = total / qty[id]
+	}
+	return prices, nil
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,75 @@
+package procurement
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCostPolicy(t *testing.T) {
+	policy, err := ParseCostPolicy("")
+	require.NoError(t, err)
+	require.Equal(t, CostPolicyActual, policy)
+
+	policy, err = ParseCostPolicy(" PO_PRICE ")
+	require.NoError(t, err)
+	require.Equal(t, CostPolicyPOPrice, policy)
+
+	_, err = ParseCostPolicy("standard")
+	require.ErrorIs(t, err, ErrValidation)
+}
+
+func TestPostGoodsReceiptValuesStockByCostPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy    CostPolicy
+		stockCost float64
+		variance  float64
+	}{
+		{CostPolicyActual, 5000, 0},
+		{CostPolicyPOPrice, 4800, 7200},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			repo := newMemoryProcRepo()
+			repo.pos[1] = PurchaseOrder{ID: 1, SupplierID: 1, Status: POStatusApproved}
+			repo.poLines[1] = []POLine{
+				{ProductID: 11, Qty: 24, Price: 4700},
+				{ProductID: 11, Qty: 12, Price: 5000},
+				{ProductID: 12, Qty: 5, Price: 0},
+			}
+			repo.nextID = 1
+			inv := &stubInventory{}
+			events := &recordingHandler{}
+			svc := NewService(repo, inv, nil, nil, nil, events)
+			svc.SetUnitConverter(stubUnits{"BOX": 12})
+			svc.SetCostPolicy(tc.policy)
+			ctx := context.Background()
+
+			grn, err := svc.CreateGoodsReceipt(ctx, CreateGRNInput{POID: 1, SupplierID: 1, WarehouseID: 2, Lines: []GRNLineInput{
+				{ProductID: 11, Qty: 3, UnitCost: 60000, UOM: "BOX"},
+				{ProductID: 12, Qty: 5, UnitCost: 100},
+			}})
+			require.NoError(t, err)
+
+			variances, err := svc.GRNPriceVariances(ctx, grn.ID)
+			require.NoError(t, err)
+			require.Len(t, variances, 2)
+			require.Equal(t, 4800.0, variances[0].POUnitCost, "the PO price is weighted by quantity")
+			require.Equal(t, 7200.0, variances[0].Variance)
+			require.Equal(t, tc.stockCost, variances[0].StockUnitCost)
+			require.Zero(t, variances[1].Variance, "unpriced PO lines have no variance")
+			require.Equal(t, 100.0, variances[1].StockUnitCost)
+
+			require.NoError(t, svc.PostGoodsReceipt(ctx, grn.ID))
+			require.Len(t, inv.records, 1)
+			require.Equal(t, tc.stockCost, inv.records[0].Lines[0].UnitCost)
+			require.Equal(t, 100.0, inv.records[0].Lines[1].UnitCost)
+
+			require.Len(t, events.posted, 1)
+			line := events.posted[0].Lines[0]
+			require.Equal(t, 5000.0, line.UnitCost, "GR/IR is credited at the GRN cost")
+			require.Equal(t, 4800.0, line.POUnitCost)
+			require.Equal(t, tc.variance, line.PriceVariance)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package procurement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ReplenishmentPlanner supplies the min/max suggestions turned into POs.
+type ReplenishmentPlanner interface {
+	ReplenishmentSuggestions(ctx context.Context, filter inventory.ReplenishmentFilter) ([]inventory.ReplenishmentSuggestion, error)
+}
+
+// SetReplenishmentPlanner enables creating POs from replenishment
+// suggestions.
+func (s *Service) SetReplenishmentPlanner(planner ReplenishmentPlanner) {
+	s.planner = planner
+}
+
+// ReplenishmentSelection picks the suggestion of a product in a warehouse.
+type ReplenishmentSelection struct {
+	WarehouseID int64
+	ProductID   int64
+}
+
+// ReplenishmentPOInput selects the suggestions to order.
+type ReplenishmentPOInput struct {
+	Selections []ReplenishmentSelection
+	Currency   string
+}
+
+type replenishmentGroup struct {
+	supplierID  int64
+	warehouseID int64
+	warehouse   string
+	leadTime    int
+	lines       []POLine
+}
+
+// CreateReplenishmentPOs turns the selected suggestions into draft POs, one
+// per preferred supplier and warehouse. Quantities are recomputed rather than
+// taken from the caller, so a suggestion already ordered is rejected, and
+// warehouses outside the caller's company scope cannot be ordered for. The
+// expected date allows for the longest lead time in the PO.
+func (s *Service) CreateReplenishmentPOs(ctx context.Context, input ReplenishmentPOInput) ([]PurchaseOrder, error) {
+	if s.planner == nil {
+		return nil, errors.New("procurement: replenishment planner not configured")
+	}
+	if len(input.Selections) == 0 {
+		return nil, fmt.Errorf("%w: select at least one suggestion", ErrValidation)
+	}
+	suggestions, err := s.planner.ReplenishmentSuggestions(ctx, inventory.ReplenishmentFilter{})
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[ReplenishmentSelection]inventory.ReplenishmentSuggestion, len(suggestions))
+	for _, sg := range suggestions {
+		byKey[ReplenishmentSelection{WarehouseID: sg.Rule.WarehouseID, ProductID: sg.Rule.ProductID}] = sg
+	}
+
+	var groups []*replenishmentGroup
+	index := make(map[[2]int64]*replenishmentGroup)
+	seen := make(map[ReplenishmentSelection]bool, len(input.Selections))
+	for _, sel := range input.Selections {
+		if seen[sel] {
+			continue
+		}
+		seen[sel] = true
+		sg, ok := byKey[sel]
+		if ok && !shared.CompanyAllowed(ctx, sg.CompanyID) {
+			return nil, fmt.Errorf("%w: warehouse #%d is outside your company scope", shared.ErrForbidden, sel.WarehouseID)
+		}
+		if !ok || sg.SuggestedQty <= 0 {
+			return nil, fmt.Errorf("%w: product #%d in warehouse #%d needs no order", ErrValidation, sel.ProductID, sel.WarehouseID)
+		}
+		if sg.Rule.SupplierID == 0 {
+			return nil, fmt.Errorf("%w: %s in %s has no preferred supplier", ErrValidation, sg.SKU, sg.WarehouseCode)
+		}
+		k := [2]int64{sg.Rule.SupplierID, sg.Rule.WarehouseID}
+		g, ok := index[k]
+		if !ok {
+			g = &replenishmentGroup{supplierID: sg.Rule.SupplierID, warehouseID: sg.Rule.WarehouseID, warehouse: sg.WarehouseCode}
+			index[k] = g
+			groups = append(groups, g)
+		}
+		g.leadTime = max(g.leadTime, sg.LeadTimeDays)
+		g.lines = append(g.lines, POLine{ProductID: sg.Rule.ProductID, Qty: sg.SuggestedQty})
+	}
+
+	currency := defaultString(input.Currency, "IDR")
+	if err := s.validateCurrency(ctx, currency); err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if err := s.ensureSupplierApproved(ctx, g.supplierID); err != nil {
+			return nil, err
+		}
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	pos := make([]PurchaseOrder, 0, len(groups))
+	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+		for _, g := range groups {
+			po := PurchaseOrder{
+				Number:       generateNumber("PO"),
+				SupplierID:   g.supplierID,
+				Status:       POStatusDraft,
+				Currency:     currency,
+				ExpectedDate: today.AddDate(0, 0, g.leadTime),
+				Note:         "Replenishment for " + g.warehouse,
+				WarehouseID:  g.warehouseID,
+			}
+			poID, err := tx.CreatePO(ctx, po)
+			if err != nil {
+				return err
+			}
+			for _, line := range g.lines {
+				line.POID = poID
+				if err := tx.InsertPOLine(ctx, line); err != nil {
+					return err
+				}
+			}
+			po.ID = poID
+			pos = append(pos, po)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, po := range pos {
+		s.recordAudit(ctx, "PO_REPLENISH", po.ID, map[string]any{"number": po.Number, "warehouse_id": po.WarehouseID})
+	}
+	return pos, nil
+}
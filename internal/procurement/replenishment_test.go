@@ -0,0 +1,110 @@
+package procurement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type stubPlanner []inventory.ReplenishmentSuggestion
+
+func (p stubPlanner) ReplenishmentSuggestions(context.Context, inventory.ReplenishmentFilter) ([]inventory.ReplenishmentSuggestion, error) {
+	return p, nil
+}
+
+func suggestion(warehouseID, productID, supplierID int64, qty float64, leadTime int) inventory.ReplenishmentSuggestion {
+	return inventory.ReplenishmentSuggestion{
+		ReplenishmentPosition: inventory.ReplenishmentPosition{
+			Rule:          inventory.ReplenishmentRule{WarehouseID: warehouseID, ProductID: productID, SupplierID: supplierID},
+			WarehouseCode: "WH-1",
+			SKU:           "SKU",
+		},
+		LeadTimeDays: leadTime,
+		SuggestedQty: qty,
+	}
+}
+
+func TestCreateReplenishmentPOsGroupsBySupplierAndWarehouse(t *testing.T) {
+	repo := newMemoryProcRepo()
+	svc := NewService(repo, &stubInventory{}, nil, nil, nil, nil)
+	svc.SetReplenishmentPlanner(stubPlanner{
+		suggestion(1, 10, 5, 75, 7),
+		suggestion(1, 11, 5, 20, 14),
+		suggestion(2, 10, 5, 30, 3),
+		suggestion(1, 12, 6, 8, 7),
+		suggestion(1, 13, 0, 4, 7),
+		suggestion(1, 14, 5, 0, 7),
+	})
+	ctx := context.Background()
+
+	_, err := svc.CreateReplenishmentPOs(ctx, ReplenishmentPOInput{})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = svc.CreateReplenishmentPOs(ctx, ReplenishmentPOInput{Selections: []ReplenishmentSelection{{WarehouseID: 1, ProductID: 13}}})
+	require.ErrorIs(t, err, ErrValidation, "suggestions without a preferred supplier cannot be ordered")
+	_, err = svc.CreateReplenishmentPOs(ctx, ReplenishmentPOInput{Selections: []ReplenishmentSelection{{WarehouseID: 1, ProductID: 14}}})
+	require.ErrorIs(t, err, ErrValidation, "products needing no order are rejected")
+	require.Empty(t, repo.pos)
+
+	pos, err := svc.CreateReplenishmentPOs(ctx, ReplenishmentPOInput{Selections: []ReplenishmentSelection{
+		{WarehouseID: 1, ProductID: 10},
+		{WarehouseID: 2, ProductID: 10},
+		{WarehouseID: 1, ProductID: 12},
+		{WarehouseID: 1, ProductID: 11},
+		{WarehouseID: 1, ProductID: 10},
+	}})
+	require.NoError(t, err)
+	require.Len(t, pos, 3)
+
+	first := repo.pos[pos[0].ID]
+	require.Equal(t, POStatusDraft, first.Status)
+	require.Equal(t, int64(5), first.SupplierID)
+	require.Equal(t, int64(1), first.WarehouseID)
+	require.Equal(t, "IDR", first.Currency)
+	require.Equal(t, time.Now().Truncate(24*time.Hour).AddDate(0, 0, 14), first.ExpectedDate, "the longest lead time sets the expected date")
+	lines := repo.poLines[pos[0].ID]
+	require.Len(t, lines, 2)
+	require.Equal(t, 75.0, lines[0].Qty)
+	require.Equal(t, 20.0, lines[1].Qty)
+
+	require.Equal(t, int64(2), repo.pos[pos[1].ID].WarehouseID)
+	require.Equal(t, int64(6), repo.pos[pos[2].ID].SupplierID)
+}
+
+func TestCreateReplenishmentPOsRejectsWarehousesOutsideScope(t *testing.T) {
+	repo := newMemoryProcRepo()
+	svc := NewService(repo, &stubInventory{}, nil, nil, nil, nil)
+	own, other := suggestion(1, 10, 5, 75, 7), suggestion(2, 10, 5, 30, 3)
+	own.CompanyID, other.CompanyID = 1, 2
+	svc.SetReplenishmentPlanner(stubPlanner{own, other})
+	ctx := shared.ContextWithCompanyScope(context.Background(), shared.CompanyScope{CompanyIDs: []int64{1}})
+
+	_, err := svc.CreateReplenishmentPOs(ctx, ReplenishmentPOInput{Selections: []ReplenishmentSelection{
+		{WarehouseID: 1, ProductID: 10},
+		{WarehouseID: 2, ProductID: 10},
+	}})
+	require.ErrorIs(t, err, shared.ErrForbidden)
+	require.Empty(t, repo.pos)
+
+	pos, err := svc.CreateReplenishmentPOs(ctx, ReplenishmentPOInput{Selections: []ReplenishmentSelection{{WarehouseID: 1, ProductID: 10}}})
+	require.NoError(t, err)
+	require.Len(t, pos, 1)
+}
+
+func TestCreateReplenishmentPOsRequiresApprovedSuppliers(t *testing.T) {
+	repo := newMemoryProcRepo()
+	repo.supplierStatus[6] = "BLOCKED"
+	svc := NewService(repo, &stubInventory{}, nil, nil, nil, nil)
+	svc.SetReplenishmentPlanner(stubPlanner{suggestion(1, 10, 5, 75, 7), suggestion(1, 12, 6, 8, 7)})
+
+	_, err := svc.CreateReplenishmentPOs(context.Background(), ReplenishmentPOInput{Selections: []ReplenishmentSelection{
+		{WarehouseID: 1, ProductID: 10},
+		{WarehouseID: 1, ProductID: 12},
+	}})
+	require.ErrorIs(t, err, ErrSupplierNotApproved)
+	require.Empty(t, repo.pos, "no PO is created when one supplier is blocked")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -31,6 +32,7 @@ type TxRepository interface {
 	CreatePR(ctx context.Context, pr PurchaseRequest) (int64, error)
 	InsertPRLine(ctx context.Context, line PRLine) error
 	UpdatePRStatus(ctx context.Context, id int64, status PRStatus) error
+	LinkPOSourcePR(ctx context.Context, poID, prID int64) error
 	CreatePO(ctx context.Context, po PurchaseOrder) (int64, error)
 	InsertPOLine(ctx context.Context, line POLine) error
 	UpdatePOStatus(ctx context.Context, id int64, status POStatus) error
@@ -38,6 +40,10 @@ type TxRepository interface {
 	CreateGRN(ctx context.Context, grn GoodsReceipt) (int64, error)
 	InsertGRNLine(ctx context.Context, line GRNLine) error
 	UpdateGRNStatus(ctx context.Context, id int64, status GRNStatus) error
+	ReceiveSerials(ctx context.Context, grn GoodsReceipt, line GRNLine) error
+	CreateInspection(ctx context.Context, inspection GRNInspection) (int64, error)
+	LockInspection(ctx context.Context, id int64) (GRNInspection, error)
+	RecordInspectionDecision(ctx context.Context, inspection GRNInspection, input DecideInspectionInput) (int64, error)
 }
 
 type txRepo struct {
@@ -79,6 +85,7 @@ func (r *Repository) GetPR(ctx context.Context, id int64) (PurchaseRequest, []PR
 		RequestBy: row.RequestBy,
 		Status:    PRStatus(row.Status),
 		Note:      row.Note,
+		Currency:  row.Currency,
 	}
 	if row.SupplierID.Valid {
 		pr.SupplierID = row.SupplierID.Int64
@@ -125,6 +132,9 @@ func (r *Repository) GetPO(ctx context.Context, id int64) (PurchaseOrder, []POLi
 	if row.ExpectedDate.Valid {
 		po.ExpectedDate = row.ExpectedDate.Time
 	}
+	if row.WarehouseID.Valid {
+		po.WarehouseID = row.WarehouseID.Int64
+	}
 
 	lineRows, err := r.queries.GetPOLines(ctx, id)
 	if err != nil {
@@ -188,6 +198,8 @@ func (r *Repository) GetGRN(ctx context.Context, id int64) (GoodsReceipt, []GRNL
 			ID:        l.ID,
 			GRNID:     l.GrnID,
 			ProductID: l.ProductID,
+			UOM:       l.Uom,
+			Serials:   l.Serials,
 		}
 		if l.Qty.Valid {
 			f, _ := l.Qty.Float64Value()
@@ -199,13 +211,19 @@ func (r *Repository) GetGRN(ctx context.Context, id int64) (GoodsReceipt, []GRNL
 		}
 		lines = append(lines, line)
 	}
+	if err := r.applyRejectedQty(ctx, id, lines); err != nil {
+		return GoodsReceipt{}, nil, err
+	}
 	return grn, lines, nil
 }
 
 
 
-// ListPOs returns purchase orders with supplier name and total.
+// ListPOs returns purchase orders with supplier name and total. Orders of
+// companies outside the caller's scope are left out.
 func (r *Repository) ListPOs(ctx context.Context, limit, offset int, filters ListFilters) ([]POListItem, int, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
+
 	// Count query
 	countSQL := `SELECT COUNT(*) FROM pos p WHERE 1=1`
 	args := []any{}
@@ -226,6 +244,11 @@ func (r *Repository) ListPOs(ctx context.Context, limit, offset int, filters Lis
 		args = append(args, "%"+filters.Search+"%")
 		argNum++
 	}
+	if !scope.Unrestricted {
+		countSQL += ` AND (p.company_id IS NULL OR p.company_id = ANY($` + itoa(argNum) + `))`
+		args = append(args, scope.CompanyIDs)
+		argNum++
+	}
 
 	var total int
 	if err := r.pool.QueryRow(ctx, countSQL, args...).Scan(&total); err != nil {
@@ -257,6 +280,11 @@ func (r *Repository) ListPOs(ctx context.Context, limit, offset int, filters Lis
 		args2 = append(args2, "%"+filters.Search+"%")
 		argNum2++
 	}
+	if !scope.Unrestricted {
+		dataSQL += ` AND (p.company_id IS NULL OR p.company_id = ANY($` + itoa(argNum2) + `))`
+		args2 = append(args2, scope.CompanyIDs)
+		argNum2++
+	}
 
 	// ORDER BY with sorting
 	orderBy := sortOrderPO(filters.SortBy, filters.SortDir)
@@ -284,8 +312,11 @@ func (r *Repository) ListPOs(ctx context.Context, limit, offset int, filters Lis
 	return items, total, nil
 }
 
-// ListGRNs returns goods receipts with supplier and warehouse names.
+// ListGRNs returns goods receipts with supplier and warehouse names, limited
+// to the caller's company scope.
 func (r *Repository) ListGRNs(ctx context.Context, limit, offset int, filters ListFilters) ([]GRNListItem, int, error) {
+	scope := shared.CompanyScopeFromContext(ctx)
+
 	// Count query
 	countSQL := `SELECT COUNT(*) FROM grns g WHERE 1=1`
 	args := []any{}
@@ -306,6 +337,11 @@ func (r *Repository) ListGRNs(ctx context.Context, limit, offset int, filters Li
 		args = append(args, "%"+filters.Search+"%")
 		argNum++
 	}
+	if !scope.Unrestricted {
+		countSQL += ` AND (g.company_id IS NULL OR g.company_id = ANY($` + itoa(argNum) + `))`
+		args = append(args, scope.CompanyIDs)
+		argNum++
+	}
 
 	var total int
 	if err := r.pool.QueryRow(ctx, countSQL, args...).Scan(&total); err != nil {
@@ -340,6 +376,11 @@ func (r *Repository) ListGRNs(ctx context.Context, limit, offset int, filters Li
 		args2 = append(args2, "%"+filters.Search+"%")
 		argNum2++
 	}
+	if !scope.Unrestricted {
+		dataSQL += ` AND (g.company_id IS NULL OR g.company_id = ANY($` + itoa(argNum2) + `))`
+		args2 = append(args2, scope.CompanyIDs)
+		argNum2++
+	}
 
 	// ORDER BY with sorting
 	orderBy := sortOrderGRN(filters.SortBy, filters.SortDir)
@@ -426,6 +467,7 @@ func (tx *txRepo) CreatePR(ctx context.Context, pr PurchaseRequest) (int64, erro
 		RequestBy:  pr.RequestBy,
 		Status:     string(pr.Status),
 		Note:       pr.Note,
+		Currency:   pr.Currency,
 	})
 }
 
@@ -448,6 +490,13 @@ func (tx *txRepo) UpdatePRStatus(ctx context.Context, id int64, status PRStatus)
 	})
 }
 
+func (tx *txRepo) LinkPOSourcePR(ctx context.Context, poID, prID int64) error {
+	return tx.queries.LinkPOSourcePR(ctx, sqlc.LinkPOSourcePRParams{
+		PoID: poID,
+		PrID: prID,
+	})
+}
+
 func (tx *txRepo) CreatePO(ctx context.Context, po PurchaseOrder) (int64, error) {
 	var expectedDate pgtype.Date
 	if !po.ExpectedDate.IsZero() {
@@ -460,6 +509,7 @@ func (tx *txRepo) CreatePO(ctx context.Context, po PurchaseOrder) (int64, error)
 		Currency:     po.Currency,
 		ExpectedDate: expectedDate,
 		Note:         po.Note,
+		WarehouseID:  pgtype.Int8{Int64: po.WarehouseID, Valid: po.WarehouseID != 0},
 	})
 }
 
@@ -533,12 +583,18 @@ func (tx *txRepo) InsertGRNLine(ctx context.Context, line GRNLine) error {
 	qty.Scan(fmt.Sprintf("%f", line.Qty))
 	var cost pgtype.Numeric
 	cost.Scan(fmt.Sprintf("%f", line.UnitCost))
+	serials := line.Serials
+	if serials == nil {
+		serials = []string{}
+	}
 
 	return tx.queries.InsertGRNLine(ctx, sqlc.InsertGRNLineParams{
 		GrnID:     line.GRNID,
 		ProductID: line.ProductID,
 		Qty:       qty,
 		UnitCost:  cost,
+		Serials:   serials,
+		Uom:       line.UOM,
 	})
 }
 
@@ -0,0 +1,159 @@
+package procurement
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const inspectionColumns = `i.id, i.grn_id, i.grn_line_id, g.number, g.supplier_id, COALESCE(s.name, ''),
+       i.product_id, COALESCE(p.name, ''), i.warehouse_id, COALESCE(w.name, ''),
+       i.qty::float8, i.unit_cost::float8, i.base_factor::float8,
+       i.accepted_qty::float8, i.rejected_qty::float8, i.status, i.created_at`
+
+const inspectionJoins = `FROM grn_inspections i
+JOIN grns g ON g.id = i.grn_id
+LEFT JOIN suppliers s ON s.id = g.supplier_id
+LEFT JOIN products p ON p.id = i.product_id
+LEFT JOIN warehouses w ON w.id = i.warehouse_id`
+
+func scanInspection(row pgx.Row) (GRNInspection, error) {
+	var (
+		inspection GRNInspection
+		status     string
+		supplierID pgtype.Int8
+	)
+	err := row.Scan(&inspection.ID, &inspection.GRNID, &inspection.GRNLineID, &inspection.GRNNumber, &supplierID,
+		&inspection.SupplierName, &inspection.ProductID, &inspection.ProductName, &inspection.WarehouseID,
+		&inspection.WarehouseName, &inspection.Qty, &inspection.UnitCost, &inspection.BaseFactor,
+		&inspection.AcceptedQty, &inspection.RejectedQty, &status, &inspection.CreatedAt)
+	inspection.SupplierID = supplierID.Int64
+	inspection.Status = InspectionStatus(status)
+	return inspection, err
+}
+
+// InspectionRequirements reports which of the products must be held for
+// inspection, either because the product is flagged or because the supplier
+// is.
+func (r *Repository) InspectionRequirements(ctx context.Context, supplierID int64, productIDs []int64) (map[int64]bool, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT p.id FROM products p
+		WHERE p.id = ANY($1)
+		  AND (p.requires_inspection
+		       OR EXISTS (SELECT 1 FROM suppliers s WHERE s.id = $2 AND s.requires_inspection))
+	`, productIDs, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	required := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		required[id] = true
+	}
+	return required, rows.Err()
+}
+
+// ListInspections returns inspections in the filter's status, oldest first.
+func (r *Repository) ListInspections(ctx context.Context, filter InspectionFilter) ([]GRNInspection, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+inspectionColumns+`
+`+inspectionJoins+`
+WHERE i.status = $1
+ORDER BY i.created_at, i.id
+LIMIT 500`, string(filter.Status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []GRNInspection
+	for rows.Next() {
+		inspection, err := scanInspection(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inspection)
+	}
+	return out, rows.Err()
+}
+
+// applyRejectedQty sets RejectedQty on the lines, converted back to each
+// line's unit.
+func (r *Repository) applyRejectedQty(ctx context.Context, grnID int64, lines []GRNLine) error {
+	rows, err := r.pool.Query(ctx, `
+		SELECT grn_line_id, (rejected_qty / base_factor)::float8
+		FROM grn_inspections
+		WHERE grn_id = $1 AND rejected_qty > 0
+	`, grnID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	rejected := make(map[int64]float64)
+	for rows.Next() {
+		var lineID int64
+		var qty float64
+		if err := rows.Scan(&lineID, &qty); err != nil {
+			return err
+		}
+		rejected[lineID] = qty
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range lines {
+		lines[i].RejectedQty = rejected[lines[i].ID]
+	}
+	return nil
+}
+
+// CreateInspection holds a GRN line in quality inspection.
+func (tx *txRepo) CreateInspection(ctx context.Context, inspection GRNInspection) (int64, error) {
+	var id int64
+	err := tx.tx.QueryRow(ctx, `
+		INSERT INTO grn_inspections (grn_id, grn_line_id, product_id, warehouse_id, qty, unit_cost, base_factor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, inspection.GRNID, inspection.GRNLineID, inspection.ProductID, inspection.WarehouseID,
+		inspection.Qty, inspection.UnitCost, inspection.BaseFactor).Scan(&id)
+	return id, err
+}
+
+// LockInspection loads the inspection and locks it against concurrent
+// decisions.
+func (tx *txRepo) LockInspection(ctx context.Context, id int64) (GRNInspection, error) {
+	inspection, err := scanInspection(tx.tx.QueryRow(ctx, `SELECT `+inspectionColumns+`
+`+inspectionJoins+`
+WHERE i.id = $1
+FOR UPDATE OF i`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return GRNInspection{}, ErrNotFound
+	}
+	return inspection, err
+}
+
+// RecordInspectionDecision stores the decision and the inspection's updated
+// quantities and status.
+func (tx *txRepo) RecordInspectionDecision(ctx context.Context, inspection GRNInspection, input DecideInspectionInput) (int64, error) {
+	var id int64
+	err := tx.tx.QueryRow(ctx, `
+		INSERT INTO grn_inspection_decisions (inspection_id, decision, qty, reason, return_reference, decided_by, decided_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6::BIGINT, 0), $7)
+		RETURNING id
+	`, inspection.ID, string(input.Decision), input.Qty, input.Reason, input.ReturnReference,
+		input.ActorID, input.DecidedAt).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.tx.Exec(ctx, `
+		UPDATE grn_inspections
+		SET accepted_qty = $2, rejected_qty = $3, status = $4,
+		    completed_at = CASE WHEN $4 = 'COMPLETED' THEN NOW() ELSE NULL END
+		WHERE id = $1
+	`, inspection.ID, inspection.AcceptedQty, inspection.RejectedQty, string(inspection.Status))
+	return id, err
+}
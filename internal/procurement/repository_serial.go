@@ -0,0 +1,67 @@
+package procurement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+)
+
+// SerialTrackedProducts reports which of the products require serial numbers.
+func (r *Repository) SerialTrackedProducts(ctx context.Context, productIDs []int64) (map[int64]bool, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id FROM products WHERE id = ANY($1) AND track_serial`, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tracked := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		tracked[id] = true
+	}
+	return tracked, rows.Err()
+}
+
+// ExistingSerials returns the serials already registered for the product.
+func (r *Repository) ExistingSerials(ctx context.Context, productID int64, serials []string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT serial_number FROM inventory_serials
+		WHERE product_id = $1 AND serial_number = ANY($2)
+		ORDER BY serial_number
+	`, productID, serials)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var serial string
+		if err := rows.Scan(&serial); err != nil {
+			return nil, err
+		}
+		out = append(out, serial)
+	}
+	return out, rows.Err()
+}
+
+// ReceiveSerials registers the line's serials as in stock at the GRN warehouse.
+func (tx *txRepo) ReceiveSerials(ctx context.Context, grn GoodsReceipt, line GRNLine) error {
+	receivedAt := pgtype.Timestamptz{Time: grn.ReceivedAt, Valid: !grn.ReceivedAt.IsZero()}
+	_, err := tx.tx.Exec(ctx, `
+		INSERT INTO inventory_serials (product_id, serial_number, status, warehouse_id, grn_id, received_at)
+		SELECT $1, serial, 'IN_STOCK', $3, $4, COALESCE($5, NOW())
+		FROM unnest($2::text[]) AS serial
+	`, line.ProductID, line.Serials, grn.WarehouseID, grn.ID, receivedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("%w: product %d", inventory.ErrDuplicateSerial, line.ProductID)
+	}
+	return err
+}
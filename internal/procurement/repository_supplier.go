@@ -0,0 +1,18 @@
+package procurement
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SupplierApprovalStatus returns the supplier's vetting status.
+func (r *Repository) SupplierApprovalStatus(ctx context.Context, supplierID int64) (string, error) {
+	var status string
+	err := r.pool.QueryRow(ctx, `SELECT approval_status FROM suppliers WHERE id = $1`, supplierID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return status, err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"time"
 
@@ -21,11 +22,21 @@ type RepositoryPort interface {
 	GetGRN(ctx context.Context, id int64) (GoodsReceipt, []GRNLine, error)
 	ListPOs(ctx context.Context, limit, offset int, filters ListFilters) ([]POListItem, int, error)
 	ListGRNs(ctx context.Context, limit, offset int, filters ListFilters) ([]GRNListItem, int, error)
+	SerialTrackedProducts(ctx context.Context, productIDs []int64) (map[int64]bool, error)
+	ExistingSerials(ctx context.Context, productID int64, serials []string) ([]string, error)
+	SupplierApprovalStatus(ctx context.Context, supplierID int64) (string, error)
+	InspectionRequirements(ctx context.Context, supplierID int64, productIDs []int64) (map[int64]bool, error)
+	ListInspections(ctx context.Context, filter InspectionFilter) ([]GRNInspection, error)
 }
 
 // InventoryPort exposes required inventory integration.
 type InventoryPort interface {
-	PostInbound(ctx context.Context, input inventory.InboundInput) (inventory.StockCardEntry, error)
+	PostInboundBatch(ctx context.Context, input inventory.InboundBatchInput) ([]inventory.StockCardEntry, error)
+}
+
+// UnitConverter resolves how many base units one unit of a product holds.
+type UnitConverter interface {
+	BaseFactor(ctx context.Context, productID int64, uom string) (float64, error)
 }
 
 // AuditPort reused from shared.
@@ -41,6 +52,12 @@ type Service struct {
 	audit       AuditPort
 	idempotency *shared.IdempotencyStore
 	integration IntegrationHandler
+	notifier    shared.ApprovalNotifier
+	currencies  shared.CurrencyValidator
+	units       UnitConverter
+	router      shared.ApprovalRouter
+	costPolicy  CostPolicy
+	planner     ReplenishmentPlanner
 }
 
 // NewService constructs procurement service.
@@ -48,12 +65,54 @@ func NewService(repo RepositoryPort, inventory InventoryPort, approvals *shared.
 	return &Service{repo: repo, inventory: inventory, approvals: approvals, audit: audit, idempotency: idem, integration: integration}
 }
 
+// SetApprovalNotifier injects the approval email notifier.
+func (s *Service) SetApprovalNotifier(notifier shared.ApprovalNotifier) {
+	s.notifier = notifier
+}
+
+// SetCurrencyValidator rejects new purchase documents in inactive currencies.
+func (s *Service) SetCurrencyValidator(v shared.CurrencyValidator) {
+	s.currencies = v
+}
+
+// SetApprovalRouter routes submitted purchase orders through the approval
+// matrix. Without it any user with the approve permission may approve.
+func (s *Service) SetApprovalRouter(router shared.ApprovalRouter) {
+	s.router = router
+}
+
+// SetUnitConverter lets goods receipts be entered in a product's alternate
+// units. Without it every quantity is taken as the base unit.
+func (s *Service) SetUnitConverter(units UnitConverter) {
+	s.units = units
+}
+
+// baseFactor returns the number of base units in one uom of the product.
+func (s *Service) baseFactor(ctx context.Context, productID int64, uom string) (float64, error) {
+	if s.units == nil {
+		return 1, nil
+	}
+	factor, err := s.units.BaseFactor(ctx, productID, uom)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	return factor, nil
+}
+
+func (s *Service) validateCurrency(ctx context.Context, code string) error {
+	if s.currencies == nil {
+		return nil
+	}
+	return s.currencies.ValidateCurrency(ctx, code)
+}
+
 // CreatePRInput describes creation payload.
 type CreatePRInput struct {
 	Number     string
 	SupplierID int64
 	RequestBy  int64
 	Note       string
+	Currency   string
 	Lines      []PRLineInput
 }
 
@@ -89,6 +148,8 @@ type GRNLineInput struct {
 	ProductID int64
 	Qty       float64
 	UnitCost  float64
+	UOM       string
+	Serials   []string
 }
 
 // CreatePurchaseRequest persists PR header and lines.
@@ -99,7 +160,10 @@ func (s *Service) CreatePurchaseRequest(ctx context.Context, input CreatePRInput
 	if input.Number == "" {
 		input.Number = generateNumber("PR")
 	}
-	pr := PurchaseRequest{Number: input.Number, SupplierID: input.SupplierID, RequestBy: input.RequestBy, Status: PRStatusDraft, Note: input.Note}
+	pr := PurchaseRequest{Number: input.Number, SupplierID: input.SupplierID, RequestBy: input.RequestBy, Status: PRStatusDraft, Note: input.Note, Currency: defaultString(input.Currency, "IDR")}
+	if err := s.validateCurrency(ctx, pr.Currency); err != nil {
+		return PurchaseRequest{}, err
+	}
 	var created PurchaseRequest
 	err := s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
 		prID, err := tx.CreatePR(ctx, pr)
@@ -151,10 +215,16 @@ func (s *Service) CreatePOFromPR(ctx context.Context, input CreatePOInput) (Purc
 	if pr.Status != PRStatusSubmitted {
 		return PurchaseOrder{}, ErrInvalidState
 	}
+	if err := s.ensureSupplierApproved(ctx, pr.SupplierID); err != nil {
+		return PurchaseOrder{}, err
+	}
 	if input.Number == "" {
 		input.Number = generateNumber("PO")
 	}
-	po := PurchaseOrder{Number: input.Number, SupplierID: pr.SupplierID, Status: POStatusDraft, Currency: defaultString(input.Currency, "IDR"), ExpectedDate: input.ExpectedDate, Note: input.Note}
+	po := PurchaseOrder{Number: input.Number, SupplierID: pr.SupplierID, Status: POStatusDraft, Currency: defaultString(input.Currency, defaultString(pr.Currency, "IDR")), ExpectedDate: input.ExpectedDate, Note: input.Note}
+	if err := s.validateCurrency(ctx, po.Currency); err != nil {
+		return PurchaseOrder{}, err
+	}
 	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
 		poID, err := tx.CreatePO(ctx, po)
 		if err != nil {
@@ -165,6 +235,9 @@ func (s *Service) CreatePOFromPR(ctx context.Context, input CreatePOInput) (Purc
 				return err
 			}
 		}
+		if err := tx.LinkPOSourcePR(ctx, poID, pr.ID); err != nil {
+			return err
+		}
 		if err := tx.UpdatePRStatus(ctx, pr.ID, PRStatusClosed); err != nil {
 			return err
 		}
@@ -181,15 +254,23 @@ func (s *Service) CreatePOFromPR(ctx context.Context, input CreatePOInput) (Purc
 
 // SubmitPurchaseOrder requests approval.
 func (s *Service) SubmitPurchaseOrder(ctx context.Context, poID int64, actorID int64) error {
-	po, _, err := s.repo.GetPO(ctx, poID)
+	po, lines, err := s.repo.GetPO(ctx, poID)
 	if err != nil {
 		return err
 	}
 	if po.Status != POStatusDraft {
 		return ErrInvalidState
 	}
+	if err := s.ensureSupplierApproved(ctx, po.SupplierID); err != nil {
+		return err
+	}
 	refID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("PO:%d", poID)))
-	return s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+	if s.router != nil {
+		if _, err := s.router.Route(ctx, "PO", refID, poTotal(lines), po.Currency); err != nil {
+			return err
+		}
+	}
+	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
 		if err := tx.UpdatePOStatus(ctx, poID, POStatusApproval); err != nil {
 			return err
 		}
@@ -198,11 +279,16 @@ func (s *Service) SubmitPurchaseOrder(ctx context.Context, poID int64, actorID i
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	s.notifyPO(ctx, shared.ApprovalSubmit, po, lines, actorID, actorID)
+	return nil
 }
 
 // ApprovePurchaseOrder marks PO as approved and logs approval.
 func (s *Service) ApprovePurchaseOrder(ctx context.Context, poID int64, actorID int64) error {
-	po, _, err := s.repo.GetPO(ctx, poID)
+	po, lines, err := s.repo.GetPO(ctx, poID)
 	if err != nil {
 		return err
 	}
@@ -211,7 +297,12 @@ func (s *Service) ApprovePurchaseOrder(ctx context.Context, poID int64, actorID
 	}
 	now := time.Now()
 	refID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("PO:%d", poID)))
-	return s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
+	if s.router != nil {
+		if err := s.router.Authorize(ctx, "PO", refID, actorID); err != nil {
+			return err
+		}
+	}
+	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
 		if err := tx.UpdatePOStatus(ctx, poID, POStatusApproved); err != nil {
 			return err
 		}
@@ -223,6 +314,56 @@ func (s *Service) ApprovePurchaseOrder(ctx context.Context, poID int64, actorID
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	s.notifyPO(ctx, shared.ApprovalApprove, po, lines, actorID, s.poSubmitter(ctx, refID))
+	return nil
+}
+
+// poSubmitter looks up who submitted the PO so the decision can be sent back.
+func (s *Service) poSubmitter(ctx context.Context, refID uuid.UUID) int64 {
+	if s.approvals == nil {
+		return 0
+	}
+	logs, err := s.approvals.List(ctx, "PO", refID)
+	if err != nil {
+		return 0
+	}
+	for _, l := range logs {
+		if l.Action == shared.ApprovalSubmit {
+			return l.ActorID
+		}
+	}
+	return 0
+}
+
+// notifyPO emails approvers/requester about a PO approval transition. Delivery
+// problems never fail the workflow.
+func (s *Service) notifyPO(ctx context.Context, action shared.ApprovalAction, po PurchaseOrder, lines []POLine, actorID, requesterID int64) {
+	if s.notifier == nil {
+		return
+	}
+	total := poTotal(lines)
+	_ = s.notifier.NotifyApproval(ctx, shared.ApprovalNotice{
+		Action:            action,
+		Module:            "PO",
+		DocumentLabel:     "Purchase Order",
+		DocumentNumber:    po.Number,
+		Path:              "/procurement/pos",
+		Summary:           fmt.Sprintf("Supplier #%d, %d line(s), total %s %.2f, expected %s", po.SupplierID, len(lines), po.Currency, total, po.ExpectedDate.Format("2006-01-02")),
+		ActorID:           actorID,
+		RequesterID:       requesterID,
+		ApprovePermission: "procurement.edit",
+	})
+}
+
+func poTotal(lines []POLine) float64 {
+	var total float64
+	for _, l := range lines {
+		total += l.Qty * l.Price
+	}
+	return total
 }
 
 // CreateGoodsReceipt inserts GRN and lines.
@@ -243,6 +384,16 @@ func (s *Service) CreateGoodsReceipt(ctx context.Context, input CreateGRNInput)
 	if len(input.Lines) == 0 {
 		return GoodsReceipt{}, ErrValidation
 	}
+	for i := range input.Lines {
+		line := &input.Lines[i]
+		line.UOM = strings.ToUpper(strings.TrimSpace(line.UOM))
+		if _, err := s.baseFactor(ctx, line.ProductID, line.UOM); err != nil {
+			return GoodsReceipt{}, err
+		}
+	}
+	if err := s.checkGRNSerials(ctx, input.Lines); err != nil {
+		return GoodsReceipt{}, err
+	}
 	grn := GoodsReceipt{Number: input.Number, POID: input.POID, SupplierID: input.SupplierID, WarehouseID: input.WarehouseID, Status: GRNStatusDraft, ReceivedAt: defaultTime(input.ReceivedAt), Note: input.Note}
 	err = s.repo.WithTx(ctx, func(ctx context.Context, tx TxRepository) error {
 		grnID, err := tx.CreateGRN(ctx, grn)
@@ -254,7 +405,7 @@ func (s *Service) CreateGoodsReceipt(ctx context.Context, input CreateGRNInput)
 			if line.ProductID == 0 || line.Qty <= 0 {
 				return ErrValidation
 			}
-			if err := tx.InsertGRNLine(ctx, GRNLine{GRNID: grnID, ProductID: line.ProductID, Qty: line.Qty, UnitCost: line.UnitCost}); err != nil {
+			if err := tx.InsertGRNLine(ctx, GRNLine{GRNID: grnID, ProductID: line.ProductID, Qty: line.Qty, UnitCost: line.UnitCost, UOM: line.UOM, Serials: line.Serials}); err != nil {
 				return err
 			}
 		}
@@ -269,6 +420,13 @@ func (s *Service) CreateGoodsReceipt(ctx context.Context, input CreateGRNInput)
 
 // PostGoodsReceipt posts GRN and updates inventory.
 func (s *Service) PostGoodsReceipt(ctx context.Context, grnID int64) error {
+	return s.PostGoodsReceiptWithOptions(ctx, grnID, PostGRNOptions{})
+}
+
+// PostGoodsReceiptWithOptions posts the GRN. Lines held for quality
+// inspection are booked into an inspection record instead of stock and
+// await DecideInspection.
+func (s *Service) PostGoodsReceiptWithOptions(ctx context.Context, grnID int64, opts PostGRNOptions) error {
 	grn, lines, err := s.repo.GetGRN(ctx, grnID)
 	if err != nil {
 		return err
@@ -276,6 +434,29 @@ func (s *Service) PostGoodsReceipt(ctx context.Context, grnID int64) error {
 	if grn.Status != GRNStatusDraft {
 		return ErrInvalidState
 	}
+	prices, err := s.poPrices(ctx, grn.POID)
+	if err != nil {
+		return err
+	}
+	// Stock is kept in base units: convert each line's quantity and cost.
+	baseLines := make([]GRNLine, len(lines))
+	factors := make([]float64, len(lines))
+	variances := make([]GRNLineVariance, len(lines))
+	for i, line := range lines {
+		factor, err := s.baseFactor(ctx, line.ProductID, line.UOM)
+		if err != nil {
+			return err
+		}
+		line.Qty *= factor
+		line.UnitCost /= factor
+		baseLines[i] = line
+		factors[i] = factor
+		variances[i] = s.lineVariance(line, prices)
+	}
+	held, err := s.heldForInspection(ctx, grn, lines, opts)
+	if err != nil {
+		return err
+	}
 	key := fmt.Sprintf("GRN:%s", grn.Number)
 	inserted := false
 	if s.idempotency != nil {
@@ -288,23 +469,44 @@ func (s *Service) PostGoodsReceipt(ctx context.Context, grnID int64) error {
 		if err := tx.UpdateGRNStatus(ctx, grnID, GRNStatusPosted); err != nil {
 			return err
 		}
+		if s.inventory == nil {
+			return errors.New("inventory integration not configured")
+		}
+		inbound := inventory.InboundBatchInput{
+			Code:        fmt.Sprintf("GRN-%s", grn.Number),
+			WarehouseID: grn.WarehouseID,
+			Lines:       make([]inventory.InboundLine, 0, len(lines)),
+			Note:        fmt.Sprintf("GRN %s", grn.Number),
+			RefModule:   "PROCUREMENT",
+			RefID:       uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("GRN:%d", grn.ID))).String(),
+		}
+		for i, line := range baseLines {
+			if held[line.ID] {
+				if _, err := tx.CreateInspection(ctx, GRNInspection{
+					GRNID:       grn.ID,
+					GRNLineID:   line.ID,
+					ProductID:   line.ProductID,
+					WarehouseID: grn.WarehouseID,
+					Qty:         line.Qty,
+					UnitCost:    variances[i].StockUnitCost,
+					BaseFactor:  factors[i],
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			inbound.Lines = append(inbound.Lines, inventory.InboundLine{ProductID: line.ProductID, Qty: line.Qty, UnitCost: variances[i].StockUnitCost})
+		}
+		if len(inbound.Lines) > 0 {
+			if _, err := s.inventory.PostInboundBatch(ctx, inbound); err != nil {
+				return err
+			}
+		}
 		for _, line := range lines {
-			if s.inventory == nil {
-				return errors.New("inventory integration not configured")
+			if len(line.Serials) == 0 {
+				continue
 			}
-			refID := uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("GRN:%d:%d", grn.ID, line.ProductID)))
-			_, err := s.inventory.PostInbound(ctx, inventory.InboundInput{
-				Code:        fmt.Sprintf("GRN-%s-%d", grn.Number, line.ProductID),
-				WarehouseID: grn.WarehouseID,
-				ProductID:   line.ProductID,
-				Qty:         line.Qty,
-				UnitCost:    line.UnitCost,
-				Note:        fmt.Sprintf("GRN %s", grn.Number),
-				ActorID:     0,
-				RefModule:   "PROCUREMENT",
-				RefID:       refID.String(),
-			})
-			if err != nil {
+			if err := tx.ReceiveSerials(ctx, grn, line); err != nil {
 				return err
 			}
 		}
@@ -316,7 +518,7 @@ func (s *Service) PostGoodsReceipt(ctx context.Context, grnID int64) error {
 		}
 		return err
 	}
-	s.recordAudit(ctx, "GRN_POST", grnID, map[string]any{"number": grn.Number})
+	s.recordAudit(ctx, "GRN_POST", grnID, map[string]any{"number": grn.Number, "held_for_inspection": len(held)})
 	if s.integration != nil {
 		evt := GRNPostedEvent{
 			ID:          grn.ID,
@@ -325,9 +527,13 @@ func (s *Service) PostGoodsReceipt(ctx context.Context, grnID int64) error {
 			WarehouseID: grn.WarehouseID,
 			ReceivedAt:  grn.ReceivedAt,
 		}
-		evt.Lines = make([]GRNLineEvent, 0, len(lines))
-		for _, line := range lines {
-			evt.Lines = append(evt.Lines, GRNLineEvent{ProductID: line.ProductID, Qty: line.Qty, UnitCost: line.UnitCost})
+		evt.Lines = make([]GRNLineEvent, 0, len(baseLines))
+		for i, line := range baseLines {
+			evtLine := GRNLineEvent{ProductID: line.ProductID, Qty: line.Qty, UnitCost: line.UnitCost, POUnitCost: variances[i].POUnitCost, Inspection: held[line.ID]}
+			if s.costPolicy == CostPolicyPOPrice {
+				evtLine.PriceVariance = variances[i].Variance
+			}
+			evt.Lines = append(evt.Lines, evtLine)
 		}
 		if err := s.integration.HandleGRNPosted(ctx, evt); err != nil {
 			return err
@@ -386,3 +592,51 @@ func defaultTime(value time.Time) time.Time {
 	}
 	return value
 }
+
+// checkGRNSerials requires one unused serial per base unit on serial-tracked
+// lines and rejects serials on other lines. Lines are normalised in place.
+func (s *Service) checkGRNSerials(ctx context.Context, lines []GRNLineInput) error {
+	productIDs := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	tracked, err := s.repo.SerialTrackedProducts(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+	seen := make(map[int64]map[string]struct{})
+	for i, line := range lines {
+		if !tracked[line.ProductID] {
+			if len(line.Serials) > 0 {
+				return fmt.Errorf("%w: product %d is not serial tracked", ErrValidation, line.ProductID)
+			}
+			continue
+		}
+		factor, err := s.baseFactor(ctx, line.ProductID, line.UOM)
+		if err != nil {
+			return err
+		}
+		serials, err := inventory.NormalizeSerials(line.Serials, line.Qty*factor)
+		if err != nil {
+			return fmt.Errorf("product %d: %w", line.ProductID, err)
+		}
+		if seen[line.ProductID] == nil {
+			seen[line.ProductID] = make(map[string]struct{})
+		}
+		for _, serial := range serials {
+			if _, dup := seen[line.ProductID][serial]; dup {
+				return fmt.Errorf("%w: %s on product %d", inventory.ErrDuplicateSerial, serial, line.ProductID)
+			}
+			seen[line.ProductID][serial] = struct{}{}
+		}
+		existing, err := s.repo.ExistingSerials(ctx, line.ProductID, serials)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return fmt.Errorf("%w: %s on product %d", inventory.ErrDuplicateSerial, strings.Join(existing, ", "), line.ProductID)
+		}
+		lines[i].Serials = serials
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package procurement
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -20,7 +21,18 @@ type memoryProcRepo struct {
 	grnLines map[int64][]GRNLine
 	invoices map[int64]APInvoice
 	payments map[int64][]APPayment
+	prSource map[int64]int64
 	nextID   int64
+
+	trackSerial map[int64]bool
+	serials     map[int64][]string
+	// supplierStatus overrides the approval status; suppliers not listed are
+	// approved.
+	supplierStatus map[int64]string
+
+	inspectProducts  map[int64]bool
+	inspectSuppliers map[int64]bool
+	inspections      map[int64]GRNInspection
 }
 
 type memoryProcTx struct {
@@ -37,6 +49,16 @@ func newMemoryProcRepo() *memoryProcRepo {
 		grnLines: make(map[int64][]GRNLine),
 		invoices: make(map[int64]APInvoice),
 		payments: make(map[int64][]APPayment),
+		prSource: make(map[int64]int64),
+
+		trackSerial: make(map[int64]bool),
+		serials:     make(map[int64][]string),
+
+		supplierStatus: make(map[int64]string),
+
+		inspectProducts:  make(map[int64]bool),
+		inspectSuppliers: make(map[int64]bool),
+		inspections:      make(map[int64]GRNInspection),
 	}
 }
 
@@ -77,6 +99,55 @@ func (r *memoryProcRepo) ListGRNs(ctx context.Context, limit, offset int, filter
 	return nil, 0, nil
 }
 
+func (r *memoryProcRepo) SerialTrackedProducts(ctx context.Context, productIDs []int64) (map[int64]bool, error) {
+	tracked := make(map[int64]bool)
+	for _, id := range productIDs {
+		if r.trackSerial[id] {
+			tracked[id] = true
+		}
+	}
+	return tracked, nil
+}
+
+func (r *memoryProcRepo) SupplierApprovalStatus(ctx context.Context, supplierID int64) (string, error) {
+	if status, ok := r.supplierStatus[supplierID]; ok {
+		return status, nil
+	}
+	return supplierApproved, nil
+}
+
+func (r *memoryProcRepo) ExistingSerials(ctx context.Context, productID int64, serials []string) ([]string, error) {
+	var out []string
+	for _, existing := range r.serials[productID] {
+		for _, serial := range serials {
+			if existing == serial {
+				out = append(out, serial)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryProcRepo) InspectionRequirements(ctx context.Context, supplierID int64, productIDs []int64) (map[int64]bool, error) {
+	required := make(map[int64]bool)
+	for _, id := range productIDs {
+		if r.inspectProducts[id] || r.inspectSuppliers[supplierID] {
+			required[id] = true
+		}
+	}
+	return required, nil
+}
+
+func (r *memoryProcRepo) ListInspections(ctx context.Context, filter InspectionFilter) ([]GRNInspection, error) {
+	var out []GRNInspection
+	for _, inspection := range r.inspections {
+		if inspection.Status == filter.Status {
+			out = append(out, inspection)
+		}
+	}
+	return out, nil
+}
+
 func (r *memoryProcRepo) GetAPInvoice(ctx context.Context, id int64) (APInvoice, error) {
 	inv, ok := r.invoices[id]
 	if !ok {
@@ -118,6 +189,14 @@ func (tx *memoryProcTx) UpdatePRStatus(ctx context.Context, id int64, status PRS
 	return nil
 }
 
+func (tx *memoryProcTx) LinkPOSourcePR(ctx context.Context, poID, prID int64) error {
+	if _, ok := tx.repo.prSource[prID]; ok {
+		return errors.New("pr already linked")
+	}
+	tx.repo.prSource[prID] = poID
+	return nil
+}
+
 func (tx *memoryProcTx) CreatePO(ctx context.Context, po PurchaseOrder) (int64, error) {
 	id := tx.nextID()
 	po.ID = id
@@ -165,6 +244,33 @@ func (tx *memoryProcTx) UpdateGRNStatus(ctx context.Context, id int64, status GR
 	return nil
 }
 
+func (tx *memoryProcTx) ReceiveSerials(ctx context.Context, grn GoodsReceipt, line GRNLine) error {
+	tx.repo.serials[line.ProductID] = append(tx.repo.serials[line.ProductID], line.Serials...)
+	return nil
+}
+
+func (tx *memoryProcTx) CreateInspection(ctx context.Context, inspection GRNInspection) (int64, error) {
+	inspection.ID = tx.nextID()
+	inspection.GRNNumber = tx.repo.grns[inspection.GRNID].Number
+	inspection.SupplierID = tx.repo.grns[inspection.GRNID].SupplierID
+	inspection.Status = InspectionPending
+	tx.repo.inspections[inspection.ID] = inspection
+	return inspection.ID, nil
+}
+
+func (tx *memoryProcTx) LockInspection(ctx context.Context, id int64) (GRNInspection, error) {
+	inspection, ok := tx.repo.inspections[id]
+	if !ok {
+		return GRNInspection{}, ErrNotFound
+	}
+	return inspection, nil
+}
+
+func (tx *memoryProcTx) RecordInspectionDecision(ctx context.Context, inspection GRNInspection, input DecideInspectionInput) (int64, error) {
+	tx.repo.inspections[inspection.ID] = inspection
+	return tx.nextID(), nil
+}
+
 func (tx *memoryProcTx) CreateAPInvoice(ctx context.Context, inv APInvoice) (int64, error) {
 	id := tx.nextID()
 	inv.ID = id
@@ -187,12 +293,16 @@ func (tx *memoryProcTx) CreatePayment(ctx context.Context, payment APPayment) (i
 }
 
 type stubInventory struct {
-	records []inventory.InboundInput
+	records []inventory.InboundBatchInput
 }
 
-func (s *stubInventory) PostInbound(ctx context.Context, input inventory.InboundInput) (inventory.StockCardEntry, error) {
+func (s *stubInventory) PostInboundBatch(ctx context.Context, input inventory.InboundBatchInput) ([]inventory.StockCardEntry, error) {
 	s.records = append(s.records, input)
-	return inventory.StockCardEntry{TxCode: input.Code, QtyIn: input.Qty}, nil
+	cards := make([]inventory.StockCardEntry, len(input.Lines))
+	for i, line := range input.Lines {
+		cards[i] = inventory.StockCardEntry{TxCode: input.Code, QtyIn: line.Qty}
+	}
+	return cards, nil
 }
 
 func TestProcurementFlow(t *testing.T) {
@@ -229,5 +339,164 @@ func TestProcurementFlow(t *testing.T) {
 
 	require.NoError(t, svc.PostGoodsReceipt(ctx, grn.ID))
 	require.Len(t, inv.records, 1)
-	require.Equal(t, 5.0, inv.records[0].Qty)
+	require.Len(t, inv.records[0].Lines, 1)
+	require.Equal(t, 5.0, inv.records[0].Lines[0].Qty)
+}
+
+func TestGoodsReceiptSerials(t *testing.T) {
+	repo := newMemoryProcRepo()
+	repo.trackSerial[11] = true
+	svc := NewService(repo, &stubInventory{}, nil, nil, nil, nil)
+	ctx := context.Background()
+	repo.pos[1] = PurchaseOrder{ID: 1, SupplierID: 1, Status: POStatusApproved}
+	repo.nextID = 1
+
+	receive := func(lines ...GRNLineInput) (GoodsReceipt, error) {
+		return svc.CreateGoodsReceipt(ctx, CreateGRNInput{POID: 1, WarehouseID: 2, Lines: lines})
+	}
+
+	_, err := receive(GRNLineInput{ProductID: 11, Qty: 2, UnitCost: 100, Serials: []string{"SN-1"}})
+	require.ErrorIs(t, err, inventory.ErrSerialCount)
+
+	_, err = receive(GRNLineInput{ProductID: 12, Qty: 1, UnitCost: 100, Serials: []string{"SN-1"}})
+	require.ErrorIs(t, err, ErrValidation)
+
+	_, err = receive(
+		GRNLineInput{ProductID: 11, Qty: 1, UnitCost: 100, Serials: []string{"SN-1"}},
+		GRNLineInput{ProductID: 11, Qty: 1, UnitCost: 100, Serials: []string{"SN-1"}},
+	)
+	require.ErrorIs(t, err, inventory.ErrDuplicateSerial)
+
+	grn, err := receive(GRNLineInput{ProductID: 11, Qty: 2, UnitCost: 100, Serials: []string{" SN-1", "SN-2 "}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"SN-1", "SN-2"}, repo.grnLines[grn.ID][0].Serials)
+	require.NoError(t, svc.PostGoodsReceipt(ctx, grn.ID))
+	require.Equal(t, []string{"SN-1", "SN-2"}, repo.serials[11])
+
+	_, err = receive(GRNLineInput{ProductID: 11, Qty: 1, UnitCost: 100, Serials: []string{"SN-2"}})
+	require.ErrorIs(t, err, inventory.ErrDuplicateSerial)
+}
+
+type stubUnits map[string]float64
+
+func (s stubUnits) BaseFactor(_ context.Context, productID int64, uom string) (float64, error) {
+	if uom == "" {
+		return 1, nil
+	}
+	factor, ok := s[uom]
+	if !ok {
+		return 0, errors.New("unknown unit " + uom)
+	}
+	return factor, nil
+}
+
+func TestGoodsReceiptInAlternateUnitPostsBaseQuantity(t *testing.T) {
+	repo := newMemoryProcRepo()
+	inv := &stubInventory{}
+	svc := NewService(repo, inv, nil, nil, nil, nil)
+	svc.SetUnitConverter(stubUnits{"BOX": 12})
+	ctx := context.Background()
+	repo.pos[1] = PurchaseOrder{ID: 1, SupplierID: 1, Status: POStatusApproved}
+	repo.nextID = 1
+
+	_, err := svc.CreateGoodsReceipt(ctx, CreateGRNInput{POID: 1, WarehouseID: 2, Lines: []GRNLineInput{{ProductID: 11, Qty: 1, UnitCost: 100, UOM: "CTN"}}})
+	require.ErrorIs(t, err, ErrValidation)
+
+	grn, err := svc.CreateGoodsReceipt(ctx, CreateGRNInput{POID: 1, WarehouseID: 2, Lines: []GRNLineInput{{ProductID: 11, Qty: 3, UnitCost: 60000, UOM: " box"}}})
+	require.NoError(t, err)
+	line := repo.grnLines[grn.ID][0]
+	require.Equal(t, "BOX", line.UOM)
+	require.Equal(t, 3.0, line.Qty, "the receipt keeps the quantity as entered")
+
+	require.NoError(t, svc.PostGoodsReceipt(ctx, grn.ID))
+	require.Len(t, inv.records, 1)
+	require.Equal(t, 36.0, inv.records[0].Lines[0].Qty)
+	require.Equal(t, 5000.0, inv.records[0].Lines[0].UnitCost)
+}
+
+func submittedPR(t *testing.T, svc *Service, supplierID int64, currency string, lines ...PRLineInput) PurchaseRequest {
+	t.Helper()
+	ctx := context.Background()
+	pr, err := svc.CreatePurchaseRequest(ctx, CreatePRInput{SupplierID: supplierID, RequestBy: 99, Currency: currency, Lines: lines})
+	require.NoError(t, err)
+	require.NoError(t, svc.SubmitPurchaseRequest(ctx, pr.ID, 99))
+	return pr
+}
+
+func TestConsolidatePurchaseRequests(t *testing.T) {
+	repo := newMemoryProcRepo()
+	svc := NewService(repo, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	pr1 := submittedPR(t, svc, 1, "USD", PRLineInput{ProductID: 11, Qty: 5}, PRLineInput{ProductID: 12, Qty: 1})
+	pr2 := submittedPR(t, svc, 1, "USD", PRLineInput{ProductID: 11, Qty: 3})
+	pr3 := submittedPR(t, svc, 1, "USD", PRLineInput{ProductID: 13, Qty: 2})
+
+	po, err := svc.ConsolidatePurchaseRequests(ctx, ConsolidatePOInput{PRIDs: []int64{pr1.ID, pr2.ID, pr3.ID, pr1.ID}})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), po.SupplierID)
+	require.Equal(t, "USD", po.Currency)
+
+	_, lines, err := repo.GetPO(ctx, po.ID)
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	require.Equal(t, int64(11), lines[0].ProductID)
+	require.Equal(t, 8.0, lines[0].Qty)
+	require.Equal(t, 1.0, lines[1].Qty)
+	require.Equal(t, 2.0, lines[2].Qty)
+
+	for _, id := range []int64{pr1.ID, pr2.ID, pr3.ID} {
+		require.Equal(t, PRStatusClosed, repo.prs[id].Status)
+		require.Equal(t, po.ID, repo.prSource[id])
+	}
+
+	_, err = svc.ConsolidatePurchaseRequests(ctx, ConsolidatePOInput{PRIDs: []int64{pr1.ID, pr2.ID}})
+	require.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestConsolidatePurchaseRequestsValidatesSupplierAndCurrency(t *testing.T) {
+	repo := newMemoryProcRepo()
+	svc := NewService(repo, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	base := submittedPR(t, svc, 1, "IDR", PRLineInput{ProductID: 11, Qty: 5})
+	otherSupplier := submittedPR(t, svc, 2, "IDR", PRLineInput{ProductID: 11, Qty: 1})
+	otherCurrency := submittedPR(t, svc, 1, "USD", PRLineInput{ProductID: 11, Qty: 1})
+
+	_, err := svc.ConsolidatePurchaseRequests(ctx, ConsolidatePOInput{PRIDs: []int64{base.ID, otherSupplier.ID}})
+	require.ErrorIs(t, err, ErrSupplierMismatch)
+
+	_, err = svc.ConsolidatePurchaseRequests(ctx, ConsolidatePOInput{PRIDs: []int64{base.ID, otherCurrency.ID}})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = svc.ConsolidatePurchaseRequests(ctx, ConsolidatePOInput{PRIDs: []int64{base.ID}})
+	require.ErrorIs(t, err, ErrValidation)
+
+	require.Equal(t, PRStatusSubmitted, repo.prs[base.ID].Status)
+	require.Empty(t, repo.pos)
+}
+
+func TestPurchaseOrdersRequireApprovedSupplier(t *testing.T) {
+	repo := newMemoryProcRepo()
+	svc := NewService(repo, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	repo.supplierStatus[1] = "PENDING"
+	pr := submittedPR(t, svc, 1, "IDR", PRLineInput{ProductID: 11, Qty: 5})
+	other := submittedPR(t, svc, 1, "IDR", PRLineInput{ProductID: 12, Qty: 1})
+	_, err := svc.CreatePOFromPR(ctx, CreatePOInput{PRID: pr.ID})
+	require.ErrorIs(t, err, ErrSupplierNotApproved)
+	_, err = svc.ConsolidatePurchaseRequests(ctx, ConsolidatePOInput{PRIDs: []int64{pr.ID, other.ID}})
+	require.ErrorIs(t, err, ErrSupplierNotApproved)
+	require.Equal(t, PRStatusSubmitted, repo.prs[pr.ID].Status)
+	require.Empty(t, repo.pos)
+
+	repo.supplierStatus[1] = "APPROVED"
+	po, err := svc.CreatePOFromPR(ctx, CreatePOInput{PRID: pr.ID})
+	require.NoError(t, err)
+
+	repo.supplierStatus[1] = "BLOCKED"
+	err = svc.SubmitPurchaseOrder(ctx, po.ID, 1)
+	require.ErrorIs(t, err, ErrSupplierNotApproved)
+	require.Equal(t, POStatusDraft, repo.pos[po.ID].Status)
 }
@@ -0,0 +1,26 @@
+package procurement
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// supplierApproved is the suppliers.approval_status that allows purchasing.
+const supplierApproved = "APPROVED"
+
+// ensureSupplierApproved rejects purchase orders for suppliers that are
+// pending vetting or blocked. Documents without a supplier are not checked.
+func (s *Service) ensureSupplierApproved(ctx context.Context, supplierID int64) error {
+	if supplierID == 0 {
+		return nil
+	}
+	status, err := s.repo.SupplierApprovalStatus(ctx, supplierID)
+	if err != nil {
+		return err
+	}
+	if status != supplierApproved {
+		return fmt.Errorf("%w (supplier #%d is %s)", ErrSupplierNotApproved, supplierID, strings.ToLower(status))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// AuditPort records access-control changes.
+type AuditPort interface {
+	Record(ctx context.Context, log shared.AuditLog) error
+}
+
+// SetAuditor enables audit entries for role, permission and user-role changes.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
+}
+
+// roleSnapshot is the before/after shape stored for role changes.
+type roleSnapshot struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// record writes an audit entry. Audit failures never fail the change itself.
+func (s *Service) record(ctx context.Context, action, entity string, entityID int64, meta map[string]any, before, after any) {
+	if s.audit == nil {
+		return
+	}
+	_ = s.audit.Record(ctx, shared.AuditLog{
+		ActorID:  shared.AuditActorFromContext(ctx),
+		Action:   action,
+		Entity:   entity,
+		EntityID: strconv.FormatInt(entityID, 10),
+		Meta:     meta,
+		Before:   before,
+		After:    after,
+	})
+}
+
+// RolePermissionNames returns the sorted permission names attached to a role.
+func (s *Service) RolePermissionNames(ctx context.Context, roleID int64) ([]string, error) {
+	rows, err := s.queries.ListRolePermissions(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// diffNames returns the names only in after and the names only in before.
+func diffNames(before, after []string) (added, removed []string) {
+	in := make(map[string]bool, len(before))
+	for _, name := range before {
+		in[name] = true
+	}
+	out := make(map[string]bool, len(after))
+	for _, name := range after {
+		out[name] = true
+		if !in[name] {
+			added = append(added, name)
+		}
+	}
+	for _, name := range before {
+		if !out[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func int64Strings(ids []int64) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, strconv.FormatInt(id, 10))
+	}
+	return out
+}
@@ -0,0 +1,23 @@
+package rbac
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffNames(t *testing.T) {
+	added, removed := diffNames(
+		[]string{"finance.gl.view", "inventory.view", "sales.order.view"},
+		[]string{"inventory.edit", "inventory.view", "finance.gl.view"},
+	)
+	if !reflect.DeepEqual(added, []string{"inventory.edit"}) {
+		t.Fatalf("unexpected added: %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"sales.order.view"}) {
+		t.Fatalf("unexpected removed: %v", removed)
+	}
+	added, removed = diffNames([]string{"a"}, []string{"a"})
+	if added != nil || removed != nil {
+		t.Fatalf("expected no changes, got %v %v", added, removed)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"log/slog"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
@@ -18,66 +19,159 @@ type Middleware struct {
 
 // RequireAny ensures the current user has at least one of the required permissions.
 func (m Middleware) RequireAny(perms ...string) func(http.Handler) http.Handler {
+	req := RouteRequirement{Permissions: normalizePermissions(perms)}
+	return func(next http.Handler) http.Handler {
+		return &guard{m: m, req: req, next: next}
+	}
+}
+
+// RequireAll ensures the current user has all required permissions.
+func (m Middleware) RequireAll(perms ...string) func(http.Handler) http.Handler {
+	req := RouteRequirement{All: true, Permissions: normalizePermissions(perms)}
+	return func(next http.Handler) http.Handler {
+		return &guard{m: m, req: req, next: next}
+	}
+}
+
+// guard is the handler built by RequireAny and RequireAll. It keeps its
+// requirement inspectable so RoutePermissions can map the router.
+type guard struct {
+	m    Middleware
+	req  RouteRequirement
+	next http.Handler
+}
+
+func (g *guard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(g.req.Permissions) == 0 {
+		g.next.ServeHTTP(w, r)
+		return
+	}
+	userID, ok := g.m.currentUserID(r)
+	if !ok {
+		httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	granted, err := g.m.Service.EffectivePermissions(r.Context(), userID)
+	if err != nil {
+		if g.m.Logger != nil {
+			msg := "rbac require any"
+			if g.req.All {
+				msg = "rbac require all"
+			}
+			g.m.Logger.ErrorContext(r.Context(), msg, slog.Any("error", err))
+		}
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if g.req.Satisfied(granted) {
+		g.next.ServeHTTP(w, r)
+		return
+	}
+	httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+// Allows reports whether the current user holds at least one of perms. It is
+// meant for handlers that shape a response by permission rather than reject
+// the request; lookup failures are logged and treated as not allowed.
+func (m Middleware) Allows(r *http.Request, perms ...string) bool {
 	normalized := normalizePermissions(perms)
+	if len(normalized) == 0 {
+		return true
+	}
+	userID, ok := m.currentUserID(r)
+	if !ok || m.Service == nil {
+		return false
+	}
+	granted, err := m.Service.EffectivePermissions(r.Context(), userID)
+	if err != nil {
+		if m.Logger != nil {
+			m.Logger.ErrorContext(r.Context(), "rbac allows", slog.Any("error", err))
+		}
+		return false
+	}
+	return hasAnyPermission(granted, normalized)
+}
+
+// CompanyScope resolves the companies the current user is assigned to, stores
+// the scope on the request context for list filtering and rejects requests
+// that target a company outside it. Holders of shared.PermSuperUser bypass
+// scoping. Anonymous requests pass through untouched.
+func (m Middleware) CompanyScope() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if len(normalized) == 0 {
-				next.ServeHTTP(w, r)
-				return
-			}
 			userID, ok := m.currentUserID(r)
-			if !ok {
-				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			if !ok || m.Service == nil {
+				next.ServeHTTP(w, r)
 				return
 			}
-			granted, err := m.Service.EffectivePermissions(r.Context(), userID)
+			scope, err := m.userCompanyScope(r, userID)
 			if err != nil {
 				if m.Logger != nil {
-					m.Logger.Error("rbac require any", slog.Any("error", err))
+					m.Logger.ErrorContext(r.Context(), "rbac company scope", slog.Any("error", err))
 				}
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
-			if hasAnyPermission(granted, normalized) {
-				next.ServeHTTP(w, r)
+			if companyID := requestedCompanyID(r); companyID > 0 && !scope.Allows(companyID) {
+				httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 				return
 			}
-			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			next.ServeHTTP(w, r.WithContext(shared.ContextWithCompanyScope(r.Context(), scope)))
 		})
 	}
 }
 
-// RequireAll ensures the current user has all required permissions.
-func (m Middleware) RequireAll(perms ...string) func(http.Handler) http.Handler {
-	normalized := normalizePermissions(perms)
+// RequireCompany rejects requests from users whose company scope holds no
+// company at all. It guards routes that always act on one company, which
+// would otherwise have nothing to fall back to. Apply it after CompanyScope.
+func (m Middleware) RequireCompany() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if len(normalized) == 0 {
-				next.ServeHTTP(w, r)
+			if _, err := shared.RequireCompanyID(r.Context(), 0, 0); err != nil {
+				httpx.Error(w, r, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 				return
 			}
-			userID, ok := m.currentUserID(r)
-			if !ok {
-				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-				return
-			}
-			granted, err := m.Service.EffectivePermissions(r.Context(), userID)
-			if err != nil {
-				if m.Logger != nil {
-					m.Logger.Error("rbac require all", slog.Any("error", err))
-				}
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-				return
-			}
-			if hasAllPermissions(granted, normalized) {
-				next.ServeHTTP(w, r)
-				return
-			}
-			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+func (m Middleware) userCompanyScope(r *http.Request, userID int64) (shared.CompanyScope, error) {
+	granted, err := m.Service.EffectivePermissions(r.Context(), userID)
+	if err != nil {
+		return shared.CompanyScope{}, err
+	}
+	if hasAnyPermission(granted, []string{shared.PermSuperUser}) {
+		return shared.CompanyScope{Unrestricted: true}, nil
+	}
+	ids, err := m.Service.UserCompanyIDs(r.Context(), userID)
+	if err != nil {
+		return shared.CompanyScope{}, err
+	}
+	return shared.CompanyScope{CompanyIDs: ids}, nil
+}
+
+// requestedCompanyID returns the company a request targets via query string,
+// url-encoded form or the session's active company.
+func requestedCompanyID(r *http.Request) int64 {
+	raw := strings.TrimSpace(r.URL.Query().Get("company_id"))
+	if raw == "" && r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if err := r.ParseForm(); err == nil {
+			raw = strings.TrimSpace(r.PostForm.Get("company_id"))
+		}
+	}
+	if raw == "" {
+		if sess := shared.SessionFromContext(r.Context()); sess != nil {
+			raw = strings.TrimSpace(sess.Get("company_id"))
+		}
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
 func (m Middleware) currentUserID(r *http.Request) (int64, bool) {
 	sess := shared.SessionFromContext(r.Context())
 	if sess == nil {
@@ -90,7 +184,7 @@ func (m Middleware) currentUserID(r *http.Request) (int64, bool) {
 	id, err := strconv.ParseInt(raw, 10, 64)
 	if err != nil {
 		if m.Logger != nil {
-			m.Logger.Error("rbac parse user id", slog.String("value", raw))
+			m.Logger.ErrorContext(r.Context(), "rbac parse user id", slog.String("value", raw))
 		}
 		return 0, false
 	}
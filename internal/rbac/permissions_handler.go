@@ -1,8 +1,14 @@
 package rbac
 
 import (
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/go-chi/chi/v5"
 
@@ -18,6 +24,7 @@ type PermissionsHandler struct {
 	csrf      *shared.CSRFManager
 	sessions  *shared.SessionManager
 	rbac      Middleware
+	routes    chi.Routes
 }
 
 // NewPermissionsHandler builds PermissionsHandler instance.
@@ -29,21 +36,102 @@ func (h *PermissionsHandler) MountRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAny(shared.PermPermissionsView))
 		r.Get("/", h.listPermissions)
+		r.Get("/simulate", h.simulateAccess)
 	})
 }
 
+// SetRoutes gives the access simulator the application router to audit.
+func (h *PermissionsHandler) SetRoutes(routes chi.Routes) {
+	h.routes = routes
+}
+
 type formErrors map[string]string
 
 func (h *PermissionsHandler) listPermissions(w http.ResponseWriter, r *http.Request) {
 	perms, err := h.service.ListPermissions(r.Context())
 	if err != nil {
-		h.logger.Error("list permissions failed", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "list permissions failed", slog.Any("error", err))
 		h.render(w, r, "pages/permissions/list.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusInternalServerError)
 		return
 	}
 	h.render(w, r, "pages/permissions/list.html", map[string]any{"Permissions": perms}, http.StatusOK)
 }
 
+// simulateAccess lists the routes a role, an ad-hoc permission list or both
+// together would be granted.
+func (h *PermissionsHandler) simulateAccess(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	data := map[string]any{"RoleID": query.Get("role_id"), "Extra": query.Get("permissions")}
+	roles, err := h.service.ListRoles(ctx)
+	if err != nil {
+		h.simulateFailed(w, r, data, err)
+		return
+	}
+	data["Roles"] = roles
+
+	granted := strings.FieldsFunc(query.Get("permissions"), func(c rune) bool {
+		return c == ',' || unicode.IsSpace(c)
+	})
+	if raw := strings.TrimSpace(query.Get("role_id")); raw != "" {
+		roleID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			h.renderSimulation(w, r, data, formErrors{"role_id": "Invalid role"}, http.StatusBadRequest)
+			return
+		}
+		role, err := h.service.GetRole(ctx, roleID)
+		if err != nil {
+			h.simulateFailed(w, r, data, err)
+			return
+		}
+		names, err := h.service.RolePermissionNames(ctx, roleID)
+		if err != nil {
+			h.simulateFailed(w, r, data, err)
+			return
+		}
+		data["Role"] = role
+		granted = append(granted, names...)
+	}
+	if h.routes == nil {
+		h.simulateFailed(w, r, data, errors.New("rbac: router not registered"))
+		return
+	}
+	routes, err := RoutePermissions(h.routes)
+	if err != nil {
+		h.simulateFailed(w, r, data, err)
+		return
+	}
+	granted = normalizePermissions(granted)
+	sort.Strings(granted)
+	data["Granted"] = granted
+	data["Modules"] = SimulateAccess(routes, granted)
+	h.renderSimulation(w, r, data, nil, http.StatusOK)
+}
+
+func (h *PermissionsHandler) simulateFailed(w http.ResponseWriter, r *http.Request, data map[string]any, err error) {
+	if errors.Is(err, ErrNotFound) {
+		h.renderSimulation(w, r, data, formErrors{"role_id": "Role not found"}, http.StatusNotFound)
+		return
+	}
+	h.logger.ErrorContext(r.Context(), "simulate access failed", slog.Any("error", err))
+	h.renderSimulation(w, r, data, formErrors{"general": shared.UserSafeMessage(err)}, http.StatusInternalServerError)
+}
+
+func (h *PermissionsHandler) renderSimulation(w http.ResponseWriter, r *http.Request, data map[string]any, errs formErrors, status int) {
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		payload := map[string]any{"granted": data["Granted"], "modules": data["Modules"]}
+		if len(errs) > 0 {
+			payload = map[string]any{"errors": errs}
+		}
+		_ = json.NewEncoder(w).Encode(payload)
+		return
+	}
+	data["Errors"] = errs
+	h.render(w, r, "pages/permissions/simulate.html", data, status)
+}
+
 func (h *PermissionsHandler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
 	sess := shared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -54,6 +142,6 @@ func (h *PermissionsHandler) render(w http.ResponseWriter, r *http.Request, temp
 	viewData := view.TemplateData{Title: "Permissions", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
 	}
 }
@@ -0,0 +1,124 @@
+package rbac
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RouteRequirement is one RequireAny or RequireAll check guarding a route.
+type RouteRequirement struct {
+	// All is set for RequireAll; otherwise any one permission suffices.
+	All         bool     `json:"all"`
+	Permissions []string `json:"permissions"`
+}
+
+// Satisfied reports whether granted passes the check.
+func (q RouteRequirement) Satisfied(granted []string) bool {
+	if q.All {
+		return hasAllPermissions(granted, q.Permissions)
+	}
+	return hasAnyPermission(granted, q.Permissions)
+}
+
+// RoutePermission lists the permission checks on one route. Routes without
+// requirements are still subject to login.
+type RoutePermission struct {
+	Method       string             `json:"method"`
+	Pattern      string             `json:"pattern"`
+	Module       string             `json:"module"`
+	Requirements []RouteRequirement `json:"requirements"`
+}
+
+// RouteAccess is the outcome of one route for a simulated permission set.
+// Missing holds the permissions of the checks that failed.
+type RouteAccess struct {
+	RoutePermission
+	Granted bool     `json:"granted"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// ModuleAccess groups simulated route access by the route's first path
+// segment.
+type ModuleAccess struct {
+	Module  string        `json:"module"`
+	Granted int           `json:"granted"`
+	Routes  []RouteAccess `json:"routes"`
+}
+
+// RoutePermissions walks the router and returns the RBAC checks on every
+// route, ordered by pattern and method. A middleware is recognised as an RBAC
+// check by wrapping a no-op handler with it, so only checks built by
+// RequireAny and RequireAll are reported.
+func RoutePermissions(routes chi.Routes) ([]RoutePermission, error) {
+	var out []RoutePermission
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		entry := RoutePermission{Method: method, Pattern: route, Module: routeModule(route)}
+		for _, mw := range middlewares {
+			if g, ok := mw(noop).(*guard); ok && len(g.req.Permissions) > 0 {
+				entry.Requirements = append(entry.Requirements, g.req)
+			}
+		}
+		for g, ok := handler.(*guard); ok; g, ok = g.next.(*guard) {
+			if len(g.req.Permissions) > 0 {
+				entry.Requirements = append(entry.Requirements, g.req)
+			}
+		}
+		out = append(out, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Pattern != out[j].Pattern {
+			return out[i].Pattern < out[j].Pattern
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out, nil
+}
+
+// SimulateAccess evaluates every route against the granted permissions the
+// same way the middleware would and groups the outcome by module.
+func SimulateAccess(routes []RoutePermission, granted []string) []ModuleAccess {
+	granted = normalizePermissions(granted)
+	var modules []ModuleAccess
+	index := make(map[string]int)
+	for _, route := range routes {
+		access := RouteAccess{RoutePermission: route, Granted: true}
+		for _, req := range route.Requirements {
+			if !req.Satisfied(granted) {
+				access.Granted = false
+				access.Missing = append(access.Missing, req.Permissions...)
+			}
+		}
+		if !access.Granted {
+			access.Missing = normalizePermissions(access.Missing)
+			sort.Strings(access.Missing)
+		}
+		i, ok := index[route.Module]
+		if !ok {
+			i = len(modules)
+			index[route.Module] = i
+			modules = append(modules, ModuleAccess{Module: route.Module})
+		}
+		if access.Granted {
+			modules[i].Granted++
+		}
+		modules[i].Routes = append(modules[i].Routes, access)
+	}
+	sort.SliceStable(modules, func(i, j int) bool { return modules[i].Module < modules[j].Module })
+	return modules
+}
+
+func routeModule(pattern string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(pattern, "/"), "/")
+	if segment == "" || strings.ContainsAny(segment, "{*") {
+		return "/"
+	}
+	return segment
+}
@@ -0,0 +1,84 @@
+package rbac
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testRouter() chi.Router {
+	var m Middleware
+	ok := func(http.ResponseWriter, *http.Request) {}
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler { return next })
+	r.Get("/", ok)
+	r.Route("/inventory", func(r chi.Router) {
+		r.Use(m.RequireAny("inventory.view", "inventory.edit"))
+		r.Get("/", ok)
+		r.With(m.RequireAll("inventory.edit", "finance.gl.post")).Post("/adjustments", ok)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(m.RequireAny("Sales.Order.View"))
+		r.Get("/sales/orders", ok)
+	})
+	r.Method(http.MethodGet, "/reports", m.RequireAny("report.view")(http.HandlerFunc(ok)))
+	return r
+}
+
+func TestRoutePermissions(t *testing.T) {
+	routes, err := RoutePermissions(testRouter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string][]RouteRequirement, len(routes))
+	for _, route := range routes {
+		got[route.Method+" "+route.Pattern] = route.Requirements
+	}
+	if len(got) != 5 {
+		t.Fatalf("expected 5 routes, got %v", got)
+	}
+	if reqs := got["GET /"]; len(reqs) != 0 {
+		t.Fatalf("expected root to be unguarded, got %v", reqs)
+	}
+	adjust := got["POST /inventory/adjustments"]
+	if len(adjust) != 2 || adjust[0].All || !adjust[1].All {
+		t.Fatalf("unexpected adjustment requirements: %+v", adjust)
+	}
+	if !reflect.DeepEqual(got["GET /sales/orders"], []RouteRequirement{{Permissions: []string{"sales.order.view"}}}) {
+		t.Fatalf("unexpected sales requirements: %+v", got["GET /sales/orders"])
+	}
+	if !reflect.DeepEqual(got["GET /reports"], []RouteRequirement{{Permissions: []string{"report.view"}}}) {
+		t.Fatalf("unexpected report requirements: %+v", got["GET /reports"])
+	}
+}
+
+func TestSimulateAccess(t *testing.T) {
+	routes, err := RoutePermissions(testRouter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules := SimulateAccess(routes, []string{"Inventory.Edit", "sales.order.view"})
+	byModule := make(map[string]ModuleAccess, len(modules))
+	for _, module := range modules {
+		byModule[module.Module] = module
+	}
+	if len(byModule) != 4 {
+		t.Fatalf("expected 4 modules, got %+v", modules)
+	}
+	inventory := byModule["inventory"]
+	if inventory.Granted != 1 || len(inventory.Routes) != 2 {
+		t.Fatalf("unexpected inventory access: %+v", inventory)
+	}
+	for _, route := range inventory.Routes {
+		if route.Method == http.MethodPost {
+			if route.Granted || !reflect.DeepEqual(route.Missing, []string{"finance.gl.post", "inventory.edit"}) {
+				t.Fatalf("unexpected adjustment access: %+v", route)
+			}
+		}
+	}
+	if byModule["sales"].Granted != 1 || byModule["/"].Granted != 1 || byModule["reports"].Granted != 0 {
+		t.Fatalf("unexpected access: %+v", modules)
+	}
+}
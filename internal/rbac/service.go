@@ -3,12 +3,14 @@ package rbac
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -17,12 +19,14 @@ var ErrNotFound = errors.New("rbac: not found")
 
 // Service orchestrates RBAC operations.
 type Service struct {
+	pool    *pgxpool.Pool
 	queries *sqlc.Queries
+	audit   AuditPort
 }
 
 // NewService constructs a Service backed by the provided pool.
 func NewService(pool *pgxpool.Pool) *Service {
-	return &Service{queries: sqlc.New(pool)}
+	return &Service{pool: pool, queries: sqlc.New(pool)}
 }
 
 // ListRoles returns all roles ordered by name.
@@ -63,7 +67,10 @@ func (s *Service) CreateRole(ctx context.Context, name, description string) (Rol
 	if err != nil {
 		return Role{}, err
 	}
-	return toDomainRole(row), nil
+	role := toDomainRole(row)
+	s.record(ctx, "role.create", shared.AuditEntityRole, role.ID, map[string]any{"name": role.Name},
+		nil, roleSnapshot{Name: role.Name, Description: role.Description})
+	return role, nil
 }
 
 // UpdateRole updates an existing role.
@@ -72,6 +79,14 @@ func (s *Service) UpdateRole(ctx context.Context, id int64, name, description st
 	if name == "" {
 		return Role{}, errors.New("rbac: role name required")
 	}
+	var before Role
+	if s.audit != nil {
+		existing, err := s.GetRole(ctx, id)
+		if err != nil {
+			return Role{}, err
+		}
+		before = existing
+	}
 	row, err := s.queries.UpdateRole(ctx, sqlc.UpdateRoleParams{
 		ID:          id,
 		Name:        name,
@@ -83,11 +98,28 @@ func (s *Service) UpdateRole(ctx context.Context, id int64, name, description st
 		}
 		return Role{}, err
 	}
-	return toDomainRole(row), nil
+	role := toDomainRole(row)
+	s.record(ctx, "role.update", shared.AuditEntityRole, role.ID, map[string]any{"name": role.Name},
+		roleSnapshot{Name: before.Name, Description: before.Description},
+		roleSnapshot{Name: role.Name, Description: role.Description})
+	return role, nil
 }
 
 // DeleteRole removes a role by ID. Returns ErrNotFound if nothing was deleted.
+// The audit entry keeps the role and the permissions it granted.
 func (s *Service) DeleteRole(ctx context.Context, id int64) error {
+	var before roleSnapshot
+	if s.audit != nil {
+		role, err := s.GetRole(ctx, id)
+		if err != nil {
+			return err
+		}
+		perms, err := s.RolePermissionNames(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = roleSnapshot{Name: role.Name, Description: role.Description, Permissions: perms}
+	}
 	rows, err := s.queries.DeleteRole(ctx, id)
 	if err != nil {
 		return err
@@ -95,6 +127,7 @@ func (s *Service) DeleteRole(ctx context.Context, id int64) error {
 	if rows == 0 {
 		return ErrNotFound
 	}
+	s.record(ctx, "role.delete", shared.AuditEntityRole, id, map[string]any{"name": before.Name}, before, nil)
 	return nil
 }
 
@@ -136,8 +169,10 @@ func (s *Service) SetRolePermissions(ctx context.Context, roleID int64, permissi
 		return err
 	}
 	existing := make(map[int64]struct{}, len(perms))
+	beforeNames := make([]string, 0, len(perms))
 	for _, p := range perms {
 		existing[p.ID] = struct{}{}
+		beforeNames = append(beforeNames, p.Name)
 	}
 	keep := make(map[int64]struct{}, len(permissionIDs))
 	for _, id := range permissionIDs {
@@ -155,25 +190,70 @@ func (s *Service) SetRolePermissions(ctx context.Context, roleID int64, permissi
 			}
 		}
 	}
+	if s.audit != nil {
+		afterNames, err := s.RolePermissionNames(ctx, roleID)
+		if err != nil {
+			return err
+		}
+		sort.Strings(beforeNames)
+		added, removed := diffNames(beforeNames, afterNames)
+		if len(added) > 0 || len(removed) > 0 {
+			s.record(ctx, "role.permissions.update", shared.AuditEntityRole, roleID,
+				map[string]any{"added": added, "removed": removed},
+				map[string]any{"permissions": beforeNames},
+				map[string]any{"permissions": afterNames})
+		}
+	}
 	return nil
 }
 
 // AssignRole assigns a role to the given user.
 func (s *Service) AssignRole(ctx context.Context, userID, roleID int64) error {
-	return s.queries.AssignRoleToUser(ctx, sqlc.AssignRoleToUserParams{
-		UserID: userID,
-		RoleID: roleID,
+	return s.changeUserRoles(ctx, "user.role.assign", userID, roleID, func() error {
+		return s.queries.AssignRoleToUser(ctx, sqlc.AssignRoleToUserParams{
+			UserID: userID,
+			RoleID: roleID,
+		})
 	})
 }
 
 // RemoveRole removes a role from a user.
 func (s *Service) RemoveRole(ctx context.Context, userID, roleID int64) error {
-	return s.queries.RemoveRoleFromUser(ctx, sqlc.RemoveRoleFromUserParams{
-		UserID: userID,
-		RoleID: roleID,
+	return s.changeUserRoles(ctx, "user.role.remove", userID, roleID, func() error {
+		return s.queries.RemoveRoleFromUser(ctx, sqlc.RemoveRoleFromUserParams{
+			UserID: userID,
+			RoleID: roleID,
+		})
 	})
 }
 
+// changeUserRoles applies a user-role change and audits the user's role set
+// before and after it.
+func (s *Service) changeUserRoles(ctx context.Context, action string, userID, roleID int64, apply func() error) error {
+	if s.audit == nil {
+		return apply()
+	}
+	before, err := s.queries.ListUserRoleNames(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	after, err := s.queries.ListUserRoleNames(ctx, userID)
+	if err != nil {
+		return err
+	}
+	added, removed := diffNames(before, after)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	s.record(ctx, action, shared.AuditEntityUserRole, userID,
+		map[string]any{"role_id": roleID, "added": added, "removed": removed},
+		map[string]any{"roles": before}, map[string]any{"roles": after})
+	return nil
+}
+
 // EffectivePermissions returns deduplicated permission names for a user.
 func (s *Service) EffectivePermissions(ctx context.Context, userID int64) ([]string, error) {
 	rows, err := s.queries.UserEffectivePermissions(ctx, userID)
@@ -185,6 +265,52 @@ func (s *Service) EffectivePermissions(ctx context.Context, userID int64) ([]str
 	return perms, nil
 }
 
+// UserCompanyIDs returns the companies a user is assigned to.
+func (s *Service) UserCompanyIDs(ctx context.Context, userID int64) ([]int64, error) {
+	return s.queries.ListUserCompanyIDs(ctx, userID)
+}
+
+// SetUserCompanies replaces the companies a user is assigned to. The old
+// assignments are removed and the new ones added in one transaction, so a
+// failure never leaves the user without companies.
+func (s *Service) SetUserCompanies(ctx context.Context, userID int64, companyIDs []int64) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+	queries := s.queries.WithTx(tx)
+	before, err := queries.ListUserCompanyIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := queries.DeleteUserCompanies(ctx, userID); err != nil {
+		return err
+	}
+	for _, id := range companyIDs {
+		if id <= 0 {
+			continue
+		}
+		if err := queries.AddUserCompany(ctx, sqlc.AddUserCompanyParams{UserID: userID, CompanyID: id}); err != nil {
+			return err
+		}
+	}
+	after, err := queries.ListUserCompanyIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	added, removed := diffNames(int64Strings(before), int64Strings(after))
+	if len(added) > 0 || len(removed) > 0 {
+		s.record(ctx, "user.companies.update", shared.AuditEntityUserCompany, userID,
+			map[string]any{"added": added, "removed": removed},
+			map[string]any{"company_ids": before}, map[string]any{"company_ids": after})
+	}
+	return nil
+}
+
 func toDomainRole(row sqlc.Role) Role {
 	return Role{
 		ID:          row.ID,
@@ -0,0 +1,120 @@
+package reportjob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Status captures the lifecycle of an async report job.
+type Status string
+
+const (
+	StatusPending    Status = "PENDING"
+	StatusInProgress Status = "IN_PROGRESS"
+	StatusReady      Status = "READY"
+	StatusFailed     Status = "FAILED"
+	StatusExpired    Status = "EXPIRED"
+)
+
+// Kind names a report that can be generated in the background.
+type Kind string
+
+const (
+	KindGLExtract          Kind = "gl_extract"
+	KindInventoryValuation Kind = "inventory_valuation"
+	KindAPAging            Kind = "ap_aging"
+	KindARAging            Kind = "ar_aging"
+	KindQuotationPDFs      Kind = "quotation_pdfs"
+	KindSalesOrderPDFs     Kind = "sales_order_pdfs"
+)
+
+var (
+	// ErrJobNotFound indicates the report job does not exist.
+	ErrJobNotFound = errors.New("reportjob: job not found")
+	// ErrUnknownKind indicates no generator is registered for the kind.
+	ErrUnknownKind = errors.New("reportjob: unknown report kind")
+	// ErrInvalidParams indicates report filters the generator cannot use.
+	ErrInvalidParams = errors.New("reportjob: invalid report parameters")
+	// ErrNotReady indicates the file has not been generated yet.
+	ErrNotReady = errors.New("reportjob: report not ready")
+	// ErrExpired indicates the generated file has been cleaned up.
+	ErrExpired = errors.New("reportjob: report expired")
+	// ErrScheduleNotFound indicates the report schedule does not exist.
+	ErrScheduleNotFound = errors.New("reportjob: schedule not found")
+	// ErrInvalidSchedule indicates a schedule with a bad cadence or recipients.
+	ErrInvalidSchedule = errors.New("reportjob: invalid schedule")
+	// ErrQueueUnavailable indicates the job was stored but could not be queued.
+	ErrQueueUnavailable = errors.New("reportjob: queue unavailable")
+	// ErrJobBusy indicates another worker holds the job's lease. The task is
+	// retried so a job left behind by a crashed worker is picked up again.
+	ErrJobBusy = errors.New("reportjob: job in progress elsewhere")
+)
+
+// Params carries the report filters as submitted by the user. Each generator
+// interprets the keys it understands.
+type Params map[string]string
+
+// Report is a persisted report generation request and its result.
+type Report struct {
+	ID           int64      `json:"id"`
+	Kind         Kind       `json:"kind"`
+	Params       Params     `json:"params"`
+	Status       Status     `json:"status"`
+	Progress     int        `json:"progress"`
+	RowsWritten  int64      `json:"rows_written"`
+	FilePath     string     `json:"-"`
+	FileName     string     `json:"file_name,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+	ErrorMessage string     `json:"error,omitempty"`
+	RequestedBy  int64      `json:"requested_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+// Downloadable returns ErrExpired or ErrNotReady when the file cannot be
+// served at now.
+func (r Report) Downloadable(now time.Time) error {
+	switch {
+	case r.Status == StatusExpired, r.ExpiresAt != nil && !now.Before(*r.ExpiresAt):
+		return ErrExpired
+	case r.Status != StatusReady || r.FilePath == "":
+		return ErrNotReady
+	}
+	return nil
+}
+
+// ProgressFunc reports rows written so far out of total. A zero total means
+// the generator cannot tell how many rows remain.
+type ProgressFunc func(done, total int64)
+
+// Format is a file type a generator can write.
+type Format struct {
+	Extension   string
+	ContentType string
+}
+
+// Generator renders one kind of report to w. Permission is required on top of
+// report access to request the kind; Validate, when set, rejects bad params
+// before the job is queued. Formats lists the file types selectable with the
+// format param; Extension and ContentType describe the default.
+type Generator struct {
+	Kind        Kind
+	Extension   string
+	ContentType string
+	Formats     map[string]Format
+	Permission  string
+	Validate    func(params Params) error
+	Generate    func(ctx context.Context, params Params, w io.Writer, progress ProgressFunc) error
+}
+
+// FileType returns the extension and content type written for params.
+func (g Generator) FileType(params Params) Format {
+	if format, ok := g.Formats[params["format"]]; ok {
+		return format
+	}
+	return Format{Extension: g.Extension, ContentType: g.ContentType}
+}
@@ -0,0 +1,207 @@
+package reportjob
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/journals"
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// progressEvery throttles progress updates to one per this many rows.
+const progressEvery = 1000
+
+// JournalLineSource pages posted journal lines.
+type JournalLineSource interface {
+	ListLines(ctx context.Context, req journals.ListLinesRequest) (journals.JournalLinePage, error)
+}
+
+// ValuationSource computes the as-of inventory valuation.
+type ValuationSource interface {
+	ValuationAsOf(ctx context.Context, filter inventory.ValuationFilter) (inventory.ValuationReport, error)
+}
+
+// DefaultGenerators returns the report kinds served by the web app and worker.
+func DefaultGenerators(lines JournalLineSource, valuation ValuationSource) []Generator {
+	return []Generator{GLExtractGenerator(lines), InventoryValuationGenerator(valuation)}
+}
+
+// GLExtractGenerator writes every posted journal line matching the optional
+// company_id, period_id and account_id params as CSV, walking the lines with
+// the keyset cursor so memory stays flat.
+func GLExtractGenerator(source JournalLineSource) Generator {
+	return Generator{
+		Kind:        KindGLExtract,
+		Extension:   "csv",
+		ContentType: "text/csv",
+		Permission:  shared.PermFinanceGLView,
+		Validate: func(params Params) error {
+			_, err := glExtractRequest(params)
+			return err
+		},
+		Generate: func(ctx context.Context, params Params, w io.Writer, progress ProgressFunc) error {
+			req, err := glExtractRequest(params)
+			if err != nil {
+				return err
+			}
+			writer := csv.NewWriter(w)
+			if err := writer.Write([]string{"Line ID", "Journal Number", "Date", "Period ID", "Account", "Debit", "Credit", "Company ID", "Branch ID", "Warehouse ID"}); err != nil {
+				return err
+			}
+			var written int64
+			for {
+				page, err := source.ListLines(ctx, req)
+				if err != nil {
+					return err
+				}
+				for _, line := range page.Lines {
+					if err := writer.Write([]string{
+						strconv.FormatInt(line.ID, 10),
+						strconv.FormatInt(line.JournalNumber, 10),
+						line.Date.Format("2006-01-02"),
+						strconv.FormatInt(line.PeriodID, 10),
+						line.AccountCode,
+						formatAmount(line.Debit),
+						formatAmount(line.Credit),
+						formatOptionalInt(line.DimCompanyID),
+						formatOptionalInt(line.DimBranchID),
+						formatOptionalInt(line.DimWarehouseID),
+					}); err != nil {
+						return err
+					}
+					written++
+					if written%progressEvery == 0 {
+						progress(written, 0)
+					}
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				req.Cursor = page.NextCursor
+			}
+			writer.Flush()
+			progress(written, written)
+			return writer.Error()
+		},
+	}
+}
+
+// InventoryValuationGenerator writes the as-of valuation lines as CSV. The
+// as_of param is required; company_id, warehouse_id and category_id narrow the
+// scope.
+func InventoryValuationGenerator(source ValuationSource) Generator {
+	return Generator{
+		Kind:        KindInventoryValuation,
+		Extension:   "csv",
+		ContentType: "text/csv",
+		Permission:  "inventory.view",
+		Validate: func(params Params) error {
+			_, err := valuationFilter(params)
+			return err
+		},
+		Generate: func(ctx context.Context, params Params, w io.Writer, progress ProgressFunc) error {
+			filter, err := valuationFilter(params)
+			if err != nil {
+				return err
+			}
+			report, err := source.ValuationAsOf(ctx, filter)
+			if err != nil {
+				return err
+			}
+			total := int64(len(report.Lines))
+			writer := csv.NewWriter(w)
+			if err := writer.Write([]string{"Warehouse", "SKU", "Product", "Qty", "Avg Cost", "Value"}); err != nil {
+				return err
+			}
+			for i, line := range report.Lines {
+				if err := writer.Write([]string{
+					line.WarehouseCode,
+					line.SKU,
+					line.ProductName,
+					strconv.FormatFloat(line.Qty, 'f', -1, 64),
+					formatAmount(line.AvgCost),
+					formatAmount(line.Value),
+				}); err != nil {
+					return err
+				}
+				if done := int64(i + 1); done%progressEvery == 0 {
+					progress(done, total)
+				}
+			}
+			if err := writer.Write([]string{"Total", "", "", strconv.FormatFloat(report.TotalQty, 'f', -1, 64), "", formatAmount(report.TotalValue)}); err != nil {
+				return err
+			}
+			writer.Flush()
+			progress(total, total)
+			return writer.Error()
+		},
+	}
+}
+
+func glExtractRequest(params Params) (journals.ListLinesRequest, error) {
+	req := journals.ListLinesRequest{Limit: shared.MaxListLimit}
+	var err error
+	if req.CompanyID, err = params.optionalInt("company_id"); err != nil {
+		return req, err
+	}
+	if req.PeriodID, err = params.optionalInt("period_id"); err != nil {
+		return req, err
+	}
+	if req.AccountID, err = params.optionalInt("account_id"); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+func valuationFilter(params Params) (inventory.ValuationFilter, error) {
+	asOf, err := time.Parse("2006-01-02", strings.TrimSpace(params["as_of"]))
+	if err != nil {
+		return inventory.ValuationFilter{}, fmt.Errorf("%w: as_of must be YYYY-MM-DD", ErrInvalidParams)
+	}
+	filter := inventory.ValuationFilter{AsOf: asOf}
+	if id, err := params.optionalInt("company_id"); err != nil {
+		return filter, err
+	} else if id != nil {
+		filter.CompanyID = *id
+	}
+	if id, err := params.optionalInt("warehouse_id"); err != nil {
+		return filter, err
+	} else if id != nil {
+		filter.WarehouseID = *id
+	}
+	if id, err := params.optionalInt("category_id"); err != nil {
+		return filter, err
+	} else if id != nil {
+		filter.CategoryID = *id
+	}
+	return filter, nil
+}
+
+func (p Params) optionalInt(key string) (*int64, error) {
+	raw := strings.TrimSpace(p[key])
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v <= 0 {
+		return nil, fmt.Errorf("%w: invalid %s", ErrInvalidParams, key)
+	}
+	return &v, nil
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatOptionalInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
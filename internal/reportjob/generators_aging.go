@@ -0,0 +1,211 @@
+package reportjob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/ap"
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/xlsx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+	"github.com/odyssey-erp/odyssey-erp/web"
+)
+
+// Output formats of the aging reports.
+const (
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+var agingFormats = map[string]Format{
+	FormatXLSX: {Extension: "xlsx", ContentType: xlsx.ContentType},
+	FormatPDF:  {Extension: "pdf", ContentType: "application/pdf"},
+}
+
+// PayablesAgingSource computes the AP aging by supplier.
+type PayablesAgingSource interface {
+	APAgingReport(ctx context.Context, asOf time.Time) (ap.APAgingReport, error)
+}
+
+// ReceivablesAgingSource computes the AR aging by customer.
+type ReceivablesAgingSource interface {
+	ARAgingReport(ctx context.Context, asOf time.Time) (ar.ARAgingReport, error)
+}
+
+// PDFRenderer converts HTML documents into PDF bytes.
+type PDFRenderer interface {
+	RenderHTML(ctx context.Context, html string) ([]byte, error)
+}
+
+// AgingGenerators returns the AP and AR aging report kinds. Both take a
+// required as_of param and write XLSX unless format is pdf.
+func AgingGenerators(payables PayablesAgingSource, receivables ReceivablesAgingSource, renderer PDFRenderer) []Generator {
+	return []Generator{
+		agingGenerator(KindAPAging, "finance.ap.view", renderer, func(ctx context.Context, asOf time.Time) (agingDocument, error) {
+			report, err := payables.APAgingReport(ctx, asOf)
+			if err != nil {
+				return agingDocument{}, err
+			}
+			doc := agingDocument{Title: "Accounts Payable Aging", Party: "Supplier", AsOf: asOf}
+			for _, s := range report.Suppliers {
+				doc.Rows = append(doc.Rows, agingRow{s.SupplierName, s.Current, s.Bucket30, s.Bucket60, s.Bucket90, s.Bucket120, s.Total})
+			}
+			b := report.Summary
+			doc.Summary = agingRow{"Total", b.Current, b.Bucket30, b.Bucket60, b.Bucket90, b.Bucket120, b.Total()}
+			return doc, nil
+		}),
+		agingGenerator(KindARAging, shared.PermFinanceARView, renderer, func(ctx context.Context, asOf time.Time) (agingDocument, error) {
+			report, err := receivables.ARAgingReport(ctx, asOf)
+			if err != nil {
+				return agingDocument{}, err
+			}
+			doc := agingDocument{Title: "Accounts Receivable Aging", Party: "Customer", AsOf: asOf}
+			for _, c := range report.Customers {
+				doc.Rows = append(doc.Rows, agingRow{c.CustomerName, c.Current, c.Bucket30, c.Bucket60, c.Bucket90, c.Bucket120, c.Total})
+			}
+			b := report.Summary
+			doc.Summary = agingRow{"Total", b.Current, b.Bucket30, b.Bucket60, b.Bucket90, b.Bucket120, b.Total()}
+			return doc, nil
+		}),
+	}
+}
+
+// agingRow is one counterparty's, or the summary's, balance by bucket.
+type agingRow struct {
+	Name      string
+	Current   float64
+	Bucket30  float64
+	Bucket60  float64
+	Bucket90  float64
+	Bucket120 float64
+	Total     float64
+}
+
+// agingDocument is the AP or AR aging in the shape both formats render.
+type agingDocument struct {
+	Title       string
+	Party       string
+	AsOf        time.Time
+	GeneratedAt time.Time
+	Rows        []agingRow
+	Summary     agingRow
+}
+
+var agingHeader = []string{"Current", "1-30", "31-60", "61-90", "Over 90", "Total"}
+
+func agingGenerator(kind Kind, permission string, renderer PDFRenderer, load func(ctx context.Context, asOf time.Time) (agingDocument, error)) Generator {
+	return Generator{
+		Kind:        kind,
+		Extension:   "xlsx",
+		ContentType: xlsx.ContentType,
+		Formats:     agingFormats,
+		Permission:  permission,
+		Validate: func(params Params) error {
+			_, _, err := agingParams(params)
+			return err
+		},
+		Generate: func(ctx context.Context, params Params, w io.Writer, progress ProgressFunc) error {
+			asOf, format, err := agingParams(params)
+			if err != nil {
+				return err
+			}
+			doc, err := load(ctx, asOf)
+			if err != nil {
+				return err
+			}
+			doc.GeneratedAt = time.Now()
+			if format == FormatPDF {
+				err = writeAgingPDF(ctx, w, doc, renderer)
+			} else {
+				err = writeAgingXLSX(w, doc)
+			}
+			if err != nil {
+				return err
+			}
+			rows := int64(len(doc.Rows))
+			progress(rows, rows)
+			return nil
+		},
+	}
+}
+
+func agingParams(params Params) (time.Time, string, error) {
+	asOf, err := time.Parse("2006-01-02", strings.TrimSpace(params["as_of"]))
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: as_of must be YYYY-MM-DD", ErrInvalidParams)
+	}
+	format := strings.ToLower(strings.TrimSpace(params["format"]))
+	if format == "" {
+		format = FormatXLSX
+	}
+	if _, ok := agingFormats[format]; !ok {
+		return time.Time{}, "", fmt.Errorf("%w: format must be xlsx or pdf", ErrInvalidParams)
+	}
+	return asOf, format, nil
+}
+
+func writeAgingXLSX(w io.Writer, doc agingDocument) error {
+	summary := xlsx.Sheet{Name: "Summary", Rows: [][]xlsx.Cell{
+		{xlsx.Text("Report"), xlsx.Text(doc.Title)},
+		{xlsx.Text("As Of"), xlsx.Text(doc.AsOf.Format("2006-01-02"))},
+		{xlsx.Text("Generated At"), xlsx.Text(doc.GeneratedAt.UTC().Format(time.RFC3339))},
+		{},
+		{xlsx.Text("Bucket"), xlsx.Text("Amount")},
+	}}
+	s := doc.Summary
+	for i, amount := range []float64{s.Current, s.Bucket30, s.Bucket60, s.Bucket90, s.Bucket120, s.Total} {
+		summary.Rows = append(summary.Rows, []xlsx.Cell{xlsx.Text(agingHeader[i]), xlsx.Num(amount)})
+	}
+
+	header := []xlsx.Cell{xlsx.Text(doc.Party)}
+	for _, title := range agingHeader {
+		header = append(header, xlsx.Text(title))
+	}
+	detail := xlsx.Sheet{Name: doc.Party + "s", Rows: [][]xlsx.Cell{header}}
+	for _, row := range append(doc.Rows, doc.Summary) {
+		detail.Rows = append(detail.Rows, []xlsx.Cell{
+			xlsx.Text(row.Name),
+			xlsx.Num(row.Current),
+			xlsx.Num(row.Bucket30),
+			xlsx.Num(row.Bucket60),
+			xlsx.Num(row.Bucket90),
+			xlsx.Num(row.Bucket120),
+			xlsx.Num(row.Total),
+		})
+	}
+	return xlsx.Write(w, []xlsx.Sheet{summary, detail})
+}
+
+func writeAgingPDF(ctx context.Context, w io.Writer, doc agingDocument, renderer PDFRenderer) error {
+	if renderer == nil {
+		return errors.New("reportjob: pdf renderer not configured")
+	}
+	funcMap := template.FuncMap{
+		"formatDate": func(t time.Time) string { return t.Format("02 Jan 2006") },
+		"formatDecimal": func(v float64) string {
+			return strconv.FormatFloat(v, 'f', 2, 64)
+		},
+	}
+	tpl, err := template.New("aging_pdf.html").Funcs(funcMap).ParseFS(web.Templates, "templates/reports/aging_pdf.html")
+	if err != nil {
+		return fmt.Errorf("parse aging template: %w", err)
+	}
+	var html bytes.Buffer
+	if err := tpl.ExecuteTemplate(&html, "reports/aging_pdf.html", view.TemplateData{Data: doc}); err != nil {
+		return fmt.Errorf("render aging: %w", err)
+	}
+	pdf, err := renderer.RenderHTML(ctx, html.String())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(pdf)
+	return err
+}
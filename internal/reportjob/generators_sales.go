@@ -0,0 +1,75 @@
+package reportjob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// SalesDocumentSource writes quotations or sales orders as a zip of PDFs.
+// docType is "quotation" or "sales_order"; a non-zero companyID restricts the
+// documents to that company.
+type SalesDocumentSource interface {
+	WriteZip(ctx context.Context, docType string, ids []int64, companyID int64, w io.Writer, progress func(done, total int64)) error
+}
+
+// SalesDocumentGenerators returns the quotation and sales order PDF batch
+// kinds. They take the document IDs as a comma-separated ids param and are
+// queued by the sales export endpoints after their company checks.
+func SalesDocumentGenerators(source SalesDocumentSource) []Generator {
+	return []Generator{
+		salesDocumentGenerator(KindQuotationPDFs, "quotation", shared.PermQuotationView, source),
+		salesDocumentGenerator(KindSalesOrderPDFs, "sales_order", shared.PermSalesOrderView, source),
+	}
+}
+
+func salesDocumentGenerator(kind Kind, docType, permission string, source SalesDocumentSource) Generator {
+	return Generator{
+		Kind:        kind,
+		Extension:   "zip",
+		ContentType: "application/zip",
+		Permission:  permission,
+		Validate: func(params Params) error {
+			_, err := documentIDs(params)
+			return err
+		},
+		Generate: func(ctx context.Context, params Params, w io.Writer, progress ProgressFunc) error {
+			ids, err := documentIDs(params)
+			if err != nil {
+				return err
+			}
+			companyID, err := params.optionalInt("company_id")
+			if err != nil {
+				return err
+			}
+			var company int64
+			if companyID != nil {
+				company = *companyID
+			}
+			return source.WriteZip(ctx, docType, ids, company, w, progress)
+		},
+	}
+}
+
+// documentIDs parses the required comma-separated ids param.
+func documentIDs(params Params) ([]int64, error) {
+	var ids []int64
+	for _, raw := range strings.Split(params["ids"], ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%w: ids must be document IDs", ErrInvalidParams)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: ids is required", ErrInvalidParams)
+	}
+	return ids, nil
+}
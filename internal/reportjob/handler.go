@@ -0,0 +1,302 @@
+package reportjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+// paramKeys are the form fields copied into a job's params.
+var paramKeys = []string{"period_id", "account_id", "as_of", "warehouse_id", "category_id", "format"}
+
+// Handler serves the async report request, status, and download endpoints.
+type Handler struct {
+	logger  *slog.Logger
+	service *Service
+	jobs    *jobs.Client
+	rbac    rbac.Middleware
+}
+
+// NewHandler constructs the handler.
+func NewHandler(logger *slog.Logger, service *Service, jobsClient *jobs.Client, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, jobs: jobsClient, rbac: rbac}
+}
+
+// MountRoutes registers routes.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Route("/reports/jobs", func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermReportView))
+		r.Post("/", h.request)
+		r.Get("/{id}", h.status)
+		r.Get("/{id}/download", h.download)
+	})
+	r.Route("/reports/schedules", func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermReportView))
+		r.Get("/", h.listSchedules)
+		r.Post("/", h.createSchedule)
+		r.Post("/{id}/deactivate", h.deactivateSchedule)
+	})
+}
+
+// scopeCompany sets the company_id param to the requested company when the
+// user may see it, else to their default company. Unrestricted users may
+// leave it empty to report on every company; users scoped to no company get
+// shared.ErrForbidden so the worker never runs without a scope.
+func scopeCompany(r *http.Request, params Params) error {
+	requested, _ := strconv.ParseInt(strings.TrimSpace(r.PostFormValue("company_id")), 10, 64)
+	companyID, err := shared.RequireCompanyID(r.Context(), requested, 0)
+	if err != nil {
+		return err
+	}
+	if companyID > 0 {
+		params["company_id"] = strconv.FormatInt(companyID, 10)
+	}
+	return nil
+}
+
+type jobResponse struct {
+	Report
+	StatusURL   string `json:"status_url"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+func (h *Handler) request(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid request", "form could not be parsed")
+		return
+	}
+	kind := Kind(strings.TrimSpace(r.PostFormValue("kind")))
+	gen, ok := h.service.Generator(kind)
+	if !ok {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid request", ErrUnknownKind.Error())
+		return
+	}
+	if gen.Permission != "" && !h.rbac.Allows(r, gen.Permission) {
+		httpx.Problem(w, http.StatusForbidden, "Forbidden", "missing permission "+gen.Permission)
+		return
+	}
+
+	params := Params{}
+	for _, key := range paramKeys {
+		if v := strings.TrimSpace(r.PostFormValue(key)); v != "" {
+			params[key] = v
+		}
+	}
+	if err := scopeCompany(r, params); err != nil {
+		httpx.Problem(w, http.StatusForbidden, "Forbidden", "no company assigned")
+		return
+	}
+
+	job, err := h.Queue(r.Context(), kind, params, currentUser(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidParams):
+			httpx.Problem(w, http.StatusBadRequest, "Invalid request", err.Error())
+		case errors.Is(err, ErrQueueUnavailable):
+			httpx.Problem(w, http.StatusServiceUnavailable, "Queue unavailable", "report could not be queued")
+		default:
+			httpx.Problem(w, http.StatusInternalServerError, "Internal error", "report could not be queued")
+		}
+		return
+	}
+	w.Header().Set("Location", StatusURL(job.ID))
+	httpx.JSON(w, http.StatusAccepted, newJobResponse(job))
+}
+
+// Queue stores a job of kind and hands it to the worker. Features that move
+// large exports to the background call it once their own access checks pass;
+// the caller's permission for the kind is not checked again. Failures other
+// than ErrInvalidParams are logged.
+func (h *Handler) Queue(ctx context.Context, kind Kind, params Params, requestedBy int64) (Report, error) {
+	job, err := h.service.Request(ctx, kind, params, requestedBy)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidParams) {
+			h.logger.ErrorContext(ctx, "request report job", slog.Any("error", err))
+		}
+		return Report{}, err
+	}
+	if _, err := h.jobs.EnqueueReportJob(ctx, job.ID); err != nil {
+		h.logger.ErrorContext(ctx, "enqueue report job", slog.Any("error", err), slog.Int64("report_job_id", job.ID))
+		_ = h.service.Fail(ctx, job.ID, err)
+		return Report{}, fmt.Errorf("%w: %v", ErrQueueUnavailable, err)
+	}
+	return job, nil
+}
+
+func (h *Handler) status(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	httpx.JSON(w, http.StatusOK, newJobResponse(job))
+}
+
+func (h *Handler) download(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	if err := job.Downloadable(h.service.now()); err != nil {
+		if errors.Is(err, ErrExpired) {
+			httpx.Problem(w, http.StatusGone, "Report expired", "request the report again")
+			return
+		}
+		httpx.Problem(w, http.StatusConflict, "Report not ready", "status is "+string(job.Status))
+		return
+	}
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			httpx.Problem(w, http.StatusGone, "Report expired", "request the report again")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "open report file", slog.Any("error", err), slog.String("path", job.FilePath))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+	contentType := "application/octet-stream"
+	if gen, ok := h.service.Generator(job.Kind); ok {
+		if format := gen.FileType(job.Params); format.ContentType != "" {
+			contentType = format.ContentType
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+job.FileName)
+	if job.FileSize > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(job.FileSize, 10))
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		h.logger.WarnContext(r.Context(), "stream report file", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) listSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.service.ListSchedules(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list report schedules", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, "Internal error", "schedules could not be loaded")
+		return
+	}
+	httpx.JSON(w, http.StatusOK, map[string]any{"schedules": schedules})
+}
+
+func (h *Handler) createSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid request", "form could not be parsed")
+		return
+	}
+	kind := Kind(strings.TrimSpace(r.PostFormValue("kind")))
+	gen, ok := h.service.Generator(kind)
+	if !ok {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid request", ErrUnknownKind.Error())
+		return
+	}
+	if gen.Permission != "" && !h.rbac.Allows(r, gen.Permission) {
+		httpx.Problem(w, http.StatusForbidden, "Forbidden", "missing permission "+gen.Permission)
+		return
+	}
+
+	params := Params{}
+	for _, key := range paramKeys {
+		if v := strings.TrimSpace(r.PostFormValue(key)); v != "" && key != "as_of" {
+			params[key] = v
+		}
+	}
+	if err := scopeCompany(r, params); err != nil {
+		httpx.Problem(w, http.StatusForbidden, "Forbidden", "no company assigned")
+		return
+	}
+	day, _ := strconv.Atoi(strings.TrimSpace(r.PostFormValue("day")))
+
+	schedule, err := h.service.CreateSchedule(r.Context(), Schedule{
+		Kind:       kind,
+		Params:     params,
+		Frequency:  Frequency(r.PostFormValue("frequency")),
+		Day:        day,
+		Recipients: parseRecipients(r.PostFormValue("recipients")),
+		CreatedBy:  currentUser(r),
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidParams) || errors.Is(err, ErrInvalidSchedule) {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "create report schedule", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, "Internal error", "schedule could not be saved")
+		return
+	}
+	httpx.JSON(w, http.StatusCreated, schedule)
+}
+
+func (h *Handler) deactivateSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeactivateSchedule(r.Context(), parseID(r)); err != nil {
+		if errors.Is(err, ErrScheduleNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "deactivate report schedule", slog.Any("error", err))
+		httpx.Problem(w, http.StatusInternalServerError, "Internal error", "schedule could not be deactivated")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load fetches the job and hides jobs requested by other users.
+func (h *Handler) load(w http.ResponseWriter, r *http.Request) (Report, bool) {
+	job, err := h.service.Get(r.Context(), parseID(r))
+	if err == nil && job.RequestedBy != 0 && job.RequestedBy != currentUser(r) {
+		err = ErrJobNotFound
+	}
+	if err != nil {
+		if errors.Is(err, ErrJobNotFound) {
+			http.NotFound(w, r)
+			return Report{}, false
+		}
+		h.logger.ErrorContext(r.Context(), "get report job", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return Report{}, false
+	}
+	return job, true
+}
+
+func newJobResponse(job Report) jobResponse {
+	resp := jobResponse{Report: job, StatusURL: StatusURL(job.ID)}
+	if job.Status == StatusReady {
+		resp.DownloadURL = StatusURL(job.ID) + "/download"
+	}
+	return resp
+}
+
+// StatusURL is the JSON status endpoint of a job; the file is served from
+// its /download sub-path once ready.
+func StatusURL(id int64) string {
+	return "/reports/jobs/" + strconv.FormatInt(id, 10)
+}
+
+func parseID(r *http.Request) int64 {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	return id
+}
+
+func currentUser(r *http.Request) int64 {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(sess.User(), 10, 64)
+	return id
+}
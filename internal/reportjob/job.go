@@ -0,0 +1,247 @@
+package reportjob
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+// Mailer delivers scheduled reports.
+type Mailer interface {
+	Send(ctx context.Context, payload jobs.SendEmailPayload) error
+}
+
+// JobConfig wires dependencies required by the worker job.
+type JobConfig struct {
+	Service    *Service
+	StorageDir string
+	Mailer     Mailer
+	Logger     *slog.Logger
+}
+
+// Job generates queued reports to storage, emails scheduled reports and
+// cleans up expired files.
+type Job struct {
+	service    *Service
+	storageDir string
+	mailer     Mailer
+	logger     *slog.Logger
+}
+
+// NewJob constructs a Job handler.
+func NewJob(cfg JobConfig) *Job {
+	return &Job{service: cfg.Service, storageDir: cfg.StorageDir, mailer: cfg.Mailer, logger: cfg.Logger}
+}
+
+// Handle fulfils the asynq.HandlerFunc contract for TaskReportGenerate.
+func (j *Job) Handle(ctx context.Context, task *asynq.Task) error {
+	if j == nil || j.service == nil {
+		return fmt.Errorf("report job not configured")
+	}
+	var payload jobs.ReportJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return asynq.SkipRetry
+	}
+	if payload.ReportJobID == 0 {
+		return asynq.SkipRetry
+	}
+	job, err := j.service.Get(ctx, payload.ReportJobID)
+	if err != nil {
+		if errors.Is(err, ErrJobNotFound) {
+			return asynq.SkipRetry
+		}
+		return err
+	}
+	gen, ok := j.service.Generator(job.Kind)
+	if !ok {
+		_ = j.service.Fail(ctx, job.ID, ErrUnknownKind)
+		return asynq.SkipRetry
+	}
+	claimed, err := j.service.Claim(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		if job.Status == StatusInProgress {
+			return ErrJobBusy
+		}
+		return nil
+	}
+	file, err := j.generate(ctx, job, gen)
+	if err != nil {
+		_ = j.service.Fail(ctx, job.ID, err)
+		if errors.Is(err, ErrInvalidParams) {
+			return asynq.SkipRetry
+		}
+		return err
+	}
+	if err := j.service.Complete(ctx, job.ID, file); err != nil {
+		return err
+	}
+	if j.logger != nil {
+		j.logger.InfoContext(ctx, "report job ready", slog.Int64("report_job_id", job.ID), slog.String("kind", string(job.Kind)), slog.Int64("rows", file.Rows))
+	}
+	return nil
+}
+
+// HandleCleanup fulfils the asynq.HandlerFunc contract for TaskReportCleanup.
+func (j *Job) HandleCleanup(ctx context.Context, _ *asynq.Task) error {
+	if j == nil || j.service == nil {
+		return fmt.Errorf("report job not configured")
+	}
+	removed, err := j.service.CleanupExpired(ctx)
+	if j.logger != nil && removed > 0 {
+		j.logger.InfoContext(ctx, "report files expired", slog.Int("removed", removed))
+	}
+	return err
+}
+
+// HandleSchedules fulfils the asynq.HandlerFunc contract for
+// TaskReportSchedule.
+func (j *Job) HandleSchedules(ctx context.Context, _ *asynq.Task) error {
+	if j == nil || j.service == nil {
+		return fmt.Errorf("report job not configured")
+	}
+	sent, err := j.RunSchedules(ctx, j.service.now())
+	if j.logger != nil && sent > 0 {
+		j.logger.InfoContext(ctx, "scheduled reports sent", slog.Int("sent", sent))
+	}
+	return err
+}
+
+// RunSchedules generates and emails the report of every schedule due on now's
+// date. A failed schedule does not stop the others; it is returned so the
+// task is retried, and schedules already sent today are skipped on retry.
+func (j *Job) RunSchedules(ctx context.Context, now time.Time) (int, error) {
+	due, err := j.service.DueSchedules(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for _, schedule := range due {
+		if err := j.runSchedule(ctx, schedule, now); err != nil {
+			if j.logger != nil {
+				j.logger.ErrorContext(ctx, "scheduled report", slog.Int64("schedule_id", schedule.ID), slog.Any("error", err))
+			}
+			errs = append(errs, fmt.Errorf("schedule %d: %w", schedule.ID, err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (j *Job) runSchedule(ctx context.Context, schedule Schedule, now time.Time) error {
+	if j.mailer == nil {
+		return errors.New("report mailer not configured")
+	}
+	gen, ok := j.service.Generator(schedule.Kind)
+	if !ok {
+		return ErrUnknownKind
+	}
+	job, err := j.service.Request(ctx, schedule.Kind, schedule.runParams(now), schedule.CreatedBy)
+	if err != nil {
+		return err
+	}
+	if _, err := j.service.Claim(ctx, job.ID); err != nil {
+		return err
+	}
+	file, err := j.generate(ctx, job, gen)
+	if err != nil {
+		_ = j.service.Fail(ctx, job.ID, err)
+		return err
+	}
+	if err := j.service.Complete(ctx, job.ID, file); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return err
+	}
+	asOf := job.Params["as_of"]
+	err = j.mailer.Send(ctx, jobs.SendEmailPayload{
+		To:      schedule.Recipients[0],
+		Cc:      schedule.Recipients[1:],
+		Subject: fmt.Sprintf("%s report as of %s", reportTitle(schedule.Kind), asOf),
+		Body:    fmt.Sprintf("Attached is the scheduled %s report as of %s.\n", reportTitle(schedule.Kind), asOf),
+		Attachments: []jobs.EmailAttachment{{
+			Filename:    file.Name,
+			ContentType: gen.FileType(job.Params).ContentType,
+			Content:     content,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	return j.service.MarkScheduleRun(ctx, schedule.ID, now)
+}
+
+// reportTitle turns a kind such as ap_aging into "AP aging".
+func reportTitle(kind Kind) string {
+	words := strings.Split(string(kind), "_")
+	if len(words[0]) <= 2 {
+		words[0] = strings.ToUpper(words[0])
+	} else {
+		words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// generate writes the report to a temporary file and renames it into place
+// once complete, so a crashed run never leaves a truncated download behind.
+func (j *Job) generate(ctx context.Context, job Report, gen Generator) (GeneratedFile, error) {
+	dir := j.storageDir
+	if strings.TrimSpace(dir) == "" {
+		dir = filepath.Join(os.TempDir(), "reports")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return GeneratedFile{}, err
+	}
+	name := fmt.Sprintf("%s-%d.%s", strings.ReplaceAll(string(job.Kind), "_", "-"), job.ID, gen.FileType(job.Params).Extension)
+	path := filepath.Join(dir, name)
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return GeneratedFile{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	var rows int64
+	report := j.service.Progress(ctx, job.ID)
+	progress := func(done, total int64) {
+		rows = done
+		report(done, total)
+	}
+	buf := bufio.NewWriter(tmp)
+	if err := gen.Generate(ctx, job.Params, buf, progress); err != nil {
+		tmp.Close()
+		return GeneratedFile{}, err
+	}
+	if err := buf.Flush(); err != nil {
+		tmp.Close()
+		return GeneratedFile{}, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return GeneratedFile{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return GeneratedFile{}, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return GeneratedFile{}, err
+	}
+	return GeneratedFile{Path: path, Name: name, Size: info.Size(), Rows: rows}, nil
+}
@@ -0,0 +1,138 @@
+package reportjob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const jobColumns = `id, kind, params, status, progress, rows_written, COALESCE(file_path, ''), COALESCE(file_name, ''),
+       COALESCE(file_size, 0), COALESCE(error_message, ''), COALESCE(requested_by, 0), created_at,
+       started_at, finished_at, expires_at`
+
+// Repository persists report jobs.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs a repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Insert stores a pending job.
+func (r *Repository) Insert(ctx context.Context, kind Kind, params Params, requestedBy int64) (Report, error) {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return Report{}, err
+	}
+	row := r.pool.QueryRow(ctx, `INSERT INTO report_jobs (kind, params, requested_by)
+VALUES ($1, $2, NULLIF($3, 0))
+RETURNING `+jobColumns, string(kind), payload, requestedBy)
+	return scanReport(row)
+}
+
+// Get loads a job by id.
+func (r *Repository) Get(ctx context.Context, id int64) (Report, error) {
+	return scanReport(r.pool.QueryRow(ctx, `SELECT `+jobColumns+` FROM report_jobs WHERE id = $1`, id))
+}
+
+// MarkInProgress claims a pending or failed job, or one whose worker started
+// it before staleBefore and never finished. It reports false when the job was
+// not claimable, e.g. because another worker is generating it.
+func (r *Repository) MarkInProgress(ctx context.Context, id int64, staleBefore time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE report_jobs
+SET status = 'IN_PROGRESS', started_at = NOW(), error_message = NULL
+WHERE id = $1
+  AND (status IN ('PENDING', 'FAILED') OR (status = 'IN_PROGRESS' AND started_at < $2))`, id, staleBefore)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UpdateProgress records rows written and the completion percentage.
+func (r *Repository) UpdateProgress(ctx context.Context, id int64, progress int, rows int64) error {
+	_, err := r.pool.Exec(ctx, `UPDATE report_jobs SET progress = $2, rows_written = $3
+WHERE id = $1 AND status = 'IN_PROGRESS'`, id, progress, rows)
+	return err
+}
+
+// MarkReady stores the generated file and its expiry.
+func (r *Repository) MarkReady(ctx context.Context, id int64, file GeneratedFile, expiresAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE report_jobs
+SET status = 'READY', progress = 100, rows_written = $2, file_path = $3, file_name = $4, file_size = $5,
+    finished_at = NOW(), expires_at = $6
+WHERE id = $1`, id, file.Rows, file.Path, file.Name, file.Size, expiresAt)
+	return err
+}
+
+// MarkFailed records the failure message.
+func (r *Repository) MarkFailed(ctx context.Context, id int64, msg string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE report_jobs SET status = 'FAILED', error_message = $2, finished_at = NOW()
+WHERE id = $1`, id, truncateError(msg))
+	return err
+}
+
+// ListExpired returns ready jobs whose files expired at or before now.
+func (r *Repository) ListExpired(ctx context.Context, now time.Time, limit int) ([]Report, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+jobColumns+` FROM report_jobs
+WHERE status = 'READY' AND expires_at <= $1
+ORDER BY expires_at
+LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var jobs []Report
+	for rows.Next() {
+		job, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// MarkExpired flags a job whose file has been removed.
+func (r *Repository) MarkExpired(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx, `UPDATE report_jobs SET status = 'EXPIRED', file_path = NULL WHERE id = $1`, id)
+	return err
+}
+
+func scanReport(row pgx.Row) (Report, error) {
+	var (
+		job    Report
+		kind   string
+		status string
+		params []byte
+	)
+	if err := row.Scan(&job.ID, &kind, &params, &status, &job.Progress, &job.RowsWritten, &job.FilePath, &job.FileName,
+		&job.FileSize, &job.ErrorMessage, &job.RequestedBy, &job.CreatedAt, &job.StartedAt, &job.FinishedAt, &job.ExpiresAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Report{}, ErrJobNotFound
+		}
+		return Report{}, err
+	}
+	job.Kind = Kind(kind)
+	job.Status = Status(status)
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &job.Params); err != nil {
+			return Report{}, err
+		}
+	}
+	return job, nil
+}
+
+func truncateError(msg string) string {
+	const max = 1000
+	if len(msg) > max {
+		return msg[:max]
+	}
+	return msg
+}
@@ -0,0 +1,85 @@
+package reportjob
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const scheduleColumns = `id, kind, params, frequency, day, recipients, is_active, last_run_on,
+       COALESCE(created_by, 0), created_at`
+
+// InsertSchedule stores a schedule.
+func (r *Repository) InsertSchedule(ctx context.Context, schedule Schedule) (Schedule, error) {
+	payload, err := json.Marshal(schedule.Params)
+	if err != nil {
+		return Schedule{}, err
+	}
+	row := r.pool.QueryRow(ctx, `INSERT INTO report_schedules (kind, params, frequency, day, recipients, is_active, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0))
+RETURNING `+scheduleColumns,
+		string(schedule.Kind), payload, string(schedule.Frequency), schedule.Day, schedule.Recipients, schedule.Active, schedule.CreatedBy)
+	return scanSchedule(row)
+}
+
+// ListSchedules returns schedules, active ones first, optionally only the
+// active ones.
+func (r *Repository) ListSchedules(ctx context.Context, activeOnly bool) ([]Schedule, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+scheduleColumns+` FROM report_schedules
+WHERE is_active OR NOT $1
+ORDER BY is_active DESC, id`, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schedules []Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// DeactivateSchedule switches a schedule off.
+func (r *Repository) DeactivateSchedule(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE report_schedules SET is_active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// MarkScheduleRun records the date a schedule last sent its report.
+func (r *Repository) MarkScheduleRun(ctx context.Context, id int64, on time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE report_schedules SET last_run_on = $2 WHERE id = $1`, id, on.Format("2006-01-02"))
+	return err
+}
+
+func scanSchedule(row pgx.Row) (Schedule, error) {
+	var (
+		schedule  Schedule
+		kind      string
+		frequency string
+		params    []byte
+	)
+	if err := row.Scan(&schedule.ID, &kind, &params, &frequency, &schedule.Day, &schedule.Recipients, &schedule.Active,
+		&schedule.LastRunOn, &schedule.CreatedBy, &schedule.CreatedAt); err != nil {
+		return Schedule{}, err
+	}
+	schedule.Kind = Kind(kind)
+	schedule.Frequency = Frequency(frequency)
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &schedule.Params); err != nil {
+			return Schedule{}, err
+		}
+	}
+	return schedule, nil
+}
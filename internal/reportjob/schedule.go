@@ -0,0 +1,177 @@
+package reportjob
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Frequency is how often a report schedule runs.
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+// Schedule generates a report on a cadence and emails it to recipients. Day
+// is the ISO weekday (1 Monday to 7 Sunday) for weekly schedules and the day
+// of the month (1-28) for monthly ones. Each run reports as of its own date.
+type Schedule struct {
+	ID         int64      `json:"id"`
+	Kind       Kind       `json:"kind"`
+	Params     Params     `json:"params"`
+	Frequency  Frequency  `json:"frequency"`
+	Day        int        `json:"day,omitempty"`
+	Recipients []string   `json:"recipients"`
+	Active     bool       `json:"active"`
+	LastRunOn  *time.Time `json:"last_run_on,omitempty"`
+	CreatedBy  int64      `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// DueOn reports whether the schedule should run on day's date and has not
+// run on it yet.
+func (s Schedule) DueOn(day time.Time) bool {
+	if !s.Active {
+		return false
+	}
+	if s.LastRunOn != nil && s.LastRunOn.Format("2006-01-02") == day.Format("2006-01-02") {
+		return false
+	}
+	switch s.Frequency {
+	case FrequencyDaily:
+		return true
+	case FrequencyWeekly:
+		return isoWeekday(day) == s.Day
+	case FrequencyMonthly:
+		return day.Day() == s.Day
+	}
+	return false
+}
+
+// runParams returns the params of a run on day.
+func (s Schedule) runParams(day time.Time) Params {
+	params := Params{}
+	for key, value := range s.Params {
+		params[key] = value
+	}
+	params["as_of"] = day.Format("2006-01-02")
+	return params
+}
+
+func (s Schedule) validate() error {
+	switch s.Frequency {
+	case FrequencyDaily:
+	case FrequencyWeekly:
+		if s.Day < 1 || s.Day > 7 {
+			return fmt.Errorf("%w: weekly day must be between 1 (Monday) and 7 (Sunday)", ErrInvalidSchedule)
+		}
+	case FrequencyMonthly:
+		if s.Day < 1 || s.Day > 28 {
+			return fmt.Errorf("%w: day of month must be between 1 and 28", ErrInvalidSchedule)
+		}
+	default:
+		return fmt.Errorf("%w: frequency must be DAILY, WEEKLY or MONTHLY", ErrInvalidSchedule)
+	}
+	if len(s.Recipients) == 0 {
+		return fmt.Errorf("%w: at least one recipient is required", ErrInvalidSchedule)
+	}
+	for _, email := range s.Recipients {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return fmt.Errorf("%w: invalid recipient %q", ErrInvalidSchedule, email)
+		}
+	}
+	return nil
+}
+
+// CreateSchedule validates and stores an active schedule. The params must be
+// valid for the kind once as_of is filled in; as_of itself is set per run.
+func (s *Service) CreateSchedule(ctx context.Context, schedule Schedule) (Schedule, error) {
+	g, ok := s.generators[Kind(strings.TrimSpace(string(schedule.Kind)))]
+	if !ok {
+		return Schedule{}, ErrUnknownKind
+	}
+	schedule.Kind = g.Kind
+	schedule.Frequency = Frequency(strings.ToUpper(strings.TrimSpace(string(schedule.Frequency))))
+	if schedule.Frequency == FrequencyDaily {
+		schedule.Day = 0
+	}
+	if err := schedule.validate(); err != nil {
+		return Schedule{}, err
+	}
+	params := Params{}
+	for key, value := range schedule.Params {
+		if key != "as_of" {
+			params[key] = value
+		}
+	}
+	schedule.Params = params
+	if g.Validate != nil {
+		if err := g.Validate(schedule.runParams(s.now())); err != nil {
+			return Schedule{}, err
+		}
+	}
+	schedule.Active = true
+	return s.repo.InsertSchedule(ctx, schedule)
+}
+
+// ListSchedules returns every schedule, active ones first.
+func (s *Service) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	return s.repo.ListSchedules(ctx, false)
+}
+
+// DeactivateSchedule stops a schedule from running. Reports already sent are
+// unaffected.
+func (s *Service) DeactivateSchedule(ctx context.Context, id int64) error {
+	return s.repo.DeactivateSchedule(ctx, id)
+}
+
+// DueSchedules returns the active schedules that should run on now's date.
+func (s *Service) DueSchedules(ctx context.Context, now time.Time) ([]Schedule, error) {
+	schedules, err := s.repo.ListSchedules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	var due []Schedule
+	for _, schedule := range schedules {
+		if schedule.DueOn(now) {
+			due = append(due, schedule)
+		}
+	}
+	return due, nil
+}
+
+// MarkScheduleRun records that a schedule sent its report on day.
+func (s *Service) MarkScheduleRun(ctx context.Context, id int64, day time.Time) error {
+	return s.repo.MarkScheduleRun(ctx, id, day)
+}
+
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+// parseRecipients splits a free-form recipient list on commas, semicolons
+// and whitespace, dropping duplicates.
+func parseRecipients(raw string) []string {
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+	out := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		email := strings.ToLower(strings.TrimSpace(field))
+		if _, dup := seen[email]; dup || email == "" {
+			continue
+		}
+		seen[email] = struct{}{}
+		out = append(out, email)
+	}
+	return out
+}
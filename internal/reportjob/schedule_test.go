@@ -0,0 +1,147 @@
+package reportjob
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/ap"
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+type stubPayables struct{}
+
+func (stubPayables) APAgingReport(_ context.Context, asOf time.Time) (ap.APAgingReport, error) {
+	return ap.APAgingReport{
+		AsOf:      asOf,
+		Summary:   ap.APAgingBucket{Current: 100, Bucket60: 250},
+		Suppliers: []ap.APAgingDetail{{SupplierID: 3, SupplierName: "Acme Supplies", Current: 100, Bucket60: 250, Total: 350}},
+	}, nil
+}
+
+type stubReceivables struct{}
+
+func (stubReceivables) ARAgingReport(_ context.Context, asOf time.Time) (ar.ARAgingReport, error) {
+	return ar.ARAgingReport{AsOf: asOf}, nil
+}
+
+type stubRenderer struct{ html string }
+
+func (r *stubRenderer) RenderHTML(_ context.Context, html string) ([]byte, error) {
+	r.html = html
+	return []byte("%PDF-1.7"), nil
+}
+
+type recordingMailer struct{ sent []jobs.SendEmailPayload }
+
+func (m *recordingMailer) Send(_ context.Context, payload jobs.SendEmailPayload) error {
+	m.sent = append(m.sent, payload)
+	return nil
+}
+
+func TestScheduleDueOn(t *testing.T) {
+	wednesday := time.Date(2026, 10, 14, 7, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		schedule Schedule
+		want     bool
+	}{
+		{"daily", Schedule{Active: true, Frequency: FrequencyDaily}, true},
+		{"inactive", Schedule{Frequency: FrequencyDaily}, false},
+		{"weekly on wednesday", Schedule{Active: true, Frequency: FrequencyWeekly, Day: 3}, true},
+		{"weekly on sunday", Schedule{Active: true, Frequency: FrequencyWeekly, Day: 7}, false},
+		{"monthly on the 14th", Schedule{Active: true, Frequency: FrequencyMonthly, Day: 14}, true},
+		{"monthly on the 1st", Schedule{Active: true, Frequency: FrequencyMonthly, Day: 1}, false},
+		{"already ran today", Schedule{Active: true, Frequency: FrequencyDaily, LastRunOn: &wednesday}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.schedule.DueOn(wednesday); got != tc.want {
+			t.Fatalf("%s: expected due %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestCreateScheduleValidates(t *testing.T) {
+	svc := NewService(newStubRepo(), AgingGenerators(stubPayables{}, stubReceivables{}, nil)...)
+	ctx := context.Background()
+
+	if _, err := svc.CreateSchedule(ctx, Schedule{Kind: KindAPAging, Frequency: "HOURLY", Recipients: []string{"ap@example.com"}}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("expected ErrInvalidSchedule for frequency, got %v", err)
+	}
+	if _, err := svc.CreateSchedule(ctx, Schedule{Kind: KindAPAging, Frequency: FrequencyWeekly, Day: 9, Recipients: []string{"ap@example.com"}}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("expected ErrInvalidSchedule for weekday, got %v", err)
+	}
+	if _, err := svc.CreateSchedule(ctx, Schedule{Kind: KindAPAging, Frequency: FrequencyDaily, Recipients: []string{"finance"}}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("expected ErrInvalidSchedule for recipient, got %v", err)
+	}
+	if _, err := svc.CreateSchedule(ctx, Schedule{Kind: KindAPAging, Frequency: FrequencyDaily, Params: Params{"format": "csv"}, Recipients: []string{"ap@example.com"}}); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expected ErrInvalidParams for format, got %v", err)
+	}
+	schedule, err := svc.CreateSchedule(ctx, Schedule{
+		Kind:       KindARAging,
+		Frequency:  "monthly",
+		Day:        1,
+		Params:     Params{"as_of": "2026-01-31", "format": "pdf"},
+		Recipients: parseRecipients("AR@example.com; cfo@example.com ar@example.com"),
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+	if !schedule.Active || schedule.Frequency != FrequencyMonthly || len(schedule.Recipients) != 2 {
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+	if _, ok := schedule.Params["as_of"]; ok {
+		t.Fatalf("as_of must be set per run, got params %v", schedule.Params)
+	}
+}
+
+func TestRunSchedulesEmailsAgingReport(t *testing.T) {
+	repo := newStubRepo()
+	renderer := &stubRenderer{}
+	svc := NewService(repo, AgingGenerators(stubPayables{}, stubReceivables{}, renderer)...)
+	mailer := &recordingMailer{}
+	job := NewJob(JobConfig{Service: svc, StorageDir: t.TempDir(), Mailer: mailer})
+	ctx := context.Background()
+
+	if _, err := svc.CreateSchedule(ctx, Schedule{Kind: KindAPAging, Frequency: FrequencyDaily, Recipients: []string{"ap@example.com", "cfo@example.com"}, CreatedBy: 4}); err != nil {
+		t.Fatalf("CreateSchedule xlsx: %v", err)
+	}
+	if _, err := svc.CreateSchedule(ctx, Schedule{Kind: KindAPAging, Frequency: FrequencyWeekly, Day: 1, Params: Params{"format": "pdf"}, Recipients: []string{"treasury@example.com"}}); err != nil {
+		t.Fatalf("CreateSchedule pdf: %v", err)
+	}
+
+	monday := time.Date(2026, 10, 12, 7, 0, 0, 0, time.UTC)
+	sent, err := job.RunSchedules(ctx, monday)
+	if err != nil || sent != 2 {
+		t.Fatalf("expected 2 scheduled reports, got %d (%v)", sent, err)
+	}
+	xlsxMail := mailer.sent[0]
+	if xlsxMail.To != "ap@example.com" || len(xlsxMail.Cc) != 1 || xlsxMail.Subject != "AP aging report as of 2026-10-12" {
+		t.Fatalf("unexpected email %+v", xlsxMail)
+	}
+	if att := xlsxMail.Attachments[0]; att.Filename != "ap-aging-1.xlsx" || !strings.HasPrefix(string(att.Content), "PK") {
+		t.Fatalf("expected xlsx attachment, got %s (%d bytes)", att.Filename, len(att.Content))
+	}
+	pdfMail := mailer.sent[1]
+	if att := pdfMail.Attachments[0]; att.Filename != "ap-aging-2.pdf" || att.ContentType != "application/pdf" {
+		t.Fatalf("expected pdf attachment, got %+v", att.Filename)
+	}
+	if !strings.Contains(renderer.html, "Acme Supplies") || !strings.Contains(renderer.html, "12 Oct 2026") {
+		t.Fatalf("pdf html missing supplier or as-of date:\n%s", renderer.html)
+	}
+	if report, _ := svc.Get(ctx, 1); report.Status != StatusReady || report.Params["as_of"] != "2026-10-12" || report.RequestedBy != 4 {
+		t.Fatalf("unexpected scheduled report %+v", report)
+	}
+
+	sent, err = job.RunSchedules(ctx, monday.Add(time.Hour))
+	if err != nil || sent != 0 {
+		t.Fatalf("schedules already sent today must be skipped, got %d (%v)", sent, err)
+	}
+	sent, err = job.RunSchedules(ctx, monday.AddDate(0, 0, 1))
+	if err != nil || sent != 1 || len(mailer.sent) != 3 {
+		t.Fatalf("expected only the daily schedule on tuesday, got %d (%v)", sent, err)
+	}
+}
@@ -0,0 +1,164 @@
+package reportjob
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is how long generated files stay downloadable when no TTL is
+// configured.
+const DefaultTTL = 24 * time.Hour
+
+// DefaultLease is how long a job may stay in progress before another worker
+// may take it over, e.g. after the worker generating it crashed.
+const DefaultLease = 30 * time.Minute
+
+// cleanupBatch bounds how many expired jobs one cleanup run handles.
+const cleanupBatch = 500
+
+// RepositoryPort describes the persistence the service relies on.
+type RepositoryPort interface {
+	Insert(ctx context.Context, kind Kind, params Params, requestedBy int64) (Report, error)
+	Get(ctx context.Context, id int64) (Report, error)
+	MarkInProgress(ctx context.Context, id int64, staleBefore time.Time) (bool, error)
+	UpdateProgress(ctx context.Context, id int64, progress int, rows int64) error
+	MarkReady(ctx context.Context, id int64, file GeneratedFile, expiresAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, msg string) error
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]Report, error)
+	MarkExpired(ctx context.Context, id int64) error
+	InsertSchedule(ctx context.Context, schedule Schedule) (Schedule, error)
+	ListSchedules(ctx context.Context, activeOnly bool) ([]Schedule, error)
+	DeactivateSchedule(ctx context.Context, id int64) error
+	MarkScheduleRun(ctx context.Context, id int64, on time.Time) error
+}
+
+// GeneratedFile describes a report written to storage.
+type GeneratedFile struct {
+	Path string
+	Name string
+	Size int64
+	Rows int64
+}
+
+// Service coordinates report job requests, progress, and expiry.
+type Service struct {
+	repo       RepositoryPort
+	generators map[Kind]Generator
+	ttl        time.Duration
+	lease      time.Duration
+	now        func() time.Time
+}
+
+// NewService constructs the service with the generators it can run.
+func NewService(repo RepositoryPort, generators ...Generator) *Service {
+	s := &Service{repo: repo, generators: make(map[Kind]Generator, len(generators)), ttl: DefaultTTL, lease: DefaultLease, now: time.Now}
+	for _, g := range generators {
+		s.generators[g.Kind] = g
+	}
+	return s
+}
+
+// SetTTL overrides how long generated files are kept.
+func (s *Service) SetTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.ttl = ttl
+	}
+}
+
+// SetLease overrides how long a job may stay in progress before it can be
+// claimed again.
+func (s *Service) SetLease(lease time.Duration) {
+	if lease > 0 {
+		s.lease = lease
+	}
+}
+
+// Generator returns the generator registered for kind.
+func (s *Service) Generator(kind Kind) (Generator, bool) {
+	g, ok := s.generators[kind]
+	return g, ok
+}
+
+// Request validates and stores a new pending job. The caller enqueues it.
+func (s *Service) Request(ctx context.Context, kind Kind, params Params, requestedBy int64) (Report, error) {
+	g, ok := s.generators[Kind(strings.TrimSpace(string(kind)))]
+	if !ok {
+		return Report{}, ErrUnknownKind
+	}
+	if params == nil {
+		params = Params{}
+	}
+	if g.Validate != nil {
+		if err := g.Validate(params); err != nil {
+			return Report{}, err
+		}
+	}
+	return s.repo.Insert(ctx, g.Kind, params, requestedBy)
+}
+
+// Get loads a job.
+func (s *Service) Get(ctx context.Context, id int64) (Report, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Claim moves a job to in-progress. It reports false when the job is not
+// runnable, e.g. finished or claimed less than a lease ago.
+func (s *Service) Claim(ctx context.Context, id int64) (bool, error) {
+	return s.repo.MarkInProgress(ctx, id, s.now().Add(-s.lease))
+}
+
+// Progress returns a ProgressFunc that records progress for the job. Updates
+// are best effort; a failed write never aborts the generation.
+func (s *Service) Progress(ctx context.Context, id int64) ProgressFunc {
+	return func(done, total int64) {
+		_ = s.repo.UpdateProgress(ctx, id, progressPercent(done, total), done)
+	}
+}
+
+// Complete marks the job ready and starts its expiry clock.
+func (s *Service) Complete(ctx context.Context, id int64, file GeneratedFile) error {
+	return s.repo.MarkReady(ctx, id, file, s.now().Add(s.ttl))
+}
+
+// Fail records a generation error.
+func (s *Service) Fail(ctx context.Context, id int64, cause error) error {
+	return s.repo.MarkFailed(ctx, id, cause.Error())
+}
+
+// CleanupExpired removes the files of expired jobs and marks them expired.
+// Files already gone are treated as removed.
+func (s *Service) CleanupExpired(ctx context.Context) (int, error) {
+	jobs, err := s.repo.ListExpired(ctx, s.now(), cleanupBatch)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, job := range jobs {
+		if job.FilePath != "" {
+			if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+				return removed, fmt.Errorf("remove report %d: %w", job.ID, err)
+			}
+		}
+		if err := s.repo.MarkExpired(ctx, job.ID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// progressPercent converts rows into a percentage. Unknown totals report 0
+// and the value is capped at 99 so only Complete shows 100.
+func progressPercent(done, total int64) int {
+	if total <= 0 || done <= 0 {
+		return 0
+	}
+	pct := int(done * 100 / total)
+	if pct > 99 {
+		pct = 99
+	}
+	return pct
+}
@@ -0,0 +1,250 @@
+package reportjob
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/accounting/journals"
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+type stubRepo struct {
+	reports   map[int64]*Report
+	progress  []int
+	schedules map[int64]*Schedule
+}
+
+func newStubRepo() *stubRepo {
+	return &stubRepo{reports: map[int64]*Report{}, schedules: map[int64]*Schedule{}}
+}
+
+func (s *stubRepo) Insert(_ context.Context, kind Kind, params Params, requestedBy int64) (Report, error) {
+	id := int64(len(s.reports) + 1)
+	s.reports[id] = &Report{ID: id, Kind: kind, Params: params, Status: StatusPending, RequestedBy: requestedBy}
+	return *s.reports[id], nil
+}
+
+func (s *stubRepo) Get(_ context.Context, id int64) (Report, error) {
+	r, ok := s.reports[id]
+	if !ok {
+		return Report{}, ErrJobNotFound
+	}
+	return *r, nil
+}
+
+func (s *stubRepo) MarkInProgress(_ context.Context, id int64, staleBefore time.Time) (bool, error) {
+	r := s.reports[id]
+	stale := r.Status == StatusInProgress && r.StartedAt != nil && r.StartedAt.Before(staleBefore)
+	if r.Status != StatusPending && r.Status != StatusFailed && !stale {
+		return false, nil
+	}
+	r.Status = StatusInProgress
+	return true, nil
+}
+
+func (s *stubRepo) UpdateProgress(_ context.Context, id int64, progress int, rows int64) error {
+	s.progress = append(s.progress, progress)
+	s.reports[id].RowsWritten = rows
+	return nil
+}
+
+func (s *stubRepo) MarkReady(_ context.Context, id int64, file GeneratedFile, expiresAt time.Time) error {
+	r := s.reports[id]
+	r.Status, r.Progress, r.FilePath, r.FileName, r.FileSize, r.RowsWritten = StatusReady, 100, file.Path, file.Name, file.Size, file.Rows
+	r.ExpiresAt = &expiresAt
+	return nil
+}
+
+func (s *stubRepo) MarkFailed(_ context.Context, id int64, msg string) error {
+	s.reports[id].Status = StatusFailed
+	s.reports[id].ErrorMessage = msg
+	return nil
+}
+
+func (s *stubRepo) ListExpired(_ context.Context, now time.Time, _ int) ([]Report, error) {
+	var out []Report
+	for _, r := range s.reports {
+		if r.Status == StatusReady && r.ExpiresAt != nil && !now.Before(*r.ExpiresAt) {
+			out = append(out, *r)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubRepo) MarkExpired(_ context.Context, id int64) error {
+	s.reports[id].Status = StatusExpired
+	s.reports[id].FilePath = ""
+	return nil
+}
+
+func (s *stubRepo) InsertSchedule(_ context.Context, schedule Schedule) (Schedule, error) {
+	schedule.ID = int64(len(s.schedules) + 1)
+	s.schedules[schedule.ID] = &schedule
+	return schedule, nil
+}
+
+func (s *stubRepo) ListSchedules(_ context.Context, activeOnly bool) ([]Schedule, error) {
+	var out []Schedule
+	for id := int64(1); id <= int64(len(s.schedules)); id++ {
+		if schedule := s.schedules[id]; schedule.Active || !activeOnly {
+			out = append(out, *schedule)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubRepo) DeactivateSchedule(_ context.Context, id int64) error {
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return ErrScheduleNotFound
+	}
+	schedule.Active = false
+	return nil
+}
+
+func (s *stubRepo) MarkScheduleRun(_ context.Context, id int64, on time.Time) error {
+	s.schedules[id].LastRunOn = &on
+	return nil
+}
+
+type stubLines struct{ pages []journals.JournalLinePage }
+
+func (s *stubLines) ListLines(_ context.Context, req journals.ListLinesRequest) (journals.JournalLinePage, error) {
+	if req.Cursor == "" {
+		return s.pages[0], nil
+	}
+	return s.pages[1], nil
+}
+
+type stubValuation struct{ lines int }
+
+func (s stubValuation) ValuationAsOf(_ context.Context, filter inventory.ValuationFilter) (inventory.ValuationReport, error) {
+	report := inventory.ValuationReport{AsOf: filter.AsOf}
+	for i := 0; i < s.lines; i++ {
+		report.Lines = append(report.Lines, inventory.ValuationLine{WarehouseCode: "WH", SKU: "SKU", Qty: 1, AvgCost: 2, Value: 2})
+	}
+	return report, nil
+}
+
+func TestRequestValidatesKindAndParams(t *testing.T) {
+	svc := NewService(newStubRepo(), DefaultGenerators(&stubLines{}, stubValuation{})...)
+	ctx := context.Background()
+
+	if _, err := svc.Request(ctx, "payroll", nil, 1); !errors.Is(err, ErrUnknownKind) {
+		t.Fatalf("expected ErrUnknownKind, got %v", err)
+	}
+	if _, err := svc.Request(ctx, KindInventoryValuation, Params{"as_of": "16/10/2026"}, 1); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expected ErrInvalidParams for bad as_of, got %v", err)
+	}
+	if _, err := svc.Request(ctx, KindGLExtract, Params{"period_id": "abc"}, 1); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expected ErrInvalidParams for bad period, got %v", err)
+	}
+	report, err := svc.Request(ctx, KindGLExtract, Params{"company_id": "3"}, 7)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if report.Status != StatusPending || report.RequestedBy != 7 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	filter, err := valuationFilter(Params{"as_of": "2026-10-16", "company_id": "2"})
+	if err != nil || filter.CompanyID != 2 {
+		t.Fatalf("expected the valuation limited to company 2, got %+v (%v)", filter, err)
+	}
+}
+
+func TestJobGeneratesFileAndCleanupExpiresIt(t *testing.T) {
+	repo := newStubRepo()
+	lines := &stubLines{pages: []journals.JournalLinePage{
+		{Lines: []journals.JournalLineItem{{ID: 9, JournalNumber: 4, AccountCode: "1100", Debit: 10}}, NextCursor: "next"},
+		{Lines: []journals.JournalLineItem{{ID: 8, JournalNumber: 4, AccountCode: "4000", Credit: 10}}},
+	}}
+	svc := NewService(repo, DefaultGenerators(lines, stubValuation{lines: 2500})...)
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	svc.SetTTL(time.Hour)
+	dir := t.TempDir()
+	job := NewJob(JobConfig{Service: svc, StorageDir: dir})
+	ctx := context.Background()
+
+	gl, _ := svc.Request(ctx, KindGLExtract, nil, 1)
+	task, _ := jobs.NewReportJobTask(gl.ID)
+	if err := job.Handle(ctx, task); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	ready, _ := svc.Get(ctx, gl.ID)
+	if ready.Status != StatusReady || ready.RowsWritten != 2 || ready.FileName != "gl-extract-1.csv" {
+		t.Fatalf("unexpected ready report %+v", ready)
+	}
+	body, err := os.ReadFile(ready.FilePath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	if got := strings.Count(string(body), "\n"); got != 3 {
+		t.Fatalf("expected header and 2 lines, got %d lines:\n%s", got, body)
+	}
+	if err := job.Handle(ctx, task); err != nil {
+		t.Fatalf("re-delivered task must be a no-op, got %v", err)
+	}
+
+	repo.progress = nil
+	inv, _ := svc.Request(ctx, KindInventoryValuation, Params{"as_of": "2026-10-16"}, 1)
+	task, _ = jobs.NewReportJobTask(inv.ID)
+	if err := job.Handle(ctx, task); err != nil {
+		t.Fatalf("Handle valuation: %v", err)
+	}
+	if len(repo.progress) != 3 || repo.progress[0] != 40 || repo.progress[2] != 99 {
+		t.Fatalf("expected throttled progress 40, 80, 99, got %v", repo.progress)
+	}
+
+	if err := ready.Downloadable(now); err != nil {
+		t.Fatalf("expected report downloadable before expiry, got %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if err := ready.Downloadable(now); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired after TTL, got %v", err)
+	}
+	removed, err := svc.CleanupExpired(ctx)
+	if err != nil || removed != 2 {
+		t.Fatalf("expected 2 expired reports, got %d (%v)", removed, err)
+	}
+	if _, err := os.Stat(ready.FilePath); !os.IsNotExist(err) {
+		t.Fatalf("expected file removed, got %v", err)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(entries) != 0 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestJobTakesOverAStaleLease(t *testing.T) {
+	repo := newStubRepo()
+	lines := &stubLines{pages: []journals.JournalLinePage{{Lines: []journals.JournalLineItem{{ID: 1, AccountCode: "1100", Debit: 10}}}}}
+	svc := NewService(repo, DefaultGenerators(lines, stubValuation{})...)
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	job := NewJob(JobConfig{Service: svc, StorageDir: t.TempDir()})
+	ctx := context.Background()
+
+	// The worker generating the report crashed ten minutes in.
+	gl, _ := svc.Request(ctx, KindGLExtract, nil, 1)
+	started := now.Add(-10 * time.Minute)
+	repo.reports[gl.ID].Status = StatusInProgress
+	repo.reports[gl.ID].StartedAt = &started
+	task, _ := jobs.NewReportJobTask(gl.ID)
+
+	if err := job.Handle(ctx, task); !errors.Is(err, ErrJobBusy) {
+		t.Fatalf("expected ErrJobBusy while the lease holds, got %v", err)
+	}
+	now = now.Add(DefaultLease)
+	if err := job.Handle(ctx, task); err != nil {
+		t.Fatalf("Handle after the lease expired: %v", err)
+	}
+	if ready, _ := svc.Get(ctx, gl.ID); ready.Status != StatusReady {
+		t.Fatalf("expected the stale job to be regenerated, got %s", ready.Status)
+	}
+}
@@ -48,7 +48,7 @@ func (h *Handler) listRoles(w http.ResponseWriter, r *http.Request) {
 
 	roles, err := h.service.ListRoles(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list roles failed", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "list roles failed", slog.Any("error", err))
 		h.render(w, r, "pages/roles/list.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusInternalServerError)
 		return
 	}
@@ -64,7 +64,7 @@ func (h *Handler) showCreateRoleForm(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) createRole(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		h.logger.Error("parse form", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "parse form", slog.Any("error", err))
 		h.render(w, r, "pages/roles/form.html", map[string]any{"Errors": formErrors{"general": "Invalid request"}}, http.StatusBadRequest)
 		return
 	}
@@ -74,7 +74,7 @@ func (h *Handler) createRole(w http.ResponseWriter, r *http.Request) {
 
 	_, err := h.service.CreateRole(r.Context(), name, description)
 	if err != nil {
-		h.logger.Error("create role failed", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "create role failed", slog.Any("error", err))
 		h.render(w, r, "pages/roles/form.html", map[string]any{
 			"Errors": formErrors{"general": shared.UserSafeMessage(err)},
 			"Role":   map[string]string{"Name": name, "Description": description},
@@ -95,7 +95,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	viewData := view.TemplateData{Title: "Roles", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
 	}
 }
 
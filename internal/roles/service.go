@@ -2,6 +2,9 @@ package roles
 
 import (
 	"context"
+	"strconv"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 // RepositoryPort defines data access methods for roles.
@@ -10,9 +13,15 @@ type RepositoryPort interface {
 	CreateRole(ctx context.Context, name, description string) (Role, error)
 }
 
+// AuditPort records role changes.
+type AuditPort interface {
+	Record(ctx context.Context, log shared.AuditLog) error
+}
+
 // Service handles role business logic.
 type Service struct {
-	repo RepositoryPort
+	repo  RepositoryPort
+	audit AuditPort
 }
 
 // NewService builds Service instance.
@@ -20,6 +29,11 @@ func NewService(repo RepositoryPort) *Service {
 	return &Service{repo: repo}
 }
 
+// SetAuditor enables audit entries for created roles.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
+}
+
 // ListRoles returns all roles.
 func (s *Service) ListRoles(ctx context.Context, filters RoleListFilters) ([]Role, error) {
 	return s.repo.ListRoles(ctx, filters)
@@ -27,5 +41,19 @@ func (s *Service) ListRoles(ctx context.Context, filters RoleListFilters) ([]Rol
 
 // CreateRole inserts a new role.
 func (s *Service) CreateRole(ctx context.Context, name, description string) (Role, error) {
-	return s.repo.CreateRole(ctx, name, description)
+	role, err := s.repo.CreateRole(ctx, name, description)
+	if err != nil {
+		return Role{}, err
+	}
+	if s.audit != nil {
+		_ = s.audit.Record(ctx, shared.AuditLog{
+			ActorID:  shared.AuditActorFromContext(ctx),
+			Action:   "role.create",
+			Entity:   shared.AuditEntityRole,
+			EntityID: strconv.FormatInt(role.ID, 10),
+			Meta:     map[string]any{"name": role.Name},
+			After:    map[string]any{"name": role.Name, "description": role.Description},
+		})
+	}
+	return role, nil
 }
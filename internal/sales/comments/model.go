@@ -0,0 +1,55 @@
+package comments
+
+import (
+	"errors"
+	"time"
+)
+
+// DocumentType identifies the sales document a comment belongs to.
+type DocumentType string
+
+const (
+	DocumentQuotation  DocumentType = "QUOTATION"
+	DocumentSalesOrder DocumentType = "SALES_ORDER"
+)
+
+// MaxMessageLength caps a single comment.
+const MaxMessageLength = 2000
+
+var (
+	ErrEmptyMessage    = errors.New("comment message is required")
+	ErrMessageTooLong  = errors.New("comment message is too long")
+	ErrDocumentClosed  = errors.New("comments are closed for this document")
+	ErrAuthorRequired  = errors.New("comment author is required")
+	ErrUnknownDocument = errors.New("unknown document type")
+)
+
+// Comment is a message left on a quotation or sales order. Comments never
+// change the document status.
+type Comment struct {
+	ID           int64        `json:"id"`
+	DocumentType DocumentType `json:"document_type"`
+	DocumentID   int64        `json:"document_id"`
+	AuthorID     int64        `json:"author_id"`
+	AuthorName   string       `json:"author_name"`
+	Message      string       `json:"message"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// Document describes the commented record, as needed to gate and announce a
+// comment.
+type Document struct {
+	Type      DocumentType
+	ID        int64
+	Number    string
+	CreatedBy int64
+	// Closed is set for terminal statuses, where the thread is read-only.
+	Closed bool
+}
+
+// Thread is the view model for the comment partial on detail pages.
+type Thread struct {
+	Comments []Comment
+	Action   string
+	Open     bool
+}
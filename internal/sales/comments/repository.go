@@ -0,0 +1,62 @@
+package comments
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository persists document comments.
+type Repository interface {
+	Insert(ctx context.Context, comment Comment) (Comment, error)
+	List(ctx context.Context, docType DocumentType, docID int64) ([]Comment, error)
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs the pgx-backed comment repository.
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repository{pool: pool}
+}
+
+func (r *repository) Insert(ctx context.Context, c Comment) (Comment, error) {
+	err := r.pool.QueryRow(ctx, `
+		WITH inserted AS (
+			INSERT INTO sales_document_comments (document_type, document_id, author_id, message)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, author_id, created_at
+		)
+		SELECT i.id, COALESCE(NULLIF(u.name, ''), u.email, ''), i.created_at
+		FROM inserted i
+		LEFT JOIN users u ON u.id = i.author_id
+	`, string(c.DocumentType), c.DocumentID, c.AuthorID, c.Message).Scan(&c.ID, &c.AuthorName, &c.CreatedAt)
+	return c, err
+}
+
+func (r *repository) List(ctx context.Context, docType DocumentType, docID int64) ([]Comment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.id, c.document_type, c.document_id, c.author_id,
+		       COALESCE(NULLIF(u.name, ''), u.email, ''), c.message, c.created_at
+		FROM sales_document_comments c
+		LEFT JOIN users u ON u.id = c.author_id
+		WHERE c.document_type = $1 AND c.document_id = $2
+		ORDER BY c.created_at, c.id
+	`, string(docType), docID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Comment
+	for rows.Next() {
+		var c Comment
+		var t string
+		if err := rows.Scan(&c.ID, &t, &c.DocumentID, &c.AuthorID, &c.AuthorName, &c.Message, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.DocumentType = DocumentType(t)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
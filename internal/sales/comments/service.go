@@ -0,0 +1,109 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// Service manages comment threads on quotations and sales orders.
+type Service struct {
+	repo     Repository
+	notifier coreshared.ApprovalNotifier
+}
+
+// NewService constructs the comment service.
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// SetNotifier injects the notifier that emails document creators about new
+// comments.
+func (s *Service) SetNotifier(notifier coreshared.ApprovalNotifier) {
+	s.notifier = notifier
+}
+
+// List returns the thread for a document, oldest first.
+func (s *Service) List(ctx context.Context, docType DocumentType, docID int64) ([]Comment, error) {
+	return s.repo.List(ctx, docType, docID)
+}
+
+// Thread loads the comments of doc for display on its detail page; action is
+// the URL the comment form posts to.
+func (s *Service) Thread(ctx context.Context, doc Document, action string) (Thread, error) {
+	list, err := s.repo.List(ctx, doc.Type, doc.ID)
+	if err != nil {
+		return Thread{}, err
+	}
+	return Thread{Comments: list, Action: action, Open: !doc.Closed}, nil
+}
+
+// Add stores a comment on an open document and notifies its creator.
+func (s *Service) Add(ctx context.Context, doc Document, authorID int64, message string) (Comment, error) {
+	message = strings.TrimSpace(message)
+	switch {
+	case authorID == 0:
+		return Comment{}, ErrAuthorRequired
+	case message == "":
+		return Comment{}, ErrEmptyMessage
+	case utf8.RuneCountInString(message) > MaxMessageLength:
+		return Comment{}, ErrMessageTooLong
+	case doc.Closed:
+		return Comment{}, ErrDocumentClosed
+	}
+	label, path, err := describe(doc)
+	if err != nil {
+		return Comment{}, err
+	}
+	comment, err := s.repo.Insert(ctx, Comment{
+		DocumentType: doc.Type,
+		DocumentID:   doc.ID,
+		AuthorID:     authorID,
+		Message:      message,
+	})
+	if err != nil {
+		return Comment{}, fmt.Errorf("insert comment: %w", err)
+	}
+	comment.DocumentType, comment.DocumentID, comment.AuthorID, comment.Message = doc.Type, doc.ID, authorID, message
+
+	// Delivery problems never fail the comment.
+	if s.notifier != nil {
+		_ = s.notifier.NotifyApproval(ctx, coreshared.ApprovalNotice{
+			Action:         coreshared.ApprovalComment,
+			Module:         string(doc.Type),
+			DocumentLabel:  label,
+			DocumentNumber: doc.Number,
+			Path:           path,
+			ActorID:        authorID,
+			RequesterID:    doc.CreatedBy,
+			Comment:        message,
+			CommentAuthor:  comment.AuthorName,
+		})
+	}
+	return comment, nil
+}
+
+// UserMessage returns the flash text for an Add error.
+func UserMessage(err error) string {
+	for _, known := range []error{ErrEmptyMessage, ErrMessageTooLong, ErrDocumentClosed, ErrAuthorRequired} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return coreshared.UserSafeMessage(err)
+}
+
+func describe(doc Document) (label, path string, err error) {
+	switch doc.Type {
+	case DocumentQuotation:
+		return "Quotation", fmt.Sprintf("/sales/quotations/%d", doc.ID), nil
+	case DocumentSalesOrder:
+		return "Sales Order", fmt.Sprintf("/sales/orders/%d", doc.ID), nil
+	default:
+		return "", "", ErrUnknownDocument
+	}
+}
@@ -0,0 +1,90 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type memoryRepo struct {
+	comments []Comment
+}
+
+func (m *memoryRepo) Insert(_ context.Context, c Comment) (Comment, error) {
+	c.ID = int64(len(m.comments) + 1)
+	c.AuthorName = "User"
+	m.comments = append(m.comments, c)
+	return c, nil
+}
+
+func (m *memoryRepo) List(_ context.Context, docType DocumentType, docID int64) ([]Comment, error) {
+	var out []Comment
+	for _, c := range m.comments {
+		if c.DocumentType == docType && c.DocumentID == docID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+type recordingNotifier struct {
+	notices []coreshared.ApprovalNotice
+}
+
+func (r *recordingNotifier) NotifyApproval(_ context.Context, notice coreshared.ApprovalNotice) error {
+	r.notices = append(r.notices, notice)
+	return errors.New("smtp down")
+}
+
+func TestAddStoresCommentAndNotifiesCreator(t *testing.T) {
+	repo := &memoryRepo{}
+	notifier := &recordingNotifier{}
+	svc := NewService(repo)
+	svc.SetNotifier(notifier)
+	doc := Document{Type: DocumentQuotation, ID: 9, Number: "QT-009", CreatedBy: 4}
+
+	comment, err := svc.Add(context.Background(), doc, 2, "  Please confirm the discount  ")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if comment.Message != "Please confirm the discount" || comment.AuthorID != 2 {
+		t.Fatalf("unexpected comment %+v", comment)
+	}
+	if len(notifier.notices) != 1 {
+		t.Fatalf("expected one notice, got %d", len(notifier.notices))
+	}
+	notice := notifier.notices[0]
+	if notice.Action != coreshared.ApprovalComment || notice.RequesterID != 4 || notice.ActorID != 2 || notice.Path != "/sales/quotations/9" {
+		t.Fatalf("unexpected notice %+v", notice)
+	}
+
+	thread, err := svc.Thread(context.Background(), doc, "/sales/quotations/9/comments")
+	if err != nil || len(thread.Comments) != 1 || !thread.Open {
+		t.Fatalf("unexpected thread %+v (%v)", thread, err)
+	}
+}
+
+func TestAddRejectsInvalidComments(t *testing.T) {
+	svc := NewService(&memoryRepo{})
+	open := Document{Type: DocumentSalesOrder, ID: 3}
+	cases := []struct {
+		doc     Document
+		author  int64
+		message string
+		want    error
+	}{
+		{open, 0, "hello", ErrAuthorRequired},
+		{open, 1, "   ", ErrEmptyMessage},
+		{open, 1, strings.Repeat("x", MaxMessageLength+1), ErrMessageTooLong},
+		{Document{Type: DocumentSalesOrder, ID: 3, Closed: true}, 1, "hello", ErrDocumentClosed},
+		{Document{Type: "INVOICE", ID: 3}, 1, "hello", ErrUnknownDocument},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Add(context.Background(), tc.doc, tc.author, tc.message); !errors.Is(err, tc.want) {
+			t.Errorf("Add(%+v, %q): expected %v, got %v", tc.doc, tc.message, tc.want, err)
+		}
+	}
+}
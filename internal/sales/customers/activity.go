@@ -0,0 +1,17 @@
+package customers
+
+import (
+	"context"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// Activity returns one page of the customer's document timeline.
+func (s *Service) Activity(ctx context.Context, customerID int64, page, perPage int) (shared.ActivityPage, error) {
+	page, perPage, limit, offset := shared.ActivityWindow(page, perPage)
+	events, err := s.repo.ListActivity(ctx, customerID, limit, offset)
+	if err != nil {
+		return shared.ActivityPage{}, err
+	}
+	return shared.NewActivityPage(events, page, perPage), nil
+}
@@ -0,0 +1,253 @@
+package customers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCreditHold         = errors.New("customer is on credit hold")
+	ErrNoActiveCreditHold = errors.New("customer has no active credit hold")
+)
+
+// creditTolerance absorbs float rounding when comparing exposure amounts.
+const creditTolerance = 0.005
+
+// CreditPolicy decides when a customer is automatically put on credit hold.
+type CreditPolicy struct {
+	// OverdueDays is the age past due date at which an open invoice triggers a hold.
+	OverdueDays int
+	// UtilizationLimit is the exposure to credit limit ratio that triggers a hold.
+	UtilizationLimit float64
+}
+
+// DefaultCreditPolicy holds customers with invoices 60+ days overdue or
+// exposure above their credit limit.
+var DefaultCreditPolicy = CreditPolicy{OverdueDays: 60, UtilizationLimit: 1.0}
+
+// OpenInvoice is a posted AR invoice with an unpaid balance.
+type OpenInvoice struct {
+	InvoiceID int64
+	Number    string
+	DueAt     time.Time
+	Balance   float64
+}
+
+// CreditExposure is what the customer currently owes or has committed to.
+type CreditExposure struct {
+	CustomerID   int64
+	CreditLimit  float64
+	OpenInvoices []OpenInvoice
+	OpenOrders   float64
+}
+
+// CreditHoldInvoice is an overdue invoice that triggered a hold.
+type CreditHoldInvoice struct {
+	InvoiceID   int64
+	Number      string
+	DueAt       time.Time
+	Balance     float64
+	DaysOverdue int
+}
+
+// CreditHold records a customer being blocked from new order confirmations.
+type CreditHold struct {
+	ID          int64
+	CustomerID  int64
+	Reason      string
+	CreditLimit float64
+	Exposure    float64
+	PlacedBy    *int64
+	PlacedAt    time.Time
+	ReleasedBy  *int64
+	ReleasedAt  *time.Time
+	ReleaseNote string
+	Invoices    []CreditHoldInvoice
+}
+
+// Active reports whether the hold still blocks confirmations.
+func (h *CreditHold) Active() bool {
+	return h != nil && h.ReleasedAt == nil
+}
+
+// CreditFinding is the outcome of evaluating a customer's credit position.
+type CreditFinding struct {
+	Reasons     []string
+	Exposure    float64
+	Utilization float64
+	Invoices    []CreditHoldInvoice
+}
+
+// Triggered reports whether the customer should be put on hold.
+func (f CreditFinding) Triggered() bool {
+	return len(f.Reasons) > 0
+}
+
+// CoveredBy reports whether a previously released hold already acknowledged
+// this finding: no new overdue invoices and no higher exposure. A release is
+// therefore not undone until the customer's position gets worse.
+func (f CreditFinding) CoveredBy(released *CreditHold) bool {
+	if released == nil || released.ReleasedAt == nil {
+		return false
+	}
+	known := make(map[int64]struct{}, len(released.Invoices))
+	for _, inv := range released.Invoices {
+		known[inv.InvoiceID] = struct{}{}
+	}
+	for _, inv := range f.Invoices {
+		if _, ok := known[inv.InvoiceID]; !ok {
+			return false
+		}
+	}
+	return f.Exposure <= released.Exposure+creditTolerance
+}
+
+// EvaluateCredit checks overdue invoices and credit utilization as of the
+// given time. pendingAmount is an order about to be committed; it counts
+// towards utilization. A zero credit limit disables the utilization check.
+func EvaluateCredit(policy CreditPolicy, exposure CreditExposure, pendingAmount float64, asOf time.Time) CreditFinding {
+	var finding CreditFinding
+	total := exposure.OpenOrders + pendingAmount
+	for _, inv := range exposure.OpenInvoices {
+		total += inv.Balance
+		days := int(asOf.Sub(inv.DueAt).Hours() / 24)
+		if policy.OverdueDays > 0 && days >= policy.OverdueDays && inv.Balance > creditTolerance {
+			finding.Invoices = append(finding.Invoices, CreditHoldInvoice{
+				InvoiceID:   inv.InvoiceID,
+				Number:      inv.Number,
+				DueAt:       inv.DueAt,
+				Balance:     inv.Balance,
+				DaysOverdue: days,
+			})
+		}
+	}
+	finding.Exposure = math.Round(total*100) / 100
+
+	if len(finding.Invoices) > 0 {
+		var overdue float64
+		for _, inv := range finding.Invoices {
+			overdue += inv.Balance
+		}
+		finding.Reasons = append(finding.Reasons, fmt.Sprintf("%d invoice(s) overdue %d+ days totalling %.2f", len(finding.Invoices), policy.OverdueDays, overdue))
+	}
+	if exposure.CreditLimit > 0 && policy.UtilizationLimit > 0 {
+		finding.Utilization = finding.Exposure / exposure.CreditLimit
+		if finding.Exposure > exposure.CreditLimit*policy.UtilizationLimit+creditTolerance {
+			finding.Reasons = append(finding.Reasons, fmt.Sprintf("exposure %.2f exceeds credit limit %.2f (%.0f%% utilized)", finding.Exposure, exposure.CreditLimit, finding.Utilization*100))
+		}
+	}
+	return finding
+}
+
+// SetCreditPolicy overrides the default credit hold policy.
+func (s *Service) SetCreditPolicy(policy CreditPolicy) {
+	s.policy = policy
+}
+
+// ActiveCreditHold returns the customer's active hold, or nil when none.
+func (s *Service) ActiveCreditHold(ctx context.Context, customerID int64) (*CreditHold, error) {
+	return s.repo.GetActiveCreditHold(ctx, customerID)
+}
+
+// EvaluateCreditHold places the customer on credit hold when overdue AR or
+// credit utilization (including pendingAmount) breaches the policy. It returns
+// the active hold, existing or new, or nil when the customer is clear.
+func (s *Service) EvaluateCreditHold(ctx context.Context, customerID int64, pendingAmount float64, actorID int64) (*CreditHold, error) {
+	var hold *CreditHold
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		active, err := repo.GetActiveCreditHold(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if active != nil {
+			hold = active
+			return nil
+		}
+		exposure, err := repo.GetCreditExposure(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		finding := EvaluateCredit(s.policy, exposure, pendingAmount, s.now())
+		if !finding.Triggered() {
+			return nil
+		}
+		released, err := repo.GetLatestReleasedCreditHold(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if finding.CoveredBy(released) {
+			return nil
+		}
+		placed := CreditHold{
+			CustomerID:  customerID,
+			Reason:      strings.Join(finding.Reasons, "; "),
+			CreditLimit: exposure.CreditLimit,
+			Exposure:    finding.Exposure,
+			PlacedAt:    s.now(),
+			Invoices:    finding.Invoices,
+		}
+		if actorID > 0 {
+			placed.PlacedBy = &actorID
+		}
+		id, err := repo.CreateCreditHold(ctx, placed)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			hold, err = repo.GetActiveCreditHold(ctx, customerID)
+			return err
+		}
+		placed.ID = id
+		hold = &placed
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("evaluate credit hold: %w", err)
+	}
+	return hold, nil
+}
+
+// ScanCreditHolds evaluates every active customer with open receivables and
+// returns the IDs of customers newly put on hold.
+func (s *Service) ScanCreditHolds(ctx context.Context) ([]int64, error) {
+	ids, err := s.repo.ListCreditHoldCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var held []int64
+	for _, id := range ids {
+		active, err := s.repo.GetActiveCreditHold(ctx, id)
+		if err != nil {
+			return held, err
+		}
+		if active != nil {
+			continue
+		}
+		hold, err := s.EvaluateCreditHold(ctx, id, 0, 0)
+		if err != nil {
+			return held, err
+		}
+		if hold != nil {
+			held = append(held, id)
+		}
+	}
+	return held, nil
+}
+
+// ReleaseCreditHold clears the customer's active hold.
+func (s *Service) ReleaseCreditHold(ctx context.Context, customerID, userID int64, note string) error {
+	return s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		active, err := repo.GetActiveCreditHold(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if active == nil {
+			return ErrNoActiveCreditHold
+		}
+		return repo.ReleaseCreditHold(ctx, active.ID, userID, strings.TrimSpace(note))
+	})
+}
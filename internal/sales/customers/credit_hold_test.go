@@ -0,0 +1,72 @@
+package customers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvaluateCreditFlagsOverdueInvoices(t *testing.T) {
+	asOf := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	exposure := CreditExposure{
+		CustomerID: 1,
+		OpenInvoices: []OpenInvoice{
+			{InvoiceID: 1, Number: "INV-1", DueAt: asOf.AddDate(0, 0, -75), Balance: 400},
+			{InvoiceID: 2, Number: "INV-2", DueAt: asOf.AddDate(0, 0, -10), Balance: 100},
+		},
+	}
+	finding := EvaluateCredit(DefaultCreditPolicy, exposure, 0, asOf)
+	if !finding.Triggered() {
+		t.Fatal("expected overdue invoice to trigger a hold")
+	}
+	if len(finding.Invoices) != 1 || finding.Invoices[0].InvoiceID != 1 || finding.Invoices[0].DaysOverdue != 75 {
+		t.Fatalf("unexpected triggering invoices %+v", finding.Invoices)
+	}
+	if finding.Exposure != 500 {
+		t.Fatalf("expected exposure 500, got %v", finding.Exposure)
+	}
+}
+
+func TestEvaluateCreditUtilization(t *testing.T) {
+	asOf := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	exposure := CreditExposure{
+		CreditLimit:  1000,
+		OpenInvoices: []OpenInvoice{{InvoiceID: 1, DueAt: asOf.AddDate(0, 0, 5), Balance: 600}},
+		OpenOrders:   300,
+	}
+	if finding := EvaluateCredit(DefaultCreditPolicy, exposure, 100, asOf); finding.Triggered() {
+		t.Fatalf("exposure at the limit should not trigger, got %v", finding.Reasons)
+	}
+	finding := EvaluateCredit(DefaultCreditPolicy, exposure, 150, asOf)
+	if !finding.Triggered() || len(finding.Invoices) != 0 {
+		t.Fatalf("expected utilization-only hold, got %+v", finding)
+	}
+
+	exposure.CreditLimit = 0
+	if finding := EvaluateCredit(DefaultCreditPolicy, exposure, 1e6, asOf); finding.Triggered() {
+		t.Fatal("zero credit limit should disable the utilization check")
+	}
+}
+
+func TestCreditFindingCoveredByRelease(t *testing.T) {
+	released := time.Now()
+	hold := &CreditHold{
+		Exposure:   500,
+		ReleasedAt: &released,
+		Invoices:   []CreditHoldInvoice{{InvoiceID: 1}},
+	}
+	same := CreditFinding{Reasons: []string{"overdue"}, Exposure: 450, Invoices: []CreditHoldInvoice{{InvoiceID: 1}}}
+	if !same.CoveredBy(hold) {
+		t.Fatal("finding already acknowledged by the release should be covered")
+	}
+	newInvoice := CreditFinding{Reasons: []string{"overdue"}, Exposure: 450, Invoices: []CreditHoldInvoice{{InvoiceID: 1}, {InvoiceID: 2}}}
+	if newInvoice.CoveredBy(hold) {
+		t.Fatal("a newly overdue invoice should trigger a new hold")
+	}
+	higher := CreditFinding{Reasons: []string{"limit"}, Exposure: 800}
+	if higher.CoveredBy(hold) {
+		t.Fatal("higher exposure should trigger a new hold")
+	}
+	if same.CoveredBy(nil) {
+		t.Fatal("no released hold should never cover a finding")
+	}
+}
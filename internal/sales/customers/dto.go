@@ -1,5 +1,7 @@
 package customers
 
+import "github.com/odyssey-erp/odyssey-erp/internal/shared"
+
 type CreateCustomerRequest struct {
 	Code             string  `json:"code" validate:"required,max=50"`
 	Name             string  `json:"name" validate:"required,max=200"`
@@ -16,6 +18,22 @@ type CreateCustomerRequest struct {
 	PostalCode       *string `json:"postal_code,omitempty" validate:"omitempty,max=20"`
 	Country          string  `json:"country" validate:"required,len=2"`
 	Notes            *string `json:"notes,omitempty"`
+	// Language of the customer's documents; empty uses the default language.
+	Language string `json:"language,omitempty"`
+	// DefaultTaxID is the output tax prefilled on new document lines.
+	DefaultTaxID *int64 `json:"default_tax_id,omitempty"`
+	// TaxIDOverride skips the country tax ID format check, e.g. for foreign entities.
+	TaxIDOverride bool `json:"tax_id_override,omitempty"`
+	// ConfirmDuplicate creates the customer even when similar ones exist.
+	ConfirmDuplicate bool `json:"confirm_duplicate,omitempty"`
+}
+
+// ValidateTaxID checks the tax ID against the format configured for Country.
+func (r CreateCustomerRequest) ValidateTaxID(v *shared.TaxIDValidator) error {
+	if r.TaxID == nil || r.TaxIDOverride {
+		return nil
+	}
+	return v.Validate(r.Country, *r.TaxID)
 }
 
 type UpdateCustomerRequest struct {
@@ -33,6 +51,32 @@ type UpdateCustomerRequest struct {
 	Country          *string  `json:"country,omitempty" validate:"omitempty,len=2"`
 	IsActive         *bool    `json:"is_active,omitempty"`
 	Notes            *string  `json:"notes,omitempty"`
+	// Language of the customer's documents; an empty string resets it to the
+	// default language.
+	Language *string `json:"language,omitempty"`
+	// DefaultTaxID replaces the default output tax; zero clears it.
+	DefaultTaxID *int64 `json:"default_tax_id,omitempty"`
+	// TaxIDOverride skips the country tax ID format check, e.g. for foreign entities.
+	TaxIDOverride bool `json:"tax_id_override,omitempty"`
+}
+
+// ValidateTaxID checks the resulting tax ID and country of existing after the
+// update against the configured format. It only runs when either changes.
+func (r UpdateCustomerRequest) ValidateTaxID(v *shared.TaxIDValidator, existing *Customer) error {
+	if r.TaxIDOverride || (r.TaxID == nil && r.Country == nil) {
+		return nil
+	}
+	country, taxID := existing.Country, ""
+	if existing.TaxID != nil {
+		taxID = *existing.TaxID
+	}
+	if r.Country != nil {
+		country = *r.Country
+	}
+	if r.TaxID != nil {
+		taxID = *r.TaxID
+	}
+	return v.Validate(country, taxID)
 }
 
 type ListCustomersRequest struct {
@@ -0,0 +1,40 @@
+package customers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func TestCreateCustomerRequestValidateTaxID(t *testing.T) {
+	v := shared.DefaultTaxIDValidator()
+	bad := "12-345"
+	req := CreateCustomerRequest{Country: "ID", TaxID: &bad}
+	if err := req.ValidateTaxID(v); !errors.Is(err, shared.ErrInvalidTaxID) {
+		t.Fatalf("expected invalid NPWP to be rejected, got %v", err)
+	}
+	req.TaxIDOverride = true
+	if err := req.ValidateTaxID(v); err != nil {
+		t.Fatalf("override must skip the check, got %v", err)
+	}
+}
+
+func TestUpdateCustomerRequestValidateTaxIDUsesExisting(t *testing.T) {
+	v := shared.DefaultTaxIDValidator()
+	foreign := "SG-2024-77"
+	existing := &Customer{Country: "SG", TaxID: &foreign}
+
+	name := "Renamed"
+	if err := (UpdateCustomerRequest{Name: &name}).ValidateTaxID(v, existing); err != nil {
+		t.Fatalf("unrelated updates must not revalidate, got %v", err)
+	}
+	country := "ID"
+	if err := (UpdateCustomerRequest{Country: &country}).ValidateTaxID(v, existing); !errors.Is(err, shared.ErrInvalidTaxID) {
+		t.Fatalf("moving to ID must validate the existing tax ID, got %v", err)
+	}
+	npwp := "12.345.678.9-012.345"
+	if err := (UpdateCustomerRequest{Country: &country, TaxID: &npwp}).ValidateTaxID(v, existing); err != nil {
+		t.Fatalf("expected valid NPWP to pass, got %v", err)
+	}
+}
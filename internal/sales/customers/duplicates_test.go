@@ -0,0 +1,64 @@
+package customers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// duplicateRepo implements only what Create touches; other methods panic via
+// the nil embedded interface.
+type duplicateRepo struct {
+	Repository
+	similar []shared.DuplicateCandidate
+	taxID   string
+	created int
+}
+
+func (r *duplicateRepo) GetByCode(context.Context, int64, string) (*Customer, error) {
+	return nil, ErrNotFound
+}
+
+func (r *duplicateRepo) FindSimilar(_ context.Context, _ int64, _, taxID, _ string, _ float64, _ int) ([]shared.DuplicateCandidate, error) {
+	r.taxID = taxID
+	return r.similar, nil
+}
+
+func (r *duplicateRepo) WithTx(ctx context.Context, fn func(context.Context, Repository) error) error {
+	return fn(ctx, r)
+}
+
+func (r *duplicateRepo) Create(context.Context, Customer) (int64, error) {
+	r.created++
+	return 0, errors.New("stop after create")
+}
+
+func TestCreateWarnsAboutDuplicatesUntilConfirmed(t *testing.T) {
+	repo := &duplicateRepo{similar: []shared.DuplicateCandidate{{ID: 7, Name: "PT Maju Jaya", TaxID: "12.345.678.9-012.345", Similarity: 0.2}}}
+	svc := NewService(repo)
+	taxID := "12.345.678.9-012.345"
+	req := CreateCustomerRequest{Code: "C-2", Name: "Maju Jaya", CompanyID: 1, Country: "ID", TaxID: &taxID}
+
+	_, err := svc.Create(context.Background(), req, 1)
+	var dupErr *shared.DuplicateError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("expected DuplicateError, got %v", err)
+	}
+	if repo.taxID != "123456789012345" {
+		t.Fatalf("expected normalized tax ID lookup, got %q", repo.taxID)
+	}
+	if got := dupErr.Candidates[0].MatchedOn; len(got) != 1 || got[0] != "tax_id" {
+		t.Fatalf("expected tax_id match, got %v", got)
+	}
+	if repo.created != 0 {
+		t.Fatal("customer must not be created before confirmation")
+	}
+
+	req.ConfirmDuplicate = true
+	_, _ = svc.Create(context.Background(), req, 1)
+	if repo.created != 1 {
+		t.Fatal("confirmed create must proceed")
+	}
+}
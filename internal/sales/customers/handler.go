@@ -1,11 +1,14 @@
 package customers
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -66,7 +69,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	h.logger.Info("List customers request", "companyID", companyID)
+	h.logger.InfoContext(r.Context(), "List customers request", "companyID", companyID)
 	customers, total, err := h.service.List(r.Context(), ListCustomersRequest{
 		CompanyID: companyID,
 		IsActive:  isActive,
@@ -75,8 +78,23 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		Offset:    offset,
 	})
 	if err != nil {
-		h.logger.Error("list customers failed", "error", err)
-		http.Error(w, "Failed to load customers", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list customers failed", "error", err)
+		httpx.Error(w, r, "Failed to load customers", http.StatusInternalServerError)
+		return
+	}
+	if !h.canViewSensitive(r) {
+		for i := range customers {
+			customers[i].MaskSensitive()
+		}
+	}
+
+	if wantsJSON(r) {
+		httpx.JSON(w, http.StatusOK, map[string]any{
+			"customers": customers,
+			"total":     total,
+			"limit":     limit,
+			"offset":    offset,
+		})
 		return
 	}
 
@@ -95,20 +113,105 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid customer ID", http.StatusBadRequest)
 		return
 	}
 
 	customer, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get customer failed", "error", err, "id", id)
-		http.Error(w, "Customer not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get customer failed", "error", err, "id", id)
+		httpx.Error(w, r, "Customer not found", http.StatusNotFound)
 		return
 	}
 
-	h.render(w, r, "pages/sales/customer_detail.html", map[string]any{
-		"Customer": customer,
-	}, http.StatusOK)
+	creditHold, err := h.service.ActiveCreditHold(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get credit hold failed", "error", err, "id", id)
+	}
+	if !h.canViewSensitive(r) {
+		customer.MaskSensitive()
+		creditHold.MaskSensitive()
+	}
+
+	if wantsJSON(r) {
+		httpx.JSON(w, http.StatusOK, customer)
+		return
+	}
+
+	minOrder, err := h.service.MinOrderSetting(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get customer minimum order setting failed", "error", err, "id", id)
+	}
+
+	data := map[string]any{
+		"Customer":   customer,
+		"CreditHold": creditHold,
+		"MinOrder":   minOrder,
+		"Tab":        "details",
+	}
+	if r.URL.Query().Get("tab") == "activity" {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		activity, err := h.service.Activity(r.Context(), id, page, shared.ActivityPerPage)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "load customer activity failed", "error", err, "id", id)
+			httpx.Error(w, r, "Failed to load activity", http.StatusInternalServerError)
+			return
+		}
+		data["Tab"] = "activity"
+		data["Activity"] = activity
+		data["ActivityBase"] = "/sales/customers/" + strconv.FormatInt(id, 10)
+	}
+
+	h.render(w, r, "pages/sales/customer_detail.html", data, http.StatusOK)
+}
+
+// Activity returns one page of the customer's document timeline as JSON.
+func (h *Handler) Activity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Problem(w, http.StatusBadRequest, "Invalid customer", "customer id must be a number")
+		return
+	}
+	if _, err := h.service.Get(r.Context(), id); err != nil {
+		httpx.Problem(w, http.StatusNotFound, "Customer not found", "")
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	activity, err := h.service.Activity(r.Context(), id, page, perPage)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load customer activity failed", "error", err, "id", id)
+		httpx.Problem(w, http.StatusInternalServerError, "Failed to load activity", "")
+		return
+	}
+	httpx.JSON(w, http.StatusOK, activity)
+}
+
+// ReleaseCreditHold clears the customer's active credit hold so new orders
+// can be confirmed again.
+func (h *Handler) ReleaseCreditHold(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	url := "/sales/customers/" + strconv.FormatInt(id, 10)
+	err = h.service.ReleaseCreditHold(r.Context(), id, h.getCurrentUserID(r), r.PostFormValue("note"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "release credit hold failed", "error", err, "id", id)
+		message := shared.UserSafeMessage(err)
+		if errors.Is(err, ErrNoActiveCreditHold) {
+			message = err.Error()
+		}
+		h.redirectWithFlash(w, r, url, "error", message)
+		return
+	}
+	h.redirectWithFlash(w, r, url, "success", "Credit hold released")
 }
 
 func (h *Handler) ShowForm(w http.ResponseWriter, r *http.Request) {
@@ -117,7 +220,7 @@ func (h *Handler) ShowForm(w http.ResponseWriter, r *http.Request) {
 	// Generate customer code
 	code, err := h.service.GenerateCode(r.Context(), companyID)
 	if err != nil {
-		h.logger.Error("generate customer code failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "generate customer code failed", "error", err)
 		code = ""
 	}
 
@@ -125,12 +228,13 @@ func (h *Handler) ShowForm(w http.ResponseWriter, r *http.Request) {
 		"Errors":        formErrors{},
 		"GeneratedCode": code,
 		"Customer":      nil,
+		"Taxes":         h.salesTaxes(r),
 	}, http.StatusOK)
 }
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -148,6 +252,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		CreditLimit:      creditLimit,
 		PaymentTermsDays: paymentTerms,
 		Country:          r.PostFormValue("country"),
+		Language:         r.PostFormValue("language"),
+	}
+	if id, err := strconv.ParseInt(r.PostFormValue("default_tax_id"), 10, 64); err == nil && id > 0 {
+		req.DefaultTaxID = &id
 	}
 
 	// Optional fields
@@ -178,14 +286,22 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if notes := r.PostFormValue("notes"); notes != "" {
 		req.Notes = &notes
 	}
+	req.TaxIDOverride = r.PostFormValue("tax_id_override") == "true"
+	req.ConfirmDuplicate = r.PostFormValue("confirm_duplicate") == "true"
 
 	customer, err := h.service.Create(r.Context(), req, userID)
+	var dupErr *shared.DuplicateError
+	if errors.As(err, &dupErr) {
+		h.renderDuplicates(w, r, dupErr.Candidates)
+		return
+	}
 	if err != nil {
-		h.logger.Error("create customer failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create customer failed", "error", err)
 		h.render(w, r, "pages/sales/customer_form.html", map[string]any{
-			"Errors":        formErrors{"general": shared.UserSafeMessage(err)},
+			"Errors":        customerFormErrors(err),
 			"GeneratedCode": req.Code,
 			"Customer":      nil,
+			"Taxes":         h.salesTaxes(r),
 		}, http.StatusBadRequest)
 		return
 	}
@@ -196,32 +312,36 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ShowEditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid customer ID", http.StatusBadRequest)
 		return
 	}
 
 	customer, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get customer failed", "error", err, "id", id)
-		http.Error(w, "Customer not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get customer failed", "error", err, "id", id)
+		httpx.Error(w, r, "Customer not found", http.StatusNotFound)
 		return
 	}
+	if !h.canViewSensitive(r) {
+		customer.MaskSensitive()
+	}
 
 	h.render(w, r, "pages/sales/customer_form.html", map[string]any{
 		"Errors":   formErrors{},
 		"Customer": customer,
+		"Taxes":    h.salesTaxes(r),
 	}, http.StatusOK)
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid customer ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -268,6 +388,15 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	if country := r.PostFormValue("country"); country != "" {
 		req.Country = &country
 	}
+	if _, ok := r.PostForm["language"]; ok {
+		language := r.PostFormValue("language")
+		req.Language = &language
+	}
+	if _, ok := r.PostForm["default_tax_id"]; ok {
+		// A blank choice clears the default tax.
+		id, _ := strconv.ParseInt(r.PostFormValue("default_tax_id"), 10, 64)
+		req.DefaultTaxID = &id
+	}
 	if notes := r.PostFormValue("notes"); notes != "" {
 		req.Notes = &notes
 	}
@@ -275,13 +404,20 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		val := isActive == "true"
 		req.IsActive = &val
 	}
+	req.TaxIDOverride = r.PostFormValue("tax_id_override") == "true"
+
+	if !h.canViewSensitive(r) {
+		// The edit form never carried the real values, so never write them back.
+		req.TaxID, req.CreditLimit, req.Notes = nil, nil, nil
+	}
 
 	customer, err := h.service.Update(r.Context(), id, req)
 	if err != nil {
-		h.logger.Error("update customer failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "update customer failed", "error", err, "id", id)
 		h.render(w, r, "pages/sales/customer_form.html", map[string]any{
-			"Errors":   formErrors{"general": shared.UserSafeMessage(err)},
+			"Errors":   customerFormErrors(err),
 			"Customer": customer,
+			"Taxes":    h.salesTaxes(r),
 		}, http.StatusBadRequest)
 		return
 	}
@@ -309,8 +445,8 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, tmpl string, da
 
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, tmpl, viewData); err != nil {
-		h.logger.Error("template render failed", "error", err, "template", tmpl)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "template render failed", "error", err, "template", tmpl)
+		httpx.Error(w, r, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
@@ -322,6 +458,56 @@ func (h *Handler) redirectWithFlash(w http.ResponseWriter, r *http.Request, url,
 	http.Redirect(w, r, url, http.StatusSeeOther)
 }
 
+// salesTaxes loads the default tax choices of the customer form.
+func (h *Handler) salesTaxes(r *http.Request) []shared.TaxRate {
+	taxes, err := h.service.SalesTaxes(r.Context())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "list sales taxes failed", "error", err)
+	}
+	return taxes
+}
+
+// customerFormErrors keeps tax ID format errors visible next to the field.
+func customerFormErrors(err error) formErrors {
+	if errors.Is(err, shared.ErrInvalidTaxID) {
+		return formErrors{"general": err.Error(), "tax_id": err.Error()}
+	}
+	if errors.Is(err, ErrUnsupportedLanguage) {
+		return formErrors{"general": err.Error(), "language": err.Error()}
+	}
+	if errors.Is(err, ErrInvalidDefaultTax) {
+		return formErrors{"general": err.Error(), "default_tax_id": err.Error()}
+	}
+	return formErrors{"general": shared.UserSafeMessage(err)}
+}
+
+// renderDuplicates asks the user to open an existing customer or confirm the
+// create; the submitted form is carried along as hidden fields.
+func (h *Handler) renderDuplicates(w http.ResponseWriter, r *http.Request, candidates []shared.DuplicateCandidate) {
+	if !h.canViewSensitive(r) {
+		for i := range candidates {
+			if candidates[i].TaxID != "" {
+				candidates[i].TaxID = maskTaxID(candidates[i].TaxID)
+			}
+		}
+	}
+	h.render(w, r, "pages/sales/customer_duplicates.html", map[string]any{
+		"Candidates": candidates,
+		"Fields":     shared.ResubmitFields(r.PostForm, "confirm_duplicate"),
+	}, http.StatusConflict)
+}
+
+// canViewSensitive reports whether the caller may see unmasked credit limits,
+// tax IDs and notes.
+func (h *Handler) canViewSensitive(r *http.Request) bool {
+	return h.rbac.Allows(r, shared.PermCustomerViewSensitive)
+}
+
+// wantsJSON reports whether the client asked for a JSON representation.
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
 func (h *Handler) getCurrentUserID(r *http.Request) int64 {
 	sess := shared.SessionFromContext(r.Context())
 	if sess != nil {
@@ -341,5 +527,5 @@ func (h *Handler) getCurrentCompanyID(r *http.Request) int64 {
 			return id
 		}
 	}
-	return 1 // Default company for development
+	return shared.ScopedCompanyID(r.Context(), 0, 1) // Default company for development
 }
@@ -0,0 +1,48 @@
+package customers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// UpdateMinOrderSetting gives the customer its own minimum order value, or
+// exempts it from the company minimum.
+func (h *Handler) UpdateMinOrderSetting(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	url := "/sales/customers/" + strconv.FormatInt(id, 10)
+	setting := MinOrderSetting{CustomerID: id, Waived: r.PostFormValue("waived") != ""}
+	if raw := strings.TrimSpace(r.PostFormValue("min_order_value")); raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			h.redirectWithFlash(w, r, url, "error", "Invalid minimum order value")
+			return
+		}
+		setting.MinOrderValue = &value
+	}
+	if err := h.service.SetMinOrderSetting(r.Context(), setting); err != nil {
+		h.logger.ErrorContext(r.Context(), "update customer minimum order failed", "error", err, "id", id)
+		message := shared.UserSafeMessage(err)
+		if errors.Is(err, ErrInvalidMinOrderValue) {
+			message = err.Error()
+		}
+		h.redirectWithFlash(w, r, url, "error", message)
+		return
+	}
+	h.redirectWithFlash(w, r, url, "success", "Minimum order value updated")
+}
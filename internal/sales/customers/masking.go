@@ -0,0 +1,47 @@
+package customers
+
+import "strings"
+
+// maskedVisibleChars is how many trailing characters of a tax ID stay visible.
+const maskedVisibleChars = 4
+
+// MaskSensitive strips fields reserved for shared.PermCustomerViewSensitive
+// holders: the credit limit is zeroed, notes are dropped and the tax ID keeps
+// only its last few characters. SensitiveMasked tells templates and API
+// clients that the zero values are redacted rather than real.
+func (c *Customer) MaskSensitive() {
+	if c == nil {
+		return
+	}
+	c.CreditLimit = 0
+	c.Notes = nil
+	if c.TaxID != nil {
+		masked := maskTaxID(*c.TaxID)
+		c.TaxID = &masked
+	}
+	c.SensitiveMasked = true
+}
+
+// MaskSensitive removes the credit limit from a hold, including the
+// utilization reason that quotes it.
+func (h *CreditHold) MaskSensitive() {
+	if h == nil {
+		return
+	}
+	h.CreditLimit = 0
+	reasons := strings.Split(h.Reason, "; ")
+	for i, reason := range reasons {
+		if strings.Contains(reason, "credit limit") {
+			reasons[i] = "exposure exceeds credit limit"
+		}
+	}
+	h.Reason = strings.Join(reasons, "; ")
+}
+
+func maskTaxID(taxID string) string {
+	runes := []rune(taxID)
+	if len(runes) <= maskedVisibleChars {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-maskedVisibleChars) + string(runes[len(runes)-maskedVisibleChars:])
+}
@@ -0,0 +1,45 @@
+package customers
+
+import "testing"
+
+func TestCustomerMaskSensitive(t *testing.T) {
+	taxID := "01.234.567.8-901.000"
+	notes := "prefers invoices by post"
+	customer := Customer{ID: 1, Name: "Acme", TaxID: &taxID, CreditLimit: 5000, Notes: &notes}
+
+	customer.MaskSensitive()
+
+	if !customer.SensitiveMasked {
+		t.Fatal("expected customer to be flagged as masked")
+	}
+	if customer.CreditLimit != 0 || customer.Notes != nil {
+		t.Fatalf("expected credit limit and notes cleared, got %v %v", customer.CreditLimit, customer.Notes)
+	}
+	if customer.TaxID == nil || *customer.TaxID != "****************.000" {
+		t.Fatalf("unexpected masked tax ID %v", customer.TaxID)
+	}
+	if taxID != "01.234.567.8-901.000" {
+		t.Fatal("masking must not mutate the original tax ID")
+	}
+	if customer.Name != "Acme" {
+		t.Fatal("non-sensitive fields must be kept")
+	}
+}
+
+func TestCreditHoldMaskSensitive(t *testing.T) {
+	hold := CreditHold{
+		CreditLimit: 1000,
+		Exposure:    1500,
+		Reason:      "1 invoice(s) overdue 60+ days totalling 400.00; exposure 1500.00 exceeds credit limit 1000.00 (150% utilized)",
+	}
+
+	hold.MaskSensitive()
+
+	if hold.CreditLimit != 0 {
+		t.Fatalf("expected credit limit cleared, got %v", hold.CreditLimit)
+	}
+	want := "1 invoice(s) overdue 60+ days totalling 400.00; exposure exceeds credit limit"
+	if hold.Reason != want {
+		t.Fatalf("unexpected reason %q", hold.Reason)
+	}
+}
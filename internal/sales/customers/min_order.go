@@ -0,0 +1,64 @@
+package customers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ErrInvalidMinOrderValue is returned for a negative minimum order value.
+var ErrInvalidMinOrderValue = errors.New("minimum order value cannot be negative")
+
+// MinOrderSetting adjusts the company minimum order value for one customer.
+// A nil MinOrderValue keeps the company minimum; Waived exempts the customer
+// from any minimum.
+type MinOrderSetting struct {
+	CustomerID    int64    `json:"customer_id"`
+	MinOrderValue *float64 `json:"min_order_value,omitempty"`
+	Waived        bool     `json:"waived"`
+}
+
+// OwnMinimum returns the customer's own minimum, or zero without one.
+func (m MinOrderSetting) OwnMinimum() float64 {
+	if m.MinOrderValue == nil {
+		return 0
+	}
+	return *m.MinOrderValue
+}
+
+// HasOwnMinimum reports whether the customer replaces the company minimum.
+func (m MinOrderSetting) HasOwnMinimum() bool {
+	return m.MinOrderValue != nil
+}
+
+// MinOrderSetting returns the customer's minimum order setting.
+func (s *Service) MinOrderSetting(ctx context.Context, customerID int64) (MinOrderSetting, error) {
+	return s.repo.GetMinOrderSetting(ctx, customerID)
+}
+
+// SetMinOrderSetting stores the customer's own minimum order value or waiver.
+func (s *Service) SetMinOrderSetting(ctx context.Context, setting MinOrderSetting) error {
+	if setting.MinOrderValue != nil && *setting.MinOrderValue < 0 {
+		return ErrInvalidMinOrderValue
+	}
+	existing, err := s.repo.GetMinOrderSetting(ctx, setting.CustomerID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetMinOrderSetting(ctx, setting); err != nil {
+		return err
+	}
+	if s.audit != nil {
+		_ = s.audit.Record(ctx, shared.AuditLog{
+			ActorID:  shared.AuditActorFromContext(ctx),
+			Action:   "customer.min_order.update",
+			Entity:   "customers",
+			EntityID: strconv.FormatInt(setting.CustomerID, 10),
+			Before:   existing,
+			After:    setting,
+		})
+	}
+	return nil
+}
@@ -18,8 +18,14 @@ type Customer struct {
 	State            *string    `json:"state,omitempty" db:"state"`
 	PostalCode       *string    `json:"postal_code,omitempty" db:"postal_code"`
 	Country          string     `json:"country" db:"country"`
+	Language         string     `json:"language" db:"language"`
+	// DefaultTaxID is the output tax prefilled on the customer's quotation
+	// and sales order lines; NO-TAX marks a tax-exempt customer.
+	DefaultTaxID     *int64     `json:"default_tax_id,omitempty" db:"default_tax_id"`
+	DefaultTaxCode   string     `json:"default_tax_code,omitempty" db:"-"`
 	IsActive         bool       `json:"is_active" db:"is_active"`
 	Notes            *string    `json:"notes,omitempty" db:"notes"`
+	SensitiveMasked  bool       `json:"sensitive_masked,omitempty" db:"-"`
 	CreatedBy        int64      `json:"created_by" db:"created_by"`
 	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
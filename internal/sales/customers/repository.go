@@ -11,12 +11,19 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
 var (
 	ErrNotFound      = errors.New("record not found")
 	ErrAlreadyExists = errors.New("record already exists")
+	// ErrUnsupportedLanguage is returned for a document language without a
+	// translation catalog.
+	ErrUnsupportedLanguage = errors.New("unsupported document language")
+	// ErrInvalidDefaultTax is returned for a default tax that does not exist
+	// or is a withholding tax.
+	ErrInvalidDefaultTax = errors.New("default tax must be a sales tax, not a withholding tax")
 )
 
 type Repository interface {
@@ -27,6 +34,26 @@ type Repository interface {
 	Create(ctx context.Context, customer Customer) (int64, error)
 	Update(ctx context.Context, id int64, updates map[string]interface{}) error
 	GenerateCode(ctx context.Context, companyID int64) (string, error)
+	FindSimilar(ctx context.Context, companyID int64, name, taxID, phone string, minSimilarity float64, limit int) ([]shared.DuplicateCandidate, error)
+
+	// Credit hold operations
+	GetCreditExposure(ctx context.Context, customerID int64) (CreditExposure, error)
+	GetActiveCreditHold(ctx context.Context, customerID int64) (*CreditHold, error)
+	GetLatestReleasedCreditHold(ctx context.Context, customerID int64) (*CreditHold, error)
+	CreateCreditHold(ctx context.Context, hold CreditHold) (int64, error)
+	ReleaseCreditHold(ctx context.Context, holdID, userID int64, note string) error
+	ListCreditHoldCandidates(ctx context.Context) ([]int64, error)
+
+	// Minimum order value
+	GetMinOrderSetting(ctx context.Context, customerID int64) (MinOrderSetting, error)
+	SetMinOrderSetting(ctx context.Context, setting MinOrderSetting) error
+
+	// Activity timeline
+	ListActivity(ctx context.Context, customerID int64, limit, offset int) ([]shared.ActivityEvent, error)
+
+	// Default tax
+	ListSalesTaxes(ctx context.Context) ([]shared.TaxRate, error)
+	IsSalesTax(ctx context.Context, taxID int64) (bool, error)
 }
 
 type dbtx interface {
@@ -69,6 +96,15 @@ func (r *repository) Get(ctx context.Context, id int64) (*Customer, error) {
 		return nil, err
 	}
 	c := mapFromSqlc(row)
+	if !shared.CompanyAllowed(ctx, c.CompanyID) {
+		return nil, ErrNotFound
+	}
+	// default_tax_id postdates the sqlc customer queries.
+	if err := r.db.QueryRow(ctx, `SELECT c.default_tax_id, COALESCE(t.code, '')
+FROM customers c LEFT JOIN taxes t ON t.id = c.default_tax_id
+WHERE c.id = $1`, id).Scan(&c.DefaultTaxID, &c.DefaultTaxCode); err != nil {
+		return nil, err
+	}
 	return &c, nil
 }
 
@@ -130,7 +166,8 @@ func (r *repository) List(ctx context.Context, req ListCustomersRequest) ([]Cust
 		SELECT id, code, name, company_id, email, phone, tax_id,
 		       credit_limit, payment_terms_days, address_line1, address_line2,
 		       city, state, postal_code, country, is_active, notes,
-		       created_by, created_at, updated_at
+		       created_by, created_at, updated_at, language, default_tax_id,
+		       COALESCE((SELECT t.code FROM taxes t WHERE t.id = customers.default_tax_id), '')
 		FROM customers
 		%s
 		ORDER BY code
@@ -156,7 +193,8 @@ func (r *repository) List(ctx context.Context, req ListCustomersRequest) ([]Cust
 			&c.ID, &c.Code, &c.Name, &c.CompanyID, &email, &phone, &taxID,
 			&creditLimit, &c.PaymentTermsDays, &addr1, &addr2,
 			&city, &state, &postal, &c.Country, &c.IsActive, &notes,
-			&c.CreatedBy, &createdAt, &updatedAt,
+			&c.CreatedBy, &createdAt, &updatedAt, &c.Language, &c.DefaultTaxID,
+			&c.DefaultTaxCode,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -188,7 +226,7 @@ func (r *repository) Create(ctx context.Context, customer Customer) (int64, erro
 	var creditLimit pgtype.Numeric
 	creditLimit.Scan(fmt.Sprintf("%f", customer.CreditLimit))
 	
-	return r.queries.CreateCustomer(ctx, sqlc.CreateCustomerParams{
+	id, err := r.queries.CreateCustomer(ctx, sqlc.CreateCustomerParams{
 		Code:             customer.Code,
 		Name:             customer.Name,
 		CompanyID:        customer.CompanyID,
@@ -206,7 +244,13 @@ func (r *repository) Create(ctx context.Context, customer Customer) (int64, erro
 		IsActive:         customer.IsActive,
 		Notes:            pgtype.Text{String: getString(customer.Notes), Valid: customer.Notes != nil},
 		CreatedBy:        customer.CreatedBy,
+		Language:         customer.Language,
 	})
+	if err != nil || customer.DefaultTaxID == nil {
+		return id, err
+	}
+	_, err = r.db.Exec(ctx, "UPDATE customers SET default_tax_id = $2 WHERE id = $1", id, *customer.DefaultTaxID)
+	return id, err
 }
 
 func (r *repository) Update(ctx context.Context, id int64, updates map[string]interface{}) error {
@@ -284,6 +328,16 @@ func (r *repository) Update(ctx context.Context, id int64, updates map[string]in
 		args = append(args, v)
 		argPos++
 	}
+	if v, ok := updates["language"]; ok {
+		query += fmt.Sprintf(", language = $%d", argPos)
+		args = append(args, v)
+		argPos++
+	}
+	if v, ok := updates["default_tax_id"]; ok {
+		query += fmt.Sprintf(", default_tax_id = $%d", argPos)
+		args = append(args, v)
+		argPos++
+	}
 	
 	query += fmt.Sprintf(" WHERE id = $%d", argPos)
 	args = append(args, id)
@@ -316,6 +370,7 @@ func mapFromSqlc(row sqlc.Customer) Customer {
 		CompanyID:        row.CompanyID,
 		PaymentTermsDays: int(row.PaymentTermsDays),
 		Country:          row.Country,
+		Language:         row.Language,
 		IsActive:         row.IsActive,
 		CreatedBy:        row.CreatedBy,
 		CreatedAt:        row.CreatedAt.Time,
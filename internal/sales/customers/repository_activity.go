@@ -0,0 +1,60 @@
+package customers
+
+import (
+	"context"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ListActivity returns the customer's quotations, sales orders, deliveries,
+// AR invoices, payments and document comments, newest first.
+func (r *repository) ListActivity(ctx context.Context, customerID int64, limit, offset int) ([]shared.ActivityEvent, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT type, id, occurred_at, reference, status, amount, note, link_type, link_id
+		FROM (
+			SELECT 'QUOTATION' AS type, q.id, q.quote_date::timestamptz AS occurred_at, q.doc_number AS reference,
+			       q.status::text AS status, q.total_amount::float8 AS amount, ''::text AS note,
+			       'QUOTATION' AS link_type, q.id AS link_id
+			FROM quotations q
+			WHERE q.customer_id = $1
+			UNION ALL
+			SELECT 'SALES_ORDER', so.id, so.order_date::timestamptz, so.doc_number,
+			       so.status::text, so.total_amount::float8, '', 'SALES_ORDER', so.id
+			FROM sales_orders so
+			WHERE so.customer_id = $1
+			UNION ALL
+			SELECT 'DELIVERY_ORDER', d.id, d.delivery_date::timestamptz, d.doc_number,
+			       d.status::text, NULL::float8, '', 'DELIVERY_ORDER', d.id
+			FROM delivery_orders d
+			WHERE d.customer_id = $1
+			UNION ALL
+			SELECT 'AR_INVOICE', i.id, COALESCE(i.posted_at, i.created_at), i.number,
+			       i.status, i.total::float8, '', 'AR_INVOICE', i.id
+			FROM ar_invoices i
+			WHERE i.customer_id = $1
+			UNION ALL
+			SELECT 'AR_PAYMENT', p.id, p.paid_at, p.number, p.method, p.amount::float8, p.note,
+			       'AR_INVOICE', p.ar_invoice_id
+			FROM ar_payments p
+			WHERE EXISTS (
+				SELECT 1 FROM ar_invoices i
+				WHERE i.customer_id = $1
+				  AND (i.id = p.ar_invoice_id
+				       OR EXISTS (SELECT 1 FROM ar_payment_allocations a WHERE a.ar_payment_id = p.id AND a.ar_invoice_id = i.id))
+			)
+			UNION ALL
+			SELECT 'COMMENT', c.id, c.created_at, COALESCE(q.doc_number, so.doc_number, ''),
+			       '', NULL::float8, c.message, c.document_type, c.document_id
+			FROM sales_document_comments c
+			LEFT JOIN quotations q ON c.document_type = 'QUOTATION' AND q.id = c.document_id
+			LEFT JOIN sales_orders so ON c.document_type = 'SALES_ORDER' AND so.id = c.document_id
+			WHERE COALESCE(q.customer_id, so.customer_id) = $1
+		) events
+		ORDER BY occurred_at DESC, type, id DESC
+		LIMIT $2 OFFSET $3
+	`, customerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return shared.ScanActivity(rows)
+}
@@ -0,0 +1,177 @@
+package customers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetCreditExposure loads the customer's credit limit, unpaid posted invoices
+// and confirmed orders that have not been invoiced yet.
+func (r *repository) GetCreditExposure(ctx context.Context, customerID int64) (CreditExposure, error) {
+	exposure := CreditExposure{CustomerID: customerID}
+	err := r.db.QueryRow(ctx, `SELECT credit_limit::float8 FROM customers WHERE id = $1`, customerID).Scan(&exposure.CreditLimit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CreditExposure{}, ErrNotFound
+		}
+		return CreditExposure{}, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, i.number, i.due_at, (i.total - COALESCE(SUM(pa.amount), 0))::float8 AS balance
+		FROM ar_invoices i
+		LEFT JOIN ar_payment_allocations pa ON pa.ar_invoice_id = i.id
+		WHERE i.customer_id = $1 AND i.status = 'POSTED'
+		GROUP BY i.id
+		HAVING i.total - COALESCE(SUM(pa.amount), 0) > 0
+		ORDER BY i.due_at, i.id
+	`, customerID)
+	if err != nil {
+		return CreditExposure{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var inv OpenInvoice
+		if err := rows.Scan(&inv.InvoiceID, &inv.Number, &inv.DueAt, &inv.Balance); err != nil {
+			return CreditExposure{}, err
+		}
+		exposure.OpenInvoices = append(exposure.OpenInvoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return CreditExposure{}, err
+	}
+
+	err = r.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(so.total_amount), 0)::float8
+		FROM sales_orders so
+		WHERE so.customer_id = $1 AND so.status = 'CONFIRMED'
+		  AND NOT EXISTS (SELECT 1 FROM ar_invoices i WHERE i.so_id = so.id AND i.status <> 'VOID')
+	`, customerID).Scan(&exposure.OpenOrders)
+	if err != nil {
+		return CreditExposure{}, err
+	}
+	return exposure, nil
+}
+
+func (r *repository) GetActiveCreditHold(ctx context.Context, customerID int64) (*CreditHold, error) {
+	return r.getCreditHold(ctx, `WHERE customer_id = $1 AND released_at IS NULL`, customerID)
+}
+
+func (r *repository) GetLatestReleasedCreditHold(ctx context.Context, customerID int64) (*CreditHold, error) {
+	return r.getCreditHold(ctx, `WHERE customer_id = $1 AND released_at IS NOT NULL ORDER BY released_at DESC LIMIT 1`, customerID)
+}
+
+func (r *repository) getCreditHold(ctx context.Context, where string, customerID int64) (*CreditHold, error) {
+	var h CreditHold
+	err := r.db.QueryRow(ctx, fmt.Sprintf(`
+		SELECT id, customer_id, reason, credit_limit::float8, exposure::float8,
+		       placed_by, placed_at, released_by, released_at, release_note
+		FROM customer_credit_holds
+		%s
+	`, where), customerID).Scan(
+		&h.ID, &h.CustomerID, &h.Reason, &h.CreditLimit, &h.Exposure,
+		&h.PlacedBy, &h.PlacedAt, &h.ReleasedBy, &h.ReleasedAt, &h.ReleaseNote,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT ar_invoice_id, invoice_number, due_at, balance::float8, days_overdue
+		FROM customer_credit_hold_invoices
+		WHERE hold_id = $1
+		ORDER BY due_at, ar_invoice_id
+	`, h.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var inv CreditHoldInvoice
+		if err := rows.Scan(&inv.InvoiceID, &inv.Number, &inv.DueAt, &inv.Balance, &inv.DaysOverdue); err != nil {
+			return nil, err
+		}
+		h.Invoices = append(h.Invoices, inv)
+	}
+	return &h, rows.Err()
+}
+
+// CreateCreditHold inserts the hold with its triggering invoices. It returns
+// 0 when the customer already has an active hold.
+func (r *repository) CreateCreditHold(ctx context.Context, hold CreditHold) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO customer_credit_holds (customer_id, reason, credit_limit, exposure, placed_by, placed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (customer_id) WHERE released_at IS NULL DO NOTHING
+		RETURNING id
+	`, hold.CustomerID, hold.Reason, hold.CreditLimit, hold.Exposure, hold.PlacedBy, hold.PlacedAt).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Another evaluation placed a hold concurrently.
+			return 0, nil
+		}
+		return 0, err
+	}
+	for _, inv := range hold.Invoices {
+		_, err := r.db.Exec(ctx, `
+			INSERT INTO customer_credit_hold_invoices (hold_id, ar_invoice_id, invoice_number, due_at, balance, days_overdue)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, id, inv.InvoiceID, inv.Number, inv.DueAt, inv.Balance, inv.DaysOverdue)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+func (r *repository) ReleaseCreditHold(ctx context.Context, holdID, userID int64, note string) error {
+	var releasedBy *int64
+	if userID > 0 {
+		releasedBy = &userID
+	}
+	tag, err := r.db.Exec(ctx, `
+		UPDATE customer_credit_holds
+		SET released_by = $2, released_at = NOW(), release_note = $3
+		WHERE id = $1 AND released_at IS NULL
+	`, holdID, releasedBy, note)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoActiveCreditHold
+	}
+	return nil
+}
+
+// ListCreditHoldCandidates returns active customers with unpaid posted invoices
+// or confirmed orders, the only customers a credit evaluation can hold.
+func (r *repository) ListCreditHoldCandidates(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT c.id
+		FROM customers c
+		WHERE c.is_active
+		  AND (EXISTS (SELECT 1 FROM ar_invoices i WHERE i.customer_id = c.id AND i.status = 'POSTED')
+		       OR EXISTS (SELECT 1 FROM sales_orders so WHERE so.customer_id = c.id AND so.status = 'CONFIRMED'))
+		ORDER BY c.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,51 @@
+package customers
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// FindSimilar returns customers of the company whose name is trigram-similar
+// to name or whose normalized tax ID or phone equals the given ones. taxID and
+// phone must already be normalized with shared.NormalizeIdentifier. The name
+// match uses the % operator so the trigram index applies; minSimilarity is
+// set as pg_trgm.similarity_threshold for the query's transaction only.
+func (r *repository) FindSimilar(ctx context.Context, companyID int64, name, taxID, phone string, minSimilarity float64, limit int) ([]shared.DuplicateCandidate, error) {
+	var candidates []shared.DuplicateCandidate
+	err := db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1::float8::text, true)`, minSimilarity); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, `
+			SELECT id, code, name, COALESCE(tax_id, ''), COALESCE(phone, ''), similarity(name, $2)::float8 AS score
+			FROM customers
+			WHERE company_id = $1
+			  AND (name % $2
+			       OR ($3 <> '' AND regexp_replace(lower(COALESCE(tax_id, '')), '[^a-z0-9]', '', 'g') = $3)
+			       OR ($4 <> '' AND regexp_replace(lower(COALESCE(phone, '')), '[^a-z0-9]', '', 'g') = $4))
+			ORDER BY score DESC, id
+			LIMIT $5
+		`, companyID, name, taxID, phone, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c shared.DuplicateCandidate
+			if err := rows.Scan(&c.ID, &c.Code, &c.Name, &c.TaxID, &c.Phone, &c.Similarity); err != nil {
+				return err
+			}
+			candidates = append(candidates, c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
@@ -0,0 +1,36 @@
+package customers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func (r *repository) GetMinOrderSetting(ctx context.Context, customerID int64) (MinOrderSetting, error) {
+	setting := MinOrderSetting{CustomerID: customerID}
+	var companyID int64
+	err := r.db.QueryRow(ctx, `SELECT company_id, min_order_value::float8, min_order_waived
+FROM customers WHERE id = $1`, customerID).Scan(&companyID, &setting.MinOrderValue, &setting.Waived)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !shared.CompanyAllowed(ctx, companyID)) {
+		return MinOrderSetting{}, ErrNotFound
+	}
+	if err != nil {
+		return MinOrderSetting{}, err
+	}
+	return setting, nil
+}
+
+func (r *repository) SetMinOrderSetting(ctx context.Context, setting MinOrderSetting) error {
+	tag, err := r.db.Exec(ctx, `UPDATE customers SET min_order_value = $2, min_order_waived = $3, updated_at = NOW()
+WHERE id = $1`, setting.CustomerID, setting.MinOrderValue, setting.Waived)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package customers
+
+import (
+	"context"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ListSalesTaxes returns the taxes a customer can default to: every tax that
+// is not withheld, at its current rate.
+func (r *repository) ListSalesTaxes(ctx context.Context) ([]shared.TaxRate, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, code, name, rate::float8 FROM taxes WHERE NOT is_withholding ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var taxes []shared.TaxRate
+	for rows.Next() {
+		var tax shared.TaxRate
+		if err := rows.Scan(&tax.TaxID, &tax.Code, &tax.Name, &tax.Rate); err != nil {
+			return nil, err
+		}
+		taxes = append(taxes, tax)
+	}
+	return taxes, rows.Err()
+}
+
+// IsSalesTax reports whether the tax exists and is not a withholding tax.
+func (r *repository) IsSalesTax(ctx context.Context, taxID int64) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM taxes WHERE id = $1 AND NOT is_withholding)`, taxID).Scan(&ok)
+	return ok, err
+}
@@ -10,6 +10,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAny("sales.customer.view"))
 		r.Get("/customers", h.List)
 		r.Get("/customers/{id}", h.Show)
+		r.Get("/customers/{id}/activity", h.Activity)
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAll("sales.customer.create"))
@@ -20,5 +21,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAll("sales.customer.edit"))
 		r.Get("/customers/{id}/edit", h.ShowEditForm)
 		r.Post("/customers/{id}/edit", h.Update)
+		r.Post("/customers/{id}/credit-hold/release", h.ReleaseCreditHold)
+		r.Post("/customers/{id}/min-order-value", h.UpdateMinOrderSetting)
 	})
 }
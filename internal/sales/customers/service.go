@@ -4,17 +4,73 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/i18n"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
+// AuditPort records customer changes.
+type AuditPort interface {
+	Record(ctx context.Context, log shared.AuditLog) error
+}
+
 type Service struct {
-	repo Repository
+	repo   Repository
+	policy CreditPolicy
+	now    func() time.Time
+	audit  AuditPort
+	taxIDs *shared.TaxIDValidator
 }
 
 func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+	return &Service{repo: repo, policy: DefaultCreditPolicy, now: time.Now, taxIDs: shared.DefaultTaxIDValidator()}
+}
+
+// SetTaxIDValidator replaces the default per-country tax ID formats.
+func (s *Service) SetTaxIDValidator(v *shared.TaxIDValidator) {
+	if v != nil {
+		s.taxIDs = v
+	}
+}
+
+// SetAuditor enables before/after audit snapshots on updates.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
+}
+
+// FindSimilar returns existing customers of the company that are likely
+// duplicates: a trigram-similar name or the same tax ID or phone number.
+func (s *Service) FindSimilar(ctx context.Context, companyID int64, name, taxID, phone string) ([]shared.DuplicateCandidate, error) {
+	name = strings.TrimSpace(name)
+	taxID = shared.NormalizeIdentifier(taxID)
+	phone = shared.NormalizeIdentifier(phone)
+	if name == "" && taxID == "" && phone == "" {
+		return nil, nil
+	}
+	candidates, err := s.repo.FindSimilar(ctx, companyID, name, taxID, phone, shared.DuplicateNameSimilarity, shared.DuplicateLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		candidates[i].Explain(taxID, phone)
+	}
+	return candidates, nil
 }
 
 func (s *Service) Create(ctx context.Context, req CreateCustomerRequest, createdBy int64) (*Customer, error) {
+	if err := req.ValidateTaxID(s.taxIDs); err != nil {
+		return nil, err
+	}
+	if !i18n.Supported(req.Language) {
+		return nil, ErrUnsupportedLanguage
+	}
+	if err := s.validateDefaultTax(ctx, req.DefaultTaxID); err != nil {
+		return nil, err
+	}
+
 	// Check if code already exists
 	existing, err := s.repo.GetByCode(ctx, req.CompanyID, req.Code)
 	if err != nil && !errors.Is(err, ErrNotFound) {
@@ -23,6 +79,15 @@ func (s *Service) Create(ctx context.Context, req CreateCustomerRequest, created
 	if existing != nil {
 		return nil, fmt.Errorf("%w: customer code already exists", ErrAlreadyExists)
 	}
+	if !req.ConfirmDuplicate {
+		similar, err := s.FindSimilar(ctx, req.CompanyID, req.Name, getString(req.TaxID), getString(req.Phone))
+		if err != nil {
+			return nil, fmt.Errorf("check similar customers: %w", err)
+		}
+		if len(similar) > 0 {
+			return nil, &shared.DuplicateError{Candidates: similar}
+		}
+	}
 
 	customer := Customer{
 		Code:             req.Code,
@@ -39,6 +104,8 @@ func (s *Service) Create(ctx context.Context, req CreateCustomerRequest, created
 		State:            req.State,
 		PostalCode:       req.PostalCode,
 		Country:          req.Country,
+		Language:         req.Language,
+		DefaultTaxID:     req.DefaultTaxID,
 		IsActive:         true,
 		Notes:            req.Notes,
 		CreatedBy:        createdBy,
@@ -64,6 +131,17 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateCustomerReques
 	if err != nil {
 		return nil, fmt.Errorf("get customer: %w", err)
 	}
+	if err := req.ValidateTaxID(s.taxIDs, existing); err != nil {
+		return nil, err
+	}
+	if req.Language != nil && !i18n.Supported(*req.Language) {
+		return nil, ErrUnsupportedLanguage
+	}
+	if req.DefaultTaxID != nil && *req.DefaultTaxID != 0 {
+		if err := s.validateDefaultTax(ctx, req.DefaultTaxID); err != nil {
+			return nil, err
+		}
+	}
 
 	updates := make(map[string]interface{})
 	if req.Name != nil {
@@ -108,6 +186,16 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateCustomerReques
 	if req.Notes != nil {
 		updates["notes"] = *req.Notes
 	}
+	if req.Language != nil {
+		updates["language"] = *req.Language
+	}
+	if req.DefaultTaxID != nil {
+		if *req.DefaultTaxID == 0 {
+			updates["default_tax_id"] = nil
+		} else {
+			updates["default_tax_id"] = *req.DefaultTaxID
+		}
+	}
 
 	if len(updates) == 0 {
 		return existing, nil
@@ -120,7 +208,22 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateCustomerReques
 		return nil, fmt.Errorf("update customer: %w", err)
 	}
 
-	return s.repo.Get(ctx, id)
+	updated, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.audit != nil {
+		_ = s.audit.Record(ctx, shared.AuditLog{
+			ActorID:  shared.AuditActorFromContext(ctx),
+			Action:   "customer.update",
+			Entity:   "customers",
+			EntityID: strconv.FormatInt(id, 10),
+			Meta:     map[string]any{"code": updated.Code},
+			Before:   existing,
+			After:    updated,
+		})
+	}
+	return updated, nil
 }
 
 func (s *Service) Get(ctx context.Context, id int64) (*Customer, error) {
@@ -134,3 +237,22 @@ func (s *Service) List(ctx context.Context, req ListCustomersRequest) ([]Custome
 func (s *Service) GenerateCode(ctx context.Context, companyID int64) (string, error) {
 	return s.repo.GenerateCode(ctx, companyID)
 }
+
+// SalesTaxes lists the taxes offered as a customer's default tax.
+func (s *Service) SalesTaxes(ctx context.Context) ([]shared.TaxRate, error) {
+	return s.repo.ListSalesTaxes(ctx)
+}
+
+func (s *Service) validateDefaultTax(ctx context.Context, taxID *int64) error {
+	if taxID == nil {
+		return nil
+	}
+	ok, err := s.repo.IsSalesTax(ctx, *taxID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidDefaultTax
+	}
+	return nil
+}
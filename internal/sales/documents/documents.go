@@ -0,0 +1,324 @@
+// Package documents renders quotations and sales orders as customer-facing
+// PDFs and bundles batches of them into zip archives.
+package documents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/products"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/i18n"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/orders"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
+)
+
+// Type names the kind of sales document exported.
+type Type string
+
+const (
+	TypeQuotation  Type = "quotation"
+	TypeSalesOrder Type = "sales_order"
+)
+
+const (
+	// MaxBatch caps the documents in one export.
+	MaxBatch = 200
+	// SyncLimit is the largest export built inside the request; larger ones
+	// are generated by the worker as a report job.
+	SyncLimit = 10
+)
+
+var (
+	// ErrUnknownType indicates a document type other than quotation or sales order.
+	ErrUnknownType = errors.New("unknown document type")
+	// ErrNoDocuments indicates an export without any document selected.
+	ErrNoDocuments = errors.New("select at least one document to export")
+	// ErrTooManyDocuments indicates an export above MaxBatch documents.
+	ErrTooManyDocuments = errors.New("too many documents selected")
+	// ErrDocumentNotFound indicates a selected document that does not exist
+	// or is outside the user's companies.
+	ErrDocumentNotFound = errors.New("document not found")
+	// ErrMixedCompanies indicates selected documents from more than one company.
+	ErrMixedCompanies = errors.New("all documents must belong to the same company")
+)
+
+// ParseType validates a document type.
+func ParseType(raw string) (Type, error) {
+	switch t := Type(strings.ToLower(strings.TrimSpace(raw))); t {
+	case TypeQuotation, TypeSalesOrder:
+		return t, nil
+	}
+	return "", ErrUnknownType
+}
+
+// QuotationSource loads quotations with their lines.
+type QuotationSource interface {
+	Get(ctx context.Context, id int64) (*quotations.Quotation, error)
+}
+
+// OrderSource loads sales orders with their lines.
+type OrderSource interface {
+	Get(ctx context.Context, id int64) (*orders.SalesOrder, error)
+}
+
+// CustomerSource loads the customer a document is addressed to.
+type CustomerSource interface {
+	Get(ctx context.Context, id int64) (*customers.Customer, error)
+}
+
+// ProductSource names the products on document lines.
+type ProductSource interface {
+	Get(ctx context.Context, id int64) (products.Product, error)
+}
+
+// Document is a quotation or sales order prepared for printing.
+type Document struct {
+	// Locale renders labels, numbers and dates in the customer's language.
+	Locale i18n.Locale
+
+	Type      Type
+	ID        int64
+	CompanyID int64
+	DocNumber string
+	Date      time.Time
+	// DueDate is the quotation's valid-until or the order's expected delivery date.
+	DueDate *time.Time
+	Status  string
+
+	CustomerName    string
+	CustomerAddress string
+
+	Currency         string
+	Lines            []Line
+	DocumentDiscount float64
+	Subtotal         float64
+	TaxAmount        float64
+	TotalAmount      float64
+	Notes            string
+
+	GeneratedAt time.Time
+}
+
+// Line is one printed document line. Amount is after the line discount and
+// before the document discount and tax.
+type Line struct {
+	LineNumber      int
+	ProductCode     string
+	ProductName     string
+	Description     string
+	Quantity        float64
+	UOM             string
+	UnitPrice       float64
+	DiscountPercent float64
+	TaxPercent      float64
+	Amount          float64
+}
+
+// LabelPrefix selects the document's title labels.
+func (d Document) LabelPrefix() string {
+	if d.Type == TypeSalesOrder {
+		return "order"
+	}
+	return "quotation"
+}
+
+// DueDateLabel selects the label of DueDate.
+func (d Document) DueDateLabel() string {
+	if d.Type == TypeSalesOrder {
+		return "expected_delivery"
+	}
+	return "valid_until"
+}
+
+// FileName is the document's name inside an export archive.
+func (d Document) FileName() string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '-'
+	}, d.DocNumber)
+	if name == "" {
+		name = fmt.Sprintf("%s-%d", d.Type, d.ID)
+	}
+	return name + ".pdf"
+}
+
+// Service loads quotations and sales orders for export.
+type Service struct {
+	quotations QuotationSource
+	orders     OrderSource
+	customers  CustomerSource
+	products   ProductSource
+	exporter   *Exporter
+	now        func() time.Time
+}
+
+// NewService constructs the service.
+func NewService(quotations QuotationSource, orders OrderSource, customers CustomerSource, products ProductSource) *Service {
+	return &Service{
+		quotations: quotations,
+		orders:     orders,
+		customers:  customers,
+		products:   products,
+		now:        time.Now,
+	}
+}
+
+// SetExporter injects the PDF exporter. Without one, Load still works and
+// Zip fails.
+func (s *Service) SetExporter(exporter *Exporter) {
+	s.exporter = exporter
+}
+
+// Load fetches the documents of one type, in document number order. Every
+// document must exist within the caller's companies and all must belong to
+// one company; when companyID is set it must be that company. Duplicate IDs
+// are exported once.
+func (s *Service) Load(ctx context.Context, docType Type, ids []int64, companyID int64) ([]Document, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, ErrNoDocuments
+	}
+	if len(ids) > MaxBatch {
+		return nil, fmt.Errorf("%w: at most %d can be exported at once", ErrTooManyDocuments, MaxBatch)
+	}
+
+	docs := make([]Document, 0, len(ids))
+	for _, id := range ids {
+		doc, err := s.load(ctx, docType, id)
+		if err != nil {
+			return nil, err
+		}
+		if companyID == 0 {
+			companyID = doc.CompanyID
+		}
+		if doc.CompanyID != companyID {
+			return nil, fmt.Errorf("%w: %s belongs to another company", ErrMixedCompanies, doc.DocNumber)
+		}
+		docs = append(docs, doc)
+	}
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].DocNumber < docs[j].DocNumber })
+	return docs, nil
+}
+
+func (s *Service) load(ctx context.Context, docType Type, id int64) (Document, error) {
+	var doc Document
+	switch docType {
+	case TypeQuotation:
+		q, err := s.quotations.Get(ctx, id)
+		if err != nil {
+			return Document{}, notFound(err, "quotation", id)
+		}
+		validUntil := q.ValidUntil
+		doc = Document{
+			ID: q.ID, CompanyID: q.CompanyID, DocNumber: q.DocNumber, Date: q.QuoteDate, DueDate: &validUntil,
+			Status: string(q.Status), Currency: q.Currency, DocumentDiscount: q.DocumentDiscountAmount,
+			Subtotal: q.Subtotal, TaxAmount: q.TaxAmount, TotalAmount: q.TotalAmount, Notes: deref(q.Notes),
+		}
+		for _, l := range q.Lines {
+			doc.Lines = append(doc.Lines, Line{
+				LineNumber: len(doc.Lines) + 1, Description: deref(l.Description), Quantity: l.Quantity, UOM: l.UOM,
+				UnitPrice: l.UnitPrice, DiscountPercent: l.DiscountPercent, TaxPercent: l.TaxPercent,
+				Amount: l.Quantity*l.UnitPrice - (l.DiscountAmount - l.DocumentDiscountAmount),
+			})
+			if err := s.nameProduct(ctx, &doc.Lines[len(doc.Lines)-1], l.ProductID); err != nil {
+				return Document{}, err
+			}
+		}
+		if err := s.addressCustomer(ctx, &doc, q.CustomerID); err != nil {
+			return Document{}, err
+		}
+	case TypeSalesOrder:
+		o, err := s.orders.Get(ctx, id)
+		if err != nil {
+			return Document{}, notFound(err, "sales order", id)
+		}
+		doc = Document{
+			ID: o.ID, CompanyID: o.CompanyID, DocNumber: o.DocNumber, Date: o.OrderDate, DueDate: o.ExpectedDeliveryDate,
+			Status: string(o.Status), Currency: o.Currency, DocumentDiscount: o.DocumentDiscountAmount,
+			Subtotal: o.Subtotal, TaxAmount: o.TaxAmount, TotalAmount: o.TotalAmount, Notes: deref(o.Notes),
+		}
+		for _, l := range o.Lines {
+			doc.Lines = append(doc.Lines, Line{
+				LineNumber: len(doc.Lines) + 1, Description: deref(l.Description), Quantity: l.Quantity, UOM: l.UOM,
+				UnitPrice: l.UnitPrice, DiscountPercent: l.DiscountPercent, TaxPercent: l.TaxPercent,
+				Amount: l.Quantity*l.UnitPrice - (l.DiscountAmount - l.DocumentDiscountAmount),
+			})
+			if err := s.nameProduct(ctx, &doc.Lines[len(doc.Lines)-1], l.ProductID); err != nil {
+				return Document{}, err
+			}
+		}
+		if err := s.addressCustomer(ctx, &doc, o.CustomerID); err != nil {
+			return Document{}, err
+		}
+	default:
+		return Document{}, ErrUnknownType
+	}
+	doc.Type = docType
+	doc.Status = strings.ToLower(doc.Status)
+	doc.GeneratedAt = s.now()
+	return doc, nil
+}
+
+// addressCustomer fills the customer name, address and document language.
+func (s *Service) addressCustomer(ctx context.Context, doc *Document, customerID int64) error {
+	customer, err := s.customers.Get(ctx, customerID)
+	if err != nil {
+		return fmt.Errorf("load customer %d of %s: %w", customerID, doc.DocNumber, err)
+	}
+	doc.Locale = i18n.For(customer.Language)
+	doc.CustomerName = customer.Name
+	var parts []string
+	for _, part := range []*string{customer.AddressLine1, customer.AddressLine2, customer.City, customer.State, customer.PostalCode} {
+		if v := strings.TrimSpace(deref(part)); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	doc.CustomerAddress = strings.Join(parts, "\n")
+	return nil
+}
+
+func (s *Service) nameProduct(ctx context.Context, line *Line, productID int64) error {
+	product, err := s.products.Get(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("load product %d: %w", productID, err)
+	}
+	line.ProductCode = product.Code
+	line.ProductName = product.Name
+	return nil
+}
+
+// notFound maps a repository miss to ErrDocumentNotFound, naming the document.
+func notFound(err error, label string, id int64) error {
+	if errors.Is(err, quotations.ErrNotFound) || errors.Is(err, orders.ErrNotFound) {
+		return fmt.Errorf("%w: %s %d", ErrDocumentNotFound, label, id)
+	}
+	return fmt.Errorf("load %s %d: %w", label, id, err)
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	out := make([]int64, 0, len(ids))
+	seen := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		if _, dup := seen[id]; dup || id <= 0 {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
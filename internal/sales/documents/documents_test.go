@@ -0,0 +1,174 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/products"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/orders"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
+)
+
+type fakeQuotations map[int64]*quotations.Quotation
+
+func (f fakeQuotations) Get(_ context.Context, id int64) (*quotations.Quotation, error) {
+	q, ok := f[id]
+	if !ok {
+		return nil, quotations.ErrNotFound
+	}
+	return q, nil
+}
+
+type fakeOrders map[int64]*orders.SalesOrder
+
+func (f fakeOrders) Get(_ context.Context, id int64) (*orders.SalesOrder, error) {
+	o, ok := f[id]
+	if !ok {
+		return nil, orders.ErrNotFound
+	}
+	return o, nil
+}
+
+type fakeCustomers map[int64]*customers.Customer
+
+func (f fakeCustomers) Get(_ context.Context, id int64) (*customers.Customer, error) {
+	c, ok := f[id]
+	if !ok {
+		return nil, errors.New("customer not found")
+	}
+	return c, nil
+}
+
+type fakeProducts struct{}
+
+func (fakeProducts) Get(_ context.Context, id int64) (products.Product, error) {
+	return products.Product{ID: id, Code: "SKU-1", Name: "Widget"}, nil
+}
+
+type htmlRenderer struct{ pages []string }
+
+func (r *htmlRenderer) RenderHTML(_ context.Context, html string) ([]byte, error) {
+	r.pages = append(r.pages, html)
+	return []byte("%PDF " + html[:20]), nil
+}
+
+func newTestService(t *testing.T) (*Service, *htmlRenderer) {
+	t.Helper()
+	quoteDate := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	address := "Jl. Sudirman 1"
+	svc := NewService(
+		fakeQuotations{
+			1: {ID: 1, DocNumber: "QT-002", CompanyID: 7, CustomerID: 100, QuoteDate: quoteDate, ValidUntil: quoteDate.AddDate(0, 0, 30),
+				Status: quotations.QuotationStatusApproved, Currency: "IDR", Subtotal: 900000, DocumentDiscountAmount: 100000, TotalAmount: 900000,
+				Lines: []quotations.QuotationLine{{ProductID: 5, Quantity: 2, UOM: "PCS", UnitPrice: 500000, DiscountAmount: 100000, DocumentDiscountAmount: 100000}}},
+			2: {ID: 2, DocNumber: "QT-001", CompanyID: 7, CustomerID: 101, QuoteDate: quoteDate, Status: quotations.QuotationStatusDraft, Currency: "USD"},
+			3: {ID: 3, DocNumber: "QT-003", CompanyID: 8, CustomerID: 101, QuoteDate: quoteDate, Currency: "USD"},
+		},
+		fakeOrders{
+			9: {ID: 9, DocNumber: "SO/2026/9", CompanyID: 7, CustomerID: 101, OrderDate: quoteDate, Status: orders.SalesOrderStatusConfirmed, Currency: "USD"},
+		},
+		fakeCustomers{
+			100: {ID: 100, Name: "PT Maju", Language: "id", AddressLine1: &address},
+			101: {ID: 101, Name: "Acme Ltd"},
+		},
+		fakeProducts{},
+	)
+	renderer := &htmlRenderer{}
+	exporter, err := NewExporter(renderer)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	svc.SetExporter(exporter)
+	return svc, renderer
+}
+
+func TestLoadValidatesSelection(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.Load(ctx, TypeQuotation, nil, 0); !errors.Is(err, ErrNoDocuments) {
+		t.Fatalf("expected ErrNoDocuments, got %v", err)
+	}
+	if _, err := svc.Load(ctx, TypeQuotation, []int64{1, 42}, 0); !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("expected ErrDocumentNotFound, got %v", err)
+	}
+	if _, err := svc.Load(ctx, TypeQuotation, []int64{1, 3}, 0); !errors.Is(err, ErrMixedCompanies) {
+		t.Fatalf("expected ErrMixedCompanies, got %v", err)
+	}
+	if _, err := svc.Load(ctx, TypeQuotation, []int64{1}, 8); !errors.Is(err, ErrMixedCompanies) {
+		t.Fatalf("expected documents outside the job's company to be rejected, got %v", err)
+	}
+	ids := make([]int64, MaxBatch+1)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	if _, err := svc.Load(ctx, TypeQuotation, ids, 0); !errors.Is(err, ErrTooManyDocuments) {
+		t.Fatalf("expected ErrTooManyDocuments, got %v", err)
+	}
+
+	docs, err := svc.Load(ctx, TypeQuotation, []int64{1, 2, 1}, 7)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(docs) != 2 || docs[0].DocNumber != "QT-001" || docs[1].DocNumber != "QT-002" {
+		t.Fatalf("expected two documents in number order, got %+v", docs)
+	}
+	line := docs[1].Lines[0]
+	if line.ProductName != "Widget" || line.Amount != 1000000 {
+		t.Fatalf("expected named line before the document discount, got %+v", line)
+	}
+}
+
+func TestWriteZipRendersOnePDFPerDocument(t *testing.T) {
+	svc, renderer := newTestService(t)
+	var buf bytes.Buffer
+	var done int64
+	progress := func(d, _ int64) { done = d }
+
+	if err := svc.WriteZip(context.Background(), "quotation", []int64{1, 2}, 7, &buf, progress); err != nil {
+		t.Fatalf("WriteZip: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		if !strings.HasPrefix(string(content), "%PDF") {
+			t.Fatalf("%s is not a rendered PDF", f.Name)
+		}
+	}
+	if strings.Join(names, ",") != "QT-001.pdf,QT-002.pdf" || done != 2 {
+		t.Fatalf("unexpected archive %v (progress %d)", names, done)
+	}
+	indonesian := renderer.pages[1]
+	for _, want := range []string{`lang="id"`, "PENAWARAN HARGA", "Berlaku Sampai", "4 April 2026", "Disetujui", "Rp 900.000", "Jl. Sudirman 1"} {
+		if !strings.Contains(indonesian, want) {
+			t.Fatalf("quotation for an Indonesian customer missing %q:\n%s", want, indonesian)
+		}
+	}
+
+	buf.Reset()
+	if err := svc.WriteZip(context.Background(), "sales_order", []int64{9}, 0, &buf, nil); err != nil {
+		t.Fatalf("WriteZip order: %v", err)
+	}
+	order := renderer.pages[2]
+	if !strings.Contains(order, "SALES ORDER") || strings.Contains(order, "Expected Delivery") {
+		t.Fatalf("unexpected sales order page:\n%s", order)
+	}
+	archive, _ = zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if archive.File[0].Name != "SO-2026-9.pdf" {
+		t.Fatalf("expected document number sanitized into the file name, got %s", archive.File[0].Name)
+	}
+}
@@ -0,0 +1,176 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/reportjob"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// JobQueue runs large exports as background report jobs.
+type JobQueue interface {
+	Queue(ctx context.Context, kind reportjob.Kind, params reportjob.Params, requestedBy int64) (reportjob.Report, error)
+}
+
+// Handler serves the quotation and sales order PDF batch exports.
+type Handler struct {
+	logger  *slog.Logger
+	service *Service
+	queue   JobQueue
+	rbac    rbac.Middleware
+}
+
+// NewHandler constructs the handler.
+func NewHandler(logger *slog.Logger, service *Service, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, rbac: rbac}
+}
+
+// SetQueue enables background exports. Without a queue, exports above
+// SyncLimit are rejected.
+func (h *Handler) SetQueue(queue JobQueue) {
+	h.queue = queue
+}
+
+// MountRoutes registers routes under /sales.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermQuotationView))
+		r.Post("/quotations/export-pdf", h.exportQuotations)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermSalesOrderView))
+		r.Post("/orders/export-pdf", h.exportOrders)
+	})
+}
+
+func (h *Handler) exportQuotations(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, TypeQuotation, reportjob.KindQuotationPDFs, "/sales/quotations", "quotations")
+}
+
+func (h *Handler) exportOrders(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, TypeSalesOrder, reportjob.KindSalesOrderPDFs, "/sales/orders", "sales-orders")
+}
+
+// export streams a zip of the selected documents, or queues a report job
+// when more than SyncLimit are selected. JSON clients get problem details
+// and the job as JSON; browsers are redirected back to the list with a flash.
+func (h *Handler) export(w http.ResponseWriter, r *http.Request, docType Type, kind reportjob.Kind, listURL, archiveName string) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	var ids []int64
+	for _, raw := range r.PostForm["ids"] {
+		for _, part := range strings.Split(raw, ",") {
+			if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	docs, err := h.service.Load(r.Context(), docType, ids, 0)
+	if err != nil {
+		h.fail(w, r, listURL, err)
+		return
+	}
+
+	if len(docs) > SyncLimit {
+		h.queueExport(w, r, kind, docs, listURL)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.service.Zip(r.Context(), docs, &buf, nil); err != nil {
+		h.fail(w, r, listURL, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.zip", archiveName, h.service.now().Format("20060102")))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (h *Handler) queueExport(w http.ResponseWriter, r *http.Request, kind reportjob.Kind, docs []Document, listURL string) {
+	if h.queue == nil {
+		h.fail(w, r, listURL, fmt.Errorf("%w: at most %d can be exported while background exports are unavailable", ErrTooManyDocuments, SyncLimit))
+		return
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = strconv.FormatInt(doc.ID, 10)
+	}
+	params := reportjob.Params{
+		"ids":        strings.Join(ids, ","),
+		"company_id": strconv.FormatInt(docs[0].CompanyID, 10),
+	}
+	job, err := h.queue.Queue(r.Context(), kind, params, currentUser(r))
+	if err != nil {
+		if wantsJSON(r) {
+			httpx.Problem(w, http.StatusServiceUnavailable, "Queue unavailable", "export could not be queued")
+			return
+		}
+		redirectWithFlash(w, r, listURL, "error", "The export could not be queued. Please try again.")
+		return
+	}
+	statusURL := reportjob.StatusURL(job.ID)
+	if wantsJSON(r) {
+		w.Header().Set("Location", statusURL)
+		httpx.JSON(w, http.StatusAccepted, map[string]any{
+			"job":          job,
+			"status_url":   statusURL,
+			"download_url": statusURL + "/download",
+		})
+		return
+	}
+	redirectWithFlash(w, r, listURL, "info", fmt.Sprintf(
+		"Exporting %d documents in the background. Download the zip from %s/download once it is ready.", len(docs), statusURL))
+}
+
+// fail reports selection problems to the user; anything else is logged.
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, listURL string, err error) {
+	status, msg := http.StatusBadRequest, err.Error()
+	switch {
+	case errors.Is(err, ErrNoDocuments), errors.Is(err, ErrTooManyDocuments),
+		errors.Is(err, ErrDocumentNotFound), errors.Is(err, ErrMixedCompanies):
+	default:
+		h.logger.ErrorContext(r.Context(), "export sales documents failed", "error", err)
+		status, msg = http.StatusInternalServerError, shared.UserSafeMessage(err)
+	}
+	if wantsJSON(r) {
+		httpx.Problem(w, status, "Export failed", msg)
+		return
+	}
+	redirectWithFlash(w, r, listURL, "error", msg)
+}
+
+func redirectWithFlash(w http.ResponseWriter, r *http.Request, url, flashType, message string) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: flashType, Message: message})
+	}
+	http.Redirect(w, r, url, http.StatusSeeOther)
+}
+
+func currentUser(r *http.Request) int64 {
+	sess := shared.SessionFromContext(r.Context())
+	if sess == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(sess.User(), 10, 64)
+	return id
+}
+
+// wantsJSON reports whether the client asked for a JSON representation.
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
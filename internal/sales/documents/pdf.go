@@ -0,0 +1,90 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+	"github.com/odyssey-erp/odyssey-erp/web"
+)
+
+// PDFRenderer converts HTML documents into PDF bytes.
+type PDFRenderer interface {
+	RenderHTML(ctx context.Context, html string) ([]byte, error)
+}
+
+// Exporter renders quotations and sales orders as PDF.
+type Exporter struct {
+	renderer  PDFRenderer
+	templates *template.Template
+}
+
+// NewExporter parses the sales document template.
+func NewExporter(renderer PDFRenderer) (*Exporter, error) {
+	tpl, err := template.New("sales_document_pdf.html").ParseFS(web.Templates, "templates/reports/sales_document_pdf.html")
+	if err != nil {
+		return nil, fmt.Errorf("parse sales document template: %w", err)
+	}
+	return &Exporter{renderer: renderer, templates: tpl}, nil
+}
+
+// PDF renders the customer copy of a document.
+func (e *Exporter) PDF(ctx context.Context, doc Document) ([]byte, error) {
+	if e == nil || e.renderer == nil {
+		return nil, fmt.Errorf("sales document exporter not initialized")
+	}
+	buf := &bytes.Buffer{}
+	if err := e.templates.ExecuteTemplate(buf, "reports/sales_document_pdf.html", view.TemplateData{Data: doc}); err != nil {
+		return nil, fmt.Errorf("render %s: %w", doc.DocNumber, err)
+	}
+	return e.renderer.RenderHTML(ctx, buf.String())
+}
+
+// WriteZip loads the documents like Load and writes them to w with Zip. It
+// takes docType as a string so report jobs can call it without importing the
+// package.
+func (s *Service) WriteZip(ctx context.Context, docType string, ids []int64, companyID int64, w io.Writer, progress func(done, total int64)) error {
+	t, err := ParseType(docType)
+	if err != nil {
+		return err
+	}
+	docs, err := s.Load(ctx, t, ids, companyID)
+	if err != nil {
+		return err
+	}
+	return s.Zip(ctx, docs, w, progress)
+}
+
+// Zip renders each document to PDF and writes them to w as a zip archive,
+// one file per document named by its number. progress, when set, is called
+// after each document.
+func (s *Service) Zip(ctx context.Context, docs []Document, w io.Writer, progress func(done, total int64)) error {
+	archive := zip.NewWriter(w)
+	names := make(map[string]int, len(docs))
+	for i, doc := range docs {
+		pdf, err := s.exporter.PDF(ctx, doc)
+		if err != nil {
+			return err
+		}
+		name := doc.FileName()
+		if n := names[name]; n > 0 {
+			name = fmt.Sprintf("%s-%d.pdf", name[:len(name)-len(".pdf")], n+1)
+		}
+		names[doc.FileName()]++
+		entry, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(pdf); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(int64(i+1), int64(len(docs)))
+		}
+	}
+	return archive.Close()
+}
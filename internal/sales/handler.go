@@ -6,8 +6,10 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/documents"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/orders"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/returns"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 )
@@ -16,6 +18,8 @@ type Handler struct {
 	customers  *customers.Handler
 	quotations *quotations.Handler
 	orders     *orders.Handler
+	returns    *returns.Handler
+	documents  *documents.Handler
 }
 
 func NewHandler(
@@ -57,13 +61,25 @@ func NewHandler(
 			csrf,
 			rbac,
 		),
+		returns:   returns.NewHandler(logger, service.Returns, templates, csrf, rbac),
+		documents: documents.NewHandler(logger, service.Documents, rbac),
 	}
+	h.quotations.SetComments(service.Comments)
+	h.orders.SetComments(service.Comments)
 	return h
 }
 
+// SetDocumentQueue lets quotation and sales order PDF exports above
+// documents.SyncLimit run as report jobs.
+func (h *Handler) SetDocumentQueue(queue documents.JobQueue) {
+	h.documents.SetQueue(queue)
+}
+
 func (h *Handler) MountRoutes(r chi.Router) {
 	// Mount sub-routes
 	h.customers.MountRoutes(r)
 	h.quotations.MountRoutes(r)
 	h.orders.MountRoutes(r)
+	h.returns.MountRoutes(r)
+	h.documents.MountRoutes(r)
 }
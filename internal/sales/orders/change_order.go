@@ -0,0 +1,439 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ChangeOrderModule identifies sales order changes in the approvals log and
+// the approval matrix.
+const ChangeOrderModule = "SO_CHANGE"
+
+type ChangeOrderStatus string
+
+const (
+	ChangeOrderStatusPending  ChangeOrderStatus = "PENDING"
+	ChangeOrderStatusApplied  ChangeOrderStatus = "APPLIED"
+	ChangeOrderStatusRejected ChangeOrderStatus = "REJECTED"
+)
+
+var (
+	ErrChangeOrderNotFound   = errors.New("sales order change not found")
+	ErrChangeOrderPending    = errors.New("the order already has a pending change")
+	ErrChangeOrderNotPending = errors.New("the change is no longer pending")
+	ErrNoChanges             = errors.New("the change does not differ from the order")
+	ErrChangeSelfApproval    = errors.New("the requester cannot approve their own change")
+	ErrChangeReasonRequired  = errors.New("a reason is required")
+)
+
+// ApprovalPort records approval history for change orders.
+type ApprovalPort interface {
+	Record(ctx context.Context, log internalShared.ApprovalLog) error
+}
+
+// ChangeOrderLine is one line quantity changed by a change order.
+type ChangeOrderLine struct {
+	LineID       int64   `json:"line_id"`
+	ProductID    int64   `json:"product_id"`
+	FromQuantity float64 `json:"from_quantity"`
+	ToQuantity   float64 `json:"to_quantity"`
+}
+
+// ChangeOrder is a requested change to a confirmed sales order. FromTotal and
+// ToTotal are the order total before and after the change; ToTotal is priced
+// again when the change is applied.
+type ChangeOrder struct {
+	ID                  int64             `json:"id"`
+	SalesOrderID        int64             `json:"sales_order_id"`
+	Status              ChangeOrderStatus `json:"status"`
+	Reason              string            `json:"reason"`
+	Lines               []ChangeOrderLine `json:"lines"`
+	FromDeliveryDate    *time.Time        `json:"from_delivery_date,omitempty"`
+	ToDeliveryDate      *time.Time        `json:"to_delivery_date,omitempty"`
+	DeliveryDateChanged bool              `json:"delivery_date_changed"`
+	FromTotal           float64           `json:"from_total"`
+	ToTotal             float64           `json:"to_total"`
+	RequiresApproval    bool              `json:"requires_approval"`
+	RequestedBy         int64             `json:"requested_by"`
+	RequestedByName     string            `json:"requested_by_name"`
+	RequestedAt         time.Time         `json:"requested_at"`
+	DecidedBy           *int64            `json:"decided_by,omitempty"`
+	DecidedByName       string            `json:"decided_by_name,omitempty"`
+	DecidedAt           *time.Time        `json:"decided_at,omitempty"`
+	DecisionNote        string            `json:"decision_note,omitempty"`
+}
+
+// Delta is the change in the order total.
+func (c ChangeOrder) Delta() float64 {
+	return math.Round((c.ToTotal-c.FromTotal)*100) / 100
+}
+
+// SetChangeOrderAutoApproveBelow applies change orders that move the order
+// total by less than limit without approval. Zero sends every change for
+// approval.
+func (s *Service) SetChangeOrderAutoApproveBelow(limit float64) {
+	s.changeLimit = limit
+}
+
+// SetApprovals enables approval history for change orders.
+func (s *Service) SetApprovals(approvals ApprovalPort) {
+	s.approvals = approvals
+}
+
+// SetApprovalRouter routes change orders through the approval matrix, matched
+// on the absolute change in the order total, as well as the auto-approve limit.
+func (s *Service) SetApprovalRouter(router internalShared.ApprovalRouter) {
+	s.router = router
+}
+
+// RequestChange records a change to a confirmed order's line quantities or
+// expected delivery date. A change the approval rules let through is applied
+// straight away; otherwise it waits for ApproveChange.
+func (s *Service) RequestChange(ctx context.Context, orderID int64, req RequestChangeOrderRequest, actorID int64) (*ChangeOrder, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrChangeReasonRequired
+	}
+	order, err := s.repo.Get(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+	if !acceptsChanges(order) {
+		return nil, fmt.Errorf("%w: only CONFIRMED or PROCESSING orders can be changed", ErrInvalidStatus)
+	}
+	change, plan, err := planChange(order, req.Lines, req.ExpectedDeliveryDate)
+	if err != nil {
+		return nil, err
+	}
+	change.Reason = reason
+	change.RequestedBy = actorID
+	change.Status = ChangeOrderStatusPending
+	if change.RequiresApproval, err = s.changeNeedsApproval(ctx, order, change); err != nil {
+		return nil, err
+	}
+
+	if !change.RequiresApproval {
+		if err := s.checkChangeCredit(ctx, order, plan, actorID); err != nil {
+			return nil, err
+		}
+		err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+			id, err := repo.CreateChangeOrder(ctx, change)
+			if err != nil {
+				return err
+			}
+			change.ID = id
+			return applyChange(ctx, repo, order, change, plan, actorID, "Applied without approval")
+		})
+		if err != nil {
+			return nil, fmt.Errorf("apply order change: %w", err)
+		}
+		s.auditChange(ctx, order, change.ID)
+		return s.repo.GetChangeOrder(ctx, change.ID)
+	}
+
+	id, err := s.repo.CreateChangeOrder(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+	if s.router != nil {
+		if _, err := s.router.Route(ctx, ChangeOrderModule, changeOrderRef(id), math.Abs(change.Delta()), order.Currency); err != nil {
+			return nil, err
+		}
+	}
+	s.recordChangeApproval(ctx, id, actorID, internalShared.ApprovalSubmit,
+		fmt.Sprintf("Change to %s submitted (total %.2f → %.2f)", order.DocNumber, change.FromTotal, change.ToTotal))
+	return s.repo.GetChangeOrder(ctx, id)
+}
+
+// ApproveChange applies a pending change: the lines are priced again, the
+// customer's credit is re-checked at the new total, and the order is updated.
+// A failed check leaves the change pending.
+func (s *Service) ApproveChange(ctx context.Context, changeID, actorID int64, note string) (*ChangeOrder, error) {
+	change, err := s.repo.GetChangeOrder(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != ChangeOrderStatusPending {
+		return nil, ErrChangeOrderNotPending
+	}
+	if actorID != 0 && actorID == change.RequestedBy {
+		return nil, ErrChangeSelfApproval
+	}
+	if s.router != nil {
+		if err := s.router.Authorize(ctx, ChangeOrderModule, changeOrderRef(changeID), actorID); err != nil {
+			return nil, err
+		}
+	}
+	order, err := s.repo.Get(ctx, change.SalesOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+	if !acceptsChanges(order) {
+		return nil, fmt.Errorf("%w: the order is %s and can no longer be changed", ErrInvalidStatus, order.Status)
+	}
+	if err := checkChangeBase(order, *change); err != nil {
+		return nil, err
+	}
+	requested := make([]ChangeOrderLineReq, len(change.Lines))
+	for i, line := range change.Lines {
+		requested[i] = ChangeOrderLineReq{LineID: line.LineID, Quantity: line.ToQuantity}
+	}
+	_, plan, err := planChange(order, requested, change.ToDeliveryDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkChangeCredit(ctx, order, plan, actorID); err != nil {
+		return nil, err
+	}
+	note = strings.TrimSpace(note)
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		return applyChange(ctx, repo, order, *change, plan, actorID, note)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("apply order change: %w", err)
+	}
+	s.auditChange(ctx, order, changeID)
+	msg := fmt.Sprintf("Change to %s approved", order.DocNumber)
+	if note != "" {
+		msg += ": " + note
+	}
+	s.recordChangeApproval(ctx, changeID, actorID, internalShared.ApprovalApprove, msg)
+	return s.repo.GetChangeOrder(ctx, changeID)
+}
+
+// RejectChange declines a pending change. A note is mandatory.
+func (s *Service) RejectChange(ctx context.Context, changeID, actorID int64, note string) (*ChangeOrder, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, ErrChangeReasonRequired
+	}
+	change, err := s.repo.GetChangeOrder(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != ChangeOrderStatusPending {
+		return nil, ErrChangeOrderNotPending
+	}
+	if err := s.repo.DecideChangeOrder(ctx, changeID, ChangeOrderStatusRejected, actorID, note, change.ToTotal); err != nil {
+		return nil, err
+	}
+	s.recordChangeApproval(ctx, changeID, actorID, internalShared.ApprovalReject, "Change rejected: "+note)
+	return s.repo.GetChangeOrder(ctx, changeID)
+}
+
+// ListChanges returns the order's change history, newest first.
+func (s *Service) ListChanges(ctx context.Context, orderID int64) ([]ChangeOrder, error) {
+	return s.repo.ListChangeOrders(ctx, orderID)
+}
+
+// GetChange returns one change order.
+func (s *Service) GetChange(ctx context.Context, changeID int64) (*ChangeOrder, error) {
+	return s.repo.GetChangeOrder(ctx, changeID)
+}
+
+func acceptsChanges(order *SalesOrder) bool {
+	return order.Status == SalesOrderStatusConfirmed || order.Status == SalesOrderStatusProcessing
+}
+
+// changePlan is the order repriced with a change applied.
+type changePlan struct {
+	lines  []SalesOrderLine
+	totals shared.DocumentTotals
+}
+
+// planChange compares the requested quantities and delivery date with the
+// order and prices the order as it would be after the change.
+func planChange(order *SalesOrder, lines []ChangeOrderLineReq, deliveryDate *time.Time) (ChangeOrder, changePlan, error) {
+	change := ChangeOrder{
+		SalesOrderID:     order.ID,
+		FromDeliveryDate: order.ExpectedDeliveryDate,
+		FromTotal:        order.TotalAmount,
+	}
+	index := make(map[int64]int, len(order.Lines))
+	for i, line := range order.Lines {
+		index[line.ID] = i
+	}
+	reqs := requestLines(order.Lines)
+	for _, req := range lines {
+		i, ok := index[req.LineID]
+		if !ok {
+			return ChangeOrder{}, changePlan{}, fmt.Errorf("%w: line %d is not on order %s", internalShared.ErrValidation, req.LineID, order.DocNumber)
+		}
+		current := order.Lines[i]
+		if req.Quantity <= 0 {
+			return ChangeOrder{}, changePlan{}, fmt.Errorf("%w: line %d quantity must be positive; cancel the order to drop every line", internalShared.ErrValidation, current.LineOrder)
+		}
+		if floor := math.Max(current.QuantityDelivered, current.QuantityInvoiced); req.Quantity < floor {
+			return ChangeOrder{}, changePlan{}, fmt.Errorf("%w: line %d quantity cannot go below the %.2f already delivered or invoiced", internalShared.ErrValidation, current.LineOrder, floor)
+		}
+		if req.Quantity == reqs[i].Quantity {
+			continue
+		}
+		reqs[i].Quantity = req.Quantity
+		change.Lines = append(change.Lines, ChangeOrderLine{
+			LineID:       current.ID,
+			ProductID:    current.ProductID,
+			FromQuantity: current.Quantity,
+			ToQuantity:   req.Quantity,
+		})
+	}
+	if deliveryDate != nil && !sameDay(order.ExpectedDeliveryDate, *deliveryDate) {
+		date := *deliveryDate
+		change.ToDeliveryDate = &date
+		change.DeliveryDateChanged = true
+	}
+	if len(change.Lines) == 0 && !change.DeliveryDateChanged {
+		return ChangeOrder{}, changePlan{}, ErrNoChanges
+	}
+
+	totals, err := priceLines(reqs, shared.DocumentDiscount{Percent: order.DocumentDiscountPercent, Amount: order.DocumentDiscountAmount})
+	if err != nil {
+		return ChangeOrder{}, changePlan{}, err
+	}
+	plan := changePlan{totals: totals, lines: make([]SalesOrderLine, len(order.Lines))}
+	for i, line := range order.Lines {
+		amounts := totals.Lines[i]
+		line.Quantity = reqs[i].Quantity
+		line.DiscountAmount = amounts.DiscountAmount
+		line.TaxAmount = amounts.TaxAmount
+		line.LineTotal = amounts.LineTotal
+		line.DocumentDiscountAmount = amounts.DocumentDiscountAmount
+		plan.lines[i] = line
+	}
+	change.ToTotal = totals.TotalAmount
+	return change, plan, nil
+}
+
+// checkChangeBase rejects applying a change when the order no longer matches
+// what the change was requested against.
+func checkChangeBase(order *SalesOrder, change ChangeOrder) error {
+	quantities := make(map[int64]float64, len(order.Lines))
+	for _, line := range order.Lines {
+		quantities[line.ID] = line.Quantity
+	}
+	for _, line := range change.Lines {
+		if qty, ok := quantities[line.LineID]; !ok || qty != line.FromQuantity {
+			return internalShared.ErrStaleRecord
+		}
+	}
+	if change.DeliveryDateChanged && !sameDayPtr(order.ExpectedDeliveryDate, change.FromDeliveryDate) {
+		return internalShared.ErrStaleRecord
+	}
+	return nil
+}
+
+// changeNeedsApproval holds changes that move the total by the auto-approve
+// limit or more, and changes the approval matrix routes to a role.
+func (s *Service) changeNeedsApproval(ctx context.Context, order *SalesOrder, change ChangeOrder) (bool, error) {
+	delta := math.Abs(change.Delta())
+	if s.changeLimit <= 0 || delta >= s.changeLimit {
+		return true, nil
+	}
+	if s.router == nil {
+		return false, nil
+	}
+	roles, err := s.router.RequiredRoles(ctx, ChangeOrderModule, delta, order.Currency)
+	if err != nil {
+		return false, err
+	}
+	return len(roles) > 0, nil
+}
+
+// checkChangeCredit re-runs the credit check at the changed total, as on
+// confirmation.
+func (s *Service) checkChangeCredit(ctx context.Context, order *SalesOrder, plan changePlan, actorID int64) error {
+	if s.credit == nil {
+		return nil
+	}
+	hold, err := s.credit.EvaluateCreditHold(ctx, order.CustomerID, plan.totals.TotalAmount, actorID)
+	if err != nil {
+		return err
+	}
+	if hold != nil {
+		return fmt.Errorf("%w: %s", customers.ErrCreditHold, hold.Reason)
+	}
+	return nil
+}
+
+// applyChange writes the repriced lines and header and marks the change
+// applied. Lines are updated in place so delivery lines keep their links.
+func applyChange(ctx context.Context, repo Repository, order *SalesOrder, change ChangeOrder, plan changePlan, actorID int64, note string) error {
+	for _, line := range plan.lines {
+		if err := repo.UpdateLineAmounts(ctx, line); err != nil {
+			return err
+		}
+	}
+	updates := map[string]interface{}{
+		"subtotal":                 plan.totals.Subtotal,
+		"tax_amount":               plan.totals.TaxAmount,
+		"total_amount":             plan.totals.TotalAmount,
+		"document_discount_amount": plan.totals.DocumentDiscountAmount,
+		"expected_updated_at":      order.UpdatedAt,
+	}
+	if change.DeliveryDateChanged {
+		updates["expected_delivery_date"] = *change.ToDeliveryDate
+	}
+	if err := repo.Update(ctx, order.ID, updates); err != nil {
+		return err
+	}
+	return repo.DecideChangeOrder(ctx, change.ID, ChangeOrderStatusApplied, actorID, note, plan.totals.TotalAmount)
+}
+
+func (s *Service) auditChange(ctx context.Context, before *SalesOrder, changeID int64) {
+	if s.audit == nil {
+		return
+	}
+	after, err := s.repo.Get(ctx, before.ID)
+	if err != nil {
+		return
+	}
+	_ = s.audit.Record(ctx, internalShared.AuditLog{
+		ActorID:  internalShared.AuditActorFromContext(ctx),
+		Action:   "sales_order.change",
+		Entity:   "sales_orders",
+		EntityID: strconv.FormatInt(before.ID, 10),
+		Meta:     map[string]any{"number": before.DocNumber, "change_id": changeID},
+		Before:   before,
+		After:    after,
+	})
+}
+
+func (s *Service) recordChangeApproval(ctx context.Context, changeID, actorID int64, action internalShared.ApprovalAction, note string) {
+	if s.approvals == nil || actorID == 0 {
+		return
+	}
+	_ = s.approvals.Record(ctx, internalShared.ApprovalLog{
+		Module:  ChangeOrderModule,
+		RefID:   changeOrderRef(changeID),
+		ActorID: actorID,
+		Action:  action,
+		Note:    note,
+	})
+}
+
+// changeOrderRef is the approvals log reference of a change order.
+func changeOrderRef(id int64) uuid.UUID {
+	return uuid.NewSHA1(uuid.Nil, []byte(fmt.Sprintf("%s:%d", ChangeOrderModule, id)))
+}
+
+func sameDay(current *time.Time, requested time.Time) bool {
+	return current != nil && current.Format("2006-01-02") == requested.Format("2006-01-02")
+}
+
+func sameDayPtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return sameDay(a, *b)
+}
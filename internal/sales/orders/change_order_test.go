@@ -0,0 +1,184 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type fakeChangeRepo struct {
+	Repository
+	order   *SalesOrder
+	changes map[int64]*ChangeOrder
+	lines   map[int64]SalesOrderLine
+	updates map[string]interface{}
+}
+
+func newFakeChangeRepo(order *SalesOrder) *fakeChangeRepo {
+	return &fakeChangeRepo{order: order, changes: map[int64]*ChangeOrder{}, lines: map[int64]SalesOrderLine{}}
+}
+
+func (f *fakeChangeRepo) WithTx(ctx context.Context, fn func(context.Context, Repository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeChangeRepo) Get(context.Context, int64) (*SalesOrder, error) {
+	copied := *f.order
+	return &copied, nil
+}
+
+func (f *fakeChangeRepo) UpdateLineAmounts(_ context.Context, line SalesOrderLine) error {
+	f.lines[line.ID] = line
+	return nil
+}
+
+func (f *fakeChangeRepo) Update(_ context.Context, _ int64, updates map[string]interface{}) error {
+	f.updates = updates
+	return nil
+}
+
+func (f *fakeChangeRepo) CreateChangeOrder(_ context.Context, c ChangeOrder) (int64, error) {
+	c.ID = int64(len(f.changes) + 1)
+	f.changes[c.ID] = &c
+	return c.ID, nil
+}
+
+func (f *fakeChangeRepo) GetChangeOrder(_ context.Context, id int64) (*ChangeOrder, error) {
+	c, ok := f.changes[id]
+	if !ok {
+		return nil, ErrChangeOrderNotFound
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (f *fakeChangeRepo) DecideChangeOrder(_ context.Context, id int64, status ChangeOrderStatus, actorID int64, note string, toTotal float64) error {
+	c := f.changes[id]
+	c.Status, c.DecidedBy, c.DecisionNote, c.ToTotal = status, &actorID, note, toTotal
+	return nil
+}
+
+type fakeCredit struct {
+	hold    *customers.CreditHold
+	checked float64
+}
+
+func (f *fakeCredit) EvaluateCreditHold(_ context.Context, _ int64, pending float64, _ int64) (*customers.CreditHold, error) {
+	f.checked = pending
+	return f.hold, nil
+}
+
+func confirmedOrder() *SalesOrder {
+	return &SalesOrder{
+		ID: 7, DocNumber: "SO-2610-0007", Status: SalesOrderStatusConfirmed, Currency: "IDR",
+		Subtotal: 1000, TotalAmount: 1000, CreatedBy: 1,
+		Lines: []SalesOrderLine{
+			{ID: 71, ProductID: 5, Quantity: 10, UnitPrice: 50, LineTotal: 500, LineOrder: 1, QuantityDelivered: 4},
+			{ID: 72, ProductID: 6, Quantity: 5, UnitPrice: 100, LineTotal: 500, LineOrder: 2},
+		},
+	}
+}
+
+func TestPlanChangeRepricesAndGuardsDeliveredQuantity(t *testing.T) {
+	order := confirmedOrder()
+	change, plan, err := planChange(order, []ChangeOrderLineReq{{LineID: 71, Quantity: 12}, {LineID: 72, Quantity: 5}}, nil)
+	if err != nil {
+		t.Fatalf("plan change: %v", err)
+	}
+	if len(change.Lines) != 1 || change.Lines[0].FromQuantity != 10 || change.Lines[0].ToQuantity != 12 {
+		t.Fatalf("unexpected changed lines %+v", change.Lines)
+	}
+	if change.ToTotal != 1100 || change.Delta() != 100 || plan.lines[0].LineTotal != 600 {
+		t.Fatalf("unexpected repricing: total %.2f, line %.2f", change.ToTotal, plan.lines[0].LineTotal)
+	}
+
+	if _, _, err := planChange(order, []ChangeOrderLineReq{{LineID: 71, Quantity: 3}}, nil); !errors.Is(err, internalShared.ErrValidation) {
+		t.Fatalf("expected validation error below delivered quantity, got %v", err)
+	}
+	if _, _, err := planChange(order, []ChangeOrderLineReq{{LineID: 99, Quantity: 3}}, nil); !errors.Is(err, internalShared.ErrValidation) {
+		t.Fatalf("expected validation error for unknown line, got %v", err)
+	}
+	same := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	order.ExpectedDeliveryDate = &same
+	if _, _, err := planChange(order, []ChangeOrderLineReq{{LineID: 72, Quantity: 5}}, &same); !errors.Is(err, ErrNoChanges) {
+		t.Fatalf("expected ErrNoChanges, got %v", err)
+	}
+}
+
+func TestRequestChangeAppliesSmallChangesAndHoldsLargeOnes(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeChangeRepo(confirmedOrder())
+	svc := NewService(repo, nil, nil)
+	svc.SetChangeOrderAutoApproveBelow(150)
+
+	later := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	change, err := svc.RequestChange(ctx, 7, RequestChangeOrderRequest{
+		Lines:                []ChangeOrderLineReq{{LineID: 71, Quantity: 12}},
+		ExpectedDeliveryDate: &later,
+		Reason:               "Customer added two units",
+	}, 2)
+	if err != nil {
+		t.Fatalf("request change: %v", err)
+	}
+	if change.Status != ChangeOrderStatusApplied || change.RequiresApproval {
+		t.Fatalf("expected change applied without approval, got %+v", change)
+	}
+	if repo.lines[71].Quantity != 12 || repo.updates["total_amount"] != 1100.0 || repo.updates["expected_delivery_date"] != later {
+		t.Fatalf("order not updated: lines %+v updates %+v", repo.lines, repo.updates)
+	}
+
+	repo = newFakeChangeRepo(confirmedOrder())
+	svc = NewService(repo, nil, nil)
+	svc.SetChangeOrderAutoApproveBelow(150)
+	change, err = svc.RequestChange(ctx, 7, RequestChangeOrderRequest{
+		Lines:  []ChangeOrderLineReq{{LineID: 72, Quantity: 8}},
+		Reason: "Customer tripled the order",
+	}, 2)
+	if err != nil {
+		t.Fatalf("request change: %v", err)
+	}
+	if change.Status != ChangeOrderStatusPending || !change.RequiresApproval || len(repo.lines) != 0 {
+		t.Fatalf("expected pending change and untouched order, got %+v", change)
+	}
+}
+
+func TestApproveChangeChecksRequesterAndCredit(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeChangeRepo(confirmedOrder())
+	credit := &fakeCredit{hold: &customers.CreditHold{Reason: "credit limit exceeded"}}
+	svc := NewService(repo, nil, nil)
+	svc.SetCreditChecker(credit)
+
+	change, err := svc.RequestChange(ctx, 7, RequestChangeOrderRequest{
+		Lines:  []ChangeOrderLineReq{{LineID: 72, Quantity: 8}},
+		Reason: "More units",
+	}, 2)
+	if err != nil || change.Status != ChangeOrderStatusPending {
+		t.Fatalf("expected pending change, got %+v, %v", change, err)
+	}
+	if _, err := svc.ApproveChange(ctx, change.ID, 2, ""); !errors.Is(err, ErrChangeSelfApproval) {
+		t.Fatalf("expected ErrChangeSelfApproval, got %v", err)
+	}
+	if _, err := svc.ApproveChange(ctx, change.ID, 3, ""); !errors.Is(err, customers.ErrCreditHold) {
+		t.Fatalf("expected credit hold, got %v", err)
+	}
+	if credit.checked != 1300 || repo.changes[change.ID].Status != ChangeOrderStatusPending {
+		t.Fatalf("expected credit checked at new total with change still pending, got %.2f", credit.checked)
+	}
+
+	credit.hold = nil
+	approved, err := svc.ApproveChange(ctx, change.ID, 3, "ok")
+	if err != nil {
+		t.Fatalf("approve change: %v", err)
+	}
+	if approved.Status != ChangeOrderStatusApplied || *approved.DecidedBy != 3 || repo.lines[72].Quantity != 8 {
+		t.Fatalf("expected applied change, got %+v", approved)
+	}
+	if _, err := svc.RejectChange(ctx, change.ID, 3, "too late"); !errors.Is(err, ErrChangeOrderNotPending) {
+		t.Fatalf("expected ErrChangeOrderNotPending, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// DeliveryPerformanceFilter scopes the delivery SLA report by expected
+// delivery date and customer.
+type DeliveryPerformanceFilter struct {
+	CompanyID  int64     `json:"company_id"`
+	CustomerID *int64    `json:"customer_id,omitempty"`
+	DateFrom   time.Time `json:"date_from"`
+	DateTo     time.Time `json:"date_to"`
+}
+
+// DeliveryPerformanceRow is one sales order with an expected delivery date.
+// FinalDeliveredAt is the latest delivered delivery order; it is only final
+// once FullyDelivered is set.
+type DeliveryPerformanceRow struct {
+	SalesOrderID     int64
+	DocNumber        string
+	CustomerID       int64
+	CustomerName     string
+	ExpectedDate     time.Time
+	FinalDeliveredAt *time.Time
+	FullyDelivered   bool
+}
+
+// DeliveryPerformanceStats summarises delivery against the expected date.
+// Only fully delivered orders are scored; the rest are pending, and overdue
+// once the expected date has passed.
+type DeliveryPerformanceStats struct {
+	Orders    int `json:"orders"`
+	Delivered int `json:"delivered"`
+	OnTime    int `json:"on_time"`
+	Late      int `json:"late"`
+	Pending   int `json:"pending"`
+	Overdue   int `json:"overdue"`
+	// OnTimeRate is on-time orders as a percentage of delivered orders.
+	OnTimeRate float64 `json:"on_time_rate"`
+	// AverageDaysLate is averaged over late orders only.
+	AverageDaysLate float64 `json:"average_days_late"`
+	daysLate        int
+}
+
+// DeliveryPerformanceGroup is the delivery summary for one customer.
+type DeliveryPerformanceGroup struct {
+	CustomerID   int64  `json:"customer_id"`
+	CustomerName string `json:"customer_name"`
+	DeliveryPerformanceStats
+}
+
+// DeliveryPerformanceReport is the on-time delivery analysis.
+type DeliveryPerformanceReport struct {
+	Filter    DeliveryPerformanceFilter  `json:"filter"`
+	Totals    DeliveryPerformanceStats   `json:"totals"`
+	Customers []DeliveryPerformanceGroup `json:"customers"`
+}
+
+// DeliveryPerformance scores sales orders expected within the filter by
+// whether their final delivery arrived on or before the expected date.
+func (s *Service) DeliveryPerformance(ctx context.Context, filter DeliveryPerformanceFilter) (DeliveryPerformanceReport, error) {
+	if filter.DateFrom.IsZero() || filter.DateTo.IsZero() {
+		return DeliveryPerformanceReport{}, fmt.Errorf("%w: date range is required", shared.ErrValidation)
+	}
+	if filter.DateTo.Before(filter.DateFrom) {
+		return DeliveryPerformanceReport{}, fmt.Errorf("%w: date_to must not be before date_from", shared.ErrValidation)
+	}
+	rows, err := s.repo.DeliveryPerformanceRows(ctx, filter)
+	if err != nil {
+		return DeliveryPerformanceReport{}, err
+	}
+	report := SummarizeDeliveryPerformance(rows, time.Now())
+	report.Filter = filter
+	return report, nil
+}
+
+// SummarizeDeliveryPerformance aggregates rows into totals and customers,
+// worst on-time rate first. Days late compare calendar dates, so a delivery
+// any time on the expected date is on time. Pending orders count as overdue
+// when the expected date is before today.
+func SummarizeDeliveryPerformance(rows []DeliveryPerformanceRow, today time.Time) DeliveryPerformanceReport {
+	var report DeliveryPerformanceReport
+	customers := make(map[int64]*DeliveryPerformanceGroup)
+	for _, row := range rows {
+		customer := customers[row.CustomerID]
+		if customer == nil {
+			customer = &DeliveryPerformanceGroup{CustomerID: row.CustomerID, CustomerName: row.CustomerName}
+			customers[row.CustomerID] = customer
+		}
+		for _, stats := range []*DeliveryPerformanceStats{&report.Totals, &customer.DeliveryPerformanceStats} {
+			stats.add(row, today)
+		}
+	}
+
+	report.Totals.rate()
+	for _, c := range customers {
+		c.rate()
+		report.Customers = append(report.Customers, *c)
+	}
+	sort.Slice(report.Customers, func(i, j int) bool {
+		a, b := report.Customers[i], report.Customers[j]
+		if (a.Delivered > 0) != (b.Delivered > 0) {
+			return a.Delivered > 0
+		}
+		if a.OnTimeRate != b.OnTimeRate {
+			return a.OnTimeRate < b.OnTimeRate
+		}
+		return a.CustomerName < b.CustomerName
+	})
+	return report
+}
+
+func (s *DeliveryPerformanceStats) add(row DeliveryPerformanceRow, today time.Time) {
+	s.Orders++
+	if !row.FullyDelivered || row.FinalDeliveredAt == nil {
+		s.Pending++
+		if calendarDaysBetween(row.ExpectedDate, today) > 0 {
+			s.Overdue++
+		}
+		return
+	}
+	s.Delivered++
+	if late := calendarDaysBetween(row.ExpectedDate, *row.FinalDeliveredAt); late > 0 {
+		s.Late++
+		s.daysLate += late
+		return
+	}
+	s.OnTime++
+}
+
+func (s *DeliveryPerformanceStats) rate() {
+	if s.Delivered > 0 {
+		s.OnTimeRate = math.Round(float64(s.OnTime)/float64(s.Delivered)*10000) / 100
+	}
+	if s.Late > 0 {
+		s.AverageDaysLate = math.Round(float64(s.daysLate)/float64(s.Late)*100) / 100
+	}
+}
+
+// calendarDaysBetween counts whole days from the date of from to the date of
+// to, each taken in its own location.
+func calendarDaysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
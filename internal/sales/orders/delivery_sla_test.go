@@ -0,0 +1,46 @@
+package orders
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeDeliveryPerformance(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	at := func(d, hour int) *time.Time {
+		v := time.Date(2026, time.March, d, hour, 0, 0, 0, time.UTC)
+		return &v
+	}
+	rows := []DeliveryPerformanceRow{
+		// Delivered late in the evening of the expected date: on time.
+		{SalesOrderID: 1, CustomerID: 1, CustomerName: "Acme", ExpectedDate: day(10), FinalDeliveredAt: at(10, 22), FullyDelivered: true},
+		// Final of several deliveries arrived three days late.
+		{SalesOrderID: 2, CustomerID: 1, CustomerName: "Acme", ExpectedDate: day(10), FinalDeliveredAt: at(13, 9), FullyDelivered: true},
+		{SalesOrderID: 3, CustomerID: 2, CustomerName: "Beta", ExpectedDate: day(5), FinalDeliveredAt: at(6, 9), FullyDelivered: true},
+		// Partly delivered on time but still open past its date.
+		{SalesOrderID: 4, CustomerID: 2, CustomerName: "Beta", ExpectedDate: day(12), FinalDeliveredAt: at(11, 9)},
+		{SalesOrderID: 5, CustomerID: 3, CustomerName: "Cargo", ExpectedDate: day(20)},
+	}
+
+	report := SummarizeDeliveryPerformance(rows, day(15))
+
+	tot := report.Totals
+	if tot.Orders != 5 || tot.Delivered != 3 || tot.OnTime != 1 || tot.Late != 2 || tot.Pending != 2 || tot.Overdue != 1 {
+		t.Fatalf("unexpected totals %+v", tot)
+	}
+	if tot.OnTimeRate != 33.33 || tot.AverageDaysLate != 2 {
+		t.Fatalf("expected 33.33%% on time and 2 days late on average, got %+v", tot)
+	}
+	if len(report.Customers) != 3 {
+		t.Fatalf("unexpected customers %+v", report.Customers)
+	}
+	want := []string{"Beta", "Acme", "Cargo"}
+	for i, name := range want {
+		if report.Customers[i].CustomerName != name {
+			t.Fatalf("customer %d: expected %s, got %+v", i, name, report.Customers)
+		}
+	}
+	if acme := report.Customers[1]; acme.OnTimeRate != 50 || acme.AverageDaysLate != 3 {
+		t.Fatalf("unexpected Acme stats %+v", acme)
+	}
+}
@@ -11,6 +11,11 @@ type CreateSalesOrderRequest struct {
 	Currency             string                    `json:"currency" validate:"required,len=3"`
 	Notes                *string                   `json:"notes,omitempty"`
 	Lines                []CreateSalesOrderLineReq `json:"lines" validate:"required,min=1,dive"`
+
+	// DocumentDiscountPercent discounts the whole document after line
+	// discounts; when zero, DocumentDiscountAmount is taken as a fixed amount.
+	DocumentDiscountPercent float64 `json:"document_discount_percent" validate:"gte=0,lte=100"`
+	DocumentDiscountAmount  float64 `json:"document_discount_amount" validate:"gte=0"`
 }
 
 type CreateSalesOrderLineReq struct {
@@ -23,6 +28,9 @@ type CreateSalesOrderLineReq struct {
 	TaxPercent      float64 `json:"tax_percent" validate:"gte=0,lte=100"`
 	Notes           *string `json:"notes,omitempty"`
 	LineOrder       int     `json:"line_order" validate:"gte=0"`
+	// UseDefaultTax replaces TaxPercent with the customer's default tax at
+	// the rate effective on the order date.
+	UseDefaultTax bool `json:"use_default_tax,omitempty"`
 }
 
 type UpdateSalesOrderRequest struct {
@@ -30,6 +38,21 @@ type UpdateSalesOrderRequest struct {
 	ExpectedDeliveryDate *time.Time                 `json:"expected_delivery_date,omitempty"`
 	Notes                *string                    `json:"notes,omitempty"`
 	Lines                *[]CreateSalesOrderLineReq `json:"lines,omitempty" validate:"omitempty,min=1,dive"`
+	// DocumentDiscountPercent and DocumentDiscountAmount replace the document
+	// discount when either is set; lines are re-priced even if Lines is nil.
+	DocumentDiscountPercent *float64 `json:"document_discount_percent,omitempty" validate:"omitempty,gte=0,lte=100"`
+	DocumentDiscountAmount  *float64 `json:"document_discount_amount,omitempty" validate:"omitempty,gte=0"`
+	// ExpectedUpdatedAt rejects the update with shared.ErrStaleRecord when the
+	// order changed after it was loaded.
+	ExpectedUpdatedAt *time.Time `json:"expected_updated_at,omitempty"`
+}
+
+// ReorderLinesRequest lists every line ID of a sales order in the desired order.
+type ReorderLinesRequest struct {
+	LineIDs []int64 `json:"line_ids" validate:"required,min=1"`
+	// ExpectedUpdatedAt rejects the reorder with shared.ErrStaleRecord when the
+	// order changed after it was loaded.
+	ExpectedUpdatedAt *time.Time `json:"expected_updated_at,omitempty"`
 }
 
 type ListSalesOrdersRequest struct {
@@ -40,4 +63,23 @@ type ListSalesOrdersRequest struct {
 	DateTo     *time.Time        `json:"date_to,omitempty"`
 	Limit      int               `json:"limit" validate:"gte=0,lte=1000"`
 	Offset     int               `json:"offset" validate:"gte=0"`
+	// Cursor switches to keyset pagination on (order_date, id); Offset is ignored when set.
+	Cursor string `json:"cursor,omitempty"`
+	// IncludeArchived also lists orders taken out of the list by archival.
+	IncludeArchived bool `json:"include_archived,omitempty"`
+}
+
+// RequestChangeOrderRequest lists the changes requested to a confirmed order.
+// Lines not listed keep their quantity; a nil ExpectedDeliveryDate keeps the
+// current date.
+type RequestChangeOrderRequest struct {
+	Lines                []ChangeOrderLineReq `json:"lines,omitempty" validate:"dive"`
+	ExpectedDeliveryDate *time.Time           `json:"expected_delivery_date,omitempty"`
+	Reason               string               `json:"reason" validate:"required"`
+}
+
+// ChangeOrderLineReq sets a new quantity on an existing order line.
+type ChangeOrderLineReq struct {
+	LineID   int64   `json:"line_id" validate:"required,gt=0"`
+	Quantity float64 `json:"quantity" validate:"required,gt=0"`
 }
@@ -1,14 +1,18 @@
 package orders
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/products"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/comments"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
@@ -21,6 +25,7 @@ type Handler struct {
 	customerService  *customers.Service
 	quotationService *quotations.Service
 	productService   *products.Service
+	comments         *comments.Service
 	templates        *view.Engine
 	csrf             *shared.CSRFManager
 	rbac             rbac.Middleware
@@ -48,6 +53,11 @@ func NewHandler(
 	}
 }
 
+// SetComments enables the comment thread on the detail page.
+func (h *Handler) SetComments(svc *comments.Service) {
+	h.comments = svc
+}
+
 type formErrors map[string]string
 
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
@@ -63,30 +73,55 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	dateFrom := h.parseDate(r.URL.Query().Get("date_from"))
 	dateTo := h.parseDate(r.URL.Query().Get("date_to"))
 
-	limit := 50
-	offset := 0
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > shared.MaxListLimit {
+		limit = shared.MaxListLimit
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		offset = 0
+	}
+	includeArchived := r.URL.Query().Get("include_archived") == "1"
 
 	orders, total, err := h.service.List(r.Context(), ListSalesOrdersRequest{
-		CompanyID: companyID,
-		Status:    statusPtr,
-		DateFrom:  dateFrom,
-		DateTo:    dateTo,
-		Limit:     limit,
-		Offset:    offset,
+		CompanyID:       companyID,
+		Status:          statusPtr,
+		DateFrom:        dateFrom,
+		DateTo:          dateTo,
+		Limit:           limit,
+		Offset:          offset,
+		Cursor:          cursor,
+		IncludeArchived: includeArchived,
 	})
+	if errors.Is(err, shared.ErrInvalidCursor) {
+		httpx.Error(w, r, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		h.logger.Error("list orders failed", "error", err)
-		http.Error(w, "Failed to load orders", http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list orders failed", "error", err)
+		httpx.Error(w, r, "Failed to load orders", http.StatusInternalServerError)
 		return
 	}
 
 	h.render(w, r, "pages/sales/orders_list.html", map[string]any{
-		"Orders": orders,
-		"Total":  total,
+		"Orders":     orders,
+		"Total":      total,
+		"Limit":      limit,
+		"Offset":     offset,
+		"Cursor":     cursor,
+		"NextCursor": NextCursor(orders, limit),
 		"Filters": map[string]any{
-			"Status":   status,
-			"DateFrom": r.URL.Query().Get("date_from"),
-			"DateTo":   r.URL.Query().Get("date_to"),
+			"Status":          status,
+			"DateFrom":        r.URL.Query().Get("date_from"),
+			"DateTo":          r.URL.Query().Get("date_to"),
+			"IncludeArchived": includeArchived,
 		},
 	}, http.StatusOK)
 }
@@ -94,29 +129,89 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
 		return
 	}
 
-	order, err := h.service.Get(r.Context(), id)
+	order, err := h.service.GetWithMargin(r.Context(), id)
 	if err != nil {
-		h.logger.Error("get order failed", "error", err)
-		http.Error(w, "Order not found", http.StatusNotFound)
+		h.logger.ErrorContext(r.Context(), "get order failed", "error", err)
+		httpx.Error(w, r, "Order not found", http.StatusNotFound)
 		return
 	}
 
 	customer, _ := h.customerService.Get(r.Context(), order.CustomerID)
+	creditHold, err := h.customerService.ActiveCreditHold(r.Context(), order.CustomerID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "get credit hold failed", "error", err, "customer_id", order.CustomerID)
+	}
 
 	var quotation *quotations.Quotation
 	if order.QuotationID != nil {
 		quotation, _ = h.quotationService.Get(r.Context(), *order.QuotationID)
 	}
 
-	h.render(w, r, "pages/sales/order_detail.html", map[string]any{
-		"Order":     order,
-		"Customer":  customer,
-		"Quotation": quotation,
-	}, http.StatusOK)
+	data := map[string]any{
+		"Order":      order,
+		"Customer":   customer,
+		"Quotation":  quotation,
+		"CreditHold": creditHold,
+	}
+	if h.comments != nil {
+		thread, err := h.comments.Thread(r.Context(), commentDocument(&order.SalesOrder), "/sales/orders/"+strconv.FormatInt(id, 10)+"/comments")
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "list order comments failed", "error", err, "id", id)
+		}
+		data["Thread"] = thread
+	}
+	changes, err := h.service.ListChanges(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list order changes failed", "error", err, "id", id)
+	}
+	data["Changes"] = changes
+	reservations, err := h.service.ListReservations(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list order reservations failed", "error", err, "id", id)
+	}
+	data["Reservations"] = reservations
+	if order.Status == SalesOrderStatusDraft {
+		warehouses, err := h.service.ListReservationWarehouses(r.Context(), order.CompanyID)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "list reservation warehouses failed", "error", err, "company_id", order.CompanyID)
+		}
+		data["Warehouses"] = warehouses
+	}
+	h.render(w, r, "pages/sales/order_detail.html", data, http.StatusOK)
+}
+
+func (h *Handler) Comment(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	path := "/sales/orders/" + strconv.FormatInt(id, 10)
+	if h.comments == nil {
+		httpx.Error(w, r, "Comments unavailable", http.StatusNotFound)
+		return
+	}
+	order, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		httpx.Error(w, r, "Order not found", http.StatusNotFound)
+		return
+	}
+	if _, err := h.comments.Add(r.Context(), commentDocument(order), h.getCurrentUserID(r), r.PostFormValue("message")); err != nil {
+		h.logger.ErrorContext(r.Context(), "comment on order failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, path, "error", comments.UserMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, path+"#comments", "success", "Comment added")
+}
+
+func commentDocument(o *SalesOrder) comments.Document {
+	return comments.Document{
+		Type:      comments.DocumentSalesOrder,
+		ID:        o.ID,
+		Number:    o.DocNumber,
+		CreatedBy: o.CreatedBy,
+		Closed:    o.IsTerminal(),
+	}
 }
 
 func (h *Handler) ShowForm(w http.ResponseWriter, r *http.Request) {
@@ -135,7 +230,7 @@ func (h *Handler) ShowForm(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -166,6 +261,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		Currency:    r.PostFormValue("currency"),
 		Lines:       lines,
 	}
+	if pct, amt := parseDocumentDiscount(r); pct != nil || amt != nil {
+		if pct != nil {
+			req.DocumentDiscountPercent = *pct
+		}
+		if amt != nil {
+			req.DocumentDiscountAmount = *amt
+		}
+	}
 	if d := r.PostFormValue("expected_delivery_date"); d != "" {
 		if t, err := time.Parse("2006-01-02", d); err == nil {
 			req.ExpectedDeliveryDate = &t
@@ -177,24 +280,25 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	order, err := h.service.Create(r.Context(), req, userID)
 	if err != nil {
-		h.logger.Error("create order failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "create order failed", "error", err)
 		h.renderFormError(w, r, shared.UserSafeMessage(err), nil)
 		return
 	}
 
-	h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(order.ID, 10), "success", "Sales order created successfully")
+	h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(order.ID, 10), "success",
+		h.withDefaultTaxNotice(r, "Sales order created successfully.", order, lines))
 }
 
 func (h *Handler) ShowEditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
 		return
 	}
 
 	order, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Not found", http.StatusNotFound)
+		httpx.Error(w, r, "Not found", http.StatusNotFound)
 		return
 	}
 
@@ -214,16 +318,21 @@ func (h *Handler) ShowEditForm(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
-	req := UpdateSalesOrderRequest{}
+	expected, err := shared.ParseVersionToken(r.PostFormValue(shared.VersionFormField))
+	if err != nil {
+		httpx.Error(w, r, "Invalid version", http.StatusBadRequest)
+		return
+	}
+	req := UpdateSalesOrderRequest{ExpectedUpdatedAt: expected}
 
 	if d := r.PostFormValue("order_date"); d != "" {
 		if t, err := time.Parse("2006-01-02", d); err == nil {
@@ -238,6 +347,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	if n := r.PostFormValue("notes"); n != "" {
 		req.Notes = &n
 	}
+	req.DocumentDiscountPercent, req.DocumentDiscountAmount = parseDocumentDiscount(r)
 
 	if len(r.PostForm["product_id"]) > 0 {
 		lines, err := h.parseSalesOrderLines(r)
@@ -250,24 +360,33 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	order, err := h.service.Update(r.Context(), id, req)
 	if err != nil {
-		h.logger.Error("update order failed", "error", err)
 		o, _ := h.service.Get(r.Context(), id)
+		if errors.Is(err, shared.ErrStaleRecord) {
+			h.renderConflict(w, r, o)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "update order failed", "error", err)
 		h.renderFormError(w, r, shared.UserSafeMessage(err), o)
 		return
 	}
 
-	h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(order.ID, 10), "success", "Sales order updated successfully")
+	var lines []CreateSalesOrderLineReq
+	if req.Lines != nil {
+		lines = *req.Lines
+	}
+	h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(order.ID, 10), "success",
+		h.withDefaultTaxNotice(r, "Sales order updated successfully.", order, lines))
 }
 
 func (h *Handler) ConvertFromQuotation(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid quotation ID", http.StatusBadRequest)
+		httpx.Error(w, r, "Invalid quotation ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
 		return
 	}
 
@@ -276,7 +395,7 @@ func (h *Handler) ConvertFromQuotation(w http.ResponseWriter, r *http.Request) {
 		h.redirectWithFlash(w, r, "/sales/quotations/"+strconv.FormatInt(id, 10), "error", "Quotation not found")
 		return
 	}
-	if quotation.Status != quotations.QuotationStatusApproved {
+	if !quotations.QuotationTransitions.Can(quotation.Status, quotations.QuotationStatusConverted) {
 		h.redirectWithFlash(w, r, "/sales/quotations/"+strconv.FormatInt(id, 10), "error", "Quotation must be approved before conversion")
 		return
 	}
@@ -324,11 +443,17 @@ func (h *Handler) ConvertFromQuotation(w http.ResponseWriter, r *http.Request) {
 		Lines:                lines,
 		Notes:                quotation.Notes,
 	}
+	// Carry the quoted discount over; a percent re-applies to the same lines.
+	if quotation.DocumentDiscountPercent > 0 {
+		req.DocumentDiscountPercent = quotation.DocumentDiscountPercent
+	} else {
+		req.DocumentDiscountAmount = quotation.DocumentDiscountAmount
+	}
 
 	order, err := h.service.Create(r.Context(), req, h.getCurrentUserID(r))
 	if err != nil {
-		h.logger.Error("convert quotation to order failed", "error", err, "quotation_id", id)
-		h.redirectWithFlash(w, r, "/sales/quotations/"+strconv.FormatInt(id, 10), "error", shared.UserSafeMessage(err))
+		h.logger.ErrorContext(r.Context(), "convert quotation to order failed", "error", err, "quotation_id", id)
+		h.redirectWithFlash(w, r, "/sales/quotations/"+strconv.FormatInt(id, 10), "error", orderErrorMessage(err))
 		return
 	}
 
@@ -339,10 +464,20 @@ func (h *Handler) Confirm(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	userID := h.getCurrentUserID(r)
 
-	_, err := h.service.Confirm(r.Context(), id, userID)
+	opts := ConfirmOptions{OverrideMinimumOrder: h.rbac.Allows(r, shared.PermSalesOrderMinValueOverride)}
+	if raw := strings.TrimSpace(r.PostFormValue("reserve_warehouse_id")); raw != "" {
+		warehouseID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(id, 10), "error", "Invalid reservation warehouse")
+			return
+		}
+		opts.ReserveWarehouseID = warehouseID
+	}
+
+	_, err := h.service.ConfirmWithOptions(r.Context(), id, userID, opts)
 	if err != nil {
-		h.logger.Error("confirm order failed", "error", err, "id", id)
-		h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(id, 10), "error", shared.UserSafeMessage(err))
+		h.logger.ErrorContext(r.Context(), "confirm order failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(id, 10), "error", orderErrorMessage(err))
 		return
 	}
 	h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(id, 10), "success", "Sales order confirmed")
@@ -355,13 +490,27 @@ func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
 
 	_, err := h.service.Cancel(r.Context(), id, userID, reason)
 	if err != nil {
-		h.logger.Error("cancel order failed", "error", err, "id", id)
+		h.logger.ErrorContext(r.Context(), "cancel order failed", "error", err, "id", id)
 		h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(id, 10), "error", shared.UserSafeMessage(err))
 		return
 	}
 	h.redirectWithFlash(w, r, "/sales/orders/"+strconv.FormatInt(id, 10), "success", "Sales order cancelled")
 }
 
+// parseDocumentDiscount reads the optional document discount fields. A field
+// that is missing or blank is returned as nil.
+func parseDocumentDiscount(r *http.Request) (percent, amount *float64) {
+	read := func(key string) *float64 {
+		raw := strings.TrimSpace(r.PostFormValue(key))
+		if raw == "" {
+			return nil
+		}
+		v, _ := strconv.ParseFloat(raw, 64)
+		return &v
+	}
+	return read("document_discount_percent"), read("document_discount_amount")
+}
+
 // Helpers
 func (h *Handler) parseSalesOrderLines(r *http.Request) ([]CreateSalesOrderLineReq, error) {
 	productIDs := r.PostForm["product_id"]
@@ -381,7 +530,9 @@ func (h *Handler) parseSalesOrderLines(r *http.Request) ([]CreateSalesOrderLineR
 		qty, _ := strconv.ParseFloat(quantities[i], 64)
 		price, _ := strconv.ParseFloat(unitPrices[i], 64)
 		dist, _ := strconv.ParseFloat(discountPercents[i], 64)
-		tax, _ := strconv.ParseFloat(taxPercents[i], 64)
+		// A blank tax takes the customer's default tax.
+		rawTax := strings.TrimSpace(formValueAt(taxPercents, i))
+		tax, _ := strconv.ParseFloat(rawTax, 64)
 
 		lines = append(lines, CreateSalesOrderLineReq{
 			ProductID:       pid,
@@ -391,11 +542,38 @@ func (h *Handler) parseSalesOrderLines(r *http.Request) ([]CreateSalesOrderLineR
 			DiscountPercent: dist,
 			TaxPercent:      tax,
 			LineOrder:       i + 1,
+			UseDefaultTax:   rawTax == "",
 		})
 	}
 	return lines, nil
 }
 
+func formValueAt(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+// withDefaultTaxNotice appends which default tax was applied to lines left
+// blank, so the user can check it.
+func (h *Handler) withDefaultTaxNotice(r *http.Request, message string, o *SalesOrder, lines []CreateSalesOrderLineReq) string {
+	applied := 0
+	for _, line := range lines {
+		if line.UseDefaultTax {
+			applied++
+		}
+	}
+	notice, err := h.service.DefaultTaxNotice(r.Context(), o.CustomerID, o.OrderDate, applied)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "load default tax failed", "error", err, "customer_id", o.CustomerID)
+	}
+	if notice == "" {
+		return message
+	}
+	return message + " " + notice
+}
+
 func (h *Handler) renderFormError(w http.ResponseWriter, r *http.Request, msg string, o *SalesOrder) {
 	companyID := h.getCurrentCompanyID(r)
 	customers, _, _ := h.customerService.List(r.Context(), customers.ListCustomersRequest{CompanyID: companyID, Limit: 1000})
@@ -407,6 +585,19 @@ func (h *Handler) renderFormError(w http.ResponseWriter, r *http.Request, msg st
 	}, http.StatusBadRequest)
 }
 
+// renderConflict shows the edit form with the current order after a stale
+// update, so the user starts again from the latest version.
+func (h *Handler) renderConflict(w http.ResponseWriter, r *http.Request, o *SalesOrder) {
+	companyID := h.getCurrentCompanyID(r)
+	customers, _, _ := h.customerService.List(r.Context(), customers.ListCustomersRequest{CompanyID: companyID, Limit: 1000})
+
+	h.render(w, r, "pages/sales/order_form.html", map[string]any{
+		"Errors":    formErrors{"general": shared.UserSafeMessage(shared.ErrStaleRecord)},
+		"Order":     o,
+		"Customers": customers,
+	}, http.StatusConflict)
+}
+
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, tmpl string, data map[string]any, status int) {
 	sess := shared.SessionFromContext(r.Context())
 	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
@@ -451,7 +642,7 @@ func (h *Handler) getCurrentCompanyID(r *http.Request) int64 {
 			return id
 		}
 	}
-	return 1
+	return shared.ScopedCompanyID(r.Context(), 0, 1)
 }
 
 func (h *Handler) parseDate(s string) *time.Time {
@@ -464,3 +655,81 @@ func (h *Handler) parseDate(s string) *time.Time {
 	}
 	return &t
 }
+
+// orderErrorMessage shows credit hold, status and minimum order errors
+// verbatim and hides everything else behind the generic user-safe message.
+func orderErrorMessage(err error) string {
+	if errors.Is(err, customers.ErrCreditHold) || errors.Is(err, ErrInvalidStatus) || errors.Is(err, quotations.ErrOpenLineFlags) ||
+		errors.Is(err, ErrInsufficientStockToReserve) || errors.Is(err, ErrReservationWarehouse) ||
+		errors.Is(err, ErrBelowMinimumOrder) {
+		return err.Error()
+	}
+	return shared.UserSafeMessage(err)
+}
+
+// ReorderLines applies a new line order from repeated line_id form values,
+// e.g. posted by drag-and-drop on the detail page. JSON clients get the
+// renumbered lines back; browsers are redirected with a flash.
+func (h *Handler) ReorderLines(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	detailURL := "/sales/orders/" + strconv.FormatInt(id, 10)
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+	expected, err := shared.ParseVersionToken(r.PostFormValue(shared.VersionFormField))
+	if err != nil {
+		httpx.Error(w, r, "Invalid version", http.StatusBadRequest)
+		return
+	}
+	req := ReorderLinesRequest{ExpectedUpdatedAt: expected}
+	for _, raw := range r.PostForm["line_id"] {
+		lineID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			httpx.Error(w, r, "Invalid line ID", http.StatusBadRequest)
+			return
+		}
+		req.LineIDs = append(req.LineIDs, lineID)
+	}
+
+	order, err := h.service.ReorderLines(r.Context(), id, req)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "reorder order lines failed", "error", err, "id", id)
+		msg := reorderErrorMessage(err)
+		if wantsJSON(r) {
+			status := http.StatusUnprocessableEntity
+			if errors.Is(err, shared.ErrStaleRecord) {
+				status = http.StatusConflict
+			}
+			httpx.Problem(w, status, "Reorder failed", msg)
+			return
+		}
+		h.redirectWithFlash(w, r, detailURL, "error", msg)
+		return
+	}
+	if wantsJSON(r) {
+		httpx.JSON(w, http.StatusOK, map[string]any{
+			"lines":   order.Lines,
+			"version": shared.VersionToken(order.UpdatedAt),
+		})
+		return
+	}
+	h.redirectWithFlash(w, r, detailURL, "success", "Line order saved")
+}
+
+// reorderErrorMessage spells out status, version and line set problems.
+func reorderErrorMessage(err error) string {
+	if errors.Is(err, ErrInvalidStatus) || errors.Is(err, shared.ErrValidation) {
+		return err.Error()
+	}
+	return shared.UserSafeMessage(err)
+}
+
+// wantsJSON reports whether the client asked for a JSON representation.
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
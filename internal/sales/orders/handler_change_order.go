@@ -0,0 +1,128 @@
+package orders
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// RequestChange records a change order from the detail page. Quantities come
+// as repeated change_line_id/change_quantity pairs; blank quantities keep the
+// line as it is.
+func (h *Handler) RequestChange(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	detailURL := "/sales/orders/" + strconv.FormatInt(id, 10)
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Bad request", http.StatusBadRequest)
+		return
+	}
+	req := RequestChangeOrderRequest{Reason: r.PostFormValue("reason")}
+	lineIDs, quantities := r.PostForm["change_line_id"], r.PostForm["change_quantity"]
+	for i, raw := range lineIDs {
+		if i >= len(quantities) || strings.TrimSpace(quantities[i]) == "" {
+			continue
+		}
+		lineID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			httpx.Error(w, r, "Invalid line ID", http.StatusBadRequest)
+			return
+		}
+		qty, err := strconv.ParseFloat(strings.TrimSpace(quantities[i]), 64)
+		if err != nil {
+			h.redirectWithFlash(w, r, detailURL, "error", "Quantities must be numbers")
+			return
+		}
+		req.Lines = append(req.Lines, ChangeOrderLineReq{LineID: lineID, Quantity: qty})
+	}
+	if d := strings.TrimSpace(r.PostFormValue("expected_delivery_date")); d != "" {
+		t, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			h.redirectWithFlash(w, r, detailURL, "error", "Invalid delivery date")
+			return
+		}
+		req.ExpectedDeliveryDate = &t
+	}
+
+	change, err := h.service.RequestChange(r.Context(), id, req, h.getCurrentUserID(r))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "request order change failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, detailURL, "error", changeErrorMessage(err))
+		return
+	}
+	msg := "Change applied"
+	if change.Status == ChangeOrderStatusPending {
+		msg = "Change submitted for approval"
+	}
+	h.redirectWithFlash(w, r, detailURL+"#changes", "success", msg)
+}
+
+// ApproveChange applies a pending change order.
+func (h *Handler) ApproveChange(w http.ResponseWriter, r *http.Request) {
+	h.decideChange(w, r, true)
+}
+
+// RejectChange declines a pending change order; a note is required.
+func (h *Handler) RejectChange(w http.ResponseWriter, r *http.Request) {
+	h.decideChange(w, r, false)
+}
+
+func (h *Handler) decideChange(w http.ResponseWriter, r *http.Request, approve bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	changeID, err := strconv.ParseInt(chi.URLParam(r, "changeID"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid change ID", http.StatusBadRequest)
+		return
+	}
+	detailURL := "/sales/orders/" + strconv.FormatInt(id, 10)
+	change, err := h.service.GetChange(r.Context(), changeID)
+	if err != nil || change.SalesOrderID != id {
+		httpx.Error(w, r, "Change not found", http.StatusNotFound)
+		return
+	}
+	note := r.PostFormValue("note")
+	userID := h.getCurrentUserID(r)
+	msg := "Change approved and applied"
+	if approve {
+		_, err = h.service.ApproveChange(r.Context(), changeID, userID, note)
+	} else {
+		_, err = h.service.RejectChange(r.Context(), changeID, userID, note)
+		msg = "Change rejected"
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "decide order change failed", "error", err, "id", id, "change_id", changeID)
+		h.redirectWithFlash(w, r, detailURL+"#changes", "error", changeErrorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, detailURL+"#changes", "success", msg)
+}
+
+// changeErrorMessage spells out change order, status, credit and validation
+// problems.
+func changeErrorMessage(err error) string {
+	for _, known := range []error{ErrInvalidStatus, ErrChangeOrderPending, ErrChangeOrderNotPending, ErrNoChanges,
+		ErrChangeSelfApproval, ErrChangeReasonRequired, customers.ErrCreditHold, shared.ErrValidation} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+	if errors.Is(err, shared.ErrStaleRecord) {
+		return "The order changed after this change was requested. Reject it and request the change again."
+	}
+	return shared.UserSafeMessage(err)
+}
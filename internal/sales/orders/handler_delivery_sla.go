@@ -0,0 +1,54 @@
+package orders
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// DeliveryPerformance returns on-time delivery rates for sales orders expected
+// between date_from and date_to (YYYY-MM-DD, default the current year to
+// date), overall and per customer. customer_id narrows to one customer.
+func (h *Handler) DeliveryPerformance(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	filter := DeliveryPerformanceFilter{
+		CompanyID: h.getCurrentCompanyID(r),
+		DateFrom:  time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:    time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}
+	for param, target := range map[string]*time.Time{"date_from": &filter.DateFrom, "date_to": &filter.DateTo} {
+		if raw := q.Get(param); raw != "" {
+			t, err := time.Parse("2006-01-02", raw)
+			if err != nil {
+				httpx.Problem(w, http.StatusBadRequest, "Invalid date", param+" must be YYYY-MM-DD")
+				return
+			}
+			*target = t
+		}
+	}
+	if raw := q.Get("customer_id"); raw != "" {
+		customerID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || customerID <= 0 {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid customer", "customer_id must be a positive integer")
+			return
+		}
+		filter.CustomerID = &customerID
+	}
+
+	report, err := h.service.DeliveryPerformance(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, shared.ErrValidation) {
+			httpx.Problem(w, http.StatusBadRequest, "Invalid filter", err.Error())
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "delivery performance report failed", "error", err)
+		httpx.Problem(w, http.StatusInternalServerError, "Failed to load delivery performance report", "")
+		return
+	}
+	httpx.JSON(w, http.StatusOK, report)
+}
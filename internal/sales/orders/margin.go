@@ -0,0 +1,106 @@
+package orders
+
+import (
+	"context"
+	"math"
+)
+
+// SetMinMarginPercent sets the margin percentage below which orders are
+// flagged. Orders with an unknown margin are never flagged.
+func (s *Service) SetMinMarginPercent(percent float64) {
+	s.minMargin = percent
+}
+
+// GetWithMargin loads an order with its estimated margin and per-line
+// margins filled in.
+func (s *Service) GetWithMargin(ctx context.Context, id int64) (*SalesOrderWithDetails, error) {
+	order, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	detailed := []SalesOrderWithDetails{{SalesOrder: *order}}
+	if err := s.attachMargins(ctx, detailed); err != nil {
+		return nil, err
+	}
+	return &detailed[0], nil
+}
+
+func (s *Service) attachMargins(ctx context.Context, orders []SalesOrderWithDetails) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(orders))
+	for i := range orders {
+		ids[i] = orders[i].ID
+	}
+	costs, err := s.repo.LineCosts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	orderMargins, lineMargins := SummarizeMargins(costs, s.minMargin)
+	for i := range orders {
+		if m, ok := orderMargins[orders[i].ID]; ok {
+			orders[i].Margin = m
+		} else {
+			orders[i].Margin = &Margin{Known: true}
+		}
+		for j := range orders[i].Lines {
+			orders[i].Lines[j].Margin = lineMargins[orders[i].Lines[j].ID]
+		}
+	}
+	return nil
+}
+
+// SummarizeMargins computes per-order and per-line margins keyed by order and
+// line ID. An order is only flagged below minPercent when every line could be
+// costed and it has revenue.
+func SummarizeMargins(costs []LineCost, minPercent float64) (map[int64]*Margin, map[int64]*Margin) {
+	type totals struct {
+		revenue, costedRevenue, cost float64
+		unknown                      int
+	}
+	byOrder := make(map[int64]*totals)
+	lines := make(map[int64]*Margin, len(costs))
+	for _, c := range costs {
+		t, ok := byOrder[c.SalesOrderID]
+		if !ok {
+			t = &totals{}
+			byOrder[c.SalesOrderID] = t
+		}
+		t.revenue += c.Revenue
+		if !c.HasCost {
+			t.unknown++
+			lines[c.LineID] = buildMargin(c.Revenue, 0, 0, 1, minPercent)
+			continue
+		}
+		cost := c.Quantity * c.AvgCost
+		t.costedRevenue += c.Revenue
+		t.cost += cost
+		lines[c.LineID] = buildMargin(c.Revenue, c.Revenue, cost, 0, minPercent)
+	}
+	orders := make(map[int64]*Margin, len(byOrder))
+	for id, t := range byOrder {
+		orders[id] = buildMargin(t.revenue, t.costedRevenue, t.cost, t.unknown, minPercent)
+	}
+	return orders, lines
+}
+
+// buildMargin measures the margin on the costed part of revenue.
+func buildMargin(revenue, costedRevenue, cost float64, unknown int, minPercent float64) *Margin {
+	m := &Margin{
+		Revenue:      round2(revenue),
+		Cost:         round2(cost),
+		Amount:       round2(costedRevenue - cost),
+		Known:        unknown == 0,
+		UnknownLines: unknown,
+	}
+	if costedRevenue != 0 {
+		m.Percent = round2(m.Amount / costedRevenue * 100)
+	}
+	m.BelowMinimum = m.Known && revenue > 0 && m.Percent < minPercent
+	return m
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,60 @@
+package orders
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeMarginRepo struct {
+	Repository
+	order *SalesOrder
+	costs []LineCost
+}
+
+func (f *fakeMarginRepo) Get(context.Context, int64) (*SalesOrder, error) {
+	return f.order, nil
+}
+
+func (f *fakeMarginRepo) LineCosts(context.Context, []int64) ([]LineCost, error) {
+	return f.costs, nil
+}
+
+func TestGetWithMarginFlagsLowMarginAndUnknownCost(t *testing.T) {
+	repo := &fakeMarginRepo{
+		order: &SalesOrder{ID: 1, Lines: []SalesOrderLine{{ID: 11}, {ID: 12}}},
+		costs: []LineCost{
+			{SalesOrderID: 1, LineID: 11, Quantity: 10, Revenue: 1000, AvgCost: 95, HasCost: true},
+			{SalesOrderID: 1, LineID: 12, Quantity: 2, Revenue: 400, AvgCost: 150, HasCost: true},
+		},
+	}
+	svc := NewService(repo, nil, nil)
+	svc.SetMinMarginPercent(15)
+
+	order, err := svc.GetWithMargin(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetWithMargin: %v", err)
+	}
+	m := order.Margin
+	if !m.Known || m.Revenue != 1400 || m.Cost != 1250 || m.Amount != 150 || m.Percent != 10.71 || !m.BelowMinimum {
+		t.Fatalf("unexpected order margin %+v", m)
+	}
+	if l := order.Lines[0].Margin; l.Amount != 50 || l.Percent != 5 || !l.BelowMinimum {
+		t.Fatalf("unexpected line margin %+v", l)
+	}
+	if l := order.Lines[1].Margin; l.Percent != 25 || l.BelowMinimum {
+		t.Fatalf("unexpected line margin %+v", l)
+	}
+
+	repo.costs[1].HasCost = false
+	order, err = svc.GetWithMargin(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetWithMargin: %v", err)
+	}
+	m = order.Margin
+	if m.Known || m.UnknownLines != 1 || m.BelowMinimum || m.Amount != 50 {
+		t.Fatalf("expected unknown margin over costed lines only, got %+v", m)
+	}
+	if l := order.Lines[1].Margin; l.Known || l.Amount != 0 || l.Cost != 0 {
+		t.Fatalf("expected unknown line margin, got %+v", l)
+	}
+}
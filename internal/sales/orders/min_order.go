@@ -0,0 +1,110 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ErrBelowMinimumOrder is matched by every MinimumOrderError.
+var ErrBelowMinimumOrder = errors.New("order is below the minimum order value")
+
+// minimumTolerance absorbs float rounding when comparing order values.
+const minimumTolerance = 0.005
+
+// MinimumOrder is the minimum order value that applies to a customer of a
+// company, in the base currency. Customers with their own minimum replace
+// the company one; waived customers have none.
+type MinimumOrder struct {
+	Value  float64
+	Source string
+	Waived bool
+}
+
+// Minimum sources.
+const (
+	MinimumSourceCompany  = "company"
+	MinimumSourceCustomer = "customer"
+)
+
+// MinimumOrderError reports an order whose value before tax falls short of
+// the applicable minimum.
+type MinimumOrderError struct {
+	Value     float64
+	Minimum   float64
+	Shortfall float64
+	Currency  string
+	Source    string
+}
+
+func (e *MinimumOrderError) Error() string {
+	return fmt.Sprintf("%s: %.2f %s is %.2f short of the %s minimum of %.2f",
+		ErrBelowMinimumOrder, e.Value, e.Currency, e.Shortfall, e.Source, e.Minimum)
+}
+
+// Is makes errors.Is(err, ErrBelowMinimumOrder) hold.
+func (e *MinimumOrderError) Is(target error) bool {
+	return target == ErrBelowMinimumOrder
+}
+
+// SetRateResolver converts foreign-currency orders to the base currency
+// before they are compared with the minimum order value. Without it orders
+// are compared as entered.
+func (s *Service) SetRateResolver(rates internalShared.RateResolver) {
+	s.rates = rates
+}
+
+// checkMinimumOrder returns a MinimumOrderError when the order's value
+// before tax is below the minimum of its customer and company.
+func (s *Service) checkMinimumOrder(ctx context.Context, order *SalesOrder) (*MinimumOrderError, error) {
+	rule, err := s.repo.MinimumOrder(ctx, order.CompanyID, order.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("minimum order value: %w", err)
+	}
+	if rule.Waived || rule.Value <= 0 {
+		return nil, nil
+	}
+	value, currency := order.Subtotal, order.Currency
+	if s.rates != nil && order.Currency != "" && !strings.EqualFold(order.Currency, s.rates.BaseCurrency()) {
+		value, err = s.rates.Convert(ctx, order.Subtotal, order.Currency, s.rates.BaseCurrency(), order.OrderDate)
+		if err != nil {
+			return nil, fmt.Errorf("minimum order value: %w", err)
+		}
+		currency = s.rates.BaseCurrency()
+	}
+	if value+minimumTolerance >= rule.Value {
+		return nil, nil
+	}
+	return &MinimumOrderError{
+		Value:     value,
+		Minimum:   rule.Value,
+		Shortfall: rule.Value - value,
+		Currency:  currency,
+		Source:    rule.Source,
+	}, nil
+}
+
+// recordMinimumOverride audits an order confirmed below its minimum.
+func (s *Service) recordMinimumOverride(ctx context.Context, order *SalesOrder, below *MinimumOrderError) {
+	if s.audit == nil {
+		return
+	}
+	_ = s.audit.Record(ctx, internalShared.AuditLog{
+		ActorID:  internalShared.AuditActorFromContext(ctx),
+		Action:   "sales_order.min_order_override",
+		Entity:   "sales_orders",
+		EntityID: strconv.FormatInt(order.ID, 10),
+		Meta: map[string]any{
+			"number":    order.DocNumber,
+			"value":     below.Value,
+			"minimum":   below.Minimum,
+			"shortfall": below.Shortfall,
+			"currency":  below.Currency,
+			"source":    below.Source,
+		},
+	})
+}
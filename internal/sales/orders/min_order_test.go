@@ -0,0 +1,105 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type recordingAudit struct {
+	logs []internalShared.AuditLog
+}
+
+func (a *recordingAudit) Record(_ context.Context, log internalShared.AuditLog) error {
+	a.logs = append(a.logs, log)
+	return nil
+}
+
+type fixedRates struct{ rate float64 }
+
+func (r fixedRates) Convert(_ context.Context, amount float64, _, _ string, _ time.Time) (float64, error) {
+	return amount * r.rate, nil
+}
+
+func (fixedRates) BaseCurrency() string { return "IDR" }
+
+func smallOrderRepo() *fakeReserveRepo {
+	repo := reservableRepo()
+	repo.order.Currency = "IDR"
+	repo.order.Subtotal = 150
+	repo.order.TotalAmount = 166.5
+	repo.minimum = MinimumOrder{Value: 500, Source: MinimumSourceCompany}
+	return repo
+}
+
+func TestConfirmRejectsOrderBelowMinimumWithShortfall(t *testing.T) {
+	repo := smallOrderRepo()
+	svc := NewService(repo, nil, nil)
+
+	_, err := svc.Confirm(context.Background(), 9, 3)
+	if !errors.Is(err, ErrBelowMinimumOrder) {
+		t.Fatalf("expected ErrBelowMinimumOrder, got %v", err)
+	}
+	var below *MinimumOrderError
+	if !errors.As(err, &below) || below.Shortfall != 350 || below.Source != MinimumSourceCompany {
+		t.Fatalf("unexpected shortfall %+v", below)
+	}
+	if !strings.Contains(err.Error(), "350.00 short of the company minimum of 500.00") {
+		t.Fatalf("shortfall missing from message: %v", err)
+	}
+	if repo.status != "" {
+		t.Fatalf("order must stay unconfirmed, got %q", repo.status)
+	}
+}
+
+func TestConfirmOverrideBelowMinimumIsAudited(t *testing.T) {
+	repo := smallOrderRepo()
+	audit := &recordingAudit{}
+	svc := NewService(repo, nil, nil)
+	svc.SetAuditor(audit)
+
+	if _, err := svc.ConfirmWithOptions(context.Background(), 9, 3, ConfirmOptions{OverrideMinimumOrder: true}); err != nil {
+		t.Fatalf("confirm with override: %v", err)
+	}
+	if repo.status != SalesOrderStatusConfirmed {
+		t.Fatalf("expected order confirmed, got %q", repo.status)
+	}
+	if len(audit.logs) != 1 || audit.logs[0].Action != "sales_order.min_order_override" || audit.logs[0].Meta["shortfall"] != 350.0 {
+		t.Fatalf("expected override audit, got %+v", audit.logs)
+	}
+}
+
+func TestConfirmSkipsMinimumForWaivedCustomer(t *testing.T) {
+	repo := smallOrderRepo()
+	repo.minimum = MinimumOrder{Value: 500, Source: MinimumSourceCustomer, Waived: true}
+	svc := NewService(repo, nil, nil)
+
+	if _, err := svc.Confirm(context.Background(), 9, 3); err != nil {
+		t.Fatalf("waived customer must confirm: %v", err)
+	}
+}
+
+func TestConfirmComparesMinimumInBaseCurrency(t *testing.T) {
+	repo := smallOrderRepo()
+	repo.order.Currency = "USD"
+	repo.order.Subtotal = 10
+	repo.minimum = MinimumOrder{Value: 150000, Source: MinimumSourceCustomer}
+	svc := NewService(repo, nil, nil)
+	svc.SetRateResolver(fixedRates{rate: 16000})
+
+	if _, err := svc.Confirm(context.Background(), 9, 3); err != nil {
+		t.Fatalf("USD 10 at 16000 meets IDR 150000: %v", err)
+	}
+
+	repo.status = ""
+	repo.order.Subtotal = 9
+	_, err := svc.Confirm(context.Background(), 9, 3)
+	var below *MinimumOrderError
+	if !errors.As(err, &below) || below.Currency != "IDR" || below.Shortfall != 6000 {
+		t.Fatalf("expected IDR 6000 shortfall, got %v", err)
+	}
+}
@@ -5,10 +5,11 @@ import "time"
 type SalesOrderStatus string
 
 const (
-	SalesOrderStatusDraft     SalesOrderStatus = "DRAFT"
-	SalesOrderStatusConfirmed SalesOrderStatus = "CONFIRMED"
-	SalesOrderStatusCancelled SalesOrderStatus = "CANCELLED"
-	SalesOrderStatusCompleted SalesOrderStatus = "COMPLETED"
+	SalesOrderStatusDraft      SalesOrderStatus = "DRAFT"
+	SalesOrderStatusConfirmed  SalesOrderStatus = "CONFIRMED"
+	SalesOrderStatusProcessing SalesOrderStatus = "PROCESSING"
+	SalesOrderStatusCancelled  SalesOrderStatus = "CANCELLED"
+	SalesOrderStatusCompleted  SalesOrderStatus = "COMPLETED"
 )
 
 type SalesOrder struct {
@@ -34,6 +35,17 @@ type SalesOrder struct {
 	CreatedAt            time.Time        `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time        `json:"updated_at" db:"updated_at"`
 	Lines                []SalesOrderLine `json:"lines,omitempty" db:"-"`
+
+	// DocumentDiscountPercent, when non-zero, sets the document discount as a
+	// share of the discounted line subtotal; otherwise DocumentDiscountAmount
+	// is a fixed amount. DocumentDiscountAmount always holds the amount applied.
+	DocumentDiscountPercent float64 `json:"document_discount_percent" db:"document_discount_percent"`
+	DocumentDiscountAmount  float64 `json:"document_discount_amount" db:"document_discount_amount"`
+}
+
+// IsTerminal reports whether the order is cancelled or completed.
+func (o *SalesOrder) IsTerminal() bool {
+	return o.Status == SalesOrderStatusCancelled || o.Status == SalesOrderStatusCompleted
 }
 
 type SalesOrderLine struct {
@@ -53,6 +65,16 @@ type SalesOrderLine struct {
 	LineOrder       int       `json:"line_order" db:"line_order"`
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	Margin          *Margin   `json:"margin,omitempty" db:"-"`
+
+	// DocumentDiscountAmount is the line's share of the document discount,
+	// already included in DiscountAmount.
+	DocumentDiscountAmount float64 `json:"document_discount_amount" db:"document_discount_amount"`
+
+	// QuantityDelivered and QuantityInvoiced are maintained by delivery and
+	// invoicing; a change order cannot cut Quantity below either.
+	QuantityDelivered float64 `json:"quantity_delivered" db:"quantity_delivered"`
+	QuantityInvoiced  float64 `json:"quantity_invoiced" db:"quantity_invoiced"`
 }
 
 type SalesOrderWithDetails struct {
@@ -61,4 +83,30 @@ type SalesOrderWithDetails struct {
 	CreatedByName   string  `json:"created_by_name" db:"created_by_name"`
 	ConfirmedByName *string `json:"confirmed_by_name,omitempty" db:"confirmed_by_name"`
 	CancelledByName *string `json:"cancelled_by_name,omitempty" db:"cancelled_by_name"`
+	Margin          *Margin `json:"margin,omitempty" db:"-"`
+}
+
+// Margin is an estimated gross margin: revenue after discount and before tax,
+// less expected COGS at the current average cost. When a product has no cost
+// history Known is false and Cost, Amount and Percent cover only the lines
+// that could be costed.
+type Margin struct {
+	Revenue      float64 `json:"revenue"`
+	Cost         float64 `json:"cost"`
+	Amount       float64 `json:"amount"`
+	Percent      float64 `json:"percent"`
+	Known        bool    `json:"known"`
+	UnknownLines int     `json:"unknown_lines,omitempty"`
+	BelowMinimum bool    `json:"below_minimum"`
+}
+
+// LineCost pairs an order line with the average cost of its product. HasCost
+// is false when no warehouse holds cost history for the product.
+type LineCost struct {
+	SalesOrderID int64
+	LineID       int64
+	Quantity     float64
+	Revenue      float64
+	AvgCost      float64
+	HasCost      bool
 }
@@ -0,0 +1,47 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ReorderLines renumbers a DRAFT sales order's lines to follow req.LineIDs. The
+// IDs must be exactly the order's current lines.
+func (s *Service) ReorderLines(ctx context.Context, id int64, req ReorderLinesRequest) (*SalesOrder, error) {
+	existing, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+	if existing.Status != SalesOrderStatusDraft {
+		return nil, fmt.Errorf("%w: only DRAFT orders can be reordered", ErrInvalidStatus)
+	}
+	if err := internalShared.CheckVersion(req.ExpectedUpdatedAt, existing.UpdatedAt); err != nil {
+		return nil, err
+	}
+	current := make([]int64, 0, len(existing.Lines))
+	for _, line := range existing.Lines {
+		current = append(current, line.ID)
+	}
+	if err := shared.CheckLineOrder(current, req.LineIDs); err != nil {
+		return nil, err
+	}
+
+	// Touching the header bumps its version so open edit forms see the change.
+	updates := map[string]interface{}{}
+	if req.ExpectedUpdatedAt != nil {
+		updates["expected_updated_at"] = *req.ExpectedUpdatedAt
+	}
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		if err := repo.Update(ctx, id, updates); err != nil {
+			return err
+		}
+		return repo.ReorderLines(ctx, id, req.LineIDs)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reorder order lines: %w", err)
+	}
+	return s.repo.Get(ctx, id)
+}
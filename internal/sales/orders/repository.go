@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
@@ -30,7 +31,23 @@ type Repository interface {
 	UpdateStatus(ctx context.Context, id int64, status SalesOrderStatus, userID int64, reason *string) error
 	UpdateQuotationStatus(ctx context.Context, quotationID int64, status quotations.QuotationStatus) error
 	DeleteLines(ctx context.Context, orderID int64) error
+	ReorderLines(ctx context.Context, orderID int64, lineIDs []int64) error
 	GenerateNumber(ctx context.Context, companyID int64, date time.Time) (string, error)
+	LineCosts(ctx context.Context, orderIDs []int64) ([]LineCost, error)
+	DeliveryPerformanceRows(ctx context.Context, filter DeliveryPerformanceFilter) ([]DeliveryPerformanceRow, error)
+	UpdateLineAmounts(ctx context.Context, line SalesOrderLine) error
+	CreateChangeOrder(ctx context.Context, change ChangeOrder) (int64, error)
+	GetChangeOrder(ctx context.Context, id int64) (*ChangeOrder, error)
+	ListChangeOrders(ctx context.Context, orderID int64) ([]ChangeOrder, error)
+	DecideChangeOrder(ctx context.Context, id int64, status ChangeOrderStatus, actorID int64, note string, toTotal float64) error
+	ListWarehouses(ctx context.Context, companyID int64) ([]WarehouseOption, error)
+	WarehouseCompanyID(ctx context.Context, warehouseID int64) (int64, error)
+	LockStock(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64]float64, error)
+	ReservedStock(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64]float64, error)
+	InsertReservations(ctx context.Context, reservations []Reservation) error
+	ReleaseReservations(ctx context.Context, orderID int64) error
+	ListReservations(ctx context.Context, orderID int64) ([]Reservation, error)
+	MinimumOrder(ctx context.Context, companyID, customerID int64) (MinimumOrder, error)
 }
 
 type dbtx interface {
@@ -73,13 +90,16 @@ func (r *repository) Get(ctx context.Context, id int64) (*SalesOrder, error) {
 		return nil, err
 	}
 	o := mapOrderFromSqlc(row)
-	
+	if !shared.CompanyAllowed(ctx, o.CompanyID) {
+		return nil, ErrNotFound
+	}
+
 	lineRows, err := r.queries.GetSalesOrderLines(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	o.Lines = mapLinesFromSqlc(lineRows)
-	
+
 	return &o, nil
 }
 
@@ -129,6 +149,9 @@ func (r *repository) List(ctx context.Context, req ListSalesOrdersRequest) ([]Sa
 		args = append(args, *req.DateTo)
 		argPos++
 	}
+	if !req.IncludeArchived {
+		conditions = append(conditions, "so.archived_at IS NULL")
+	}
 
 	whereClause := "WHERE " + conditions[0]
 	for i := 1; i < len(conditions); i++ {
@@ -142,6 +165,22 @@ func (r *repository) List(ctx context.Context, req ListSalesOrdersRequest) ([]Sa
 		return nil, 0, err
 	}
 
+	// Keyset pagination: the total above ignores the cursor so callers still
+	// see the full result size.
+	pageClause := fmt.Sprintf("LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	pageArgs := []interface{}{req.Limit, req.Offset}
+	if req.Cursor != "" {
+		cursor, err := shared.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		whereClause += fmt.Sprintf(" AND (so.order_date, so.id) < ($%d::date, $%d)", argPos, argPos+1)
+		args = append(args, cursor.At, cursor.ID)
+		argPos += 2
+		pageClause = fmt.Sprintf("LIMIT $%d", argPos)
+		pageArgs = []interface{}{req.Limit}
+	}
+
 	query := fmt.Sprintf(`
 		SELECT so.id, so.doc_number, so.company_id, so.customer_id, so.quotation_id,
 		       so.order_date, so.expected_delivery_date, so.status, so.currency,
@@ -160,10 +199,10 @@ func (r *repository) List(ctx context.Context, req ListSalesOrdersRequest) ([]Sa
 		LEFT JOIN users u3 ON so.cancelled_by = u3.id
 		%s
 		ORDER BY so.order_date DESC, so.id DESC
-		LIMIT $%d OFFSET $%d
-	`, whereClause, argPos, argPos+1)
+		%s
+	`, whereClause, pageClause)
 
-	args = append(args, req.Limit, req.Offset)
+	args = append(args, pageArgs...)
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
@@ -193,22 +232,57 @@ func (r *repository) List(ctx context.Context, req ListSalesOrdersRequest) ([]Sa
 			return nil, 0, err
 		}
 
-		if quotationID.Valid { o.QuotationID = &quotationID.Int64 }
-		if orderDatePG.Valid { o.OrderDate = orderDatePG.Time }
-		if expectedDelivery.Valid { o.ExpectedDeliveryDate = &expectedDelivery.Time }
-		if subtotal.Valid { f, _ := subtotal.Float64Value(); o.Subtotal = f.Float64 }
-		if taxAmount.Valid { f, _ := taxAmount.Float64Value(); o.TaxAmount = f.Float64 }
-		if totalAmount.Valid { f, _ := totalAmount.Float64Value(); o.TotalAmount = f.Float64 }
-		if notes.Valid { o.Notes = &notes.String }
-		if confirmedBy.Valid { o.ConfirmedBy = &confirmedBy.Int64 }
-		if confirmedAt.Valid { o.ConfirmedAt = &confirmedAt.Time }
-		if cancelledBy.Valid { o.CancelledBy = &cancelledBy.Int64 }
-		if cancelledAt.Valid { o.CancelledAt = &cancelledAt.Time }
-		if cancellationReason.Valid { o.CancellationReason = &cancellationReason.String }
-		if createdAt.Valid { o.CreatedAt = createdAt.Time }
-		if updatedAt.Valid { o.UpdatedAt = updatedAt.Time }
-		if confirmedByName.Valid { o.ConfirmedByName = &confirmedByName.String }
-		if cancelledByName.Valid { o.CancelledByName = &cancelledByName.String }
+		if quotationID.Valid {
+			o.QuotationID = &quotationID.Int64
+		}
+		if orderDatePG.Valid {
+			o.OrderDate = orderDatePG.Time
+		}
+		if expectedDelivery.Valid {
+			o.ExpectedDeliveryDate = &expectedDelivery.Time
+		}
+		if subtotal.Valid {
+			f, _ := subtotal.Float64Value()
+			o.Subtotal = f.Float64
+		}
+		if taxAmount.Valid {
+			f, _ := taxAmount.Float64Value()
+			o.TaxAmount = f.Float64
+		}
+		if totalAmount.Valid {
+			f, _ := totalAmount.Float64Value()
+			o.TotalAmount = f.Float64
+		}
+		if notes.Valid {
+			o.Notes = &notes.String
+		}
+		if confirmedBy.Valid {
+			o.ConfirmedBy = &confirmedBy.Int64
+		}
+		if confirmedAt.Valid {
+			o.ConfirmedAt = &confirmedAt.Time
+		}
+		if cancelledBy.Valid {
+			o.CancelledBy = &cancelledBy.Int64
+		}
+		if cancelledAt.Valid {
+			o.CancelledAt = &cancelledAt.Time
+		}
+		if cancellationReason.Valid {
+			o.CancellationReason = &cancellationReason.String
+		}
+		if createdAt.Valid {
+			o.CreatedAt = createdAt.Time
+		}
+		if updatedAt.Valid {
+			o.UpdatedAt = updatedAt.Time
+		}
+		if confirmedByName.Valid {
+			o.ConfirmedByName = &confirmedByName.String
+		}
+		if cancelledByName.Valid {
+			o.CancelledByName = &cancelledByName.String
+		}
 
 		orders = append(orders, o)
 	}
@@ -229,6 +303,9 @@ func (r *repository) Create(ctx context.Context, o SalesOrder) (int64, error) {
 	subtotal.Scan(fmt.Sprintf("%f", o.Subtotal))
 	taxAmount.Scan(fmt.Sprintf("%f", o.TaxAmount))
 	totalAmount.Scan(fmt.Sprintf("%f", o.TotalAmount))
+	var docDiscountPercent, docDiscountAmount pgtype.Numeric
+	docDiscountPercent.Scan(fmt.Sprintf("%f", o.DocumentDiscountPercent))
+	docDiscountAmount.Scan(fmt.Sprintf("%f", o.DocumentDiscountAmount))
 
 	var orderDate pgtype.Date
 	if !o.OrderDate.IsZero() {
@@ -236,19 +313,21 @@ func (r *repository) Create(ctx context.Context, o SalesOrder) (int64, error) {
 	}
 
 	return r.queries.CreateSalesOrder(ctx, sqlc.CreateSalesOrderParams{
-		DocNumber:            o.DocNumber,
-		CompanyID:            o.CompanyID,
-		CustomerID:           o.CustomerID,
-		QuotationID:          quotationID,
-		OrderDate:            orderDate,
-		ExpectedDeliveryDate: expectedDelivery,
-		Status:               sqlc.SalesOrderStatus(o.Status),
-		Currency:             o.Currency,
-		Subtotal:             subtotal,
-		TaxAmount:            taxAmount,
-		TotalAmount:          totalAmount,
-		Notes:                pgtype.Text{String: getString(o.Notes), Valid: o.Notes != nil},
-		CreatedBy:            o.CreatedBy,
+		DocNumber:               o.DocNumber,
+		CompanyID:               o.CompanyID,
+		CustomerID:              o.CustomerID,
+		QuotationID:             quotationID,
+		OrderDate:               orderDate,
+		ExpectedDeliveryDate:    expectedDelivery,
+		Status:                  sqlc.SalesOrderStatus(o.Status),
+		Currency:                o.Currency,
+		Subtotal:                subtotal,
+		TaxAmount:               taxAmount,
+		TotalAmount:             totalAmount,
+		Notes:                   pgtype.Text{String: getString(o.Notes), Valid: o.Notes != nil},
+		CreatedBy:               o.CreatedBy,
+		DocumentDiscountPercent: docDiscountPercent,
+		DocumentDiscountAmount:  docDiscountAmount,
 	})
 }
 
@@ -256,7 +335,7 @@ func (r *repository) Update(ctx context.Context, id int64, updates map[string]in
 	query := "UPDATE sales_orders SET updated_at = NOW()"
 	var args []interface{}
 	argPos := 1
-	
+
 	if v, ok := updates["order_date"]; ok {
 		query += fmt.Sprintf(", order_date = $%d", argPos)
 		args = append(args, v)
@@ -287,12 +366,36 @@ func (r *repository) Update(ctx context.Context, id int64, updates map[string]in
 		args = append(args, v)
 		argPos++
 	}
-	
+	if v, ok := updates["document_discount_percent"]; ok {
+		query += fmt.Sprintf(", document_discount_percent = $%d", argPos)
+		args = append(args, v)
+		argPos++
+	}
+	if v, ok := updates["document_discount_amount"]; ok {
+		query += fmt.Sprintf(", document_discount_amount = $%d", argPos)
+		args = append(args, v)
+		argPos++
+	}
+
 	query += fmt.Sprintf(" WHERE id = $%d", argPos)
 	args = append(args, id)
-	
-	_, err := r.db.Exec(ctx, query, args...)
-	return err
+	argPos++
+
+	// Optimistic lock: only update the row version the caller loaded.
+	expected, guarded := updates["expected_updated_at"]
+	if guarded {
+		query += fmt.Sprintf(" AND updated_at = $%d", argPos)
+		args = append(args, expected)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if guarded && tag.RowsAffected() == 0 {
+		return shared.ErrStaleRecord
+	}
+	return nil
 }
 
 func (r *repository) InsertLine(ctx context.Context, line SalesOrderLine) (int64, error) {
@@ -304,21 +407,24 @@ func (r *repository) InsertLine(ctx context.Context, line SalesOrderLine) (int64
 	taxPercent.Scan(fmt.Sprintf("%f", line.TaxPercent))
 	taxAmount.Scan(fmt.Sprintf("%f", line.TaxAmount))
 	lineTotal.Scan(fmt.Sprintf("%f", line.LineTotal))
-	
+	var docDiscountAmount pgtype.Numeric
+	docDiscountAmount.Scan(fmt.Sprintf("%f", line.DocumentDiscountAmount))
+
 	return r.queries.InsertSalesOrderLine(ctx, sqlc.InsertSalesOrderLineParams{
-		SalesOrderID:    line.SalesOrderID,
-		ProductID:       line.ProductID,
-		Description:     pgtype.Text{String: getString(line.Description), Valid: line.Description != nil},
-		Quantity:        quantity,
-		Uom:             line.UOM,
-		UnitPrice:       unitPrice,
-		DiscountPercent: discountPercent,
-		DiscountAmount:  discountAmount,
-		TaxPercent:      taxPercent,
-		TaxAmount:       taxAmount,
-		LineTotal:       lineTotal,
-		Notes:           pgtype.Text{String: getString(line.Notes), Valid: line.Notes != nil},
-		LineOrder:       int32(line.LineOrder),
+		SalesOrderID:           line.SalesOrderID,
+		ProductID:              line.ProductID,
+		Description:            pgtype.Text{String: getString(line.Description), Valid: line.Description != nil},
+		Quantity:               quantity,
+		Uom:                    line.UOM,
+		UnitPrice:              unitPrice,
+		DiscountPercent:        discountPercent,
+		DiscountAmount:         discountAmount,
+		TaxPercent:             taxPercent,
+		TaxAmount:              taxAmount,
+		LineTotal:              lineTotal,
+		Notes:                  pgtype.Text{String: getString(line.Notes), Valid: line.Notes != nil},
+		LineOrder:              int32(line.LineOrder),
+		DocumentDiscountAmount: docDiscountAmount,
 	})
 }
 
@@ -387,15 +493,15 @@ func (r *repository) GenerateNumber(ctx context.Context, companyID int64, date t
 
 func mapOrderFromSqlc(row sqlc.SalesOrder) SalesOrder {
 	o := SalesOrder{
-		ID:          row.ID,
-		DocNumber:   row.DocNumber,
-		CompanyID:   row.CompanyID,
-		CustomerID:  row.CustomerID,
-		Status:      SalesOrderStatus(row.Status),
-		Currency:    row.Currency,
-		CreatedBy:   row.CreatedBy,
-		CreatedAt:   row.CreatedAt.Time,
-		UpdatedAt:   row.UpdatedAt.Time,
+		ID:         row.ID,
+		DocNumber:  row.DocNumber,
+		CompanyID:  row.CompanyID,
+		CustomerID: row.CustomerID,
+		Status:     SalesOrderStatus(row.Status),
+		Currency:   row.Currency,
+		CreatedBy:  row.CreatedBy,
+		CreatedAt:  row.CreatedAt.Time,
+		UpdatedAt:  row.UpdatedAt.Time,
 	}
 	if row.QuotationID.Valid {
 		val := row.QuotationID.Int64
@@ -420,6 +526,14 @@ func mapOrderFromSqlc(row sqlc.SalesOrder) SalesOrder {
 		f, _ := row.TotalAmount.Float64Value()
 		o.TotalAmount = f.Float64
 	}
+	if row.DocumentDiscountPercent.Valid {
+		f, _ := row.DocumentDiscountPercent.Float64Value()
+		o.DocumentDiscountPercent = f.Float64
+	}
+	if row.DocumentDiscountAmount.Valid {
+		f, _ := row.DocumentDiscountAmount.Float64Value()
+		o.DocumentDiscountAmount = f.Float64
+	}
 	if row.Notes.Valid {
 		val := row.Notes.String
 		o.Notes = &val
@@ -451,11 +565,11 @@ func mapLinesFromSqlc(rows []sqlc.SalesOrderLine) []SalesOrderLine {
 	var lines []SalesOrderLine
 	for _, l := range rows {
 		line := SalesOrderLine{
-			ID:              l.ID,
-			SalesOrderID:    l.SalesOrderID,
-			ProductID:       l.ProductID,
-			UOM:             l.Uom,
-			LineOrder:       int(l.LineOrder),
+			ID:           l.ID,
+			SalesOrderID: l.SalesOrderID,
+			ProductID:    l.ProductID,
+			UOM:          l.Uom,
+			LineOrder:    int(l.LineOrder),
 		}
 		if l.Description.Valid {
 			val := l.Description.String
@@ -465,6 +579,14 @@ func mapLinesFromSqlc(rows []sqlc.SalesOrderLine) []SalesOrderLine {
 			f, _ := l.Quantity.Float64Value()
 			line.Quantity = f.Float64
 		}
+		if l.QuantityDelivered.Valid {
+			f, _ := l.QuantityDelivered.Float64Value()
+			line.QuantityDelivered = f.Float64
+		}
+		if l.QuantityInvoiced.Valid {
+			f, _ := l.QuantityInvoiced.Float64Value()
+			line.QuantityInvoiced = f.Float64
+		}
 		if l.UnitPrice.Valid {
 			f, _ := l.UnitPrice.Float64Value()
 			line.UnitPrice = f.Float64
@@ -477,6 +599,10 @@ func mapLinesFromSqlc(rows []sqlc.SalesOrderLine) []SalesOrderLine {
 			f, _ := l.DiscountAmount.Float64Value()
 			line.DiscountAmount = f.Float64
 		}
+		if l.DocumentDiscountAmount.Valid {
+			f, _ := l.DocumentDiscountAmount.Float64Value()
+			line.DocumentDiscountAmount = f.Float64
+		}
 		if l.TaxPercent.Valid {
 			f, _ := l.TaxPercent.Float64Value()
 			line.TaxPercent = f.Float64
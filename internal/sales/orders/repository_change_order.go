@@ -0,0 +1,141 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// UpdateLineAmounts writes a repriced line's quantity and amounts in place.
+func (r *repository) UpdateLineAmounts(ctx context.Context, line SalesOrderLine) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE sales_order_lines
+		SET quantity = $2, discount_amount = $3, tax_amount = $4, line_total = $5,
+		    document_discount_amount = $6, updated_at = NOW()
+		WHERE id = $1
+	`, line.ID, line.Quantity, line.DiscountAmount, line.TaxAmount, line.LineTotal, line.DocumentDiscountAmount)
+	return err
+}
+
+// CreateChangeOrder stores a pending change. A second pending change for the
+// same order fails with ErrChangeOrderPending.
+func (r *repository) CreateChangeOrder(ctx context.Context, c ChangeOrder) (int64, error) {
+	lines, err := json.Marshal(changeLines(c.Lines))
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO sales_order_changes (sales_order_id, status, reason, lines, from_delivery_date,
+		    to_delivery_date, delivery_date_changed, from_total, to_total, requires_approval, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, c.SalesOrderID, c.Status, c.Reason, lines, c.FromDeliveryDate, c.ToDeliveryDate,
+		c.DeliveryDateChanged, c.FromTotal, c.ToTotal, c.RequiresApproval, c.RequestedBy).Scan(&id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return 0, ErrChangeOrderPending
+	}
+	return id, err
+}
+
+const changeOrderSelect = `
+	SELECT c.id, c.sales_order_id, so.company_id, c.status, c.reason, c.lines,
+	       c.from_delivery_date, c.to_delivery_date, c.delivery_date_changed,
+	       c.from_total::float8, c.to_total::float8, c.requires_approval,
+	       c.requested_by, COALESCE(NULLIF(ru.name, ''), ru.email, ''), c.requested_at,
+	       c.decided_by, COALESCE(NULLIF(du.name, ''), du.email, ''), c.decided_at,
+	       COALESCE(c.decision_note, '')
+	FROM sales_order_changes c
+	JOIN sales_orders so ON so.id = c.sales_order_id
+	LEFT JOIN users ru ON ru.id = c.requested_by
+	LEFT JOIN users du ON du.id = c.decided_by
+`
+
+// GetChangeOrder returns a change order of a company the caller may see.
+func (r *repository) GetChangeOrder(ctx context.Context, id int64) (*ChangeOrder, error) {
+	c, companyID, err := scanChangeOrder(r.db.QueryRow(ctx, changeOrderSelect+`WHERE c.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrChangeOrderNotFound
+		}
+		return nil, err
+	}
+	if !shared.CompanyAllowed(ctx, companyID) {
+		return nil, ErrChangeOrderNotFound
+	}
+	return &c, nil
+}
+
+// ListChangeOrders returns an order's changes, newest first.
+func (r *repository) ListChangeOrders(ctx context.Context, orderID int64) ([]ChangeOrder, error) {
+	rows, err := r.db.Query(ctx, changeOrderSelect+`WHERE c.sales_order_id = $1 ORDER BY c.requested_at DESC, c.id DESC`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ChangeOrder
+	for rows.Next() {
+		c, _, err := scanChangeOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// DecideChangeOrder closes a pending change. A change decided meanwhile fails
+// with ErrChangeOrderNotPending.
+func (r *repository) DecideChangeOrder(ctx context.Context, id int64, status ChangeOrderStatus, actorID int64, note string, toTotal float64) error {
+	var decidedBy *int64
+	if actorID > 0 {
+		decidedBy = &actorID
+	}
+	tag, err := r.db.Exec(ctx, `
+		UPDATE sales_order_changes
+		SET status = $2, decided_by = $3, decided_at = NOW(), decision_note = NULLIF($4, ''), to_total = $5
+		WHERE id = $1 AND status = 'PENDING'
+	`, id, status, decidedBy, note, toTotal)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrChangeOrderNotPending
+	}
+	return nil
+}
+
+func scanChangeOrder(row pgx.Row) (ChangeOrder, int64, error) {
+	var c ChangeOrder
+	var companyID int64
+	var lines []byte
+	var status string
+	err := row.Scan(&c.ID, &c.SalesOrderID, &companyID, &status, &c.Reason, &lines,
+		&c.FromDeliveryDate, &c.ToDeliveryDate, &c.DeliveryDateChanged,
+		&c.FromTotal, &c.ToTotal, &c.RequiresApproval,
+		&c.RequestedBy, &c.RequestedByName, &c.RequestedAt,
+		&c.DecidedBy, &c.DecidedByName, &c.DecidedAt,
+		&c.DecisionNote)
+	if err != nil {
+		return ChangeOrder{}, 0, err
+	}
+	if err := json.Unmarshal(lines, &c.Lines); err != nil {
+		return ChangeOrder{}, 0, err
+	}
+	c.Status = ChangeOrderStatus(status)
+	return c, companyID, nil
+}
+
+// changeLines keeps an empty change list as a JSON array.
+func changeLines(lines []ChangeOrderLine) []ChangeOrderLine {
+	if lines == nil {
+		return []ChangeOrderLine{}
+	}
+	return lines
+}
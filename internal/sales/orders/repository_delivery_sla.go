@@ -0,0 +1,49 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// DeliveryPerformanceRows returns the company's non-cancelled sales orders
+// expected within the filter, with their latest delivered delivery order. An
+// order is fully delivered once completed or every line is delivered in full.
+func (r *repository) DeliveryPerformanceRows(ctx context.Context, filter DeliveryPerformanceFilter) ([]DeliveryPerformanceRow, error) {
+	if !shared.CompanyAllowed(ctx, filter.CompanyID) {
+		return nil, nil
+	}
+	args := []interface{}{filter.CompanyID, filter.DateFrom, filter.DateTo}
+	customerFilter := ""
+	if filter.CustomerID != nil {
+		args = append(args, *filter.CustomerID)
+		customerFilter = fmt.Sprintf(" AND so.customer_id = $%d", len(args))
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT so.id, so.doc_number, so.customer_id, c.name, so.expected_delivery_date,
+		       (SELECT MAX(d.delivered_at) FROM delivery_orders d
+		         WHERE d.sales_order_id = so.id AND d.status = 'DELIVERED'),
+		       so.status = 'COMPLETED' OR NOT EXISTS (
+		           SELECT 1 FROM sales_order_lines l
+		           WHERE l.sales_order_id = so.id AND l.quantity_delivered < l.quantity)
+		FROM sales_orders so
+		JOIN customers c ON c.id = so.customer_id
+		WHERE so.company_id = $1 AND so.status <> 'CANCELLED'
+		  AND so.expected_delivery_date BETWEEN $2 AND $3`+customerFilter+`
+		ORDER BY so.expected_delivery_date, so.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []DeliveryPerformanceRow
+	for rows.Next() {
+		var row DeliveryPerformanceRow
+		if err := rows.Scan(&row.SalesOrderID, &row.DocNumber, &row.CustomerID, &row.CustomerName, &row.ExpectedDate, &row.FinalDeliveredAt, &row.FullyDelivered); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
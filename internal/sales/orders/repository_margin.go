@@ -0,0 +1,56 @@
+package orders
+
+import "context"
+
+// LineCosts returns every line of the given orders with the current average
+// cost of its product. Costs come from the warehouses of the order's active
+// delivery orders; orders not yet delivered use all warehouses of the order's
+// company. Stock-weighted averages are used where stock is on hand.
+func (r *repository) LineCosts(ctx context.Context, orderIDs []int64) ([]LineCost, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.db.Query(ctx, `WITH scope AS (
+    SELECT DISTINCT sales_order_id, warehouse_id
+    FROM delivery_orders
+    WHERE sales_order_id = ANY($1) AND status <> 'CANCELLED'
+)
+SELECT l.sales_order_id, l.id, l.quantity::float8,
+       (l.quantity * l.unit_price - l.discount_amount)::float8,
+       cost.avg_cost
+FROM sales_order_lines l
+JOIN sales_orders so ON so.id = l.sales_order_id
+LEFT JOIN LATERAL (
+    SELECT (CASE WHEN SUM(ib.qty) FILTER (WHERE ib.qty > 0) > 0
+                 THEN SUM(ib.qty * ib.avg_cost) FILTER (WHERE ib.qty > 0) / SUM(ib.qty) FILTER (WHERE ib.qty > 0)
+                 ELSE MAX(ib.avg_cost) END)::float8 AS avg_cost
+    FROM inventory_balances ib
+    JOIN warehouses w ON w.id = ib.warehouse_id
+    JOIN branches b ON b.id = w.branch_id
+    WHERE ib.product_id = l.product_id AND ib.avg_cost > 0
+      AND CASE WHEN EXISTS (SELECT 1 FROM scope s WHERE s.sales_order_id = so.id)
+               THEN ib.warehouse_id IN (SELECT s.warehouse_id FROM scope s WHERE s.sales_order_id = so.id)
+               ELSE b.company_id = so.company_id END
+) cost ON TRUE
+WHERE l.sales_order_id = ANY($1)
+ORDER BY l.sales_order_id, l.line_order, l.id`, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var costs []LineCost
+	for rows.Next() {
+		var (
+			c       LineCost
+			avgCost *float64
+		)
+		if err := rows.Scan(&c.SalesOrderID, &c.LineID, &c.Quantity, &c.Revenue, &avgCost); err != nil {
+			return nil, err
+		}
+		if avgCost != nil {
+			c.AvgCost, c.HasCost = *avgCost, true
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
+}
@@ -0,0 +1,26 @@
+package orders
+
+import "context"
+
+// MinimumOrder resolves the minimum order value of a customer: its own
+// minimum when set, otherwise the company's.
+func (r *repository) MinimumOrder(ctx context.Context, companyID, customerID int64) (MinimumOrder, error) {
+	var (
+		rule     MinimumOrder
+		customer *float64
+	)
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(c.min_order_waived, FALSE), c.min_order_value::float8, COALESCE(m.min_order_value, 0)::float8
+		FROM (SELECT $1::bigint AS company_id) k
+		LEFT JOIN company_min_order_values m ON m.company_id = k.company_id
+		LEFT JOIN customers c ON c.id = $2
+	`, companyID, customerID).Scan(&rule.Waived, &customer, &rule.Value)
+	if err != nil {
+		return MinimumOrder{}, err
+	}
+	rule.Source = MinimumSourceCompany
+	if customer != nil {
+		rule.Value, rule.Source = *customer, MinimumSourceCustomer
+	}
+	return rule, nil
+}
@@ -0,0 +1,14 @@
+package orders
+
+import "context"
+
+// ReorderLines sets each line's line_order to its 1-based position in lineIDs.
+func (r *repository) ReorderLines(ctx context.Context, orderID int64, lineIDs []int64) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE sales_order_lines AS l
+		SET line_order = o.position, updated_at = NOW()
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, position)
+		WHERE l.sales_order_id = $1 AND l.id = o.id
+	`, orderID, lineIDs)
+	return err
+}
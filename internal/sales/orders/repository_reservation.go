@@ -0,0 +1,143 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ListWarehouses returns the warehouses of the company by code.
+func (r *repository) ListWarehouses(ctx context.Context, companyID int64) ([]WarehouseOption, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT w.id, w.code, w.name
+		FROM warehouses w
+		JOIN branches b ON b.id = w.branch_id
+		WHERE b.company_id = $1
+		ORDER BY w.code
+	`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []WarehouseOption
+	for rows.Next() {
+		var w WarehouseOption
+		if err := rows.Scan(&w.ID, &w.Code, &w.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// WarehouseCompanyID returns the company owning the warehouse.
+func (r *repository) WarehouseCompanyID(ctx context.Context, warehouseID int64) (int64, error) {
+	var companyID int64
+	err := r.db.QueryRow(ctx, `
+		SELECT b.company_id FROM warehouses w JOIN branches b ON b.id = w.branch_id WHERE w.id = $1
+	`, warehouseID).Scan(&companyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return companyID, err
+}
+
+// LockStock touches the balance rows of the products so concurrent
+// reservations of the same stock serialise, and returns the on-hand
+// quantity per product in base units. Products without a balance row are
+// absent from the map.
+func (r *repository) LockStock(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64]float64, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE inventory_balances SET updated_at = NOW()
+		WHERE warehouse_id = $1 AND product_id = ANY($2)
+		RETURNING product_id, qty::float8
+	`, warehouseID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	return scanProductQuantities(rows)
+}
+
+// ReservedStock returns the open reserved quantity per product in base
+// units.
+func (r *repository) ReservedStock(ctx context.Context, warehouseID int64, productIDs []int64) (map[int64]float64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT product_id, SUM(open_base)::float8
+		FROM stock_reservation_open
+		WHERE warehouse_id = $1 AND product_id = ANY($2)
+		GROUP BY product_id
+	`, warehouseID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	return scanProductQuantities(rows)
+}
+
+func scanProductQuantities(rows pgx.Rows) (map[int64]float64, error) {
+	defer rows.Close()
+	out := make(map[int64]float64)
+	for rows.Next() {
+		var productID int64
+		var qty float64
+		if err := rows.Scan(&productID, &qty); err != nil {
+			return nil, err
+		}
+		out[productID] = qty
+	}
+	return out, rows.Err()
+}
+
+// InsertReservations stores the reservations.
+func (r *repository) InsertReservations(ctx context.Context, reservations []Reservation) error {
+	for _, res := range reservations {
+		if _, err := r.db.Exec(ctx, `
+			INSERT INTO stock_reservations (company_id, sales_order_id, sales_order_line_id, warehouse_id,
+				product_id, quantity, uom, base_factor, status, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, res.CompanyID, res.SalesOrderID, res.SalesOrderLineID, res.WarehouseID, res.ProductID,
+			res.Quantity, res.UOM, res.BaseFactor, res.Status, res.CreatedBy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseReservations releases the active reservations of the order.
+func (r *repository) ReleaseReservations(ctx context.Context, orderID int64) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE stock_reservations
+		SET status = 'RELEASED', closed_at = NOW(), updated_at = NOW()
+		WHERE sales_order_id = $1 AND status = 'ACTIVE'
+	`, orderID)
+	return err
+}
+
+// ListReservations returns the reservations of the order, newest first.
+func (r *repository) ListReservations(ctx context.Context, orderID int64) ([]Reservation, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT r.id, r.company_id, r.sales_order_id, r.sales_order_line_id, r.warehouse_id, w.name,
+			r.product_id, p.name, r.quantity::float8, r.uom, r.base_factor::float8,
+			r.delivered_quantity::float8, r.status, r.created_by, r.created_at
+		FROM stock_reservations r
+		JOIN warehouses w ON w.id = r.warehouse_id
+		JOIN products p ON p.id = r.product_id
+		WHERE r.sales_order_id = $1
+		ORDER BY r.created_at DESC, r.id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Reservation
+	for rows.Next() {
+		var res Reservation
+		if err := rows.Scan(&res.ID, &res.CompanyID, &res.SalesOrderID, &res.SalesOrderLineID, &res.WarehouseID,
+			&res.WarehouseName, &res.ProductID, &res.ProductName, &res.Quantity, &res.UOM, &res.BaseFactor,
+			&res.DeliveredQuantity, &res.Status, &res.CreatedBy, &res.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,165 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrInsufficientStockToReserve is returned when the warehouse cannot
+	// cover the quantity a confirmation asks to reserve.
+	ErrInsufficientStockToReserve = errors.New("not enough available stock to reserve")
+	// ErrReservationWarehouse is returned when the reservation warehouse
+	// belongs to another company.
+	ErrReservationWarehouse = errors.New("the reservation warehouse belongs to another company")
+)
+
+// ReservationStatus is the lifecycle of a stock reservation.
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "ACTIVE"    // Holding stock
+	ReservationFulfilled ReservationStatus = "FULFILLED" // Fully delivered
+	ReservationReleased  ReservationStatus = "RELEASED"  // Order cancelled
+)
+
+// Reservation holds stock of one order line in a warehouse until it is
+// delivered. Quantities are in the line's unit; BaseFactor converts them to
+// the product's base unit.
+type Reservation struct {
+	ID                int64             `json:"id"`
+	CompanyID         int64             `json:"company_id"`
+	SalesOrderID      int64             `json:"sales_order_id"`
+	SalesOrderLineID  int64             `json:"sales_order_line_id"`
+	WarehouseID       int64             `json:"warehouse_id"`
+	WarehouseName     string            `json:"warehouse_name,omitempty"`
+	ProductID         int64             `json:"product_id"`
+	ProductName       string            `json:"product_name,omitempty"`
+	Quantity          float64           `json:"quantity"`
+	UOM               string            `json:"uom"`
+	BaseFactor        float64           `json:"base_factor"`
+	DeliveredQuantity float64           `json:"delivered_quantity"`
+	Status            ReservationStatus `json:"status"`
+	CreatedBy         int64             `json:"created_by"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+// Open returns the quantity still held, in the line's unit.
+func (r Reservation) Open() float64 {
+	if r.Status != ReservationActive {
+		return 0
+	}
+	return r.Quantity - r.DeliveredQuantity
+}
+
+// WarehouseOption is a warehouse that can hold reservations.
+type WarehouseOption struct {
+	ID   int64  `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// ConfirmOptions tunes order confirmation. A zero ReserveWarehouseID
+// confirms without reserving stock. OverrideMinimumOrder lets an order below
+// the minimum order value be confirmed; the override is audited.
+type ConfirmOptions struct {
+	ReserveWarehouseID   int64
+	OverrideMinimumOrder bool
+}
+
+// ListReservationWarehouses returns the warehouses of the company.
+func (s *Service) ListReservationWarehouses(ctx context.Context, companyID int64) ([]WarehouseOption, error) {
+	return s.repo.ListWarehouses(ctx, companyID)
+}
+
+// ListReservations returns the reservations of an order.
+func (s *Service) ListReservations(ctx context.Context, orderID int64) ([]Reservation, error) {
+	return s.repo.ListReservations(ctx, orderID)
+}
+
+// reserve locks the warehouse stock of the order's products and reserves
+// every line in full, or fails with ErrInsufficientStockToReserve naming the
+// first product that is short.
+func (s *Service) reserve(ctx context.Context, tx Repository, order *SalesOrder, warehouseID, userID int64) error {
+	reservations, err := s.reservationsFor(ctx, order, warehouseID, userID)
+	if err != nil {
+		return err
+	}
+	productIDs := reservationProducts(reservations)
+	onHand, err := tx.LockStock(ctx, warehouseID, productIDs)
+	if err != nil {
+		return fmt.Errorf("lock stock: %w", err)
+	}
+	reserved, err := tx.ReservedStock(ctx, warehouseID, productIDs)
+	if err != nil {
+		return fmt.Errorf("reserved stock: %w", err)
+	}
+	if err := checkReservable(reservations, onHand, reserved); err != nil {
+		return err
+	}
+	return tx.InsertReservations(ctx, reservations)
+}
+
+// reservationsFor builds one reservation per order line for its full
+// quantity.
+func (s *Service) reservationsFor(ctx context.Context, order *SalesOrder, warehouseID, userID int64) ([]Reservation, error) {
+	out := make([]Reservation, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		factor := 1.0
+		if s.units != nil {
+			var err error
+			if factor, err = s.units.BaseFactor(ctx, line.ProductID, line.UOM); err != nil {
+				return nil, fmt.Errorf("unit of line %d: %w", line.LineOrder, err)
+			}
+		}
+		out = append(out, Reservation{
+			CompanyID:        order.CompanyID,
+			SalesOrderID:     order.ID,
+			SalesOrderLineID: line.ID,
+			WarehouseID:      warehouseID,
+			ProductID:        line.ProductID,
+			Quantity:         line.Quantity,
+			UOM:              line.UOM,
+			BaseFactor:       factor,
+			Status:           ReservationActive,
+			CreatedBy:        userID,
+		})
+	}
+	return out, nil
+}
+
+// checkReservable compares the base-unit demand per product with what is on
+// hand and not yet reserved by other orders.
+func checkReservable(reservations []Reservation, onHand, reserved map[int64]float64) error {
+	demand := make(map[int64]float64)
+	for _, r := range reservations {
+		demand[r.ProductID] += r.Quantity * r.BaseFactor
+	}
+	for _, productID := range reservationProducts(reservations) {
+		available := math.Max(onHand[productID]-reserved[productID], 0)
+		if demand[productID] > available+1e-9 {
+			return fmt.Errorf("%w: product %d needs %.4g, %.4g available",
+				ErrInsufficientStockToReserve, productID, demand[productID], available)
+		}
+	}
+	return nil
+}
+
+// reservationProducts lists the distinct products in ascending order.
+func reservationProducts(reservations []Reservation) []int64 {
+	seen := make(map[int64]struct{}, len(reservations))
+	var out []int64
+	for _, r := range reservations {
+		if _, ok := seen[r.ProductID]; ok {
+			continue
+		}
+		seen[r.ProductID] = struct{}{}
+		out = append(out, r.ProductID)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
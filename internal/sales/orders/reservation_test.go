@@ -0,0 +1,160 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeReserveRepo struct {
+	Repository
+	order        *SalesOrder
+	warehouseCo  int64
+	onHand       map[int64]float64
+	reserved     map[int64]float64
+	status       SalesOrderStatus
+	reservations []Reservation
+	released     bool
+	minimum      MinimumOrder
+}
+
+func (f *fakeReserveRepo) WithTx(ctx context.Context, fn func(context.Context, Repository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeReserveRepo) Get(context.Context, int64) (*SalesOrder, error) {
+	copied := *f.order
+	return &copied, nil
+}
+
+func (f *fakeReserveRepo) UpdateStatus(_ context.Context, _ int64, status SalesOrderStatus, _ int64, _ *string) error {
+	f.status = status
+	return nil
+}
+
+func (f *fakeReserveRepo) WarehouseCompanyID(context.Context, int64) (int64, error) {
+	return f.warehouseCo, nil
+}
+
+func (f *fakeReserveRepo) LockStock(context.Context, int64, []int64) (map[int64]float64, error) {
+	return f.onHand, nil
+}
+
+func (f *fakeReserveRepo) ReservedStock(context.Context, int64, []int64) (map[int64]float64, error) {
+	return f.reserved, nil
+}
+
+func (f *fakeReserveRepo) InsertReservations(_ context.Context, reservations []Reservation) error {
+	f.reservations = append(f.reservations, reservations...)
+	return nil
+}
+
+func (f *fakeReserveRepo) ReleaseReservations(context.Context, int64) error {
+	f.released = true
+	return nil
+}
+
+func (f *fakeReserveRepo) MinimumOrder(context.Context, int64, int64) (MinimumOrder, error) {
+	return f.minimum, nil
+}
+
+type fakeBoxUnits struct{}
+
+func (fakeBoxUnits) BaseFactor(_ context.Context, _ int64, uom string) (float64, error) {
+	if uom == "BOX" {
+		return 12, nil
+	}
+	return 1, nil
+}
+
+func reservableRepo() *fakeReserveRepo {
+	return &fakeReserveRepo{
+		order: &SalesOrder{
+			ID: 9, CompanyID: 1, Status: SalesOrderStatusDraft, TotalAmount: 100,
+			Lines: []SalesOrderLine{
+				{ID: 91, ProductID: 5, Quantity: 2, UOM: "BOX", LineOrder: 1},
+				{ID: 92, ProductID: 5, Quantity: 6, UOM: "PCS", LineOrder: 2},
+				{ID: 93, ProductID: 6, Quantity: 1, UOM: "PCS", LineOrder: 3},
+			},
+		},
+		warehouseCo: 1,
+		// Product 5: 40 on hand, 10 held by another order, so 30 available
+		// for a demand of 2 boxes of 12 plus 6 pieces.
+		onHand:   map[int64]float64{5: 40, 6: 1},
+		reserved: map[int64]float64{5: 10},
+	}
+}
+
+func TestConfirmReservesEveryLineInBaseUnits(t *testing.T) {
+	repo := reservableRepo()
+	svc := NewService(repo, nil, nil)
+	svc.SetUnitConverter(fakeBoxUnits{})
+
+	if _, err := svc.ConfirmWithOptions(context.Background(), 9, 3, ConfirmOptions{ReserveWarehouseID: 4}); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if repo.status != SalesOrderStatusConfirmed {
+		t.Fatalf("expected order confirmed, got %q", repo.status)
+	}
+	if len(repo.reservations) != 3 {
+		t.Fatalf("expected one reservation per line, got %+v", repo.reservations)
+	}
+	box := repo.reservations[0]
+	if box.SalesOrderLineID != 91 || box.WarehouseID != 4 || box.Quantity != 2 || box.BaseFactor != 12 || box.Status != ReservationActive {
+		t.Fatalf("unexpected box reservation %+v", box)
+	}
+}
+
+func TestConfirmRejectsReservationBeyondAvailableStock(t *testing.T) {
+	repo := reservableRepo()
+	repo.reserved[5] = 11
+	svc := NewService(repo, nil, nil)
+	svc.SetUnitConverter(fakeBoxUnits{})
+
+	_, err := svc.ConfirmWithOptions(context.Background(), 9, 3, ConfirmOptions{ReserveWarehouseID: 4})
+	if !errors.Is(err, ErrInsufficientStockToReserve) {
+		t.Fatalf("expected ErrInsufficientStockToReserve, got %v", err)
+	}
+	if len(repo.reservations) != 0 {
+		t.Fatalf("nothing must be reserved, got %+v", repo.reservations)
+	}
+}
+
+func TestConfirmRejectsWarehouseOfAnotherCompany(t *testing.T) {
+	repo := reservableRepo()
+	repo.warehouseCo = 2
+	svc := NewService(repo, nil, nil)
+
+	_, err := svc.ConfirmWithOptions(context.Background(), 9, 3, ConfirmOptions{ReserveWarehouseID: 4})
+	if !errors.Is(err, ErrReservationWarehouse) {
+		t.Fatalf("expected ErrReservationWarehouse, got %v", err)
+	}
+	if repo.status != "" {
+		t.Fatalf("order must stay draft, got %q", repo.status)
+	}
+}
+
+func TestConfirmWithoutWarehouseReservesNothing(t *testing.T) {
+	repo := reservableRepo()
+	svc := NewService(repo, nil, nil)
+
+	if _, err := svc.Confirm(context.Background(), 9, 3); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if len(repo.reservations) != 0 {
+		t.Fatalf("expected no reservations, got %+v", repo.reservations)
+	}
+}
+
+func TestCancelReleasesReservations(t *testing.T) {
+	repo := reservableRepo()
+	repo.order.Status = SalesOrderStatusConfirmed
+	svc := NewService(repo, nil, nil)
+
+	if _, err := svc.Cancel(context.Background(), 9, 3, "customer withdrew"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if repo.status != SalesOrderStatusCancelled || !repo.released {
+		t.Fatalf("expected cancelled order with released reservations, got %q released=%v", repo.status, repo.released)
+	}
+}
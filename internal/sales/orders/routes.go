@@ -8,7 +8,9 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAny("sales.order.view"))
 		r.Get("/orders", h.List)
+		r.Get("/orders/delivery-performance", h.DeliveryPerformance)
 		r.Get("/orders/{id}", h.Show)
+		r.Post("/orders/{id}/comments", h.Comment)
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAll("sales.order.create"))
@@ -20,7 +22,14 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAll("sales.order.edit"))
 		r.Get("/orders/{id}/edit", h.ShowEditForm)
 		r.Post("/orders/{id}/edit", h.Update)
+		r.Post("/orders/{id}/lines/reorder", h.ReorderLines)
 		r.Post("/orders/{id}/confirm", h.Confirm)
 		r.Post("/orders/{id}/cancel", h.Cancel)
+		r.Post("/orders/{id}/changes", h.RequestChange)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAll("sales.order.confirm"))
+		r.Post("/orders/{id}/changes/{changeID}/approve", h.ApproveChange)
+		r.Post("/orders/{id}/changes/{changeID}/reject", h.RejectChange)
 	})
 }
@@ -4,21 +4,43 @@ import (
 	"context"
 	"errors"
 	"fmt"
-
+	"strconv"
+	"time"
 
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/shared"
+	internalShared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 var (
 	ErrInvalidStatus = errors.New("invalid status transition")
 )
 
+// CreditChecker evaluates customer credit holds before orders are committed.
+type CreditChecker interface {
+	EvaluateCreditHold(ctx context.Context, customerID int64, pendingAmount float64, actorID int64) (*customers.CreditHold, error)
+}
+
+// AuditPort records sales order changes.
+type AuditPort interface {
+	Record(ctx context.Context, log internalShared.AuditLog) error
+}
+
 type Service struct {
 	repo         Repository
 	customerRepo customers.Repository
 	quoteRepo    quotations.Repository
+	credit       CreditChecker
+	audit        AuditPort
+	minMargin    float64
+	currencies   internalShared.CurrencyValidator
+	units        shared.UnitConverter
+	changeLimit  float64
+	approvals    ApprovalPort
+	router       internalShared.ApprovalRouter
+	taxes        internalShared.DefaultTaxResolver
+	rates        internalShared.RateResolver
 }
 
 func NewService(repo Repository, customerRepo customers.Repository, quoteRepo quotations.Repository) *Service {
@@ -29,11 +51,44 @@ func NewService(repo Repository, customerRepo customers.Repository, quoteRepo qu
 	}
 }
 
+// SetCreditChecker enables credit hold evaluation on order creation and
+// confirmation.
+func (s *Service) SetCreditChecker(checker CreditChecker) {
+	s.credit = checker
+}
+
+// SetAuditor enables before/after audit snapshots on updates.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
+}
+
+// SetCurrencyValidator rejects new orders in inactive currencies.
+func (s *Service) SetCurrencyValidator(v internalShared.CurrencyValidator) {
+	s.currencies = v
+}
+
+// SetDefaultTaxResolver fills lines marked UseDefaultTax from the customer's
+// default tax.
+func (s *Service) SetDefaultTaxResolver(taxes internalShared.DefaultTaxResolver) {
+	s.taxes = taxes
+}
+
+// SetUnitConverter restricts line units to the product's base and alternate
+// units.
+func (s *Service) SetUnitConverter(units shared.UnitConverter) {
+	s.units = units
+}
+
 func (s *Service) Create(ctx context.Context, req CreateSalesOrderRequest, createdBy int64) (*SalesOrder, error) {
 	_, err := s.customerRepo.Get(ctx, req.CustomerID)
 	if err != nil {
 		return nil, fmt.Errorf("verify customer: %w", err)
 	}
+	if s.currencies != nil {
+		if err := s.currencies.ValidateCurrency(ctx, req.Currency); err != nil {
+			return nil, err
+		}
+	}
 
 	if req.QuotationID != nil {
 		q, err := s.quoteRepo.Get(ctx, *req.QuotationID)
@@ -43,10 +98,13 @@ func (s *Service) Create(ctx context.Context, req CreateSalesOrderRequest, creat
 		if q.Status == quotations.QuotationStatusConverted {
 			return nil, errors.New("quotation already converted to sales order")
 		}
-		if q.Status != quotations.QuotationStatusApproved {
-			return nil, errors.New("quotation must be approved to create sales order")
+		if err := quotations.QuotationTransitions.Validate(q.Status, quotations.QuotationStatusConverted); err != nil {
+			return nil, fmt.Errorf("quotation must be approved to create sales order: %w", err)
+		}
+		if err := quotations.CheckConvertible(ctx, s.quoteRepo, q.ID); err != nil {
+			return nil, err
 		}
-		// logic to check if already converted? 
+		// logic to check if already converted?
 		// For now simplifying.
 	}
 
@@ -55,33 +113,38 @@ func (s *Service) Create(ctx context.Context, req CreateSalesOrderRequest, creat
 		return nil, fmt.Errorf("generate doc number: %w", err)
 	}
 
-	var subtotal, taxAmount, totalAmount float64
-	for _, lineReq := range req.Lines {
-		discount, tax, lineTotal := shared.CalculateLineTotals(
-			lineReq.Quantity,
-			lineReq.UnitPrice,
-			lineReq.DiscountPercent,
-			lineReq.TaxPercent,
-		)
-		subtotal += (lineReq.Quantity * lineReq.UnitPrice) - discount
-		taxAmount += tax
-		totalAmount += lineTotal
+	if err := shared.CheckUnits(ctx, s.units, lineUnits(req.Lines)); err != nil {
+		return nil, err
+	}
+	if err := s.applyDefaultTax(ctx, req.CustomerID, req.OrderDate, req.Lines); err != nil {
+		return nil, err
 	}
 
+	totals, err := priceLines(req.Lines, shared.DocumentDiscount{
+		Percent: req.DocumentDiscountPercent,
+		Amount:  req.DocumentDiscountAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	totalAmount := totals.TotalAmount
+
 	order := SalesOrder{
-		DocNumber:            docNumber,
-		CompanyID:            req.CompanyID,
-		CustomerID:           req.CustomerID,
-		QuotationID:          req.QuotationID,
-		OrderDate:            req.OrderDate,
-		ExpectedDeliveryDate: req.ExpectedDeliveryDate,
-		Status:               SalesOrderStatusDraft,
-		Currency:             req.Currency,
-		Subtotal:             subtotal,
-		TaxAmount:            taxAmount,
-		TotalAmount:          totalAmount,
-		Notes:                req.Notes,
-		CreatedBy:            createdBy,
+		DocNumber:               docNumber,
+		CompanyID:               req.CompanyID,
+		CustomerID:              req.CustomerID,
+		QuotationID:             req.QuotationID,
+		OrderDate:               req.OrderDate,
+		ExpectedDeliveryDate:    req.ExpectedDeliveryDate,
+		Status:                  SalesOrderStatusDraft,
+		Currency:                req.Currency,
+		Subtotal:                totals.Subtotal,
+		TaxAmount:               totals.TaxAmount,
+		TotalAmount:             totals.TotalAmount,
+		Notes:                   req.Notes,
+		CreatedBy:               createdBy,
+		DocumentDiscountPercent: req.DocumentDiscountPercent,
+		DocumentDiscountAmount:  totals.DocumentDiscountAmount,
 	}
 
 	var orderID int64
@@ -93,27 +156,22 @@ func (s *Service) Create(ctx context.Context, req CreateSalesOrderRequest, creat
 		orderID = id
 
 		for i, lineReq := range req.Lines {
-			discount, tax, lineTotal := shared.CalculateLineTotals(
-				lineReq.Quantity,
-				lineReq.UnitPrice,
-				lineReq.DiscountPercent,
-				lineReq.TaxPercent,
-			)
-
+			amounts := totals.Lines[i]
 			line := SalesOrderLine{
-				SalesOrderID:    orderID,
-				ProductID:       lineReq.ProductID,
-				Description:     lineReq.Description,
-				Quantity:        lineReq.Quantity,
-				UOM:             lineReq.UOM,
-				UnitPrice:       lineReq.UnitPrice,
-				DiscountPercent: lineReq.DiscountPercent,
-				DiscountAmount:  discount,
-				TaxPercent:      lineReq.TaxPercent,
-				TaxAmount:       tax,
-				LineTotal:       lineTotal,
-				Notes:           lineReq.Notes,
-				LineOrder:       lineReq.LineOrder,
+				SalesOrderID:           orderID,
+				ProductID:              lineReq.ProductID,
+				Description:            lineReq.Description,
+				Quantity:               lineReq.Quantity,
+				UOM:                    lineReq.UOM,
+				UnitPrice:              lineReq.UnitPrice,
+				DiscountPercent:        lineReq.DiscountPercent,
+				DiscountAmount:         amounts.DiscountAmount,
+				TaxPercent:             lineReq.TaxPercent,
+				TaxAmount:              amounts.TaxAmount,
+				LineTotal:              amounts.LineTotal,
+				Notes:                  lineReq.Notes,
+				LineOrder:              lineReq.LineOrder,
+				DocumentDiscountAmount: amounts.DocumentDiscountAmount,
 			}
 			if line.LineOrder == 0 {
 				line.LineOrder = i + 1
@@ -136,6 +194,13 @@ func (s *Service) Create(ctx context.Context, req CreateSalesOrderRequest, creat
 		return nil, err
 	}
 
+	// The order is kept as a draft; a resulting hold only blocks confirmation.
+	if s.credit != nil {
+		if _, err := s.credit.EvaluateCreditHold(ctx, req.CustomerID, totalAmount, createdBy); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.repo.Get(ctx, orderID)
 }
 
@@ -148,36 +213,66 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateSalesOrderRequ
 	if existing.Status != SalesOrderStatusDraft {
 		return nil, fmt.Errorf("%w: can only update DRAFT orders", ErrInvalidStatus)
 	}
+	if err := internalShared.CheckVersion(req.ExpectedUpdatedAt, existing.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	discount := shared.DocumentDiscount{Percent: existing.DocumentDiscountPercent, Amount: existing.DocumentDiscountAmount}
+	discountChanged := req.DocumentDiscountPercent != nil || req.DocumentDiscountAmount != nil
+	if discountChanged {
+		discount = shared.DocumentDiscount{}
+		if req.DocumentDiscountPercent != nil {
+			discount.Percent = *req.DocumentDiscountPercent
+		}
+		if req.DocumentDiscountAmount != nil {
+			discount.Amount = *req.DocumentDiscountAmount
+		}
+	}
+	if req.Lines != nil {
+		if err := shared.CheckUnits(ctx, s.units, lineUnits(*req.Lines)); err != nil {
+			return nil, err
+		}
+		orderDate := existing.OrderDate
+		if req.OrderDate != nil {
+			orderDate = *req.OrderDate
+		}
+		if err := s.applyDefaultTax(ctx, existing.CustomerID, orderDate, *req.Lines); err != nil {
+			return nil, err
+		}
+	}
+	// A new document discount re-prices the current lines.
+	if req.Lines == nil && discountChanged {
+		current := requestLines(existing.Lines)
+		req.Lines = &current
+	}
 
 	var subtotal, taxAmount, totalAmount float64
 	var linesToInsert []SalesOrderLine
 
 	if req.Lines != nil && len(*req.Lines) > 0 {
+		totals, err := priceLines(*req.Lines, discount)
+		if err != nil {
+			return nil, err
+		}
+		subtotal, taxAmount, totalAmount = totals.Subtotal, totals.TaxAmount, totals.TotalAmount
+		discount.Amount = totals.DocumentDiscountAmount
 		for i, lineReq := range *req.Lines {
-			discount, tax, lineTotal := shared.CalculateLineTotals(
-				lineReq.Quantity,
-				lineReq.UnitPrice,
-				lineReq.DiscountPercent,
-				lineReq.TaxPercent,
-			)
-			subtotal += (lineReq.Quantity * lineReq.UnitPrice) - discount
-			taxAmount += tax
-			totalAmount += lineTotal
-
+			amounts := totals.Lines[i]
 			line := SalesOrderLine{
-				SalesOrderID:    id,
-				ProductID:       lineReq.ProductID,
-				Description:     lineReq.Description,
-				Quantity:        lineReq.Quantity,
-				UOM:             lineReq.UOM,
-				UnitPrice:       lineReq.UnitPrice,
-				DiscountPercent: lineReq.DiscountPercent,
-				DiscountAmount:  discount,
-				TaxPercent:      lineReq.TaxPercent,
-				TaxAmount:       tax,
-				LineTotal:       lineTotal,
-				Notes:           lineReq.Notes,
-				LineOrder:       lineReq.LineOrder,
+				SalesOrderID:           id,
+				ProductID:              lineReq.ProductID,
+				Description:            lineReq.Description,
+				Quantity:               lineReq.Quantity,
+				UOM:                    lineReq.UOM,
+				UnitPrice:              lineReq.UnitPrice,
+				DiscountPercent:        lineReq.DiscountPercent,
+				DiscountAmount:         amounts.DiscountAmount,
+				TaxPercent:             lineReq.TaxPercent,
+				TaxAmount:              amounts.TaxAmount,
+				LineTotal:              amounts.LineTotal,
+				Notes:                  lineReq.Notes,
+				LineOrder:              lineReq.LineOrder,
+				DocumentDiscountAmount: amounts.DocumentDiscountAmount,
 			}
 			if line.LineOrder == 0 {
 				line.LineOrder = i + 1
@@ -204,6 +299,11 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateSalesOrderRequ
 		updates["subtotal"] = subtotal
 		updates["tax_amount"] = taxAmount
 		updates["total_amount"] = totalAmount
+		updates["document_discount_percent"] = discount.Percent
+		updates["document_discount_amount"] = discount.Amount
+	}
+	if req.ExpectedUpdatedAt != nil {
+		updates["expected_updated_at"] = *req.ExpectedUpdatedAt
 	}
 
 	err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
@@ -229,10 +329,33 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateSalesOrderRequ
 		return nil, fmt.Errorf("update order: %w", err)
 	}
 
-	return s.repo.Get(ctx, id)
+	updated, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.audit != nil {
+		_ = s.audit.Record(ctx, internalShared.AuditLog{
+			ActorID:  internalShared.AuditActorFromContext(ctx),
+			Action:   "sales_order.update",
+			Entity:   "sales_orders",
+			EntityID: strconv.FormatInt(id, 10),
+			Meta:     map[string]any{"number": updated.DocNumber},
+			Before:   existing,
+			After:    updated,
+		})
+	}
+	return updated, nil
 }
 
 func (s *Service) Confirm(ctx context.Context, id int64, userID int64) (*SalesOrder, error) {
+	return s.ConfirmWithOptions(ctx, id, userID, ConfirmOptions{})
+}
+
+// ConfirmWithOptions confirms a DRAFT order and, when opts names a
+// warehouse, reserves the ordered quantities there in the same transaction.
+// An order below its minimum order value is rejected with a
+// MinimumOrderError unless opts.OverrideMinimumOrder is set.
+func (s *Service) ConfirmWithOptions(ctx context.Context, id int64, userID int64, opts ConfirmOptions) (*SalesOrder, error) {
 	existing, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get order: %w", err)
@@ -242,9 +365,48 @@ func (s *Service) Confirm(ctx context.Context, id int64, userID int64) (*SalesOr
 		return nil, fmt.Errorf("%w: can only confirm DRAFT orders", ErrInvalidStatus)
 	}
 
-	err = s.repo.UpdateStatus(ctx, id, SalesOrderStatusConfirmed, userID, nil)
+	if s.credit != nil {
+		hold, err := s.credit.EvaluateCreditHold(ctx, existing.CustomerID, existing.TotalAmount, userID)
+		if err != nil {
+			return nil, err
+		}
+		if hold != nil {
+			return nil, fmt.Errorf("%w: %s", customers.ErrCreditHold, hold.Reason)
+		}
+	}
+
+	below, err := s.checkMinimumOrder(ctx, existing)
+	if err != nil {
+		return nil, err
+	}
+	if below != nil && !opts.OverrideMinimumOrder {
+		return nil, below
+	}
+
+	if opts.ReserveWarehouseID != 0 {
+		companyID, err := s.repo.WarehouseCompanyID(ctx, opts.ReserveWarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("reservation warehouse: %w", err)
+		}
+		if companyID != existing.CompanyID {
+			return nil, ErrReservationWarehouse
+		}
+	}
+
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		if err := repo.UpdateStatus(ctx, id, SalesOrderStatusConfirmed, userID, nil); err != nil {
+			return fmt.Errorf("confirm order: %w", err)
+		}
+		if opts.ReserveWarehouseID == 0 {
+			return nil
+		}
+		return s.reserve(ctx, repo, existing, opts.ReserveWarehouseID, userID)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("confirm order: %w", err)
+		return nil, err
+	}
+	if below != nil {
+		s.recordMinimumOverride(ctx, existing, below)
 	}
 
 	return s.repo.Get(ctx, id)
@@ -260,9 +422,17 @@ func (s *Service) Cancel(ctx context.Context, id int64, cancelledBy int64, reaso
 		return nil, fmt.Errorf("%w: order is already final", ErrInvalidStatus)
 	}
 
-	err = s.repo.UpdateStatus(ctx, id, SalesOrderStatusCancelled, cancelledBy, &reason)
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		if err := repo.UpdateStatus(ctx, id, SalesOrderStatusCancelled, cancelledBy, &reason); err != nil {
+			return fmt.Errorf("cancel order: %w", err)
+		}
+		if err := repo.ReleaseReservations(ctx, id); err != nil {
+			return fmt.Errorf("release reservations: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("cancel order: %w", err)
+		return nil, err
 	}
 
 	return s.repo.Get(ctx, id)
@@ -273,5 +443,96 @@ func (s *Service) Get(ctx context.Context, id int64) (*SalesOrder, error) {
 }
 
 func (s *Service) List(ctx context.Context, req ListSalesOrdersRequest) ([]SalesOrderWithDetails, int, error) {
-	return s.repo.List(ctx, req)
+	orders, total, err := s.repo.List(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.attachMargins(ctx, orders); err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+// NextCursor returns the keyset token for the page after orders, or "" when
+// the page was not full and there is nothing left to fetch.
+func NextCursor(orders []SalesOrderWithDetails, limit int) string {
+	if limit <= 0 || len(orders) < limit {
+		return ""
+	}
+	last := orders[len(orders)-1]
+	return internalShared.EncodeCursor(internalShared.Cursor{At: last.OrderDate, ID: last.ID})
+}
+
+// priceLines applies line and document discounts to the requested lines.
+func priceLines(reqs []CreateSalesOrderLineReq, discount shared.DocumentDiscount) (shared.DocumentTotals, error) {
+	pricing := make([]shared.LinePricing, len(reqs))
+	for i, req := range reqs {
+		pricing[i] = shared.LinePricing{
+			Quantity:        req.Quantity,
+			UnitPrice:       req.UnitPrice,
+			DiscountPercent: req.DiscountPercent,
+			TaxPercent:      req.TaxPercent,
+		}
+	}
+	return shared.CalculateDocumentTotals(pricing, discount)
+}
+
+// requestLines turns stored lines back into line requests for re-pricing.
+// applyDefaultTax sets the tax of lines marked UseDefaultTax to the
+// customer's default tax on the order date.
+func (s *Service) applyDefaultTax(ctx context.Context, customerID int64, on time.Time, lines []CreateSalesOrderLineReq) error {
+	var tax *internalShared.TaxRate
+	for i := range lines {
+		if !lines[i].UseDefaultTax {
+			continue
+		}
+		if tax == nil {
+			t, err := shared.DefaultTax(ctx, s.taxes, customerID, on)
+			if err != nil {
+				return err
+			}
+			tax = &t
+		}
+		lines[i].TaxPercent = tax.Rate
+	}
+	return nil
+}
+
+// DefaultTaxNotice describes the customer's default tax applied to the given
+// number of lines on the date; it is empty when none applied.
+func (s *Service) DefaultTaxNotice(ctx context.Context, customerID int64, on time.Time, lines int) (string, error) {
+	if s.taxes == nil || lines == 0 {
+		return "", nil
+	}
+	tax, err := s.taxes.CustomerDefaultTax(ctx, customerID, on)
+	if err != nil {
+		return "", err
+	}
+	return shared.DefaultTaxNotice(tax, lines), nil
+}
+
+func lineUnits(reqs []CreateSalesOrderLineReq) []shared.LineUnit {
+	units := make([]shared.LineUnit, len(reqs))
+	for i, req := range reqs {
+		units[i] = shared.LineUnit{ProductID: req.ProductID, UOM: req.UOM}
+	}
+	return units
+}
+
+func requestLines(lines []SalesOrderLine) []CreateSalesOrderLineReq {
+	reqs := make([]CreateSalesOrderLineReq, len(lines))
+	for i, line := range lines {
+		reqs[i] = CreateSalesOrderLineReq{
+			ProductID:       line.ProductID,
+			Description:     line.Description,
+			Quantity:        line.Quantity,
+			UOM:             line.UOM,
+			UnitPrice:       line.UnitPrice,
+			DiscountPercent: line.DiscountPercent,
+			TaxPercent:      line.TaxPercent,
+			Notes:           line.Notes,
+			LineOrder:       line.LineOrder,
+		}
+	}
+	return reqs
 }
@@ -10,6 +10,11 @@ type CreateQuotationRequest struct {
 	Currency   string                   `json:"currency" validate:"required,len=3"`
 	Notes      *string                  `json:"notes,omitempty"`
 	Lines      []CreateQuotationLineReq `json:"lines" validate:"required,min=1,dive"`
+
+	// DocumentDiscountPercent discounts the whole document after line
+	// discounts; when zero, DocumentDiscountAmount is taken as a fixed amount.
+	DocumentDiscountPercent float64 `json:"document_discount_percent" validate:"gte=0,lte=100"`
+	DocumentDiscountAmount  float64 `json:"document_discount_amount" validate:"gte=0"`
 }
 
 type CreateQuotationLineReq struct {
@@ -22,6 +27,9 @@ type CreateQuotationLineReq struct {
 	TaxPercent      float64 `json:"tax_percent" validate:"gte=0,lte=100"`
 	Notes           *string `json:"notes,omitempty"`
 	LineOrder       int     `json:"line_order" validate:"gte=0"`
+	// UseDefaultTax replaces TaxPercent with the customer's default tax at
+	// the rate effective on the quote date.
+	UseDefaultTax bool `json:"use_default_tax,omitempty"`
 }
 
 type UpdateQuotationRequest struct {
@@ -29,6 +37,43 @@ type UpdateQuotationRequest struct {
 	ValidUntil *time.Time                `json:"valid_until,omitempty"`
 	Notes      *string                   `json:"notes,omitempty"`
 	Lines      *[]CreateQuotationLineReq `json:"lines,omitempty" validate:"omitempty,min=1,dive"`
+	// DocumentDiscountPercent and DocumentDiscountAmount replace the document
+	// discount when either is set; lines are re-priced even if Lines is nil.
+	DocumentDiscountPercent *float64 `json:"document_discount_percent,omitempty" validate:"omitempty,gte=0,lte=100"`
+	DocumentDiscountAmount  *float64 `json:"document_discount_amount,omitempty" validate:"omitempty,gte=0"`
+	// ExpectedUpdatedAt rejects the update with shared.ErrStaleRecord when the
+	// quotation changed after it was loaded.
+	ExpectedUpdatedAt *time.Time `json:"expected_updated_at,omitempty"`
+}
+
+// RejectQuotationRequest records why a quotation was lost. LossReason is a
+// configured loss reason code; Reason is free text for the detail.
+type RejectQuotationRequest struct {
+	LossReason string `json:"loss_reason"`
+	Reason     string `json:"reason"`
+}
+
+// ReorderLinesRequest lists every line ID of a quotation in the desired order.
+type ReorderLinesRequest struct {
+	LineIDs []int64 `json:"line_ids" validate:"required,min=1"`
+	// ExpectedUpdatedAt rejects the reorder with shared.ErrStaleRecord when the
+	// quotation changed after it was loaded.
+	ExpectedUpdatedAt *time.Time `json:"expected_updated_at,omitempty"`
+}
+
+// LineReviewInput is the approver's decision on one quotation line. An empty
+// decision approves the line; REVISE requires a note.
+type LineReviewInput struct {
+	LineID   int64        `json:"line_id" validate:"required,gt=0"`
+	Decision LineDecision `json:"decision" validate:"omitempty,oneof=APPROVED REVISE"`
+	Note     string       `json:"note,omitempty"`
+}
+
+// ReviewQuotationRequest approves a quotation with per-line decisions. With
+// RequireResubmission the quotation returns to DRAFT instead.
+type ReviewQuotationRequest struct {
+	Lines               []LineReviewInput `json:"lines,omitempty" validate:"dive"`
+	RequireResubmission bool              `json:"require_resubmission"`
 }
 
 type ListQuotationsRequest struct {
@@ -40,3 +85,20 @@ type ListQuotationsRequest struct {
 	Limit      int              `json:"limit" validate:"gte=0,lte=1000"`
 	Offset     int              `json:"offset" validate:"gte=0"`
 }
+
+type SaveTemplateRequest struct {
+	CompanyID   int64                 `json:"company_id" validate:"required,gt=0"`
+	Name        string                `json:"name" validate:"required,max=120"`
+	Description *string               `json:"description,omitempty"`
+	Lines       []SaveTemplateLineReq `json:"lines" validate:"required,min=1,dive"`
+}
+
+type SaveTemplateLineReq struct {
+	ProductID       int64   `json:"product_id" validate:"required,gt=0"`
+	Description     *string `json:"description,omitempty"`
+	Quantity        float64 `json:"quantity" validate:"required,gt=0"`
+	UOM             string  `json:"uom" validate:"required,max=20"`
+	DiscountPercent float64 `json:"discount_percent" validate:"gte=0,lte=100"`
+	TaxPercent      float64 `json:"tax_percent" validate:"gte=0,lte=100"`
+	LineOrder       int     `json:"line_order" validate:"gte=0"`
+}
@@ -0,0 +1,108 @@
+package quotations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// LossReason is a structured reason a quotation was rejected or lost.
+type LossReason struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// DefaultLossReasons is used when no loss reasons file is configured.
+var DefaultLossReasons = []LossReason{
+	{Code: "PRICE", Label: "Price too high"},
+	{Code: "COMPETITOR", Label: "Lost to competitor"},
+	{Code: "TIMING", Label: "Timing"},
+	{Code: "BUDGET", Label: "No budget"},
+	{Code: "REQUIREMENTS", Label: "Requirements not met"},
+	{Code: "OTHER", Label: "Other"},
+}
+
+// ErrInvalidLossReason is returned when a rejection has no loss reason or one
+// that is not configured.
+var ErrInvalidLossReason = fmt.Errorf("%w: choose a loss reason from the list", coreshared.ErrValidation)
+
+// NewLossReasons normalises codes to upper case and checks that every reason
+// has a unique code and a label.
+func NewLossReasons(reasons []LossReason) ([]LossReason, error) {
+	if len(reasons) == 0 {
+		return nil, fmt.Errorf("loss reasons: at least one reason is required")
+	}
+	out := make([]LossReason, 0, len(reasons))
+	seen := make(map[string]bool, len(reasons))
+	for _, r := range reasons {
+		r.Code = strings.ToUpper(strings.TrimSpace(r.Code))
+		r.Label = strings.TrimSpace(r.Label)
+		if r.Code == "" || r.Label == "" {
+			return nil, fmt.Errorf("loss reasons: code and label are required")
+		}
+		if seen[r.Code] {
+			return nil, fmt.Errorf("loss reasons: duplicate code %s", r.Code)
+		}
+		seen[r.Code] = true
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// LoadLossReasons reads a JSON list of loss reasons. An empty path returns
+// DefaultLossReasons.
+func LoadLossReasons(path string) ([]LossReason, error) {
+	if strings.TrimSpace(path) == "" {
+		return DefaultLossReasons, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read loss reasons: %w", err)
+	}
+	var reasons []LossReason
+	if err := json.Unmarshal(raw, &reasons); err != nil {
+		return nil, fmt.Errorf("parse loss reasons: %w", err)
+	}
+	return NewLossReasons(reasons)
+}
+
+// SetLossReasons replaces the loss reasons offered when rejecting.
+func (s *Service) SetLossReasons(reasons []LossReason) {
+	s.lossReasons = reasons
+}
+
+// LossReasons lists the configured loss reasons in display order.
+func (s *Service) LossReasons() []LossReason {
+	if len(s.lossReasons) == 0 {
+		return DefaultLossReasons
+	}
+	return s.lossReasons
+}
+
+// LossReasonLabel returns the label for code. Codes that are no longer
+// configured are shown as stored.
+func (s *Service) LossReasonLabel(code string) string {
+	return lossReasonLabel(s.LossReasons(), code)
+}
+
+func lossReasonLabel(reasons []LossReason, code string) string {
+	for _, r := range reasons {
+		if r.Code == code {
+			return r.Label
+		}
+	}
+	return code
+}
+
+func (s *Service) validLossReason(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, r := range s.LossReasons() {
+		if r.Code == code {
+			return code, nil
+		}
+	}
+	return "", ErrInvalidLossReason
+}
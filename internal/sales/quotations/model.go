@@ -1,6 +1,10 @@
 package quotations
 
-import "time"
+import (
+	"time"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
 
 type QuotationStatus string
 
@@ -12,6 +16,15 @@ const (
 	QuotationStatusConverted QuotationStatus = "CONVERTED"
 )
 
+// QuotationTransitions lists the allowed quotation status changes. A review
+// that requires resubmission sends a SUBMITTED quotation back to DRAFT;
+// REJECTED and CONVERTED are terminal.
+var QuotationTransitions = coreshared.NewStateMachine("quotation", map[QuotationStatus][]QuotationStatus{
+	QuotationStatusDraft:     {QuotationStatusSubmitted},
+	QuotationStatusSubmitted: {QuotationStatusApproved, QuotationStatusRejected, QuotationStatusDraft},
+	QuotationStatusApproved:  {QuotationStatusConverted},
+})
+
 type Quotation struct {
 	ID              int64            `json:"id" db:"id"`
 	DocNumber       string           `json:"doc_number" db:"doc_number"`
@@ -31,9 +44,23 @@ type Quotation struct {
 	RejectedBy      *int64           `json:"rejected_by,omitempty" db:"rejected_by"`
 	RejectedAt      *time.Time       `json:"rejected_at,omitempty" db:"rejected_at"`
 	RejectionReason *string          `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	// LossReason is the structured loss reason code recorded on rejection.
+	LossReason      *string          `json:"loss_reason,omitempty" db:"loss_reason"`
 	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time        `json:"updated_at" db:"updated_at"`
 	Lines           []QuotationLine  `json:"lines,omitempty" db:"-"`
+
+	// DocumentDiscountPercent, when non-zero, sets the document discount as a
+	// share of the discounted line subtotal; otherwise DocumentDiscountAmount
+	// is a fixed amount. DocumentDiscountAmount always holds the amount applied.
+	DocumentDiscountPercent float64 `json:"document_discount_percent" db:"document_discount_percent"`
+	DocumentDiscountAmount  float64 `json:"document_discount_amount" db:"document_discount_amount"`
+}
+
+// IsTerminal reports whether the quotation can no longer change: rejected or
+// already converted to a sales order.
+func (q *Quotation) IsTerminal() bool {
+	return QuotationTransitions.IsTerminal(q.Status)
 }
 
 type QuotationLine struct {
@@ -53,6 +80,10 @@ type QuotationLine struct {
 	LineOrder       int       `json:"line_order" db:"line_order"`
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+
+	// DocumentDiscountAmount is the line's share of the document discount,
+	// already included in DiscountAmount.
+	DocumentDiscountAmount float64 `json:"document_discount_amount" db:"document_discount_amount"`
 }
 
 type QuotationWithDetails struct {
@@ -62,3 +93,29 @@ type QuotationWithDetails struct {
 	ApprovedByName *string `json:"approved_by_name,omitempty" db:"approved_by_name"`
 	RejectedByName *string `json:"rejected_by_name,omitempty" db:"rejected_by_name"`
 }
+
+// QuotationTemplate is a named, company-wide set of line items reps reuse for
+// repeat quotes. Prices are resolved when a quotation is created from it.
+type QuotationTemplate struct {
+	ID          int64                   `json:"id" db:"id"`
+	CompanyID   int64                   `json:"company_id" db:"company_id"`
+	Name        string                  `json:"name" db:"name"`
+	Description *string                 `json:"description,omitempty" db:"description"`
+	CreatedBy   int64                   `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time               `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at" db:"updated_at"`
+	LineCount   int                     `json:"line_count" db:"line_count"`
+	Lines       []QuotationTemplateLine `json:"lines,omitempty" db:"-"`
+}
+
+type QuotationTemplateLine struct {
+	ID              int64   `json:"id" db:"id"`
+	TemplateID      int64   `json:"template_id" db:"template_id"`
+	ProductID       int64   `json:"product_id" db:"product_id"`
+	Description     *string `json:"description,omitempty" db:"description"`
+	Quantity        float64 `json:"quantity" db:"quantity"`
+	UOM             string  `json:"uom" db:"uom"`
+	DiscountPercent float64 `json:"discount_percent" db:"discount_percent"`
+	TaxPercent      float64 `json:"tax_percent" db:"tax_percent"`
+	LineOrder       int     `json:"line_order" db:"line_order"`
+}
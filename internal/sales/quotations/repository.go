@@ -11,11 +11,13 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/platform/db"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
 
 var (
-	ErrNotFound      = errors.New("record not found")
+	ErrNotFound         = errors.New("record not found")
+	ErrTemplateNotFound = errors.New("quotation template not found")
 )
 
 type Repository interface {
@@ -27,8 +29,22 @@ type Repository interface {
 	Update(ctx context.Context, id int64, updates map[string]interface{}) error
 	InsertLine(ctx context.Context, line QuotationLine) (int64, error)
 	UpdateStatus(ctx context.Context, id int64, status QuotationStatus, userID int64, reason *string) error
+	SetLossReason(ctx context.Context, id int64, code string) error
+	WinLossRows(ctx context.Context, filter WinLossFilter) ([]WinLossRow, error)
 	DeleteLines(ctx context.Context, quotationID int64) error
+	ReorderLines(ctx context.Context, quotationID int64, lineIDs []int64) error
 	GenerateNumber(ctx context.Context, companyID int64, date time.Time) (string, error)
+	ListTemplates(ctx context.Context, companyID int64) ([]QuotationTemplate, error)
+	GetTemplate(ctx context.Context, id int64) (*QuotationTemplate, error)
+	CreateTemplate(ctx context.Context, tmpl QuotationTemplate) (int64, error)
+	UpdateTemplate(ctx context.Context, id int64, name string, description *string) error
+	DeleteTemplate(ctx context.Context, id int64) error
+	InsertTemplateLine(ctx context.Context, line QuotationTemplateLine) (int64, error)
+	DeleteTemplateLines(ctx context.Context, templateID int64) error
+	InsertLineReview(ctx context.Context, review LineReview) (int64, error)
+	ListLineReviews(ctx context.Context, quotationID int64) ([]LineReview, error)
+	GetLineReview(ctx context.Context, id int64) (*LineReview, error)
+	ResolveLineReview(ctx context.Context, id, userID int64, response string) error
 }
 
 type dbtx interface {
@@ -56,7 +72,7 @@ func (r *repository) WithTx(ctx context.Context, fn func(context.Context, Reposi
 		repoTx := &repository{
 			db:      tx,
 			queries: r.queries.WithTx(tx),
-			pool:    r.pool, 
+			pool:    r.pool,
 		}
 		return fn(ctx, repoTx)
 	})
@@ -71,6 +87,9 @@ func (r *repository) Get(ctx context.Context, id int64) (*Quotation, error) {
 		return nil, err
 	}
 	q := mapQuotationFromSqlc(row)
+	if !shared.CompanyAllowed(ctx, q.CompanyID) {
+		return nil, ErrNotFound
+	}
 
 	lineRows, err := r.queries.GetQuotationLines(ctx, id)
 	if err != nil {
@@ -148,7 +167,7 @@ func (r *repository) List(ctx context.Context, req ListQuotationsRequest) ([]Quo
 		SELECT q.id, q.doc_number, q.company_id, q.customer_id, q.quote_date, q.valid_until,
 		       q.status, q.currency, q.subtotal, q.tax_amount, q.total_amount, q.notes,
 		       q.created_by, q.approved_by, q.approved_at, q.rejected_by, q.rejected_at,
-		       q.rejection_reason, q.created_at, q.updated_at,
+		       q.rejection_reason, q.loss_reason, q.created_at, q.updated_at,
 		       c.name as customer_name,
 		       u1.full_name as created_by_name,
 		       u2.full_name as approved_by_name,
@@ -174,40 +193,76 @@ func (r *repository) List(ctx context.Context, req ListQuotationsRequest) ([]Quo
 	var quotations []QuotationWithDetails
 	for rows.Next() {
 		var q QuotationWithDetails
-		var quoteDatePG, validUntilPG pgtype.Date 
+		var quoteDatePG, validUntilPG pgtype.Date
 		var subtotal, taxAmount, totalAmount pgtype.Numeric
 		var approvedBy, rejectedBy pgtype.Int8
 		var approvedAt, rejectedAt pgtype.Timestamptz
-		var notes, rejectionReason, approvedByName, rejectedByName pgtype.Text
+		var notes, rejectionReason, lossReason, approvedByName, rejectedByName pgtype.Text
 		var createdAt, updatedAt pgtype.Timestamptz
 
 		err := rows.Scan(
 			&q.ID, &q.DocNumber, &q.CompanyID, &q.CustomerID, &quoteDatePG, &validUntilPG,
 			&q.Status, &q.Currency, &subtotal, &taxAmount, &totalAmount, &notes,
 			&q.CreatedBy, &approvedBy, &approvedAt, &rejectedBy, &rejectedAt,
-			&rejectionReason, &createdAt, &updatedAt,
+			&rejectionReason, &lossReason, &createdAt, &updatedAt,
 			&q.CustomerName, &q.CreatedByName, &approvedByName, &rejectedByName,
 		)
 		if err != nil {
 			return nil, 0, err
 		}
 
-		if quoteDatePG.Valid { q.QuoteDate = quoteDatePG.Time }
-		if validUntilPG.Valid { q.ValidUntil = validUntilPG.Time }
-		if subtotal.Valid { f, _ := subtotal.Float64Value(); q.Subtotal = f.Float64 }
-		if taxAmount.Valid { f, _ := taxAmount.Float64Value(); q.TaxAmount = f.Float64 }
-		if totalAmount.Valid { f, _ := totalAmount.Float64Value(); q.TotalAmount = f.Float64 }
-		if notes.Valid { q.Notes = &notes.String }
-		if approvedBy.Valid { q.ApprovedBy = &approvedBy.Int64 }
-		if approvedAt.Valid { q.ApprovedAt = &approvedAt.Time }
-		if rejectedBy.Valid { q.RejectedBy = &rejectedBy.Int64 }
-		if rejectedAt.Valid { q.RejectedAt = &rejectedAt.Time }
-		if rejectionReason.Valid { q.RejectionReason = &rejectionReason.String }
-		if createdAt.Valid { q.CreatedAt = createdAt.Time }
-		if updatedAt.Valid { q.UpdatedAt = updatedAt.Time }
-		if approvedByName.Valid { q.ApprovedByName = &approvedByName.String }
-		if rejectedByName.Valid { q.RejectedByName = &rejectedByName.String }
-		
+		if quoteDatePG.Valid {
+			q.QuoteDate = quoteDatePG.Time
+		}
+		if validUntilPG.Valid {
+			q.ValidUntil = validUntilPG.Time
+		}
+		if subtotal.Valid {
+			f, _ := subtotal.Float64Value()
+			q.Subtotal = f.Float64
+		}
+		if taxAmount.Valid {
+			f, _ := taxAmount.Float64Value()
+			q.TaxAmount = f.Float64
+		}
+		if totalAmount.Valid {
+			f, _ := totalAmount.Float64Value()
+			q.TotalAmount = f.Float64
+		}
+		if notes.Valid {
+			q.Notes = &notes.String
+		}
+		if approvedBy.Valid {
+			q.ApprovedBy = &approvedBy.Int64
+		}
+		if approvedAt.Valid {
+			q.ApprovedAt = &approvedAt.Time
+		}
+		if rejectedBy.Valid {
+			q.RejectedBy = &rejectedBy.Int64
+		}
+		if rejectedAt.Valid {
+			q.RejectedAt = &rejectedAt.Time
+		}
+		if rejectionReason.Valid {
+			q.RejectionReason = &rejectionReason.String
+		}
+		if lossReason.Valid {
+			q.LossReason = &lossReason.String
+		}
+		if createdAt.Valid {
+			q.CreatedAt = createdAt.Time
+		}
+		if updatedAt.Valid {
+			q.UpdatedAt = updatedAt.Time
+		}
+		if approvedByName.Valid {
+			q.ApprovedByName = &approvedByName.String
+		}
+		if rejectedByName.Valid {
+			q.RejectedByName = &rejectedByName.String
+		}
+
 		quotations = append(quotations, q)
 	}
 
@@ -222,25 +277,30 @@ func (r *repository) Create(ctx context.Context, q Quotation) (int64, error) {
 	if !q.ValidUntil.IsZero() {
 		validUntil = pgtype.Date{Time: q.ValidUntil, Valid: true}
 	}
-	
+
 	var subtotal, taxAmount, totalAmount pgtype.Numeric
 	subtotal.Scan(fmt.Sprintf("%f", q.Subtotal))
 	taxAmount.Scan(fmt.Sprintf("%f", q.TaxAmount))
 	totalAmount.Scan(fmt.Sprintf("%f", q.TotalAmount))
+	var docDiscountPercent, docDiscountAmount pgtype.Numeric
+	docDiscountPercent.Scan(fmt.Sprintf("%f", q.DocumentDiscountPercent))
+	docDiscountAmount.Scan(fmt.Sprintf("%f", q.DocumentDiscountAmount))
 
 	return r.queries.CreateQuotation(ctx, sqlc.CreateQuotationParams{
-		DocNumber:   q.DocNumber,
-		CompanyID:   q.CompanyID,
-		CustomerID:  q.CustomerID,
-		QuoteDate:   quoteDate,
-		ValidUntil:  validUntil,
-		Status:      sqlc.QuotationStatus(q.Status),
-		Currency:    q.Currency,
-		Subtotal:    subtotal,
-		TaxAmount:   taxAmount,
-		TotalAmount: totalAmount,
-		Notes:       pgtype.Text{String: getString(q.Notes), Valid: q.Notes != nil},
-		CreatedBy:   q.CreatedBy,
+		DocNumber:               q.DocNumber,
+		CompanyID:               q.CompanyID,
+		CustomerID:              q.CustomerID,
+		QuoteDate:               quoteDate,
+		ValidUntil:              validUntil,
+		Status:                  sqlc.QuotationStatus(q.Status),
+		Currency:                q.Currency,
+		Subtotal:                subtotal,
+		TaxAmount:               taxAmount,
+		TotalAmount:             totalAmount,
+		Notes:                   pgtype.Text{String: getString(q.Notes), Valid: q.Notes != nil},
+		CreatedBy:               q.CreatedBy,
+		DocumentDiscountPercent: docDiscountPercent,
+		DocumentDiscountAmount:  docDiscountAmount,
 	})
 }
 
@@ -248,7 +308,7 @@ func (r *repository) Update(ctx context.Context, id int64, updates map[string]in
 	query := "UPDATE quotations SET updated_at = NOW()"
 	var args []interface{}
 	argPos := 1
-	
+
 	if v, ok := updates["quote_date"]; ok {
 		query += fmt.Sprintf(", quote_date = $%d", argPos)
 		args = append(args, v)
@@ -279,12 +339,36 @@ func (r *repository) Update(ctx context.Context, id int64, updates map[string]in
 		args = append(args, v)
 		argPos++
 	}
-	
+	if v, ok := updates["document_discount_percent"]; ok {
+		query += fmt.Sprintf(", document_discount_percent = $%d", argPos)
+		args = append(args, v)
+		argPos++
+	}
+	if v, ok := updates["document_discount_amount"]; ok {
+		query += fmt.Sprintf(", document_discount_amount = $%d", argPos)
+		args = append(args, v)
+		argPos++
+	}
+
 	query += fmt.Sprintf(" WHERE id = $%d", argPos)
 	args = append(args, id)
-	
-	_, err := r.db.Exec(ctx, query, args...)
-	return err
+	argPos++
+
+	// Optimistic lock: only update the row version the caller loaded.
+	expected, guarded := updates["expected_updated_at"]
+	if guarded {
+		query += fmt.Sprintf(" AND updated_at = $%d", argPos)
+		args = append(args, expected)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if guarded && tag.RowsAffected() == 0 {
+		return shared.ErrStaleRecord
+	}
+	return nil
 }
 
 func (r *repository) InsertLine(ctx context.Context, line QuotationLine) (int64, error) {
@@ -296,21 +380,24 @@ func (r *repository) InsertLine(ctx context.Context, line QuotationLine) (int64,
 	taxPercent.Scan(fmt.Sprintf("%f", line.TaxPercent))
 	taxAmount.Scan(fmt.Sprintf("%f", line.TaxAmount))
 	lineTotal.Scan(fmt.Sprintf("%f", line.LineTotal))
-	
+	var docDiscountAmount pgtype.Numeric
+	docDiscountAmount.Scan(fmt.Sprintf("%f", line.DocumentDiscountAmount))
+
 	return r.queries.InsertQuotationLine(ctx, sqlc.InsertQuotationLineParams{
-		QuotationID:     line.QuotationID,
-		ProductID:       line.ProductID,
-		Description:     pgtype.Text{String: getString(line.Description), Valid: line.Description != nil},
-		Quantity:        quantity,
-		Uom:             line.UOM,
-		UnitPrice:       unitPrice,
-		DiscountPercent: discountPercent,
-		DiscountAmount:  discountAmount,
-		TaxPercent:      taxPercent,
-		TaxAmount:       taxAmount,
-		LineTotal:       lineTotal,
-		Notes:           pgtype.Text{String: getString(line.Notes), Valid: line.Notes != nil},
-		LineOrder:       int32(line.LineOrder),
+		QuotationID:            line.QuotationID,
+		ProductID:              line.ProductID,
+		Description:            pgtype.Text{String: getString(line.Description), Valid: line.Description != nil},
+		Quantity:               quantity,
+		Uom:                    line.UOM,
+		UnitPrice:              unitPrice,
+		DiscountPercent:        discountPercent,
+		DiscountAmount:         discountAmount,
+		TaxPercent:             taxPercent,
+		TaxAmount:              taxAmount,
+		LineTotal:              lineTotal,
+		Notes:                  pgtype.Text{String: getString(line.Notes), Valid: line.Notes != nil},
+		LineOrder:              int32(line.LineOrder),
+		DocumentDiscountAmount: docDiscountAmount,
 	})
 }
 
@@ -341,6 +428,11 @@ func (r *repository) UpdateStatus(ctx context.Context, id int64, status Quotatio
 	})
 }
 
+func (r *repository) SetLossReason(ctx context.Context, id int64, code string) error {
+	_, err := r.db.Exec(ctx, `UPDATE quotations SET loss_reason = $2 WHERE id = $1`, id, code)
+	return err
+}
+
 func (r *repository) DeleteLines(ctx context.Context, quotationID int64) error {
 	return r.queries.DeleteQuotationLines(ctx, quotationID)
 }
@@ -362,17 +454,145 @@ func (r *repository) GenerateNumber(ctx context.Context, companyID int64, date t
 	return fmt.Sprintf("QT-%s-%04d", date.Format("0601"), seq), nil
 }
 
+func (r *repository) ListTemplates(ctx context.Context, companyID int64) ([]QuotationTemplate, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT t.id, t.company_id, t.name, t.description, t.created_by, t.created_at, t.updated_at,
+		       COUNT(l.id)
+		FROM quotation_templates t
+		LEFT JOIN quotation_template_lines l ON l.template_id = t.id
+		WHERE t.company_id = $1
+		GROUP BY t.id
+		ORDER BY t.name
+	`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []QuotationTemplate
+	for rows.Next() {
+		var t QuotationTemplate
+		var description pgtype.Text
+		if err := rows.Scan(&t.ID, &t.CompanyID, &t.Name, &description, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt, &t.LineCount); err != nil {
+			return nil, err
+		}
+		if description.Valid {
+			t.Description = &description.String
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+func (r *repository) GetTemplate(ctx context.Context, id int64) (*QuotationTemplate, error) {
+	var t QuotationTemplate
+	var description pgtype.Text
+	err := r.db.QueryRow(ctx, `
+		SELECT id, company_id, name, description, created_by, created_at, updated_at
+		FROM quotation_templates
+		WHERE id = $1
+	`, id).Scan(&t.ID, &t.CompanyID, &t.Name, &description, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	if !shared.CompanyAllowed(ctx, t.CompanyID) {
+		return nil, ErrTemplateNotFound
+	}
+	if description.Valid {
+		t.Description = &description.String
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, template_id, product_id, description, quantity::float8, uom,
+		       discount_percent::float8, tax_percent::float8, line_order
+		FROM quotation_template_lines
+		WHERE template_id = $1
+		ORDER BY line_order, id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l QuotationTemplateLine
+		var lineDescription pgtype.Text
+		if err := rows.Scan(&l.ID, &l.TemplateID, &l.ProductID, &lineDescription, &l.Quantity, &l.UOM,
+			&l.DiscountPercent, &l.TaxPercent, &l.LineOrder); err != nil {
+			return nil, err
+		}
+		if lineDescription.Valid {
+			l.Description = &lineDescription.String
+		}
+		t.Lines = append(t.Lines, l)
+	}
+	t.LineCount = len(t.Lines)
+	return &t, rows.Err()
+}
+
+func (r *repository) CreateTemplate(ctx context.Context, t QuotationTemplate) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO quotation_templates (company_id, name, description, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, t.CompanyID, t.Name, pgtype.Text{String: getString(t.Description), Valid: t.Description != nil}, t.CreatedBy).Scan(&id)
+	return id, templateNameErr(err)
+}
+
+func (r *repository) UpdateTemplate(ctx context.Context, id int64, name string, description *string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE quotation_templates
+		SET name = $2, description = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, name, pgtype.Text{String: getString(description), Valid: description != nil})
+	return templateNameErr(err)
+}
+
+func (r *repository) DeleteTemplate(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM quotation_templates WHERE id = $1`, id)
+	return err
+}
+
+func (r *repository) InsertTemplateLine(ctx context.Context, line QuotationTemplateLine) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO quotation_template_lines (template_id, product_id, description, quantity, uom,
+		                                      discount_percent, tax_percent, line_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, line.TemplateID, line.ProductID, pgtype.Text{String: getString(line.Description), Valid: line.Description != nil},
+		line.Quantity, line.UOM, line.DiscountPercent, line.TaxPercent, line.LineOrder).Scan(&id)
+	return id, err
+}
+
+func (r *repository) DeleteTemplateLines(ctx context.Context, templateID int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM quotation_template_lines WHERE template_id = $1`, templateID)
+	return err
+}
+
+// templateNameErr maps the per-company unique name violation to ErrDuplicate.
+func templateNameErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("template name already used: %w", shared.ErrDuplicate)
+	}
+	return err
+}
+
 func mapQuotationFromSqlc(row sqlc.Quotation) Quotation {
 	q := Quotation{
-		ID:          row.ID,
-		DocNumber:   row.DocNumber,
-		CompanyID:   row.CompanyID,
-		CustomerID:  row.CustomerID,
-		Status:      QuotationStatus(row.Status),
-		Currency:    row.Currency,
-		CreatedBy:   row.CreatedBy,
-		CreatedAt:   row.CreatedAt.Time,
-		UpdatedAt:   row.UpdatedAt.Time,
+		ID:         row.ID,
+		DocNumber:  row.DocNumber,
+		CompanyID:  row.CompanyID,
+		CustomerID: row.CustomerID,
+		Status:     QuotationStatus(row.Status),
+		Currency:   row.Currency,
+		CreatedBy:  row.CreatedBy,
+		CreatedAt:  row.CreatedAt.Time,
+		UpdatedAt:  row.UpdatedAt.Time,
 	}
 	if row.QuoteDate.Valid {
 		q.QuoteDate = row.QuoteDate.Time
@@ -392,6 +612,14 @@ func mapQuotationFromSqlc(row sqlc.Quotation) Quotation {
 		f, _ := row.TotalAmount.Float64Value()
 		q.TotalAmount = f.Float64
 	}
+	if row.DocumentDiscountPercent.Valid {
+		f, _ := row.DocumentDiscountPercent.Float64Value()
+		q.DocumentDiscountPercent = f.Float64
+	}
+	if row.DocumentDiscountAmount.Valid {
+		f, _ := row.DocumentDiscountAmount.Float64Value()
+		q.DocumentDiscountAmount = f.Float64
+	}
 	if row.Notes.Valid {
 		val := row.Notes.String
 		q.Notes = &val
@@ -416,6 +644,10 @@ func mapQuotationFromSqlc(row sqlc.Quotation) Quotation {
 		val := row.RejectionReason.String
 		q.RejectionReason = &val
 	}
+	if row.LossReason.Valid {
+		val := row.LossReason.String
+		q.LossReason = &val
+	}
 	return q
 }
 
@@ -423,11 +655,11 @@ func mapLinesFromSqlc(rows []sqlc.QuotationLine) []QuotationLine {
 	var lines []QuotationLine
 	for _, l := range rows {
 		line := QuotationLine{
-			ID:              l.ID,
-			QuotationID:     l.QuotationID,
-			ProductID:       l.ProductID,
-			UOM:             l.Uom,
-			LineOrder:       int(l.LineOrder),
+			ID:          l.ID,
+			QuotationID: l.QuotationID,
+			ProductID:   l.ProductID,
+			UOM:         l.Uom,
+			LineOrder:   int(l.LineOrder),
 		}
 		if l.Description.Valid {
 			val := l.Description.String
@@ -449,6 +681,10 @@ func mapLinesFromSqlc(rows []sqlc.QuotationLine) []QuotationLine {
 			f, _ := l.DiscountAmount.Float64Value()
 			line.DiscountAmount = f.Float64
 		}
+		if l.DocumentDiscountAmount.Valid {
+			f, _ := l.DocumentDiscountAmount.Float64Value()
+			line.DocumentDiscountAmount = f.Float64
+		}
 		if l.TaxPercent.Valid {
 			f, _ := l.TaxPercent.Float64Value()
 			line.TaxPercent = f.Float64
@@ -0,0 +1,76 @@
+package quotations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const lineReviewColumns = `id, quotation_id, quotation_line_id, decision, note, reviewed_by, reviewed_at,
+		response, resolved_by, resolved_at`
+
+func scanLineReview(row pgx.Row) (LineReview, error) {
+	var review LineReview
+	err := row.Scan(&review.ID, &review.QuotationID, &review.QuotationLineID, &review.Decision, &review.Note,
+		&review.ReviewedBy, &review.ReviewedAt, &review.Response, &review.ResolvedBy, &review.ResolvedAt)
+	return review, err
+}
+
+func (r *repository) InsertLineReview(ctx context.Context, review LineReview) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO quotation_line_reviews (quotation_id, quotation_line_id, decision, note, reviewed_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, review.QuotationID, review.QuotationLineID, string(review.Decision), review.Note, review.ReviewedBy).Scan(&id)
+	return id, err
+}
+
+func (r *repository) ListLineReviews(ctx context.Context, quotationID int64) ([]LineReview, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+lineReviewColumns+`
+		FROM quotation_line_reviews
+		WHERE quotation_id = $1
+		ORDER BY reviewed_at, id
+	`, quotationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var reviews []LineReview
+	for rows.Next() {
+		review, err := scanLineReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+func (r *repository) GetLineReview(ctx context.Context, id int64) (*LineReview, error) {
+	review, err := scanLineReview(r.db.QueryRow(ctx, `
+		SELECT `+lineReviewColumns+`
+		FROM quotation_line_reviews
+		WHERE id = $1
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ResolveLineReview stores the rep's response; already resolved rows are left
+// untouched.
+func (r *repository) ResolveLineReview(ctx context.Context, id, userID int64, response string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE quotation_line_reviews
+		SET response = $2, resolved_by = $3, resolved_at = NOW()
+		WHERE id = $1 AND resolved_at IS NULL
+	`, id, response, userID)
+	return err
+}
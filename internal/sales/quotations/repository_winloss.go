@@ -0,0 +1,42 @@
+package quotations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func (r *repository) WinLossRows(ctx context.Context, filter WinLossFilter) ([]WinLossRow, error) {
+	if !shared.CompanyAllowed(ctx, filter.CompanyID) {
+		return nil, nil
+	}
+	args := []interface{}{filter.CompanyID, filter.DateFrom, filter.DateTo}
+	customerFilter := ""
+	if filter.CustomerID != nil {
+		args = append(args, *filter.CustomerID)
+		customerFilter = fmt.Sprintf(" AND q.customer_id = $%d", len(args))
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT to_char(q.quote_date, 'YYYY-MM'), q.customer_id, c.name, q.status,
+		       COALESCE(q.loss_reason, ''), COUNT(*), COALESCE(SUM(q.total_amount), 0)::float8
+		FROM quotations q
+		JOIN customers c ON c.id = q.customer_id
+		WHERE q.company_id = $1 AND q.quote_date BETWEEN $2 AND $3`+customerFilter+`
+		GROUP BY 1, 2, 3, 4, 5
+		ORDER BY 1, 2
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []WinLossRow
+	for rows.Next() {
+		var row WinLossRow
+		if err := rows.Scan(&row.Period, &row.CustomerID, &row.CustomerName, &row.Status, &row.LossReason, &row.Count, &row.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
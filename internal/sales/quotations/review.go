@@ -0,0 +1,202 @@
+package quotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// LineDecision is an approver's verdict on a single quotation line.
+type LineDecision string
+
+const (
+	LineDecisionApproved LineDecision = "APPROVED"
+	LineDecisionRevise   LineDecision = "REVISE"
+)
+
+var (
+	// ErrOpenLineFlags indicates an approved quotation still has lines flagged
+	// for revision that the sales rep has not addressed.
+	ErrOpenLineFlags = errors.New("quotation has open line flags")
+	// ErrReviewNotFound indicates the line review does not exist on the quotation.
+	ErrReviewNotFound = errors.New("quotation line review not found")
+)
+
+// LineReview records one approver decision on a quotation line. REVISE
+// reviews stay open until the rep responds.
+type LineReview struct {
+	ID              int64        `json:"id" db:"id"`
+	QuotationID     int64        `json:"quotation_id" db:"quotation_id"`
+	QuotationLineID *int64       `json:"quotation_line_id,omitempty" db:"quotation_line_id"`
+	Decision        LineDecision `json:"decision" db:"decision"`
+	Note            *string      `json:"note,omitempty" db:"note"`
+	ReviewedBy      int64        `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt      time.Time    `json:"reviewed_at" db:"reviewed_at"`
+	Response        *string      `json:"response,omitempty" db:"response"`
+	ResolvedBy      *int64       `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt      *time.Time   `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// IsOpen reports whether the review flags its line and has no response yet.
+func (r LineReview) IsOpen() bool {
+	return r.Decision == LineDecisionRevise && r.ResolvedAt == nil && r.QuotationLineID != nil
+}
+
+// OpenLineFlags returns the latest review of each line when it is an
+// unresolved REVISE flag. reviews must be ordered oldest first.
+func OpenLineFlags(reviews []LineReview) []LineReview {
+	latest := make(map[int64]LineReview, len(reviews))
+	order := make([]int64, 0, len(reviews))
+	for _, review := range reviews {
+		if review.QuotationLineID == nil {
+			continue
+		}
+		lineID := *review.QuotationLineID
+		if _, seen := latest[lineID]; !seen {
+			order = append(order, lineID)
+		}
+		latest[lineID] = review
+	}
+	var open []LineReview
+	for _, lineID := range order {
+		if review := latest[lineID]; review.IsOpen() {
+			open = append(open, review)
+		}
+	}
+	return open
+}
+
+// Review approves a SUBMITTED quotation while recording a decision for every
+// line. Lines missing from req are approved. Flagged lines keep the quotation
+// APPROVED but block conversion until resolved, unless the approver requires
+// resubmission, which sends it back to DRAFT.
+func (s *Service) Review(ctx context.Context, id int64, reviewer int64, req ReviewQuotationRequest) (*Quotation, error) {
+	existing, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get quotation: %w", err)
+	}
+	status := QuotationStatusApproved
+	if req.RequireResubmission {
+		status = QuotationStatusDraft
+	}
+	if err := QuotationTransitions.Validate(existing.Status, status); err != nil {
+		return nil, err
+	}
+
+	onQuote := make(map[int64]bool, len(existing.Lines))
+	for _, line := range existing.Lines {
+		onQuote[line.ID] = true
+	}
+	decisions := make(map[int64]LineReviewInput, len(req.Lines))
+	for _, input := range req.Lines {
+		if !onQuote[input.LineID] {
+			return nil, fmt.Errorf("%w: line %d is not on this quotation", coreshared.ErrValidation, input.LineID)
+		}
+		decisions[input.LineID] = input
+	}
+	reviews := make([]LineReview, 0, len(existing.Lines))
+	flagged := 0
+	for _, line := range existing.Lines {
+		input, ok := decisions[line.ID]
+		decision := LineDecisionApproved
+		if ok && input.Decision != "" {
+			decision = input.Decision
+		}
+		lineID := line.ID
+		review := LineReview{QuotationID: id, QuotationLineID: &lineID, Decision: decision, ReviewedBy: reviewer}
+		if note := strings.TrimSpace(input.Note); note != "" {
+			review.Note = &note
+		}
+		switch decision {
+		case LineDecisionApproved:
+		case LineDecisionRevise:
+			if review.Note == nil {
+				return nil, fmt.Errorf("%w: line %d needs a note explaining the revision", coreshared.ErrValidation, line.LineOrder)
+			}
+			flagged++
+		default:
+			return nil, fmt.Errorf("%w: unknown line decision %q", coreshared.ErrValidation, decision)
+		}
+		reviews = append(reviews, review)
+	}
+	if req.RequireResubmission && flagged == 0 {
+		return nil, fmt.Errorf("%w: flag at least one line to require resubmission", coreshared.ErrValidation)
+	}
+
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		for _, review := range reviews {
+			if _, err := repo.InsertLineReview(ctx, review); err != nil {
+				return fmt.Errorf("record line review: %w", err)
+			}
+		}
+		return repo.UpdateStatus(ctx, id, status, reviewer, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("approve quotation: %w", err)
+	}
+
+	updated, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case req.RequireResubmission:
+		s.notify(ctx, coreshared.ApprovalReject, updated, reviewer,
+			fmt.Sprintf("Resubmission required: %d line(s) flagged for revision", flagged))
+	case flagged > 0:
+		s.notify(ctx, coreshared.ApprovalApprove, updated, reviewer,
+			fmt.Sprintf("%d line(s) flagged for revision before conversion", flagged))
+	default:
+		s.notify(ctx, coreshared.ApprovalApprove, updated, reviewer, "")
+	}
+	return updated, nil
+}
+
+// ListLineReviews returns every line decision on a quotation, oldest first.
+func (s *Service) ListLineReviews(ctx context.Context, id int64) ([]LineReview, error) {
+	return s.repo.ListLineReviews(ctx, id)
+}
+
+// ResolveLineFlag records the rep's response to an open REVISE flag on an
+// APPROVED quotation.
+func (s *Service) ResolveLineFlag(ctx context.Context, quotationID, reviewID, userID int64, response string) error {
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return fmt.Errorf("%w: describe how the flagged line was addressed", coreshared.ErrValidation)
+	}
+	existing, err := s.repo.Get(ctx, quotationID)
+	if err != nil {
+		return fmt.Errorf("get quotation: %w", err)
+	}
+	if existing.Status != QuotationStatusApproved {
+		return fmt.Errorf("%w: line flags can only be resolved on APPROVED quotations", ErrInvalidStatus)
+	}
+	review, err := s.repo.GetLineReview(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if review.QuotationID != quotationID {
+		return ErrReviewNotFound
+	}
+	if !review.IsOpen() {
+		return fmt.Errorf("%w: line flag is not open", ErrInvalidStatus)
+	}
+	return s.repo.ResolveLineReview(ctx, reviewID, userID, response)
+}
+
+// CheckConvertible reports ErrOpenLineFlags when the quotation still has
+// unresolved line flags.
+func CheckConvertible(ctx context.Context, repo Repository, quotationID int64) error {
+	reviews, err := repo.ListLineReviews(ctx, quotationID)
+	if err != nil {
+		return fmt.Errorf("list line reviews: %w", err)
+	}
+	if open := OpenLineFlags(reviews); len(open) > 0 {
+		return fmt.Errorf("%w: resolve %d flagged line(s) before conversion", ErrOpenLineFlags, len(open))
+	}
+	return nil
+}
@@ -8,18 +8,30 @@ func (h *Handler) MountRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAny("sales.quotation.view"))
 		r.Get("/quotations", h.List)
+		r.Get("/quotations/templates", h.ListTemplates)
+		r.Get("/quotations/win-loss", h.WinLoss)
+		r.Get("/quotations/loss-reasons", h.LossReasons)
 		r.Get("/quotations/{id}", h.Show)
+		r.Post("/quotations/{id}/comments", h.Comment)
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAll("sales.quotation.create"))
 		r.Get("/quotations/new", h.ShowForm)
 		r.Post("/quotations", h.Create)
+		r.Get("/quotations/templates/new", h.ShowTemplateForm)
+		r.Post("/quotations/templates", h.CreateTemplate)
+		r.Post("/quotations/{id}/save-template", h.SaveAsTemplate)
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAll("sales.quotation.edit"))
 		r.Get("/quotations/{id}/edit", h.ShowEditForm)
 		r.Post("/quotations/{id}/edit", h.Update)
 		r.Post("/quotations/{id}/submit", h.Submit)
+		r.Post("/quotations/{id}/lines/reorder", h.ReorderLines)
+		r.Post("/quotations/{id}/reviews/{reviewID}/resolve", h.ResolveLineFlag)
+		r.Get("/quotations/templates/{id}/edit", h.ShowEditTemplateForm)
+		r.Post("/quotations/templates/{id}/edit", h.UpdateTemplate)
+		r.Post("/quotations/templates/{id}/delete", h.DeleteTemplate)
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(h.rbac.RequireAll("sales.quotation.approve"))
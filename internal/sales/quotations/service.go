@@ -4,19 +4,35 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
-
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/products"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/shared"
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 var (
-	ErrInvalidStatus = errors.New("invalid status transition")
+	// ErrInvalidStatus is the shared invalid-transition error, so it also
+	// matches the TransitionErrors of QuotationTransitions.
+	ErrInvalidStatus = coreshared.ErrInvalidTransition
 )
 
+// ProductPricer resolves a product's current list price.
+type ProductPricer interface {
+	Get(ctx context.Context, id int64) (products.Product, error)
+}
+
 type Service struct {
 	repo         Repository
 	customerRepo customers.Repository
+	notifier     coreshared.ApprovalNotifier
+	pricer       ProductPricer
+	currencies   coreshared.CurrencyValidator
+	units        shared.UnitConverter
+	lossReasons  []LossReason
+	taxes        coreshared.DefaultTaxResolver
 }
 
 func NewService(repo Repository, customerRepo customers.Repository) *Service {
@@ -26,10 +42,37 @@ func NewService(repo Repository, customerRepo customers.Repository) *Service {
 	}
 }
 
+// SetApprovalNotifier injects the approval email notifier.
+func (s *Service) SetApprovalNotifier(notifier coreshared.ApprovalNotifier) {
+	s.notifier = notifier
+}
+
+// SetCurrencyValidator rejects new quotations in inactive currencies.
+func (s *Service) SetCurrencyValidator(v coreshared.CurrencyValidator) {
+	s.currencies = v
+}
+
+// SetDefaultTaxResolver fills lines marked UseDefaultTax from the customer's
+// default tax.
+func (s *Service) SetDefaultTaxResolver(taxes coreshared.DefaultTaxResolver) {
+	s.taxes = taxes
+}
+
+// SetUnitConverter restricts line units to the product's base and alternate
+// units.
+func (s *Service) SetUnitConverter(units shared.UnitConverter) {
+	s.units = units
+}
+
 func (s *Service) Create(ctx context.Context, req CreateQuotationRequest, createdBy int64) (*Quotation, error) {
 	if req.ValidUntil.Before(req.QuoteDate) {
 		return nil, errors.New("valid_until must be after quote_date")
 	}
+	if s.currencies != nil {
+		if err := s.currencies.ValidateCurrency(ctx, req.Currency); err != nil {
+			return nil, err
+		}
+	}
 
 	_, err := s.customerRepo.Get(ctx, req.CustomerID)
 	if err != nil {
@@ -41,32 +84,36 @@ func (s *Service) Create(ctx context.Context, req CreateQuotationRequest, create
 		return nil, fmt.Errorf("generate doc number: %w", err)
 	}
 
-	var subtotal, taxAmount, totalAmount float64
-	for _, lineReq := range req.Lines {
-		discount, tax, lineTotal := shared.CalculateLineTotals(
-			lineReq.Quantity,
-			lineReq.UnitPrice,
-			lineReq.DiscountPercent,
-			lineReq.TaxPercent,
-		)
-		subtotal += (lineReq.Quantity * lineReq.UnitPrice) - discount
-		taxAmount += tax
-		totalAmount += lineTotal
+	if err := shared.CheckUnits(ctx, s.units, lineUnits(req.Lines)); err != nil {
+		return nil, err
+	}
+	if err := s.applyDefaultTax(ctx, req.CustomerID, req.QuoteDate, req.Lines); err != nil {
+		return nil, err
+	}
+
+	totals, err := priceLines(req.Lines, shared.DocumentDiscount{
+		Percent: req.DocumentDiscountPercent,
+		Amount:  req.DocumentDiscountAmount,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	quotation := Quotation{
-		DocNumber:   docNumber,
-		CompanyID:   req.CompanyID,
-		CustomerID:  req.CustomerID,
-		QuoteDate:   req.QuoteDate,
-		ValidUntil:  req.ValidUntil,
-		Status:      QuotationStatusDraft,
-		Currency:    req.Currency,
-		Subtotal:    subtotal,
-		TaxAmount:   taxAmount,
-		TotalAmount: totalAmount,
-		Notes:       req.Notes,
-		CreatedBy:   createdBy,
+		DocNumber:               docNumber,
+		CompanyID:               req.CompanyID,
+		CustomerID:              req.CustomerID,
+		QuoteDate:               req.QuoteDate,
+		ValidUntil:              req.ValidUntil,
+		Status:                  QuotationStatusDraft,
+		Currency:                req.Currency,
+		Subtotal:                totals.Subtotal,
+		TaxAmount:               totals.TaxAmount,
+		TotalAmount:             totals.TotalAmount,
+		Notes:                   req.Notes,
+		CreatedBy:               createdBy,
+		DocumentDiscountPercent: req.DocumentDiscountPercent,
+		DocumentDiscountAmount:  totals.DocumentDiscountAmount,
 	}
 
 	var quotationID int64
@@ -78,27 +125,22 @@ func (s *Service) Create(ctx context.Context, req CreateQuotationRequest, create
 		quotationID = id
 
 		for i, lineReq := range req.Lines {
-			discount, tax, lineTotal := shared.CalculateLineTotals(
-				lineReq.Quantity,
-				lineReq.UnitPrice,
-				lineReq.DiscountPercent,
-				lineReq.TaxPercent,
-			)
-
+			amounts := totals.Lines[i]
 			line := QuotationLine{
-				QuotationID:     quotationID,
-				ProductID:       lineReq.ProductID,
-				Description:     lineReq.Description,
-				Quantity:        lineReq.Quantity,
-				UOM:             lineReq.UOM,
-				UnitPrice:       lineReq.UnitPrice,
-				DiscountPercent: lineReq.DiscountPercent,
-				DiscountAmount:  discount,
-				TaxPercent:      lineReq.TaxPercent,
-				TaxAmount:       tax,
-				LineTotal:       lineTotal,
-				Notes:           lineReq.Notes,
-				LineOrder:       lineReq.LineOrder,
+				QuotationID:            quotationID,
+				ProductID:              lineReq.ProductID,
+				Description:            lineReq.Description,
+				Quantity:               lineReq.Quantity,
+				UOM:                    lineReq.UOM,
+				UnitPrice:              lineReq.UnitPrice,
+				DiscountPercent:        lineReq.DiscountPercent,
+				DiscountAmount:         amounts.DiscountAmount,
+				TaxPercent:             lineReq.TaxPercent,
+				TaxAmount:              amounts.TaxAmount,
+				LineTotal:              amounts.LineTotal,
+				Notes:                  lineReq.Notes,
+				LineOrder:              lineReq.LineOrder,
+				DocumentDiscountAmount: amounts.DocumentDiscountAmount,
 			}
 			if line.LineOrder == 0 {
 				line.LineOrder = i + 1
@@ -128,37 +170,67 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateQuotationReque
 	if existing.Status != QuotationStatusDraft {
 		return nil, fmt.Errorf("%w: only DRAFT quotations can be updated", ErrInvalidStatus)
 	}
+	if err := coreshared.CheckVersion(req.ExpectedUpdatedAt, existing.UpdatedAt); err != nil {
+		return nil, err
+	}
 
 	// Calculate new totals if lines are provided
+	discount := shared.DocumentDiscount{Percent: existing.DocumentDiscountPercent, Amount: existing.DocumentDiscountAmount}
+	discountChanged := req.DocumentDiscountPercent != nil || req.DocumentDiscountAmount != nil
+	if discountChanged {
+		discount = shared.DocumentDiscount{}
+		if req.DocumentDiscountPercent != nil {
+			discount.Percent = *req.DocumentDiscountPercent
+		}
+		if req.DocumentDiscountAmount != nil {
+			discount.Amount = *req.DocumentDiscountAmount
+		}
+	}
+	if req.Lines != nil {
+		if err := shared.CheckUnits(ctx, s.units, lineUnits(*req.Lines)); err != nil {
+			return nil, err
+		}
+		quoteDate := existing.QuoteDate
+		if req.QuoteDate != nil {
+			quoteDate = *req.QuoteDate
+		}
+		if err := s.applyDefaultTax(ctx, existing.CustomerID, quoteDate, *req.Lines); err != nil {
+			return nil, err
+		}
+	}
+	// A new document discount re-prices the current lines.
+	if req.Lines == nil && discountChanged {
+		current := requestLines(existing.Lines)
+		req.Lines = &current
+	}
+
 	var subtotal, taxAmount, totalAmount float64
 	var linesToInsert []QuotationLine
 
 	if req.Lines != nil && len(*req.Lines) > 0 {
+		totals, err := priceLines(*req.Lines, discount)
+		if err != nil {
+			return nil, err
+		}
+		subtotal, taxAmount, totalAmount = totals.Subtotal, totals.TaxAmount, totals.TotalAmount
+		discount.Amount = totals.DocumentDiscountAmount
 		for i, lineReq := range *req.Lines {
-			discount, tax, lineTotal := shared.CalculateLineTotals(
-				lineReq.Quantity,
-				lineReq.UnitPrice,
-				lineReq.DiscountPercent,
-				lineReq.TaxPercent,
-			)
-			subtotal += (lineReq.Quantity * lineReq.UnitPrice) - discount
-			taxAmount += tax
-			totalAmount += lineTotal
-
+			amounts := totals.Lines[i]
 			line := QuotationLine{
-				QuotationID:     id,
-				ProductID:       lineReq.ProductID,
-				Description:     lineReq.Description,
-				Quantity:        lineReq.Quantity,
-				UOM:             lineReq.UOM,
-				UnitPrice:       lineReq.UnitPrice,
-				DiscountPercent: lineReq.DiscountPercent,
-				DiscountAmount:  discount,
-				TaxPercent:      lineReq.TaxPercent,
-				TaxAmount:       tax,
-				LineTotal:       lineTotal,
-				Notes:           lineReq.Notes,
-				LineOrder:       lineReq.LineOrder,
+				QuotationID:            id,
+				ProductID:              lineReq.ProductID,
+				Description:            lineReq.Description,
+				Quantity:               lineReq.Quantity,
+				UOM:                    lineReq.UOM,
+				UnitPrice:              lineReq.UnitPrice,
+				DiscountPercent:        lineReq.DiscountPercent,
+				DiscountAmount:         amounts.DiscountAmount,
+				TaxPercent:             lineReq.TaxPercent,
+				TaxAmount:              amounts.TaxAmount,
+				LineTotal:              amounts.LineTotal,
+				Notes:                  lineReq.Notes,
+				LineOrder:              lineReq.LineOrder,
+				DocumentDiscountAmount: amounts.DocumentDiscountAmount,
 			}
 			if line.LineOrder == 0 {
 				line.LineOrder = i + 1
@@ -166,7 +238,7 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateQuotationReque
 			linesToInsert = append(linesToInsert, line)
 		}
 	} else {
-		// Keep existing totals if lines not changed? 
+		// Keep existing totals if lines not changed?
 		// Or if lines not provided, we assume checking only header update.
 		// Use existing totals.
 		subtotal = existing.Subtotal
@@ -189,6 +261,11 @@ func (s *Service) Update(ctx context.Context, id int64, req UpdateQuotationReque
 		updates["subtotal"] = subtotal
 		updates["tax_amount"] = taxAmount
 		updates["total_amount"] = totalAmount
+		updates["document_discount_percent"] = discount.Percent
+		updates["document_discount_amount"] = discount.Amount
+	}
+	if req.ExpectedUpdatedAt != nil {
+		updates["expected_updated_at"] = *req.ExpectedUpdatedAt
 	}
 
 	err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
@@ -223,8 +300,8 @@ func (s *Service) Submit(ctx context.Context, id int64, userID int64) (*Quotatio
 		return nil, fmt.Errorf("get quotation: %w", err)
 	}
 
-	if existing.Status != QuotationStatusDraft {
-		return nil, fmt.Errorf("%w: can only submit DRAFT quotations", ErrInvalidStatus)
+	if err := QuotationTransitions.Validate(existing.Status, QuotationStatusSubmitted); err != nil {
+		return nil, err
 	}
 
 	err = s.repo.UpdateStatus(ctx, id, QuotationStatusSubmitted, userID, nil)
@@ -232,49 +309,328 @@ func (s *Service) Submit(ctx context.Context, id int64, userID int64) (*Quotatio
 		return nil, fmt.Errorf("submit quotation: %w", err)
 	}
 
-	return s.repo.Get(ctx, id)
+	updated, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, coreshared.ApprovalSubmit, updated, userID, "")
+	return updated, nil
 }
 
+// Approve approves every line of a SUBMITTED quotation.
 func (s *Service) Approve(ctx context.Context, id int64, approvedBy int64) (*Quotation, error) {
+	return s.Review(ctx, id, approvedBy, ReviewQuotationRequest{})
+}
+
+// Reject closes a SUBMITTED quotation as lost. The loss reason must be one
+// of the configured LossReasons; the free-text reason carries the detail.
+func (s *Service) Reject(ctx context.Context, id int64, rejectedBy int64, req RejectQuotationRequest) (*Quotation, error) {
+	lossReason, err := s.validLossReason(req.LossReason)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+
 	existing, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get quotation: %w", err)
 	}
 
-	if existing.Status != QuotationStatusSubmitted {
-		return nil, fmt.Errorf("%w: can only approve SUBMITTED quotations", ErrInvalidStatus)
+	if err := QuotationTransitions.Validate(existing.Status, QuotationStatusRejected); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		if err := repo.UpdateStatus(ctx, id, QuotationStatusRejected, rejectedBy, &reason); err != nil {
+			return err
+		}
+		return repo.SetLossReason(ctx, id, lossReason)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reject quotation: %w", err)
 	}
 
-	err = s.repo.UpdateStatus(ctx, id, QuotationStatusApproved, approvedBy, nil)
+	updated, err := s.repo.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("approve quotation: %w", err)
+		return nil, err
+	}
+	s.notify(ctx, coreshared.ApprovalReject, updated, rejectedBy, reason)
+	return updated, nil
+}
+
+// notify emails approvers/requester about a quotation approval transition.
+// Delivery problems never fail the workflow.
+func (s *Service) notify(ctx context.Context, action coreshared.ApprovalAction, q *Quotation, actorID int64, reason string) {
+	if s.notifier == nil || q == nil {
+		return
 	}
+	_ = s.notifier.NotifyApproval(ctx, coreshared.ApprovalNotice{
+		Action:            action,
+		Module:            "QUOTATION",
+		DocumentLabel:     "Quotation",
+		DocumentNumber:    q.DocNumber,
+		Path:              fmt.Sprintf("/sales/quotations/%d", q.ID),
+		Summary:           fmt.Sprintf("Customer #%d, total %s %.2f, valid until %s", q.CustomerID, q.Currency, q.TotalAmount, q.ValidUntil.Format("2006-01-02")),
+		ActorID:           actorID,
+		RequesterID:       q.CreatedBy,
+		ApprovePermission: coreshared.PermQuotationApprove,
+		Reason:            reason,
+	})
+}
 
+func (s *Service) Get(ctx context.Context, id int64) (*Quotation, error) {
 	return s.repo.Get(ctx, id)
 }
 
-func (s *Service) Reject(ctx context.Context, id int64, rejectedBy int64, reason string) (*Quotation, error) {
-	existing, err := s.repo.Get(ctx, id)
+func (s *Service) List(ctx context.Context, req ListQuotationsRequest) ([]QuotationWithDetails, int, error) {
+	return s.repo.List(ctx, req)
+}
+
+// SetProductPricer injects the product lookup used to price lines created
+// from templates.
+func (s *Service) SetProductPricer(pricer ProductPricer) {
+	s.pricer = pricer
+}
+
+func (s *Service) ListTemplates(ctx context.Context, companyID int64) ([]QuotationTemplate, error) {
+	return s.repo.ListTemplates(ctx, companyID)
+}
+
+func (s *Service) GetTemplate(ctx context.Context, id int64) (*QuotationTemplate, error) {
+	return s.repo.GetTemplate(ctx, id)
+}
+
+func (s *Service) CreateTemplate(ctx context.Context, req SaveTemplateRequest, createdBy int64) (*QuotationTemplate, error) {
+	if err := validateTemplate(req); err != nil {
+		return nil, err
+	}
+
+	var templateID int64
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		id, err := repo.CreateTemplate(ctx, QuotationTemplate{
+			CompanyID:   req.CompanyID,
+			Name:        strings.TrimSpace(req.Name),
+			Description: req.Description,
+			CreatedBy:   createdBy,
+		})
+		if err != nil {
+			return fmt.Errorf("create quotation template: %w", err)
+		}
+		templateID = id
+		return insertTemplateLines(ctx, repo, id, req.Lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTemplate(ctx, templateID)
+}
+
+func (s *Service) UpdateTemplate(ctx context.Context, id int64, req SaveTemplateRequest) (*QuotationTemplate, error) {
+	if _, err := s.repo.GetTemplate(ctx, id); err != nil {
+		return nil, fmt.Errorf("get quotation template: %w", err)
+	}
+	if err := validateTemplate(req); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo Repository) error {
+		if err := repo.UpdateTemplate(ctx, id, strings.TrimSpace(req.Name), req.Description); err != nil {
+			return fmt.Errorf("update quotation template: %w", err)
+		}
+		if err := repo.DeleteTemplateLines(ctx, id); err != nil {
+			return err
+		}
+		return insertTemplateLines(ctx, repo, id, req.Lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTemplate(ctx, id)
+}
+
+func (s *Service) DeleteTemplate(ctx context.Context, id int64) error {
+	if _, err := s.repo.GetTemplate(ctx, id); err != nil {
+		return fmt.Errorf("get quotation template: %w", err)
+	}
+	return s.repo.DeleteTemplate(ctx, id)
+}
+
+// SaveAsTemplate stores the products, quantities and discounts of an existing
+// quotation as a named template for its company.
+func (s *Service) SaveAsTemplate(ctx context.Context, quotationID int64, name string, createdBy int64) (*QuotationTemplate, error) {
+	q, err := s.repo.Get(ctx, quotationID)
 	if err != nil {
 		return nil, fmt.Errorf("get quotation: %w", err)
 	}
 
-	if existing.Status != QuotationStatusSubmitted {
-		return nil, fmt.Errorf("%w: can only reject SUBMITTED quotations", ErrInvalidStatus)
+	req := SaveTemplateRequest{CompanyID: q.CompanyID, Name: name}
+	for _, line := range q.Lines {
+		req.Lines = append(req.Lines, SaveTemplateLineReq{
+			ProductID:       line.ProductID,
+			Description:     line.Description,
+			Quantity:        line.Quantity,
+			UOM:             line.UOM,
+			DiscountPercent: line.DiscountPercent,
+			TaxPercent:      line.TaxPercent,
+			LineOrder:       line.LineOrder,
+		})
 	}
+	return s.CreateTemplate(ctx, req, createdBy)
+}
 
-	err = s.repo.UpdateStatus(ctx, id, QuotationStatusRejected, rejectedBy, &reason)
+// TemplateLines expands a template into quotation lines priced at the
+// products' current list price.
+func (s *Service) TemplateLines(ctx context.Context, templateID int64) ([]CreateQuotationLineReq, error) {
+	if s.pricer == nil {
+		return nil, errors.New("product pricing not configured")
+	}
+	tmpl, err := s.repo.GetTemplate(ctx, templateID)
 	if err != nil {
-		return nil, fmt.Errorf("reject quotation: %w", err)
+		return nil, fmt.Errorf("get quotation template: %w", err)
 	}
 
-	return s.repo.Get(ctx, id)
+	lines := make([]CreateQuotationLineReq, 0, len(tmpl.Lines))
+	for i, line := range tmpl.Lines {
+		product, err := s.pricer.Get(ctx, line.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("resolve price for product %d: %w", line.ProductID, err)
+		}
+		if !product.IsActive {
+			return nil, fmt.Errorf("%w: product %s is inactive", coreshared.ErrValidation, product.Code)
+		}
+		req := CreateQuotationLineReq{
+			ProductID:       line.ProductID,
+			Description:     line.Description,
+			Quantity:        line.Quantity,
+			UOM:             line.UOM,
+			UnitPrice:       product.Price,
+			DiscountPercent: line.DiscountPercent,
+			TaxPercent:      line.TaxPercent,
+			LineOrder:       line.LineOrder,
+		}
+		if req.LineOrder == 0 {
+			req.LineOrder = i + 1
+		}
+		lines = append(lines, req)
+	}
+	return lines, nil
 }
 
-func (s *Service) Get(ctx context.Context, id int64) (*Quotation, error) {
-	return s.repo.Get(ctx, id)
+func validateTemplate(req SaveTemplateRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: template name is required", coreshared.ErrValidation)
+	}
+	if len(req.Lines) == 0 {
+		return fmt.Errorf("%w: template needs at least one line", coreshared.ErrValidation)
+	}
+	for _, line := range req.Lines {
+		if line.ProductID <= 0 || line.Quantity <= 0 {
+			return fmt.Errorf("%w: template lines need a product and positive quantity", coreshared.ErrValidation)
+		}
+		if line.DiscountPercent < 0 || line.DiscountPercent > 100 {
+			return fmt.Errorf("%w: discount must be between 0 and 100", coreshared.ErrValidation)
+		}
+	}
+	return nil
 }
 
-func (s *Service) List(ctx context.Context, req ListQuotationsRequest) ([]QuotationWithDetails, int, error) {
-	return s.repo.List(ctx, req)
+func insertTemplateLines(ctx context.Context, repo Repository, templateID int64, reqs []SaveTemplateLineReq) error {
+	for i, req := range reqs {
+		line := QuotationTemplateLine{
+			TemplateID:      templateID,
+			ProductID:       req.ProductID,
+			Description:     req.Description,
+			Quantity:        req.Quantity,
+			UOM:             req.UOM,
+			DiscountPercent: req.DiscountPercent,
+			TaxPercent:      req.TaxPercent,
+			LineOrder:       req.LineOrder,
+		}
+		if line.UOM == "" {
+			line.UOM = "PCS"
+		}
+		if line.LineOrder == 0 {
+			line.LineOrder = i + 1
+		}
+		if _, err := repo.InsertTemplateLine(ctx, line); err != nil {
+			return fmt.Errorf("insert quotation template line: %w", err)
+		}
+	}
+	return nil
+}
+
+// priceLines applies line and document discounts to the requested lines.
+func priceLines(reqs []CreateQuotationLineReq, discount shared.DocumentDiscount) (shared.DocumentTotals, error) {
+	pricing := make([]shared.LinePricing, len(reqs))
+	for i, req := range reqs {
+		pricing[i] = shared.LinePricing{
+			Quantity:        req.Quantity,
+			UnitPrice:       req.UnitPrice,
+			DiscountPercent: req.DiscountPercent,
+			TaxPercent:      req.TaxPercent,
+		}
+	}
+	return shared.CalculateDocumentTotals(pricing, discount)
+}
+
+// requestLines turns stored lines back into line requests for re-pricing.
+// applyDefaultTax sets the tax of lines marked UseDefaultTax to the
+// customer's default tax on the quote date.
+func (s *Service) applyDefaultTax(ctx context.Context, customerID int64, on time.Time, lines []CreateQuotationLineReq) error {
+	var tax *coreshared.TaxRate
+	for i := range lines {
+		if !lines[i].UseDefaultTax {
+			continue
+		}
+		if tax == nil {
+			t, err := shared.DefaultTax(ctx, s.taxes, customerID, on)
+			if err != nil {
+				return err
+			}
+			tax = &t
+		}
+		lines[i].TaxPercent = tax.Rate
+	}
+	return nil
+}
+
+// DefaultTaxNotice describes the customer's default tax applied to the given
+// number of lines on the date; it is empty when none applied.
+func (s *Service) DefaultTaxNotice(ctx context.Context, customerID int64, on time.Time, lines int) (string, error) {
+	if s.taxes == nil || lines == 0 {
+		return "", nil
+	}
+	tax, err := s.taxes.CustomerDefaultTax(ctx, customerID, on)
+	if err != nil {
+		return "", err
+	}
+	return shared.DefaultTaxNotice(tax, lines), nil
+}
+
+func lineUnits(reqs []CreateQuotationLineReq) []shared.LineUnit {
+	units := make([]shared.LineUnit, len(reqs))
+	for i, req := range reqs {
+		units[i] = shared.LineUnit{ProductID: req.ProductID, UOM: req.UOM}
+	}
+	return units
+}
+
+func requestLines(lines []QuotationLine) []CreateQuotationLineReq {
+	reqs := make([]CreateQuotationLineReq, len(lines))
+	for i, line := range lines {
+		reqs[i] = CreateQuotationLineReq{
+			ProductID:       line.ProductID,
+			Description:     line.Description,
+			Quantity:        line.Quantity,
+			UOM:             line.UOM,
+			UnitPrice:       line.UnitPrice,
+			DiscountPercent: line.DiscountPercent,
+			TaxPercent:      line.TaxPercent,
+			Notes:           line.Notes,
+			LineOrder:       line.LineOrder,
+		}
+	}
+	return reqs
 }
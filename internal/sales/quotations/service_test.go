@@ -0,0 +1,490 @@
+package quotations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/products"
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type fakeTemplateRepo struct {
+	Repository
+	template *QuotationTemplate
+}
+
+func (f *fakeTemplateRepo) GetTemplate(context.Context, int64) (*QuotationTemplate, error) {
+	if f.template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	return f.template, nil
+}
+
+type fakePricer map[int64]products.Product
+
+func (f fakePricer) Get(_ context.Context, id int64) (products.Product, error) {
+	p, ok := f[id]
+	if !ok {
+		return products.Product{}, errors.New("product not found")
+	}
+	return p, nil
+}
+
+func TestTemplateLinesResolveCurrentPrices(t *testing.T) {
+	repo := &fakeTemplateRepo{template: &QuotationTemplate{ID: 3, Lines: []QuotationTemplateLine{
+		{ProductID: 10, Quantity: 2, UOM: "PCS", DiscountPercent: 5, TaxPercent: 11},
+		{ProductID: 11, Quantity: 1, UOM: "BOX"},
+	}}}
+	svc := NewService(repo, nil)
+	svc.SetProductPricer(fakePricer{
+		10: {ID: 10, Code: "P-10", Price: 125000, IsActive: true},
+		11: {ID: 11, Code: "P-11", Price: 40000, IsActive: true},
+	})
+
+	lines, err := svc.TemplateLines(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("TemplateLines: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0].UnitPrice != 125000 || lines[0].DiscountPercent != 5 || lines[0].Quantity != 2 || lines[0].LineOrder != 1 {
+		t.Fatalf("unexpected first line %+v", lines[0])
+	}
+	if lines[1].UnitPrice != 40000 || lines[1].UOM != "BOX" || lines[1].LineOrder != 2 {
+		t.Fatalf("unexpected second line %+v", lines[1])
+	}
+}
+
+func TestTemplateLinesRejectInactiveProduct(t *testing.T) {
+	repo := &fakeTemplateRepo{template: &QuotationTemplate{Lines: []QuotationTemplateLine{{ProductID: 10, Quantity: 1}}}}
+	svc := NewService(repo, nil)
+	svc.SetProductPricer(fakePricer{10: {ID: 10, Code: "P-10", Price: 1}})
+
+	if _, err := svc.TemplateLines(context.Background(), 1); !errors.Is(err, coreshared.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestCreateTemplateRequiresLines(t *testing.T) {
+	svc := NewService(&fakeTemplateRepo{}, nil)
+	_, err := svc.CreateTemplate(context.Background(), SaveTemplateRequest{CompanyID: 1, Name: "Monthly restock"}, 1)
+	if !errors.Is(err, coreshared.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+type fakeUpdateRepo struct {
+	Repository
+	quotation Quotation
+	updates   map[string]interface{}
+}
+
+func (f *fakeUpdateRepo) Get(context.Context, int64) (*Quotation, error) {
+	q := f.quotation
+	return &q, nil
+}
+
+func (f *fakeUpdateRepo) WithTx(ctx context.Context, fn func(context.Context, Repository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeUpdateRepo) Update(_ context.Context, _ int64, updates map[string]interface{}) error {
+	f.updates = updates
+	return nil
+}
+
+func TestUpdateRejectsStaleVersion(t *testing.T) {
+	loaded := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	repo := &fakeUpdateRepo{quotation: Quotation{ID: 1, Status: QuotationStatusDraft, UpdatedAt: loaded.Add(time.Minute)}}
+	svc := NewService(repo, nil)
+	notes := "revised"
+
+	_, err := svc.Update(context.Background(), 1, UpdateQuotationRequest{Notes: &notes, ExpectedUpdatedAt: &loaded})
+	if !errors.Is(err, coreshared.ErrStaleRecord) {
+		t.Fatalf("expected ErrStaleRecord, got %v", err)
+	}
+	if repo.updates != nil {
+		t.Fatalf("stale update must not be written, got %v", repo.updates)
+	}
+
+	current := repo.quotation.UpdatedAt
+	if _, err := svc.Update(context.Background(), 1, UpdateQuotationRequest{Notes: &notes, ExpectedUpdatedAt: &current}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if repo.updates["expected_updated_at"] != current {
+		t.Fatalf("expected the version to guard the write, got %v", repo.updates)
+	}
+}
+
+type fakeReorderRepo struct {
+	fakeUpdateRepo
+	reordered []int64
+}
+
+func (f *fakeReorderRepo) WithTx(ctx context.Context, fn func(context.Context, Repository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeReorderRepo) ReorderLines(_ context.Context, _ int64, lineIDs []int64) error {
+	f.reordered = lineIDs
+	return nil
+}
+
+func TestReorderLinesRequiresExactLineSet(t *testing.T) {
+	repo := &fakeReorderRepo{fakeUpdateRepo: fakeUpdateRepo{quotation: Quotation{
+		ID:     1,
+		Status: QuotationStatusDraft,
+		Lines:  []QuotationLine{{ID: 10, LineOrder: 1}, {ID: 11, LineOrder: 2}, {ID: 12, LineOrder: 3}},
+	}}}
+	svc := NewService(repo, nil)
+	ctx := context.Background()
+
+	for _, ids := range [][]int64{{12, 10}, {12, 10, 10}, {12, 10, 99}, {12, 10, 11, 13}} {
+		if _, err := svc.ReorderLines(ctx, 1, ReorderLinesRequest{LineIDs: ids}); !errors.Is(err, coreshared.ErrValidation) {
+			t.Fatalf("%v: expected validation error, got %v", ids, err)
+		}
+	}
+	if repo.reordered != nil {
+		t.Fatalf("invalid orders must not be written, got %v", repo.reordered)
+	}
+
+	if _, err := svc.ReorderLines(ctx, 1, ReorderLinesRequest{LineIDs: []int64{12, 10, 11}}); err != nil {
+		t.Fatalf("ReorderLines: %v", err)
+	}
+	if len(repo.reordered) != 3 || repo.reordered[0] != 12 || repo.reordered[2] != 11 {
+		t.Fatalf("unexpected order %v", repo.reordered)
+	}
+
+	repo.quotation.Status = QuotationStatusSubmitted
+	if _, err := svc.ReorderLines(ctx, 1, ReorderLinesRequest{LineIDs: []int64{10, 11, 12}}); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+type fakeReviewRepo struct {
+	fakeUpdateRepo
+	reviews []LineReview
+	status  QuotationStatus
+}
+
+func (f *fakeReviewRepo) WithTx(ctx context.Context, fn func(context.Context, Repository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeReviewRepo) InsertLineReview(_ context.Context, review LineReview) (int64, error) {
+	review.ID = int64(len(f.reviews) + 1)
+	f.reviews = append(f.reviews, review)
+	return review.ID, nil
+}
+
+func (f *fakeReviewRepo) ListLineReviews(context.Context, int64) ([]LineReview, error) {
+	return f.reviews, nil
+}
+
+func (f *fakeReviewRepo) UpdateStatus(_ context.Context, _ int64, status QuotationStatus, _ int64, _ *string) error {
+	f.status = status
+	f.quotation.Status = status
+	return nil
+}
+
+func TestReviewFlagsLinesAndBlocksConversion(t *testing.T) {
+	repo := &fakeReviewRepo{fakeUpdateRepo: fakeUpdateRepo{quotation: Quotation{
+		ID:     1,
+		Status: QuotationStatusSubmitted,
+		Lines:  []QuotationLine{{ID: 10, LineOrder: 1}, {ID: 11, LineOrder: 2}},
+	}}}
+	svc := NewService(repo, nil)
+	ctx := context.Background()
+
+	_, err := svc.Review(ctx, 1, 7, ReviewQuotationRequest{Lines: []LineReviewInput{{LineID: 11, Decision: LineDecisionRevise}}})
+	if !errors.Is(err, coreshared.ErrValidation) {
+		t.Fatalf("expected a note to be required, got %v", err)
+	}
+	_, err = svc.Review(ctx, 1, 7, ReviewQuotationRequest{Lines: []LineReviewInput{{LineID: 99, Decision: LineDecisionApproved}}})
+	if !errors.Is(err, coreshared.ErrValidation) {
+		t.Fatalf("expected foreign line to be rejected, got %v", err)
+	}
+	if _, err := svc.Review(ctx, 1, 7, ReviewQuotationRequest{RequireResubmission: true}); !errors.Is(err, coreshared.ErrValidation) {
+		t.Fatalf("expected resubmission without flags to be rejected, got %v", err)
+	}
+	if len(repo.reviews) != 0 {
+		t.Fatalf("invalid reviews must not be written, got %v", repo.reviews)
+	}
+
+	q, err := svc.Review(ctx, 1, 7, ReviewQuotationRequest{Lines: []LineReviewInput{{LineID: 11, Decision: LineDecisionRevise, Note: "price below floor"}}})
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if q.Status != QuotationStatusApproved || len(repo.reviews) != 2 {
+		t.Fatalf("expected approval with a decision per line, got %s and %d reviews", q.Status, len(repo.reviews))
+	}
+	if repo.reviews[0].Decision != LineDecisionApproved || repo.reviews[1].Decision != LineDecisionRevise {
+		t.Fatalf("unexpected decisions %+v", repo.reviews)
+	}
+	if err := CheckConvertible(ctx, repo, 1); !errors.Is(err, ErrOpenLineFlags) {
+		t.Fatalf("expected open flags to block conversion, got %v", err)
+	}
+
+	resolved := time.Now()
+	repo.reviews[1].ResolvedAt = &resolved
+	if err := CheckConvertible(ctx, repo, 1); err != nil {
+		t.Fatalf("resolved flags must not block conversion, got %v", err)
+	}
+}
+
+func TestReviewRequireResubmissionReturnsToDraft(t *testing.T) {
+	repo := &fakeReviewRepo{fakeUpdateRepo: fakeUpdateRepo{quotation: Quotation{
+		ID:     1,
+		Status: QuotationStatusSubmitted,
+		Lines:  []QuotationLine{{ID: 10, LineOrder: 1}},
+	}}}
+	svc := NewService(repo, nil)
+
+	q, err := svc.Review(context.Background(), 1, 7, ReviewQuotationRequest{
+		Lines:               []LineReviewInput{{LineID: 10, Decision: LineDecisionRevise, Note: "wrong UOM"}},
+		RequireResubmission: true,
+	})
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if q.Status != QuotationStatusDraft {
+		t.Fatalf("expected DRAFT, got %s", q.Status)
+	}
+}
+
+func TestStatusChangesGoThroughQuotationTransitions(t *testing.T) {
+	repo := &fakeReviewRepo{fakeUpdateRepo: fakeUpdateRepo{quotation: Quotation{
+		ID:     1,
+		Status: QuotationStatusApproved,
+		Lines:  []QuotationLine{{ID: 10, LineOrder: 1}},
+	}}}
+	svc := NewService(repo, nil)
+	ctx := context.Background()
+
+	_, err := svc.Review(ctx, 1, 7, ReviewQuotationRequest{})
+	var te *coreshared.TransitionError
+	if !errors.Is(err, ErrInvalidStatus) || !errors.As(err, &te) || te.From != "APPROVED" || te.To != "APPROVED" {
+		t.Fatalf("expected an APPROVED -> APPROVED transition error, got %v", err)
+	}
+	if _, err := svc.Submit(ctx, 1, 7); !errors.Is(err, coreshared.ErrInvalidTransition) {
+		t.Fatalf("expected approved quotation not to be resubmitted, got %v", err)
+	}
+	if len(repo.reviews) != 0 {
+		t.Fatalf("rejected transitions must not write reviews, got %v", repo.reviews)
+	}
+	if !QuotationTransitions.Can(QuotationStatusApproved, QuotationStatusConverted) {
+		t.Fatal("approved quotations convert to sales orders")
+	}
+	for _, status := range []QuotationStatus{QuotationStatusRejected, QuotationStatusConverted} {
+		if q := (Quotation{Status: status}); !q.IsTerminal() {
+			t.Fatalf("%s must be terminal", status)
+		}
+	}
+}
+
+func TestOpenLineFlagsUsesLatestDecision(t *testing.T) {
+	line := int64(10)
+	reviews := []LineReview{
+		{ID: 1, QuotationLineID: &line, Decision: LineDecisionRevise},
+		{ID: 2, QuotationLineID: &line, Decision: LineDecisionApproved},
+		{ID: 3, Decision: LineDecisionRevise},
+	}
+	if open := OpenLineFlags(reviews); len(open) != 0 {
+		t.Fatalf("later approval should clear the flag, got %+v", open)
+	}
+	reviews = append(reviews, LineReview{ID: 4, QuotationLineID: &line, Decision: LineDecisionRevise})
+	if open := OpenLineFlags(reviews); len(open) != 1 || open[0].ID != 4 {
+		t.Fatalf("expected review 4 open, got %+v", open)
+	}
+}
+
+type fakeRepriceRepo struct {
+	fakeUpdateRepo
+	inserted []QuotationLine
+}
+
+func (f *fakeRepriceRepo) WithTx(ctx context.Context, fn func(context.Context, Repository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeRepriceRepo) DeleteLines(context.Context, int64) error {
+	f.inserted = nil
+	return nil
+}
+
+func (f *fakeRepriceRepo) InsertLine(_ context.Context, line QuotationLine) (int64, error) {
+	f.inserted = append(f.inserted, line)
+	return int64(len(f.inserted)), nil
+}
+
+func TestUpdateDocumentDiscountRepricesExistingLines(t *testing.T) {
+	repo := &fakeRepriceRepo{fakeUpdateRepo: fakeUpdateRepo{quotation: Quotation{
+		ID:     1,
+		Status: QuotationStatusDraft,
+		Lines: []QuotationLine{
+			{ProductID: 7, Quantity: 1, UnitPrice: 100, TaxPercent: 10, LineOrder: 1},
+			{ProductID: 8, Quantity: 2, UnitPrice: 100, TaxPercent: 10, LineOrder: 2},
+		},
+	}}}
+	svc := NewService(repo, nil)
+	amount := 30.0
+
+	if _, err := svc.Update(context.Background(), 1, UpdateQuotationRequest{DocumentDiscountAmount: &amount}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(repo.inserted) != 2 || repo.inserted[0].DocumentDiscountAmount != 10 || repo.inserted[1].DocumentDiscountAmount != 20 {
+		t.Fatalf("expected the discount spread 10/20, got %+v", repo.inserted)
+	}
+	if repo.inserted[1].DiscountAmount != 20 || repo.inserted[1].TaxAmount != 18 || repo.inserted[1].LineTotal != 198 {
+		t.Fatalf("expected tax on the discounted base, got %+v", repo.inserted[1])
+	}
+	if repo.updates["subtotal"] != 270.0 || repo.updates["total_amount"] != 297.0 || repo.updates["document_discount_amount"] != 30.0 {
+		t.Fatalf("unexpected header updates %v", repo.updates)
+	}
+}
+
+type fakeRejectRepo struct {
+	fakeReorderRepo
+	status     QuotationStatus
+	reason     *string
+	lossReason string
+}
+
+func (f *fakeRejectRepo) WithTx(ctx context.Context, fn func(context.Context, Repository) error) error {
+	return fn(ctx, f)
+}
+
+func (f *fakeRejectRepo) UpdateStatus(_ context.Context, _ int64, status QuotationStatus, _ int64, reason *string) error {
+	f.status = status
+	f.reason = reason
+	return nil
+}
+
+func (f *fakeRejectRepo) SetLossReason(_ context.Context, _ int64, code string) error {
+	f.lossReason = code
+	return nil
+}
+
+func TestRejectRequiresConfiguredLossReason(t *testing.T) {
+	repo := &fakeRejectRepo{fakeReorderRepo: fakeReorderRepo{fakeUpdateRepo: fakeUpdateRepo{quotation: Quotation{ID: 1, Status: QuotationStatusSubmitted}}}}
+	svc := NewService(repo, nil)
+
+	for _, code := range []string{"", "CHEAPER"} {
+		_, err := svc.Reject(context.Background(), 1, 9, RejectQuotationRequest{LossReason: code, Reason: "lost"})
+		if !errors.Is(err, ErrInvalidLossReason) {
+			t.Fatalf("loss reason %q: expected ErrInvalidLossReason, got %v", code, err)
+		}
+	}
+	if repo.status != "" {
+		t.Fatalf("invalid rejection must not be written, got status %s", repo.status)
+	}
+
+	if _, err := svc.Reject(context.Background(), 1, 9, RejectQuotationRequest{LossReason: " competitor ", Reason: " went with Acme "}); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if repo.status != QuotationStatusRejected || repo.lossReason != "COMPETITOR" || repo.reason == nil || *repo.reason != "went with Acme" {
+		t.Fatalf("unexpected rejection status=%s loss=%q reason=%v", repo.status, repo.lossReason, repo.reason)
+	}
+}
+
+func TestNewLossReasonsRejectsDuplicateCodes(t *testing.T) {
+	if _, err := NewLossReasons([]LossReason{{Code: "price", Label: "Price"}, {Code: "PRICE", Label: "Too expensive"}}); err == nil {
+		t.Fatal("expected duplicate codes to be rejected")
+	}
+	reasons, err := NewLossReasons([]LossReason{{Code: " price ", Label: "Price"}})
+	if err != nil || reasons[0].Code != "PRICE" {
+		t.Fatalf("expected normalised code, got %+v, %v", reasons, err)
+	}
+}
+
+func TestSummarizeWinLoss(t *testing.T) {
+	reasons := []LossReason{{Code: "PRICE", Label: "Price"}, {Code: "TIMING", Label: "Timing"}}
+	rows := []WinLossRow{
+		{Period: "2026-02", CustomerID: 2, CustomerName: "Beta", Status: QuotationStatusConverted, Count: 1, Amount: 300},
+		{Period: "2026-01", CustomerID: 1, CustomerName: "Acme", Status: QuotationStatusConverted, Count: 3, Amount: 900},
+		{Period: "2026-01", CustomerID: 1, CustomerName: "Acme", Status: QuotationStatusRejected, LossReason: "PRICE", Count: 2, Amount: 400},
+		{Period: "2026-01", CustomerID: 1, CustomerName: "Acme", Status: QuotationStatusSubmitted, Count: 1, Amount: 50},
+		{Period: "2026-02", CustomerID: 2, CustomerName: "Beta", Status: QuotationStatusRejected, Count: 1, Amount: 100},
+	}
+
+	report := SummarizeWinLoss(rows, reasons)
+
+	if report.Totals.Quoted != 8 || report.Totals.Won != 4 || report.Totals.Lost != 3 || report.Totals.Open != 1 {
+		t.Fatalf("unexpected totals %+v", report.Totals)
+	}
+	if got := report.Totals.ConversionRate; got < 57.14 || got > 57.15 {
+		t.Fatalf("expected 4 of 7 decided converted, got %v", got)
+	}
+	if len(report.Periods) != 2 || report.Periods[0].Key != "2026-01" || report.Periods[0].ConversionRate != 60 {
+		t.Fatalf("unexpected periods %+v", report.Periods)
+	}
+	if len(report.Customers) != 2 || report.Customers[0].Label != "Acme" || report.Customers[0].LostAmount != 400 {
+		t.Fatalf("unexpected customers %+v", report.Customers)
+	}
+	want := []string{"PRICE", UnspecifiedLossReason, "TIMING"}
+	if len(report.LossReasons) != len(want) {
+		t.Fatalf("unexpected loss reasons %+v", report.LossReasons)
+	}
+	for i, code := range want {
+		if report.LossReasons[i].Code != code {
+			t.Fatalf("loss reason %d: expected %s, got %+v", i, code, report.LossReasons)
+		}
+	}
+	if report.LossReasons[2].Count != 0 || report.LossReasons[0].Share < 66.66 || report.LossReasons[0].Share > 66.67 {
+		t.Fatalf("unexpected loss reason shares %+v", report.LossReasons)
+	}
+}
+
+type fakeTaxResolver struct {
+	customers map[int64]coreshared.TaxRate
+}
+
+func (f fakeTaxResolver) CustomerDefaultTax(_ context.Context, customerID int64, _ time.Time) (*coreshared.TaxRate, error) {
+	tax, ok := f.customers[customerID]
+	if !ok {
+		return nil, nil
+	}
+	return &tax, nil
+}
+
+func (f fakeTaxResolver) SupplierDefaultTax(context.Context, int64, time.Time) (*coreshared.TaxRate, error) {
+	return nil, nil
+}
+
+func (f fakeTaxResolver) RateOn(context.Context, int64, time.Time) (*coreshared.TaxRate, error) {
+	return nil, nil
+}
+
+func TestApplyDefaultTaxFillsBlankLines(t *testing.T) {
+	svc := NewService(nil, nil)
+	svc.SetDefaultTaxResolver(fakeTaxResolver{customers: map[int64]coreshared.TaxRate{
+		1: {Code: "PPN", Rate: 11},
+		2: {Code: "NO-TAX", Rate: 0},
+	}})
+	ctx := context.Background()
+	on := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	lines := []CreateQuotationLineReq{{TaxPercent: 5}, {UseDefaultTax: true}}
+	if err := svc.applyDefaultTax(ctx, 1, on, lines); err != nil {
+		t.Fatalf("apply default tax: %v", err)
+	}
+	if lines[0].TaxPercent != 5 || lines[1].TaxPercent != 11 {
+		t.Fatalf("expected entered tax kept and default applied, got %v and %v", lines[0].TaxPercent, lines[1].TaxPercent)
+	}
+
+	exempt := []CreateQuotationLineReq{{TaxPercent: 11, UseDefaultTax: true}}
+	if err := svc.applyDefaultTax(ctx, 2, on, exempt); err != nil {
+		t.Fatalf("apply exempt tax: %v", err)
+	}
+	if exempt[0].TaxPercent != 0 {
+		t.Fatalf("expected exempt customer to carry no tax, got %v", exempt[0].TaxPercent)
+	}
+
+	err := svc.applyDefaultTax(ctx, 3, on, []CreateQuotationLineReq{{UseDefaultTax: true}})
+	if !errors.Is(err, coreshared.ErrNoDefaultTax) {
+		t.Fatalf("expected ErrNoDefaultTax, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package quotations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// UnspecifiedLossReason groups rejections recorded before loss reasons existed.
+const UnspecifiedLossReason = "UNSPECIFIED"
+
+// WinLossFilter scopes the win/loss report by quote date and customer.
+type WinLossFilter struct {
+	CompanyID  int64     `json:"company_id"`
+	CustomerID *int64    `json:"customer_id,omitempty"`
+	DateFrom   time.Time `json:"date_from"`
+	DateTo     time.Time `json:"date_to"`
+}
+
+// WinLossRow counts quotations of one month, customer, status and loss reason.
+type WinLossRow struct {
+	Period       string
+	CustomerID   int64
+	CustomerName string
+	Status       QuotationStatus
+	LossReason   string
+	Count        int
+	Amount       float64
+}
+
+// WinLossStats summarises outcomes. Won quotations were converted to a sales
+// order, lost ones were rejected and the rest are still open.
+type WinLossStats struct {
+	Quoted     int     `json:"quoted"`
+	Won        int     `json:"won"`
+	Lost       int     `json:"lost"`
+	Open       int     `json:"open"`
+	WonAmount  float64 `json:"won_amount"`
+	LostAmount float64 `json:"lost_amount"`
+	// ConversionRate is won as a percentage of won plus lost.
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// WinLossGroup is the outcome summary for one period or customer.
+type WinLossGroup struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	WinLossStats
+}
+
+// LossReasonStat counts lost quotations by loss reason.
+type LossReasonStat struct {
+	Code   string  `json:"code"`
+	Label  string  `json:"label"`
+	Count  int     `json:"count"`
+	Amount float64 `json:"amount"`
+	// Share is the percentage of lost quotations with this reason.
+	Share float64 `json:"share"`
+}
+
+// WinLossReport is the quotation pipeline outcome analysis.
+type WinLossReport struct {
+	Filter      WinLossFilter    `json:"filter"`
+	Totals      WinLossStats     `json:"totals"`
+	Periods     []WinLossGroup   `json:"periods"`
+	Customers   []WinLossGroup   `json:"customers"`
+	LossReasons []LossReasonStat `json:"loss_reasons"`
+}
+
+// WinLoss summarises conversion rates and loss reasons by month and customer
+// for quotations dated within the filter.
+func (s *Service) WinLoss(ctx context.Context, filter WinLossFilter) (WinLossReport, error) {
+	if filter.DateFrom.IsZero() || filter.DateTo.IsZero() {
+		return WinLossReport{}, fmt.Errorf("%w: date range is required", coreshared.ErrValidation)
+	}
+	if filter.DateTo.Before(filter.DateFrom) {
+		return WinLossReport{}, fmt.Errorf("%w: date_to must not be before date_from", coreshared.ErrValidation)
+	}
+	rows, err := s.repo.WinLossRows(ctx, filter)
+	if err != nil {
+		return WinLossReport{}, err
+	}
+	report := SummarizeWinLoss(rows, s.LossReasons())
+	report.Filter = filter
+	return report, nil
+}
+
+// SummarizeWinLoss aggregates rows into totals, months in order, customers by
+// quote count and loss reasons by count. Configured reasons are listed even
+// when unused so the report shape stays stable.
+func SummarizeWinLoss(rows []WinLossRow, reasons []LossReason) WinLossReport {
+	var report WinLossReport
+	periods := make(map[string]*WinLossGroup)
+	customers := make(map[int64]*WinLossGroup)
+	lost := make(map[string]*LossReasonStat, len(reasons))
+	for _, r := range reasons {
+		lost[r.Code] = &LossReasonStat{Code: r.Code, Label: r.Label}
+	}
+	for _, row := range rows {
+		period := periods[row.Period]
+		if period == nil {
+			period = &WinLossGroup{Key: row.Period, Label: row.Period}
+			periods[row.Period] = period
+		}
+		customer := customers[row.CustomerID]
+		if customer == nil {
+			customer = &WinLossGroup{Key: fmt.Sprint(row.CustomerID), Label: row.CustomerName}
+			customers[row.CustomerID] = customer
+		}
+		for _, stats := range []*WinLossStats{&report.Totals, &period.WinLossStats, &customer.WinLossStats} {
+			stats.add(row)
+		}
+		if row.Status != QuotationStatusRejected {
+			continue
+		}
+		code := row.LossReason
+		if code == "" {
+			code = UnspecifiedLossReason
+		}
+		stat := lost[code]
+		if stat == nil {
+			label := lossReasonLabel(reasons, code)
+			if code == UnspecifiedLossReason {
+				label = "Not recorded"
+			}
+			stat = &LossReasonStat{Code: code, Label: label}
+			lost[code] = stat
+		}
+		stat.Count += row.Count
+		stat.Amount += row.Amount
+	}
+
+	report.Totals.rate()
+	for _, p := range periods {
+		p.rate()
+		report.Periods = append(report.Periods, *p)
+	}
+	sort.Slice(report.Periods, func(i, j int) bool { return report.Periods[i].Key < report.Periods[j].Key })
+	for _, c := range customers {
+		c.rate()
+		report.Customers = append(report.Customers, *c)
+	}
+	sort.Slice(report.Customers, func(i, j int) bool {
+		if report.Customers[i].Quoted != report.Customers[j].Quoted {
+			return report.Customers[i].Quoted > report.Customers[j].Quoted
+		}
+		return report.Customers[i].Label < report.Customers[j].Label
+	})
+	order := make(map[string]int, len(reasons))
+	for i, r := range reasons {
+		order[r.Code] = i
+	}
+	for _, stat := range lost {
+		if report.Totals.Lost > 0 {
+			stat.Share = float64(stat.Count) / float64(report.Totals.Lost) * 100
+		}
+		report.LossReasons = append(report.LossReasons, *stat)
+	}
+	sort.Slice(report.LossReasons, func(i, j int) bool {
+		a, b := report.LossReasons[i], report.LossReasons[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		ai, aok := order[a.Code]
+		bi, bok := order[b.Code]
+		if aok != bok {
+			return aok
+		}
+		if ai != bi {
+			return ai < bi
+		}
+		return a.Code < b.Code
+	})
+	return report
+}
+
+func (s *WinLossStats) add(row WinLossRow) {
+	s.Quoted += row.Count
+	switch row.Status {
+	case QuotationStatusConverted:
+		s.Won += row.Count
+		s.WonAmount += row.Amount
+	case QuotationStatusRejected:
+		s.Lost += row.Count
+		s.LostAmount += row.Amount
+	default:
+		s.Open += row.Count
+	}
+}
+
+func (s *WinLossStats) rate() {
+	if decided := s.Won + s.Lost; decided > 0 {
+		s.ConversionRate = float64(s.Won) / float64(decided) * 100
+	}
+}
@@ -0,0 +1,236 @@
+package returns
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
+	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/internal/view"
+)
+
+// Handler serves the sales return pages under /sales/returns.
+type Handler struct {
+	logger    *slog.Logger
+	service   *Service
+	templates *view.Engine
+	csrf      *shared.CSRFManager
+	rbac      rbac.Middleware
+}
+
+// NewHandler constructs the sales return handler.
+func NewHandler(logger *slog.Logger, service *Service, templates *view.Engine, csrf *shared.CSRFManager, rbac rbac.Middleware) *Handler {
+	return &Handler{logger: logger, service: service, templates: templates, csrf: csrf, rbac: rbac}
+}
+
+// MountRoutes registers the return routes on the /sales router.
+func (h *Handler) MountRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAny(shared.PermSalesReturnView))
+		r.Get("/returns", h.List)
+		r.Get("/returns/{id}", h.Show)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAll(shared.PermSalesReturnCreate))
+		r.Get("/returns/new", h.ShowForm)
+		r.Post("/returns", h.Create)
+	})
+	r.With(h.rbac.RequireAll(shared.PermSalesReturnReceive)).Post("/returns/{id}/receive", h.Receive)
+	r.With(h.rbac.RequireAll(shared.PermFinanceAREdit)).Post("/returns/{id}/credit", h.Credit)
+}
+
+// List shows returns, optionally narrowed to a status or sales order.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	filter := ListFilter{
+		CompanyID: shared.ScopedCompanyID(r.Context(), 0, 0),
+		Status:    Status(strings.ToUpper(r.URL.Query().Get("status"))),
+	}
+	filter.SalesOrderID, _ = strconv.ParseInt(r.URL.Query().Get("so_id"), 10, 64)
+	list, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list sales returns failed", "error", err)
+		httpx.Error(w, r, "Failed to load returns", http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/sales/returns_list.html", map[string]any{
+		"Returns": list,
+		"Status":  string(filter.Status),
+	}, http.StatusOK)
+}
+
+// Show renders one return with its receive and credit actions.
+func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	ret, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			h.logger.ErrorContext(r.Context(), "get sales return failed", "error", err, "id", id)
+		}
+		httpx.Error(w, r, "Return not found", http.StatusNotFound)
+		return
+	}
+	subtotal, tax, total := ret.CreditAmounts()
+	h.render(w, r, "pages/sales/return_detail.html", map[string]any{
+		"Return":     ret,
+		"Subtotal":   subtotal,
+		"Tax":        tax,
+		"Total":      total,
+		"CanReceive": h.rbac.Allows(r, shared.PermSalesReturnReceive),
+		"CanCredit":  h.rbac.Allows(r, shared.PermFinanceAREdit),
+	}, http.StatusOK)
+}
+
+// ShowForm lists the returnable lines of the sales order given by so_id.
+func (h *Handler) ShowForm(w http.ResponseWriter, r *http.Request) {
+	data := map[string]any{}
+	if raw := r.URL.Query().Get("so_id"); raw != "" {
+		soID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			data["Error"] = "Invalid sales order"
+		} else if order, err := h.service.OrderInfo(r.Context(), soID); err != nil {
+			data["Error"] = returnErrorMessage(err)
+		} else {
+			data["Order"] = order
+		}
+	}
+	h.render(w, r, "pages/sales/return_form.html", data, http.StatusOK)
+}
+
+// Create requests a return from the quantities posted per sales order line.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	soID, _ := strconv.ParseInt(r.PostFormValue("sales_order_id"), 10, 64)
+	input := CreateInput{
+		SalesOrderID: soID,
+		Reason:       r.PostFormValue("reason"),
+		RequestedBy:  currentUserID(r),
+	}
+	input.DeliveryOrderID, _ = strconv.ParseInt(r.PostFormValue("delivery_order_id"), 10, 64)
+	input.WarehouseID, _ = strconv.ParseInt(r.PostFormValue("warehouse_id"), 10, 64)
+	lineIDs := r.PostForm["line_id"]
+	quantities := r.PostForm["quantity"]
+	for i := range lineIDs {
+		if i >= len(quantities) {
+			break
+		}
+		lineID, _ := strconv.ParseInt(lineIDs[i], 10, 64)
+		qty, _ := strconv.ParseFloat(strings.TrimSpace(quantities[i]), 64)
+		input.Lines = append(input.Lines, LineInput{SalesOrderLineID: lineID, Quantity: qty})
+	}
+	ret, err := h.service.Create(r.Context(), input)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "create sales return failed", "error", err, "so_id", soID)
+		h.redirectWithFlash(w, r, "/sales/returns/new?so_id="+strconv.FormatInt(soID, 10), "error", returnErrorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, returnPath(ret.ID), "success", "Return "+ret.Number+" requested")
+}
+
+// Receive restocks the returned goods. Each line's received quantity is
+// posted as received_<line id>; missing values receive the line in full.
+func (h *Handler) Receive(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	input := ReceiveInput{ReturnID: id, ReceivedBy: currentUserID(r), Quantities: map[int64]float64{}}
+	for key, values := range r.PostForm {
+		lineID, ok := strings.CutPrefix(key, "received_")
+		if !ok || len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+			continue
+		}
+		parsedID, err := strconv.ParseInt(lineID, 10, 64)
+		if err != nil {
+			continue
+		}
+		qty, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+		if err != nil {
+			h.redirectWithFlash(w, r, returnPath(id), "error", "Invalid received quantity")
+			return
+		}
+		input.Quantities[parsedID] = qty
+	}
+	if _, err := h.service.Receive(r.Context(), input); err != nil {
+		h.logger.ErrorContext(r.Context(), "receive sales return failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, returnPath(id), "error", returnErrorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, returnPath(id), "success", "Returned goods received into stock")
+}
+
+// Credit raises the AR credit note for a received return.
+func (h *Handler) Credit(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	ret, err := h.service.Credit(r.Context(), id, currentUserID(r))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "credit sales return failed", "error", err, "id", id)
+		h.redirectWithFlash(w, r, returnPath(id), "error", returnErrorMessage(err))
+		return
+	}
+	h.redirectWithFlash(w, r, returnPath(id), "success", "Credit note "+ret.CreditNoteNo+" raised")
+}
+
+// returnErrorMessage shows workflow errors verbatim and hides the rest
+// behind the generic user-safe message.
+func returnErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidStatus), errors.Is(err, ErrExceedsReturnable), errors.Is(err, ErrNothingReceived),
+		errors.Is(err, ErrNotFound), errors.Is(err, shared.ErrValidation):
+		return err.Error()
+	}
+	return shared.UserSafeMessage(err)
+}
+
+func returnPath(id int64) string {
+	return "/sales/returns/" + strconv.FormatInt(id, 10)
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, tmpl string, data map[string]any, status int) {
+	sess := shared.SessionFromContext(r.Context())
+	csrfToken, _ := h.csrf.EnsureToken(r.Context(), sess)
+	var flash *shared.FlashMessage
+	if sess != nil {
+		flash = sess.PopFlash()
+	}
+	viewData := view.TemplateData{
+		Title:       "Sales Returns",
+		CSRFToken:   csrfToken,
+		Flash:       flash,
+		CurrentPath: r.URL.Path,
+		Data:        data,
+	}
+	w.WriteHeader(status)
+	if err := h.templates.Render(w, tmpl, viewData); err != nil {
+		h.logger.ErrorContext(r.Context(), "render sales return page", "error", err, "template", tmpl)
+	}
+}
+
+func (h *Handler) redirectWithFlash(w http.ResponseWriter, r *http.Request, url, kind, message string) {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		sess.AddFlash(shared.FlashMessage{Kind: kind, Message: message})
+	}
+	http.Redirect(w, r, url, http.StatusSeeOther)
+}
+
+func currentUserID(r *http.Request) int64 {
+	if sess := shared.SessionFromContext(r.Context()); sess != nil {
+		if id, err := strconv.ParseInt(sess.User(), 10, 64); err == nil {
+			return id
+		}
+	}
+	return 0
+}
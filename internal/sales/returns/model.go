@@ -0,0 +1,159 @@
+// Package returns handles customer returns (RMA) against sales orders.
+package returns
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Status tracks a return from request to credit.
+type Status string
+
+const (
+	// StatusRequested is waiting for the goods to arrive.
+	StatusRequested Status = "REQUESTED"
+	// StatusReceived has been restocked and awaits its credit note.
+	StatusReceived Status = "RECEIVED"
+	// StatusCredited has an AR credit note raised for the received goods.
+	StatusCredited Status = "CREDITED"
+)
+
+var (
+	// ErrNotFound indicates the return does not exist.
+	ErrNotFound = errors.New("sales return not found")
+	// ErrInvalidStatus is returned when a step does not fit the return's status.
+	ErrInvalidStatus = errors.New("invalid sales return status")
+	// ErrExceedsReturnable indicates more is returned than was delivered and
+	// not already returned or requested.
+	ErrExceedsReturnable = errors.New("return quantity exceeds delivered quantity")
+	// ErrNothingReceived is returned when a receipt has no positive quantities.
+	ErrNothingReceived = errors.New("no returned quantity received")
+)
+
+// Return is an RMA against one sales order.
+type Return struct {
+	ID              int64
+	Number          string
+	CompanyID       int64
+	CustomerID      int64
+	CustomerName    string
+	SalesOrderID    int64
+	SalesOrderNo    string
+	DeliveryOrderID *int64
+	WarehouseID     int64
+	Currency        string
+	Status          Status
+	Reason          string
+	RequestedBy     int64
+	RequestedAt     time.Time
+	ReceivedBy      *int64
+	ReceivedAt      *time.Time
+	RestockedAt     *time.Time
+	CreditedBy      *int64
+	CreditedAt      *time.Time
+	CreditNoteID    *int64
+	CreditNoteNo    string
+	Lines           []Line
+}
+
+// Line is one returned sales order line.
+type Line struct {
+	ID               int64
+	ReturnID         int64
+	SalesOrderLineID int64
+	ProductID        int64
+	ProductName      string
+	UOM              string
+	Quantity         float64
+	QuantityReceived float64
+	UnitPrice        float64
+	DiscountPercent  float64
+	TaxPercent       float64
+	UnitCost         float64
+}
+
+// CreditAmounts returns the net, tax and gross value of the received quantity.
+func (l Line) CreditAmounts() (subtotal, tax, total float64) {
+	subtotal = round2(l.QuantityReceived * l.UnitPrice * (1 - l.DiscountPercent/100))
+	tax = round2(subtotal * l.TaxPercent / 100)
+	return subtotal, tax, subtotal + tax
+}
+
+// CreditAmounts totals the credit for every received line.
+func (r Return) CreditAmounts() (subtotal, tax, total float64) {
+	for _, line := range r.Lines {
+		s, t, _ := line.CreditAmounts()
+		subtotal += s
+		tax += t
+	}
+	subtotal, tax = round2(subtotal), round2(tax)
+	return subtotal, tax, round2(subtotal + tax)
+}
+
+// OrderInfo is the sales order a return is raised against.
+type OrderInfo struct {
+	ID          int64
+	DocNumber   string
+	CompanyID   int64
+	CustomerID  int64
+	Currency    string
+	WarehouseID int64
+	Lines       []ReturnableLine
+}
+
+// ReturnableLine is a sales order line with how much can still be returned.
+type ReturnableLine struct {
+	SalesOrderLineID int64
+	ProductID        int64
+	ProductName      string
+	Delivered        float64
+	Pending          float64
+	UnitPrice        float64
+	DiscountPercent  float64
+	TaxPercent       float64
+}
+
+// Returnable is the delivered quantity not already covered by an open return.
+// Received returns are already netted out of Delivered.
+func (l ReturnableLine) Returnable() float64 {
+	return math.Max(l.Delivered-l.Pending, 0)
+}
+
+// CreateInput requests a return of some delivered lines.
+type CreateInput struct {
+	SalesOrderID    int64
+	DeliveryOrderID int64
+	WarehouseID     int64
+	Reason          string
+	RequestedBy     int64
+	Lines           []LineInput
+}
+
+// LineInput is the quantity of one sales order line to return.
+type LineInput struct {
+	SalesOrderLineID int64
+	Quantity         float64
+}
+
+// ReceiveInput books returned goods. Lines missing from Quantities are
+// received in full; a lower quantity records a partial receipt. Quantities
+// are ignored when retrying the restock of a return already received.
+type ReceiveInput struct {
+	ReturnID   int64
+	ReceivedBy int64
+	Quantities map[int64]float64
+}
+
+// ListFilter narrows List. Zero fields match everything; List is always
+// limited to the companies in the caller's scope.
+type ListFilter struct {
+	CompanyID    int64
+	SalesOrderID int64
+	Status       Status
+	Limit        int
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
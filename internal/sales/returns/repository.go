@@ -0,0 +1,327 @@
+package returns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// Repository persists sales returns.
+type Repository interface {
+	OrderInfo(ctx context.Context, salesOrderID int64) (OrderInfo, error)
+	DeliveryWarehouse(ctx context.Context, deliveryOrderID, salesOrderID int64) (int64, error)
+	Create(ctx context.Context, ret Return) (Return, error)
+	Get(ctx context.Context, id int64) (Return, error)
+	List(ctx context.Context, filter ListFilter) ([]Return, error)
+	OriginalUnitCost(ctx context.Context, salesOrderLineID, warehouseID, productID int64) (float64, error)
+	MarkReceived(ctx context.Context, id, receivedBy int64, lines []Line, at time.Time) error
+	MarkRestocked(ctx context.Context, id int64, at time.Time) error
+	Credit(ctx context.Context, id, creditedBy int64, at time.Time, raise func() (int64, error)) error
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository constructs the pgx-backed sales return repository.
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repository{pool: pool}
+}
+
+func (r *repository) OrderInfo(ctx context.Context, salesOrderID int64) (OrderInfo, error) {
+	var info OrderInfo
+	err := r.pool.QueryRow(ctx, `
+		SELECT so.id, so.doc_number, so.company_id, so.customer_id, so.currency,
+		       COALESCE((SELECT d.warehouse_id FROM delivery_orders d
+		                 WHERE d.sales_order_id = so.id AND d.status = 'DELIVERED'
+		                 ORDER BY d.delivered_at DESC NULLS LAST, d.id DESC LIMIT 1), 0)
+		FROM sales_orders so
+		WHERE so.id = $1
+	`, salesOrderID).Scan(&info.ID, &info.DocNumber, &info.CompanyID, &info.CustomerID, &info.Currency, &info.WarehouseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return OrderInfo{}, fmt.Errorf("sales order %d: %w", salesOrderID, ErrNotFound)
+	}
+	if err != nil {
+		return OrderInfo{}, err
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT l.id, l.product_id, COALESCE(p.name, ''), l.quantity_delivered::float8,
+		       COALESCE((SELECT SUM(rl.quantity) FROM sales_return_lines rl
+		                 JOIN sales_returns sr ON sr.id = rl.sales_return_id
+		                 WHERE rl.sales_order_line_id = l.id AND sr.status = 'REQUESTED'), 0)::float8,
+		       l.unit_price::float8,
+		       -- Effective percent, including the order's document discount.
+		       (CASE WHEN l.quantity * l.unit_price > 0
+		             THEN l.discount_amount / (l.quantity * l.unit_price) * 100
+		             ELSE l.discount_percent END)::float8,
+		       l.tax_percent::float8
+		FROM sales_order_lines l
+		LEFT JOIN products p ON p.id = l.product_id
+		WHERE l.sales_order_id = $1
+		ORDER BY l.line_order, l.id
+	`, salesOrderID)
+	if err != nil {
+		return OrderInfo{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l ReturnableLine
+		if err := rows.Scan(&l.SalesOrderLineID, &l.ProductID, &l.ProductName, &l.Delivered, &l.Pending,
+			&l.UnitPrice, &l.DiscountPercent, &l.TaxPercent); err != nil {
+			return OrderInfo{}, err
+		}
+		info.Lines = append(info.Lines, l)
+	}
+	return info, rows.Err()
+}
+
+func (r *repository) DeliveryWarehouse(ctx context.Context, deliveryOrderID, salesOrderID int64) (int64, error) {
+	var warehouseID int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT warehouse_id FROM delivery_orders WHERE id = $1 AND sales_order_id = $2
+	`, deliveryOrderID, salesOrderID).Scan(&warehouseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("delivery order %d: %w", deliveryOrderID, ErrNotFound)
+	}
+	return warehouseID, err
+}
+
+func (r *repository) Create(ctx context.Context, ret Return) (Return, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Return{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO sales_returns (number, company_id, customer_id, sales_order_id, delivery_order_id,
+		                           warehouse_id, currency, status, reason, requested_by)
+		VALUES (generate_sales_return_number(), $1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, number, requested_at
+	`, ret.CompanyID, ret.CustomerID, ret.SalesOrderID, ret.DeliveryOrderID, ret.WarehouseID,
+		ret.Currency, string(ret.Status), ret.Reason, ret.RequestedBy).Scan(&ret.ID, &ret.Number, &ret.RequestedAt)
+	if err != nil {
+		return Return{}, err
+	}
+	for i := range ret.Lines {
+		line := &ret.Lines[i]
+		line.ReturnID = ret.ID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO sales_return_lines (sales_return_id, sales_order_line_id, product_id, quantity,
+			                                unit_price, discount_percent, tax_percent)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`, ret.ID, line.SalesOrderLineID, line.ProductID, line.Quantity,
+			line.UnitPrice, line.DiscountPercent, line.TaxPercent).Scan(&line.ID)
+		if err != nil {
+			return Return{}, err
+		}
+	}
+	return ret, tx.Commit(ctx)
+}
+
+const selectReturn = `
+	SELECT r.id, r.number, r.company_id, r.customer_id, COALESCE(c.name, ''), r.sales_order_id,
+	       COALESCE(so.doc_number, ''), r.delivery_order_id, r.warehouse_id, r.currency, r.status, r.reason,
+	       r.requested_by, r.requested_at, r.received_by, r.received_at, r.restocked_at, r.credited_by, r.credited_at,
+	       r.ar_credit_note_id, COALESCE(cn.number, '')
+	FROM sales_returns r
+	LEFT JOIN customers c ON c.id = r.customer_id
+	LEFT JOIN sales_orders so ON so.id = r.sales_order_id
+	LEFT JOIN ar_credit_notes cn ON cn.id = r.ar_credit_note_id`
+
+func scanReturn(row pgx.Row) (Return, error) {
+	var ret Return
+	var status string
+	err := row.Scan(&ret.ID, &ret.Number, &ret.CompanyID, &ret.CustomerID, &ret.CustomerName, &ret.SalesOrderID,
+		&ret.SalesOrderNo, &ret.DeliveryOrderID, &ret.WarehouseID, &ret.Currency, &status, &ret.Reason,
+		&ret.RequestedBy, &ret.RequestedAt, &ret.ReceivedBy, &ret.ReceivedAt, &ret.RestockedAt, &ret.CreditedBy, &ret.CreditedAt,
+		&ret.CreditNoteID, &ret.CreditNoteNo)
+	ret.Status = Status(status)
+	return ret, err
+}
+
+func (r *repository) Get(ctx context.Context, id int64) (Return, error) {
+	ret, err := scanReturn(r.pool.QueryRow(ctx, selectReturn+` WHERE r.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Return{}, ErrNotFound
+	}
+	if err != nil {
+		return Return{}, err
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT l.id, l.sales_return_id, l.sales_order_line_id, l.product_id, COALESCE(p.name, ''),
+		       COALESCE(sol.uom, ''), l.quantity::float8, l.quantity_received::float8, l.unit_price::float8,
+		       l.discount_percent::float8, l.tax_percent::float8, l.unit_cost::float8
+		FROM sales_return_lines l
+		LEFT JOIN products p ON p.id = l.product_id
+		LEFT JOIN sales_order_lines sol ON sol.id = l.sales_order_line_id
+		WHERE l.sales_return_id = $1
+		ORDER BY l.id
+	`, id)
+	if err != nil {
+		return Return{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l Line
+		if err := rows.Scan(&l.ID, &l.ReturnID, &l.SalesOrderLineID, &l.ProductID, &l.ProductName,
+			&l.UOM, &l.Quantity, &l.QuantityReceived, &l.UnitPrice, &l.DiscountPercent, &l.TaxPercent, &l.UnitCost); err != nil {
+			return Return{}, err
+		}
+		ret.Lines = append(ret.Lines, l)
+	}
+	return ret, rows.Err()
+}
+
+func (r *repository) List(ctx context.Context, filter ListFilter) ([]Return, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if scope := shared.CompanyScopeFromContext(ctx); !scope.Unrestricted {
+		add("r.company_id = ANY($%d)", scope.CompanyIDs)
+	}
+	if filter.CompanyID != 0 {
+		add("r.company_id = $%d", filter.CompanyID)
+	}
+	if filter.SalesOrderID != 0 {
+		add("r.sales_order_id = $%d", filter.SalesOrderID)
+	}
+	if filter.Status != "" {
+		add("r.status = $%d", string(filter.Status))
+	}
+	query := selectReturn
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY r.requested_at DESC, r.id DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Return
+	for rows.Next() {
+		ret, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ret)
+	}
+	return out, rows.Err()
+}
+
+// OriginalUnitCost is the weighted cost at which the sales order line left
+// stock. Deliveries post their outbound movements as DO-<number>-L<line>.
+// Without such movements the warehouse's current average cost is used.
+func (r *repository) OriginalUnitCost(ctx context.Context, salesOrderLineID, warehouseID, productID int64) (float64, error) {
+	var cost float64
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(
+		         (SELECT SUM(-l.qty * COALESCE(l.unit_cost, 0)) / NULLIF(SUM(-l.qty), 0)
+		          FROM delivery_order_lines dol
+		          JOIN delivery_orders d ON d.id = dol.delivery_order_id
+		          JOIN inventory_tx t ON t.code = 'DO-' || d.doc_number || '-L' || dol.id
+		          JOIN inventory_tx_lines l ON l.tx_id = t.id AND l.product_id = dol.product_id
+		          WHERE dol.sales_order_line_id = $1),
+		         (SELECT avg_cost FROM inventory_balances WHERE warehouse_id = $2 AND product_id = $3),
+		         0)::float8
+	`, salesOrderLineID, warehouseID, productID).Scan(&cost)
+	return cost, err
+}
+
+func (r *repository) MarkReceived(ctx context.Context, id, receivedBy int64, lines []Line, at time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE sales_returns SET status = 'RECEIVED', received_by = $2, received_at = $3
+		WHERE id = $1 AND status = 'REQUESTED'
+	`, id, receivedBy, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvalidStatus
+	}
+	for _, line := range lines {
+		if _, err := tx.Exec(ctx, `
+			UPDATE sales_return_lines SET quantity_received = $2, unit_cost = $3 WHERE id = $1
+		`, line.ID, line.QuantityReceived, line.UnitCost); err != nil {
+			return err
+		}
+		if line.QuantityReceived <= 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE sales_order_lines
+			SET quantity_returned = quantity_returned + $2,
+			    quantity_delivered = GREATEST(quantity_delivered - $2, 0),
+			    updated_at = NOW()
+			WHERE id = $1
+		`, line.SalesOrderLineID, line.QuantityReceived); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *repository) MarkRestocked(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE sales_returns SET restocked_at = $2 WHERE id = $1 AND restocked_at IS NULL
+	`, id, at)
+	return err
+}
+
+// Credit locks the return while raise creates its credit note, so a return
+// is credited once. NO KEY UPDATE leaves the row open to the credit note's
+// foreign key check.
+func (r *repository) Credit(ctx context.Context, id, creditedBy int64, at time.Time, raise func() (int64, error)) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM sales_returns WHERE id = $1 FOR NO KEY UPDATE`, id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if Status(status) != StatusReceived {
+		return ErrInvalidStatus
+	}
+	creditNoteID, err := raise()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE sales_returns SET status = 'CREDITED', credited_by = $2, credited_at = $3, ar_credit_note_id = $4
+		WHERE id = $1
+	`, id, creditedBy, at, creditNoteID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,321 @@
+package returns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// InventoryPort restocks returned goods.
+type InventoryPort interface {
+	PostInbound(ctx context.Context, input inventory.InboundInput) (inventory.StockCardEntry, error)
+}
+
+// UnitConverter resolves how many base units one unit of a product holds.
+type UnitConverter interface {
+	BaseFactor(ctx context.Context, productID int64, uom string) (float64, error)
+}
+
+// CreditNotePort raises AR credit notes for received returns.
+type CreditNotePort interface {
+	CreateCreditNote(ctx context.Context, input ar.CreateARCreditNoteInput) (*ar.ARCreditNote, error)
+}
+
+// Service runs the return workflow: requested, received, credited.
+type Service struct {
+	repo      Repository
+	inventory InventoryPort
+	credits   CreditNotePort
+	units     UnitConverter
+	now       func() time.Time
+}
+
+// NewService constructs the sales return service.
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// SetInventory enables restocking when returns are received.
+func (s *Service) SetInventory(inv InventoryPort) {
+	s.inventory = inv
+}
+
+// SetUnitConverter restocks lines sold in an alternate unit in base units.
+func (s *Service) SetUnitConverter(units UnitConverter) {
+	s.units = units
+}
+
+// SetCreditNotes enables crediting received returns.
+func (s *Service) SetCreditNotes(credits CreditNotePort) {
+	s.credits = credits
+}
+
+// Get loads a return with its lines. Returns of companies outside the
+// caller's scope are not found.
+func (s *Service) Get(ctx context.Context, id int64) (Return, error) {
+	ret, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Return{}, err
+	}
+	if !coreshared.CompanyAllowed(ctx, ret.CompanyID) {
+		return Return{}, ErrNotFound
+	}
+	return ret, nil
+}
+
+// List returns the newest returns first.
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]Return, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// OrderInfo loads a sales order with the quantities still returnable.
+func (s *Service) OrderInfo(ctx context.Context, salesOrderID int64) (OrderInfo, error) {
+	order, err := s.repo.OrderInfo(ctx, salesOrderID)
+	if err != nil {
+		return OrderInfo{}, err
+	}
+	if !coreshared.CompanyAllowed(ctx, order.CompanyID) {
+		return OrderInfo{}, fmt.Errorf("sales order %d: %w", salesOrderID, ErrNotFound)
+	}
+	return order, nil
+}
+
+// Create requests a return of delivered sales order lines. A line can be
+// returned in several partial returns as long as the total stays within the
+// delivered quantity.
+func (s *Service) Create(ctx context.Context, input CreateInput) (Return, error) {
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Reason == "" {
+		return Return{}, fmt.Errorf("%w: reason required", coreshared.ErrValidation)
+	}
+	order, err := s.OrderInfo(ctx, input.SalesOrderID)
+	if err != nil {
+		return Return{}, err
+	}
+	warehouseID := input.WarehouseID
+	if input.DeliveryOrderID != 0 {
+		doWarehouse, err := s.repo.DeliveryWarehouse(ctx, input.DeliveryOrderID, order.ID)
+		if err != nil {
+			return Return{}, err
+		}
+		if warehouseID == 0 {
+			warehouseID = doWarehouse
+		}
+	}
+	if warehouseID == 0 {
+		warehouseID = order.WarehouseID
+	}
+	if warehouseID == 0 {
+		return Return{}, fmt.Errorf("%w: warehouse required", coreshared.ErrValidation)
+	}
+
+	lines, err := buildLines(order, input.Lines)
+	if err != nil {
+		return Return{}, err
+	}
+	ret := Return{
+		CompanyID:    order.CompanyID,
+		CustomerID:   order.CustomerID,
+		SalesOrderID: order.ID,
+		SalesOrderNo: order.DocNumber,
+		WarehouseID:  warehouseID,
+		Currency:     order.Currency,
+		Status:       StatusRequested,
+		Reason:       input.Reason,
+		RequestedBy:  input.RequestedBy,
+		Lines:        lines,
+	}
+	if input.DeliveryOrderID != 0 {
+		doID := input.DeliveryOrderID
+		ret.DeliveryOrderID = &doID
+	}
+	created, err := s.repo.Create(ctx, ret)
+	if err != nil {
+		return Return{}, fmt.Errorf("create sales return: %w", err)
+	}
+	return created, nil
+}
+
+// buildLines checks requested quantities against what each line can still
+// return and copies the line's pricing for the credit note.
+func buildLines(order OrderInfo, inputs []LineInput) ([]Line, error) {
+	byID := make(map[int64]ReturnableLine, len(order.Lines))
+	for _, line := range order.Lines {
+		byID[line.SalesOrderLineID] = line
+	}
+	requested := make(map[int64]float64, len(inputs))
+	var lines []Line
+	for _, input := range inputs {
+		if input.Quantity <= 0 {
+			continue
+		}
+		soLine, ok := byID[input.SalesOrderLineID]
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d is not on sales order %s", coreshared.ErrValidation, input.SalesOrderLineID, order.DocNumber)
+		}
+		requested[soLine.SalesOrderLineID] += input.Quantity
+		if requested[soLine.SalesOrderLineID] > soLine.Returnable()+0.0001 {
+			return nil, fmt.Errorf("%w: %s can return at most %.2f", ErrExceedsReturnable, soLine.ProductName, soLine.Returnable())
+		}
+		lines = append(lines, Line{
+			SalesOrderLineID: soLine.SalesOrderLineID,
+			ProductID:        soLine.ProductID,
+			ProductName:      soLine.ProductName,
+			Quantity:         input.Quantity,
+			UnitPrice:        soLine.UnitPrice,
+			DiscountPercent:  soLine.DiscountPercent,
+			TaxPercent:       soLine.TaxPercent,
+		})
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: return at least one line", coreshared.ErrValidation)
+	}
+	return lines, nil
+}
+
+// Receive books the returned goods back into the return's warehouse at the
+// cost they left stock, and lowers the sales order lines' delivered
+// quantities. Receiving less than requested closes the rest of the line.
+// The receipt is recorded before the goods are restocked; when restocking
+// fails, receiving the return again retries it with the recorded quantities.
+func (s *Service) Receive(ctx context.Context, input ReceiveInput) (Return, error) {
+	ret, err := s.Get(ctx, input.ReturnID)
+	if err != nil {
+		return Return{}, err
+	}
+	if ret.Status == StatusReceived && ret.RestockedAt == nil {
+		return s.restock(ctx, ret, input.ReceivedBy)
+	}
+	if ret.Status != StatusRequested {
+		return Return{}, fmt.Errorf("%w: only REQUESTED returns can be received", ErrInvalidStatus)
+	}
+	received := 0
+	for i := range ret.Lines {
+		line := &ret.Lines[i]
+		qty := line.Quantity
+		if q, ok := input.Quantities[line.ID]; ok {
+			qty = q
+		}
+		if qty < 0 || qty > line.Quantity+0.0001 {
+			return Return{}, fmt.Errorf("%w: %s received %.2f of %.2f requested", coreshared.ErrValidation, line.ProductName, qty, line.Quantity)
+		}
+		line.QuantityReceived = qty
+		if qty == 0 {
+			continue
+		}
+		received++
+		cost, err := s.repo.OriginalUnitCost(ctx, line.SalesOrderLineID, ret.WarehouseID, line.ProductID)
+		if err != nil {
+			return Return{}, fmt.Errorf("original cost of %s: %w", line.ProductName, err)
+		}
+		factor, err := s.baseFactor(ctx, *line)
+		if err != nil {
+			return Return{}, err
+		}
+		// Stock costs are per base unit; the line is in the unit it was sold in.
+		line.UnitCost = cost * factor
+	}
+	if received == 0 {
+		return Return{}, ErrNothingReceived
+	}
+
+	if err := s.repo.MarkReceived(ctx, ret.ID, input.ReceivedBy, ret.Lines, s.now().UTC()); err != nil {
+		return Return{}, fmt.Errorf("receive sales return: %w", err)
+	}
+	return s.restock(ctx, ret, input.ReceivedBy)
+}
+
+// restock posts the received lines into stock. Each line posts under its
+// own code, so lines restocked by an earlier attempt are skipped.
+func (s *Service) restock(ctx context.Context, ret Return, actorID int64) (Return, error) {
+	if s.inventory != nil {
+		for _, line := range ret.Lines {
+			if line.QuantityReceived == 0 {
+				continue
+			}
+			factor, err := s.baseFactor(ctx, line)
+			if err != nil {
+				return Return{}, err
+			}
+			_, err = s.inventory.PostInbound(ctx, inventory.InboundInput{
+				Code:        fmt.Sprintf("%s-L%d", ret.Number, line.ID),
+				WarehouseID: ret.WarehouseID,
+				ProductID:   line.ProductID,
+				Qty:         line.QuantityReceived * factor,
+				UnitCost:    line.UnitCost / factor,
+				Note:        fmt.Sprintf("Return %s from %s", ret.Number, ret.SalesOrderNo),
+				ActorID:     actorID,
+				RefModule:   "SALES_RETURN",
+			})
+			if err != nil && !errors.Is(err, coreshared.ErrIdempotencyConflict) {
+				return Return{}, fmt.Errorf("restock %s: %w", line.ProductName, err)
+			}
+		}
+	}
+	if err := s.repo.MarkRestocked(ctx, ret.ID, s.now().UTC()); err != nil {
+		return Return{}, fmt.Errorf("restock sales return: %w", err)
+	}
+	return s.repo.Get(ctx, ret.ID)
+}
+
+// Credit raises an AR credit note for the received goods. The credit reduces
+// the sales order's open invoice when it can absorb it and otherwise stays on
+// the customer's account. The return stays locked while the note is raised.
+func (s *Service) Credit(ctx context.Context, id, creditedBy int64) (Return, error) {
+	if s.credits == nil {
+		return Return{}, errors.New("sales return: credit notes not configured")
+	}
+	ret, err := s.Get(ctx, id)
+	if err != nil {
+		return Return{}, err
+	}
+	if ret.Status != StatusReceived {
+		return Return{}, fmt.Errorf("%w: only RECEIVED returns can be credited", ErrInvalidStatus)
+	}
+	subtotal, tax, total := ret.CreditAmounts()
+	if total <= 0 {
+		return Return{}, ErrNothingReceived
+	}
+	err = s.repo.Credit(ctx, ret.ID, creditedBy, s.now().UTC(), func() (int64, error) {
+		note, err := s.credits.CreateCreditNote(ctx, ar.CreateARCreditNoteInput{
+			CustomerID:    ret.CustomerID,
+			SOID:          ret.SalesOrderID,
+			SalesReturnID: ret.ID,
+			Currency:      ret.Currency,
+			Subtotal:      subtotal,
+			TaxAmount:     tax,
+			Total:         total,
+			Reason:        fmt.Sprintf("Return %s: %s", ret.Number, ret.Reason),
+			CreatedBy:     creditedBy,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("raise credit note: %w", err)
+		}
+		return note.ID, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			return Return{}, fmt.Errorf("%w: only RECEIVED returns can be credited", ErrInvalidStatus)
+		}
+		return Return{}, fmt.Errorf("credit sales return: %w", err)
+	}
+	return s.repo.Get(ctx, ret.ID)
+}
+
+// baseFactor returns the number of base units in one unit of the line.
+func (s *Service) baseFactor(ctx context.Context, line Line) (float64, error) {
+	if s.units == nil {
+		return 1, nil
+	}
+	factor, err := s.units.BaseFactor(ctx, line.ProductID, line.UOM)
+	if err != nil {
+		return 0, fmt.Errorf("unit of %s: %w", line.ProductName, err)
+	}
+	return factor, nil
+}
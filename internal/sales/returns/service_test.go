@@ -0,0 +1,298 @@
+package returns
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/ar"
+	"github.com/odyssey-erp/odyssey-erp/internal/inventory"
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type memoryRepo struct {
+	order   OrderInfo
+	returns map[int64]Return
+	costs   map[int64]float64
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{
+		order: OrderInfo{
+			ID: 10, DocNumber: "SO-1", CompanyID: 1, CustomerID: 5, Currency: "IDR", WarehouseID: 3,
+			Lines: []ReturnableLine{
+				{SalesOrderLineID: 101, ProductID: 7, ProductName: "Widget", Delivered: 10, UnitPrice: 100, DiscountPercent: 10, TaxPercent: 11},
+				{SalesOrderLineID: 102, ProductID: 8, ProductName: "Gadget", Delivered: 0, UnitPrice: 50},
+			},
+		},
+		returns: map[int64]Return{},
+		costs:   map[int64]float64{101: 62.5},
+	}
+}
+
+func (m *memoryRepo) OrderInfo(ctx context.Context, salesOrderID int64) (OrderInfo, error) {
+	if salesOrderID != m.order.ID {
+		return OrderInfo{}, ErrNotFound
+	}
+	info := m.order
+	info.Lines = append([]ReturnableLine(nil), m.order.Lines...)
+	for i := range info.Lines {
+		for _, ret := range m.returns {
+			if ret.Status != StatusRequested {
+				continue
+			}
+			for _, line := range ret.Lines {
+				if line.SalesOrderLineID == info.Lines[i].SalesOrderLineID {
+					info.Lines[i].Pending += line.Quantity
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+func (m *memoryRepo) DeliveryWarehouse(ctx context.Context, deliveryOrderID, salesOrderID int64) (int64, error) {
+	return 4, nil
+}
+
+func (m *memoryRepo) Create(ctx context.Context, ret Return) (Return, error) {
+	ret.ID = int64(len(m.returns) + 1)
+	ret.Number = "RMA-TEST"
+	for i := range ret.Lines {
+		ret.Lines[i].ID = ret.ID*100 + int64(i)
+		ret.Lines[i].ReturnID = ret.ID
+	}
+	m.returns[ret.ID] = ret
+	return ret, nil
+}
+
+func (m *memoryRepo) Get(ctx context.Context, id int64) (Return, error) {
+	ret, ok := m.returns[id]
+	if !ok {
+		return Return{}, ErrNotFound
+	}
+	ret.Lines = append([]Line(nil), ret.Lines...)
+	return ret, nil
+}
+
+func (m *memoryRepo) List(ctx context.Context, filter ListFilter) ([]Return, error) {
+	var out []Return
+	for _, ret := range m.returns {
+		out = append(out, ret)
+	}
+	return out, nil
+}
+
+func (m *memoryRepo) OriginalUnitCost(ctx context.Context, salesOrderLineID, warehouseID, productID int64) (float64, error) {
+	return m.costs[salesOrderLineID], nil
+}
+
+func (m *memoryRepo) MarkReceived(ctx context.Context, id, receivedBy int64, lines []Line, at time.Time) error {
+	ret := m.returns[id]
+	ret.Status = StatusReceived
+	ret.ReceivedBy, ret.ReceivedAt = &receivedBy, &at
+	ret.Lines = lines
+	for _, line := range lines {
+		for i := range m.order.Lines {
+			if m.order.Lines[i].SalesOrderLineID == line.SalesOrderLineID {
+				m.order.Lines[i].Delivered -= line.QuantityReceived
+			}
+		}
+	}
+	m.returns[id] = ret
+	return nil
+}
+
+func (m *memoryRepo) MarkRestocked(ctx context.Context, id int64, at time.Time) error {
+	ret := m.returns[id]
+	ret.RestockedAt = &at
+	m.returns[id] = ret
+	return nil
+}
+
+func (m *memoryRepo) Credit(ctx context.Context, id, creditedBy int64, at time.Time, raise func() (int64, error)) error {
+	ret := m.returns[id]
+	if ret.Status != StatusReceived {
+		return ErrInvalidStatus
+	}
+	creditNoteID, err := raise()
+	if err != nil {
+		return err
+	}
+	ret.Status = StatusCredited
+	ret.CreditedBy, ret.CreditedAt, ret.CreditNoteID = &creditedBy, &at, &creditNoteID
+	m.returns[id] = ret
+	return nil
+}
+
+type recordingInventory struct {
+	inbound []inventory.InboundInput
+	err     error
+}
+
+func (r *recordingInventory) PostInbound(ctx context.Context, input inventory.InboundInput) (inventory.StockCardEntry, error) {
+	if r.err != nil {
+		return inventory.StockCardEntry{}, r.err
+	}
+	for _, posted := range r.inbound {
+		if posted.Code == input.Code {
+			return inventory.StockCardEntry{}, coreshared.ErrIdempotencyConflict
+		}
+	}
+	r.inbound = append(r.inbound, input)
+	return inventory.StockCardEntry{}, nil
+}
+
+type recordingCredits struct {
+	notes []ar.CreateARCreditNoteInput
+}
+
+func (r *recordingCredits) CreateCreditNote(ctx context.Context, input ar.CreateARCreditNoteInput) (*ar.ARCreditNote, error) {
+	r.notes = append(r.notes, input)
+	return &ar.ARCreditNote{ID: 77, Total: input.Total}, nil
+}
+
+func TestReturnWorkflowPartialReceipt(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	inv := &recordingInventory{}
+	credits := &recordingCredits{}
+	svc := NewService(repo)
+	svc.SetInventory(inv)
+	svc.SetCreditNotes(credits)
+
+	ret, err := svc.Create(ctx, CreateInput{
+		SalesOrderID: 10,
+		Reason:       "Damaged in transit",
+		RequestedBy:  2,
+		Lines:        []LineInput{{SalesOrderLineID: 101, Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if ret.Status != StatusRequested || ret.WarehouseID != 3 {
+		t.Fatalf("unexpected return: %+v", ret)
+	}
+
+	// A second return may only take what is not already requested.
+	if _, err := svc.Create(ctx, CreateInput{SalesOrderID: 10, Reason: "More", Lines: []LineInput{{SalesOrderLineID: 101, Quantity: 7}}}); !errors.Is(err, ErrExceedsReturnable) {
+		t.Fatalf("expected ErrExceedsReturnable, got %v", err)
+	}
+
+	if _, err := svc.Credit(ctx, ret.ID, 3); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected credit before receipt to fail, got %v", err)
+	}
+
+	lineID := ret.Lines[0].ID
+	ret, err = svc.Receive(ctx, ReceiveInput{ReturnID: ret.ID, ReceivedBy: 3, Quantities: map[int64]float64{lineID: 3}})
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if ret.Status != StatusReceived {
+		t.Fatalf("expected RECEIVED, got %s", ret.Status)
+	}
+	if len(inv.inbound) != 1 || inv.inbound[0].Qty != 3 || inv.inbound[0].UnitCost != 62.5 || inv.inbound[0].WarehouseID != 3 {
+		t.Fatalf("unexpected restock: %+v", inv.inbound)
+	}
+	if repo.order.Lines[0].Delivered != 7 {
+		t.Fatalf("expected delivered reduced to 7, got %v", repo.order.Lines[0].Delivered)
+	}
+
+	ret, err = svc.Credit(ctx, ret.ID, 4)
+	if err != nil {
+		t.Fatalf("credit: %v", err)
+	}
+	if ret.Status != StatusCredited || ret.CreditNoteID == nil || *ret.CreditNoteID != 77 {
+		t.Fatalf("unexpected credited return: %+v", ret)
+	}
+	// 3 x 100 less 10% = 270, plus 11% tax = 29.70.
+	note := credits.notes[0]
+	if note.Subtotal != 270 || note.TaxAmount != 29.7 || note.Total != 299.7 || note.SOID != 10 || note.CustomerID != 5 {
+		t.Fatalf("unexpected credit note: %+v", note)
+	}
+}
+
+func TestCreateRejectsUndeliveredLines(t *testing.T) {
+	svc := NewService(newMemoryRepo())
+	_, err := svc.Create(context.Background(), CreateInput{
+		SalesOrderID: 10,
+		Reason:       "Wrong item",
+		Lines:        []LineInput{{SalesOrderLineID: 102, Quantity: 1}},
+	})
+	if !errors.Is(err, ErrExceedsReturnable) {
+		t.Fatalf("expected ErrExceedsReturnable, got %v", err)
+	}
+	_, err = svc.Create(context.Background(), CreateInput{SalesOrderID: 10, Lines: []LineInput{{SalesOrderLineID: 101, Quantity: 1}}})
+	if !errors.Is(err, coreshared.ErrValidation) {
+		t.Fatalf("expected reason validation error, got %v", err)
+	}
+}
+
+func TestReceiveRequiresSomeQuantity(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(newMemoryRepo())
+	ret, err := svc.Create(ctx, CreateInput{SalesOrderID: 10, Reason: "Wrong size", Lines: []LineInput{{SalesOrderLineID: 101, Quantity: 2}}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_, err = svc.Receive(ctx, ReceiveInput{ReturnID: ret.ID, Quantities: map[int64]float64{ret.Lines[0].ID: 0}})
+	if !errors.Is(err, ErrNothingReceived) {
+		t.Fatalf("expected ErrNothingReceived, got %v", err)
+	}
+}
+
+func TestReceiveRetriesFailedRestock(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	inv := &recordingInventory{err: errors.New("stock locked")}
+	svc := NewService(repo)
+	svc.SetInventory(inv)
+
+	ret, err := svc.Create(ctx, CreateInput{SalesOrderID: 10, Reason: "Wrong size", Lines: []LineInput{{SalesOrderLineID: 101, Quantity: 2}}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Receive(ctx, ReceiveInput{ReturnID: ret.ID, ReceivedBy: 3}); err == nil {
+		t.Fatalf("expected the restock failure")
+	}
+	ret, _ = repo.Get(ctx, ret.ID)
+	if ret.Status != StatusReceived || ret.RestockedAt != nil {
+		t.Fatalf("expected a received return awaiting restock, got %+v", ret)
+	}
+
+	inv.err = nil
+	ret, err = svc.Receive(ctx, ReceiveInput{ReturnID: ret.ID, ReceivedBy: 3, Quantities: map[int64]float64{ret.Lines[0].ID: 1}})
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if ret.RestockedAt == nil || len(inv.inbound) != 1 || inv.inbound[0].Qty != 2 {
+		t.Fatalf("expected the recorded 2 units restocked once, got %+v", inv.inbound)
+	}
+	if _, err := svc.Receive(ctx, ReceiveInput{ReturnID: ret.ID}); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected a restocked return to refuse another receipt, got %v", err)
+	}
+}
+
+func TestReturnsOutsideScopeAreNotFound(t *testing.T) {
+	repo := newMemoryRepo()
+	svc := NewService(repo)
+	ret, err := svc.Create(context.Background(), CreateInput{SalesOrderID: 10, Reason: "Wrong size", Lines: []LineInput{{SalesOrderLineID: 101, Quantity: 1}}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	ctx := coreshared.ContextWithCompanyScope(context.Background(), coreshared.CompanyScope{CompanyIDs: []int64{2}})
+	if _, err := svc.Get(ctx, ret.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from Get, got %v", err)
+	}
+	if _, err := svc.Receive(ctx, ReceiveInput{ReturnID: ret.ID}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from Receive, got %v", err)
+	}
+	if _, err := svc.OrderInfo(ctx, 10); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from OrderInfo, got %v", err)
+	}
+	if _, err := svc.Create(ctx, CreateInput{SalesOrderID: 10, Reason: "Again", Lines: []LineInput{{SalesOrderLineID: 101, Quantity: 1}}}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from Create, got %v", err)
+	}
+}
@@ -2,10 +2,16 @@ package sales
 
 import (
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/categories"
 	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/products"
+	"github.com/odyssey-erp/odyssey-erp/internal/masterdata/taxes"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/comments"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/customers"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/documents"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/orders"
 	"github.com/odyssey-erp/odyssey-erp/internal/sales/quotations"
+	"github.com/odyssey-erp/odyssey-erp/internal/sales/returns"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 )
 
 type Service struct {
@@ -13,6 +19,9 @@ type Service struct {
 	Quotations *quotations.Service
 	Orders     *orders.Service
 	Products   *products.Service
+	Comments   *comments.Service
+	Returns    *returns.Service
+	Documents  *documents.Service
 	pool       *pgxpool.Pool
 }
 
@@ -23,17 +32,36 @@ func NewService(pool *pgxpool.Pool) *Service {
 	orderRepo := orders.NewRepository(pool)
 	prodRepo := products.NewRepository(pool)
 
+	taxResolver := taxes.NewResolver(pool)
+
 	// Services
 	custSvc := customers.NewService(custRepo)
-	prodSvc := products.NewService(prodRepo)
+	prodSvc := products.NewService(prodRepo, categories.NewService(categories.NewRepository(pool)))
 	quoteSvc := quotations.NewService(quoteRepo, custRepo)
+	quoteSvc.SetProductPricer(prodSvc)
+	quoteSvc.SetUnitConverter(prodSvc)
+	quoteSvc.SetDefaultTaxResolver(taxResolver)
 	orderSvc := orders.NewService(orderRepo, custRepo, quoteRepo)
+	orderSvc.SetCreditChecker(custSvc)
+	orderSvc.SetUnitConverter(prodSvc)
+	orderSvc.SetDefaultTaxResolver(taxResolver)
+	commentSvc := comments.NewService(comments.NewRepository(pool))
+	returnSvc := returns.NewService(returns.NewRepository(pool))
+	returnSvc.SetUnitConverter(prodSvc)
+
+	auditLogger := shared.NewAuditLogger(pool)
+	custSvc.SetAuditor(auditLogger)
+	orderSvc.SetAuditor(auditLogger)
+	prodSvc.SetAuditor(auditLogger)
 
 	return &Service{
 		Customers:  custSvc,
 		Quotations: quoteSvc,
 		Orders:     orderSvc,
 		Products:   prodSvc,
+		Comments:   commentSvc,
+		Returns:    returnSvc,
+		Documents:  documents.NewService(quoteSvc, orderSvc, custSvc, prodSvc),
 		pool:       pool,
 	}
 }
@@ -1,5 +1,14 @@
 package shared
 
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
 func CalculateLineTotals(quantity, unitPrice, discountPercent, taxPercent float64) (discountAmount, taxAmount, lineTotal float64) {
 	grossAmount := quantity * unitPrice
 	discountAmount = grossAmount * (discountPercent / 100)
@@ -8,3 +17,141 @@ func CalculateLineTotals(quantity, unitPrice, discountPercent, taxPercent float6
 	lineTotal = netAmount + taxAmount
 	return
 }
+
+// DocumentDiscount is a discount on a whole quotation or order, applied after
+// line discounts. A non-zero Percent takes precedence over Amount.
+type DocumentDiscount struct {
+	Percent float64
+	Amount  float64
+}
+
+// LinePricing is the input of one document line.
+type LinePricing struct {
+	Quantity        float64
+	UnitPrice       float64
+	DiscountPercent float64
+	TaxPercent      float64
+}
+
+// LineAmounts are a line's amounts after line and document discounts.
+// DiscountAmount includes the line's share of the document discount, so
+// quantity * unit price - DiscountAmount is the taxable base.
+type LineAmounts struct {
+	DiscountAmount         float64
+	DocumentDiscountAmount float64
+	TaxAmount              float64
+	LineTotal              float64
+}
+
+// DocumentTotals are the reconciled totals of a document: the header amounts
+// equal the sum of the line amounts.
+type DocumentTotals struct {
+	Lines                  []LineAmounts
+	DocumentDiscountAmount float64
+	Subtotal               float64
+	TaxAmount              float64
+	TotalAmount            float64
+}
+
+// CalculateDocumentTotals prices every line, then spreads the document
+// discount over the lines in proportion to their net amounts, and taxes each
+// line on what is left. Amounts are rounded to cents and leftover cents go to
+// the lines with the largest remainders, so the line shares always add up to
+// the document discount.
+func CalculateDocumentTotals(lines []LinePricing, discount DocumentDiscount) (DocumentTotals, error) {
+	if discount.Percent < 0 || discount.Percent > 100 {
+		return DocumentTotals{}, fmt.Errorf("%w: document discount must be between 0 and 100 percent", coreshared.ErrValidation)
+	}
+	if discount.Amount < 0 {
+		return DocumentTotals{}, fmt.Errorf("%w: document discount cannot be negative", coreshared.ErrValidation)
+	}
+
+	lineDiscount := make([]int64, len(lines))
+	net := make([]int64, len(lines))
+	var netTotal int64
+	for i, line := range lines {
+		gross := toCents(line.Quantity * line.UnitPrice)
+		lineDiscount[i] = toCents(line.Quantity * line.UnitPrice * line.DiscountPercent / 100)
+		net[i] = gross - lineDiscount[i]
+		netTotal += net[i]
+	}
+
+	docDiscount := toCents(discount.Amount)
+	if discount.Percent > 0 {
+		docDiscount = toCents(float64(netTotal) * discount.Percent / 10000)
+	}
+	if docDiscount > netTotal {
+		return DocumentTotals{}, fmt.Errorf("%w: document discount %.2f exceeds the discounted subtotal %.2f",
+			coreshared.ErrValidation, fromCents(docDiscount), fromCents(netTotal))
+	}
+	shares := allocateCents(docDiscount, net)
+
+	totals := DocumentTotals{Lines: make([]LineAmounts, len(lines)), DocumentDiscountAmount: fromCents(docDiscount)}
+	var subtotal, tax int64
+	for i, line := range lines {
+		base := net[i] - shares[i]
+		lineTax := toCents(float64(base) * line.TaxPercent / 10000)
+		totals.Lines[i] = LineAmounts{
+			DiscountAmount:         fromCents(lineDiscount[i] + shares[i]),
+			DocumentDiscountAmount: fromCents(shares[i]),
+			TaxAmount:              fromCents(lineTax),
+			LineTotal:              fromCents(base + lineTax),
+		}
+		subtotal += base
+		tax += lineTax
+	}
+	totals.Subtotal = fromCents(subtotal)
+	totals.TaxAmount = fromCents(tax)
+	totals.TotalAmount = fromCents(subtotal + tax)
+	return totals, nil
+}
+
+// allocateCents splits total over weights proportionally with the largest
+// remainder method. total must not exceed the sum of the positive weights; the
+// shares then always add up to total.
+func allocateCents(total int64, weights []int64) []int64 {
+	shares := make([]int64, len(weights))
+	var sum int64
+	for _, w := range weights {
+		if w > 0 {
+			sum += w
+		}
+	}
+	if total == 0 || sum == 0 {
+		return shares
+	}
+	remainders := make([]int64, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		// 128-bit product so large currency amounts cannot overflow.
+		hi, lo := bits.Mul64(uint64(total), uint64(w))
+		q, r := bits.Div64(hi, lo, uint64(sum))
+		shares[i], remainders[i] = int64(q), int64(r)
+		allocated += shares[i]
+	}
+	order := make([]int, 0, len(weights))
+	for i, w := range weights {
+		if w > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for k := 0; allocated < total; k++ {
+		shares[order[k%len(order)]]++
+		allocated++
+	}
+	return shares
+}
+
+func toCents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
+
+func fromCents(c int64) float64 {
+	return float64(c) / 100
+}
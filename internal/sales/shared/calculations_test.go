@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"errors"
+	"testing"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+func TestCalculateDocumentTotalsSharesSumToDocumentDiscount(t *testing.T) {
+	// Three equal lines cannot split 100.00 evenly; the odd cent must land
+	// on exactly one line.
+	lines := []LinePricing{
+		{Quantity: 1, UnitPrice: 100, TaxPercent: 11},
+		{Quantity: 1, UnitPrice: 100, TaxPercent: 11},
+		{Quantity: 1, UnitPrice: 100, TaxPercent: 11},
+	}
+	totals, err := CalculateDocumentTotals(lines, DocumentDiscount{Amount: 100})
+	if err != nil {
+		t.Fatalf("CalculateDocumentTotals: %v", err)
+	}
+	var shares, subtotal, tax, total int64
+	for _, line := range totals.Lines {
+		shares += toCents(line.DocumentDiscountAmount)
+		subtotal += toCents(line.LineTotal - line.TaxAmount)
+		tax += toCents(line.TaxAmount)
+		total += toCents(line.LineTotal)
+	}
+	if shares != 10000 || totals.DocumentDiscountAmount != 100 {
+		t.Fatalf("line shares %d do not add up to the document discount: %+v", shares, totals)
+	}
+	if totals.Lines[0].DocumentDiscountAmount != 33.34 || totals.Lines[1].DocumentDiscountAmount != 33.33 {
+		t.Fatalf("unexpected split %+v", totals.Lines)
+	}
+	if subtotal != toCents(totals.Subtotal) || tax != toCents(totals.TaxAmount) || total != toCents(totals.TotalAmount) {
+		t.Fatalf("header totals do not reconcile with lines: %+v", totals)
+	}
+	if totals.Subtotal != 200 || totals.TotalAmount != totals.Subtotal+totals.TaxAmount {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+}
+
+func TestCalculateDocumentTotalsPercentAfterLineDiscounts(t *testing.T) {
+	lines := []LinePricing{
+		{Quantity: 2, UnitPrice: 50, DiscountPercent: 10, TaxPercent: 10},
+		{Quantity: 3, UnitPrice: 70, TaxPercent: 0},
+	}
+	totals, err := CalculateDocumentTotals(lines, DocumentDiscount{Percent: 10, Amount: 999})
+	if err != nil {
+		t.Fatalf("CalculateDocumentTotals: %v", err)
+	}
+	// Net after line discounts is 90 + 210 = 300, so 10% is 30 split 9 / 21.
+	if totals.DocumentDiscountAmount != 30 {
+		t.Fatalf("percent should win over amount, got %.2f", totals.DocumentDiscountAmount)
+	}
+	first := totals.Lines[0]
+	if first.DocumentDiscountAmount != 9 || first.DiscountAmount != 19 || first.TaxAmount != 8.1 || first.LineTotal != 89.1 {
+		t.Fatalf("unexpected first line %+v", first)
+	}
+	if totals.Lines[1].DocumentDiscountAmount != 21 || totals.Subtotal != 270 || totals.TotalAmount != 278.1 {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+}
+
+func TestCalculateDocumentTotalsRejectsInvalidDiscounts(t *testing.T) {
+	lines := []LinePricing{{Quantity: 1, UnitPrice: 100, DiscountPercent: 50}}
+	for name, discount := range map[string]DocumentDiscount{
+		"percent over 100":   {Percent: 120},
+		"negative amount":    {Amount: -1},
+		"exceeds net amount": {Amount: 50.01},
+	} {
+		if _, err := CalculateDocumentTotals(lines, discount); !errors.Is(err, coreshared.ErrValidation) {
+			t.Fatalf("%s: expected validation error, got %v", name, err)
+		}
+	}
+	totals, err := CalculateDocumentTotals(lines, DocumentDiscount{})
+	if err != nil || totals.Subtotal != 50 || totals.Lines[0].DiscountAmount != 50 {
+		t.Fatalf("zero discount should match line pricing, got %+v, %v", totals, err)
+	}
+}
@@ -0,0 +1,26 @@
+package shared
+
+import (
+	"fmt"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// CheckLineOrder verifies that lineIDs names each of the document's current
+// lines exactly once, so a reorder cannot drop, duplicate or add lines.
+func CheckLineOrder(current, lineIDs []int64) error {
+	if len(lineIDs) != len(current) {
+		return fmt.Errorf("%w: expected %d line IDs, got %d", coreshared.ErrValidation, len(current), len(lineIDs))
+	}
+	remaining := make(map[int64]bool, len(current))
+	for _, id := range current {
+		remaining[id] = true
+	}
+	for _, id := range lineIDs {
+		if !remaining[id] {
+			return fmt.Errorf("%w: line %d is not on the document or is listed twice", coreshared.ErrValidation, id)
+		}
+		delete(remaining, id)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coreshared "github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// DefaultTax resolves the customer's default tax at the rate effective on the
+// document date, for lines entered without a tax. It fails with
+// ErrNoDefaultTax when the customer has none, so a forgotten tax is never
+// silently 0%; tax-exempt customers default to NO-TAX instead.
+func DefaultTax(ctx context.Context, taxes coreshared.DefaultTaxResolver, customerID int64, on time.Time) (coreshared.TaxRate, error) {
+	if taxes == nil {
+		return coreshared.TaxRate{}, coreshared.ErrNoDefaultTax
+	}
+	tax, err := taxes.CustomerDefaultTax(ctx, customerID, on)
+	if err != nil {
+		return coreshared.TaxRate{}, err
+	}
+	if tax == nil {
+		return coreshared.TaxRate{}, coreshared.ErrNoDefaultTax
+	}
+	return *tax, nil
+}
+
+// DefaultTaxNotice tells the user which default tax was applied to how many
+// lines, for the flash message after saving a document; it is empty when no
+// line used the default.
+func DefaultTaxNotice(tax *coreshared.TaxRate, lines int) string {
+	if tax == nil || lines == 0 {
+		return ""
+	}
+	if tax.Exempt() {
+		return fmt.Sprintf("Customer is tax exempt (%s); %d line(s) carry no tax.", tax.Code, lines)
+	}
+	return fmt.Sprintf("Customer default tax %s applied to %d line(s).", tax.Notice(), lines)
+}
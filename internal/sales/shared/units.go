@@ -0,0 +1,28 @@
+package shared
+
+import "context"
+
+// UnitConverter resolves how many base units one unit of a product holds.
+type UnitConverter interface {
+	BaseFactor(ctx context.Context, productID int64, uom string) (float64, error)
+}
+
+// LineUnit is the product and unit of measure of one document line.
+type LineUnit struct {
+	ProductID int64
+	UOM       string
+}
+
+// CheckUnits verifies that every line is quoted in a unit its product can be
+// converted from. A nil converter accepts any unit.
+func CheckUnits(ctx context.Context, units UnitConverter, lines []LineUnit) error {
+	if units == nil {
+		return nil
+	}
+	for _, line := range lines {
+		if _, err := units.BaseFactor(ctx, line.ProductID, line.UOM); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package shared
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Activity event types shown on customer and supplier timelines.
+const (
+	ActivityQuotation     = "QUOTATION"
+	ActivitySalesOrder    = "SALES_ORDER"
+	ActivityDeliveryOrder = "DELIVERY_ORDER"
+	ActivityARInvoice     = "AR_INVOICE"
+	ActivityARPayment     = "AR_PAYMENT"
+	ActivityComment       = "COMMENT"
+	ActivityPurchaseOrder = "PURCHASE_ORDER"
+	ActivityGoodsReceipt  = "GOODS_RECEIPT"
+	ActivityAPInvoice     = "AP_INVOICE"
+	ActivityAPPayment     = "AP_PAYMENT"
+)
+
+// ActivityPerPage is the default timeline page size.
+const ActivityPerPage = 25
+
+// ActivityEvent is one document or comment on a customer or supplier timeline.
+// Amount is nil for events without a value, such as deliveries and comments.
+type ActivityEvent struct {
+	Type      string    `json:"type"`
+	ID        int64     `json:"id"`
+	Date      time.Time `json:"date"`
+	Reference string    `json:"reference"`
+	Status    string    `json:"status,omitempty"`
+	Amount    *float64  `json:"amount,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	URL       string    `json:"url,omitempty"`
+}
+
+// ActivityPage is one page of a timeline, newest first.
+type ActivityPage struct {
+	Events  []ActivityEvent `json:"events"`
+	Page    int             `json:"page"`
+	PerPage int             `json:"per_page"`
+	HasMore bool            `json:"has_more"`
+}
+
+// ActivityWindow normalises page and perPage and returns the LIMIT and OFFSET
+// to query. The limit asks for one extra row so HasMore can be set.
+func ActivityWindow(page, perPage int) (int, int, int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = ActivityPerPage
+	}
+	return page, perPage, perPage + 1, (page - 1) * perPage
+}
+
+// NewActivityPage trims the extra row fetched by ActivityWindow.
+func NewActivityPage(events []ActivityEvent, page, perPage int) ActivityPage {
+	out := ActivityPage{Events: events, Page: page, PerPage: perPage}
+	if len(events) > perPage {
+		out.Events = events[:perPage]
+		out.HasMore = true
+	}
+	if out.Events == nil {
+		out.Events = []ActivityEvent{}
+	}
+	return out
+}
+
+// ScanActivity reads timeline rows selected as (type, id, occurred_at,
+// reference, status, amount, note, link_type, link_id). The link columns name
+// the document the event opens; for comments that is the commented document.
+func ScanActivity(rows pgx.Rows) ([]ActivityEvent, error) {
+	defer rows.Close()
+	var events []ActivityEvent
+	for rows.Next() {
+		var (
+			ev       ActivityEvent
+			linkType string
+			linkID   int64
+		)
+		if err := rows.Scan(&ev.Type, &ev.ID, &ev.Date, &ev.Reference, &ev.Status, &ev.Amount, &ev.Note, &linkType, &linkID); err != nil {
+			return nil, err
+		}
+		ev.URL = ActivityURL(linkType, linkID)
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// ActivityURL returns the detail page of a timeline document, or "" when the
+// document type has no detail page.
+func ActivityURL(eventType string, id int64) string {
+	if id <= 0 {
+		return ""
+	}
+	ref := strconv.FormatInt(id, 10)
+	switch eventType {
+	case ActivityQuotation:
+		return "/sales/quotations/" + ref
+	case ActivitySalesOrder:
+		return "/sales/orders/" + ref
+	case ActivityDeliveryOrder:
+		return "/delivery/orders/" + ref
+	case ActivityARInvoice:
+		return "/finance/ar/invoices/" + ref
+	case ActivityAPInvoice:
+		return "/finance/ap/invoices/" + ref
+	case ActivityAPPayment:
+		return "/finance/ap/payments/" + ref
+	}
+	return ""
+}
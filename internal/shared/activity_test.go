@@ -0,0 +1,37 @@
+package shared
+
+import "testing"
+
+func TestActivityWindowAndPage(t *testing.T) {
+	page, perPage, limit, offset := ActivityWindow(3, 0)
+	if page != 3 || perPage != ActivityPerPage || limit != ActivityPerPage+1 || offset != 2*ActivityPerPage {
+		t.Fatalf("unexpected window page=%d perPage=%d limit=%d offset=%d", page, perPage, limit, offset)
+	}
+
+	events := make([]ActivityEvent, 3)
+	got := NewActivityPage(events, 1, 2)
+	if len(got.Events) != 2 || !got.HasMore {
+		t.Fatalf("expected 2 events with more, got %d more=%v", len(got.Events), got.HasMore)
+	}
+	last := NewActivityPage(nil, 2, 2)
+	if last.Events == nil || last.HasMore {
+		t.Fatalf("expected empty final page, got %+v", last)
+	}
+}
+
+func TestActivityURL(t *testing.T) {
+	cases := map[string]string{
+		ActivityQuotation:     "/sales/quotations/7",
+		ActivityDeliveryOrder: "/delivery/orders/7",
+		ActivityAPPayment:     "/finance/ap/payments/7",
+		ActivityGoodsReceipt:  "",
+	}
+	for typ, want := range cases {
+		if got := ActivityURL(typ, 7); got != want {
+			t.Errorf("ActivityURL(%s) = %q, want %q", typ, got, want)
+		}
+	}
+	if got := ActivityURL(ActivityQuotation, 0); got != "" {
+		t.Errorf("expected no URL without an id, got %q", got)
+	}
+}
@@ -21,6 +21,9 @@ const (
 	ApprovalApprove ApprovalAction = "APPROVE"
 	// ApprovalReject marks a reject action.
 	ApprovalReject ApprovalAction = "REJECT"
+	// ApprovalComment marks a comment left on a document under review. It is
+	// announced but never recorded as an approval step.
+	ApprovalComment ApprovalAction = "COMMENT"
 )
 
 // ApprovalLog represents a single approval record.
@@ -65,7 +68,7 @@ func (r *ApprovalRecorder) Record(ctx context.Context, log ApprovalLog) error {
 	_, err := r.pool.Exec(ctx, `INSERT INTO approvals (module, ref_id, actor_id, action, note, at)
 VALUES ($1, $2, $3, $4, $5, COALESCE($6, NOW()))`, log.Module, log.RefID, log.ActorID, string(log.Action), log.Note, log.At)
 	if err != nil {
-		r.logger.Error("record approval", slog.Any("error", err))
+		r.logger.ErrorContext(ctx, "record approval", slog.Any("error", err))
 		return err
 	}
 	return nil
@@ -113,3 +116,44 @@ func (r *ApprovalRecorder) EnsureSubmit(ctx context.Context, module string, ref
 	}
 	return nil
 }
+
+// ErrApproverRole is returned when the approver holds none of the roles the
+// approval matrix requires for the document.
+var ErrApproverRole = errors.New("approver role not allowed by the approval matrix")
+
+// ApprovalRouter applies the approval matrix to submissions. Roles are
+// snapshotted when a document is submitted so matrix changes only affect new
+// submissions.
+type ApprovalRouter interface {
+	// RequiredRoles returns the approver roles the matrix asks for at amount,
+	// none when no band covers it. An empty currency is the base currency.
+	RequiredRoles(ctx context.Context, module string, amount float64, currency string) ([]string, error)
+	// Route records the roles required for the submission and returns them.
+	Route(ctx context.Context, module string, ref uuid.UUID, amount float64, currency string) ([]string, error)
+	// Authorize returns ErrApproverRole when the actor holds none of
+	// the roles recorded for the submission.
+	Authorize(ctx context.Context, module string, ref uuid.UUID, actorID int64) error
+}
+
+// ApprovalNotice describes an approval transition that should be announced to
+// the approvers (on submit) or to the requester (on approve/reject/comment).
+type ApprovalNotice struct {
+	Action            ApprovalAction
+	Module            string
+	DocumentLabel     string
+	DocumentNumber    string
+	Path              string
+	Summary           string
+	ActorID           int64
+	RequesterID       int64
+	ApprovePermission string
+	Reason            string
+	Comment           string
+	CommentAuthor     string
+}
+
+// ApprovalNotifier delivers approval notices. Implementations should queue the
+// delivery so the approval workflow never waits on a mail server.
+type ApprovalNotifier interface {
+	NotifyApproval(ctx context.Context, notice ApprovalNotice) error
+}
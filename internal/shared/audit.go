@@ -4,11 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	// MaxAuditSnapshotBytes caps the encoded size of a before/after snapshot.
+	MaxAuditSnapshotBytes = 16 * 1024
+	// maxAuditFieldBytes caps a single snapshot value before it is elided.
+	maxAuditFieldBytes = 1024
+	// AuditRedacted replaces sensitive values in snapshots.
+	AuditRedacted = "[REDACTED]"
+)
+
+// sensitiveAuditKeys are matched against lowercased keys with separators removed.
+var sensitiveAuditKeys = []string{"password", "secret", "token", "apikey", "privatekey"}
+
 // AuditLog represents a record stored in audit_logs.
 type AuditLog struct {
 	ActorID  int64
@@ -17,6 +32,23 @@ type AuditLog struct {
 	EntityID string
 	Meta     map[string]any
 	At       time.Time
+	// Before and After are optional snapshots of the entity around an update.
+	// They are encoded with AuditSnapshot before being stored.
+	Before any
+	After  any
+}
+
+// Audit entities written for access-control changes. The audit viewer's
+// security filter matches exactly these.
+const (
+	AuditEntityRole        = "roles"
+	AuditEntityUserRole    = "user_roles"
+	AuditEntityUserCompany = "user_companies"
+)
+
+// SecurityAuditEntities lists the entities shown by the security changes filter.
+func SecurityAuditEntities() []string {
+	return []string{AuditEntityRole, AuditEntityUserRole, AuditEntityUserCompany}
 }
 
 // AuditLogger writes records into audit_logs.
@@ -41,6 +73,90 @@ func (l *AuditLogger) Record(ctx context.Context, log AuditLog) error {
 	if err != nil {
 		return err
 	}
-	_, err = l.pool.Exec(ctx, `INSERT INTO audit_logs (actor_id, action, entity, entity_id, meta, occurred_at) VALUES ($1, $2, $3, $4, $5, COALESCE($6, NOW()))`, log.ActorID, log.Action, log.Entity, log.EntityID, metaJSON, log.At)
+	before, err := AuditSnapshot(log.Before)
+	if err != nil {
+		return fmt.Errorf("audit before snapshot: %w", err)
+	}
+	after, err := AuditSnapshot(log.After)
+	if err != nil {
+		return fmt.Errorf("audit after snapshot: %w", err)
+	}
+	_, err = l.pool.Exec(ctx, `INSERT INTO audit_logs (actor_id, action, entity, entity_id, meta, occurred_at, before, after) VALUES ($1, $2, $3, $4, $5, COALESCE($6, NOW()), $7, $8)`, log.ActorID, log.Action, log.Entity, log.EntityID, metaJSON, log.At, before, after)
 	return err
 }
+
+// AuditActorFromContext returns the signed-in user ID, or 0 when unknown.
+func AuditActorFromContext(ctx context.Context) int64 {
+	sess := SessionFromContext(ctx)
+	if sess == nil {
+		return 0
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(sess.User()), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// AuditSnapshot encodes v as a JSON object suitable for audit_logs.before/after.
+// Sensitive fields are redacted, oversized values are elided and a snapshot
+// still above MaxAuditSnapshotBytes is replaced by a truncation marker. A nil
+// value yields nil so the column stays NULL.
+func AuditSnapshot(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	fields, ok := decoded.(map[string]any)
+	if !ok {
+		fields = map[string]any{"value": decoded}
+	}
+	sanitizeAuditFields(fields)
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > MaxAuditSnapshotBytes {
+		return json.Marshal(map[string]any{"_truncated": true, "_bytes": len(out)})
+	}
+	return out, nil
+}
+
+func sanitizeAuditFields(fields map[string]any) {
+	for key, value := range fields {
+		if sensitiveAuditKey(key) {
+			fields[key] = AuditRedacted
+			continue
+		}
+		switch typed := value.(type) {
+		case map[string]any:
+			sanitizeAuditFields(typed)
+		case []any:
+			for _, item := range typed {
+				if nested, ok := item.(map[string]any); ok {
+					sanitizeAuditFields(nested)
+				}
+			}
+		}
+		if encoded, err := json.Marshal(fields[key]); err == nil && len(encoded) > maxAuditFieldBytes {
+			fields[key] = fmt.Sprintf("[elided %d bytes]", len(encoded))
+		}
+	}
+}
+
+func sensitiveAuditKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(key))
+	for _, marker := range sensitiveAuditKeys {
+		if strings.Contains(normalized, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAuditSnapshotRedactsSensitiveFields(t *testing.T) {
+	type user struct {
+		Email        string            `json:"email"`
+		PasswordHash string            `json:"password_hash"`
+		Settings     map[string]string `json:"settings"`
+	}
+	raw, err := AuditSnapshot(user{
+		Email:        "a@example.com",
+		PasswordHash: "$2a$10$hash",
+		Settings:     map[string]string{"theme": "dark", "ApiKey": "k-123"},
+	})
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got["email"] != "a@example.com" || got["password_hash"] != AuditRedacted {
+		t.Fatalf("unexpected snapshot %v", got)
+	}
+	settings := got["settings"].(map[string]any)
+	if settings["ApiKey"] != AuditRedacted || settings["theme"] != "dark" {
+		t.Fatalf("nested fields not sanitized: %v", settings)
+	}
+}
+
+func TestAuditSnapshotLimitsSize(t *testing.T) {
+	if raw, err := AuditSnapshot(nil); err != nil || raw != nil {
+		t.Fatalf("nil snapshot = %q, %v", raw, err)
+	}
+
+	raw, err := AuditSnapshot(map[string]string{"notes": strings.Repeat("x", 5000), "name": "Widget"})
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	var got map[string]any
+	_ = json.Unmarshal(raw, &got)
+	if got["name"] != "Widget" || !strings.HasPrefix(got["notes"].(string), "[elided") {
+		t.Fatalf("expected long field elided, got %v", got)
+	}
+
+	wide := make(map[string]string, 40)
+	for i := 0; i < 40; i++ {
+		wide[strings.Repeat("k", i+1)] = strings.Repeat("v", 900)
+	}
+	raw, err = AuditSnapshot(wide)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(raw) > MaxAuditSnapshotBytes || !strings.Contains(string(raw), `"_truncated":true`) {
+		t.Fatalf("expected truncation marker, got %d bytes", len(raw))
+	}
+}
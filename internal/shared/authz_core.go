@@ -9,6 +9,15 @@ const (
 	PermRolesEdit = "roles.edit"
 
 	PermPermissionsView = "permissions.view"
+
+	// PermReportView grants access to cross-module operational reports.
+	PermReportView = "report.view"
+
+	// PermArchiveManage grants the archived document list and restores.
+	PermArchiveManage = "system.archive"
+
+	// PermSuperUser bypasses company scoping.
+	PermSuperUser = "system.superuser"
 )
 
 // CoreScopes lists all permissions related to the core platform.
@@ -19,5 +28,8 @@ func CoreScopes() []string {
 		PermRolesView,
 		PermRolesEdit,
 		PermPermissionsView,
+		PermReportView,
+		PermArchiveManage,
+		PermSuperUser,
 	}
 }
@@ -7,6 +7,7 @@ const (
 	PermFinancePeriodClose = "finance.period.close"
 	PermFinanceOverride    = "finance.override.lock"
 	PermFinanceBoardPack   = "finance.boardpack"
+	PermFinanceFXManage    = "finance.fx.manage"
 
 	// AR permissions
 	PermFinanceARView = "finance.ar.view"
@@ -21,6 +22,7 @@ func FinanceScopes() []string {
 		PermFinancePeriodClose,
 		PermFinanceOverride,
 		PermFinanceBoardPack,
+		PermFinanceFXManage,
 		PermFinanceARView,
 		PermFinanceAREdit,
 	}
@@ -7,6 +7,8 @@ const (
 	PermCustomerCreate = "sales.customer.create"
 	PermCustomerEdit   = "sales.customer.edit"
 	PermCustomerDelete = "sales.customer.delete"
+	// PermCustomerViewSensitive unmasks credit limit, tax ID and notes.
+	PermCustomerViewSensitive = "sales.customer.view_sensitive"
 
 	// Quotation permissions
 	PermQuotationView    = "sales.quotation.view"
@@ -22,6 +24,14 @@ const (
 	PermSalesOrderEdit    = "sales.order.edit"
 	PermSalesOrderConfirm = "sales.order.confirm"
 	PermSalesOrderCancel  = "sales.order.cancel"
+	// PermSalesOrderMinValueOverride confirms orders below the minimum order
+	// value.
+	PermSalesOrderMinValueOverride = "sales.order.min_value_override"
+
+	// Sales return permissions. Crediting a return needs PermFinanceAREdit.
+	PermSalesReturnView    = "sales.return.view"
+	PermSalesReturnCreate  = "sales.return.create"
+	PermSalesReturnReceive = "sales.return.receive"
 
 	// Delivery Order permissions
 	PermDeliveryOrderView     = "delivery.order.view"
@@ -41,6 +51,7 @@ func SalesScopes() []string {
 		PermCustomerCreate,
 		PermCustomerEdit,
 		PermCustomerDelete,
+		PermCustomerViewSensitive,
 		PermQuotationView,
 		PermQuotationCreate,
 		PermQuotationEdit,
@@ -52,6 +63,10 @@ func SalesScopes() []string {
 		PermSalesOrderEdit,
 		PermSalesOrderConfirm,
 		PermSalesOrderCancel,
+		PermSalesOrderMinValueOverride,
+		PermSalesReturnView,
+		PermSalesReturnCreate,
+		PermSalesReturnReceive,
 	}
 }
 
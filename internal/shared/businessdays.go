@@ -0,0 +1,138 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultHolidayCalendar is the calendar used when a company has none set.
+const DefaultHolidayCalendar = "ID"
+
+// Holiday is a non-working day in a holiday calendar.
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// BusinessCalendar treats Saturdays, Sundays and the listed holidays as
+// non-working days. Dates are compared by calendar day in their own location.
+type BusinessCalendar struct {
+	holidays map[string]string
+}
+
+// NewBusinessCalendar builds a calendar from a holiday list.
+func NewBusinessCalendar(holidays []Holiday) BusinessCalendar {
+	cal := BusinessCalendar{holidays: make(map[string]string, len(holidays))}
+	for _, h := range holidays {
+		cal.holidays[h.Date.Format("2006-01-02")] = h.Name
+	}
+	return cal
+}
+
+// Holiday returns the holiday name when t falls on one.
+func (c BusinessCalendar) Holiday(t time.Time) (string, bool) {
+	name, ok := c.holidays[t.Format("2006-01-02")]
+	return name, ok
+}
+
+// IsBusinessDay reports whether t is a weekday that is not a holiday.
+func (c BusinessCalendar) IsBusinessDay(t time.Time) bool {
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// NextBusinessDay returns t when it is a business day, otherwise the first
+// business day after it. The time of day is kept.
+func (c BusinessCalendar) NextBusinessDay(t time.Time) time.Time {
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// AddBusinessDays moves n business days forward from t; n <= 0 returns
+// NextBusinessDay(t).
+func (c BusinessCalendar) AddBusinessDays(t time.Time, n int) time.Time {
+	t = c.NextBusinessDay(t)
+	for ; n > 0; n-- {
+		t = c.NextBusinessDay(t.AddDate(0, 0, 1))
+	}
+	return t
+}
+
+// DueDatePolicy adjusts a computed invoice due date to the company's
+// business-day setting.
+type DueDatePolicy interface {
+	AdjustDueDate(ctx context.Context, companyID int64, due time.Time) (time.Time, error)
+}
+
+// dueDateLookahead bounds how far past the due date holidays are loaded; no
+// calendar has a longer run of non-working days.
+const dueDateLookahead = 31
+
+// BusinessDayPolicy rolls due dates forward to the next business day for
+// companies that opted in, using their holiday calendar.
+type BusinessDayPolicy struct {
+	pool *pgxpool.Pool
+}
+
+// NewBusinessDayPolicy constructs BusinessDayPolicy.
+func NewBusinessDayPolicy(pool *pgxpool.Pool) *BusinessDayPolicy {
+	return &BusinessDayPolicy{pool: pool}
+}
+
+// AdjustDueDate implements DueDatePolicy. Companies without a policy row keep
+// calendar-day due dates.
+func (p *BusinessDayPolicy) AdjustDueDate(ctx context.Context, companyID int64, due time.Time) (time.Time, error) {
+	if p == nil || p.pool == nil || companyID == 0 || due.IsZero() {
+		return due, nil
+	}
+	var (
+		enabled  bool
+		calendar string
+	)
+	err := p.pool.QueryRow(ctx, `SELECT business_days, holiday_calendar FROM company_due_date_policies WHERE company_id = $1`, companyID).Scan(&enabled, &calendar)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return due, nil
+	}
+	if err != nil {
+		return due, err
+	}
+	if !enabled {
+		return due, nil
+	}
+	holidays, err := p.Holidays(ctx, calendar, due, due.AddDate(0, 0, dueDateLookahead))
+	if err != nil {
+		return due, err
+	}
+	return NewBusinessCalendar(holidays).NextBusinessDay(due), nil
+}
+
+// Holidays lists the holidays of a calendar between from and to inclusive.
+func (p *BusinessDayPolicy) Holidays(ctx context.Context, calendar string, from, to time.Time) ([]Holiday, error) {
+	rows, err := p.pool.Query(ctx, `SELECT holiday_date, name FROM holiday_calendar_days
+WHERE calendar_code = $1 AND holiday_date BETWEEN $2::DATE AND $3::DATE
+ORDER BY holiday_date`, calendar, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var holidays []Holiday
+	for rows.Next() {
+		var h Holiday
+		if err := rows.Scan(&h.Date, &h.Name); err != nil {
+			return nil, err
+		}
+		// Re-anchor in the due date's location so day keys match.
+		h.Date = time.Date(h.Date.Year(), h.Date.Month(), h.Date.Day(), 0, 0, 0, 0, from.Location())
+		holidays = append(holidays, h)
+	}
+	return holidays, rows.Err()
+}
@@ -0,0 +1,40 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBusinessCalendarRollsPastWeekendsAndHolidays(t *testing.T) {
+	cal := NewBusinessCalendar([]Holiday{
+		{Date: date(2026, 8, 17), Name: "Hari Kemerdekaan"},
+		{Date: date(2026, 12, 25), Name: "Hari Raya Natal"},
+	})
+
+	cases := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{"weekday unchanged", date(2026, 10, 16), date(2026, 10, 16)},
+		{"saturday to monday", date(2026, 10, 17), date(2026, 10, 19)},
+		{"sunday before holiday monday", date(2026, 8, 16), date(2026, 8, 18)},
+		{"holiday friday to monday", date(2026, 12, 25), date(2026, 12, 28)},
+	}
+	for _, tc := range cases {
+		if got := cal.NextBusinessDay(tc.in); !got.Equal(tc.want) {
+			t.Errorf("%s: NextBusinessDay(%s) = %s, want %s", tc.name, tc.in.Format("2006-01-02"), got.Format("2006-01-02"), tc.want.Format("2006-01-02"))
+		}
+	}
+
+	if name, ok := cal.Holiday(date(2026, 8, 17)); !ok || name != "Hari Kemerdekaan" {
+		t.Fatalf("expected independence day holiday, got %q %v", name, ok)
+	}
+	if got := cal.AddBusinessDays(date(2026, 8, 14), 1); !got.Equal(date(2026, 8, 18)) {
+		t.Fatalf("AddBusinessDays across weekend and holiday = %s", got.Format("2006-01-02"))
+	}
+}
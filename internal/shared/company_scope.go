@@ -0,0 +1,100 @@
+package shared
+
+import "context"
+
+// CompanyScope lists the companies the current user may access. Super-users
+// carry an unrestricted scope.
+type CompanyScope struct {
+	Unrestricted bool
+	CompanyIDs   []int64
+}
+
+// Allows reports whether the scope covers the company.
+func (s CompanyScope) Allows(companyID int64) bool {
+	if s.Unrestricted {
+		return true
+	}
+	for _, id := range s.CompanyIDs {
+		if id == companyID {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCompanyID returns the first assigned company, or 0 when none is
+// assigned or the scope is unrestricted.
+func (s CompanyScope) DefaultCompanyID() int64 {
+	if s.Unrestricted || len(s.CompanyIDs) == 0 {
+		return 0
+	}
+	return s.CompanyIDs[0]
+}
+
+// FilterIDs keeps only the companies covered by the scope. An empty input on a
+// restricted scope expands to every assigned company so "all companies" list
+// filters never leak other companies' rows.
+func (s CompanyScope) FilterIDs(ids []int64) []int64 {
+	if s.Unrestricted {
+		return ids
+	}
+	if len(ids) == 0 {
+		return append([]int64(nil), s.CompanyIDs...)
+	}
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if s.Allows(id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+type companyScopeContextKey struct{}
+
+// ContextWithCompanyScope stores the company scope in context.
+func ContextWithCompanyScope(ctx context.Context, scope CompanyScope) context.Context {
+	return context.WithValue(ctx, companyScopeContextKey{}, scope)
+}
+
+// CompanyScopeFromContext extracts the company scope. Contexts without a scope
+// (background jobs, tests) are treated as unrestricted.
+func CompanyScopeFromContext(ctx context.Context) CompanyScope {
+	scope, ok := ctx.Value(companyScopeContextKey{}).(CompanyScope)
+	if !ok {
+		return CompanyScope{Unrestricted: true}
+	}
+	return scope
+}
+
+// CompanyAllowed reports whether the request context may access the company.
+func CompanyAllowed(ctx context.Context, companyID int64) bool {
+	return CompanyScopeFromContext(ctx).Allows(companyID)
+}
+
+// ScopedCompanyID resolves the company a handler should operate on: the
+// requested company when the scope covers it, otherwise the user's default
+// assigned company. Only unrestricted scopes use fallback; a restricted scope
+// without assigned companies resolves to 0 rather than borrowing another
+// company.
+func ScopedCompanyID(ctx context.Context, requested, fallback int64) int64 {
+	scope := CompanyScopeFromContext(ctx)
+	if requested > 0 && scope.Allows(requested) {
+		return requested
+	}
+	if scope.Unrestricted {
+		return fallback
+	}
+	return scope.DefaultCompanyID()
+}
+
+// RequireCompanyID is ScopedCompanyID for handlers that cannot proceed
+// without a company. It returns ErrForbidden when a restricted scope has no
+// company to offer.
+func RequireCompanyID(ctx context.Context, requested, fallback int64) (int64, error) {
+	id := ScopedCompanyID(ctx, requested, fallback)
+	if id == 0 && !CompanyScopeFromContext(ctx).Unrestricted {
+		return 0, ErrForbidden
+	}
+	return id, nil
+}
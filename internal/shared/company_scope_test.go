@@ -0,0 +1,68 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompanyScopeRestricted(t *testing.T) {
+	scope := CompanyScope{CompanyIDs: []int64{2, 5}}
+	if !scope.Allows(5) || scope.Allows(1) {
+		t.Fatalf("unexpected Allows results for %+v", scope)
+	}
+	if got := scope.FilterIDs(nil); !reflect.DeepEqual(got, []int64{2, 5}) {
+		t.Fatalf("FilterIDs(nil) = %v, want assigned companies", got)
+	}
+	if got := scope.FilterIDs([]int64{1, 2, 3}); !reflect.DeepEqual(got, []int64{2}) {
+		t.Fatalf("FilterIDs = %v, want [2]", got)
+	}
+
+	ctx := ContextWithCompanyScope(context.Background(), scope)
+	if CompanyAllowed(ctx, 1) {
+		t.Fatal("company 1 should be blocked")
+	}
+	if got := ScopedCompanyID(ctx, 0, 1); got != 2 {
+		t.Fatalf("ScopedCompanyID default = %d, want 2", got)
+	}
+	if got := ScopedCompanyID(ctx, 5, 1); got != 5 {
+		t.Fatalf("ScopedCompanyID requested = %d, want 5", got)
+	}
+	if got := ScopedCompanyID(ctx, 3, 1); got != 2 {
+		t.Fatalf("ScopedCompanyID outside scope = %d, want default 2", got)
+	}
+}
+
+func TestCompanyScopeWithoutCompanies(t *testing.T) {
+	ctx := ContextWithCompanyScope(context.Background(), CompanyScope{})
+	if CompanyAllowed(ctx, 1) {
+		t.Fatal("an empty scope should block every company")
+	}
+	if got := ScopedCompanyID(ctx, 0, 1); got != 0 {
+		t.Fatalf("ScopedCompanyID = %d, want 0 instead of the fallback", got)
+	}
+	if got := ScopedCompanyID(ctx, 1, 1); got != 0 {
+		t.Fatalf("ScopedCompanyID requested = %d, want 0", got)
+	}
+	if _, err := RequireCompanyID(ctx, 0, 1); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("RequireCompanyID err = %v, want ErrForbidden", err)
+	}
+}
+
+func TestCompanyScopeUnrestricted(t *testing.T) {
+	ctx := context.Background()
+	if !CompanyAllowed(ctx, 42) {
+		t.Fatal("context without scope should be unrestricted")
+	}
+	if got := ScopedCompanyID(ctx, 0, 1); got != 1 {
+		t.Fatalf("ScopedCompanyID fallback = %d, want 1", got)
+	}
+	if id, err := RequireCompanyID(ctx, 0, 0); err != nil || id != 0 {
+		t.Fatalf("RequireCompanyID = %d, %v, want 0 without error", id, err)
+	}
+	super := CompanyScope{Unrestricted: true}
+	if got := super.FilterIDs(nil); got != nil {
+		t.Fatalf("super-user FilterIDs(nil) = %v, want nil (all companies)", got)
+	}
+}
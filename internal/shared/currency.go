@@ -0,0 +1,23 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInactiveCurrency indicates a document uses a currency that is unknown or
+// switched off in the currency list.
+var ErrInactiveCurrency = errors.New("currency is not active")
+
+// CurrencyValidator checks document currencies against the currency list.
+type CurrencyValidator interface {
+	ValidateCurrency(ctx context.Context, code string) error
+}
+
+// RateResolver converts amounts between currencies using the exchange rate
+// effective on a date.
+type RateResolver interface {
+	Convert(ctx context.Context, amount float64, from, to string, on time.Time) (float64, error)
+	BaseCurrency() string
+}
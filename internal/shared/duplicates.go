@@ -0,0 +1,103 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DuplicateNameSimilarity is the minimum pg_trgm similarity between two names
+// for an existing record to be reported as a likely duplicate.
+const DuplicateNameSimilarity = 0.4
+
+// DuplicateLimit caps how many candidates a similarity check returns.
+const DuplicateLimit = 5
+
+// ErrPossibleDuplicate indicates similar records exist and the user has not
+// confirmed the create.
+var ErrPossibleDuplicate = errors.New("possible duplicate")
+
+// DuplicateCandidate is an existing record resembling one being created.
+// MatchedOn lists why it was flagged: "name", "tax_id" and/or "phone".
+type DuplicateCandidate struct {
+	ID         int64    `json:"id"`
+	Code       string   `json:"code"`
+	Name       string   `json:"name"`
+	TaxID      string   `json:"tax_id,omitempty"`
+	Phone      string   `json:"phone,omitempty"`
+	Similarity float64  `json:"similarity"`
+	MatchedOn  []string `json:"matched_on"`
+}
+
+// Explain fills MatchedOn by comparing the candidate with the submitted tax ID
+// and phone.
+func (c *DuplicateCandidate) Explain(taxID, phone string) {
+	c.MatchedOn = c.MatchedOn[:0]
+	if c.Similarity >= DuplicateNameSimilarity {
+		c.MatchedOn = append(c.MatchedOn, "name")
+	}
+	if id := NormalizeIdentifier(taxID); id != "" && id == NormalizeIdentifier(c.TaxID) {
+		c.MatchedOn = append(c.MatchedOn, "tax_id")
+	}
+	if p := NormalizeIdentifier(phone); p != "" && p == NormalizeIdentifier(c.Phone) {
+		c.MatchedOn = append(c.MatchedOn, "phone")
+	}
+}
+
+// DuplicateError carries the candidates found by a pre-save similarity check.
+type DuplicateError struct {
+	Candidates []DuplicateCandidate
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%d similar record(s) already exist", len(e.Candidates))
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrPossibleDuplicate).
+func (e *DuplicateError) Unwrap() error {
+	return ErrPossibleDuplicate
+}
+
+// NormalizeIdentifier lowercases v and drops everything but letters and
+// digits, so tax IDs and phone numbers compare regardless of punctuation. The
+// repositories apply the same rule in SQL with regexp_replace.
+func NormalizeIdentifier(v string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(v) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// FormField is a submitted form value carried through a confirmation step.
+type FormField struct {
+	Name  string
+	Value string
+}
+
+// ResubmitFields flattens a posted form into hidden fields, dropping the CSRF
+// token and any names in skip, so a confirmation page can post it again.
+func ResubmitFields(form url.Values, skip ...string) []FormField {
+	omit := map[string]bool{CSRFFormField: true}
+	for _, name := range skip {
+		omit[name] = true
+	}
+	names := make([]string, 0, len(form))
+	for name := range form {
+		if !omit[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var fields []FormField
+	for _, name := range names {
+		for _, value := range form[name] {
+			fields = append(fields, FormField{Name: name, Value: value})
+		}
+	}
+	return fields
+}
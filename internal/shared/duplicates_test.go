@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestDuplicateCandidateExplain(t *testing.T) {
+	c := DuplicateCandidate{Name: "PT Maju Jaya", TaxID: "12.345.678.9-012.345", Phone: "+62 21 555-0101", Similarity: 0.2}
+	c.Explain("123456789012345", "62215550101")
+	if len(c.MatchedOn) != 2 || c.MatchedOn[0] != "tax_id" || c.MatchedOn[1] != "phone" {
+		t.Fatalf("expected tax_id and phone matches, got %v", c.MatchedOn)
+	}
+	c.Similarity = 0.55
+	c.Explain("", "")
+	if len(c.MatchedOn) != 1 || c.MatchedOn[0] != "name" {
+		t.Fatalf("expected only a name match, got %v", c.MatchedOn)
+	}
+
+	var err error = &DuplicateError{Candidates: []DuplicateCandidate{c}}
+	if !errors.Is(err, ErrPossibleDuplicate) {
+		t.Fatal("DuplicateError must unwrap to ErrPossibleDuplicate")
+	}
+}
+
+func TestResubmitFieldsDropsTokenAndSkippedNames(t *testing.T) {
+	form := url.Values{
+		CSRFFormField:       {"secret"},
+		"confirm_duplicate": {"true"},
+		"name":              {"PT Maju"},
+		"code":              {"C-001"},
+	}
+	fields := ResubmitFields(form, "confirm_duplicate")
+	if len(fields) != 2 || fields[0] != (FormField{Name: "code", Value: "C-001"}) || fields[1].Name != "name" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+}
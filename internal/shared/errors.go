@@ -31,6 +31,8 @@ var (
 	ErrInvalidInput = errors.New("invalid input")
 	// ErrConflict indicates a state conflict (e.g., already processed).
 	ErrConflict = errors.New("conflict")
+	// ErrStaleRecord indicates the record changed after an edit form loaded it.
+	ErrStaleRecord = errors.New("record changed since it was loaded")
 )
 
 // ============================================================================
@@ -78,6 +80,11 @@ var userSafeMessages = map[error]string{
 	ErrCSRFTokenMismatch:  "Security token expired. Please refresh and try again.",
 	ErrInvalidInput:       "The provided input is invalid.",
 	ErrConflict:           "This action cannot be completed due to a conflict.",
+	ErrStaleRecord:        "Someone else changed this record after you opened it. Reload the page and reapply your changes.",
+	ErrInactiveCurrency:   "This currency is not active. Choose an active currency or ask finance to enable it.",
+	ErrApproverRole:       "The approval matrix requires a different approver role for this amount.",
+	ErrNoDefaultTax:       "Enter a tax rate on every line or set a default tax on the customer.",
+	ErrInvalidTransition:  "This action is not allowed in the document's current status.",
 }
 
 // UserSafeMessage returns a user-friendly error message.
@@ -134,6 +141,9 @@ func IsUserError(err error) bool {
 		errors.Is(err, ErrValidation) ||
 		errors.Is(err, ErrInvalidInput) ||
 		errors.Is(err, ErrConflict) ||
+		errors.Is(err, ErrStaleRecord) ||
+		errors.Is(err, ErrInactiveCurrency) ||
+		errors.Is(err, ErrNoDefaultTax) ||
 		errors.Is(err, ErrInvalidCredentials)
 }
 
@@ -1,6 +1,18 @@
 package shared
 
-import "math"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+)
+
+// MaxListLimit caps the page size accepted by list endpoints.
+const MaxListLimit = 1000
+
+// ErrInvalidCursor indicates a malformed or tampered pagination cursor.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
 
 // Pagination contains metadata for paginated listings.
 type Pagination struct {
@@ -21,3 +33,29 @@ func NewPagination(page, perPage, total int) Pagination {
 	totalPages := int(math.Ceil(float64(total) / float64(perPage)))
 	return Pagination{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages}
 }
+
+// Cursor is the keyset position of the last row returned by a list query.
+// At carries the primary sort key (when it is a date) and ID breaks ties.
+type Cursor struct {
+	At time.Time `json:"at,omitempty"`
+	ID int64     `json:"id"`
+}
+
+// EncodeCursor renders a cursor as an opaque URL-safe token.
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a token produced by EncodeCursor.
+func DecodeCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,26 @@
+package shared
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	in := Cursor{At: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), ID: 42}
+	out, err := DecodeCursor(EncodeCursor(in))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !out.At.Equal(in.At) || out.ID != in.ID {
+		t.Fatalf("round trip mismatch: %+v", out)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, token := range []string{"", "not-base64!", EncodeCursor(Cursor{})} {
+		if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor for %q, got %v", token, err)
+		}
+	}
+}
@@ -11,12 +11,17 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/requestid"
 )
 
 // FlashMessage represents a one-time notification stored in session.
+// Error flashes carry the Reference of the request that raised them, so the
+// page shown after the redirect can quote the request ID found in the logs.
 type FlashMessage struct {
-	Kind    string `json:"kind"`
-	Message string `json:"message"`
+	Kind      string `json:"kind"`
+	Message   string `json:"message"`
+	Reference string `json:"reference,omitempty"`
 }
 
 // SessionManager orchestrates cookie based sessions backed by Redis.
@@ -119,6 +124,13 @@ func (sm *SessionManager) Commit(ctx context.Context, w http.ResponseWriter, r *
 	}
 
 	if sess.dirty || sess.isNew {
+		if id := requestid.FromContext(ctx); id != "" {
+			for i, flash := range sess.flashes {
+				if flash.Reference == "" && (flash.Kind == "danger" || flash.Kind == "error") {
+					sess.flashes[i].Reference = id
+				}
+			}
+		}
 		payload := sessionPayload{Values: sess.values, UserID: sess.userID, Flashes: sess.flashes}
 		data, err := json.Marshal(payload)
 		if err != nil {
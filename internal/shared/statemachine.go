@@ -0,0 +1,68 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransition is matched by every TransitionError, so handlers can
+// recognise a rejected status change from any entity.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// TransitionError reports a status change that an entity's state machine
+// does not allow.
+type TransitionError struct {
+	Entity string
+	From   string
+	To     string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s cannot move from %s to %s", e.Entity, e.From, e.To)
+}
+
+// Is makes errors.Is(err, ErrInvalidTransition) hold.
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// StateMachine lists the status transitions allowed for one entity. Statuses
+// without an entry are terminal.
+type StateMachine[S ~string] struct {
+	entity string
+	next   map[S][]S
+}
+
+// NewStateMachine builds the state machine of entity from its allowed
+// transitions, keyed by the status they leave.
+func NewStateMachine[S ~string](entity string, transitions map[S][]S) *StateMachine[S] {
+	return &StateMachine[S]{entity: entity, next: transitions}
+}
+
+// Can reports whether an entity in status from may move to status to.
+func (m *StateMachine[S]) Can(from, to S) bool {
+	for _, allowed := range m.next[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns a TransitionError unless from may move to to.
+func (m *StateMachine[S]) Validate(from, to S) error {
+	if m.Can(from, to) {
+		return nil
+	}
+	return &TransitionError{Entity: m.entity, From: string(from), To: string(to)}
+}
+
+// Next lists the statuses reachable from from in one step.
+func (m *StateMachine[S]) Next(from S) []S {
+	return append([]S(nil), m.next[from]...)
+}
+
+// IsTerminal reports whether no transition leaves status.
+func (m *StateMachine[S]) IsTerminal(status S) bool {
+	return len(m.next[status]) == 0
+}
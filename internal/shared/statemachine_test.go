@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"errors"
+	"testing"
+)
+
+type docStatus string
+
+func TestStateMachineValidate(t *testing.T) {
+	sm := NewStateMachine("document", map[docStatus][]docStatus{
+		"DRAFT":     {"SUBMITTED", "CANCELLED"},
+		"SUBMITTED": {"APPROVED"},
+	})
+
+	if err := sm.Validate("DRAFT", "SUBMITTED"); err != nil {
+		t.Fatalf("DRAFT -> SUBMITTED: %v", err)
+	}
+	err := sm.Validate("SUBMITTED", "CANCELLED")
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	var te *TransitionError
+	if !errors.As(err, &te) || te.From != "SUBMITTED" || te.To != "CANCELLED" {
+		t.Fatalf("unexpected transition error %#v", err)
+	}
+	if got := err.Error(); got != "document cannot move from SUBMITTED to CANCELLED" {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if !sm.IsTerminal("APPROVED") || sm.IsTerminal("DRAFT") {
+		t.Fatal("terminal statuses are those without outgoing transitions")
+	}
+	next := sm.Next("DRAFT")
+	next[0] = "APPROVED"
+	if sm.Can("DRAFT", "APPROVED") {
+		t.Fatal("Next must return a copy")
+	}
+}
@@ -0,0 +1,41 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoDefaultTax indicates a document line left its tax blank for a customer
+// or supplier without a default tax.
+var ErrNoDefaultTax = errors.New("no tax entered and no default tax configured")
+
+// TaxRate is a tax with the rate in effect on a document date.
+type TaxRate struct {
+	TaxID int64
+	Code  string
+	Name  string
+	Rate  float64
+}
+
+// Exempt reports whether the tax charges nothing, as NO-TAX does for
+// tax-exempt customers.
+func (t TaxRate) Exempt() bool {
+	return t.Rate == 0
+}
+
+// Notice describes the tax for flash messages, e.g. "PPN (11.00%)".
+func (t TaxRate) Notice() string {
+	return fmt.Sprintf("%s (%.2f%%)", t.Code, t.Rate)
+}
+
+// DefaultTaxResolver looks up the default output tax of a customer and the
+// default input tax of a supplier at the rate effective on a date. Both
+// return nil when no default is configured. RateOn returns a given tax at the
+// rate effective on the date, nil when the tax does not exist.
+type DefaultTaxResolver interface {
+	CustomerDefaultTax(ctx context.Context, customerID int64, on time.Time) (*TaxRate, error)
+	SupplierDefaultTax(ctx context.Context, supplierID int64, on time.Time) (*TaxRate, error)
+	RateOn(ctx context.Context, taxID int64, on time.Time) (*TaxRate, error)
+}
@@ -0,0 +1,160 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidTaxID indicates a tax ID that does not match its country's format.
+var ErrInvalidTaxID = errors.New("invalid tax ID")
+
+// TaxIDFormat describes the accepted tax ID format of one country. Pattern is
+// matched against the trimmed value; Checksum optionally names an algorithm
+// from taxIDChecksums that is run over the digits of the value.
+type TaxIDFormat struct {
+	Country  string `json:"country"`
+	Name     string `json:"name"`
+	Pattern  string `json:"pattern"`
+	Example  string `json:"example,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// DefaultTaxIDFormats are used when no formats file is configured. NPWP is
+// accepted in its dotted 15-digit form or as plain 15/16 digits (the 16-digit
+// form replaced it from 2024).
+var DefaultTaxIDFormats = []TaxIDFormat{
+	{
+		Country: "ID",
+		Name:    "NPWP",
+		Pattern: `^(\d{2}\.\d{3}\.\d{3}\.\d-\d{3}\.\d{3}|\d{15}|\d{16})$`,
+		Example: "12.345.678.9-012.345",
+	},
+}
+
+// taxIDChecksums holds the checksum algorithms a format may reference.
+var taxIDChecksums = map[string]func(digits string) bool{
+	"luhn": luhnValid,
+}
+
+type taxIDRule struct {
+	format  TaxIDFormat
+	pattern *regexp.Regexp
+	check   func(string) bool
+}
+
+// TaxIDValidator checks tax IDs against per-country formats. Countries without
+// a configured format accept any value.
+type TaxIDValidator struct {
+	rules map[string]taxIDRule
+}
+
+// NewTaxIDValidator compiles the given formats.
+func NewTaxIDValidator(formats []TaxIDFormat) (*TaxIDValidator, error) {
+	v := &TaxIDValidator{rules: make(map[string]taxIDRule, len(formats))}
+	for _, f := range formats {
+		country := strings.ToUpper(strings.TrimSpace(f.Country))
+		if country == "" {
+			return nil, errors.New("tax id format: country is required")
+		}
+		pattern, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("tax id format %s: %w", country, err)
+		}
+		rule := taxIDRule{format: f, pattern: pattern}
+		if f.Checksum != "" {
+			check, ok := taxIDChecksums[strings.ToLower(f.Checksum)]
+			if !ok {
+				return nil, fmt.Errorf("tax id format %s: unknown checksum %q", country, f.Checksum)
+			}
+			rule.check = check
+		}
+		if rule.format.Name == "" {
+			rule.format.Name = "Tax ID"
+		}
+		v.rules[country] = rule
+	}
+	return v, nil
+}
+
+// DefaultTaxIDValidator returns a validator for DefaultTaxIDFormats.
+func DefaultTaxIDValidator() *TaxIDValidator {
+	v, err := NewTaxIDValidator(DefaultTaxIDFormats)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// LoadTaxIDValidator reads formats from a JSON file holding a list of
+// TaxIDFormat objects. An empty path yields the default formats.
+func LoadTaxIDValidator(path string) (*TaxIDValidator, error) {
+	if strings.TrimSpace(path) == "" {
+		return DefaultTaxIDValidator(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tax id formats: %w", err)
+	}
+	var formats []TaxIDFormat
+	if err := json.Unmarshal(raw, &formats); err != nil {
+		return nil, fmt.Errorf("parse tax id formats: %w", err)
+	}
+	return NewTaxIDValidator(formats)
+}
+
+// Validate checks taxID against the country's format. Empty tax IDs and
+// countries without a format always pass.
+func (v *TaxIDValidator) Validate(country, taxID string) error {
+	taxID = strings.TrimSpace(taxID)
+	if v == nil || taxID == "" {
+		return nil
+	}
+	rule, ok := v.rules[strings.ToUpper(strings.TrimSpace(country))]
+	if !ok {
+		return nil
+	}
+	if !rule.pattern.MatchString(taxID) {
+		if rule.format.Example != "" {
+			return fmt.Errorf("%w: %s must look like %s", ErrInvalidTaxID, rule.format.Name, rule.format.Example)
+		}
+		return fmt.Errorf("%w: %s has an invalid format", ErrInvalidTaxID, rule.format.Name)
+	}
+	if rule.check != nil && !rule.check(digitsOnly(taxID)) {
+		return fmt.Errorf("%w: %s check digit does not match", ErrInvalidTaxID, rule.format.Name)
+	}
+	return nil
+}
+
+func digitsOnly(v string) string {
+	var b strings.Builder
+	for _, r := range v {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func luhnValid(digits string) bool {
+	if digits == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,50 @@
+package shared
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultTaxIDValidatorNPWP(t *testing.T) {
+	v := DefaultTaxIDValidator()
+	for _, valid := range []string{"12.345.678.9-012.345", "123456789012345", "1234567890123456", " 12.345.678.9-012.345 ", ""} {
+		if err := v.Validate("ID", valid); err != nil {
+			t.Fatalf("expected %q to be valid: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"12-345", "12.345.678.9.012.345", "ABCDEFGHIJKLMNO"} {
+		if err := v.Validate("id", invalid); !errors.Is(err, ErrInvalidTaxID) {
+			t.Fatalf("expected %q to be rejected, got %v", invalid, err)
+		}
+	}
+	if err := v.Validate("SG", "anything goes"); err != nil {
+		t.Fatalf("countries without a format must pass, got %v", err)
+	}
+}
+
+func TestLoadTaxIDValidatorFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "formats.json")
+	body := `[{"country":"XX","name":"Test ID","pattern":"^\\d{4}$","checksum":"luhn"}]`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := LoadTaxIDValidator(path)
+	if err != nil {
+		t.Fatalf("LoadTaxIDValidator: %v", err)
+	}
+	if err := v.Validate("XX", "4242"); err != nil {
+		t.Fatalf("expected luhn-valid ID to pass: %v", err)
+	}
+	if err := v.Validate("XX", "4243"); !errors.Is(err, ErrInvalidTaxID) {
+		t.Fatalf("expected checksum failure, got %v", err)
+	}
+	if err := v.Validate("ID", "not an npwp"); err != nil {
+		t.Fatalf("configured file replaces the defaults, got %v", err)
+	}
+
+	if _, err := NewTaxIDValidator([]TaxIDFormat{{Country: "XX", Pattern: ".*", Checksum: "crc"}}); err == nil {
+		t.Fatal("expected unknown checksum to be rejected")
+	}
+}
@@ -0,0 +1,43 @@
+package shared
+
+import (
+	"strings"
+	"time"
+)
+
+// VersionFormField is the hidden edit-form field carrying the record version
+// the form was loaded with.
+const VersionFormField = "version"
+
+// VersionToken encodes a record's updated_at as an edit-form version token.
+// Microsecond precision matches what PostgreSQL stores.
+func VersionToken(updatedAt time.Time) string {
+	if updatedAt.IsZero() {
+		return ""
+	}
+	return updatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+// ParseVersionToken decodes a token from VersionToken. An empty token yields
+// nil so clients that send none keep last-write-wins behaviour; a malformed
+// one is rejected with ErrInvalidInput.
+func ParseVersionToken(token string) (*time.Time, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, token)
+	if err != nil {
+		return nil, ErrInvalidInput
+	}
+	return &t, nil
+}
+
+// CheckVersion returns ErrStaleRecord when expected is set and differs from
+// the record's current updated_at.
+func CheckVersion(expected *time.Time, current time.Time) error {
+	if expected != nil && !expected.Equal(current) {
+		return ErrStaleRecord
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package shared
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVersionTokenRoundTripAndCheck(t *testing.T) {
+	loaded := time.Date(2026, 10, 16, 9, 30, 0, 123456000, time.FixedZone("WIB", 7*3600))
+	expected, err := ParseVersionToken(VersionToken(loaded))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := CheckVersion(expected, loaded.UTC()); err != nil {
+		t.Fatalf("expected same version to pass, got %v", err)
+	}
+	if err := CheckVersion(expected, loaded.Add(time.Microsecond)); !errors.Is(err, ErrStaleRecord) {
+		t.Fatalf("expected ErrStaleRecord, got %v", err)
+	}
+	if v, err := ParseVersionToken(""); v != nil || err != nil {
+		t.Fatalf("empty token must disable the check, got %v, %v", v, err)
+	}
+	if _, err := ParseVersionToken("yesterday"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
@@ -11,6 +11,79 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const compareTrialBalanceByCompany = `-- name: CompareTrialBalanceByCompany :many
+WITH target_period AS (
+    SELECT p.id, p.start_date, p.end_date FROM periods p WHERE p.id = $1
+)
+SELECT acc.code, acc.name, acc.type,
+       jl.dim_company_id::BIGINT AS company_id,
+       COALESCE(SUM(CASE WHEN je.date < tp.start_date THEN (jl.debit - jl.credit) ELSE 0 END),0)::float8 AS opening,
+       COALESCE(SUM(CASE WHEN je.date BETWEEN tp.start_date AND tp.end_date THEN jl.debit ELSE 0 END),0)::float8 AS debit,
+       COALESCE(SUM(CASE WHEN je.date BETWEEN tp.start_date AND tp.end_date THEN jl.credit ELSE 0 END),0)::float8 AS credit
+FROM journal_lines jl
+JOIN accounts posted ON posted.id = jl.account_id
+LEFT JOIN accounts own ON posted.company_id IS NULL AND own.company_id = jl.dim_company_id AND own.code = posted.code
+JOIN accounts acc ON acc.id = COALESCE(own.id, posted.id)
+JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
+JOIN target_period tp ON TRUE
+WHERE jl.dim_company_id = ANY($2::BIGINT[])
+  AND je.date <= tp.end_date
+  AND ($3::bigint IS NULL OR jl.dim_branch_id = $3::bigint)
+  AND ($4::bigint IS NULL OR jl.dim_warehouse_id = $4::bigint)
+GROUP BY acc.code, acc.name, acc.type, jl.dim_company_id
+ORDER BY acc.code, jl.dim_company_id
+`
+
+type CompareTrialBalanceByCompanyParams struct {
+	PeriodID    int64       `json:"period_id"`
+	CompanyIds  []int64     `json:"company_ids"`
+	BranchID    pgtype.Int8 `json:"branch_id"`
+	WarehouseID pgtype.Int8 `json:"warehouse_id"`
+}
+
+type CompareTrialBalanceByCompanyRow struct {
+	Code      string      `json:"code"`
+	Name      string      `json:"name"`
+	Type      AccountType `json:"type"`
+	CompanyID int64       `json:"company_id"`
+	Opening   float64     `json:"opening"`
+	Debit     float64     `json:"debit"`
+	Credit    float64     `json:"credit"`
+}
+
+func (q *Queries) CompareTrialBalanceByCompany(ctx context.Context, arg CompareTrialBalanceByCompanyParams) ([]CompareTrialBalanceByCompanyRow, error) {
+	rows, err := q.db.Query(ctx, compareTrialBalanceByCompany,
+		arg.PeriodID,
+		arg.CompanyIds,
+		arg.BranchID,
+		arg.WarehouseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CompareTrialBalanceByCompanyRow
+	for rows.Next() {
+		var i CompareTrialBalanceByCompanyRow
+		if err := rows.Scan(
+			&i.Code,
+			&i.Name,
+			&i.Type,
+			&i.CompanyID,
+			&i.Opening,
+			&i.Debit,
+			&i.Credit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAccounts = `-- name: GetAccounts :many
 SELECT id, code, name, type, parent_id, is_active, created_at, updated_at
 FROM accounts
@@ -56,3 +129,88 @@ func (q *Queries) GetAccounts(ctx context.Context) ([]GetAccountsRow, error) {
 	}
 	return items, nil
 }
+
+const profitLossByDimension = `-- name: ProfitLossByDimension :many
+WITH target_period AS (
+    SELECT p.start_date, p.end_date FROM periods p WHERE p.id = $1
+)
+SELECT acc.code, acc.name, acc.type,
+       (CASE WHEN $2::text = 'warehouse' THEN jl.dim_warehouse_id ELSE jl.dim_branch_id END)::BIGINT AS dimension_id,
+       COALESCE(CASE WHEN $2::text = 'warehouse' THEN w.name ELSE b.name END, '')::TEXT AS dimension_name,
+       COALESCE(SUM(jl.debit),0)::float8 AS debit,
+       COALESCE(SUM(jl.credit),0)::float8 AS credit
+FROM journal_lines jl
+JOIN accounts posted ON posted.id = jl.account_id
+LEFT JOIN accounts own ON posted.company_id IS NULL AND own.company_id = jl.dim_company_id AND own.code = posted.code
+JOIN accounts acc ON acc.id = COALESCE(own.id, posted.id) AND acc.type IN ('REVENUE', 'EXPENSE')
+JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
+JOIN target_period tp ON je.date BETWEEN tp.start_date AND tp.end_date
+LEFT JOIN branches b ON b.id = jl.dim_branch_id
+LEFT JOIN warehouses w ON w.id = jl.dim_warehouse_id
+WHERE ($3::bigint IS NULL OR jl.dim_company_id = $3::bigint)
+  AND ($4::bigint IS NULL OR jl.dim_branch_id = $4::bigint)
+  AND (NOT $5::bool OR jl.dim_branch_id IS NULL)
+  AND ($6::bigint IS NULL OR jl.dim_warehouse_id = $6::bigint)
+  AND (NOT $7::bool OR jl.dim_warehouse_id IS NULL)
+GROUP BY acc.code, acc.name, acc.type, 4, 5
+ORDER BY acc.code, 4 NULLS LAST
+`
+
+type ProfitLossByDimensionParams struct {
+	PeriodID             int64       `json:"period_id"`
+	Dimension            string      `json:"dimension"`
+	CompanyID            pgtype.Int8 `json:"company_id"`
+	BranchID             pgtype.Int8 `json:"branch_id"`
+	BranchUnallocated    bool        `json:"branch_unallocated"`
+	WarehouseID          pgtype.Int8 `json:"warehouse_id"`
+	WarehouseUnallocated bool        `json:"warehouse_unallocated"`
+}
+
+type ProfitLossByDimensionRow struct {
+	Code          string      `json:"code"`
+	Name          string      `json:"name"`
+	Type          AccountType `json:"type"`
+	DimensionID   pgtype.Int8 `json:"dimension_id"`
+	DimensionName string      `json:"dimension_name"`
+	Debit         float64     `json:"debit"`
+	Credit        float64     `json:"credit"`
+}
+
+// Period revenue and expense per account, split by the branch or warehouse on
+// each journal line. Lines without that dimension come back with a NULL
+// dimension_id (the unallocated bucket).
+func (q *Queries) ProfitLossByDimension(ctx context.Context, arg ProfitLossByDimensionParams) ([]ProfitLossByDimensionRow, error) {
+	rows, err := q.db.Query(ctx, profitLossByDimension,
+		arg.PeriodID,
+		arg.Dimension,
+		arg.CompanyID,
+		arg.BranchID,
+		arg.BranchUnallocated,
+		arg.WarehouseID,
+		arg.WarehouseUnallocated,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProfitLossByDimensionRow
+	for rows.Next() {
+		var i ProfitLossByDimensionRow
+		if err := rows.Scan(
+			&i.Code,
+			&i.Name,
+			&i.Type,
+			&i.DimensionID,
+			&i.DimensionName,
+			&i.Debit,
+			&i.Credit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
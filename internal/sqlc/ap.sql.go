@@ -15,26 +15,34 @@ const createAPInvoice = `-- name: CreateAPInvoice :one
 INSERT INTO ap_invoices (
     number, supplier_id, grn_id, po_id, currency, 
     subtotal, tax_amount, total, status, 
-    due_at, created_by, created_at, updated_at
+    issued_at, due_at, payment_terms_days, created_by,
+    withholding_tax_id, withholding_rate, withholding_amount, company_id, created_at, updated_at
 ) VALUES (
     $1, $2, $3, $4, $5, 
     $6, $7, $8, $9, 
-    $10, $11, NOW(), NOW()
+    $10, $11, $12, $13,
+    $14, $15, $16, $17, NOW(), NOW()
 ) RETURNING id
 `
 
 type CreateAPInvoiceParams struct {
-	Number     string         `json:"number"`
-	SupplierID int64          `json:"supplier_id"`
-	GrnID      pgtype.Int8    `json:"grn_id"`
-	PoID       pgtype.Int8    `json:"po_id"`
-	Currency   string         `json:"currency"`
-	Subtotal   pgtype.Numeric `json:"subtotal"`
-	TaxAmount  pgtype.Numeric `json:"tax_amount"`
-	Total      pgtype.Numeric `json:"total"`
-	Status     string         `json:"status"`
-	DueAt      pgtype.Date    `json:"due_at"`
-	CreatedBy  pgtype.Int8    `json:"created_by"`
+	Number            string         `json:"number"`
+	SupplierID        int64          `json:"supplier_id"`
+	GrnID             pgtype.Int8    `json:"grn_id"`
+	PoID              pgtype.Int8    `json:"po_id"`
+	Currency          string         `json:"currency"`
+	Subtotal          pgtype.Numeric `json:"subtotal"`
+	TaxAmount         pgtype.Numeric `json:"tax_amount"`
+	Total             pgtype.Numeric `json:"total"`
+	Status            string         `json:"status"`
+	IssuedAt          pgtype.Date    `json:"issued_at"`
+	DueAt             pgtype.Date    `json:"due_at"`
+	PaymentTermsDays  pgtype.Int4    `json:"payment_terms_days"`
+	CreatedBy         pgtype.Int8    `json:"created_by"`
+	WithholdingTaxID  pgtype.Int8    `json:"withholding_tax_id"`
+	WithholdingRate   pgtype.Numeric `json:"withholding_rate"`
+	WithholdingAmount pgtype.Numeric `json:"withholding_amount"`
+	CompanyID         pgtype.Int8    `json:"company_id"`
 }
 
 func (q *Queries) CreateAPInvoice(ctx context.Context, arg CreateAPInvoiceParams) (int64, error) {
@@ -48,8 +56,14 @@ func (q *Queries) CreateAPInvoice(ctx context.Context, arg CreateAPInvoiceParams
 		arg.TaxAmount,
 		arg.Total,
 		arg.Status,
+		arg.IssuedAt,
 		arg.DueAt,
+		arg.PaymentTermsDays,
 		arg.CreatedBy,
+		arg.WithholdingTaxID,
+		arg.WithholdingRate,
+		arg.WithholdingAmount,
+		arg.CompanyID,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -102,21 +116,23 @@ func (q *Queries) CreateAPInvoiceLine(ctx context.Context, arg CreateAPInvoiceLi
 const createAPPayment = `-- name: CreateAPPayment :one
 INSERT INTO ap_payments (
     number, ap_invoice_id, supplier_id, amount, paid_at, method, note, 
-    created_by, created_at, updated_at
+    created_by, payment_run_id, withheld_amount, created_at, updated_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW()
 ) RETURNING id
 `
 
 type CreateAPPaymentParams struct {
-	Number      string         `json:"number"`
-	ApInvoiceID pgtype.Int8    `json:"ap_invoice_id"`
-	SupplierID  pgtype.Int8    `json:"supplier_id"`
-	Amount      pgtype.Numeric `json:"amount"`
-	PaidAt      pgtype.Date    `json:"paid_at"`
-	Method      string         `json:"method"`
-	Note        string         `json:"note"`
-	CreatedBy   pgtype.Int8    `json:"created_by"`
+	Number         string         `json:"number"`
+	ApInvoiceID    pgtype.Int8    `json:"ap_invoice_id"`
+	SupplierID     pgtype.Int8    `json:"supplier_id"`
+	Amount         pgtype.Numeric `json:"amount"`
+	PaidAt         pgtype.Date    `json:"paid_at"`
+	Method         string         `json:"method"`
+	Note           string         `json:"note"`
+	CreatedBy      pgtype.Int8    `json:"created_by"`
+	PaymentRunID   pgtype.Int8    `json:"payment_run_id"`
+	WithheldAmount pgtype.Numeric `json:"withheld_amount"`
 }
 
 func (q *Queries) CreateAPPayment(ctx context.Context, arg CreateAPPaymentParams) (int64, error) {
@@ -129,6 +145,8 @@ func (q *Queries) CreateAPPayment(ctx context.Context, arg CreateAPPaymentParams
 		arg.Method,
 		arg.Note,
 		arg.CreatedBy,
+		arg.PaymentRunID,
+		arg.WithheldAmount,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -137,19 +155,65 @@ func (q *Queries) CreateAPPayment(ctx context.Context, arg CreateAPPaymentParams
 
 const createAPPaymentAllocation = `-- name: CreateAPPaymentAllocation :one
 INSERT INTO ap_payment_allocations (
-    ap_payment_id, ap_invoice_id, amount, created_at
-) VALUES ($1, $2, $3, NOW())
+    ap_payment_id, ap_invoice_id, amount, withheld_amount, created_at
+) VALUES ($1, $2, $3, $4, NOW())
 RETURNING id
 `
 
 type CreateAPPaymentAllocationParams struct {
-	ApPaymentID int64          `json:"ap_payment_id"`
-	ApInvoiceID int64          `json:"ap_invoice_id"`
-	Amount      pgtype.Numeric `json:"amount"`
+	ApPaymentID    int64          `json:"ap_payment_id"`
+	ApInvoiceID    int64          `json:"ap_invoice_id"`
+	Amount         pgtype.Numeric `json:"amount"`
+	WithheldAmount pgtype.Numeric `json:"withheld_amount"`
 }
 
 func (q *Queries) CreateAPPaymentAllocation(ctx context.Context, arg CreateAPPaymentAllocationParams) (int64, error) {
-	row := q.db.QueryRow(ctx, createAPPaymentAllocation, arg.ApPaymentID, arg.ApInvoiceID, arg.Amount)
+	row := q.db.QueryRow(ctx, createAPPaymentAllocation,
+		arg.ApPaymentID,
+		arg.ApInvoiceID,
+		arg.Amount,
+		arg.WithheldAmount,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createAPPaymentRun = `-- name: CreateAPPaymentRun :one
+INSERT INTO ap_payment_runs (
+    number, pay_date, method, note, due_before, supplier_id,
+    cash_limit, group_by_supplier, total, created_by, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW()
+) RETURNING id
+`
+
+type CreateAPPaymentRunParams struct {
+	Number          string         `json:"number"`
+	PayDate         pgtype.Date    `json:"pay_date"`
+	Method          string         `json:"method"`
+	Note            string         `json:"note"`
+	DueBefore       pgtype.Date    `json:"due_before"`
+	SupplierID      pgtype.Int8    `json:"supplier_id"`
+	CashLimit       pgtype.Numeric `json:"cash_limit"`
+	GroupBySupplier bool           `json:"group_by_supplier"`
+	Total           pgtype.Numeric `json:"total"`
+	CreatedBy       pgtype.Int8    `json:"created_by"`
+}
+
+func (q *Queries) CreateAPPaymentRun(ctx context.Context, arg CreateAPPaymentRunParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createAPPaymentRun,
+		arg.Number,
+		arg.PayDate,
+		arg.Method,
+		arg.Note,
+		arg.DueBefore,
+		arg.SupplierID,
+		arg.CashLimit,
+		arg.GroupBySupplier,
+		arg.Total,
+		arg.CreatedBy,
+	)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
@@ -177,38 +241,61 @@ func (q *Queries) GenerateAPPaymentNumber(ctx context.Context) (interface{}, err
 	return column_1, err
 }
 
+const generateAPPaymentRunNumber = `-- name: GenerateAPPaymentRunNumber :one
+SELECT 'PR-' || TO_CHAR(NOW(), 'YYYYMMDD') || '-' || LPAD(NEXTVAL('ap_payment_runs_id_seq')::TEXT, 4, '0')
+`
+
+func (q *Queries) GenerateAPPaymentRunNumber(ctx context.Context) (interface{}, error) {
+	row := q.db.QueryRow(ctx, generateAPPaymentRunNumber)
+	var column_1 interface{}
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const getAPInvoice = `-- name: GetAPInvoice :one
 SELECT 
     i.id, i.number, i.supplier_id, s.name AS supplier_name, i.grn_id, i.po_id, i.currency, 
-    subtotal, tax_amount, total, status, due_at, 
+    subtotal, tax_amount, total, status, issued_at, due_at, i.payment_terms_days, 
     posted_at, posted_by, voided_at, voided_by, void_reason,
-    created_by, created_at, updated_at 
+    created_by, created_at, updated_at,
+    i.withholding_tax_id, COALESCE(wt.code, '') AS withholding_code, i.withholding_rate, i.withholding_amount,
+    COALESCE((
+        SELECT SUM(pa.withheld_amount) FROM ap_payment_allocations pa WHERE pa.ap_invoice_id = i.id
+    ), 0)::NUMERIC AS withheld_amount
 FROM ap_invoices i
 JOIN suppliers s ON s.id = i.supplier_id
+LEFT JOIN taxes wt ON wt.id = i.withholding_tax_id
 WHERE i.id = $1
 `
 
 type GetAPInvoiceRow struct {
-	ID           int64              `json:"id"`
-	Number       string             `json:"number"`
-	SupplierID   int64              `json:"supplier_id"`
-	SupplierName string             `json:"supplier_name"`
-	GrnID        pgtype.Int8        `json:"grn_id"`
-	PoID         pgtype.Int8        `json:"po_id"`
-	Currency     string             `json:"currency"`
-	Subtotal     pgtype.Numeric     `json:"subtotal"`
-	TaxAmount    pgtype.Numeric     `json:"tax_amount"`
-	Total        pgtype.Numeric     `json:"total"`
-	Status       string             `json:"status"`
-	DueAt        pgtype.Date        `json:"due_at"`
-	PostedAt     pgtype.Timestamptz `json:"posted_at"`
-	PostedBy     pgtype.Int8        `json:"posted_by"`
-	VoidedAt     pgtype.Timestamptz `json:"voided_at"`
-	VoidedBy     pgtype.Int8        `json:"voided_by"`
-	VoidReason   pgtype.Text        `json:"void_reason"`
-	CreatedBy    pgtype.Int8        `json:"created_by"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	ID                int64              `json:"id"`
+	Number            string             `json:"number"`
+	SupplierID        int64              `json:"supplier_id"`
+	SupplierName      string             `json:"supplier_name"`
+	GrnID             pgtype.Int8        `json:"grn_id"`
+	PoID              pgtype.Int8        `json:"po_id"`
+	Currency          string             `json:"currency"`
+	Subtotal          pgtype.Numeric     `json:"subtotal"`
+	TaxAmount         pgtype.Numeric     `json:"tax_amount"`
+	Total             pgtype.Numeric     `json:"total"`
+	Status            string             `json:"status"`
+	IssuedAt          pgtype.Date        `json:"issued_at"`
+	DueAt             pgtype.Date        `json:"due_at"`
+	PaymentTermsDays  pgtype.Int4        `json:"payment_terms_days"`
+	PostedAt          pgtype.Timestamptz `json:"posted_at"`
+	PostedBy          pgtype.Int8        `json:"posted_by"`
+	VoidedAt          pgtype.Timestamptz `json:"voided_at"`
+	VoidedBy          pgtype.Int8        `json:"voided_by"`
+	VoidReason        pgtype.Text        `json:"void_reason"`
+	CreatedBy         pgtype.Int8        `json:"created_by"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	WithholdingTaxID  pgtype.Int8        `json:"withholding_tax_id"`
+	WithholdingCode   string             `json:"withholding_code"`
+	WithholdingRate   pgtype.Numeric     `json:"withholding_rate"`
+	WithholdingAmount pgtype.Numeric     `json:"withholding_amount"`
+	WithheldAmount    pgtype.Numeric     `json:"withheld_amount"`
 }
 
 func (q *Queries) GetAPInvoice(ctx context.Context, id int64) (GetAPInvoiceRow, error) {
@@ -226,7 +313,9 @@ func (q *Queries) GetAPInvoice(ctx context.Context, id int64) (GetAPInvoiceRow,
 		&i.TaxAmount,
 		&i.Total,
 		&i.Status,
+		&i.IssuedAt,
 		&i.DueAt,
+		&i.PaymentTermsDays,
 		&i.PostedAt,
 		&i.PostedBy,
 		&i.VoidedAt,
@@ -235,6 +324,11 @@ func (q *Queries) GetAPInvoice(ctx context.Context, id int64) (GetAPInvoiceRow,
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.WithholdingTaxID,
+		&i.WithholdingCode,
+		&i.WithholdingRate,
+		&i.WithholdingAmount,
+		&i.WithheldAmount,
 	)
 	return i, err
 }
@@ -266,23 +360,28 @@ func (q *Queries) GetAPInvoiceBalance(ctx context.Context, id int64) (GetAPInvoi
 const getAPInvoiceBalancesBatch = `-- name: GetAPInvoiceBalancesBatch :many
 SELECT 
     i.id,
+    i.supplier_id,
+    s.name AS supplier_name,
     i.due_at,
     i.total,
     COALESCE(SUM(pa.amount), 0)::NUMERIC AS paid_amount,
     (i.total - COALESCE(SUM(pa.amount), 0))::NUMERIC AS balance
 FROM ap_invoices i
+JOIN suppliers s ON s.id = i.supplier_id
 LEFT JOIN ap_payment_allocations pa ON pa.ap_invoice_id = i.id
 WHERE i.status = 'POSTED'
-GROUP BY i.id, i.due_at, i.total
+GROUP BY i.id, i.supplier_id, s.name, i.due_at, i.total
 HAVING (i.total - COALESCE(SUM(pa.amount), 0)) > 0
 `
 
 type GetAPInvoiceBalancesBatchRow struct {
-	ID         int64          `json:"id"`
-	DueAt      pgtype.Date    `json:"due_at"`
-	Total      pgtype.Numeric `json:"total"`
-	PaidAmount pgtype.Numeric `json:"paid_amount"`
-	Balance    pgtype.Numeric `json:"balance"`
+	ID           int64          `json:"id"`
+	SupplierID   int64          `json:"supplier_id"`
+	SupplierName string         `json:"supplier_name"`
+	DueAt        pgtype.Date    `json:"due_at"`
+	Total        pgtype.Numeric `json:"total"`
+	PaidAmount   pgtype.Numeric `json:"paid_amount"`
+	Balance      pgtype.Numeric `json:"balance"`
 }
 
 func (q *Queries) GetAPInvoiceBalancesBatch(ctx context.Context) ([]GetAPInvoiceBalancesBatchRow, error) {
@@ -296,6 +395,8 @@ func (q *Queries) GetAPInvoiceBalancesBatch(ctx context.Context) ([]GetAPInvoice
 		var i GetAPInvoiceBalancesBatchRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.SupplierID,
+			&i.SupplierName,
 			&i.DueAt,
 			&i.Total,
 			&i.PaidAmount,
@@ -865,6 +966,70 @@ func (q *Queries) ListAPPayments(ctx context.Context) ([]ListAPPaymentsRow, erro
 	return items, nil
 }
 
+const lockPayableAPInvoices = `-- name: LockPayableAPInvoices :many
+SELECT
+    i.id, i.number, i.supplier_id, s.name AS supplier_name, i.currency, i.due_at,
+    (i.total - COALESCE((
+        SELECT SUM(pa.amount) FROM ap_payment_allocations pa WHERE pa.ap_invoice_id = i.id
+    ), 0))::NUMERIC AS balance,
+    GREATEST(i.withholding_amount - COALESCE((
+        SELECT SUM(pa.withheld_amount) FROM ap_payment_allocations pa WHERE pa.ap_invoice_id = i.id
+    ), 0), 0)::NUMERIC AS withholding_due
+FROM ap_invoices i
+JOIN suppliers s ON s.id = i.supplier_id
+WHERE i.id = ANY($1::BIGINT[])
+  AND i.status = 'POSTED'
+  AND ($2::BOOLEAN OR i.company_id IS NULL OR i.company_id = ANY($3::BIGINT[]))
+ORDER BY i.id
+FOR UPDATE OF i
+`
+
+type LockPayableAPInvoicesParams struct {
+	InvoiceIds   []int64 `json:"invoice_ids"`
+	Unrestricted bool    `json:"unrestricted"`
+	CompanyIds   []int64 `json:"company_ids"`
+}
+
+type LockPayableAPInvoicesRow struct {
+	ID             int64          `json:"id"`
+	Number         string         `json:"number"`
+	SupplierID     int64          `json:"supplier_id"`
+	SupplierName   string         `json:"supplier_name"`
+	Currency       string         `json:"currency"`
+	DueAt          pgtype.Date    `json:"due_at"`
+	Balance        pgtype.Numeric `json:"balance"`
+	WithholdingDue pgtype.Numeric `json:"withholding_due"`
+}
+
+func (q *Queries) LockPayableAPInvoices(ctx context.Context, arg LockPayableAPInvoicesParams) ([]LockPayableAPInvoicesRow, error) {
+	rows, err := q.db.Query(ctx, lockPayableAPInvoices, arg.InvoiceIds, arg.Unrestricted, arg.CompanyIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LockPayableAPInvoicesRow
+	for rows.Next() {
+		var i LockPayableAPInvoicesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Number,
+			&i.SupplierID,
+			&i.SupplierName,
+			&i.Currency,
+			&i.DueAt,
+			&i.Balance,
+			&i.WithholdingDue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const postAPInvoice = `-- name: PostAPInvoice :exec
 UPDATE ap_invoices 
 SET status = 'POSTED', posted_at = NOW(), posted_by = $2, updated_at = NOW()
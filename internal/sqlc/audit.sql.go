@@ -11,8 +11,53 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const auditLogGet = `-- name: AuditLogGet :one
+SELECT a.id,
+       a.occurred_at AS at,
+       COALESCE(u.email, a.actor_id::text) AS actor,
+       a.action,
+       a.entity,
+       a.entity_id::text AS entity_id,
+       a.meta,
+       a.before,
+       a.after
+FROM audit_logs a
+LEFT JOIN users u ON u.id = a.actor_id
+WHERE a.id = $1
+`
+
+type AuditLogGetRow struct {
+	ID       int64              `json:"id"`
+	At       pgtype.Timestamptz `json:"at"`
+	Actor    string             `json:"actor"`
+	Action   string             `json:"action"`
+	Entity   string             `json:"entity"`
+	EntityID string             `json:"entity_id"`
+	Meta     []byte             `json:"meta"`
+	Before   []byte             `json:"before"`
+	After    []byte             `json:"after"`
+}
+
+func (q *Queries) AuditLogGet(ctx context.Context, id int64) (AuditLogGetRow, error) {
+	row := q.db.QueryRow(ctx, auditLogGet, id)
+	var i AuditLogGetRow
+	err := row.Scan(
+		&i.ID,
+		&i.At,
+		&i.Actor,
+		&i.Action,
+		&i.Entity,
+		&i.EntityID,
+		&i.Meta,
+		&i.Before,
+		&i.After,
+	)
+	return i, err
+}
+
 const auditTimelineAll = `-- name: AuditTimelineAll :many
-SELECT a.occurred_at AS at,
+SELECT a.id,
+       a.occurred_at AS at,
        COALESCE(u.email, a.actor_id::text) AS actor,
        a.action,
        a.entity,
@@ -32,18 +77,21 @@ WHERE a.occurred_at BETWEEN $1 AND $2
   AND ($3::text IS NULL OR a.actor_id::text = $3::text)
   AND ($4::text IS NULL OR a.entity = $4::text)
   AND ($5::text IS NULL OR a.action = $5::text)
+  AND ($6::text[] IS NULL OR a.entity = ANY($6::text[]))
 ORDER BY a.occurred_at DESC
 `
 
 type AuditTimelineAllParams struct {
-	FromAt pgtype.Timestamptz `json:"from_at"`
-	ToAt   pgtype.Timestamptz `json:"to_at"`
-	Actor  pgtype.Text        `json:"actor"`
-	Entity pgtype.Text        `json:"entity"`
-	Action pgtype.Text        `json:"action"`
+	FromAt   pgtype.Timestamptz `json:"from_at"`
+	ToAt     pgtype.Timestamptz `json:"to_at"`
+	Actor    pgtype.Text        `json:"actor"`
+	Entity   pgtype.Text        `json:"entity"`
+	Action   pgtype.Text        `json:"action"`
+	Entities []string           `json:"entities"`
 }
 
 type AuditTimelineAllRow struct {
+	ID         int64              `json:"id"`
 	At         pgtype.Timestamptz `json:"at"`
 	Actor      string             `json:"actor"`
 	Action     string             `json:"action"`
@@ -60,6 +108,7 @@ func (q *Queries) AuditTimelineAll(ctx context.Context, arg AuditTimelineAllPara
 		arg.Actor,
 		arg.Entity,
 		arg.Action,
+		arg.Entities,
 	)
 	if err != nil {
 		return nil, err
@@ -69,6 +118,7 @@ func (q *Queries) AuditTimelineAll(ctx context.Context, arg AuditTimelineAllPara
 	for rows.Next() {
 		var i AuditTimelineAllRow
 		if err := rows.Scan(
+			&i.ID,
 			&i.At,
 			&i.Actor,
 			&i.Action,
@@ -88,7 +138,8 @@ func (q *Queries) AuditTimelineAll(ctx context.Context, arg AuditTimelineAllPara
 }
 
 const auditTimelineWindow = `-- name: AuditTimelineWindow :many
-SELECT a.occurred_at AS at,
+SELECT a.id,
+       a.occurred_at AS at,
        COALESCE(u.email, a.actor_id::text) AS actor,
        a.action,
        a.entity,
@@ -108,8 +159,9 @@ WHERE a.occurred_at BETWEEN $1 AND $2
   AND ($3::text IS NULL OR a.actor_id::text = $3::text)
   AND ($4::text IS NULL OR a.entity = $4::text)
   AND ($5::text IS NULL OR a.action = $5::text)
+  AND ($6::text[] IS NULL OR a.entity = ANY($6::text[]))
 ORDER BY a.occurred_at DESC
-LIMIT $7 OFFSET $6
+LIMIT $8 OFFSET $7
 `
 
 type AuditTimelineWindowParams struct {
@@ -118,11 +170,13 @@ type AuditTimelineWindowParams struct {
 	Actor      pgtype.Text        `json:"actor"`
 	Entity     pgtype.Text        `json:"entity"`
 	Action     pgtype.Text        `json:"action"`
+	Entities   []string           `json:"entities"`
 	OffsetRows int32              `json:"offset_rows"`
 	LimitRows  int32              `json:"limit_rows"`
 }
 
 type AuditTimelineWindowRow struct {
+	ID         int64              `json:"id"`
 	At         pgtype.Timestamptz `json:"at"`
 	Actor      string             `json:"actor"`
 	Action     string             `json:"action"`
@@ -139,6 +193,7 @@ func (q *Queries) AuditTimelineWindow(ctx context.Context, arg AuditTimelineWind
 		arg.Actor,
 		arg.Entity,
 		arg.Action,
+		arg.Entities,
 		arg.OffsetRows,
 		arg.LimitRows,
 	)
@@ -150,6 +205,7 @@ func (q *Queries) AuditTimelineWindow(ctx context.Context, arg AuditTimelineWind
 	for rows.Next() {
 		var i AuditTimelineWindowRow
 		if err := rows.Scan(
+			&i.ID,
 			&i.At,
 			&i.Actor,
 			&i.Action,
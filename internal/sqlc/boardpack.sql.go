@@ -19,8 +19,10 @@ SELECT acc.code, acc.name, acc.type,
        COALESCE(SUM(CASE WHEN je.date < tp.start_date THEN (jl.debit - jl.credit) ELSE 0 END),0)::float8 AS opening,
        COALESCE(SUM(CASE WHEN je.date BETWEEN tp.start_date AND tp.end_date THEN jl.debit ELSE 0 END),0)::float8 AS debit,
        COALESCE(SUM(CASE WHEN je.date BETWEEN tp.start_date AND tp.end_date THEN jl.credit ELSE 0 END),0)::float8 AS credit
-FROM accounts acc
-JOIN journal_lines jl ON jl.account_id = acc.id
+FROM journal_lines jl
+JOIN accounts posted ON posted.id = jl.account_id
+LEFT JOIN accounts own ON posted.company_id IS NULL AND own.company_id = jl.dim_company_id AND own.code = posted.code
+JOIN accounts acc ON acc.id = COALESCE(own.id, posted.id)
 JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
 JOIN target_period tp ON TRUE
 WHERE COALESCE(jl.dim_company_id, 0) = $1 AND je.date <= tp.end_date
@@ -165,6 +165,72 @@ func (q *Queries) InsertPeriodLegacy(ctx context.Context, arg InsertPeriodLegacy
 	return i, err
 }
 
+const insertPeriodSnapshot = `-- name: InsertPeriodSnapshot :one
+INSERT INTO period_close_snapshots (period_id, company_id, run_id, net_income, total_assets, total_liabilities, total_equity, captured_by)
+VALUES ($1, $2, $3, $4::float8, $5::float8,
+        $6::float8, $7::float8, $8)
+ON CONFLICT (period_id) DO NOTHING
+RETURNING id
+`
+
+type InsertPeriodSnapshotParams struct {
+	PeriodID         int64   `json:"period_id"`
+	CompanyID        int64   `json:"company_id"`
+	RunID            int64   `json:"run_id"`
+	NetIncome        float64 `json:"net_income"`
+	TotalAssets      float64 `json:"total_assets"`
+	TotalLiabilities float64 `json:"total_liabilities"`
+	TotalEquity      float64 `json:"total_equity"`
+	CapturedBy       int64   `json:"captured_by"`
+}
+
+func (q *Queries) InsertPeriodSnapshot(ctx context.Context, arg InsertPeriodSnapshotParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertPeriodSnapshot,
+		arg.PeriodID,
+		arg.CompanyID,
+		arg.RunID,
+		arg.NetIncome,
+		arg.TotalAssets,
+		arg.TotalLiabilities,
+		arg.TotalEquity,
+		arg.CapturedBy,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertPeriodSnapshotLine = `-- name: InsertPeriodSnapshotLine :exec
+INSERT INTO period_close_snapshot_lines (snapshot_id, account_code, account_name, account_type, opening, debit, credit, closing)
+VALUES ($1, $2, $3, $4,
+        $5::float8, $6::float8, $7::float8, $8::float8)
+`
+
+type InsertPeriodSnapshotLineParams struct {
+	SnapshotID  int64   `json:"snapshot_id"`
+	AccountCode string  `json:"account_code"`
+	AccountName string  `json:"account_name"`
+	AccountType string  `json:"account_type"`
+	Opening     float64 `json:"opening"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	Closing     float64 `json:"closing"`
+}
+
+func (q *Queries) InsertPeriodSnapshotLine(ctx context.Context, arg InsertPeriodSnapshotLineParams) error {
+	_, err := q.db.Exec(ctx, insertPeriodSnapshotLine,
+		arg.SnapshotID,
+		arg.AccountCode,
+		arg.AccountName,
+		arg.AccountType,
+		arg.Opening,
+		arg.Debit,
+		arg.Credit,
+		arg.Closing,
+	)
+	return err
+}
+
 const listChecklistItems = `-- name: ListChecklistItems :many
 SELECT id, period_close_run_id, code, label, status, assigned_to, completed_at, comment, created_at, updated_at
 FROM period_close_checklist_items
@@ -203,6 +269,55 @@ func (q *Queries) ListChecklistItems(ctx context.Context, periodCloseRunID int64
 	return items, nil
 }
 
+const listPeriodSnapshotLines = `-- name: ListPeriodSnapshotLines :many
+SELECT account_code, account_name, account_type,
+       opening::float8 AS opening,
+       debit::float8 AS debit,
+       credit::float8 AS credit,
+       closing::float8 AS closing
+FROM period_close_snapshot_lines
+WHERE snapshot_id = $1
+ORDER BY account_code
+`
+
+type ListPeriodSnapshotLinesRow struct {
+	AccountCode string  `json:"account_code"`
+	AccountName string  `json:"account_name"`
+	AccountType string  `json:"account_type"`
+	Opening     float64 `json:"opening"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	Closing     float64 `json:"closing"`
+}
+
+func (q *Queries) ListPeriodSnapshotLines(ctx context.Context, snapshotID int64) ([]ListPeriodSnapshotLinesRow, error) {
+	rows, err := q.db.Query(ctx, listPeriodSnapshotLines, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPeriodSnapshotLinesRow
+	for rows.Next() {
+		var i ListPeriodSnapshotLinesRow
+		if err := rows.Scan(
+			&i.AccountCode,
+			&i.AccountName,
+			&i.AccountType,
+			&i.Opening,
+			&i.Debit,
+			&i.Credit,
+			&i.Closing,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPeriods = `-- name: ListPeriods :many
 SELECT ap.id, ap.period_id, COALESCE(ap.company_id, 0), ap.name, ap.start_date, ap.end_date, ap.status,
        ap.soft_closed_by, ap.soft_closed_at, ap.closed_by, ap.closed_at, ap.metadata, ap.created_at, ap.updated_at,
@@ -513,6 +628,48 @@ func (q *Queries) LoadPeriodForUpdate(ctx context.Context, id int64) (LoadPeriod
 	return i, err
 }
 
+const loadPeriodSnapshot = `-- name: LoadPeriodSnapshot :one
+SELECT id, period_id, company_id, run_id,
+       net_income::float8 AS net_income,
+       total_assets::float8 AS total_assets,
+       total_liabilities::float8 AS total_liabilities,
+       total_equity::float8 AS total_equity,
+       captured_by, captured_at
+FROM period_close_snapshots
+WHERE period_id = $1
+`
+
+type LoadPeriodSnapshotRow struct {
+	ID               int64              `json:"id"`
+	PeriodID         int64              `json:"period_id"`
+	CompanyID        int64              `json:"company_id"`
+	RunID            int64              `json:"run_id"`
+	NetIncome        float64            `json:"net_income"`
+	TotalAssets      float64            `json:"total_assets"`
+	TotalLiabilities float64            `json:"total_liabilities"`
+	TotalEquity      float64            `json:"total_equity"`
+	CapturedBy       int64              `json:"captured_by"`
+	CapturedAt       pgtype.Timestamptz `json:"captured_at"`
+}
+
+func (q *Queries) LoadPeriodSnapshot(ctx context.Context, periodID int64) (LoadPeriodSnapshotRow, error) {
+	row := q.db.QueryRow(ctx, loadPeriodSnapshot, periodID)
+	var i LoadPeriodSnapshotRow
+	err := row.Scan(
+		&i.ID,
+		&i.PeriodID,
+		&i.CompanyID,
+		&i.RunID,
+		&i.NetIncome,
+		&i.TotalAssets,
+		&i.TotalLiabilities,
+		&i.TotalEquity,
+		&i.CapturedBy,
+		&i.CapturedAt,
+	)
+	return i, err
+}
+
 const lockChecklistItemRun = `-- name: LockChecklistItemRun :one
 SELECT period_close_run_id FROM period_close_checklist_items WHERE id = $1 FOR UPDATE
 `
@@ -176,6 +176,36 @@ func (q *Queries) ConsolBalancesByType(ctx context.Context, arg ConsolBalancesBy
 	return items, nil
 }
 
+const consolGroupSourceWatermark = `-- name: ConsolGroupSourceWatermark :one
+SELECT GREATEST(
+    COALESCE((
+        SELECT MAX(je.updated_at)
+        FROM journal_entries je
+        JOIN journal_lines jl ON jl.je_id = je.id
+        JOIN consol_members cm ON cm.company_id = jl.dim_company_id AND cm.group_id = $2
+        WHERE je.period_id = $1
+    ), 'epoch'::timestamptz),
+    COALESCE((SELECT MAX(updated_at) FROM consol_members WHERE group_id = $2), 'epoch'::timestamptz),
+    COALESCE((SELECT MAX(updated_at) FROM account_map WHERE group_id = $2), 'epoch'::timestamptz),
+    COALESCE((SELECT changed_at FROM consol_group_changes WHERE group_id = $2), 'epoch'::timestamptz)
+)::timestamptz AS source_watermark
+`
+
+type ConsolGroupSourceWatermarkParams struct {
+	PeriodID int64 `json:"period_id"`
+	GroupID  int64 `json:"group_id"`
+}
+
+// Latest change to anything the group's balances are built from: journals
+// booked by members in the period, membership and account mappings,
+// including members and mappings since deleted.
+func (q *Queries) ConsolGroupSourceWatermark(ctx context.Context, arg ConsolGroupSourceWatermarkParams) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, consolGroupSourceWatermark, arg.PeriodID, arg.GroupID)
+	var source_watermark pgtype.Timestamptz
+	err := row.Scan(&source_watermark)
+	return source_watermark, err
+}
+
 const deleteConsolBalances = `-- name: DeleteConsolBalances :exec
 DELETE FROM mv_consol_balances WHERE period_id = $1 AND group_id = $2
 `
@@ -222,6 +252,29 @@ func (q *Queries) FxRateForPeriod(ctx context.Context, arg FxRateForPeriodParams
 	return i, err
 }
 
+const getConsolRefreshWatermark = `-- name: GetConsolRefreshWatermark :one
+SELECT source_watermark, refreshed_at
+FROM consol_refresh_watermarks
+WHERE group_id = $1 AND period_id = $2
+`
+
+type GetConsolRefreshWatermarkParams struct {
+	GroupID  int64 `json:"group_id"`
+	PeriodID int64 `json:"period_id"`
+}
+
+type GetConsolRefreshWatermarkRow struct {
+	SourceWatermark pgtype.Timestamptz `json:"source_watermark"`
+	RefreshedAt     pgtype.Timestamptz `json:"refreshed_at"`
+}
+
+func (q *Queries) GetConsolRefreshWatermark(ctx context.Context, arg GetConsolRefreshWatermarkParams) (GetConsolRefreshWatermarkRow, error) {
+	row := q.db.QueryRow(ctx, getConsolRefreshWatermark, arg.GroupID, arg.PeriodID)
+	var i GetConsolRefreshWatermarkRow
+	err := row.Scan(&i.SourceWatermark, &i.RefreshedAt)
+	return i, err
+}
+
 const getGroup = `-- name: GetGroup :one
 SELECT name, reporting_currency FROM consol_groups WHERE id = $1
 `
@@ -328,6 +381,24 @@ func (q *Queries) Members(ctx context.Context, groupID int64) ([]MembersRow, err
 	return items, nil
 }
 
+const upsertConsolRefreshWatermark = `-- name: UpsertConsolRefreshWatermark :exec
+INSERT INTO consol_refresh_watermarks (group_id, period_id, source_watermark, refreshed_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (group_id, period_id)
+DO UPDATE SET source_watermark = EXCLUDED.source_watermark, refreshed_at = EXCLUDED.refreshed_at
+`
+
+type UpsertConsolRefreshWatermarkParams struct {
+	GroupID         int64              `json:"group_id"`
+	PeriodID        int64              `json:"period_id"`
+	SourceWatermark pgtype.Timestamptz `json:"source_watermark"`
+}
+
+func (q *Queries) UpsertConsolRefreshWatermark(ctx context.Context, arg UpsertConsolRefreshWatermarkParams) error {
+	_, err := q.db.Exec(ctx, upsertConsolRefreshWatermark, arg.GroupID, arg.PeriodID, arg.SourceWatermark)
+	return err
+}
+
 const upsertFxRate = `-- name: UpsertFxRate :exec
 INSERT INTO fx_rates (as_of_date, pair, average_rate, closing_rate)
 VALUES ($1, $2, $3, $4)
@@ -12,7 +12,7 @@ import (
 )
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, name, is_active, created_at, updated_at 
+SELECT id, email, name, is_active, manager_id, created_at, updated_at 
 FROM users 
 ORDER BY id
 `
@@ -22,6 +22,7 @@ type ListUsersRow struct {
 	Email     string             `json:"email"`
 	Name      string             `json:"name"`
 	IsActive  bool               `json:"is_active"`
+	ManagerID pgtype.Int8        `json:"manager_id"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
@@ -40,6 +41,7 @@ func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
 			&i.Email,
 			&i.Name,
 			&i.IsActive,
+			&i.ManagerID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -52,3 +54,28 @@ func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
 	}
 	return items, nil
 }
+
+const setUserManager = `-- name: SetUserManager :exec
+UPDATE users SET manager_id = $2, updated_at = NOW() WHERE id = $1
+`
+
+type SetUserManagerParams struct {
+	ID        int64       `json:"id"`
+	ManagerID pgtype.Int8 `json:"manager_id"`
+}
+
+func (q *Queries) SetUserManager(ctx context.Context, arg SetUserManagerParams) error {
+	_, err := q.db.Exec(ctx, setUserManager, arg.ID, arg.ManagerID)
+	return err
+}
+
+const userExists = `-- name: UserExists :one
+SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)
+`
+
+func (q *Queries) UserExists(ctx context.Context, id int64) (bool, error) {
+	row := q.db.QueryRow(ctx, userExists, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
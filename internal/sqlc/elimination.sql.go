@@ -48,6 +48,29 @@ func (q *Queries) ElimGetRule(ctx context.Context, id int64) (EliminationRule, e
 	return i, err
 }
 
+const elimGetRuleTemplate = `-- name: ElimGetRuleTemplate :one
+SELECT id, group_id, name, account_src, account_tgt, match_criteria, is_active, created_by, created_at, updated_at
+FROM elimination_rule_templates WHERE id = $1
+`
+
+func (q *Queries) ElimGetRuleTemplate(ctx context.Context, id int64) (EliminationRuleTemplate, error) {
+	row := q.db.QueryRow(ctx, elimGetRuleTemplate, id)
+	var i EliminationRuleTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Name,
+		&i.AccountSrc,
+		&i.AccountTgt,
+		&i.MatchCriteria,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const elimInsertRule = `-- name: ElimInsertRule :one
 INSERT INTO elimination_rules (group_id, name, source_company_id, target_company_id, account_src, account_tgt, match_criteria, created_by)
 VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
@@ -95,6 +118,158 @@ func (q *Queries) ElimInsertRule(ctx context.Context, arg ElimInsertRuleParams)
 	return i, err
 }
 
+const elimInsertRuleTemplate = `-- name: ElimInsertRuleTemplate :one
+INSERT INTO elimination_rule_templates (group_id, name, account_src, account_tgt, match_criteria, created_by)
+VALUES ($1,$2,$3,$4,$5,$6)
+RETURNING id, group_id, name, account_src, account_tgt, match_criteria, is_active, created_by, created_at, updated_at
+`
+
+type ElimInsertRuleTemplateParams struct {
+	GroupID       int64  `json:"group_id"`
+	Name          string `json:"name"`
+	AccountSrc    string `json:"account_src"`
+	AccountTgt    string `json:"account_tgt"`
+	MatchCriteria []byte `json:"match_criteria"`
+	CreatedBy     int64  `json:"created_by"`
+}
+
+func (q *Queries) ElimInsertRuleTemplate(ctx context.Context, arg ElimInsertRuleTemplateParams) (EliminationRuleTemplate, error) {
+	row := q.db.QueryRow(ctx, elimInsertRuleTemplate,
+		arg.GroupID,
+		arg.Name,
+		arg.AccountSrc,
+		arg.AccountTgt,
+		arg.MatchCriteria,
+		arg.CreatedBy,
+	)
+	var i EliminationRuleTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Name,
+		&i.AccountSrc,
+		&i.AccountTgt,
+		&i.MatchCriteria,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const elimListActiveRuleTemplates = `-- name: ElimListActiveRuleTemplates :many
+SELECT id, group_id, name, account_src, account_tgt, match_criteria, is_active, created_by, created_at, updated_at
+FROM elimination_rule_templates
+WHERE is_active
+ORDER BY group_id, id
+`
+
+func (q *Queries) ElimListActiveRuleTemplates(ctx context.Context) ([]EliminationRuleTemplate, error) {
+	rows, err := q.db.Query(ctx, elimListActiveRuleTemplates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EliminationRuleTemplate
+	for rows.Next() {
+		var i EliminationRuleTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Name,
+			&i.AccountSrc,
+			&i.AccountTgt,
+			&i.MatchCriteria,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const elimListEnabledMembers = `-- name: ElimListEnabledMembers :many
+SELECT cm.company_id, c.code
+FROM consol_members cm
+JOIN companies c ON c.id = cm.company_id
+WHERE cm.group_id = $1 AND cm.enabled
+ORDER BY cm.company_id
+`
+
+type ElimListEnabledMembersRow struct {
+	CompanyID int64  `json:"company_id"`
+	Code      string `json:"code"`
+}
+
+func (q *Queries) ElimListEnabledMembers(ctx context.Context, groupID int64) ([]ElimListEnabledMembersRow, error) {
+	rows, err := q.db.Query(ctx, elimListEnabledMembers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ElimListEnabledMembersRow
+	for rows.Next() {
+		var i ElimListEnabledMembersRow
+		if err := rows.Scan(&i.CompanyID, &i.Code); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const elimListGroupRules = `-- name: ElimListGroupRules :many
+SELECT id, group_id, name, source_company_id, target_company_id, account_src, account_tgt, match_criteria,
+       is_active, created_by, created_at, updated_at
+FROM elimination_rules
+WHERE group_id = $1 OR group_id IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ElimListGroupRules(ctx context.Context, groupID pgtype.Int8) ([]EliminationRule, error) {
+	rows, err := q.db.Query(ctx, elimListGroupRules, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EliminationRule
+	for rows.Next() {
+		var i EliminationRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Name,
+			&i.SourceCompanyID,
+			&i.TargetCompanyID,
+			&i.AccountSrc,
+			&i.AccountTgt,
+			&i.MatchCriteria,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const elimListRecentPeriods = `-- name: ElimListRecentPeriods :many
 SELECT ap.id, ap.period_id, ap.name, ap.start_date, ap.end_date
 FROM accounting_periods ap
@@ -136,6 +311,44 @@ func (q *Queries) ElimListRecentPeriods(ctx context.Context, limit int32) ([]Eli
 	return items, nil
 }
 
+const elimListRuleTemplates = `-- name: ElimListRuleTemplates :many
+SELECT id, group_id, name, account_src, account_tgt, match_criteria, is_active, created_by, created_at, updated_at
+FROM elimination_rule_templates
+ORDER BY group_id, name
+LIMIT $1
+`
+
+func (q *Queries) ElimListRuleTemplates(ctx context.Context, limit int32) ([]EliminationRuleTemplate, error) {
+	rows, err := q.db.Query(ctx, elimListRuleTemplates, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EliminationRuleTemplate
+	for rows.Next() {
+		var i EliminationRuleTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Name,
+			&i.AccountSrc,
+			&i.AccountTgt,
+			&i.MatchCriteria,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const elimListRules = `-- name: ElimListRules :many
 SELECT id, group_id, name, source_company_id, target_company_id, account_src, account_tgt, match_criteria,
        is_active, created_by, created_at, updated_at
@@ -204,6 +417,16 @@ func (q *Queries) ElimLoadAccountingPeriod(ctx context.Context, id int64) (ElimL
 	return i, err
 }
 
+const elimLockGroup = `-- name: ElimLockGroup :one
+SELECT id FROM consol_groups WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) ElimLockGroup(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, elimLockGroup, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
 const getRun = `-- name: GetRun :one
 SELECT er.id, er.period_id, er.rule_id, er.status, er.created_by, er.created_at, er.simulated_at, er.posted_at, er.journal_entry_id, er.summary,
        ru.id, ru.group_id, ru.name, ru.source_company_id, ru.target_company_id, ru.account_src, ru.account_tgt, ru.match_criteria,
@@ -396,7 +619,7 @@ func (q *Queries) ListRuns(ctx context.Context, arg ListRunsParams) ([]ListRunsR
 }
 
 const lookupAccountID = `-- name: LookupAccountID :one
-SELECT id FROM accounts WHERE code = $1
+SELECT id FROM accounts WHERE code = $1 ORDER BY company_id NULLS FIRST, id LIMIT 1
 `
 
 func (q *Queries) LookupAccountID(ctx context.Context, code string) (int64, error) {
@@ -11,6 +11,82 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const applyInboundBalances = `-- name: ApplyInboundBalances :many
+WITH incoming AS (
+    SELECT l.product_id, SUM(l.qty) AS qty, SUM(l.qty * l.unit_cost) AS cost
+    FROM unnest($1::bigint[], $2::float8[], $3::float8[]) AS l(product_id, qty, unit_cost)
+    GROUP BY l.product_id
+)
+INSERT INTO inventory_balances (
+    warehouse_id, product_id, qty, avg_cost, updated_at
+)
+SELECT $4::bigint, i.product_id, i.qty, COALESCE(i.cost / NULLIF(i.qty, 0), 0), NOW()
+FROM incoming i
+ON CONFLICT (warehouse_id, product_id)
+DO UPDATE SET
+    qty = inventory_balances.qty + EXCLUDED.qty,
+    avg_cost = CASE
+        WHEN inventory_balances.qty + EXCLUDED.qty <> 0
+        THEN (inventory_balances.qty * inventory_balances.avg_cost + EXCLUDED.qty * EXCLUDED.avg_cost)
+             / (inventory_balances.qty + EXCLUDED.qty)
+        ELSE 0
+    END,
+    updated_at = NOW()
+RETURNING warehouse_id, product_id, qty, avg_cost, updated_at
+`
+
+type ApplyInboundBalancesParams struct {
+	ProductIds  []int64   `json:"product_ids"`
+	Qtys        []float64 `json:"qtys"`
+	UnitCosts   []float64 `json:"unit_costs"`
+	WarehouseID int64     `json:"warehouse_id"`
+}
+
+func (q *Queries) ApplyInboundBalances(ctx context.Context, arg ApplyInboundBalancesParams) ([]InventoryBalance, error) {
+	rows, err := q.db.Query(ctx, applyInboundBalances,
+		arg.ProductIds,
+		arg.Qtys,
+		arg.UnitCosts,
+		arg.WarehouseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InventoryBalance
+	for rows.Next() {
+		var i InventoryBalance
+		if err := rows.Scan(
+			&i.WarehouseID,
+			&i.ProductID,
+			&i.Qty,
+			&i.AvgCost,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clearABCClasses = `-- name: ClearABCClasses :exec
+DELETE FROM inventory_abc_classes WHERE company_id = $1 AND warehouse_id = $2
+`
+
+type ClearABCClassesParams struct {
+	CompanyID   int64 `json:"company_id"`
+	WarehouseID int64 `json:"warehouse_id"`
+}
+
+func (q *Queries) ClearABCClasses(ctx context.Context, arg ClearABCClassesParams) error {
+	_, err := q.db.Exec(ctx, clearABCClasses, arg.CompanyID, arg.WarehouseID)
+	return err
+}
+
 const getBalanceForUpdate = `-- name: GetBalanceForUpdate :one
 SELECT warehouse_id, product_id, qty, avg_cost, updated_at 
 FROM inventory_balances 
@@ -103,6 +179,94 @@ func (q *Queries) GetStockCard(ctx context.Context, arg GetStockCardParams) ([]G
 	return items, nil
 }
 
+const insertABCClasses = `-- name: InsertABCClasses :exec
+INSERT INTO inventory_abc_classes (
+    company_id, warehouse_id, product_id, abc_class, consumption_value, cumulative_share, rank, period_start, period_end
+)
+SELECT $1::bigint, $2::bigint, c.product_id, c.abc_class, c.consumption_value, c.cumulative_share, c.rank,
+       $3::date, $4::date
+FROM unnest(
+    $5::bigint[], $6::text[], $7::float8[],
+    $8::float8[], $9::int[]
+) AS c(product_id, abc_class, consumption_value, cumulative_share, rank)
+`
+
+type InsertABCClassesParams struct {
+	CompanyID         int64       `json:"company_id"`
+	WarehouseID       int64       `json:"warehouse_id"`
+	PeriodStart       pgtype.Date `json:"period_start"`
+	PeriodEnd         pgtype.Date `json:"period_end"`
+	ProductIds        []int64     `json:"product_ids"`
+	Classes           []string    `json:"classes"`
+	ConsumptionValues []float64   `json:"consumption_values"`
+	CumulativeShares  []float64   `json:"cumulative_shares"`
+	Ranks             []int32     `json:"ranks"`
+}
+
+func (q *Queries) InsertABCClasses(ctx context.Context, arg InsertABCClassesParams) error {
+	_, err := q.db.Exec(ctx, insertABCClasses,
+		arg.CompanyID,
+		arg.WarehouseID,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.ProductIds,
+		arg.Classes,
+		arg.ConsumptionValues,
+		arg.CumulativeShares,
+		arg.Ranks,
+	)
+	return err
+}
+
+const insertCardEntries = `-- name: InsertCardEntries :exec
+INSERT INTO inventory_cards (
+    warehouse_id, product_id, tx_id, tx_code, tx_type,
+    qty_in, qty_out, balance_qty, unit_cost, balance_cost,
+    posted_at, note
+)
+SELECT $1::bigint, c.product_id, $2::bigint, $3::text, $4::text,
+       c.qty_in, c.qty_out, c.balance_qty, c.unit_cost, c.balance_cost,
+       $5::timestamptz, $6::text
+FROM unnest(
+    $7::bigint[], $8::float8[], $9::float8[],
+    $10::float8[], $11::float8[], $12::float8[]
+) WITH ORDINALITY AS c(product_id, qty_in, qty_out, balance_qty, unit_cost, balance_cost, ord)
+ORDER BY c.ord
+`
+
+type InsertCardEntriesParams struct {
+	WarehouseID  int64              `json:"warehouse_id"`
+	TxID         int64              `json:"tx_id"`
+	TxCode       string             `json:"tx_code"`
+	TxType       string             `json:"tx_type"`
+	PostedAt     pgtype.Timestamptz `json:"posted_at"`
+	Note         string             `json:"note"`
+	ProductIds   []int64            `json:"product_ids"`
+	QtyIns       []float64          `json:"qty_ins"`
+	QtyOuts      []float64          `json:"qty_outs"`
+	BalanceQtys  []float64          `json:"balance_qtys"`
+	UnitCosts    []float64          `json:"unit_costs"`
+	BalanceCosts []float64          `json:"balance_costs"`
+}
+
+func (q *Queries) InsertCardEntries(ctx context.Context, arg InsertCardEntriesParams) error {
+	_, err := q.db.Exec(ctx, insertCardEntries,
+		arg.WarehouseID,
+		arg.TxID,
+		arg.TxCode,
+		arg.TxType,
+		arg.PostedAt,
+		arg.Note,
+		arg.ProductIds,
+		arg.QtyIns,
+		arg.QtyOuts,
+		arg.BalanceQtys,
+		arg.UnitCosts,
+		arg.BalanceCosts,
+	)
+	return err
+}
+
 const insertCardEntry = `-- name: InsertCardEntry :exec
 INSERT INTO inventory_cards (
     warehouse_id, product_id, tx_id, tx_code, tx_type, 
@@ -212,6 +376,387 @@ func (q *Queries) InsertTransactionLine(ctx context.Context, arg InsertTransacti
 	return err
 }
 
+const insertTransactionLines = `-- name: InsertTransactionLines :exec
+INSERT INTO inventory_tx_lines (
+    tx_id, product_id, qty, unit_cost, src_warehouse_id, dst_warehouse_id
+)
+SELECT $1::bigint, l.product_id, l.qty, l.unit_cost,
+       NULLIF(l.src_warehouse_id, 0), NULLIF(l.dst_warehouse_id, 0)
+FROM unnest(
+    $2::bigint[], $3::float8[], $4::float8[],
+    $5::bigint[], $6::bigint[]
+) AS l(product_id, qty, unit_cost, src_warehouse_id, dst_warehouse_id)
+`
+
+type InsertTransactionLinesParams struct {
+	TxID            int64     `json:"tx_id"`
+	ProductIds      []int64   `json:"product_ids"`
+	Qtys            []float64 `json:"qtys"`
+	UnitCosts       []float64 `json:"unit_costs"`
+	SrcWarehouseIds []int64   `json:"src_warehouse_ids"`
+	DstWarehouseIds []int64   `json:"dst_warehouse_ids"`
+}
+
+func (q *Queries) InsertTransactionLines(ctx context.Context, arg InsertTransactionLinesParams) error {
+	_, err := q.db.Exec(ctx, insertTransactionLines,
+		arg.TxID,
+		arg.ProductIds,
+		arg.Qtys,
+		arg.UnitCosts,
+		arg.SrcWarehouseIds,
+		arg.DstWarehouseIds,
+	)
+	return err
+}
+
+const inventoryConsumptionValue = `-- name: InventoryConsumptionValue :many
+SELECT p.id AS product_id, p.sku, p.name AS product_name, p.category_id,
+       SUM(c.qty_out)::float8 AS qty,
+       SUM(c.qty_out * c.unit_cost)::float8 AS value
+FROM inventory_cards c
+JOIN products p ON p.id = c.product_id
+WHERE c.tx_type IN ('OUT', 'ADJUST')
+  AND c.qty_out > 0
+  AND c.posted_at >= $1::timestamptz
+  AND c.posted_at <= $2::timestamptz
+  AND ($3::bigint IS NULL OR c.warehouse_id = $3::bigint)
+  AND c.warehouse_id IN (
+      SELECT w.id FROM warehouses w JOIN branches b ON b.id = w.branch_id
+      WHERE b.company_id = $4::bigint)
+GROUP BY p.id, p.sku, p.name, p.category_id
+HAVING SUM(c.qty_out) > 0
+ORDER BY value DESC, p.sku
+`
+
+type InventoryConsumptionValueParams struct {
+	FromTs      pgtype.Timestamptz `json:"from_ts"`
+	ToTs        pgtype.Timestamptz `json:"to_ts"`
+	WarehouseID pgtype.Int8        `json:"warehouse_id"`
+	CompanyID   int64              `json:"company_id"`
+}
+
+type InventoryConsumptionValueRow struct {
+	ProductID   int64   `json:"product_id"`
+	Sku         string  `json:"sku"`
+	ProductName string  `json:"product_name"`
+	CategoryID  int64   `json:"category_id"`
+	Qty         float64 `json:"qty"`
+	Value       float64 `json:"value"`
+}
+
+// Issued quantity and value per product over a period. Deliveries post as
+// negative adjustments; transfers only move stock and are not consumption.
+func (q *Queries) InventoryConsumptionValue(ctx context.Context, arg InventoryConsumptionValueParams) ([]InventoryConsumptionValueRow, error) {
+	rows, err := q.db.Query(ctx, inventoryConsumptionValue,
+		arg.FromTs,
+		arg.ToTs,
+		arg.WarehouseID,
+		arg.CompanyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InventoryConsumptionValueRow
+	for rows.Next() {
+		var i InventoryConsumptionValueRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.Sku,
+			&i.ProductName,
+			&i.CategoryID,
+			&i.Qty,
+			&i.Value,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const inventoryGLBalanceAsOf = `-- name: InventoryGLBalanceAsOf :one
+SELECT COALESCE(SUM(jl.debit - jl.credit), 0)::float8 AS balance
+FROM journal_lines jl
+JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
+JOIN accounts a ON a.id = jl.account_id
+JOIN account_mappings am ON am.module = 'GRN' AND am.key = 'grn.inventory'
+JOIN accounts ma ON ma.id = am.account_id AND ma.code = a.code
+WHERE je.date <= $1::date
+  AND ($2::bigint IS NULL OR jl.dim_warehouse_id = $2::bigint)
+  AND ($3::bigint IS NULL OR COALESCE(jl.dim_company_id, a.company_id) = $3::bigint)
+`
+
+type InventoryGLBalanceAsOfParams struct {
+	AsOf        pgtype.Date `json:"as_of"`
+	WarehouseID pgtype.Int8 `json:"warehouse_id"`
+	CompanyID   pgtype.Int8 `json:"company_id"`
+}
+
+// Balance of the mapped inventory asset account, for tie-out with the valuation.
+// The account is matched by code so company charts count too; a line belongs
+// to its company dimension, falling back to the company owning its account.
+func (q *Queries) InventoryGLBalanceAsOf(ctx context.Context, arg InventoryGLBalanceAsOfParams) (float64, error) {
+	row := q.db.QueryRow(ctx, inventoryGLBalanceAsOf, arg.AsOf, arg.WarehouseID, arg.CompanyID)
+	var balance float64
+	err := row.Scan(&balance)
+	return balance, err
+}
+
+const inventoryValuationAsOf = `-- name: InventoryValuationAsOf :many
+WITH last_card AS (
+    SELECT DISTINCT ON (c.warehouse_id, c.product_id)
+           c.warehouse_id, c.product_id, c.balance_qty, c.balance_cost
+    FROM inventory_cards c
+    WHERE c.posted_at <= $1::timestamptz
+      AND ($2::bigint IS NULL OR c.warehouse_id = $2::bigint)
+    ORDER BY c.warehouse_id, c.product_id, c.posted_at DESC, c.id DESC
+)
+SELECT lc.warehouse_id, w.code AS warehouse_code, w.name AS warehouse_name,
+       lc.product_id, p.sku, p.name AS product_name, p.category_id,
+       lc.balance_qty::float8 AS qty,
+       lc.balance_cost::float8 AS avg_cost,
+       (lc.balance_qty * lc.balance_cost)::float8 AS value
+FROM last_card lc
+JOIN warehouses w ON w.id = lc.warehouse_id
+JOIN products p ON p.id = lc.product_id
+WHERE lc.balance_qty <> 0
+  AND ($3::bigint IS NULL OR p.category_id = $3::bigint)
+  AND ($4::bigint IS NULL
+       OR w.branch_id IN (SELECT b.id FROM branches b WHERE b.company_id = $4::bigint))
+ORDER BY w.code, p.sku
+`
+
+type InventoryValuationAsOfParams struct {
+	AsOf        pgtype.Timestamptz `json:"as_of"`
+	WarehouseID pgtype.Int8        `json:"warehouse_id"`
+	CategoryID  pgtype.Int8        `json:"category_id"`
+	CompanyID   pgtype.Int8        `json:"company_id"`
+}
+
+type InventoryValuationAsOfRow struct {
+	WarehouseID   int64   `json:"warehouse_id"`
+	WarehouseCode string  `json:"warehouse_code"`
+	WarehouseName string  `json:"warehouse_name"`
+	ProductID     int64   `json:"product_id"`
+	Sku           string  `json:"sku"`
+	ProductName   string  `json:"product_name"`
+	CategoryID    int64   `json:"category_id"`
+	Qty           float64 `json:"qty"`
+	AvgCost       float64 `json:"avg_cost"`
+	Value         float64 `json:"value"`
+}
+
+// Latest stock card per warehouse/product at or before as_of; cards carry the
+// running quantity and moving-average cost so no full replay is needed.
+func (q *Queries) InventoryValuationAsOf(ctx context.Context, arg InventoryValuationAsOfParams) ([]InventoryValuationAsOfRow, error) {
+	rows, err := q.db.Query(ctx, inventoryValuationAsOf, arg.AsOf, arg.WarehouseID, arg.CategoryID, arg.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InventoryValuationAsOfRow
+	for rows.Next() {
+		var i InventoryValuationAsOfRow
+		if err := rows.Scan(
+			&i.WarehouseID,
+			&i.WarehouseCode,
+			&i.WarehouseName,
+			&i.ProductID,
+			&i.Sku,
+			&i.ProductName,
+			&i.CategoryID,
+			&i.Qty,
+			&i.AvgCost,
+			&i.Value,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listABCClasses = `-- name: ListABCClasses :many
+SELECT product_id, abc_class::text AS abc_class, consumption_value::float8 AS consumption_value, rank,
+       period_start, period_end, classified_at
+FROM inventory_abc_classes
+WHERE company_id = $1 AND warehouse_id = $2
+ORDER BY rank
+`
+
+type ListABCClassesParams struct {
+	CompanyID   int64 `json:"company_id"`
+	WarehouseID int64 `json:"warehouse_id"`
+}
+
+type ListABCClassesRow struct {
+	ProductID        int64              `json:"product_id"`
+	AbcClass         string             `json:"abc_class"`
+	ConsumptionValue float64            `json:"consumption_value"`
+	Rank             int32              `json:"rank"`
+	PeriodStart      pgtype.Date        `json:"period_start"`
+	PeriodEnd        pgtype.Date        `json:"period_end"`
+	ClassifiedAt     pgtype.Timestamptz `json:"classified_at"`
+}
+
+func (q *Queries) ListABCClasses(ctx context.Context, arg ListABCClassesParams) ([]ListABCClassesRow, error) {
+	rows, err := q.db.Query(ctx, listABCClasses, arg.CompanyID, arg.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListABCClassesRow
+	for rows.Next() {
+		var i ListABCClassesRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.AbcClass,
+			&i.ConsumptionValue,
+			&i.Rank,
+			&i.PeriodStart,
+			&i.PeriodEnd,
+			&i.ClassifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCostCards = `-- name: ListCostCards :many
+SELECT id, tx_code, tx_type, posted_at, qty_in, qty_out, balance_qty, unit_cost, balance_cost, note
+FROM inventory_cards
+WHERE warehouse_id = $1
+  AND product_id = $2
+  AND (posted_at >= $3
+       OR id = (SELECT o.id FROM inventory_cards o
+                WHERE o.warehouse_id = $1
+                  AND o.product_id = $2
+                  AND o.posted_at < $3
+                ORDER BY o.posted_at DESC, o.id DESC
+                LIMIT 1))
+ORDER BY posted_at ASC, id ASC
+`
+
+type ListCostCardsParams struct {
+	WarehouseID int64              `json:"warehouse_id"`
+	ProductID   int64              `json:"product_id"`
+	FromDate    pgtype.Timestamptz `json:"from_date"`
+}
+
+type ListCostCardsRow struct {
+	ID          int64              `json:"id"`
+	TxCode      string             `json:"tx_code"`
+	TxType      string             `json:"tx_type"`
+	PostedAt    pgtype.Timestamptz `json:"posted_at"`
+	QtyIn       pgtype.Numeric     `json:"qty_in"`
+	QtyOut      pgtype.Numeric     `json:"qty_out"`
+	BalanceQty  pgtype.Numeric     `json:"balance_qty"`
+	UnitCost    pgtype.Numeric     `json:"unit_cost"`
+	BalanceCost pgtype.Numeric     `json:"balance_cost"`
+	Note        string             `json:"note"`
+}
+
+// Cards replayed by the cost recalculation: the last card before from_date
+// as the opening balance, then every card from from_date on.
+func (q *Queries) ListCostCards(ctx context.Context, arg ListCostCardsParams) ([]ListCostCardsRow, error) {
+	rows, err := q.db.Query(ctx, listCostCards, arg.WarehouseID, arg.ProductID, arg.FromDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCostCardsRow
+	for rows.Next() {
+		var i ListCostCardsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TxCode,
+			&i.TxType,
+			&i.PostedAt,
+			&i.QtyIn,
+			&i.QtyOut,
+			&i.BalanceQty,
+			&i.UnitCost,
+			&i.BalanceCost,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockBalancesForUpdate = `-- name: LockBalancesForUpdate :many
+SELECT warehouse_id, product_id, qty, avg_cost, updated_at
+FROM inventory_balances
+WHERE warehouse_id = $1 AND product_id = ANY($2::bigint[])
+ORDER BY product_id
+FOR UPDATE
+`
+
+type LockBalancesForUpdateParams struct {
+	WarehouseID int64   `json:"warehouse_id"`
+	ProductIds  []int64 `json:"product_ids"`
+}
+
+func (q *Queries) LockBalancesForUpdate(ctx context.Context, arg LockBalancesForUpdateParams) ([]InventoryBalance, error) {
+	rows, err := q.db.Query(ctx, lockBalancesForUpdate, arg.WarehouseID, arg.ProductIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InventoryBalance
+	for rows.Next() {
+		var i InventoryBalance
+		if err := rows.Scan(
+			&i.WarehouseID,
+			&i.ProductID,
+			&i.Qty,
+			&i.AvgCost,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCardCost = `-- name: UpdateCardCost :exec
+UPDATE inventory_cards
+SET unit_cost = $2, balance_cost = $3
+WHERE id = $1
+`
+
+type UpdateCardCostParams struct {
+	ID          int64          `json:"id"`
+	UnitCost    pgtype.Numeric `json:"unit_cost"`
+	BalanceCost pgtype.Numeric `json:"balance_cost"`
+}
+
+func (q *Queries) UpdateCardCost(ctx context.Context, arg UpdateCardCostParams) error {
+	_, err := q.db.Exec(ctx, updateCardCost, arg.ID, arg.UnitCost, arg.BalanceCost)
+	return err
+}
+
 const upsertBalance = `-- name: UpsertBalance :exec
 INSERT INTO inventory_balances (
     warehouse_id, product_id, qty, avg_cost, updated_at
@@ -49,32 +49,35 @@ func (q *Queries) CreateBranch(ctx context.Context, arg CreateBranchParams) (Bra
 }
 
 const createCategory = `-- name: CreateCategory :one
-INSERT INTO categories (code, name, created_at, updated_at) 
-VALUES ($1, $2, $3, $4) 
-RETURNING id, code, name, parent_id, company_id, created_at, updated_at
+INSERT INTO categories (code, name, attribute_schema, created_at, updated_at) 
+VALUES ($1, $2, $3, $4, $5) 
+RETURNING id, code, name, parent_id, company_id, created_at, updated_at, attribute_schema
 `
 
 type CreateCategoryParams struct {
-	Code      string             `json:"code"`
-	Name      string             `json:"name"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	Code            string             `json:"code"`
+	Name            string             `json:"name"`
+	AttributeSchema []byte             `json:"attribute_schema"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
 }
 
 type CreateCategoryRow struct {
-	ID        int64              `json:"id"`
-	Code      string             `json:"code"`
-	Name      string             `json:"name"`
-	ParentID  pgtype.Int8        `json:"parent_id"`
-	CompanyID pgtype.Int8        `json:"company_id"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID              int64              `json:"id"`
+	Code            string             `json:"code"`
+	Name            string             `json:"name"`
+	ParentID        pgtype.Int8        `json:"parent_id"`
+	CompanyID       pgtype.Int8        `json:"company_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	AttributeSchema []byte             `json:"attribute_schema"`
 }
 
 func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (CreateCategoryRow, error) {
 	row := q.db.QueryRow(ctx, createCategory,
 		arg.Code,
 		arg.Name,
+		arg.AttributeSchema,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -87,6 +90,7 @@ func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams)
 		&i.CompanyID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AttributeSchema,
 	)
 	return i, err
 }
@@ -129,19 +133,21 @@ func (q *Queries) CreateCompany(ctx context.Context, arg CreateCompanyParams) (C
 }
 
 const createProduct = `-- name: CreateProduct :one
-INSERT INTO products (sku, name, category_id, unit_id, price, tax_id, is_active) 
-VALUES ($1, $2, $3, $4, $5, $6, $7) 
-RETURNING id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, company_id
+INSERT INTO products (sku, name, category_id, unit_id, price, tax_id, is_active, attributes, track_serial) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
+RETURNING id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, company_id, attributes, updated_at, track_serial
 `
 
 type CreateProductParams struct {
-	Sku        string         `json:"sku"`
-	Name       string         `json:"name"`
-	CategoryID int64          `json:"category_id"`
-	UnitID     int64          `json:"unit_id"`
-	Price      pgtype.Numeric `json:"price"`
-	TaxID      pgtype.Int8    `json:"tax_id"`
-	IsActive   bool           `json:"is_active"`
+	Sku         string         `json:"sku"`
+	Name        string         `json:"name"`
+	CategoryID  int64          `json:"category_id"`
+	UnitID      int64          `json:"unit_id"`
+	Price       pgtype.Numeric `json:"price"`
+	TaxID       pgtype.Int8    `json:"tax_id"`
+	IsActive    bool           `json:"is_active"`
+	Attributes  []byte         `json:"attributes"`
+	TrackSerial bool           `json:"track_serial"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
@@ -153,6 +159,8 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		arg.Price,
 		arg.TaxID,
 		arg.IsActive,
+		arg.Attributes,
+		arg.TrackSerial,
 	)
 	var i Product
 	err := row.Scan(
@@ -166,23 +174,30 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		&i.IsActive,
 		&i.DeletedAt,
 		&i.CompanyID,
+		&i.Attributes,
+		&i.UpdatedAt,
+		&i.TrackSerial,
 	)
 	return i, err
 }
 
 const createSupplier = `-- name: CreateSupplier :one
-INSERT INTO suppliers (code, name, phone, email, address, is_active) 
-VALUES ($1, $2, $3, $4, $5, $6) 
-RETURNING id, code, name, phone, email, address, is_active, company_id
+INSERT INTO suppliers (code, name, phone, email, address, is_active, tax_id, country, payment_terms_days, withholding_tax_id) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
+RETURNING id, code, name, phone, email, address, is_active, company_id, tax_id, country, payment_terms_days, withholding_tax_id
 `
 
 type CreateSupplierParams struct {
-	Code     string `json:"code"`
-	Name     string `json:"name"`
-	Phone    string `json:"phone"`
-	Email    string `json:"email"`
-	Address  string `json:"address"`
-	IsActive bool   `json:"is_active"`
+	Code             string      `json:"code"`
+	Name             string      `json:"name"`
+	Phone            string      `json:"phone"`
+	Email            string      `json:"email"`
+	Address          string      `json:"address"`
+	IsActive         bool        `json:"is_active"`
+	TaxID            string      `json:"tax_id"`
+	Country          string      `json:"country"`
+	PaymentTermsDays int32       `json:"payment_terms_days"`
+	WithholdingTaxID pgtype.Int8 `json:"withholding_tax_id"`
 }
 
 func (q *Queries) CreateSupplier(ctx context.Context, arg CreateSupplierParams) (Supplier, error) {
@@ -193,6 +208,10 @@ func (q *Queries) CreateSupplier(ctx context.Context, arg CreateSupplierParams)
 		arg.Email,
 		arg.Address,
 		arg.IsActive,
+		arg.TaxID,
+		arg.Country,
+		arg.PaymentTermsDays,
+		arg.WithholdingTaxID,
 	)
 	var i Supplier
 	err := row.Scan(
@@ -204,28 +223,39 @@ func (q *Queries) CreateSupplier(ctx context.Context, arg CreateSupplierParams)
 		&i.Address,
 		&i.IsActive,
 		&i.CompanyID,
+		&i.TaxID,
+		&i.Country,
+		&i.PaymentTermsDays,
+		&i.WithholdingTaxID,
 	)
 	return i, err
 }
 
 const createTax = `-- name: CreateTax :one
-INSERT INTO taxes (code, name, rate) VALUES ($1, $2, $3) RETURNING id, code, name, rate
+INSERT INTO taxes (code, name, rate, is_withholding) VALUES ($1, $2, $3, $4) RETURNING id, code, name, rate, is_withholding
 `
 
 type CreateTaxParams struct {
-	Code string         `json:"code"`
-	Name string         `json:"name"`
-	Rate pgtype.Numeric `json:"rate"`
+	Code          string         `json:"code"`
+	Name          string         `json:"name"`
+	Rate          pgtype.Numeric `json:"rate"`
+	IsWithholding bool           `json:"is_withholding"`
 }
 
 func (q *Queries) CreateTax(ctx context.Context, arg CreateTaxParams) (Tax, error) {
-	row := q.db.QueryRow(ctx, createTax, arg.Code, arg.Name, arg.Rate)
+	row := q.db.QueryRow(ctx, createTax,
+		arg.Code,
+		arg.Name,
+		arg.Rate,
+		arg.IsWithholding,
+	)
 	var i Tax
 	err := row.Scan(
 		&i.ID,
 		&i.Code,
 		&i.Name,
 		&i.Rate,
+		&i.IsWithholding,
 	)
 	return i, err
 }
@@ -372,7 +402,8 @@ func (q *Queries) DeleteWarehouse(ctx context.Context, id int64) error {
 
 const getBranch = `-- name: GetBranch :one
 
-SELECT id, company_id, code, name, address, created_at, updated_at 
+SELECT id, company_id, code, name, address, created_at, updated_at, gl_dim_company_id,
+       default_warehouse_id
 FROM branches WHERE id = $1
 `
 
@@ -390,23 +421,26 @@ func (q *Queries) GetBranch(ctx context.Context, id int64) (Branch, error) {
 		&i.Address,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.GlDimCompanyID,
+		&i.DefaultWarehouseID,
 	)
 	return i, err
 }
 
 const getCategory = `-- name: GetCategory :one
 
-SELECT id, code, name, parent_id, company_id, created_at, updated_at FROM categories WHERE id = $1
+SELECT id, code, name, parent_id, company_id, created_at, updated_at, attribute_schema FROM categories WHERE id = $1
 `
 
 type GetCategoryRow struct {
-	ID        int64              `json:"id"`
-	Code      string             `json:"code"`
-	Name      string             `json:"name"`
-	ParentID  pgtype.Int8        `json:"parent_id"`
-	CompanyID pgtype.Int8        `json:"company_id"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID              int64              `json:"id"`
+	Code            string             `json:"code"`
+	Name            string             `json:"name"`
+	ParentID        pgtype.Int8        `json:"parent_id"`
+	CompanyID       pgtype.Int8        `json:"company_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	AttributeSchema []byte             `json:"attribute_schema"`
 }
 
 // =============================================================================
@@ -423,19 +457,37 @@ func (q *Queries) GetCategory(ctx context.Context, id int64) (GetCategoryRow, er
 		&i.CompanyID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AttributeSchema,
+	)
+	return i, err
+}
+
+const getCompanyDueDatePolicy = `-- name: GetCompanyDueDatePolicy :one
+SELECT company_id, business_days, holiday_calendar, updated_at
+FROM company_due_date_policies WHERE company_id = $1
+`
+
+func (q *Queries) GetCompanyDueDatePolicy(ctx context.Context, companyID int64) (CompanyDueDatePolicy, error) {
+	row := q.db.QueryRow(ctx, getCompanyDueDatePolicy, companyID)
+	var i CompanyDueDatePolicy
+	err := row.Scan(
+		&i.CompanyID,
+		&i.BusinessDays,
+		&i.HolidayCalendar,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const getProduct = `-- name: GetProduct :one
 
-SELECT id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, company_id 
+SELECT id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, company_id, attributes, updated_at, track_serial 
 FROM products WHERE id = $1
 `
 
 // =============================================================================
-// PRODUCTS (id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at)
-// Note: uses 'sku' instead of 'code', no 'cost', no created/updated_at
+// PRODUCTS (id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, updated_at, track_serial)
+// Note: uses 'sku' instead of 'code', no 'cost', no created_at
 // =============================================================================
 func (q *Queries) GetProduct(ctx context.Context, id int64) (Product, error) {
 	row := q.db.QueryRow(ctx, getProduct, id)
@@ -451,13 +503,16 @@ func (q *Queries) GetProduct(ctx context.Context, id int64) (Product, error) {
 		&i.IsActive,
 		&i.DeletedAt,
 		&i.CompanyID,
+		&i.Attributes,
+		&i.UpdatedAt,
+		&i.TrackSerial,
 	)
 	return i, err
 }
 
 const getSupplier = `-- name: GetSupplier :one
 
-SELECT id, code, name, phone, email, address, is_active, company_id 
+SELECT id, code, name, phone, email, address, is_active, company_id, tax_id, country, payment_terms_days, withholding_tax_id 
 FROM suppliers WHERE id = $1
 `
 
@@ -476,17 +531,21 @@ func (q *Queries) GetSupplier(ctx context.Context, id int64) (Supplier, error) {
 		&i.Address,
 		&i.IsActive,
 		&i.CompanyID,
+		&i.TaxID,
+		&i.Country,
+		&i.PaymentTermsDays,
+		&i.WithholdingTaxID,
 	)
 	return i, err
 }
 
 const getTax = `-- name: GetTax :one
 
-SELECT id, code, name, rate FROM taxes WHERE id = $1
+SELECT id, code, name, rate, is_withholding FROM taxes WHERE id = $1
 `
 
 // =============================================================================
-// TAXES (id, code, name, rate) - no timestamps in schema
+// TAXES (id, code, name, rate, is_withholding) - no timestamps in schema
 // =============================================================================
 func (q *Queries) GetTax(ctx context.Context, id int64) (Tax, error) {
 	row := q.db.QueryRow(ctx, getTax, id)
@@ -496,6 +555,7 @@ func (q *Queries) GetTax(ctx context.Context, id int64) (Tax, error) {
 		&i.Code,
 		&i.Name,
 		&i.Rate,
+		&i.IsWithholding,
 	)
 	return i, err
 }
@@ -523,7 +583,7 @@ func (q *Queries) GetUnit(ctx context.Context, id int64) (Unit, error) {
 
 const getWarehouse = `-- name: GetWarehouse :one
 
-SELECT id, branch_id, code, name, address, created_at, updated_at 
+SELECT id, branch_id, code, name, address, created_at, updated_at, gl_dim_company_id, gl_dim_branch_id
 FROM warehouses WHERE id = $1
 `
 
@@ -541,10 +601,59 @@ func (q *Queries) GetWarehouse(ctx context.Context, id int64) (Warehouse, error)
 		&i.Address,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.GlDimCompanyID,
+		&i.GlDimBranchID,
 	)
 	return i, err
 }
 
+const getWarehouseCompanyID = `-- name: GetWarehouseCompanyID :one
+SELECT b.company_id
+FROM warehouses w
+JOIN branches b ON b.id = w.branch_id
+WHERE w.id = $1
+`
+
+func (q *Queries) GetWarehouseCompanyID(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getWarehouseCompanyID, id)
+	var company_id int64
+	err := row.Scan(&company_id)
+	return company_id, err
+}
+
+const getWarehouseGLDimensions = `-- name: GetWarehouseGLDimensions :one
+SELECT COALESCE(w.gl_dim_company_id, b.gl_dim_company_id, b.company_id)::BIGINT AS company_id,
+       COALESCE(w.gl_dim_branch_id, b.id)::BIGINT AS branch_id,
+       w.id AS warehouse_id
+FROM warehouses w
+JOIN branches b ON b.id = w.branch_id
+WHERE w.id = $1
+`
+
+type GetWarehouseGLDimensionsRow struct {
+	CompanyID   int64 `json:"company_id"`
+	BranchID    int64 `json:"branch_id"`
+	WarehouseID int64 `json:"warehouse_id"`
+}
+
+func (q *Queries) GetWarehouseGLDimensions(ctx context.Context, id int64) (GetWarehouseGLDimensionsRow, error) {
+	row := q.db.QueryRow(ctx, getWarehouseGLDimensions, id)
+	var i GetWarehouseGLDimensionsRow
+	err := row.Scan(&i.CompanyID, &i.BranchID, &i.WarehouseID)
+	return i, err
+}
+
+const holidayCalendarExists = `-- name: HolidayCalendarExists :one
+SELECT EXISTS (SELECT 1 FROM holiday_calendar_days WHERE calendar_code = $1)
+`
+
+func (q *Queries) HolidayCalendarExists(ctx context.Context, calendarCode string) (bool, error) {
+	row := q.db.QueryRow(ctx, holidayCalendarExists, calendarCode)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const mdGetCompany = `-- name: MdGetCompany :one
 
 SELECT id, code, name, address, tax_id, created_at, updated_at 
@@ -569,6 +678,89 @@ func (q *Queries) MdGetCompany(ctx context.Context, id int64) (Company, error) {
 	return i, err
 }
 
+const setBranchDefaultWarehouse = `-- name: SetBranchDefaultWarehouse :exec
+UPDATE branches
+SET default_warehouse_id = $1, updated_at = $2
+WHERE id = $3
+`
+
+type SetBranchDefaultWarehouseParams struct {
+	DefaultWarehouseID pgtype.Int8        `json:"default_warehouse_id"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+	ID                 int64              `json:"id"`
+}
+
+func (q *Queries) SetBranchDefaultWarehouse(ctx context.Context, arg SetBranchDefaultWarehouseParams) error {
+	_, err := q.db.Exec(ctx, setBranchDefaultWarehouse, arg.DefaultWarehouseID, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setBranchGLDimensions = `-- name: SetBranchGLDimensions :exec
+UPDATE branches
+SET gl_dim_company_id = $1, updated_at = $2
+WHERE id = $3
+`
+
+type SetBranchGLDimensionsParams struct {
+	GlDimCompanyID pgtype.Int8        `json:"gl_dim_company_id"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	ID             int64              `json:"id"`
+}
+
+func (q *Queries) SetBranchGLDimensions(ctx context.Context, arg SetBranchGLDimensionsParams) error {
+	_, err := q.db.Exec(ctx, setBranchGLDimensions, arg.GlDimCompanyID, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setCompanyDueDatePolicy = `-- name: SetCompanyDueDatePolicy :exec
+INSERT INTO company_due_date_policies (company_id, business_days, holiday_calendar, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (company_id) DO UPDATE
+SET business_days = EXCLUDED.business_days,
+    holiday_calendar = EXCLUDED.holiday_calendar,
+    updated_at = EXCLUDED.updated_at
+`
+
+type SetCompanyDueDatePolicyParams struct {
+	CompanyID       int64              `json:"company_id"`
+	BusinessDays    bool               `json:"business_days"`
+	HolidayCalendar string             `json:"holiday_calendar"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) SetCompanyDueDatePolicy(ctx context.Context, arg SetCompanyDueDatePolicyParams) error {
+	_, err := q.db.Exec(ctx, setCompanyDueDatePolicy,
+		arg.CompanyID,
+		arg.BusinessDays,
+		arg.HolidayCalendar,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const setWarehouseGLDimensions = `-- name: SetWarehouseGLDimensions :exec
+UPDATE warehouses
+SET gl_dim_company_id = $1, gl_dim_branch_id = $2, updated_at = $3
+WHERE id = $4
+`
+
+type SetWarehouseGLDimensionsParams struct {
+	GlDimCompanyID pgtype.Int8        `json:"gl_dim_company_id"`
+	GlDimBranchID  pgtype.Int8        `json:"gl_dim_branch_id"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	ID             int64              `json:"id"`
+}
+
+func (q *Queries) SetWarehouseGLDimensions(ctx context.Context, arg SetWarehouseGLDimensionsParams) error {
+	_, err := q.db.Exec(ctx, setWarehouseGLDimensions,
+		arg.GlDimCompanyID,
+		arg.GlDimBranchID,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
 const softDeleteProduct = `-- name: SoftDeleteProduct :exec
 UPDATE products SET deleted_at = $1 WHERE id = $2
 `
@@ -611,20 +803,22 @@ func (q *Queries) UpdateBranch(ctx context.Context, arg UpdateBranchParams) erro
 }
 
 const updateCategory = `-- name: UpdateCategory :exec
-UPDATE categories SET code = $1, name = $2, updated_at = $3 WHERE id = $4
+UPDATE categories SET code = $1, name = $2, attribute_schema = $3, updated_at = $4 WHERE id = $5
 `
 
 type UpdateCategoryParams struct {
-	Code      string             `json:"code"`
-	Name      string             `json:"name"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	ID        int64              `json:"id"`
+	Code            string             `json:"code"`
+	Name            string             `json:"name"`
+	AttributeSchema []byte             `json:"attribute_schema"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	ID              int64              `json:"id"`
 }
 
 func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error {
 	_, err := q.db.Exec(ctx, updateCategory,
 		arg.Code,
 		arg.Name,
+		arg.AttributeSchema,
 		arg.UpdatedAt,
 		arg.ID,
 	)
@@ -658,25 +852,30 @@ func (q *Queries) UpdateCompany(ctx context.Context, arg UpdateCompanyParams) er
 	return err
 }
 
-const updateProduct = `-- name: UpdateProduct :exec
+const updateProduct = `-- name: UpdateProduct :execrows
 UPDATE products 
-SET sku = $1, name = $2, category_id = $3, unit_id = $4, price = $5, tax_id = $6, is_active = $7 
-WHERE id = $8
+SET sku = $1, name = $2, category_id = $3, unit_id = $4, price = $5, tax_id = $6, is_active = $7, attributes = $8, track_serial = $9, updated_at = NOW() 
+WHERE id = $10
+  AND ($11::timestamptz IS NULL OR updated_at = $11::timestamptz)
 `
 
 type UpdateProductParams struct {
-	Sku        string         `json:"sku"`
-	Name       string         `json:"name"`
-	CategoryID int64          `json:"category_id"`
-	UnitID     int64          `json:"unit_id"`
-	Price      pgtype.Numeric `json:"price"`
-	TaxID      pgtype.Int8    `json:"tax_id"`
-	IsActive   bool           `json:"is_active"`
-	ID         int64          `json:"id"`
-}
-
-func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) error {
-	_, err := q.db.Exec(ctx, updateProduct,
+	Sku               string             `json:"sku"`
+	Name              string             `json:"name"`
+	CategoryID        int64              `json:"category_id"`
+	UnitID            int64              `json:"unit_id"`
+	Price             pgtype.Numeric     `json:"price"`
+	TaxID             pgtype.Int8        `json:"tax_id"`
+	IsActive          bool               `json:"is_active"`
+	Attributes        []byte             `json:"attributes"`
+	TrackSerial       bool               `json:"track_serial"`
+	ID                int64              `json:"id"`
+	ExpectedUpdatedAt pgtype.Timestamptz `json:"expected_updated_at"`
+}
+
+// A NULL expected_updated_at skips the optimistic lock.
+func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateProduct,
 		arg.Sku,
 		arg.Name,
 		arg.CategoryID,
@@ -684,25 +883,35 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) er
 		arg.Price,
 		arg.TaxID,
 		arg.IsActive,
+		arg.Attributes,
+		arg.TrackSerial,
 		arg.ID,
+		arg.ExpectedUpdatedAt,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const updateSupplier = `-- name: UpdateSupplier :exec
 UPDATE suppliers 
-SET code = $1, name = $2, phone = $3, email = $4, address = $5, is_active = $6 
-WHERE id = $7
+SET code = $1, name = $2, phone = $3, email = $4, address = $5, is_active = $6, tax_id = $7, country = $8, payment_terms_days = $9, withholding_tax_id = $10 
+WHERE id = $11
 `
 
 type UpdateSupplierParams struct {
-	Code     string `json:"code"`
-	Name     string `json:"name"`
-	Phone    string `json:"phone"`
-	Email    string `json:"email"`
-	Address  string `json:"address"`
-	IsActive bool   `json:"is_active"`
-	ID       int64  `json:"id"`
+	Code             string      `json:"code"`
+	Name             string      `json:"name"`
+	Phone            string      `json:"phone"`
+	Email            string      `json:"email"`
+	Address          string      `json:"address"`
+	IsActive         bool        `json:"is_active"`
+	TaxID            string      `json:"tax_id"`
+	Country          string      `json:"country"`
+	PaymentTermsDays int32       `json:"payment_terms_days"`
+	WithholdingTaxID pgtype.Int8 `json:"withholding_tax_id"`
+	ID               int64       `json:"id"`
 }
 
 func (q *Queries) UpdateSupplier(ctx context.Context, arg UpdateSupplierParams) error {
@@ -713,20 +922,25 @@ func (q *Queries) UpdateSupplier(ctx context.Context, arg UpdateSupplierParams)
 		arg.Email,
 		arg.Address,
 		arg.IsActive,
+		arg.TaxID,
+		arg.Country,
+		arg.PaymentTermsDays,
+		arg.WithholdingTaxID,
 		arg.ID,
 	)
 	return err
 }
 
 const updateTax = `-- name: UpdateTax :exec
-UPDATE taxes SET code = $1, name = $2, rate = $3 WHERE id = $4
+UPDATE taxes SET code = $1, name = $2, rate = $3, is_withholding = $4 WHERE id = $5
 `
 
 type UpdateTaxParams struct {
-	Code string         `json:"code"`
-	Name string         `json:"name"`
-	Rate pgtype.Numeric `json:"rate"`
-	ID   int64          `json:"id"`
+	Code          string         `json:"code"`
+	Name          string         `json:"name"`
+	Rate          pgtype.Numeric `json:"rate"`
+	IsWithholding bool           `json:"is_withholding"`
+	ID            int64          `json:"id"`
 }
 
 func (q *Queries) UpdateTax(ctx context.Context, arg UpdateTaxParams) error {
@@ -734,6 +948,7 @@ func (q *Queries) UpdateTax(ctx context.Context, arg UpdateTaxParams) error {
 		arg.Code,
 		arg.Name,
 		arg.Rate,
+		arg.IsWithholding,
 		arg.ID,
 	)
 	return err
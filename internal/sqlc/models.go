@@ -591,27 +591,31 @@ type AccountingPeriod struct {
 }
 
 type ApInvoice struct {
-	ID         int64              `json:"id"`
-	Number     string             `json:"number"`
-	SupplierID int64              `json:"supplier_id"`
-	GrnID      pgtype.Int8        `json:"grn_id"`
-	Currency   string             `json:"currency"`
-	Total      pgtype.Numeric     `json:"total"`
-	Status     string             `json:"status"`
-	IssuedAt   pgtype.Date        `json:"issued_at"`
-	DueAt      pgtype.Date        `json:"due_at"`
-	CreatedAt  pgtype.Timestamptz `json:"created_at"`
-	CompanyID  pgtype.Int8        `json:"company_id"`
-	Subtotal   pgtype.Numeric     `json:"subtotal"`
-	TaxAmount  pgtype.Numeric     `json:"tax_amount"`
-	PostedAt   pgtype.Timestamptz `json:"posted_at"`
-	PostedBy   pgtype.Int8        `json:"posted_by"`
-	VoidedAt   pgtype.Timestamptz `json:"voided_at"`
-	VoidedBy   pgtype.Int8        `json:"voided_by"`
-	VoidReason pgtype.Text        `json:"void_reason"`
-	CreatedBy  pgtype.Int8        `json:"created_by"`
-	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
-	PoID       pgtype.Int8        `json:"po_id"`
+	ID                int64              `json:"id"`
+	Number            string             `json:"number"`
+	SupplierID        int64              `json:"supplier_id"`
+	GrnID             pgtype.Int8        `json:"grn_id"`
+	Currency          string             `json:"currency"`
+	Total             pgtype.Numeric     `json:"total"`
+	Status            string             `json:"status"`
+	IssuedAt          pgtype.Date        `json:"issued_at"`
+	DueAt             pgtype.Date        `json:"due_at"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	CompanyID         pgtype.Int8        `json:"company_id"`
+	Subtotal          pgtype.Numeric     `json:"subtotal"`
+	TaxAmount         pgtype.Numeric     `json:"tax_amount"`
+	PostedAt          pgtype.Timestamptz `json:"posted_at"`
+	PostedBy          pgtype.Int8        `json:"posted_by"`
+	VoidedAt          pgtype.Timestamptz `json:"voided_at"`
+	VoidedBy          pgtype.Int8        `json:"voided_by"`
+	VoidReason        pgtype.Text        `json:"void_reason"`
+	CreatedBy         pgtype.Int8        `json:"created_by"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	PoID              pgtype.Int8        `json:"po_id"`
+	PaymentTermsDays  pgtype.Int4        `json:"payment_terms_days"`
+	WithholdingTaxID  pgtype.Int8        `json:"withholding_tax_id"`
+	WithholdingRate   pgtype.Numeric     `json:"withholding_rate"`
+	WithholdingAmount pgtype.Numeric     `json:"withholding_amount"`
 }
 
 type ApInvoiceLine struct {
@@ -631,25 +635,43 @@ type ApInvoiceLine struct {
 }
 
 type ApPayment struct {
-	ID          int64              `json:"id"`
-	Number      string             `json:"number"`
-	ApInvoiceID pgtype.Int8        `json:"ap_invoice_id"`
-	Amount      pgtype.Numeric     `json:"amount"`
-	PaidAt      pgtype.Date        `json:"paid_at"`
-	Method      string             `json:"method"`
-	Note        string             `json:"note"`
-	CreatedBy   pgtype.Int8        `json:"created_by"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	SupplierID  pgtype.Int8        `json:"supplier_id"`
+	ID             int64              `json:"id"`
+	Number         string             `json:"number"`
+	ApInvoiceID    pgtype.Int8        `json:"ap_invoice_id"`
+	Amount         pgtype.Numeric     `json:"amount"`
+	PaidAt         pgtype.Date        `json:"paid_at"`
+	Method         string             `json:"method"`
+	Note           string             `json:"note"`
+	CreatedBy      pgtype.Int8        `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	SupplierID     pgtype.Int8        `json:"supplier_id"`
+	PaymentRunID   pgtype.Int8        `json:"payment_run_id"`
+	WithheldAmount pgtype.Numeric     `json:"withheld_amount"`
 }
 
 type ApPaymentAllocation struct {
-	ID          int64              `json:"id"`
-	ApPaymentID int64              `json:"ap_payment_id"`
-	ApInvoiceID int64              `json:"ap_invoice_id"`
-	Amount      pgtype.Numeric     `json:"amount"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ID             int64              `json:"id"`
+	ApPaymentID    int64              `json:"ap_payment_id"`
+	ApInvoiceID    int64              `json:"ap_invoice_id"`
+	Amount         pgtype.Numeric     `json:"amount"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	WithheldAmount pgtype.Numeric     `json:"withheld_amount"`
+}
+
+type ApPaymentRun struct {
+	ID              int64              `json:"id"`
+	Number          string             `json:"number"`
+	PayDate         pgtype.Date        `json:"pay_date"`
+	Method          string             `json:"method"`
+	Note            string             `json:"note"`
+	DueBefore       pgtype.Date        `json:"due_before"`
+	SupplierID      pgtype.Int8        `json:"supplier_id"`
+	CashLimit       pgtype.Numeric     `json:"cash_limit"`
+	GroupBySupplier bool               `json:"group_by_supplier"`
+	Total           pgtype.Numeric     `json:"total"`
+	CreatedBy       pgtype.Int8        `json:"created_by"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
 }
 
 type Approval struct {
@@ -729,6 +751,8 @@ type AuditLog struct {
 	EntityID   string             `json:"entity_id"`
 	Meta       []byte             `json:"meta"`
 	OccurredAt pgtype.Timestamptz `json:"occurred_at"`
+	Before     []byte             `json:"before"`
+	After      []byte             `json:"after"`
 }
 
 type BoardPack struct {
@@ -770,16 +794,21 @@ type Branch struct {
 	Address   string             `json:"address"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	// Company dimension override for GL postings; NULL uses company_id
+	GlDimCompanyID pgtype.Int8 `json:"gl_dim_company_id"`
+	// Default warehouse for deliveries; NULL means none
+	DefaultWarehouseID pgtype.Int8 `json:"default_warehouse_id"`
 }
 
 type Category struct {
-	ID        int64              `json:"id"`
-	Code      string             `json:"code"`
-	Name      string             `json:"name"`
-	ParentID  pgtype.Int8        `json:"parent_id"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	CompanyID pgtype.Int8        `json:"company_id"`
+	ID              int64              `json:"id"`
+	Code            string             `json:"code"`
+	Name            string             `json:"name"`
+	ParentID        pgtype.Int8        `json:"parent_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	CompanyID       pgtype.Int8        `json:"company_id"`
+	AttributeSchema []byte             `json:"attribute_schema"`
 }
 
 type Company struct {
@@ -793,6 +822,13 @@ type Company struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type CompanyDueDatePolicy struct {
+	CompanyID       int64              `json:"company_id"`
+	BusinessDays    bool               `json:"business_days"`
+	HolidayCalendar string             `json:"holiday_calendar"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
 type ConsolGroup struct {
 	ID                int64              `json:"id"`
 	Name              string             `json:"name"`
@@ -813,6 +849,11 @@ type ConsolGroupAccount struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type ConsolGroupChange struct {
+	GroupID   int64              `json:"group_id"`
+	ChangedAt pgtype.Timestamptz `json:"changed_at"`
+}
+
 type ConsolMember struct {
 	ID        int64              `json:"id"`
 	GroupID   int64              `json:"group_id"`
@@ -822,6 +863,13 @@ type ConsolMember struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type ConsolRefreshWatermark struct {
+	GroupID         int64              `json:"group_id"`
+	PeriodID        int64              `json:"period_id"`
+	SourceWatermark pgtype.Timestamptz `json:"source_watermark"`
+	RefreshedAt     pgtype.Timestamptz `json:"refreshed_at"`
+}
+
 type Customer struct {
 	ID               int64              `json:"id"`
 	Code             string             `json:"code"`
@@ -843,6 +891,7 @@ type Customer struct {
 	CreatedBy        int64              `json:"created_by"`
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	Language         string             `json:"language"`
 }
 
 // Delivery orders for fulfilling sales orders with inventory integration
@@ -856,17 +905,21 @@ type DeliveryOrder struct {
 	CustomerID   int64       `json:"customer_id"`
 	DeliveryDate pgtype.Date `json:"delivery_date"`
 	// Lifecycle status: DRAFT → CONFIRMED → IN_TRANSIT → DELIVERED or CANCELLED
-	Status         DeliveryOrderStatus `json:"status"`
-	DriverName     pgtype.Text         `json:"driver_name"`
-	VehicleNumber  pgtype.Text         `json:"vehicle_number"`
-	TrackingNumber pgtype.Text         `json:"tracking_number"`
-	Notes          pgtype.Text         `json:"notes"`
-	CreatedBy      int64               `json:"created_by"`
-	ConfirmedBy    pgtype.Int8         `json:"confirmed_by"`
-	ConfirmedAt    pgtype.Timestamptz  `json:"confirmed_at"`
-	DeliveredAt    pgtype.Timestamptz  `json:"delivered_at"`
-	CreatedAt      pgtype.Timestamptz  `json:"created_at"`
-	UpdatedAt      pgtype.Timestamptz  `json:"updated_at"`
+	Status          DeliveryOrderStatus `json:"status"`
+	DriverName      pgtype.Text         `json:"driver_name"`
+	VehicleNumber   pgtype.Text         `json:"vehicle_number"`
+	TrackingNumber  pgtype.Text         `json:"tracking_number"`
+	Notes           pgtype.Text         `json:"notes"`
+	CreatedBy       int64               `json:"created_by"`
+	ConfirmedBy     pgtype.Int8         `json:"confirmed_by"`
+	ConfirmedAt     pgtype.Timestamptz  `json:"confirmed_at"`
+	DeliveredAt     pgtype.Timestamptz  `json:"delivered_at"`
+	CreatedAt       pgtype.Timestamptz  `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz  `json:"updated_at"`
+	PodReceiverName pgtype.Text         `json:"pod_receiver_name"`
+	// Receiver signature as an image data URL
+	PodSignature pgtype.Text `json:"pod_signature"`
+	PodNotes     pgtype.Text `json:"pod_notes"`
 }
 
 // Line items in delivery orders, linked to sales order lines
@@ -931,6 +984,19 @@ type EliminationRule struct {
 	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
 }
 
+type EliminationRuleTemplate struct {
+	ID            int64              `json:"id"`
+	GroupID       int64              `json:"group_id"`
+	Name          string             `json:"name"`
+	AccountSrc    string             `json:"account_src"`
+	AccountTgt    string             `json:"account_tgt"`
+	MatchCriteria []byte             `json:"match_criteria"`
+	IsActive      bool               `json:"is_active"`
+	CreatedBy     int64              `json:"created_by"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
 type EliminationRun struct {
 	ID             int64                `json:"id"`
 	PeriodID       int64                `json:"period_id"`
@@ -986,6 +1052,14 @@ type GrnLine struct {
 	ProductID int64          `json:"product_id"`
 	Qty       pgtype.Numeric `json:"qty"`
 	UnitCost  pgtype.Numeric `json:"unit_cost"`
+	Serials   []string       `json:"serials"`
+	Uom       string         `json:"uom"`
+}
+
+type HolidayCalendarDay struct {
+	CalendarCode string      `json:"calendar_code"`
+	HolidayDate  pgtype.Date `json:"holiday_date"`
+	Name         string      `json:"name"`
 }
 
 type IcArapPair struct {
@@ -1026,6 +1100,19 @@ type IdempotencyKey struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type InventoryAbcClass struct {
+	CompanyID        int64              `json:"company_id"`
+	WarehouseID      int64              `json:"warehouse_id"`
+	ProductID        int64              `json:"product_id"`
+	AbcClass         string             `json:"abc_class"`
+	ConsumptionValue pgtype.Numeric     `json:"consumption_value"`
+	CumulativeShare  pgtype.Numeric     `json:"cumulative_share"`
+	Rank             int32              `json:"rank"`
+	PeriodStart      pgtype.Date        `json:"period_start"`
+	PeriodEnd        pgtype.Date        `json:"period_end"`
+	ClassifiedAt     pgtype.Timestamptz `json:"classified_at"`
+}
+
 type InventoryBalance struct {
 	WarehouseID int64              `json:"warehouse_id"`
 	ProductID   int64              `json:"product_id"`
@@ -1051,6 +1138,21 @@ type InventoryCard struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 }
 
+type InventorySerial struct {
+	ID                  int64              `json:"id"`
+	ProductID           int64              `json:"product_id"`
+	SerialNumber        string             `json:"serial_number"`
+	Status              string             `json:"status"`
+	WarehouseID         int64              `json:"warehouse_id"`
+	GrnID               pgtype.Int8        `json:"grn_id"`
+	ReceivedAt          pgtype.Timestamptz `json:"received_at"`
+	DeliveryOrderID     pgtype.Int8        `json:"delivery_order_id"`
+	DeliveryOrderLineID pgtype.Int8        `json:"delivery_order_line_id"`
+	DeliveredAt         pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+}
+
 type InventoryTx struct {
 	ID          int64              `json:"id"`
 	Code        string             `json:"code"`
@@ -1185,6 +1287,31 @@ type PeriodCloseRun struct {
 	UpdatedAt   pgtype.Timestamptz   `json:"updated_at"`
 }
 
+type PeriodCloseSnapshot struct {
+	ID               int64              `json:"id"`
+	PeriodID         int64              `json:"period_id"`
+	CompanyID        int64              `json:"company_id"`
+	RunID            int64              `json:"run_id"`
+	NetIncome        pgtype.Numeric     `json:"net_income"`
+	TotalAssets      pgtype.Numeric     `json:"total_assets"`
+	TotalLiabilities pgtype.Numeric     `json:"total_liabilities"`
+	TotalEquity      pgtype.Numeric     `json:"total_equity"`
+	CapturedBy       int64              `json:"captured_by"`
+	CapturedAt       pgtype.Timestamptz `json:"captured_at"`
+}
+
+type PeriodCloseSnapshotLine struct {
+	ID          int64          `json:"id"`
+	SnapshotID  int64          `json:"snapshot_id"`
+	AccountCode string         `json:"account_code"`
+	AccountName string         `json:"account_name"`
+	AccountType string         `json:"account_type"`
+	Opening     pgtype.Numeric `json:"opening"`
+	Debit       pgtype.Numeric `json:"debit"`
+	Credit      pgtype.Numeric `json:"credit"`
+	Closing     pgtype.Numeric `json:"closing"`
+}
+
 type Permission struct {
 	ID          int64  `json:"id"`
 	Name        string `json:"name"`
@@ -1203,6 +1330,7 @@ type Po struct {
 	ApprovedBy   pgtype.Int8        `json:"approved_by"`
 	ApprovedAt   pgtype.Timestamptz `json:"approved_at"`
 	CompanyID    pgtype.Int8        `json:"company_id"`
+	WarehouseID  pgtype.Int8        `json:"warehouse_id"`
 }
 
 type PoLine struct {
@@ -1215,6 +1343,11 @@ type PoLine struct {
 	Note      string         `json:"note"`
 }
 
+type PoSourcePr struct {
+	PoID int64 `json:"po_id"`
+	PrID int64 `json:"pr_id"`
+}
+
 type Pr struct {
 	ID         int64              `json:"id"`
 	Number     string             `json:"number"`
@@ -1224,6 +1357,7 @@ type Pr struct {
 	Note       string             `json:"note"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 	CompanyID  pgtype.Int8        `json:"company_id"`
+	Currency   string             `json:"currency"`
 }
 
 type PrLine struct {
@@ -1245,49 +1379,78 @@ type Product struct {
 	IsActive   bool               `json:"is_active"`
 	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
 	// Tenant isolation: company that owns this product
-	CompanyID pgtype.Int8 `json:"company_id"`
+	CompanyID   pgtype.Int8        `json:"company_id"`
+	Attributes  []byte             `json:"attributes"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	TrackSerial bool               `json:"track_serial"`
 }
 
 type Quotation struct {
-	ID              int64              `json:"id"`
-	DocNumber       string             `json:"doc_number"`
-	CompanyID       int64              `json:"company_id"`
-	CustomerID      int64              `json:"customer_id"`
-	QuoteDate       pgtype.Date        `json:"quote_date"`
-	ValidUntil      pgtype.Date        `json:"valid_until"`
-	Status          QuotationStatus    `json:"status"`
-	Currency        string             `json:"currency"`
-	Subtotal        pgtype.Numeric     `json:"subtotal"`
-	TaxAmount       pgtype.Numeric     `json:"tax_amount"`
-	TotalAmount     pgtype.Numeric     `json:"total_amount"`
-	Notes           pgtype.Text        `json:"notes"`
-	CreatedBy       int64              `json:"created_by"`
-	ApprovedBy      pgtype.Int8        `json:"approved_by"`
-	ApprovedAt      pgtype.Timestamptz `json:"approved_at"`
-	RejectedBy      pgtype.Int8        `json:"rejected_by"`
-	RejectedAt      pgtype.Timestamptz `json:"rejected_at"`
-	RejectionReason pgtype.Text        `json:"rejection_reason"`
-	CreatedAt       pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	ID                      int64              `json:"id"`
+	DocNumber               string             `json:"doc_number"`
+	CompanyID               int64              `json:"company_id"`
+	CustomerID              int64              `json:"customer_id"`
+	QuoteDate               pgtype.Date        `json:"quote_date"`
+	ValidUntil              pgtype.Date        `json:"valid_until"`
+	Status                  QuotationStatus    `json:"status"`
+	Currency                string             `json:"currency"`
+	Subtotal                pgtype.Numeric     `json:"subtotal"`
+	TaxAmount               pgtype.Numeric     `json:"tax_amount"`
+	TotalAmount             pgtype.Numeric     `json:"total_amount"`
+	Notes                   pgtype.Text        `json:"notes"`
+	CreatedBy               int64              `json:"created_by"`
+	ApprovedBy              pgtype.Int8        `json:"approved_by"`
+	ApprovedAt              pgtype.Timestamptz `json:"approved_at"`
+	RejectedBy              pgtype.Int8        `json:"rejected_by"`
+	RejectedAt              pgtype.Timestamptz `json:"rejected_at"`
+	RejectionReason         pgtype.Text        `json:"rejection_reason"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt               pgtype.Timestamptz `json:"updated_at"`
+	DocumentDiscountPercent pgtype.Numeric     `json:"document_discount_percent"`
+	DocumentDiscountAmount  pgtype.Numeric     `json:"document_discount_amount"`
+	LossReason              pgtype.Text        `json:"loss_reason"`
 }
 
 type QuotationLine struct {
-	ID              int64              `json:"id"`
-	QuotationID     int64              `json:"quotation_id"`
-	ProductID       int64              `json:"product_id"`
-	Description     pgtype.Text        `json:"description"`
-	Quantity        pgtype.Numeric     `json:"quantity"`
-	Uom             string             `json:"uom"`
-	UnitPrice       pgtype.Numeric     `json:"unit_price"`
-	DiscountPercent pgtype.Numeric     `json:"discount_percent"`
-	DiscountAmount  pgtype.Numeric     `json:"discount_amount"`
-	TaxPercent      pgtype.Numeric     `json:"tax_percent"`
-	TaxAmount       pgtype.Numeric     `json:"tax_amount"`
-	LineTotal       pgtype.Numeric     `json:"line_total"`
-	Notes           pgtype.Text        `json:"notes"`
-	LineOrder       int32              `json:"line_order"`
-	CreatedAt       pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	ID                     int64              `json:"id"`
+	QuotationID            int64              `json:"quotation_id"`
+	ProductID              int64              `json:"product_id"`
+	Description            pgtype.Text        `json:"description"`
+	Quantity               pgtype.Numeric     `json:"quantity"`
+	Uom                    string             `json:"uom"`
+	UnitPrice              pgtype.Numeric     `json:"unit_price"`
+	DiscountPercent        pgtype.Numeric     `json:"discount_percent"`
+	DiscountAmount         pgtype.Numeric     `json:"discount_amount"`
+	TaxPercent             pgtype.Numeric     `json:"tax_percent"`
+	TaxAmount              pgtype.Numeric     `json:"tax_amount"`
+	LineTotal              pgtype.Numeric     `json:"line_total"`
+	Notes                  pgtype.Text        `json:"notes"`
+	LineOrder              int32              `json:"line_order"`
+	CreatedAt              pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt              pgtype.Timestamptz `json:"updated_at"`
+	DocumentDiscountAmount pgtype.Numeric     `json:"document_discount_amount"`
+}
+
+type QuotationTemplate struct {
+	ID          int64              `json:"id"`
+	CompanyID   int64              `json:"company_id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	CreatedBy   int64              `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type QuotationTemplateLine struct {
+	ID              int64          `json:"id"`
+	TemplateID      int64          `json:"template_id"`
+	ProductID       int64          `json:"product_id"`
+	Description     pgtype.Text    `json:"description"`
+	Quantity        pgtype.Numeric `json:"quantity"`
+	Uom             string         `json:"uom"`
+	DiscountPercent pgtype.Numeric `json:"discount_percent"`
+	TaxPercent      pgtype.Numeric `json:"tax_percent"`
+	LineOrder       int32          `json:"line_order"`
 }
 
 type Role struct {
@@ -1305,27 +1468,29 @@ type RolePermission struct {
 }
 
 type SalesOrder struct {
-	ID                   int64              `json:"id"`
-	DocNumber            string             `json:"doc_number"`
-	CompanyID            int64              `json:"company_id"`
-	CustomerID           int64              `json:"customer_id"`
-	QuotationID          pgtype.Int8        `json:"quotation_id"`
-	OrderDate            pgtype.Date        `json:"order_date"`
-	ExpectedDeliveryDate pgtype.Date        `json:"expected_delivery_date"`
-	Status               SalesOrderStatus   `json:"status"`
-	Currency             string             `json:"currency"`
-	Subtotal             pgtype.Numeric     `json:"subtotal"`
-	TaxAmount            pgtype.Numeric     `json:"tax_amount"`
-	TotalAmount          pgtype.Numeric     `json:"total_amount"`
-	Notes                pgtype.Text        `json:"notes"`
-	CreatedBy            int64              `json:"created_by"`
-	ConfirmedBy          pgtype.Int8        `json:"confirmed_by"`
-	ConfirmedAt          pgtype.Timestamptz `json:"confirmed_at"`
-	CancelledBy          pgtype.Int8        `json:"cancelled_by"`
-	CancelledAt          pgtype.Timestamptz `json:"cancelled_at"`
-	CancellationReason   pgtype.Text        `json:"cancellation_reason"`
-	CreatedAt            pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+	ID                      int64              `json:"id"`
+	DocNumber               string             `json:"doc_number"`
+	CompanyID               int64              `json:"company_id"`
+	CustomerID              int64              `json:"customer_id"`
+	QuotationID             pgtype.Int8        `json:"quotation_id"`
+	OrderDate               pgtype.Date        `json:"order_date"`
+	ExpectedDeliveryDate    pgtype.Date        `json:"expected_delivery_date"`
+	Status                  SalesOrderStatus   `json:"status"`
+	Currency                string             `json:"currency"`
+	Subtotal                pgtype.Numeric     `json:"subtotal"`
+	TaxAmount               pgtype.Numeric     `json:"tax_amount"`
+	TotalAmount             pgtype.Numeric     `json:"total_amount"`
+	Notes                   pgtype.Text        `json:"notes"`
+	CreatedBy               int64              `json:"created_by"`
+	ConfirmedBy             pgtype.Int8        `json:"confirmed_by"`
+	ConfirmedAt             pgtype.Timestamptz `json:"confirmed_at"`
+	CancelledBy             pgtype.Int8        `json:"cancelled_by"`
+	CancelledAt             pgtype.Timestamptz `json:"cancelled_at"`
+	CancellationReason      pgtype.Text        `json:"cancellation_reason"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt               pgtype.Timestamptz `json:"updated_at"`
+	DocumentDiscountPercent pgtype.Numeric     `json:"document_discount_percent"`
+	DocumentDiscountAmount  pgtype.Numeric     `json:"document_discount_amount"`
 }
 
 type SalesOrderLine struct {
@@ -1335,19 +1500,20 @@ type SalesOrderLine struct {
 	Description  pgtype.Text    `json:"description"`
 	Quantity     pgtype.Numeric `json:"quantity"`
 	// Total quantity delivered across all DOs (auto-updated by trigger)
-	QuantityDelivered pgtype.Numeric     `json:"quantity_delivered"`
-	QuantityInvoiced  pgtype.Numeric     `json:"quantity_invoiced"`
-	Uom               string             `json:"uom"`
-	UnitPrice         pgtype.Numeric     `json:"unit_price"`
-	DiscountPercent   pgtype.Numeric     `json:"discount_percent"`
-	DiscountAmount    pgtype.Numeric     `json:"discount_amount"`
-	TaxPercent        pgtype.Numeric     `json:"tax_percent"`
-	TaxAmount         pgtype.Numeric     `json:"tax_amount"`
-	LineTotal         pgtype.Numeric     `json:"line_total"`
-	Notes             pgtype.Text        `json:"notes"`
-	LineOrder         int32              `json:"line_order"`
-	CreatedAt         pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	QuantityDelivered      pgtype.Numeric     `json:"quantity_delivered"`
+	QuantityInvoiced       pgtype.Numeric     `json:"quantity_invoiced"`
+	Uom                    string             `json:"uom"`
+	UnitPrice              pgtype.Numeric     `json:"unit_price"`
+	DiscountPercent        pgtype.Numeric     `json:"discount_percent"`
+	DiscountAmount         pgtype.Numeric     `json:"discount_amount"`
+	TaxPercent             pgtype.Numeric     `json:"tax_percent"`
+	TaxAmount              pgtype.Numeric     `json:"tax_amount"`
+	LineTotal              pgtype.Numeric     `json:"line_total"`
+	Notes                  pgtype.Text        `json:"notes"`
+	LineOrder              int32              `json:"line_order"`
+	CreatedAt              pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt              pgtype.Timestamptz `json:"updated_at"`
+	DocumentDiscountAmount pgtype.Numeric     `json:"document_discount_amount"`
 }
 
 type Session struct {
@@ -1376,14 +1542,19 @@ type Supplier struct {
 	Address  string `json:"address"`
 	IsActive bool   `json:"is_active"`
 	// Tenant isolation: company that owns this supplier
-	CompanyID pgtype.Int8 `json:"company_id"`
+	CompanyID        pgtype.Int8 `json:"company_id"`
+	TaxID            string      `json:"tax_id"`
+	Country          string      `json:"country"`
+	PaymentTermsDays int32       `json:"payment_terms_days"`
+	WithholdingTaxID pgtype.Int8 `json:"withholding_tax_id"`
 }
 
 type Tax struct {
-	ID   int64          `json:"id"`
-	Code string         `json:"code"`
-	Name string         `json:"name"`
-	Rate pgtype.Numeric `json:"rate"`
+	ID            int64          `json:"id"`
+	Code          string         `json:"code"`
+	Name          string         `json:"name"`
+	Rate          pgtype.Numeric `json:"rate"`
+	IsWithholding bool           `json:"is_withholding"`
 }
 
 type Unit struct {
@@ -1404,6 +1575,12 @@ type User struct {
 	Name         string             `json:"name"`
 }
 
+type UserCompany struct {
+	UserID    int64              `json:"user_id"`
+	CompanyID int64              `json:"company_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type UserRole struct {
 	UserID    int64              `json:"user_id"`
 	RoleID    int64              `json:"role_id"`
@@ -1502,4 +1679,8 @@ type Warehouse struct {
 	Address   string             `json:"address"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	// Company dimension override for GL postings; NULL uses the branch default
+	GlDimCompanyID pgtype.Int8 `json:"gl_dim_company_id"`
+	// Branch dimension override for GL postings; NULL uses branch_id
+	GlDimBranchID pgtype.Int8 `json:"gl_dim_branch_id"`
 }
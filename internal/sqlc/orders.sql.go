@@ -97,7 +97,7 @@ const getByDocNumber = `-- name: GetByDocNumber :one
 SELECT id, doc_number, company_id, sales_order_id, warehouse_id, customer_id,
        delivery_date, status, driver_name, vehicle_number, tracking_number,
        notes, created_by, confirmed_by, confirmed_at, delivered_at,
-       created_at, updated_at
+       created_at, updated_at, pod_receiver_name, pod_signature, pod_notes
 FROM delivery_orders
 WHERE company_id = $1 AND doc_number = $2
 `
@@ -129,6 +129,9 @@ func (q *Queries) GetByDocNumber(ctx context.Context, arg GetByDocNumberParams)
 		&i.DeliveredAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PodReceiverName,
+		&i.PodSignature,
+		&i.PodNotes,
 	)
 	return i, err
 }
@@ -137,7 +140,7 @@ const getByID = `-- name: GetByID :one
 SELECT id, doc_number, company_id, sales_order_id, warehouse_id, customer_id,
        delivery_date, status, driver_name, vehicle_number, tracking_number,
        notes, created_by, confirmed_by, confirmed_at, delivered_at,
-       created_at, updated_at
+       created_at, updated_at, pod_receiver_name, pod_signature, pod_notes
 FROM delivery_orders
 WHERE id = $1
 `
@@ -164,10 +167,36 @@ func (q *Queries) GetByID(ctx context.Context, id int64) (DeliveryOrder, error)
 		&i.DeliveredAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PodReceiverName,
+		&i.PodSignature,
+		&i.PodNotes,
 	)
 	return i, err
 }
 
+const getDefaultDeliveryWarehouse = `-- name: GetDefaultDeliveryWarehouse :one
+SELECT default_warehouse_id
+FROM branches
+WHERE company_id = $1
+  AND default_warehouse_id IS NOT NULL
+  AND ($2::bigint IS NULL OR id = $2::bigint)
+ORDER BY id
+LIMIT 1
+`
+
+type GetDefaultDeliveryWarehouseParams struct {
+	CompanyID int64       `json:"company_id"`
+	BranchID  pgtype.Int8 `json:"branch_id"`
+}
+
+// Branch default for a company; without a branch the lowest branch ID wins.
+func (q *Queries) GetDefaultDeliveryWarehouse(ctx context.Context, arg GetDefaultDeliveryWarehouseParams) (pgtype.Int8, error) {
+	row := q.db.QueryRow(ctx, getDefaultDeliveryWarehouse, arg.CompanyID, arg.BranchID)
+	var default_warehouse_id pgtype.Int8
+	err := row.Scan(&default_warehouse_id)
+	return default_warehouse_id, err
+}
+
 const getDeliverableSOLines = `-- name: GetDeliverableSOLines :many
 SELECT sol.id AS sales_order_line_id,
        sol.sales_order_id,
@@ -381,10 +410,12 @@ SELECT dor.id, dor.doc_number, dor.company_id, dor.sales_order_id, dor.warehouse
        dor.customer_id, dor.delivery_date, dor.status, dor.driver_name,
        dor.vehicle_number, dor.tracking_number, dor.notes, dor.created_by,
        dor.confirmed_by, dor.confirmed_at, dor.delivered_at,
-       dor.created_at, dor.updated_at,
+       dor.created_at, dor.updated_at, dor.pod_receiver_name, dor.pod_signature,
+       dor.pod_notes,
        so.doc_number AS sales_order_number,
        w.name AS warehouse_name,
        c.name AS customer_name,
+       c.language AS customer_language,
        u_created.email AS created_by_name,
        u_confirmed.email AS confirmed_by_name,
        COUNT(dol.id) AS line_count,
@@ -401,7 +432,8 @@ GROUP BY dor.id, dor.doc_number, dor.company_id, dor.sales_order_id, dor.warehou
             dor.customer_id, dor.delivery_date, dor.status, dor.driver_name,
             dor.vehicle_number, dor.tracking_number, dor.notes, dor.created_by,
             dor.confirmed_by, dor.confirmed_at, dor.delivered_at,
-            dor.created_at, dor.updated_at, so.doc_number, w.name, c.name,
+            dor.created_at, dor.updated_at, dor.pod_receiver_name, dor.pod_signature,
+            dor.pod_notes, so.doc_number, w.name, c.name, c.language,
             u_created.email, u_confirmed.email
 `
 
@@ -424,9 +456,13 @@ type GetWithDetailsRow struct {
 	DeliveredAt      pgtype.Timestamptz  `json:"delivered_at"`
 	CreatedAt        pgtype.Timestamptz  `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz  `json:"updated_at"`
+	PodReceiverName  pgtype.Text         `json:"pod_receiver_name"`
+	PodSignature     pgtype.Text         `json:"pod_signature"`
+	PodNotes         pgtype.Text         `json:"pod_notes"`
 	SalesOrderNumber string              `json:"sales_order_number"`
 	WarehouseName    string              `json:"warehouse_name"`
 	CustomerName     string              `json:"customer_name"`
+	CustomerLanguage string              `json:"customer_language"`
 	CreatedByName    string              `json:"created_by_name"`
 	ConfirmedByName  pgtype.Text         `json:"confirmed_by_name"`
 	LineCount        int64               `json:"line_count"`
@@ -455,9 +491,13 @@ func (q *Queries) GetWithDetails(ctx context.Context, id int64) (GetWithDetailsR
 		&i.DeliveredAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PodReceiverName,
+		&i.PodSignature,
+		&i.PodNotes,
 		&i.SalesOrderNumber,
 		&i.WarehouseName,
 		&i.CustomerName,
+		&i.CustomerLanguage,
 		&i.CreatedByName,
 		&i.ConfirmedByName,
 		&i.LineCount,
@@ -48,8 +48,8 @@ func (q *Queries) CreateGRN(ctx context.Context, arg CreateGRNParams) (int64, er
 
 const createPO = `-- name: CreatePO :one
 
-INSERT INTO pos (number, supplier_id, status, currency, expected_date, note, created_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW())
+INSERT INTO pos (number, supplier_id, status, currency, expected_date, note, warehouse_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
 RETURNING id
 `
 
@@ -60,6 +60,7 @@ type CreatePOParams struct {
 	Currency     string      `json:"currency"`
 	ExpectedDate pgtype.Date `json:"expected_date"`
 	Note         string      `json:"note"`
+	WarehouseID  pgtype.Int8 `json:"warehouse_id"`
 }
 
 // =============================================================================
@@ -73,6 +74,7 @@ func (q *Queries) CreatePO(ctx context.Context, arg CreatePOParams) (int64, erro
 		arg.Currency,
 		arg.ExpectedDate,
 		arg.Note,
+		arg.WarehouseID,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -81,8 +83,8 @@ func (q *Queries) CreatePO(ctx context.Context, arg CreatePOParams) (int64, erro
 
 const createPR = `-- name: CreatePR :one
 
-INSERT INTO prs (number, supplier_id, request_by, status, note, created_at)
-VALUES ($1, $2, $3, $4, $5, NOW())
+INSERT INTO prs (number, supplier_id, request_by, status, note, currency, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
 RETURNING id
 `
 
@@ -92,6 +94,7 @@ type CreatePRParams struct {
 	RequestBy  int64       `json:"request_by"`
 	Status     string      `json:"status"`
 	Note       string      `json:"note"`
+	Currency   string      `json:"currency"`
 }
 
 // =============================================================================
@@ -104,6 +107,7 @@ func (q *Queries) CreatePR(ctx context.Context, arg CreatePRParams) (int64, erro
 		arg.RequestBy,
 		arg.Status,
 		arg.Note,
+		arg.Currency,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -143,7 +147,7 @@ func (q *Queries) GetGRN(ctx context.Context, id int64) (GetGRNRow, error) {
 }
 
 const getGRNLines = `-- name: GetGRNLines :many
-SELECT id, grn_id, product_id, qty, unit_cost
+SELECT id, grn_id, product_id, qty, unit_cost, serials, uom
 FROM grn_lines WHERE grn_id = $1 ORDER BY id
 `
 
@@ -162,6 +166,8 @@ func (q *Queries) GetGRNLines(ctx context.Context, grnID int64) ([]GrnLine, erro
 			&i.ProductID,
 			&i.Qty,
 			&i.UnitCost,
+			&i.Serials,
+			&i.Uom,
 		); err != nil {
 			return nil, err
 		}
@@ -174,7 +180,7 @@ func (q *Queries) GetGRNLines(ctx context.Context, grnID int64) ([]GrnLine, erro
 }
 
 const getPO = `-- name: GetPO :one
-SELECT id, number, supplier_id, status, currency, expected_date, note
+SELECT id, number, supplier_id, status, currency, expected_date, note, warehouse_id
 FROM pos WHERE id = $1
 `
 
@@ -186,6 +192,7 @@ type GetPORow struct {
 	Currency     string      `json:"currency"`
 	ExpectedDate pgtype.Date `json:"expected_date"`
 	Note         string      `json:"note"`
+	WarehouseID  pgtype.Int8 `json:"warehouse_id"`
 }
 
 func (q *Queries) GetPO(ctx context.Context, id int64) (GetPORow, error) {
@@ -199,6 +206,7 @@ func (q *Queries) GetPO(ctx context.Context, id int64) (GetPORow, error) {
 		&i.Currency,
 		&i.ExpectedDate,
 		&i.Note,
+		&i.WarehouseID,
 	)
 	return i, err
 }
@@ -237,7 +245,7 @@ func (q *Queries) GetPOLines(ctx context.Context, poID int64) ([]PoLine, error)
 }
 
 const getPR = `-- name: GetPR :one
-SELECT id, number, supplier_id, request_by, status, note
+SELECT id, number, supplier_id, request_by, status, note, currency
 FROM prs WHERE id = $1
 `
 
@@ -248,6 +256,7 @@ type GetPRRow struct {
 	RequestBy  int64       `json:"request_by"`
 	Status     string      `json:"status"`
 	Note       string      `json:"note"`
+	Currency   string      `json:"currency"`
 }
 
 func (q *Queries) GetPR(ctx context.Context, id int64) (GetPRRow, error) {
@@ -260,6 +269,7 @@ func (q *Queries) GetPR(ctx context.Context, id int64) (GetPRRow, error) {
 		&i.RequestBy,
 		&i.Status,
 		&i.Note,
+		&i.Currency,
 	)
 	return i, err
 }
@@ -296,8 +306,8 @@ func (q *Queries) GetPRLines(ctx context.Context, prID int64) ([]PrLine, error)
 }
 
 const insertGRNLine = `-- name: InsertGRNLine :exec
-INSERT INTO grn_lines (grn_id, product_id, qty, unit_cost)
-VALUES ($1, $2, $3, $4)
+INSERT INTO grn_lines (grn_id, product_id, qty, unit_cost, serials, uom)
+VALUES ($1, $2, $3, $4, $5, $6)
 `
 
 type InsertGRNLineParams struct {
@@ -305,6 +315,8 @@ type InsertGRNLineParams struct {
 	ProductID int64          `json:"product_id"`
 	Qty       pgtype.Numeric `json:"qty"`
 	UnitCost  pgtype.Numeric `json:"unit_cost"`
+	Serials   []string       `json:"serials"`
+	Uom       string         `json:"uom"`
 }
 
 func (q *Queries) InsertGRNLine(ctx context.Context, arg InsertGRNLineParams) error {
@@ -313,6 +325,8 @@ func (q *Queries) InsertGRNLine(ctx context.Context, arg InsertGRNLineParams) er
 		arg.ProductID,
 		arg.Qty,
 		arg.UnitCost,
+		arg.Serials,
+		arg.Uom,
 	)
 	return err
 }
@@ -365,6 +379,21 @@ func (q *Queries) InsertPRLine(ctx context.Context, arg InsertPRLineParams) erro
 	return err
 }
 
+const linkPOSourcePR = `-- name: LinkPOSourcePR :exec
+INSERT INTO po_source_prs (po_id, pr_id)
+VALUES ($1, $2)
+`
+
+type LinkPOSourcePRParams struct {
+	PoID int64 `json:"po_id"`
+	PrID int64 `json:"pr_id"`
+}
+
+func (q *Queries) LinkPOSourcePR(ctx context.Context, arg LinkPOSourcePRParams) error {
+	_, err := q.db.Exec(ctx, linkPOSourcePR, arg.PoID, arg.PrID)
+	return err
+}
+
 const setPOApproval = `-- name: SetPOApproval :exec
 UPDATE pos SET approved_by = $1, approved_at = $2 WHERE id = $3
 `
@@ -12,20 +12,28 @@ import (
 
 type Querier interface {
 	ActiveConsolidationPeriod(ctx context.Context) (string, error)
+	AddUserCompany(ctx context.Context, arg AddUserCompanyParams) error
 	AggregateAccountBalances(ctx context.Context, arg AggregateAccountBalancesParams) ([]AggregateAccountBalancesRow, error)
 	AggregateBalances(ctx context.Context, arg AggregateBalancesParams) ([]AggregateBalancesRow, error)
 	AgingAP(ctx context.Context, arg AgingAPParams) ([]AgingAPRow, error)
 	AgingAR(ctx context.Context, arg AgingARParams) ([]AgingARRow, error)
+	ApplyInboundBalances(ctx context.Context, arg ApplyInboundBalancesParams) ([]InventoryBalance, error)
 	AssignRoleToUser(ctx context.Context, arg AssignRoleToUserParams) error
 	AttachPermissionToRole(ctx context.Context, arg AttachPermissionToRoleParams) error
+	AuditLogGet(ctx context.Context, id int64) (AuditLogGetRow, error)
 	AuditTimelineAll(ctx context.Context, arg AuditTimelineAllParams) ([]AuditTimelineAllRow, error)
 	AuditTimelineWindow(ctx context.Context, arg AuditTimelineWindowParams) ([]AuditTimelineWindowRow, error)
 	AuthGetUserByEmail(ctx context.Context, email string) (AuthGetUserByEmailRow, error)
 	Balances(ctx context.Context, arg BalancesParams) ([]BalancesRow, error)
 	CalculateConsolBalances(ctx context.Context, arg CalculateConsolBalancesParams) error
 	CheckWarehouseExists(ctx context.Context, id int64) (bool, error)
+	ClearABCClasses(ctx context.Context, arg ClearABCClassesParams) error
 	CompareMonthlyNetRevenue(ctx context.Context, arg CompareMonthlyNetRevenueParams) ([]CompareMonthlyNetRevenueRow, error)
+	CompareTrialBalanceByCompany(ctx context.Context, arg CompareTrialBalanceByCompanyParams) ([]CompareTrialBalanceByCompanyRow, error)
 	ConsolBalancesByType(ctx context.Context, arg ConsolBalancesByTypeParams) ([]ConsolBalancesByTypeRow, error)
+	// Latest change to anything the group's balances are built from: journals
+	// booked by members in the period, membership and account mappings.
+	ConsolGroupSourceWatermark(ctx context.Context, arg ConsolGroupSourceWatermarkParams) (pgtype.Timestamptz, error)
 	ContributionByBranch(ctx context.Context, arg ContributionByBranchParams) ([]ContributionByBranchRow, error)
 	CountARInvoicesByDelivery(ctx context.Context, deliveryOrderID pgtype.Int8) (int64, error)
 	CountPendingChecklistItems(ctx context.Context, periodCloseRunID int64) (int64, error)
@@ -34,6 +42,7 @@ type Querier interface {
 	CreateAPInvoiceLine(ctx context.Context, arg CreateAPInvoiceLineParams) (int64, error)
 	CreateAPPayment(ctx context.Context, arg CreateAPPaymentParams) (int64, error)
 	CreateAPPaymentAllocation(ctx context.Context, arg CreateAPPaymentAllocationParams) (int64, error)
+	CreateAPPaymentRun(ctx context.Context, arg CreateAPPaymentRunParams) (int64, error)
 	CreateARInvoice(ctx context.Context, arg CreateARInvoiceParams) (int64, error)
 	CreateARInvoiceLine(ctx context.Context, arg CreateARInvoiceLineParams) (int64, error)
 	CreateARPayment(ctx context.Context, arg CreateARPaymentParams) (int64, error)
@@ -77,17 +86,26 @@ type Querier interface {
 	DeleteSupplier(ctx context.Context, id int64) error
 	DeleteTax(ctx context.Context, id int64) error
 	DeleteUnit(ctx context.Context, id int64) error
+	DeleteUserCompanies(ctx context.Context, userID int64) error
 	DeleteWarehouse(ctx context.Context, id int64) error
 	DetachPermissionFromRole(ctx context.Context, arg DetachPermissionFromRoleParams) error
 	ElimGetRule(ctx context.Context, id int64) (EliminationRule, error)
+	ElimGetRuleTemplate(ctx context.Context, id int64) (EliminationRuleTemplate, error)
 	ElimInsertRule(ctx context.Context, arg ElimInsertRuleParams) (EliminationRule, error)
+	ElimInsertRuleTemplate(ctx context.Context, arg ElimInsertRuleTemplateParams) (EliminationRuleTemplate, error)
+	ElimListActiveRuleTemplates(ctx context.Context) ([]EliminationRuleTemplate, error)
+	ElimListEnabledMembers(ctx context.Context, groupID int64) ([]ElimListEnabledMembersRow, error)
+	ElimListGroupRules(ctx context.Context, groupID pgtype.Int8) ([]EliminationRule, error)
 	ElimListRecentPeriods(ctx context.Context, limit int32) ([]ElimListRecentPeriodsRow, error)
+	ElimListRuleTemplates(ctx context.Context, limit int32) ([]EliminationRuleTemplate, error)
 	ElimListRules(ctx context.Context, limit int32) ([]EliminationRule, error)
 	ElimLoadAccountingPeriod(ctx context.Context, id int64) (ElimLoadAccountingPeriodRow, error)
+	ElimLockGroup(ctx context.Context, id int64) (int64, error)
 	FindPeriodID(ctx context.Context, code string) (int64, error)
 	FxRateForPeriod(ctx context.Context, arg FxRateForPeriodParams) (FxRateForPeriodRow, error)
 	GenerateAPInvoiceNumber(ctx context.Context) (interface{}, error)
 	GenerateAPPaymentNumber(ctx context.Context) (interface{}, error)
+	GenerateAPPaymentRunNumber(ctx context.Context) (interface{}, error)
 	GenerateARInvoiceNumber(ctx context.Context) (string, error)
 	GenerateARPaymentNumber(ctx context.Context) (string, error)
 	GenerateDocNumber(ctx context.Context, arg GenerateDocNumberParams) (string, error)
@@ -112,6 +130,8 @@ type Querier interface {
 	// =============================================================================
 	GetCategory(ctx context.Context, id int64) (GetCategoryRow, error)
 	GetCompany(ctx context.Context, id int64) (GetCompanyRow, error)
+	GetCompanyDueDatePolicy(ctx context.Context, companyID int64) (CompanyDueDatePolicy, error)
+	GetConsolRefreshWatermark(ctx context.Context, arg GetConsolRefreshWatermarkParams) (GetConsolRefreshWatermarkRow, error)
 	// =============================================================================
 	// CUSTOMERS
 	// =============================================================================
@@ -170,9 +190,13 @@ type Querier interface {
 	// WAREHOUSES (id, branch_id, code, name, address, created_at, updated_at)
 	// =============================================================================
 	GetWarehouse(ctx context.Context, id int64) (Warehouse, error)
+	GetWarehouseGLDimensions(ctx context.Context, id int64) (GetWarehouseGLDimensionsRow, error)
 	GetWithDetails(ctx context.Context, id int64) (GetWithDetailsRow, error)
+	HolidayCalendarExists(ctx context.Context, calendarCode string) (bool, error)
+	InsertABCClasses(ctx context.Context, arg InsertABCClassesParams) error
 	InsertAccountingPeriod(ctx context.Context, arg InsertAccountingPeriodParams) (int64, error)
 	InsertBoardPack(ctx context.Context, arg InsertBoardPackParams) (int64, error)
+	InsertCardEntries(ctx context.Context, arg InsertCardEntriesParams) error
 	InsertCardEntry(ctx context.Context, arg InsertCardEntryParams) error
 	InsertChecklistItem(ctx context.Context, arg InsertChecklistItemParams) (PeriodCloseChecklistItem, error)
 	InsertCloseRun(ctx context.Context, arg InsertCloseRunParams) (InsertCloseRunRow, error)
@@ -181,14 +205,27 @@ type Querier interface {
 	InsertPOLine(ctx context.Context, arg InsertPOLineParams) error
 	InsertPRLine(ctx context.Context, arg InsertPRLineParams) error
 	InsertPeriodLegacy(ctx context.Context, arg InsertPeriodLegacyParams) (InsertPeriodLegacyRow, error)
+	InsertPeriodSnapshot(ctx context.Context, arg InsertPeriodSnapshotParams) (int64, error)
+	InsertPeriodSnapshotLine(ctx context.Context, arg InsertPeriodSnapshotLineParams) error
 	InsertQuotationLine(ctx context.Context, arg InsertQuotationLineParams) (int64, error)
 	InsertRun(ctx context.Context, arg InsertRunParams) (EliminationRun, error)
 	InsertSalesOrderLine(ctx context.Context, arg InsertSalesOrderLineParams) (int64, error)
 	InsertSnapshot(ctx context.Context, arg InsertSnapshotParams) (VarianceSnapshot, error)
 	InsertTransaction(ctx context.Context, arg InsertTransactionParams) (int64, error)
 	InsertTransactionLine(ctx context.Context, arg InsertTransactionLineParams) error
+	InsertTransactionLines(ctx context.Context, arg InsertTransactionLinesParams) error
+	// Issued quantity and value per product over a period. Deliveries post as
+	// negative adjustments; transfers only move stock and are not consumption.
+	InventoryConsumptionValue(ctx context.Context, arg InventoryConsumptionValueParams) ([]InventoryConsumptionValueRow, error)
+	// Balance of the mapped inventory asset account, for tie-out with the valuation.
+	InventoryGLBalanceAsOf(ctx context.Context, arg InventoryGLBalanceAsOfParams) (float64, error)
+	// Latest stock card per warehouse/product at or before as_of; cards carry the
+	// running quantity and moving-average cost so no full replay is needed.
+	InventoryValuationAsOf(ctx context.Context, arg InventoryValuationAsOfParams) ([]InventoryValuationAsOfRow, error)
 	IsAPPaymentPosted(ctx context.Context, arg IsAPPaymentPostedParams) (bool, error)
 	KpiSummary(ctx context.Context, arg KpiSummaryParams) (KpiSummaryRow, error)
+	LinkPOSourcePR(ctx context.Context, arg LinkPOSourcePRParams) error
+	ListABCClasses(ctx context.Context, arg ListABCClassesParams) ([]ListABCClassesRow, error)
 	ListAPInvoiceLines(ctx context.Context, apInvoiceID int64) ([]ApInvoiceLine, error)
 	ListAPInvoicePayments(ctx context.Context, apInvoiceID int64) ([]ListAPInvoicePaymentsRow, error)
 	ListAPInvoices(ctx context.Context) ([]ListAPInvoicesRow, error)
@@ -208,6 +245,7 @@ type Querier interface {
 	ListGroupIDs(ctx context.Context) ([]int64, error)
 	ListInvoicePayments(ctx context.Context, arInvoiceID int64) ([]ListInvoicePaymentsRow, error)
 	ListPaymentAllocations(ctx context.Context, arPaymentID int64) ([]ArPaymentAllocation, error)
+	ListPeriodSnapshotLines(ctx context.Context, snapshotID int64) ([]ListPeriodSnapshotLinesRow, error)
 	ListPeriods(ctx context.Context, arg ListPeriodsParams) ([]ListPeriodsRow, error)
 	ListPermissions(ctx context.Context) ([]Permission, error)
 	ListRecentPeriods(ctx context.Context, arg ListRecentPeriodsParams) ([]ListRecentPeriodsRow, error)
@@ -215,6 +253,8 @@ type Querier interface {
 	ListRuns(ctx context.Context, arg ListRunsParams) ([]ListRunsRow, error)
 	ListSnapshots(ctx context.Context, arg ListSnapshotsParams) ([]ListSnapshotsRow, error)
 	ListTemplates(ctx context.Context, dollar_1 bool) ([]ListTemplatesRow, error)
+	ListUserCompanyIDs(ctx context.Context, userID int64) ([]int64, error)
+	ListUserRoleNames(ctx context.Context, userID int64) ([]string, error)
 	ListUsers(ctx context.Context) ([]ListUsersRow, error)
 	ListVarianceSnapshots(ctx context.Context, arg ListVarianceSnapshotsParams) ([]ListVarianceSnapshotsRow, error)
 	LoadCloseRun(ctx context.Context, id int64) (LoadCloseRunRow, error)
@@ -223,7 +263,10 @@ type Querier interface {
 	LoadPeriod(ctx context.Context, id int64) (LoadPeriodRow, error)
 	LoadPeriodByLedgerID(ctx context.Context, periodID int64) (LoadPeriodByLedgerIDRow, error)
 	LoadPeriodForUpdate(ctx context.Context, id int64) (LoadPeriodForUpdateRow, error)
+	LoadPeriodSnapshot(ctx context.Context, periodID int64) (LoadPeriodSnapshotRow, error)
+	LockBalancesForUpdate(ctx context.Context, arg LockBalancesForUpdateParams) ([]InventoryBalance, error)
 	LockChecklistItemRun(ctx context.Context, id int64) (int64, error)
+	LockPayableAPInvoices(ctx context.Context, arg LockPayableAPInvoicesParams) ([]LockPayableAPInvoicesRow, error)
 	LookupAccountID(ctx context.Context, code string) (int64, error)
 	MarkFailed(ctx context.Context, arg MarkFailedParams) error
 	MarkInProgress(ctx context.Context, id int64) error
@@ -241,6 +284,10 @@ type Querier interface {
 	PeriodRangeConflict(ctx context.Context, arg PeriodRangeConflictParams) (int32, error)
 	PostAPInvoice(ctx context.Context, arg PostAPInvoiceParams) error
 	PostARInvoice(ctx context.Context, arg PostARInvoiceParams) error
+	// Period revenue and expense per account, split by the branch or warehouse on
+	// each journal line. Lines without that dimension come back with a NULL
+	// dimension_id (the unallocated bucket).
+	ProfitLossByDimension(ctx context.Context, arg ProfitLossByDimensionParams) ([]ProfitLossByDimensionRow, error)
 	RbacCreateRole(ctx context.Context, arg RbacCreateRoleParams) (Role, error)
 	RbacListRoles(ctx context.Context) ([]Role, error)
 	RemoveRoleFromUser(ctx context.Context, arg RemoveRoleFromUserParams) error
@@ -248,7 +295,11 @@ type Querier interface {
 	RolesListRoles(ctx context.Context, arg RolesListRolesParams) ([]Role, error)
 	SavePayload(ctx context.Context, arg SavePayloadParams) error
 	SaveRunSimulation(ctx context.Context, arg SaveRunSimulationParams) error
+	SetBranchGLDimensions(ctx context.Context, arg SetBranchGLDimensionsParams) error
+	SetCompanyDueDatePolicy(ctx context.Context, arg SetCompanyDueDatePolicyParams) error
 	SetPOApproval(ctx context.Context, arg SetPOApprovalParams) error
+	SetUserManager(ctx context.Context, arg SetUserManagerParams) error
+	SetWarehouseGLDimensions(ctx context.Context, arg SetWarehouseGLDimensionsParams) error
 	SoftDeleteProduct(ctx context.Context, arg SoftDeleteProductParams) error
 	SumAccountBalance(ctx context.Context, arg SumAccountBalanceParams) (float64, error)
 	UpdateAPStatus(ctx context.Context, arg UpdateAPStatusParams) error
@@ -264,7 +315,8 @@ type Querier interface {
 	UpdateLineQuantity(ctx context.Context, arg UpdateLineQuantityParams) error
 	UpdatePOStatus(ctx context.Context, arg UpdatePOStatusParams) error
 	UpdatePRStatus(ctx context.Context, arg UpdatePRStatusParams) error
-	UpdateProduct(ctx context.Context, arg UpdateProductParams) error
+	// A NULL expected_updated_at skips the optimistic lock.
+	UpdateProduct(ctx context.Context, arg UpdateProductParams) (int64, error)
 	UpdateQuotationStatus(ctx context.Context, arg UpdateQuotationStatusParams) error
 	UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, error)
 	UpdateRunStatus(ctx context.Context, arg UpdateRunStatusParams) error
@@ -277,8 +329,10 @@ type Querier interface {
 	UpdateUnit(ctx context.Context, arg UpdateUnitParams) error
 	UpdateWarehouse(ctx context.Context, arg UpdateWarehouseParams) error
 	UpsertBalance(ctx context.Context, arg UpsertBalanceParams) error
+	UpsertConsolRefreshWatermark(ctx context.Context, arg UpsertConsolRefreshWatermarkParams) error
 	UpsertFxRate(ctx context.Context, arg UpsertFxRateParams) error
 	UserEffectivePermissions(ctx context.Context, userID int64) ([]string, error)
+	UserExists(ctx context.Context, id int64) (bool, error)
 	VarGetRule(ctx context.Context, id int64) (VarGetRuleRow, error)
 	VarInsertRule(ctx context.Context, arg VarInsertRuleParams) (VarInsertRuleRow, error)
 	VarListRules(ctx context.Context, companyID int64) ([]VarListRulesRow, error)
@@ -9,6 +9,22 @@ import (
 	"context"
 )
 
+const addUserCompany = `-- name: AddUserCompany :exec
+INSERT INTO user_companies (user_id, company_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type AddUserCompanyParams struct {
+	UserID    int64 `json:"user_id"`
+	CompanyID int64 `json:"company_id"`
+}
+
+func (q *Queries) AddUserCompany(ctx context.Context, arg AddUserCompanyParams) error {
+	_, err := q.db.Exec(ctx, addUserCompany, arg.UserID, arg.CompanyID)
+	return err
+}
+
 const assignRoleToUser = `-- name: AssignRoleToUser :exec
 INSERT INTO user_roles (user_id, role_id)
 VALUES ($1, $2)
@@ -72,6 +88,16 @@ func (q *Queries) DeleteRole(ctx context.Context, id int64) (int64, error) {
 	return result.RowsAffected(), nil
 }
 
+const deleteUserCompanies = `-- name: DeleteUserCompanies :exec
+DELETE FROM user_companies
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserCompanies(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserCompanies, userID)
+	return err
+}
+
 const detachPermissionFromRole = `-- name: DetachPermissionFromRole :exec
 DELETE FROM role_permissions
 WHERE role_id = $1 AND permission_id = $2
@@ -160,6 +186,61 @@ func (q *Queries) ListRolePermissions(ctx context.Context, roleID int64) ([]Perm
 	return items, nil
 }
 
+const listUserCompanyIDs = `-- name: ListUserCompanyIDs :many
+SELECT company_id
+FROM user_companies
+WHERE user_id = $1
+ORDER BY company_id
+`
+
+func (q *Queries) ListUserCompanyIDs(ctx context.Context, userID int64) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listUserCompanyIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var company_id int64
+		if err := rows.Scan(&company_id); err != nil {
+			return nil, err
+		}
+		items = append(items, company_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserRoleNames = `-- name: ListUserRoleNames :many
+SELECT r.name
+FROM user_roles ur
+JOIN roles r ON r.id = ur.role_id
+WHERE ur.user_id = $1
+ORDER BY r.name
+`
+
+func (q *Queries) ListUserRoleNames(ctx context.Context, userID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listUserRoleNames, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const rbacCreateRole = `-- name: RbacCreateRole :one
 INSERT INTO roles (name, description)
 VALUES ($1, $2)
@@ -15,8 +15,8 @@ const createCustomer = `-- name: CreateCustomer :one
 INSERT INTO customers (
     code, name, company_id, email, phone, tax_id,
     credit_limit, payment_terms_days, address_line1, address_line2,
-    city, state, postal_code, country, is_active, notes, created_by
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+    city, state, postal_code, country, is_active, notes, created_by, language
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING id
 `
 
@@ -38,6 +38,7 @@ type CreateCustomerParams struct {
 	IsActive         bool           `json:"is_active"`
 	Notes            pgtype.Text    `json:"notes"`
 	CreatedBy        int64          `json:"created_by"`
+	Language         string         `json:"language"`
 }
 
 func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams) (int64, error) {
@@ -59,6 +60,7 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 		arg.IsActive,
 		arg.Notes,
 		arg.CreatedBy,
+		arg.Language,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -68,24 +70,27 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 const createQuotation = `-- name: CreateQuotation :one
 INSERT INTO quotations (
     doc_number, company_id, customer_id, quote_date, valid_until,
-    status, currency, subtotal, tax_amount, total_amount, notes, created_by
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    status, currency, subtotal, tax_amount, total_amount, notes, created_by,
+    document_discount_percent, document_discount_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING id
 `
 
 type CreateQuotationParams struct {
-	DocNumber   string          `json:"doc_number"`
-	CompanyID   int64           `json:"company_id"`
-	CustomerID  int64           `json:"customer_id"`
-	QuoteDate   pgtype.Date     `json:"quote_date"`
-	ValidUntil  pgtype.Date     `json:"valid_until"`
-	Status      QuotationStatus `json:"status"`
-	Currency    string          `json:"currency"`
-	Subtotal    pgtype.Numeric  `json:"subtotal"`
-	TaxAmount   pgtype.Numeric  `json:"tax_amount"`
-	TotalAmount pgtype.Numeric  `json:"total_amount"`
-	Notes       pgtype.Text     `json:"notes"`
-	CreatedBy   int64           `json:"created_by"`
+	DocNumber               string          `json:"doc_number"`
+	CompanyID               int64           `json:"company_id"`
+	CustomerID              int64           `json:"customer_id"`
+	QuoteDate               pgtype.Date     `json:"quote_date"`
+	ValidUntil              pgtype.Date     `json:"valid_until"`
+	Status                  QuotationStatus `json:"status"`
+	Currency                string          `json:"currency"`
+	Subtotal                pgtype.Numeric  `json:"subtotal"`
+	TaxAmount               pgtype.Numeric  `json:"tax_amount"`
+	TotalAmount             pgtype.Numeric  `json:"total_amount"`
+	Notes                   pgtype.Text     `json:"notes"`
+	CreatedBy               int64           `json:"created_by"`
+	DocumentDiscountPercent pgtype.Numeric  `json:"document_discount_percent"`
+	DocumentDiscountAmount  pgtype.Numeric  `json:"document_discount_amount"`
 }
 
 func (q *Queries) CreateQuotation(ctx context.Context, arg CreateQuotationParams) (int64, error) {
@@ -102,6 +107,8 @@ func (q *Queries) CreateQuotation(ctx context.Context, arg CreateQuotationParams
 		arg.TotalAmount,
 		arg.Notes,
 		arg.CreatedBy,
+		arg.DocumentDiscountPercent,
+		arg.DocumentDiscountAmount,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -112,25 +119,28 @@ const createSalesOrder = `-- name: CreateSalesOrder :one
 INSERT INTO sales_orders (
     doc_number, company_id, customer_id, quotation_id, order_date,
     expected_delivery_date, status, currency, subtotal, tax_amount,
-    total_amount, notes, created_by
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    total_amount, notes, created_by, document_discount_percent,
+    document_discount_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 RETURNING id
 `
 
 type CreateSalesOrderParams struct {
-	DocNumber            string           `json:"doc_number"`
-	CompanyID            int64            `json:"company_id"`
-	CustomerID           int64            `json:"customer_id"`
-	QuotationID          pgtype.Int8      `json:"quotation_id"`
-	OrderDate            pgtype.Date      `json:"order_date"`
-	ExpectedDeliveryDate pgtype.Date      `json:"expected_delivery_date"`
-	Status               SalesOrderStatus `json:"status"`
-	Currency             string           `json:"currency"`
-	Subtotal             pgtype.Numeric   `json:"subtotal"`
-	TaxAmount            pgtype.Numeric   `json:"tax_amount"`
-	TotalAmount          pgtype.Numeric   `json:"total_amount"`
-	Notes                pgtype.Text      `json:"notes"`
-	CreatedBy            int64            `json:"created_by"`
+	DocNumber               string           `json:"doc_number"`
+	CompanyID               int64            `json:"company_id"`
+	CustomerID              int64            `json:"customer_id"`
+	QuotationID             pgtype.Int8      `json:"quotation_id"`
+	OrderDate               pgtype.Date      `json:"order_date"`
+	ExpectedDeliveryDate    pgtype.Date      `json:"expected_delivery_date"`
+	Status                  SalesOrderStatus `json:"status"`
+	Currency                string           `json:"currency"`
+	Subtotal                pgtype.Numeric   `json:"subtotal"`
+	TaxAmount               pgtype.Numeric   `json:"tax_amount"`
+	TotalAmount             pgtype.Numeric   `json:"total_amount"`
+	Notes                   pgtype.Text      `json:"notes"`
+	CreatedBy               int64            `json:"created_by"`
+	DocumentDiscountPercent pgtype.Numeric   `json:"document_discount_percent"`
+	DocumentDiscountAmount  pgtype.Numeric   `json:"document_discount_amount"`
 }
 
 func (q *Queries) CreateSalesOrder(ctx context.Context, arg CreateSalesOrderParams) (int64, error) {
@@ -148,6 +158,8 @@ func (q *Queries) CreateSalesOrder(ctx context.Context, arg CreateSalesOrderPara
 		arg.TotalAmount,
 		arg.Notes,
 		arg.CreatedBy,
+		arg.DocumentDiscountPercent,
+		arg.DocumentDiscountAmount,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -177,7 +189,7 @@ const getCustomer = `-- name: GetCustomer :one
 SELECT id, code, name, company_id, email, phone, tax_id,
        credit_limit, payment_terms_days, address_line1, address_line2,
        city, state, postal_code, country, is_active, notes,
-       created_by, created_at, updated_at
+       created_by, created_at, updated_at, language
 FROM customers
 WHERE id = $1
 `
@@ -209,6 +221,7 @@ func (q *Queries) GetCustomer(ctx context.Context, id int64) (Customer, error) {
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Language,
 	)
 	return i, err
 }
@@ -217,7 +230,7 @@ const getCustomerByCode = `-- name: GetCustomerByCode :one
 SELECT id, code, name, company_id, email, phone, tax_id,
        credit_limit, payment_terms_days, address_line1, address_line2,
        city, state, postal_code, country, is_active, notes,
-       created_by, created_at, updated_at
+       created_by, created_at, updated_at, language
 FROM customers
 WHERE company_id = $1 AND code = $2
 `
@@ -251,6 +264,7 @@ func (q *Queries) GetCustomerByCode(ctx context.Context, arg GetCustomerByCodePa
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Language,
 	)
 	return i, err
 }
@@ -260,7 +274,8 @@ const getQuotation = `-- name: GetQuotation :one
 SELECT id, doc_number, company_id, customer_id, quote_date, valid_until,
        status, currency, subtotal, tax_amount, total_amount, notes,
        created_by, approved_by, approved_at, rejected_by, rejected_at,
-       rejection_reason, created_at, updated_at
+       rejection_reason, created_at, updated_at,
+       document_discount_percent, document_discount_amount, loss_reason
 FROM quotations
 WHERE id = $1
 `
@@ -292,6 +307,9 @@ func (q *Queries) GetQuotation(ctx context.Context, id int64) (Quotation, error)
 		&i.RejectionReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DocumentDiscountPercent,
+		&i.DocumentDiscountAmount,
+		&i.LossReason,
 	)
 	return i, err
 }
@@ -300,7 +318,8 @@ const getQuotationByDocNumber = `-- name: GetQuotationByDocNumber :one
 SELECT id, doc_number, company_id, customer_id, quote_date, valid_until,
        status, currency, subtotal, tax_amount, total_amount, notes,
        created_by, approved_by, approved_at, rejected_by, rejected_at,
-       rejection_reason, created_at, updated_at
+       rejection_reason, created_at, updated_at,
+       document_discount_percent, document_discount_amount, loss_reason
 FROM quotations
 WHERE doc_number = $1
 `
@@ -329,6 +348,9 @@ func (q *Queries) GetQuotationByDocNumber(ctx context.Context, docNumber string)
 		&i.RejectionReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DocumentDiscountPercent,
+		&i.DocumentDiscountAmount,
+		&i.LossReason,
 	)
 	return i, err
 }
@@ -336,7 +358,8 @@ func (q *Queries) GetQuotationByDocNumber(ctx context.Context, docNumber string)
 const getQuotationLines = `-- name: GetQuotationLines :many
 SELECT id, quotation_id, product_id, description, quantity, uom,
        unit_price, discount_percent, discount_amount, tax_percent,
-       tax_amount, line_total, notes, line_order, created_at, updated_at
+       tax_amount, line_total, notes, line_order, created_at, updated_at,
+       document_discount_amount
 FROM quotation_lines
 WHERE quotation_id = $1
 ORDER BY line_order, id
@@ -368,6 +391,7 @@ func (q *Queries) GetQuotationLines(ctx context.Context, quotationID int64) ([]Q
 			&i.LineOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DocumentDiscountAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -384,7 +408,8 @@ const getSalesOrder = `-- name: GetSalesOrder :one
 SELECT id, doc_number, company_id, customer_id, quotation_id, order_date,
        expected_delivery_date, status, currency, subtotal, tax_amount, total_amount,
        notes, created_by, confirmed_by, confirmed_at, cancelled_by, cancelled_at,
-       cancellation_reason, created_at, updated_at
+       cancellation_reason, created_at, updated_at,
+       document_discount_percent, document_discount_amount
 FROM sales_orders
 WHERE id = $1
 `
@@ -417,6 +442,8 @@ func (q *Queries) GetSalesOrder(ctx context.Context, id int64) (SalesOrder, erro
 		&i.CancellationReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DocumentDiscountPercent,
+		&i.DocumentDiscountAmount,
 	)
 	return i, err
 }
@@ -425,7 +452,8 @@ const getSalesOrderByDocNumber = `-- name: GetSalesOrderByDocNumber :one
 SELECT id, doc_number, company_id, customer_id, quotation_id, order_date,
        expected_delivery_date, status, currency, subtotal, tax_amount, total_amount,
        notes, created_by, confirmed_by, confirmed_at, cancelled_by, cancelled_at,
-       cancellation_reason, created_at, updated_at
+       cancellation_reason, created_at, updated_at,
+       document_discount_percent, document_discount_amount
 FROM sales_orders
 WHERE doc_number = $1
 `
@@ -455,6 +483,8 @@ func (q *Queries) GetSalesOrderByDocNumber(ctx context.Context, docNumber string
 		&i.CancellationReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DocumentDiscountPercent,
+		&i.DocumentDiscountAmount,
 	)
 	return i, err
 }
@@ -463,7 +493,8 @@ const getSalesOrderLines = `-- name: GetSalesOrderLines :many
 SELECT id, sales_order_id, product_id, description, quantity,
        quantity_delivered, quantity_invoiced, uom, unit_price,
        discount_percent, discount_amount, tax_percent, tax_amount,
-       line_total, notes, line_order, created_at, updated_at
+       line_total, notes, line_order, created_at, updated_at,
+       document_discount_amount
 FROM sales_order_lines
 WHERE sales_order_id = $1
 ORDER BY line_order, id
@@ -497,6 +528,7 @@ func (q *Queries) GetSalesOrderLines(ctx context.Context, salesOrderID int64) ([
 			&i.LineOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DocumentDiscountAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -512,25 +544,26 @@ const insertQuotationLine = `-- name: InsertQuotationLine :one
 INSERT INTO quotation_lines (
     quotation_id, product_id, description, quantity, uom,
     unit_price, discount_percent, discount_amount, tax_percent,
-    tax_amount, line_total, notes, line_order
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    tax_amount, line_total, notes, line_order, document_discount_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING id
 `
 
 type InsertQuotationLineParams struct {
-	QuotationID     int64          `json:"quotation_id"`
-	ProductID       int64          `json:"product_id"`
-	Description     pgtype.Text    `json:"description"`
-	Quantity        pgtype.Numeric `json:"quantity"`
-	Uom             string         `json:"uom"`
-	UnitPrice       pgtype.Numeric `json:"unit_price"`
-	DiscountPercent pgtype.Numeric `json:"discount_percent"`
-	DiscountAmount  pgtype.Numeric `json:"discount_amount"`
-	TaxPercent      pgtype.Numeric `json:"tax_percent"`
-	TaxAmount       pgtype.Numeric `json:"tax_amount"`
-	LineTotal       pgtype.Numeric `json:"line_total"`
-	Notes           pgtype.Text    `json:"notes"`
-	LineOrder       int32          `json:"line_order"`
+	QuotationID            int64          `json:"quotation_id"`
+	ProductID              int64          `json:"product_id"`
+	Description            pgtype.Text    `json:"description"`
+	Quantity               pgtype.Numeric `json:"quantity"`
+	Uom                    string         `json:"uom"`
+	UnitPrice              pgtype.Numeric `json:"unit_price"`
+	DiscountPercent        pgtype.Numeric `json:"discount_percent"`
+	DiscountAmount         pgtype.Numeric `json:"discount_amount"`
+	TaxPercent             pgtype.Numeric `json:"tax_percent"`
+	TaxAmount              pgtype.Numeric `json:"tax_amount"`
+	LineTotal              pgtype.Numeric `json:"line_total"`
+	Notes                  pgtype.Text    `json:"notes"`
+	LineOrder              int32          `json:"line_order"`
+	DocumentDiscountAmount pgtype.Numeric `json:"document_discount_amount"`
 }
 
 func (q *Queries) InsertQuotationLine(ctx context.Context, arg InsertQuotationLineParams) (int64, error) {
@@ -548,6 +581,7 @@ func (q *Queries) InsertQuotationLine(ctx context.Context, arg InsertQuotationLi
 		arg.LineTotal,
 		arg.Notes,
 		arg.LineOrder,
+		arg.DocumentDiscountAmount,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -559,27 +593,28 @@ INSERT INTO sales_order_lines (
     sales_order_id, product_id, description, quantity,
     quantity_delivered, quantity_invoiced, uom, unit_price,
     discount_percent, discount_amount, tax_percent, tax_amount,
-    line_total, notes, line_order
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+    line_total, notes, line_order, document_discount_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 RETURNING id
 `
 
 type InsertSalesOrderLineParams struct {
-	SalesOrderID      int64          `json:"sales_order_id"`
-	ProductID         int64          `json:"product_id"`
-	Description       pgtype.Text    `json:"description"`
-	Quantity          pgtype.Numeric `json:"quantity"`
-	QuantityDelivered pgtype.Numeric `json:"quantity_delivered"`
-	QuantityInvoiced  pgtype.Numeric `json:"quantity_invoiced"`
-	Uom               string         `json:"uom"`
-	UnitPrice         pgtype.Numeric `json:"unit_price"`
-	DiscountPercent   pgtype.Numeric `json:"discount_percent"`
-	DiscountAmount    pgtype.Numeric `json:"discount_amount"`
-	TaxPercent        pgtype.Numeric `json:"tax_percent"`
-	TaxAmount         pgtype.Numeric `json:"tax_amount"`
-	LineTotal         pgtype.Numeric `json:"line_total"`
-	Notes             pgtype.Text    `json:"notes"`
-	LineOrder         int32          `json:"line_order"`
+	SalesOrderID           int64          `json:"sales_order_id"`
+	ProductID              int64          `json:"product_id"`
+	Description            pgtype.Text    `json:"description"`
+	Quantity               pgtype.Numeric `json:"quantity"`
+	QuantityDelivered      pgtype.Numeric `json:"quantity_delivered"`
+	QuantityInvoiced       pgtype.Numeric `json:"quantity_invoiced"`
+	Uom                    string         `json:"uom"`
+	UnitPrice              pgtype.Numeric `json:"unit_price"`
+	DiscountPercent        pgtype.Numeric `json:"discount_percent"`
+	DiscountAmount         pgtype.Numeric `json:"discount_amount"`
+	TaxPercent             pgtype.Numeric `json:"tax_percent"`
+	TaxAmount              pgtype.Numeric `json:"tax_amount"`
+	LineTotal              pgtype.Numeric `json:"line_total"`
+	Notes                  pgtype.Text    `json:"notes"`
+	LineOrder              int32          `json:"line_order"`
+	DocumentDiscountAmount pgtype.Numeric `json:"document_discount_amount"`
 }
 
 func (q *Queries) InsertSalesOrderLine(ctx context.Context, arg InsertSalesOrderLineParams) (int64, error) {
@@ -599,6 +634,7 @@ func (q *Queries) InsertSalesOrderLine(ctx context.Context, arg InsertSalesOrder
 		arg.LineTotal,
 		arg.Notes,
 		arg.LineOrder,
+		arg.DocumentDiscountAmount,
 	)
 	var id int64
 	err := row.Scan(&id)
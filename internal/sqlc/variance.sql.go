@@ -15,7 +15,9 @@ const aggregateBalances = `-- name: AggregateBalances :many
 SELECT acc.code, acc.name, SUM(jl.debit - jl.credit)::float8 AS amount
 FROM journal_lines jl
 JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
-JOIN accounts acc ON acc.id = jl.account_id
+JOIN accounts posted ON posted.id = jl.account_id
+LEFT JOIN accounts own ON posted.company_id IS NULL AND own.company_id = jl.dim_company_id AND own.code = posted.code
+JOIN accounts acc ON acc.id = COALESCE(own.id, posted.id)
 JOIN accounting_periods ap ON ap.id = $1
 WHERE je.period_id = ap.period_id AND COALESCE(jl.dim_company_id, 0) = $2
 GROUP BY acc.code, acc.name
@@ -0,0 +1,91 @@
+package users
+
+import (
+	"context"
+	"errors"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+// ErrUnknownCompany is returned when a user is scoped to a company that does
+// not exist.
+var ErrUnknownCompany = errors.New("unknown company")
+
+// CompanyAssigner reads and replaces the companies a user is scoped to. The
+// RBAC service implements it.
+type CompanyAssigner interface {
+	UserCompanyIDs(ctx context.Context, userID int64) ([]int64, error)
+	SetUserCompanies(ctx context.Context, userID int64, companyIDs []int64) error
+}
+
+// SetCompanyAssigner enables changing users' company scope.
+func (s *Service) SetCompanyAssigner(assigner CompanyAssigner) {
+	s.scoper = assigner
+}
+
+// Companies lists the companies the current user may scope other users to.
+func (s *Service) Companies(ctx context.Context) ([]CompanyOption, error) {
+	companies, err := s.repo.ListCompanies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CompanyOption, 0, len(companies))
+	for _, company := range companies {
+		if shared.CompanyAllowed(ctx, company.ID) {
+			out = append(out, company)
+		}
+	}
+	return out, nil
+}
+
+// SetUserCompanies replaces the companies a user is scoped to. Administrators
+// can only grant companies inside their own scope, and the user's companies
+// outside it are kept as they are.
+func (s *Service) SetUserCompanies(ctx context.Context, userID int64, companyIDs []int64) error {
+	if s.scoper == nil {
+		return errors.New("users: company assignment not configured")
+	}
+	exists, err := s.repo.UserExists(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	companies, err := s.repo.ListCompanies(ctx)
+	if err != nil {
+		return err
+	}
+	known := make(map[int64]bool, len(companies))
+	for _, company := range companies {
+		known[company.ID] = true
+	}
+	seen := make(map[int64]bool, len(companyIDs))
+	ids := make([]int64, 0, len(companyIDs))
+	for _, id := range companyIDs {
+		if seen[id] {
+			continue
+		}
+		if !known[id] {
+			return ErrUnknownCompany
+		}
+		if !shared.CompanyAllowed(ctx, id) {
+			return shared.ErrForbidden
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if !shared.CompanyScopeFromContext(ctx).Unrestricted {
+		current, err := s.scoper.UserCompanyIDs(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, id := range current {
+			if !seen[id] && !shared.CompanyAllowed(ctx, id) {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return s.scoper.SetUserCompanies(ctx, userID, ids)
+}
@@ -0,0 +1,92 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+)
+
+type fakeCompanyRepo struct {
+	RepositoryPort
+	companies []CompanyOption
+}
+
+func (f *fakeCompanyRepo) UserExists(_ context.Context, id int64) (bool, error) {
+	return id == 7, nil
+}
+
+func (f *fakeCompanyRepo) ListCompanies(context.Context) ([]CompanyOption, error) {
+	return f.companies, nil
+}
+
+type fakeCompanyAssigner struct {
+	current []int64
+	set     []int64
+}
+
+func (f *fakeCompanyAssigner) UserCompanyIDs(context.Context, int64) ([]int64, error) {
+	return f.current, nil
+}
+
+func (f *fakeCompanyAssigner) SetUserCompanies(_ context.Context, _ int64, ids []int64) error {
+	f.set = ids
+	return nil
+}
+
+func newCompanyService(current ...int64) (*Service, *fakeCompanyAssigner) {
+	svc := NewService(&fakeCompanyRepo{companies: []CompanyOption{
+		{ID: 1, Code: "MAJU"}, {ID: 2, Code: "SUB"}, {ID: 3, Code: "EXT"},
+	}})
+	assigner := &fakeCompanyAssigner{current: current}
+	svc.SetCompanyAssigner(assigner)
+	return svc, assigner
+}
+
+func TestSetUserCompaniesValidatesCompanies(t *testing.T) {
+	svc, assigner := newCompanyService()
+	ctx := context.Background()
+
+	if err := svc.SetUserCompanies(ctx, 7, []int64{2, 1, 2}); err != nil {
+		t.Fatalf("SetUserCompanies: %v", err)
+	}
+	if !reflect.DeepEqual(assigner.set, []int64{2, 1}) {
+		t.Fatalf("expected deduplicated companies [2 1], got %v", assigner.set)
+	}
+	if err := svc.SetUserCompanies(ctx, 7, []int64{9}); !errors.Is(err, ErrUnknownCompany) {
+		t.Fatalf("expected ErrUnknownCompany, got %v", err)
+	}
+	if err := svc.SetUserCompanies(ctx, 8, []int64{1}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestSetUserCompaniesStaysInsideAdminScope(t *testing.T) {
+	// The user already belongs to MAJU and EXT; the admin only sees MAJU
+	// and SUB.
+	svc, assigner := newCompanyService(1, 3)
+	ctx := shared.ContextWithCompanyScope(context.Background(), shared.CompanyScope{CompanyIDs: []int64{1, 2}})
+
+	if err := svc.SetUserCompanies(ctx, 7, []int64{3}); !errors.Is(err, shared.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for a company outside the admin's scope, got %v", err)
+	}
+	if assigner.set != nil {
+		t.Fatalf("nothing should be saved, got %v", assigner.set)
+	}
+	if err := svc.SetUserCompanies(ctx, 7, []int64{2}); err != nil {
+		t.Fatalf("SetUserCompanies: %v", err)
+	}
+	if !reflect.DeepEqual(assigner.set, []int64{2, 3}) {
+		t.Fatalf("expected SUB plus the untouched EXT, got %v", assigner.set)
+	}
+
+	companies, err := svc.Companies(ctx)
+	if err != nil {
+		t.Fatalf("Companies: %v", err)
+	}
+	if len(companies) != 2 || companies[0].ID != 1 || companies[1].ID != 2 {
+		t.Fatalf("expected only the admin's companies, got %+v", companies)
+	}
+}
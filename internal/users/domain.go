@@ -8,6 +8,36 @@ type User struct {
 	Email     string
 	Name      string
 	IsActive  bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ManagerID int64
+	// CompanyIDs are the companies the user is scoped to.
+	CompanyIDs []int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// HasCompany reports whether the user is scoped to the company.
+func (u User) HasCompany(companyID int64) bool {
+	for _, id := range u.CompanyIDs {
+		if id == companyID {
+			return true
+		}
+	}
+	return false
+}
+
+// CompanyOption is a company users can be scoped to.
+type CompanyOption struct {
+	ID   int64
+	Code string
+	Name string
+}
+
+// CreateUserInput describes a new user account.
+type CreateUserInput struct {
+	Email    string
+	Name     string
+	Password string
+	// MustChangePassword makes the user pick their own password at first
+	// login.
+	MustChangePassword bool
 }
@@ -1,11 +1,17 @@
 package users
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/auth"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/csvimport"
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -35,6 +41,14 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Use(h.rbac.RequireAny(shared.PermUsersEdit))
 		r.Get("/new", h.showCreateUserForm)
 		r.Post("/", h.createUser)
+		r.Post("/{id}/manager", h.updateManager)
+		r.Post("/{id}/companies", h.updateCompanies)
+	})
+	// Imports assign roles, so they also need the right to manage roles.
+	r.Group(func(r chi.Router) {
+		r.Use(h.rbac.RequireAll(shared.PermUsersEdit, shared.PermRolesEdit))
+		r.Get("/import", h.showImportForm)
+		r.With(csvimport.Guard(importLimits)).Post("/import", h.importUsers)
 	})
 }
 
@@ -43,22 +57,129 @@ type formErrors map[string]string
 func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := h.service.ListUsers(r.Context())
 	if err != nil {
-		h.logger.Error("list users failed", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "list users failed", slog.Any("error", err))
 		h.render(w, r, "pages/users/list.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusInternalServerError)
 		return
 	}
-	h.render(w, r, "pages/users/list.html", map[string]any{"Users": users}, http.StatusOK)
+	companies, err := h.service.Companies(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "list companies failed", slog.Any("error", err))
+		h.render(w, r, "pages/users/list.html", map[string]any{"Errors": formErrors{"general": shared.UserSafeMessage(err)}}, http.StatusInternalServerError)
+		return
+	}
+	h.render(w, r, "pages/users/list.html", map[string]any{"Users": users, "Companies": companies}, http.StatusOK)
 }
 
 func (h *Handler) showCreateUserForm(w http.ResponseWriter, r *http.Request) {
-	h.render(w, r, "pages/users/form.html", map[string]any{"Errors": formErrors{}}, http.StatusOK)
+	h.render(w, r, "pages/users/form.html", map[string]any{
+		"Errors": formErrors{},
+		"Form":   CreateUserInput{MustChangePassword: true},
+		"Policy": h.service.PasswordPolicy(),
+	}, http.StatusOK)
 }
 
 func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) {
-	// User creation is not yet implemented - return an honest error
-	h.render(w, r, "pages/users/form.html", map[string]any{
-		"Errors": formErrors{"general": "User creation is not yet implemented. Please contact your administrator."},
-	}, http.StatusNotImplemented)
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	input := CreateUserInput{
+		Email:              r.PostFormValue("email"),
+		Name:               r.PostFormValue("name"),
+		Password:           r.PostFormValue("password"),
+		MustChangePassword: r.PostFormValue("must_change_password") != "",
+	}
+	errs := formErrors{}
+	if input.Password != r.PostFormValue("confirm_password") {
+		errs["confirm_password"] = "Passwords do not match"
+	} else if _, err := h.service.CreateUser(r.Context(), input); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrEmailTaken):
+			errs["email"] = err.Error()
+		case errors.Is(err, auth.ErrPasswordTooShort), errors.Is(err, auth.ErrPasswordTooSimple),
+			errors.Is(err, auth.ErrPasswordCommon):
+			errs["password"] = err.Error()
+		default:
+			h.logger.ErrorContext(r.Context(), "create user", slog.Any("error", err))
+			errs["general"] = shared.UserSafeMessage(err)
+		}
+	}
+	if len(errs) > 0 {
+		input.Password = ""
+		h.render(w, r, "pages/users/form.html", map[string]any{
+			"Errors": errs,
+			"Form":   input,
+			"Policy": h.service.PasswordPolicy(),
+		}, http.StatusBadRequest)
+		return
+	}
+	h.redirectWithFlash(w, r, "/users", "success", "User created")
+}
+
+func (h *Handler) updateManager(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	var managerID *int64
+	if raw := strings.TrimSpace(r.PostFormValue("manager_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			h.redirectWithFlash(w, r, "/users", "error", "Invalid manager")
+			return
+		}
+		managerID = &id
+	}
+	if err := h.service.SetManager(r.Context(), userID, managerID); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrInvalidManager):
+			h.redirectWithFlash(w, r, "/users", "error", err.Error())
+		default:
+			h.logger.ErrorContext(r.Context(), "set user manager", slog.Any("error", err), slog.Int64("user_id", userID))
+			h.redirectWithFlash(w, r, "/users", "error", shared.UserSafeMessage(err))
+		}
+		return
+	}
+	h.redirectWithFlash(w, r, "/users", "success", "Manager updated")
+}
+
+func (h *Handler) updateCompanies(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, r, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	companyIDs := make([]int64, 0, len(r.PostForm["company_ids"]))
+	for _, raw := range r.PostForm["company_ids"] {
+		id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil || id <= 0 {
+			h.redirectWithFlash(w, r, "/users", "error", "Invalid company")
+			return
+		}
+		companyIDs = append(companyIDs, id)
+	}
+	if err := h.service.SetUserCompanies(r.Context(), userID, companyIDs); err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrUnknownCompany):
+			h.redirectWithFlash(w, r, "/users", "error", err.Error())
+		case errors.Is(err, shared.ErrForbidden):
+			h.redirectWithFlash(w, r, "/users", "error", "You can only assign companies in your own scope")
+		default:
+			h.logger.ErrorContext(r.Context(), "set user companies", slog.Any("error", err), slog.Int64("user_id", userID))
+			h.redirectWithFlash(w, r, "/users", "error", shared.UserSafeMessage(err))
+		}
+		return
+	}
+	h.redirectWithFlash(w, r, "/users", "success", "Companies updated")
 }
 
 func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string, data map[string]any, status int) {
@@ -71,7 +192,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, template string
 	viewData := view.TemplateData{Title: "Users", CSRFToken: csrfToken, Flash: flash, CurrentPath: r.URL.Path, Data: data}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, template, viewData); err != nil {
-		h.logger.Error("render template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render template", slog.Any("error", err))
 	}
 }
 
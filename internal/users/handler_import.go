@@ -0,0 +1,56 @@
+package users
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/csvimport"
+)
+
+// importLimits bounds user imports. Every row is hashed with bcrypt, so the
+// row cap stays well below the other imports.
+var importLimits = csvimport.Limits{MaxBytes: 1 << 20, MaxRows: 500, PerMinute: 5}
+
+func (h *Handler) showImportForm(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, "pages/users/import.html", map[string]any{
+		"Errors":  formErrors{},
+		"MaxRows": importLimits.MaxRows,
+	}, http.StatusOK)
+}
+
+// importUsers creates the users of an uploaded CSV and shows the result of
+// every row. Nothing is created when any row is rejected.
+func (h *Handler) importUsers(w http.ResponseWriter, r *http.Request) {
+	body, err := csvimport.OpenUpload(r, "file")
+	if err != nil {
+		csvimport.WriteError(w, importLimits, err)
+		return
+	}
+	rows, err := ParseImport(body, importLimits.MaxRows)
+	var report ImportReport
+	if err == nil {
+		report, err = h.service.ImportUsers(r.Context(), rows)
+	}
+	data := map[string]any{"Errors": formErrors{}, "MaxRows": importLimits.MaxRows}
+	switch {
+	case errors.Is(err, ErrInvalidImport):
+		data["Errors"] = formErrors{"file": err.Error()}
+		h.render(w, r, "pages/users/import.html", data, http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, ErrEmailTaken):
+		data["Errors"] = formErrors{"general": "An email in the file was registered while importing; nothing was created. Upload the file again."}
+		h.render(w, r, "pages/users/import.html", data, http.StatusConflict)
+		return
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "import users", slog.Any("error", err))
+		csvimport.WriteError(w, importLimits, err)
+		return
+	}
+	data["Report"] = report
+	status := http.StatusOK
+	if report.Failed() {
+		status = http.StatusUnprocessableEntity
+	}
+	h.render(w, r, "pages/users/import.html", data, status)
+}
@@ -0,0 +1,349 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/hibiken/asynq"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/csvimport"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+// ErrInvalidImport is returned when the import file cannot be read as users.
+var ErrInvalidImport = errors.New("invalid user import")
+
+// importColumns are the required CSV columns. role and companies take
+// several values separated by ";".
+var importColumns = []string{"email", "name", "role", "companies"}
+
+// tempPasswordAlphabet leaves out characters that are easy to misread.
+const tempPasswordAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789!#%+=?@"
+
+// tempPasswordLength is the length of generated temporary passwords.
+const tempPasswordLength = 16
+
+// ImportRow is one user read from the import file.
+type ImportRow struct {
+	Line      int
+	Email     string
+	Name      string
+	Roles     []string
+	Companies []string
+}
+
+// ImportResult reports what happened to one import row.
+type ImportResult struct {
+	Line   int
+	Email  string
+	UserID int64
+	Errors []string
+	// EmailQueued is false when the credentials email could not be queued;
+	// the user exists but has to be sent a password another way.
+	EmailQueued bool
+}
+
+// ImportReport lists the result of every row. Users are only created when
+// no row has errors.
+type ImportReport struct {
+	Rows    []ImportResult
+	Created int
+}
+
+// Failed reports whether any row was rejected, in which case no user was
+// created.
+func (r ImportReport) Failed() bool {
+	for _, row := range r.Rows {
+		if len(row.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportedUser is a validated import row ready to be stored.
+type ImportedUser struct {
+	Input        CreateUserInput
+	PasswordHash string
+	RoleIDs      []int64
+	CompanyIDs   []int64
+}
+
+// CredentialQueue enqueues the emails carrying imported users' temporary
+// passwords.
+type CredentialQueue interface {
+	EnqueueSendEmail(ctx context.Context, payload jobs.SendEmailPayload) (*asynq.TaskInfo, error)
+}
+
+// AuditPort records the roles and companies given to imported users.
+type AuditPort interface {
+	Record(ctx context.Context, log shared.AuditLog) error
+}
+
+// SetCredentialMailer enables emailing temporary passwords to imported
+// users. The email links to the login page under baseURL.
+func (s *Service) SetCredentialMailer(queue CredentialQueue, baseURL string) {
+	s.mailer = queue
+	if baseURL != "" {
+		s.loginURL = strings.TrimRight(baseURL, "/") + "/auth/login"
+	}
+}
+
+// SetAuditor enables audit entries for imported users' roles and companies.
+func (s *Service) SetAuditor(audit AuditPort) {
+	s.audit = audit
+}
+
+// ParseImport reads users from CSV with the columns email, name, role and
+// companies. Roles are role names and companies are company codes, each
+// separated by ";".
+func ParseImport(r io.Reader, maxRows int) ([]ImportRow, error) {
+	reader := csvimport.NewReader(r, maxRows)
+	header, err := reader.Header()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidImport, name)
+		}
+	}
+	var rows []ImportRow
+	for {
+		record, line, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, ImportRow{
+			Line:      line,
+			Email:     field("email"),
+			Name:      field("name"),
+			Roles:     splitList(field("role")),
+			Companies: splitList(field("companies")),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file has no users", ErrInvalidImport)
+	}
+	return rows, nil
+}
+
+// ImportUsers creates the users of an import in one transaction, each with a
+// temporary password they must change at first login, their roles and their
+// company scope. Every row is checked first: a malformed or duplicate email,
+// an email already in use, an unknown role or company, or a company outside
+// the importer's own scope rejects the whole import, and the report lists
+// the problems per row. Once the users are
+// stored their credentials are emailed.
+func (s *Service) ImportUsers(ctx context.Context, rows []ImportRow) (ImportReport, error) {
+	report := ImportReport{Rows: make([]ImportResult, len(rows))}
+	firstLine := make(map[string]int, len(rows))
+	var emails, roleNames, companyCodes []string
+	for i := range rows {
+		row := &rows[i]
+		row.Email = strings.ToLower(strings.TrimSpace(row.Email))
+		row.Name = strings.TrimSpace(row.Name)
+		result := &report.Rows[i]
+		result.Line, result.Email = row.Line, row.Email
+		if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+			result.Errors = append(result.Errors, ErrInvalidEmail.Error())
+		} else if line, dup := firstLine[row.Email]; dup {
+			result.Errors = append(result.Errors, "email repeats line "+strconv.Itoa(line))
+		} else {
+			firstLine[row.Email] = row.Line
+			emails = append(emails, row.Email)
+		}
+		if row.Name == "" {
+			result.Errors = append(result.Errors, "name is required")
+		}
+		if len(row.Roles) == 0 {
+			result.Errors = append(result.Errors, "at least one role is required")
+		}
+		if len(row.Companies) == 0 {
+			result.Errors = append(result.Errors, "at least one company is required")
+		}
+		roleNames = append(roleNames, row.Roles...)
+		companyCodes = append(companyCodes, row.Companies...)
+	}
+
+	taken, err := s.repo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	roleIDs, err := s.repo.RoleIDsByName(ctx, roleNames)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	companyIDs, err := s.repo.CompanyIDsByCode(ctx, companyCodes)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	users := make([]ImportedUser, len(rows))
+	passwords := make([]string, len(rows))
+	for i, row := range rows {
+		result := &report.Rows[i]
+		if taken[row.Email] {
+			result.Errors = append(result.Errors, ErrEmailTaken.Error())
+		}
+		user := ImportedUser{Input: CreateUserInput{Email: row.Email, Name: row.Name, MustChangePassword: true}}
+		for _, name := range row.Roles {
+			id, ok := roleIDs[strings.ToLower(name)]
+			if !ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("role %q does not exist", name))
+				continue
+			}
+			user.RoleIDs = appendUnique(user.RoleIDs, id)
+		}
+		for _, code := range row.Companies {
+			id, ok := companyIDs[strings.ToUpper(code)]
+			if !ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("company %q does not exist", code))
+				continue
+			}
+			if !shared.CompanyAllowed(ctx, id) {
+				result.Errors = append(result.Errors, fmt.Sprintf("company %q is outside your company scope", code))
+				continue
+			}
+			user.CompanyIDs = appendUnique(user.CompanyIDs, id)
+		}
+		users[i] = user
+	}
+	if report.Failed() {
+		return report, nil
+	}
+
+	for i := range users {
+		password, err := s.temporaryPassword(users[i].Input.Email)
+		if err != nil {
+			return ImportReport{}, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return ImportReport{}, err
+		}
+		passwords[i] = password
+		users[i].PasswordHash = string(hash)
+	}
+	ids, err := s.repo.ImportUsers(ctx, users)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	for i, id := range ids {
+		result := &report.Rows[i]
+		result.UserID = id
+		s.recordImport(ctx, id, rows[i], users[i])
+		result.EmailQueued = s.sendCredentials(ctx, users[i].Input, passwords[i])
+	}
+	report.Created = len(ids)
+	return report, nil
+}
+
+// temporaryPassword draws a random password that meets the policy.
+func (s *Service) temporaryPassword(email string) (string, error) {
+	alphabet := big.NewInt(int64(len(tempPasswordAlphabet)))
+	length := max(tempPasswordLength, s.policy.MinLength)
+	for attempt := 0; attempt < 20; attempt++ {
+		buf := make([]byte, length)
+		for i := range buf {
+			n, err := rand.Int(rand.Reader, alphabet)
+			if err != nil {
+				return "", err
+			}
+			buf[i] = tempPasswordAlphabet[n.Int64()]
+		}
+		if s.policy.Validate(string(buf), email) == nil {
+			return string(buf), nil
+		}
+	}
+	return "", errors.New("users: could not generate a temporary password that meets the policy")
+}
+
+// sendCredentials queues the welcome email with the temporary password and
+// reports whether it was queued.
+func (s *Service) sendCredentials(ctx context.Context, input CreateUserInput, password string) bool {
+	if s.mailer == nil {
+		return false
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", input.Name)
+	body.WriteString("An Odyssey ERP account has been created for you.\n\n")
+	if s.loginURL != "" {
+		fmt.Fprintf(&body, "Sign in at: %s\n", s.loginURL)
+	}
+	fmt.Fprintf(&body, "Email: %s\nTemporary password: %s\n\n", input.Email, password)
+	body.WriteString("You will be asked to choose your own password when you first sign in.\n")
+	_, err := s.mailer.EnqueueSendEmail(ctx, jobs.SendEmailPayload{
+		To:      input.Email,
+		Subject: "Your Odyssey ERP account",
+		Body:    body.String(),
+	})
+	return err == nil
+}
+
+// recordImport audits the roles and companies an imported user starts with.
+func (s *Service) recordImport(ctx context.Context, userID int64, row ImportRow, user ImportedUser) {
+	if s.audit == nil {
+		return
+	}
+	actor := shared.AuditActorFromContext(ctx)
+	entityID := strconv.FormatInt(userID, 10)
+	_ = s.audit.Record(ctx, shared.AuditLog{
+		ActorID:  actor,
+		Action:   "user.role.assign",
+		Entity:   shared.AuditEntityUserRole,
+		EntityID: entityID,
+		Meta:     map[string]any{"import": true, "added": row.Roles},
+		After:    map[string]any{"roles": row.Roles},
+	})
+	_ = s.audit.Record(ctx, shared.AuditLog{
+		ActorID:  actor,
+		Action:   "user.companies.update",
+		Entity:   shared.AuditEntityUserCompany,
+		EntityID: entityID,
+		Meta:     map[string]any{"import": true},
+		After:    map[string]any{"company_ids": user.CompanyIDs},
+	})
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func appendUnique(ids []int64, id int64) []int64 {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,175 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/auth"
+	"github.com/odyssey-erp/odyssey-erp/internal/shared"
+	"github.com/odyssey-erp/odyssey-erp/jobs"
+)
+
+type fakeImportRepo struct {
+	RepositoryPort
+	taken     map[string]bool
+	roles     map[string]int64
+	companies map[string]int64
+	imported  []ImportedUser
+}
+
+func (f *fakeImportRepo) ExistingEmails(_ context.Context, emails []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	for _, email := range emails {
+		if f.taken[email] {
+			out[email] = true
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeImportRepo) RoleIDsByName(context.Context, []string) (map[string]int64, error) {
+	return f.roles, nil
+}
+
+func (f *fakeImportRepo) CompanyIDsByCode(context.Context, []string) (map[string]int64, error) {
+	return f.companies, nil
+}
+
+func (f *fakeImportRepo) ImportUsers(_ context.Context, users []ImportedUser) ([]int64, error) {
+	f.imported = users
+	ids := make([]int64, len(users))
+	for i := range users {
+		ids[i] = int64(100 + i)
+	}
+	return ids, nil
+}
+
+type fakeCredentialQueue struct {
+	sent []jobs.SendEmailPayload
+	fail string
+}
+
+func (f *fakeCredentialQueue) EnqueueSendEmail(_ context.Context, payload jobs.SendEmailPayload) (*asynq.TaskInfo, error) {
+	if payload.To == f.fail {
+		return nil, errors.New("redis down")
+	}
+	f.sent = append(f.sent, payload)
+	return &asynq.TaskInfo{}, nil
+}
+
+func newImportRepo() *fakeImportRepo {
+	return &fakeImportRepo{
+		taken:     map[string]bool{"old@maju.id": true},
+		roles:     map[string]int64{"finance": 2, "sales": 3},
+		companies: map[string]int64{"MAJU": 1, "SUB": 4},
+	}
+}
+
+func TestParseImportRequiresColumns(t *testing.T) {
+	_, err := ParseImport(strings.NewReader("email,name,role\nsiti@maju.id,Siti,Finance\n"), 10)
+	if !errors.Is(err, ErrInvalidImport) {
+		t.Fatalf("expected ErrInvalidImport, got %v", err)
+	}
+	rows, err := ParseImport(strings.NewReader("Email,Name,Role,Companies\nsiti@maju.id,Siti,Finance; Sales ,maju;sub\n"), 10)
+	if err != nil {
+		t.Fatalf("ParseImport: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Line != 2 || len(rows[0].Roles) != 2 || rows[0].Roles[1] != "Sales" || len(rows[0].Companies) != 2 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+}
+
+func TestImportUsersRejectsWholeFileOnAnyBadRow(t *testing.T) {
+	repo := newImportRepo()
+	svc := NewService(repo)
+	queue := &fakeCredentialQueue{}
+	svc.SetCredentialMailer(queue, "https://erp.example.com/")
+
+	report, err := svc.ImportUsers(context.Background(), []ImportRow{
+		{Line: 2, Email: "Siti@Maju.id", Name: "Siti", Roles: []string{"Finance"}, Companies: []string{"maju"}},
+		{Line: 3, Email: "siti@maju.id", Name: "Siti 2", Roles: []string{"Auditor"}, Companies: []string{"XX"}},
+		{Line: 4, Email: "old@maju.id", Name: "", Roles: []string{"Sales"}, Companies: []string{"SUB"}},
+		{Line: 5, Email: "not-an-email", Name: "Budi", Roles: nil, Companies: []string{"SUB"}},
+	})
+	if err != nil {
+		t.Fatalf("ImportUsers: %v", err)
+	}
+	if !report.Failed() || report.Created != 0 || repo.imported != nil || len(queue.sent) != 0 {
+		t.Fatalf("a bad row must stop the whole import, got %+v", report)
+	}
+	if len(report.Rows[0].Errors) != 0 {
+		t.Fatalf("line 2 is valid, got %v", report.Rows[0].Errors)
+	}
+	want := map[int][]string{
+		1: {"email repeats line 2", `role "Auditor" does not exist`, `company "XX" does not exist`},
+		2: {"name is required", ErrEmailTaken.Error()},
+		3: {ErrInvalidEmail.Error(), "at least one role is required"},
+	}
+	for i, errs := range want {
+		if strings.Join(report.Rows[i].Errors, "|") != strings.Join(errs, "|") {
+			t.Fatalf("line %d: expected %v, got %v", report.Rows[i].Line, errs, report.Rows[i].Errors)
+		}
+	}
+}
+
+func TestImportUsersCreatesUsersAndEmailsCredentials(t *testing.T) {
+	repo := newImportRepo()
+	svc := NewService(repo)
+	queue := &fakeCredentialQueue{fail: "budi@maju.id"}
+	svc.SetCredentialMailer(queue, "https://erp.example.com/")
+
+	report, err := svc.ImportUsers(context.Background(), []ImportRow{
+		{Line: 2, Email: "Siti@Maju.id", Name: "Siti", Roles: []string{"Finance", "finance", "Sales"}, Companies: []string{"maju", "SUB"}},
+		{Line: 3, Email: "budi@maju.id", Name: "Budi", Roles: []string{"Sales"}, Companies: []string{"SUB"}},
+	})
+	if err != nil {
+		t.Fatalf("ImportUsers: %v", err)
+	}
+	if report.Failed() || report.Created != 2 || len(repo.imported) != 2 {
+		t.Fatalf("expected two users, got %+v", report)
+	}
+	siti := repo.imported[0]
+	if siti.Input.Email != "siti@maju.id" || !siti.Input.MustChangePassword {
+		t.Fatalf("unexpected user %+v", siti.Input)
+	}
+	if len(siti.RoleIDs) != 2 || len(siti.CompanyIDs) != 2 {
+		t.Fatalf("expected deduplicated roles and both companies, got %+v", siti)
+	}
+	if len(queue.sent) != 1 || queue.sent[0].To != "siti@maju.id" || !strings.Contains(queue.sent[0].Body, "https://erp.example.com/auth/login") {
+		t.Fatalf("expected one credentials email, got %+v", queue.sent)
+	}
+	password := strings.TrimSpace(strings.SplitN(strings.SplitN(queue.sent[0].Body, "Temporary password: ", 2)[1], "\n", 2)[0])
+	if err := auth.DefaultPasswordPolicy().Validate(password, "siti@maju.id"); err != nil {
+		t.Fatalf("temporary password must meet the policy: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(siti.PasswordHash), []byte(password)) != nil {
+		t.Fatal("the emailed password must match the stored hash")
+	}
+	if !report.Rows[0].EmailQueued || report.Rows[1].EmailQueued || report.Rows[1].UserID != 101 {
+		t.Fatalf("unexpected row results %+v", report.Rows)
+	}
+}
+
+func TestImportUsersRejectsCompaniesOutsideImporterScope(t *testing.T) {
+	repo := newImportRepo()
+	svc := NewService(repo)
+	ctx := shared.ContextWithCompanyScope(context.Background(), shared.CompanyScope{CompanyIDs: []int64{1}})
+
+	report, err := svc.ImportUsers(ctx, []ImportRow{
+		{Line: 2, Email: "siti@maju.id", Name: "Siti", Roles: []string{"Finance"}, Companies: []string{"MAJU", "SUB"}},
+	})
+	if err != nil {
+		t.Fatalf("ImportUsers: %v", err)
+	}
+	if !report.Failed() || repo.imported != nil {
+		t.Fatalf("a company outside the scope must stop the import, got %+v", report)
+	}
+	if got := strings.Join(report.Rows[0].Errors, "|"); got != `company "SUB" is outside your company scope` {
+		t.Fatalf("unexpected errors %q", got)
+	}
+}
@@ -2,7 +2,10 @@ package users
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odyssey-erp/odyssey-erp/internal/sqlc"
 )
@@ -35,9 +38,51 @@ func (r *Repository) ListUsers(ctx context.Context) ([]User, error) {
 			Email:     row.Email,
 			Name:      row.Name,
 			IsActive:  row.IsActive,
+			ManagerID: row.ManagerID.Int64,
 			CreatedAt: row.CreatedAt.Time,
 			UpdatedAt: row.UpdatedAt.Time,
 		}
 	}
 	return users, nil
 }
+
+// UserExists reports whether the user exists.
+func (r *Repository) UserExists(ctx context.Context, id int64) (bool, error) {
+	return r.queries.UserExists(ctx, id)
+}
+
+// SetManager stores the user's manager; nil clears it.
+func (r *Repository) SetManager(ctx context.Context, userID int64, managerID *int64) error {
+	param := pgtype.Int8{}
+	if managerID != nil {
+		param = pgtype.Int8{Int64: *managerID, Valid: true}
+	}
+	return r.queries.SetUserManager(ctx, sqlc.SetUserManagerParams{ID: userID, ManagerID: param})
+}
+
+// CreateUser inserts the user and records the initial password in the
+// password history.
+func (r *Repository) CreateUser(ctx context.Context, input CreateUserInput, passwordHash string) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (email, name, password_hash, is_active, must_change_password)
+		VALUES ($1, $2, $3, TRUE, $4)
+		ON CONFLICT (email) DO NOTHING
+		RETURNING id`, input.Email, input.Name, passwordHash, input.MustChangePassword).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrEmailTaken
+	}
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_password_history (user_id, password_hash) VALUES ($1, $2)`, id, passwordHash); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit(ctx)
+}
@@ -0,0 +1,39 @@
+package users
+
+import "context"
+
+// ListCompanies returns every company ordered by code.
+func (r *Repository) ListCompanies(ctx context.Context) ([]CompanyOption, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id::BIGINT, code, name FROM companies ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []CompanyOption
+	for rows.Next() {
+		var company CompanyOption
+		if err := rows.Scan(&company.ID, &company.Code, &company.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, company)
+	}
+	return out, rows.Err()
+}
+
+// UserCompanyIDs returns every user's assigned companies keyed by user ID.
+func (r *Repository) UserCompanyIDs(ctx context.Context) (map[int64][]int64, error) {
+	rows, err := r.pool.Query(ctx, `SELECT user_id, company_id FROM user_companies ORDER BY user_id, company_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[int64][]int64{}
+	for rows.Next() {
+		var userID, companyID int64
+		if err := rows.Scan(&userID, &companyID); err != nil {
+			return nil, err
+		}
+		out[userID] = append(out[userID], companyID)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,113 @@
+package users
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ExistingEmails returns which of the lower-cased emails belong to a user.
+func (r *Repository) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	rows, err := r.pool.Query(ctx, `SELECT LOWER(email) FROM users WHERE LOWER(email) = ANY($1)`, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]bool)
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		out[email] = true
+	}
+	return out, rows.Err()
+}
+
+// RoleIDsByName resolves role names case-insensitively, keyed by the
+// lower-cased name.
+func (r *Repository) RoleIDsByName(ctx context.Context, names []string) (map[string]int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT LOWER(name), id FROM roles
+		WHERE LOWER(name) IN (SELECT LOWER(n) FROM UNNEST($1::TEXT[]) AS n)`, names)
+	if err != nil {
+		return nil, err
+	}
+	return scanIDs(rows)
+}
+
+// CompanyIDsByCode resolves company codes case-insensitively, keyed by the
+// upper-cased code.
+func (r *Repository) CompanyIDsByCode(ctx context.Context, codes []string) (map[string]int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT UPPER(code), id::BIGINT FROM companies
+		WHERE UPPER(code) IN (SELECT UPPER(c) FROM UNNEST($1::TEXT[]) AS c)`, codes)
+	if err != nil {
+		return nil, err
+	}
+	return scanIDs(rows)
+}
+
+// ImportUsers inserts the users with their password history, roles and
+// companies in one transaction and returns their IDs in order. An email
+// taken since it was checked rolls the whole import back with ErrEmailTaken.
+func (r *Repository) ImportUsers(ctx context.Context, users []ImportedUser) ([]int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+	ids := make([]int64, 0, len(users))
+	for _, user := range users {
+		var id int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO users (email, name, password_hash, is_active, must_change_password)
+			VALUES ($1, $2, $3, TRUE, $4)
+			ON CONFLICT (email) DO NOTHING
+			RETURNING id`, user.Input.Email, user.Input.Name, user.PasswordHash, user.Input.MustChangePassword).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEmailTaken
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_password_history (user_id, password_hash) VALUES ($1, $2)`, id, user.PasswordHash); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_roles (user_id, role_id)
+			SELECT $1, UNNEST($2::BIGINT[])
+			ON CONFLICT DO NOTHING`, id, user.RoleIDs); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_companies (user_id, company_id)
+			SELECT $1, UNNEST($2::BIGINT[])
+			ON CONFLICT DO NOTHING`, id, user.CompanyIDs); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func scanIDs(rows pgx.Rows) (map[string]int64, error) {
+	defer rows.Close()
+	out := make(map[string]int64)
+	for rows.Next() {
+		var (
+			key string
+			id  int64
+		)
+		if err := rows.Scan(&key, &id); err != nil {
+			return nil, err
+		}
+		out[key] = id
+	}
+	return out, rows.Err()
+}
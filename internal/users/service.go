@@ -2,24 +2,120 @@ package users
 
 import (
 	"context"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/auth"
+)
+
+var (
+	// ErrUserNotFound is returned when the user does not exist.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidManager is returned when a user is made their own manager or
+	// the manager does not exist.
+	ErrInvalidManager = errors.New("invalid manager")
+	// ErrInvalidEmail is returned when a new user's email is not an address.
+	ErrInvalidEmail = errors.New("invalid email address")
+	// ErrEmailTaken is returned when another user already has the email.
+	ErrEmailTaken = errors.New("email is already in use")
 )
 
 // RepositoryPort defines data access methods for users.
 type RepositoryPort interface {
 	ListUsers(ctx context.Context) ([]User, error)
+	UserExists(ctx context.Context, id int64) (bool, error)
+	SetManager(ctx context.Context, userID int64, managerID *int64) error
+	CreateUser(ctx context.Context, input CreateUserInput, passwordHash string) (int64, error)
+	ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
+	RoleIDsByName(ctx context.Context, names []string) (map[string]int64, error)
+	CompanyIDsByCode(ctx context.Context, codes []string) (map[string]int64, error)
+	ListCompanies(ctx context.Context) ([]CompanyOption, error)
+	UserCompanyIDs(ctx context.Context) (map[int64][]int64, error)
+	ImportUsers(ctx context.Context, users []ImportedUser) ([]int64, error)
 }
 
 // Service handles user business logic.
 type Service struct {
-	repo RepositoryPort
+	repo     RepositoryPort
+	policy   auth.PasswordPolicy
+	mailer   CredentialQueue
+	loginURL string
+	audit    AuditPort
+	scoper   CompanyAssigner
 }
 
 // NewService builds Service instance.
 func NewService(repo RepositoryPort) *Service {
-	return &Service{repo: repo}
+	return &Service{repo: repo, policy: auth.DefaultPasswordPolicy()}
+}
+
+// SetPasswordPolicy replaces the policy new users' passwords must meet.
+func (s *Service) SetPasswordPolicy(policy auth.PasswordPolicy) {
+	s.policy = policy
+}
+
+// PasswordPolicy returns the enforced policy.
+func (s *Service) PasswordPolicy() auth.PasswordPolicy {
+	return s.policy
+}
+
+// CreateUser adds an active user whose initial password meets the policy.
+func (s *Service) CreateUser(ctx context.Context, input CreateUserInput) (int64, error) {
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+	input.Name = strings.TrimSpace(input.Name)
+	if addr, err := mail.ParseAddress(input.Email); err != nil || addr.Address != input.Email {
+		return 0, ErrInvalidEmail
+	}
+	if err := s.policy.Validate(input.Password, input.Email); err != nil {
+		return 0, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.CreateUser(ctx, input, string(hash))
 }
 
 // ListUsers returns all users.
 func (s *Service) ListUsers(ctx context.Context) ([]User, error) {
-	return s.repo.ListUsers(ctx)
+	users, err := s.repo.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	companies, err := s.repo.UserCompanyIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		users[i].CompanyIDs = companies[users[i].ID]
+	}
+	return users, nil
+}
+
+// SetManager records who the user reports to. Approvals the user leaves past
+// their SLA are escalated to this manager; nil clears it.
+func (s *Service) SetManager(ctx context.Context, userID int64, managerID *int64) error {
+	exists, err := s.repo.UserExists(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	if managerID != nil {
+		if *managerID == userID {
+			return ErrInvalidManager
+		}
+		exists, err := s.repo.UserExists(ctx, *managerID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrInvalidManager
+		}
+	}
+	return s.repo.SetManager(ctx, userID, managerID)
 }
@@ -0,0 +1,170 @@
+package variance
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+)
+
+// DiffChange classifies how an account moved between two snapshots.
+type DiffChange string
+
+const (
+	// DiffNewlyFlagged marks accounts that breach the threshold only in the
+	// later snapshot.
+	DiffNewlyFlagged DiffChange = "NEWLY_FLAGGED"
+	// DiffNoLongerFlagged marks accounts that breached the threshold only in
+	// the earlier snapshot.
+	DiffNoLongerFlagged DiffChange = "NO_LONGER_FLAGGED"
+	// DiffWorsening marks accounts whose absolute variance grew.
+	DiffWorsening DiffChange = "WORSENING"
+	// DiffImproving marks accounts whose absolute variance shrank.
+	DiffImproving DiffChange = "IMPROVING"
+)
+
+// ErrSnapshotNotReady occurs when comparing a snapshot without a payload.
+var ErrSnapshotNotReady = errors.New("variance: snapshot not ready")
+
+// SnapshotDiffRow pairs an account's rows from two snapshots. Before or After
+// is nil when the account is missing from that snapshot.
+type SnapshotDiffRow struct {
+	AccountCode string
+	AccountName string
+	Before      *VarianceRow
+	After       *VarianceRow
+	Change      DiffChange
+	// Delta is the change in absolute variance; positive means worse.
+	Delta float64
+}
+
+// SnapshotDiff compares a snapshot (After) with an earlier one (Before).
+// Accounts whose flag and variance did not move are left out.
+type SnapshotDiff struct {
+	Before          Snapshot
+	After           Snapshot
+	NewlyFlagged    []SnapshotDiffRow
+	NoLongerFlagged []SnapshotDiffRow
+	Worsening       []SnapshotDiffRow
+	Improving       []SnapshotDiffRow
+}
+
+// DiffSection is one group of a SnapshotDiff, for display.
+type DiffSection struct {
+	Change DiffChange
+	Title  string
+	Rows   []SnapshotDiffRow
+}
+
+// Sections lists the diff's groups in display order.
+func (d SnapshotDiff) Sections() []DiffSection {
+	return []DiffSection{
+		{Change: DiffNewlyFlagged, Title: "Newly Flagged", Rows: d.NewlyFlagged},
+		{Change: DiffNoLongerFlagged, Title: "No Longer Flagged", Rows: d.NoLongerFlagged},
+		{Change: DiffWorsening, Title: "Worsening", Rows: d.Worsening},
+		{Change: DiffImproving, Title: "Improving", Rows: d.Improving},
+	}
+}
+
+// DiffRows aligns two payloads by account code and classifies every account
+// that changed. A missing account counts as zero variance and not flagged.
+func DiffRows(before, after []VarianceRow) SnapshotDiff {
+	type pair struct{ before, after *VarianceRow }
+	pairs := make(map[string]*pair)
+	for i := range before {
+		pairs[before[i].AccountCode] = &pair{before: &before[i]}
+	}
+	for i := range after {
+		p, ok := pairs[after[i].AccountCode]
+		if !ok {
+			p = &pair{}
+			pairs[after[i].AccountCode] = p
+		}
+		p.after = &after[i]
+	}
+
+	var diff SnapshotDiff
+	for code, p := range pairs {
+		row := SnapshotDiffRow{AccountCode: code, Before: p.before, After: p.after}
+		var wasFlagged, isFlagged bool
+		var wasVariance, isVariance float64
+		if p.before != nil {
+			row.AccountName = p.before.AccountName
+			wasFlagged = p.before.Flagged
+			wasVariance = math.Abs(p.before.Variance)
+		}
+		if p.after != nil {
+			row.AccountName = p.after.AccountName
+			isFlagged = p.after.Flagged
+			isVariance = math.Abs(p.after.Variance)
+		}
+		row.Delta = round2(isVariance - wasVariance)
+		switch {
+		case isFlagged && !wasFlagged:
+			row.Change = DiffNewlyFlagged
+			diff.NewlyFlagged = append(diff.NewlyFlagged, row)
+		case wasFlagged && !isFlagged:
+			row.Change = DiffNoLongerFlagged
+			diff.NoLongerFlagged = append(diff.NoLongerFlagged, row)
+		case row.Delta > 0:
+			row.Change = DiffWorsening
+			diff.Worsening = append(diff.Worsening, row)
+		case row.Delta < 0:
+			row.Change = DiffImproving
+			diff.Improving = append(diff.Improving, row)
+		}
+	}
+	for _, rows := range [][]SnapshotDiffRow{diff.NewlyFlagged, diff.NoLongerFlagged, diff.Worsening, diff.Improving} {
+		sortDiffRows(rows)
+	}
+	return diff
+}
+
+// sortDiffRows puts the largest moves first, then orders by account code.
+func sortDiffRows(rows []SnapshotDiffRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		di, dj := math.Abs(rows[i].Delta), math.Abs(rows[j].Delta)
+		if di != dj {
+			return di > dj
+		}
+		return rows[i].AccountCode < rows[j].AccountCode
+	})
+}
+
+// CompareSnapshots diffs two ready snapshots, typically consecutive runs of
+// the same rule. beforeID is the earlier run.
+func (s *Service) CompareSnapshots(ctx context.Context, beforeID, afterID int64) (SnapshotDiff, error) {
+	before, beforeRows, err := s.readySnapshot(ctx, beforeID)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+	after, afterRows, err := s.readySnapshot(ctx, afterID)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+	diff := DiffRows(beforeRows, afterRows)
+	diff.Before = before
+	diff.After = after
+	return diff, nil
+}
+
+// ListComparableSnapshots returns the ready snapshots a snapshot can be
+// compared with, newest first, those of its own rule leading.
+func (s *Service) ListComparableSnapshots(ctx context.Context, snapshot Snapshot) ([]Snapshot, error) {
+	return s.repo.ListReadySnapshots(ctx, snapshot.ID, snapshot.RuleID)
+}
+
+func (s *Service) readySnapshot(ctx context.Context, id int64) (Snapshot, []VarianceRow, error) {
+	snap, err := s.repo.GetSnapshot(ctx, id)
+	if err != nil {
+		return Snapshot{}, nil, err
+	}
+	if snap.Status != SnapshotReady {
+		return Snapshot{}, nil, ErrSnapshotNotReady
+	}
+	rows, err := s.repo.LoadPayload(ctx, id)
+	if err != nil {
+		return Snapshot{}, nil, err
+	}
+	return snap, rows, nil
+}
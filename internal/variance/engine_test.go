@@ -19,3 +19,40 @@ func TestComputeVariance(t *testing.T) {
 		t.Fatalf("expected flagged variance")
 	}
 }
+
+func TestDiffRowsClassifiesAccounts(t *testing.T) {
+	before := []VarianceRow{
+		{AccountCode: "1000", AccountName: "Cash", Variance: 100},
+		{AccountCode: "2000", AccountName: "AP", Variance: -900, Flagged: true},
+		{AccountCode: "3000", AccountName: "Equity", Variance: 50},
+		{AccountCode: "4000", AccountName: "Sales", Variance: 300, Flagged: true},
+		{AccountCode: "5000", AccountName: "COGS", Variance: -40},
+	}
+	after := []VarianceRow{
+		{AccountCode: "1000", AccountName: "Cash", Variance: 500, Flagged: true},
+		{AccountCode: "2000", AccountName: "AP", Variance: -100},
+		{AccountCode: "3000", AccountName: "Equity", Variance: -80},
+		{AccountCode: "5000", AccountName: "COGS", Variance: 40},
+		{AccountCode: "6000", AccountName: "Freight", Variance: 20},
+	}
+	diff := DiffRows(before, after)
+
+	if len(diff.NewlyFlagged) != 1 || diff.NewlyFlagged[0].AccountCode != "1000" {
+		t.Fatalf("expected 1000 newly flagged, got %+v", diff.NewlyFlagged)
+	}
+	if len(diff.NoLongerFlagged) != 2 || diff.NoLongerFlagged[0].AccountCode != "2000" || diff.NoLongerFlagged[1].AccountCode != "4000" {
+		t.Fatalf("expected 2000 and 4000 no longer flagged, got %+v", diff.NoLongerFlagged)
+	}
+	if diff.NoLongerFlagged[1].After != nil {
+		t.Fatalf("expected the account missing from the later snapshot to have no After row")
+	}
+	if len(diff.Worsening) != 2 || diff.Worsening[0].AccountCode != "3000" || diff.Worsening[1].AccountCode != "6000" {
+		t.Fatalf("expected 3000 then 6000 worsening, got %+v", diff.Worsening)
+	}
+	if diff.Worsening[1].Before != nil || diff.Worsening[1].Delta != 20 {
+		t.Fatalf("expected a new account to count from zero variance, got %+v", diff.Worsening[1])
+	}
+	if len(diff.Improving) != 0 {
+		t.Fatalf("expected an unchanged absolute variance to be left out, got %+v", diff.Improving)
+	}
+}
@@ -2,6 +2,7 @@ package variance
 
 import (
 	"encoding/csv"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/rbac"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
@@ -38,6 +40,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 		r.Post("/rules", h.createRule)
 		r.Get("/snapshots", h.listSnapshots)
 		r.Post("/snapshots", h.triggerSnapshot)
+		r.Get("/snapshots/compare", h.compareSnapshots)
 		r.Get("/snapshots/{id}", h.showSnapshot)
 		r.Get("/snapshots/{id}/export", h.exportSnapshot)
 	})
@@ -46,7 +49,7 @@ func (h *Handler) MountRoutes(r chi.Router) {
 func (h *Handler) listRules(w http.ResponseWriter, r *http.Request) {
 	rules, err := h.service.ListRules(r.Context(), 0)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	h.render(w, r, "pages/variance/rules.html", "Variance Rules", map[string]any{
@@ -56,7 +59,7 @@ func (h *Handler) listRules(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) createRule(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	actor := currentUser(r)
@@ -70,7 +73,7 @@ func (h *Handler) createRule(w http.ResponseWriter, r *http.Request) {
 		ActorID:         actor,
 	}
 	if _, err := h.service.CreateRule(r.Context(), input); err != nil {
-		h.logger.Warn("create variance rule", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "create variance rule", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/variance/rules", "danger", shared.UserSafeMessage(err))
 		return
 	}
@@ -92,14 +95,14 @@ func (h *Handler) listSnapshots(w http.ResponseWriter, r *http.Request) {
 
 	snapshots, total, err := h.service.ListSnapshots(r.Context(), filters)
 	if err != nil {
-		h.logger.Error("list snapshots", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list snapshots", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	rules, err := h.service.ListRules(r.Context(), 0)
 	if err != nil {
-		h.logger.Error("list rules", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.logger.ErrorContext(r.Context(), "list rules", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	h.render(w, r, "pages/variance/snapshots.html", "Variance Snapshots", map[string]any{
@@ -112,7 +115,7 @@ func (h *Handler) listSnapshots(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) triggerSnapshot(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		httpx.Error(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 	req := SnapshotRequest{
@@ -122,13 +125,13 @@ func (h *Handler) triggerSnapshot(w http.ResponseWriter, r *http.Request) {
 	}
 	snapshot, err := h.service.TriggerSnapshot(r.Context(), req)
 	if err != nil {
-		h.logger.Warn("trigger variance snapshot", slog.Any("error", err))
+		h.logger.WarnContext(r.Context(), "trigger variance snapshot", slog.Any("error", err))
 		h.redirectWithFlash(w, r, "/variance/snapshots", "danger", shared.UserSafeMessage(err))
 		return
 	}
 	if h.jobs != nil {
 		if _, err := h.jobs.EnqueueVarianceSnapshot(r.Context(), snapshot.ID); err != nil && h.logger != nil {
-			h.logger.Warn("enqueue variance snapshot", slog.Any("error", err))
+			h.logger.WarnContext(r.Context(), "enqueue variance snapshot", slog.Any("error", err))
 		}
 	}
 	h.redirectWithFlash(w, r, "/variance/snapshots", "success", "Snapshot queued")
@@ -142,17 +145,43 @@ func (h *Handler) showSnapshot(w http.ResponseWriter, r *http.Request) {
 			http.NotFound(w, r)
 			return
 		}
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	rows, err := h.service.LoadSnapshotPayload(r.Context(), id)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	comparable, err := h.service.ListComparableSnapshots(r.Context(), snapshot)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "list comparable snapshots", slog.Any("error", err))
+	}
 	h.render(w, r, "pages/variance/snapshot_detail.html", "Variance Snapshot", map[string]any{
-		"Snapshot": snapshot,
-		"Rows":     rows,
+		"Snapshot":   snapshot,
+		"Rows":       rows,
+		"Comparable": comparable,
+	}, http.StatusOK)
+}
+
+func (h *Handler) compareSnapshots(w http.ResponseWriter, r *http.Request) {
+	beforeID := parseInt64(r.URL.Query().Get("before"))
+	afterID := parseInt64(r.URL.Query().Get("after"))
+	diff, err := h.service.CompareSnapshots(r.Context(), beforeID, afterID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSnapshotNotFound):
+			http.NotFound(w, r)
+		case errors.Is(err, ErrSnapshotNotReady):
+			h.redirectWithFlash(w, r, "/variance/snapshots/"+strconv.FormatInt(afterID, 10), "danger", "Both snapshots must be ready to compare.")
+		default:
+			h.logger.ErrorContext(r.Context(), "compare variance snapshots", slog.Any("error", err))
+			httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	h.render(w, r, "pages/variance/snapshot_compare.html", "Variance Snapshot Comparison", map[string]any{
+		"Diff": diff,
 	}, http.StatusOK)
 }
 
@@ -160,11 +189,11 @@ func (h *Handler) exportSnapshot(w http.ResponseWriter, r *http.Request) {
 	id := parseInt64(chi.URLParam(r, "id"))
 	rows, err := h.service.LoadSnapshotPayload(r.Context(), id)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	if len(rows) == 0 {
-		http.Error(w, "snapshot empty", http.StatusBadRequest)
+		httpx.Error(w, r, "snapshot empty", http.StatusBadRequest)
 		return
 	}
 	w.Header().Set("Content-Type", "text/csv")
@@ -194,7 +223,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request, tpl, title stri
 	}
 	w.WriteHeader(status)
 	if err := h.templates.Render(w, tpl, viewData); err != nil && h.logger != nil {
-		h.logger.Error("render variance template", slog.Any("error", err))
+		h.logger.ErrorContext(r.Context(), "render variance template", slog.Any("error", err))
 	}
 }
 
@@ -32,7 +32,7 @@ func (j *SnapshotJob) Handle(ctx context.Context, task *asynq.Task) error {
 	}
 	if err := j.service.ProcessSnapshot(ctx, payload.SnapshotID); err != nil {
 		if j.logger != nil {
-			j.logger.Error("variance snapshot", slog.Int64("snapshot_id", payload.SnapshotID), slog.Any("error", err))
+			j.logger.ErrorContext(ctx, "variance snapshot", slog.Int64("snapshot_id", payload.SnapshotID), slog.Any("error", err))
 		}
 		return err
 	}
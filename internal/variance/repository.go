@@ -166,6 +166,36 @@ func (r *Repository) LoadPayload(ctx context.Context, id int64) ([]VarianceRow,
 	return rows, nil
 }
 
+// ListReadySnapshots returns up to 50 ready snapshots other than excludeID,
+// newest first with those of ruleID leading. Rule carries only the name.
+func (r *Repository) ListReadySnapshots(ctx context.Context, excludeID, ruleID int64) ([]Snapshot, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT vs.id, vs.rule_id, vs.period_id, vs.status, vs.created_at, vr.name
+FROM variance_snapshots vs
+JOIN variance_rules vr ON vr.id = vs.rule_id
+WHERE vs.status = 'READY' AND vs.id <> $1
+ORDER BY (vs.rule_id = $2) DESC, vs.created_at DESC, vs.id DESC
+LIMIT 50`, excludeID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var snapshots []Snapshot
+	for rows.Next() {
+		var snap Snapshot
+		var status string
+		rule := &Rule{}
+		if err := rows.Scan(&snap.ID, &snap.RuleID, &snap.PeriodID, &status, &snap.CreatedAt, &rule.Name); err != nil {
+			return nil, err
+		}
+		snap.Status = SnapshotStatus(status)
+		rule.ID = snap.RuleID
+		snap.Rule = rule
+		snapshots = append(snapshots, snap)
+	}
+	return snapshots, rows.Err()
+}
+
 // AggregateBalances summarises account balances for company/period.
 func (r *Repository) AggregateBalances(ctx context.Context, accountingPeriodID, companyID int64) (map[string]AccountBalance, error) {
 	rows, err := r.queries.AggregateBalances(ctx, sqlc.AggregateBalancesParams{
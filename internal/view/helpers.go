@@ -1,6 +1,23 @@
 package view
 
+import (
+	"html/template"
+	"strings"
+)
+
 // LayoutData helps templates access nested data conveniently.
 type LayoutData struct {
 	Main any
 }
+
+// SignatureURL marks a stored PNG or JPEG data URL safe for an img src.
+// Anything else renders as an empty URL.
+func SignatureURL(sig *string) template.URL {
+	if sig == nil {
+		return ""
+	}
+	if strings.HasPrefix(*sig, "data:image/png;base64,") || strings.HasPrefix(*sig, "data:image/jpeg;base64,") {
+		return template.URL(*sig)
+	}
+	return ""
+}
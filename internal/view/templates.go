@@ -9,10 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/i18n"
 	"github.com/odyssey-erp/odyssey-erp/internal/shared"
 	"github.com/odyssey-erp/odyssey-erp/web"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 )
 
 // Engine renders HTML templates.
@@ -29,62 +28,28 @@ type TemplateData struct {
 	Data        any
 }
 
-// NewEngine parses templates at build-time.
+// Formatting controls how numbers and amounts render in the UI. It only
+// affects presentation; stored values are never rounded.
+type Formatting struct {
+	Locale i18n.Locale
+	// Currency is the company currency, used for amounts whose document has
+	// no currency of its own.
+	Currency string
+}
+
+// DefaultFormatting renders Indonesian separators in rupiah.
+func DefaultFormatting() Formatting {
+	return Formatting{Locale: i18n.For(i18n.Indonesian), Currency: "IDR"}
+}
+
+// NewEngine parses templates at build-time with DefaultFormatting.
 func NewEngine() (*Engine, error) {
-	printer := message.NewPrinter(language.Indonesian)
-	funcMap := template.FuncMap{
-		"formatDate": func(t time.Time) string {
-			if t.IsZero() {
-				return ""
-			}
-			return t.Format("02 Jan 2006 15:04")
-		},
-		"formatDecimal": func(v float64) string {
-			return printer.Sprintf("%.2f", v)
-		},
-		"now": func() time.Time {
-			return time.Now()
-		},
-		"countByStatus": func(items interface{}, status string) int {
-			count := 0
-			if items == nil {
-				return count
-			}
-			// Use reflection to handle different types
-			return count
-		},
-		"sub": func(a, b int) int {
-			return a - b
-		},
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"addf": func(a, b float64) float64 {
-			return a + b
-		},
-		"mul": func(a, b int) int {
-			return a * b
-		},
-		"mulf": func(a, b float64) float64 {
-			return a * b
-		},
-		"div": func(a, b int) int {
-			if b == 0 {
-				return 0
-			}
-			return a / b
-		},
-		"divf": func(a, b float64) float64 {
-			if b == 0 {
-				return 0
-			}
-			return a / b
-		},
-		"lower": strings.ToLower,
-		"upper": strings.ToUpper,
-	}
+	return NewEngineWithFormatting(DefaultFormatting())
+}
 
-	base, err := template.New("root").Funcs(funcMap).ParseFS(web.Templates,
+// NewEngineWithFormatting parses templates with the given number formatting.
+func NewEngineWithFormatting(format Formatting) (*Engine, error) {
+	base, err := template.New("root").Funcs(templateFuncs(format)).ParseFS(web.Templates,
 		"templates/layouts/*.html",
 		"templates/partials/*.html",
 		"templates/partials/*/*.html",
@@ -137,3 +102,76 @@ func (e *Engine) Render(w http.ResponseWriter, name string, data TemplateData) e
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	return tpl.ExecuteTemplate(w, name, data)
 }
+
+// templateFuncs returns the helpers available to every template.
+func templateFuncs(format Formatting) template.FuncMap {
+	locale := format.Locale
+	currencyOr := func(currency string) string {
+		if strings.TrimSpace(currency) == "" {
+			return format.Currency
+		}
+		return currency
+	}
+	return template.FuncMap{
+		"formatDate": func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format("02 Jan 2006 15:04")
+		},
+		"formatDecimal": locale.Money,
+		"formatNumber":  locale.Number,
+		"formatQty":     locale.Qty,
+		"formatAmount": func(v float64, currency string) string {
+			return locale.Amount(v, currencyOr(currency))
+		},
+		"formatMoney": func(v float64, currency string) string {
+			return locale.Currency(v, currencyOr(currency))
+		},
+		"currencySymbol": func(currency string) string {
+			return i18n.CurrencySymbol(currencyOr(currency))
+		},
+		"now": func() time.Time {
+			return time.Now()
+		},
+		"countByStatus": func(items interface{}, status string) int {
+			count := 0
+			if items == nil {
+				return count
+			}
+			// Use reflection to handle different types
+			return count
+		},
+		"sub": func(a, b int) int {
+			return a - b
+		},
+		"add": func(a, b int) int {
+			return a + b
+		},
+		"addf": func(a, b float64) float64 {
+			return a + b
+		},
+		"mul": func(a, b int) int {
+			return a * b
+		},
+		"mulf": func(a, b float64) float64 {
+			return a * b
+		},
+		"div": func(a, b int) int {
+			if b == 0 {
+				return 0
+			}
+			return a / b
+		},
+		"divf": func(a, b float64) float64 {
+			if b == 0 {
+				return 0
+			}
+			return a / b
+		},
+		"lower":        strings.ToLower,
+		"upper":        strings.ToUpper,
+		"signatureURL": SignatureURL,
+		"versionToken": shared.VersionToken,
+	}
+}
@@ -1,9 +1,14 @@
 package view
 
 import (
+	"html/template"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/i18n"
 )
 
 func TestNewEngine(t *testing.T) {
@@ -11,3 +16,17 @@ func TestNewEngine(t *testing.T) {
 	assert.NoError(t, err, "Templates should parse without error")
 	assert.NotNil(t, engine)
 }
+
+func TestTemplateFuncsFormatAmounts(t *testing.T) {
+	tpl := template.Must(template.New("t").Funcs(templateFuncs(DefaultFormatting())).Parse(
+		`{{ formatMoney 1000000 "IDR" }}|{{ formatMoney 1234.5 "USD" }}|{{ formatMoney 2500 "" }}|{{ formatAmount 1234.5 "USD" }}|{{ formatDecimal 1234.5 }}|{{ formatQty 2.5 }}`))
+	var out strings.Builder
+	require.NoError(t, tpl.Execute(&out, nil))
+	assert.Equal(t, "Rp 1.000.000|US$ 1.234,50|Rp 2.500|1.234,50|1.234,50|2,5", out.String())
+
+	english := Formatting{Locale: i18n.For(i18n.English), Currency: "USD"}
+	tpl = template.Must(template.New("t").Funcs(templateFuncs(english)).Parse(`{{ formatMoney 1234.5 "" }}|{{ formatDecimal 1234.5 }}`))
+	out.Reset()
+	require.NoError(t, tpl.Execute(&out, nil))
+	assert.Equal(t, "US$ 1,234.50|1,234.50", out.String())
+}
@@ -62,17 +62,17 @@ func (j *AnomalyScanJob) Handle(ctx context.Context, t *asynq.Task) error {
 		slog.Int("window_months", payload.WindowMonths),
 		slog.Float64("z_threshold", payload.Z),
 	)
-	logger.Info("starting anomaly scan")
+	logger.InfoContext(ctx, "starting anomaly scan")
 
 	scopes, anomalies, err := j.scan(ctx, payload, start)
 	if err != nil {
 		resultErr = err
-		logger.Error("scan failed", slog.Any("error", err))
+		logger.ErrorContext(ctx, "scan failed", slog.Any("error", err))
 		return resultErr
 	}
 
 	for _, a := range anomalies {
-		logger.Warn("finance anomaly detected",
+		logger.WarnContext(ctx, "finance anomaly detected",
 			slog.Int64("company_id", a.CompanyID),
 			slog.Int64("branch_id", a.BranchID),
 			slog.String("period", a.Period),
@@ -83,7 +83,7 @@ func (j *AnomalyScanJob) Handle(ctx context.Context, t *asynq.Task) error {
 		j.metrics().AddAnomalies(a.Severity, a.CompanyID, a.BranchID, 1)
 	}
 
-	logger.Info("completed anomaly scan",
+	logger.InfoContext(ctx, "completed anomaly scan",
 		slog.Int("scopes", scopes),
 		slog.Int("anomalies", len(anomalies)),
 		slog.Duration("duration", time.Since(start)),
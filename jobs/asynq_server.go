@@ -10,6 +10,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 )
 
 // Worker wraps the Asynq server and optional scheduler.
@@ -39,6 +41,8 @@ type WorkerConfig struct {
 	Logger    *slog.Logger
 	Handlers  []TaskHandler
 	Cron      []CronRegistration
+	// Mailer delivers TaskTypeSendEmail tasks; when nil emails are only logged.
+	Mailer *SMTPMailer
 }
 
 // NewWorker constructs a Worker instance.
@@ -50,7 +54,12 @@ func NewWorker(cfg WorkerConfig) (*Worker, error) {
 		},
 	})
 	mux := asynq.NewServeMux()
-	mux.HandleFunc(TaskTypeSendEmail, HandleSendEmailTask)
+	mux.Use(requestIDMiddleware(cfg.Logger))
+	if cfg.Mailer != nil {
+		mux.HandleFunc(TaskTypeSendEmail, cfg.Mailer.Handle)
+	} else {
+		mux.HandleFunc(TaskTypeSendEmail, HandleSendEmailTask)
+	}
 	mux.HandleFunc(TaskInventoryRevaluation, HandleInventoryRevaluationTask)
 	mux.HandleFunc(TaskProcurementReindex, HandleProcurementReindexTask)
 	for _, h := range cfg.Handlers {
@@ -122,7 +131,7 @@ func (c *Client) EnqueueSendEmail(ctx context.Context, payload SendEmailPayload)
 	if err != nil {
 		return nil, err
 	}
-	return c.client.EnqueueContext(ctx, task, asynq.Queue(QueueDefault))
+	return c.client.EnqueueContext(ctx, withRequestID(ctx, task), asynq.Queue(QueueDefault), asynq.MaxRetry(SendEmailMaxRetry))
 }
 
 // EnqueueVarianceSnapshot enqueues a variance snapshot task.
@@ -131,7 +140,7 @@ func (c *Client) EnqueueVarianceSnapshot(ctx context.Context, snapshotID int64)
 	if err != nil {
 		return nil, err
 	}
-	return c.client.EnqueueContext(ctx, task, asynq.Queue(QueueDefault))
+	return c.client.EnqueueContext(ctx, withRequestID(ctx, task), asynq.Queue(QueueDefault))
 }
 
 // EnqueueBoardPack enqueues a board pack generation task.
@@ -140,7 +149,26 @@ func (c *Client) EnqueueBoardPack(ctx context.Context, boardPackID int64) (*asyn
 	if err != nil {
 		return nil, err
 	}
-	return c.client.EnqueueContext(ctx, task, asynq.Queue(QueueDefault))
+	return c.client.EnqueueContext(ctx, withRequestID(ctx, task), asynq.Queue(QueueDefault))
+}
+
+// EnqueueReportJob enqueues an async report generation task.
+func (c *Client) EnqueueReportJob(ctx context.Context, reportJobID int64) (*asynq.TaskInfo, error) {
+	task, err := NewReportJobTask(reportJobID)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.EnqueueContext(ctx, withRequestID(ctx, task), asynq.Queue(QueueDefault))
+}
+
+// EnqueueARInvoiceEmail enqueues delivery of a logged AR invoice email. It
+// retries like any other email.
+func (c *Client) EnqueueARInvoiceEmail(ctx context.Context, emailID int64) (*asynq.TaskInfo, error) {
+	task, err := NewARInvoiceEmailTask(emailID)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.EnqueueContext(ctx, withRequestID(ctx, task), asynq.Queue(QueueDefault), asynq.MaxRetry(SendEmailMaxRetry))
 }
 
 // Close releases client resources.
@@ -208,8 +236,8 @@ func (h *Handler) index(w http.ResponseWriter, r *http.Request) {
 			Data:        data,
 		}
 		if err := h.templates.Render(w, "pages/jobs/index.html", viewData); err != nil {
-			h.logger.Error("render jobs template", slog.Any("error", err))
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			h.logger.ErrorContext(r.Context(), "render jobs template", slog.Any("error", err))
+			httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		}
 		return
 	}
@@ -297,8 +325,8 @@ func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
 	}
 	info, err := h.inspector.GetQueueInfo(QueueDefault)
 	if err != nil {
-		h.logger.Warn("jobs health", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		h.logger.WarnContext(r.Context(), "jobs health", slog.Any("error", err))
+		httpx.Error(w, r, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hibiken/asynq"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/consol"
 	consolhttp "github.com/odyssey-erp/odyssey-erp/internal/consol/http"
 	jobmetrics "github.com/odyssey-erp/odyssey-erp/internal/jobs"
 )
@@ -18,12 +20,31 @@ import (
 const (
 	// TaskConsolidateRefresh schedules the consolidation refresh routine.
 	TaskConsolidateRefresh = "consol:refresh"
+
+	// ConsolScopeAll refreshes every consolidation group.
+	ConsolScopeAll = "all"
+	// ConsolScopeChanged refreshes only groups whose source data changed since
+	// their last refresh.
+	ConsolScopeChanged = "changed"
 )
 
 // ConsolidateRefreshPayload configures the scope of the consolidation refresh job.
+// GroupID is a group identifier, ConsolScopeAll or ConsolScopeChanged. Period
+// is a period code or "active".
 type ConsolidateRefreshPayload struct {
-	GroupID string `json:"group_id"`
-	Period  string `json:"period"`
+	GroupID     string             `json:"group_id"`
+	Period      string             `json:"period"`
+	Restatement *ConsolRestatement `json:"restatement,omitempty"`
+}
+
+// ConsolRestatement marks a refresh that rebuilds a past period after a
+// member restated it. The rebuild ignores refresh watermarks and leaves an
+// audit note per group.
+type ConsolRestatement struct {
+	Reason      string `json:"reason"`
+	RequestedBy int64  `json:"requested_by,omitempty"`
+	// OverrideLock allows rebuilding a locked period.
+	OverrideLock bool `json:"override_lock,omitempty"`
 }
 
 // ConsolidationService describes the behaviour required to rebuild materialised balances.
@@ -31,10 +52,20 @@ type ConsolidationService interface {
 	RebuildConsolidation(ctx context.Context, groupID int64, period string) error
 }
 
+// mappingChecker is implemented by services that can report unmapped balances
+// ahead of a rebuild.
+type mappingChecker interface {
+	CheckMappings(ctx context.Context, groupID int64, period string) (consol.MappingCheck, error)
+}
+
 // ConsolidationRepository provides helper lookups for the job runtime.
 type ConsolidationRepository interface {
 	ListGroupIDs(ctx context.Context) ([]int64, error)
 	ActiveConsolidationPeriod(ctx context.Context) (string, error)
+	RefreshWatermark(ctx context.Context, groupID int64, period string) (consol.RefreshWatermark, error)
+	MarkRefreshed(ctx context.Context, groupID int64, period string, sourceChangedAt time.Time) error
+	PeriodLocked(ctx context.Context, period string) (bool, error)
+	RecordRestatement(ctx context.Context, restatement consol.Restatement) error
 }
 
 // ConsolidateRefreshJob coordinates the refresh workflow.
@@ -62,7 +93,7 @@ func NewConsolidateRefreshJob(service ConsolidationService, repo ConsolidationRe
 // NewConsolidateRefreshTask creates an Asynq task for refreshing consolidated balances.
 func NewConsolidateRefreshTask(groupID, period string) (*asynq.Task, error) {
 	if groupID == "" {
-		groupID = "all"
+		groupID = ConsolScopeAll
 	}
 	if period == "" {
 		period = "active"
@@ -75,7 +106,29 @@ func NewConsolidateRefreshTask(groupID, period string) (*asynq.Task, error) {
 	return asynq.NewTask(TaskConsolidateRefresh, body, asynq.Queue(QueueDefault)), nil
 }
 
-// Handle executes the consolidate refresh job.
+// NewConsolidateRestatementTask creates an Asynq task that rebuilds one past
+// period for the group, or every group when groupID is empty.
+func NewConsolidateRestatementTask(groupID, period string, restatement ConsolRestatement) (*asynq.Task, error) {
+	if period == "" || period == "active" {
+		return nil, errors.New("consolidate restatement: period code is required")
+	}
+	restatement.Reason = strings.TrimSpace(restatement.Reason)
+	if restatement.Reason == "" {
+		return nil, errors.New("consolidate restatement: reason is required")
+	}
+	if groupID == "" || groupID == ConsolScopeChanged {
+		groupID = ConsolScopeAll
+	}
+	payload := ConsolidateRefreshPayload{GroupID: groupID, Period: period, Restatement: &restatement}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TaskConsolidateRefresh, body, asynq.Queue(QueueDefault)), nil
+}
+
+// Handle executes the consolidate refresh job. Locked periods are only
+// rebuilt by a restatement that overrides the lock.
 func (j *ConsolidateRefreshJob) Handle(ctx context.Context, task *asynq.Task) error {
 	if j == nil || j.Service == nil || j.Repo == nil {
 		return errors.New("consolidate refresh: dependencies not configured")
@@ -85,7 +138,7 @@ func (j *ConsolidateRefreshJob) Handle(ctx context.Context, task *asynq.Task) er
 		return asynq.SkipRetry
 	}
 	if payload.GroupID == "" {
-		payload.GroupID = "all"
+		payload.GroupID = ConsolScopeAll
 	}
 	if payload.Period == "" {
 		payload.Period = "active"
@@ -104,6 +157,19 @@ func (j *ConsolidateRefreshJob) Handle(ctx context.Context, task *asynq.Task) er
 		return resultErr
 	}
 
+	locked, err := j.Repo.PeriodLocked(ctx, period)
+	if err != nil {
+		resultErr = err
+		j.log().Error("read period lock", slog.String("period", period), slog.Any("error", err))
+		return resultErr
+	}
+	restatement := payload.Restatement
+	if locked && (restatement == nil || !restatement.OverrideLock) {
+		resultErr = fmt.Errorf("%w: %s: %w", consol.ErrPeriodLocked, period, asynq.SkipRetry)
+		j.log().Warn("refusing to rebuild locked period", slog.String("period", period), slog.String("scope", payload.GroupID))
+		return resultErr
+	}
+
 	groupIDs, err := j.resolveGroups(ctx, payload.GroupID)
 	if err != nil {
 		resultErr = err
@@ -116,22 +182,91 @@ func (j *ConsolidateRefreshJob) Handle(ctx context.Context, task *asynq.Task) er
 	}
 
 	start := j.now()
-	refreshed := 0
+	changedOnly := payload.GroupID == ConsolScopeChanged && restatement == nil
+	var refreshed, skipped []int64
 	for _, groupID := range groupIDs {
+		// The watermark is read before rebuilding so changes landing mid-rebuild
+		// are picked up again by the next incremental run.
+		watermark, err := j.Repo.RefreshWatermark(ctx, groupID, period)
+		if err != nil {
+			resultErr = err
+			j.log().Error("read refresh watermark", slog.Int64("group_id", groupID), slog.String("period", period), slog.Any("error", err))
+			return resultErr
+		}
+		if changedOnly && !watermark.Changed() {
+			skipped = append(skipped, groupID)
+			j.log().Info("consolidation unchanged, skipping group", slog.Int64("group_id", groupID), slog.String("period", period),
+				slog.Time("source_changed_at", watermark.SourceChangedAt), slog.Time("refreshed_at", watermark.RefreshedAt))
+			continue
+		}
+		j.warnUnmapped(ctx, groupID, period)
 		if err := j.Service.RebuildConsolidation(ctx, groupID, period); err != nil {
 			resultErr = err
 			j.log().Error("rebuild consolidation", slog.Int64("group_id", groupID), slog.String("period", period), slog.Any("error", err))
 			return resultErr
 		}
-		refreshed++
+		if err := j.Repo.MarkRefreshed(ctx, groupID, period, watermark.SourceChangedAt); err != nil {
+			resultErr = err
+			j.log().Error("record refresh watermark", slog.Int64("group_id", groupID), slog.String("period", period), slog.Any("error", err))
+			return resultErr
+		}
+		if restatement != nil {
+			note := consol.Restatement{
+				GroupID:        groupID,
+				Period:         period,
+				Reason:         restatement.Reason,
+				RequestedBy:    restatement.RequestedBy,
+				LockOverridden: locked,
+				RestatedAt:     j.now(),
+			}
+			if err := j.Repo.RecordRestatement(ctx, note); err != nil {
+				resultErr = err
+				j.log().Error("record restatement", slog.Int64("group_id", groupID), slog.String("period", period), slog.Any("error", err))
+				return resultErr
+			}
+			j.log().Info("restated consolidation", slog.Int64("group_id", groupID), slog.String("period", period),
+				slog.String("reason", restatement.Reason), slog.Bool("lock_overridden", locked))
+		}
+		refreshed = append(refreshed, groupID)
 	}
 
-	consolhttp.BustConsolViewCache()
+	if len(refreshed) > 0 {
+		consolhttp.BustConsolViewCache()
+	}
 
-	j.log().Info("refreshed consolidation balances", slog.String("period", period), slog.Int("groups", refreshed), slog.Duration("duration", time.Since(start)))
+	j.log().Info("refreshed consolidation balances",
+		slog.String("period", period),
+		slog.String("scope", payload.GroupID),
+		slog.Int("groups", len(refreshed)),
+		slog.Int("skipped", len(skipped)),
+		slog.Any("refreshed_group_ids", refreshed),
+		slog.Any("skipped_group_ids", skipped),
+		slog.Duration("duration", time.Since(start)))
 	return resultErr
 }
 
+// warnUnmapped logs balances the rebuild will leave out because their
+// accounts are not mapped. It never blocks the refresh.
+func (j *ConsolidateRefreshJob) warnUnmapped(ctx context.Context, groupID int64, period string) {
+	checker, ok := j.Service.(mappingChecker)
+	if !ok {
+		return
+	}
+	check, err := checker.CheckMappings(ctx, groupID, period)
+	if err != nil {
+		j.log().Warn("check account mappings", slog.Int64("group_id", groupID), slog.String("period", period), slog.Any("error", err))
+		return
+	}
+	if check.Complete() {
+		return
+	}
+	j.log().Warn("unmapped balances left out of consolidation",
+		slog.Int64("group_id", groupID),
+		slog.String("period", period),
+		slog.Int("accounts", check.Accounts),
+		slog.Float64("amount", check.Total))
+}
+
 func (j *ConsolidateRefreshJob) resolvePeriod(ctx context.Context, period string) (string, error) {
 	if period != "" && period != "active" {
 		return period, nil
@@ -150,7 +285,7 @@ func (j *ConsolidateRefreshJob) resolvePeriod(ctx context.Context, period string
 }
 
 func (j *ConsolidateRefreshJob) resolveGroups(ctx context.Context, group string) ([]int64, error) {
-	if group == "" || group == "all" {
+	if group == "" || group == ConsolScopeAll || group == ConsolScopeChanged {
 		return j.Repo.ListGroupIDs(ctx)
 	}
 	id, err := strconv.ParseInt(group, 10, 64)
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	jobmetrics "github.com/odyssey-erp/odyssey-erp/internal/jobs"
+)
+
+// TaskCreditHoldScan evaluates customer credit holds for overdue AR and credit utilization.
+const TaskCreditHoldScan = "sales:credit_hold_scan"
+
+// CreditHoldScanner places customers on credit hold when they breach the credit policy.
+type CreditHoldScanner interface {
+	ScanCreditHolds(ctx context.Context) ([]int64, error)
+}
+
+// CreditHoldScanJob runs the nightly customer credit hold evaluation.
+type CreditHoldScanJob struct {
+	Scanner CreditHoldScanner
+	Logger  *slog.Logger
+	Metrics *jobmetrics.Metrics
+}
+
+// NewCreditHoldScanJob constructs the job handler.
+func NewCreditHoldScanJob(scanner CreditHoldScanner, logger *slog.Logger, metrics *jobmetrics.Metrics) *CreditHoldScanJob {
+	return &CreditHoldScanJob{Scanner: scanner, Logger: logger, Metrics: metrics}
+}
+
+// NewCreditHoldScanTask creates an Asynq task for the credit hold scan.
+func NewCreditHoldScanTask() *asynq.Task {
+	return asynq.NewTask(TaskCreditHoldScan, nil, asynq.Queue(QueueDefault))
+}
+
+// Handle evaluates every candidate customer and logs newly placed holds.
+func (j *CreditHoldScanJob) Handle(ctx context.Context, _ *asynq.Task) error {
+	if j == nil || j.Scanner == nil {
+		return errors.New("credit hold scan: handler not configured")
+	}
+	start := time.Now()
+	tracker := j.metrics().Track(TaskCreditHoldScan)
+	var resultErr error
+	defer func() {
+		resultErr = tracker.End(resultErr)
+	}()
+
+	held, err := j.Scanner.ScanCreditHolds(ctx)
+	if err != nil {
+		resultErr = err
+		j.logger().Error("credit hold scan failed", slog.Any("error", err), slog.Int("held", len(held)))
+		return resultErr
+	}
+	j.logger().Info("completed credit hold scan",
+		slog.Int("held", len(held)),
+		slog.Any("customer_ids", held),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+func (j *CreditHoldScanJob) logger() *slog.Logger {
+	if j.Logger != nil {
+		return j.Logger.With(slog.String("job", TaskCreditHoldScan))
+	}
+	return slog.Default().With(slog.String("job", TaskCreditHoldScan))
+}
+
+func (j *CreditHoldScanJob) metrics() *jobmetrics.Metrics {
+	if j.Metrics != nil {
+		return j.Metrics
+	}
+	return defaultJobMetrics
+}
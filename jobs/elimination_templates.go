@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	jobmetrics "github.com/odyssey-erp/odyssey-erp/internal/jobs"
+)
+
+// TaskEliminationTemplatesApply re-applies elimination rule templates to current group members.
+const TaskEliminationTemplatesApply = "consol:elimination_templates_apply"
+
+// EliminationTemplateApplier expands active rule templates into missing rules.
+type EliminationTemplateApplier interface {
+	ApplyAllTemplates(ctx context.Context) (int, error)
+}
+
+// EliminationTemplatesJob keeps generated elimination rules in step with group membership.
+type EliminationTemplatesJob struct {
+	Applier EliminationTemplateApplier
+	Logger  *slog.Logger
+	Metrics *jobmetrics.Metrics
+}
+
+// NewEliminationTemplatesJob constructs the job handler.
+func NewEliminationTemplatesJob(applier EliminationTemplateApplier, logger *slog.Logger, metrics *jobmetrics.Metrics) *EliminationTemplatesJob {
+	return &EliminationTemplatesJob{Applier: applier, Logger: logger, Metrics: metrics}
+}
+
+// NewEliminationTemplatesApplyTask creates an Asynq task for the template re-apply.
+func NewEliminationTemplatesApplyTask() *asynq.Task {
+	return asynq.NewTask(TaskEliminationTemplatesApply, nil, asynq.Queue(QueueDefault))
+}
+
+// Handle applies every active template and logs how many rules were created.
+func (j *EliminationTemplatesJob) Handle(ctx context.Context, _ *asynq.Task) error {
+	if j == nil || j.Applier == nil {
+		return errors.New("elimination templates: handler not configured")
+	}
+	start := time.Now()
+	tracker := j.metrics().Track(TaskEliminationTemplatesApply)
+	var resultErr error
+	defer func() {
+		resultErr = tracker.End(resultErr)
+	}()
+
+	created, err := j.Applier.ApplyAllTemplates(ctx)
+	if err != nil {
+		resultErr = err
+		j.logger().Error("elimination template apply failed", slog.Any("error", err), slog.Int("created", created))
+		return resultErr
+	}
+	j.logger().Info("completed elimination template apply",
+		slog.Int("created", created),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+func (j *EliminationTemplatesJob) logger() *slog.Logger {
+	if j.Logger != nil {
+		return j.Logger.With(slog.String("job", TaskEliminationTemplatesApply))
+	}
+	return slog.Default().With(slog.String("job", TaskEliminationTemplatesApply))
+}
+
+func (j *EliminationTemplatesJob) metrics() *jobmetrics.Metrics {
+	if j.Metrics != nil {
+		return j.Metrics
+	}
+	return defaultJobMetrics
+}
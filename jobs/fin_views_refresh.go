@@ -14,12 +14,12 @@ func RefreshFinancialViews(ctx context.Context, pool *pgxpool.Pool, logger *slog
 	}
 	if _, err := pool.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY gl_balances`); err != nil {
 		if logger != nil {
-			logger.Error("refresh gl_balances", slog.Any("error", err))
+			logger.ErrorContext(ctx, "refresh gl_balances", slog.Any("error", err))
 		}
 		return err
 	}
 	if logger != nil {
-		logger.Info("refreshed gl_balances", slog.String("job", "fin_views_refresh"))
+		logger.InfoContext(ctx, "refreshed gl_balances", slog.String("job", "fin_views_refresh"))
 	}
 	return nil
 }
@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	jobmetrics "github.com/odyssey-erp/odyssey-erp/internal/jobs"
+)
+
+// TaskFXDailyFetch pulls the day's exchange rates from the configured provider.
+const TaskFXDailyFetch = "finance:fx_daily_fetch"
+
+// DailyRateFetcher stores the provider's rates for a date.
+type DailyRateFetcher interface {
+	FetchDaily(ctx context.Context, on time.Time) (int, error)
+}
+
+// FXDailyFetchJob runs the daily exchange rate fetch.
+type FXDailyFetchJob struct {
+	Fetcher DailyRateFetcher
+	Logger  *slog.Logger
+	Metrics *jobmetrics.Metrics
+	Now     func() time.Time
+}
+
+// NewFXDailyFetchJob constructs the job handler.
+func NewFXDailyFetchJob(fetcher DailyRateFetcher, logger *slog.Logger, metrics *jobmetrics.Metrics) *FXDailyFetchJob {
+	return &FXDailyFetchJob{Fetcher: fetcher, Logger: logger, Metrics: metrics}
+}
+
+// NewFXDailyFetchTask creates an Asynq task for the daily rate fetch.
+func NewFXDailyFetchTask() *asynq.Task {
+	return asynq.NewTask(TaskFXDailyFetch, nil, asynq.Queue(QueueDefault))
+}
+
+// Handle fetches today's rates and logs how many were stored.
+func (j *FXDailyFetchJob) Handle(ctx context.Context, _ *asynq.Task) error {
+	if j == nil || j.Fetcher == nil {
+		return errors.New("fx daily fetch: handler not configured")
+	}
+	start := time.Now()
+	tracker := j.metrics().Track(TaskFXDailyFetch)
+	var resultErr error
+	defer func() {
+		resultErr = tracker.End(resultErr)
+	}()
+
+	on := start.UTC()
+	if j.Now != nil {
+		on = j.Now().UTC()
+	}
+	stored, err := j.Fetcher.FetchDaily(ctx, on)
+	if err != nil {
+		resultErr = err
+		j.logger().Error("fx daily fetch failed", slog.Any("error", err), slog.String("date", on.Format("2006-01-02")))
+		return resultErr
+	}
+	j.logger().Info("completed fx daily fetch",
+		slog.Int("rates", stored),
+		slog.String("date", on.Format("2006-01-02")),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+func (j *FXDailyFetchJob) logger() *slog.Logger {
+	if j.Logger != nil {
+		return j.Logger.With(slog.String("job", TaskFXDailyFetch))
+	}
+	return slog.Default().With(slog.String("job", TaskFXDailyFetch))
+}
+
+func (j *FXDailyFetchJob) metrics() *jobmetrics.Metrics {
+	if j.Metrics != nil {
+		return j.Metrics
+	}
+	return defaultJobMetrics
+}
@@ -8,7 +8,7 @@ import (
 // RunGLIntegrityCheck is a placeholder integrity check for the general ledger.
 func RunGLIntegrityCheck(ctx context.Context, logger *slog.Logger) error {
 	if logger != nil {
-		logger.Info("GL integrity check executed", slog.String("job", "gl_integrity"))
+		logger.InfoContext(ctx, "GL integrity check executed", slog.String("job", "gl_integrity"))
 	}
 	// Future implementation: query debits/credits per period and ensure balance.
 	return nil
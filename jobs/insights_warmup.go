@@ -58,16 +58,16 @@ func (j *InsightsWarmupJob) Handle(ctx context.Context, t *asynq.Task) error {
 	}()
 
 	logger := j.logger().With(slog.String("period_scope", payload.PeriodScope))
-	logger.Info("starting insights warmup")
+	logger.InfoContext(ctx, "starting insights warmup")
 
 	scopes, err := j.fetchScopes(ctx)
 	if err != nil {
 		resultErr = err
-		logger.Error("load warmup scopes", slog.Any("error", err))
+		logger.ErrorContext(ctx, "load warmup scopes", slog.Any("error", err))
 		return resultErr
 	}
 	if len(scopes) == 0 {
-		logger.Info("no scopes discovered for warmup")
+		logger.InfoContext(ctx, "no scopes discovered for warmup")
 		return resultErr
 	}
 
@@ -76,13 +76,13 @@ func (j *InsightsWarmupJob) Handle(ctx context.Context, t *asynq.Task) error {
 	for _, scope := range scopes {
 		if err := j.warmScope(ctx, scope, now); err != nil {
 			resultErr = err
-			logger.Error("warm scope", slog.Int64("company_id", scope.CompanyID), slog.Int64("branch_id", scope.BranchValue()), slog.Any("error", err))
+			logger.ErrorContext(ctx, "warm scope", slog.Int64("company_id", scope.CompanyID), slog.Int64("branch_id", scope.BranchValue()), slog.Any("error", err))
 			return resultErr
 		}
 		warmed++
 	}
 
-	logger.Info("completed insights warmup", slog.Int("scopes", warmed), slog.Duration("duration", time.Since(now)))
+	logger.InfoContext(ctx, "completed insights warmup", slog.Int("scopes", warmed), slog.Duration("duration", time.Since(now)))
 	return resultErr
 }
 
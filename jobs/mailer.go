@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// SendEmailMaxRetry bounds how often a failed email delivery is retried.
+const SendEmailMaxRetry = 5
+
+// SMTPMailer delivers SendEmailPayload messages through an SMTP relay.
+type SMTPMailer struct {
+	Host   string
+	Port   int
+	From   string
+	Logger *slog.Logger
+}
+
+// Handle implements the TaskTypeSendEmail handler. Transport failures are
+// returned so Asynq retries the delivery; malformed payloads are dropped.
+func (m *SMTPMailer) Handle(ctx context.Context, t *asynq.Task) error {
+	var payload SendEmailPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("decode email payload: %v: %w", err, asynq.SkipRetry)
+	}
+	if strings.TrimSpace(payload.To) == "" {
+		return fmt.Errorf("email recipient missing: %w", asynq.SkipRetry)
+	}
+	if err := m.Send(ctx, payload); err != nil {
+		if m.Logger != nil {
+			m.Logger.WarnContext(ctx, "send email", slog.String("to", payload.To), slog.Any("error", err))
+		}
+		return err
+	}
+	return nil
+}
+
+// Send writes a single message to the relay. Cc and Bcc recipients receive
+// the same message; only Cc is listed in the headers.
+func (m *SMTPMailer) Send(ctx context.Context, payload SendEmailPayload) error {
+	if m == nil || m.Host == "" {
+		return errors.New("smtp mailer not configured")
+	}
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, nil, m.From, recipients(payload), buildMessage(m.From, payload, time.Now()))
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+func recipients(payload SendEmailPayload) []string {
+	out := []string{payload.To}
+	for _, addr := range append(append([]string(nil), payload.Cc...), payload.Bcc...) {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			out = append(out, addr)
+		}
+	}
+	return out
+}
+
+func buildMessage(from string, payload SendEmailPayload, now time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", payload.To)
+	if len(payload.Cc) > 0 {
+		fmt.Fprintf(&b, "Cc: %s\r\n", sanitizeHeader(strings.Join(payload.Cc, ", ")))
+	}
+	fmt.Fprintf(&b, "Subject: %s\r\n", sanitizeHeader(payload.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	body := strings.ReplaceAll(payload.Body, "\n", "\r\n")
+	if len(payload.Attachments) == 0 {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		b.WriteString("\r\n")
+		b.WriteString(body)
+		return []byte(b.String())
+	}
+	boundary := fmt.Sprintf("odyssey-%d", now.UnixNano())
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%q\r\n", boundary)
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(body)
+	b.WriteString("\r\n")
+	for _, att := range payload.Attachments {
+		contentType := att.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s\r\n", sanitizeHeader(contentType))
+		b.WriteString("Content-Transfer-Encoding: base64\r\n")
+		fmt.Fprintf(&b, "Content-Disposition: attachment; filename=%q\r\n\r\n", sanitizeHeader(att.Filename))
+		encoded := base64.StdEncoding.EncodeToString(att.Content)
+		for len(encoded) > 76 {
+			b.WriteString(encoded[:76])
+			b.WriteString("\r\n")
+			encoded = encoded[76:]
+		}
+		b.WriteString(encoded)
+		b.WriteString("\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return []byte(b.String())
+}
+
+func sanitizeHeader(v string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/requestid"
+)
+
+// requestIDField is the payload key carrying the ID of the request that
+// queued a task.
+const requestIDField = "request_id"
+
+// withRequestID copies the request ID in ctx into the task payload so the
+// worker logs the task under the ID of the request that queued it. Only
+// empty and JSON object payloads are tagged; others are queued unchanged.
+func withRequestID(ctx context.Context, task *asynq.Task) *asynq.Task {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return task
+	}
+	fields := map[string]json.RawMessage{}
+	if len(task.Payload()) > 0 {
+		if err := json.Unmarshal(task.Payload(), &fields); err != nil {
+			return task
+		}
+	}
+	if _, ok := fields[requestIDField]; ok {
+		return task
+	}
+	fields[requestIDField], _ = json.Marshal(id)
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return task
+	}
+	return asynq.NewTask(task.Type(), payload)
+}
+
+// requestIDMiddleware runs each task under the request ID it was queued
+// with, or under its task ID when it was not queued by a request, and logs
+// failures with that ID.
+func requestIDMiddleware(logger *slog.Logger) asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+			var tagged struct {
+				RequestID string `json:"request_id"`
+			}
+			_ = json.Unmarshal(task.Payload(), &tagged)
+			id := tagged.RequestID
+			if !requestid.Valid(id) {
+				id, _ = asynq.GetTaskID(ctx)
+			}
+			if id != "" {
+				ctx = requestid.NewContext(ctx, id)
+			}
+			err := next.ProcessTask(ctx, task)
+			if err != nil && logger != nil {
+				logger.WarnContext(ctx, "task failed", slog.String("type", task.Type()), slog.Any("error", err))
+			}
+			return err
+		})
+	}
+}
@@ -21,13 +21,39 @@ const (
 	TaskVarianceSnapshotProcess = "variance:snapshot_process"
 	// TaskBoardPackGenerate triggers board pack generation.
 	TaskBoardPackGenerate = "boardpack:generate"
+	// TaskBoardPackSchedule generates board packs for schedules due today.
+	TaskBoardPackSchedule = "boardpack:schedule"
+	// TaskReportGenerate renders an async report job to storage.
+	TaskReportGenerate = "report:generate"
+	// TaskReportCleanup removes expired report files.
+	TaskReportCleanup = "report:cleanup"
+	// TaskReportSchedule emails the reports of schedules due today.
+	TaskReportSchedule = "report:schedule"
+	// TaskDataExport uploads incremental table extracts to the data lake.
+	TaskDataExport = "dataexport:run"
+	// TaskApprovalEscalation escalates approvals pending past their SLA.
+	TaskApprovalEscalation = "approvals:escalate"
+	// TaskARInvoiceEmail renders an AR invoice and emails it to the customer.
+	TaskARInvoiceEmail = "ar:invoice_email"
+	// TaskDocumentArchive archives closed documents past the retention window.
+	TaskDocumentArchive = "archive:run"
 )
 
 // SendEmailPayload describes the information required to send an email.
 type SendEmailPayload struct {
-	To      string `json:"to"`
-	Subject string `json:"subject"`
-	Body    string `json:"body"`
+	To          string            `json:"to"`
+	Cc          []string          `json:"cc,omitempty"`
+	Bcc         []string          `json:"bcc,omitempty"`
+	Subject     string            `json:"subject"`
+	Body        string            `json:"body"`
+	Attachments []EmailAttachment `json:"attachments,omitempty"`
+}
+
+// EmailAttachment is a file sent along with an email.
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
 }
 
 // NewSendEmailTask constructs an Asynq task.
@@ -39,13 +65,13 @@ func NewSendEmailTask(payload SendEmailPayload) (*asynq.Task, error) {
 	return asynq.NewTask(TaskTypeSendEmail, data), nil
 }
 
-// HandleSendEmailTask processes TaskTypeSendEmail tasks.
+// HandleSendEmailTask processes TaskTypeSendEmail tasks when no SMTP mailer is
+// configured on the worker.
 func HandleSendEmailTask(ctx context.Context, t *asynq.Task) error {
 	var payload SendEmailPayload
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
 		return asynq.SkipRetry
 	}
-	// Placeholder: integrate with SMTP/Mailpit in phase 2.
 	fmt.Printf("[jobs] send email to %s subject=%s\n", payload.To, payload.Subject)
 	return nil
 }
@@ -121,3 +147,67 @@ func NewBoardPackTask(boardPackID int64) (*asynq.Task, error) {
 	}
 	return asynq.NewTask(TaskBoardPackGenerate, body, asynq.Queue(QueueDefault)), nil
 }
+
+// NewBoardPackScheduleTask constructs the daily board pack schedule task.
+func NewBoardPackScheduleTask() *asynq.Task {
+	return asynq.NewTask(TaskBoardPackSchedule, nil, asynq.Queue(QueueDefault))
+}
+
+// ReportJobPayload points to the report job that should be generated.
+type ReportJobPayload struct {
+	ReportJobID int64 `json:"report_job_id"`
+}
+
+// NewReportJobTask enqueues an async report generation job.
+func NewReportJobTask(reportJobID int64) (*asynq.Task, error) {
+	if reportJobID == 0 {
+		return nil, fmt.Errorf("jobs: report job id required")
+	}
+	body, err := json.Marshal(ReportJobPayload{ReportJobID: reportJobID})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TaskReportGenerate, body, asynq.Queue(QueueDefault)), nil
+}
+
+// NewReportCleanupTask constructs the expired report cleanup task.
+func NewReportCleanupTask() *asynq.Task {
+	return asynq.NewTask(TaskReportCleanup, nil, asynq.Queue(QueueDefault))
+}
+
+// NewReportScheduleTask constructs the daily report schedule task.
+func NewReportScheduleTask() *asynq.Task {
+	return asynq.NewTask(TaskReportSchedule, nil, asynq.Queue(QueueDefault))
+}
+
+// NewDataExportTask constructs the scheduled data lake export task.
+func NewDataExportTask() *asynq.Task {
+	return asynq.NewTask(TaskDataExport, nil, asynq.Queue(QueueDefault))
+}
+
+// NewApprovalEscalationTask constructs the scheduled approval SLA escalation task.
+func NewApprovalEscalationTask() *asynq.Task {
+	return asynq.NewTask(TaskApprovalEscalation, nil, asynq.Queue(QueueDefault))
+}
+
+// NewDocumentArchiveTask constructs the scheduled document archival task.
+func NewDocumentArchiveTask() *asynq.Task {
+	return asynq.NewTask(TaskDocumentArchive, nil, asynq.Queue(QueueDefault))
+}
+
+// ARInvoiceEmailPayload points to the queued AR invoice email.
+type ARInvoiceEmailPayload struct {
+	EmailID int64 `json:"email_id"`
+}
+
+// NewARInvoiceEmailTask constructs the AR invoice email task.
+func NewARInvoiceEmailTask(emailID int64) (*asynq.Task, error) {
+	if emailID == 0 {
+		return nil, fmt.Errorf("jobs: invoice email id required")
+	}
+	body, err := json.Marshal(ARInvoiceEmailPayload{EmailID: emailID})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TaskARInvoiceEmail, body, asynq.Queue(QueueDefault)), nil
+}
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_delivery_backorders_updated_at ON delivery_backorders;
+DROP TABLE IF EXISTS delivery_backorders;
+DROP TYPE IF EXISTS delivery_backorder_status;
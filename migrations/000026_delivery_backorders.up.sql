@@ -0,0 +1,38 @@
+-- Delivery back-orders: shortfall recorded when a DO ships less than requested
+
+CREATE TYPE delivery_backorder_status AS ENUM (
+    'OPEN',
+    'FULFILLED',
+    'CANCELLED'
+);
+
+CREATE TABLE IF NOT EXISTS delivery_backorders (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE RESTRICT,
+    sales_order_id BIGINT NOT NULL REFERENCES sales_orders(id) ON DELETE CASCADE,
+    sales_order_line_id BIGINT NOT NULL REFERENCES sales_order_lines(id) ON DELETE CASCADE,
+    delivery_order_id BIGINT NOT NULL REFERENCES delivery_orders(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id BIGINT NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quantity_requested NUMERIC(14,4) NOT NULL CHECK (quantity_requested > 0),
+    quantity_short NUMERIC(14,4) NOT NULL CHECK (quantity_short > 0),
+    status delivery_backorder_status NOT NULL DEFAULT 'OPEN',
+    fulfilled_delivery_order_id BIGINT REFERENCES delivery_orders(id) ON DELETE SET NULL,
+    fulfilled_at TIMESTAMPTZ,
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_backorder_short CHECK (quantity_short <= quantity_requested)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_backorders_company_status ON delivery_backorders(company_id, status);
+CREATE INDEX IF NOT EXISTS idx_delivery_backorders_so_line ON delivery_backorders(sales_order_line_id);
+CREATE INDEX IF NOT EXISTS idx_delivery_backorders_do ON delivery_backorders(delivery_order_id);
+
+CREATE TRIGGER trg_delivery_backorders_updated_at
+    BEFORE UPDATE ON delivery_backorders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE delivery_backorders IS
+'Quantities that could not be shipped on a delivery order due to insufficient stock; fulfilled by a later DO';
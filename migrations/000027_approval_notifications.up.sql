@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS notify_approval_emails BOOLEAN NOT NULL DEFAULT TRUE;
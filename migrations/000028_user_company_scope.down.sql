@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission_id IN (
+    SELECT id FROM permissions WHERE name = 'system.superuser'
+);
+DELETE FROM permissions WHERE name = 'system.superuser';
+
+DROP TABLE IF EXISTS user_companies;
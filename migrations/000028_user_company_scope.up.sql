@@ -0,0 +1,25 @@
+-- Restrict users to the companies they are assigned to.
+CREATE TABLE IF NOT EXISTS user_companies (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, company_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_companies_company ON user_companies(company_id);
+
+-- Existing users keep access to every company that exists today.
+INSERT INTO user_companies (user_id, company_id)
+SELECT u.id, c.id FROM users u CROSS JOIN companies c
+ON CONFLICT DO NOTHING;
+
+-- Super-users bypass company scoping.
+INSERT INTO permissions (name, description) VALUES
+    ('system.superuser', 'Bypass company scoping and access every company')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'Admin'
+AND p.name = 'system.superuser'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS quotation_template_lines;
+DROP TABLE IF EXISTS quotation_templates;
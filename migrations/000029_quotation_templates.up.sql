@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS quotation_templates (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_quotation_templates_company_name UNIQUE (company_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS quotation_template_lines (
+    id BIGSERIAL PRIMARY KEY,
+    template_id BIGINT NOT NULL REFERENCES quotation_templates(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    description TEXT,
+    quantity NUMERIC(14,4) NOT NULL CHECK (quantity > 0),
+    uom TEXT NOT NULL DEFAULT 'PCS',
+    discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent >= 0 AND discount_percent <= 100),
+    tax_percent NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (tax_percent >= 0),
+    line_order INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotation_template_lines_template ON quotation_template_lines(template_id);
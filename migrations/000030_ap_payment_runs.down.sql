@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_ap_invoices_due_open;
+DROP INDEX IF EXISTS idx_ap_payments_run;
+ALTER TABLE ap_payments DROP COLUMN IF EXISTS payment_run_id;
+DROP TABLE IF EXISTS ap_payment_runs;
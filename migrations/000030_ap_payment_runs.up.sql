@@ -0,0 +1,22 @@
+-- AP payment runs: pay many due invoices in one batch
+
+CREATE TABLE IF NOT EXISTS ap_payment_runs (
+    id BIGSERIAL PRIMARY KEY,
+    number TEXT NOT NULL UNIQUE,
+    pay_date DATE NOT NULL,
+    method TEXT NOT NULL DEFAULT 'TRANSFER',
+    note TEXT NOT NULL DEFAULT '',
+    due_before DATE NOT NULL,
+    supplier_id BIGINT REFERENCES suppliers(id) ON DELETE SET NULL,
+    cash_limit NUMERIC(15,2) NOT NULL DEFAULT 0,
+    group_by_supplier BOOLEAN NOT NULL DEFAULT TRUE,
+    total NUMERIC(15,2) NOT NULL DEFAULT 0,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE ap_payments
+    ADD COLUMN IF NOT EXISTS payment_run_id BIGINT REFERENCES ap_payment_runs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ap_payments_run ON ap_payments(payment_run_id);
+CREATE INDEX IF NOT EXISTS idx_ap_invoices_due_open ON ap_invoices(due_at) WHERE status = 'POSTED';
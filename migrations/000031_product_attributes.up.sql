@@ -0,0 +1,7 @@
+-- Product custom attributes: per-category schemas and per-product values
+
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS attribute_schema JSONB NOT NULL DEFAULT '[]'::JSONB;
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::JSONB;
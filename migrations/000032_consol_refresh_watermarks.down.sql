@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trg_account_map_group_change ON account_map;
+DROP TRIGGER IF EXISTS trg_consol_members_group_change ON consol_members;
+DROP FUNCTION IF EXISTS consol_stamp_group_change();
+DROP TABLE IF EXISTS consol_group_changes;
+DROP INDEX IF EXISTS idx_journal_entries_period_updated;
+DROP TABLE IF EXISTS consol_refresh_watermarks;
@@ -0,0 +1,40 @@
+-- Consolidation refresh watermarks: lets the nightly job skip unchanged groups
+
+CREATE TABLE IF NOT EXISTS consol_refresh_watermarks (
+    group_id BIGINT NOT NULL REFERENCES consol_groups(id) ON DELETE CASCADE,
+    period_id BIGINT NOT NULL REFERENCES periods(id) ON DELETE CASCADE,
+    source_watermark TIMESTAMPTZ NOT NULL,
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, period_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_period_updated ON journal_entries(period_id, updated_at);
+
+-- Deleted members and mappings leave no updated_at behind, so removing one
+-- (or moving it to another group) stamps the group it left instead.
+CREATE TABLE IF NOT EXISTS consol_group_changes (
+    group_id BIGINT PRIMARY KEY REFERENCES consol_groups(id) ON DELETE CASCADE,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE OR REPLACE FUNCTION consol_stamp_group_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- The group itself may be going away in the same cascade.
+    INSERT INTO consol_group_changes (group_id, changed_at)
+    SELECT OLD.group_id, NOW()
+    WHERE EXISTS (SELECT 1 FROM consol_groups WHERE id = OLD.group_id)
+    ON CONFLICT (group_id) DO UPDATE SET changed_at = EXCLUDED.changed_at;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_consol_members_group_change
+AFTER DELETE OR UPDATE OF group_id ON consol_members
+FOR EACH ROW
+EXECUTE FUNCTION consol_stamp_group_change();
+
+CREATE TRIGGER trg_account_map_group_change
+AFTER DELETE OR UPDATE OF group_id ON account_map
+FOR EACH ROW
+EXECUTE FUNCTION consol_stamp_group_change();
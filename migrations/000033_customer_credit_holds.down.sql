@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS customer_credit_hold_invoices;
+DROP TABLE IF EXISTS customer_credit_holds;
@@ -0,0 +1,27 @@
+-- Customer credit holds: automatic holds for overdue AR or exceeded credit limits
+
+CREATE TABLE IF NOT EXISTS customer_credit_holds (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id BIGINT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    credit_limit NUMERIC(18,2) NOT NULL DEFAULT 0,
+    exposure NUMERIC(18,2) NOT NULL DEFAULT 0,
+    placed_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    placed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    released_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    released_at TIMESTAMPTZ NULL,
+    release_note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_customer_credit_holds_active ON customer_credit_holds(customer_id) WHERE released_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_customer_credit_holds_customer ON customer_credit_holds(customer_id, placed_at DESC);
+
+CREATE TABLE IF NOT EXISTS customer_credit_hold_invoices (
+    hold_id BIGINT NOT NULL REFERENCES customer_credit_holds(id) ON DELETE CASCADE,
+    ar_invoice_id BIGINT NOT NULL REFERENCES ar_invoices(id) ON DELETE CASCADE,
+    invoice_number TEXT NOT NULL,
+    due_at TIMESTAMPTZ NOT NULL,
+    balance NUMERIC(14,2) NOT NULL,
+    days_overdue INT NOT NULL,
+    PRIMARY KEY (hold_id, ar_invoice_id)
+);
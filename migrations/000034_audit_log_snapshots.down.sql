@@ -0,0 +1,3 @@
+ALTER TABLE audit_logs
+    DROP COLUMN IF EXISTS after,
+    DROP COLUMN IF EXISTS before;
@@ -0,0 +1,5 @@
+-- Audit log snapshots: before/after state of the entity for update operations
+
+ALTER TABLE audit_logs
+    ADD COLUMN IF NOT EXISTS before JSONB,
+    ADD COLUMN IF NOT EXISTS after JSONB;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS inventory_transfer_requests;
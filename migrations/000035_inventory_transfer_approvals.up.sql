@@ -0,0 +1,25 @@
+-- Inventory transfer approvals: high-value transfers wait for approval before posting
+
+CREATE TABLE IF NOT EXISTS inventory_transfer_requests (
+    id BIGSERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    qty NUMERIC(14,4) NOT NULL CHECK (qty > 0),
+    src_warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    dst_warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    unit_cost NUMERIC(14,4) NOT NULL DEFAULT 0,
+    value NUMERIC(18,2) NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    ref_module TEXT NOT NULL DEFAULT '',
+    ref_id UUID NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING','APPROVED','REJECTED')),
+    requested_by BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ NULL,
+    decision_reason TEXT NOT NULL DEFAULT '',
+    posted_at TIMESTAMPTZ NULL,
+    CHECK (src_warehouse_id <> dst_warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_transfer_requests_status ON inventory_transfer_requests(status, requested_at DESC);
@@ -0,0 +1,4 @@
+ALTER TABLE delivery_orders
+    DROP COLUMN IF EXISTS pod_notes,
+    DROP COLUMN IF EXISTS pod_signature,
+    DROP COLUMN IF EXISTS pod_receiver_name;
@@ -0,0 +1,8 @@
+-- Proof of delivery captured when a delivery order is marked delivered
+
+ALTER TABLE delivery_orders
+    ADD COLUMN IF NOT EXISTS pod_receiver_name TEXT,
+    ADD COLUMN IF NOT EXISTS pod_signature TEXT,
+    ADD COLUMN IF NOT EXISTS pod_notes TEXT;
+
+COMMENT ON COLUMN delivery_orders.pod_signature IS 'Receiver signature as an image data URL';
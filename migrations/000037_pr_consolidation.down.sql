@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS po_source_prs;
+
+ALTER TABLE prs DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,11 @@
+-- Purchase request consolidation: PR currency and PR-to-PO source links
+
+ALTER TABLE prs
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'IDR';
+
+CREATE TABLE IF NOT EXISTS po_source_prs (
+    po_id BIGINT NOT NULL REFERENCES pos(id) ON DELETE CASCADE,
+    pr_id BIGINT NOT NULL REFERENCES prs(id) ON DELETE RESTRICT,
+    PRIMARY KEY (po_id, pr_id),
+    UNIQUE (pr_id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE warehouses
+    DROP COLUMN IF EXISTS gl_dim_branch_id,
+    DROP COLUMN IF EXISTS gl_dim_company_id;
+
+ALTER TABLE branches DROP COLUMN IF EXISTS gl_dim_company_id;
@@ -0,0 +1,10 @@
+-- Default GL dimensions stamped on integration journals per warehouse/branch.
+-- NULL falls back to the organisational hierarchy (warehouse -> branch -> company).
+-- Existing journal_lines are not backfilled here; see docs/reference/account-mapping.md.
+
+ALTER TABLE branches
+    ADD COLUMN IF NOT EXISTS gl_dim_company_id BIGINT NULL REFERENCES companies(id) ON DELETE SET NULL;
+
+ALTER TABLE warehouses
+    ADD COLUMN IF NOT EXISTS gl_dim_company_id BIGINT NULL REFERENCES companies(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS gl_dim_branch_id BIGINT NULL REFERENCES branches(id) ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+-- Credit limits, tax IDs and notes are masked unless the viewer holds this permission.
+INSERT INTO permissions (name, description) VALUES
+    ('sales.customer.view_sensitive', 'View customer credit limits, tax IDs and notes')
+ON CONFLICT (name) DO NOTHING;
+
+-- Roles that can already edit customers keep seeing the full record.
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT DISTINCT rp.role_id, p.id
+FROM role_permissions rp
+JOIN permissions existing ON existing.id = rp.permission_id
+CROSS JOIN permissions p
+WHERE existing.name = 'sales.customer.edit'
+AND p.name = 'sales.customer.view_sensitive'
+ON CONFLICT DO NOTHING;
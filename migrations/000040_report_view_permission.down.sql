@@ -0,0 +1,4 @@
+-- report.view predates this migration in seeded databases, so only the Admin grant is reverted.
+DELETE FROM role_permissions
+WHERE role_id IN (SELECT id FROM roles WHERE name = 'Admin')
+AND permission_id IN (SELECT id FROM permissions WHERE name = 'report.view');
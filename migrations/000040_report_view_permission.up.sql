@@ -0,0 +1,10 @@
+-- Cross-module operational reports (open items export).
+INSERT INTO permissions (name, description) VALUES
+    ('report.view', 'Access reports')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'Admin'
+AND p.name = 'report.view'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+ALTER TABLE suppliers
+    DROP COLUMN IF EXISTS country,
+    DROP COLUMN IF EXISTS tax_id;
@@ -0,0 +1,4 @@
+-- Suppliers carry a tax ID validated against the format of their country.
+ALTER TABLE suppliers
+    ADD COLUMN IF NOT EXISTS tax_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS country TEXT NOT NULL DEFAULT 'ID';
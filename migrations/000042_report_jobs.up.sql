@@ -0,0 +1,21 @@
+-- Background generation of large reports (GL extract, inventory as-of).
+CREATE TABLE report_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}'::JSONB,
+    status TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'IN_PROGRESS', 'READY', 'FAILED', 'EXPIRED')),
+    progress INT NOT NULL DEFAULT 0,
+    rows_written BIGINT NOT NULL DEFAULT 0,
+    file_path TEXT,
+    file_name TEXT,
+    file_size BIGINT,
+    error_message TEXT,
+    requested_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+CREATE INDEX idx_report_jobs_requested_by ON report_jobs(requested_by, created_at DESC);
+CREATE INDEX idx_report_jobs_expiry ON report_jobs(expires_at) WHERE status = 'READY';
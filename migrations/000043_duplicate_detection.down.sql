@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_suppliers_name_trgm;
+DROP INDEX IF EXISTS idx_customers_name_trgm;
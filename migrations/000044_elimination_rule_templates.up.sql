@@ -0,0 +1,16 @@
+-- Account-pair templates expanded into elimination_rules for every enabled
+-- member pair of a consolidation group.
+CREATE TABLE elimination_rule_templates (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES consol_groups(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    account_src TEXT NOT NULL,
+    account_tgt TEXT NOT NULL,
+    match_criteria JSONB NOT NULL DEFAULT '{}'::JSONB,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_elimination_rule_templates UNIQUE (group_id, account_src, account_tgt)
+);
+CREATE INDEX idx_elimination_rule_templates_active ON elimination_rule_templates(group_id) WHERE is_active;
@@ -0,0 +1,16 @@
+-- ABC classification by consumption value, read by cycle-count scheduling.
+-- warehouse_id 0 holds the classification across all warehouses of company_id.
+CREATE TABLE inventory_abc_classes (
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    warehouse_id BIGINT NOT NULL DEFAULT 0,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    abc_class CHAR(1) NOT NULL CHECK (abc_class IN ('A', 'B', 'C')),
+    consumption_value NUMERIC(18,2) NOT NULL DEFAULT 0,
+    cumulative_share NUMERIC(7,4) NOT NULL DEFAULT 0,
+    rank INT NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    classified_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (company_id, warehouse_id, product_id)
+);
+CREATE INDEX idx_inventory_abc_classes_class ON inventory_abc_classes(company_id, warehouse_id, abc_class);
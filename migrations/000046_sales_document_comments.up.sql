@@ -0,0 +1,11 @@
+-- Free-form comment threads on quotations and sales orders. Comments do not
+-- affect the document status.
+CREATE TABLE sales_document_comments (
+    id BIGSERIAL PRIMARY KEY,
+    document_type TEXT NOT NULL CHECK (document_type IN ('QUOTATION', 'SALES_ORDER')),
+    document_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL REFERENCES users(id),
+    message TEXT NOT NULL CHECK (length(message) BETWEEN 1 AND 2000),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_sales_document_comments_document ON sales_document_comments(document_type, document_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS company_due_date_policies;
+DROP TABLE IF EXISTS holiday_calendar_days;
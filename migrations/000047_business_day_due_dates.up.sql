@@ -0,0 +1,56 @@
+-- Holiday calendars used to roll invoice due dates to the next business day.
+CREATE TABLE holiday_calendar_days (
+    calendar_code TEXT NOT NULL,
+    holiday_date DATE NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY (calendar_code, holiday_date)
+);
+
+-- Per-company due date setting. Companies without a row keep calendar-day
+-- due dates.
+CREATE TABLE company_due_date_policies (
+    company_id BIGINT PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    business_days BOOLEAN NOT NULL DEFAULT FALSE,
+    holiday_calendar TEXT NOT NULL DEFAULT 'ID',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Starter calendar: Indonesian national holidays (libur nasional) per the
+-- joint ministerial decrees. Collective leave days (cuti bersama) are not
+-- included. Extend yearly once the decree is published.
+INSERT INTO holiday_calendar_days (calendar_code, holiday_date, name) VALUES
+    ('ID', '2025-01-01', 'Tahun Baru Masehi'),
+    ('ID', '2025-01-27', 'Isra Mikraj Nabi Muhammad SAW'),
+    ('ID', '2025-01-29', 'Tahun Baru Imlek'),
+    ('ID', '2025-03-29', 'Hari Suci Nyepi'),
+    ('ID', '2025-03-31', 'Hari Raya Idul Fitri'),
+    ('ID', '2025-04-01', 'Hari Raya Idul Fitri'),
+    ('ID', '2025-04-18', 'Wafat Yesus Kristus'),
+    ('ID', '2025-04-20', 'Kebangkitan Yesus Kristus (Paskah)'),
+    ('ID', '2025-05-01', 'Hari Buruh Internasional'),
+    ('ID', '2025-05-12', 'Hari Raya Waisak'),
+    ('ID', '2025-05-29', 'Kenaikan Yesus Kristus'),
+    ('ID', '2025-06-01', 'Hari Lahir Pancasila'),
+    ('ID', '2025-06-06', 'Hari Raya Idul Adha'),
+    ('ID', '2025-06-27', 'Tahun Baru Islam'),
+    ('ID', '2025-08-17', 'Hari Kemerdekaan Republik Indonesia'),
+    ('ID', '2025-09-05', 'Maulid Nabi Muhammad SAW'),
+    ('ID', '2025-12-25', 'Hari Raya Natal'),
+    ('ID', '2026-01-01', 'Tahun Baru Masehi'),
+    ('ID', '2026-01-16', 'Isra Mikraj Nabi Muhammad SAW'),
+    ('ID', '2026-02-17', 'Tahun Baru Imlek'),
+    ('ID', '2026-03-19', 'Hari Suci Nyepi'),
+    ('ID', '2026-03-21', 'Hari Raya Idul Fitri'),
+    ('ID', '2026-03-22', 'Hari Raya Idul Fitri'),
+    ('ID', '2026-04-03', 'Wafat Yesus Kristus'),
+    ('ID', '2026-04-05', 'Kebangkitan Yesus Kristus (Paskah)'),
+    ('ID', '2026-05-01', 'Hari Buruh Internasional'),
+    ('ID', '2026-05-14', 'Kenaikan Yesus Kristus'),
+    ('ID', '2026-05-27', 'Hari Raya Idul Adha'),
+    ('ID', '2026-05-31', 'Hari Raya Waisak'),
+    ('ID', '2026-06-01', 'Hari Lahir Pancasila'),
+    ('ID', '2026-06-16', 'Tahun Baru Islam'),
+    ('ID', '2026-08-17', 'Hari Kemerdekaan Republik Indonesia'),
+    ('ID', '2026-08-25', 'Maulid Nabi Muhammad SAW'),
+    ('ID', '2026-12-25', 'Hari Raya Natal')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+-- Products had no modification timestamp; edit forms use updated_at as the
+-- optimistic locking version.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
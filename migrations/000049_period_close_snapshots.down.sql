@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS trg_period_close_snapshot_lines_immutable ON period_close_snapshot_lines;
+DROP TRIGGER IF EXISTS trg_period_close_snapshots_immutable ON period_close_snapshots;
+DROP TABLE IF EXISTS period_close_snapshot_lines;
+DROP TABLE IF EXISTS period_close_snapshots;
+DROP FUNCTION IF EXISTS reject_period_close_snapshot_change();
@@ -0,0 +1,44 @@
+-- Trial balance captured when a period is hard closed. Rows are written once
+-- and never changed, so the "as reported" figures survive later postings.
+CREATE TABLE period_close_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    period_id BIGINT NOT NULL UNIQUE REFERENCES accounting_periods(id) ON DELETE RESTRICT,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE RESTRICT,
+    run_id BIGINT NOT NULL REFERENCES period_close_runs(id) ON DELETE RESTRICT,
+    net_income NUMERIC(18,2) NOT NULL DEFAULT 0,
+    total_assets NUMERIC(18,2) NOT NULL DEFAULT 0,
+    total_liabilities NUMERIC(18,2) NOT NULL DEFAULT 0,
+    total_equity NUMERIC(18,2) NOT NULL DEFAULT 0,
+    captured_by BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE period_close_snapshot_lines (
+    id BIGSERIAL PRIMARY KEY,
+    snapshot_id BIGINT NOT NULL REFERENCES period_close_snapshots(id) ON DELETE RESTRICT,
+    account_code TEXT NOT NULL,
+    account_name TEXT NOT NULL,
+    account_type TEXT NOT NULL,
+    opening NUMERIC(18,2) NOT NULL DEFAULT 0,
+    debit NUMERIC(18,2) NOT NULL DEFAULT 0,
+    credit NUMERIC(18,2) NOT NULL DEFAULT 0,
+    closing NUMERIC(18,2) NOT NULL DEFAULT 0,
+    UNIQUE (snapshot_id, account_code)
+);
+
+CREATE OR REPLACE FUNCTION reject_period_close_snapshot_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'period close snapshots are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_period_close_snapshots_immutable
+    BEFORE UPDATE OR DELETE ON period_close_snapshots
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_period_close_snapshot_change();
+
+CREATE TRIGGER trg_period_close_snapshot_lines_immutable
+    BEFORE UPDATE OR DELETE ON period_close_snapshot_lines
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_period_close_snapshot_change();
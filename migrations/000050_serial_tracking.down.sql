@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_inventory_serials_updated_at ON inventory_serials;
+DROP TABLE IF EXISTS inventory_serials;
+ALTER TABLE grn_lines DROP COLUMN IF EXISTS serials;
+ALTER TABLE products DROP COLUMN IF EXISTS track_serial;
@@ -0,0 +1,32 @@
+-- Products flagged for serial tracking need one serial number per unit on
+-- goods receipt and on delivery.
+ALTER TABLE products ADD COLUMN track_serial BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Serials entered on a draft GRN line; registered in inventory_serials when
+-- the GRN is posted.
+ALTER TABLE grn_lines ADD COLUMN serials TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE inventory_serials (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    serial_number TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'IN_STOCK' CHECK (status IN ('IN_STOCK','DELIVERED')),
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    grn_id BIGINT REFERENCES grns(id) ON DELETE SET NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivery_order_id BIGINT REFERENCES delivery_orders(id) ON DELETE SET NULL,
+    delivery_order_line_id BIGINT REFERENCES delivery_order_lines(id) ON DELETE SET NULL,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, serial_number)
+);
+
+CREATE INDEX idx_inventory_serials_serial_number ON inventory_serials(serial_number);
+CREATE INDEX idx_inventory_serials_stock ON inventory_serials(warehouse_id, product_id) WHERE status = 'IN_STOCK';
+CREATE INDEX idx_inventory_serials_delivery_order ON inventory_serials(delivery_order_id);
+
+CREATE TRIGGER trg_inventory_serials_updated_at
+    BEFORE UPDATE ON inventory_serials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+ALTER TABLE ap_invoices DROP COLUMN IF EXISTS payment_terms_days;
+ALTER TABLE suppliers DROP COLUMN IF EXISTS payment_terms_days;
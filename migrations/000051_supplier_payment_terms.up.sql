@@ -0,0 +1,8 @@
+-- Default net payment terms per supplier, mirroring customers.payment_terms_days.
+ALTER TABLE suppliers
+    ADD COLUMN payment_terms_days INT NOT NULL DEFAULT 30
+        CHECK (payment_terms_days >= 0 AND payment_terms_days <= 365);
+
+-- Terms applied when an AP invoice due date was derived from the supplier;
+-- NULL when the due date was entered by hand.
+ALTER TABLE ap_invoices ADD COLUMN payment_terms_days INT;
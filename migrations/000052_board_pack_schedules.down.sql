@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_board_packs_period_template;
+DROP TABLE IF EXISTS board_pack_schedules;
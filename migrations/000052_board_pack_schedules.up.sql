@@ -0,0 +1,20 @@
+-- Board packs generated automatically for a company/template combination,
+-- either when a period is hard closed or on a fixed day each month.
+CREATE TABLE board_pack_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    template_id BIGINT NOT NULL REFERENCES board_pack_templates(id) ON DELETE CASCADE,
+    trigger TEXT NOT NULL CHECK (trigger IN ('PERIOD_CLOSE', 'MONTHLY')),
+    day_of_month INT CHECK (day_of_month BETWEEN 1 AND 28),
+    recipients TEXT[] NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (company_id, template_id),
+    CHECK (trigger <> 'MONTHLY' OR day_of_month IS NOT NULL)
+);
+CREATE INDEX idx_board_pack_schedules_trigger ON board_pack_schedules(trigger) WHERE is_active;
+
+-- Speeds up the duplicate check made before a scheduled pack is created.
+CREATE INDEX idx_board_packs_period_template ON board_packs(period_id, template_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ap_invoices_supplier;
+DROP INDEX IF EXISTS idx_grns_supplier;
+DROP INDEX IF EXISTS idx_pos_supplier;
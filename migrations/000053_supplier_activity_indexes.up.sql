@@ -0,0 +1,6 @@
+-- Supplier lookups used by the supplier activity timeline. The customer side
+-- already has equivalent indexes on quotations, sales_orders and
+-- delivery_orders.
+CREATE INDEX IF NOT EXISTS idx_pos_supplier ON pos(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_grns_supplier ON grns(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_ap_invoices_supplier ON ap_invoices(supplier_id);
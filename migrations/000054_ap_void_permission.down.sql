@@ -0,0 +1,9 @@
+-- finance.ap.edit predates this migration in seeded databases, so only the
+-- grants copied from finance.ap.void are removed.
+DELETE FROM role_permissions rp
+USING permissions edit, role_permissions voidrp, permissions void
+WHERE edit.name = 'finance.ap.edit'
+  AND rp.permission_id = edit.id
+  AND voidrp.role_id = rp.role_id
+  AND voidrp.permission_id = void.id
+  AND void.name = 'finance.ap.void';
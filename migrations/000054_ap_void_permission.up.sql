@@ -0,0 +1,13 @@
+-- Voiding AP invoices now needs finance.ap.edit. Grant it to every role that
+-- could void before so existing users keep the ability.
+INSERT INTO permissions (name, description) VALUES
+    ('finance.ap.edit', 'Manage AP documents')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, edit.id
+FROM role_permissions rp
+JOIN permissions void ON void.id = rp.permission_id AND void.name = 'finance.ap.void'
+CROSS JOIN permissions edit
+WHERE edit.name = 'finance.ap.edit'
+ON CONFLICT DO NOTHING;
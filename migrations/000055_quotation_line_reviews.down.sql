@@ -0,0 +1 @@
+DROP TABLE IF EXISTS quotation_line_reviews;
@@ -0,0 +1,16 @@
+-- Per-line approver decisions on quotations. A REVISE row stays open until the
+-- sales rep records a response; open flags block conversion to a sales order.
+CREATE TABLE quotation_line_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    quotation_id BIGINT NOT NULL REFERENCES quotations(id) ON DELETE CASCADE,
+    quotation_line_id BIGINT REFERENCES quotation_lines(id) ON DELETE SET NULL,
+    decision VARCHAR(16) NOT NULL CHECK (decision IN ('APPROVED', 'REVISE')),
+    note TEXT,
+    reviewed_by BIGINT NOT NULL REFERENCES users(id),
+    reviewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    response TEXT,
+    resolved_by BIGINT REFERENCES users(id),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_quotation_line_reviews_quotation ON quotation_line_reviews(quotation_id, reviewed_at);
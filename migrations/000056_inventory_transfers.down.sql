@@ -0,0 +1,2 @@
+ALTER TABLE inventory_transfer_requests DROP COLUMN IF EXISTS in_transit;
+DROP TABLE IF EXISTS inventory_transfers;
@@ -0,0 +1,44 @@
+-- Posted stock transfers with their dispatch and receipt. Two-phase transfers
+-- stay IN_TRANSIT between leaving the source and arriving at the destination.
+CREATE TABLE IF NOT EXISTS inventory_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    qty NUMERIC(14,4) NOT NULL CHECK (qty > 0),
+    src_warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    dst_warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    unit_cost NUMERIC(14,4) NOT NULL DEFAULT 0,
+    value NUMERIC(18,2) NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'IN_TRANSIT' CHECK (status IN ('IN_TRANSIT','RECEIVED')),
+    dispatched_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    dispatched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    received_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    received_at TIMESTAMPTZ NULL,
+    CHECK (src_warehouse_id <> dst_warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_transfers_status ON inventory_transfers(status, dispatched_at);
+CREATE INDEX IF NOT EXISTS idx_inventory_transfers_product ON inventory_transfers(product_id, dispatched_at DESC);
+
+-- Backfill from the paired <code>-OUT / <code>-IN transactions posted so far.
+INSERT INTO inventory_transfers
+    (code, product_id, qty, src_warehouse_id, dst_warehouse_id, unit_cost, value, note, status,
+     dispatched_by, dispatched_at, received_by, received_at)
+SELECT left(o.code, length(o.code) - 4), ol.product_id, -ol.qty, ol.src_warehouse_id, il.dst_warehouse_id,
+       COALESCE(il.unit_cost, 0), round(-ol.amount, 2), o.note, 'RECEIVED',
+       o.created_by, o.posted_at, i.created_by, i.posted_at
+FROM inventory_tx o
+JOIN inventory_tx_lines ol ON ol.tx_id = o.id
+JOIN inventory_tx i ON i.code = left(o.code, length(o.code) - 4) || '-IN' AND i.tx_type = 'TRANSFER'
+JOIN inventory_tx_lines il ON il.tx_id = i.id AND il.product_id = ol.product_id
+WHERE o.tx_type = 'TRANSFER'
+  AND o.code LIKE '%-OUT'
+  AND ol.qty < 0
+  AND ol.src_warehouse_id IS NOT NULL
+  AND il.dst_warehouse_id IS NOT NULL
+  AND ol.src_warehouse_id <> il.dst_warehouse_id
+ON CONFLICT (code) DO NOTHING;
+
+-- Held transfers remember whether they should post in two phases once approved.
+ALTER TABLE inventory_transfer_requests ADD COLUMN IF NOT EXISTS in_transit BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE name = 'finance.fx.manage');
+DELETE FROM permissions WHERE name = 'finance.fx.manage';
+
+DROP TABLE IF EXISTS fx_daily_rates;
+DROP TABLE IF EXISTS currencies;
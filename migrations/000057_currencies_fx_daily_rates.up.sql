@@ -0,0 +1,62 @@
+-- Currency master and daily exchange rates.
+--
+-- fx_rates already holds monthly average/closing quotes keyed by pair for
+-- consolidation, so daily rates live in fx_daily_rates.
+
+CREATE TABLE IF NOT EXISTS currencies (
+    code CHAR(3) PRIMARY KEY CHECK (code = UPPER(code)),
+    name TEXT NOT NULL,
+    decimals SMALLINT NOT NULL DEFAULT 2 CHECK (decimals BETWEEN 0 AND 4),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO currencies (code, name, decimals) VALUES
+    ('IDR', 'Indonesian Rupiah', 2),
+    ('USD', 'US Dollar', 2),
+    ('SGD', 'Singapore Dollar', 2),
+    ('EUR', 'Euro', 2),
+    ('JPY', 'Japanese Yen', 0),
+    ('CNY', 'Chinese Yuan', 2),
+    ('MYR', 'Malaysian Ringgit', 2),
+    ('AUD', 'Australian Dollar', 2)
+ON CONFLICT (code) DO NOTHING;
+
+-- Keep every currency already used on a document valid.
+INSERT INTO currencies (code, name)
+SELECT DISTINCT UPPER(TRIM(currency)), UPPER(TRIM(currency))
+FROM (
+    SELECT currency FROM prs
+    UNION SELECT currency FROM pos
+    UNION SELECT currency FROM ap_invoices
+    UNION SELECT currency FROM ar_invoices
+    UNION SELECT currency FROM quotations
+    UNION SELECT currency FROM sales_orders
+    UNION SELECT reporting_currency FROM consol_groups
+) used
+WHERE LENGTH(TRIM(currency)) = 3
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS fx_daily_rates (
+    from_currency CHAR(3) NOT NULL REFERENCES currencies(code) ON DELETE CASCADE,
+    to_currency CHAR(3) NOT NULL REFERENCES currencies(code) ON DELETE CASCADE,
+    rate_date DATE NOT NULL,
+    rate NUMERIC(20,8) NOT NULL CHECK (rate > 0),
+    source TEXT NOT NULL DEFAULT 'manual',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (from_currency, to_currency, rate_date),
+    CHECK (from_currency <> to_currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_daily_rates_date ON fx_daily_rates(rate_date);
+
+INSERT INTO permissions (name, description) VALUES
+    ('finance.fx.manage', 'Maintain currencies and exchange rates')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'Admin'
+AND p.name = 'finance.fx.manage'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,35 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE name IN ('sales.return.view', 'sales.return.create', 'sales.return.receive'));
+DELETE FROM permissions WHERE name IN ('sales.return.view', 'sales.return.create', 'sales.return.receive');
+
+DROP FUNCTION IF EXISTS generate_ar_credit_note_number();
+DROP FUNCTION IF EXISTS generate_sales_return_number();
+
+ALTER TABLE sales_returns DROP CONSTRAINT IF EXISTS fk_sales_returns_credit_note;
+DROP TABLE IF EXISTS ar_credit_notes;
+
+CREATE OR REPLACE FUNCTION update_so_line_quantity_delivered()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_total_delivered NUMERIC;
+BEGIN
+    SELECT COALESCE(SUM(dol.quantity_delivered), 0)
+    INTO v_total_delivered
+    FROM delivery_order_lines dol
+    INNER JOIN delivery_orders dord ON dord.id = dol.delivery_order_id
+    WHERE dol.sales_order_line_id = NEW.sales_order_line_id
+      AND dord.status IN ('CONFIRMED', 'IN_TRANSIT', 'DELIVERED');
+
+    UPDATE sales_order_lines
+    SET quantity_delivered = v_total_delivered,
+        updated_at = NOW()
+    WHERE id = NEW.sales_order_line_id;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE sales_order_lines DROP COLUMN IF EXISTS quantity_returned;
+
+DROP TABLE IF EXISTS sales_return_lines;
+DROP TABLE IF EXISTS sales_returns;
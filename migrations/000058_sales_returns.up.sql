@@ -0,0 +1,158 @@
+-- Sales returns (RMA): goods sent back against a sales order. Receiving
+-- restocks at the original delivery cost and lowers the SO line's delivered
+-- quantity; crediting raises an AR credit note.
+CREATE TABLE sales_returns (
+    id BIGSERIAL PRIMARY KEY,
+    number TEXT NOT NULL UNIQUE,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    customer_id BIGINT NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+    sales_order_id BIGINT NOT NULL REFERENCES sales_orders(id) ON DELETE RESTRICT,
+    delivery_order_id BIGINT REFERENCES delivery_orders(id) ON DELETE SET NULL,
+    warehouse_id BIGINT NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    currency TEXT NOT NULL DEFAULT 'IDR',
+    status VARCHAR(16) NOT NULL DEFAULT 'REQUESTED' CHECK (status IN ('REQUESTED', 'RECEIVED', 'CREDITED')),
+    reason TEXT NOT NULL,
+    requested_by BIGINT NOT NULL REFERENCES users(id),
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    received_by BIGINT REFERENCES users(id),
+    received_at TIMESTAMPTZ,
+    -- Set once every received line is back in stock; a RECEIVED return
+    -- without it retries its restock.
+    restocked_at TIMESTAMPTZ,
+    credited_by BIGINT REFERENCES users(id),
+    credited_at TIMESTAMPTZ,
+    ar_credit_note_id BIGINT
+);
+
+CREATE INDEX idx_sales_returns_order ON sales_returns(sales_order_id);
+CREATE INDEX idx_sales_returns_status ON sales_returns(company_id, status, requested_at DESC);
+
+CREATE TABLE sales_return_lines (
+    id BIGSERIAL PRIMARY KEY,
+    sales_return_id BIGINT NOT NULL REFERENCES sales_returns(id) ON DELETE CASCADE,
+    sales_order_line_id BIGINT NOT NULL REFERENCES sales_order_lines(id) ON DELETE RESTRICT,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity NUMERIC(14,4) NOT NULL CHECK (quantity > 0),
+    quantity_received NUMERIC(14,4) NOT NULL DEFAULT 0 CHECK (quantity_received >= 0),
+    unit_price NUMERIC(18,2) NOT NULL DEFAULT 0,
+    discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0,
+    tax_percent NUMERIC(5,2) NOT NULL DEFAULT 0,
+    unit_cost NUMERIC(14,4) NOT NULL DEFAULT 0,
+    CHECK (quantity_received <= quantity)
+);
+
+CREATE INDEX idx_sales_return_lines_return ON sales_return_lines(sales_return_id);
+CREATE INDEX idx_sales_return_lines_so_line ON sales_return_lines(sales_order_line_id);
+
+ALTER TABLE sales_order_lines
+    ADD COLUMN quantity_returned NUMERIC(14,4) NOT NULL DEFAULT 0 CHECK (quantity_returned >= 0);
+
+-- Delivered quantity is now net of received returns, so later delivery
+-- updates keep returns subtracted.
+CREATE OR REPLACE FUNCTION update_so_line_quantity_delivered()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_total_delivered NUMERIC;
+BEGIN
+    SELECT COALESCE(SUM(dol.quantity_delivered), 0)
+    INTO v_total_delivered
+    FROM delivery_order_lines dol
+    INNER JOIN delivery_orders dord ON dord.id = dol.delivery_order_id
+    WHERE dol.sales_order_line_id = NEW.sales_order_line_id
+      AND dord.status IN ('CONFIRMED', 'IN_TRANSIT', 'DELIVERED');
+
+    UPDATE sales_order_lines
+    SET quantity_delivered = GREATEST(v_total_delivered - quantity_returned, 0),
+        updated_at = NOW()
+    WHERE id = NEW.sales_order_line_id;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- AR credit notes. ar_invoice_id is set when the credit reduces an open
+-- invoice; otherwise the credit stays on the customer's account.
+CREATE TABLE ar_credit_notes (
+    id BIGSERIAL PRIMARY KEY,
+    number TEXT NOT NULL UNIQUE,
+    customer_id BIGINT NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+    so_id BIGINT REFERENCES sales_orders(id) ON DELETE SET NULL,
+    ar_invoice_id BIGINT REFERENCES ar_invoices(id) ON DELETE SET NULL,
+    sales_return_id BIGINT REFERENCES sales_returns(id) ON DELETE SET NULL,
+    currency TEXT NOT NULL DEFAULT 'IDR',
+    subtotal NUMERIC(15,4) NOT NULL DEFAULT 0,
+    tax_amount NUMERIC(15,4) NOT NULL DEFAULT 0,
+    total NUMERIC(15,4) NOT NULL CHECK (total > 0),
+    reason TEXT NOT NULL DEFAULT '',
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ar_credit_notes_invoice ON ar_credit_notes(ar_invoice_id);
+-- A sales return is credited by at most one credit note.
+CREATE UNIQUE INDEX idx_ar_credit_notes_sales_return ON ar_credit_notes(sales_return_id) WHERE sales_return_id IS NOT NULL;
+CREATE INDEX idx_ar_credit_notes_customer ON ar_credit_notes(customer_id);
+
+ALTER TABLE sales_returns
+    ADD CONSTRAINT fk_sales_returns_credit_note
+    FOREIGN KEY (ar_credit_note_id) REFERENCES ar_credit_notes(id) ON DELETE SET NULL;
+
+CREATE OR REPLACE FUNCTION generate_sales_return_number()
+RETURNS TEXT AS $$
+DECLARE
+    prefix TEXT := 'RMA-' || TO_CHAR(NOW(), 'YYMM') || '-';
+    seq INT;
+BEGIN
+    SELECT COALESCE(MAX(CAST(SUBSTRING(number FROM LENGTH(prefix)+1) AS INT)), 0) + 1
+    INTO seq
+    FROM sales_returns
+    WHERE number LIKE prefix || '%';
+    RETURN prefix || LPAD(seq::TEXT, 5, '0');
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION generate_ar_credit_note_number()
+RETURNS TEXT AS $$
+DECLARE
+    prefix TEXT := 'CN-' || TO_CHAR(NOW(), 'YYMM') || '-';
+    seq INT;
+BEGIN
+    SELECT COALESCE(MAX(CAST(SUBSTRING(number FROM LENGTH(prefix)+1) AS INT)), 0) + 1
+    INTO seq
+    FROM ar_credit_notes
+    WHERE number LIKE prefix || '%';
+    RETURN prefix || LPAD(seq::TEXT, 5, '0');
+END;
+$$ LANGUAGE plpgsql;
+
+INSERT INTO permissions (name, description) VALUES
+    ('sales.return.view', 'View sales returns'),
+    ('sales.return.create', 'Request sales returns'),
+    ('sales.return.receive', 'Receive returned goods into stock')
+ON CONFLICT (name) DO NOTHING;
+
+-- Whoever can create sales orders can request returns; whoever can post
+-- inventory can receive them. Crediting needs finance.ar.edit.
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id
+FROM role_permissions rp
+JOIN permissions src ON src.id = rp.permission_id AND src.name = 'sales.order.view'
+CROSS JOIN permissions p
+WHERE p.name = 'sales.return.view'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id
+FROM role_permissions rp
+JOIN permissions src ON src.id = rp.permission_id AND src.name = 'sales.order.create'
+CROSS JOIN permissions p
+WHERE p.name = 'sales.return.create'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id
+FROM role_permissions rp
+JOIN permissions src ON src.id = rp.permission_id AND src.name = 'inventory.edit'
+CROSS JOIN permissions p
+WHERE p.name IN ('sales.return.view', 'sales.return.receive')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+ALTER TABLE sales_order_lines DROP COLUMN IF EXISTS document_discount_amount;
+ALTER TABLE quotation_lines DROP COLUMN IF EXISTS document_discount_amount;
+ALTER TABLE sales_orders
+    DROP COLUMN IF EXISTS document_discount_amount,
+    DROP COLUMN IF EXISTS document_discount_percent;
+ALTER TABLE quotations
+    DROP COLUMN IF EXISTS document_discount_amount,
+    DROP COLUMN IF EXISTS document_discount_percent;
@@ -0,0 +1,24 @@
+-- Document-level discounts on quotations and sales orders, applied after line
+-- discounts. A non-zero percent takes precedence; document_discount_amount
+-- always holds the resolved amount. Each line stores its allocated share, which
+-- is also included in the line's discount_amount so the totals triggers and
+-- margins keep using quantity * unit_price - discount_amount as the net.
+ALTER TABLE quotations
+    ADD COLUMN document_discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0
+        CHECK (document_discount_percent >= 0 AND document_discount_percent <= 100),
+    ADD COLUMN document_discount_amount NUMERIC(18,2) NOT NULL DEFAULT 0
+        CHECK (document_discount_amount >= 0);
+
+ALTER TABLE sales_orders
+    ADD COLUMN document_discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0
+        CHECK (document_discount_percent >= 0 AND document_discount_percent <= 100),
+    ADD COLUMN document_discount_amount NUMERIC(18,2) NOT NULL DEFAULT 0
+        CHECK (document_discount_amount >= 0);
+
+ALTER TABLE quotation_lines
+    ADD COLUMN document_discount_amount NUMERIC(18,2) NOT NULL DEFAULT 0
+        CHECK (document_discount_amount >= 0);
+
+ALTER TABLE sales_order_lines
+    ADD COLUMN document_discount_amount NUMERIC(18,2) NOT NULL DEFAULT 0
+        CHECK (document_discount_amount >= 0);
@@ -0,0 +1 @@
+DROP VIEW IF EXISTS document_number_register;
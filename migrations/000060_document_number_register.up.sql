@@ -0,0 +1,47 @@
+-- One row per numbered document, for sequence integrity reporting.
+-- series groups the numbers that share one counter; seq is the counter value.
+-- company_id is NULL for document types numbered across all companies.
+CREATE OR REPLACE VIEW document_number_register AS
+SELECT 'QT'::TEXT AS doc_type,
+       q.id AS doc_id,
+       q.company_id,
+       regexp_replace(q.doc_number, '-[0-9]+$', '') AS series,
+       substring(q.doc_number FROM '([0-9]+)$')::BIGINT AS seq,
+       q.doc_number AS number,
+       q.status::TEXT AS status,
+       FALSE AS voided,
+       date_trunc('month', q.quote_date)::DATE AS period
+FROM quotations q
+WHERE q.doc_number ~ '-[0-9]+$'
+UNION ALL
+SELECT 'SO', so.id, so.company_id,
+       regexp_replace(so.doc_number, '-[0-9]+$', ''),
+       substring(so.doc_number FROM '([0-9]+)$')::BIGINT,
+       so.doc_number, so.status::TEXT, so.status = 'CANCELLED',
+       date_trunc('month', so.order_date)::DATE
+FROM sales_orders so
+WHERE so.doc_number ~ '-[0-9]+$'
+UNION ALL
+SELECT 'DO', d.id, d.company_id,
+       regexp_replace(d.doc_number, '-[0-9]+$', ''),
+       substring(d.doc_number FROM '([0-9]+)$')::BIGINT,
+       d.doc_number, d.status::TEXT, d.status = 'CANCELLED',
+       date_trunc('month', d.delivery_date)::DATE
+FROM delivery_orders d
+WHERE d.doc_number ~ '-[0-9]+$'
+UNION ALL
+SELECT 'INV', i.id, NULL::BIGINT,
+       regexp_replace(i.number, '-[0-9]+$', ''),
+       substring(i.number FROM '([0-9]+)$')::BIGINT,
+       i.number, i.status, i.status = 'VOID',
+       date_trunc('month', i.created_at)::DATE
+FROM ar_invoices i
+WHERE i.number ~ '^INV-[0-9]{4}-[0-9]+$'
+UNION ALL
+SELECT 'JE', je.id, NULL::BIGINT,
+       'JE', je.number, je.number::TEXT, je.status::TEXT, je.status = 'VOID',
+       date_trunc('month', je.created_at)::DATE
+FROM journal_entries je;
+
+COMMENT ON VIEW document_number_register IS
+'Numbered documents by type and series, used by the sequence integrity report';
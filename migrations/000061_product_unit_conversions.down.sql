@@ -0,0 +1,2 @@
+ALTER TABLE grn_lines DROP COLUMN IF EXISTS uom;
+DROP TABLE IF EXISTS product_unit_conversions;
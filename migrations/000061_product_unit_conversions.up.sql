@@ -0,0 +1,20 @@
+-- Alternate units a product can be bought or sold in. One unit_id equals
+-- factor of per_unit_id, which is the product's base unit or another
+-- alternate unit. base_factor is the resolved number of base units and is
+-- recomputed whenever the product is saved.
+CREATE TABLE product_unit_conversions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    unit_id BIGINT NOT NULL REFERENCES units(id) ON DELETE RESTRICT,
+    per_unit_id BIGINT NOT NULL REFERENCES units(id) ON DELETE RESTRICT,
+    factor NUMERIC(18,6) NOT NULL CHECK (factor > 0),
+    base_factor NUMERIC(18,6) NOT NULL CHECK (base_factor > 0),
+    UNIQUE (product_id, unit_id),
+    CHECK (unit_id <> per_unit_id)
+);
+
+CREATE INDEX idx_product_unit_conversions_product ON product_unit_conversions(product_id);
+
+-- Unit the GRN line quantity and cost were entered in. Blank means the
+-- product's base unit.
+ALTER TABLE grn_lines ADD COLUMN uom TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- Incremental data lake export progress per table. exported_through is the
+-- updated_at cutoff of the last run whose manifest was uploaded; the next run
+-- exports rows updated after it.
+CREATE TABLE data_export_watermarks (
+    table_name TEXT PRIMARY KEY,
+    exported_through TIMESTAMPTZ NOT NULL,
+    last_run_id TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
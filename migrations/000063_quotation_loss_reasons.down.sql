@@ -0,0 +1 @@
+ALTER TABLE quotations DROP COLUMN IF EXISTS loss_reason;
@@ -0,0 +1,3 @@
+-- Structured reason a rejected quotation was lost, one of the configured loss
+-- reason codes. rejection_reason keeps the free-text detail.
+ALTER TABLE quotations ADD COLUMN loss_reason TEXT;
@@ -0,0 +1,6 @@
+-- Warehouse prefilled on new delivery orders for the branch. The application
+-- keeps it within the branch's company.
+ALTER TABLE branches
+    ADD COLUMN IF NOT EXISTS default_warehouse_id BIGINT NULL REFERENCES warehouses(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN branches.default_warehouse_id IS 'Default warehouse for deliveries; NULL means none';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS approval_escalations;
+ALTER TABLE users DROP COLUMN IF EXISTS manager_id;
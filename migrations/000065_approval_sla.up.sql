@@ -0,0 +1,15 @@
+-- Approval SLA escalation: who a user reports to, and which pending approvals
+-- have already been escalated as overdue.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS manager_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN users.manager_id IS 'Manager notified when the user leaves approvals past SLA';
+
+CREATE TABLE IF NOT EXISTS approval_escalations (
+    module TEXT NOT NULL,
+    ref_id UUID NOT NULL,
+    submitted_at TIMESTAMPTZ NOT NULL,
+    escalated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    notified_user_ids BIGINT[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (module, ref_id, submitted_at)
+);
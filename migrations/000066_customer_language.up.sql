@@ -0,0 +1,5 @@
+-- Language for customer-facing documents. Empty uses the default language.
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN customers.language IS 'Document language code (en, id); empty means the default language';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS approval_routes;
+DROP TABLE IF EXISTS approval_matrix;
@@ -0,0 +1,25 @@
+-- Approval matrix: which roles approve a document type per amount band, and
+-- the roles snapshotted for each submission when it was routed.
+CREATE TABLE IF NOT EXISTS approval_matrix (
+    id BIGSERIAL PRIMARY KEY,
+    document_type TEXT NOT NULL,
+    min_amount NUMERIC(18,2) NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+    max_amount NUMERIC(18,2) NULL,
+    roles TEXT[] NOT NULL CHECK (cardinality(roles) > 0),
+    updated_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (max_amount IS NULL OR max_amount > min_amount)
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_matrix_type ON approval_matrix(document_type, min_amount);
+
+COMMENT ON COLUMN approval_matrix.max_amount IS 'Exclusive upper bound; NULL has no upper limit';
+
+CREATE TABLE IF NOT EXISTS approval_routes (
+    module TEXT NOT NULL,
+    ref_id UUID NOT NULL,
+    amount NUMERIC(18,2) NOT NULL,
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    routed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (module, ref_id)
+);
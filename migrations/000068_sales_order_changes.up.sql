@@ -0,0 +1,26 @@
+-- Change orders against confirmed sales orders: the requested quantity and
+-- delivery date changes, the totals before and after, and who decided them.
+CREATE TABLE IF NOT EXISTS sales_order_changes (
+    id BIGSERIAL PRIMARY KEY,
+    sales_order_id BIGINT NOT NULL REFERENCES sales_orders(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPLIED', 'REJECTED')),
+    reason TEXT NOT NULL,
+    lines JSONB NOT NULL DEFAULT '[]',
+    from_delivery_date DATE NULL,
+    to_delivery_date DATE NULL,
+    delivery_date_changed BOOLEAN NOT NULL DEFAULT FALSE,
+    from_total NUMERIC(18,2) NOT NULL,
+    to_total NUMERIC(18,2) NOT NULL,
+    requires_approval BOOLEAN NOT NULL,
+    requested_by BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ NULL,
+    decision_note TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_order_changes_order ON sales_order_changes(sales_order_id, requested_at);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sales_order_changes_pending ON sales_order_changes(sales_order_id) WHERE status = 'PENDING';
+
+COMMENT ON COLUMN sales_order_changes.lines IS 'Changed lines: [{line_id, product_id, from_quantity, to_quantity}]';
+COMMENT ON COLUMN sales_order_changes.to_total IS 'Order total after the change, as priced at request time and repriced when applied';
@@ -0,0 +1,8 @@
+ALTER TABLE ap_payments DROP COLUMN IF EXISTS withheld_amount;
+ALTER TABLE ap_payment_allocations DROP COLUMN IF EXISTS withheld_amount;
+ALTER TABLE ap_invoices
+    DROP COLUMN IF EXISTS withholding_amount,
+    DROP COLUMN IF EXISTS withholding_rate,
+    DROP COLUMN IF EXISTS withholding_tax_id;
+ALTER TABLE suppliers DROP COLUMN IF EXISTS withholding_tax_id;
+ALTER TABLE taxes DROP COLUMN IF EXISTS is_withholding;
@@ -0,0 +1,24 @@
+-- Withholding taxes (PPh) are deducted from supplier payments and remitted to
+-- the tax office instead of being added to the invoice like VAT.
+ALTER TABLE taxes ADD COLUMN is_withholding BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE taxes SET is_withholding = TRUE WHERE code LIKE 'PPH%';
+
+-- Default withholding tax for a supplier; NULL when payments to it are not
+-- subject to withholding.
+ALTER TABLE suppliers
+    ADD COLUMN withholding_tax_id BIGINT NULL REFERENCES taxes(id) ON DELETE SET NULL;
+
+-- Withholding applied to an invoice, copied from the supplier unless
+-- overridden. Rate and amount are frozen so later tax changes leave open
+-- invoices alone; the amount is the rate applied to the subtotal (DPP).
+ALTER TABLE ap_invoices
+    ADD COLUMN withholding_tax_id BIGINT NULL REFERENCES taxes(id) ON DELETE RESTRICT,
+    ADD COLUMN withholding_rate NUMERIC(5,2) NOT NULL DEFAULT 0,
+    ADD COLUMN withholding_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (withholding_amount >= 0);
+
+-- Tax withheld from each allocation. The allocation amount stays the gross
+-- amount settled on the invoice; the payment amount is the cash paid.
+ALTER TABLE ap_payment_allocations
+    ADD COLUMN withheld_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (withheld_amount >= 0);
+ALTER TABLE ap_payments
+    ADD COLUMN withheld_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (withheld_amount >= 0);
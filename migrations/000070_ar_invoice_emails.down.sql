@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ar_invoice_emails;
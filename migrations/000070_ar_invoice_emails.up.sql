@@ -0,0 +1,18 @@
+-- Log of AR invoices emailed to customers. Rows are queued by the web app and
+-- delivered by the worker, which records each attempt.
+CREATE TABLE IF NOT EXISTS ar_invoice_emails (
+    id BIGSERIAL PRIMARY KEY,
+    ar_invoice_id BIGINT NOT NULL REFERENCES ar_invoices(id) ON DELETE CASCADE,
+    recipient TEXT NOT NULL,
+    cc TEXT[] NOT NULL DEFAULT '{}',
+    bcc TEXT[] NOT NULL DEFAULT '{}',
+    include_statement BOOLEAN NOT NULL DEFAULT FALSE,
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'SENT', 'FAILED')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    requested_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ar_invoice_emails_invoice ON ar_invoice_emails(ar_invoice_id, requested_at DESC);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_inventory_tx_note_trgm;
+DROP INDEX IF EXISTS idx_inventory_tx_code_trgm;
+DROP INDEX IF EXISTS idx_inventory_tx_lines_product_tx;
+DROP INDEX IF EXISTS idx_inventory_tx_warehouse_posted_at;
+DROP INDEX IF EXISTS idx_inventory_tx_posted_at;
+DROP INDEX IF EXISTS idx_inventory_tx_ref;
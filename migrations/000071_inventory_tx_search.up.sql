@@ -0,0 +1,9 @@
+-- Indexes backing the inventory transaction search.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_inventory_tx_ref ON inventory_tx(ref_module, ref_id, posted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_tx_posted_at ON inventory_tx(posted_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_tx_warehouse_posted_at ON inventory_tx(warehouse_id, posted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_tx_lines_product_tx ON inventory_tx_lines(product_id, tx_id);
+CREATE INDEX IF NOT EXISTS idx_inventory_tx_code_trgm ON inventory_tx USING GIN (code gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_inventory_tx_note_trgm ON inventory_tx USING GIN (note gin_trgm_ops);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS consol_restatements;
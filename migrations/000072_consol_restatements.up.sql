@@ -0,0 +1,12 @@
+-- Audit notes for consolidations rebuilt after a member restated a past period.
+CREATE TABLE IF NOT EXISTS consol_restatements (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES consol_groups(id) ON DELETE CASCADE,
+    period_id BIGINT NOT NULL REFERENCES periods(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    requested_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    lock_overridden BOOLEAN NOT NULL DEFAULT FALSE,
+    restated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_consol_restatements_group_period ON consol_restatements(group_id, period_id, restated_at DESC);
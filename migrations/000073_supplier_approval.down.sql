@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_suppliers_approval_status;
+
+ALTER TABLE suppliers
+    DROP COLUMN IF EXISTS approval_changed_at,
+    DROP COLUMN IF EXISTS approval_changed_by,
+    DROP COLUMN IF EXISTS approval_note,
+    DROP COLUMN IF EXISTS approval_status;
@@ -0,0 +1,12 @@
+-- Supplier vetting: procurement may only raise purchase orders for approved
+-- suppliers. Existing suppliers are approved; new ones wait for vetting.
+ALTER TABLE suppliers
+    ADD COLUMN IF NOT EXISTS approval_status TEXT NOT NULL DEFAULT 'APPROVED'
+        CHECK (approval_status IN ('PENDING', 'APPROVED', 'BLOCKED')),
+    ADD COLUMN IF NOT EXISTS approval_note TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS approval_changed_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS approval_changed_at TIMESTAMPTZ NULL;
+
+ALTER TABLE suppliers ALTER COLUMN approval_status SET DEFAULT 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_suppliers_approval_status ON suppliers(approval_status);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_journal_lines_amount;
+DROP INDEX IF EXISTS idx_journal_entries_memo_trgm;
+DROP INDEX IF EXISTS idx_journal_entries_date_number;
+DROP INDEX IF EXISTS idx_journal_entries_source_date;
@@ -0,0 +1,9 @@
+-- Indexes backing the journal entry search. Date ranges use the existing
+-- (date, status) index and account filters the existing (account_id, je_id)
+-- index.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_source_date ON journal_entries(source_module, date DESC);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_date_number ON journal_entries(date DESC, number DESC);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_memo_trgm ON journal_entries USING GIN (memo gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_journal_lines_amount ON journal_lines((debit + credit), je_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_delivery_orders_vehicle;
+DROP INDEX IF EXISTS idx_delivery_orders_driver;
+
+ALTER TABLE delivery_orders
+    DROP COLUMN IF EXISTS vehicle_id,
+    DROP COLUMN IF EXISTS driver_id;
+
+DROP TABLE IF EXISTS delivery_vehicles;
+DROP TABLE IF EXISTS delivery_drivers;
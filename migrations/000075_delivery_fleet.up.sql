@@ -0,0 +1,32 @@
+-- Managed driver and vehicle pools for delivery orders. The free-text
+-- driver_name and vehicle_number columns stay for ad-hoc exceptions; orders
+-- picked from the pool also carry the master record for reporting.
+CREATE TABLE IF NOT EXISTS delivery_drivers (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    phone TEXT NOT NULL DEFAULT '',
+    license_number TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delivery_drivers_name ON delivery_drivers(LOWER(name));
+
+CREATE TABLE IF NOT EXISTS delivery_vehicles (
+    id BIGSERIAL PRIMARY KEY,
+    plate_number TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delivery_vehicles_plate ON delivery_vehicles(UPPER(plate_number));
+
+ALTER TABLE delivery_orders
+    ADD COLUMN IF NOT EXISTS driver_id BIGINT NULL REFERENCES delivery_drivers(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS vehicle_id BIGINT NULL REFERENCES delivery_vehicles(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_delivery_orders_driver ON delivery_orders(driver_id) WHERE driver_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_delivery_orders_vehicle ON delivery_orders(vehicle_id) WHERE vehicle_id IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS netting_allocations;
+DROP TABLE IF EXISTS netting_settlements;
+DROP TABLE IF EXISTS counterparty_links;
@@ -0,0 +1,43 @@
+-- AR/AP netting for counterparties that trade with us both ways. A customer
+-- and a supplier must be linked explicitly before their balances are netted;
+-- each record takes part in at most one link.
+CREATE TABLE IF NOT EXISTS counterparty_links (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id BIGINT NOT NULL UNIQUE REFERENCES customers(id) ON DELETE CASCADE,
+    supplier_id BIGINT NOT NULL UNIQUE REFERENCES suppliers(id) ON DELETE CASCADE,
+    created_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A settlement offsets receivables against payables in one currency. It is
+-- recorded as an AR payment and an AP payment with method NETTING so the
+-- invoice balances and histories stay consistent with the subledgers.
+CREATE TABLE IF NOT EXISTS netting_settlements (
+    id BIGSERIAL PRIMARY KEY,
+    number TEXT NOT NULL UNIQUE,
+    link_id BIGINT NOT NULL REFERENCES counterparty_links(id) ON DELETE RESTRICT,
+    customer_id BIGINT NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+    supplier_id BIGINT NOT NULL REFERENCES suppliers(id) ON DELETE RESTRICT,
+    currency TEXT NOT NULL,
+    amount NUMERIC(15,2) NOT NULL CHECK (amount > 0),
+    settled_at DATE NOT NULL,
+    ar_payment_id BIGINT NOT NULL REFERENCES ar_payments(id) ON DELETE RESTRICT,
+    ap_payment_id BIGINT NOT NULL REFERENCES ap_payments(id) ON DELETE RESTRICT,
+    note TEXT NOT NULL DEFAULT '',
+    created_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_netting_settlements_link ON netting_settlements(link_id, settled_at DESC);
+
+-- One row per invoice touched by a settlement; exactly one invoice column is set.
+CREATE TABLE IF NOT EXISTS netting_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    settlement_id BIGINT NOT NULL REFERENCES netting_settlements(id) ON DELETE CASCADE,
+    ar_invoice_id BIGINT NULL REFERENCES ar_invoices(id) ON DELETE RESTRICT,
+    ap_invoice_id BIGINT NULL REFERENCES ap_invoices(id) ON DELETE RESTRICT,
+    amount NUMERIC(15,2) NOT NULL CHECK (amount > 0),
+    CHECK ((ar_invoice_id IS NULL) <> (ap_invoice_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_netting_allocations_settlement ON netting_allocations(settlement_id);
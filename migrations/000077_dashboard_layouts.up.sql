@@ -0,0 +1,7 @@
+-- Per-user landing dashboard layout: the enabled KPI widgets in display order.
+-- Users without a row get every widget they are permitted to see.
+CREATE TABLE IF NOT EXISTS dashboard_layouts (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    widgets TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
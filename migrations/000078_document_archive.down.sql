@@ -0,0 +1,12 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE name = 'system.archive');
+DELETE FROM permissions WHERE name = 'system.archive';
+
+DROP TABLE IF EXISTS archived_documents;
+DROP TABLE IF EXISTS archive_runs;
+
+DROP INDEX IF EXISTS idx_ar_invoices_active_list;
+DROP INDEX IF EXISTS idx_sales_orders_active_list;
+
+ALTER TABLE ar_invoices DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,50 @@
+-- Document archival. Archived documents stay in their tables so every
+-- reference to them stays valid; archived_at takes them out of the list
+-- queries, which read through the partial indexes on active rows.
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+ALTER TABLE ar_invoices ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_sales_orders_active_list
+    ON sales_orders(company_id, order_date DESC, id DESC) WHERE archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_ar_invoices_active_list
+    ON ar_invoices(created_at DESC) WHERE archived_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS archive_runs (
+    id BIGSERIAL PRIMARY KEY,
+    retention_days INT NOT NULL CHECK (retention_days > 0),
+    cutoff TIMESTAMPTZ NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    archived INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+-- One row per archiving of a document. restored_at is set when the
+-- document is brought back; a restored document is not archived again by
+-- the scheduled run.
+CREATE TABLE IF NOT EXISTS archived_documents (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES archive_runs(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('SALES_ORDER', 'AR_INVOICE')),
+    document_id BIGINT NOT NULL,
+    doc_number TEXT NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL,
+    restored_at TIMESTAMPTZ,
+    restored_by BIGINT REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_archived_documents_active
+    ON archived_documents(kind, document_id) WHERE restored_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_archived_documents_run ON archived_documents(run_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('system.archive', 'View archived documents and restore them')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id
+FROM role_permissions rp
+JOIN permissions src ON src.id = rp.permission_id AND src.name = 'users.edit'
+CROSS JOIN permissions p
+WHERE p.name = 'system.archive'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_sales_order_completed_release_reservations ON sales_orders;
+DROP FUNCTION IF EXISTS release_stock_reservations_on_completion();
+DROP VIEW IF EXISTS stock_reservation_open;
+DROP TABLE IF EXISTS stock_reservations;
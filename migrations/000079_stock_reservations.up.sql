@@ -0,0 +1,68 @@
+-- Stock reservations: a confirmed sales order may hold stock in a warehouse
+-- until it is delivered. Reservations lower the available quantity without
+-- touching inventory_balances.
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    sales_order_id BIGINT NOT NULL REFERENCES sales_orders(id) ON DELETE CASCADE,
+    sales_order_line_id BIGINT NOT NULL REFERENCES sales_order_lines(id) ON DELETE CASCADE,
+    warehouse_id BIGINT NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    -- quantity and delivered_quantity are in the order line's unit;
+    -- base_factor converts them to the product's base unit.
+    quantity NUMERIC(14,4) NOT NULL CHECK (quantity > 0),
+    uom TEXT NOT NULL,
+    base_factor NUMERIC(18,6) NOT NULL DEFAULT 1 CHECK (base_factor > 0),
+    delivered_quantity NUMERIC(14,4) NOT NULL DEFAULT 0 CHECK (delivered_quantity >= 0 AND delivered_quantity <= quantity),
+    status TEXT NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'FULFILLED', 'RELEASED')),
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_stock_reservations_active_line
+    ON stock_reservations(sales_order_line_id) WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_active_stock
+    ON stock_reservations(warehouse_id, product_id) WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_order ON stock_reservations(sales_order_id);
+
+-- Open part of every active reservation in base units. committed_base is the
+-- part already on confirmed or in-transit delivery orders of the same order
+-- line, which the committed stock checks count separately.
+CREATE OR REPLACE VIEW stock_reservation_open AS
+SELECT r.id,
+       r.company_id,
+       r.sales_order_id,
+       r.sales_order_line_id,
+       r.warehouse_id,
+       r.product_id,
+       (r.quantity - r.delivered_quantity) * r.base_factor AS open_base,
+       LEAST(r.quantity - r.delivered_quantity, COALESCE((
+           SELECT SUM(dl.quantity_to_deliver)
+           FROM delivery_order_lines dl
+           JOIN delivery_orders d ON d.id = dl.delivery_order_id
+           WHERE dl.sales_order_line_id = r.sales_order_line_id
+             AND d.status IN ('CONFIRMED', 'IN_TRANSIT')
+       ), 0)) * r.base_factor AS committed_base
+FROM stock_reservations r
+WHERE r.status = 'ACTIVE';
+
+-- An order completed by the delivery triggers may still hold reservations on
+-- lines that were not delivered in full; nothing else will ship against
+-- them, so completing the order releases them.
+CREATE OR REPLACE FUNCTION release_stock_reservations_on_completion()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE stock_reservations
+    SET status = 'RELEASED', closed_at = NOW(), updated_at = NOW()
+    WHERE sales_order_id = NEW.id AND status = 'ACTIVE';
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_sales_order_completed_release_reservations
+AFTER UPDATE OF status ON sales_orders
+FOR EACH ROW
+WHEN (NEW.status = 'COMPLETED' AND OLD.status IS DISTINCT FROM NEW.status)
+EXECUTE FUNCTION release_stock_reservations_on_completion();
@@ -0,0 +1,9 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE name = 'procurement.inspect');
+DELETE FROM permissions WHERE name = 'procurement.inspect';
+
+DROP TABLE IF EXISTS grn_inspection_decisions;
+DROP TABLE IF EXISTS grn_inspections;
+
+ALTER TABLE suppliers DROP COLUMN IF EXISTS requires_inspection;
+ALTER TABLE products DROP COLUMN IF EXISTS requires_inspection;
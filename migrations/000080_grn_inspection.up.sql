@@ -0,0 +1,54 @@
+-- Quality inspection hold on goods receipts. Held lines are booked into a
+-- QC holding record instead of stock; inspectors accept them into the
+-- receiving warehouse or reject them back to the supplier.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS requires_inspection BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS requires_inspection BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- One row per held GRN line. Quantities and cost are in the product's base
+-- unit; base_factor converts back to the GRN line's unit.
+CREATE TABLE IF NOT EXISTS grn_inspections (
+    id BIGSERIAL PRIMARY KEY,
+    grn_id BIGINT NOT NULL REFERENCES grns(id) ON DELETE CASCADE,
+    grn_line_id BIGINT NOT NULL UNIQUE REFERENCES grn_lines(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id BIGINT NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    qty NUMERIC(14,4) NOT NULL CHECK (qty > 0),
+    unit_cost NUMERIC(18,6) NOT NULL CHECK (unit_cost >= 0),
+    base_factor NUMERIC(18,6) NOT NULL DEFAULT 1 CHECK (base_factor > 0),
+    accepted_qty NUMERIC(14,4) NOT NULL DEFAULT 0 CHECK (accepted_qty >= 0),
+    rejected_qty NUMERIC(14,4) NOT NULL DEFAULT 0 CHECK (rejected_qty >= 0),
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'COMPLETED')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    CHECK (accepted_qty + rejected_qty <= qty)
+);
+
+CREATE INDEX IF NOT EXISTS idx_grn_inspections_pending ON grn_inspections(created_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_grn_inspections_grn ON grn_inspections(grn_id);
+
+-- Every accept or reject step. Rejected goods go back to the supplier;
+-- return_reference records the supplier's RMA or the return document.
+CREATE TABLE IF NOT EXISTS grn_inspection_decisions (
+    id BIGSERIAL PRIMARY KEY,
+    inspection_id BIGINT NOT NULL REFERENCES grn_inspections(id) ON DELETE CASCADE,
+    decision TEXT NOT NULL CHECK (decision IN ('ACCEPT', 'REJECT')),
+    qty NUMERIC(14,4) NOT NULL CHECK (qty > 0),
+    reason TEXT NOT NULL DEFAULT '',
+    return_reference TEXT NOT NULL DEFAULT '',
+    decided_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_grn_inspection_decisions_inspection ON grn_inspection_decisions(inspection_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('procurement.inspect', 'Accept or reject goods held for quality inspection')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id
+FROM role_permissions rp
+JOIN permissions src ON src.id = rp.permission_id AND src.name = 'procurement.edit'
+CROSS JOIN permissions p
+WHERE p.name = 'procurement.inspect'
+ON CONFLICT DO NOTHING;
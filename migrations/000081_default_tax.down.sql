@@ -0,0 +1,4 @@
+ALTER TABLE suppliers DROP COLUMN IF EXISTS default_tax_id;
+ALTER TABLE customers DROP COLUMN IF EXISTS default_tax_id;
+
+DROP TABLE IF EXISTS tax_rates;
@@ -0,0 +1,26 @@
+-- Effective-dated tax rates. A document uses the latest rate effective on or
+-- before its date; taxes.rate stays the rate entered last and is the
+-- fallback for taxes without history.
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id BIGSERIAL PRIMARY KEY,
+    tax_id BIGINT NOT NULL REFERENCES taxes(id) ON DELETE CASCADE,
+    rate NUMERIC(5,2) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tax_id, effective_from)
+);
+
+INSERT INTO tax_rates (tax_id, rate, effective_from)
+SELECT id, rate, DATE '1900-01-01' FROM taxes
+ON CONFLICT (tax_id, effective_from) DO NOTHING;
+
+-- Tax-exempt parties default to NO-TAX so their lines are explicitly 0%.
+INSERT INTO taxes (code, name, rate) VALUES ('NO-TAX', 'Tax exempt', 0)
+ON CONFLICT (code) DO NOTHING;
+
+-- Default output tax of a customer and default input tax of a supplier,
+-- prefilled on document lines that do not enter a tax.
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS default_tax_id BIGINT NULL REFERENCES taxes(id) ON DELETE SET NULL;
+ALTER TABLE suppliers
+    ADD COLUMN IF NOT EXISTS default_tax_id BIGINT NULL REFERENCES taxes(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_password_history;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS must_change_password,
+    DROP COLUMN IF EXISTS password_changed_at;
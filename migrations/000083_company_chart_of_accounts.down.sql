@@ -0,0 +1,12 @@
+-- Codes are only globally unique again while no company chart reuses a
+-- shared code; otherwise the per-chart indexes stay in place.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM accounts GROUP BY code HAVING COUNT(*) > 1) THEN
+        DROP INDEX IF EXISTS uq_accounts_company_code;
+        DROP INDEX IF EXISTS uq_accounts_shared_code;
+        ALTER TABLE accounts ADD CONSTRAINT accounts_code_key UNIQUE (code);
+    ELSE
+        RAISE NOTICE 'company charts reuse account codes; keeping per-chart unique indexes';
+    END IF;
+END $$;
@@ -0,0 +1,7 @@
+-- Company charts of accounts. Accounts with a company_id form that company's
+-- chart; accounts without one stay the shared chart used by every company
+-- that has no own account with the same code. Codes are unique per chart.
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_code_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_accounts_shared_code ON accounts(code) WHERE company_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_accounts_company_code ON accounts(company_id, code) WHERE company_id IS NOT NULL;
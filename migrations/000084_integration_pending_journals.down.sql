@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pending_journals;
+DROP TABLE IF EXISTS integration_posting_settings;
@@ -0,0 +1,32 @@
+-- Integration posting settings. A company/module without a row keeps
+-- auto-posting integration journals straight to the ledger.
+CREATE TABLE IF NOT EXISTS integration_posting_settings (
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    module TEXT NOT NULL,
+    auto_post BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by BIGINT REFERENCES users(id),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (company_id, module)
+);
+
+-- Integration journals staged for review instead of being auto-posted.
+CREATE TABLE IF NOT EXISTS pending_journals (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    module TEXT NOT NULL,
+    period_id BIGINT NOT NULL REFERENCES periods(id),
+    date DATE NOT NULL,
+    source_module TEXT NOT NULL,
+    source_id UUID NOT NULL,
+    memo TEXT NOT NULL DEFAULT '',
+    lines JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+    journal_entry_id BIGINT REFERENCES journal_entries(id),
+    decided_by BIGINT REFERENCES users(id),
+    decided_at TIMESTAMPTZ,
+    decision_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (source_module, source_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_journals_company_status ON pending_journals(company_id, status, created_at);
@@ -0,0 +1,18 @@
+-- Reports generated on a cadence by the worker and emailed to recipients.
+-- day is the ISO weekday (1 = Monday) for weekly schedules and the day of
+-- the month for monthly ones.
+CREATE TABLE IF NOT EXISTS report_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}'::JSONB,
+    frequency TEXT NOT NULL CHECK (frequency IN ('DAILY', 'WEEKLY', 'MONTHLY')),
+    day INT NOT NULL DEFAULT 0,
+    recipients TEXT[] NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_run_on DATE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (frequency <> 'WEEKLY' OR day BETWEEN 1 AND 7),
+    CHECK (frequency <> 'MONTHLY' OR day BETWEEN 1 AND 28)
+);
+CREATE INDEX IF NOT EXISTS idx_report_schedules_active ON report_schedules(frequency) WHERE is_active;
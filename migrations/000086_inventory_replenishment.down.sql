@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pos_warehouse_open;
+ALTER TABLE pos DROP COLUMN IF EXISTS warehouse_id;
+DROP TABLE IF EXISTS inventory_replenishment_rules;
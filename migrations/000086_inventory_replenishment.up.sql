@@ -0,0 +1,24 @@
+-- Min/max replenishment rules per warehouse and product. A product is
+-- suggested for ordering once its projected stock (on hand + on order -
+-- committed) falls to the reorder point; the suggestion tops it up to
+-- max_qty. lead_time_days NULL uses the configured default.
+CREATE TABLE IF NOT EXISTS inventory_replenishment_rules (
+    id BIGSERIAL PRIMARY KEY,
+    warehouse_id BIGINT NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    min_qty NUMERIC(14,4) NOT NULL CHECK (min_qty >= 0),
+    max_qty NUMERIC(14,4) NOT NULL,
+    safety_stock NUMERIC(14,4) NOT NULL DEFAULT 0 CHECK (safety_stock >= 0),
+    lead_time_days INT NULL CHECK (lead_time_days >= 0),
+    supplier_id BIGINT NULL REFERENCES suppliers(id) ON DELETE SET NULL,
+    updated_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (warehouse_id, product_id),
+    CHECK (max_qty >= min_qty)
+);
+
+-- Warehouse a purchase order restocks. Set on POs created from replenishment
+-- suggestions so their open quantity counts as on order for that warehouse.
+ALTER TABLE pos ADD COLUMN IF NOT EXISTS warehouse_id BIGINT NULL REFERENCES warehouses(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_pos_warehouse_open ON pos(warehouse_id)
+    WHERE warehouse_id IS NOT NULL AND status IN ('DRAFT', 'APPROVAL', 'APPROVED');
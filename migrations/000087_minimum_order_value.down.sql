@@ -0,0 +1,9 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE name = 'sales.order.min_value_override');
+DELETE FROM permissions WHERE name = 'sales.order.min_value_override';
+
+ALTER TABLE customers
+    DROP COLUMN IF EXISTS min_order_waived,
+    DROP COLUMN IF EXISTS min_order_value;
+
+DROP TABLE IF EXISTS company_min_order_values;
@@ -0,0 +1,25 @@
+-- Per-company minimum order value. Sales orders below it cannot be confirmed
+-- without the override permission. Companies without a row have no minimum.
+CREATE TABLE company_min_order_values (
+    company_id BIGINT PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    min_order_value NUMERIC(18,2) NOT NULL DEFAULT 0 CHECK (min_order_value >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A customer may replace the company minimum with its own, or be exempt.
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS min_order_value NUMERIC(18,2) CHECK (min_order_value >= 0),
+    ADD COLUMN IF NOT EXISTS min_order_waived BOOLEAN NOT NULL DEFAULT FALSE;
+
+INSERT INTO permissions (name, description) VALUES
+    ('sales.order.min_value_override', 'Confirm sales orders below the minimum order value')
+ON CONFLICT (name) DO NOTHING;
+
+-- Roles that may approve quotations may also approve small orders.
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id
+FROM role_permissions rp
+JOIN permissions src ON src.id = rp.permission_id AND src.name = 'sales.quotation.approve'
+CROSS JOIN permissions p
+WHERE p.name = 'sales.order.min_value_override'
+ON CONFLICT DO NOTHING;
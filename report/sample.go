@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/odyssey-erp/odyssey-erp/internal/platform/httpx"
 	"github.com/odyssey-erp/odyssey-erp/internal/view"
 	"github.com/odyssey-erp/odyssey-erp/web"
 )
@@ -149,7 +150,7 @@ func (h *Handler) sample(w http.ResponseWriter, r *http.Request) {
 	pdf, err := h.client.RenderHTML(r.Context(), html)
 	if err != nil {
 		h.logger.Error("render sample pdf", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		httpx.Error(w, r, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
 		return
 	}
 	w.Header().Set("Content-Type", "application/pdf")
@@ -160,7 +161,7 @@ func (h *Handler) sample(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) stockCardPDF(w http.ResponseWriter, r *http.Request) {
 	if h.templates == nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	warehouseID, _ := strconv.ParseInt(r.URL.Query().Get("warehouse_id"), 10, 64)
@@ -181,13 +182,13 @@ func (h *Handler) stockCardPDF(w http.ResponseWriter, r *http.Request) {
 	buf := &bytes.Buffer{}
 	if err := h.templates.ExecuteTemplate(buf, "reports/stock_card_pdf.html", view.TemplateData{Data: data}); err != nil {
 		h.logger.Error("render stock card report", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	pdf, err := h.client.RenderHTML(r.Context(), buf.String())
 	if err != nil {
 		h.logger.Error("render stock card pdf", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		httpx.Error(w, r, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
 		return
 	}
 	w.Header().Set("Content-Type", "application/pdf")
@@ -197,7 +198,7 @@ func (h *Handler) stockCardPDF(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) grnPDF(w http.ResponseWriter, r *http.Request) {
 	if h.templates == nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	number := r.URL.Query().Get("number")
@@ -217,13 +218,13 @@ func (h *Handler) grnPDF(w http.ResponseWriter, r *http.Request) {
 	buf := &bytes.Buffer{}
 	if err := h.templates.ExecuteTemplate(buf, "reports/grn_pdf.html", view.TemplateData{Data: data}); err != nil {
 		h.logger.Error("render grn report", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpx.Error(w, r, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 	pdf, err := h.client.RenderHTML(r.Context(), buf.String())
 	if err != nil {
 		h.logger.Error("render grn pdf", slog.Any("error", err))
-		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		httpx.Error(w, r, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
 		return
 	}
 	w.Header().Set("Content-Type", "application/pdf")
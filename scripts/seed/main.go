@@ -121,8 +121,8 @@ func seedUsers(ctx context.Context, pool *pgxpool.Pool) error {
 	for _, u := range users {
 		hash, _ := bcrypt.GenerateFromPassword([]byte(u.password), bcrypt.DefaultCost)
 		_, err := pool.Exec(ctx, `
-			INSERT INTO users (email, password_hash, is_active, created_at, updated_at)
-			VALUES ($1, $2, TRUE, NOW(), NOW())
+			INSERT INTO users (email, password_hash, is_active, must_change_password, created_at, updated_at)
+			VALUES ($1, $2, TRUE, TRUE, NOW(), NOW())
 			ON CONFLICT (email) DO NOTHING`, u.email, string(hash))
 		if err != nil {
 			return err
@@ -157,9 +157,11 @@ func seedRBAC(ctx context.Context, pool *pgxpool.Pool) error {
 		// Inventory
 		{"inventory.view", "View inventory transactions"},
 		{"inventory.edit", "Post inventory transactions"},
+		{"inventory.approve", "Approve high-value stock transfers"},
 		// Procurement
 		{"procurement.view", "View procurement documents"},
 		{"procurement.edit", "Manage procurement documents"},
+		{"procurement.inspect", "Accept or reject goods held for quality inspection"},
 		// Finance
 		{"finance.ap.view", "View AP documents"},
 		{"finance.ap.edit", "Manage AP documents"},
@@ -173,6 +175,7 @@ func seedRBAC(ctx context.Context, pool *pgxpool.Pool) error {
 		{"sales.customer.view", "View customer data"},
 		{"sales.customer.create", "Create new customers"},
 		{"sales.customer.edit", "Edit customer information"},
+		{"sales.customer.view_sensitive", "View customer credit limits, tax IDs and notes"},
 		{"sales.quotation.view", "View sales quotations"},
 		{"sales.quotation.create", "Create new quotations"},
 		{"sales.quotation.edit", "Edit quotations"},
@@ -222,11 +225,11 @@ func seedRBAC(ctx context.Context, pool *pgxpool.Pool) error {
 			"users.view", "users.edit", "roles.view", "roles.edit", "permissions.view",
 			"org.view", "org.edit", "master.view", "master.edit", "master.import",
 			"rbac.view", "rbac.edit", "report.view",
-			"inventory.view", "inventory.edit",
-			"procurement.view", "procurement.edit",
+			"inventory.view", "inventory.edit", "inventory.approve",
+			"procurement.view", "procurement.edit", "procurement.inspect",
 			"finance.ap.view", "finance.ap.edit", "finance.boardpack", "finance.ar.view", "finance.ar.edit", "finance.gl.view",
 			"finance.view_analytics", "finance.export_analytics",
-			"sales.customer.view", "sales.customer.create", "sales.customer.edit",
+			"sales.customer.view", "sales.customer.create", "sales.customer.edit", "sales.customer.view_sensitive",
 			"sales.quotation.view", "sales.quotation.create", "sales.quotation.edit", "sales.quotation.approve",
 			"sales.order.view", "sales.order.create", "sales.order.edit", "sales.order.confirm", "sales.order.cancel",
 			"delivery.order.view", "delivery.order.create", "delivery.order.edit", "delivery.order.confirm", "delivery.order.ship", "delivery.order.complete", "delivery.order.cancel",
@@ -234,10 +237,10 @@ func seedRBAC(ctx context.Context, pool *pgxpool.Pool) error {
 		}},
 		{"manager", "Manage operations", []string{
 			"org.view", "org.edit", "master.view", "master.edit", "master.import", "report.view",
-			"inventory.view", "inventory.edit",
-			"procurement.view", "procurement.edit",
+			"inventory.view", "inventory.edit", "inventory.approve",
+			"procurement.view", "procurement.edit", "procurement.inspect",
 			"finance.ap.view", "finance.boardpack", "finance.ar.view", "finance.ar.edit",
-			"sales.customer.view", "sales.customer.create", "sales.customer.edit",
+			"sales.customer.view", "sales.customer.create", "sales.customer.edit", "sales.customer.view_sensitive",
 			"sales.quotation.view", "sales.quotation.create", "sales.quotation.edit", "sales.quotation.approve",
 			"sales.order.view", "sales.order.create", "sales.order.edit", "sales.order.confirm", "sales.order.cancel",
 		}},
@@ -402,19 +405,20 @@ func seedMasterData(ctx context.Context, pool *pgxpool.Pool) error {
 
 	// Taxes
 	taxes := []struct {
-		code string
-		name string
-		rate float64
+		code        string
+		name        string
+		rate        float64
+		withholding bool
 	}{
-		{"PPN", "PPN 11%", 11.00},
-		{"PPH23", "PPh 23 - 2%", 2.00},
-		{"NO-TAX", "Tanpa Pajak", 0.00},
+		{"PPN", "PPN 11%", 11.00, false},
+		{"PPH23", "PPh 23 - 2%", 2.00, true},
+		{"NO-TAX", "Tanpa Pajak", 0.00, false},
 	}
 	for _, t := range taxes {
 		_, err := tx.Exec(ctx, `
-			INSERT INTO taxes (code, name, rate)
-			VALUES ($1, $2, $3)
-			ON CONFLICT (code) DO NOTHING`, t.code, t.name, t.rate)
+			INSERT INTO taxes (code, name, rate, is_withholding)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (code) DO NOTHING`, t.code, t.name, t.rate, t.withholding)
 		if err != nil {
 			return err
 		}
@@ -458,8 +462,8 @@ func seedMasterData(ctx context.Context, pool *pgxpool.Pool) error {
 	}
 	for _, s := range suppliers {
 		_, err := tx.Exec(ctx, `
-			INSERT INTO suppliers (code, name, phone, email, address, is_active)
-			VALUES ($1, $2, $3, $4, $5, TRUE)
+			INSERT INTO suppliers (code, name, phone, email, address, is_active, approval_status, default_tax_id)
+			VALUES ($1, $2, $3, $4, $5, TRUE, 'APPROVED', (SELECT id FROM taxes WHERE code = 'PPN'))
 			ON CONFLICT (code) DO NOTHING`, s.code, s.name, s.phone, s.email, s.address)
 		if err != nil {
 			return err
@@ -560,7 +564,7 @@ func seedAccounting(ctx context.Context, pool *pgxpool.Pool) error {
 		_, err := tx.Exec(ctx, `
 			INSERT INTO accounts (code, name, type, is_active)
 			VALUES ($1, $2, $3::account_type, TRUE)
-			ON CONFLICT (code) DO NOTHING`, a.code, a.name, a.accType)
+			ON CONFLICT (code) WHERE company_id IS NULL DO NOTHING`, a.code, a.name, a.accType)
 		if err != nil {
 			return err
 		}
@@ -654,7 +658,7 @@ func seedConsolidation(ctx context.Context, pool *pgxpool.Pool) error {
 		INSERT INTO account_map (group_id, company_id, local_account_id, group_account_id)
 		SELECT $1, cm.company_id, a.id, cga.id
 		FROM consol_members cm
-		JOIN accounts a ON TRUE
+		JOIN accounts a ON a.company_id IS NULL OR a.company_id = cm.company_id
 		JOIN consol_group_accounts cga ON cga.group_id = $1 AND cga.type = a.type
 		WHERE cm.group_id = $1
 		  AND a.code IN ('1100', '2100', '3100', '4100', '5100')
@@ -848,8 +852,8 @@ func seedSales(ctx context.Context, pool *pgxpool.Pool) error {
 	}
 	for _, c := range customers {
 		_, err := tx.Exec(ctx, `
-			INSERT INTO customers (code, name, company_id, email, phone, country, is_active, created_by)
-			VALUES ($1, $2, $3, $4, $5, 'ID', TRUE, $6)
+			INSERT INTO customers (code, name, company_id, email, phone, country, is_active, created_by, default_tax_id)
+			VALUES ($1, $2, $3, $4, $5, 'ID', TRUE, $6, (SELECT id FROM taxes WHERE code = 'PPN'))
 			ON CONFLICT (company_id, code) DO NOTHING`, c.code, c.name, companyID, c.email, c.phone, adminID)
 		if err != nil {
 			return err
@@ -999,11 +1003,11 @@ func seedJournals(ctx context.Context, pool *pgxpool.Pool) error {
 	pool.QueryRow(ctx, "SELECT id FROM periods WHERE code = $1", periodCode).Scan(&periodID)
 
 	// Get Accounts
-	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '1110'").Scan(&accBank) // Kas
-	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '4100'").Scan(&accRev) // Sales
-	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '5200'").Scan(&accExp) // Op Exp
-	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '1210'").Scan(&accRec) // AR
-	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '2110'").Scan(&accPay) // AP
+	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '1110' AND company_id IS NULL").Scan(&accBank) // Kas
+	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '4100' AND company_id IS NULL").Scan(&accRev) // Sales
+	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '5200' AND company_id IS NULL").Scan(&accExp) // Op Exp
+	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '1210' AND company_id IS NULL").Scan(&accRec) // AR
+	pool.QueryRow(ctx, "SELECT id FROM accounts WHERE code = '2110' AND company_id IS NULL").Scan(&accPay) // AP
 
 	if periodID == 0 || accBank == 0 {
 		// Data missing, skip
@@ -67,7 +67,7 @@ func seedChartOfAccounts(ctx context.Context, pool *pgxpool.Pool) error {
 			if id, ok := codeToID[parentCode]; ok {
 				parent = id
 			} else {
-				if err := tx.QueryRow(ctx, `SELECT id FROM accounts WHERE code=$1`, parentCode).Scan(&parent); err != nil {
+				if err := tx.QueryRow(ctx, `SELECT id FROM accounts WHERE code=$1 AND company_id IS NULL`, parentCode).Scan(&parent); err != nil {
 					return fmt.Errorf("lookup parent %s: %w", parentCode, err)
 				}
 			}
@@ -75,7 +75,7 @@ func seedChartOfAccounts(ctx context.Context, pool *pgxpool.Pool) error {
 		var id int64
 		err := tx.QueryRow(ctx, `INSERT INTO accounts (code, name, type, parent_id, is_active, created_at, updated_at)
 VALUES ($1,$2,$3,$4,TRUE,NOW(),NOW())
-ON CONFLICT (code) DO UPDATE SET name=EXCLUDED.name, type=EXCLUDED.type, parent_id=EXCLUDED.parent_id, is_active=EXCLUDED.is_active, updated_at=NOW()
+ON CONFLICT (code) WHERE company_id IS NULL DO UPDATE SET name=EXCLUDED.name, type=EXCLUDED.type, parent_id=EXCLUDED.parent_id, is_active=EXCLUDED.is_active, updated_at=NOW()
 RETURNING id`, code, name, accType, parent).Scan(&id)
 		if err != nil {
 			return fmt.Errorf("upsert account %s: %w", code, err)
@@ -89,15 +89,21 @@ func seedMappings(ctx context.Context, pool *pgxpool.Pool) error {
 	mappings := map[string]string{
 		"grn.inventory":                  "1300",
 		"grn.grir":                       "5500",
+		"grn.inspection":                 "1310",
+		"grn.ppv":                        "5110",
 		"ap.invoice.ap":                  "2100",
 		"ap.invoice.inventory":           "1300",
 		"ap.invoice.expense":             "5200",
 		"ap.invoice.tax_input":           "5400",
 		"ap.payment.cash":                "1110",
 		"ap.payment.ap":                  "2100",
+		"ap.payment.withholding":         "2200",
+		"netting.ap":                     "2100",
+		"netting.ar":                     "1200",
 		"inventory.adjustment.gain":      "5300",
 		"inventory.adjustment.loss":      "5300",
 		"inventory.adjustment.inventory": "1300",
+		"close.retained_earnings":        "3100",
 	}
 	tx, err := pool.Begin(ctx)
 	if err != nil {
@@ -110,7 +116,7 @@ func seedMappings(ctx context.Context, pool *pgxpool.Pool) error {
 		module := strings.ToUpper(parts[0])
 		mappingKey := key
 		var accountID int64
-		if err := tx.QueryRow(ctx, `SELECT id FROM accounts WHERE code=$1`, code).Scan(&accountID); err != nil {
+		if err := tx.QueryRow(ctx, `SELECT id FROM accounts WHERE code=$1 AND company_id IS NULL`, code).Scan(&accountID); err != nil {
 			return fmt.Errorf("lookup account %s: %w", code, err)
 		}
 		if _, err := tx.Exec(ctx, `INSERT INTO account_mappings (module, key, account_id, created_at, updated_at)
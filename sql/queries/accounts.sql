@@ -2,3 +2,54 @@
 SELECT id, code, name, type, parent_id, is_active, created_at, updated_at
 FROM accounts
 ORDER BY code;
+
+-- name: CompareTrialBalanceByCompany :many
+WITH target_period AS (
+    SELECT p.id, p.start_date, p.end_date FROM periods p WHERE p.id = sqlc.arg(period_id)
+)
+SELECT acc.code, acc.name, acc.type,
+       jl.dim_company_id::BIGINT AS company_id,
+       COALESCE(SUM(CASE WHEN je.date < tp.start_date THEN (jl.debit - jl.credit) ELSE 0 END),0)::float8 AS opening,
+       COALESCE(SUM(CASE WHEN je.date BETWEEN tp.start_date AND tp.end_date THEN jl.debit ELSE 0 END),0)::float8 AS debit,
+       COALESCE(SUM(CASE WHEN je.date BETWEEN tp.start_date AND tp.end_date THEN jl.credit ELSE 0 END),0)::float8 AS credit
+FROM journal_lines jl
+JOIN accounts posted ON posted.id = jl.account_id
+LEFT JOIN accounts own ON posted.company_id IS NULL AND own.company_id = jl.dim_company_id AND own.code = posted.code
+JOIN accounts acc ON acc.id = COALESCE(own.id, posted.id)
+JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
+JOIN target_period tp ON TRUE
+WHERE jl.dim_company_id = ANY(sqlc.arg(company_ids)::BIGINT[])
+  AND je.date <= tp.end_date
+  AND (sqlc.narg(branch_id)::bigint IS NULL OR jl.dim_branch_id = sqlc.narg(branch_id)::bigint)
+  AND (sqlc.narg(warehouse_id)::bigint IS NULL OR jl.dim_warehouse_id = sqlc.narg(warehouse_id)::bigint)
+GROUP BY acc.code, acc.name, acc.type, jl.dim_company_id
+ORDER BY acc.code, jl.dim_company_id;
+
+
+-- name: ProfitLossByDimension :many
+-- Period revenue and expense per account, split by the branch or warehouse on
+-- each journal line. Lines without that dimension come back with a NULL
+-- dimension_id (the unallocated bucket).
+WITH target_period AS (
+    SELECT p.start_date, p.end_date FROM periods p WHERE p.id = sqlc.arg(period_id)
+)
+SELECT acc.code, acc.name, acc.type,
+       (CASE WHEN sqlc.arg(dimension)::text = 'warehouse' THEN jl.dim_warehouse_id ELSE jl.dim_branch_id END)::BIGINT AS dimension_id,
+       COALESCE(CASE WHEN sqlc.arg(dimension)::text = 'warehouse' THEN w.name ELSE b.name END, '')::TEXT AS dimension_name,
+       COALESCE(SUM(jl.debit),0)::float8 AS debit,
+       COALESCE(SUM(jl.credit),0)::float8 AS credit
+FROM journal_lines jl
+JOIN accounts posted ON posted.id = jl.account_id
+LEFT JOIN accounts own ON posted.company_id IS NULL AND own.company_id = jl.dim_company_id AND own.code = posted.code
+JOIN accounts acc ON acc.id = COALESCE(own.id, posted.id) AND acc.type IN ('REVENUE', 'EXPENSE')
+JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
+JOIN target_period tp ON je.date BETWEEN tp.start_date AND tp.end_date
+LEFT JOIN branches b ON b.id = jl.dim_branch_id
+LEFT JOIN warehouses w ON w.id = jl.dim_warehouse_id
+WHERE (sqlc.narg(company_id)::bigint IS NULL OR jl.dim_company_id = sqlc.narg(company_id)::bigint)
+  AND (sqlc.narg(branch_id)::bigint IS NULL OR jl.dim_branch_id = sqlc.narg(branch_id)::bigint)
+  AND (NOT sqlc.arg(branch_unallocated)::bool OR jl.dim_branch_id IS NULL)
+  AND (sqlc.narg(warehouse_id)::bigint IS NULL OR jl.dim_warehouse_id = sqlc.narg(warehouse_id)::bigint)
+  AND (NOT sqlc.arg(warehouse_unallocated)::bool OR jl.dim_warehouse_id IS NULL)
+GROUP BY acc.code, acc.name, acc.type, 4, 5
+ORDER BY acc.code, 4 NULLS LAST;
@@ -2,11 +2,13 @@
 INSERT INTO ap_invoices (
     number, supplier_id, grn_id, po_id, currency, 
     subtotal, tax_amount, total, status, 
-    due_at, created_by, created_at, updated_at
+    issued_at, due_at, payment_terms_days, created_by,
+    withholding_tax_id, withholding_rate, withholding_amount, company_id, created_at, updated_at
 ) VALUES (
     $1, $2, $3, $4, $5, 
     $6, $7, $8, $9, 
-    $10, $11, NOW(), NOW()
+    $10, $11, $12, $13,
+    $14, $15, $16, $17, NOW(), NOW()
 ) RETURNING id;
 
 -- name: UpdateAPStatus :exec
@@ -27,11 +29,16 @@ WHERE id = $1 AND status IN ('DRAFT', 'POSTED');
 -- name: GetAPInvoice :one
 SELECT 
     i.id, i.number, i.supplier_id, s.name AS supplier_name, i.grn_id, i.po_id, i.currency, 
-    subtotal, tax_amount, total, status, due_at, 
+    subtotal, tax_amount, total, status, issued_at, due_at, i.payment_terms_days, 
     posted_at, posted_by, voided_at, voided_by, void_reason,
-    created_by, created_at, updated_at 
+    created_by, created_at, updated_at,
+    i.withholding_tax_id, COALESCE(wt.code, '') AS withholding_code, i.withholding_rate, i.withholding_amount,
+    COALESCE((
+        SELECT SUM(pa.withheld_amount) FROM ap_payment_allocations pa WHERE pa.ap_invoice_id = i.id
+    ), 0)::NUMERIC AS withheld_amount
 FROM ap_invoices i
 JOIN suppliers s ON s.id = i.supplier_id
+LEFT JOIN taxes wt ON wt.id = i.withholding_tax_id
 WHERE i.id = $1;
 
 -- name: GetAPInvoiceByNumber :one
@@ -97,15 +104,15 @@ ORDER BY i.created_at DESC;
 -- name: CreateAPPayment :one
 INSERT INTO ap_payments (
     number, ap_invoice_id, supplier_id, amount, paid_at, method, note, 
-    created_by, created_at, updated_at
+    created_by, payment_run_id, withheld_amount, created_at, updated_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW()
 ) RETURNING id;
 
 -- name: CreateAPPaymentAllocation :one
 INSERT INTO ap_payment_allocations (
-    ap_payment_id, ap_invoice_id, amount, created_at
-) VALUES ($1, $2, $3, NOW())
+    ap_payment_id, ap_invoice_id, amount, withheld_amount, created_at
+) VALUES ($1, $2, $3, $4, NOW())
 RETURNING id;
 
 -- name: ListAPPayments :many
@@ -168,12 +175,43 @@ SELECT 'PAY-' || TO_CHAR(NOW(), 'YYYYMMDD') || '-' || LPAD(NEXTVAL('ap_payments_
 -- name: GetAPInvoiceBalancesBatch :many
 SELECT 
     i.id,
+    i.supplier_id,
+    s.name AS supplier_name,
     i.due_at,
     i.total,
     COALESCE(SUM(pa.amount), 0)::NUMERIC AS paid_amount,
     (i.total - COALESCE(SUM(pa.amount), 0))::NUMERIC AS balance
 FROM ap_invoices i
+JOIN suppliers s ON s.id = i.supplier_id
 LEFT JOIN ap_payment_allocations pa ON pa.ap_invoice_id = i.id
 WHERE i.status = 'POSTED'
-GROUP BY i.id, i.due_at, i.total
+GROUP BY i.id, i.supplier_id, s.name, i.due_at, i.total
 HAVING (i.total - COALESCE(SUM(pa.amount), 0)) > 0;
+
+-- name: CreateAPPaymentRun :one
+INSERT INTO ap_payment_runs (
+    number, pay_date, method, note, due_before, supplier_id,
+    cash_limit, group_by_supplier, total, created_by, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW()
+) RETURNING id;
+
+-- name: GenerateAPPaymentRunNumber :one
+SELECT 'PR-' || TO_CHAR(NOW(), 'YYYYMMDD') || '-' || LPAD(NEXTVAL('ap_payment_runs_id_seq')::TEXT, 4, '0');
+
+-- name: LockPayableAPInvoices :many
+SELECT
+    i.id, i.number, i.supplier_id, s.name AS supplier_name, i.currency, i.due_at,
+    (i.total - COALESCE((
+        SELECT SUM(pa.amount) FROM ap_payment_allocations pa WHERE pa.ap_invoice_id = i.id
+    ), 0))::NUMERIC AS balance,
+    GREATEST(i.withholding_amount - COALESCE((
+        SELECT SUM(pa.withheld_amount) FROM ap_payment_allocations pa WHERE pa.ap_invoice_id = i.id
+    ), 0), 0)::NUMERIC AS withholding_due
+FROM ap_invoices i
+JOIN suppliers s ON s.id = i.supplier_id
+WHERE i.id = ANY(@invoice_ids::BIGINT[])
+  AND i.status = 'POSTED'
+  AND (@unrestricted::BOOLEAN OR i.company_id IS NULL OR i.company_id = ANY(@company_ids::BIGINT[]))
+ORDER BY i.id
+FOR UPDATE OF i;
@@ -1,5 +1,6 @@
 -- name: AuditTimelineWindow :many
-SELECT a.occurred_at AS at,
+SELECT a.id,
+       a.occurred_at AS at,
        COALESCE(u.email, a.actor_id::text) AS actor,
        a.action,
        a.entity,
@@ -19,11 +20,13 @@ WHERE a.occurred_at BETWEEN sqlc.arg(from_at) AND sqlc.arg(to_at)
   AND (sqlc.narg(actor)::text IS NULL OR a.actor_id::text = sqlc.narg(actor)::text)
   AND (sqlc.narg(entity)::text IS NULL OR a.entity = sqlc.narg(entity)::text)
   AND (sqlc.narg(action)::text IS NULL OR a.action = sqlc.narg(action)::text)
+  AND (sqlc.narg(entities)::text[] IS NULL OR a.entity = ANY(sqlc.narg(entities)::text[]))
 ORDER BY a.occurred_at DESC
 LIMIT sqlc.arg(limit_rows) OFFSET sqlc.arg(offset_rows);
 
 -- name: AuditTimelineAll :many
-SELECT a.occurred_at AS at,
+SELECT a.id,
+       a.occurred_at AS at,
        COALESCE(u.email, a.actor_id::text) AS actor,
        a.action,
        a.entity,
@@ -43,4 +46,19 @@ WHERE a.occurred_at BETWEEN sqlc.arg(from_at) AND sqlc.arg(to_at)
   AND (sqlc.narg(actor)::text IS NULL OR a.actor_id::text = sqlc.narg(actor)::text)
   AND (sqlc.narg(entity)::text IS NULL OR a.entity = sqlc.narg(entity)::text)
   AND (sqlc.narg(action)::text IS NULL OR a.action = sqlc.narg(action)::text)
+  AND (sqlc.narg(entities)::text[] IS NULL OR a.entity = ANY(sqlc.narg(entities)::text[]))
 ORDER BY a.occurred_at DESC;
+
+-- name: AuditLogGet :one
+SELECT a.id,
+       a.occurred_at AS at,
+       COALESCE(u.email, a.actor_id::text) AS actor,
+       a.action,
+       a.entity,
+       a.entity_id::text AS entity_id,
+       a.meta,
+       a.before,
+       a.after
+FROM audit_logs a
+LEFT JOIN users u ON u.id = a.actor_id
+WHERE a.id = sqlc.arg(id);
@@ -157,3 +157,35 @@ SET status = $2,
     completed_at = CASE WHEN $2 = 'COMPLETED' THEN NOW() ELSE completed_at END,
     updated_at = NOW()
 WHERE id = $1;
+
+-- name: InsertPeriodSnapshot :one
+INSERT INTO period_close_snapshots (period_id, company_id, run_id, net_income, total_assets, total_liabilities, total_equity, captured_by)
+VALUES (sqlc.arg(period_id), sqlc.arg(company_id), sqlc.arg(run_id), sqlc.arg(net_income)::float8, sqlc.arg(total_assets)::float8,
+        sqlc.arg(total_liabilities)::float8, sqlc.arg(total_equity)::float8, sqlc.arg(captured_by))
+ON CONFLICT (period_id) DO NOTHING
+RETURNING id;
+
+-- name: InsertPeriodSnapshotLine :exec
+INSERT INTO period_close_snapshot_lines (snapshot_id, account_code, account_name, account_type, opening, debit, credit, closing)
+VALUES (sqlc.arg(snapshot_id), sqlc.arg(account_code), sqlc.arg(account_name), sqlc.arg(account_type),
+        sqlc.arg(opening)::float8, sqlc.arg(debit)::float8, sqlc.arg(credit)::float8, sqlc.arg(closing)::float8);
+
+-- name: LoadPeriodSnapshot :one
+SELECT id, period_id, company_id, run_id,
+       net_income::float8 AS net_income,
+       total_assets::float8 AS total_assets,
+       total_liabilities::float8 AS total_liabilities,
+       total_equity::float8 AS total_equity,
+       captured_by, captured_at
+FROM period_close_snapshots
+WHERE period_id = $1;
+
+-- name: ListPeriodSnapshotLines :many
+SELECT account_code, account_name, account_type,
+       opening::float8 AS opening,
+       debit::float8 AS debit,
+       credit::float8 AS credit,
+       closing::float8 AS closing
+FROM period_close_snapshot_lines
+WHERE snapshot_id = $1
+ORDER BY account_code;
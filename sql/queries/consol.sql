@@ -51,6 +51,34 @@ FROM (
 ) AS base
 GROUP BY base.group_account_id;
 
+-- name: ConsolGroupSourceWatermark :one
+-- Latest change to anything the group's balances are built from: journals
+-- booked by members in the period, membership and account mappings,
+-- including members and mappings since deleted.
+SELECT GREATEST(
+    COALESCE((
+        SELECT MAX(je.updated_at)
+        FROM journal_entries je
+        JOIN journal_lines jl ON jl.je_id = je.id
+        JOIN consol_members cm ON cm.company_id = jl.dim_company_id AND cm.group_id = $2
+        WHERE je.period_id = $1
+    ), 'epoch'::timestamptz),
+    COALESCE((SELECT MAX(updated_at) FROM consol_members WHERE group_id = $2), 'epoch'::timestamptz),
+    COALESCE((SELECT MAX(updated_at) FROM account_map WHERE group_id = $2), 'epoch'::timestamptz),
+    COALESCE((SELECT changed_at FROM consol_group_changes WHERE group_id = $2), 'epoch'::timestamptz)
+)::timestamptz AS source_watermark;
+
+-- name: GetConsolRefreshWatermark :one
+SELECT source_watermark, refreshed_at
+FROM consol_refresh_watermarks
+WHERE group_id = $1 AND period_id = $2;
+
+-- name: UpsertConsolRefreshWatermark :exec
+INSERT INTO consol_refresh_watermarks (group_id, period_id, source_watermark, refreshed_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (group_id, period_id)
+DO UPDATE SET source_watermark = EXCLUDED.source_watermark, refreshed_at = EXCLUDED.refreshed_at;
+
 -- name: ListGroupIDs :many
 SELECT id FROM consol_groups ORDER BY id;
 
@@ -1,4 +1,10 @@
 -- name: ListUsers :many
-SELECT id, email, name, is_active, created_at, updated_at 
+SELECT id, email, name, is_active, manager_id, created_at, updated_at 
 FROM users 
 ORDER BY id;
+
+-- name: UserExists :one
+SELECT EXISTS (SELECT 1 FROM users WHERE id = $1);
+
+-- name: SetUserManager :exec
+UPDATE users SET manager_id = sqlc.narg(manager_id), updated_at = NOW() WHERE id = $1;
@@ -73,7 +73,7 @@ WHERE je.period_id = ap.period_id
   AND COALESCE(jl.dim_company_id, 0) = $3;
 
 -- name: LookupAccountID :one
-SELECT id FROM accounts WHERE code = $1;
+SELECT id FROM accounts WHERE code = $1 ORDER BY company_id NULLS FIRST, id LIMIT 1;
 
 -- name: ElimLoadAccountingPeriod :one
 SELECT ap.id, ap.period_id, ap.name, ap.start_date, ap.end_date
@@ -85,3 +85,41 @@ SELECT ap.id, ap.period_id, ap.name, ap.start_date, ap.end_date
 FROM accounting_periods ap
 ORDER BY ap.start_date DESC
 LIMIT $1;
+
+-- name: ElimListGroupRules :many
+SELECT id, group_id, name, source_company_id, target_company_id, account_src, account_tgt, match_criteria,
+       is_active, created_by, created_at, updated_at
+FROM elimination_rules
+WHERE group_id = $1 OR group_id IS NULL
+ORDER BY id;
+
+-- name: ElimInsertRuleTemplate :one
+INSERT INTO elimination_rule_templates (group_id, name, account_src, account_tgt, match_criteria, created_by)
+VALUES ($1,$2,$3,$4,$5,$6)
+RETURNING id, group_id, name, account_src, account_tgt, match_criteria, is_active, created_by, created_at, updated_at;
+
+-- name: ElimGetRuleTemplate :one
+SELECT id, group_id, name, account_src, account_tgt, match_criteria, is_active, created_by, created_at, updated_at
+FROM elimination_rule_templates WHERE id = $1;
+
+-- name: ElimListRuleTemplates :many
+SELECT id, group_id, name, account_src, account_tgt, match_criteria, is_active, created_by, created_at, updated_at
+FROM elimination_rule_templates
+ORDER BY group_id, name
+LIMIT $1;
+
+-- name: ElimListActiveRuleTemplates :many
+SELECT id, group_id, name, account_src, account_tgt, match_criteria, is_active, created_by, created_at, updated_at
+FROM elimination_rule_templates
+WHERE is_active
+ORDER BY group_id, id;
+
+-- name: ElimLockGroup :one
+SELECT id FROM consol_groups WHERE id = $1 FOR UPDATE;
+
+-- name: ElimListEnabledMembers :many
+SELECT cm.company_id, c.code
+FROM consol_members cm
+JOIN companies c ON c.id = cm.company_id
+WHERE cm.group_id = $1 AND cm.enabled
+ORDER BY cm.company_id;
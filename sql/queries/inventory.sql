@@ -50,3 +50,160 @@ INSERT INTO inventory_cards (
     $6, $7, $8, $9, $10, 
     $11, $12
 );
+
+-- name: LockBalancesForUpdate :many
+SELECT warehouse_id, product_id, qty, avg_cost, updated_at
+FROM inventory_balances
+WHERE warehouse_id = @warehouse_id AND product_id = ANY(@product_ids::bigint[])
+ORDER BY product_id
+FOR UPDATE;
+
+-- name: InsertTransactionLines :exec
+INSERT INTO inventory_tx_lines (
+    tx_id, product_id, qty, unit_cost, src_warehouse_id, dst_warehouse_id
+)
+SELECT @tx_id::bigint, l.product_id, l.qty, l.unit_cost,
+       NULLIF(l.src_warehouse_id, 0), NULLIF(l.dst_warehouse_id, 0)
+FROM unnest(
+    @product_ids::bigint[], @qtys::float8[], @unit_costs::float8[],
+    @src_warehouse_ids::bigint[], @dst_warehouse_ids::bigint[]
+) AS l(product_id, qty, unit_cost, src_warehouse_id, dst_warehouse_id);
+
+-- name: ApplyInboundBalances :many
+WITH incoming AS (
+    SELECT l.product_id, SUM(l.qty) AS qty, SUM(l.qty * l.unit_cost) AS cost
+    FROM unnest(@product_ids::bigint[], @qtys::float8[], @unit_costs::float8[]) AS l(product_id, qty, unit_cost)
+    GROUP BY l.product_id
+)
+INSERT INTO inventory_balances (
+    warehouse_id, product_id, qty, avg_cost, updated_at
+)
+SELECT @warehouse_id::bigint, i.product_id, i.qty, COALESCE(i.cost / NULLIF(i.qty, 0), 0), NOW()
+FROM incoming i
+ON CONFLICT (warehouse_id, product_id)
+DO UPDATE SET
+    qty = inventory_balances.qty + EXCLUDED.qty,
+    avg_cost = CASE
+        WHEN inventory_balances.qty + EXCLUDED.qty <> 0
+        THEN (inventory_balances.qty * inventory_balances.avg_cost + EXCLUDED.qty * EXCLUDED.avg_cost)
+             / (inventory_balances.qty + EXCLUDED.qty)
+        ELSE 0
+    END,
+    updated_at = NOW()
+RETURNING warehouse_id, product_id, qty, avg_cost, updated_at;
+
+-- name: InsertCardEntries :exec
+INSERT INTO inventory_cards (
+    warehouse_id, product_id, tx_id, tx_code, tx_type,
+    qty_in, qty_out, balance_qty, unit_cost, balance_cost,
+    posted_at, note
+)
+SELECT @warehouse_id::bigint, c.product_id, @tx_id::bigint, @tx_code::text, @tx_type::text,
+       c.qty_in, c.qty_out, c.balance_qty, c.unit_cost, c.balance_cost,
+       @posted_at::timestamptz, @note::text
+FROM unnest(
+    @product_ids::bigint[], @qty_ins::float8[], @qty_outs::float8[],
+    @balance_qtys::float8[], @unit_costs::float8[], @balance_costs::float8[]
+) WITH ORDINALITY AS c(product_id, qty_in, qty_out, balance_qty, unit_cost, balance_cost, ord)
+ORDER BY c.ord;
+
+-- name: InventoryValuationAsOf :many
+-- Latest stock card per warehouse/product at or before as_of; cards carry the
+-- running quantity and moving-average cost so no full replay is needed.
+WITH last_card AS (
+    SELECT DISTINCT ON (c.warehouse_id, c.product_id)
+           c.warehouse_id, c.product_id, c.balance_qty, c.balance_cost
+    FROM inventory_cards c
+    WHERE c.posted_at <= sqlc.arg(as_of)::timestamptz
+      AND (sqlc.narg(warehouse_id)::bigint IS NULL OR c.warehouse_id = sqlc.narg(warehouse_id)::bigint)
+    ORDER BY c.warehouse_id, c.product_id, c.posted_at DESC, c.id DESC
+)
+SELECT lc.warehouse_id, w.code AS warehouse_code, w.name AS warehouse_name,
+       lc.product_id, p.sku, p.name AS product_name, p.category_id,
+       lc.balance_qty::float8 AS qty,
+       lc.balance_cost::float8 AS avg_cost,
+       (lc.balance_qty * lc.balance_cost)::float8 AS value
+FROM last_card lc
+JOIN warehouses w ON w.id = lc.warehouse_id
+JOIN products p ON p.id = lc.product_id
+WHERE lc.balance_qty <> 0
+  AND (sqlc.narg(category_id)::bigint IS NULL OR p.category_id = sqlc.narg(category_id)::bigint)
+  AND (sqlc.narg(company_id)::bigint IS NULL
+       OR w.branch_id IN (SELECT b.id FROM branches b WHERE b.company_id = sqlc.narg(company_id)::bigint))
+ORDER BY w.code, p.sku;
+
+-- name: InventoryGLBalanceAsOf :one
+-- Balance of the mapped inventory asset account, for tie-out with the valuation.
+-- The account is matched by code so company charts count too; a line belongs
+-- to its company dimension, falling back to the company owning its account.
+SELECT COALESCE(SUM(jl.debit - jl.credit), 0)::float8 AS balance
+FROM journal_lines jl
+JOIN journal_entries je ON je.id = jl.je_id AND je.status = 'POSTED'
+JOIN accounts a ON a.id = jl.account_id
+JOIN account_mappings am ON am.module = 'GRN' AND am.key = 'grn.inventory'
+JOIN accounts ma ON ma.id = am.account_id AND ma.code = a.code
+WHERE je.date <= sqlc.arg(as_of)::date
+  AND (sqlc.narg(warehouse_id)::bigint IS NULL OR jl.dim_warehouse_id = sqlc.narg(warehouse_id)::bigint)
+  AND (sqlc.narg(company_id)::bigint IS NULL OR COALESCE(jl.dim_company_id, a.company_id) = sqlc.narg(company_id)::bigint);
+
+-- name: InventoryConsumptionValue :many
+-- Issued quantity and value per product over a period. Deliveries post as
+-- negative adjustments; transfers only move stock and are not consumption.
+SELECT p.id AS product_id, p.sku, p.name AS product_name, p.category_id,
+       SUM(c.qty_out)::float8 AS qty,
+       SUM(c.qty_out * c.unit_cost)::float8 AS value
+FROM inventory_cards c
+JOIN products p ON p.id = c.product_id
+WHERE c.tx_type IN ('OUT', 'ADJUST')
+  AND c.qty_out > 0
+  AND c.posted_at >= sqlc.arg(from_ts)::timestamptz
+  AND c.posted_at <= sqlc.arg(to_ts)::timestamptz
+  AND (sqlc.narg(warehouse_id)::bigint IS NULL OR c.warehouse_id = sqlc.narg(warehouse_id)::bigint)
+  AND c.warehouse_id IN (
+      SELECT w.id FROM warehouses w JOIN branches b ON b.id = w.branch_id
+      WHERE b.company_id = sqlc.arg(company_id)::bigint)
+GROUP BY p.id, p.sku, p.name, p.category_id
+HAVING SUM(c.qty_out) > 0
+ORDER BY value DESC, p.sku;
+
+-- name: ClearABCClasses :exec
+DELETE FROM inventory_abc_classes WHERE company_id = $1 AND warehouse_id = $2;
+
+-- name: InsertABCClasses :exec
+INSERT INTO inventory_abc_classes (
+    company_id, warehouse_id, product_id, abc_class, consumption_value, cumulative_share, rank, period_start, period_end
+)
+SELECT @company_id::bigint, @warehouse_id::bigint, c.product_id, c.abc_class, c.consumption_value, c.cumulative_share, c.rank,
+       @period_start::date, @period_end::date
+FROM unnest(
+    @product_ids::bigint[], @classes::text[], @consumption_values::float8[],
+    @cumulative_shares::float8[], @ranks::int[]
+) AS c(product_id, abc_class, consumption_value, cumulative_share, rank);
+
+-- name: ListABCClasses :many
+SELECT product_id, abc_class::text AS abc_class, consumption_value::float8 AS consumption_value, rank,
+       period_start, period_end, classified_at
+FROM inventory_abc_classes
+WHERE company_id = $1 AND warehouse_id = $2
+ORDER BY rank;
+
+-- name: ListCostCards :many
+-- Cards replayed by the cost recalculation: the last card before from_date
+-- as the opening balance, then every card from from_date on.
+SELECT id, tx_code, tx_type, posted_at, qty_in, qty_out, balance_qty, unit_cost, balance_cost, note
+FROM inventory_cards
+WHERE warehouse_id = sqlc.arg(warehouse_id)
+  AND product_id = sqlc.arg(product_id)
+  AND (posted_at >= sqlc.arg(from_date)
+       OR id = (SELECT o.id FROM inventory_cards o
+                WHERE o.warehouse_id = sqlc.arg(warehouse_id)
+                  AND o.product_id = sqlc.arg(product_id)
+                  AND o.posted_at < sqlc.arg(from_date)
+                ORDER BY o.posted_at DESC, o.id DESC
+                LIMIT 1))
+ORDER BY posted_at ASC, id ASC;
+
+-- name: UpdateCardCost :exec
+UPDATE inventory_cards
+SET unit_cost = $2, balance_cost = $3
+WHERE id = $1;
@@ -17,17 +17,17 @@ UPDATE units SET code = $1, name = $2, updated_at = $3 WHERE id = $4;
 DELETE FROM units WHERE id = $1;
 
 -- =============================================================================
--- TAXES (id, code, name, rate) - no timestamps in schema
+-- TAXES (id, code, name, rate, is_withholding) - no timestamps in schema
 -- =============================================================================
 
 -- name: GetTax :one
-SELECT id, code, name, rate FROM taxes WHERE id = $1;
+SELECT id, code, name, rate, is_withholding FROM taxes WHERE id = $1;
 
 -- name: CreateTax :one
-INSERT INTO taxes (code, name, rate) VALUES ($1, $2, $3) RETURNING id, code, name, rate;
+INSERT INTO taxes (code, name, rate, is_withholding) VALUES ($1, $2, $3, $4) RETURNING id, code, name, rate, is_withholding;
 
 -- name: UpdateTax :exec
-UPDATE taxes SET code = $1, name = $2, rate = $3 WHERE id = $4;
+UPDATE taxes SET code = $1, name = $2, rate = $3, is_withholding = $4 WHERE id = $5;
 
 -- name: DeleteTax :exec
 DELETE FROM taxes WHERE id = $1;
@@ -37,15 +37,15 @@ DELETE FROM taxes WHERE id = $1;
 -- =============================================================================
 
 -- name: GetCategory :one
-SELECT id, code, name, parent_id, company_id, created_at, updated_at FROM categories WHERE id = $1;
+SELECT id, code, name, parent_id, company_id, created_at, updated_at, attribute_schema FROM categories WHERE id = $1;
 
 -- name: CreateCategory :one
-INSERT INTO categories (code, name, created_at, updated_at) 
-VALUES ($1, $2, $3, $4) 
-RETURNING id, code, name, parent_id, company_id, created_at, updated_at;
+INSERT INTO categories (code, name, attribute_schema, created_at, updated_at) 
+VALUES ($1, $2, $3, $4, $5) 
+RETURNING id, code, name, parent_id, company_id, created_at, updated_at, attribute_schema;
 
 -- name: UpdateCategory :exec
-UPDATE categories SET code = $1, name = $2, updated_at = $3 WHERE id = $4;
+UPDATE categories SET code = $1, name = $2, attribute_schema = $3, updated_at = $4 WHERE id = $5;
 
 -- name: DeleteCategory :exec
 DELETE FROM categories WHERE id = $1;
@@ -55,18 +55,18 @@ DELETE FROM categories WHERE id = $1;
 -- =============================================================================
 
 -- name: GetSupplier :one
-SELECT id, code, name, phone, email, address, is_active, company_id 
+SELECT id, code, name, phone, email, address, is_active, company_id, tax_id, country, payment_terms_days, withholding_tax_id 
 FROM suppliers WHERE id = $1;
 
 -- name: CreateSupplier :one
-INSERT INTO suppliers (code, name, phone, email, address, is_active) 
-VALUES ($1, $2, $3, $4, $5, $6) 
-RETURNING id, code, name, phone, email, address, is_active, company_id;
+INSERT INTO suppliers (code, name, phone, email, address, is_active, tax_id, country, payment_terms_days, withholding_tax_id) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
+RETURNING id, code, name, phone, email, address, is_active, company_id, tax_id, country, payment_terms_days, withholding_tax_id;
 
 -- name: UpdateSupplier :exec
 UPDATE suppliers 
-SET code = $1, name = $2, phone = $3, email = $4, address = $5, is_active = $6 
-WHERE id = $7;
+SET code = $1, name = $2, phone = $3, email = $4, address = $5, is_active = $6, tax_id = $7, country = $8, payment_terms_days = $9, withholding_tax_id = $10 
+WHERE id = $11;
 
 -- name: DeleteSupplier :exec
 DELETE FROM suppliers WHERE id = $1;
@@ -92,12 +92,28 @@ WHERE id = $6;
 -- name: DeleteCompany :exec
 DELETE FROM companies WHERE id = $1;
 
+-- name: GetCompanyDueDatePolicy :one
+SELECT company_id, business_days, holiday_calendar, updated_at
+FROM company_due_date_policies WHERE company_id = $1;
+
+-- name: SetCompanyDueDatePolicy :exec
+INSERT INTO company_due_date_policies (company_id, business_days, holiday_calendar, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (company_id) DO UPDATE
+SET business_days = EXCLUDED.business_days,
+    holiday_calendar = EXCLUDED.holiday_calendar,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: HolidayCalendarExists :one
+SELECT EXISTS (SELECT 1 FROM holiday_calendar_days WHERE calendar_code = $1);
+
 -- =============================================================================
 -- BRANCHES (id, company_id, code, name, address, created_at, updated_at)
 -- =============================================================================
 
 -- name: GetBranch :one
-SELECT id, company_id, code, name, address, created_at, updated_at 
+SELECT id, company_id, code, name, address, created_at, updated_at, gl_dim_company_id,
+       default_warehouse_id
 FROM branches WHERE id = $1;
 
 -- name: CreateBranch :one
@@ -113,12 +129,28 @@ WHERE id = $6;
 -- name: DeleteBranch :exec
 DELETE FROM branches WHERE id = $1;
 
+-- name: SetBranchGLDimensions :exec
+UPDATE branches
+SET gl_dim_company_id = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: SetBranchDefaultWarehouse :exec
+UPDATE branches
+SET default_warehouse_id = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: GetWarehouseCompanyID :one
+SELECT b.company_id
+FROM warehouses w
+JOIN branches b ON b.id = w.branch_id
+WHERE w.id = $1;
+
 -- =============================================================================
 -- WAREHOUSES (id, branch_id, code, name, address, created_at, updated_at)
 -- =============================================================================
 
 -- name: GetWarehouse :one
-SELECT id, branch_id, code, name, address, created_at, updated_at 
+SELECT id, branch_id, code, name, address, created_at, updated_at, gl_dim_company_id, gl_dim_branch_id
 FROM warehouses WHERE id = $1;
 
 -- name: CreateWarehouse :one
@@ -134,24 +166,39 @@ WHERE id = $6;
 -- name: DeleteWarehouse :exec
 DELETE FROM warehouses WHERE id = $1;
 
+-- name: SetWarehouseGLDimensions :exec
+UPDATE warehouses
+SET gl_dim_company_id = $1, gl_dim_branch_id = $2, updated_at = $3
+WHERE id = $4;
+
+-- name: GetWarehouseGLDimensions :one
+SELECT COALESCE(w.gl_dim_company_id, b.gl_dim_company_id, b.company_id)::BIGINT AS company_id,
+       COALESCE(w.gl_dim_branch_id, b.id)::BIGINT AS branch_id,
+       w.id AS warehouse_id
+FROM warehouses w
+JOIN branches b ON b.id = w.branch_id
+WHERE w.id = $1;
+
 -- =============================================================================
--- PRODUCTS (id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at)
--- Note: uses 'sku' instead of 'code', no 'cost', no created/updated_at
+-- PRODUCTS (id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, updated_at, track_serial)
+-- Note: uses 'sku' instead of 'code', no 'cost', no created_at
 -- =============================================================================
 
 -- name: GetProduct :one
-SELECT id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, company_id 
+SELECT id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, company_id, attributes, updated_at, track_serial 
 FROM products WHERE id = $1;
 
 -- name: CreateProduct :one
-INSERT INTO products (sku, name, category_id, unit_id, price, tax_id, is_active) 
-VALUES ($1, $2, $3, $4, $5, $6, $7) 
-RETURNING id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, company_id;
+INSERT INTO products (sku, name, category_id, unit_id, price, tax_id, is_active, attributes, track_serial) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
+RETURNING id, sku, name, category_id, unit_id, price, tax_id, is_active, deleted_at, company_id, attributes, updated_at, track_serial;
 
--- name: UpdateProduct :exec
+-- name: UpdateProduct :execrows
+-- A NULL expected_updated_at skips the optimistic lock.
 UPDATE products 
-SET sku = $1, name = $2, category_id = $3, unit_id = $4, price = $5, tax_id = $6, is_active = $7 
-WHERE id = $8;
+SET sku = $1, name = $2, category_id = $3, unit_id = $4, price = $5, tax_id = $6, is_active = $7, attributes = $8, track_serial = $9, updated_at = NOW() 
+WHERE id = $10
+  AND (sqlc.narg(expected_updated_at)::timestamptz IS NULL OR updated_at = sqlc.narg(expected_updated_at)::timestamptz);
 
 -- name: DeleteProduct :exec
 DELETE FROM products WHERE id = $1;
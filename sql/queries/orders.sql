@@ -2,7 +2,7 @@
 SELECT id, doc_number, company_id, sales_order_id, warehouse_id, customer_id,
        delivery_date, status, driver_name, vehicle_number, tracking_number,
        notes, created_by, confirmed_by, confirmed_at, delivered_at,
-       created_at, updated_at
+       created_at, updated_at, pod_receiver_name, pod_signature, pod_notes
 FROM delivery_orders
 WHERE id = $1;
 
@@ -10,7 +10,7 @@ WHERE id = $1;
 SELECT id, doc_number, company_id, sales_order_id, warehouse_id, customer_id,
        delivery_date, status, driver_name, vehicle_number, tracking_number,
        notes, created_by, confirmed_by, confirmed_at, delivered_at,
-       created_at, updated_at
+       created_at, updated_at, pod_receiver_name, pod_signature, pod_notes
 FROM delivery_orders
 WHERE company_id = $1 AND doc_number = $2;
 
@@ -27,10 +27,12 @@ SELECT dor.id, dor.doc_number, dor.company_id, dor.sales_order_id, dor.warehouse
        dor.customer_id, dor.delivery_date, dor.status, dor.driver_name,
        dor.vehicle_number, dor.tracking_number, dor.notes, dor.created_by,
        dor.confirmed_by, dor.confirmed_at, dor.delivered_at,
-       dor.created_at, dor.updated_at,
+       dor.created_at, dor.updated_at, dor.pod_receiver_name, dor.pod_signature,
+       dor.pod_notes,
        so.doc_number AS sales_order_number,
        w.name AS warehouse_name,
        c.name AS customer_name,
+       c.language AS customer_language,
        u_created.email AS created_by_name,
        u_confirmed.email AS confirmed_by_name,
        COUNT(dol.id) AS line_count,
@@ -47,7 +49,8 @@ GROUP BY dor.id, dor.doc_number, dor.company_id, dor.sales_order_id, dor.warehou
             dor.customer_id, dor.delivery_date, dor.status, dor.driver_name,
             dor.vehicle_number, dor.tracking_number, dor.notes, dor.created_by,
             dor.confirmed_by, dor.confirmed_at, dor.delivered_at,
-            dor.created_at, dor.updated_at, so.doc_number, w.name, c.name,
+            dor.created_at, dor.updated_at, dor.pod_receiver_name, dor.pod_signature,
+            dor.pod_notes, so.doc_number, w.name, c.name, c.language,
             u_created.email, u_confirmed.email;
 
 -- name: GetLinesWithDetails :many
@@ -131,3 +134,13 @@ WHERE id = $1;
 
 -- name: CheckWarehouseExists :one
 SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1);
+
+-- name: GetDefaultDeliveryWarehouse :one
+-- Branch default for a company; without a branch the lowest branch ID wins.
+SELECT default_warehouse_id
+FROM branches
+WHERE company_id = sqlc.arg(company_id)
+  AND default_warehouse_id IS NOT NULL
+  AND (sqlc.narg(branch_id)::bigint IS NULL OR id = sqlc.narg(branch_id)::bigint)
+ORDER BY id
+LIMIT 1;
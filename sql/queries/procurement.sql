@@ -3,8 +3,8 @@
 -- =============================================================================
 
 -- name: CreatePR :one
-INSERT INTO prs (number, supplier_id, request_by, status, note, created_at)
-VALUES ($1, $2, $3, $4, $5, NOW())
+INSERT INTO prs (number, supplier_id, request_by, status, note, currency, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
 RETURNING id;
 
 -- name: InsertPRLine :exec
@@ -12,7 +12,7 @@ INSERT INTO pr_lines (pr_id, product_id, qty, note)
 VALUES ($1, $2, $3, $4);
 
 -- name: GetPR :one
-SELECT id, number, supplier_id, request_by, status, note
+SELECT id, number, supplier_id, request_by, status, note, currency
 FROM prs WHERE id = $1;
 
 -- name: GetPRLines :many
@@ -22,13 +22,17 @@ FROM pr_lines WHERE pr_id = $1 ORDER BY id;
 -- name: UpdatePRStatus :exec
 UPDATE prs SET status = $1 WHERE id = $2;
 
+-- name: LinkPOSourcePR :exec
+INSERT INTO po_source_prs (po_id, pr_id)
+VALUES ($1, $2);
+
 -- =============================================================================
 -- PURCHASE ORDERS (PO)
 -- =============================================================================
 
 -- name: CreatePO :one
-INSERT INTO pos (number, supplier_id, status, currency, expected_date, note, created_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW())
+INSERT INTO pos (number, supplier_id, status, currency, expected_date, note, warehouse_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
 RETURNING id;
 
 -- name: InsertPOLine :exec
@@ -36,7 +40,7 @@ INSERT INTO po_lines (po_id, product_id, qty, price, tax_id, note)
 VALUES ($1, $2, $3, $4, $5, $6);
 
 -- name: GetPO :one
-SELECT id, number, supplier_id, status, currency, expected_date, note
+SELECT id, number, supplier_id, status, currency, expected_date, note, warehouse_id
 FROM pos WHERE id = $1;
 
 -- name: GetPOLines :many
@@ -59,15 +63,15 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
 RETURNING id;
 
 -- name: InsertGRNLine :exec
-INSERT INTO grn_lines (grn_id, product_id, qty, unit_cost)
-VALUES ($1, $2, $3, $4);
+INSERT INTO grn_lines (grn_id, product_id, qty, unit_cost, serials, uom)
+VALUES ($1, $2, $3, $4, $5, $6);
 
 -- name: GetGRN :one
 SELECT id, number, po_id, supplier_id, warehouse_id, status, received_at, note
 FROM grns WHERE id = $1;
 
 -- name: GetGRNLines :many
-SELECT id, grn_id, product_id, qty, unit_cost
+SELECT id, grn_id, product_id, qty, unit_cost, serials, uom
 FROM grn_lines WHERE grn_id = $1 ORDER BY id;
 
 -- name: UpdateGRNStatus :exec
@@ -67,3 +67,25 @@ JOIN role_permissions rp ON rp.role_id = ur.role_id
 JOIN permissions p ON p.id = rp.permission_id
 WHERE ur.user_id = $1
 ORDER BY p.name;
+
+-- name: ListUserCompanyIDs :many
+SELECT company_id
+FROM user_companies
+WHERE user_id = $1
+ORDER BY company_id;
+
+-- name: ListUserRoleNames :many
+SELECT r.name
+FROM user_roles ur
+JOIN roles r ON r.id = ur.role_id
+WHERE ur.user_id = $1
+ORDER BY r.name;
+
+-- name: DeleteUserCompanies :exec
+DELETE FROM user_companies
+WHERE user_id = $1;
+
+-- name: AddUserCompany :exec
+INSERT INTO user_companies (user_id, company_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
@@ -6,7 +6,7 @@
 SELECT id, code, name, company_id, email, phone, tax_id,
        credit_limit, payment_terms_days, address_line1, address_line2,
        city, state, postal_code, country, is_active, notes,
-       created_by, created_at, updated_at
+       created_by, created_at, updated_at, language
 FROM customers
 WHERE id = $1;
 
@@ -14,7 +14,7 @@ WHERE id = $1;
 SELECT id, code, name, company_id, email, phone, tax_id,
        credit_limit, payment_terms_days, address_line1, address_line2,
        city, state, postal_code, country, is_active, notes,
-       created_by, created_at, updated_at
+       created_by, created_at, updated_at, language
 FROM customers
 WHERE company_id = $1 AND code = $2;
 
@@ -22,8 +22,8 @@ WHERE company_id = $1 AND code = $2;
 INSERT INTO customers (
     code, name, company_id, email, phone, tax_id,
     credit_limit, payment_terms_days, address_line1, address_line2,
-    city, state, postal_code, country, is_active, notes, created_by
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+    city, state, postal_code, country, is_active, notes, created_by, language
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING id;
 
 -- name: UpdateCustomer :exec
@@ -50,7 +50,8 @@ WHERE id = sqlc.arg('id');
 SELECT id, doc_number, company_id, customer_id, quote_date, valid_until,
        status, currency, subtotal, tax_amount, total_amount, notes,
        created_by, approved_by, approved_at, rejected_by, rejected_at,
-       rejection_reason, created_at, updated_at
+       rejection_reason, created_at, updated_at,
+       document_discount_percent, document_discount_amount, loss_reason
 FROM quotations
 WHERE id = $1;
 
@@ -58,29 +59,32 @@ WHERE id = $1;
 SELECT id, doc_number, company_id, customer_id, quote_date, valid_until,
        status, currency, subtotal, tax_amount, total_amount, notes,
        created_by, approved_by, approved_at, rejected_by, rejected_at,
-       rejection_reason, created_at, updated_at
+       rejection_reason, created_at, updated_at,
+       document_discount_percent, document_discount_amount, loss_reason
 FROM quotations
 WHERE doc_number = $1;
 
 -- name: CreateQuotation :one
 INSERT INTO quotations (
     doc_number, company_id, customer_id, quote_date, valid_until,
-    status, currency, subtotal, tax_amount, total_amount, notes, created_by
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    status, currency, subtotal, tax_amount, total_amount, notes, created_by,
+    document_discount_percent, document_discount_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING id;
 
 -- name: InsertQuotationLine :one
 INSERT INTO quotation_lines (
     quotation_id, product_id, description, quantity, uom,
     unit_price, discount_percent, discount_amount, tax_percent,
-    tax_amount, line_total, notes, line_order
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    tax_amount, line_total, notes, line_order, document_discount_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING id;
 
 -- name: GetQuotationLines :many
 SELECT id, quotation_id, product_id, description, quantity, uom,
        unit_price, discount_percent, discount_amount, tax_percent,
-       tax_amount, line_total, notes, line_order, created_at, updated_at
+       tax_amount, line_total, notes, line_order, created_at, updated_at,
+       document_discount_amount
 FROM quotation_lines
 WHERE quotation_id = $1
 ORDER BY line_order, id;
@@ -107,7 +111,8 @@ DELETE FROM quotation_lines WHERE quotation_id = $1;
 SELECT id, doc_number, company_id, customer_id, quotation_id, order_date,
        expected_delivery_date, status, currency, subtotal, tax_amount, total_amount,
        notes, created_by, confirmed_by, confirmed_at, cancelled_by, cancelled_at,
-       cancellation_reason, created_at, updated_at
+       cancellation_reason, created_at, updated_at,
+       document_discount_percent, document_discount_amount
 FROM sales_orders
 WHERE id = $1;
 
@@ -115,7 +120,8 @@ WHERE id = $1;
 SELECT id, doc_number, company_id, customer_id, quotation_id, order_date,
        expected_delivery_date, status, currency, subtotal, tax_amount, total_amount,
        notes, created_by, confirmed_by, confirmed_at, cancelled_by, cancelled_at,
-       cancellation_reason, created_at, updated_at
+       cancellation_reason, created_at, updated_at,
+       document_discount_percent, document_discount_amount
 FROM sales_orders
 WHERE doc_number = $1;
 
@@ -123,8 +129,9 @@ WHERE doc_number = $1;
 INSERT INTO sales_orders (
     doc_number, company_id, customer_id, quotation_id, order_date,
     expected_delivery_date, status, currency, subtotal, tax_amount,
-    total_amount, notes, created_by
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    total_amount, notes, created_by, document_discount_percent,
+    document_discount_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 RETURNING id;
 
 -- name: InsertSalesOrderLine :one
@@ -132,15 +139,16 @@ INSERT INTO sales_order_lines (
     sales_order_id, product_id, description, quantity,
     quantity_delivered, quantity_invoiced, uom, unit_price,
     discount_percent, discount_amount, tax_percent, tax_amount,
-    line_total, notes, line_order
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+    line_total, notes, line_order, document_discount_amount
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 RETURNING id;
 
 -- name: GetSalesOrderLines :many
 SELECT id, sales_order_id, product_id, description, quantity,
        quantity_delivered, quantity_invoiced, uom, unit_price,
        discount_percent, discount_amount, tax_percent, tax_amount,
-       line_total, notes, line_order, created_at, updated_at
+       line_total, notes, line_order, created_at, updated_at,
+       document_discount_amount
 FROM sales_order_lines
 WHERE sales_order_id = $1
 ORDER BY line_order, id;
@@ -47,10 +47,14 @@ function bridgeServerFlashToToast() {
     const flashEl = document.getElementById('server-flash');
     if (!flashEl) return;
 
-    const message = flashEl.dataset.flashMessage;
+    let message = flashEl.dataset.flashMessage;
     const kind = flashEl.dataset.flashKind;
+    const reference = flashEl.dataset.flashReference;
     
     if (!message) return;
+    if (reference) {
+        message += ` (Ref: ${reference})`;
+    }
 
     const variant = FLASH_KIND_TO_TOAST_VARIANT[kind] || 'info';
     